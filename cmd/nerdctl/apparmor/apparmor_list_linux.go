@@ -19,8 +19,10 @@ package apparmor
 import (
 	"github.com/spf13/cobra"
 
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/apparmor"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 )
 
 func listCommand() *cobra.Command {
@@ -42,7 +44,7 @@ func listCommand() *cobra.Command {
 	return cmd
 }
 
-func listOptions(cmd *cobra.Command) (types.ApparmorListOptions, error) {
+func listOptions(cmd *cobra.Command, globalOptions types.GlobalCommandOptions) (types.ApparmorListOptions, error) {
 	quiet, err := cmd.Flags().GetBool("quiet")
 	if err != nil {
 		return types.ApparmorListOptions{}, err
@@ -51,6 +53,10 @@ func listOptions(cmd *cobra.Command) (types.ApparmorListOptions, error) {
 	if err != nil {
 		return types.ApparmorListOptions{}, err
 	}
+	format, err = helpers.ListFormat(globalOptions, format, quiet)
+	if err != nil {
+		return types.ApparmorListOptions{}, err
+	}
 	return types.ApparmorListOptions{
 		Quiet:  quiet,
 		Format: format,
@@ -59,9 +65,15 @@ func listOptions(cmd *cobra.Command) (types.ApparmorListOptions, error) {
 }
 
 func listAction(cmd *cobra.Command, args []string) error {
-	options, err := listOptions(cmd)
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	options, err := listOptions(cmd, globalOptions)
 	if err != nil {
 		return err
 	}
-	return apparmor.List(options)
+	stdout, finish := helpers.OutputWriter(cmd, globalOptions, formatter.KindAppArmorList, formatter.ItemsFromJSONLines)
+	options.Stdout = stdout
+	return finish(apparmor.List(options))
 }
@@ -29,6 +29,7 @@ import (
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/builder"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
 )
 
 func Command() *cobra.Command {
@@ -44,6 +45,11 @@ func Command() *cobra.Command {
 		BuildCommand(),
 		pruneCommand(),
 		debugCommand(),
+		startCommand(),
+		stopCommand(),
+		listCommand(),
+		removeCommand(),
+		binfmtCommand(),
 	)
 	return cmd
 }
@@ -60,8 +66,11 @@ func pruneCommand() *cobra.Command {
 	}
 
 	cmd.Flags().String("buildkit-host", "", "BuildKit address")
+	cmd.Flags().String("builder", "", "Name of the builder started by \"nerdctl builder start\"")
 	cmd.Flags().BoolP("all", "a", false, "Remove all unused build cache, not just dangling ones")
 	cmd.Flags().BoolP("force", "f", false, "Do not prompt for confirmation")
+	cmd.Flags().String("keep-storage", "", "Amount of build cache to keep (e.g., \"10GB\")")
+	cmd.Flags().StringSlice("filter", nil, "Provide filter values (e.g., \"until=24h\")")
 	return cmd
 }
 
@@ -122,12 +131,29 @@ func pruneOptions(cmd *cobra.Command) (types.BuilderPruneOptions, error) {
 		return types.BuilderPruneOptions{}, err
 	}
 
+	var keepStorage int64
+	if s, err := cmd.Flags().GetString("keep-storage"); err != nil {
+		return types.BuilderPruneOptions{}, err
+	} else if s != "" {
+		keepStorage, err = units.RAMInBytes(s)
+		if err != nil {
+			return types.BuilderPruneOptions{}, fmt.Errorf("invalid --keep-storage %q: %w", s, err)
+		}
+	}
+
+	filters, err := cmd.Flags().GetStringSlice("filter")
+	if err != nil {
+		return types.BuilderPruneOptions{}, err
+	}
+
 	return types.BuilderPruneOptions{
 		Stderr:       cmd.OutOrStderr(),
 		GOptions:     globalOptions,
 		BuildKitHost: buildkitHost,
 		All:          all,
 		Force:        force,
+		KeepStorage:  keepStorage,
+		Filters:      filters,
 	}, nil
 }
 
@@ -159,6 +185,10 @@ func debugAction(cmd *cobra.Command, args []string) error {
 	if len(args) < 1 {
 		return fmt.Errorf("context needs to be specified")
 	}
+	if globalOptions.Offline {
+		// buildg resolves the base images on its own, without nerdctl
+		return fmt.Errorf("nerdctl builder debug is not supported in the offline mode: %w", dockerconfigresolver.ErrOffline)
+	}
 
 	buildgBinary, err := exec.LookPath("buildg")
 	if err != nil {
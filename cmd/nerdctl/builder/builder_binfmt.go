@@ -0,0 +1,97 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/builder"
+)
+
+func binfmtCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:           "binfmt",
+		Short:         "Manage QEMU emulators for building and running images of other platforms",
+		RunE:          helpers.UnknownSubcommandAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.AddCommand(
+		binfmtListCommand(),
+		binfmtInstallCommand(),
+	)
+	return cmd
+}
+
+func binfmtListCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:           "ls",
+		Aliases:       []string{"list"},
+		Short:         "List the platforms that can be executed on the host",
+		Args:          cobra.NoArgs,
+		RunE:          binfmtListAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().String("format", "", "Format the output using the given Go template, e.g, '{{json .}}'")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "table"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	return cmd
+}
+
+func binfmtListAction(cmd *cobra.Command, args []string) error {
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	return builder.BinfmtList(cmd.Context(), types.BuilderBinfmtListOptions{
+		Stdout: cmd.OutOrStdout(),
+		Format: format,
+	})
+}
+
+func binfmtInstallCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "install [flags] [PLATFORM...]",
+		Short: "Register QEMU emulators to binfmt_misc (defaults to all the platforms)",
+		Long: `Register QEMU emulators to binfmt_misc (defaults to all the platforms).
+Runs the tonistiigi/binfmt image in a privileged container. Requires rootful mode.`,
+		RunE:          binfmtInstallAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().String("image", builder.DefaultBinfmtImage, "Image for registering the emulators")
+	return cmd
+}
+
+func binfmtInstallAction(cmd *cobra.Command, args []string) error {
+	image, err := cmd.Flags().GetString("image")
+	if err != nil {
+		return err
+	}
+	nerdctlCmd, nerdctlArgs := helpers.GlobalFlags(cmd)
+	return builder.BinfmtInstall(cmd.Context(), args, types.BuilderBinfmtInstallOptions{
+		Stdout:      cmd.OutOrStdout(),
+		Stderr:      cmd.ErrOrStderr(),
+		Image:       image,
+		NerdctlCmd:  nerdctlCmd,
+		NerdctlArgs: nerdctlArgs,
+	})
+}
@@ -0,0 +1,76 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestBuilderBinfmtList(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+	testCase.Command = test.Command("builder", "binfmt", "ls", "--format", "{{.Platform}} {{.Status}}")
+	testCase.Expected = test.Expects(0, nil, expect.Contains(fmt.Sprintf("linux/%s native", runtime.GOARCH)))
+
+	testCase.Run(t)
+}
+
+func TestBuildMultiPlatformBinfmtInstall(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.All(
+		nerdtest.Build,
+		nerdtest.Rootful,
+		require.Not(nerdtest.Docker),
+		require.Arch("amd64"),
+	)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		dockerfile := fmt.Sprintf(`FROM %s
+RUN uname -m > /arch`, testutil.CommonImage)
+		data.Temp().Save(dockerfile, "Dockerfile")
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rmi", "-f", data.Identifier())
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		return helpers.Command("build", "--platform=linux/amd64,linux/arm64", "-t", data.Identifier(), data.Temp().Path())
+	}
+
+	testCase.Expected = func(data test.Data, helpers test.Helpers) *test.Expected {
+		return &test.Expected{
+			Output: func(stdout, info string, t *testing.T) {
+				helpers.Command("run", "--rm", "--platform=linux/arm64", data.Identifier(), "cat", "/arch").
+					Run(&test.Expected{Output: expect.Equals("aarch64\n")})
+			},
+		}
+	}
+
+	testCase.Run(t)
+}
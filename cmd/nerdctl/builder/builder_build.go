@@ -33,6 +33,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/buildkitutil"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/builder"
+	"github.com/containerd/nerdctl/v2/pkg/sshutil"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
@@ -47,13 +48,14 @@ If Dockerfile is not present and -f is not specified, it will look for Container
 		SilenceErrors: true,
 	}
 	cmd.Flags().String("buildkit-host", "", "BuildKit address")
+	cmd.Flags().String("builder", "", "Name of the builder started by \"nerdctl builder start\"")
 	cmd.Flags().StringArray("add-host", nil, "Add a custom host-to-IP mapping (format: \"host:ip\")")
 	cmd.Flags().StringArrayP("tag", "t", nil, "Name and optionally a tag in the 'name:tag' format")
 	cmd.Flags().StringP("file", "f", "", "Name of the Dockerfile")
 	cmd.Flags().String("target", "", "Set the target build stage to build")
 	cmd.Flags().StringArray("build-arg", nil, "Set build-time variables")
 	cmd.Flags().Bool("no-cache", false, "Do not use cache when building the image")
-	cmd.Flags().StringP("output", "o", "", "Output destination (format: type=local,dest=path)")
+	cmd.Flags().StringP("output", "o", "", "Output destination (format: type=local,dest=path). Supported types: local, tar, oci, docker, image, registry, cacheonly")
 	cmd.Flags().String("progress", "auto", "Set type of progress output (auto, plain, tty). Use plain to show container output")
 	cmd.Flags().String("provenance", "", "Shorthand for \"--attest=type=provenance\"")
 	cmd.Flags().Bool("pull", false, "On true, always attempt to pull latest image version from remote. Default uses buildkit's default.")
@@ -78,10 +80,12 @@ If Dockerfile is not present and -f is not specified, it will look for Container
 	cmd.Flags().StringSlice("platform", []string{}, "Set target platform for build (e.g., \"amd64\", \"arm64\")")
 	cmd.RegisterFlagCompletionFunc("platform", completion.Platforms)
 	cmd.Flags().StringArray("build-context", []string{}, "Additional build contexts (e.g., name=path)")
+	cmd.Flags().Bool("binfmt-install", true, "Install QEMU emulators for the target platforms that cannot be executed on the host (rootful only)")
 	// #endregion
 
 	cmd.Flags().String("iidfile", "", "Write the image ID to the file")
 	cmd.Flags().StringArray("label", nil, "Set metadata for an image")
+	cmd.Flags().StringSlice("label-file", nil, "Set metadata for an image from file")
 
 	return cmd
 }
@@ -139,6 +143,18 @@ func processBuildCommandFlag(cmd *cobra.Command, args []string) (types.BuilderBu
 	if err != nil {
 		return types.BuilderBuildOptions{}, err
 	}
+	labelFile, err := cmd.Flags().GetStringSlice("label-file")
+	if err != nil {
+		return types.BuilderBuildOptions{}, err
+	}
+	cfg, err := helpers.LoadNerdctlTOML(helpers.NerdctlTOMLPath())
+	if err != nil {
+		return types.BuilderBuildOptions{}, err
+	}
+	defaultLabels, err := cfg.DefaultLabels()
+	if err != nil {
+		return types.BuilderBuildOptions{}, err
+	}
 	noCache, err := cmd.Flags().GetBool("no-cache")
 	if err != nil {
 		return types.BuilderBuildOptions{}, err
@@ -211,6 +227,12 @@ func processBuildCommandFlag(cmd *cobra.Command, args []string) (types.BuilderBu
 		return types.BuilderBuildOptions{}, err
 	}
 
+	binfmtInstall, err := cmd.Flags().GetBool("binfmt-install")
+	if err != nil {
+		return types.BuilderBuildOptions{}, err
+	}
+	nerdctlCmd, nerdctlArgs := helpers.GlobalFlags(cmd)
+
 	usernsRemap, err := cmd.Flags().GetString("userns-remap")
 	if err != nil {
 		return types.BuilderBuildOptions{}, err
@@ -229,6 +251,8 @@ func processBuildCommandFlag(cmd *cobra.Command, args []string) (types.BuilderBu
 		Target:               target,
 		BuildArgs:            buildArgs,
 		Label:                label,
+		LabelFile:            labelFile,
+		DefaultLabels:        defaultLabels,
 		NoCache:              noCache,
 		Pull:                 pull,
 		Secret:               secret,
@@ -247,10 +271,35 @@ func processBuildCommandFlag(cmd *cobra.Command, args []string) (types.BuilderBu
 		NetworkMode:          network,
 		ExtendedBuildContext: extendedBuildCtx,
 		ExtraHosts:           extraHosts,
+		BinfmtInstall:        binfmtInstall,
+		NerdctlCmd:           nerdctlCmd,
+		NerdctlArgs:          nerdctlArgs,
 	}, nil
 }
 
 func GetBuildkitHost(cmd *cobra.Command, namespace string) (string, error) {
+	if cmd.Flags().Changed("builder") {
+		// If a builder started by `nerdctl builder start` is specified, use its address.
+		if cmd.Flags().Changed("buildkit-host") {
+			return "", errors.New("--builder and --buildkit-host must not be specified together")
+		}
+		name, err := cmd.Flags().GetString("builder")
+		if err != nil {
+			return "", err
+		}
+		globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+		if err != nil {
+			return "", err
+		}
+		buildkitHost, err := builder.BuildkitHost(globalOptions, name)
+		if err != nil {
+			return "", err
+		}
+		if err := buildkitutil.PingBKDaemon(buildkitHost); err != nil {
+			return "", err
+		}
+		return buildkitHost, nil
+	}
 	if cmd.Flags().Changed("buildkit-host") {
 		// If address is explicitly specified, use it.
 		buildkitHost, err := cmd.Flags().GetString("buildkit-host")
@@ -270,6 +319,13 @@ func GetBuildkitHost(cmd *cobra.Command, namespace string) (string, error) {
 		return buildkitHost, nil
 
 	}
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return "", err
+	}
+	if sshutil.IsSSH(globalOptions.Address) {
+		return buildkitutil.GetBuildkitHostOverSSH(globalOptions.Address, namespace)
+	}
 	return buildkitutil.GetBuildkitHost(namespace)
 }
 
@@ -17,8 +17,11 @@
 package builder
 
 import (
+	"archive/tar"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -360,6 +363,40 @@ COPY %s /`, testFileName)
 					}
 				},
 			},
+			{
+				Description: "-o type tar destination FILE: verify the file copied from context is in the tar archive without creating an image",
+				Require:     require.Not(nerdtest.Docker),
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					return helpers.Command("build", "-t", data.Identifier("foo"), "-t", data.Identifier("bar"),
+						"-o", fmt.Sprintf("type=tar,dest=%s", data.Temp().Path("out.tar")), data.Labels().Get("buildCtx"))
+				},
+				Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+					return &test.Expected{
+						Output: func(stdout, info string, t *testing.T) {
+							f, err := os.Open(data.Temp().Path("out.tar"))
+							assert.NilError(t, err)
+							defer f.Close()
+							tr := tar.NewReader(f)
+							found := false
+							for {
+								hdr, err := tr.Next()
+								if errors.Is(err, io.EOF) {
+									break
+								}
+								assert.NilError(t, err)
+								if hdr.Name == testFileName {
+									b, err := io.ReadAll(tr)
+									assert.NilError(t, err)
+									assert.Equal(t, string(b), testContent, "file content is identical")
+									found = true
+								}
+							}
+							assert.Assert(t, found, "file not found in the tar archive")
+							helpers.Fail("image", "inspect", data.Identifier("foo"))
+						},
+					}
+				},
+			},
 		},
 	}
 
@@ -943,6 +980,19 @@ func TestBuildAttestation(t *testing.T) {
 					}
 				},
 			},
+			{
+				Description: "Image index",
+				Cleanup: func(data test.Data, helpers test.Helpers) {
+					helpers.Anyhow("rmi", "-f", data.Identifier())
+				},
+				Setup: func(data test.Data, helpers test.Helpers) {
+					helpers.Ensure("build", "--sbom=true", "--provenance=mode=max", "-t", data.Identifier(), data.Labels().Get("buildCtx"))
+				},
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					return helpers.Command("image", "inspect", "--mode=native", "--format={{json .Index.Manifests}}", data.Identifier())
+				},
+				Expected: test.Expects(0, nil, expect.Contains(`"vnd.docker.reference.type":"attestation-manifest"`)),
+			},
 		},
 	}
 
@@ -1064,3 +1114,77 @@ CMD ["echo", "nerdctl-build-test-string"]`, testutil.CommonImage)
 	}
 	testCase.Run(t)
 }
+
+func TestBuildCacheLocal(t *testing.T) {
+	nerdtest.Setup()
+
+	testCase := &test.Case{
+		Require: require.All(
+			nerdtest.Build,
+			require.Not(nerdtest.Docker),
+		),
+		Setup: func(data test.Data, helpers test.Helpers) {
+			dockerfile := fmt.Sprintf(`FROM %s
+RUN echo nerdctl-build-test-cache > /cached`, testutil.CommonImage)
+			data.Temp().Save(dockerfile, "Dockerfile")
+			helpers.Ensure("build", "-t", data.Identifier(),
+				"--cache-to", "type=local,dest="+data.Temp().Path("cache")+",mode=max", data.Temp().Path())
+		},
+		Cleanup: func(data test.Data, helpers test.Helpers) {
+			helpers.Anyhow("rmi", "-f", data.Identifier())
+		},
+		SubTests: []*test.Case{
+			{
+				Description: "cache is exported to the local directory",
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					return helpers.Custom("ls", data.Temp().Path("cache"))
+				},
+				Expected: test.Expects(0, nil, expect.Contains("index.json")),
+			},
+			{
+				Description: "cache is imported from the local directory",
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					return helpers.Command("build", "--progress=plain", "-t", data.Identifier(),
+						"--cache-from", "type=local,src="+data.Temp().Path("cache"), data.Temp().Path())
+				},
+				Expected: test.Expects(0, nil, nil),
+			},
+			{
+				Description: "invalid cache type",
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					return helpers.Command("build", "--cache-to", "type=foo", data.Temp().Path())
+				},
+				Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New(`unknown cache type "foo"`)}, nil),
+			},
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestBuildErrorDiagnosis(t *testing.T) {
+	nerdtest.Setup()
+
+	dockerfile := fmt.Sprintf(`FROM %s
+RUN --mount=type=secret,id=nerdctl-missing-secret,required=true cat /run/secrets/nerdctl-missing-secret`, testutil.CommonImage)
+
+	testCase := &test.Case{
+		Require: require.All(
+			nerdtest.Build,
+			require.Not(nerdtest.Docker),
+		),
+		Setup: func(data test.Data, helpers test.Helpers) {
+			data.Temp().Save(dockerfile, "Dockerfile")
+			data.Labels().Set("buildCtx", data.Temp().Path())
+		},
+		Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+			return helpers.Command("build", "--progress=plain", "--no-cache", data.Labels().Get("buildCtx"))
+		},
+		Expected: test.Expects(expect.ExitCodeGenericFail, []error{
+			errors.New("Dockerfile:2"),
+			errors.New("hint: the secret \"nerdctl-missing-secret\" is not provided"),
+		}, nil),
+	}
+
+	testCase.Run(t)
+}
@@ -68,6 +68,19 @@ CMD ["echo", "nerdctl-test-builder-prune"]`, testutil.CommonImage)
 				Command:  test.Command("builder", "prune", "--force", "--all"),
 				Expected: test.Expects(0, nil, nil),
 			},
+			{
+				Description: "PruneKeepStorageAndFilter",
+				NoParallel:  true,
+				Command:     test.Command("builder", "prune", "--force", "--keep-storage", "100MB", "--filter", "until=1h"),
+				Expected:    test.Expects(0, nil, nil),
+			},
+			{
+				Description: "PruneInvalidFilter",
+				NoParallel:  true,
+				Require:     require.Not(nerdtest.Docker),
+				Command:     test.Command("builder", "prune", "--force", "--filter", "label=foo"),
+				Expected:    test.Expects(expect.ExitCodeGenericFail, nil, nil),
+			},
 			{
 				Description: "builder with buildkit-host",
 				NoParallel:  true,
@@ -0,0 +1,87 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/buildkitutil/buildkitd"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/builder"
+)
+
+func startCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "start [flags] [NAME]",
+		Short: "Start a BuildKit daemon (builder)",
+		Long: `Start a BuildKit daemon (builder) supervised by nerdctl, defining it on the first start.
+
+The "default" builder listens on the socket auto-detected by "nerdctl build".
+Other builders can be used with "nerdctl build --builder=NAME".`,
+		Args:          cobra.MaximumNArgs(1),
+		RunE:          startAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().String("worker", "", "Worker of buildkitd (\"containerd\"|\"oci\"), defaults to \"containerd\"")
+	cmd.RegisterFlagCompletionFunc("worker", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{buildkitd.WorkerContainerd, buildkitd.WorkerOCI}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().String("config", "", "Path of buildkitd.toml")
+	cmd.Flags().StringArray("buildkitd-flag", nil, "Extra flag for buildkitd (e.g., \"--debug\")")
+	cmd.Flags().Duration("timeout", 30*time.Second, "Time to wait for buildkitd to become ready")
+	return cmd
+}
+
+func startAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	worker, err := cmd.Flags().GetString("worker")
+	if err != nil {
+		return err
+	}
+	config, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return err
+	}
+	buildkitdFlags, err := cmd.Flags().GetStringArray("buildkitd-flag")
+	if err != nil {
+		return err
+	}
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+	name := buildkitd.DefaultName
+	if len(args) > 0 {
+		name = args[0]
+	}
+	return builder.Start(cmd.Context(), types.BuilderStartOptions{
+		Stdout:         cmd.OutOrStdout(),
+		GOptions:       globalOptions,
+		Name:           name,
+		Worker:         worker,
+		Config:         config,
+		BuildkitdFlags: buildkitdFlags,
+		Timeout:        timeout,
+	})
+}
@@ -0,0 +1,92 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestBuilderStart(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.All(
+		nerdtest.Build,
+		require.Not(nerdtest.Docker),
+		require.Binary("buildkitd"),
+	)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		// The name is kept short, as it is part of the path of the socket of buildkitd
+		helpers.Ensure("builder", "start", "test-builder-start")
+		data.Labels().Set("builder", "test-builder-start")
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("builder", "rm", "-f", "test-builder-start")
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "ls shows the builder as running",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("builder", "ls", "--format", "{{.Name}} {{.Status}} {{.Worker}}")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.Contains(data.Labels().Get("builder") + " running containerd"),
+				}
+			},
+		},
+		{
+			Description: "build with --builder",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				dockerfile := fmt.Sprintf(`FROM %s
+CMD ["echo", "nerdctl-test-builder-start"]`, testutil.CommonImage)
+				data.Temp().Save(dockerfile, "Dockerfile")
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rmi", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("build", "--builder", data.Labels().Get("builder"), "-t", data.Identifier(), data.Temp().Path())
+			},
+			Expected: test.Expects(0, nil, nil),
+		},
+		{
+			Description: "start with a different configuration fails",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("builder", "start", "--worker", "oci", data.Labels().Get("builder"))
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+		{
+			Description: "unknown builder",
+			Command:     test.Command("build", "--builder", "nerdctl-test-no-such-builder", "."),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
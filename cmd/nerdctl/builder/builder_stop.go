@@ -0,0 +1,59 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/buildkitutil/buildkitd"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/builder"
+)
+
+func stopCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:           "stop [flags] [NAME...]",
+		Short:         "Stop BuildKit daemons (builders) started by \"nerdctl builder start\"",
+		RunE:          stopAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().Duration("timeout", 10*time.Second, "Time to wait for buildkitd to exit before killing it")
+	return cmd
+}
+
+func stopAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		args = []string{buildkitd.DefaultName}
+	}
+	return builder.Stop(cmd.Context(), args, types.BuilderStopOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+		Timeout:  timeout,
+	})
+}
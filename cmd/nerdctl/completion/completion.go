@@ -18,6 +18,7 @@ package completion
 
 import (
 	"context"
+	"path/filepath"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -27,7 +28,10 @@ import (
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/machine"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/secret"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
+	"github.com/containerd/nerdctl/v2/pkg/contextstore"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/netutil"
@@ -157,6 +161,26 @@ func VolumeNames(cmd *cobra.Command) ([]string, cobra.ShellCompDirective) {
 	return candidates, cobra.ShellCompDirectiveNoFileComp
 }
 
+func SecretNames(cmd *cobra.Command) ([]string, cobra.ShellCompDirective) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	secretStore, err := secret.Store(globalOptions.Namespace, globalOptions.DataRoot, globalOptions.Address)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	secrets, err := secretStore.List()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	candidates := []string{}
+	for _, s := range secrets {
+		candidates = append(candidates, s.Name)
+	}
+	return candidates, cobra.ShellCompDirectiveNoFileComp
+}
+
 func Platforms(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	candidates := []string{
 		"amd64",
@@ -180,3 +204,35 @@ func getVolumes(cmd *cobra.Command, globalOptions types.GlobalCommandOptions) (m
 	}
 	return volume.Volumes(globalOptions.Namespace, globalOptions.DataRoot, globalOptions.Address, volumeSize, nil)
 }
+
+func ContextNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cs, err := contextstore.New(filepath.Dir(helpers.NerdctlTOMLPath()))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	contexts, err := cs.List()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var candidates []string
+	for _, c := range contexts {
+		candidates = append(candidates, c.Name)
+	}
+	return candidates, cobra.ShellCompDirectiveNoFileComp
+}
+
+func MachineNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cs, err := contextstore.New(filepath.Dir(helpers.NerdctlTOMLPath()))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	machines, err := machine.Machines(cmd.Context(), cs)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var candidates []string
+	for _, m := range machines {
+		candidates = append(candidates, m.Name)
+	}
+	return candidates, cobra.ShellCompDirectiveNoFileComp
+}
@@ -46,3 +46,11 @@ func CgroupManagerNames(cmd *cobra.Command, args []string, toComplete string) ([
 	}
 	return candidates, cobra.ShellCompDirectiveNoFileComp
 }
+
+func RootlessKitPortDriverNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return rootlessutil.PortDrivers, cobra.ShellCompDirectiveNoFileComp
+}
+
+func PortForwardingBackendNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{"iptables", "nftables"}, cobra.ShellCompDirectiveNoFileComp
+}
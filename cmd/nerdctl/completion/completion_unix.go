@@ -30,7 +30,7 @@ import (
 )
 
 func NetworkDrivers(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	candidates := []string{"bridge", "macvlan", "ipvlan"}
+	candidates := []string{"bridge", "macvlan", "ipvlan", "wireguard"}
 	return candidates, cobra.ShellCompDirectiveNoFileComp
 }
 
@@ -17,6 +17,8 @@
 package compose
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
@@ -39,6 +41,7 @@ func Command() *cobra.Command {
 	cmd.PersistentFlags().String("env-file", "", "Specify an alternate environment file")
 	cmd.PersistentFlags().String("ipfs-address", "", "multiaddr of IPFS API (default uses $IPFS_PATH env variable if defined or local directory ~/.ipfs)")
 	cmd.PersistentFlags().StringArray("profile", []string{}, "Specify a profile to enable")
+	cmd.PersistentFlags().Int("parallel", -1, "Control max parallelism, -1 for unlimited")
 
 	cmd.AddCommand(
 		upCommand(),
@@ -64,6 +67,8 @@ func Command() *cobra.Command {
 		unpauseCommand(),
 		topCommand(),
 		createCommand(),
+		lockCommand(),
+		outdatedCommand(),
 	)
 
 	return cmd
@@ -95,6 +100,13 @@ func getComposeOptions(cmd *cobra.Command, debugFull, experimental bool) (compos
 	if err != nil {
 		return composer.Options{}, err
 	}
+	parallel, err := cmd.Flags().GetInt("parallel")
+	if err != nil {
+		return composer.Options{}, err
+	}
+	if parallel == 0 || parallel < -1 {
+		return composer.Options{}, fmt.Errorf("invalid --parallel %d: must be a positive number, or -1 for unlimited", parallel)
+	}
 
 	return composer.Options{
 		Project:          projectName,
@@ -107,5 +119,6 @@ func getComposeOptions(cmd *cobra.Command, debugFull, experimental bool) (compos
 		DebugPrintFull:   debugFull,
 		Experimental:     experimental,
 		IPFSAddress:      ipfsAddressStr,
+		Parallel:         parallel,
 	}, nil
 }
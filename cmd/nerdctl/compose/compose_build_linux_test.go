@@ -107,6 +107,14 @@ services:
 
 			Expected: test.Expects(expect.ExitCodeSuccess, nil, nil),
 		},
+		{
+			Description: "build with parallel limit",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("compose", "-f", data.Labels().Get("composeYaml"), "build", "--parallel=1")
+			},
+
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, nil),
+		},
 		{
 			Description: "build bogus",
 			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
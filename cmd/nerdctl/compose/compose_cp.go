@@ -41,6 +41,7 @@ func copyCommand() *cobra.Command {
 	}
 	cmd.Flags().Bool("dry-run", false, "Execute command in dry run mode")
 	cmd.Flags().BoolP("follow-link", "L", false, "Always follow symbol link in SRC_PATH")
+	cmd.Flags().BoolP("archive", "a", false, "Archive mode (copy all uid/gid information)")
 	cmd.Flags().Int("index", 0, "index of the container if service has multiple replicas")
 	return cmd
 }
@@ -67,6 +68,10 @@ func copyAction(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	archive, err := cmd.Flags().GetBool("archive")
+	if err != nil {
+		return err
+	}
 	index, err := cmd.Flags().GetInt("index")
 	if err != nil {
 		return err
@@ -98,6 +103,7 @@ func copyAction(cmd *cobra.Command, args []string) error {
 		Destination: destination,
 		Index:       index,
 		FollowLink:  followLink,
+		Archive:     archive,
 		DryRun:      dryRun,
 	}
 	return c.Copy(ctx, co)
@@ -90,3 +90,46 @@ services:
 
 	testCase.Run(t)
 }
+
+func TestComposeCopyWithIndex(t *testing.T) {
+	var dockerComposeYAML = fmt.Sprintf(`
+services:
+  svc0:
+    image: %s
+    command: "sleep infinity"
+    deploy:
+      replicas: 2
+`, testutil.CommonImage)
+
+	testCase := nerdtest.Setup()
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		compYamlPath := data.Temp().Save(dockerComposeYAML, "compose.yaml")
+		helpers.Ensure("compose", "-f", compYamlPath, "up", "-d")
+		data.Labels().Set("composeYaml", compYamlPath)
+		helpers.Ensure("compose", "-f", compYamlPath, "cp", "--index", "2", data.Temp().Save("second", "test-file"), "svc0:/test-file")
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("compose", "-f", data.Temp().Path("compose.yaml"), "down", "-v")
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "copied only to the second instance",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("compose", "-f", data.Labels().Get("composeYaml"), "exec", "-T", "--index", "1", "svc0", "ls", "/test-file")
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+		{
+			Description: "copy from the second instance",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("compose", "-f", data.Labels().Get("composeYaml"), "cp", "--index", "2", "svc0:/test-file", "-")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Contains("second")),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -36,6 +36,7 @@ func downCommand() *cobra.Command {
 	}
 	cmd.Flags().BoolP("volumes", "v", false, "Remove named volumes declared in the `volumes` section of the Compose file and anonymous volumes attached to containers.")
 	cmd.Flags().Bool("remove-orphans", false, "Remove containers for services not defined in the Compose file.")
+	cmd.Flags().UintP("timeout", "t", 0, "Seconds to wait for stop before killing the containers (default: stop_grace_period of the services)")
 	return cmd
 }
 
@@ -71,5 +72,12 @@ func downAction(cmd *cobra.Command, args []string) error {
 		RemoveVolumes: volumes,
 		RemoveOrphans: removeOrphans,
 	}
+	if cmd.Flags().Changed("timeout") {
+		timeValue, err := cmd.Flags().GetUint("timeout")
+		if err != nil {
+			return err
+		}
+		downOpts.Timeout = &timeValue
+	}
 	return c.Down(ctx, downOpts)
 }
@@ -21,6 +21,8 @@ import (
 	"testing"
 	"time"
 
+	"gotest.tools/v3/assert"
+
 	"github.com/containerd/nerdctl/v2/pkg/composer/serviceparser"
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
 )
@@ -98,3 +100,38 @@ services:
 	base.ComposeCmd("-p", projectName, "-f", compOrphan.YAMLFullPath(), "down", "--remove-orphans").AssertOK()
 	base.ComposeCmd("-p", projectName, "-f", compFull.YAMLFullPath(), "ps", "-a").AssertOutNotContains(orphanContainer)
 }
+
+func TestComposeDownStopGracePeriod(t *testing.T) {
+	base := testutil.NewBase(t)
+
+	// `sleep` ignores SIGTERM as PID 1, so each service is stopped after its stop_grace_period.
+	// The services are stopped in reverse dependency order, so the whole down takes 2 seconds.
+	var dockerComposeYAML = fmt.Sprintf(`
+services:
+  web:
+    image: %s
+    command: "sleep infinity"
+    stop_grace_period: 1s
+    depends_on:
+      - db
+  db:
+    image: %s
+    command: "sleep infinity"
+    stop_grace_period: 1s
+`, testutil.AlpineImage, testutil.AlpineImage)
+
+	comp := testutil.NewComposeDir(t, dockerComposeYAML)
+	defer comp.CleanUp()
+	projectName := comp.ProjectName()
+
+	base.ComposeCmd("-p", projectName, "-f", comp.YAMLFullPath(), "up", "-d").AssertOK()
+	defer base.ComposeCmd("-p", projectName, "-f", comp.YAMLFullPath(), "down", "-v").Run()
+
+	start := time.Now()
+	base.ComposeCmd("-p", projectName, "-f", comp.YAMLFullPath(), "--parallel", "1", "down").AssertOK()
+	elapsed := time.Since(start)
+	t.Logf("elapsed=%v", elapsed)
+	assert.Assert(t, elapsed >= 2*time.Second, "the services must be stopped one after another")
+	assert.Assert(t, elapsed < 10*time.Second, "stop_grace_period must be used instead of the default timeout")
+	base.ComposeCmd("-p", projectName, "-f", comp.YAMLFullPath(), "ps", "-a").AssertOutNotContains(projectName)
+}
@@ -19,6 +19,7 @@ package compose
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
 	"text/tabwriter"
 
@@ -30,6 +31,7 @@ import (
 	"github.com/containerd/containerd/v2/pkg/progress"
 
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/compose"
 	"github.com/containerd/nerdctl/v2/pkg/formatter"
@@ -68,6 +70,15 @@ func imagesAction(cmd *cobra.Command, args []string) error {
 	if format != "json" && format != "" {
 		return fmt.Errorf("unsupported format %s, supported formats are: [json]", format)
 	}
+	format, err = helpers.JSONFormat(cmd, globalOptions, format, quiet)
+	if err != nil {
+		return err
+	}
+	stdout, finish := helpers.OutputWriter(cmd, globalOptions, formatter.KindComposeImageList, formatter.ItemsFromJSONArray)
+	return finish(images(cmd, args, globalOptions, format, quiet, stdout))
+}
+
+func images(cmd *cobra.Command, args []string, globalOptions types.GlobalCommandOptions, format string, quiet bool, stdout io.Writer) error {
 
 	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), globalOptions.Namespace, globalOptions.Address)
 	if err != nil {
@@ -100,7 +111,7 @@ func imagesAction(cmd *cobra.Command, args []string) error {
 
 	sn := client.SnapshotService(globalOptions.Snapshotter)
 
-	return printComposeImages(ctx, cmd, containers, sn, format)
+	return printComposeImages(ctx, stdout, containers, sn, format)
 }
 
 func printComposeImageIDs(ctx context.Context, containers []containerd.Container) error {
@@ -124,7 +135,7 @@ func printComposeImageIDs(ctx context.Context, containers []containerd.Container
 	return nil
 }
 
-func printComposeImages(ctx context.Context, cmd *cobra.Command, containers []containerd.Container, sn snapshots.Snapshotter, format string) error {
+func printComposeImages(ctx context.Context, stdout io.Writer, containers []containerd.Container, sn snapshots.Snapshotter, format string) error {
 	type composeImagePrintable struct {
 		ContainerName string
 		Repository    string
@@ -189,11 +200,11 @@ func printComposeImages(ctx context.Context, cmd *cobra.Command, containers []co
 		if err != nil {
 			return err
 		}
-		_, err = fmt.Fprint(cmd.OutOrStdout(), outJSON)
+		_, err = fmt.Fprint(stdout, outJSON)
 		return err
 	}
 
-	w := tabwriter.NewWriter(cmd.OutOrStdout(), 4, 8, 4, ' ', 0)
+	w := tabwriter.NewWriter(stdout, 4, 8, 4, ' ', 0)
 	fmt.Fprintln(w, "Container\tRepository\tTag\tImage Id\tSize")
 	for _, p := range imagePrintables {
 		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
@@ -0,0 +1,122 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/composer"
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestComposeLock(t *testing.T) {
+	var dockerComposeYAML = fmt.Sprintf(`
+services:
+  svc0:
+    image: %s
+    command: sleep infinity
+`, testutil.CommonImage)
+
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		data.Labels().Set("composeYaml", data.Temp().Save(dockerComposeYAML, "compose.yaml"))
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("compose", "-f", data.Temp().Path("compose.yaml"), "down", "-v")
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "`compose up --locked` fails without a lockfile",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("compose", "-f", data.Labels().Get("composeYaml"), "up", "-d", "--locked")
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("compose lock")}, nil),
+		},
+		{
+			Description: "`compose lock` pins the image to a digest",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("compose", "-f", data.Labels().Get("composeYaml"), "lock")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout, info string, t *testing.T) {
+						b, err := os.ReadFile(data.Temp().Path(composer.LockFileName))
+						assert.NilError(t, err, info)
+						var lf composer.LockFile
+						assert.NilError(t, json.Unmarshal(b, &lf), info)
+						assert.Equal(t, lf.Services["svc0"].Image, testutil.CommonImage, info)
+						assert.Assert(t, strings.HasPrefix(lf.Services["svc0"].Digest, "sha256:"), info)
+						data.Labels().Set("digest", lf.Services["svc0"].Digest)
+					},
+				}
+			},
+		},
+		{
+			Description: "`compose up --locked` uses the pinned image",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("compose", "-f", data.Labels().Get("composeYaml"), "up", "-d", "--locked")
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("compose", "-f", data.Labels().Get("composeYaml"), "ps", "--format", "json", "svc0")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return test.Expects(expect.ExitCodeSuccess, nil, expect.Contains("@"+data.Labels().Get("digest")))(data, helpers)
+			},
+		},
+		{
+			Description: "`compose outdated` reports the image as up to date",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("compose", "-f", data.Labels().Get("composeYaml"), "outdated", "--exit-code")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Contains(composer.OutdatedStatusUpToDate)),
+		},
+		{
+			Description: "`compose up --locked` fails when the image was changed after locking",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				data.Temp().Save(strings.ReplaceAll(dockerComposeYAML, testutil.CommonImage, testutil.BusyboxImage), "compose.yaml")
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("compose", "-f", data.Labels().Get("composeYaml"), "up", "-d", "--locked")
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("after locking")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -17,6 +17,8 @@
 package compose
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
@@ -38,6 +40,7 @@ func logsCommand() *cobra.Command {
 	cmd.Flags().String("tail", "all", "Number of lines to show from the end of the logs")
 	cmd.Flags().Bool("no-color", false, "Produce monochrome output")
 	cmd.Flags().Bool("no-log-prefix", false, "Don't print prefix in logs")
+	cmd.Flags().Int("index", 0, "index of the container if the service has multiple instances")
 	return cmd
 }
 
@@ -66,6 +69,13 @@ func logsAction(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	index, err := cmd.Flags().GetInt("index")
+	if err != nil {
+		return err
+	}
+	if index < 0 {
+		return fmt.Errorf("index starts from 1 and should be equal or greater than 1, given index: %d", index)
+	}
 
 	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), globalOptions.Namespace, globalOptions.Address)
 	if err != nil {
@@ -87,6 +97,7 @@ func logsAction(cmd *cobra.Command, args []string) error {
 		Tail:        tail,
 		NoColor:     noColor,
 		NoLogPrefix: noLogPrefix,
+		Index:       index,
 	}
 	return c.Logs(ctx, lo, args)
 }
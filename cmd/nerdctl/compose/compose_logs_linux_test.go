@@ -0,0 +1,83 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestComposeLogsWithIndex(t *testing.T) {
+	dockerComposeYAML := fmt.Sprintf(`
+services:
+  svc0:
+    image: %s
+    command: "sh -c 'hostname; sleep infinity'"
+    deploy:
+      replicas: 2
+`, testutil.CommonImage)
+
+	testCase := nerdtest.Setup()
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		yamlPath := data.Temp().Save(dockerComposeYAML, "compose.yaml")
+		data.Labels().Set("YAMLPath", yamlPath)
+		helpers.Ensure("compose", "-f", yamlPath, "up", "-d")
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("compose", "-f", data.Temp().Path("compose.yaml"), "down", "-v")
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "second instance",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("compose", "-f", data.Labels().Get("YAMLPath"), "logs", "--no-log-prefix", "--index", "2", "svc0")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				hostname := helpers.Capture("compose", "-f", data.Labels().Get("YAMLPath"), "exec", "-T", "--index", "2", "svc0", "hostname")
+				return &test.Expected{
+					Output: expect.Equals(hostname),
+				}
+			},
+		},
+		{
+			Description: "out of range",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("compose", "-f", data.Labels().Get("YAMLPath"), "logs", "--index", "3", "svc0")
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("out of range")}, nil),
+		},
+		{
+			Description: "no service",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("compose", "-f", data.Labels().Get("YAMLPath"), "logs", "--index", "1")
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("requires exactly one service")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -0,0 +1,87 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestComposeOffline(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("pull", "--quiet", testutil.CommonImage)
+		yaml := fmt.Sprintf(`
+services:
+  local:
+    image: %s
+    command: sleep infinity
+  always:
+    image: %s
+    pull_policy: always
+  missing:
+    image: example.com/%s:nonexistent
+`, testutil.CommonImage, testutil.CommonImage, data.Identifier())
+		data.Temp().Save(yaml, "compose.yaml")
+		data.Labels().Set("yaml", data.Temp().Path("compose.yaml"))
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		if data.Labels().Get("yaml") != "" {
+			helpers.Anyhow("compose", "-f", data.Labels().Get("yaml"), "down")
+		}
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "up reports the services that require the network",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("--offline", "compose", "-f", data.Labels().Get("yaml"), "up", "-d")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					ExitCode: 1,
+					Errors: []error{
+						errors.New("always: pulls image " + testutil.CommonImage + ` (pull policy "always")`),
+						errors.New("missing: pulls image example.com/" + data.Identifier() + ":nonexistent, which is not available locally"),
+					},
+					Output: expect.DoesNotContain("local:"),
+				}
+			},
+		},
+		{
+			Description: "up of the services with local images succeeds",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("--offline", "compose", "-f", data.Labels().Get("yaml"), "up", "-d", "local")
+			},
+			Expected: test.Expects(0, nil, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -0,0 +1,123 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/compose"
+	"github.com/containerd/nerdctl/v2/pkg/composer"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+)
+
+func outdatedCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:           "outdated [flags] [SERVICE...]",
+		Short:         "Compare the lockfile with the current digests of the image references",
+		RunE:          outdatedAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().String("lockfile", "", "Path of the lockfile (default \""+composer.LockFileName+"\" in the project directory)")
+	cmd.Flags().String("format", "", "Format the output. Supported values: [json]")
+	cmd.Flags().Bool("exit-code", false, "Exit with status 1 if an image is not up to date")
+	return cmd
+}
+
+func outdatedAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	lockFile, err := cmd.Flags().GetString("lockfile")
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	if format != "json" && format != "" {
+		return fmt.Errorf("unsupported format %s, supported formats are: [json]", format)
+	}
+	exitCode, err := cmd.Flags().GetBool("exit-code")
+	if err != nil {
+		return err
+	}
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), globalOptions.Namespace, globalOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	options, err := getComposeOptions(cmd, globalOptions.DebugFull, globalOptions.Experimental)
+	if err != nil {
+		return err
+	}
+	c, err := compose.New(client, globalOptions, options, cmd.OutOrStdout(), cmd.ErrOrStderr())
+	if err != nil {
+		return err
+	}
+
+	oo := composer.OutdatedOptions{
+		LockFile: lockFile,
+	}
+	entries, err := c.Outdated(ctx, oo, args)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		outJSON, err := formatter.ToJSON(entries, "", "")
+		if err != nil {
+			return err
+		}
+		if _, err = fmt.Fprint(cmd.OutOrStdout(), outJSON); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 4, 8, 4, ' ', 0)
+		fmt.Fprintln(w, "SERVICE\tIMAGE\tLOCKED\tLATEST\tSTATUS")
+		for _, e := range entries {
+			locked := e.Locked
+			if locked == "" {
+				locked = "<none>"
+			}
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Service, e.Image, locked, e.Latest, e.Status); err != nil {
+				return err
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if exitCode {
+		for _, e := range entries {
+			if e.Status != composer.OutdatedStatusUpToDate {
+				return errors.New("some images are not up to date")
+			}
+		}
+	}
+	return nil
+}
@@ -18,7 +18,9 @@ package compose
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"text/tabwriter"
 
@@ -32,6 +34,7 @@ import (
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/compose"
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
@@ -78,6 +81,11 @@ func psAction(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	stdout, finish := helpers.OutputWriter(cmd, globalOptions, formatter.KindComposeContainerList, formatter.ItemsFromJSONArray)
+	return finish(ps(cmd, args, globalOptions, stdout))
+}
+
+func ps(cmd *cobra.Command, args []string, globalOptions types.GlobalCommandOptions, stdout io.Writer) error {
 	format, err := cmd.Flags().GetString("format")
 	if err != nil {
 		return err
@@ -97,6 +105,13 @@ func psAction(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	format, err = helpers.JSONFormat(cmd, globalOptions, format, quiet)
+	if err != nil {
+		return err
+	}
+	if displayServices && globalOptions.Output == formatter.OutputJSON {
+		return errors.New("--services cannot be used with --output json")
+	}
 	filter, err := cmd.Flags().GetString("filter")
 	if err != nil {
 		return err
@@ -170,7 +185,7 @@ func psAction(cmd *cobra.Command, args []string) error {
 
 	if quiet {
 		for _, c := range containers {
-			fmt.Fprintln(cmd.OutOrStdout(), c.ID())
+			fmt.Fprintln(stdout, c.ID())
 		}
 		return nil
 	}
@@ -201,7 +216,7 @@ func psAction(cmd *cobra.Command, args []string) error {
 
 	if displayServices {
 		for _, p := range containersPrintable {
-			fmt.Fprintln(cmd.OutOrStdout(), p.Service)
+			fmt.Fprintln(stdout, p.Service)
 		}
 		return nil
 	}
@@ -210,11 +225,11 @@ func psAction(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return err
 		}
-		_, err = fmt.Fprint(cmd.OutOrStdout(), outJSON)
+		_, err = fmt.Fprint(stdout, outJSON)
 		return err
 	}
 
-	w := tabwriter.NewWriter(cmd.OutOrStdout(), 4, 8, 4, ' ', 0)
+	w := tabwriter.NewWriter(stdout, 4, 8, 4, ' ', 0)
 	fmt.Fprintln(w, "NAME\tIMAGE\tCOMMAND\tSERVICE\tSTATUS\tPORTS")
 	for _, p := range containersPrintable {
 		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
@@ -51,6 +51,8 @@ func upCommand() *cobra.Command {
 	cmd.Flags().Bool("no-recreate", false, "Don't recreate containers if they exist, conflict with --force-recreate.")
 	cmd.Flags().StringArray("scale", []string{}, "Scale SERVICE to NUM instances. Overrides the `scale` setting in the Compose file if present.")
 	cmd.Flags().String("pull", "", "Pull image before running (\"always\"|\"missing\"|\"never\")")
+	cmd.Flags().Bool("locked", false, "Use the images pinned by `nerdctl compose lock`, and fail if the lockfile is missing or out of date")
+	cmd.Flags().String("lockfile", "", "Path of the lockfile (default \""+composer.LockFileName+"\" in the project directory)")
 	return cmd
 }
 
@@ -120,6 +122,14 @@ func upAction(cmd *cobra.Command, services []string) error {
 	if forceRecreate && noRecreate {
 		return errors.New("flag --force-recreate and --no-recreate cannot be specified together")
 	}
+	locked, err := cmd.Flags().GetBool("locked")
+	if err != nil {
+		return err
+	}
+	lockFile, err := cmd.Flags().GetString("lockfile")
+	if err != nil {
+		return err
+	}
 	scale := make(map[string]int)
 	for _, s := range scaleSlice {
 		parts := strings.Split(s, "=")
@@ -162,6 +172,8 @@ func upAction(cmd *cobra.Command, services []string) error {
 		Pull:                 pull,
 		ForceRecreate:        forceRecreate,
 		NoRecreate:           noRecreate,
+		Locked:               locked,
+		LockFile:             lockFile,
 	}
 	return c.Up(ctx, uo, services)
 }
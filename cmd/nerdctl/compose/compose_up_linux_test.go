@@ -646,3 +646,37 @@ services:
 	base.Cmd("images").AssertOutNotContains(testutil.CommonImage)
 	base.ComposeCmd("-f", comp.YAMLFullPath(), "up").AssertExitCode(1)
 }
+
+func TestComposeUpPortConflicts(t *testing.T) {
+	base := testutil.NewBase(t)
+
+	containerName := testutil.Identifier(t)
+	base.Cmd("run", "-d", "--name", containerName, "-p", "127.0.0.1:18082:80", testutil.CommonImage, "sleep", "infinity").AssertOK()
+	defer base.Cmd("rm", "-f", containerName).Run()
+
+	var dockerComposeYAML = fmt.Sprintf(`
+services:
+  foo:
+    image: %[1]s
+    command: "sleep infinity"
+    ports:
+      - "18081:80"
+  bar:
+    image: %[1]s
+    command: "sleep infinity"
+    ports:
+      - "127.0.0.1:18081:8080"
+      - "18082:80"
+`, testutil.CommonImage)
+
+	comp := testutil.NewComposeDir(t, dockerComposeYAML)
+	defer comp.CleanUp()
+	defer base.ComposeCmd("-f", comp.YAMLFullPath(), "down", "-v").Run()
+
+	cmd := base.ComposeCmd("-f", comp.YAMLFullPath(), "up", "-d")
+	cmd.AssertFail()
+	cmd.AssertCombinedOutContains("127.0.0.1:18081/tcp")
+	cmd.AssertCombinedOutContains(fmt.Sprintf("0.0.0.0:18082/tcp: service \"bar\" is already allocated by container %q", containerName))
+	// no container is created when the ports conflict
+	base.ComposeCmd("-f", comp.YAMLFullPath(), "ps", "-a").AssertOutNotContains("foo")
+}
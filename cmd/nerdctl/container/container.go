@@ -36,6 +36,7 @@ func Command() *cobra.Command {
 		RunCommand(),
 		UpdateCommand(),
 		ExecCommand(),
+		DebugCommand(),
 		listCommand(),
 		inspectCommand(),
 		LogsCommand(),
@@ -50,10 +51,16 @@ func Command() *cobra.Command {
 		WaitCommand(),
 		UnpauseCommand(),
 		CommitCommand(),
+		ExportCommand(),
 		RenameCommand(),
+		PublishCommand(),
+		UnpublishCommand(),
 		pruneCommand(),
 		StatsCommand(),
 		AttachCommand(),
+		autoUpdateCommand(),
+		restartPolicyCommand(),
+		adoptCommand(),
 	)
 	AddCpCommand(cmd)
 	return cmd
@@ -0,0 +1,93 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/logging"
+)
+
+func adoptCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "adopt [flags] CONTAINER",
+		Short: "Make a container created by another tool manageable by nerdctl",
+		Long: `Make a container created by another tool (e.g., ctr or CRI) manageable by nerdctl commands,
+by synthesizing the nerdctl labels and the state directory of the container.
+
+The network of the container is recorded as "host" or "none", as the network namespace is still owned by
+the tool that created the container. The addresses are discovered from the network namespace of the running task.
+
+The log driver takes effect on the next "nerdctl start". No logs are collected when --log-driver is not specified.`,
+		Args:          helpers.IsExactArgs(1),
+		RunE:          adoptAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completion.ContainerNames(cmd, nil)
+		},
+	}
+	cmd.Flags().String("name", "", "Assign a name to the container, suggested from the image by default")
+	cmd.Flags().String("log-driver", "", "Logging driver for the container (e.g., json-file). It also supports logURI (eg: --log-driver binary://<path>)")
+	cmd.RegisterFlagCompletionFunc("log-driver", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return logging.Drivers(), cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().StringArray("log-opt", nil, "Log driver options")
+	return cmd
+}
+
+func adoptAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	name, err := cmd.Flags().GetString("name")
+	if err != nil {
+		return err
+	}
+	logDriver, err := cmd.Flags().GetString("log-driver")
+	if err != nil {
+		return err
+	}
+	logOpt, err := cmd.Flags().GetStringArray("log-opt")
+	if err != nil {
+		return err
+	}
+	options := types.ContainerAdoptOptions{
+		Stdout:    cmd.OutOrStdout(),
+		GOptions:  globalOptions,
+		Name:      name,
+		LogDriver: logDriver,
+		LogOpt:    logOpt,
+	}
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return container.Adopt(ctx, client, args[0], options)
+}
@@ -0,0 +1,88 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestContainerAdopt(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.All(
+		require.Not(nerdtest.Docker),
+		require.Not(nerdtest.Rootless),
+		require.Binary("ctr"),
+	)
+	testCase.NoParallel = true
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("pull", "--quiet", testutil.CommonImage)
+		helpers.Custom("ctr", "--namespace", testutil.Namespace, "run", "-d",
+			testutil.CommonImage, data.Identifier(), "sleep", nerdtest.Infinity).Run(&test.Expected{})
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier("adopted"))
+		helpers.Custom("ctr", "--namespace", testutil.Namespace, "task", "kill", "-s", "SIGKILL", data.Identifier()).Run(nil)
+		helpers.Custom("ctr", "--namespace", testutil.Namespace, "container", "rm", data.Identifier()).Run(nil)
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "adopt",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				helpers.Ensure("container", "adopt", "--name", data.Identifier("adopted"), data.Identifier())
+				return helpers.Command("inspect", "--format", "{{.Name}} {{.State.Status}} {{index .Config.Labels \"nerdctl/adopted\"}}", data.Identifier("adopted"))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.Equals(data.Identifier("adopted") + " running true\n"),
+				}
+			},
+		},
+		{
+			Description: "adopting twice fails",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("container", "adopt", data.Identifier())
+			},
+			Expected: test.Expects(1, []error{errors.New("already managed by nerdctl")}, nil),
+		},
+		{
+			Description: "the adopted container can be stopped and removed",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				helpers.Ensure("stop", "--time", "1", data.Identifier("adopted"))
+				helpers.Ensure("rm", data.Identifier("adopted"))
+				return helpers.Command("ps", "-a", "--filter", "name="+data.Identifier("adopted"), "--format", "{{.Names}}")
+			},
+			Expected: test.Expects(0, nil, expect.Equals("")),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -59,6 +59,7 @@ Caveats:
 	}
 	cmd.Flags().String("detach-keys", consoleutil.DefaultDetachKeys, "Override the default detach keys")
 	cmd.Flags().Bool("no-stdin", false, "Do not attach STDIN")
+	cmd.Flags().Bool("sig-proxy", true, "Proxy received signals to the process, unless it has a TTY")
 	return cmd
 }
 
@@ -75,6 +76,10 @@ func attachOptions(cmd *cobra.Command) (types.ContainerAttachOptions, error) {
 	if err != nil {
 		return types.ContainerAttachOptions{}, err
 	}
+	sigProxy, err := cmd.Flags().GetBool("sig-proxy")
+	if err != nil {
+		return types.ContainerAttachOptions{}, err
+	}
 
 	var stdin io.Reader
 	if !noStdin {
@@ -86,6 +91,7 @@ func attachOptions(cmd *cobra.Command) (types.ContainerAttachOptions, error) {
 		Stdout:     cmd.OutOrStdout(),
 		Stderr:     cmd.ErrOrStderr(),
 		DetachKeys: detachKeys,
+		SigProxy:   sigProxy,
 	}, nil
 }
 
@@ -0,0 +1,99 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+)
+
+func autoUpdateCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "auto-update [flags]",
+		Args:  cobra.NoArgs,
+		Short: "Re-create the containers whose image was updated, according to their io.containers.autoupdate label",
+		Long: `Re-create the running containers labeled with io.containers.autoupdate, when their image was updated.
+
+The "registry" policy checks the image tag in the registry, and pulls the new image.
+The "local" policy checks the image tag in the local store.
+
+The new container is created with the command line of the previous one.
+When it does not keep running during --health-window, it is replaced by the previous container (unless --rollback=false).`,
+		RunE:          autoUpdateAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().Bool("dry-run", false, "Only check for the updates, without re-creating the containers")
+	cmd.Flags().Bool("rollback", true, "Restore the previous container when the new one fails")
+	cmd.Flags().Duration("health-window", 10*time.Second, "Time the new container must keep running to be considered healthy")
+	cmd.Flags().String("format", "", "Format the output using the given Go template, e.g, '{{json .}}'")
+	return cmd
+}
+
+func autoUpdateOptions(cmd *cobra.Command) (types.ContainerAutoUpdateOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.ContainerAutoUpdateOptions{}, err
+	}
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return types.ContainerAutoUpdateOptions{}, err
+	}
+	rollback, err := cmd.Flags().GetBool("rollback")
+	if err != nil {
+		return types.ContainerAutoUpdateOptions{}, err
+	}
+	healthWindow, err := cmd.Flags().GetDuration("health-window")
+	if err != nil {
+		return types.ContainerAutoUpdateOptions{}, err
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return types.ContainerAutoUpdateOptions{}, err
+	}
+	nerdctlCmd, nerdctlArgs := helpers.GlobalFlags(cmd)
+	return types.ContainerAutoUpdateOptions{
+		Stdout:       cmd.OutOrStdout(),
+		Stderr:       cmd.ErrOrStderr(),
+		GOptions:     globalOptions,
+		NerdctlCmd:   nerdctlCmd,
+		NerdctlArgs:  nerdctlArgs,
+		DryRun:       dryRun,
+		Rollback:     rollback,
+		HealthWindow: healthWindow,
+		Format:       format,
+	}, nil
+}
+
+func autoUpdateAction(cmd *cobra.Command, args []string) error {
+	options, err := autoUpdateOptions(cmd)
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	return container.AutoUpdate(ctx, client, options)
+}
@@ -0,0 +1,85 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestContainerAutoUpdateLocal(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	// auto-update looks at all the containers of the namespace
+	testCase.Require = require.All(require.Not(nerdtest.Docker), nerdtest.Private)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("pull", "--quiet", testutil.AlpineImage)
+		helpers.Ensure("pull", "--quiet", testutil.BusyboxImage)
+		helpers.Ensure("tag", testutil.AlpineImage, data.Identifier("image"))
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), "--label", "io.containers.autoupdate=local",
+			data.Identifier("image"), "sleep", nerdtest.Infinity)
+		helpers.Ensure("tag", testutil.BusyboxImage, data.Identifier("image"))
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+		helpers.Anyhow("rm", "-f", data.Identifier()+"-autoupdate-backup")
+		helpers.Anyhow("rmi", "-f", data.Identifier("image"))
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		out := helpers.Capture("container", "auto-update", "--dry-run")
+		assert.Assert(t, strings.Contains(out, "pending"), out)
+		return helpers.Command("container", "auto-update", "--health-window", "2s", "--format", "json")
+	}
+
+	testCase.Expected = func(data test.Data, helpers test.Helpers) *test.Expected {
+		return &test.Expected{
+			Output: func(stdout, info string, t *testing.T) {
+				var entry struct {
+					Container string
+					Policy    string
+					Updated   string
+				}
+				assert.NilError(t, json.Unmarshal([]byte(strings.TrimSpace(stdout)), &entry), info)
+				assert.Equal(t, entry.Container, data.Identifier(), info)
+				assert.Equal(t, entry.Policy, "local", info)
+				assert.Equal(t, entry.Updated, "true", info)
+
+				// The new container runs the new image, with the same command
+				helpers.Ensure("exec", data.Identifier(), "true")
+				helpers.Fail("exec", data.Identifier(), "test", "-e", "/etc/alpine-release")
+				helpers.Fail("inspect", data.Identifier()+"-autoupdate-backup")
+				// Nothing left to update
+				out := helpers.Capture("container", "auto-update", "--format", "{{.Updated}}")
+				assert.Equal(t, strings.TrimSpace(out), "false", info)
+			},
+		}
+	}
+
+	testCase.Run(t)
+}
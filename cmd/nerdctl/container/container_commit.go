@@ -17,6 +17,8 @@
 package container
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
@@ -24,6 +26,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/leaseutil"
 )
 
 func CommitCommand() *cobra.Command {
@@ -38,8 +41,9 @@ func CommitCommand() *cobra.Command {
 	}
 	cmd.Flags().StringP("author", "a", "", `Author (e.g., "nerdctl contributor <nerdctl-dev@example.com>")`)
 	cmd.Flags().StringP("message", "m", "", "Commit message")
-	cmd.Flags().StringArrayP("change", "c", nil, "Apply Dockerfile instruction to the created image (supported directives: [CMD, ENTRYPOINT])")
+	cmd.Flags().StringArrayP("change", "c", nil, "Apply Dockerfile instruction to the created image (supported directives: [CMD, ENTRYPOINT, ENV, EXPOSE, LABEL, STOPSIGNAL, USER, VOLUME, WORKDIR])")
 	cmd.Flags().BoolP("pause", "p", true, "Pause container during commit")
+	cmd.Flags().Duration("lease-duration", leaseutil.DefaultDuration, "Expiration of the lease holding the intermediate data, renewed during the commit")
 	return cmd
 }
 
@@ -65,6 +69,13 @@ func commitOptions(cmd *cobra.Command) (types.ContainerCommitOptions, error) {
 	if err != nil {
 		return types.ContainerCommitOptions{}, err
 	}
+	leaseDuration, err := cmd.Flags().GetDuration("lease-duration")
+	if err != nil {
+		return types.ContainerCommitOptions{}, err
+	}
+	if leaseDuration <= 0 {
+		return types.ContainerCommitOptions{}, fmt.Errorf("invalid lease-duration: %s", leaseDuration)
+	}
 
 	return types.ContainerCommitOptions{
 		Stdout:   cmd.OutOrStdout(),
@@ -73,6 +84,8 @@ func commitOptions(cmd *cobra.Command) (types.ContainerCommitOptions, error) {
 		Message:  message,
 		Pause:    pause,
 		Change:   change,
+
+		LeaseDuration: leaseDuration,
 	}, nil
 
 }
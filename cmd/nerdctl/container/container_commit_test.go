@@ -82,6 +82,37 @@ func TestCommit(t *testing.T) {
 			},
 			Expected: test.Expects(0, nil, expect.Equals("hello-test-commit\n")),
 		},
+		{
+			Description: "with changes",
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				identifier := data.Identifier()
+				helpers.Anyhow("rm", "-f", identifier)
+				helpers.Anyhow("rmi", "-f", identifier)
+			},
+			Setup: func(data test.Data, helpers test.Helpers) {
+				identifier := data.Identifier()
+				helpers.Ensure("create", "--name", identifier, testutil.CommonImage, "true")
+				helpers.Ensure(
+					"commit",
+					"-c", `ENV FOO="hello world"`,
+					"-c", "EXPOSE 8080/tcp",
+					"-c", "LABEL org.example.foo=bar",
+					"-c", "WORKDIR /app",
+					"-c", "USER nobody",
+					identifier, identifier)
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("image", "inspect", "--format",
+					"{{json .Config.Env}} {{json .Config.ExposedPorts}} {{json .Config.Labels}} {{.Config.WorkingDir}} {{.Config.User}}",
+					data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Contains(
+				`"FOO=hello world"`,
+				`"8080/tcp"`,
+				`"org.example.foo":"bar"`,
+				" /app nobody",
+			)),
+		},
 	}
 
 	testCase.Run(t)
@@ -0,0 +1,164 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestCopyBetweenContainers(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	// Docker does not support copying between containers
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier("src"), testutil.CommonImage, "sleep", nerdtest.Infinity)
+		helpers.Ensure("run", "-d", "--name", data.Identifier("dest"), testutil.CommonImage, "sleep", nerdtest.Infinity)
+		helpers.Ensure("exec", data.Identifier("src"), "sh", "-c", "mkdir -p /data && echo -n "+data.Identifier()+" >/data/file")
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier("src"))
+		helpers.Anyhow("rm", "-f", data.Identifier("dest"))
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		helpers.Ensure("cp", data.Identifier("src")+":/data", data.Identifier("dest")+":/copied")
+		return helpers.Command("exec", data.Identifier("dest"), "cat", "/copied/file")
+	}
+
+	testCase.Expected = func(data test.Data, helpers test.Helpers) *test.Expected {
+		return &test.Expected{
+			Output: expect.Equals(data.Identifier()),
+		}
+	}
+
+	testCase.Run(t)
+}
+
+func TestCopyArchiveStreaming(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.CommonImage, "sleep", nerdtest.Infinity)
+		helpers.Ensure("exec", data.Identifier(), "sh", "-c", "mkdir -p /data && echo -n "+data.Identifier()+" >/data/file")
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "write a tar archive to stdout",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("cp", data.Identifier()+":/data", "-")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout string, info string, t *testing.T) {
+						tr := tar.NewReader(bytes.NewReader([]byte(stdout)))
+						found := false
+						for {
+							hdr, err := tr.Next()
+							if err != nil {
+								break
+							}
+							if hdr.Name == "data/file" {
+								found = true
+							}
+						}
+						assert.Assert(t, found, "data/file not found in the archive"+info)
+					},
+				}
+			},
+		},
+		{
+			Description: "read a tar archive from stdin",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				var buf bytes.Buffer
+				tw := tar.NewWriter(&buf)
+				content := []byte(data.Identifier("stdin"))
+				assert.NilError(helpers.T(), tw.WriteHeader(&tar.Header{Name: "fromstdin", Mode: 0o644, Size: int64(len(content))}))
+				_, err := tw.Write(content)
+				assert.NilError(helpers.T(), err)
+				assert.NilError(helpers.T(), tw.Close())
+
+				cmd := helpers.Command("cp", "-", data.Identifier()+":/data")
+				cmd.Feed(&buf)
+				return cmd
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout string, info string, t *testing.T) {
+						helpers.Command("exec", data.Identifier(), "cat", "/data/fromstdin").
+							Run(&test.Expected{Output: expect.Equals(data.Identifier("stdin"))})
+					},
+				}
+			},
+		},
+		{
+			Description: "reading a tar archive into a file should fail",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				cmd := helpers.Command("cp", "-", data.Identifier()+":/data/file")
+				cmd.Feed(bytes.NewReader(nil))
+				return cmd
+			},
+			Expected: test.Expects(1, nil, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestCopyChown(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	// Docker does not support --chown
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.CommonImage, "sleep", nerdtest.Infinity)
+		data.Temp().Save(data.Identifier(), "file")
+		data.Labels().Set("src", data.Temp().Path("file"))
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		helpers.Ensure("cp", "--chown", "1234:5678", data.Labels().Get("src"), data.Identifier()+":/tmp/file")
+		return helpers.Command("exec", data.Identifier(), "stat", "-c", "%u:%g", "/tmp/file")
+	}
+
+	testCase.Expected = test.Expects(0, nil, expect.Equals("1234:5678\n"))
+
+	testCase.Run(t)
+}
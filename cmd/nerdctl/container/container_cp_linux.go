@@ -32,7 +32,7 @@ import (
 )
 
 func copyCommand() *cobra.Command {
-	shortHelp := "Copy files/folders between a running container and the local filesystem."
+	shortHelp := "Copy files/folders between a container and the local filesystem, or between two containers."
 
 	longHelp := shortHelp + `
 This command requires 'tar' to be installed on the host (not in the container).
@@ -44,7 +44,8 @@ Using 'nerdctl cp' with untrusted or malicious containers is unsupported and may
 `
 
 	usage := `cp [flags] CONTAINER:SRC_PATH DEST_PATH|-
-  nerdctl cp [flags] SRC_PATH|- CONTAINER:DEST_PATH`
+  nerdctl cp [flags] SRC_PATH|- CONTAINER:DEST_PATH
+  nerdctl cp [flags] CONTAINER:SRC_PATH CONTAINER:DEST_PATH`
 	var cmd = &cobra.Command{
 		Use:               usage,
 		Args:              helpers.IsExactArgs(2),
@@ -57,6 +58,8 @@ Using 'nerdctl cp' with untrusted or malicious containers is unsupported and may
 	}
 
 	cmd.Flags().BoolP("follow-link", "L", false, "Always follow symbolic link in SRC_PATH.")
+	cmd.Flags().BoolP("archive", "a", false, "Archive mode (copy all uid/gid information)")
+	cmd.Flags().String("chown", "", "Set the ownership of the copied files (UID[:GID], numeric)")
 
 	return cmd
 }
@@ -90,6 +93,14 @@ func copyOptions(cmd *cobra.Command, args []string) (types.ContainerCpOptions, e
 	if err != nil {
 		return types.ContainerCpOptions{}, err
 	}
+	archive, err := cmd.Flags().GetBool("archive")
+	if err != nil {
+		return types.ContainerCpOptions{}, err
+	}
+	chown, err := cmd.Flags().GetString("chown")
+	if err != nil {
+		return types.ContainerCpOptions{}, err
+	}
 
 	srcSpec, err := parseCpFileSpec(args[0])
 	if err != nil {
@@ -101,33 +112,37 @@ func copyOptions(cmd *cobra.Command, args []string) (types.ContainerCpOptions, e
 		return types.ContainerCpOptions{}, err
 	}
 
-	if (srcSpec.Container != nil && destSpec.Container != nil) || (len(srcSpec.Path) == 0 && len(destSpec.Path) == 0) {
+	if len(srcSpec.Path) == 0 && len(destSpec.Path) == 0 {
 		return types.ContainerCpOptions{}, fmt.Errorf("one of src or dest must be a local file specification")
 	}
 	if srcSpec.Container == nil && destSpec.Container == nil {
 		return types.ContainerCpOptions{}, fmt.Errorf("one of src or dest must be a container file specification")
 	}
-	if srcSpec.Path == "-" {
-		return types.ContainerCpOptions{}, fmt.Errorf("support for reading a tar archive from stdin is not implemented yet")
+	if srcSpec.Path == "-" && (srcSpec.Container != nil || destSpec.Container == nil) {
+		return types.ContainerCpOptions{}, fmt.Errorf("a tar archive can only be read from stdin to be copied into a container")
 	}
-	if destSpec.Path == "-" {
-		return types.ContainerCpOptions{}, fmt.Errorf("support for writing a tar archive to stdout is not implemented yet")
+	if destSpec.Path == "-" && (destSpec.Container != nil || srcSpec.Container == nil) {
+		return types.ContainerCpOptions{}, fmt.Errorf("a tar archive can only be written to stdout when copying from a container")
 	}
 
-	container2host := srcSpec.Container != nil
-	var containerReq string
-	if container2host {
-		containerReq = *srcSpec.Container
-	} else {
-		containerReq = *destSpec.Container
+	var srcContainerReq, destContainerReq string
+	if srcSpec.Container != nil {
+		srcContainerReq = *srcSpec.Container
+	}
+	if destSpec.Container != nil {
+		destContainerReq = *destSpec.Container
 	}
 	return types.ContainerCpOptions{
-		GOptions:       globalOptions,
-		Container2Host: container2host,
-		ContainerReq:   containerReq,
-		DestPath:       destSpec.Path,
-		SrcPath:        srcSpec.Path,
-		FollowSymLink:  flagL,
+		Stdin:            cmd.InOrStdin(),
+		Stdout:           cmd.OutOrStdout(),
+		GOptions:         globalOptions,
+		SrcContainerReq:  srcContainerReq,
+		DestContainerReq: destContainerReq,
+		DestPath:         destSpec.Path,
+		SrcPath:          srcSpec.Path,
+		FollowSymLink:    flagL,
+		Archive:          archive,
+		Chown:            chown,
 	}, nil
 }
 
@@ -28,6 +28,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 )
 
 func CreateCommand() *cobra.Command {
@@ -39,11 +40,11 @@ func CreateCommand() *cobra.Command {
 		longHelp += "WARNING: `nerdctl create` is experimental on Windows and currently broken (https://github.com/containerd/nerdctl/issues/28)"
 	case "freebsd":
 		longHelp += "\n"
-		longHelp += "WARNING: `nerdctl create` is experimental on FreeBSD and currently requires `--net=none` (https://github.com/containerd/nerdctl/blob/main/docs/freebsd.md)"
+		longHelp += "WARNING: `nerdctl create` is experimental on FreeBSD, and the bridge networks require a runtime supporting vnet jails (https://github.com/containerd/nerdctl/blob/main/docs/freebsd.md)"
 	}
 	var cmd = &cobra.Command{
 		Use:               "create [flags] IMAGE [COMMAND] [ARG...]",
-		Args:              cobra.MinimumNArgs(1),
+		Args:              imageArgsOrFromConfig,
 		Short:             shortHelp,
 		Long:              longHelp,
 		RunE:              createAction,
@@ -69,7 +70,17 @@ func createOptions(cmd *cobra.Command) (types.ContainerCreateOptions, error) {
 		return opt, err
 	}
 
+	if err = applyPresets(cmd); err != nil {
+		return opt, err
+	}
+
 	opt.NerdctlCmd, opt.NerdctlArgs = helpers.GlobalFlags(cmd)
+	// "detach" and "attach" are only available in `nerdctl run`
+	opt.CreateFlags = helpers.ChangedLocalFlags(cmd, "detach", "attach", "preset", "dry-run", "config-out", "from-config")
+	opt.DryRun, err = cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return opt, err
+	}
 
 	// #region for basic flags
 	// The command `container start` doesn't support the flag `--interactive`. Set the default value of `opt.Interactive` false.
@@ -105,6 +116,26 @@ func createOptions(cmd *cobra.Command) (types.ContainerCreateOptions, error) {
 	if err != nil {
 		return opt, err
 	}
+	opt.OnStart, err = cmd.Flags().GetStringArray("on-start")
+	if err != nil {
+		return opt, err
+	}
+	opt.OnPostStart, err = cmd.Flags().GetStringArray("on-post-start")
+	if err != nil {
+		return opt, err
+	}
+	opt.OnStop, err = cmd.Flags().GetStringArray("on-stop")
+	if err != nil {
+		return opt, err
+	}
+	opt.WatchConfig, err = cmd.Flags().GetStringArray("watch-config")
+	if err != nil {
+		return opt, err
+	}
+	opt.WatchConfigSignal, err = cmd.Flags().GetString("watch-config-signal")
+	if err != nil {
+		return opt, err
+	}
 	// #endregion
 
 	// #region for platform flags
@@ -119,12 +150,23 @@ func createOptions(cmd *cobra.Command) (types.ContainerCreateOptions, error) {
 	if err != nil {
 		return opt, err
 	}
-	if opt.InitProcessFlag || cmd.Flags().Changed("init-binary") {
-		var initBinary string
-		initBinary, err = cmd.Flags().GetString("init-binary")
+	initBinary, err := cmd.Flags().GetString("init-binary")
+	if err != nil {
+		return opt, err
+	}
+	if !cmd.Flags().Changed("init") || !cmd.Flags().Changed("init-binary") {
+		cfg, err := helpers.LoadNerdctlTOML(helpers.NerdctlTOMLPath())
 		if err != nil {
 			return opt, err
 		}
+		if !cmd.Flags().Changed("init") {
+			opt.InitProcessFlag = cfg.Init
+		}
+		if !cmd.Flags().Changed("init-binary") && cfg.InitBinary != "" {
+			initBinary = cfg.InitBinary
+		}
+	}
+	if opt.InitProcessFlag || cmd.Flags().Changed("init-binary") {
 		opt.InitBinary = &initBinary
 	}
 	// #endregion
@@ -161,6 +203,10 @@ func createOptions(cmd *cobra.Command) (types.ContainerCreateOptions, error) {
 	if err != nil {
 		return opt, err
 	}
+	opt.NumaNode, err = cmd.Flags().GetString("numa-node")
+	if err != nil {
+		return opt, err
+	}
 	opt.CPURealtimePeriod, err = cmd.Flags().GetUint64("cpu-rt-period")
 	if err != nil {
 		return opt, err
@@ -169,6 +215,22 @@ func createOptions(cmd *cobra.Command) (types.ContainerCreateOptions, error) {
 	if err != nil {
 		return opt, err
 	}
+	opt.CPUCount, err = cmd.Flags().GetUint64("cpu-count")
+	if err != nil {
+		return opt, err
+	}
+	opt.CPUPercent, err = cmd.Flags().GetUint64("cpu-percent")
+	if err != nil {
+		return opt, err
+	}
+	opt.IOMaximumBandwidth, err = cmd.Flags().GetString("io-maxbandwidth")
+	if err != nil {
+		return opt, err
+	}
+	opt.IOMaximumIOps, err = cmd.Flags().GetUint64("io-maxiops")
+	if err != nil {
+		return opt, err
+	}
 	opt.Memory, err = cmd.Flags().GetString("memory")
 	if err != nil {
 		return opt, err
@@ -337,6 +399,10 @@ func createOptions(cmd *cobra.Command) (types.ContainerCreateOptions, error) {
 	if err != nil {
 		return opt, err
 	}
+	opt.Secret, err = cmd.Flags().GetStringArray("secret")
+	if err != nil {
+		return opt, err
+	}
 	// #endregion
 
 	// #region for rootfs flags
@@ -344,10 +410,22 @@ func createOptions(cmd *cobra.Command) (types.ContainerCreateOptions, error) {
 	if err != nil {
 		return opt, err
 	}
+	opt.ReadOnlyTmpfsSize, err = cmd.Flags().GetString("read-only-tmpfs-size")
+	if err != nil {
+		return opt, err
+	}
+	opt.Immutable, err = cmd.Flags().GetBool("immutable")
+	if err != nil {
+		return opt, err
+	}
 	opt.Rootfs, err = cmd.Flags().GetBool("rootfs")
 	if err != nil {
 		return opt, err
 	}
+	opt.StorageOpt, err = cmd.Flags().GetStringArray("storage-opt")
+	if err != nil {
+		return opt, err
+	}
 	// #endregion
 
 	// #region for env flags
@@ -368,6 +446,25 @@ func createOptions(cmd *cobra.Command) (types.ContainerCreateOptions, error) {
 	if err != nil {
 		return opt, err
 	}
+	opt.EnvFileExpand, err = cmd.Flags().GetBool("env-file-expand")
+	if err != nil {
+		return opt, err
+	}
+	opt.EnvFileStrict, err = cmd.Flags().GetBool("env-file-strict")
+	if err != nil {
+		return opt, err
+	}
+	opt.TZ, err = cmd.Flags().GetString("tz")
+	if err != nil {
+		return opt, err
+	}
+	if !cmd.Flags().Changed("tz") {
+		cfg, err := helpers.LoadNerdctlTOML(helpers.NerdctlTOMLPath())
+		if err != nil {
+			return opt, err
+		}
+		opt.TZ = cfg.TZ
+	}
 	// #endregion
 
 	// #region for metadata flags
@@ -384,10 +481,23 @@ func createOptions(cmd *cobra.Command) (types.ContainerCreateOptions, error) {
 	if err != nil {
 		return opt, err
 	}
+	cfg, err := helpers.LoadNerdctlTOML(helpers.NerdctlTOMLPath())
+	if err != nil {
+		return opt, err
+	}
+	opt.DefaultLabels, err = cfg.DefaultLabels()
+	if err != nil {
+		return opt, err
+	}
+	opt.Admission = cfg.Admission
 	opt.Annotations, err = cmd.Flags().GetStringArray("annotation")
 	if err != nil {
 		return opt, err
 	}
+	opt.NetAccel, err = cmd.Flags().GetBool("net-accel")
+	if err != nil {
+		return opt, err
+	}
 	opt.CidFile, err = cmd.Flags().GetString("cidfile")
 	if err != nil {
 		return opt, err
@@ -399,6 +509,10 @@ func createOptions(cmd *cobra.Command) (types.ContainerCreateOptions, error) {
 			return opt, err
 		}
 	}
+	opt.ConfigOut, err = cmd.Flags().GetString("config-out")
+	if err != nil {
+		return opt, err
+	}
 	// #endregion
 
 	// #region for logging flags
@@ -443,6 +557,10 @@ func createOptions(cmd *cobra.Command) (types.ContainerCreateOptions, error) {
 	if err != nil {
 		return opt, err
 	}
+	opt.IPFSGateway, err = cmd.Flags().GetString("ipfs-gateway")
+	if err != nil {
+		return opt, err
+	}
 	// #endregion
 
 	// #region for image pull and verify options
@@ -458,6 +576,7 @@ func createOptions(cmd *cobra.Command) (types.ContainerCreateOptions, error) {
 		GOptions:      opt.GOptions,
 		VerifyOptions: imageVerifyOpt,
 		IPFSAddress:   opt.IPFSAddress,
+		IPFSGateway:   opt.IPFSGateway,
 		Stdout:        opt.Stdout,
 		Stderr:        opt.Stderr,
 		Quiet:         quiet,
@@ -492,6 +611,10 @@ func createOptions(cmd *cobra.Command) (types.ContainerCreateOptions, error) {
 }
 
 func createAction(cmd *cobra.Command, args []string) error {
+	args, err := applyRunConfig(cmd, args)
+	if err != nil {
+		return err
+	}
 	createOpt, err := createOptions(cmd)
 	if err != nil {
 		return err
@@ -523,6 +646,9 @@ func createAction(cmd *cobra.Command, args []string) error {
 		}
 		return err
 	}
+	if createOpt.DryRun {
+		return nil
+	}
 	// defer setting `nerdctl/error` label in case of error
 	defer func() {
 		if err != nil {
@@ -530,6 +656,7 @@ func createAction(cmd *cobra.Command, args []string) error {
 		}
 	}()
 
-	fmt.Fprintln(createOpt.Stdout, c.ID())
-	return nil
+	stdout, finish := helpers.OutputWriter(cmd, createOpt.GOptions, formatter.KindContainerCreate, formatter.ItemsFromTextLines)
+	fmt.Fprintln(stdout, c.ID())
+	return finish(nil)
 }
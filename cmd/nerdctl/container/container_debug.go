@@ -0,0 +1,127 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+)
+
+func DebugCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "debug [flags] CONTAINER [COMMAND] [ARG...]",
+		Args:  cobra.MinimumNArgs(1),
+		Short: "Run an ephemeral debug container attached to a running container",
+		Long: `Run an ephemeral debug container that shares the PID, network, and IPC namespaces of a running container,
+so that a container without a shell (e.g., a distroless container) can be troubleshot with the tools of another image.
+
+The root filesystem of the target container is mounted at --target-mount (default: /target),
+and is also visible at /proc/1/root, as the PID namespace is shared.
+The debug container is removed when it exits.
+`,
+		Example: `  nerdctl debug -it --image busybox web
+  nerdctl debug -it --image nicolaka/netshoot web tcpdump -i eth0`,
+		RunE:              debugAction,
+		ValidArgsFunction: execShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().SetInterspersed(false)
+
+	cmd.Flags().String("image", "busybox", "Image of the debug container")
+	cmd.Flags().String("name", "", "Name of the debug container")
+	cmd.Flags().BoolP("tty", "t", false, "Allocate a pseudo-TTY")
+	cmd.Flags().BoolP("interactive", "i", false, "Keep STDIN open even if not attached")
+	cmd.Flags().Bool("privileged", false, "Give extended privileges to the debug container")
+	cmd.Flags().String("pull", "missing", `Pull the image before running ("always"|"missing"|"never")`)
+	cmd.Flags().String("target-mount", "/target", "Path where the root filesystem of the target container is mounted (empty for not mounting it)")
+	return cmd
+}
+
+func debugOptions(cmd *cobra.Command) (types.ContainerDebugOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.ContainerDebugOptions{}, err
+	}
+	image, err := cmd.Flags().GetString("image")
+	if err != nil {
+		return types.ContainerDebugOptions{}, err
+	}
+	name, err := cmd.Flags().GetString("name")
+	if err != nil {
+		return types.ContainerDebugOptions{}, err
+	}
+	flagT, err := cmd.Flags().GetBool("tty")
+	if err != nil {
+		return types.ContainerDebugOptions{}, err
+	}
+	flagI, err := cmd.Flags().GetBool("interactive")
+	if err != nil {
+		return types.ContainerDebugOptions{}, err
+	}
+	privileged, err := cmd.Flags().GetBool("privileged")
+	if err != nil {
+		return types.ContainerDebugOptions{}, err
+	}
+	pull, err := cmd.Flags().GetString("pull")
+	if err != nil {
+		return types.ContainerDebugOptions{}, err
+	}
+	targetMount, err := cmd.Flags().GetString("target-mount")
+	if err != nil {
+		return types.ContainerDebugOptions{}, err
+	}
+	nerdctlCmd, nerdctlArgs := helpers.GlobalFlags(cmd)
+	return types.ContainerDebugOptions{
+		Stdin:       cmd.InOrStdin(),
+		Stdout:      cmd.OutOrStdout(),
+		Stderr:      cmd.ErrOrStderr(),
+		GOptions:    globalOptions,
+		Image:       image,
+		Name:        name,
+		TTY:         flagT,
+		Interactive: flagI,
+		Privileged:  privileged,
+		Pull:        pull,
+		TargetMount: targetMount,
+		NerdctlCmd:  nerdctlCmd,
+		NerdctlArgs: nerdctlArgs,
+	}, nil
+}
+
+func debugAction(cmd *cobra.Command, args []string) error {
+	options, err := debugOptions(cmd)
+	if err != nil {
+		return err
+	}
+	// simulate the behavior of double dash
+	if len(args) >= 2 && args[1] == "--" {
+		args = append(args[:1], args[2:]...)
+	}
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return container.Debug(ctx, client, args[0], args[1:], options)
+}
@@ -0,0 +1,76 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestDebug(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier("target"), testutil.CommonImage,
+			"sh", "-c", "echo foo > /hello && sleep infinity")
+		nerdtest.EnsureContainerStarted(helpers, data.Identifier("target"))
+		helpers.Ensure("create", "--name", data.Identifier("stopped"), testutil.CommonImage)
+		data.Labels().Set("target", data.Identifier("target"))
+		data.Labels().Set("stopped", data.Identifier("stopped"))
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier("target"), data.Identifier("stopped"))
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "shares the namespaces and mounts the root filesystem",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("debug", "--image", testutil.CommonImage, data.Labels().Get("target"),
+					"sh", "-c", "cat /target/hello /proc/1/root/hello && ps")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Contains("foo\nfoo\n", "sleep infinity")),
+		},
+		{
+			Description: "propagates the exit code",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("debug", "--image", testutil.CommonImage, "--target-mount=", data.Labels().Get("target"),
+					"sh", "-c", "test ! -e /target && exit 42")
+			},
+			Expected: test.Expects(42, nil, nil),
+		},
+		{
+			Description: "fails for a stopped container",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("debug", "--image", testutil.CommonImage, data.Labels().Get("stopped"), "true")
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("is not running")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -63,3 +63,33 @@ func TestDiff(t *testing.T) {
 
 	testCase.Run(t)
 }
+
+func TestDiffFormatJSON(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.All(require.Not(require.Windows), require.Not(nerdtest.Docker))
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "--name", data.Identifier(), testutil.CommonImage,
+			"sh", "-euxc", "touch /a; rm /bin/base64")
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		return helpers.Command("diff", "--format", "json", data.Identifier())
+	}
+
+	testCase.Expected = test.Expects(
+		0,
+		nil,
+		expect.Contains(
+			`{"Kind":"A","Path":"/a"}`,
+			`{"Kind":"C","Path":"/bin"}`,
+			`{"Kind":"D","Path":"/bin/base64"}`),
+	)
+
+	testCase.Run(t)
+}
@@ -50,6 +50,8 @@ func ExecCommand() *cobra.Command {
 	cmd.Flags().StringArrayP("env", "e", nil, "Set environment variables")
 	// env-file is defined as StringSlice, not StringArray, to allow specifying "--env-file=FILE1,FILE2" (compatible with Podman)
 	cmd.Flags().StringSlice("env-file", nil, "Set environment variables from file")
+	cmd.Flags().Bool("env-file-expand", false, "Expand ${VAR} references in env files, using variables defined earlier in the files or in the host environment")
+	cmd.Flags().Bool("env-file-strict", false, "Fail on malformed lines in env files instead of passing them through")
 	cmd.Flags().Bool("privileged", false, "Give extended privileges to the command")
 	cmd.Flags().StringP("user", "u", "", "Username or UID (format: <name|uid>[:<group|gid>])")
 	return cmd
@@ -96,6 +98,14 @@ func execOptions(cmd *cobra.Command) (types.ContainerExecOptions, error) {
 	if err != nil {
 		return types.ContainerExecOptions{}, err
 	}
+	envFileExpand, err := cmd.Flags().GetBool("env-file-expand")
+	if err != nil {
+		return types.ContainerExecOptions{}, err
+	}
+	envFileStrict, err := cmd.Flags().GetBool("env-file-strict")
+	if err != nil {
+		return types.ContainerExecOptions{}, err
+	}
 	env, err := cmd.Flags().GetStringArray("env")
 	if err != nil {
 		return types.ContainerExecOptions{}, err
@@ -110,15 +120,21 @@ func execOptions(cmd *cobra.Command) (types.ContainerExecOptions, error) {
 	}
 
 	return types.ContainerExecOptions{
-		GOptions:    globalOptions,
-		TTY:         flagT,
-		Interactive: flagI,
-		Detach:      flagD,
-		Workdir:     workdir,
-		Env:         env,
-		EnvFile:     envFile,
-		Privileged:  privileged,
-		User:        user,
+		Stdin:         cmd.InOrStdin(),
+		Stdout:        cmd.OutOrStdout(),
+		Stderr:        cmd.ErrOrStderr(),
+		GOptions:      globalOptions,
+		TTY:           flagT,
+		Interactive:   flagI,
+		Detach:        flagD,
+		Workdir:       workdir,
+		Env:           env,
+		EnvFile:       envFile,
+		EnvFileExpand: envFileExpand,
+		EnvFileStrict: envFileStrict,
+		Privileged:    privileged,
+		User:          user,
+		SigProxy:      true,
 	}, nil
 }
 
@@ -54,6 +54,48 @@ func TestExecWithUser(t *testing.T) {
 	}
 }
 
+func TestExecUserFromRootfs(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), "--group-add", "nogroup", testutil.AlpineImage, "sleep", nerdtest.Infinity)
+		nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+		// The user and the group only exist in the running container, not in the image.
+		helpers.Ensure("exec", data.Identifier(), "sh", "-c",
+			"echo 'debugger:x:4321:4321::/home/debugger:/bin/sh' >> /etc/passwd && echo 'debuggers:x:4321:' >> /etc/group && echo 'tracers:x:4322:debugger' >> /etc/group")
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "user added at runtime, with supplementary groups and --group-add",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", "--user", "debugger", data.Identifier(), "id")
+			},
+			Expected: test.Expects(0, nil, expect.Contains("uid=4321(debugger) gid=4321(debuggers) groups=4321(debuggers),4322(tracers),65533(nogroup)")),
+		},
+		{
+			Description: "user and group names",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", "--user", "debugger:tracers", data.Identifier(), "id")
+			},
+			Expected: test.Expects(0, nil, expect.Contains("uid=4321(debugger) gid=4322(tracers) groups=4322(tracers),65533(nogroup)")),
+		},
+		{
+			Description: "unknown user",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", "--user", "nonexistent", data.Identifier(), "id")
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
+
 func TestExecTTY(t *testing.T) {
 	const sttyPartialOutput = "speed 38400 baud"
 
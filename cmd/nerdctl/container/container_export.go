@@ -0,0 +1,120 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+)
+
+func ExportCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:               "export [flags] CONTAINER",
+		Short:             "Export a container's filesystem as a tar archive (streamed to STDOUT by default)",
+		Args:              helpers.IsExactArgs(1),
+		RunE:              exportAction,
+		ValidArgsFunction: exportShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().StringP("output", "o", "", "Write to a file, instead of STDOUT")
+	cmd.Flags().Bool("live", false, "Export a running container from a point-in-time snapshot, pausing it only while the snapshot is taken")
+	cmd.Flags().String("compression", "", `Compression of the archive ("none"|"gzip"|"zstd") (default: inferred from the extension of --output, or "none")`)
+	cmd.RegisterFlagCompletionFunc("compression", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"none", "gzip", "zstd"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().StringArray("path", nil, "Export only the path in the container (can be specified multiple times)")
+	return cmd
+}
+
+func exportOptions(cmd *cobra.Command) (types.ContainerExportOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.ContainerExportOptions{}, err
+	}
+	live, err := cmd.Flags().GetBool("live")
+	if err != nil {
+		return types.ContainerExportOptions{}, err
+	}
+	compression, err := cmd.Flags().GetString("compression")
+	if err != nil {
+		return types.ContainerExportOptions{}, err
+	}
+	paths, err := cmd.Flags().GetStringArray("path")
+	if err != nil {
+		return types.ContainerExportOptions{}, err
+	}
+	return types.ContainerExportOptions{
+		Stdout:      cmd.OutOrStdout(),
+		GOptions:    globalOptions,
+		Live:        live,
+		Compression: compression,
+		Paths:       paths,
+	}, nil
+}
+
+func exportAction(cmd *cobra.Command, args []string) error {
+	options, err := exportOptions(cmd)
+	if err != nil {
+		return err
+	}
+	outputPath, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+	if options.Compression == "" {
+		options.Compression = container.ExportCompressionFromPath(outputPath)
+	}
+	if outputPath != "" {
+		f, err := os.OpenFile(outputPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		options.Stdout = f
+	} else if out, ok := options.Stdout.(*os.File); ok && isatty.IsTerminal(out.Fd()) {
+		return fmt.Errorf("cowardly refusing to export to a terminal. Use the -o flag or redirect")
+	}
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	err = container.Export(ctx, client, args[0], options)
+	if err != nil && outputPath != "" {
+		os.Remove(outputPath)
+	}
+	return err
+}
+
+func exportShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return completion.ContainerNames(cmd, nil)
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
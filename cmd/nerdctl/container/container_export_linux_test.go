@@ -0,0 +1,86 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestExport(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.CommonImage, "sleep", nerdtest.Infinity)
+		nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+		helpers.Ensure("exec", data.Identifier(), "sh", "-euc", "echo hello > /tmp/foo")
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+		helpers.Anyhow("volume", "rm", "-f", data.Identifier("live"), data.Identifier("full"))
+	}
+
+	// the archives are verified by importing them into volumes
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "live export of a path with compression",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("export", "--live", "--path", "/tmp/foo", "-o", data.Temp().Path("live.tar.gz"), data.Identifier())
+				helpers.Ensure("volume", "import", "-i", data.Temp().Path("live.tar.gz"), data.Identifier("live"))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "-v", data.Identifier("live")+":/data", testutil.CommonImage,
+					"sh", "-euc", "cat /data/tmp/foo; ls /data")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Equals("hello\ntmp\n")),
+		},
+		{
+			Description: "full export",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("container", "export", "-o", data.Temp().Path("full.tar"), data.Identifier())
+				helpers.Ensure("volume", "import", "-i", data.Temp().Path("full.tar"), data.Identifier("full"))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "-v", data.Identifier("full")+":/data", testutil.CommonImage,
+					"sh", "-euc", "cat /data/tmp/foo; test -f /data/etc/alpine-release")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Equals("hello\n")),
+		},
+		{
+			Description: "nonexistent path",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("export", "--path", "/nonexistent", "-o", data.Temp().Path("none.tar"), data.Identifier())
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("does not exist in the container")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
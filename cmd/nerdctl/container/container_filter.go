@@ -0,0 +1,62 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	containerd "github.com/containerd/containerd/v2/client"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+)
+
+// appendFilterTargets appends the IDs of the containers matching --filter to args.
+// The containers that are already specified in args (by name, ID, or ID prefix) are not appended again.
+func appendFilterTargets(ctx context.Context, cmd *cobra.Command, client *containerd.Client, args []string) ([]string, error) {
+	filters, allMatched, err := helpers.ProcessBatchFilterFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := container.FilterTargets(ctx, client, filters, allMatched)
+	if err != nil || len(ids) == 0 {
+		return args, err
+	}
+	specified := make(map[string]struct{})
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount == 1 {
+				specified[found.Container.ID()] = struct{}{}
+			}
+			return nil
+		},
+	}
+	for _, req := range args {
+		// The errors, e.g., "no such container", are reported when args are processed
+		_, _ = walker.Walk(ctx, req)
+	}
+	for _, id := range ids {
+		if _, ok := specified[id]; !ok {
+			args = append(args, id)
+		}
+	}
+	return args, nil
+}
@@ -0,0 +1,104 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestBatchFilter(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		label := "project=" + data.Identifier()
+		helpers.Ensure("run", "-d", "--name", data.Identifier("1"), "--label", label, testutil.CommonImage, "sleep", nerdtest.Infinity)
+		helpers.Ensure("run", "-d", "--name", data.Identifier("2"), "--label", label, testutil.CommonImage, "sleep", nerdtest.Infinity)
+		data.Labels().Set("filter", "label="+label)
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier("1"), data.Identifier("2"))
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "more than one match requires --all-matched",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("stop", "--filter", data.Labels().Get("filter"))
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("--all-matched")}, nil),
+		},
+		{
+			Description: "a container specified both by name and by --filter is removed once",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("run", "-d", "--name", data.Identifier("3"), testutil.CommonImage, "sleep", nerdtest.Infinity)
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier("3"))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("rm", "-f", data.Identifier("3"), "--filter", "name="+data.Identifier("3"))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.Equals(data.Identifier("3") + "\n"),
+				}
+			},
+		},
+		{
+			Description: "pause a single match, then stop and rm with --all-matched",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				helpers.Ensure("pause", "--filter", data.Labels().Get("filter"), "--filter", "name="+data.Identifier("1"))
+				helpers.Ensure("unpause", data.Identifier("1"))
+				helpers.Ensure("stop", "--filter", data.Labels().Get("filter"), "--filter", "status=running", "--all-matched")
+				helpers.Ensure("rm", "--filter", data.Labels().Get("filter"), "--all-matched")
+				return helpers.Command("ps", "-a", "-q", "--filter", data.Labels().Get("filter"))
+			},
+			Expected: test.Expects(0, nil, func(stdout string, info string, t *testing.T) {
+				assert.Equal(t, strings.TrimSpace(stdout), "", info)
+			}),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestBatchFilterRequiresArgsOrFilter(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Command = test.Command("stop")
+
+	testCase.Expected = test.Expects(expect.ExitCodeGenericFail, []error{errors.New("requires at least 1 arg")}, nil)
+
+	testCase.Run(t)
+}
@@ -77,6 +77,10 @@ func InspectOptions(cmd *cobra.Command) (opt types.ContainerInspectOptions, err
 	if err != nil {
 		return
 	}
+	format, err = helpers.InspectFormat(globalOptions, format)
+	if err != nil {
+		return
+	}
 
 	size, err := cmd.Flags().GetBool("size")
 	if err != nil {
@@ -103,9 +107,11 @@ func inspectAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
+	stdout, finish := helpers.OutputWriter(cmd, opt.GOptions, formatter.KindContainerInspect, formatter.ItemsFromJSONArray)
+	opt.Stdout = stdout
 	entries, err := container.Inspect(ctx, client, args, opt)
 	if err != nil {
-		return err
+		return finish(err)
 	}
 
 	// Display
@@ -114,7 +120,7 @@ func inspectAction(cmd *cobra.Command, args []string) error {
 			log.G(ctx).Error(formatErr)
 		}
 	}
-	return err
+	return finish(err)
 }
 
 func containerInspectShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
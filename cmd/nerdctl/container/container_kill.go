@@ -26,19 +26,23 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 )
 
 func KillCommand() *cobra.Command {
 	var cmd = &cobra.Command{
 		Use:               "kill [flags] CONTAINER [CONTAINER, ...]",
 		Short:             "Kill one or more running containers",
-		Args:              cobra.MinimumNArgs(1),
+		Args:              helpers.ContainerArgsOrFilter,
 		RunE:              killAction,
 		ValidArgsFunction: killShellComplete,
 		SilenceUsage:      true,
 		SilenceErrors:     true,
 	}
-	cmd.Flags().StringP("signal", "s", "KILL", "Signal to send to the container")
+	cmd.Flags().StringP("signal", "s", "KILL", `Signal to send to the container, e.g., "SIGTERM", "TERM", or "15"`)
+	cmd.Flags().Bool("all-processes", false, "Send the signal to all the processes in the container, not only to the init process")
+	helpers.AddParallelFlag(cmd)
+	helpers.AddBatchFilterFlags(cmd)
 	return cmd
 }
 
@@ -51,11 +55,21 @@ func killAction(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	allProcesses, err := cmd.Flags().GetBool("all-processes")
+	if err != nil {
+		return err
+	}
+	parallel, err := helpers.ProcessParallelFlag(cmd)
+	if err != nil {
+		return err
+	}
 	options := types.ContainerKillOptions{
-		GOptions:   globalOptions,
-		KillSignal: killSignal,
-		Stdout:     cmd.OutOrStdout(),
-		Stderr:     cmd.ErrOrStderr(),
+		GOptions:     globalOptions,
+		KillSignal:   killSignal,
+		AllProcesses: allProcesses,
+		Parallel:     parallel,
+		Stdout:       cmd.OutOrStdout(),
+		Stderr:       cmd.ErrOrStderr(),
 	}
 
 	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
@@ -64,7 +78,14 @@ func killAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
-	return container.Kill(ctx, client, args, options)
+	args, err = appendFilterTargets(ctx, cmd, client, args)
+	if err != nil {
+		return err
+	}
+
+	stdout, finish := helpers.OutputWriter(cmd, options.GOptions, formatter.KindContainerKill, formatter.ItemsFromTextLines)
+	options.Stdout = stdout
+	return finish(container.Kill(ctx, client, args, options))
 }
 
 func killShellComplete(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
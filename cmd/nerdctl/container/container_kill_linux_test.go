@@ -24,6 +24,10 @@ import (
 	"github.com/coreos/go-iptables/iptables"
 	"gotest.tools/v3/assert"
 
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
 	iptablesutil "github.com/containerd/nerdctl/v2/pkg/testutil/iptables"
@@ -78,3 +82,55 @@ func TestKillCleanupForwards(t *testing.T) {
 	base.Cmd("kill", testContainerName).AssertOK()
 	assert.Equal(t, iptablesutil.ForwardExists(t, ipt, chain, containerIP, hostPort), false)
 }
+
+func TestKillSignalForms(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.SubTests = []*test.Case{}
+	for _, sig := range []string{"SIGTERM", "TERM", "sigterm", "15"} {
+		testCase.SubTests = append(testCase.SubTests, &test.Case{
+			Description: sig,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.CommonImage,
+					"sh", "-c", "trap 'exit 42' TERM; while true; do sleep 0.1; done")
+				nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				helpers.Ensure("kill", "-s", sig, data.Identifier())
+				return helpers.Command("wait", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Equals("42\n")),
+		})
+	}
+
+	testCase.Run(t)
+}
+
+func TestKillAllProcesses(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		// PID 1 has no handler for SIGTERM, so only the child processes are terminated by the signal.
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.CommonImage,
+			"sh", "-c", "sleep "+nerdtest.Infinity+" & wait $!")
+		nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		helpers.Ensure("kill", "--all-processes", "-s", "TERM", data.Identifier())
+		return helpers.Command("wait", data.Identifier())
+	}
+
+	testCase.Expected = test.Expects(0, nil, expect.Equals("143\n"))
+
+	testCase.Run(t)
+}
@@ -48,6 +48,7 @@ func PsCommand() *cobra.Command {
 	cmd.Flags().Bool("no-trunc", false, "Don't truncate output")
 	cmd.Flags().BoolP("quiet", "q", false, "Only display container IDs")
 	cmd.Flags().BoolP("size", "s", false, "Display total file sizes")
+	cmd.Flags().Bool("sync", false, "Query containerd for the command and the status of every container, instead of the cached index")
 
 	// Alias "-f" is reserved for "--filter"
 	cmd.Flags().String("format", "", "Format the output using the given Go template, e.g, '{{json .}}', 'wide'")
@@ -99,6 +100,15 @@ func processOptions(cmd *cobra.Command) (types.ContainerListOptions, FormattingA
 	if err != nil {
 		return types.ContainerListOptions{}, FormattingAndPrintingOptions{}, err
 	}
+	format, err = helpers.ListFormat(globalOptions, format, quiet)
+	if err != nil {
+		return types.ContainerListOptions{}, FormattingAndPrintingOptions{}, err
+	}
+
+	sync, err := cmd.Flags().GetBool("sync")
+	if err != nil {
+		return types.ContainerListOptions{}, FormattingAndPrintingOptions{}, err
+	}
 
 	size := false
 	if !quiet {
@@ -115,6 +125,7 @@ func processOptions(cmd *cobra.Command) (types.ContainerListOptions, FormattingA
 			Truncate: trunc,
 			Size:     size || (format == "wide" && !quiet),
 			Filters:  filters,
+			Sync:     sync,
 		}, FormattingAndPrintingOptions{
 			Stdout: cmd.OutOrStdout(),
 			Quiet:  quiet,
@@ -135,12 +146,15 @@ func psAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
+	stdout, finish := helpers.OutputWriter(cmd, clOpts.GOptions, formatter.KindContainerList, formatter.ItemsFromJSONLines)
+	fpOpts.Stdout = stdout
+
 	containers, err := container.List(ctx, client, clOpts)
 	if err != nil {
-		return err
+		return finish(err)
 	}
 
-	return formatAndPrintContainerInfo(containers, fpOpts)
+	return finish(formatAndPrintContainerInfo(containers, fpOpts))
 }
 
 // FormattingAndPrintingOptions specifies options for formatting and printing of `nerdctl (container) list`.
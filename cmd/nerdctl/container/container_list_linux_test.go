@@ -26,6 +26,7 @@ import (
 
 	"gotest.tools/v3/assert"
 
+	"github.com/containerd/nerdctl/mod/tigron/require"
 	"github.com/containerd/nerdctl/mod/tigron/test"
 
 	"github.com/containerd/nerdctl/v2/pkg/formatter"
@@ -662,3 +663,55 @@ func TestContainerListStatusFilter(t *testing.T) {
 
 	testCase.Run(t)
 }
+
+func TestContainerListSync(t *testing.T) {
+	testCase := nerdtest.Setup()
+	testCase.Require = require.Not(nerdtest.Docker)
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier("running"), testutil.CommonImage, "sleep", nerdtest.Infinity)
+		helpers.Ensure("run", "-d", "--name", data.Identifier("paused"), testutil.CommonImage, "sleep", nerdtest.Infinity)
+		helpers.Ensure("pause", data.Identifier("paused"))
+		helpers.Ensure("create", "--name", data.Identifier("created"), testutil.CommonImage, "echo", "foo")
+		data.Labels().Set("prefix", data.Identifier())
+		data.Labels().Set("running", data.Identifier("running"))
+		data.Labels().Set("paused", data.Identifier("paused"))
+		data.Labels().Set("created", data.Identifier("created"))
+	}
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier("running"), data.Identifier("paused"), data.Identifier("created"))
+	}
+
+	expected := func(data test.Data, helpers test.Helpers) *test.Expected {
+		return &test.Expected{
+			Output: func(stdout, info string, t *testing.T) {
+				statuses := map[string]string{}
+				for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+					name, status, _ := strings.Cut(line, " ")
+					statuses[name] = status
+				}
+				assert.Equal(t, statuses[data.Labels().Get("running")], `Up "sleep infinity"`, info)
+				assert.Equal(t, statuses[data.Labels().Get("paused")], `Paused "sleep infinity"`, info)
+				assert.Equal(t, statuses[data.Labels().Get("created")], `Created "echo foo"`, info)
+			},
+		}
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "cached index",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("ps", "-a", "--filter", "name="+data.Labels().Get("prefix"), "--format", "{{.Names}} {{.Status}} {{.Command}}")
+			},
+			Expected: expected,
+		},
+		{
+			Description: "sync",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("ps", "-a", "--sync", "--filter", "name="+data.Labels().Get("prefix"), "--format", "{{.Names}} {{.Status}} {{.Command}}")
+			},
+			Expected: expected,
+		},
+	}
+
+	testCase.Run(t)
+}
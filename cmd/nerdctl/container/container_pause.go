@@ -26,18 +26,20 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 )
 
 func PauseCommand() *cobra.Command {
 	var cmd = &cobra.Command{
 		Use:               "pause [flags] CONTAINER [CONTAINER, ...]",
-		Args:              cobra.MinimumNArgs(1),
+		Args:              helpers.ContainerArgsOrFilter,
 		Short:             "Pause all processes within one or more containers",
 		RunE:              pauseAction,
 		ValidArgsFunction: pauseShellComplete,
 		SilenceUsage:      true,
 		SilenceErrors:     true,
 	}
+	helpers.AddBatchFilterFlags(cmd)
 	return cmd
 }
 
@@ -64,7 +66,14 @@ func pauseAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
-	return container.Pause(ctx, client, args, options)
+	args, err = appendFilterTargets(ctx, cmd, client, args)
+	if err != nil {
+		return err
+	}
+
+	stdout, finish := helpers.OutputWriter(cmd, options.GOptions, formatter.KindContainerPause, formatter.ItemsFromTextLines)
+	options.Stdout = stdout
+	return finish(container.Pause(ctx, client, args, options))
 }
 
 func pauseShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
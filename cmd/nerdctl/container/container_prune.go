@@ -35,6 +35,8 @@ func pruneCommand() *cobra.Command {
 		SilenceErrors: true,
 	}
 	cmd.Flags().BoolP("force", "f", false, "Do not prompt for confirmation")
+	cmd.Flags().StringSlice("filter", nil, "Provide filter values (e.g. 'until=24h', 'label=<key>=<value>')")
+	helpers.AddParallelFlag(cmd)
 	return cmd
 }
 
@@ -44,9 +46,21 @@ func pruneOptions(cmd *cobra.Command) (types.ContainerPruneOptions, error) {
 		return types.ContainerPruneOptions{}, err
 	}
 
+	filters, err := cmd.Flags().GetStringSlice("filter")
+	if err != nil {
+		return types.ContainerPruneOptions{}, err
+	}
+
+	parallel, err := helpers.ProcessParallelFlag(cmd)
+	if err != nil {
+		return types.ContainerPruneOptions{}, err
+	}
+
 	return types.ContainerPruneOptions{
 		GOptions: globalOptions,
 		Stdout:   cmd.OutOrStdout(),
+		Filters:  filters,
+		Parallel: parallel,
 	}, nil
 }
 
@@ -0,0 +1,114 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nettestutil"
+)
+
+func TestContainerPublish(t *testing.T) {
+	const hostPort = "18361"
+
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.All(
+		require.Not(nerdtest.Docker),
+		// Updating the ports of a running container is not supported in rootless mode yet
+		require.Not(nerdtest.Rootless),
+	)
+	testCase.NoParallel = true
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.NginxAlpineImage)
+		helpers.Ensure("run", "-d", "--name", data.Identifier("other"), testutil.NginxAlpineImage)
+		nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+		nerdtest.EnsureContainerStarted(helpers, data.Identifier("other"))
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier(), data.Identifier("other"))
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "publish",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				helpers.Ensure("container", "publish", data.Identifier(), hostPort+":80")
+				return helpers.Command("port", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.All(
+				expect.Contains("80/tcp -> 0.0.0.0:"+hostPort),
+				func(stdout string, info string, t *testing.T) {
+					resp, err := nettestutil.HTTPGet("http://127.0.0.1:"+hostPort, 30, false)
+					assert.NilError(t, err, info)
+					defer resp.Body.Close()
+					body, err := io.ReadAll(resp.Body)
+					assert.NilError(t, err, info)
+					assert.Assert(t, len(body) > 0, info)
+				},
+			)),
+		},
+		{
+			Description: "publishing the same host port twice fails",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("container", "publish", data.Identifier(), hostPort+":8080")
+			},
+			Expected: test.Expects(1, []error{errors.New("is already published")}, nil),
+		},
+		{
+			Description: "publishing a host port of another container fails",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("container", "publish", data.Identifier("other"), hostPort+":80")
+			},
+			Expected: test.Expects(1, []error{errors.New("is already in use")}, nil),
+		},
+		{
+			Description: "unpublish",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				helpers.Ensure("container", "unpublish", data.Identifier(), "80/tcp")
+				return helpers.Command("port", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.DoesNotContain(hostPort)),
+		},
+		{
+			Description: "unpublishing a port that is not published fails",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("container", "unpublish", data.Identifier(), "80/tcp")
+			},
+			Expected: test.Expects(1, []error{errors.New("no public port 80/tcp published")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -24,12 +24,13 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 )
 
 func RemoveCommand() *cobra.Command {
 	var cmd = &cobra.Command{
 		Use:               "rm [flags] CONTAINER [CONTAINER, ...]",
-		Args:              cobra.MinimumNArgs(1),
+		Args:              helpers.ContainerArgsOrFilter,
 		Short:             "Remove one or more containers",
 		RunE:              removeAction,
 		ValidArgsFunction: rmShellComplete,
@@ -39,6 +40,8 @@ func RemoveCommand() *cobra.Command {
 	cmd.Aliases = []string{"remove"}
 	cmd.Flags().BoolP("force", "f", false, "Force the removal of a running|paused|unknown container (uses SIGKILL)")
 	cmd.Flags().BoolP("volumes", "v", false, "Remove volumes associated with the container")
+	helpers.AddParallelFlag(cmd)
+	helpers.AddBatchFilterFlags(cmd)
 	return cmd
 }
 
@@ -55,10 +58,15 @@ func removeAction(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	parallel, err := helpers.ProcessParallelFlag(cmd)
+	if err != nil {
+		return err
+	}
 	options := types.ContainerRemoveOptions{
 		GOptions: globalOptions,
 		Force:    force,
 		Volumes:  removeAnonVolumes,
+		Parallel: parallel,
 		Stdout:   cmd.OutOrStdout(),
 	}
 
@@ -68,7 +76,14 @@ func removeAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
-	return container.Remove(ctx, client, args, options)
+	args, err = appendFilterTargets(ctx, cmd, client, args)
+	if err != nil {
+		return err
+	}
+
+	stdout, finish := helpers.OutputWriter(cmd, options.GOptions, formatter.KindContainerRemove, formatter.ItemsFromTextLines)
+	options.Stdout = stdout
+	return finish(container.Remove(ctx, client, args, options))
 }
 
 func rmShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -0,0 +1,202 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+// anonymousVolumeName returns the name of the anonymous volume mounted on dest in the container.
+func anonymousVolumeName(helpers test.Helpers, container, dest string) string {
+	inspect := nerdtest.InspectContainer(helpers, container)
+	for _, m := range inspect.Mounts {
+		if m.Destination == dest {
+			return m.Name
+		}
+	}
+	assert.Assert(helpers.T(), false, "failed to find the anonymous volume mounted on %s", dest)
+	return ""
+}
+
+func TestRemoveContainerAnonymousVolumes(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "rm -v removes the anonymous volumes",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("create", "--name", data.Identifier(), "-v", "/anonymous", testutil.CommonImage)
+				data.Labels().Set("anonName", anonymousVolumeName(helpers, data.Identifier(), "/anonymous"))
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+				helpers.Anyhow("volume", "rm", "-f", data.Labels().Get("anonName"))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				helpers.Ensure("rm", "-v", data.Identifier())
+				return helpers.Command("volume", "inspect", data.Labels().Get("anonName"))
+			},
+			Expected: test.Expects(1, nil, nil),
+		},
+		{
+			Description: "rm without -v keeps the anonymous volumes",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("create", "--name", data.Identifier(), "-v", "/anonymous", testutil.CommonImage)
+				data.Labels().Set("anonName", anonymousVolumeName(helpers, data.Identifier(), "/anonymous"))
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+				helpers.Anyhow("volume", "rm", "-f", data.Labels().Get("anonName"))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				helpers.Ensure("rm", data.Identifier())
+				return helpers.Command("volume", "inspect", data.Labels().Get("anonName"))
+			},
+			Expected: test.Expects(0, nil, nil),
+		},
+		{
+			Description: "rm -v keeps the anonymous volumes used by --volumes-from",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("create", "--name", data.Identifier("owner"), "-v", "/anonymous", testutil.CommonImage)
+				helpers.Ensure("create", "--name", data.Identifier("consumer"), "--volumes-from", data.Identifier("owner"), testutil.CommonImage)
+				data.Labels().Set("anonName", anonymousVolumeName(helpers, data.Identifier("owner"), "/anonymous"))
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier("owner"))
+				helpers.Anyhow("rm", "-f", data.Identifier("consumer"))
+				helpers.Anyhow("volume", "rm", "-f", data.Labels().Get("anonName"))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				// The consumer does not own the volume
+				helpers.Ensure("rm", "-v", data.Identifier("consumer"))
+				helpers.Ensure("volume", "inspect", data.Labels().Get("anonName"))
+				// The owner does, but the volume is still in use
+				helpers.Ensure("create", "--name", data.Identifier("consumer"), "--volumes-from", data.Identifier("owner"), testutil.CommonImage)
+				helpers.Ensure("rm", "-v", data.Identifier("owner"))
+				helpers.Ensure("volume", "inspect", data.Labels().Get("anonName"))
+				// Once orphaned, it is left for `volume prune`
+				helpers.Ensure("rm", data.Identifier("consumer"))
+				return helpers.Command("volume", "inspect", data.Labels().Get("anonName"))
+			},
+			Expected: test.Expects(0, nil, nil),
+		},
+		{
+			Description: "run --rm removes the anonymous volumes",
+			// A private namespace is needed to list the volumes without seeing the ones of the other tests
+			Require: nerdtest.Private,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("volume", "create", data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("volume", "rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				helpers.Ensure("run", "--rm", "-v", "/anonymous", "-v", data.Identifier()+":/named", testutil.CommonImage)
+				return helpers.Command("volume", "ls", "-q")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				// The named volume is kept, and no anonymous volume is left behind
+				return &test.Expected{
+					Output: expect.Equals(data.Identifier() + "\n"),
+				}
+			},
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestRemoveContainerParallel(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "rm --parallel removes all the containers, and aggregates the errors",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				for _, name := range []string{"a", "b", "c", "d"} {
+					helpers.Ensure("create", "--name", data.Identifier(name), testutil.CommonImage)
+				}
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier("a"), data.Identifier("b"), data.Identifier("c"), data.Identifier("d"))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("rm", "--parallel", "3",
+					data.Identifier("a"), data.Identifier("b"), data.Identifier("nonexistent"), data.Identifier("c"), data.Identifier("d"))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					ExitCode: expect.ExitCodeGenericFail,
+					Errors:   []error{errors.New("1 errors"), errors.New("no such container: " + data.Identifier("nonexistent"))},
+					Output: expect.All(
+						expect.Contains(data.Identifier("a"), data.Identifier("b"), data.Identifier("c"), data.Identifier("d")),
+						func(stdout string, info string, t *testing.T) {
+							containers := helpers.Capture("ps", "-a")
+							for _, name := range []string{"a", "b", "c", "d"} {
+								assert.Assert(t, !strings.Contains(containers, data.Identifier(name)), containers)
+							}
+						},
+					),
+				}
+			},
+		},
+		{
+			Description: "stop --parallel",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				for _, name := range []string{"a", "b"} {
+					helpers.Ensure("run", "-d", "--name", data.Identifier(name), testutil.CommonImage, "sleep", nerdtest.Infinity)
+				}
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier("a"), data.Identifier("b"))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("stop", "--parallel", "2", "--time", "1", data.Identifier("a"), data.Identifier("b"))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout string, info string, t *testing.T) {
+						for _, name := range []string{"a", "b"} {
+							inspect := nerdtest.InspectContainer(helpers, data.Identifier(name))
+							assert.Equal(t, inspect.State.Running, false, name)
+						}
+					},
+				}
+			},
+		},
+		{
+			Description: "invalid --parallel",
+			Command:     test.Command("rm", "--parallel", "0", "nonexistent"),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, []error{errors.New("invalid --parallel value 0")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
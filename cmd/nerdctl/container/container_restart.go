@@ -17,28 +17,30 @@
 package container
 
 import (
-	"time"
-
 	"github.com/spf13/cobra"
 
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 )
 
 func RestartCommand() *cobra.Command {
 	var cmd = &cobra.Command{
 		Use:               "restart [flags] CONTAINER [CONTAINER, ...]",
-		Args:              cobra.MinimumNArgs(1),
+		Args:              helpers.ContainerArgsOrFilter,
 		Short:             "Restart one or more running containers",
 		RunE:              restartAction,
 		ValidArgsFunction: startShellComplete,
 		SilenceUsage:      true,
 		SilenceErrors:     true,
 	}
-	cmd.Flags().UintP("time", "t", 10, "Seconds to wait for stop before killing it")
-	cmd.Flags().StringP("signal", "s", "", "Signal to send to stop the container, before killing it")
+	cmd.Flags().IntP("timeout", "t", 10, "Seconds to wait for stop before killing it, -1 to wait indefinitely (default: --stop-timeout of the container, or 10)")
+	cmd.Flags().Int("time", 10, "Seconds to wait for stop before killing it")
+	cmd.Flags().MarkDeprecated("time", "use --timeout instead")
+	cmd.Flags().StringP("signal", "s", "", "Signal to send to stop the container, before killing it (default: --stop-signal of the container, or STOPSIGNAL of the image, or SIGTERM)")
+	helpers.AddBatchFilterFlags(cmd)
 	return cmd
 }
 
@@ -48,15 +50,9 @@ func restartOptions(cmd *cobra.Command) (types.ContainerRestartOptions, error) {
 		return types.ContainerRestartOptions{}, err
 	}
 
-	var timeout *time.Duration
-	if cmd.Flags().Changed("time") {
-		// Seconds to wait for stop before killing it
-		timeValue, err := cmd.Flags().GetUint("time")
-		if err != nil {
-			return types.ContainerRestartOptions{}, err
-		}
-		t := time.Duration(timeValue) * time.Second
-		timeout = &t
+	timeout, err := helpers.ProcessStopTimeoutFlag(cmd)
+	if err != nil {
+		return types.ContainerRestartOptions{}, err
 	}
 
 	var signal string
@@ -89,5 +85,12 @@ func restartAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
-	return container.Restart(ctx, client, args, options)
+	args, err = appendFilterTargets(ctx, cmd, client, args)
+	if err != nil {
+		return err
+	}
+
+	stdout, finish := helpers.OutputWriter(cmd, options.GOption, formatter.KindContainerRestart, formatter.ItemsFromTextLines)
+	options.Stdout = stdout
+	return finish(container.Restart(ctx, client, args, options))
 }
@@ -0,0 +1,150 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+)
+
+func restartPolicyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "restart-policy",
+		Short:         "Inspect and update the restart policies of containers",
+		RunE:          helpers.UnknownSubcommandAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.AddCommand(
+		restartPolicyListCommand(),
+		restartPolicySetCommand(),
+	)
+	return cmd
+}
+
+func restartPolicyListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "ls [flags] [CONTAINER...]",
+		Aliases:           []string{"list"},
+		Short:             "List the restart policies of containers",
+		RunE:              restartPolicyListAction,
+		ValidArgsFunction: restartPolicyShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().StringSliceP("filter", "f", nil, "Filter the containers, as 'nerdctl ps --filter'")
+	cmd.Flags().String("format", "", "Format the output using the given Go template, e.g, '{{json .}}'")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "table"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	return cmd
+}
+
+func restartPolicySetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set [flags] POLICY [CONTAINER...]",
+		Short: "Update the restart policy of containers, without re-creating them",
+		Long: `Update the restart policy of the containers specified by the arguments and by --filter, without re-creating them.
+
+POLICY is one of "no", "always", "on-failure[:max-retries]", and "unless-stopped".
+
+Example:
+  nerdctl container restart-policy set --filter label=env=prod unless-stopped`,
+		Args:          cobra.MinimumNArgs(1),
+		RunE:          restartPolicySetAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return []string{"no", "always", "on-failure", "unless-stopped"}, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completion.ContainerNames(cmd, nil)
+		},
+	}
+	cmd.Flags().StringSliceP("filter", "f", nil, "Filter the containers, as 'nerdctl ps --filter'")
+	return cmd
+}
+
+func restartPolicyListAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	filters, err := cmd.Flags().GetStringSlice("filter")
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	format, err = helpers.ListFormat(globalOptions, format, false)
+	if err != nil {
+		return err
+	}
+	stdout, finish := helpers.OutputWriter(cmd, globalOptions, formatter.KindRestartPolicyList, formatter.ItemsFromJSONLines)
+	options := types.ContainerRestartPolicyListOptions{
+		Stdout:   stdout,
+		GOptions: globalOptions,
+		Filters:  filters,
+		Format:   format,
+	}
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return finish(container.ListRestartPolicies(ctx, client, args, options))
+}
+
+func restartPolicySetAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	filters, err := cmd.Flags().GetStringSlice("filter")
+	if err != nil {
+		return err
+	}
+	options := types.ContainerRestartPolicySetOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+		Policy:   args[0],
+		Filters:  filters,
+	}
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return container.SetRestartPolicy(ctx, client, args[1:], options)
+}
+
+func restartPolicyShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// show container names
+	return completion.ContainerNames(cmd, nil)
+}
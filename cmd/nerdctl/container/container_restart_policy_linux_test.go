@@ -0,0 +1,87 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestContainerRestartPolicySet(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier("prod"), "--label", "env="+data.Identifier("prod"),
+			testutil.CommonImage, "sleep", nerdtest.Infinity)
+		helpers.Ensure("run", "-d", "--name", data.Identifier("dev"), "--label", "env="+data.Identifier("dev"),
+			testutil.CommonImage, "sleep", nerdtest.Infinity)
+		helpers.Ensure("create", "--name", data.Identifier("created"), "--label", "env="+data.Identifier("prod"),
+			testutil.CommonImage, "sleep", nerdtest.Infinity)
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier("prod"), data.Identifier("dev"), data.Identifier("created"))
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		helpers.Ensure("container", "restart-policy", "set", "--filter", "label=env="+data.Identifier("prod"), "unless-stopped")
+		return helpers.Command("container", "restart-policy", "ls", "--format", "{{.Name}} {{.Policy}} {{.DesiredStatus}}",
+			data.Identifier("prod"), data.Identifier("dev"), data.Identifier("created"))
+	}
+
+	testCase.Expected = func(data test.Data, helpers test.Helpers) *test.Expected {
+		return &test.Expected{
+			Output: expect.All(
+				expect.Contains(data.Identifier("prod")+" unless-stopped running"),
+				expect.Contains(data.Identifier("created")+" unless-stopped created"),
+				func(stdout string, info string, t *testing.T) {
+					for _, line := range strings.Split(stdout, "\n") {
+						if strings.HasPrefix(line, data.Identifier("dev")+" ") {
+							assert.Equal(t, line, data.Identifier("dev")+" no ", info)
+						}
+					}
+				},
+			),
+		}
+	}
+
+	testCase.Run(t)
+}
+
+func TestContainerRestartPolicySetRequiresTarget(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Command = test.Command("container", "restart-policy", "set", "always")
+
+	testCase.Expected = test.Expects(expect.ExitCodeGenericFail, []error{errors.New("requires at least one container or --filter")}, nil)
+
+	testCase.Run(t)
+}
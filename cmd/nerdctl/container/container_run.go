@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
@@ -57,11 +58,11 @@ func RunCommand() *cobra.Command {
 		longHelp += "WARNING: `nerdctl run` is experimental on Windows and currently broken (https://github.com/containerd/nerdctl/issues/28)"
 	case "freebsd":
 		longHelp += "\n"
-		longHelp += "WARNING: `nerdctl run` is experimental on FreeBSD and currently requires `--net=none` (https://github.com/containerd/nerdctl/blob/main/docs/freebsd.md)"
+		longHelp += "WARNING: `nerdctl run` is experimental on FreeBSD, and the bridge networks require a runtime supporting vnet jails (https://github.com/containerd/nerdctl/blob/main/docs/freebsd.md)"
 	}
 	var cmd = &cobra.Command{
 		Use:               "run [flags] IMAGE [COMMAND] [ARG...]",
-		Args:              cobra.MinimumNArgs(1),
+		Args:              imageArgsOrFromConfig,
 		Short:             shortHelp,
 		Long:              longHelp,
 		RunE:              runAction,
@@ -74,6 +75,9 @@ func RunCommand() *cobra.Command {
 	setCreateFlags(cmd)
 
 	cmd.Flags().BoolP("detach", "d", false, "Run container in background and print container ID")
+	cmd.Flags().Bool("wait-healthy", false, "Wait until the HEALTHCHECK of the image passes (requires -d)")
+	cmd.Flags().String("wait-port", "", "Wait until the TCP port of the container accepts connections (requires -d)")
+	cmd.Flags().Duration("wait-timeout", 60*time.Second, "Timeout of --wait-healthy and --wait-port")
 	cmd.Flags().StringSliceP("attach", "a", []string{}, "Attach STDIN, STDOUT, or STDERR")
 
 	return cmd
@@ -97,9 +101,14 @@ func setCreateFlags(cmd *cobra.Command) {
 	cmd.RegisterFlagCompletionFunc("pull", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return []string{"always", "missing", "never"}, cobra.ShellCompDirectiveNoFileComp
 	})
-	cmd.Flags().String("stop-signal", "SIGTERM", "Signal to stop a container")
-	cmd.Flags().Int("stop-timeout", 0, "Timeout (in seconds) to stop a container")
+	cmd.Flags().String("stop-signal", "", "Signal to stop a container (default: STOPSIGNAL of the image, or SIGTERM)")
+	cmd.Flags().Int("stop-timeout", 0, "Timeout (in seconds) to stop a container, -1 to wait indefinitely (default: 10)")
 	cmd.Flags().String("detach-keys", consoleutil.DefaultDetachKeys, "Override the default detach keys")
+	cmd.Flags().StringArray("on-start", nil, "Host command to run before the container starts")
+	cmd.Flags().StringArray("on-post-start", nil, `Command to run after the container starts, on the host, or inside the container with the "exec:" prefix`)
+	cmd.Flags().StringArray("on-stop", nil, `Command to run before the container is stopped, on the host, or inside the container with the "exec:" prefix`)
+	cmd.Flags().StringArray("watch-config", nil, "Restart the container when the bind-mounted file or directory at the path in the container changes")
+	cmd.Flags().String("watch-config-signal", "", "Send the signal to the container instead of restarting it on --watch-config changes (e.g., SIGHUP)")
 
 	// #region for init process
 	cmd.Flags().Bool("init", false, "Run an init process inside the container, Default to use tini")
@@ -129,11 +138,17 @@ func setCreateFlags(cmd *cobra.Command) {
 	cmd.Flags().StringSlice("dns-option", nil, "Set DNS options")
 	// publish is defined as StringSlice, not StringArray, to allow specifying "--publish=80:80,443:443" (compatible with Podman)
 	cmd.Flags().StringSliceP("publish", "p", nil, "Publish a container's port(s) to the host")
+	cmd.Flags().BoolP("publish-all", "P", false, "Publish all exposed ports to random ports")
+	cmd.Flags().Bool("userland-proxy", false, "Run a userland proxy for each published port, for hosts where hairpin NAT is unavailable")
 	cmd.Flags().String("ip", "", "IPv4 address to assign to the container")
 	cmd.Flags().String("ip6", "", "IPv6 address to assign to the container")
 	cmd.Flags().StringP("hostname", "h", "", "Container host name")
 	cmd.Flags().String("domainname", "", "Container domain name")
 	cmd.Flags().String("mac-address", "", "MAC address to assign to the container")
+	cmd.Flags().String("network-bandwidth", "", "Limit the ingress and egress rate of the container (e.g., \"10Mbit\")")
+	cmd.Flags().String("network-delay", "", "Delay the packets sent by the container (e.g., \"50ms\")")
+	cmd.Flags().Bool("net-accel", false, "Accelerate the networking of the rootless container with bypass4netns (requires bypass4netnsd, see \"nerdctl system bypass4netnsd\")")
+	cmd.Flags().StringArray("allow-from", nil, "Allow the connections to the published ports from the CIDR, on networks created with \"--ingress-policy=deny\"")
 	// #endregion
 
 	cmd.Flags().String("ipc", "", `IPC namespace to use ("host"|"private")`)
@@ -163,11 +178,16 @@ func setCreateFlags(cmd *cobra.Command) {
 	})
 	cmd.Flags().String("cpuset-cpus", "", "CPUs in which to allow execution (0-3, 0,1)")
 	cmd.Flags().String("cpuset-mems", "", "MEMs in which to allow execution (0-3, 0,1)")
+	cmd.Flags().String("numa-node", "", "NUMA nodes (0-1, 0,1) to pin the container to, setting --cpuset-cpus and --cpuset-mems from the host topology")
 	cmd.Flags().Uint64("cpu-shares", 0, "CPU shares (relative weight)")
 	cmd.Flags().Int64("cpu-quota", -1, "Limit CPU CFS (Completely Fair Scheduler) quota")
 	cmd.Flags().Uint64("cpu-period", 0, "Limit CPU CFS (Completely Fair Scheduler) period")
 	cmd.Flags().Uint64("cpu-rt-period", 0, "Limit CPU real-time period in microseconds")
 	cmd.Flags().Uint64("cpu-rt-runtime", 0, "Limit CPU real-time runtime in microseconds")
+	cmd.Flags().Uint64("cpu-count", 0, "CPU count (Windows only)")
+	cmd.Flags().Uint64("cpu-percent", 0, "CPU percent (Windows only)")
+	cmd.Flags().String("io-maxbandwidth", "", `Maximum IO bandwidth limit for the system drive, e.g., "100M" (Windows only)`)
+	cmd.Flags().Uint64("io-maxiops", 0, "Maximum IOps limit for the system drive (Windows only)")
 	// device is defined as StringSlice, not StringArray, to allow specifying "--device=DEV1,DEV2" (compatible with Podman)
 	cmd.Flags().StringSlice("device", nil, "Add a host device to the container")
 	// ulimit is defined as StringSlice, not StringArray, to allow specifying "--ulimit=ULIMIT1,ULIMIT2" (compatible with Podman)
@@ -227,12 +247,19 @@ func setCreateFlags(cmd *cobra.Command) {
 	cmd.Flags().StringArray("mount", nil, "Attach a filesystem mount to the container")
 	// volumes-from needs to be StringArray, not StringSlice, to prevent "id1,id2" from being split to {"id1", "id2"} (compatible with Docker)
 	cmd.Flags().StringArray("volumes-from", nil, "Mount volumes from the specified container(s)")
+	cmd.Flags().StringArray("secret", nil, `Mount a secret created with "nerdctl secret create", e.g., "id=mysecret,target=/run/secrets/password,uid=0,gid=0,mode=0444"`)
+	cmd.RegisterFlagCompletionFunc("secret", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completion.SecretNames(cmd)
+	})
 	// #endregion
 
 	// rootfs flags
 	cmd.Flags().Bool("read-only", false, "Mount the container's root filesystem as read only")
+	cmd.Flags().String("read-only-tmpfs-size", "64m", `Size of the tmpfs mounted on /run and /tmp with --read-only ("0" to disable the tmpfs mounts)`)
+	cmd.Flags().Bool("immutable", false, "Mount the container's root filesystem as read only (implies --read-only), and forbid exec and cp into the container")
 	// rootfs flags (from Podman)
 	cmd.Flags().Bool("rootfs", false, "The first argument is not an image but the rootfs to the exploded container")
+	cmd.Flags().StringArray("storage-opt", nil, "Storage driver options for the container (\"size=<SIZE>\" limits the size of the writable layer)")
 
 	// #region env flags
 	// entrypoint needs to be StringArray, not StringSlice, to prevent "FOO=foo1,foo2" from being split to {"FOO=foo1", "foo2"}
@@ -246,6 +273,9 @@ func setCreateFlags(cmd *cobra.Command) {
 	cmd.Flags().StringSlice("add-host", nil, "Add a custom host-to-IP mapping (host:ip)")
 	// env-file is defined as StringSlice, not StringArray, to allow specifying "--env-file=FILE1,FILE2" (compatible with Podman)
 	cmd.Flags().StringSlice("env-file", nil, "Set environment variables from file")
+	cmd.Flags().Bool("env-file-expand", false, "Expand ${VAR} references in env files, using variables defined earlier in the files or in the host environment")
+	cmd.Flags().Bool("env-file-strict", false, "Fail on malformed lines in env files instead of passing them through")
+	cmd.Flags().String("tz", "", `Set the timezone of the container, e.g., "Asia/Tokyo", or "local" for the timezone of the host`)
 
 	// #region metadata flags
 	cmd.Flags().String("name", "", "Assign a name to the container")
@@ -260,6 +290,8 @@ func setCreateFlags(cmd *cobra.Command) {
 	// label-file is defined as StringSlice, not StringArray, to allow specifying "--env-file=FILE1,FILE2" (compatible with Podman)
 	cmd.Flags().StringSlice("label-file", nil, "Set metadata on container from file")
 	cmd.Flags().String("cidfile", "", "Write the container ID to the file")
+	cmd.Flags().String("config-out", "", "Write the flags, the image, and the command to the file, to be replayed with --from-config")
+	cmd.Flags().String("from-config", "", "Read the flags, the image, and the command from the file written by --config-out (the flags and the arguments on the command line take precedence)")
 	// #endregion
 
 	// #region logging flags
@@ -288,6 +320,7 @@ func setCreateFlags(cmd *cobra.Command) {
 	// #endregion
 
 	cmd.Flags().String("ipfs-address", "", "multiaddr of IPFS API (default uses $IPFS_PATH env variable if defined or local directory ~/.ipfs)")
+	cmd.Flags().String("ipfs-gateway", "", "HTTP gateway of IPFS (e.g., https://ipfs.io) to fall back to when the IPFS API is not available")
 	cmd.Flags().String("isolation", "default", "Specify isolation technology for container. On Linux the only valid value is default. Windows options are host, process and hyperv with process isolation as the default")
 	cmd.RegisterFlagCompletionFunc("isolation", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if runtime.GOOS == "windows" {
@@ -296,6 +329,9 @@ func setCreateFlags(cmd *cobra.Command) {
 		return []string{"default"}, cobra.ShellCompDirectiveNoFileComp
 	})
 	cmd.Flags().String("userns", "", "Specify host to disable userns-remap")
+	cmd.Flags().StringSlice("preset", nil, "Apply the named presets of the flags defined in nerdctl.toml (the flags on the command line take precedence)")
+	cmd.RegisterFlagCompletionFunc("preset", presetShellComplete)
+	cmd.Flags().Bool("dry-run", false, "Print the plan of the container (the command line with the presets expanded, the OCI spec, the mounts, the networks, and the log config) as JSON, without creating the container")
 
 }
 
@@ -323,6 +359,30 @@ func processCreateCommandFlagsInRun(cmd *cobra.Command) (types.ContainerCreateOp
 	if err != nil {
 		return opt, err
 	}
+	opt.WaitHealthy, err = cmd.Flags().GetBool("wait-healthy")
+	if err != nil {
+		return opt, err
+	}
+	waitPort, err := cmd.Flags().GetString("wait-port")
+	if err != nil {
+		return opt, err
+	}
+	if waitPort != "" {
+		opt.WaitPort, err = container.ParseWaitPort(waitPort)
+		if err != nil {
+			return opt, err
+		}
+	}
+	opt.WaitTimeout, err = cmd.Flags().GetDuration("wait-timeout")
+	if err != nil {
+		return opt, err
+	}
+	if (opt.WaitHealthy || opt.WaitPort != 0) && !opt.Detach {
+		return opt, errors.New("flags --wait-healthy and --wait-port require -d")
+	}
+	if opt.WaitPort != 0 && runtime.GOOS != "linux" {
+		return opt, fmt.Errorf("flag --wait-port is not supported on %s", runtime.GOOS)
+	}
 	opt.Attach, err = cmd.Flags().GetStringSlice("attach")
 	if err != nil {
 		return opt, err
@@ -348,6 +408,10 @@ func processCreateCommandFlagsInRun(cmd *cobra.Command) (types.ContainerCreateOp
 func runAction(cmd *cobra.Command, args []string) error {
 	var isDetached bool
 
+	args, err := applyRunConfig(cmd, args)
+	if err != nil {
+		return err
+	}
 	createOpt, err := processCreateCommandFlagsInRun(cmd)
 	if err != nil {
 		return err
@@ -384,6 +448,9 @@ func runAction(cmd *cobra.Command, args []string) error {
 		}
 		return err
 	}
+	if createOpt.DryRun {
+		return nil
+	}
 	// defer setting `nerdctl/error` label in case of error
 	defer func() {
 		if err != nil {
@@ -420,18 +487,33 @@ func runAction(cmd *cobra.Command, args []string) error {
 		return err
 	}
 	logURI := lab[labels.LogURI]
+	if err := containerutil.MountNetworkVolumes(c, lab); err != nil {
+		return err
+	}
 	detachC := make(chan struct{})
 	task, err := taskutil.NewTask(ctx, client, c, createOpt.Attach, createOpt.Interactive, createOpt.TTY, createOpt.Detach,
 		con, logURI, createOpt.DetachKeys, createOpt.GOptions.Namespace, detachC)
 	if err != nil {
 		return err
 	}
+	if err := containerutil.RunHooks(ctx, c, lab, containerutil.HookStart); err != nil {
+		if _, deleteErr := task.Delete(ctx); deleteErr != nil {
+			log.L.WithError(deleteErr).Debug("failed to delete task")
+		}
+		return err
+	}
 	if err := task.Start(ctx); err != nil {
 		return err
 	}
+	if err := containerutil.RunHooks(ctx, c, lab, containerutil.HookPostStart); err != nil {
+		log.L.WithError(err).Warn("post-start hook failed")
+	}
 
 	if createOpt.Detach {
 		fmt.Fprintln(createOpt.Stdout, id)
+		if createOpt.WaitHealthy || createOpt.WaitPort != 0 {
+			return container.WaitReady(ctx, client, c, createOpt)
+		}
 		return nil
 	}
 	if createOpt.TTY {
@@ -0,0 +1,100 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestRunAdmission(t *testing.T) {
+	dir := t.TempDir()
+	hook := filepath.Join(dir, "hook")
+	// adds a label, and rejects the containers with "reject" in the command
+	err := os.WriteFile(hook, []byte(`#!/bin/sh
+set -eu
+req=$(cat)
+if echo "$req" | grep -q '"reject"'; then
+	echo "rejected by the test hook" >&2
+	exit 1
+fi
+echo "$req" | jq -c '{Allowed: true, Labels: (.Labels + {"com.example.admitted": "true"})}'
+`), 0o755)
+	assert.NilError(t, err)
+	tomlPath := filepath.Join(dir, "nerdctl.toml")
+	err = os.WriteFile(tomlPath, []byte(fmt.Sprintf(`
+[admission]
+deny_privileged = true
+allowed_registries = ["ghcr.io/stargz-containers"]
+hooks = [%q]
+`, hook)), 0o400)
+	assert.NilError(t, err)
+
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.All(
+		require.Not(nerdtest.Docker),
+		require.Binary("jq"),
+	)
+	testCase.Env = map[string]string{
+		"NERDCTL_TOML": tomlPath,
+	}
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("pull", "--quiet", testutil.CommonImage)
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "privileged",
+			Command:     test.Command("run", "--rm", "--privileged", testutil.CommonImage, "true"),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, []error{errors.New("privileged containers are not allowed")}, nil),
+		},
+		{
+			Description: "rejected by the hook",
+			Command:     test.Command("run", "--rm", testutil.CommonImage, "echo", "reject"),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, []error{errors.New("rejected by the test hook")}, nil),
+		},
+		{
+			Description: "mutated by the hook",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("create", "--name", data.Identifier(), testutil.CommonImage)
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("container", "inspect", "--format", `{{index .Config.Labels "com.example.admitted"}}`, data.Identifier())
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Equals("true\n")),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -709,3 +709,51 @@ func TestRunCPURealTimeSettingCgroupV1(t *testing.T) {
 
 	testCase.Run(t)
 }
+
+func TestRunNumaNode(t *testing.T) {
+	nodeCPUs, err := os.ReadFile("/sys/devices/system/node/node0/cpulist")
+	if err != nil {
+		t.Skip("test requires NUMA node 0 in sysfs")
+	}
+
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		helpers.Ensure("create", "--name", data.Identifier(), "--numa-node", "0", testutil.AlpineImage, "sleep", nerdtest.Infinity)
+		return helpers.Command("inspect", "--format", "{{.HostConfig.CPUSetCPUs}} {{.HostConfig.CPUSetMems}}", data.Identifier())
+	}
+
+	testCase.Expected = test.Expects(expect.ExitCodeSuccess, nil, expect.Equals(strings.TrimSpace(string(nodeCPUs))+" 0\n"))
+
+	testCase.Run(t)
+}
+
+func TestRunMemorySwappinessCgroupV2(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.All(
+		require.Not(nerdtest.Docker),
+		nerdtest.CGroupV2,
+	)
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		helpers.Ensure("create", "--name", data.Identifier(), "--memory-swappiness", "10", "--memory-reservation", "6m",
+			testutil.AlpineImage, "sleep", nerdtest.Infinity)
+		return helpers.Command("inspect", "--format", "{{.HostConfig.MemorySwappiness}} {{.HostConfig.MemoryReservation}}", data.Identifier())
+	}
+
+	// cgroup v2 has no memory.swappiness, so the flag is discarded
+	testCase.Expected = test.Expects(expect.ExitCodeSuccess, nil, expect.Equals("<nil> 6291456\n"))
+
+	testCase.Run(t)
+}
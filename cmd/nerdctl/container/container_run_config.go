@@ -0,0 +1,96 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+)
+
+// imageArgsOrFromConfig requires the image argument, unless --from-config is specified.
+func imageArgsOrFromConfig(cmd *cobra.Command, args []string) error {
+	if cmd.Flags().Changed("from-config") {
+		return nil
+	}
+	return cobra.MinimumNArgs(1)(cmd, args)
+}
+
+// applyRunConfig sets the flags of the file of --from-config that are not specified on the command line,
+// and returns the image and the command of the file when args is empty.
+func applyRunConfig(cmd *cobra.Command, args []string) ([]string, error) {
+	if !cmd.Flags().Changed("from-config") {
+		return args, nil
+	}
+	path, err := cmd.Flags().GetString("from-config")
+	if err != nil {
+		return nil, err
+	}
+	rc, err := container.ReadRunConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make(map[string]bool)
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		changed[f.Name] = true
+	})
+	for _, kv := range rc.Flags {
+		name, value, ok := strings.Cut(strings.TrimPrefix(kv, "--"), "=")
+		if !ok || !strings.HasPrefix(kv, "--") {
+			return nil, fmt.Errorf("invalid flag %q in %s, expected --KEY=VALUE", kv, path)
+		}
+		if changed[name] {
+			continue
+		}
+		f := cmd.Flags().Lookup(name)
+		if f == nil {
+			// e.g., the flags only available in `nerdctl run`
+			log.L.Warnf("ignoring the flag %q of %s, not supported by `nerdctl %s`", "--"+name, path, cmd.Name())
+			continue
+		}
+		// the values of the slices are recorded one by one, so they must not be split again on commas
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			if !f.Changed {
+				err = sv.Replace([]string{value})
+			} else {
+				err = sv.Append(value)
+			}
+			f.Changed = true
+		} else {
+			err = cmd.Flags().Set(name, value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid flag %q in %s: %w", kv, path, err)
+		}
+	}
+
+	if len(args) > 0 {
+		return args, nil
+	}
+	if rc.Image == "" {
+		return nil, errors.New("no image in " + path)
+	}
+	return append([]string{rc.Image}, rc.Args...), nil
+}
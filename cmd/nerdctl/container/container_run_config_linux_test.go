@@ -0,0 +1,59 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestRunConfigOutFromConfig(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		config := filepath.Join(data.Temp().Path(), "run.json")
+		helpers.Ensure("run", "-d", "--name", data.Identifier("orig"), "--config-out", config,
+			"--env", "FOO=a,b", "--label", "foo=bar", "--label", "baz=qux",
+			testutil.CommonImage, "sh", "-c", `echo "$FOO" && sleep `+nerdtest.Infinity)
+		data.Labels().Set("config", config)
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier("orig"), data.Identifier("replay"))
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		// the flags on the command line take precedence over the file
+		helpers.Ensure("create", "--from-config", data.Labels().Get("config"), "--name", data.Identifier("replay"), "--label", "foo=replayed")
+		return helpers.Command("inspect", "--format",
+			`{{index .Config.Labels "foo"}} {{index .Config.Labels "baz"}} {{range .Config.Env}}{{.}} {{end}}{{join .Config.Cmd " "}}`,
+			data.Identifier("replay"))
+	}
+
+	testCase.Expected = test.Expects(0, nil, expect.Contains("replayed qux", "FOO=a,b", `sh -c echo "$FOO" && sleep`))
+
+	testCase.Run(t)
+}
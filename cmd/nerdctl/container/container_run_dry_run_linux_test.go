@@ -0,0 +1,98 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+	"github.com/containerd/nerdctl/mod/tigron/tig"
+
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestRunDryRun(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("pull", "--quiet", testutil.CommonImage)
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "the plan is printed",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--dry-run", "--name", data.Identifier(), "--env=FOO=bar", "-v", "/data",
+					"--log-opt=max-size=1m", testutil.CommonImage, "echo", "foo")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return test.Expects(expect.ExitCodeSuccess, nil, expect.JSON(container.DryRunPlan{}, func(plan container.DryRunPlan, info string, t tig.T) {
+					assert.Equal(t, plan.Name, data.Identifier(), info)
+					assert.Assert(t, slices.Contains(plan.Spec.Process.Env, "FOO=bar"), info)
+					assert.DeepEqual(t, plan.Spec.Process.Args, []string{"echo", "foo"})
+					assert.Assert(t, slices.ContainsFunc(plan.Mounts, func(m dockercompat.MountPoint) bool {
+						return m.Type == "volume" && m.Destination == "/data"
+					}), info)
+					assert.Equal(t, len(plan.Networks), 1, info)
+					assert.Equal(t, plan.Networks[0].Name, "bridge", info)
+					assert.Assert(t, len(plan.Networks[0].Config) > 0, info)
+					assert.Equal(t, plan.LogConfig.Driver, "json-file", info)
+					assert.Equal(t, plan.LogConfig.Opts["max-size"], "1m", info)
+				}))(data, helpers)
+			},
+		},
+		{
+			Description: "the container is not created",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("create", "--dry-run", "--name", data.Identifier(), testutil.CommonImage)
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("container", "inspect", data.Identifier())
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("no such container")}, nil),
+		},
+		{
+			Description: "the name is not reserved",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("create", "--dry-run", "--name", data.Identifier(), testutil.CommonImage)
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("create", "--name", data.Identifier(), testutil.CommonImage)
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
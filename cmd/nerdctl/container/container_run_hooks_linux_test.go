@@ -0,0 +1,88 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestRunHooks(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "hooks run on start, post-start, and pre-stop",
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Setup: func(data test.Data, helpers test.Helpers) {
+				hookLog := data.Temp().Path("hook.log")
+				data.Labels().Set("hookLog", hookLog)
+				helpers.Ensure("run", "-d", "--name", data.Identifier(),
+					"--on-start", `echo "$NERDCTL_HOOK_EVENT $NERDCTL_CONTAINER_NAME" >> `+hookLog,
+					"--on-post-start", "exec:touch /tmp/post-started",
+					"--on-post-start", `echo "$NERDCTL_HOOK_EVENT" >> `+hookLog,
+					"--on-stop", `echo "$NERDCTL_HOOK_EVENT" >> `+hookLog,
+					testutil.CommonImage, "sleep", nerdtest.Infinity)
+				nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+				helpers.Ensure("exec", data.Identifier(), "test", "-f", "/tmp/post-started")
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("stop", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout, info string, t *testing.T) {
+						b, err := os.ReadFile(data.Labels().Get("hookLog"))
+						assert.NilError(t, err)
+						assert.Equal(t, string(b), "start "+data.Identifier()+"\npost-start\npre-stop\n")
+					},
+				}
+			},
+		},
+		{
+			Description: "failing start hook aborts the start",
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "-d", "--name", data.Identifier(), "--on-start", "exit 1", testutil.CommonImage, "sleep", nerdtest.Infinity)
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New(`start hook "exit 1"`)}, nil),
+		},
+		{
+			Description: "exec hook is not supported on start",
+			Command:     test.Command("run", "--rm", "--on-start", "exec:true", testutil.CommonImage, "true"),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, []error{errors.New("not supported")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
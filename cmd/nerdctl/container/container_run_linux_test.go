@@ -311,6 +311,74 @@ func TestRunWithInit(t *testing.T) {
 	assert.Equal(t, base.InspectContainer(container2).State.ExitCode, 143)
 }
 
+func TestRunWithInitConfig(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "init in nerdctl.toml",
+			Config:      test.WithConfig(nerdtest.NerdctlToml, `init = true`),
+			Command:     test.Command("run", "--rm", testutil.AlpineImage, "cat", "/proc/1/comm"),
+			Expected:    test.Expects(0, nil, expect.Equals("tini\n")),
+		},
+		{
+			Description: "--init=false overrides nerdctl.toml",
+			Config:      test.WithConfig(nerdtest.NerdctlToml, `init = true`),
+			Command:     test.Command("run", "--rm", "--init=false", testutil.AlpineImage, "cat", "/proc/1/comm"),
+			Expected:    test.Expects(0, nil, expect.Equals("cat\n")),
+		},
+		{
+			Description: "init_binary in nerdctl.toml",
+			Config: test.WithConfig(nerdtest.NerdctlToml, `init = true
+init_binary = "tini-custom"`),
+			Command:  test.Command("run", "--rm", testutil.AlpineImage, "cat", "/proc/1/comm"),
+			Expected: test.Expects(0, nil, expect.Equals("tini-custom\n")),
+		},
+		{
+			Description: "nonexistent init binary",
+			Command:     test.Command("run", "--rm", "--init-binary", "nonexistent-init", testutil.AlpineImage, "true"),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, []error{errors.New(`init binary "nonexistent-init" not found`)}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestRunWithTimezone(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.SubTests = []*test.Case{
+		{
+			// The alpine image has no tz database
+			Description: "zone file",
+			Command:     test.Command("run", "--rm", "--tz", "Asia/Tokyo", testutil.AlpineImage, "date", "+%Z"),
+			Expected:    test.Expects(0, nil, expect.Equals("JST\n")),
+		},
+		{
+			Description: "POSIX TZ string",
+			Command:     test.Command("run", "--rm", "--tz", "ABC-9", testutil.AlpineImage, "sh", "-c", "echo $TZ; date +%Z"),
+			Expected:    test.Expects(0, nil, expect.Equals("ABC-9\nABC\n")),
+		},
+		{
+			Description: "tz in nerdctl.toml",
+			Config:      test.WithConfig(nerdtest.NerdctlToml, `tz = "Asia/Tokyo"`),
+			Command:     test.Command("run", "--rm", testutil.AlpineImage, "date", "+%Z"),
+			Expected:    test.Expects(0, nil, expect.Equals("JST\n")),
+		},
+		{
+			Description: "unknown timezone",
+			Command:     test.Command("run", "--rm", "--tz", "Mars/Olympus_Mons", testutil.AlpineImage, "true"),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, []error{errors.New(`unknown timezone "Mars/Olympus_Mons"`)}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
+
 func TestRunTTY(t *testing.T) {
 	const sttyPartialOutput = "speed 38400 baud"
 
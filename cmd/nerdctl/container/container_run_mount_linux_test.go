@@ -28,6 +28,7 @@ import (
 
 	"github.com/containerd/containerd/v2/core/mount"
 	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
 	"github.com/containerd/nerdctl/mod/tigron/test"
 
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
@@ -283,6 +284,49 @@ func TestRunTmpfs(t *testing.T) {
 	base.Cmd("run", "--rm", "--tmpfs", "/tmp:size=64m,exec", testutil.AlpineImage, "grep", "/tmp", "/proc/mounts").AssertOutWithFunc(f([]string{"rw", "nosuid", "nodev", "size=65536k"}, []string{"noexec"}))
 	// for https://github.com/containerd/nerdctl/issues/594
 	base.Cmd("run", "--rm", "--tmpfs", "/dev/shm:rw,exec,size=1g", testutil.AlpineImage, "grep", "/dev/shm", "/proc/mounts").AssertOutWithFunc(f([]string{"rw", "nosuid", "nodev", "size=1048576k"}, []string{"noexec"}))
+	base.Cmd("run", "--rm", "--mount", "type=tmpfs,dst=/tmp,exec,tmpfs-size=64m", testutil.AlpineImage, "grep", "/tmp", "/proc/mounts").AssertOutWithFunc(f([]string{"rw", "nosuid", "nodev", "size=65536k"}, []string{"noexec"}))
+	base.Cmd("run", "--rm", "--mount", "type=tmpfs,dst=/tmp,tmpfs-uid=1000,tmpfs-gid=100,tmpfs-mode=700", testutil.AlpineImage, "stat", "-c", "%u:%g:%a", "/tmp").AssertOutExactly("1000:100:700\n")
+}
+
+func TestRunMountImage(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	// Docker does not support writable image mounts
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "image is mounted read-only",
+			Command: test.Command("run", "--rm",
+				"--mount", "type=image,source="+testutil.AlpineImage+",target=/opt/alpine",
+				testutil.CommonImage, "sh", "-euc", "cat /opt/alpine/etc/os-release; ! touch /opt/alpine/foo"),
+			Expected: test.Expects(0, nil, expect.Contains("Alpine")),
+		},
+		{
+			Description: "rw image mount gets a writable layer",
+			Command: test.Command("run", "--rm",
+				"--mount", "type=image,source="+testutil.AlpineImage+",target=/opt/alpine,rw=true",
+				testutil.CommonImage, "sh", "-euc", "touch /opt/alpine/foo && ls /opt/alpine/foo"),
+			Expected: test.Expects(0, nil, expect.Contains("/opt/alpine/foo")),
+		},
+		{
+			Description: "image mount is shown in inspect",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("create", "--name", data.Identifier(),
+					"--mount", "type=image,src="+testutil.AlpineImage+",dst=/opt/alpine",
+					testutil.CommonImage)
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("inspect", "--format", "{{range .Mounts}}{{.Type}} {{.Destination}} {{.RW}}{{end}}", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Equals("image /opt/alpine false\n")),
+		},
+	}
+
+	testCase.Run(t)
 }
 
 func TestRunBindMountTmpfs(t *testing.T) {
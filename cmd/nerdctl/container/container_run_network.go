@@ -161,5 +161,40 @@ func loadNetworkFlags(cmd *cobra.Command) (types.NetworkOptions, error) {
 	}
 	netOpts.PortMappings = portMappings
 
+	// -P/--publish-all
+	publishAll, err := cmd.Flags().GetBool("publish-all")
+	if err != nil {
+		return netOpts, err
+	}
+	netOpts.PublishAll = publishAll
+
+	// --network-bandwidth=<rate>
+	networkBandwidth, err := cmd.Flags().GetString("network-bandwidth")
+	if err != nil {
+		return netOpts, err
+	}
+	netOpts.NetworkBandwidth = networkBandwidth
+
+	// --network-delay=<duration>
+	networkDelay, err := cmd.Flags().GetString("network-delay")
+	if err != nil {
+		return netOpts, err
+	}
+	netOpts.NetworkDelay = networkDelay
+
+	// --userland-proxy
+	userlandProxy, err := cmd.Flags().GetBool("userland-proxy")
+	if err != nil {
+		return netOpts, err
+	}
+	netOpts.UserlandProxy = userlandProxy
+
+	// --allow-from=<CIDR>
+	allowFrom, err := cmd.Flags().GetStringArray("allow-from")
+	if err != nil {
+		return netOpts, err
+	}
+	netOpts.AllowFrom = strutil.DedupeStrSlice(allowFrom)
+
 	return netOpts, nil
 }
@@ -0,0 +1,121 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+)
+
+// applyPresets expands the presets specified with `--preset` into the flags of the command.
+func applyPresets(cmd *cobra.Command) error {
+	names, err := cmd.Flags().GetStringSlice("preset")
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	tomlPath := helpers.NerdctlTOMLPath()
+	cfg, err := helpers.LoadNerdctlTOML(tomlPath)
+	if err != nil {
+		return err
+	}
+	var args []string
+	for _, name := range names {
+		preset, ok := cfg.Presets[name]
+		if !ok {
+			return fmt.Errorf("preset %q is not defined in %q", name, tomlPath)
+		}
+		args = append(args, preset...)
+	}
+	if err = parsePresetFlags(cmd.LocalFlags(), args, cmd.ErrOrStderr()); err != nil {
+		return fmt.Errorf("failed to apply the presets %v: %w", names, err)
+	}
+	return nil
+}
+
+// parsePresetFlags parses args into flags, as if args were specified before the flags on the command line:
+// the scalar flags already specified are kept, and the values of the slice flags already specified
+// are appended after the values of args.
+func parsePresetFlags(flags *pflag.FlagSet, args []string, stderr io.Writer) error {
+	fs := pflag.NewFlagSet("preset", pflag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.SetNormalizeFunc(flags.GetNormalizeFunc())
+	specified := make(map[*pflag.Flag][]string)
+	flags.VisitAll(func(f *pflag.Flag) {
+		switch {
+		case slices.Contains([]string{"help", "preset", "dry-run"}, f.Name):
+			// not allowed in presets
+		case !f.Changed:
+			fs.AddFlag(f)
+		default:
+			if sv, ok := f.Value.(pflag.SliceValue); ok {
+				specified[f] = sv.GetSlice()
+				fs.AddFlag(f)
+				return
+			}
+			kept := *f
+			kept.Value = &ignoredValue{typ: f.Value.Type()}
+			fs.AddFlag(&kept)
+		}
+	})
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("presets must consist of flags, got %q", fs.Args())
+	}
+	for f, values := range specified {
+		sv := f.Value.(pflag.SliceValue)
+		all := sv.GetSlice()
+		if err := sv.Replace(append(all[len(values):], values...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ignoredValue is a flag value that ignores the values set.
+type ignoredValue struct {
+	typ string
+}
+
+func (v *ignoredValue) String() string { return "" }
+
+func (v *ignoredValue) Set(string) error { return nil }
+
+func (v *ignoredValue) Type() string { return v.typ }
+
+func presetShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := helpers.LoadNerdctlTOML(helpers.NerdctlTOMLPath())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var candidates []string
+	for name := range cfg.Presets {
+		candidates = append(candidates, name)
+	}
+	slices.Sort(candidates)
+	return candidates, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,96 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+	"github.com/containerd/nerdctl/mod/tigron/tig"
+
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestRunPreset(t *testing.T) {
+	tomlPath := filepath.Join(t.TempDir(), "nerdctl.toml")
+	err := os.WriteFile(tomlPath, []byte(`
+[presets]
+hardened = ["--read-only", "--cap-drop=ALL", "--security-opt=no-new-privileges", "--env=FOO=preset"]
+restart = ["--restart=always"]
+invalid = ["--read-only", "foo"]
+`), 0400)
+	assert.NilError(t, err)
+
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+	testCase.Env = map[string]string{
+		"NERDCTL_TOML": tomlPath,
+	}
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("pull", "--quiet", testutil.CommonImage)
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "dry-run expands the presets",
+			Command:     test.Command("run", "--preset=hardened", "--dry-run", "--env=FOO=cli", testutil.CommonImage, "echo", "foo bar"),
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.JSON(container.DryRunPlan{}, func(plan container.DryRunPlan, info string, t tig.T) {
+				assert.Equal(t, plan.Command,
+					"nerdctl run --cap-drop=ALL --env=FOO=preset --env=FOO=cli --read-only=true --security-opt=no-new-privileges "+testutil.CommonImage+" echo 'foo bar'", info)
+				assert.Assert(t, plan.Spec.Root.Readonly, info)
+				assert.Equal(t, plan.Spec.Process.Env[len(plan.Spec.Process.Env)-1], "FOO=cli", info)
+			})),
+		},
+		{
+			Description: "flags on the command line take precedence",
+			Command:     test.Command("create", "--preset=restart", "--restart=no", "--dry-run", testutil.CommonImage),
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.JSON(container.DryRunPlan{}, func(plan container.DryRunPlan, info string, t tig.T) {
+				assert.Assert(t, strings.Contains(plan.Command, "--restart=no"), info)
+				assert.Assert(t, !strings.Contains(plan.Command, "always"), info)
+			})),
+		},
+		{
+			Description: "undefined preset",
+			Command:     test.Command("run", "--preset=undefined", "--dry-run", testutil.CommonImage),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, []error{errors.New(`preset "undefined" is not defined`)}, nil),
+		},
+		{
+			Description: "preset with arguments",
+			Command:     test.Command("run", "--preset=invalid", "--dry-run", testutil.CommonImage),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, []error{errors.New("presets must consist of flags")}, nil),
+		},
+		{
+			Description: "run with the preset",
+			Command:     test.Command("run", "--rm", "--preset=hardened", testutil.CommonImage, "sh", "-euc", "echo $FOO; touch /foo || echo read-only"),
+			Expected:    test.Expects(expect.ExitCodeSuccess, nil, expect.Equals("preset\nread-only\n")),
+		},
+	}
+
+	testCase.Run(t)
+}
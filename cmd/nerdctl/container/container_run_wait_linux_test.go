@@ -0,0 +1,82 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestRunWaitReady(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	cleanup := func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "port accepts connections",
+			Cleanup:     cleanup,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "-d", "--name", data.Identifier(), "--wait-port", "80", testutil.NginxAlpineImage)
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, nil),
+		},
+		{
+			Description: "port times out",
+			Cleanup:     cleanup,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "-d", "--name", data.Identifier(), "--wait-port", "8080/tcp", "--wait-timeout", "3s",
+					testutil.CommonImage, "sleep", nerdtest.Infinity)
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("timed out")}, nil),
+		},
+		{
+			Description: "container exits",
+			Cleanup:     cleanup,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "-d", "--name", data.Identifier(), "--wait-port", "80", testutil.CommonImage, "true")
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("exited before becoming ready")}, nil),
+		},
+		{
+			Description: "image without healthcheck",
+			Cleanup:     cleanup,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "-d", "--name", data.Identifier(), "--wait-healthy", testutil.CommonImage, "sleep", nerdtest.Infinity)
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("no HEALTHCHECK")}, nil),
+		},
+		{
+			Description: "requires detach",
+			Command:     test.Command("run", "--rm", "--wait-port", "80", testutil.CommonImage, "true"),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, []error{errors.New("require -d")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
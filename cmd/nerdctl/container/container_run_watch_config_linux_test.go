@@ -0,0 +1,87 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"testing"
+	"time"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestRunWatchConfig(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "signal",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				confDir := data.Temp().Dir("conf")
+				data.Temp().Save("foo", "conf", "app.conf")
+				helpers.Ensure("run", "-d", "--name", data.Identifier(),
+					"-v", confDir+":/etc/app",
+					"--watch-config", "/etc/app/app.conf", "--watch-config-signal", "SIGHUP",
+					testutil.CommonImage, "sh", "-c", `trap "echo reloaded" HUP; echo ready; while true; do sleep 0.1; done`)
+				nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+				data.Temp().Save("bar", "conf", "app.conf")
+				time.Sleep(3 * time.Second)
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", data.Identifier())
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Equals("ready\nreloaded\n")),
+		},
+		{
+			Description: "restart",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				confDir := data.Temp().Dir("conf")
+				outDir := data.Temp().Dir("out")
+				helpers.Ensure("run", "-d", "--name", data.Identifier(),
+					"-v", confDir+":/etc/app", "-v", outDir+":/out",
+					"--watch-config", "/etc/app",
+					testutil.CommonImage, "sh", "-c", "echo started >> /out/log; sleep "+nerdtest.Infinity)
+				nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+				data.Temp().Save("foo", "conf", "app.conf")
+				time.Sleep(5 * time.Second)
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Custom("cat", data.Temp().Path("out", "log"))
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Equals("started\nstarted\n")),
+		},
+		{
+			Description: "not in a bind mount",
+			Command:     test.Command("run", "--rm", "--watch-config", "/etc/hostname", testutil.CommonImage, "true"),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -17,6 +17,8 @@
 package container
 
 import (
+	"errors"
+
 	"github.com/spf13/cobra"
 
 	containerd "github.com/containerd/containerd/v2/client"
@@ -27,6 +29,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
 	"github.com/containerd/nerdctl/v2/pkg/consoleutil"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 )
 
 func StartCommand() *cobra.Command {
@@ -64,6 +67,9 @@ func startOptions(cmd *cobra.Command) (types.ContainerStartOptions, error) {
 	if err != nil {
 		return types.ContainerStartOptions{}, err
 	}
+	if attach && globalOptions.Output == formatter.OutputJSON {
+		return types.ContainerStartOptions{}, errors.New("--attach cannot be used with --output json")
+	}
 	return types.ContainerStartOptions{
 		Stdout:      cmd.OutOrStdout(),
 		GOptions:    globalOptions,
@@ -85,7 +91,9 @@ func startAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
-	return container.Start(ctx, client, args, options)
+	stdout, finish := helpers.OutputWriter(cmd, options.GOptions, formatter.KindContainerStart, formatter.ItemsFromTextLines)
+	options.Stdout = stdout
+	return finish(container.Start(ctx, client, args, options))
 }
 
 func startShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -17,8 +17,6 @@
 package container
 
 import (
-	"time"
-
 	"github.com/spf13/cobra"
 
 	containerd "github.com/containerd/containerd/v2/client"
@@ -28,20 +26,25 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 )
 
 func StopCommand() *cobra.Command {
 	var cmd = &cobra.Command{
 		Use:               "stop [flags] CONTAINER [CONTAINER, ...]",
-		Args:              cobra.MinimumNArgs(1),
+		Args:              helpers.ContainerArgsOrFilter,
 		Short:             "Stop one or more running containers",
 		RunE:              stopAction,
 		ValidArgsFunction: stopShellComplete,
 		SilenceUsage:      true,
 		SilenceErrors:     true,
 	}
-	cmd.Flags().IntP("time", "t", 10, "Seconds to wait before sending a SIGKILL")
-	cmd.Flags().StringP("signal", "s", "SIGTERM", "Signal to send to the container")
+	cmd.Flags().IntP("timeout", "t", 10, "Seconds to wait before sending a SIGKILL, -1 to wait indefinitely (default: --stop-timeout of the container, or 10)")
+	cmd.Flags().Int("time", 10, "Seconds to wait before sending a SIGKILL")
+	cmd.Flags().MarkDeprecated("time", "use --timeout instead")
+	cmd.Flags().StringP("signal", "s", "", "Signal to send to the container (default: --stop-signal of the container, or STOPSIGNAL of the image, or SIGTERM)")
+	helpers.AddParallelFlag(cmd)
+	helpers.AddBatchFilterFlags(cmd)
 	return cmd
 }
 
@@ -50,14 +53,9 @@ func stopOptions(cmd *cobra.Command) (types.ContainerStopOptions, error) {
 	if err != nil {
 		return types.ContainerStopOptions{}, err
 	}
-	var timeout *time.Duration
-	if cmd.Flags().Changed("time") {
-		timeValue, err := cmd.Flags().GetInt("time")
-		if err != nil {
-			return types.ContainerStopOptions{}, err
-		}
-		t := time.Duration(timeValue) * time.Second
-		timeout = &t
+	timeout, err := helpers.ProcessStopTimeoutFlag(cmd)
+	if err != nil {
+		return types.ContainerStopOptions{}, err
 	}
 	var signal string
 	if cmd.Flags().Changed("signal") {
@@ -67,12 +65,17 @@ func stopOptions(cmd *cobra.Command) (types.ContainerStopOptions, error) {
 		}
 		signal = signalValue
 	}
+	parallel, err := helpers.ProcessParallelFlag(cmd)
+	if err != nil {
+		return types.ContainerStopOptions{}, err
+	}
 	return types.ContainerStopOptions{
 		Stdout:   cmd.OutOrStdout(),
 		Stderr:   cmd.ErrOrStderr(),
 		GOptions: globalOptions,
 		Timeout:  timeout,
 		Signal:   signal,
+		Parallel: parallel,
 	}, nil
 }
 
@@ -88,7 +91,14 @@ func stopAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
-	return container.Stop(ctx, client, args, options)
+	args, err = appendFilterTargets(ctx, cmd, client, args)
+	if err != nil {
+		return err
+	}
+
+	stdout, finish := helpers.OutputWriter(cmd, options.GOptions, formatter.KindContainerStop, formatter.ItemsFromTextLines)
+	options.Stdout = stdout
+	return finish(container.Stop(ctx, client, args, options))
 }
 
 func stopShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
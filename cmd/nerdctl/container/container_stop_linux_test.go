@@ -96,6 +96,80 @@ func TestStopWithStopSignal(t *testing.T) {
 	testCase.Run(t)
 }
 
+func TestStopSignalAndTimeoutConfig(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "STOPSIGNAL of the image",
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("create", "--name", data.Identifier(), testutil.NginxAlpineImage)
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("inspect", "--format", "{{.Config.StopSignal}} {{.Config.StopTimeout}}", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Equals("SIGQUIT <nil>\n")),
+		},
+		{
+			Description: "--stop-signal and --stop-timeout take precedence over the image",
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("create", "--name", data.Identifier(), "--stop-signal", "SIGUSR1", "--stop-timeout", "5", testutil.NginxAlpineImage)
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("inspect", "--format", "{{.Config.StopSignal}} {{.Config.StopTimeout}}", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Equals("SIGUSR1 5\n")),
+		},
+		{
+			Description: "invalid --stop-signal",
+			Command:     test.Command("create", "--stop-signal", "SIGNOPE", testutil.CommonImage),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestStopTimeoutFlag(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		// sleep as PID 1 ignores SIGTERM, and the stop timeout of the container is long.
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), "--stop-timeout", "60", testutil.CommonImage, "sleep", nerdtest.Infinity)
+		nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		start := time.Now()
+		helpers.Ensure("stop", "--timeout", "1", data.Identifier())
+		data.Labels().Set("elapsed", time.Since(start).String())
+		return helpers.Command("inspect", "--format", "{{.State.ExitCode}}", data.Identifier())
+	}
+
+	testCase.Expected = func(data test.Data, helpers test.Helpers) *test.Expected {
+		return &test.Expected{
+			Output: func(stdout, info string, t *testing.T) {
+				assert.Equal(t, stdout, "137\n", info)
+				elapsed, err := time.ParseDuration(data.Labels().Get("elapsed"))
+				assert.NilError(t, err)
+				assert.Assert(t, elapsed < 30*time.Second, "stop took %s", elapsed)
+			},
+		}
+	}
+
+	testCase.Run(t)
+}
+
 func TestStopCleanupForwards(t *testing.T) {
 	const (
 		hostPort          = 9999
@@ -26,6 +26,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 )
 
 func UnpauseCommand() *cobra.Command {
@@ -64,7 +65,9 @@ func unpauseAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
-	return container.Unpause(ctx, client, args, options)
+	stdout, finish := helpers.OutputWriter(cmd, options.GOptions, formatter.KindContainerUnpause, formatter.ItemsFromTextLines)
+	options.Stdout = stdout
+	return finish(container.Unpause(ctx, client, args, options))
 }
 
 func unpauseShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -0,0 +1,249 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/contextstore"
+)
+
+// resolveContext returns the context specified by `--context` ($NERDCTL_CONTEXT), or the current context.
+// The contexts are stored next to nerdctl.toml.
+// The subcommands of `nerdctl context` always use the default context, so that a broken context can be fixed.
+// The subcommands of `nerdctl machine` manage the virtual machines on the host, even when the current context points to a machine.
+// The internal subcommands such as the OCI hook receive the effective global flags, so they do not need the context.
+func resolveContext(cmd *cobra.Command, tomlPath string) (*contextstore.Context, error) {
+	if commands := commandNames(cmd); len(commands) >= 2 && (commands[1] == "context" || commands[1] == "machine" || commands[1] == "internal") {
+		return &contextstore.Context{Name: contextstore.DefaultName}, nil
+	}
+	name, err := cmd.Flags().GetString("context")
+	if err != nil {
+		return nil, err
+	}
+	nctx, err := contextstore.Resolve(filepath.Dir(tomlPath), name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the context (Hint: see `nerdctl context ls`): %w", err)
+	}
+	return nctx, nil
+}
+
+// commandNames returns the names of the command and its parents, starting from the root command.
+func commandNames(cmd *cobra.Command) []string {
+	var commands []string
+	for tcmd := cmd; tcmd != nil; tcmd = tcmd.Parent() {
+		commands = append([]string{tcmd.Name()}, commands...)
+	}
+	return commands
+}
+
+// applyContext applies the context to the global flags that are not specified by the flags or the environment variables.
+// So the order of precedence is: flags, environment variables, the context, and nerdctl.toml.
+func applyContext(cmd *cobra.Command, nctx *contextstore.Context) error {
+	for _, f := range []struct {
+		names []string // the flag and the aliases
+		env   string
+		value string
+	}{
+		{[]string{"address", "a", "H", "host"}, "CONTAINERD_ADDRESS", nctx.Address},
+		{[]string{"namespace", "n"}, "CONTAINERD_NAMESPACE", nctx.Namespace},
+		{[]string{"snapshotter", "storage-driver"}, "CONTAINERD_SNAPSHOTTER", nctx.Snapshotter},
+	} {
+		if f.value == "" {
+			continue
+		}
+		if _, ok := os.LookupEnv(f.env); ok {
+			continue
+		}
+		changed := false
+		for _, name := range f.names {
+			if fl := cmd.Root().Flags().Lookup(name); fl != nil && fl.Changed {
+				changed = true
+			}
+			if fl := cmd.Flags().Lookup(name); fl != nil && fl.Changed {
+				changed = true
+			}
+		}
+		if changed {
+			continue
+		}
+		if err := cmd.Flags().Set(f.names[0], f.value); err != nil {
+			return fmt.Errorf("failed to apply context %q: %w", nctx.Name, err)
+		}
+	}
+	return nil
+}
+
+// appNeedsContextDelegation returns true when the command has to be executed by another nerdctl process
+// (on the remote host over SSH, or with sudo) according to the context.
+func appNeedsContextDelegation(cmd *cobra.Command, nctx *contextstore.Context) bool {
+	if !nctx.Delegated() {
+		return false
+	}
+	commands := commandNames(cmd)
+	if len(commands) < 2 {
+		return false
+	}
+	switch commands[1] {
+	// completion, help: false, because they do not interact with containerd
+	case "completion", "help":
+		return false
+	}
+	return true
+}
+
+// appNeedsSSHDelegation returns true when the command cannot be executed with the containerd socket forwarded over SSH,
+// as it needs the filesystem of the containerd host, e.g., for the FIFOs of the container IO, the OCI hooks, and
+// the state of the containers, the networks, and the volumes.
+// Such commands are executed by nerdctl on the remote host.
+func appNeedsSSHDelegation(cmd *cobra.Command) bool {
+	commands := commandNames(cmd)
+	if len(commands) < 2 {
+		return false
+	}
+	var sub string
+	if len(commands) >= 3 {
+		sub = commands[2]
+	}
+	switch commands[1] {
+	// executed locally, regardless of the address
+	case "completion", "help", "context", "machine", "login", "logout", "apparmor", "ipfs", "internal":
+		return false
+	// only use the APIs of containerd and BuildKit.
+	// compose parses the project locally, and executes the other commands as subprocesses.
+	case "ps", "inspect", "stop", "kill", "pause", "unpause", "wait", "port", "stats", "commit",
+		"images", "pull", "push", "load", "save", "tag", "rmi", "history", "build",
+		"events", "info", "version", "compose":
+		return false
+	case "container":
+		switch sub {
+		case "ls", "inspect", "stop", "kill", "pause", "unpause", "wait", "port", "stats", "commit":
+			return false
+		}
+	case "image":
+		switch sub {
+		// record-access: needs fanotify on the host
+		// soci, nydusify: execute the converters on the host
+		case "record-access", "soci", "nydusify":
+			return true
+		}
+		return false
+	case "system":
+		switch sub {
+		case "info", "events":
+			return false
+		}
+	case "builder":
+		switch sub {
+		case "build", "prune", "debug":
+			return false
+		}
+	}
+	return true
+}
+
+// sshAddressContext returns the context for executing the commands on the remote host of the SSH address.
+func sshAddressContext(globalOptions types.GlobalCommandOptions) (*contextstore.Context, error) {
+	d, socket, err := clientutil.SSHSocket(globalOptions.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &contextstore.Context{
+		Name:        globalOptions.Address,
+		Address:     socket,
+		Namespace:   globalOptions.Namespace,
+		Snapshotter: globalOptions.Snapshotter,
+		SSH:         d.String(),
+	}, nil
+}
+
+// delegate executes the command with the context, i.e., on the remote host over SSH, or with sudo.
+// The environment variables are not propagated to the remote host.
+func delegate(nctx *contextstore.Context, cmd *cobra.Command, args []string, excludedFlags ...string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	tty := term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+	argv, err := nctx.DelegateArgs(self, commandLine(cmd, args, excludedFlags...), tty)
+	if err != nil {
+		return err
+	}
+	log.L.Debugf("Executing %v for %q", argv, nctx.Name)
+	c := exec.Command(argv[0], argv[1:]...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	// *exec.ExitError is handled by errutil.HandleExitCoder to propagate the exit code
+	return c.Run()
+}
+
+// commandLine reconstructs the arguments of the command line from the parsed flags and args of cmd,
+// without the excluded flags.
+func commandLine(cmd *cobra.Command, args []string, excludedFlags ...string) []string {
+	var res []string
+	appendFlags := func(flags *pflag.FlagSet) {
+		flags.VisitAll(func(f *pflag.Flag) {
+			if !f.Changed || slices.Contains(excludedFlags, f.Name) {
+				return
+			}
+			if v, ok := f.Value.(pflag.SliceValue); ok {
+				for _, elem := range v.GetSlice() {
+					res = append(res, "--"+f.Name+"="+elem)
+				}
+				return
+			}
+			res = append(res, "--"+f.Name+"="+f.Value.String())
+		})
+	}
+	var path []*cobra.Command
+	for tcmd := cmd; tcmd != nil; tcmd = tcmd.Parent() {
+		path = append([]*cobra.Command{tcmd}, path...)
+	}
+	for i, tcmd := range path {
+		switch {
+		case i == 0:
+			// the global flags, including the aliases such as "-n"
+			appendFlags(tcmd.Flags())
+		case i == len(path)-1:
+			res = append(res, tcmd.Name())
+			appendFlags(tcmd.LocalFlags())
+		default:
+			// e.g., "--file" of `nerdctl compose`
+			res = append(res, tcmd.Name())
+			appendFlags(tcmd.PersistentFlags())
+		}
+	}
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		res = append(res, args[:dash]...)
+		res = append(res, "--")
+		return append(res, args[dash:]...)
+	}
+	return append(res, args...)
+}
@@ -0,0 +1,78 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package context
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/context"
+)
+
+func createCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "create [flags] CONTEXT",
+		Short:         "Create a context",
+		Args:          cobra.ExactArgs(1),
+		RunE:          createAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().String("description", "", "Description of the context")
+	cmd.Flags().String("containerd", "", `containerd endpoint of the context, e.g., "address=/run/containerd/containerd.sock,namespace=default,snapshotter=overlayfs"`)
+	cmd.Flags().String("ssh", "", `Execute the commands on the remote host over SSH, e.g., "ssh://user@host:22"`)
+	cmd.Flags().Bool("sudo", false, "Execute the commands with sudo, e.g., for using the rootful containerd from a non-root user")
+	return cmd
+}
+
+func createOptions(cmd *cobra.Command) (types.ContextCreateOptions, error) {
+	description, err := cmd.Flags().GetString("description")
+	if err != nil {
+		return types.ContextCreateOptions{}, err
+	}
+	containerd, err := cmd.Flags().GetString("containerd")
+	if err != nil {
+		return types.ContextCreateOptions{}, err
+	}
+	ssh, err := cmd.Flags().GetString("ssh")
+	if err != nil {
+		return types.ContextCreateOptions{}, err
+	}
+	sudo, err := cmd.Flags().GetBool("sudo")
+	if err != nil {
+		return types.ContextCreateOptions{}, err
+	}
+	return types.ContextCreateOptions{
+		Stdout:      cmd.OutOrStdout(),
+		Description: description,
+		Containerd:  containerd,
+		SSH:         ssh,
+		Sudo:        sudo,
+	}, nil
+}
+
+func createAction(cmd *cobra.Command, args []string) error {
+	options, err := createOptions(cmd)
+	if err != nil {
+		return err
+	}
+	cs, err := newContextStore()
+	if err != nil {
+		return err
+	}
+	return context.Create(cs, args[0], options)
+}
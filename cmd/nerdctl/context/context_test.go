@@ -0,0 +1,116 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package context
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestMain(m *testing.M) {
+	testutil.M(m)
+}
+
+func TestContext(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+	testCase.NoParallel = true
+	// isolate the contexts of this test
+	testCase.Env = map[string]string{
+		"NERDCTL_TOML": filepath.Join(t.TempDir(), "nerdctl.toml"),
+	}
+
+	const namespaceKey = "namespace"
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("context", "create", "--description=test", "--containerd=namespace="+data.Identifier(), "foo")
+		data.Labels().Set(namespaceKey, data.Identifier())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("context", "use", "default")
+		helpers.Anyhow("context", "rm", "foo")
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "ls",
+			Command:     test.Command("context", "ls"),
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, func(stdout string, info string, t *testing.T) {
+				lines := strings.Split(strings.TrimSpace(stdout), "\n")
+				assert.Equal(t, len(lines), 3, info)
+				assert.Assert(t, strings.HasPrefix(lines[1], "default *"), info)
+				assert.Assert(t, strings.HasPrefix(lines[2], "foo "), info)
+			}),
+		},
+		{
+			Description: "--context applies the namespace",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				bin, _ := exec.LookPath(testutil.GetTarget())
+				return helpers.Custom(bin, "--context=foo", "info", "--mode=native", "--format={{.Namespace}}")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return test.Expects(expect.ExitCodeSuccess, nil, expect.Equals(data.Labels().Get(namespaceKey)+"\n"))(data, helpers)
+			},
+		},
+		{
+			Description: "--namespace takes precedence over the current context",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("context", "use", "foo")
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("context", "use", "default")
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				bin, _ := exec.LookPath(testutil.GetTarget())
+				return helpers.Custom(bin, "--namespace=bar", "info", "--mode=native", "--format={{.Namespace}}")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Equals("bar\n")),
+		},
+		{
+			Description: "the current context cannot be removed",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("context", "use", "foo")
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("context", "use", "default")
+			},
+			Command:  test.Command("context", "rm", "foo"),
+			Expected: test.Expects(1, []error{errors.New("is in use")}, nil),
+		},
+		{
+			Description: "unknown context",
+			Command:     test.Command("--context=unknown", "info"),
+			Expected:    test.Expects(1, []error{errors.New("does not exist")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
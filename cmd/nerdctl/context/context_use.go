@@ -0,0 +1,53 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package context
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/context"
+)
+
+func useCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "use CONTEXT",
+		Short:             `Set the current context (use "default" for resetting to nerdctl.toml)`,
+		Args:              cobra.ExactArgs(1),
+		RunE:              useAction,
+		ValidArgsFunction: useShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	return cmd
+}
+
+func useAction(cmd *cobra.Command, args []string) error {
+	cs, err := newContextStore()
+	if err != nil {
+		return err
+	}
+	return context.Use(cs, args[0], types.ContextUseOptions{Stdout: cmd.OutOrStdout()})
+}
+
+func useShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completion.ContextNames(cmd, args, toComplete)
+}
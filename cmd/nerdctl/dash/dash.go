@@ -0,0 +1,97 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dash
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/dash"
+)
+
+func Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dash [flags]",
+		Short: "Show a terminal dashboard of the containers, the images, the logs, and the stats",
+		Long: `Show a terminal dashboard of the containers, the images, the logs, and the stats.
+
+Keys:
+  ←/→, tab, 1-4  switch the pane
+  ↑/↓, j/k       select a row, or scroll the logs
+  enter, l       show the logs of the selected container
+  e              run the shell in the selected container
+  s              stop the selected container
+  d              remove the selected container or image
+  q, ctrl-c      quit
+`,
+		Args:          cobra.NoArgs,
+		RunE:          dashAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().Duration("interval", 2*time.Second, "Interval of refreshing the panes")
+	cmd.Flags().String("shell", "sh", "Command run in the selected container by the 'e' key")
+	return cmd
+}
+
+func dashOptions(cmd *cobra.Command) (types.DashOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.DashOptions{}, err
+	}
+	interval, err := cmd.Flags().GetDuration("interval")
+	if err != nil {
+		return types.DashOptions{}, err
+	}
+	shell, err := cmd.Flags().GetString("shell")
+	if err != nil {
+		return types.DashOptions{}, err
+	}
+	nerdctlCmd, nerdctlArgs := helpers.GlobalFlags(cmd)
+	return types.DashOptions{
+		Stdin:       os.Stdin,
+		Stdout:      os.Stdout,
+		GOptions:    globalOptions,
+		Interval:    interval,
+		Shell:       shell,
+		NerdctlCmd:  nerdctlCmd,
+		NerdctlArgs: nerdctlArgs,
+	}, nil
+}
+
+func dashAction(cmd *cobra.Command, _ []string) error {
+	options, err := dashOptions(cmd)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	client, ctx, cancel, err := clientutil.NewClient(ctx, options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return dash.Run(ctx, client, options)
+}
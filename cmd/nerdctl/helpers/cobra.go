@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"time"
 
@@ -163,6 +164,25 @@ func GlobalFlags(cmd *cobra.Command) (string, []string) {
 	return args0, args
 }
 
+// ChangedLocalFlags returns the non-persistent flags that were set on the command line, in the "--key=value" form.
+// Slice flags are returned as one "--key=value" per element. The flags in skip are omitted.
+func ChangedLocalFlags(cmd *cobra.Command, skip ...string) []string {
+	var args []string
+	cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		if !f.Changed || slices.Contains(skip, f.Name) {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			for _, v := range sv.GetSlice() {
+				args = append(args, "--"+f.Name+"="+v)
+			}
+			return
+		}
+		args = append(args, "--"+f.Name+"="+f.Value.String())
+	})
+	return args
+}
+
 // AddPersistentStringArrayFlag is similar to cmd.Flags().StringArray but supports aliases and env var and persistent.
 // See https://github.com/spf13/cobra/blob/main/user_guide.md#persistent-flags to learn what is "persistent".
 func AddPersistentStringArrayFlag(cmd *cobra.Command, name string, aliases, nonPersistentAliases []string, value []string, env string, usage string) {
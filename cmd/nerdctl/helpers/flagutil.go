@@ -17,11 +17,22 @@
 package helpers
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/pelletier/go-toml/v2"
 	"github.com/spf13/cobra"
 
+	"github.com/containerd/log"
+
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/config"
+	ncdefaults "github.com/containerd/nerdctl/v2/pkg/defaults"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 )
 
 func VerifyOptions(cmd *cobra.Command) (opt types.ImageVerifyOptions, err error) {
@@ -67,6 +78,10 @@ func ProcessRootCmdFlags(cmd *cobra.Command) (types.GlobalCommandOptions, error)
 	if err != nil {
 		return types.GlobalCommandOptions{}, err
 	}
+	snapshotterFallback, err := cmd.Flags().GetString("snapshotter-fallback")
+	if err != nil {
+		return types.GlobalCommandOptions{}, err
+	}
 	cniPath, err := cmd.Flags().GetString("cni-path")
 	if err != nil {
 		return types.GlobalCommandOptions{}, err
@@ -83,9 +98,13 @@ func ProcessRootCmdFlags(cmd *cobra.Command) (types.GlobalCommandOptions, error)
 	if err != nil {
 		return types.GlobalCommandOptions{}, err
 	}
-	insecureRegistry, err := cmd.Flags().GetBool("insecure-registry")
-	if err != nil {
-		return types.GlobalCommandOptions{}, err
+	insecureRegistryFlag := cmd.Flags().Lookup("insecure-registry")
+	if insecureRegistryFlag == nil {
+		return types.GlobalCommandOptions{}, errors.New("flag accessed but not defined: insecure-registry")
+	}
+	insecureRegistry, ok := insecureRegistryFlag.Value.(*InsecureRegistryValue)
+	if !ok {
+		return types.GlobalCommandOptions{}, fmt.Errorf("unexpected type %T of --insecure-registry", insecureRegistryFlag.Value)
 	}
 	hostsDir, err := cmd.Flags().GetStringSlice("hosts-dir")
 	if err != nil {
@@ -95,6 +114,10 @@ func ProcessRootCmdFlags(cmd *cobra.Command) (types.GlobalCommandOptions, error)
 	if err != nil {
 		return types.GlobalCommandOptions{}, err
 	}
+	offline, err := cmd.Flags().GetBool("offline")
+	if err != nil {
+		return types.GlobalCommandOptions{}, err
+	}
 	hostGatewayIP, err := cmd.Flags().GetString("host-gateway-ip")
 	if err != nil {
 		return types.GlobalCommandOptions{}, err
@@ -107,10 +130,60 @@ func ProcessRootCmdFlags(cmd *cobra.Command) (types.GlobalCommandOptions, error)
 	if err != nil {
 		return types.GlobalCommandOptions{}, err
 	}
+	kubeReadWrite, err := cmd.Flags().GetBool("i-know-what-i-am-doing")
+	if err != nil {
+		return types.GlobalCommandOptions{}, err
+	}
 	cdiSpecDirs, err := cmd.Flags().GetStringSlice("cdi-spec-dirs")
 	if err != nil {
 		return types.GlobalCommandOptions{}, err
 	}
+	portForwardingBackend, err := cmd.Flags().GetString("port-forwarding-backend")
+	if err != nil {
+		return types.GlobalCommandOptions{}, err
+	}
+	rootlessKitPortDriver, err := cmd.Flags().GetString("rootlesskit-port-driver")
+	if err != nil {
+		return types.GlobalCommandOptions{}, err
+	}
+	tlsCACert, err := cmd.Flags().GetString("tlscacert")
+	if err != nil {
+		return types.GlobalCommandOptions{}, err
+	}
+	tlsCert, err := cmd.Flags().GetString("tlscert")
+	if err != nil {
+		return types.GlobalCommandOptions{}, err
+	}
+	tlsKey, err := cmd.Flags().GetString("tlskey")
+	if err != nil {
+		return types.GlobalCommandOptions{}, err
+	}
+	tlsSPIFFEID, err := cmd.Flags().GetString("tls-spiffe-id")
+	if err != nil {
+		return types.GlobalCommandOptions{}, err
+	}
+	tlsSPIFFETrustDomain, err := cmd.Flags().GetString("tls-spiffe-trust-domain")
+	if err != nil {
+		return types.GlobalCommandOptions{}, err
+	}
+	// The global --output flag is shadowed by the --output flag of some commands, e.g., `nerdctl build`.
+	output, err := cmd.Root().PersistentFlags().GetString("output")
+	if err != nil {
+		return types.GlobalCommandOptions{}, err
+	}
+	switch output {
+	case "", formatter.OutputText:
+		output = ""
+	case formatter.OutputJSON:
+	default:
+		return types.GlobalCommandOptions{}, fmt.Errorf("invalid --output %q, must be either %q or %q", output, formatter.OutputText, formatter.OutputJSON)
+	}
+	// The [registries], [credentials], [p2p] and [admission] tables, and the defaults of the containers,
+	// are only in nerdctl.toml, not in the flags
+	tomlCfg, err := LoadNerdctlTOML(NerdctlTOMLPath())
+	if err != nil {
+		return types.GlobalCommandOptions{}, err
+	}
 
 	return types.GlobalCommandOptions{
 		Debug:            debug,
@@ -122,13 +195,35 @@ func ProcessRootCmdFlags(cmd *cobra.Command) (types.GlobalCommandOptions, error)
 		CNINetConfPath:   cniConfigPath,
 		DataRoot:         dataRoot,
 		CgroupManager:    cgroupManager,
-		InsecureRegistry: insecureRegistry,
+		InsecureRegistry: insecureRegistry.All,
 		HostsDir:         hostsDir,
 		Experimental:     experimental,
+		Offline:          offline,
 		HostGatewayIP:    hostGatewayIP,
 		BridgeIP:         bridgeIP,
 		KubeHideDupe:     kubeHideDupe,
+		KubeReadWrite:    kubeReadWrite,
 		CDISpecDirs:      cdiSpecDirs,
+
+		PortForwardingBackend: portForwardingBackend,
+		RootlessKitPortDriver: rootlessKitPortDriver,
+		SnapshotterFallback:   snapshotterFallback,
+		TLSCACert:             tlsCACert,
+		TLSCert:               tlsCert,
+		TLSKey:                tlsKey,
+		TLSSPIFFEID:           tlsSPIFFEID,
+		TLSSPIFFETrustDomain:  tlsSPIFFETrustDomain,
+		Output:                output,
+		InsecureRegistries:    insecureRegistry.Hosts,
+		Registries:            tomlCfg.Registries,
+		Credentials:           tomlCfg.Credentials,
+		P2P:                   tomlCfg.P2P,
+		Init:                  tomlCfg.Init,
+		InitBinary:            tomlCfg.InitBinary,
+		TZ:                    tomlCfg.TZ,
+		LabelFiles:            tomlCfg.LabelFiles,
+		Labels:                tomlCfg.Labels,
+		Admission:             tomlCfg.Admission,
 	}, nil
 }
 
@@ -144,3 +239,142 @@ func CheckExperimental(feature string) func(cmd *cobra.Command, args []string) e
 		return nil
 	}
 }
+
+// InsecureRegistryValue is the value of the global --insecure-registry flag,
+// either a bool for all the registries, or the comma-separated list of the insecure registries.
+type InsecureRegistryValue struct {
+	All     bool
+	Hosts   []string
+	changed bool
+}
+
+// NewInsecureRegistryValue returns the value of --insecure-registry, with the defaults of nerdctl.toml.
+func NewInsecureRegistryValue(all bool, hosts []string) *InsecureRegistryValue {
+	return &InsecureRegistryValue{All: all, Hosts: hosts}
+}
+
+func (v *InsecureRegistryValue) Set(s string) error {
+	if !v.changed {
+		// The flags override nerdctl.toml
+		v.All, v.Hosts, v.changed = false, nil, true
+	}
+	if b, err := strconv.ParseBool(s); err == nil {
+		v.All = b
+		return nil
+	}
+	for _, host := range strings.Split(s, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			v.Hosts = append(v.Hosts, host)
+		}
+	}
+	return nil
+}
+
+func (v *InsecureRegistryValue) String() string {
+	if v.All || len(v.Hosts) == 0 {
+		return strconv.FormatBool(v.All)
+	}
+	return strings.Join(v.Hosts, ",")
+}
+
+func (v *InsecureRegistryValue) Type() string {
+	return "bool|hosts"
+}
+
+// NerdctlTOMLPath returns the path of nerdctl.toml ($NERDCTL_TOML).
+func NerdctlTOMLPath() string {
+	if v, ok := os.LookupEnv("NERDCTL_TOML"); ok {
+		return v
+	}
+	return ncdefaults.NerdctlTOML()
+}
+
+// LoadNerdctlTOML loads nerdctl.toml on top of the default config.
+// A missing file is not an error.
+func LoadNerdctlTOML(tomlPath string) (*config.Config, error) {
+	cfg := config.New()
+	r, err := os.Open(tomlPath)
+	if err != nil {
+		log.L.WithError(err).Debugf("Not loading config from %q", tomlPath)
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		return cfg, nil
+	}
+	defer r.Close()
+	log.L.Debugf("Loading config from %q", tomlPath)
+	dec := toml.NewDecoder(r).DisallowUnknownFields() // set Strict to detect typo
+	if err := dec.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("failed to load nerdctl config (not daemon config) from %q (Hint: don't mix up daemon's `config.toml` with `nerdctl.toml`): %w", tomlPath, err)
+	}
+	log.L.Debugf("Loaded config %+v", cfg)
+	return cfg, nil
+}
+
+// DefaultParallel is the default value of the --parallel flag of the bulk operations.
+const DefaultParallel = 8
+
+// AddParallelFlag adds the --parallel flag to a bulk operation command, such as `nerdctl rm`.
+func AddParallelFlag(cmd *cobra.Command) {
+	cmd.Flags().Int("parallel", DefaultParallel, "Maximum number of objects processed concurrently")
+}
+
+// AddBatchFilterFlags adds the --filter and --all-matched flags, to select the containers of a batch operation
+// (e.g., `nerdctl stop --filter label=project=x --all-matched`).
+func AddBatchFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("filter", nil, "Also apply to the containers matching the filters, as 'nerdctl ps --filter'")
+	cmd.Flags().Bool("all-matched", false, "Allow --filter to match more than one container")
+}
+
+// ProcessBatchFilterFlags returns the values of the --filter and --all-matched flags.
+func ProcessBatchFilterFlags(cmd *cobra.Command) ([]string, bool, error) {
+	filters, err := cmd.Flags().GetStringSlice("filter")
+	if err != nil {
+		return nil, false, err
+	}
+	allMatched, err := cmd.Flags().GetBool("all-matched")
+	if err != nil {
+		return nil, false, err
+	}
+	return filters, allMatched, nil
+}
+
+// ContainerArgsOrFilter requires at least one container argument, unless --filter is specified.
+func ContainerArgsOrFilter(cmd *cobra.Command, args []string) error {
+	if cmd.Flags().Changed("filter") {
+		return nil
+	}
+	return cobra.MinimumNArgs(1)(cmd, args)
+}
+
+// ProcessParallelFlag returns the value of the --parallel flag.
+func ProcessParallelFlag(cmd *cobra.Command) (int, error) {
+	parallel, err := cmd.Flags().GetInt("parallel")
+	if err != nil {
+		return 0, err
+	}
+	if parallel < 1 {
+		return 0, fmt.Errorf("invalid --parallel value %d: must be 1 or greater", parallel)
+	}
+	return parallel, nil
+}
+
+// ProcessStopTimeoutFlag returns the value of the --timeout flag (or its deprecated alias --time)
+// of `nerdctl stop` and `nerdctl restart`. Nil means the stop timeout of the container.
+func ProcessStopTimeoutFlag(cmd *cobra.Command) (*time.Duration, error) {
+	for _, name := range []string{"timeout", "time"} {
+		if !cmd.Flags().Changed(name) {
+			continue
+		}
+		seconds, err := cmd.Flags().GetInt(name)
+		if err != nil {
+			return nil, err
+		}
+		if seconds < -1 {
+			return nil, fmt.Errorf("invalid --%s value %d: must be -1 or greater", name, seconds)
+		}
+		timeout := time.Duration(seconds) * time.Second
+		return &timeout, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,78 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package helpers
+
+import (
+	"errors"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+)
+
+// OutputWriter returns the writer the command has to print its human-readable output to,
+// and the function to call with the result of the command.
+// With `--output json`, that function prints the output collected by the writer as a formatter.Document of kind.
+func OutputWriter(cmd *cobra.Command, globalOptions types.GlobalCommandOptions, kind string, from formatter.ItemsFrom) (io.Writer, func(error) error) {
+	if globalOptions.Output != formatter.OutputJSON {
+		return cmd.OutOrStdout(), func(err error) error { return err }
+	}
+	dw := formatter.NewDocumentWriter(cmd.OutOrStdout(), kind, from)
+	return dw, dw.Close
+}
+
+// ListFormat returns the --format of a list command, which has to be `{{json .}}` with `--output json`.
+func ListFormat(globalOptions types.GlobalCommandOptions, format string, quiet bool) (string, error) {
+	if globalOptions.Output != formatter.OutputJSON {
+		return format, nil
+	}
+	if format != "" {
+		return "", errors.New("--format cannot be used with --output json")
+	}
+	if quiet {
+		return "", errors.New("--quiet cannot be used with --output json")
+	}
+	return "{{json .}}", nil
+}
+
+// InspectFormat returns the --format of an inspect command, which has to be the default JSON with `--output json`.
+func InspectFormat(globalOptions types.GlobalCommandOptions, format string) (string, error) {
+	if globalOptions.Output != formatter.OutputJSON {
+		return format, nil
+	}
+	if format != "" {
+		return "", errors.New("--format cannot be used with --output json")
+	}
+	return "", nil
+}
+
+// JSONFormat returns the --format of a command that prints a JSON array with `--format json`, e.g., `nerdctl compose ps`,
+// which has to be `json` with `--output json`.
+func JSONFormat(cmd *cobra.Command, globalOptions types.GlobalCommandOptions, format string, quiet bool) (string, error) {
+	if globalOptions.Output != formatter.OutputJSON {
+		return format, nil
+	}
+	if cmd.Flags().Changed("format") {
+		return "", errors.New("--format cannot be used with --output json")
+	}
+	if quiet {
+		return "", errors.New("--quiet cannot be used with --output json")
+	}
+	return "json", nil
+}
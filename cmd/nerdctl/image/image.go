@@ -49,6 +49,14 @@ func Command() *cobra.Command {
 		decryptCommand(),
 		pruneCommand(),
 		squashCommand(),
+		editCommand(),
+		rebaseCommand(),
+		promoteCommand(),
+		signCommand(),
+		sociCommand(),
+		nydusifyCommand(),
+		recordAccessCommand(),
+		outdatedCommand(),
 	)
 	return cmd
 }
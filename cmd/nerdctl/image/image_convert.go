@@ -77,10 +77,7 @@ func convertCommand() *cobra.Command {
 
 	// #region nydus flags
 	cmd.Flags().Bool("nydus", false, "Convert an OCI image to Nydus image. Should be used in conjunction with '--oci'")
-	cmd.Flags().String("nydus-builder-path", "nydus-image", "The nydus-image binary path, if unset, search in PATH environment")
-	cmd.Flags().String("nydus-work-dir", "", "Work directory path for image conversion, default is the nerdctl data root directory")
-	cmd.Flags().String("nydus-prefetch-patterns", "", "The file path pattern list want to prefetch")
-	cmd.Flags().String("nydus-compressor", "lz4_block", "Nydus blob compression algorithm, possible values: `none`, `lz4_block`, `zstd`, default is `lz4_block`")
+	addNydusFlags(cmd)
 	// #endregion
 
 	// #region overlaybd flags
@@ -180,19 +177,7 @@ func convertOptions(cmd *cobra.Command) (types.ImageConvertOptions, error) {
 	if err != nil {
 		return types.ImageConvertOptions{}, err
 	}
-	nydusBuilderPath, err := cmd.Flags().GetString("nydus-builder-path")
-	if err != nil {
-		return types.ImageConvertOptions{}, err
-	}
-	nydusWorkDir, err := cmd.Flags().GetString("nydus-work-dir")
-	if err != nil {
-		return types.ImageConvertOptions{}, err
-	}
-	nydusPrefetchPatterns, err := cmd.Flags().GetString("nydus-prefetch-patterns")
-	if err != nil {
-		return types.ImageConvertOptions{}, err
-	}
-	nydusCompressor, err := cmd.Flags().GetString("nydus-compressor")
+	nydusOpts, err := processNydusFlags(cmd)
 	if err != nil {
 		return types.ImageConvertOptions{}, err
 	}
@@ -258,10 +243,12 @@ func convertOptions(cmd *cobra.Command) (types.ImageConvertOptions, error) {
 		// #endregion
 		// #region nydus flags
 		Nydus:                 nydus,
-		NydusBuilderPath:      nydusBuilderPath,
-		NydusWorkDir:          nydusWorkDir,
-		NydusPrefetchPatterns: nydusPrefetchPatterns,
-		NydusCompressor:       nydusCompressor,
+		NydusBuilderPath:      nydusOpts.NydusBuilderPath,
+		NydusWorkDir:          nydusOpts.NydusWorkDir,
+		NydusPrefetchPatterns: nydusOpts.NydusPrefetchPatterns,
+		NydusPrefetchRecordIn: nydusOpts.NydusPrefetchRecordIn,
+		NydusCompressor:       nydusOpts.NydusCompressor,
+		NydusChunkDict:        nydusOpts.NydusChunkDict,
 		// #endregion
 		// #region overlaybd flags
 		Overlaybd:      overlaybd,
@@ -17,12 +17,16 @@
 package image
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
+	"gotest.tools/v3/assert"
+
 	"github.com/containerd/nerdctl/mod/tigron/require"
 	"github.com/containerd/nerdctl/mod/tigron/test"
 
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/converter"
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
 	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
 	"github.com/containerd/nerdctl/v2/pkg/testutil/testregistry"
@@ -66,6 +70,32 @@ func TestImageConvert(t *testing.T) {
 				},
 				Expected: test.Expects(0, nil, nil),
 			},
+			{
+				Description: "nydusify with chunk dict",
+				Require: require.All(
+					require.Binary("nydus-image"),
+				),
+				Setup: func(data test.Data, helpers test.Helpers) {
+					helpers.Ensure("image", "nydusify", testutil.CommonImage, data.Identifier("chunk-dict"))
+				},
+				Cleanup: func(data test.Data, helpers test.Helpers) {
+					helpers.Anyhow("rmi", "-f", data.Identifier("chunk-dict"))
+					helpers.Anyhow("rmi", "-f", data.Identifier("converted-image"))
+				},
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					return helpers.Command("image", "nydusify", "--format=json", "--nydus-chunk-dict", data.Identifier("chunk-dict"),
+						testutil.CommonImage, data.Identifier("converted-image"))
+				},
+				Expected: test.Expects(0, nil, func(stdout string, info string, t *testing.T) {
+					var res converter.ConvertedImageInfo
+					assert.NilError(t, json.Unmarshal([]byte(stdout), &res), info)
+					assert.Assert(t, res.NydusStats != nil, info)
+					assert.Assert(t, res.NydusStats.SourceSize > 0, info)
+					assert.Assert(t, res.NydusStats.BootstrapSize > 0, info)
+					// the chunks of the same image are all in the chunk dictionary
+					assert.Assert(t, res.NydusStats.DedupRatio > 0, info)
+				}),
+			},
 			{
 				Description: "zstd",
 				Cleanup: func(data test.Data, helpers test.Helpers) {
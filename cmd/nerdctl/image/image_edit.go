@@ -0,0 +1,149 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+)
+
+const editHelp = `Rewrite the config of an image and store the result as a new image, reusing all the layers.
+
+The layers are neither pulled nor rebuilt, so this is useful for late-binding metadata such as build IDs.
+
+Use '--platform' to define the platforms to edit.
+When neither '--platform' nor '--all-platforms' is given, only the default platform is edited.
+
+Example:
+  nerdctl image edit --label org.opencontainers.image.revision=abcdef --env BUILD_ID=42 example.com/foo:latest example.com/foo:42
+`
+
+func editCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "edit [flags] SOURCE_IMAGE TARGET_IMAGE",
+		Short:             "Rewrite the config of an image without rebuilding it",
+		Long:              editHelp,
+		Args:              helpers.IsExactArgs(2),
+		RunE:              editAction,
+		ValidArgsFunction: editShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().StringArrayP("change", "c", nil, "Apply Dockerfile instruction to the image config (CMD|ENTRYPOINT|ENV|EXPOSE|LABEL|USER|WORKDIR|VOLUME|STOPSIGNAL)")
+	cmd.Flags().StringArray("label", nil, "Set a label (KEY=VALUE)")
+	cmd.Flags().StringArray("env", nil, "Set an environment variable (KEY=VALUE)")
+	cmd.Flags().String("entrypoint", "", "Override the entrypoint (JSON array or shell form)")
+	cmd.Flags().String("cmd", "", "Override the command (JSON array or shell form)")
+	cmd.Flags().String("user", "", "Override the user")
+	cmd.Flags().String("workdir", "", "Override the working directory")
+	cmd.Flags().StringArray("expose", nil, "Expose a port or a range of ports (e.g., 80, 53/udp, 8000-8010)")
+	// #region platform flags
+	// platform is defined as StringSlice, not StringArray, to allow specifying "--platform=amd64,arm64"
+	cmd.Flags().StringSlice("platform", []string{}, "Edit the config for a specific platform")
+	cmd.RegisterFlagCompletionFunc("platform", completion.Platforms)
+	cmd.Flags().Bool("all-platforms", false, "Edit the config for all platforms")
+	// #endregion
+	return cmd
+}
+
+func processEditCommandFlags(cmd *cobra.Command) (types.ImageEditOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.ImageEditOptions{}, err
+	}
+	change, err := cmd.Flags().GetStringArray("change")
+	if err != nil {
+		return types.ImageEditOptions{}, err
+	}
+	label, err := cmd.Flags().GetStringArray("label")
+	if err != nil {
+		return types.ImageEditOptions{}, err
+	}
+	env, err := cmd.Flags().GetStringArray("env")
+	if err != nil {
+		return types.ImageEditOptions{}, err
+	}
+	entrypoint, err := cmd.Flags().GetString("entrypoint")
+	if err != nil {
+		return types.ImageEditOptions{}, err
+	}
+	command, err := cmd.Flags().GetString("cmd")
+	if err != nil {
+		return types.ImageEditOptions{}, err
+	}
+	user, err := cmd.Flags().GetString("user")
+	if err != nil {
+		return types.ImageEditOptions{}, err
+	}
+	workdir, err := cmd.Flags().GetString("workdir")
+	if err != nil {
+		return types.ImageEditOptions{}, err
+	}
+	expose, err := cmd.Flags().GetStringArray("expose")
+	if err != nil {
+		return types.ImageEditOptions{}, err
+	}
+	platforms, err := cmd.Flags().GetStringSlice("platform")
+	if err != nil {
+		return types.ImageEditOptions{}, err
+	}
+	allPlatforms, err := cmd.Flags().GetBool("all-platforms")
+	if err != nil {
+		return types.ImageEditOptions{}, err
+	}
+	return types.ImageEditOptions{
+		Stdout:       cmd.OutOrStdout(),
+		GOptions:     globalOptions,
+		Change:       change,
+		Label:        label,
+		Env:          env,
+		Entrypoint:   entrypoint,
+		Cmd:          command,
+		User:         user,
+		Workdir:      workdir,
+		Expose:       expose,
+		Platforms:    platforms,
+		AllPlatforms: allPlatforms,
+	}, nil
+}
+
+func editAction(cmd *cobra.Command, args []string) error {
+	options, err := processEditCommandFlags(cmd)
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return image.Edit(ctx, client, args[0], args[1], options)
+}
+
+func editShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		// show image names
+		return completion.ImageNames(cmd)
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
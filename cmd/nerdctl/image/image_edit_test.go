@@ -0,0 +1,97 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestImageEdit(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("pull", "--quiet", testutil.CommonImage)
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "config is rewritten and layers are reused",
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rmi", "-f", data.Identifier())
+			},
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("image", "edit",
+					"--label", "org.example.build-id=42",
+					"--env", "BUILD_ID=42",
+					"--workdir", "/srv",
+					"--expose", "8080",
+					"-c", `CMD ["echo", "edited"]`,
+					testutil.CommonImage, data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("image", "inspect", testutil.CommonImage, data.Identifier())
+			},
+			Expected: test.Expects(0, nil, func(stdout string, info string, t *testing.T) {
+				var dc []dockercompat.Image
+				assert.NilError(t, json.Unmarshal([]byte(stdout), &dc), info)
+				assert.Equal(t, len(dc), 2, info)
+				src, edited := dc[0], dc[1]
+				assert.DeepEqual(t, edited.RootFS.Layers, src.RootFS.Layers)
+				assert.Equal(t, edited.Config.Labels["org.example.build-id"], "42", info)
+				assert.Assert(t, edited.Config.Env[len(edited.Config.Env)-1] == "BUILD_ID=42", info)
+				assert.Equal(t, edited.Config.WorkingDir, "/srv", info)
+				_, ok := edited.Config.ExposedPorts["8080/tcp"]
+				assert.Assert(t, ok, info)
+				assert.DeepEqual(t, edited.Config.Cmd, []string{"echo", "edited"})
+			}),
+		},
+		{
+			Description: "the edited image runs",
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rmi", "-f", data.Identifier())
+			},
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("image", "edit", "--cmd", "pwd", "--workdir", "/tmp", testutil.CommonImage, data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Equals("/tmp\n")),
+		},
+		{
+			Description: "no changes",
+			Command:     test.Command("image", "edit", testutil.CommonImage, "nerdctl-test-image-edit-nochange"),
+			Expected:    test.Expects(1, []error{errors.New("no changes specified")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -22,7 +22,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
 	"strconv"
 	"text/tabwriter"
 	"text/template"
@@ -84,12 +83,25 @@ func historyAction(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	quiet, err := cmd.Flags().GetBool("quiet")
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	format, err = helpers.ListFormat(globalOptions, format, quiet)
+	if err != nil {
+		return err
+	}
 	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), globalOptions.Namespace, globalOptions.Address)
 	if err != nil {
 		return err
 	}
 	defer cancel()
 
+	stdout, finish := helpers.OutputWriter(cmd, globalOptions, formatter.KindImageHistory, formatter.ItemsFromJSONLines)
 	walker := &imagewalker.ImageWalker{
 		Client: client,
 		OnFound: func(ctx context.Context, found imagewalker.Found) error {
@@ -145,7 +157,7 @@ func historyAction(cmd *cobra.Command, args []string) error {
 				}
 				historys = append(historys, history)
 			}
-			err = printHistory(cmd, historys)
+			err = printHistory(cmd, stdout, format, historys)
 			if err != nil {
 				return fmt.Errorf("failed printHistory: %w", err)
 			}
@@ -153,7 +165,7 @@ func historyAction(cmd *cobra.Command, args []string) error {
 		},
 	}
 
-	return walker.WalkAll(ctx, args, true)
+	return finish(walker.WalkAll(ctx, args, true))
 }
 
 type historyPrinter struct {
@@ -162,7 +174,7 @@ type historyPrinter struct {
 	tmpl                  *template.Template
 }
 
-func printHistory(cmd *cobra.Command, historys []historyPrintable) error {
+func printHistory(cmd *cobra.Command, w io.Writer, format string, historys []historyPrintable) error {
 	quiet, err := cmd.Flags().GetBool("quiet")
 	if err != nil {
 		return err
@@ -176,14 +188,6 @@ func printHistory(cmd *cobra.Command, historys []historyPrintable) error {
 		return err
 	}
 
-	var w io.Writer
-	w = os.Stdout
-
-	format, err := cmd.Flags().GetString("format")
-	if err != nil {
-		return err
-	}
-
 	var tmpl *template.Template
 	switch format {
 	case "", "table":
@@ -72,6 +72,10 @@ func InspectOptions(cmd *cobra.Command, platform *string) (types.ImageInspectOpt
 	if err != nil {
 		return types.ImageInspectOptions{}, err
 	}
+	format, err = helpers.InspectFormat(globalOptions, format)
+	if err != nil {
+		return types.ImageInspectOptions{}, err
+	}
 	if platform == nil {
 		tempPlatform, err := cmd.Flags().GetString("platform")
 		if err != nil {
@@ -105,9 +109,11 @@ func imageInspectAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
+	stdout, finish := helpers.OutputWriter(cmd, options.GOptions, formatter.KindImageInspect, formatter.ItemsFromJSONArray)
+	options.Stdout = stdout
 	entries, err := image.Inspect(ctx, client, args, options)
 	if err != nil {
-		return err
+		return finish(err)
 	}
 
 	// Display
@@ -116,7 +122,7 @@ func imageInspectAction(cmd *cobra.Command, args []string) error {
 			log.G(ctx).Error(formatErr)
 		}
 	}
-	return err
+	return finish(err)
 }
 
 func imageInspectShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -26,6 +26,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
 )
 
@@ -95,6 +96,10 @@ func listOptions(cmd *cobra.Command, args []string) (*types.ImageListOptions, er
 	if err != nil {
 		return nil, err
 	}
+	format, err = helpers.ListFormat(globalOptions, format, quiet)
+	if err != nil {
+		return nil, err
+	}
 	var inputFilters []string
 	if cmd.Flags().Changed("filter") {
 		inputFilters, err = cmd.Flags().GetStringSlice("filter")
@@ -140,7 +145,9 @@ func imagesAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
-	return image.ListCommandHandler(ctx, client, options)
+	stdout, finish := helpers.OutputWriter(cmd, options.GOptions, formatter.KindImageList, formatter.ItemsFromJSONLines)
+	options.Stdout = stdout
+	return finish(image.ListCommandHandler(ctx, client, options))
 }
 
 func imagesShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
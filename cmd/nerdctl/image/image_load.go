@@ -39,6 +39,7 @@ func LoadCommand() *cobra.Command {
 
 	cmd.Flags().StringP("input", "i", "", "Read from tar archive file, instead of STDIN")
 	cmd.Flags().BoolP("quiet", "q", false, "Suppress the load output")
+	cmd.Flags().String("verify-bundle", "", "Verify the digests and the cosign signatures in the archive against the policy file (containers-policy.json format) before importing it")
 
 	// #region platform flags
 	// platform is defined as StringSlice, not StringArray, to allow specifying "--platform=amd64,arm64"
@@ -71,6 +72,10 @@ func processLoadCommandFlags(cmd *cobra.Command) (types.ImageLoadOptions, error)
 	if err != nil {
 		return types.ImageLoadOptions{}, err
 	}
+	verifyBundle, err := cmd.Flags().GetString("verify-bundle")
+	if err != nil {
+		return types.ImageLoadOptions{}, err
+	}
 	return types.ImageLoadOptions{
 		GOptions:     globalOptions,
 		Input:        input,
@@ -79,6 +84,7 @@ func processLoadCommandFlags(cmd *cobra.Command) (types.ImageLoadOptions, error)
 		Stdout:       cmd.OutOrStdout(),
 		Stdin:        cmd.InOrStdin(),
 		Quiet:        quiet,
+		VerifyBundle: verifyBundle,
 	}, nil
 }
 
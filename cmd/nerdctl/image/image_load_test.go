@@ -17,6 +17,7 @@
 package image
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -115,3 +116,57 @@ func TestLoadQuiet(t *testing.T) {
 
 	testCase.Run(t)
 }
+
+func TestLoadVerifyBundle(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		identifier := data.Identifier()
+		helpers.Ensure("pull", "--quiet", testutil.CommonImage)
+		helpers.Ensure("tag", testutil.CommonImage, identifier)
+		helpers.Ensure("save", identifier, "-o", data.Temp().Path("common.tar"))
+		helpers.Ensure("rmi", "-f", identifier)
+		data.Labels().Set("image", identifier)
+		data.Labels().Set("archive", data.Temp().Path("common.tar"))
+		data.Labels().Set("accept", data.Temp().Save(`{"default": [{"type": "insecureAcceptAnything"}]}`, "accept.json"))
+		data.Labels().Set("reject", data.Temp().Save(`{"default": [{"type": "reject"}]}`, "reject.json"))
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "accepted by the policy",
+			NoParallel:  true,
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rmi", "-f", data.Labels().Get("image"))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("load", "--verify-bundle", data.Labels().Get("accept"), "--input", data.Labels().Get("archive"))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.Contains(fmt.Sprintf("Loaded image: %s:latest", data.Labels().Get("image"))),
+				}
+			},
+		},
+		{
+			Description: "rejected by the policy",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("load", "--verify-bundle", data.Labels().Get("reject"), "--input", data.Labels().Get("archive"))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					ExitCode: 1,
+					Errors:   []error{errors.New("rejected by policy")},
+					Output: func(stdout string, info string, t *testing.T) {
+						assert.Assert(t, !strings.Contains(helpers.Capture("images"), data.Labels().Get("image")), info)
+					},
+				}
+			},
+		},
+	}
+
+	testCase.Run(t)
+}
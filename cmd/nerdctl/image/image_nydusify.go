@@ -0,0 +1,136 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+)
+
+const nydusifyHelp = `Convert an image to a Nydus image, for lazy pulling with the nydus snapshotter.
+
+This is the same as 'nerdctl image convert --nydus --oci'.
+The statistics of the conversion (deduplication with '--nydus-chunk-dict', estimated cold-start savings) are printed
+to stderr, or included in the output with '--format=json'.
+
+e.g., 'nerdctl image nydusify --nydus-chunk-dict example.com/base:nydus example.com/foo:orig example.com/foo:nydus'
+`
+
+func nydusifyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "nydusify [flags] SOURCE_IMAGE TARGET_IMAGE",
+		Short:             "Convert an image to a Nydus image",
+		Long:              nydusifyHelp,
+		Args:              helpers.IsExactArgs(2),
+		RunE:              nydusifyAction,
+		ValidArgsFunction: imageConvertShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().String("format", "", "Format the output using the given Go template, e.g, 'json'")
+	addNydusFlags(cmd)
+	// #region platform flags
+	// platform is defined as StringSlice, not StringArray, to allow specifying "--platform=amd64,arm64"
+	cmd.Flags().StringSlice("platform", []string{}, "Convert content for a specific platform")
+	cmd.RegisterFlagCompletionFunc("platform", completion.Platforms)
+	cmd.Flags().Bool("all-platforms", false, "Convert content for all platforms")
+	// #endregion
+	return cmd
+}
+
+// addNydusFlags adds the flags for the nydus conversion, shared by `image convert` and `image nydusify`.
+func addNydusFlags(cmd *cobra.Command) {
+	cmd.Flags().String("nydus-builder-path", "nydus-image", "The nydus-image binary path, if unset, search in PATH environment")
+	cmd.Flags().String("nydus-work-dir", "", "Work directory path for image conversion, default is the nerdctl data root directory")
+	cmd.Flags().String("nydus-prefetch-patterns", "", "The file path pattern list want to prefetch")
+	cmd.Flags().String("nydus-prefetch-record-in", "", "Read 'ctr-remote optimize --record-out=<FILE>' record file for generating the prefetch table (EXPERIMENTAL)")
+	cmd.Flags().String("nydus-compressor", "lz4_block", "Nydus blob compression algorithm, possible values: `none`, `lz4_block`, `zstd`, default is `lz4_block`")
+	cmd.Flags().String("nydus-chunk-dict", "", "Local Nydus image used as a chunk dictionary, for deduplicating the chunks across images")
+}
+
+// processNydusFlags returns the options of the nydus conversion, except for the Nydus field.
+func processNydusFlags(cmd *cobra.Command) (types.ImageConvertOptions, error) {
+	builderPath, err := cmd.Flags().GetString("nydus-builder-path")
+	if err != nil {
+		return types.ImageConvertOptions{}, err
+	}
+	workDir, err := cmd.Flags().GetString("nydus-work-dir")
+	if err != nil {
+		return types.ImageConvertOptions{}, err
+	}
+	prefetchPatterns, err := cmd.Flags().GetString("nydus-prefetch-patterns")
+	if err != nil {
+		return types.ImageConvertOptions{}, err
+	}
+	prefetchRecordIn, err := cmd.Flags().GetString("nydus-prefetch-record-in")
+	if err != nil {
+		return types.ImageConvertOptions{}, err
+	}
+	compressor, err := cmd.Flags().GetString("nydus-compressor")
+	if err != nil {
+		return types.ImageConvertOptions{}, err
+	}
+	chunkDict, err := cmd.Flags().GetString("nydus-chunk-dict")
+	if err != nil {
+		return types.ImageConvertOptions{}, err
+	}
+	return types.ImageConvertOptions{
+		NydusBuilderPath:      builderPath,
+		NydusWorkDir:          workDir,
+		NydusPrefetchPatterns: prefetchPatterns,
+		NydusPrefetchRecordIn: prefetchRecordIn,
+		NydusCompressor:       compressor,
+		NydusChunkDict:        chunkDict,
+	}, nil
+}
+
+func nydusifyAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	options, err := processNydusFlags(cmd)
+	if err != nil {
+		return err
+	}
+	if options.Format, err = cmd.Flags().GetString("format"); err != nil {
+		return err
+	}
+	if options.Platforms, err = cmd.Flags().GetStringSlice("platform"); err != nil {
+		return err
+	}
+	if options.AllPlatforms, err = cmd.Flags().GetBool("all-platforms"); err != nil {
+		return err
+	}
+	options.GOptions = globalOptions
+	options.Nydus = true
+	options.Oci = true
+	options.Stdout = cmd.OutOrStdout()
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return image.Convert(ctx, client, args[0], args[1], options)
+}
@@ -0,0 +1,107 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+)
+
+const outdatedHelp = `Compare the tagged images with their registries, and report the stale images and the containers created from them.
+
+An image is "outdated" when its tag points to a new digest in the registry.
+With --policy=patch|minor|major, the registry is also searched for a newer version tag, e.g., "1.19-alpine"
+can be followed by "1.19.1-alpine" with "patch", by "1.21-alpine" with "minor", and by "2.0-alpine" with "major".
+
+Containers keep running the image they were created from: they have to be recreated to use the pulled images.
+
+Example:
+  nerdctl image outdated --policy=minor --format=json
+`
+
+func outdatedCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "outdated [flags] [IMAGE...]",
+		Short:             "Report the images whose tags were updated in the registry",
+		Long:              outdatedHelp,
+		RunE:              outdatedAction,
+		ValidArgsFunction: outdatedShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().String("policy", image.OutdatedPolicyDigest, "Policy to look for newer version tags (\"digest\"|\"patch\"|\"minor\"|\"major\"), \"digest\" only checks the current tags")
+	cmd.RegisterFlagCompletionFunc("policy", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{image.OutdatedPolicyDigest, image.OutdatedPolicyPatch, image.OutdatedPolicyMinor, image.OutdatedPolicyMajor}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().String("format", "", "Format the output using the given Go template, e.g, '{{json .}}'")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "table"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().Bool("update", false, "Pull the updated tags, and the newer tags found with --policy")
+	return cmd
+}
+
+func processOutdatedCommandFlags(cmd *cobra.Command) (types.ImageOutdatedOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.ImageOutdatedOptions{}, err
+	}
+	policy, err := cmd.Flags().GetString("policy")
+	if err != nil {
+		return types.ImageOutdatedOptions{}, err
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return types.ImageOutdatedOptions{}, err
+	}
+	update, err := cmd.Flags().GetBool("update")
+	if err != nil {
+		return types.ImageOutdatedOptions{}, err
+	}
+	return types.ImageOutdatedOptions{
+		Stdout:   cmd.OutOrStdout(),
+		Stderr:   cmd.ErrOrStderr(),
+		GOptions: globalOptions,
+		Policy:   policy,
+		Format:   format,
+		Update:   update,
+	}, nil
+}
+
+func outdatedAction(cmd *cobra.Command, args []string) error {
+	options, err := processOutdatedCommandFlags(cmd)
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return image.Outdated(ctx, client, args, options)
+}
+
+func outdatedShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// show image names
+	return completion.ImageNames(cmd)
+}
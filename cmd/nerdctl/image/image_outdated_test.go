@@ -0,0 +1,80 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestImageOutdated(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("pull", "--quiet", testutil.CommonImage)
+		helpers.Ensure("create", "--name", data.Identifier(), testutil.CommonImage)
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "a freshly pulled image is up to date, and lists its containers",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("image", "outdated", "--format", "json", testutil.CommonImage)
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout, info string, t *testing.T) {
+						lines := strings.Split(strings.TrimSpace(stdout), "\n")
+						assert.Equal(t, len(lines), 1, info)
+						var entry struct {
+							Name       string
+							Status     string
+							Containers []string
+						}
+						assert.NilError(t, json.Unmarshal([]byte(lines[0]), &entry), info)
+						assert.Equal(t, entry.Status, "up-to-date", info)
+						assert.Assert(t, strings.Contains(strings.Join(entry.Containers, ","), data.Identifier()), info)
+					},
+				}
+			},
+		},
+		{
+			Description: "an unknown policy is rejected",
+			Command:     test.Command("image", "outdated", "--policy", "newest", testutil.CommonImage),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, []error{errors.New(`unknown policy "newest"`)}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
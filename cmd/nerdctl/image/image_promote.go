@@ -0,0 +1,156 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+)
+
+const promoteHelp = `Copy an image between repositories (e.g., staging to production), only after checking policy gates.
+
+The gates are checked against SOURCE_IMAGE in the registry, in this order:
+  - signature:     --verify=cosign|notation (with the same flags as 'nerdctl pull')
+  - SBOM:          --require-sbom requires an SBOM attestation (SPDX or CycloneDX) attached to the image
+  - vulnerability: --scanner=trivy|grype (or --scan-report=FILE) with --severity-threshold
+
+The image is copied as it is (all the platforms and the attestations), so the digest is kept.
+The cosign signature of the image is copied together, unless --copy-signatures=false is specified.
+
+Example:
+  nerdctl image promote --verify=cosign --cosign-key cosign.pub --require-sbom --scanner=trivy --severity-threshold=high \
+    registry.example.com/staging/app:1.0 registry.example.com/prod/app:1.0
+`
+
+func promoteCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "promote [flags] SOURCE_IMAGE TARGET_IMAGE",
+		Short:             "Copy an image between repositories after checking policy gates",
+		Long:              promoteHelp,
+		Args:              helpers.IsExactArgs(2),
+		RunE:              promoteAction,
+		ValidArgsFunction: promoteShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	// #region verify flags
+	cmd.Flags().String("verify", "none", "Verify the signature of the image (none|cosign|notation)")
+	cmd.RegisterFlagCompletionFunc("verify", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"none", "cosign", "notation"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().String("cosign-key", "", "Path to the public key file, KMS, URI or Kubernetes Secret for --verify=cosign")
+	cmd.Flags().String("cosign-certificate-identity", "", "The identity expected in a valid Fulcio certificate for --verify=cosign. Valid values include email address, DNS names, IP addresses, and URIs. Either --cosign-certificate-identity or --cosign-certificate-identity-regexp must be set for keyless flows")
+	cmd.Flags().String("cosign-certificate-identity-regexp", "", "A regular expression alternative to --cosign-certificate-identity for --verify=cosign. Accepts the Go regular expression syntax described at https://golang.org/s/re2syntax. Either --cosign-certificate-identity or --cosign-certificate-identity-regexp must be set for keyless flows")
+	cmd.Flags().String("cosign-certificate-oidc-issuer", "", "The OIDC issuer expected in a valid Fulcio certificate for --verify=cosign,, e.g. https://token.actions.githubusercontent.com or https://oauth2.sigstore.dev/auth. Either --cosign-certificate-oidc-issuer or --cosign-certificate-oidc-issuer-regexp must be set for keyless flows")
+	cmd.Flags().String("cosign-certificate-oidc-issuer-regexp", "", "A regular expression alternative to --certificate-oidc-issuer for --verify=cosign,. Accepts the Go regular expression syntax described at https://golang.org/s/re2syntax. Either --cosign-certificate-oidc-issuer or --cosign-certificate-oidc-issuer-regexp must be set for keyless flows")
+	// #endregion
+
+	cmd.Flags().Bool("require-sbom", false, "Require an SBOM attestation attached to the image")
+	cmd.Flags().String("scanner", "", "Vulnerability scanner to run against the image (trivy|grype)")
+	cmd.RegisterFlagCompletionFunc("scanner", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"trivy", "grype"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().String("scan-report", "", "JSON vulnerability report of the image created by trivy or grype, instead of running --scanner")
+	cmd.Flags().String("severity-threshold", "critical", "Lowest severity of the vulnerabilities that block the promotion (low|medium|high|critical)")
+	cmd.RegisterFlagCompletionFunc("severity-threshold", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"low", "medium", "high", "critical"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().Bool("copy-signatures", true, "Copy the cosign signature of the image to the target repository")
+	cmd.Flags().Bool("dry-run", false, "Only check the policy gates, without copying the image")
+	cmd.Flags().BoolP("quiet", "q", false, "Suppress verbose output")
+	return cmd
+}
+
+func processPromoteCommandFlags(cmd *cobra.Command) (types.ImagePromoteOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.ImagePromoteOptions{}, err
+	}
+	verifyOptions, err := helpers.VerifyOptions(cmd)
+	if err != nil {
+		return types.ImagePromoteOptions{}, err
+	}
+	requireSBOM, err := cmd.Flags().GetBool("require-sbom")
+	if err != nil {
+		return types.ImagePromoteOptions{}, err
+	}
+	scanner, err := cmd.Flags().GetString("scanner")
+	if err != nil {
+		return types.ImagePromoteOptions{}, err
+	}
+	scanReport, err := cmd.Flags().GetString("scan-report")
+	if err != nil {
+		return types.ImagePromoteOptions{}, err
+	}
+	severityThreshold, err := cmd.Flags().GetString("severity-threshold")
+	if err != nil {
+		return types.ImagePromoteOptions{}, err
+	}
+	copySignatures, err := cmd.Flags().GetBool("copy-signatures")
+	if err != nil {
+		return types.ImagePromoteOptions{}, err
+	}
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return types.ImagePromoteOptions{}, err
+	}
+	quiet, err := cmd.Flags().GetBool("quiet")
+	if err != nil {
+		return types.ImagePromoteOptions{}, err
+	}
+	return types.ImagePromoteOptions{
+		Stdout:            cmd.OutOrStdout(),
+		Stderr:            cmd.ErrOrStderr(),
+		GOptions:          globalOptions,
+		VerifyOptions:     verifyOptions,
+		RequireSBOM:       requireSBOM,
+		Scanner:           scanner,
+		ScanReport:        scanReport,
+		SeverityThreshold: severityThreshold,
+		CopySignatures:    copySignatures,
+		DryRun:            dryRun,
+		Quiet:             quiet,
+	}, nil
+}
+
+func promoteAction(cmd *cobra.Command, args []string) error {
+	options, err := processPromoteCommandFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return image.Promote(ctx, client, args[0], args[1], options)
+}
+
+func promoteShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) < 2 {
+		// show image names
+		return completion.ImageNames(cmd)
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
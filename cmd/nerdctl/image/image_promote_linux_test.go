@@ -0,0 +1,108 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest/registry"
+)
+
+func TestImagePromote(t *testing.T) {
+	dockerfile := fmt.Sprintf(`FROM %s
+CMD ["echo", "nerdctl-build-test-string"]
+	`, testutil.CommonImage)
+
+	nerdtest.Setup()
+
+	var reg *registry.Server
+
+	testCase := &test.Case{
+		Require: require.All(
+			require.Linux,
+			nerdtest.Build,
+			require.Not(nerdtest.Docker),
+			nerdtest.Registry,
+		),
+
+		Setup: func(data test.Data, helpers test.Helpers) {
+			data.Temp().Save(dockerfile, "Dockerfile")
+			data.Temp().Save(`{"matches": [{"vulnerability": {"id": "CVE-2024-0001", "severity": "Medium"}}]}`, "report.json")
+			reg = nerdtest.RegistryWithNoAuth(data, helpers, 0, false)
+			reg.Setup(data, helpers)
+			registryPrefix := fmt.Sprintf("%s:%d/%s", "127.0.0.1", reg.Port, data.Identifier())
+
+			helpers.Ensure("build", "-t", registryPrefix+"/staging:1.0", data.Temp().Path())
+			helpers.Ensure("push", registryPrefix+"/staging:1.0")
+
+			data.Labels().Set("registry_prefix", registryPrefix)
+			data.Labels().Set("report", data.Temp().Path("report.json"))
+		},
+
+		Cleanup: func(data test.Data, helpers test.Helpers) {
+			if reg != nil {
+				reg.Cleanup(data, helpers)
+				registryPrefix := data.Labels().Get("registry_prefix")
+				helpers.Anyhow("rmi", "-f", registryPrefix+"/staging:1.0", registryPrefix+"/prod:1.0")
+			}
+		},
+
+		SubTests: []*test.Case{
+			{
+				Description: "Blocked without an SBOM",
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					registryPrefix := data.Labels().Get("registry_prefix")
+					return helpers.Command("image", "promote", "--require-sbom", registryPrefix+"/staging:1.0", registryPrefix+"/prod:1.0")
+				},
+				Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("no SBOM attestation")}, nil),
+			},
+			{
+				Description: "Blocked by the vulnerabilities",
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					registryPrefix := data.Labels().Get("registry_prefix")
+					return helpers.Command("image", "promote", "--scan-report", data.Labels().Get("report"), "--severity-threshold", "medium",
+						registryPrefix+"/staging:1.0", registryPrefix+"/prod:1.0")
+				},
+				Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("1 medium (CVE-2024-0001)")}, nil),
+			},
+			{
+				Description: "Promoted",
+				NoParallel:  true,
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					registryPrefix := data.Labels().Get("registry_prefix")
+					return helpers.Command("image", "promote", "--quiet", "--scan-report", data.Labels().Get("report"),
+						registryPrefix+"/staging:1.0", registryPrefix+"/prod:1.0")
+				},
+				Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+					return &test.Expected{
+						Output: expect.Contains(data.Labels().Get("registry_prefix") + "/prod@sha256:"),
+					}
+				},
+			},
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -40,6 +40,7 @@ func pruneCommand() *cobra.Command {
 	cmd.Flags().BoolP("all", "a", false, "Remove all unused images, not just dangling ones")
 	cmd.Flags().StringSlice("filter", []string{}, "Filter output based on conditions provided")
 	cmd.Flags().BoolP("force", "f", false, "Do not prompt for confirmation")
+	helpers.AddParallelFlag(cmd)
 	return cmd
 }
 
@@ -66,12 +67,18 @@ func pruneOptions(cmd *cobra.Command) (types.ImagePruneOptions, error) {
 		return types.ImagePruneOptions{}, err
 	}
 
+	parallel, err := helpers.ProcessParallelFlag(cmd)
+	if err != nil {
+		return types.ImagePruneOptions{}, err
+	}
+
 	return types.ImagePruneOptions{
 		Stdout:   cmd.OutOrStdout(),
 		GOptions: globalOptions,
 		All:      all,
 		Filters:  filters,
 		Force:    force,
+		Parallel: parallel,
 	}, err
 }
 
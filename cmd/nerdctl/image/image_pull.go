@@ -17,6 +17,8 @@
 package image
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
@@ -66,8 +68,10 @@ func PullCommand() *cobra.Command {
 	// #endregion
 
 	cmd.Flags().BoolP("quiet", "q", false, "Suppress verbose output")
+	cmd.Flags().Duration("max-wait", 0, "Maximum duration to wait for the pull to complete, e.g., 5m (default: no limit)")
 
 	cmd.Flags().String("ipfs-address", "", "multiaddr of IPFS API (default uses $IPFS_PATH env variable if defined or local directory ~/.ipfs)")
+	cmd.Flags().String("ipfs-gateway", "", "HTTP gateway of IPFS (e.g., https://ipfs.io) to fall back to when the IPFS API is not available")
 
 	return cmd
 }
@@ -104,10 +108,21 @@ func processPullCommandFlags(cmd *cobra.Command) (types.ImagePullOptions, error)
 	if err != nil {
 		return types.ImagePullOptions{}, err
 	}
+	maxWait, err := cmd.Flags().GetDuration("max-wait")
+	if err != nil {
+		return types.ImagePullOptions{}, err
+	}
+	if maxWait < 0 {
+		return types.ImagePullOptions{}, fmt.Errorf("--max-wait must not be negative, got %s", maxWait)
+	}
 	ipfsAddressStr, err := cmd.Flags().GetString("ipfs-address")
 	if err != nil {
 		return types.ImagePullOptions{}, err
 	}
+	ipfsGateway, err := cmd.Flags().GetString("ipfs-gateway")
+	if err != nil {
+		return types.ImagePullOptions{}, err
+	}
 
 	sociIndexDigest, err := cmd.Flags().GetString("soci-index-digest")
 	if err != nil {
@@ -125,7 +140,9 @@ func processPullCommandFlags(cmd *cobra.Command) (types.ImagePullOptions, error)
 		Unpack:          unpack,
 		Mode:            "always",
 		Quiet:           quiet,
+		MaxWait:         maxWait,
 		IPFSAddress:     ipfsAddressStr,
+		IPFSGateway:     ipfsGateway,
 		RFlags: types.RemoteSnapshotterFlags{
 			SociIndexDigest: sociIndexDigest,
 		},
@@ -17,6 +17,7 @@
 package image
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -281,3 +282,119 @@ func TestImagePullProcessOutput(t *testing.T) {
 
 	testCase.Run(t)
 }
+
+func TestImagePullSnapshotterFallback(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.All(
+		require.Not(nerdtest.Docker),
+		require.Not(nerdtest.Stargz),
+	)
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "run falls back to overlayfs when the remote snapshotter is unavailable",
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("--snapshotter=stargz", "--snapshotter-fallback=overlayfs",
+					"run", "--name", data.Identifier(), "--pull=always", testutil.CommonImage, "echo", "fallback-ok")
+			},
+			Expected: test.Expects(0, nil, expect.Contains("fallback-ok")),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestImagePullMaxWait(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "pull fails when it does not complete within --max-wait",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rmi", "-f", testutil.BusyboxImage)
+			},
+			Command:  test.Command("pull", "--quiet", "--max-wait=1ms", testutil.BusyboxImage),
+			Expected: test.Expects(1, []error{errors.New("within --max-wait=1ms")}, nil),
+		},
+		{
+			Description: "pull succeeds within --max-wait",
+			NoParallel:  true,
+			Command:     test.Command("pull", "--quiet", "--max-wait=5m", testutil.CommonImage),
+			Expected:    test.Expects(0, nil, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestRunPlatformMismatchPullsAgain(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.All(
+		require.Not(nerdtest.Docker),
+		require.Arch("amd64"),
+		nerdtest.Build,
+	)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		// A single-platform build is a manifest, not an index, so containerd does not check its platform
+		data.Temp().Save(fmt.Sprintf("FROM %s\n", testutil.CommonImage), "Dockerfile")
+		helpers.Ensure("build", "--platform=linux/arm64", "-t", data.Identifier(), data.Temp().Path())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rmi", "-f", data.Identifier())
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		return helpers.Command("run", "--rm", "--pull=never", "--platform=linux/amd64", data.Identifier(), "uname", "-m")
+	}
+
+	testCase.Expected = test.Expects(1, []error{errors.New(`the image platform "linux/arm64" does not match the requested platform "linux/amd64"`)}, nil)
+
+	testCase.Run(t)
+}
+
+func TestImagePullOffline(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("pull", "--quiet", testutil.CommonImage)
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "pull fails",
+			Command:     test.Command("--offline", "pull", testutil.CommonImage),
+			Expected:    test.Expects(1, []error{errors.New("registry access is disabled in the offline mode")}, nil),
+		},
+		{
+			Description: "run uses the local image",
+			Command:     test.Command("--offline", "run", "--rm", testutil.CommonImage, "echo", "offline-ok"),
+			Expected:    test.Expects(0, nil, expect.Contains("offline-ok")),
+		},
+		{
+			Description: "run fails for the image not available locally",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("--offline", "run", "--rm", "example.com/"+data.Identifier()+":nonexistent")
+			},
+			Expected: test.Expects(1, []error{errors.New("image not available locally")}, nil),
+		},
+		{
+			Description: "run --pull=always fails",
+			Command:     test.Command("--offline", "run", "--rm", "--pull=always", testutil.CommonImage, "true"),
+			Expected:    test.Expects(1, []error{errors.New("registry access is disabled in the offline mode")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -0,0 +1,94 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+)
+
+const rebaseHelp = `Replace the base layers of an image with the layers of another base image, without rebuilding it.
+
+APP_IMAGE must have been built on top of OLD_BASE_IMAGE, i.e., the layers of OLD_BASE_IMAGE must be
+the bottom layers of APP_IMAGE. These layers are replaced with the layers of NEW_BASE_IMAGE, and the
+result is stored as TARGET_IMAGE. The config of APP_IMAGE (env, entrypoint, ...) is kept as it is.
+
+This is useful for picking up the security patches of a base image.
+The application layers must not depend on the files changed between the base images.
+
+Example:
+  nerdctl image rebase example.com/app:1.0 alpine:3.20.0 alpine:3.20.3 example.com/app:1.0-patched
+`
+
+func rebaseCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "rebase [flags] APP_IMAGE OLD_BASE_IMAGE NEW_BASE_IMAGE TARGET_IMAGE",
+		Short:             "Replace the base layers of an image without rebuilding it",
+		Long:              rebaseHelp,
+		Args:              helpers.IsExactArgs(4),
+		RunE:              rebaseAction,
+		ValidArgsFunction: rebaseShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().String("platform", "", "Rebase the images for a specific platform (default: host platform)")
+	cmd.RegisterFlagCompletionFunc("platform", completion.Platforms)
+	return cmd
+}
+
+func processRebaseCommandFlags(cmd *cobra.Command) (types.ImageRebaseOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.ImageRebaseOptions{}, err
+	}
+	platform, err := cmd.Flags().GetString("platform")
+	if err != nil {
+		return types.ImageRebaseOptions{}, err
+	}
+	return types.ImageRebaseOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+		Platform: platform,
+	}, nil
+}
+
+func rebaseAction(cmd *cobra.Command, args []string) error {
+	options, err := processRebaseCommandFlags(cmd)
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return image.Rebase(ctx, client, args[0], args[1], args[2], args[3], options)
+}
+
+func rebaseShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) < 3 {
+		// show image names
+		return completion.ImageNames(cmd)
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,89 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestImageRebase(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.All(
+		require.Not(nerdtest.Docker),
+		nerdtest.CGroup,
+	)
+	testCase.NoParallel = true
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		identifier := data.Identifier()
+		newBase := identifier + "-new-base"
+		app := identifier + "-app"
+		helpers.Ensure("pull", "--quiet", testutil.CommonImage)
+
+		helpers.Ensure("run", "-d", "--name", newBase, testutil.CommonImage, "sleep", nerdtest.Infinity)
+		helpers.Ensure("exec", newBase, "sh", "-euxc", "echo patched > /patched")
+		helpers.Ensure("commit", newBase, newBase)
+
+		helpers.Ensure("run", "-d", "--name", app, testutil.CommonImage, "sleep", nerdtest.Infinity)
+		helpers.Ensure("exec", app, "sh", "-euxc", "echo app > /app")
+		helpers.Ensure("commit", "-c", `CMD ["cat", "/patched", "/app"]`, app, app)
+
+		data.Labels().Set("newBase", newBase)
+		data.Labels().Set("app", app)
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		for _, name := range []string{data.Labels().Get("newBase"), data.Labels().Get("app")} {
+			helpers.Anyhow("rm", "-f", name)
+			helpers.Anyhow("rmi", "-f", name)
+		}
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "app layers are moved onto the new base",
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rmi", "-f", data.Identifier())
+			},
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("image", "rebase", data.Labels().Get("app"), testutil.CommonImage, data.Labels().Get("newBase"), data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Equals("patched\napp\n")),
+		},
+		{
+			Description: "app not based on the old base",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("image", "rebase", testutil.CommonImage, data.Labels().Get("app"), data.Labels().Get("newBase"), data.Identifier())
+			},
+			Expected: test.Expects(1, []error{errors.New("cannot be based on it")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
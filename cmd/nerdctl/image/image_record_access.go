@@ -0,0 +1,130 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"compress/gzip"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+)
+
+const recordAccessHelp = `Run an image and record the files accessed by the container, for creating an eStargz image
+that prioritizes these files for lazy pulling.
+
+The container runs until it exits, until --period elapses, or until a line of its output contains --wait-line.
+The output of the container is printed to stderr.
+
+The record is compatible with 'ctr-remote optimize --record-out' and 'nerdctl image convert --estargz-record-in'.
+It is written to --record-out, or printed to stdout if neither --record-out nor --convert is specified.
+With --convert, the image is converted to an eStargz image with the record.
+
+Linux only. Not supported in rootless mode. Requires experimental mode to be enabled.
+
+Example:
+  nerdctl image record-access --convert example.com/app:esgz example.com/app -- /app --warmup
+`
+
+func recordAccessCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "record-access [flags] IMAGE [-- COMMAND [ARG...]]",
+		Short:             "Record the files accessed by an image, for optimizing eStargz images",
+		Long:              recordAccessHelp,
+		Args:              cobra.MinimumNArgs(1),
+		RunE:              recordAccessAction,
+		ValidArgsFunction: recordAccessShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().String("record-out", "", "Write the record of the accessed files to the file")
+	cmd.Flags().String("convert", "", "Convert the image to an eStargz image with this name, prioritizing the accessed files")
+	cmd.Flags().Int("estargz-compression-level", gzip.BestCompression, "eStargz compression level of the converted image")
+	cmd.Flags().Int("estargz-chunk-size", 0, "eStargz chunk size of the converted image")
+	cmd.Flags().Duration("period", 10*time.Second, "Time to run the container for, before killing it")
+	cmd.Flags().String("wait-line", "", "Kill the container when a line of its stdout contains this string")
+	return cmd
+}
+
+func processRecordAccessCommandFlags(cmd *cobra.Command) (types.ImageRecordAccessOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.ImageRecordAccessOptions{}, err
+	}
+	recordOut, err := cmd.Flags().GetString("record-out")
+	if err != nil {
+		return types.ImageRecordAccessOptions{}, err
+	}
+	convert, err := cmd.Flags().GetString("convert")
+	if err != nil {
+		return types.ImageRecordAccessOptions{}, err
+	}
+	compressionLevel, err := cmd.Flags().GetInt("estargz-compression-level")
+	if err != nil {
+		return types.ImageRecordAccessOptions{}, err
+	}
+	chunkSize, err := cmd.Flags().GetInt("estargz-chunk-size")
+	if err != nil {
+		return types.ImageRecordAccessOptions{}, err
+	}
+	period, err := cmd.Flags().GetDuration("period")
+	if err != nil {
+		return types.ImageRecordAccessOptions{}, err
+	}
+	waitLine, err := cmd.Flags().GetString("wait-line")
+	if err != nil {
+		return types.ImageRecordAccessOptions{}, err
+	}
+	return types.ImageRecordAccessOptions{
+		Stdout:                  cmd.OutOrStdout(),
+		Stderr:                  cmd.ErrOrStderr(),
+		GOptions:                globalOptions,
+		RecordOut:               recordOut,
+		Convert:                 convert,
+		EstargzCompressionLevel: compressionLevel,
+		EstargzChunkSize:        chunkSize,
+		Period:                  period,
+		WaitLine:                waitLine,
+	}, nil
+}
+
+func recordAccessAction(cmd *cobra.Command, args []string) error {
+	options, err := processRecordAccessCommandFlags(cmd)
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return image.RecordAccess(ctx, client, args[0], args[1:], options)
+}
+
+func recordAccessShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		// show image names
+		return completion.ImageNames(cmd)
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 )
 
 func RmiCommand() *cobra.Command {
@@ -77,7 +78,9 @@ func rmiAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
-	return image.Remove(ctx, client, args, options)
+	stdout, finish := helpers.OutputWriter(cmd, options.GOptions, formatter.KindImageRemove, formatter.ItemsFromKeyValueLines)
+	options.Stdout = stdout
+	return finish(image.Remove(ctx, client, args, options))
 }
 
 func rmiShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
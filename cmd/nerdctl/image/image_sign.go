@@ -0,0 +1,104 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+)
+
+const signHelp = `Sign a local image without pushing it.
+
+The signature is stored locally as a signature image referring to the image (the "subject" of the signature),
+and is pushed together with the image by 'nerdctl push'. This allows signing the image in a separate
+pipeline stage, e.g., after building and testing it, and before pushing it.
+
+A cosign signature is stored as REPOSITORY:sha256-<DIGEST>.sig, and a notation signature is stored as
+REPOSITORY:sha256-<DIGEST>.notation-<SIGNATURE>.
+When --cosign-key is not specified, the image is signed with cosign in keyless mode (OIDC).
+
+Example:
+  nerdctl image sign --cosign-key cosign.key example.com/app:1.0
+  nerdctl push example.com/app:1.0
+`
+
+func signCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "sign [flags] IMAGE",
+		Short:             "Sign a local image, to push the signature together with the image",
+		Long:              signHelp,
+		Args:              helpers.IsExactArgs(1),
+		RunE:              signAction,
+		ValidArgsFunction: signShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().String("provider", "cosign", "Signing provider (cosign|notation)")
+	cmd.RegisterFlagCompletionFunc("provider", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"cosign", "notation"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().String("cosign-key", "", "Path to the private key file, KMS URI or Kubernetes Secret for --provider=cosign (keyless mode when not specified)")
+	cmd.Flags().String("notation-key-name", "", "Signing key name for a key previously added to notation's key list for --provider=notation")
+	return cmd
+}
+
+func processSignCommandFlags(cmd *cobra.Command) (types.ImageSignCommandOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.ImageSignCommandOptions{}, err
+	}
+	var signOptions types.ImageSignOptions
+	if signOptions.Provider, err = cmd.Flags().GetString("provider"); err != nil {
+		return types.ImageSignCommandOptions{}, err
+	}
+	if signOptions.CosignKey, err = cmd.Flags().GetString("cosign-key"); err != nil {
+		return types.ImageSignCommandOptions{}, err
+	}
+	if signOptions.NotationKeyName, err = cmd.Flags().GetString("notation-key-name"); err != nil {
+		return types.ImageSignCommandOptions{}, err
+	}
+	return types.ImageSignCommandOptions{
+		Stdout:      cmd.OutOrStdout(),
+		GOptions:    globalOptions,
+		SignOptions: signOptions,
+	}, nil
+}
+
+func signAction(cmd *cobra.Command, args []string) error {
+	options, err := processSignCommandFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return image.Sign(ctx, client, args[0], options)
+}
+
+func signShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// show image names
+	return completion.ImageNames(cmd)
+}
@@ -0,0 +1,105 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest/registry"
+)
+
+func TestImageSignWithCosign(t *testing.T) {
+	dockerfile := fmt.Sprintf(`FROM %s
+CMD ["echo", "nerdctl-build-test-string"]
+	`, testutil.CommonImage)
+
+	nerdtest.Setup()
+
+	var reg *registry.Server
+
+	testCase := &test.Case{
+		Require: require.All(
+			require.Linux,
+			nerdtest.Build,
+			require.Binary("cosign"),
+			require.Not(nerdtest.Docker),
+			nerdtest.Registry,
+		),
+
+		Env: map[string]string{
+			"COSIGN_PASSWORD": "1",
+		},
+
+		Setup: func(data test.Data, helpers test.Helpers) {
+			data.Temp().Save(dockerfile, "Dockerfile")
+			pri, pub := nerdtest.GenerateCosignKeyPair(data, helpers, "1")
+			reg = nerdtest.RegistryWithNoAuth(data, helpers, 0, false)
+			reg.Setup(data, helpers)
+			testImageRef := fmt.Sprintf("%s:%d/%s:one", "127.0.0.1", reg.Port, data.Identifier())
+
+			helpers.Ensure("build", "-t", testImageRef, data.Temp().Path())
+			helpers.Ensure("image", "sign", "--cosign-key="+pri, testImageRef)
+			helpers.Ensure("push", testImageRef)
+
+			data.Labels().Set("public_key", pub)
+			data.Labels().Set("image_ref", testImageRef)
+		},
+
+		Cleanup: func(data test.Data, helpers test.Helpers) {
+			if reg != nil {
+				reg.Cleanup(data, helpers)
+				helpers.Anyhow("rmi", "-f", data.Labels().Get("image_ref"))
+			}
+		},
+
+		SubTests: []*test.Case{
+			{
+				Description: "The signature is stored locally",
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					return helpers.Command("images", "--format", "{{.Repository}}:{{.Tag}}")
+				},
+				Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+					repo := strings.TrimSuffix(data.Labels().Get("image_ref"), ":one")
+					return &test.Expected{
+						Output: expect.Match(regexp.MustCompile(regexp.QuoteMeta(repo) + `:sha256-[0-9a-f]{64}\.sig`)),
+					}
+				},
+			},
+			{
+				Description: "The signature is pushed together with the image",
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					return helpers.Command(
+						"pull", "--quiet", "--verify=cosign",
+						"--cosign-key="+data.Labels().Get("public_key"),
+						data.Labels().Get("image_ref"))
+				},
+				Expected: test.Expects(0, nil, nil),
+			},
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -0,0 +1,117 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+)
+
+func sociCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "soci",
+		Short:         "Manage SOCI (Seekable OCI) indexes for lazy pulling with the soci snapshotter",
+		RunE:          helpers.UnknownSubcommandAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.AddCommand(sociCreateCommand())
+	return cmd
+}
+
+func sociCreateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create [flags] IMAGE",
+		Short: "Create the SOCI index of an image",
+		Long: `Create the SOCI index of a local image, using the soci CLI.
+
+With --push, the index is pushed to the registry of the image as a referrer, so that the image can be lazily pulled
+with "nerdctl pull --snapshotter=soci" and "nerdctl run --snapshotter=soci".
+`,
+		Args:              helpers.IsExactArgs(1),
+		RunE:              sociCreateAction,
+		ValidArgsFunction: sociCreateShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().Bool("push", false, "Push the SOCI index to the registry of the image")
+	// #region soci flags
+	cmd.Flags().Int64("soci-span-size", -1, "Span size that soci index uses to segment layer data. Default is 4 MiB.")
+	cmd.Flags().Int64("soci-min-layer-size", -1, "Minimum layer size to build zTOC for. Smaller layers won't have zTOC and not lazy pulled. Default is 10 MiB.")
+	// #endregion
+	// #region platform flags
+	// platform is defined as StringSlice, not StringArray, to allow specifying "--platform=amd64,arm64"
+	cmd.Flags().StringSlice("platform", []string{}, "Create the SOCI index for a specific platform")
+	cmd.RegisterFlagCompletionFunc("platform", completion.Platforms)
+	cmd.Flags().Bool("all-platforms", false, "Create the SOCI index for all platforms")
+	// #endregion
+	return cmd
+}
+
+func processSociCreateCommandFlags(cmd *cobra.Command) (types.ImageSociCreateOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.ImageSociCreateOptions{}, err
+	}
+	push, err := cmd.Flags().GetBool("push")
+	if err != nil {
+		return types.ImageSociCreateOptions{}, err
+	}
+	sociOpts, err := sociOptions(cmd)
+	if err != nil {
+		return types.ImageSociCreateOptions{}, err
+	}
+	platforms, err := cmd.Flags().GetStringSlice("platform")
+	if err != nil {
+		return types.ImageSociCreateOptions{}, err
+	}
+	allPlatforms, err := cmd.Flags().GetBool("all-platforms")
+	if err != nil {
+		return types.ImageSociCreateOptions{}, err
+	}
+	return types.ImageSociCreateOptions{
+		GOptions:     globalOptions,
+		SociOptions:  sociOpts,
+		Platforms:    platforms,
+		AllPlatforms: allPlatforms,
+		Push:         push,
+	}, nil
+}
+
+func sociCreateAction(cmd *cobra.Command, args []string) error {
+	options, err := processSociCreateCommandFlags(cmd)
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return image.SociCreate(ctx, client, args[0], options)
+}
+
+func sociCreateShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// show image names
+	return completion.ImageNames(cmd)
+}
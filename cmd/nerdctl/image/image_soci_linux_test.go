@@ -0,0 +1,69 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestImageSociCreate(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.All(
+		require.Not(nerdtest.Docker),
+		nerdtest.Soci,
+	)
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "create the index of a local image",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("pull", "--quiet", testutil.UbuntuImage)
+				helpers.Ensure("tag", testutil.UbuntuImage, data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rmi", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("image", "soci", "create", "--soci-span-size=2097152", "--soci-min-layer-size=20971520", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, nil),
+		},
+		{
+			Description: "image not found",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("image", "soci", "create", data.Identifier())
+			},
+			Expected: test.Expects(1, []error{errors.New("the image must be pulled or built first")}, nil),
+		},
+		{
+			Description: "help",
+			Command:     test.Command("image", "soci", "create", "--help"),
+			Expected:    test.Expects(0, nil, expect.Contains("--push")),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -25,12 +25,16 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+	"github.com/containerd/nerdctl/v2/pkg/leaseutil"
 )
 
 func addSquashFlags(cmd *cobra.Command) {
 	cmd.Flags().IntP("last-n-layer", "n", 0, "The number of layers specified for squashing the last N (N=layer-count) must be greater than 1.")
 	cmd.Flags().StringP("author", "a", "nerdctl", `Author (e.g., "nerdctl contributor <nerdctl-dev@example.com>")`)
 	cmd.Flags().StringP("message", "m", "generated by nerdctl squash", "Commit message")
+	cmd.Flags().StringArray("label", nil, "Set a label on the squashed image (KEY=VALUE)")
+	cmd.Flags().Bool("no-unpack", false, "Do not unpack the squashed image into the snapshotter (e.g., for images only to be pushed)")
+	cmd.Flags().Duration("lease-duration", leaseutil.DefaultDuration, "Expiration of the lease holding the intermediate data, renewed during the squash")
 }
 
 // squashCommand returns a new `squash` command to compress the number of layers of the image
@@ -67,6 +71,21 @@ func processSquashCommandFlags(cmd *cobra.Command, args []string) (options types
 	if err != nil {
 		return options, err
 	}
+	label, err := cmd.Flags().GetStringArray("label")
+	if err != nil {
+		return options, err
+	}
+	noUnpack, err := cmd.Flags().GetBool("no-unpack")
+	if err != nil {
+		return options, err
+	}
+	leaseDuration, err := cmd.Flags().GetDuration("lease-duration")
+	if err != nil {
+		return options, err
+	}
+	if leaseDuration <= 0 {
+		return options, fmt.Errorf("invalid lease-duration: %s", leaseDuration)
+	}
 
 	options = types.ImageSquashOptions{
 		GOptions: globalOptions,
@@ -78,6 +97,9 @@ func processSquashCommandFlags(cmd *cobra.Command, args []string) (options types
 		TargetImageName: args[1],
 
 		SquashLayerLastN: layerN,
+		Label:            label,
+		NoUnpack:         noUnpack,
+		LeaseDuration:    leaseDuration,
 	}
 	return options, nil
 }
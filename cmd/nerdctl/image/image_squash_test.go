@@ -18,6 +18,8 @@ package image
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"gotest.tools/v3/assert"
@@ -94,6 +96,70 @@ func TestSquash(t *testing.T) {
 				assert.Equal(t, history[0].Comment, "squash commit", info)
 			}),
 		},
+		{
+			Description: "repeated squash reuses the layer and sets labels",
+			Require: require.All(
+				require.Not(nerdtest.Docker),
+				nerdtest.CGroup,
+			),
+			NoParallel: true,
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				identifier := data.Identifier()
+				helpers.Anyhow("rm", "-f", identifier)
+				helpers.Anyhow("rmi", "-f", identifier)
+				helpers.Anyhow("rmi", "-f", squashIdentifierName(identifier))
+				helpers.Anyhow("rmi", "-f", squashIdentifierName(identifier)+"-again")
+			},
+			Setup: func(data test.Data, helpers test.Helpers) {
+				identifier := data.Identifier()
+				helpers.Ensure("run", "-d", "--name", identifier, testutil.CommonImage, "sleep", nerdtest.Infinity)
+				helpers.Ensure("exec", identifier, "sh", "-euxc", `echo hello > /foo`)
+				helpers.Ensure("commit", "--pause=true", identifier, identifier)
+				helpers.Ensure("image", "squash", "-n", "2", "--label", "squash.source="+identifier, identifier, squashIdentifierName(identifier))
+				helpers.Ensure("image", "squash", "-n", "2", "--label", "squash.source="+identifier, identifier, squashIdentifierName(identifier)+"-again")
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				identifier := data.Identifier()
+				return helpers.Command("image", "inspect", "--mode=native",
+					"--format", `{{index .ImageConfig.Config.Labels "squash.source"}} {{json .Manifest.Layers}}`,
+					squashIdentifierName(identifier), squashIdentifierName(identifier)+"-again")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout string, info string, t *testing.T) {
+						lines := strings.Split(strings.TrimSpace(stdout), "\n")
+						assert.Equal(t, len(lines), 2, info)
+						assert.Assert(t, strings.HasPrefix(lines[0], data.Identifier()+" ["), info)
+						assert.Equal(t, lines[0], lines[1], info)
+					},
+				}
+			},
+		},
+		{
+			Description: "no-unpack",
+			Require: require.All(
+				require.Not(nerdtest.Docker),
+				nerdtest.CGroup,
+			),
+			NoParallel: true,
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				identifier := data.Identifier()
+				helpers.Anyhow("rm", "-f", identifier)
+				helpers.Anyhow("rmi", "-f", identifier)
+				helpers.Anyhow("rmi", "-f", squashIdentifierName(identifier))
+			},
+			Setup: func(data test.Data, helpers test.Helpers) {
+				identifier := data.Identifier()
+				helpers.Ensure("run", "-d", "--name", identifier, testutil.CommonImage, "sleep", nerdtest.Infinity)
+				helpers.Ensure("exec", identifier, "sh", "-euxc", `echo hello > /foo`)
+				helpers.Ensure("commit", "--pause=true", identifier, identifier)
+				helpers.Ensure("image", "squash", "-n", "2", "--no-unpack", identifier, squashIdentifierName(identifier))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("image", "save", "-o", filepath.Join(data.Temp().Path(), "squash.tar"), squashIdentifierName(data.Identifier()))
+			},
+			Expected: test.Expects(0, nil, nil),
+		},
 	}
 
 	testCase.Run(t)
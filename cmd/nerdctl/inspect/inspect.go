@@ -18,10 +18,14 @@ package inspect
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
@@ -32,9 +36,14 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/namespace"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/network"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
 	"github.com/containerd/nerdctl/v2/pkg/formatter"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/imagewalker"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil/volumestore"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
 )
 
 func Command() *cobra.Command {
@@ -53,21 +62,19 @@ func Command() *cobra.Command {
 	return cmd
 }
 
-var validInspectType = map[string]bool{
-	"container": true,
-	"image":     true,
-}
+// inspectTypes lists the object types in the order they are resolved when --type is not specified.
+var inspectTypes = []string{"image", "container", "volume", "network", "namespace"}
 
 func addInspectFlags(cmd *cobra.Command) {
-	cmd.Flags().BoolP("size", "s", false, "Display total file sizes (for containers)")
+	cmd.Flags().BoolP("size", "s", false, "Display total file sizes (for containers and volumes)")
 
 	cmd.Flags().StringP("format", "f", "", "Format the output using the given Go template, e.g, '{{json .}}'")
 	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return []string{"json"}, cobra.ShellCompDirectiveNoFileComp
 	})
-	cmd.Flags().String("type", "", "Return JSON for specified type")
+	cmd.Flags().String("type", "", "Only inspect objects of the given type ("+strings.Join(inspectTypes, "|")+")")
 	cmd.RegisterFlagCompletionFunc("type", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return []string{"image", "container", ""}, cobra.ShellCompDirectiveNoFileComp
+		return inspectTypes, cobra.ShellCompDirectiveNoFileComp
 	})
 	cmd.Flags().String("mode", "dockercompat", `Inspect mode, "dockercompat" for Docker-compatible output, "native" for containerd-native output`)
 	cmd.RegisterFlagCompletionFunc("mode", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -80,113 +87,226 @@ func inspectAction(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	namespace := globalOptions.Namespace
-	address := globalOptions.Address
 	format, err := cmd.Flags().GetString("format")
 	if err != nil {
 		return err
 	}
+	format, err = helpers.InspectFormat(globalOptions, format)
+	if err != nil {
+		return err
+	}
 	inspectType, err := cmd.Flags().GetString("type")
 	if err != nil {
 		return err
 	}
-
-	if len(inspectType) > 0 && !validInspectType[inspectType] {
-		return fmt.Errorf("%q is not a valid value for --type", inspectType)
+	searchTypes := inspectTypes
+	if len(inspectType) > 0 {
+		if !slices.Contains(inspectTypes, inspectType) {
+			return fmt.Errorf("%q is not a valid value for --type", inspectType)
+		}
+		searchTypes = []string{inspectType}
 	}
 
-	// container and image inspect can share the same client, since no `platform`
+	// All the object types can share the same client, since no `platform`
 	// flag will be passed for image inspect.
-	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), namespace, address)
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), globalOptions.Namespace, globalOptions.Address)
 	if err != nil {
 		return err
 	}
 	defer cancel()
 
-	imagewalker := &imagewalker.ImageWalker{
-		Client: client,
-		OnFound: func(ctx context.Context, found imagewalker.Found) error {
-			return nil
-		},
-	}
-
-	containerwalker := &containerwalker.ContainerWalker{
-		Client: client,
-		OnFound: func(ctx context.Context, found containerwalker.Found) error {
-			return nil
-		},
+	x := &inspector{
+		cmd:           cmd,
+		client:        client,
+		globalOptions: globalOptions,
 	}
 
-	inspectImage := len(inspectType) == 0 || inspectType == "image"
-	inspectContainer := len(inspectType) == 0 || inspectType == "container"
-
-	var imageInspectOptions types.ImageInspectOptions
-	var containerInspectOptions types.ContainerInspectOptions
-	if inspectImage {
-		platform := ""
-		imageInspectOptions, err = imagecmd.InspectOptions(cmd, &platform)
+	var errs []error
+	var entries []interface{}
+	for _, req := range args {
+		typ, err := x.resolve(ctx, req, searchTypes)
 		if err != nil {
 			return err
 		}
+		if typ == "" {
+			if len(inspectType) > 0 {
+				errs = append(errs, fmt.Errorf("no such %s %s", inspectType, req))
+			} else {
+				errs = append(errs, fmt.Errorf("no such object %s", req))
+			}
+			continue
+		}
+		if found, err := x.inspect(ctx, typ, req); err != nil {
+			errs = append(errs, err)
+		} else {
+			entries = append(entries, found...)
+		}
 	}
-	if inspectContainer {
-		containerInspectOptions, err = containercmd.InspectOptions(cmd)
+
+	stdout, finish := helpers.OutputWriter(cmd, globalOptions, formatter.KindInspect, formatter.ItemsFromJSONArray)
+	if len(errs) > 0 {
+		return finish(fmt.Errorf("%d errors: %v", len(errs), errs))
+	}
+
+	if formatErr := formatter.FormatSlice(format, stdout, entries); formatErr != nil {
+		log.G(ctx).Error(formatErr)
+	}
+
+	return finish(nil)
+}
+
+// inspector resolves and inspects the objects of any type.
+// The stores are opened on the first use, so that the types that are not needed are never touched.
+type inspector struct {
+	cmd           *cobra.Command
+	client        *containerd.Client
+	globalOptions types.GlobalCommandOptions
+
+	volStore   volumestore.VolumeStore
+	cniEnv     *netutil.CNIEnv
+	namespaces []string
+}
+
+// resolve returns the first type in searchTypes that has an object matching req, or "" if there is none.
+func (x *inspector) resolve(ctx context.Context, req string, searchTypes []string) (string, error) {
+	for _, typ := range searchTypes {
+		found, err := x.exists(ctx, typ, req)
 		if err != nil {
-			return err
+			return "", err
+		}
+		if found {
+			return typ, nil
 		}
 	}
+	return "", nil
+}
 
-	var errs []error
-	var entries []interface{}
-	for _, req := range args {
-		var ni int
-		var nc int
-
-		if inspectImage {
-			ni, err = imagewalker.Walk(ctx, req)
+func (x *inspector) exists(ctx context.Context, typ, req string) (bool, error) {
+	switch typ {
+	case "image":
+		walker := &imagewalker.ImageWalker{
+			Client: x.client,
+			OnFound: func(ctx context.Context, found imagewalker.Found) error {
+				return nil
+			},
+		}
+		n, err := walker.Walk(ctx, req)
+		return n > 0, err
+	case "container":
+		walker := &containerwalker.ContainerWalker{
+			Client: x.client,
+			OnFound: func(ctx context.Context, found containerwalker.Found) error {
+				return nil
+			},
+		}
+		n, err := walker.Walk(ctx, req)
+		return n > 0, err
+	case "volume":
+		if x.volStore == nil {
+			volStore, err := volume.Store(x.globalOptions.Namespace, x.globalOptions.DataRoot, x.globalOptions.Address)
 			if err != nil {
-				return err
+				return false, err
 			}
+			x.volStore = volStore
 		}
-		if inspectContainer {
-			nc, err = containerwalker.Walk(ctx, req)
+		// Names that are not valid volume names are not volumes either
+		_, err := x.volStore.Get(req, false)
+		return err == nil, nil
+	case "network":
+		if x.cniEnv == nil {
+			cniEnv, err := netutil.NewCNIEnv(x.globalOptions.CNIPath, x.globalOptions.CNINetConfPath, netutil.WithNamespace(x.globalOptions.Namespace))
 			if err != nil {
-				return err
+				return false, err
 			}
+			x.cniEnv = cniEnv
 		}
-
-		if ni == 0 && nc == 0 {
-			errs = append(errs, fmt.Errorf("no such object %s", req))
-		} else if ni > 0 {
-			if imageEntries, err := image.Inspect(ctx, client, []string{req}, imageInspectOptions); err != nil {
-				errs = append(errs, err)
-			} else {
-				entries = append(entries, imageEntries...)
-			}
-		} else if nc > 0 {
-			if containerEntries, err := container.Inspect(ctx, client, []string{req}, containerInspectOptions); err != nil {
-				errs = append(errs, err)
-			} else {
-				entries = append(entries, containerEntries...)
+		netLists, errs := x.cniEnv.ListNetworksMatch([]string{req}, true)
+		if len(errs) > 0 {
+			return false, errors.Join(errs...)
+		}
+		return len(netLists[req]) > 0, nil
+	case "namespace":
+		if x.namespaces == nil {
+			namespaces, err := x.client.NamespaceService().List(ctx)
+			if err != nil {
+				return false, err
 			}
+			x.namespaces = namespaces
 		}
+		return slices.Contains(x.namespaces, req), nil
 	}
+	return false, fmt.Errorf("unknown type %q", typ)
+}
 
-	if len(errs) > 0 {
-		return fmt.Errorf("%d errors: %v", len(errs), errs)
-	}
-
-	if formatErr := formatter.FormatSlice(format, cmd.OutOrStdout(), entries); formatErr != nil {
-		log.G(ctx).Error(formatErr)
+func (x *inspector) inspect(ctx context.Context, typ, req string) ([]any, error) {
+	switch typ {
+	case "image":
+		platform := ""
+		options, err := imagecmd.InspectOptions(x.cmd, &platform)
+		if err != nil {
+			return nil, err
+		}
+		return image.Inspect(ctx, x.client, []string{req}, options)
+	case "container":
+		options, err := containercmd.InspectOptions(x.cmd)
+		if err != nil {
+			return nil, err
+		}
+		return container.Inspect(ctx, x.client, []string{req}, options)
+	case "volume":
+		size, err := x.cmd.Flags().GetBool("size")
+		if err != nil {
+			return nil, err
+		}
+		entries, warns, err := volume.InspectEntries([]string{req}, types.VolumeInspectOptions{
+			GOptions: x.globalOptions,
+			Size:     size,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return entries, errors.Join(warns...)
+	case "network":
+		mode, err := x.cmd.Flags().GetString("mode")
+		if err != nil {
+			return nil, err
+		}
+		entries, errs, err := network.InspectEntries(ctx, x.client, types.NetworkInspectOptions{
+			GOptions: x.globalOptions,
+			Mode:     mode,
+			Networks: []string{req},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return entries, errors.Join(errs...)
+	case "namespace":
+		return namespace.InspectEntries(ctx, x.client, []string{req})
 	}
-
-	return nil
+	return nil, fmt.Errorf("unknown type %q", typ)
 }
 
 func inspectShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	// show container names
-	containers, _ := completion.ContainerNames(cmd, nil)
-	// show image names
-	images, _ := completion.ImageNames(cmd)
-	return append(containers, images...), cobra.ShellCompDirectiveNoFileComp
+	inspectType, _ := cmd.Flags().GetString("type")
+	var candidates []string
+	for _, typ := range inspectTypes {
+		if inspectType != "" && inspectType != typ {
+			continue
+		}
+		var names []string
+		switch typ {
+		case "image":
+			names, _ = completion.ImageNames(cmd)
+		case "container":
+			names, _ = completion.ContainerNames(cmd, nil)
+		case "volume":
+			names, _ = completion.VolumeNames(cmd)
+		case "network":
+			names, _ = completion.NetworkNames(cmd, []string{"host", "none"})
+		case "namespace":
+			names, _ = completion.NamespaceNames(cmd, nil, "")
+		}
+		candidates = append(candidates, names...)
+	}
+	return candidates, cobra.ShellCompDirectiveNoFileComp
 }
@@ -18,10 +18,14 @@ package inspect
 
 import (
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 
 	"gotest.tools/v3/assert"
 
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
 	"github.com/containerd/nerdctl/mod/tigron/test"
 
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
@@ -74,3 +78,81 @@ func TestInspectSimpleCase(t *testing.T) {
 
 	testCase.Run(t)
 }
+
+func TestInspectObjectTypes(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("volume", "create", "--label", "type=volume", data.Identifier())
+		helpers.Ensure("network", "create", "--label", "type=network", data.Identifier())
+		helpers.Ensure("run", "-d", "--quiet", "--name", data.Identifier(), testutil.CommonImage, "sleep", nerdtest.Infinity)
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+		helpers.Anyhow("network", "rm", data.Identifier())
+		helpers.Anyhow("volume", "rm", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "the container is resolved before the volume and the network without --type",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("inspect", "--format", "{{.State.Status}}", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Equals("running\n")),
+		},
+		{
+			Description: "volume",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("inspect", "--type", "volume", "--format", "{{json .Labels}}", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Contains(`"type":"volume"`)),
+		},
+		{
+			Description: "network",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("inspect", "--type", "network", "--format", "{{json .Labels}}", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Contains(`"type":"network"`)),
+		},
+		{
+			Description: "namespace",
+			Require:     require.Not(nerdtest.Docker),
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("inspect", "--type", "namespace", "--format", "{{.Name}}", string(helpers.Read(nerdtest.Namespace)))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return test.Expects(0, nil, expect.Equals(string(helpers.Read(nerdtest.Namespace))+"\n"))(data, helpers)
+			},
+		},
+		{
+			Description: "multiple arguments in a single array",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("inspect", "--type", "volume", data.Identifier(), data.Identifier())
+			},
+			Expected: test.Expects(0, nil, func(stdout string, info string, t *testing.T) {
+				var inspectResult []json.RawMessage
+				assert.NilError(t, json.Unmarshal([]byte(stdout), &inspectResult), info)
+				assert.Equal(t, len(inspectResult), 2, info)
+			}),
+		},
+		{
+			Description: "missing object of the given type",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("inspect", "--type", "network", "does-not-exist")
+			},
+			Expected: test.Expects(1, []error{errors.New("no such network does-not-exist")}, nil),
+		},
+		{
+			Description: "invalid type",
+			Require:     require.Not(nerdtest.Docker),
+			Command:     test.Command("inspect", "--type", "secret", "foo"),
+			Expected: test.Expects(1, []error{errors.New(`"secret" is not a valid value for --type`)}, func(stdout string, info string, t *testing.T) {
+				assert.Assert(t, strings.TrimSpace(stdout) == "", info)
+			}),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -31,6 +31,10 @@ func Command() *cobra.Command {
 
 	cmd.AddCommand(
 		newInternalOCIHookCommandCommand(),
+		newInternalUserlandProxyCommand(),
+		newInternalBuildkitdSupervisorCommand(),
+		newInternalFanotifyCommand(),
+		newInternalWatchConfigCommand(),
 	)
 
 	return cmd
@@ -0,0 +1,51 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/pkg/buildkitutil/buildkitd"
+)
+
+func newInternalBuildkitdSupervisorCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:           "buildkitd-supervisor [flags] -- BUILDKITD [ARG...]",
+		Short:         "Supervisor of buildkitd for `nerdctl builder start`",
+		Args:          cobra.MinimumNArgs(1),
+		RunE:          internalBuildkitdSupervisorAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().String("state-dir", "", "State directory of the builder")
+	return cmd
+}
+
+func internalBuildkitdSupervisorAction(cmd *cobra.Command, args []string) error {
+	stateDir, err := cmd.Flags().GetString("state-dir")
+	if err != nil {
+		return err
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	return buildkitd.Supervise(ctx, stateDir, args)
+}
@@ -0,0 +1,39 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package internal
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/pkg/fanotifyutil"
+)
+
+func newInternalFanotifyCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:           "fanotify TARGET",
+		Short:         "Monitor of the accessed files for `nerdctl image record-access`",
+		Args:          cobra.ExactArgs(1),
+		RunE:          internalFanotifyAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	return cmd
+}
+
+func internalFanotifyAction(cmd *cobra.Command, args []string) error {
+	return fanotifyutil.Serve(args[0])
+}
@@ -0,0 +1,77 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/pkg/portutil/userlandproxy"
+)
+
+func newInternalUserlandProxyCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:           "userland-proxy",
+		Short:         "Userland proxy for a published port",
+		Args:          cobra.NoArgs,
+		RunE:          internalUserlandProxyAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().String("proto", "tcp", "Protocol (\"tcp\"|\"udp\")")
+	cmd.Flags().String("host-ip", "0.0.0.0", "Host IP to listen on")
+	cmd.Flags().Int("host-port", 0, "Host port to listen on")
+	cmd.Flags().String("container-ip", "", "Container IP to forward to")
+	cmd.Flags().Int("container-port", 0, "Container port to forward to")
+	return cmd
+}
+
+func internalUserlandProxyAction(cmd *cobra.Command, args []string) error {
+	proto, err := cmd.Flags().GetString("proto")
+	if err != nil {
+		return err
+	}
+	hostIP, err := cmd.Flags().GetString("host-ip")
+	if err != nil {
+		return err
+	}
+	hostPort, err := cmd.Flags().GetInt("host-port")
+	if err != nil {
+		return err
+	}
+	containerIP, err := cmd.Flags().GetString("container-ip")
+	if err != nil {
+		return err
+	}
+	containerPort, err := cmd.Flags().GetInt("container-port")
+	if err != nil {
+		return err
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	return userlandproxy.Run(ctx, &userlandproxy.Proxy{
+		Protocol:      proto,
+		HostIP:        hostIP,
+		HostPort:      hostPort,
+		ContainerIP:   containerIP,
+		ContainerPort: containerPort,
+	})
+}
@@ -0,0 +1,69 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/watchconfig"
+)
+
+func newInternalWatchConfigCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:           "watch-config PATH...",
+		Short:         "Restart or signal a container when the config files change",
+		Args:          cobra.MinimumNArgs(1),
+		RunE:          internalWatchConfigAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().String("id", "", "Container ID")
+	cmd.Flags().String("signal", "", "Signal to send instead of restarting the container")
+	return cmd
+}
+
+func internalWatchConfigAction(cmd *cobra.Command, args []string) error {
+	id, err := cmd.Flags().GetString("id")
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		return errors.New("--id is required")
+	}
+	sig, err := cmd.Flags().GetString("signal")
+	if err != nil {
+		return err
+	}
+	nerdctlCmd, nerdctlArgs := helpers.GlobalFlags(cmd)
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	return watchconfig.Watch(ctx, args, func() {
+		log.L.Infof("config files changed, notifying container %s", id)
+		if err := watchconfig.Notify(nerdctlCmd, nerdctlArgs, id, sig); err != nil {
+			log.L.WithError(err).Errorf("failed to notify container %s", id)
+		}
+	})
+}
@@ -33,6 +33,7 @@ func NewIPFSCommand() *cobra.Command {
 	}
 	cmd.AddCommand(
 		newIPFSRegistryCommand(),
+		newIPFSImageCommand(),
 	)
 	return cmd
 }
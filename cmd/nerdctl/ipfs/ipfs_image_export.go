@@ -0,0 +1,67 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package ipfs
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/ipfs"
+)
+
+func newIPFSImageExportCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:           "export --car FILE CID",
+		Short:         "Export an image on IPFS to a CAR file, for importing it to IPFS on another host.",
+		Args:          cobra.ExactArgs(1),
+		RunE:          ipfsImageExportAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().String("car", "", "CAR file to write, or \"-\" for STDOUT")
+	cmd.Flags().String("ipfs-address", "", "multiaddr of IPFS API (default is pulled from $IPFS_PATH/api file. If $IPFS_PATH env var is not present, it defaults to ~/.ipfs)")
+	return cmd
+}
+
+func processIPFSImageExportOptions(cmd *cobra.Command) (types.IPFSImageExportOptions, error) {
+	car, err := cmd.Flags().GetString("car")
+	if err != nil {
+		return types.IPFSImageExportOptions{}, err
+	}
+	if car == "" {
+		return types.IPFSImageExportOptions{}, errors.New("--car is required")
+	}
+	ipfsAddress, err := cmd.Flags().GetString("ipfs-address")
+	if err != nil {
+		return types.IPFSImageExportOptions{}, err
+	}
+	return types.IPFSImageExportOptions{
+		Stdout:      cmd.OutOrStdout(),
+		IPFSAddress: ipfsAddress,
+		CAR:         car,
+	}, nil
+}
+
+func ipfsImageExportAction(cmd *cobra.Command, args []string) error {
+	options, err := processIPFSImageExportOptions(cmd)
+	if err != nil {
+		return err
+	}
+	return ipfs.ImageExport(cmd.Context(), args[0], options)
+}
@@ -0,0 +1,74 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package ipfs
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/ipfs"
+)
+
+func newIPFSImageImportCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:           "import --car FILE",
+		Short:         "Import a CAR file exported by `nerdctl ipfs image export` to IPFS, and print the CID of the image.",
+		Args:          cobra.NoArgs,
+		RunE:          ipfsImageImportAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().String("car", "", "CAR file to read, or \"-\" for STDIN")
+	cmd.Flags().Bool("pin", true, "pin the imported image")
+	cmd.Flags().String("ipfs-address", "", "multiaddr of IPFS API (default is pulled from $IPFS_PATH/api file. If $IPFS_PATH env var is not present, it defaults to ~/.ipfs)")
+	return cmd
+}
+
+func processIPFSImageImportOptions(cmd *cobra.Command) (types.IPFSImageImportOptions, error) {
+	car, err := cmd.Flags().GetString("car")
+	if err != nil {
+		return types.IPFSImageImportOptions{}, err
+	}
+	if car == "" {
+		return types.IPFSImageImportOptions{}, errors.New("--car is required")
+	}
+	pin, err := cmd.Flags().GetBool("pin")
+	if err != nil {
+		return types.IPFSImageImportOptions{}, err
+	}
+	ipfsAddress, err := cmd.Flags().GetString("ipfs-address")
+	if err != nil {
+		return types.IPFSImageImportOptions{}, err
+	}
+	return types.IPFSImageImportOptions{
+		Stdout:      cmd.OutOrStdout(),
+		Stdin:       cmd.InOrStdin(),
+		IPFSAddress: ipfsAddress,
+		CAR:         car,
+		Pin:         pin,
+	}, nil
+}
+
+func ipfsImageImportAction(cmd *cobra.Command, args []string) error {
+	options, err := processIPFSImageImportOptions(cmd)
+	if err != nil {
+		return err
+	}
+	return ipfs.ImageImport(cmd.Context(), options)
+}
@@ -0,0 +1,87 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package ipfs
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/ipfs"
+)
+
+func newIPFSImagePinCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:           "pin CID",
+		Short:         "Pin all the blobs of an image on IPFS, so that the garbage collection of IPFS does not remove them.",
+		Args:          cobra.ExactArgs(1),
+		RunE:          ipfsImagePinAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	addIPFSImagePinFlags(cmd)
+	return cmd
+}
+
+func newIPFSImageUnpinCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:           "unpin CID",
+		Short:         "Unpin all the blobs of an image on IPFS.",
+		Args:          cobra.ExactArgs(1),
+		RunE:          ipfsImageUnpinAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	addIPFSImagePinFlags(cmd)
+	return cmd
+}
+
+func addIPFSImagePinFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("quiet", "q", false, "Only print the CID of the image, instead of the CIDs of all the blobs")
+	cmd.Flags().String("ipfs-address", "", "multiaddr of IPFS API (default is pulled from $IPFS_PATH/api file. If $IPFS_PATH env var is not present, it defaults to ~/.ipfs)")
+}
+
+func processIPFSImagePinOptions(cmd *cobra.Command) (types.IPFSImagePinOptions, error) {
+	quiet, err := cmd.Flags().GetBool("quiet")
+	if err != nil {
+		return types.IPFSImagePinOptions{}, err
+	}
+	ipfsAddress, err := cmd.Flags().GetString("ipfs-address")
+	if err != nil {
+		return types.IPFSImagePinOptions{}, err
+	}
+	return types.IPFSImagePinOptions{
+		Stdout:      cmd.OutOrStdout(),
+		IPFSAddress: ipfsAddress,
+		Quiet:       quiet,
+	}, nil
+}
+
+func ipfsImagePinAction(cmd *cobra.Command, args []string) error {
+	options, err := processIPFSImagePinOptions(cmd)
+	if err != nil {
+		return err
+	}
+	return ipfs.ImagePin(cmd.Context(), args[0], options)
+}
+
+func ipfsImageUnpinAction(cmd *cobra.Command, args []string) error {
+	options, err := processIPFSImagePinOptions(cmd)
+	if err != nil {
+		return err
+	}
+	return ipfs.ImageUnpin(cmd.Context(), args[0], options)
+}
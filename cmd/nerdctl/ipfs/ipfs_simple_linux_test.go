@@ -65,6 +65,28 @@ func TestIPFSSimple(t *testing.T) {
 			},
 			Expected: test.Expects(0, nil, expect.Equals("hello\n")),
 		},
+		{
+			Description: "with CAR export and import",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				data.Labels().Set(mainImageCIDKey, pushToIPFS(helpers, testutil.CommonImage))
+				helpers.Ensure("ipfs", "image", "export", "--car", data.Temp().Path("image.car"), data.Labels().Get(mainImageCIDKey))
+				helpers.Ensure("ipfs", "image", "unpin", data.Labels().Get(mainImageCIDKey))
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				if data.Labels().Get(mainImageCIDKey) != "" {
+					helpers.Anyhow("ipfs", "image", "pin", data.Labels().Get(mainImageCIDKey))
+				}
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("ipfs", "image", "import", "--car", data.Temp().Path("image.car"))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.Equals(data.Labels().Get(mainImageCIDKey) + "\n"),
+				}
+			},
+		},
 		{
 			Description: "with stargz snapshotter",
 			NoParallel:  true,
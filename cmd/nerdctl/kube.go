@@ -0,0 +1,206 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+)
+
+// kubeNamespace is the containerd namespace of the CRI plugin, i.e., of the pod containers of Kubernetes.
+const kubeNamespace = "k8s.io"
+
+type kubePolicy int
+
+const (
+	// kubeAllowed is for the commands that only read the state, and for the ones that do not touch the pod containers,
+	// e.g., `nerdctl commit` and `nerdctl pull`.
+	kubeAllowed kubePolicy = iota
+	// kubeGated is for the commands that are safe alongside kubelet, but may interfere with the pods.
+	// They require --i-know-what-i-am-doing.
+	kubeGated
+	// kubeRefused is for the commands that create or remove containers, or change their lifecycle,
+	// as the pod containers are managed by kubelet. This is the default for the commands that are not listed in
+	// kubeCommandPolicies.
+	kubeRefused
+)
+
+// kubeCommandPolicies lists the commands that are allowed or gated in the "k8s.io" namespace.
+// The keys are the command paths without the leading "nerdctl", e.g., "container logs".
+// The commands that are not listed here are refused, so that a new command that creates, removes,
+// or changes containers cannot slip through.
+var kubeCommandPolicies = map[string]kubePolicy{
+	// the root command, the help, and the shell completion
+	"":                 kubeAllowed,
+	"help":             kubeAllowed,
+	"completion":       kubeAllowed,
+	"__complete":       kubeAllowed,
+	"__completeNoDesc": kubeAllowed,
+
+	// read-only commands
+	"apparmor inspect":            kubeAllowed,
+	"apparmor ls":                 kubeAllowed,
+	"builder binfmt ls":           kubeAllowed,
+	"builder ls":                  kubeAllowed,
+	"compose config":              kubeAllowed,
+	"compose images":              kubeAllowed,
+	"compose outdated":            kubeAllowed,
+	"compose port":                kubeAllowed,
+	"compose ps":                  kubeAllowed,
+	"compose top":                 kubeAllowed,
+	"compose version":             kubeAllowed,
+	"container diff":              kubeAllowed,
+	"container export":            kubeAllowed,
+	"container inspect":           kubeAllowed,
+	"container ls":                kubeAllowed,
+	"container port":              kubeAllowed,
+	"container restart-policy ls": kubeAllowed,
+	"container wait":              kubeAllowed,
+	"context inspect":             kubeAllowed,
+	"context ls":                  kubeAllowed,
+	"diff":                        kubeAllowed,
+	"events":                      kubeAllowed,
+	"export":                      kubeAllowed,
+	"history":                     kubeAllowed,
+	"image history":               kubeAllowed,
+	"image inspect":               kubeAllowed,
+	"image ls":                    kubeAllowed,
+	"image outdated":              kubeAllowed,
+	"images":                      kubeAllowed,
+	"info":                        kubeAllowed,
+	"inspect":                     kubeAllowed,
+	"machine ls":                  kubeAllowed,
+	"namespace inspect":           kubeAllowed,
+	"namespace ls":                kubeAllowed,
+	"network inspect":             kubeAllowed,
+	"network ls":                  kubeAllowed,
+	"port":                        kubeAllowed,
+	"ps":                          kubeAllowed,
+	"secret ls":                   kubeAllowed,
+	"system bypass4netnsd status": kubeAllowed,
+	"system check-ports":          kubeAllowed,
+	"system df":                   kubeAllowed,
+	"system events":               kubeAllowed,
+	"system info":                 kubeAllowed,
+	"top":                         kubeAllowed,
+	"version":                     kubeAllowed,
+	"volume export":               kubeAllowed,
+	"volume inspect":              kubeAllowed,
+	"volume ls":                   kubeAllowed,
+	"volume snapshot ls":          kubeAllowed,
+	"wait":                        kubeAllowed,
+
+	// the commands that build, transfer, or convert images without touching the pod containers
+	"build":               kubeAllowed,
+	"builder build":       kubeAllowed,
+	"commit":              kubeAllowed,
+	"compose build":       kubeAllowed,
+	"compose lock":        kubeAllowed,
+	"compose pull":        kubeAllowed,
+	"compose push":        kubeAllowed,
+	"container commit":    kubeAllowed,
+	"image build":         kubeAllowed,
+	"image convert":       kubeAllowed,
+	"image decrypt":       kubeAllowed,
+	"image edit":          kubeAllowed,
+	"image encrypt":       kubeAllowed,
+	"image load":          kubeAllowed,
+	"image nydusify":      kubeAllowed,
+	"image promote":       kubeAllowed,
+	"image pull":          kubeAllowed,
+	"image push":          kubeAllowed,
+	"image rebase":        kubeAllowed,
+	"image save":          kubeAllowed,
+	"image sign":          kubeAllowed,
+	"image soci create":   kubeAllowed,
+	"image squash":        kubeAllowed,
+	"image tag":           kubeAllowed,
+	"ipfs image export":   kubeAllowed,
+	"ipfs image import":   kubeAllowed,
+	"ipfs image pin":      kubeAllowed,
+	"ipfs image unpin":    kubeAllowed,
+	"ipfs registry serve": kubeAllowed,
+	"load":                kubeAllowed,
+	"login":               kubeAllowed,
+	"logout":              kubeAllowed,
+	"p2p serve":           kubeAllowed,
+	"pull":                kubeAllowed,
+	"push":                kubeAllowed,
+	"save":                kubeAllowed,
+	"tag":                 kubeAllowed,
+
+	// the internal commands are only executed by nerdctl itself, e.g., as the OCI hooks of the existing containers
+	"internal buildkitd-supervisor": kubeAllowed,
+	"internal fanotify":             kubeAllowed,
+	"internal oci-hook":             kubeAllowed,
+	"internal userland-proxy":       kubeAllowed,
+	"internal watch-config":         kubeAllowed,
+
+	// the commands that are safe alongside kubelet, but may interfere with the pods
+	"attach":           kubeGated,
+	"compose cp":       kubeGated,
+	"compose exec":     kubeGated,
+	"compose logs":     kubeGated,
+	"container attach": kubeGated,
+	"container cp":     kubeGated,
+	"container exec":   kubeGated,
+	"container logs":   kubeGated,
+	"container stats":  kubeGated,
+	"cp":               kubeGated,
+	"exec":             kubeGated,
+	"image prune":      kubeGated,
+	"image rm":         kubeGated,
+	"logs":             kubeGated,
+	"rmi":              kubeGated,
+	"stats":            kubeGated,
+}
+
+// kubeCommandPolicy returns the policy of the command in the "k8s.io" namespace.
+// The commands that are not known to be safe are refused.
+func kubeCommandPolicy(commands []string) kubePolicy {
+	if len(commands) > 0 {
+		commands = commands[1:]
+	}
+	if policy, ok := kubeCommandPolicies[strings.Join(commands, " ")]; ok {
+		return policy
+	}
+	return kubeRefused
+}
+
+// checkKubeNamespace refuses the commands that would interfere with kubelet in the "k8s.io" namespace,
+// unless they are safe alongside kubelet and --i-know-what-i-am-doing is specified.
+func checkKubeNamespace(cmd *cobra.Command, globalOptions types.GlobalCommandOptions) error {
+	if globalOptions.Namespace != kubeNamespace {
+		return nil
+	}
+	commands := commandNames(cmd)
+	switch kubeCommandPolicy(commands) {
+	case kubeGated:
+		if !globalOptions.KubeReadWrite {
+			return fmt.Errorf("%q in the %q namespace may interfere with the pods managed by kubelet, specify --i-know-what-i-am-doing to proceed",
+				strings.Join(commands, " "), kubeNamespace)
+		}
+	case kubeRefused:
+		return fmt.Errorf("%q is not supported in the %q namespace, as the pod containers are managed by kubelet (use kubectl instead)",
+			strings.Join(commands, " "), kubeNamespace)
+	}
+	return nil
+}
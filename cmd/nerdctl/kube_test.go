@@ -0,0 +1,166 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestKubeCommandPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		commands []string
+		expected kubePolicy
+	}{
+		{[]string{"nerdctl"}, kubeAllowed},
+		{[]string{"nerdctl", "ps"}, kubeAllowed},
+		{[]string{"nerdctl", "container", "inspect"}, kubeAllowed},
+		{[]string{"nerdctl", "pull"}, kubeAllowed},
+		{[]string{"nerdctl", "image", "ls"}, kubeAllowed},
+		{[]string{"nerdctl", "compose", "ps"}, kubeAllowed},
+		{[]string{"nerdctl", "exec"}, kubeGated},
+		{[]string{"nerdctl", "container", "logs"}, kubeGated},
+		{[]string{"nerdctl", "rmi"}, kubeGated},
+		{[]string{"nerdctl", "image", "rm"}, kubeGated},
+		{[]string{"nerdctl", "image", "prune"}, kubeGated},
+		{[]string{"nerdctl", "run"}, kubeRefused},
+		{[]string{"nerdctl", "container", "prune"}, kubeRefused},
+		{[]string{"nerdctl", "system", "prune"}, kubeRefused},
+		{[]string{"nerdctl", "compose", "up"}, kubeRefused},
+		{[]string{"nerdctl", "compose", "down"}, kubeRefused},
+		{[]string{"nerdctl", "compose", "rm"}, kubeRefused},
+		{[]string{"nerdctl", "debug"}, kubeRefused},
+		{[]string{"nerdctl", "container", "debug"}, kubeRefused},
+		{[]string{"nerdctl", "container", "auto-update"}, kubeRefused},
+		{[]string{"nerdctl", "container", "adopt"}, kubeRefused},
+		{[]string{"nerdctl", "container", "publish"}, kubeRefused},
+		{[]string{"nerdctl", "container", "unpublish"}, kubeRefused},
+		{[]string{"nerdctl", "container", "restart-policy", "set"}, kubeRefused},
+		{[]string{"nerdctl", "container", "restart-policy", "ls"}, kubeAllowed},
+		{[]string{"nerdctl", "system", "gc"}, kubeRefused},
+		{[]string{"nerdctl", "system", "watchdog"}, kubeRefused},
+		{[]string{"nerdctl", "system", "serve"}, kubeRefused},
+		{[]string{"nerdctl", "system", "docker-api"}, kubeRefused},
+		{[]string{"nerdctl", "no-such-command"}, kubeRefused},
+	} {
+		assert.Equal(t, kubeCommandPolicy(tc.commands), tc.expected, "%v", tc.commands)
+	}
+	for _, cmd := range []string{"kill", "stop", "restart", "pause", "unpause", "start", "update", "rename"} {
+		assert.Equal(t, kubeCommandPolicy([]string{"nerdctl", cmd}), kubeRefused, cmd)
+		assert.Equal(t, kubeCommandPolicy([]string{"nerdctl", "container", cmd}), kubeRefused, "container "+cmd)
+	}
+}
+
+// TestKubeCommandPolicies checks that every command listed in kubeCommandPolicies exists,
+// so that a renamed command is not silently refused.
+func TestKubeCommandPolicies(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("some of the listed commands are only available on Linux")
+	}
+	app, err := newApp()
+	assert.NilError(t, err)
+	for path := range kubeCommandPolicies {
+		if path == "" || strings.HasPrefix(path, "__") || path == "help" || path == "completion" {
+			continue
+		}
+		cmd, rest, err := app.Find(strings.Fields(path))
+		assert.NilError(t, err, path)
+		assert.Assert(t, len(rest) == 0 && cmd.Runnable(), "%q is not a command", path)
+		assert.Equal(t, strings.Join(commandNames(cmd)[1:], " "), path)
+	}
+}
+
+func TestKubeNamespaceGuard(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "run is refused",
+			Command:     test.Command("--namespace=k8s.io", "run", "--rm", testutil.CommonImage, "true"),
+			Expected:    test.Expects(1, []error{errors.New("pod containers are managed by kubelet")}, nil),
+		},
+		{
+			Description: "rm is refused even with --i-know-what-i-am-doing",
+			Command:     test.Command("--namespace=k8s.io", "--i-know-what-i-am-doing", "container", "rm", "foo"),
+			Expected:    test.Expects(1, []error{errors.New("pod containers are managed by kubelet")}, nil),
+		},
+		{
+			Description: "exec requires --i-know-what-i-am-doing",
+			Command:     test.Command("--namespace=k8s.io", "exec", "foo", "true"),
+			Expected:    test.Expects(1, []error{errors.New("specify --i-know-what-i-am-doing")}, nil),
+		},
+		{
+			Description: "image prune requires --i-know-what-i-am-doing",
+			Command:     test.Command("--namespace=k8s.io", "image", "prune", "--force"),
+			Expected:    test.Expects(1, []error{errors.New("specify --i-know-what-i-am-doing")}, nil),
+		},
+		{
+			Description: "stop is refused",
+			Command:     test.Command("--namespace=k8s.io", "--i-know-what-i-am-doing", "stop", "foo"),
+			Expected:    test.Expects(1, []error{errors.New("pod containers are managed by kubelet")}, nil),
+		},
+		{
+			Description: "container kill is refused",
+			Command:     test.Command("--namespace=k8s.io", "container", "kill", "foo"),
+			Expected:    test.Expects(1, []error{errors.New("pod containers are managed by kubelet")}, nil),
+		},
+		{
+			Description: "compose down is refused",
+			Command:     test.Command("--namespace=k8s.io", "compose", "down"),
+			Expected:    test.Expects(1, []error{errors.New("pod containers are managed by kubelet")}, nil),
+		},
+		{
+			Description: "rmi requires --i-know-what-i-am-doing",
+			Command:     test.Command("--namespace=k8s.io", "rmi", "nonexistent-"+t.Name()),
+			Expected:    test.Expects(1, []error{errors.New("specify --i-know-what-i-am-doing")}, nil),
+		},
+		{
+			Description: "debug is refused",
+			Command:     test.Command("--namespace=k8s.io", "--i-know-what-i-am-doing", "debug", "foo"),
+			Expected:    test.Expects(1, []error{errors.New("pod containers are managed by kubelet")}, nil),
+		},
+		{
+			Description: "container adopt is refused",
+			Command:     test.Command("--namespace=k8s.io", "container", "adopt", "foo"),
+			Expected:    test.Expects(1, []error{errors.New("pod containers are managed by kubelet")}, nil),
+		},
+		{
+			Description: "system gc is refused",
+			Command:     test.Command("--namespace=k8s.io", "system", "gc"),
+			Expected:    test.Expects(1, []error{errors.New("pod containers are managed by kubelet")}, nil),
+		},
+		{
+			Description: "other namespaces are not guarded",
+			Command:     test.Command("exec", "nonexistent-"+t.Name(), "true"),
+			Expected:    test.Expects(1, []error{errors.New("no such container")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
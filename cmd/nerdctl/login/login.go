@@ -41,7 +41,7 @@ func Command() *cobra.Command {
 	}
 	cmd.Flags().StringP("username", "u", "", "Username")
 	cmd.Flags().StringP("password", "p", "", "Password")
-	cmd.Flags().Bool("password-stdin", false, "Take the password from stdin")
+	cmd.Flags().Bool("password-stdin", false, "Take the password from stdin, or an identity token when --username is not set")
 	return cmd
 }
 
@@ -75,11 +75,8 @@ func loginOptions(cmd *cobra.Command) (types.LoginCommandOptions, error) {
 		}
 	}
 
+	var identityToken string
 	if passwordStdin {
-		if username == "" {
-			return types.LoginCommandOptions{}, errors.New("must provide --username with --password-stdin")
-		}
-
 		contents, err := io.ReadAll(cmd.InOrStdin())
 		if err != nil {
 			return types.LoginCommandOptions{}, err
@@ -87,11 +84,19 @@ func loginOptions(cmd *cobra.Command) (types.LoginCommandOptions, error) {
 
 		password = strings.TrimSuffix(string(contents), "\n")
 		password = strings.TrimSuffix(password, "\r")
+		if password == "" {
+			return types.LoginCommandOptions{}, errors.New("empty password or identity token from stdin")
+		}
+		if username == "" {
+			// Without a username, the secret is an OAuth2 refresh token, e.g., from `az acr login --expose-token`
+			identityToken, password = password, ""
+		}
 	}
 	return types.LoginCommandOptions{
-		GOptions: globalOptions,
-		Username: username,
-		Password: password,
+		GOptions:      globalOptions,
+		Username:      username,
+		Password:      password,
+		IdentityToken: identityToken,
 	}, nil
 }
 
@@ -17,15 +17,20 @@
 package login
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/spf13/cobra"
 
 	"github.com/containerd/log"
 
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/logout"
 )
 
 func LogoutCommand() *cobra.Command {
-	return &cobra.Command{
+	var cmd = &cobra.Command{
 		Use:               "logout [flags] [SERVER]",
 		Args:              cobra.MaximumNArgs(1),
 		Short:             "Log out from a container registry",
@@ -34,15 +39,32 @@ func LogoutCommand() *cobra.Command {
 		SilenceUsage:      true,
 		SilenceErrors:     true,
 	}
+	cmd.Flags().Bool("all", false, "Log out from all registries")
+	return cmd
 }
 
 func logoutAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	all, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		return err
+	}
+	if all {
+		if len(args) > 0 {
+			return errors.New("--all cannot be used with a server")
+		}
+		return logoutAllAction(cmd, globalOptions)
+	}
+
 	logoutServer := ""
 	if len(args) > 0 {
 		logoutServer = args[0]
 	}
 
-	errGroup, err := logout.Logout(cmd.Context(), logoutServer)
+	errGroup, err := logout.Logout(cmd.Context(), logoutServer, globalOptions)
 	if err != nil {
 		log.L.WithError(err).Errorf("Failed to erase credentials for: %s", logoutServer)
 	}
@@ -56,8 +78,26 @@ func logoutAction(cmd *cobra.Command, args []string) error {
 	return err
 }
 
+func logoutAllAction(cmd *cobra.Command, globalOptions types.GlobalCommandOptions) error {
+	errGroup, err := logout.LogoutAll(cmd.Context(), globalOptions)
+	for server, v := range errGroup {
+		log.L.WithError(v).Errorf("Failed to erase credentials for: %s", server)
+	}
+	if err != nil {
+		return err
+	}
+	if len(errGroup) > 0 {
+		return fmt.Errorf("failed to erase the credentials of %d registries", len(errGroup))
+	}
+	return nil
+}
+
 func logoutShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	candidates, err := logout.ShellCompletion()
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	candidates, err := logout.ShellCompletion(globalOptions)
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
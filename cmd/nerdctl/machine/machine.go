@@ -0,0 +1,60 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package machine
+
+import (
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/machine"
+	"github.com/containerd/nerdctl/v2/pkg/contextstore"
+)
+
+func Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Annotations:   map[string]string{helpers.Category: helpers.Management},
+		Use:           "machine",
+		Short:         "Manage Linux virtual machines with containerd (Lima)",
+		Long:          "A machine is a Linux virtual machine with containerd, created with Lima (https://lima-vm.io), and paired with the context \"lima-<NAME>\"",
+		RunE:          helpers.UnknownSubcommandAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.AddCommand(
+		createCommand(),
+		startCommand(),
+		stopCommand(),
+		listCommand(),
+		removeCommand(),
+	)
+	return cmd
+}
+
+// newContextStore returns the context store next to nerdctl.toml.
+func newContextStore() (contextstore.ContextStore, error) {
+	return contextstore.New(filepath.Dir(helpers.NerdctlTOMLPath()))
+}
+
+// machineName returns the machine name in args, or the default name.
+func machineName(args []string) string {
+	if len(args) == 0 {
+		return machine.DefaultName
+	}
+	return args[0]
+}
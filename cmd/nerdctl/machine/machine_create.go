@@ -0,0 +1,89 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package machine
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/machine"
+)
+
+func createCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "create [flags] [MACHINE]",
+		Short:         "Create and start a machine, and create its context",
+		Long:          "Create and start a machine (default: \"" + machine.DefaultName + "\"), and create its context \"lima-<MACHINE>\"",
+		Args:          cobra.MaximumNArgs(1),
+		RunE:          createAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().Int("cpus", 0, "Number of CPUs (default: the default of Lima)")
+	cmd.Flags().Float64("memory", 0, "Memory size in GiB (default: the default of Lima)")
+	cmd.Flags().Float64("disk", 0, "Disk size in GiB (default: the default of Lima)")
+	cmd.Flags().String("vm-type", "", `Type of the virtual machine ("qemu"|"vz"|"wsl2") (default: the default of Lima)`)
+	cmd.RegisterFlagCompletionFunc("vm-type", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"qemu", "vz", "wsl2"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().Bool("use", true, "Set the context of the machine as the current context")
+	return cmd
+}
+
+func createOptions(cmd *cobra.Command) (types.MachineCreateOptions, error) {
+	cpus, err := cmd.Flags().GetInt("cpus")
+	if err != nil {
+		return types.MachineCreateOptions{}, err
+	}
+	memory, err := cmd.Flags().GetFloat64("memory")
+	if err != nil {
+		return types.MachineCreateOptions{}, err
+	}
+	disk, err := cmd.Flags().GetFloat64("disk")
+	if err != nil {
+		return types.MachineCreateOptions{}, err
+	}
+	vmType, err := cmd.Flags().GetString("vm-type")
+	if err != nil {
+		return types.MachineCreateOptions{}, err
+	}
+	use, err := cmd.Flags().GetBool("use")
+	if err != nil {
+		return types.MachineCreateOptions{}, err
+	}
+	return types.MachineCreateOptions{
+		Stdout: cmd.OutOrStdout(),
+		Stderr: cmd.ErrOrStderr(),
+		CPUs:   cpus,
+		Memory: memory,
+		Disk:   disk,
+		VMType: vmType,
+		Use:    use,
+	}, nil
+}
+
+func createAction(cmd *cobra.Command, args []string) error {
+	options, err := createOptions(cmd)
+	if err != nil {
+		return err
+	}
+	cs, err := newContextStore()
+	if err != nil {
+		return err
+	}
+	return machine.Create(cmd.Context(), cs, machineName(args), options)
+}
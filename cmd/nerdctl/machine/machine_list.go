@@ -0,0 +1,82 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package machine
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/machine"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+)
+
+func listCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "ls",
+		Aliases:       []string{"list"},
+		Short:         "List machines. The machine of the current context is marked with \"*\".",
+		Args:          cobra.NoArgs,
+		RunE:          listAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().BoolP("quiet", "q", false, "Only display machine names")
+	cmd.Flags().String("format", "", "Format the output using the given Go template, e.g, '{{json .}}', 'table', 'wide'")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "table", "wide"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	return cmd
+}
+
+func listOptions(cmd *cobra.Command, globalOptions types.GlobalCommandOptions) (types.MachineListOptions, error) {
+	quiet, err := cmd.Flags().GetBool("quiet")
+	if err != nil {
+		return types.MachineListOptions{}, err
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return types.MachineListOptions{}, err
+	}
+	format, err = helpers.ListFormat(globalOptions, format, quiet)
+	if err != nil {
+		return types.MachineListOptions{}, err
+	}
+	return types.MachineListOptions{
+		Stdout: cmd.OutOrStdout(),
+		Quiet:  quiet,
+		Format: format,
+	}, nil
+}
+
+func listAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	options, err := listOptions(cmd, globalOptions)
+	if err != nil {
+		return err
+	}
+	cs, err := newContextStore()
+	if err != nil {
+		return err
+	}
+	stdout, finish := helpers.OutputWriter(cmd, globalOptions, formatter.KindMachineList, formatter.ItemsFromJSONLines)
+	options.Stdout = stdout
+	return finish(machine.List(cmd.Context(), cs, options))
+}
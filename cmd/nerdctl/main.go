@@ -17,14 +17,12 @@
 package main
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"runtime"
 	"strings"
 
 	"github.com/fatih/color"
-	"github.com/pelletier/go-toml/v2"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
@@ -34,21 +32,26 @@ import (
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/compose"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/container"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/context"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/dash"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/image"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/inspect"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/internal"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/ipfs"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/login"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/machine"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/namespace"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/network"
+	p2pcmd "github.com/containerd/nerdctl/v2/cmd/nerdctl/p2p"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/secret"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/system"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/volume"
-	"github.com/containerd/nerdctl/v2/pkg/config"
-	ncdefaults "github.com/containerd/nerdctl/v2/pkg/defaults"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/errutil"
 	"github.com/containerd/nerdctl/v2/pkg/logging"
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+	"github.com/containerd/nerdctl/v2/pkg/sshutil"
 	"github.com/containerd/nerdctl/v2/pkg/store"
 	"github.com/containerd/nerdctl/v2/pkg/version"
 )
@@ -146,56 +149,63 @@ func xmain() error {
 }
 
 func initRootCmdFlags(rootCmd *cobra.Command, tomlPath string) (*pflag.FlagSet, error) {
-	cfg := config.New()
-	if r, err := os.Open(tomlPath); err == nil {
-		log.L.Debugf("Loading config from %q", tomlPath)
-		defer r.Close()
-		dec := toml.NewDecoder(r).DisallowUnknownFields() // set Strict to detect typo
-		if err := dec.Decode(cfg); err != nil {
-			return nil, fmt.Errorf("failed to load nerdctl config (not daemon config) from %q (Hint: don't mix up daemon's `config.toml` with `nerdctl.toml`): %w", tomlPath, err)
-		}
-		log.L.Debugf("Loaded config %+v", cfg)
-	} else {
-		log.L.WithError(err).Debugf("Not loading config from %q", tomlPath)
-		if !errors.Is(err, os.ErrNotExist) {
-			return nil, err
-		}
+	cfg, err := helpers.LoadNerdctlTOML(tomlPath)
+	if err != nil {
+		return nil, err
 	}
 	aliasToBeInherited := pflag.NewFlagSet(rootCmd.Name(), pflag.ExitOnError)
 
 	rootCmd.PersistentFlags().Bool("debug", cfg.Debug, "debug mode")
 	rootCmd.PersistentFlags().Bool("debug-full", cfg.DebugFull, "debug mode (with full output)")
+	helpers.AddPersistentStringFlag(rootCmd, "context", nil, nil, nil, aliasToBeInherited, "", "NERDCTL_CONTEXT", `Name of the context to use, overriding the one set with "nerdctl context use"`)
+	rootCmd.RegisterFlagCompletionFunc("context", completion.ContextNames)
 	// -a is aliases (conflicts with nerdctl images -a)
-	helpers.AddPersistentStringFlag(rootCmd, "address", []string{"a", "H"}, nil, []string{"host"}, aliasToBeInherited, cfg.Address, "CONTAINERD_ADDRESS", `containerd address, optionally with "unix://" prefix`)
+	helpers.AddPersistentStringFlag(rootCmd, "address", []string{"a", "H"}, nil, []string{"host"}, aliasToBeInherited, cfg.Address, "CONTAINERD_ADDRESS", `containerd address, optionally with "unix://" prefix, or "ssh://[USER@]HOST[:PORT][/PATH]" for the containerd on a remote host, or "tcp://HOST:PORT" for the containerd behind a TLS proxy`)
+	helpers.AddPersistentStringFlag(rootCmd, "tlscacert", nil, nil, nil, aliasToBeInherited, cfg.TLSCACert, "NERDCTL_TLSCACERT", `CA certificate for verifying the containerd at a "tcp://" address, defaults to the system roots`)
+	helpers.AddPersistentStringFlag(rootCmd, "tlscert", nil, nil, nil, aliasToBeInherited, cfg.TLSCert, "NERDCTL_TLSCERT", `Client certificate for connecting to the containerd at a "tcp://" address`)
+	helpers.AddPersistentStringFlag(rootCmd, "tlskey", nil, nil, nil, aliasToBeInherited, cfg.TLSKey, "NERDCTL_TLSKEY", `Client key for connecting to the containerd at a "tcp://" address`)
+	helpers.AddPersistentStringFlag(rootCmd, "tls-spiffe-id", nil, nil, nil, aliasToBeInherited, cfg.TLSSPIFFEID, "NERDCTL_TLS_SPIFFE_ID", `SPIFFE ID that the server certificate of a "tcp://" address must have, e.g., "spiffe://example.org/containerd"`)
+	helpers.AddPersistentStringFlag(rootCmd, "tls-spiffe-trust-domain", nil, nil, nil, aliasToBeInherited, cfg.TLSSPIFFETrustDomain, "NERDCTL_TLS_SPIFFE_TRUST_DOMAIN", `Trust domain that the SPIFFE ID of the server certificate of a "tcp://" address must belong to, e.g., "example.org"`)
 	// -n is aliases (conflicts with nerdctl logs -n)
 	helpers.AddPersistentStringFlag(rootCmd, "namespace", []string{"n"}, nil, nil, aliasToBeInherited, cfg.Namespace, "CONTAINERD_NAMESPACE", `containerd namespace, such as "moby" for Docker, "k8s.io" for Kubernetes`)
 	rootCmd.RegisterFlagCompletionFunc("namespace", completion.NamespaceNames)
 	helpers.AddPersistentStringFlag(rootCmd, "snapshotter", nil, nil, []string{"storage-driver"}, aliasToBeInherited, cfg.Snapshotter, "CONTAINERD_SNAPSHOTTER", "containerd snapshotter")
 	rootCmd.RegisterFlagCompletionFunc("snapshotter", completion.SnapshotterNames)
 	rootCmd.RegisterFlagCompletionFunc("storage-driver", completion.SnapshotterNames)
+	helpers.AddPersistentStringFlag(rootCmd, "snapshotter-fallback", nil, nil, nil, aliasToBeInherited, cfg.SnapshotterFallback, "NERDCTL_SNAPSHOTTER_FALLBACK", `Snapshotter to fall back to when a remote snapshotter fails to prepare the snapshots of an image, e.g., "overlayfs"`)
+	rootCmd.RegisterFlagCompletionFunc("snapshotter-fallback", completion.SnapshotterNames)
 	helpers.AddPersistentStringFlag(rootCmd, "cni-path", nil, nil, nil, aliasToBeInherited, cfg.CNIPath, "CNI_PATH", "cni plugins binary directory")
 	helpers.AddPersistentStringFlag(rootCmd, "cni-netconfpath", nil, nil, nil, aliasToBeInherited, cfg.CNINetConfPath, "NETCONFPATH", "cni config directory")
 	rootCmd.PersistentFlags().String("data-root", cfg.DataRoot, "Root directory of persistent nerdctl state (managed by nerdctl, not by containerd)")
 	rootCmd.PersistentFlags().String("cgroup-manager", cfg.CgroupManager, `Cgroup manager to use ("cgroupfs"|"systemd")`)
 	rootCmd.RegisterFlagCompletionFunc("cgroup-manager", completion.CgroupManagerNames)
-	rootCmd.PersistentFlags().Bool("insecure-registry", cfg.InsecureRegistry, "skips verifying HTTPS certs, and allows falling back to plain HTTP")
+	rootCmd.PersistentFlags().Var(helpers.NewInsecureRegistryValue(cfg.InsecureRegistry, cfg.InsecureRegistries), "insecure-registry",
+		`skips verifying HTTPS certs, and allows falling back to plain HTTP, for all the registries, or for the comma-separated registries (e.g., "registry.example.com:5000,10.0.0.0/8")`)
+	rootCmd.PersistentFlags().Lookup("insecure-registry").NoOptDefVal = "true"
 	// hosts-dir is defined as StringSlice, not StringArray, to allow specifying "--hosts-dir=/etc/containerd/certs.d,/etc/docker/certs.d"
 	rootCmd.PersistentFlags().StringSlice("hosts-dir", cfg.HostsDir, "A directory that contains <HOST:PORT>/hosts.toml (containerd style) or <HOST:PORT>/{ca.cert, cert.pem, key.pem} (docker style)")
 	// Experimental enable experimental feature, see in https://github.com/containerd/nerdctl/blob/main/docs/experimental.md
 	helpers.AddPersistentBoolFlag(rootCmd, "experimental", nil, nil, cfg.Experimental, "NERDCTL_EXPERIMENTAL", "Control experimental: https://github.com/containerd/nerdctl/blob/main/docs/experimental.md")
+	helpers.AddPersistentBoolFlag(rootCmd, "offline", nil, nil, cfg.Offline, "NERDCTL_OFFLINE", "Disable the registry access: the pulls fail, and only the local images are used")
 	helpers.AddPersistentStringFlag(rootCmd, "host-gateway-ip", nil, nil, nil, aliasToBeInherited, cfg.HostGatewayIP, "NERDCTL_HOST_GATEWAY_IP", "IP address that the special 'host-gateway' string in --add-host resolves to. Defaults to the IP address of the host. It has no effect without setting --add-host")
 	helpers.AddPersistentStringFlag(rootCmd, "bridge-ip", nil, nil, nil, aliasToBeInherited, cfg.BridgeIP, "NERDCTL_BRIDGE_IP", "IP address for the default nerdctl bridge network")
 	rootCmd.PersistentFlags().Bool("kube-hide-dupe", cfg.KubeHideDupe, "Deduplicate images for Kubernetes with namespace k8s.io")
+	rootCmd.PersistentFlags().Bool("i-know-what-i-am-doing", cfg.KubeReadWrite, "Allow the operations that are safe alongside kubelet (exec, logs, stats, cp, image prune) in the k8s.io namespace")
 	rootCmd.PersistentFlags().StringSlice("cdi-spec-dirs", cfg.CDISpecDirs, "The directories to search for CDI spec files. Defaults to /etc/cdi,/var/run/cdi")
+	helpers.AddPersistentStringFlag(rootCmd, "port-forwarding-backend", nil, nil, nil, aliasToBeInherited, cfg.PortForwardingBackend, "NERDCTL_PORT_FORWARDING_BACKEND", `Backend for forwarding the published ports of the networks created from now on ("iptables"|"nftables"), defaults to the choice of the CNI "portmap" plugin; "nftables" requires CNI plugins v1.7.0 or later`)
+	rootCmd.RegisterFlagCompletionFunc("port-forwarding-backend", completion.PortForwardingBackendNames)
+	helpers.AddPersistentStringFlag(rootCmd, "rootlesskit-port-driver", nil, nil, nil, aliasToBeInherited, cfg.RootlessKitPortDriver, "NERDCTL_ROOTLESSKIT_PORT_DRIVER", `Port driver of RootlessKit for rootless containerd ("builtin"|"slirp4netns"|"implicit"), defaults to "builtin"`)
+	rootCmd.RegisterFlagCompletionFunc("rootlesskit-port-driver", completion.RootlessKitPortDriverNames)
+	helpers.AddPersistentStringFlag(rootCmd, "output", nil, nil, nil, aliasToBeInherited, cfg.Output, "NERDCTL_OUTPUT", `Output format of the commands that support it ("text"|"json"), see https://github.com/containerd/nerdctl/blob/main/docs/output.md`)
+	rootCmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp
+	})
 	rootCmd.PersistentFlags().String("userns-remap", cfg.UsernsRemap, "Support idmapping for creating and running containers. This options is only supported on linux. If `host` is passed, no idmapping is done. if a user name is passed, it does idmapping based on the uidmap and gidmap ranges specified in /etc/subuid and /etc/subgid respectively")
 	return aliasToBeInherited, nil
 }
 
 func newApp() (*cobra.Command, error) {
-	tomlPath := ncdefaults.NerdctlTOML()
-	if v, ok := os.LookupEnv("NERDCTL_TOML"); ok {
-		tomlPath = v
-	}
+	tomlPath := helpers.NerdctlTOMLPath()
 
 	short := "nerdctl is a command line interface for containerd"
 	long := fmt.Sprintf(`%s
@@ -223,10 +233,20 @@ Config file ($NERDCTL_TOML): %s
 	}
 
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		nctx, err := resolveContext(cmd, tomlPath)
+		if err != nil {
+			return err
+		}
+		if err = applyContext(cmd, nctx); err != nil {
+			return err
+		}
 		globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
 		if err != nil {
 			return err
 		}
+		if err = checkOutputFormat(cmd, globalOptions); err != nil {
+			return err
+		}
 		debug := globalOptions.DebugFull
 		if !debug {
 			debug = globalOptions.Debug
@@ -234,8 +254,34 @@ Config file ($NERDCTL_TOML): %s
 		if debug {
 			log.SetLevel(log.DebugLevel.String())
 		}
+		if appNeedsContextDelegation(cmd, nctx) {
+			// execute the command on the remote host over SSH, or with sudo
+			if err = delegate(nctx, cmd, args, "context"); err != nil {
+				return err
+			}
+			os.Exit(0)
+		}
 		address := globalOptions.Address
-		if strings.Contains(address, "://") && !strings.HasPrefix(address, "unix://") {
+		if sshutil.IsSSH(address) {
+			if _, err = sshutil.Parse(address); err != nil {
+				return err
+			}
+		} else if clientutil.IsTCP(address) {
+			if _, err = clientutil.TCPHost(address); err != nil {
+				return err
+			}
+			tlsOptions := clientutil.TLSOptions{
+				CACert:            globalOptions.TLSCACert,
+				Cert:              globalOptions.TLSCert,
+				Key:               globalOptions.TLSKey,
+				SPIFFEID:          globalOptions.TLSSPIFFEID,
+				SPIFFETrustDomain: globalOptions.TLSSPIFFETrustDomain,
+			}
+			if err = tlsOptions.Validate(); err != nil {
+				return err
+			}
+			clientutil.SetTLSOptions(tlsOptions)
+		} else if strings.Contains(address, "://") && !strings.HasPrefix(address, "unix://") {
 			return fmt.Errorf("invalid address %q", address)
 		}
 		cgroupManager := globalOptions.CgroupManager
@@ -246,7 +292,16 @@ Config file ($NERDCTL_TOML): %s
 				return fmt.Errorf("invalid cgroup-manager %q (supported values: \"systemd\", \"cgroupfs\", \"none\")", cgroupManager)
 			}
 		}
-
+		switch globalOptions.PortForwardingBackend {
+		case "", "iptables", "nftables":
+		default:
+			return fmt.Errorf("invalid port-forwarding-backend %q (supported values: \"iptables\", \"nftables\")", globalOptions.PortForwardingBackend)
+		}
+		switch globalOptions.RootlessKitPortDriver {
+		case "", "builtin", "slirp4netns", "implicit":
+		default:
+			return fmt.Errorf("invalid rootlesskit-port-driver %q (supported values: \"builtin\", \"slirp4netns\", \"implicit\")", globalOptions.RootlessKitPortDriver)
+		}
 		// Since we store containers' stateful information on the filesystem per namespace, we need namespaces to be
 		// valid, safe path segments.
 		// Note that the container runtime will further enforce additional restrictions on namespace names
@@ -256,6 +311,28 @@ Config file ($NERDCTL_TOML): %s
 		if err = store.IsFilesystemSafe(globalOptions.Namespace); err != nil {
 			return err
 		}
+		if err = checkKubeNamespace(cmd, globalOptions); err != nil {
+			return err
+		}
+		if sshutil.IsSSH(address) {
+			if appNeedsSSHDelegation(cmd) {
+				// execute the command on the remote host, as it needs the filesystem of the containerd host
+				nctx, err = sshAddressContext(globalOptions)
+				if err != nil {
+					return err
+				}
+				if err = delegate(nctx, cmd, args, "address", "a", "H", "host", "context"); err != nil {
+					return err
+				}
+				os.Exit(0)
+			}
+			// containerd is on the remote host, so RootlessKit on the local host is irrelevant
+			return nil
+		}
+		if clientutil.IsTCP(address) {
+			// containerd is behind a TLS proxy, so RootlessKit on the local host is irrelevant
+			return nil
+		}
 		if appNeedsRootlessParentMain(cmd, args) {
 			// reexec /proc/self/exe with `nsenter` into RootlessKit namespaces
 			return rootlessutil.ParentMain(globalOptions.HostGatewayIP)
@@ -269,6 +346,7 @@ Config file ($NERDCTL_TOML): %s
 		container.RunCommand(),
 		container.UpdateCommand(),
 		container.ExecCommand(),
+		container.DebugCommand(),
 		// #endregion
 
 		// #region Container management
@@ -284,6 +362,7 @@ Config file ($NERDCTL_TOML): %s
 		container.PauseCommand(),
 		container.UnpauseCommand(),
 		container.CommitCommand(),
+		container.ExportCommand(),
 		container.WaitCommand(),
 		container.RenameCommand(),
 		container.AttachCommand(),
@@ -316,14 +395,20 @@ Config file ($NERDCTL_TOML): %s
 		container.TopCommand(),
 		container.StatsCommand(),
 
+		// Dashboard
+		dash.Command(),
+
 		// #region helpers.Management
 		container.Command(),
 		image.Command(),
 		network.Command(),
 		volume.Command(),
+		secret.Command(),
 		system.Command(),
 		namespace.Command(),
 		builder.Command(),
+		context.Command(),
+		machine.Command(),
 		// #endregion
 
 		// Internal
@@ -340,6 +425,9 @@ Config file ($NERDCTL_TOML): %s
 
 		// IPFS
 		ipfs.NewIPFSCommand(),
+
+		// P2P
+		p2pcmd.NewP2PCommand(),
 	)
 	addApparmorCommand(rootCmd)
 	container.AddCpCommand(rootCmd)
@@ -42,7 +42,8 @@ func appNeedsRootlessParentMain(cmd *cobra.Command, args []string) bool {
 	// completion, login, logout, version: false, because it shouldn't require the daemon to be running
 	// apparmor: false, because it requires the initial mount namespace to access /sys/kernel/security
 	// cp, compose cp: false, because it requires the initial mount namespace to inspect file owners
-	case "", "completion", "login", "logout", "apparmor", "cp", "version":
+	// context, machine: false, because the contexts and the virtual machines are managed on the host
+	case "", "completion", "login", "logout", "apparmor", "cp", "version", "context", "machine":
 		return false
 	case "container":
 		if len(commands) < 3 {
@@ -60,6 +61,12 @@ func appNeedsRootlessParentMain(cmd *cobra.Command, args []string) bool {
 		case "cp":
 			return false
 		}
+	case "system":
+		// system bypass4netnsd: false, because bypass4netnsd has to run in the initial network namespace
+		// system rootless: false, because rootless containerd may not be running yet
+		if len(commands) >= 3 && (commands[2] == "bypass4netnsd" || commands[2] == "rootless") {
+			return false
+		}
 	}
 	return true
 }
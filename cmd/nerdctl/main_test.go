@@ -17,14 +17,19 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 
+	"gotest.tools/v3/assert"
+
 	"github.com/containerd/containerd/v2/defaults"
 	"github.com/containerd/nerdctl/mod/tigron/expect"
 	"github.com/containerd/nerdctl/mod/tigron/require"
 	"github.com/containerd/nerdctl/mod/tigron/test"
 
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
 	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
 )
@@ -130,3 +135,129 @@ version = 2`),
 
 	testCase.Run(t)
 }
+
+// TestOutputJSON validates the documents printed with the global `--output json` flag
+func TestOutputJSON(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	// Docker does not support --output json
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("volume", "create", data.Identifier())
+		data.Labels().Set("volume", data.Identifier())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("volume", "rm", "-f", data.Identifier())
+	}
+
+	document := func(kind string, items []string) func(data test.Data, helpers test.Helpers) *test.Expected {
+		return func(data test.Data, helpers test.Helpers) *test.Expected {
+			return &test.Expected{
+				Output: func(stdout, info string, t *testing.T) {
+					var doc formatter.Document
+					assert.NilError(t, json.Unmarshal([]byte(stdout), &doc), info)
+					assert.Equal(t, doc.SchemaVersion, formatter.SchemaVersion, info)
+					assert.Equal(t, doc.Kind, kind, info)
+					assert.Equal(t, len(doc.Items), len(items), info)
+					for i, item := range items {
+						assert.Assert(t, strings.Contains(string(doc.Items[i]), data.Labels().Get(item)), info)
+					}
+				},
+			}
+		}
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "invalid output",
+			Command:     test.Command("--output", "yaml", "volume", "ls"),
+			Expected:    test.Expects(1, []error{errors.New("invalid --output")}, nil),
+		},
+		{
+			Description: "unsupported command",
+			Command:     test.Command("--output", "json", "system", "prune"),
+			Expected:    test.Expects(1, []error{errors.New(`--output json is not supported by "nerdctl system prune"`)}, nil),
+		},
+		{
+			Description: "unsupported command with NERDCTL_OUTPUT",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				cmd := helpers.Command("events")
+				cmd.Setenv("NERDCTL_OUTPUT", "json")
+				return cmd
+			},
+			Expected: test.Expects(1, []error{errors.New(`--output json is not supported by "nerdctl events"`)}, nil),
+		},
+		{
+			Description: "format cannot be used",
+			Command:     test.Command("--output", "json", "volume", "ls", "--format", "{{.Name}}"),
+			Expected:    test.Expects(1, []error{errors.New("--format cannot be used with --output json")}, nil),
+		},
+		{
+			Description: "list",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("--output", "json", "volume", "ls", "--filter", "name="+data.Labels().Get("volume"))
+			},
+			Expected: document(formatter.KindVolumeList, []string{"volume"}),
+		},
+		{
+			Description: "inspect",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("--output", "json", "volume", "inspect", data.Labels().Get("volume"))
+			},
+			Expected: document(formatter.KindVolumeInspect, []string{"volume"}),
+		},
+		{
+			Description: "inspect a containerd namespace",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				data.Labels().Set("namespace", string(helpers.Read(nerdtest.Namespace)))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("--output", "json", "namespace", "inspect", data.Labels().Get("namespace"))
+			},
+			Expected: document(formatter.KindNamespaceInspect, []string{"namespace"}),
+		},
+		{
+			Description: "single object",
+			Command:     test.Command("--output", "json", "version"),
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout, info string, t *testing.T) {
+						var doc formatter.Document
+						assert.NilError(t, json.Unmarshal([]byte(stdout), &doc), info)
+						assert.Equal(t, doc.Kind, formatter.KindVersion, info)
+						assert.Equal(t, len(doc.Items), 1, info)
+					},
+				}
+			},
+		},
+		{
+			Description: "action",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("volume", "create", data.Identifier())
+				data.Labels().Set("removed", data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("volume", "rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("--output", "json", "volume", "rm", data.Labels().Get("removed"))
+			},
+			Expected: document(formatter.KindVolumeRemove, []string{"removed"}),
+		},
+		{
+			Description: "action failure",
+			Command:     test.Command("--output", "json", "rm", "does-not-exist"),
+			Expected: test.Expects(1, nil, func(stdout, info string, t *testing.T) {
+				var doc formatter.Document
+				assert.NilError(t, json.Unmarshal([]byte(stdout), &doc), info)
+				assert.Equal(t, doc.Kind, formatter.KindContainerRemove, info)
+				assert.Equal(t, len(doc.Items), 0, info)
+				assert.Equal(t, len(doc.Errors), 1, info)
+			}),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -17,10 +17,15 @@
 package namespace
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"sort"
 	"strings"
 	"text/tabwriter"
+	"text/template"
 
 	"github.com/spf13/cobra"
 
@@ -28,7 +33,9 @@ import (
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 	"github.com/containerd/nerdctl/v2/pkg/mountutil/volumestore"
 )
 
@@ -51,6 +58,37 @@ func Command() *cobra.Command {
 	return cmd
 }
 
+// addLimitFlags adds the flags for the default resource constraints and labels of the containers in a namespace.
+func addLimitFlags(cmd *cobra.Command) {
+	cmd.Flags().Float64("cpus", 0, "Default and maximum number of CPUs of the containers")
+	cmd.Flags().Int64("cpu-quota", -1, "Default and maximum CPU CFS quota of the containers, per the period of 100ms (0 for unlimited)")
+	cmd.Flags().StringP("memory", "m", "", "Default and maximum memory limit of the containers (0 for unlimited)")
+	cmd.Flags().Int64("pids-limit", -1, "Default and maximum pids limit of the containers (0 for unlimited)")
+	cmd.Flags().StringArray("default-label", nil, "Default labels of the containers (an empty value removes the default label)")
+}
+
+func processLimitFlags(cmd *cobra.Command, options *types.NamespaceCreateOptions) error {
+	var err error
+	options.CPUs, err = cmd.Flags().GetFloat64("cpus")
+	if err != nil {
+		return err
+	}
+	options.CPUQuota, err = cmd.Flags().GetInt64("cpu-quota")
+	if err != nil {
+		return err
+	}
+	options.Memory, err = cmd.Flags().GetString("memory")
+	if err != nil {
+		return err
+	}
+	options.PidsLimit, err = cmd.Flags().GetInt64("pids-limit")
+	if err != nil {
+		return err
+	}
+	options.DefaultLabels, err = cmd.Flags().GetStringArray("default-label")
+	return err
+}
+
 func listCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:           "ls",
@@ -61,32 +99,71 @@ func listCommand() *cobra.Command {
 		SilenceErrors: true,
 	}
 	cmd.Flags().BoolP("quiet", "q", false, "Only display names")
+	cmd.Flags().String("format", "", "Format the output using the given Go template, e.g, '{{json .}}'")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "table"}, cobra.ShellCompDirectiveNoFileComp
+	})
 	return cmd
 }
 
+type namespacePrintable struct {
+	Name       string
+	Containers int
+	Images     int
+	Volumes    int
+	Labels     string
+}
+
 func listAction(cmd *cobra.Command, args []string) error {
 	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
 	if err != nil {
 		return err
 	}
-	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), globalOptions.Namespace, globalOptions.Address)
+	quiet, err := cmd.Flags().GetBool("quiet")
 	if err != nil {
 		return err
 	}
-	defer cancel()
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	format, err = helpers.ListFormat(globalOptions, format, quiet)
+	if err != nil {
+		return err
+	}
+	var tmpl *template.Template
+	switch format {
+	case "", "table":
+	case "raw":
+		return errors.New("unsupported format: \"raw\"")
+	default:
+		if quiet {
+			return errors.New("format and quiet must not be specified together")
+		}
+		tmpl, err = formatter.ParseTemplate(format)
+		if err != nil {
+			return err
+		}
+	}
+	stdout, finish := helpers.OutputWriter(cmd, globalOptions, formatter.KindNamespaceList, formatter.ItemsFromJSONLines)
+	return finish(listNamespaces(cmd.Context(), globalOptions, quiet, tmpl, stdout))
+}
 
-	nsService := client.NamespaceService()
-	nsList, err := nsService.List(ctx)
+func listNamespaces(ctx context.Context, globalOptions types.GlobalCommandOptions, quiet bool, tmpl *template.Template, stdout io.Writer) error {
+	client, ctx, cancel, err := clientutil.NewClient(ctx, globalOptions.Namespace, globalOptions.Address)
 	if err != nil {
 		return err
 	}
-	quiet, err := cmd.Flags().GetBool("quiet")
+	defer cancel()
+
+	nsService := client.NamespaceService()
+	nsList, err := nsService.List(ctx)
 	if err != nil {
 		return err
 	}
 	if quiet {
 		for _, ns := range nsList {
-			fmt.Fprintln(cmd.OutOrStdout(), ns)
+			fmt.Fprintln(stdout, ns)
 		}
 		return nil
 	}
@@ -95,31 +172,34 @@ func listAction(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	w := tabwriter.NewWriter(cmd.OutOrStdout(), 4, 8, 4, ' ', 0)
-	// no "NETWORKS", because networks are global objects
-	fmt.Fprintln(w, "NAME\tCONTAINERS\tIMAGES\tVOLUMES\tLABELS")
+	w := stdout
+	if tmpl == nil {
+		w = tabwriter.NewWriter(stdout, 4, 8, 4, ' ', 0)
+		// no "NETWORKS", because networks are global objects
+		fmt.Fprintln(w, "NAME\tCONTAINERS\tIMAGES\tVOLUMES\tLABELS")
+	}
 	for _, ns := range nsList {
 		ctx = namespaces.WithNamespace(ctx, ns)
-		var numContainers, numImages, numVolumes int
+		p := namespacePrintable{Name: ns}
 		var labelStrings []string
 
 		containers, err := client.Containers(ctx)
 		if err != nil {
 			log.L.Warn(err)
 		}
-		numContainers = len(containers)
+		p.Containers = len(containers)
 
 		images, err := client.ImageService().List(ctx)
 		if err != nil {
 			log.L.Warn(err)
 		}
-		numImages = len(images)
+		p.Images = len(images)
 
 		volStore, err := volumestore.New(dataStore, ns)
 		if err != nil {
 			log.L.Warn(err)
 		} else {
-			numVolumes, err = volStore.Count()
+			p.Volumes, err = volStore.Count()
 			if err != nil {
 				log.L.Warn(err)
 			}
@@ -133,7 +213,21 @@ func listAction(cmd *cobra.Command, args []string) error {
 			labelStrings = append(labelStrings, strings.Join([]string{k, v}, "="))
 		}
 		sort.Strings(labelStrings)
-		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%v\t\n", ns, numContainers, numImages, numVolumes, strings.Join(labelStrings, ","))
+		p.Labels = strings.Join(labelStrings, ",")
+		if tmpl != nil {
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, p); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w, b.String()); err != nil {
+				return err
+			}
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%v\t\n", p.Name, p.Containers, p.Images, p.Volumes, p.Labels)
+	}
+	if f, ok := w.(formatter.Flusher); ok {
+		return f.Flush()
 	}
-	return w.Flush()
+	return nil
 }
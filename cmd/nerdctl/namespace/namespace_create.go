@@ -36,6 +36,7 @@ func createCommand() *cobra.Command {
 	}
 
 	cmd.Flags().StringArrayP("label", "l", nil, "Set labels for a namespace")
+	addLimitFlags(cmd)
 	return cmd
 }
 
@@ -48,10 +49,14 @@ func processNamespaceCreateCommandOption(cmd *cobra.Command) (types.NamespaceCre
 	if err != nil {
 		return types.NamespaceCreateOptions{}, err
 	}
-	return types.NamespaceCreateOptions{
+	options := types.NamespaceCreateOptions{
 		GOptions: globalOptions,
 		Labels:   labels,
-	}, nil
+	}
+	if err := processLimitFlags(cmd, &options); err != nil {
+		return types.NamespaceCreateOptions{}, err
+	}
+	return options, nil
 }
 
 func createAction(cmd *cobra.Command, args []string) error {
@@ -23,6 +23,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/namespace"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 )
 
 func inspectCommand() *cobra.Command {
@@ -50,6 +51,10 @@ func inspectOptions(cmd *cobra.Command) (types.NamespaceInspectOptions, error) {
 	if err != nil {
 		return types.NamespaceInspectOptions{}, err
 	}
+	format, err = helpers.InspectFormat(globalOptions, format)
+	if err != nil {
+		return types.NamespaceInspectOptions{}, err
+	}
 	return types.NamespaceInspectOptions{
 		GOptions: globalOptions,
 		Format:   format,
@@ -69,5 +74,7 @@ func inspectAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
-	return namespace.Inspect(ctx, client, args, options)
+	stdout, finish := helpers.OutputWriter(cmd, options.GOptions, formatter.KindNamespaceInspect, formatter.ItemsFromJSONArray)
+	options.Stdout = stdout
+	return finish(namespace.Inspect(ctx, client, args, options))
 }
@@ -0,0 +1,94 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package namespace
+
+import (
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestNamespaceLimits(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.All(
+		require.Not(nerdtest.Docker),
+		nerdtest.CgroupsAccessible,
+	)
+
+	const namespaceKey = "namespace"
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("namespace", "create", "--memory=64m", "--pids-limit=50", "--default-label=team=foo", data.Identifier())
+		data.Labels().Set(namespaceKey, data.Identifier())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		bin, _ := exec.LookPath(testutil.GetTarget())
+		helpers.Custom(bin, "--namespace", data.Identifier(), "rm", "-f", data.Identifier()).Run(nil)
+		helpers.Custom(bin, "--namespace", data.Identifier(), "rmi", "-f", testutil.CommonImage).Run(nil)
+		helpers.Anyhow("namespace", "remove", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "defaults are applied",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				bin, _ := exec.LookPath(testutil.GetTarget())
+				ns := data.Labels().Get(namespaceKey)
+				return helpers.Custom(bin, "--namespace", ns, "create", "--name", ns, testutil.CommonImage)
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(_ string, _ string, t *testing.T) {
+						bin, _ := exec.LookPath(testutil.GetTarget())
+						ns := data.Labels().Get(namespaceKey)
+						helpers.Custom(bin, "--namespace", ns, "inspect", ns).Run(&test.Expected{
+							Output: func(stdout string, info string, t *testing.T) {
+								var dc []dockercompat.Container
+								assert.NilError(t, json.Unmarshal([]byte(stdout), &dc), info)
+								assert.Equal(t, len(dc), 1, info)
+								assert.Equal(t, dc[0].HostConfig.Memory, int64(64*1024*1024), info)
+								assert.Equal(t, dc[0].Config.Labels["team"], "foo", info)
+							},
+						})
+					},
+				}
+			},
+		},
+		{
+			Description: "exceeding the limit fails",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				bin, _ := exec.LookPath(testutil.GetTarget())
+				return helpers.Custom(bin, "--namespace", data.Labels().Get(namespaceKey), "create", "--memory=128m", testutil.CommonImage)
+			},
+			Expected: test.Expects(1, []error{errors.New("exceeds the limit of namespace")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
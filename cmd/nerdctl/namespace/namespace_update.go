@@ -28,13 +28,14 @@ import (
 func updateCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:           "update [flags] NAMESPACE",
-		Short:         "Update labels for a namespace",
+		Short:         "Update labels and default resource constraints for a namespace",
 		RunE:          updateAction,
 		Args:          cobra.MinimumNArgs(1),
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
 	cmd.Flags().StringArrayP("label", "l", nil, "Set labels for a namespace")
+	addLimitFlags(cmd)
 	return cmd
 }
 
@@ -47,10 +48,14 @@ func processNamespaceUpdateCommandOption(cmd *cobra.Command) (types.NamespaceUpd
 	if err != nil {
 		return types.NamespaceUpdateOptions{}, err
 	}
-	return types.NamespaceUpdateOptions{
+	options := types.NamespaceCreateOptions{
 		GOptions: globalOptions,
 		Labels:   labels,
-	}, nil
+	}
+	if err := processLimitFlags(cmd, &options); err != nil {
+		return types.NamespaceUpdateOptions{}, err
+	}
+	return types.NamespaceUpdateOptions(options), nil
 }
 
 func updateAction(cmd *cobra.Command, args []string) error {
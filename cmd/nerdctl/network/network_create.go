@@ -50,6 +50,10 @@ func createCommand() *cobra.Command {
 	cmd.Flags().String("ip-range", "", `Allocate container ip from a sub-range`)
 	cmd.Flags().StringArray("label", nil, "Set metadata for a network")
 	cmd.Flags().Bool("ipv6", false, "Enable IPv6 networking")
+	cmd.Flags().String("ingress-policy", "", `Policy for the connections to the published ports, "accept" (default) or "deny" (use "nerdctl run --allow-from" for exceptions)`)
+	cmd.RegisterFlagCompletionFunc("ingress-policy", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"accept", "deny"}, cobra.ShellCompDirectiveNoFileComp
+	})
 	return cmd
 }
 
@@ -99,18 +103,23 @@ func createAction(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	ingressPolicy, err := cmd.Flags().GetString("ingress-policy")
+	if err != nil {
+		return err
+	}
 
 	return network.Create(types.NetworkCreateOptions{
-		GOptions:    globalOptions,
-		Name:        name,
-		Driver:      driver,
-		Options:     strutil.ConvertKVStringsToMap(opts),
-		IPAMDriver:  ipamDriver,
-		IPAMOptions: strutil.ConvertKVStringsToMap(ipamOpts),
-		Subnets:     subnets,
-		Gateway:     gatewayStr,
-		IPRange:     ipRangeStr,
-		Labels:      labels,
-		IPv6:        ipv6,
+		GOptions:      globalOptions,
+		Name:          name,
+		Driver:        driver,
+		Options:       strutil.ConvertKVStringsToMap(opts),
+		IPAMDriver:    ipamDriver,
+		IPAMOptions:   strutil.ConvertKVStringsToMap(ipamOpts),
+		Subnets:       subnets,
+		Gateway:       gatewayStr,
+		IPRange:       ipRangeStr,
+		Labels:        labels,
+		IPv6:          ipv6,
+		IngressPolicy: ingressPolicy,
 	}, cmd.OutOrStdout())
 }
@@ -24,6 +24,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/network"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 )
 
 func inspectCommand() *cobra.Command {
@@ -60,6 +61,10 @@ func inspectAction(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	format, err = helpers.InspectFormat(globalOptions, format)
+	if err != nil {
+		return err
+	}
 
 	options := types.NetworkInspectOptions{
 		GOptions: globalOptions,
@@ -75,7 +80,9 @@ func inspectAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
-	return network.Inspect(ctx, client, options)
+	stdout, finish := helpers.OutputWriter(cmd, options.GOptions, formatter.KindNetworkInspect, formatter.ItemsFromJSONArray)
+	options.Stdout = stdout
+	return finish(network.Inspect(ctx, client, options))
 }
 
 func networkInspectShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -22,6 +22,7 @@ import (
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/network"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 )
 
 func listCommand() *cobra.Command {
@@ -56,15 +57,20 @@ func listAction(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	format, err = helpers.ListFormat(globalOptions, format, quiet)
+	if err != nil {
+		return err
+	}
 	filters, err := cmd.Flags().GetStringSlice("filter")
 	if err != nil {
 		return err
 	}
-	return network.List(cmd.Context(), types.NetworkListOptions{
+	stdout, finish := helpers.OutputWriter(cmd, globalOptions, formatter.KindNetworkList, formatter.ItemsFromJSONLines)
+	return finish(network.List(cmd.Context(), types.NetworkListOptions{
 		GOptions: globalOptions,
 		Quiet:    quiet,
 		Format:   format,
 		Filters:  filters,
-		Stdout:   cmd.OutOrStdout(),
-	})
+		Stdout:   stdout,
+	}))
 }
@@ -40,6 +40,7 @@ func pruneCommand() *cobra.Command {
 		SilenceErrors: true,
 	}
 	cmd.Flags().BoolP("force", "f", false, "Do not prompt for confirmation")
+	cmd.Flags().StringSlice("filter", nil, "Provide filter values (e.g. 'until=24h', 'label=<key>=<value>')")
 	return cmd
 }
 
@@ -52,6 +53,10 @@ func pruneAction(cmd *cobra.Command, _ []string) error {
 	if err != nil {
 		return err
 	}
+	filters, err := cmd.Flags().GetStringSlice("filter")
+	if err != nil {
+		return err
+	}
 
 	if !force {
 		var confirm string
@@ -69,6 +74,7 @@ func pruneAction(cmd *cobra.Command, _ []string) error {
 		GOptions:             globalOptions,
 		NetworkDriversToKeep: NetworkDriversToKeep,
 		Stdout:               cmd.OutOrStdout(),
+		Filters:              filters,
 	}
 
 	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
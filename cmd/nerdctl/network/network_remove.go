@@ -24,6 +24,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/network"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 	"github.com/containerd/nerdctl/v2/pkg/netutil"
 )
 
@@ -60,7 +61,9 @@ func removeAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
-	return network.Remove(ctx, client, options)
+	stdout, finish := helpers.OutputWriter(cmd, options.GOptions, formatter.KindNetworkRemove, formatter.ItemsFromTextLines)
+	options.Stdout = stdout
+	return finish(network.Remove(ctx, client, options))
 }
 
 func networkRmShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
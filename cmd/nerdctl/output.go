@@ -0,0 +1,111 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+)
+
+// jsonOutputCommands lists the commands that print a formatter.Document with `--output json`, see docs/output.md.
+// The keys are the command paths without the leading "nerdctl", e.g., "container ls".
+// The commands that are not listed here fail with `--output json`, rather than printing the human-readable text
+// to a script that expects JSON.
+var jsonOutputCommands = map[string]bool{
+	"apparmor ls":                 true,
+	"builder ls":                  true,
+	"compose images":              true,
+	"compose ps":                  true,
+	"container create":            true,
+	"container inspect":           true,
+	"container kill":              true,
+	"container ls":                true,
+	"container pause":             true,
+	"container restart":           true,
+	"container restart-policy ls": true,
+	"container rm":                true,
+	"container start":             true,
+	"container stop":              true,
+	"container unpause":           true,
+	"context inspect":             true,
+	"context ls":                  true,
+	"create":                      true,
+	"history":                     true,
+	"image history":               true,
+	"image inspect":               true,
+	"image ls":                    true,
+	"image rm":                    true,
+	"images":                      true,
+	"info":                        true,
+	"inspect":                     true,
+	"kill":                        true,
+	"machine ls":                  true,
+	"namespace inspect":           true,
+	"namespace ls":                true,
+	"network inspect":             true,
+	"network ls":                  true,
+	"network rm":                  true,
+	"pause":                       true,
+	"ps":                          true,
+	"restart":                     true,
+	"rm":                          true,
+	"rmi":                         true,
+	"secret ls":                   true,
+	"start":                       true,
+	"stop":                        true,
+	"system df":                   true,
+	"system info":                 true,
+	"unpause":                     true,
+	"version":                     true,
+	"volume inspect":              true,
+	"volume ls":                   true,
+	"volume rm":                   true,
+	"volume snapshot ls":          true,
+}
+
+// outputJSONExempted returns true for the commands that are not affected by `--output json`,
+// i.e., the help, the shell completion, and the internal commands executed by nerdctl itself, e.g., the OCI hooks,
+// so that `output = "json"` in nerdctl.toml does not break them.
+func outputJSONExempted(commands []string) bool {
+	if len(commands) < 2 {
+		return true
+	}
+	switch commands[1] {
+	case "help", "completion", "__complete", "__completeNoDesc", "internal":
+		return true
+	}
+	return false
+}
+
+// checkOutputFormat refuses `--output json` for the commands that do not support it.
+// The format may come from the flag, $NERDCTL_OUTPUT, or nerdctl.toml.
+func checkOutputFormat(cmd *cobra.Command, globalOptions types.GlobalCommandOptions) error {
+	if globalOptions.Output != formatter.OutputJSON {
+		return nil
+	}
+	commands := commandNames(cmd)
+	if outputJSONExempted(commands) || jsonOutputCommands[strings.Join(commands[1:], " ")] {
+		return nil
+	}
+	return fmt.Errorf("--output json is not supported by %q, see https://github.com/containerd/nerdctl/blob/main/docs/output.md",
+		strings.Join(commands, " "))
+}
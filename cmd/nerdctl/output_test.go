@@ -0,0 +1,81 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+)
+
+func TestCheckOutputFormat(t *testing.T) {
+	app, err := newApp()
+	assert.NilError(t, err)
+	jsonOptions := types.GlobalCommandOptions{Output: formatter.OutputJSON}
+	for _, tc := range []struct {
+		path string
+		err  string
+	}{
+		{path: "ps"},
+		{path: "container ls"},
+		{path: "image inspect"},
+		{path: "volume rm"},
+		{path: "inspect"},
+		{path: ""},
+		{path: "internal oci-hook"},
+		{path: "secret ls"},
+		{path: "context ls"},
+		{path: "compose ps"},
+		{path: "namespace ls"},
+		{path: "info"},
+		{path: "version"},
+		{path: "system prune", err: `--output json is not supported by "nerdctl system prune"`},
+		{path: "builder prune", err: `--output json is not supported by "nerdctl builder prune"`},
+		{path: "stats", err: `--output json is not supported by "nerdctl stats"`},
+		{path: "run", err: `--output json is not supported by "nerdctl run"`},
+	} {
+		cmd, _, err := app.Find(strings.Fields(tc.path))
+		assert.NilError(t, err, tc.path)
+		if tc.err == "" {
+			assert.NilError(t, checkOutputFormat(cmd, jsonOptions), tc.path)
+		} else {
+			assert.ErrorContains(t, checkOutputFormat(cmd, jsonOptions), tc.err, tc.path)
+		}
+		assert.NilError(t, checkOutputFormat(cmd, types.GlobalCommandOptions{}), tc.path)
+	}
+}
+
+// TestJSONOutputCommands checks that every command listed in jsonOutputCommands exists,
+// so that a renamed command does not silently lose its JSON output.
+func TestJSONOutputCommands(t *testing.T) {
+	app, err := newApp()
+	assert.NilError(t, err)
+	for path := range jsonOutputCommands {
+		if path == "apparmor ls" && runtime.GOOS != "linux" {
+			continue
+		}
+		cmd, rest, err := app.Find(strings.Fields(path))
+		assert.NilError(t, err, path)
+		assert.Assert(t, len(rest) == 0 && cmd.Runnable(), "%q is not a command", path)
+		assert.Equal(t, strings.Join(commandNames(cmd)[1:], " "), path)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package p2p
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/p2p"
+)
+
+func newP2PServeCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:           "serve [OPTIONS]",
+		Short:         "Serve the blobs of the content store to the peers on the LAN.",
+		Args:          cobra.NoArgs,
+		RunE:          p2pServeAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().String("listen", "", "address to listen (default: listen in the [p2p] table of nerdctl.toml, or \":5055\")")
+	cmd.Flags().Bool("mdns", false, "advertise the server on the LAN with mDNS (default: mdns in the [p2p] table of nerdctl.toml)")
+	return cmd
+}
+
+func processP2PServeOptions(cmd *cobra.Command) (types.P2PServeOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.P2PServeOptions{}, err
+	}
+	listen, err := cmd.Flags().GetString("listen")
+	if err != nil {
+		return types.P2PServeOptions{}, err
+	}
+	if listen == "" {
+		listen = globalOptions.P2P.Listen
+	}
+	mdns := globalOptions.P2P.MDNS
+	if cmd.Flags().Changed("mdns") {
+		if mdns, err = cmd.Flags().GetBool("mdns"); err != nil {
+			return types.P2PServeOptions{}, err
+		}
+	}
+	return types.P2PServeOptions{
+		GOptions: globalOptions,
+		Listen:   listen,
+		MDNS:     mdns,
+	}, nil
+}
+
+func p2pServeAction(cmd *cobra.Command, args []string) error {
+	options, err := processP2PServeOptions(cmd)
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	return p2p.Serve(ctx, client, options)
+}
@@ -0,0 +1,83 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package secret
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/secret"
+)
+
+func createCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create [flags] SECRET FILE|-",
+		Short: "Create a secret from a file or stdin",
+		Long: `Create a secret from a file, or from stdin ("-").
+
+The secret is encrypted with the "encrypt_command" in the [secret] table of nerdctl.toml, if set.`,
+		Args:          cobra.ExactArgs(2),
+		RunE:          createAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().StringArrayP("label", "l", nil, "Set a label on the secret")
+	return cmd
+}
+
+func createOptions(cmd *cobra.Command, file string) (types.SecretCreateOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.SecretCreateOptions{}, err
+	}
+	labels, err := cmd.Flags().GetStringArray("label")
+	if err != nil {
+		return types.SecretCreateOptions{}, err
+	}
+	for _, label := range labels {
+		if label == "" {
+			return types.SecretCreateOptions{}, fmt.Errorf("labels cannot be empty (%w)", errdefs.ErrInvalidArgument)
+		}
+	}
+	cfg, err := helpers.LoadNerdctlTOML(helpers.NerdctlTOMLPath())
+	if err != nil {
+		return types.SecretCreateOptions{}, err
+	}
+	return types.SecretCreateOptions{
+		GOptions:       globalOptions,
+		Labels:         labels,
+		File:           file,
+		EncryptCommand: cfg.Secret.EncryptCommand,
+		DecryptCommand: cfg.Secret.DecryptCommand,
+		Stdin:          cmd.InOrStdin(),
+		Stdout:         cmd.OutOrStdout(),
+	}, nil
+}
+
+func createAction(cmd *cobra.Command, args []string) error {
+	options, err := createOptions(cmd, args[1])
+	if err != nil {
+		return err
+	}
+	_, err = secret.Create(args[0], options)
+	return err
+}
@@ -0,0 +1,169 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package secret
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/errdefs"
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/secretstore"
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+// wipeStagingDir removes the staged secrets of the container, as a reboot of the host does.
+func wipeStagingDir(helpers test.Helpers, name string) {
+	id := strings.TrimSpace(helpers.Capture("inspect", "--format", "{{.ID}}", name))
+	dir, err := secretstore.StagingDir(string(helpers.Read(nerdtest.Namespace)), id)
+	if err != nil {
+		helpers.T().Fatal(err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		helpers.T().Fatal(err)
+	}
+}
+
+func TestSecret(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		cmd := helpers.Command("secret", "create", data.Identifier(), "-")
+		cmd.Feed(strings.NewReader("hunter2"))
+		cmd.Run(&test.Expected{Output: expect.Equals(data.Identifier() + "\n")})
+		data.Labels().Set("secret", data.Identifier())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("secret", "rm", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "ls",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("secret", "ls", "--format", "{{.Name}} {{.Encrypted}} {{.Size}}")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.Contains(data.Labels().Get("secret") + " false 7\n"),
+				}
+			},
+		},
+		{
+			Description: "duplicate name",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				cmd := helpers.Command("secret", "create", data.Labels().Get("secret"), "-")
+				cmd.Feed(strings.NewReader("hunter3"))
+				return cmd
+			},
+			Expected: test.Expects(1, []error{errdefs.ErrAlreadyExists}, nil),
+		},
+		{
+			Description: "run --secret",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				secret := data.Labels().Get("secret")
+				return helpers.Command("run", "--rm", "--secret", secret,
+					"--secret", "id="+secret+",target=/etc/password,uid=1000,mode=0400",
+					testutil.CommonImage, "sh", "-c", "cat /run/secrets/"+secret+"; echo; stat -c '%u %a' /etc/password")
+			},
+			Expected: test.Expects(0, nil, expect.Equals("hunter2\n1000 400\n")),
+		},
+		{
+			Description: "secret in use cannot be removed",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("create", "--name", data.Identifier(), "--secret", data.Labels().Get("secret"), testutil.CommonImage, "true")
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("secret", "rm", data.Labels().Get("secret"))
+			},
+			Expected: test.Expects(1, []error{errdefs.ErrFailedPrecondition}, nil),
+		},
+		{
+			Description: "restart after the staging dir is wiped",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("run", "-d", "--name", data.Identifier(), "--secret", data.Labels().Get("secret"), testutil.CommonImage, "sleep", nerdtest.Infinity)
+				helpers.Ensure("stop", "-t", "0", data.Identifier())
+				wipeStagingDir(helpers, data.Identifier())
+				helpers.Ensure("start", data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", data.Identifier(), "cat", "/run/secrets/"+data.Labels().Get("secret"))
+			},
+			Expected: test.Expects(0, nil, expect.Equals("hunter2")),
+		},
+		{
+			Description: "restart policy after the staging dir is wiped",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("run", "-d", "--restart=always", "--name", data.Identifier(), "--secret", data.Labels().Get("secret"),
+					testutil.CommonImage, "sh", "-c", "cat /run/secrets/"+data.Labels().Get("secret")+"; echo; sleep 1")
+				wipeStagingDir(helpers, data.Identifier())
+				// wait for the containerd restart manager to start the container again
+				for range 60 {
+					if strings.Count(helpers.Capture("logs", data.Identifier()), "hunter2") >= 2 {
+						break
+					}
+					time.Sleep(time.Second)
+				}
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout, info string, t *testing.T) {
+						assert.Assert(t, strings.Count(stdout, "hunter2") >= 2, "the container was not restarted with the secret: %s", info)
+					},
+				}
+			},
+		},
+		{
+			Description: "restart policy requires a nerdctl log driver",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("create", "--restart=always", "--log-driver=none", "--secret", data.Labels().Get("secret"), testutil.CommonImage, "true")
+			},
+			Expected: test.Expects(1, []error{errors.New("requires a nerdctl log driver")}, nil),
+		},
+		{
+			Description: "nonexistent secret",
+			Command:     test.Command("run", "--rm", "--secret", "nonexistent-secret", testutil.CommonImage, "true"),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, []error{errors.New("not found")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
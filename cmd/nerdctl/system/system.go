@@ -36,6 +36,15 @@ func Command() *cobra.Command {
 		EventsCommand(),
 		InfoCommand(),
 		pruneCommand(),
+		gcCommand(),
+		watchdogCommand(),
+		serveCommand(),
+		dockerAPICommand(),
+		checkPortsCommand(),
+		dfCommand(),
+		benchSnapshotterCommand(),
+		doctorCommand(),
 	)
+	addPlatformCommands(cmd)
 	return cmd
 }
@@ -0,0 +1,114 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/system"
+)
+
+const benchSnapshotterHelp = `Measure the time to pull, unpack, and cold-start an image with each snapshotter.
+
+By default, the available ones of overlayfs, stargz, nydus, overlaybd, and soci are benchmarked.
+The image should be in the format of the snapshotter (e.g., eStargz for stargz) for lazy pulling;
+otherwise the remote snapshotters fall back to pulling the whole layers.
+
+The cold start is the time from creating the container until it exits, or until a line of its stdout
+contains --wait-line. The container runs without networking.
+
+Each snapshotter is benchmarked in a temporary namespace, which is removed afterward.
+The blobs already present in the content store are not fetched again, so remove the image beforehand
+for measuring the pull time of non-remote snapshotters.
+
+Example:
+  nerdctl system bench-snapshotter --snapshotters=overlayfs,stargz ghcr.io/stargz-containers/python:3.13-esgz -- python3 -c 'print("hi")'
+`
+
+func benchSnapshotterCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "bench-snapshotter [flags] IMAGE [-- COMMAND [ARG...]]",
+		Short:             "Benchmark the pull and the cold start of an image with each snapshotter",
+		Long:              benchSnapshotterHelp,
+		Args:              cobra.MinimumNArgs(1),
+		RunE:              benchSnapshotterAction,
+		ValidArgsFunction: benchSnapshotterShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().StringSlice("snapshotters", nil, "Snapshotters to benchmark (default: the available ones of overlayfs, stargz, nydus, overlaybd, and soci)")
+	cmd.RegisterFlagCompletionFunc("snapshotters", completion.SnapshotterNames)
+	cmd.Flags().String("format", "", "Format the output using the given Go template, e.g, '{{json .}}'")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "table"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().Duration("timeout", time.Minute, "Time to wait for the container to exit, or to print --wait-line")
+	cmd.Flags().String("wait-line", "", "Regard the container as started when a line of its stdout contains this string")
+	return cmd
+}
+
+func benchSnapshotterAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	snapshotters, err := cmd.Flags().GetStringSlice("snapshotters")
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+	waitLine, err := cmd.Flags().GetString("wait-line")
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), globalOptions.Namespace, globalOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return system.BenchSnapshotter(ctx, client, args[0], args[1:], types.SystemBenchSnapshotterOptions{
+		Stdout:       cmd.OutOrStdout(),
+		Stderr:       cmd.ErrOrStderr(),
+		GOptions:     globalOptions,
+		Snapshotters: snapshotters,
+		Format:       format,
+		Timeout:      timeout,
+		WaitLine:     waitLine,
+	})
+}
+
+func benchSnapshotterShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		// show image names
+		return completion.ImageNames(cmd)
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,64 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/cmd/system"
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestSystemBenchSnapshotter(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "overlayfs",
+			Command: test.Command("system", "bench-snapshotter", "--snapshotters=overlayfs", "--format={{json .}}",
+				testutil.CommonImage, "--", "true"),
+			Expected: test.Expects(0, nil, func(stdout string, info string, t *testing.T) {
+				var res system.BenchSnapshotterResult
+				assert.NilError(t, json.Unmarshal([]byte(stdout), &res), info)
+				assert.Equal(t, res.Snapshotter, "overlayfs", info)
+				assert.Equal(t, res.Error, "", info)
+				assert.Assert(t, res.Pull != "-" && res.Unpack != "-" && res.ColdStart != "-", info)
+			}),
+		},
+		{
+			Description: "unavailable snapshotter",
+			Command: test.Command("system", "bench-snapshotter", "--snapshotters=no-such-snapshotter", "--format={{json .}}",
+				testutil.CommonImage),
+			Expected: test.Expects(0, nil, func(stdout string, info string, t *testing.T) {
+				var res system.BenchSnapshotterResult
+				assert.NilError(t, json.Unmarshal([]byte(stdout), &res), info)
+				assert.Equal(t, res.Error, "snapshotter is not available", info)
+			}),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -0,0 +1,93 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/system"
+)
+
+func bypass4netnsdCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bypass4netnsd",
+		Short: "Manage bypass4netnsd, the daemon for `nerdctl run --net-accel` (rootless only)",
+		Long: `Manage bypass4netnsd, the daemon for accelerating the networking of rootless containers with bypass4netns.
+The daemon listens on "${XDG_RUNTIME_DIR}/bypass4netnsd.sock".
+When bypass4netnsd is managed by systemd (containerd-rootless-setuptool.sh install-bypass4netnsd), these commands are not needed.`,
+		RunE:          helpers.UnknownSubcommandAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:           "start",
+			Short:         "Start bypass4netnsd in the background",
+			Args:          cobra.NoArgs,
+			RunE:          bypass4netnsdStartAction,
+			SilenceUsage:  true,
+			SilenceErrors: true,
+		},
+		&cobra.Command{
+			Use:           "stop",
+			Short:         "Stop bypass4netnsd started by `nerdctl system bypass4netnsd start`",
+			Args:          cobra.NoArgs,
+			RunE:          bypass4netnsdStopAction,
+			SilenceUsage:  true,
+			SilenceErrors: true,
+		},
+		bypass4netnsdStatusCommand(),
+	)
+	return cmd
+}
+
+func bypass4netnsdStatusCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "status",
+		Short:         "Show the status of bypass4netnsd",
+		Args:          cobra.NoArgs,
+		RunE:          bypass4netnsdStatusAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().StringP("format", "f", "", "Format the output using the given Go template, e.g, '{{json .}}'")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	return cmd
+}
+
+func bypass4netnsdStartAction(cmd *cobra.Command, _ []string) error {
+	return system.Bypass4netnsdStart(cmd.Context(), types.SystemBypass4netnsdOptions{Stdout: cmd.OutOrStdout()})
+}
+
+func bypass4netnsdStopAction(cmd *cobra.Command, _ []string) error {
+	return system.Bypass4netnsdStop(cmd.Context(), types.SystemBypass4netnsdOptions{Stdout: cmd.OutOrStdout()})
+}
+
+func bypass4netnsdStatusAction(cmd *cobra.Command, _ []string) error {
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	return system.Bypass4netnsdStatus(cmd.Context(), types.SystemBypass4netnsdOptions{
+		Stdout: cmd.OutOrStdout(),
+		Format: format,
+	})
+}
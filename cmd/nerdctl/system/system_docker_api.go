@@ -0,0 +1,85 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/system"
+	ncdefaults "github.com/containerd/nerdctl/v2/pkg/defaults"
+)
+
+func dockerAPICommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "docker-api [flags]",
+		Short: "Serve a subset of the Docker Engine API on a unix socket (experimental)",
+		Long: `Serve the commonly used subset of the Docker Engine API (containers, images, exec, volumes, and networks) on a unix socket,
+so that the tools written for Docker can be used with containerd, e.g., by setting DOCKER_HOST=unix://<socket>.
+See docs/docker-api.md for the supported endpoints.
+
+The socket is only accessible by the owner, and the group specified with --group.
+`,
+		Args:          cobra.NoArgs,
+		PreRunE:       helpers.CheckExperimental("`nerdctl system docker-api`"),
+		RunE:          dockerAPIAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().String("socket", filepath.Join(filepath.Dir(ncdefaults.APIServerSocket()), "docker.sock"), "Path of the unix socket to listen on")
+	cmd.Flags().String("group", "", "Name or ID of the group that may access the socket, in addition to the owner")
+	return cmd
+}
+
+func dockerAPIOptions(cmd *cobra.Command) (types.SystemDockerAPIOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.SystemDockerAPIOptions{}, err
+	}
+	socket, err := cmd.Flags().GetString("socket")
+	if err != nil {
+		return types.SystemDockerAPIOptions{}, err
+	}
+	group, err := cmd.Flags().GetString("group")
+	if err != nil {
+		return types.SystemDockerAPIOptions{}, err
+	}
+	nerdctlCmd, nerdctlArgs := helpers.GlobalFlags(cmd)
+	return types.SystemDockerAPIOptions{
+		GOptions:    globalOptions,
+		Socket:      socket,
+		Group:       group,
+		NerdctlCmd:  nerdctlCmd,
+		NerdctlArgs: nerdctlArgs,
+	}, nil
+}
+
+func dockerAPIAction(cmd *cobra.Command, _ []string) error {
+	options, err := dockerAPIOptions(cmd)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return system.DockerAPI(ctx, options)
+}
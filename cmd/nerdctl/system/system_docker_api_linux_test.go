@@ -0,0 +1,116 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	dockerclient "github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestSystemDockerAPI(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("pull", "--quiet", testutil.CommonImage)
+		// Not under data.Temp(), which may exceed the maximum length of a socket path
+		dir, err := os.MkdirTemp("", "nerdctl-docker-api")
+		assert.NilError(helpers.T(), err)
+		data.Labels().Set("dir", dir)
+		data.Labels().Set("container", data.Identifier())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+		if dir := data.Labels().Get("dir"); dir != "" {
+			os.RemoveAll(dir)
+		}
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		socket := filepath.Join(data.Labels().Get("dir"), "docker.sock")
+		cmd := helpers.Command("system", "docker-api", "--socket", socket)
+		cmd.WithTimeout(30 * time.Second)
+		cmd.Background()
+
+		ctx := context.Background()
+		client, err := dockerclient.NewClientWithOpts(dockerclient.WithHost("unix://"+socket), dockerclient.WithAPIVersionNegotiation())
+		assert.NilError(helpers.T(), err)
+		defer client.Close()
+		for range 50 {
+			if _, err = client.Ping(ctx); err == nil {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		assert.NilError(helpers.T(), err)
+
+		created, err := client.ContainerCreate(ctx, &dockercontainer.Config{
+			Image: testutil.CommonImage,
+			Cmd:   []string{"sh", "-c", "echo foo; echo bar >&2; exit 3"},
+		}, nil, nil, nil, data.Labels().Get("container"))
+		assert.NilError(helpers.T(), err)
+
+		statusC, errC := client.ContainerWait(ctx, created.ID, dockercontainer.WaitConditionNextExit)
+		assert.NilError(helpers.T(), client.ContainerStart(ctx, created.ID, dockercontainer.StartOptions{}))
+		select {
+		case status := <-statusC:
+			assert.Equal(helpers.T(), status.StatusCode, int64(3))
+		case err := <-errC:
+			assert.NilError(helpers.T(), err)
+		}
+
+		inspected, err := client.ContainerInspect(ctx, created.ID)
+		assert.NilError(helpers.T(), err)
+		assert.Equal(helpers.T(), inspected.Name, "/"+data.Labels().Get("container"))
+
+		logs, err := client.ContainerLogs(ctx, created.ID, dockercontainer.LogsOptions{ShowStdout: true, ShowStderr: true})
+		assert.NilError(helpers.T(), err)
+		var stdout, stderr bytes.Buffer
+		_, err = stdcopy.StdCopy(&stdout, &stderr, logs)
+		logs.Close()
+		assert.NilError(helpers.T(), err)
+		assert.Equal(helpers.T(), stdout.String(), "foo\n")
+		assert.Equal(helpers.T(), stderr.String(), "bar\n")
+
+		assert.NilError(helpers.T(), client.ContainerRemove(ctx, created.ID, dockercontainer.RemoveOptions{}))
+		_, err = client.ContainerInspect(ctx, created.ID)
+		assert.Assert(helpers.T(), dockerclient.IsErrNotFound(err), err)
+		return cmd
+	}
+
+	testCase.Expected = test.Expects(expect.ExitCodeTimeout, nil, nil)
+
+	testCase.Run(t)
+}
@@ -0,0 +1,99 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/cmd/system"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func parseDoctorChecks(t *testing.T, stdout string) map[string]system.DoctorCheck {
+	checks := make(map[string]system.DoctorCheck)
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		var c system.DoctorCheck
+		assert.NilError(t, json.Unmarshal([]byte(line), &c), line)
+		checks[c.Name] = c
+	}
+	return checks
+}
+
+func TestSystemDoctor(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	const staleDirKey = "staleDir"
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "json",
+			Command:     test.Command("system", "doctor", "--format", "json"),
+			Expected: test.Expects(expect.ExitCodeNoCheck, nil, func(stdout string, info string, t *testing.T) {
+				checks := parseDoctorChecks(t, stdout)
+				assert.Equal(t, checks["containerd"].Status, "ok", info)
+				assert.Equal(t, checks["containerd version"].Status != "fail", true, info)
+			}),
+		},
+		{
+			Description: "fix stale state directories",
+			Require:     nerdtest.Rootful,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				dataRoot := data.Temp().Path()
+				// creates the data store
+				helpers.Anyhow("system", "doctor", "--data-root", dataRoot)
+				entries, err := os.ReadDir(dataRoot)
+				assert.NilError(t, err)
+				assert.Equal(t, len(entries), 1)
+				namespace := string(helpers.Read(nerdtest.Namespace))
+				staleDir := filepath.Join(dataRoot, entries[0].Name(), "containers", namespace, strings.Repeat("0", 64))
+				assert.NilError(t, os.MkdirAll(staleDir, 0o700))
+				old := time.Now().Add(-time.Hour)
+				assert.NilError(t, os.Chtimes(staleDir, old, old))
+				data.Labels().Set(staleDirKey, staleDir)
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("system", "doctor", "--data-root", data.Temp().Path(), "--fix", "--format", "json")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					ExitCode: expect.ExitCodeNoCheck,
+					Output: func(stdout string, info string, t *testing.T) {
+						checks := parseDoctorChecks(t, stdout)
+						assert.Equal(t, checks["state directories"].Fixed, true, info)
+						_, err := os.Stat(data.Labels().Get(staleDirKey))
+						assert.Assert(t, os.IsNotExist(err), info)
+					},
+				}
+			},
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -0,0 +1,149 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/system"
+)
+
+func gcCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gc [flags]",
+		Short: "Remove unused images according to the garbage collection policy",
+		Long: `Remove unused images according to the garbage collection policy.
+
+The policy is configured in the [gc] table of nerdctl.toml, and can be overridden with the flags:
+first the unused images older than the max image age are removed, then the oldest unused images
+are removed until the content store fits in the max content size.
+The images used by containers and the images matching the keep patterns are never removed.
+
+With --schedule, the garbage collection is repeated at the interval until the process is terminated.
+`,
+		Args:          cobra.NoArgs,
+		RunE:          gcAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().String("max-content-size", "", `Size budget of the content store, e.g., "10GiB" (default: "max_content_size" in nerdctl.toml)`)
+	cmd.Flags().String("max-image-age", "", `Age after which unused images are removed, e.g., "720h" (default: "max_image_age" in nerdctl.toml)`)
+	cmd.Flags().StringArray("keep", nil, `Image reference pattern that is never removed, e.g., "alpine:*" (default: "keep" in nerdctl.toml)`)
+	cmd.Flags().Bool("dry-run", false, "Only print the images that would be removed")
+	cmd.Flags().Bool("schedule", false, "Keep running, and repeat the garbage collection at the interval")
+	cmd.Flags().String("interval", "", `Interval of --schedule, e.g., "1h" (default: "interval" in nerdctl.toml, or "1h")`)
+	return cmd
+}
+
+func gcOptions(cmd *cobra.Command) (types.SystemGCOptions, time.Duration, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.SystemGCOptions{}, 0, err
+	}
+	cfg, err := helpers.LoadNerdctlTOML(helpers.NerdctlTOMLPath())
+	if err != nil {
+		return types.SystemGCOptions{}, 0, err
+	}
+	policy := cfg.GC
+	if cmd.Flags().Changed("max-content-size") {
+		if policy.MaxContentSize, err = cmd.Flags().GetString("max-content-size"); err != nil {
+			return types.SystemGCOptions{}, 0, err
+		}
+	}
+	if cmd.Flags().Changed("max-image-age") {
+		if policy.MaxImageAge, err = cmd.Flags().GetString("max-image-age"); err != nil {
+			return types.SystemGCOptions{}, 0, err
+		}
+	}
+	if cmd.Flags().Changed("keep") {
+		if policy.Keep, err = cmd.Flags().GetStringArray("keep"); err != nil {
+			return types.SystemGCOptions{}, 0, err
+		}
+	}
+	if cmd.Flags().Changed("interval") {
+		if policy.Interval, err = cmd.Flags().GetString("interval"); err != nil {
+			return types.SystemGCOptions{}, 0, err
+		}
+	}
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return types.SystemGCOptions{}, 0, err
+	}
+
+	options := types.SystemGCOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+		Keep:     policy.Keep,
+		DryRun:   dryRun,
+	}
+	if policy.MaxContentSize != "" {
+		if options.MaxContentSize, err = units.RAMInBytes(policy.MaxContentSize); err != nil {
+			return types.SystemGCOptions{}, 0, fmt.Errorf("invalid max content size %q: %w", policy.MaxContentSize, err)
+		}
+	}
+	if policy.MaxImageAge != "" {
+		if options.MaxImageAge, err = time.ParseDuration(policy.MaxImageAge); err != nil {
+			return types.SystemGCOptions{}, 0, fmt.Errorf("invalid max image age %q: %w", policy.MaxImageAge, err)
+		}
+	}
+	if options.MaxContentSize <= 0 && options.MaxImageAge <= 0 {
+		return types.SystemGCOptions{}, 0, errors.New("no garbage collection policy: specify --max-content-size or --max-image-age, or configure [gc] in nerdctl.toml")
+	}
+	interval, err := time.ParseDuration(policy.Interval)
+	if err != nil || interval <= 0 {
+		return types.SystemGCOptions{}, 0, fmt.Errorf("invalid interval %q", policy.Interval)
+	}
+	return options, interval, nil
+}
+
+func gcAction(cmd *cobra.Command, _ []string) error {
+	options, interval, err := gcOptions(cmd)
+	if err != nil {
+		return err
+	}
+	schedule, err := cmd.Flags().GetBool("schedule")
+	if err != nil {
+		return err
+	}
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if !schedule {
+		return system.GC(ctx, client, options)
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	log.G(ctx).Infof("Running garbage collection every %s", interval)
+	return system.GCSchedule(ctx, client, options, interval)
+}
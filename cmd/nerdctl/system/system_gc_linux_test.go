@@ -0,0 +1,106 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestSystemGC(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "no policy",
+			Command:     test.Command("system", "gc"),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, []error{errors.New("no garbage collection policy")}, nil),
+		},
+		{
+			Description: "invalid keep pattern",
+			Command:     test.Command("system", "gc", "--max-image-age", "1h", "--keep", "[", "--dry-run"),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, []error{errors.New("invalid keep pattern")}, nil),
+		},
+		{
+			Description: "max image age with keep patterns",
+			// Private because of removing all the unused images evidently
+			Require: nerdtest.Private,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("pull", "--quiet", testutil.CommonImage)
+				helpers.Ensure("tag", testutil.CommonImage, data.Identifier("keep"))
+				helpers.Ensure("tag", testutil.CommonImage, data.Identifier("remove"))
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rmi", "-f", data.Identifier("keep"), data.Identifier("remove"))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("system", "gc", "--max-image-age", "1ns", "--keep", data.Identifier("keep")+":*")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.All(
+						expect.Contains("Removed: docker.io/library/"+data.Identifier("remove")+":latest"),
+						expect.DoesNotContain(data.Identifier("keep")),
+						func(stdout string, info string, t *testing.T) {
+							images := helpers.Capture("images")
+							assert.Assert(t, strings.Contains(images, data.Identifier("keep")), images)
+							assert.Assert(t, !strings.Contains(images, data.Identifier("remove")), images)
+						},
+					),
+				}
+			},
+		},
+		{
+			Description: "dry run",
+			Require:     nerdtest.Private,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("pull", "--quiet", testutil.CommonImage)
+				helpers.Ensure("tag", testutil.CommonImage, data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rmi", "-f", data.Identifier())
+			},
+			Command: test.Command("system", "gc", "--max-content-size", "1", "--dry-run"),
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.All(
+						expect.Contains("Would remove: docker.io/library/"+data.Identifier()+":latest"),
+						expect.Contains("(estimated)"),
+						func(stdout string, info string, t *testing.T) {
+							images := helpers.Capture("images")
+							assert.Assert(t, strings.Contains(images, data.Identifier()), images)
+						},
+					),
+				}
+			},
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/system"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 )
 
 func InfoCommand() *cobra.Command {
@@ -59,6 +60,10 @@ func infoOptions(cmd *cobra.Command) (types.SystemInfoOptions, error) {
 	if err != nil {
 		return types.SystemInfoOptions{}, err
 	}
+	format, err = helpers.ListFormat(globalOptions, format, false)
+	if err != nil {
+		return types.SystemInfoOptions{}, err
+	}
 	return types.SystemInfoOptions{
 		GOptions: globalOptions,
 		Mode:     mode,
@@ -80,5 +85,7 @@ func infoAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
-	return system.Info(ctx, client, options)
+	stdout, finish := helpers.OutputWriter(cmd, options.GOptions, formatter.KindInfo, formatter.ItemsFromJSONLines)
+	options.Stdout = stdout
+	return finish(system.Info(ctx, client, options))
 }
@@ -61,6 +61,18 @@ func TestInfo(t *testing.T) {
 			Command:     test.Command("info", "--format", "json"),
 			Expected:    test.Expects(0, nil, testInfoComparator),
 		},
+		{
+			Description: "info with capabilities",
+			Require:     require.Not(nerdtest.Docker),
+			Command:     test.Command("info", "--format", "json"),
+			Expected: test.Expects(0, nil, func(stdout string, info string, t *testing.T) {
+				var dinf dockercompat.Info
+				err := json.Unmarshal([]byte(stdout), &dinf)
+				assert.NilError(t, err, "failed to unmarshal stdout"+info)
+				assert.Assert(t, dinf.Capabilities != nil, "expected info.Capabilities to be set"+info)
+				assert.Assert(t, len(dinf.Capabilities.Snapshotters) > 0, "expected at least one snapshotter"+info)
+			}),
+		},
 		{
 			Description: "info with namespace",
 			Require:     require.Not(nerdtest.Docker),
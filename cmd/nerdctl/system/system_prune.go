@@ -44,6 +44,12 @@ func pruneCommand() *cobra.Command {
 	cmd.Flags().BoolP("all", "a", false, "Remove all unused images, not just dangling ones")
 	cmd.Flags().BoolP("force", "f", false, "Do not prompt for confirmation")
 	cmd.Flags().Bool("volumes", false, "Prune volumes")
+	cmd.Flags().Bool("containers", false, "Prune stopped containers")
+	cmd.Flags().Bool("networks", false, "Prune unused networks")
+	cmd.Flags().String("images", "", "Prune images, \"dangling\" or \"all\" (unused)")
+	cmd.Flags().Bool("build-cache", false, "Prune build cache")
+	cmd.Flags().StringSlice("filter", nil, "Provide filter values (e.g. 'until=24h', 'label=<key>=<value>')")
+	helpers.AddParallelFlag(cmd)
 	return cmd
 }
 
@@ -63,21 +69,63 @@ func pruneOptions(cmd *cobra.Command) (types.SystemPruneOptions, error) {
 		return types.SystemPruneOptions{}, err
 	}
 
-	buildkitHost, err := builder.GetBuildkitHost(cmd, globalOptions.Namespace)
+	containers, err := cmd.Flags().GetBool("containers")
 	if err != nil {
-		log.L.WithError(err).Warn("BuildKit is not running. Build caches will not be pruned.")
-		buildkitHost = ""
+		return types.SystemPruneOptions{}, err
+	}
+
+	networks, err := cmd.Flags().GetBool("networks")
+	if err != nil {
+		return types.SystemPruneOptions{}, err
+	}
+
+	images, err := cmd.Flags().GetString("images")
+	if err != nil {
+		return types.SystemPruneOptions{}, err
+	}
+
+	buildCache, err := cmd.Flags().GetBool("build-cache")
+	if err != nil {
+		return types.SystemPruneOptions{}, err
+	}
+
+	filters, err := cmd.Flags().GetStringSlice("filter")
+	if err != nil {
+		return types.SystemPruneOptions{}, err
+	}
+
+	parallel, err := helpers.ProcessParallelFlag(cmd)
+	if err != nil {
+		return types.SystemPruneOptions{}, err
 	}
 
-	return types.SystemPruneOptions{
+	options, err := system.ResolvePruneTargets(types.SystemPruneOptions{
 		Stdout:               cmd.OutOrStdout(),
 		Stderr:               cmd.ErrOrStderr(),
 		GOptions:             globalOptions,
 		All:                  all,
 		Volumes:              vFlag,
-		BuildKitHost:         buildkitHost,
+		Containers:           containers,
+		Networks:             networks,
+		Images:               images,
+		BuildCache:           buildCache,
+		Filters:              filters,
 		NetworkDriversToKeep: network.NetworkDriversToKeep,
-	}, nil
+		Parallel:             parallel,
+	})
+	if err != nil {
+		return types.SystemPruneOptions{}, err
+	}
+
+	if options.BuildCache {
+		options.BuildKitHost, err = builder.GetBuildkitHost(cmd, globalOptions.Namespace)
+		if err != nil {
+			log.L.WithError(err).Warn("BuildKit is not running. Build caches will not be pruned.")
+			options.BuildKitHost = ""
+		}
+	}
+
+	return options, nil
 }
 
 func grantSystemPrunePermission(cmd *cobra.Command, options types.SystemPruneOptions) (bool, error) {
@@ -88,21 +136,41 @@ func grantSystemPrunePermission(cmd *cobra.Command, options types.SystemPruneOpt
 
 	if !force {
 		var confirm string
-		msg := `This will remove:
-  - all stopped containers
+		msg := "This will remove:"
+		if options.Containers {
+			msg += `
+  - all stopped containers`
+		}
+		if options.Networks {
+			msg += `
   - all networks not used by at least one container`
+		}
 		if options.Volumes {
 			msg += `
-  - all volumes not used by at least one container`
+  - all anonymous volumes not used by at least one container`
 		}
-		if options.All {
+		switch options.Images {
+		case "all":
 			msg += `
-  - all images without at least one container associated to them
-  - all build cache`
-		} else {
+  - all images without at least one container associated to them`
+		case "dangling":
 			msg += `
-  - all dangling images
+  - all dangling images`
+		}
+		if options.BuildCache {
+			if options.All {
+				msg += `
+  - all build cache`
+			} else {
+				msg += `
   - all dangling build cache`
+			}
+		}
+		if len(options.Filters) > 0 {
+			msg += "\n\nItems to be pruned will be filtered with:"
+			for _, f := range options.Filters {
+				msg += "\n  - " + f
+			}
 		}
 
 		msg += "\nAre you sure you want to continue? [y/N] "
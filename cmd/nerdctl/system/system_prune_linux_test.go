@@ -17,6 +17,7 @@
 package system
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -74,6 +75,59 @@ func TestSystemPrune(t *testing.T) {
 				}
 			},
 		},
+		{
+			Description: "networks only",
+			Require:     require.All(nerdtest.Private, require.Not(nerdtest.Docker)),
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("network", "create", data.Identifier())
+				helpers.Ensure("create", "--name", data.Identifier(), testutil.CommonImage)
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("network", "rm", data.Identifier())
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: test.Command("system", "prune", "-f", "--networks"),
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.All(
+						expect.Contains("Total reclaimed space:"),
+						func(stdout string, info string, t *testing.T) {
+							networks := helpers.Capture("network", "ls")
+							containers := helpers.Capture("ps", "-a")
+							assert.Assert(t, !strings.Contains(networks, data.Identifier()), networks)
+							assert.Assert(t, strings.Contains(containers, data.Identifier()), containers)
+						},
+					),
+				}
+			},
+		},
+		{
+			Description: "containers filtered by label",
+			Require:     require.All(nerdtest.Private, require.Not(nerdtest.Docker)),
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("create", "--name", data.Identifier("labeled"), "--label", "prune=yes", testutil.CommonImage)
+				helpers.Ensure("create", "--name", data.Identifier("unlabeled"), testutil.CommonImage)
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier("labeled"), data.Identifier("unlabeled"))
+			},
+			Command: test.Command("system", "prune", "-f", "--containers", "--filter", "label=prune=yes"),
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout string, info string, t *testing.T) {
+						containers := helpers.Capture("ps", "-a")
+						assert.Assert(t, !strings.Contains(containers, data.Identifier("labeled")), containers)
+						assert.Assert(t, strings.Contains(containers, data.Identifier("unlabeled")), containers)
+					},
+				}
+			},
+		},
+		{
+			Description: "until is not supported with volumes",
+			Require:     require.Not(nerdtest.Docker),
+			Command:     test.Command("system", "prune", "-f", "--volumes", "--filter", "until=24h"),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, []error{errors.New("not supported with --volumes")}, nil),
+		},
 		{
 			Description: "buildkit",
 			// FIXME: using a dedicated namespace does not work with rootful (because of buildkitd)
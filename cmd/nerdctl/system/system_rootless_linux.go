@@ -0,0 +1,89 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/system"
+)
+
+func rootlessCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "rootless",
+		Short:         "Manage rootless containerd",
+		RunE:          helpers.UnknownSubcommandAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.AddCommand(rootlessSetupCommand())
+	return cmd
+}
+
+func rootlessSetupCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "setup [flags]",
+		Short: "Set up rootless containerd as a systemd user service",
+		Long: `Set up rootless containerd as a systemd user service.
+
+The prerequisites (subuid/subgid ranges, newuidmap/newgidmap, systemd, the network driver of RootlessKit,
+and the delegation of the cgroup v2 controllers) are checked first, with hints for the unsatisfied ones.
+Then "containerd.service" is installed to "~/.config/systemd/user" and started.
+
+The port driver of RootlessKit is specified with the global --rootlesskit-port-driver flag,
+or with "rootlesskit_port_driver" in nerdctl.toml.`,
+		Args:          cobra.NoArgs,
+		RunE:          rootlessSetupAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().Bool("check", false, "Only check the prerequisites")
+	cmd.Flags().String("net", "auto", `Network driver of RootlessKit, "auto" (pasta if installed, otherwise slirp4netns), "pasta", or "slirp4netns"`)
+	cmd.RegisterFlagCompletionFunc("net", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"auto", "pasta", "slirp4netns"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().Bool("force", false, "Overwrite the existing systemd unit")
+	return cmd
+}
+
+func rootlessSetupAction(cmd *cobra.Command, _ []string) error {
+	checkOnly, err := cmd.Flags().GetBool("check")
+	if err != nil {
+		return err
+	}
+	net, err := cmd.Flags().GetString("net")
+	if err != nil {
+		return err
+	}
+	force, err := cmd.Flags().GetBool("force")
+	if err != nil {
+		return err
+	}
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	return system.RootlessSetup(cmd.Context(), types.SystemRootlessSetupOptions{
+		Stdout:     cmd.OutOrStdout(),
+		Net:        net,
+		CheckOnly:  checkOnly,
+		Force:      force,
+		PortDriver: globalOptions.RootlessKitPortDriver,
+	})
+}
@@ -0,0 +1,100 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/system"
+	ncdefaults "github.com/containerd/nerdctl/v2/pkg/defaults"
+)
+
+func serveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve [flags]",
+		Short: "Serve the nerdctl API on a unix socket",
+		Long: `Serve a subset of the nerdctl operations (images, containers, and compose projects) as JSON over HTTP on a unix socket,
+so that GUIs and agents can drive nerdctl without running the nerdctl binary for every call.
+See docs/api.md for the API.
+
+The socket is only accessible by the owner, and the group specified with --group.
+Every request is also authorized by --read-only, --allow-uid, and --authz-command, when specified.
+The authorization command receives the request as JSON on its stdin, and allows the request by exiting with 0.
+`,
+		Args:          cobra.NoArgs,
+		RunE:          serveAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().String("socket", ncdefaults.APIServerSocket(), "Path of the unix socket to listen on")
+	cmd.Flags().String("group", "", "Name or ID of the group that may access the socket, in addition to the owner")
+	cmd.Flags().Bool("read-only", false, "Only allow the operations that do not modify anything")
+	cmd.Flags().IntSlice("allow-uid", nil, "Only allow the clients running as the uid (can be specified multiple times, Linux only)")
+	cmd.Flags().String("authz-command", "", "Command that authorizes every request")
+	return cmd
+}
+
+func serveOptions(cmd *cobra.Command) (types.SystemServeOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.SystemServeOptions{}, err
+	}
+	socket, err := cmd.Flags().GetString("socket")
+	if err != nil {
+		return types.SystemServeOptions{}, err
+	}
+	group, err := cmd.Flags().GetString("group")
+	if err != nil {
+		return types.SystemServeOptions{}, err
+	}
+	readOnly, err := cmd.Flags().GetBool("read-only")
+	if err != nil {
+		return types.SystemServeOptions{}, err
+	}
+	allowUIDs, err := cmd.Flags().GetIntSlice("allow-uid")
+	if err != nil {
+		return types.SystemServeOptions{}, err
+	}
+	authzCommand, err := cmd.Flags().GetString("authz-command")
+	if err != nil {
+		return types.SystemServeOptions{}, err
+	}
+	return types.SystemServeOptions{
+		GOptions:     globalOptions,
+		Socket:       socket,
+		Group:        group,
+		ReadOnly:     readOnly,
+		AllowUIDs:    allowUIDs,
+		AuthzCommand: authzCommand,
+	}, nil
+}
+
+func serveAction(cmd *cobra.Command, _ []string) error {
+	options, err := serveOptions(cmd)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return system.Serve(ctx, options)
+}
@@ -0,0 +1,108 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/sdk"
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestSystemServe(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("create", "--name", data.Identifier(), testutil.CommonImage)
+		// Not under data.Temp(), which may exceed the maximum length of a socket path
+		dir, err := os.MkdirTemp("", "nerdctl-serve")
+		assert.NilError(helpers.T(), err)
+		data.Labels().Set("dir", dir)
+		data.Labels().Set("container", data.Identifier())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+		if dir := data.Labels().Get("dir"); dir != "" {
+			os.RemoveAll(dir)
+		}
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		socket := filepath.Join(data.Labels().Get("dir"), "nerdctl.sock")
+		cmd := helpers.Command("system", "serve", "--socket", socket, "--read-only")
+		cmd.WithTimeout(10 * time.Second)
+		cmd.Background()
+
+		client := &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+				},
+			},
+		}
+		var (
+			resp *http.Response
+			err  error
+		)
+		for range 50 {
+			if resp, err = client.Get("http://nerdctl/v1/containers?all=1"); err == nil {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		assert.NilError(helpers.T(), err)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.NilError(helpers.T(), err)
+		assert.Equal(helpers.T(), resp.StatusCode, http.StatusOK, string(body))
+		var containers []sdk.Container
+		assert.NilError(helpers.T(), json.Unmarshal(body, &containers))
+		found := false
+		for _, c := range containers {
+			found = found || c.Name == data.Labels().Get("container")
+		}
+		assert.Assert(helpers.T(), found, string(body))
+
+		resp, err = client.Post("http://nerdctl/v1/containers/"+data.Labels().Get("container")+"/start", "", nil)
+		assert.NilError(helpers.T(), err)
+		resp.Body.Close()
+		assert.Equal(helpers.T(), resp.StatusCode, http.StatusForbidden)
+		return cmd
+	}
+
+	testCase.Expected = test.Expects(expect.ExitCodeTimeout, nil, nil)
+
+	testCase.Run(t)
+}
@@ -0,0 +1,144 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/system"
+	ncdefaults "github.com/containerd/nerdctl/v2/pkg/defaults"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+func watchdogCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watchdog [flags]",
+		Short: "Monitor the disk usage, and evict containers under disk pressure",
+		Long: `Monitor the usage of the filesystem of the containerd root directory.
+
+When the usage exceeds the threshold, the unused images are pruned (except the images matching "keep" in the [gc] table
+of nerdctl.toml), and then the containers are stopped and removed in the order of the eviction priority label,
+until the usage goes below the target.
+
+The containers with lower priorities are evicted first. The containers without the label have the priority 0.
+The containers with the label value "never" are never evicted.
+
+The watchdog publishes the "` + system.WatchdogPressureTopic + `" and "` + system.WatchdogEvictTopic + `" events,
+which can be observed with "nerdctl events".
+`,
+		Args:          cobra.NoArgs,
+		RunE:          watchdogAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().String("root", ncdefaults.ContainerdRoot(), "Directory on the monitored filesystem")
+	cmd.Flags().Float64("threshold", 90, "Filesystem usage in percent, above which images are pruned and containers are evicted")
+	cmd.Flags().Float64("target", 80, "Filesystem usage in percent that the pruning and the eviction aim at")
+	cmd.Flags().Duration("interval", 30*time.Second, "Interval of monitoring")
+	cmd.Flags().String("priority-label", labels.EvictionPriority, "Container label that specifies the eviction priority")
+	cmd.Flags().Duration("stop-timeout", 10*time.Second, "Timeout for stopping a running container before evicting it")
+	cmd.Flags().Bool("once", false, "Check the filesystem usage only once, instead of monitoring it")
+	return cmd
+}
+
+func watchdogOptions(cmd *cobra.Command) (types.SystemWatchdogOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.SystemWatchdogOptions{}, err
+	}
+	cfg, err := helpers.LoadNerdctlTOML(helpers.NerdctlTOMLPath())
+	if err != nil {
+		return types.SystemWatchdogOptions{}, err
+	}
+	root, err := cmd.Flags().GetString("root")
+	if err != nil {
+		return types.SystemWatchdogOptions{}, err
+	}
+	threshold, err := cmd.Flags().GetFloat64("threshold")
+	if err != nil {
+		return types.SystemWatchdogOptions{}, err
+	}
+	target, err := cmd.Flags().GetFloat64("target")
+	if err != nil {
+		return types.SystemWatchdogOptions{}, err
+	}
+	if threshold <= 0 || threshold > 100 || target <= 0 || target > threshold {
+		return types.SystemWatchdogOptions{}, errors.New("the threshold and the target must satisfy 0 < target <= threshold <= 100")
+	}
+	interval, err := cmd.Flags().GetDuration("interval")
+	if err != nil {
+		return types.SystemWatchdogOptions{}, err
+	}
+	if interval <= 0 {
+		return types.SystemWatchdogOptions{}, errors.New("the interval must be positive")
+	}
+	priorityLabel, err := cmd.Flags().GetString("priority-label")
+	if err != nil {
+		return types.SystemWatchdogOptions{}, err
+	}
+	stopTimeout, err := cmd.Flags().GetDuration("stop-timeout")
+	if err != nil {
+		return types.SystemWatchdogOptions{}, err
+	}
+	once, err := cmd.Flags().GetBool("once")
+	if err != nil {
+		return types.SystemWatchdogOptions{}, err
+	}
+	return types.SystemWatchdogOptions{
+		Stdout:        cmd.OutOrStdout(),
+		GOptions:      globalOptions,
+		Root:          root,
+		Threshold:     threshold,
+		Target:        target,
+		Interval:      interval,
+		PriorityLabel: priorityLabel,
+		StopTimeout:   stopTimeout,
+		Keep:          cfg.GC.Keep,
+		Once:          once,
+	}, nil
+}
+
+func watchdogAction(cmd *cobra.Command, _ []string) error {
+	options, err := watchdogOptions(cmd)
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if !options.Once {
+		log.G(ctx).Infof("Monitoring the filesystem usage of %s every %s (threshold %.1f%%, target %.1f%%)",
+			options.Root, options.Interval, options.Threshold, options.Target)
+	}
+	return system.Watchdog(ctx, client, options)
+}
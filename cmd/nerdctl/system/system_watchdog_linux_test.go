@@ -0,0 +1,75 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestSystemWatchdog(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "target above threshold",
+			Command:     test.Command("system", "watchdog", "--once", "--threshold", "80", "--target", "90"),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, []error{errors.New("0 < target <= threshold <= 100")}, nil),
+		},
+		{
+			Description: "evict containers under pressure",
+			// Private because of evicting all the evictable containers evidently
+			Require: nerdtest.Private,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("run", "-d", "--name", data.Identifier("protected"), "--label", "nerdctl/eviction-priority=never", testutil.CommonImage, "sleep", nerdtest.Infinity)
+				helpers.Ensure("run", "-d", "--name", data.Identifier("evicted"), testutil.CommonImage, "sleep", nerdtest.Infinity)
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier("protected"), data.Identifier("evicted"))
+			},
+			// The thresholds are tiny, so that the watchdog always detects the pressure
+			Command: test.Command("system", "watchdog", "--once", "--threshold", "0.001", "--target", "0.001", "--stop-timeout", "1s"),
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.All(
+						expect.Contains("Evicted: "+data.Identifier("evicted")),
+						expect.DoesNotContain("Evicted: "+data.Identifier("protected")),
+						func(stdout string, info string, t *testing.T) {
+							containers := helpers.Capture("ps", "-a")
+							assert.Assert(t, strings.Contains(containers, data.Identifier("protected")), containers)
+							assert.Assert(t, !strings.Contains(containers, data.Identifier("evicted")), containers)
+						},
+					),
+				}
+			},
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -20,7 +20,6 @@ import (
 	"bytes"
 	"fmt"
 	"io"
-	"os"
 	"text/template"
 
 	"github.com/spf13/cobra"
@@ -28,11 +27,13 @@ import (
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/formatter"
 	"github.com/containerd/nerdctl/v2/pkg/infoutil"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+	"github.com/containerd/nerdctl/v2/pkg/sshutil"
 )
 
 func versionCommand() *cobra.Command {
@@ -52,8 +53,6 @@ func versionCommand() *cobra.Command {
 }
 
 func versionAction(cmd *cobra.Command, args []string) error {
-	var w io.Writer = os.Stdout
-	var tmpl *template.Template
 	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
 	if err != nil {
 		return err
@@ -62,8 +61,20 @@ func versionAction(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	format, err = helpers.ListFormat(globalOptions, format, false)
+	if err != nil {
+		return err
+	}
+	w, finish := helpers.OutputWriter(cmd, globalOptions, formatter.KindVersion, formatter.ItemsFromJSONLines)
+	return finish(printVersion(cmd, globalOptions, format, w))
+}
+
+func printVersion(cmd *cobra.Command, globalOptions types.GlobalCommandOptions, format string, w io.Writer) error {
+	var (
+		tmpl *template.Template
+		err  error
+	)
 	if format != "" {
-		var err error
 		tmpl, err = formatter.ParseTemplate(format)
 		if err != nil {
 			return err
@@ -72,7 +83,7 @@ func versionAction(cmd *cobra.Command, args []string) error {
 
 	address := globalOptions.Address
 	// rootless `nerdctl version` runs in the host namespaces, so the address is different
-	if rootlessutil.IsRootless() {
+	if rootlessutil.IsRootless() && !sshutil.IsSSH(address) && !clientutil.IsTCP(address) {
 		address, err = rootlessutil.RootlessContainredSockAddress()
 		if err != nil {
 			log.L.WithError(err).Warning("failed to inspect the rootless containerd socket address")
@@ -37,6 +37,10 @@ func Command() *cobra.Command {
 		createCommand(),
 		removeCommand(),
 		pruneCommand(),
+		exportCommand(),
+		importCommand(),
+		cloneCommand(),
+		snapshotCommand(),
 	)
 	return cmd
 }
@@ -0,0 +1,65 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volume
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
+)
+
+func cloneCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clone [flags] SOURCE TARGET",
+		Short: "Create a new volume with the content of an existing volume",
+		Long: `Create a new volume with the content, the labels, and the options (e.g., "size") of an existing volume.
+The files are reflinked on the file systems supporting them (btrfs, xfs), otherwise copied.`,
+		Args:              cobra.ExactArgs(2),
+		RunE:              cloneAction,
+		ValidArgsFunction: volumeCloneShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().StringArray("label", nil, "Set a label on the new volume")
+	return cmd
+}
+
+func cloneAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	labels, err := cmd.Flags().GetStringArray("label")
+	if err != nil {
+		return err
+	}
+	return volume.Clone(cmd.Context(), args[0], args[1], types.VolumeCloneOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+		Labels:   labels,
+	})
+}
+
+func volumeCloneShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completion.VolumeNames(cmd)
+}
@@ -18,6 +18,7 @@ package volume
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -26,6 +27,7 @@ import (
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
 func createCommand() *cobra.Command {
@@ -38,6 +40,9 @@ func createCommand() *cobra.Command {
 		SilenceErrors: true,
 	}
 	cmd.Flags().StringArray("label", nil, "Set a label on the volume")
+	cmd.Flags().StringP("driver", "d", "local", "Specify volume driver name (\"local\", or the name of a volume plugin)")
+	cmd.Flags().StringArrayP("opt", "o", nil, "Set driver specific options")
+	cmd.Flags().String("size", "", "Limit the size of the volume (e.g., \"10G\"), using the project quota of the file system")
 	return cmd
 }
 
@@ -56,10 +61,31 @@ func createOptions(cmd *cobra.Command) (types.VolumeCreateOptions, error) {
 		}
 	}
 
+	driver, err := cmd.Flags().GetString("driver")
+	if err != nil {
+		return types.VolumeCreateOptions{}, err
+	}
+	opts, err := cmd.Flags().GetStringArray("opt")
+	if err != nil {
+		return types.VolumeCreateOptions{}, err
+	}
+	for _, opt := range opts {
+		if !strings.Contains(opt, "=") {
+			return types.VolumeCreateOptions{}, fmt.Errorf("invalid option %q, expected KEY=VALUE (%w)", opt, errdefs.ErrInvalidArgument)
+		}
+	}
+	size, err := cmd.Flags().GetString("size")
+	if err != nil {
+		return types.VolumeCreateOptions{}, err
+	}
+
 	return types.VolumeCreateOptions{
-		GOptions: globalOptions,
-		Labels:   labels,
-		Stdout:   cmd.OutOrStdout(),
+		GOptions:   globalOptions,
+		Labels:     labels,
+		Driver:     driver,
+		DriverOpts: strutil.ConvertKVStringsToMap(opts),
+		Size:       size,
+		Stdout:     cmd.OutOrStdout(),
 	}, nil
 }
 
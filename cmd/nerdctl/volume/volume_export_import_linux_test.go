@@ -0,0 +1,82 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volume
+
+import (
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestVolumeExportImportClone(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("volume", "create", "--label", "foo=bar", data.Identifier("src"))
+		helpers.Ensure("run", "--rm", "-v", data.Identifier("src")+":/data", testutil.CommonImage,
+			"sh", "-euc", "echo hello > /data/hello; mkdir /data/dir; chown 1234:5678 /data/dir")
+		helpers.Ensure("volume", "export", "-o", data.Temp().Path("src.tar"), data.Identifier("src"))
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("volume", "rm", "-f", data.Identifier("src"), data.Identifier("imported"), data.Identifier("cloned"))
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "import",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("volume", "import", "-i", data.Temp().Path("src.tar"), data.Identifier("imported"))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "-v", data.Identifier("imported")+":/data", testutil.CommonImage,
+					"sh", "-euc", "cat /data/hello; stat -c %u:%g /data/dir")
+			},
+			Expected: test.Expects(0, nil, expect.Equals("hello\n1234:5678\n")),
+		},
+		{
+			Description: "clone",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("volume", "clone", data.Identifier("src"), data.Identifier("cloned"))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "-v", data.Identifier("cloned")+":/data", testutil.CommonImage,
+					"sh", "-euc", "cat /data/hello; stat -c %u:%g /data/dir")
+			},
+			Expected: test.Expects(0, nil, expect.Equals("hello\n1234:5678\n")),
+		},
+		{
+			Description: "clone to an existing volume should fail",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("volume", "clone", data.Identifier("src"), data.Identifier("src"))
+			},
+			Expected: test.Expects(1, nil, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
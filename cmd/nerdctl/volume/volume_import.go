@@ -0,0 +1,63 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volume
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
+)
+
+func importCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import [flags] VOLUME",
+		Short: "Import a tar archive (read from STDIN by default) into a volume",
+		Long: `Import a tar archive into a volume, creating the volume if it does not exist.
+The archive may be compressed with gzip or zstd.`,
+		Args:          cobra.ExactArgs(1),
+		RunE:          importAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().StringP("input", "i", "", "Read from a tar archive file, instead of STDIN")
+	cmd.Flags().StringArray("label", nil, "Set a label on the volume, when it is created")
+	return cmd
+}
+
+func importAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	input, err := cmd.Flags().GetString("input")
+	if err != nil {
+		return err
+	}
+	labels, err := cmd.Flags().GetStringArray("label")
+	if err != nil {
+		return err
+	}
+	return volume.Import(cmd.Context(), args[0], types.VolumeImportOptions{
+		Stdout:   cmd.OutOrStdout(),
+		Stdin:    cmd.InOrStdin(),
+		GOptions: globalOptions,
+		Input:    input,
+		Labels:   labels,
+	})
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 )
 
 func inspectCommand() *cobra.Command {
@@ -56,6 +57,10 @@ func inspectOptions(cmd *cobra.Command) (types.VolumeInspectOptions, error) {
 	if err != nil {
 		return types.VolumeInspectOptions{}, err
 	}
+	format, err = helpers.InspectFormat(globalOptions, format)
+	if err != nil {
+		return types.VolumeInspectOptions{}, err
+	}
 	return types.VolumeInspectOptions{
 		GOptions: globalOptions,
 		Format:   format,
@@ -69,7 +74,9 @@ func inspectAction(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	return volume.Inspect(cmd.Context(), args, options)
+	stdout, finish := helpers.OutputWriter(cmd, options.GOptions, formatter.KindVolumeInspect, formatter.ItemsFromJSONArray)
+	options.Stdout = stdout
+	return finish(volume.Inspect(cmd.Context(), args, options))
 }
 
 func volumeInspectShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
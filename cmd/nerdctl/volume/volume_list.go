@@ -22,6 +22,7 @@ import (
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 )
 
 func listCommand() *cobra.Command {
@@ -58,6 +59,10 @@ func listOptions(cmd *cobra.Command) (types.VolumeListOptions, error) {
 	if err != nil {
 		return types.VolumeListOptions{}, err
 	}
+	format, err = helpers.ListFormat(globalOptions, format, quiet)
+	if err != nil {
+		return types.VolumeListOptions{}, err
+	}
 	size, err := cmd.Flags().GetBool("size")
 	if err != nil {
 		return types.VolumeListOptions{}, err
@@ -81,5 +86,7 @@ func listAction(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	return volume.List(options)
+	stdout, finish := helpers.OutputWriter(cmd, options.GOptions, formatter.KindVolumeList, formatter.ItemsFromJSONLines)
+	options.Stdout = stdout
+	return finish(volume.List(options))
 }
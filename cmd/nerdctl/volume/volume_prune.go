@@ -39,6 +39,7 @@ func pruneCommand() *cobra.Command {
 	}
 	cmd.Flags().BoolP("all", "a", false, "Remove all unused volumes, not just anonymous ones")
 	cmd.Flags().BoolP("force", "f", false, "Do not prompt for confirmation")
+	cmd.Flags().StringSlice("filter", nil, "Provide filter values (e.g. 'anonymous=true', 'label=<key>=<value>')")
 	return cmd
 }
 
@@ -58,10 +59,16 @@ func pruneOptions(cmd *cobra.Command) (types.VolumePruneOptions, error) {
 		return types.VolumePruneOptions{}, err
 	}
 
+	filters, err := cmd.Flags().GetStringSlice("filter")
+	if err != nil {
+		return types.VolumePruneOptions{}, err
+	}
+
 	options := types.VolumePruneOptions{
 		GOptions: globalOptions,
 		All:      all,
 		Force:    force,
+		Filters:  filters,
 		Stdout:   cmd.OutOrStdout(),
 	}
 	return options, nil
@@ -20,6 +20,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/containerd/errdefs"
 	"github.com/containerd/nerdctl/mod/tigron/expect"
 	"github.com/containerd/nerdctl/mod/tigron/test"
 
@@ -106,6 +107,59 @@ func TestVolumePrune(t *testing.T) {
 				}
 			},
 		},
+		{
+			Description: "prune named only with filter",
+			NoParallel:  true,
+			Setup:       setup,
+			Cleanup:     cleanup,
+			Command:     test.Command("volume", "prune", "-f", "--filter", "anonymous=false"),
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.All(
+						expect.Contains(data.Labels().Get("namedDangling")),
+						expect.DoesNotContain(
+							data.Labels().Get("anonIDBusy"),
+							data.Labels().Get("anonIDDangling"),
+							data.Labels().Get("namedBusy"),
+						),
+						func(stdout string, info string, t *testing.T) {
+							helpers.Ensure("volume", "inspect", data.Labels().Get("anonIDDangling"))
+							helpers.Fail("volume", "inspect", data.Labels().Get("namedDangling"))
+						},
+					),
+				}
+			},
+		},
+		{
+			Description: "prune with label filter",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				setup(data, helpers)
+				helpers.Ensure("volume", "create", "--label", "prune=yes", data.Identifier("labeled"))
+				data.Labels().Set("namedLabeled", data.Identifier("labeled"))
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				cleanup(data, helpers)
+				helpers.Anyhow("volume", "rm", "-f", data.Identifier("labeled"))
+			},
+			Command: test.Command("volume", "prune", "-f", "--all", "--filter", "label=prune=yes"),
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.All(
+						expect.Contains(data.Labels().Get("namedLabeled")),
+						expect.DoesNotContain(
+							data.Labels().Get("anonIDDangling"),
+							data.Labels().Get("namedDangling"),
+						),
+					),
+				}
+			},
+		},
+		{
+			Description: "unsupported filter should fail",
+			Command:     test.Command("volume", "prune", "-f", "--filter", "dangling=true"),
+			Expected:    test.Expects(1, []error{errdefs.ErrInvalidArgument}, nil),
+		},
 	}
 
 	testCase.Run(t)
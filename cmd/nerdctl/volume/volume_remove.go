@@ -24,6 +24,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 )
 
 func removeCommand() *cobra.Command {
@@ -70,7 +71,9 @@ func removeAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
-	return volume.Remove(ctx, client, args, options)
+	stdout, finish := helpers.OutputWriter(cmd, options.GOptions, formatter.KindVolumeRemove, formatter.ItemsFromTextLines)
+	options.Stdout = stdout
+	return finish(volume.Remove(ctx, client, args, options))
 }
 
 func removeShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
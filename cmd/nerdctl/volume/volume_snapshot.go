@@ -0,0 +1,190 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volume
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+)
+
+func snapshotCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Manage the snapshots of volumes",
+		Long: `Manage the snapshots of volumes.
+
+The snapshots are taken with btrfs when the volume directory is a btrfs subvolume,
+with ZFS when the volume directory is the mountpoint of a ZFS dataset,
+and by copying the files otherwise (with reflinks on the file systems supporting them).`,
+		RunE:          helpers.UnknownSubcommandAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.AddCommand(
+		snapshotCreateCommand(),
+		snapshotListCommand(),
+		snapshotRestoreCommand(),
+		snapshotRemoveCommand(),
+	)
+	return cmd
+}
+
+func snapshotCreateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "create [flags] VOLUME [SNAPSHOT]",
+		Short:             "Take a snapshot of a volume. The snapshot is named after the current time by default.",
+		Args:              cobra.RangeArgs(1, 2),
+		RunE:              snapshotCreateAction,
+		ValidArgsFunction: snapshotVolumeShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().Int("keep", 0, "Remove the oldest snapshots of the volume, keeping this number of snapshots (0 keeps all)")
+	return cmd
+}
+
+func snapshotCreateAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	keep, err := cmd.Flags().GetInt("keep")
+	if err != nil {
+		return err
+	}
+	snapshot := ""
+	if len(args) > 1 {
+		snapshot = args[1]
+	}
+	return volume.SnapshotCreate(cmd.Context(), args[0], snapshot, types.VolumeSnapshotCreateOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+		Keep:     keep,
+	})
+}
+
+func snapshotListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "ls [flags] VOLUME",
+		Aliases:           []string{"list"},
+		Short:             "List the snapshots of a volume",
+		Args:              cobra.ExactArgs(1),
+		RunE:              snapshotListAction,
+		ValidArgsFunction: snapshotVolumeShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().BoolP("quiet", "q", false, "Only display snapshot names")
+	cmd.Flags().String("format", "", "Format the output using the given go template")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "table", "wide"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	return cmd
+}
+
+func snapshotListAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	quiet, err := cmd.Flags().GetBool("quiet")
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	format, err = helpers.ListFormat(globalOptions, format, quiet)
+	if err != nil {
+		return err
+	}
+	stdout, finish := helpers.OutputWriter(cmd, globalOptions, formatter.KindVolumeSnapshotList, formatter.ItemsFromJSONLines)
+	return finish(volume.SnapshotList(cmd.Context(), args[0], types.VolumeSnapshotListOptions{
+		Stdout:   stdout,
+		GOptions: globalOptions,
+		Quiet:    quiet,
+		Format:   format,
+	}))
+}
+
+func snapshotRestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "restore [flags] VOLUME SNAPSHOT",
+		Short:             "Replace the content of a volume with a snapshot. The volume must not be used by running containers.",
+		Args:              cobra.ExactArgs(2),
+		RunE:              snapshotRestoreAction,
+		ValidArgsFunction: snapshotVolumeShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	return cmd
+}
+
+func snapshotRestoreAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), globalOptions.Namespace, globalOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	return volume.SnapshotRestore(ctx, client, args[0], args[1], types.VolumeSnapshotRestoreOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+	})
+}
+
+func snapshotRemoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "rm [flags] VOLUME SNAPSHOT [SNAPSHOT...]",
+		Aliases:           []string{"remove"},
+		Short:             "Remove snapshots of a volume",
+		Args:              cobra.MinimumNArgs(2),
+		RunE:              snapshotRemoveAction,
+		ValidArgsFunction: snapshotVolumeShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	return cmd
+}
+
+func snapshotRemoveAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	return volume.SnapshotRemove(cmd.Context(), args[0], args[1:], types.VolumeSnapshotRemoveOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+	})
+}
+
+func snapshotVolumeShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completion.VolumeNames(cmd)
+}
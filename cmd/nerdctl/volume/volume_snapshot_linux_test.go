@@ -0,0 +1,96 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volume
+
+import (
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestVolumeSnapshot(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("volume", "create", data.Identifier())
+		helpers.Ensure("run", "--rm", "-v", data.Identifier()+":/data", testutil.CommonImage, "sh", "-euc", "echo v1 > /data/f")
+		helpers.Ensure("volume", "snapshot", "create", data.Identifier(), "snap1")
+		helpers.Ensure("run", "--rm", "-v", data.Identifier()+":/data", testutil.CommonImage, "sh", "-euc", "echo v2 > /data/f; touch /data/g")
+		helpers.Ensure("volume", "snapshot", "create", data.Identifier(), "snap2")
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+		helpers.Anyhow("volume", "rm", "-f", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "ls",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("volume", "snapshot", "ls", "-q", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Equals("snap1\nsnap2\n")),
+		},
+		{
+			Description: "restore while the volume is in use should fail",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("run", "-d", "--name", data.Identifier(), "-v", data.Identifier()+":/data", testutil.CommonImage, "sleep", nerdtest.Infinity)
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("volume", "snapshot", "restore", data.Identifier(), "snap1")
+			},
+			Expected: test.Expects(1, nil, nil),
+		},
+		{
+			Description: "restore",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("volume", "snapshot", "restore", data.Identifier(), "snap1")
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "-v", data.Identifier()+":/data", testutil.CommonImage, "sh", "-euc", "cat /data/f; ls /data")
+			},
+			Expected: test.Expects(0, nil, expect.Equals("v1\nf\n")),
+		},
+		{
+			Description: "create with --keep",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("volume", "snapshot", "create", "--keep", "1", data.Identifier(), "snap3")
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("volume", "snapshot", "ls", "-q", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Equals("snap3\n")),
+		},
+	}
+
+	testCase.Run(t)
+}
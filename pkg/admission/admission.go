@@ -0,0 +1,160 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package admission admits the containers before creating them,
+// with the policy of the [admission] table of nerdctl.toml and the admission hooks.
+package admission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/config"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+// ErrDenied is returned when a container is rejected.
+var ErrDenied = errors.New("denied by the admission policy")
+
+// HookTimeout is the timeout of an admission hook.
+const HookTimeout = 30 * time.Second
+
+// Request is the container to be admitted, written to the stdin of the admission hooks as JSON.
+type Request struct {
+	Namespace string `json:"Namespace"`
+	ID        string `json:"ID"`
+	Name      string `json:"Name"`
+	// Image is the normalized name of the image, e.g., "docker.io/library/alpine:latest".
+	// Empty for the containers with `--rootfs`.
+	Image      string            `json:"Image"`
+	Privileged bool              `json:"Privileged"`
+	Labels     map[string]string `json:"Labels"`
+	Spec       *specs.Spec       `json:"Spec"`
+}
+
+// Response is the response of an admission hook, read from its stdout as JSON.
+// An empty stdout admits the container without mutating it.
+type Response struct {
+	Allowed bool   `json:"Allowed"`
+	Reason  string `json:"Reason,omitempty"`
+	// Labels replace the labels of the container, when not nil.
+	// The labels prefixed with "nerdctl/" cannot be mutated.
+	Labels map[string]string `json:"Labels,omitempty"`
+	// Spec replaces the spec of the container, when not nil.
+	Spec *specs.Spec `json:"Spec,omitempty"`
+}
+
+// Admit evaluates the policy, and then runs the hooks in order.
+// The hooks may mutate the labels and the spec of req.
+func Admit(ctx context.Context, cfg config.AdmissionConfig, req *Request) error {
+	if err := checkPolicy(cfg, req); err != nil {
+		return err
+	}
+	for _, hook := range cfg.Hooks {
+		if err := runHook(ctx, hook, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkPolicy(cfg config.AdmissionConfig, req *Request) error {
+	if cfg.DenyPrivileged && req.Privileged {
+		return fmt.Errorf("%w: privileged containers are not allowed", ErrDenied)
+	}
+	if len(cfg.AllowedRegistries) == 0 {
+		return nil
+	}
+	if req.Image == "" {
+		return fmt.Errorf("%w: containers without images are not allowed, as the registries are restricted", ErrDenied)
+	}
+	for _, allowed := range cfg.AllowedRegistries {
+		if strings.HasPrefix(req.Image, strings.TrimSuffix(allowed, "/")+"/") {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: image %q is not from the allowed registries %v", ErrDenied, req.Image, cfg.AllowedRegistries)
+}
+
+func runHook(ctx context.Context, hook string, req *Request) error {
+	ctx, cancel := context.WithTimeout(ctx, HookTimeout)
+	defer cancel()
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, hook)
+	cmd.Stdin = bytes.NewReader(b)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	log.G(ctx).Debugf("running admission hook %v", cmd.Args)
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return fmt.Errorf("failed to run admission hook %q: %w", hook, err)
+		}
+		return fmt.Errorf("%w: rejected by %q (%w): %s", ErrDenied, hook, err, strings.TrimSpace(stderr.String()))
+	}
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return nil
+	}
+	var res Response
+	if err := json.Unmarshal(stdout.Bytes(), &res); err != nil {
+		return fmt.Errorf("failed to parse the response of admission hook %q: %w", hook, err)
+	}
+	if !res.Allowed {
+		return fmt.Errorf("%w: rejected by %q: %s", ErrDenied, hook, res.Reason)
+	}
+	if res.Labels != nil {
+		if err := checkInternalLabels(req.Labels, res.Labels); err != nil {
+			return fmt.Errorf("admission hook %q: %w", hook, err)
+		}
+		req.Labels = res.Labels
+	}
+	if res.Spec != nil {
+		req.Spec = res.Spec
+	}
+	return nil
+}
+
+// checkInternalLabels checks that the labels prefixed with "nerdctl/" are not mutated.
+func checkInternalLabels(old, updated map[string]string) error {
+	internal := func(m map[string]string) map[string]string {
+		res := make(map[string]string)
+		for k, v := range m {
+			if strings.HasPrefix(k, labels.Prefix) {
+				res[k] = v
+			}
+		}
+		return res
+	}
+	if !maps.Equal(internal(old), internal(updated)) {
+		return fmt.Errorf("labels prefixed with %q cannot be mutated", labels.Prefix)
+	}
+	return nil
+}
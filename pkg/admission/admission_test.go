@@ -0,0 +1,73 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package admission
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/config"
+)
+
+func TestCheckPolicy(t *testing.T) {
+	cfg := config.AdmissionConfig{
+		DenyPrivileged:    true,
+		AllowedRegistries: []string{"registry.example.com", "docker.io/library/"},
+	}
+	for _, image := range []string{
+		"registry.example.com/foo/bar:latest",
+		"docker.io/library/alpine:latest",
+	} {
+		assert.NilError(t, checkPolicy(cfg, &Request{Image: image}), image)
+	}
+	for _, image := range []string{
+		"registry.example.com.evil.example/foo:latest",
+		"docker.io/evil/alpine:latest",
+		"",
+	} {
+		err := checkPolicy(cfg, &Request{Image: image})
+		assert.Assert(t, errors.Is(err, ErrDenied), image)
+	}
+
+	err := checkPolicy(cfg, &Request{Image: "docker.io/library/alpine:latest", Privileged: true})
+	assert.ErrorContains(t, err, "privileged containers are not allowed")
+
+	assert.NilError(t, checkPolicy(config.AdmissionConfig{}, &Request{Privileged: true}))
+}
+
+func TestAdmitPolicyBeforeHooks(t *testing.T) {
+	cfg := config.AdmissionConfig{
+		DenyPrivileged: true,
+		Hooks:          []string{"/nonexistent"},
+	}
+	err := Admit(context.Background(), cfg, &Request{Privileged: true})
+	assert.Assert(t, errors.Is(err, ErrDenied))
+
+	err = Admit(context.Background(), cfg, &Request{})
+	assert.ErrorContains(t, err, "failed to run admission hook")
+}
+
+func TestCheckInternalLabels(t *testing.T) {
+	old := map[string]string{"nerdctl/name": "foo", "com.example": "bar"}
+	assert.NilError(t, checkInternalLabels(old, map[string]string{"nerdctl/name": "foo", "com.example.owner": "team-a"}))
+	assert.ErrorContains(t, checkInternalLabels(old, map[string]string{"nerdctl/name": "bar"}), "cannot be mutated")
+	assert.ErrorContains(t, checkInternalLabels(old, map[string]string{}), "cannot be mutated")
+	assert.ErrorContains(t, checkInternalLabels(old, map[string]string{"nerdctl/name": "foo", "nerdctl/foo": "bar"}), "cannot be mutated")
+}
@@ -0,0 +1,92 @@
+//go:build unix
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package admission
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/config"
+)
+
+func writeHook(t *testing.T, script string) string {
+	t.Helper()
+	hook := filepath.Join(t.TempDir(), "hook")
+	assert.NilError(t, os.WriteFile(hook, []byte("#!/bin/sh\nset -eu\n"+script), 0o755))
+	return hook
+}
+
+func TestAdmitHooks(t *testing.T) {
+	newRequest := func() *Request {
+		return &Request{
+			ID:     "foo",
+			Labels: map[string]string{"nerdctl/name": "foo"},
+			Spec:   &specs.Spec{Hostname: "foo"},
+		}
+	}
+	ctx := context.Background()
+
+	// empty stdout admits the container as is
+	req := newRequest()
+	assert.NilError(t, Admit(ctx, config.AdmissionConfig{Hooks: []string{writeHook(t, "cat >/dev/null\n")}}, req))
+	assert.DeepEqual(t, req, newRequest())
+
+	// mutation
+	mutate := writeHook(t, `cat >/dev/null
+echo '{"Allowed": true, "Labels": {"nerdctl/name": "foo", "com.example.owner": "team-a"}, "Spec": {"hostname": "bar"}}'
+`)
+	req = newRequest()
+	assert.NilError(t, Admit(ctx, config.AdmissionConfig{Hooks: []string{mutate}}, req))
+	assert.Equal(t, req.Labels["com.example.owner"], "team-a")
+	assert.Equal(t, req.Spec.Hostname, "bar")
+
+	// the request is written to stdin
+	checkStdin := writeHook(t, `grep -q '"ID":"foo"' || { echo "unexpected request" >&2; exit 1; }
+`)
+	assert.NilError(t, Admit(ctx, config.AdmissionConfig{Hooks: []string{checkStdin}}, newRequest()))
+
+	for _, tc := range []struct {
+		script string
+		reason string
+	}{
+		{"cat >/dev/null\necho 'no privileged' >&2\nexit 1\n", "no privileged"},
+		{"cat >/dev/null\necho '{\"Allowed\": false, \"Reason\": \"no latest\"}'\n", "no latest"},
+	} {
+		err := Admit(ctx, config.AdmissionConfig{Hooks: []string{writeHook(t, tc.script)}}, newRequest())
+		assert.Assert(t, errors.Is(err, ErrDenied), tc.script)
+		assert.ErrorContains(t, err, tc.reason)
+	}
+
+	// the hooks are run in order, and a rejection stops the rest
+	rejectInternal := writeHook(t, `cat >/dev/null
+echo '{"Allowed": true, "Labels": {"nerdctl/name": "bar"}}'
+`)
+	err := Admit(ctx, config.AdmissionConfig{Hooks: []string{rejectInternal, mutate}}, newRequest())
+	assert.ErrorContains(t, err, "cannot be mutated")
+
+	invalid := writeHook(t, "cat >/dev/null\necho '{'\n")
+	err = Admit(ctx, config.AdmissionConfig{Hooks: []string{invalid}}, newRequest())
+	assert.ErrorContains(t, err, "failed to parse the response")
+}
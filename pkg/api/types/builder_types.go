@@ -16,7 +16,10 @@
 
 package types
 
-import "io"
+import (
+	"io"
+	"time"
+)
 
 // BuilderBuildOptions specifies options for `nerdctl (image/builder) build`.
 type BuilderBuildOptions struct {
@@ -63,6 +66,10 @@ type BuilderBuildOptions struct {
 	IidFile string
 	// Label is the metadata for an image
 	Label []string
+	// LabelFile is the line delimited files of the labels for an image
+	LabelFile []string
+	// DefaultLabels are the labels of the label_files and the [labels] table of nerdctl.toml, overridden by Label and LabelFile
+	DefaultLabels map[string]string
 	// BuildContext is the build context
 	BuildContext string
 	// ExtendedBuildContext is a pair of key=value (e.g. myorg/myapp=docker-image://path/to/image, dir2=/path/to/dir2)
@@ -73,6 +80,12 @@ type BuilderBuildOptions struct {
 	Pull *bool
 	// ExtraHosts is a set of custom host-to-IP mappings.
 	ExtraHosts []string
+	// BinfmtInstall installs the QEMU emulators for the target platforms that cannot be executed on the host
+	BinfmtInstall bool
+	// NerdctlCmd is the command name of nerdctl
+	NerdctlCmd string
+	// NerdctlArgs is the arguments of nerdctl
+	NerdctlArgs []string
 }
 
 // BuilderPruneOptions specifies options for `nerdctl builder prune`.
@@ -86,4 +99,73 @@ type BuilderPruneOptions struct {
 	All bool
 	// Force will not prompt for confirmation.
 	Force bool
+	// KeepStorage is the amount of build cache to keep, in bytes (0 for no limit)
+	KeepStorage int64
+	// Filters are the filters of the build cache to remove (e.g., "until=24h")
+	Filters []string
+}
+
+// BuilderStartOptions specifies options for `nerdctl builder start`.
+type BuilderStartOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Name is the name of the builder
+	Name string
+	// Worker is the worker of buildkitd ("containerd"|"oci"), empty for the default or the existing definition
+	Worker string
+	// Config is the path of buildkitd.toml
+	Config string
+	// BuildkitdFlags are extra flags for buildkitd
+	BuildkitdFlags []string
+	// Timeout is the time to wait for buildkitd to become ready
+	Timeout time.Duration
+}
+
+// BuilderStopOptions specifies options for `nerdctl builder stop`.
+type BuilderStopOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Timeout is the time to wait for buildkitd to exit before killing it
+	Timeout time.Duration
+}
+
+// BuilderListOptions specifies options for `nerdctl builder ls`.
+type BuilderListOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Format the output using the given Go template (e.g., '{{json .}}')
+	Format string
+	// Quiet only shows the names
+	Quiet bool
+}
+
+// BuilderRemoveOptions specifies options for `nerdctl builder rm`.
+type BuilderRemoveOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Force stops running builders before removing them
+	Force bool
+}
+
+// BuilderBinfmtListOptions specifies options for `nerdctl builder binfmt ls`.
+type BuilderBinfmtListOptions struct {
+	Stdout io.Writer
+	// Format the output using the given Go template (e.g., '{{json .}}')
+	Format string
+}
+
+// BuilderBinfmtInstallOptions specifies options for `nerdctl builder binfmt install`.
+type BuilderBinfmtInstallOptions struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// Image is the image of tonistiigi/binfmt
+	Image string
+	// NerdctlCmd is the command name of nerdctl
+	NerdctlCmd string
+	// NerdctlArgs is the arguments of nerdctl
+	NerdctlArgs []string
 }
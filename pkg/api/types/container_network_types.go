@@ -46,4 +46,14 @@ type NetworkOptions struct {
 	UTSNamespace string
 	// PortMappings specifies a list of ports to publish from the container to the host
 	PortMappings []cni.PortMapping
+	// PublishAll publishes all the ports exposed by the image to random host ports
+	PublishAll bool
+	// NetworkBandwidth limits the ingress and egress rate of the container (e.g., "10Mbit")
+	NetworkBandwidth string
+	// NetworkDelay delays the packets sent by the container (e.g., "50ms")
+	NetworkDelay string
+	// UserlandProxy runs a userland proxy for each published port, for environments where hairpin NAT is unavailable
+	UserlandProxy bool
+	// AllowFrom is the CIDRs allowed to connect to the published ports, on networks with the "deny" ingress policy
+	AllowFrom []string
 }
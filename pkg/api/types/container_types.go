@@ -19,6 +19,8 @@ package types
 import (
 	"io"
 	"time"
+
+	"github.com/containerd/nerdctl/v2/pkg/config"
 )
 
 // ContainerStartOptions specifies options for the `nerdctl (container) start`.
@@ -42,6 +44,10 @@ type ContainerKillOptions struct {
 	GOptions GlobalCommandOptions
 	// KillSignal is the signal to send to the container
 	KillSignal string
+	// AllProcesses sends the signal to all the processes in the container, not only to the init process
+	AllProcesses bool
+	// Parallel is the maximum number of containers processed concurrently
+	Parallel int
 }
 
 // ContainerCreateOptions specifies options for `nerdctl (container) create` and `nerdctl (container) run`.
@@ -55,9 +61,16 @@ type ContainerCreateOptions struct {
 	NerdctlCmd string
 	// NerdctlArgs is the arguments of nerdctl
 	NerdctlArgs []string
+	// CreateFlags are the flags of the command line, recorded for `nerdctl container auto-update`
+	CreateFlags []string
 
 	// InRun is true when it's generated in the `run` command
 	InRun bool
+	// Admission is the admission policy of the container
+	Admission config.AdmissionConfig
+	// DryRun prints the plan of the container (the OCI spec, the mounts, the networks, and the log config)
+	// as JSON, without creating the container
+	DryRun bool
 
 	// #region for basic flags
 	// Interactive keep STDIN open even if not attached
@@ -70,6 +83,12 @@ type ContainerCreateOptions struct {
 	Detach bool
 	// The key sequence for detaching a container.
 	DetachKeys string
+	// WaitHealthy blocks the detached `nerdctl run` until the HEALTHCHECK of the image passes
+	WaitHealthy bool
+	// WaitPort blocks the detached `nerdctl run` until the TCP port of the container accepts connections
+	WaitPort int
+	// WaitTimeout is the timeout of WaitHealthy and WaitPort
+	WaitTimeout time.Duration
 	// Attach STDIN, STDOUT, or STDERR
 	Attach []string
 	// Restart specifies the policy to apply when a container exits
@@ -80,10 +99,23 @@ type ContainerCreateOptions struct {
 	Pull string
 	// Pid namespace to use
 	Pid string
-	// StopSignal signal to stop a container, default is SIGTERM
+	// StopSignal signal to stop a container, default is STOPSIGNAL of the image, or SIGTERM
 	StopSignal string
-	// StopTimeout specifies the timeout (in seconds) to stop a container
+	// StopTimeout specifies the timeout (in seconds) to stop a container, default is 10.
+	// -1 waits indefinitely.
 	StopTimeout int
+	// OnStart specifies the host commands to run before the container starts
+	OnStart []string
+	// OnPostStart specifies the commands to run after the container starts.
+	// The commands with the "exec:" prefix run inside the container, the others on the host.
+	OnPostStart []string
+	// OnStop specifies the commands to run before the container is stopped, like OnPostStart
+	OnStop []string
+	// WatchConfig specifies the bind-mounted files and directories to watch, as paths in the container.
+	// The container is restarted, or WatchConfigSignal is sent to it, when they change.
+	WatchConfig []string
+	// WatchConfigSignal specifies the signal to send on the changes of WatchConfig, instead of restarting
+	WatchConfigSignal string
 	// #endregion
 
 	// #region for platform flags
@@ -116,10 +148,20 @@ type ContainerCreateOptions struct {
 	CPUSetCPUs string
 	// CPUSetMems specifies the memory nodes (MEMs) in which to allow execution (0-3, 0,1). Only effective on NUMA systems.
 	CPUSetMems string
+	// NumaNode specifies the NUMA nodes (0-1, 0,1) to derive CPUSetCPUs and CPUSetMems from the host topology
+	NumaNode string
 	// Limit CPU real-time period in microseconds
 	CPURealtimePeriod uint64
 	// Limit CPU real-time runtime in microseconds
 	CPURealtimeRuntime uint64
+	// CPUCount specifies the number of CPUs (Windows only)
+	CPUCount uint64
+	// CPUPercent specifies the usable percentage of the CPUs (Windows only)
+	CPUPercent uint64
+	// IOMaximumBandwidth specifies the maximum IO bandwidth of the system drive, e.g., "100M" (Windows only)
+	IOMaximumBandwidth string
+	// IOMaximumIOps specifies the maximum IOps of the system drive (Windows only)
+	IOMaximumIOps uint64
 	// Memory specifies the memory limit
 	Memory string
 	// MemoryReservationChanged specifies whether the memory soft limit has been changed
@@ -214,13 +256,22 @@ type ContainerCreateOptions struct {
 	Mount []string
 	// VolumesFrom specifies a list of specified containers to mount from
 	VolumesFrom []string
+	// Secret specifies a list of secrets created with `nerdctl secret create` to mount, e.g., "id=mysecret,target=/run/secrets/password"
+	Secret []string
 	// #endregion
 
 	// #region for rootfs flags
 	// ReadOnly mount the container's root filesystem as read only
 	ReadOnly bool
+	// ReadOnlyTmpfsSize is the size of the tmpfs mounted on /run and /tmp with ReadOnly, e.g., "64m".
+	// "0" disables the tmpfs mounts.
+	ReadOnlyTmpfsSize string
+	// Immutable implies ReadOnly, and forbids `nerdctl exec` and `nerdctl cp` into the container
+	Immutable bool
 	// Rootfs specifies the first argument is not an image but the rootfs to the exploded container. Corresponds to Podman CLI.
 	Rootfs bool
+	// StorageOpt specifies the storage driver options of the container, e.g., "size=10G"
+	StorageOpt []string
 	// #endregion
 
 	// #region for env flags
@@ -234,6 +285,12 @@ type ContainerCreateOptions struct {
 	Env []string
 	// EnvFile set environment variables from file
 	EnvFile []string
+	// EnvFileExpand expands ${VAR} references in the env files
+	EnvFileExpand bool
+	// EnvFileStrict rejects malformed lines in the env files
+	EnvFileStrict bool
+	// TZ sets the timezone of the container, e.g., "Asia/Tokyo", or "local" for the timezone of the host
+	TZ string
 	// #endregion
 
 	// #region for metadata flags
@@ -246,12 +303,18 @@ type ContainerCreateOptions struct {
 	Label []string
 	// LabelFile read in a line delimited file of labels
 	LabelFile []string
+	// DefaultLabels are the labels of the label_files and the [labels] table of nerdctl.toml, overridden by Label and LabelFile
+	DefaultLabels map[string]string
 	// Annotations set meta data on a container (passed through to the OCI runtime)
 	Annotations []string
+	// NetAccel accelerates the networking of the rootless container with bypass4netns
+	NetAccel bool
 	// CidFile write the container ID to the file
 	CidFile string
 	// PidFile specifies the file path to write the task's pid. The CLI syntax conforms to Podman convention.
 	PidFile string
+	// ConfigOut is the file to write the flags, the image, and the command to, to be replayed with `nerdctl create --from-config`
+	ConfigOut string
 	// #endregion
 
 	// #region for logging flags
@@ -281,6 +344,8 @@ type ContainerCreateOptions struct {
 	// #region for ipfs flags
 	// IPFSAddress specifies the multiaddr of IPFS API (default uses $IPFS_PATH env variable if defined or local directory ~/.ipfs)
 	IPFSAddress string
+	// IPFSGateway is the HTTP gateway of IPFS (e.g., "https://ipfs.io") used when the IPFS API is not available
+	IPFSGateway string
 	// #endregion
 
 	// ImagePullOpt specifies image pull options which holds the ImageVerifyOptions for verifying the image.
@@ -302,6 +367,8 @@ type ContainerStopOptions struct {
 
 	// Signal to send to the container, before sending SIGKILL
 	Signal string
+	// Parallel is the maximum number of containers processed concurrently
+	Parallel int
 }
 
 // ContainerRestartOptions specifies options for `nerdctl (container) restart`.
@@ -326,6 +393,10 @@ type ContainerPruneOptions struct {
 	Stdout io.Writer
 	// GOptions is the global options
 	GOptions GlobalCommandOptions
+	// Filters restricts the containers to prune (e.g. "until=24h", "label=foo=bar")
+	Filters []string
+	// Parallel is the maximum number of containers processed concurrently
+	Parallel int
 }
 
 // ContainerUnpauseOptions specifies options for `nerdctl (container) unpause`.
@@ -340,6 +411,8 @@ type ContainerRemoveOptions struct {
 	Force bool
 	// Volumes removes anonymous volumes associated with the container
 	Volumes bool
+	// Parallel is the maximum number of containers processed concurrently
+	Parallel int
 }
 
 // ContainerRenameOptions specifies options for `nerdctl (container) rename`.
@@ -349,6 +422,75 @@ type ContainerRenameOptions struct {
 	GOptions GlobalCommandOptions
 }
 
+// ContainerAutoUpdateOptions specifies options for `nerdctl container auto-update`.
+type ContainerAutoUpdateOptions struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// NerdctlCmd is the path of the nerdctl binary, for re-creating the containers
+	NerdctlCmd string
+	// NerdctlArgs is the global flags passed to NerdctlCmd
+	NerdctlArgs []string
+	// DryRun only reports the containers to update
+	DryRun bool
+	// Rollback restores the previous container when the new one does not stay up during HealthWindow
+	Rollback bool
+	// HealthWindow is the time the new container must keep running to be considered healthy
+	HealthWindow time.Duration
+	// Format the output using the given Go template, e.g, '{{json .}}'
+	Format string
+}
+
+// ContainerRestartPolicyListOptions specifies options for `nerdctl container restart-policy ls`.
+type ContainerRestartPolicyListOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Filters matches containers based on given conditions, as `nerdctl ps --filter`
+	Filters []string
+	// Format the output using the given Go template (e.g., '{{json .}}')
+	Format string
+}
+
+// ContainerRestartPolicySetOptions specifies options for `nerdctl container restart-policy set`.
+type ContainerRestartPolicySetOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Policy is the restart policy to be set (e.g., "unless-stopped", "on-failure:3")
+	Policy string
+	// Filters matches containers based on given conditions, as `nerdctl ps --filter`
+	Filters []string
+}
+
+// ContainerAdoptOptions specifies options for `nerdctl container adopt`.
+type ContainerAdoptOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Name is the name to assign to the container, suggested from the image when empty
+	Name string
+	// LogDriver is the logging driver to use on the next `nerdctl start`, no logs are collected when empty
+	LogDriver string
+	// LogOpt is a list of log driver specific options
+	LogOpt []string
+}
+
+// ContainerPublishOptions specifies options for `nerdctl container publish`.
+type ContainerPublishOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+}
+
+// ContainerUnpublishOptions specifies options for `nerdctl container unpublish`.
+type ContainerUnpublishOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+}
+
 // ContainerTopOptions specifies options for `nerdctl top`.
 type ContainerTopOptions struct {
 	Stdout io.Writer
@@ -373,6 +515,19 @@ type ContainerInspectOptions struct {
 }
 
 // ContainerCommitOptions specifies options for `nerdctl (container) commit`.
+// ContainerExportOptions specifies options for `nerdctl (container) export`.
+type ContainerExportOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Live exports a running container from a point-in-time snapshot, pausing the container only while the snapshot is taken
+	Live bool
+	// Compression is the compression of the archive: "none", "gzip", or "zstd"
+	Compression string
+	// Paths are the paths in the container to export, instead of the whole filesystem
+	Paths []string
+}
+
 type ContainerCommitOptions struct {
 	Stdout io.Writer
 	// GOptions is the global options
@@ -381,10 +536,13 @@ type ContainerCommitOptions struct {
 	Author string
 	// Commit message
 	Message string
-	// Apply Dockerfile instruction to the created image (supported directives: [CMD, ENTRYPOINT])
+	// Apply Dockerfile instruction to the created image (supported directives: [CMD, ENTRYPOINT, ENV, EXPOSE, LABEL, STOPSIGNAL, USER, VOLUME, WORKDIR])
 	Change []string
 	// Pause container during commit
 	Pause bool
+	// LeaseDuration is the expiration of the lease holding the intermediate content and snapshots.
+	// The lease is renewed during the commit, so this is the time until the garbage of an aborted commit is removed.
+	LeaseDuration time.Duration
 }
 
 // ContainerDiffOptions specifies options for `nerdctl (container) diff`.
@@ -392,6 +550,8 @@ type ContainerDiffOptions struct {
 	Stdout io.Writer
 	// GOptions is the global options
 	GOptions GlobalCommandOptions
+	// Format the output using the given Go template (e.g., '{{json .}}')
+	Format string
 }
 
 // ContainerLogsOptions specifies options for `nerdctl (container) logs`.
@@ -432,10 +592,43 @@ type ContainerAttachOptions struct {
 	GOptions GlobalCommandOptions
 	// DetachKeys is the key sequences to detach from the container.
 	DetachKeys string
+	// SigProxy proxies the received signals to the process, unless it has a TTY.
+	SigProxy bool
+}
+
+// ContainerDebugOptions specifies options for `nerdctl (container) debug`.
+type ContainerDebugOptions struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Image is the image of the debug container
+	Image string
+	// Name is the name of the debug container, generated when empty
+	Name string
+	// Allocate a pseudo-TTY
+	TTY bool
+	// Keep STDIN open even if not attached
+	Interactive bool
+	// Privileged gives extended privileges to the debug container
+	Privileged bool
+	// Pull is the pull policy of Image: "always", "missing", or "never"
+	Pull string
+	// TargetMount is the path where the root filesystem of the target container is mounted, or empty for not mounting it
+	TargetMount string
+	// NerdctlCmd is the path of the nerdctl binary, for running the debug container
+	NerdctlCmd string
+	// NerdctlArgs is the global flags passed to NerdctlCmd
+	NerdctlArgs []string
 }
 
 // ContainerExecOptions specifies options for `nerdctl (container) exec`
 type ContainerExecOptions struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
 	GOptions GlobalCommandOptions
 	// Allocate a pseudo-TTY
 	TTY bool
@@ -449,10 +642,16 @@ type ContainerExecOptions struct {
 	Env []string
 	// Set environment variables from file
 	EnvFile []string
+	// Expand ${VAR} references in the env files
+	EnvFileExpand bool
+	// Reject malformed lines in the env files
+	EnvFileStrict bool
 	// Give extended privileges to the command
 	Privileged bool
 	// Username or UID (format: <name|uid>[:<group|gid>])
 	User string
+	// SigProxy proxies the received signals to the process, unless it has a TTY.
+	SigProxy bool
 }
 
 // ContainerListOptions specifies options for `nerdctl (container) list`.
@@ -470,21 +669,35 @@ type ContainerListOptions struct {
 	Size bool
 	// Filters matches containers based on given conditions.
 	Filters []string
+	// Sync queries containerd for the command and the status of every container, instead of reading
+	// them from the lifecycle state index.
+	Sync bool
 }
 
 // ContainerCpOptions specifies options for `nerdctl (container) cp`
 type ContainerCpOptions struct {
+	// Stdin is read as a tar archive when SrcPath is "-".
+	Stdin io.Reader
+	// Stdout receives a tar archive when DestPath is "-".
+	Stdout io.Writer
 	// GOptions is the global options.
 	GOptions GlobalCommandOptions
-	// ContainerReq is name, short ID, or long ID of container to copy to/from.
-	ContainerReq   string
-	Container2Host bool
+	// SrcContainerReq is name, short ID, or long ID of container to copy from.
+	// Empty when copying from the local filesystem.
+	SrcContainerReq string
+	// DestContainerReq is name, short ID, or long ID of container to copy to.
+	// Empty when copying to the local filesystem.
+	DestContainerReq string
 	// Destination path to copy file to.
 	DestPath string
 	// Source path to copy file from.
 	SrcPath string
 	// Follow symbolic links in SRC_PATH
 	FollowSymLink bool
+	// Archive preserves the UID/GID of the source files, including when copying to the local filesystem.
+	Archive bool
+	// Chown sets the ownership of the copied files to UID[:GID]
+	Chown string
 }
 
 // ContainerStatsOptions specifies options for `nerdctl stats`.
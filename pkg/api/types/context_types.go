@@ -0,0 +1,58 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package types
+
+import "io"
+
+// ContextCreateOptions specifies options for `nerdctl context create`.
+type ContextCreateOptions struct {
+	Stdout io.Writer
+	// Description of the context
+	Description string
+	// Containerd is the containerd endpoint of the context, e.g., "address=/run/containerd/containerd.sock,namespace=default,snapshotter=overlayfs"
+	Containerd string
+	// SSH is the destination of the remote host, e.g., "ssh://user@host:22"
+	SSH string
+	// Sudo executes the commands with sudo
+	Sudo bool
+}
+
+// ContextUseOptions specifies options for `nerdctl context use`.
+type ContextUseOptions struct {
+	Stdout io.Writer
+}
+
+// ContextListOptions specifies options for `nerdctl context ls`.
+type ContextListOptions struct {
+	Stdout io.Writer
+	// Only display context names
+	Quiet bool
+	// Format the output using the given Go template (e.g., '{{json .}}', 'table', 'wide')
+	Format string
+}
+
+// ContextInspectOptions specifies options for `nerdctl context inspect`.
+type ContextInspectOptions struct {
+	Stdout io.Writer
+	// Format the output using the given Go template, e.g, '{{json .}}'
+	Format string
+}
+
+// ContextRemoveOptions specifies options for `nerdctl context rm`.
+type ContextRemoveOptions struct {
+	Stdout io.Writer
+}
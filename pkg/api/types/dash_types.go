@@ -0,0 +1,40 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package types
+
+import (
+	"os"
+	"time"
+)
+
+// DashOptions specifies options for `nerdctl dash`.
+type DashOptions struct {
+	// Stdin is the terminal to read the keys from
+	Stdin *os.File
+	// Stdout is the terminal to draw on
+	Stdout *os.File
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Interval is the interval of refreshing the panes
+	Interval time.Duration
+	// Shell is the command executed in the containers by the exec key
+	Shell string
+	// NerdctlCmd is the path of the nerdctl binary, for running exec processes
+	NerdctlCmd string
+	// NerdctlArgs is the global flags passed to NerdctlCmd
+	NerdctlArgs []string
+}
@@ -19,3 +19,9 @@ package types
 import "github.com/containerd/nerdctl/v2/pkg/config"
 
 type GlobalCommandOptions config.Config
+
+// IsInsecureRegistry returns whether verifying HTTPS certs is skipped, and falling back to plain HTTP is allowed,
+// for the registry host (e.g., "registry.example.com:5000").
+func (o GlobalCommandOptions) IsInsecureRegistry(host string) bool {
+	return o.InsecureRegistry || config.MatchRegistry(o.InsecureRegistries, host)
+}
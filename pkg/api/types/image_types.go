@@ -18,6 +18,7 @@ package types
 
 import (
 	"io"
+	"time"
 
 	"github.com/opencontainers/image-spec/specs-go/v1"
 )
@@ -113,6 +114,10 @@ type ImageConvertOptions struct {
 	NydusPrefetchPatterns string
 	// NydusCompressor nydus blob compression algorithm, possible values: `none`, `lz4_block`, `zstd`, default is `lz4_block`
 	NydusCompressor string
+	// NydusPrefetchRecordIn read 'ctr-remote optimize --record-out=<FILE>' record file for generating the prefetch table (EXPERIMENTAL)
+	NydusPrefetchRecordIn string
+	// NydusChunkDict the local nydus image used as a chunk dictionary for deduplicating the chunks across images
+	NydusChunkDict string
 	// #endregion
 
 	// #region overlaybd flags
@@ -201,12 +206,20 @@ type ImagePullOptions struct {
 	Unpack *bool
 	// Content for specific platforms. Empty if `--all-platforms` is true
 	OCISpecPlatform []v1.Platform
+	// RequirePlatform treats the local image as missing when its config does not match the single OCISpecPlatform,
+	// so that it is pulled again, instead of failing at runtime with "exec format error".
+	// Set when the platform is explicitly requested, e.g., `nerdctl run --platform`.
+	RequirePlatform bool
 	// Pull mode
 	Mode string
+	// MaxWait is the maximum duration to wait for the pull to complete, no limit when zero
+	MaxWait time.Duration
 	// Suppress verbose output
 	Quiet bool
 	// multiaddr of IPFS API (default uses $IPFS_PATH env variable if defined or local directory ~/.ipfs)
 	IPFSAddress string
+	// IPFSGateway is the HTTP gateway of IPFS (e.g., "https://ipfs.io") used when the IPFS API is not available
+	IPFSGateway string
 	// Flags to pass into remote snapshotters
 	RFlags RemoteSnapshotterFlags
 }
@@ -243,6 +256,8 @@ type ImagePruneOptions struct {
 	Filters []string
 	// Force will not prompt for confirmation.
 	Force bool
+	// Parallel is the maximum number of images removed concurrently.
+	Parallel int
 }
 
 // ImageSaveOptions specifies options for `nerdctl (image) save`.
@@ -266,6 +281,37 @@ type ImageSignOptions struct {
 	NotationKeyName string
 }
 
+// ImageSignCommandOptions specifies options for `nerdctl image sign`.
+type ImageSignCommandOptions struct {
+	Stdout   io.Writer
+	GOptions GlobalCommandOptions
+	// SignOptions specifies the provider and the key. A cosign signature is made in keyless mode when CosignKey is empty.
+	SignOptions ImageSignOptions
+}
+
+// ImagePromoteOptions specifies options for `nerdctl image promote`.
+type ImagePromoteOptions struct {
+	Stdout   io.Writer
+	Stderr   io.Writer
+	GOptions GlobalCommandOptions
+	// VerifyOptions specifies the signature gate. The source image must be signed when the provider is not "none".
+	VerifyOptions ImageVerifyOptions
+	// RequireSBOM requires an SBOM attestation attached to the source image
+	RequireSBOM bool
+	// Scanner is the vulnerability scanner (trivy|grype) to run against the source image
+	Scanner string
+	// ScanReport is the path of a JSON vulnerability report of the source image, created by trivy or grype
+	ScanReport string
+	// SeverityThreshold is the lowest severity (low|medium|high|critical) of the vulnerabilities that block the promotion
+	SeverityThreshold string
+	// CopySignatures copies the cosign signatures of the source image to the target repository
+	CopySignatures bool
+	// DryRun only checks the policy gates, without copying the image
+	DryRun bool
+	// Suppress verbose output
+	Quiet bool
+}
+
 // ImageVerifyOptions contains options for verifying an image. It contains options from
 // all providers. The `provider` field determines which provider is used.
 type ImageVerifyOptions struct {
@@ -291,6 +337,90 @@ type SociOptions struct {
 	MinLayerSize int64
 }
 
+// ImageSociCreateOptions specifies options for `nerdctl image soci create`.
+type ImageSociCreateOptions struct {
+	GOptions    GlobalCommandOptions
+	SociOptions SociOptions
+	// Platforms create the SOCI index for specific platforms
+	Platforms []string
+	// AllPlatforms create the SOCI index for all platforms
+	AllPlatforms bool
+	// Push pushes the SOCI index to the registry of the image after creating it
+	Push bool
+}
+
+// ImageEditOptions specifies options for `nerdctl image edit`.
+type ImageEditOptions struct {
+	Stdout   io.Writer
+	GOptions GlobalCommandOptions
+
+	// Change is the list of the Dockerfile instructions applied to the image config (e.g., "LABEL foo=bar")
+	Change []string
+	// Label is the list of the labels to be set (KEY=VALUE)
+	Label []string
+	// Env is the list of the environment variables to be set (KEY=VALUE)
+	Env []string
+	// Entrypoint overrides the entrypoint, in the JSON array form or the shell form
+	Entrypoint string
+	// Cmd overrides the command, in the JSON array form or the shell form
+	Cmd string
+	// User overrides the user
+	User string
+	// Workdir overrides the working directory
+	Workdir string
+	// Expose is the list of the ports to be exposed (e.g., "80", "53/udp", "8000-8010")
+	Expose []string
+
+	// Platforms edit the config for specific platforms
+	Platforms []string
+	// AllPlatforms edit the config for all platforms
+	AllPlatforms bool
+}
+
+// ImageRebaseOptions specifies options for `nerdctl image rebase`.
+type ImageRebaseOptions struct {
+	Stdout   io.Writer
+	GOptions GlobalCommandOptions
+
+	// Platform is the platform of the images to rebase. Defaults to the host platform.
+	Platform string
+}
+
+// ImageOutdatedOptions specifies options for `nerdctl image outdated`.
+type ImageOutdatedOptions struct {
+	Stdout   io.Writer
+	Stderr   io.Writer
+	GOptions GlobalCommandOptions
+
+	// Policy is "digest" to only check whether the tags were updated in the registry,
+	// or "patch", "minor", "major" to also look for newer semver tags.
+	Policy string
+	// Format the output using the given Go template, e.g, '{{json .}}'
+	Format string
+	// Update pulls the updated tags, and the newer tags found with Policy
+	Update bool
+}
+
+// ImageRecordAccessOptions specifies options for `nerdctl image record-access`.
+type ImageRecordAccessOptions struct {
+	Stdout   io.Writer
+	Stderr   io.Writer
+	GOptions GlobalCommandOptions
+
+	// RecordOut is the file to write the record of the accessed files to
+	RecordOut string
+	// Convert is the name of the eStargz image to convert the image to, prioritizing the accessed files
+	Convert string
+	// EstargzCompressionLevel eStargz compression level of the converted image
+	EstargzCompressionLevel int
+	// EstargzChunkSize eStargz chunk size of the converted image
+	EstargzChunkSize int
+	// Period is the time to run the container for, before killing it
+	Period time.Duration
+	// WaitLine kills the container when a line of its stdout contains this string
+	WaitLine string
+}
+
 // ImageSquashOptions specifies options for `nerdctl image squash`.
 type ImageSquashOptions struct {
 	// GOptions is the global options
@@ -307,4 +437,11 @@ type ImageSquashOptions struct {
 
 	// SquashLayerLastN is the number of layers to squash
 	SquashLayerLastN int
+	// Label is the list of the labels to be set on the squashed image config (KEY=VALUE)
+	Label []string
+	// NoUnpack skips unpacking the squashed image into the snapshotter, e.g., for images only to be pushed
+	NoUnpack bool
+	// LeaseDuration is the expiration of the lease holding the intermediate content and snapshots.
+	// The lease is renewed during the squash, so this is the time until the garbage of an aborted squash is removed.
+	LeaseDuration time.Duration
 }
@@ -17,6 +17,7 @@
 package types
 
 import (
+	"io"
 	"time"
 )
 
@@ -31,3 +32,33 @@ type IPFSRegistryServeOptions struct {
 	// ReadTimeout timeout duration of a read request to IPFS. Zero means no timeout.
 	ReadTimeout time.Duration
 }
+
+// IPFSImageExportOptions specifies options for `nerdctl ipfs image export`.
+type IPFSImageExportOptions struct {
+	Stdout io.Writer
+	// IPFSAddress multiaddr of IPFS API (default is pulled from $IPFS_PATH/api file. If $IPFS_PATH env var is not present, it defaults to ~/.ipfs)
+	IPFSAddress string
+	// CAR is the path of the CAR file to write, or "-" for Stdout
+	CAR string
+}
+
+// IPFSImageImportOptions specifies options for `nerdctl ipfs image import`.
+type IPFSImageImportOptions struct {
+	Stdout io.Writer
+	Stdin  io.Reader
+	// IPFSAddress multiaddr of IPFS API (default is pulled from $IPFS_PATH/api file. If $IPFS_PATH env var is not present, it defaults to ~/.ipfs)
+	IPFSAddress string
+	// CAR is the path of the CAR file to read, or "-" for Stdin
+	CAR string
+	// Pin pins the imported image
+	Pin bool
+}
+
+// IPFSImagePinOptions specifies options for `nerdctl ipfs image pin` and `nerdctl ipfs image unpin`.
+type IPFSImagePinOptions struct {
+	Stdout io.Writer
+	// IPFSAddress multiaddr of IPFS API (default is pulled from $IPFS_PATH/api file. If $IPFS_PATH env var is not present, it defaults to ~/.ipfs)
+	IPFSAddress string
+	// Quiet only prints the root CID
+	Quiet bool
+}
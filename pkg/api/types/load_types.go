@@ -31,4 +31,6 @@ type ImageLoadOptions struct {
 	AllPlatforms bool
 	// Quiet suppresses the load output.
 	Quiet bool
+	// VerifyBundle is the path of the policy file used for verifying the archive before importing it.
+	VerifyBundle string
 }
@@ -30,4 +30,8 @@ type LoginCommandOptions struct {
 	//
 	// If it's empty, the user will be prompted to provide it.
 	Password string
+	// IdentityToken is an OAuth2 refresh token, used instead of Username and Password.
+	//
+	// It's stored as the "identitytoken" of the auth config.
+	IdentityToken string
 }
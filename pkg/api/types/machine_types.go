@@ -0,0 +1,66 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package types
+
+import "io"
+
+// MachineCreateOptions specifies options for `nerdctl machine create`.
+type MachineCreateOptions struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// CPUs is the number of CPUs. Zero means the default of Lima.
+	CPUs int
+	// Memory is the memory size in GiB. Zero means the default of Lima.
+	Memory float64
+	// Disk is the disk size in GiB. Zero means the default of Lima.
+	Disk float64
+	// VMType is the type of the VM, e.g., "qemu", "vz", "wsl2". Empty means the default of Lima.
+	VMType string
+	// Use sets the context of the machine as the current context
+	Use bool
+}
+
+// MachineStartOptions specifies options for `nerdctl machine start`.
+type MachineStartOptions struct {
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// MachineStopOptions specifies options for `nerdctl machine stop`.
+type MachineStopOptions struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// Force stops the machine forcibly
+	Force bool
+}
+
+// MachineRemoveOptions specifies options for `nerdctl machine rm`.
+type MachineRemoveOptions struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// Force removes the running machines too
+	Force bool
+}
+
+// MachineListOptions specifies options for `nerdctl machine ls`.
+type MachineListOptions struct {
+	Stdout io.Writer
+	// Only display machine names
+	Quiet bool
+	// Format the output using the given Go template (e.g., '{{json .}}', 'table', 'wide')
+	Format string
+}
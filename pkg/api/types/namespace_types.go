@@ -23,6 +23,17 @@ type NamespaceCreateOptions struct {
 	GOptions GlobalCommandOptions
 	// Labels are the namespace labels
 	Labels []string
+	// CPUs is the default and the maximum number of CPUs of the containers (0: unchanged)
+	CPUs float64
+	// CPUQuota is the default and the maximum CPU CFS quota of the containers, per the period of 100ms
+	// (-1: unchanged, 0: unlimited)
+	CPUQuota int64
+	// Memory is the default and the maximum memory limit of the containers ("": unchanged, "0": unlimited)
+	Memory string
+	// PidsLimit is the default and the maximum pids limit of the containers (-1: unchanged, 0: unlimited)
+	PidsLimit int64
+	// DefaultLabels are the default labels of the containers. An empty value removes the default label.
+	DefaultLabels []string
 }
 
 // NamespaceUpdateOptions specifies options for `nerdctl namespace update`.
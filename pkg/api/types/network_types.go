@@ -35,6 +35,8 @@ type NetworkCreateOptions struct {
 	IPRange     string
 	Labels      []string
 	IPv6        bool
+	// IngressPolicy is "accept" (default) or "deny" for the connections to the published ports
+	IngressPolicy string
 }
 
 // NetworkInspectOptions specifies options for `nerdctl network inspect`.
@@ -70,6 +72,8 @@ type NetworkPruneOptions struct {
 	GOptions GlobalCommandOptions
 	// Network drivers to keep while pruning
 	NetworkDriversToKeep []string
+	// Filters restricts the networks to prune (e.g. "until=24h", "label=foo=bar")
+	Filters []string
 }
 
 // NetworkRemoveOptions specifies options for `nerdctl network rm`.
@@ -0,0 +1,27 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package types
+
+// P2PServeOptions specifies options for `nerdctl p2p serve`.
+type P2PServeOptions struct {
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Listen is the address to listen, e.g., ":5055"
+	Listen string
+	// MDNS advertises the server on the LAN with mDNS
+	MDNS bool
+}
@@ -0,0 +1,50 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package types
+
+import "io"
+
+// SecretCreateOptions specifies options for `nerdctl secret create`.
+type SecretCreateOptions struct {
+	Stdout   io.Writer
+	Stdin    io.Reader
+	GOptions GlobalCommandOptions
+	// Labels are the labels of the secret
+	Labels []string
+	// File is the file to read the secret from, "-" for stdin
+	File string
+	// EncryptCommand encrypts the secret, read from stdin, to stdout. Empty means no encryption.
+	EncryptCommand []string
+	// DecryptCommand decrypts the secret encrypted by EncryptCommand, read from stdin, to stdout
+	DecryptCommand []string
+}
+
+// SecretListOptions specifies options for `nerdctl secret ls`.
+type SecretListOptions struct {
+	Stdout   io.Writer
+	GOptions GlobalCommandOptions
+	// Only display secret names
+	Quiet bool
+	// Format the output using the given go template
+	Format string
+}
+
+// SecretRemoveOptions specifies options for `nerdctl secret rm`.
+type SecretRemoveOptions struct {
+	Stdout   io.Writer
+	GOptions GlobalCommandOptions
+}
@@ -16,7 +16,10 @@
 
 package types
 
-import "io"
+import (
+	"io"
+	"time"
+)
 
 // SystemInfoOptions specifies options for `nerdctl (system) info`.
 type SystemInfoOptions struct {
@@ -51,8 +54,156 @@ type SystemPruneOptions struct {
 	All bool
 	// Volumes decide whether prune volumes or not
 	Volumes bool
+	// Containers, Networks, Images, and BuildCache select the objects to prune, in addition to Volumes.
+	// When none of them is set, the containers, the networks, the images, and the build cache are pruned.
+	Containers bool
+	Networks   bool
+	// Images is "dangling" or "all" to prune the dangling images or all the unused images, or empty
+	Images     string
+	BuildCache bool
+	// Filters restricts the objects to prune (e.g. "until=24h", "label=foo=bar")
+	Filters []string
 	// BuildKitHost the address of BuildKit host
 	BuildKitHost string
 	// NetworkDriversToKeep the network drivers which need to keep
 	NetworkDriversToKeep []string
+	// Parallel is the maximum number of containers or images removed concurrently
+	Parallel int
+}
+
+// SystemGCOptions specifies options for `nerdctl system gc`.
+type SystemGCOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// MaxContentSize is the size budget of the content store in bytes. Zero means no budget.
+	MaxContentSize int64
+	// MaxImageAge is the age after which unused images are removed. Zero means no age limit.
+	MaxImageAge time.Duration
+	// Keep is the list of image reference patterns that are never removed
+	Keep []string
+	// DryRun only prints the images that would be removed
+	DryRun bool
+}
+
+// SystemWatchdogOptions specifies options for `nerdctl system watchdog`.
+type SystemWatchdogOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Root is the directory on the monitored filesystem, usually the root directory of containerd
+	Root string
+	// Threshold is the filesystem usage in percent, above which images are pruned and containers are evicted
+	Threshold float64
+	// Target is the filesystem usage in percent that the pruning and the eviction aim at
+	Target float64
+	// Interval is the interval of monitoring the filesystem usage
+	Interval time.Duration
+	// PriorityLabel is the container label that specifies the eviction priority
+	PriorityLabel string
+	// StopTimeout is the timeout for stopping a running container before evicting it
+	StopTimeout time.Duration
+	// Keep is the list of image reference patterns that are never removed
+	Keep []string
+	// Once checks the filesystem usage only once, instead of monitoring it
+	Once bool
+}
+
+// SystemDiskUsageOptions specifies options for `nerdctl system df`.
+type SystemDiskUsageOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Format the output using the given Go template, e.g, '{{json .}}'
+	Format string
+	// Verbose shows the disk usage of each container and volume
+	Verbose bool
+}
+
+// SystemCheckPortsOptions specifies options for `nerdctl system check-ports`.
+type SystemCheckPortsOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Format the output using the given Go template, e.g, '{{json .}}'
+	Format string
+	// All shows the rules of the running containers too, not only the stale ones
+	All bool
+}
+
+// SystemBypass4netnsdOptions specifies options for `nerdctl system bypass4netnsd (start|stop|status)`.
+type SystemBypass4netnsdOptions struct {
+	Stdout io.Writer
+	// Format the output using the given Go template, e.g, '{{json .}}'
+	Format string
+}
+
+// SystemRootlessSetupOptions specifies options for `nerdctl system rootless setup`.
+type SystemRootlessSetupOptions struct {
+	Stdout io.Writer
+	// Net is the network driver of RootlessKit, "auto", "pasta", or "slirp4netns"
+	Net string
+	// CheckOnly only checks the prerequisites, without installing the systemd unit
+	CheckOnly bool
+	// Force overwrites the existing systemd unit
+	Force bool
+	// PortDriver is the port driver of RootlessKit, "builtin", "slirp4netns", "implicit", or empty for the default
+	PortDriver string
+}
+
+// SystemBenchSnapshotterOptions specifies options for `nerdctl system bench-snapshotter`.
+type SystemBenchSnapshotterOptions struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Snapshotters to benchmark. Defaults to the available ones of overlayfs, stargz, nydus, overlaybd, and soci.
+	Snapshotters []string
+	// Format the output using the given Go template, e.g, '{{json .}}'
+	Format string
+	// Timeout is the time to wait for the container to exit or to print WaitLine
+	Timeout time.Duration
+	// WaitLine regards the container as started when a line of its stdout contains this string
+	WaitLine string
+}
+
+// SystemDoctorOptions specifies options for `nerdctl system doctor`.
+type SystemDoctorOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Format the output using the given Go template, e.g, '{{json .}}'
+	Format string
+	// Fix applies the safe fixes for the failed checks
+	Fix bool
+}
+
+// SystemServeOptions specifies options for `nerdctl system serve`.
+type SystemServeOptions struct {
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Socket is the path of the unix socket to listen on
+	Socket string
+	// Group is the name or the ID of the group that may access the socket, in addition to the owner
+	Group string
+	// ReadOnly only allows the operations that do not modify anything
+	ReadOnly bool
+	// AllowUIDs only allows the clients running as the uids, when not empty
+	AllowUIDs []int
+	// AuthzCommand is the path of a command that authorizes every request, when not empty
+	AuthzCommand string
+}
+
+// SystemDockerAPIOptions specifies options for `nerdctl system docker-api`.
+type SystemDockerAPIOptions struct {
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Socket is the path of the unix socket to listen on
+	Socket string
+	// Group is the name or the ID of the group that may access the socket, in addition to the owner
+	Group string
+	// NerdctlCmd is the path of the nerdctl binary, for creating containers and running exec processes
+	NerdctlCmd string
+	// NerdctlArgs is the global flags passed to NerdctlCmd
+	NerdctlArgs []string
 }
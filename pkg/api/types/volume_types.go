@@ -24,6 +24,38 @@ type VolumeCreateOptions struct {
 	GOptions GlobalCommandOptions
 	// Labels are the volume labels
 	Labels []string
+	// Driver is the volume driver ("local", or the name of a volume plugin)
+	Driver string
+	// DriverOpts are the driver-specific options
+	DriverOpts map[string]string
+	// Size is the size limit of the volume (e.g., "10G"), the same as the "size" option of the local driver
+	Size string
+}
+
+// VolumeExportOptions specifies options for `nerdctl volume export`.
+type VolumeExportOptions struct {
+	// Stdout is the destination of the tar archive
+	Stdout   io.Writer
+	GOptions GlobalCommandOptions
+}
+
+// VolumeImportOptions specifies options for `nerdctl volume import`.
+type VolumeImportOptions struct {
+	Stdout   io.Writer
+	Stdin    io.Reader
+	GOptions GlobalCommandOptions
+	// Input is the tar archive file to read, instead of Stdin. The archive may be compressed with gzip or zstd.
+	Input string
+	// Labels are the labels of the volume, when it is created
+	Labels []string
+}
+
+// VolumeCloneOptions specifies options for `nerdctl volume clone`.
+type VolumeCloneOptions struct {
+	Stdout   io.Writer
+	GOptions GlobalCommandOptions
+	// Labels are the labels of the new volume, in addition to the labels of the source volume
+	Labels []string
 }
 
 // VolumeInspectOptions specifies options for `nerdctl volume inspect`.
@@ -58,6 +90,8 @@ type VolumePruneOptions struct {
 	All bool
 	// Do not prompt for confirmation
 	Force bool
+	// Filters restricts the volumes to prune (e.g. "anonymous=true", "label=foo=bar")
+	Filters []string
 }
 
 // VolumeRemoveOptions specifies options for `nerdctl volume rm`.
@@ -67,3 +101,33 @@ type VolumeRemoveOptions struct {
 	// Force the removal of one or more volumes
 	Force bool
 }
+
+// VolumeSnapshotCreateOptions specifies options for `nerdctl volume snapshot create`.
+type VolumeSnapshotCreateOptions struct {
+	Stdout   io.Writer
+	GOptions GlobalCommandOptions
+	// Keep is the number of the snapshots to keep, removing the oldest ones after creating the snapshot. 0 keeps all.
+	Keep int
+}
+
+// VolumeSnapshotListOptions specifies options for `nerdctl volume snapshot ls`.
+type VolumeSnapshotListOptions struct {
+	Stdout   io.Writer
+	GOptions GlobalCommandOptions
+	// Only display snapshot names
+	Quiet bool
+	// Format the output using the given go template
+	Format string
+}
+
+// VolumeSnapshotRestoreOptions specifies options for `nerdctl volume snapshot restore`.
+type VolumeSnapshotRestoreOptions struct {
+	Stdout   io.Writer
+	GOptions GlobalCommandOptions
+}
+
+// VolumeSnapshotRemoveOptions specifies options for `nerdctl volume snapshot rm`.
+type VolumeSnapshotRemoveOptions struct {
+	Stdout   io.Writer
+	GOptions GlobalCommandOptions
+}
@@ -0,0 +1,478 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package apiserver implements the API server of `nerdctl system serve`, which exposes a subset of the operations of
+// pkg/sdk as JSON over HTTP on a unix socket. The API is documented in docs/api.md.
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/sdk"
+	"github.com/containerd/nerdctl/v2/pkg/version"
+)
+
+// APIVersion is the prefix of the paths of the API.
+// Within an API version, fields and endpoints are only added.
+const APIVersion = "v1"
+
+// maxBodySize is the maximum size of a request body.
+const maxBodySize = 1 << 20
+
+// Backend is the subset of sdk.Client that the server exposes.
+type Backend interface {
+	Images(ctx context.Context) ([]sdk.Image, error)
+	Pull(ctx context.Context, ref string, opts ...sdk.PullOption) (*sdk.Image, error)
+	RemoveImage(ctx context.Context, req string, opts ...sdk.RemoveOption) error
+	Containers(ctx context.Context, opts ...sdk.ListOption) ([]sdk.Container, error)
+	StartContainer(ctx context.Context, req string) error
+	StopContainer(ctx context.Context, req string, opts ...sdk.StopOption) error
+	RemoveContainer(ctx context.Context, req string, opts ...sdk.RemoveOption) error
+	ComposeUp(ctx context.Context, opts ...sdk.ComposeOption) ([]sdk.ComposeContainer, error)
+	ComposeDown(ctx context.Context, opts ...sdk.ComposeOption) error
+	ComposeContainers(ctx context.Context, opts ...sdk.ComposeOption) ([]sdk.ComposeContainer, error)
+}
+
+// Version is the response of `GET /v1/version`.
+type Version struct {
+	Version    string
+	Revision   string
+	APIVersion string
+}
+
+// PullRequest is the request body of `POST /v1/images/pull`.
+type PullRequest struct {
+	Ref          string
+	Platforms    []string
+	AllPlatforms bool
+}
+
+// ComposeRequest is the request body of `POST /v1/compose/{up,down,ps}`.
+// The paths are resolved on the host of the server.
+type ComposeRequest struct {
+	Files            []string
+	ProjectDirectory string
+	ProjectName      string
+	Profiles         []string
+	EnvFile          string
+	Services         []string
+	RemoveOrphans    bool
+	// ForceRecreate and Scale are only used by up
+	ForceRecreate bool
+	Scale         map[string]int
+	// RemoveVolumes is only used by down
+	RemoveVolumes bool
+}
+
+// Error is the response body of the failed requests.
+type Error struct {
+	Message string
+}
+
+// Server serves the API.
+type Server struct {
+	backend    Backend
+	authorizer Authorizer
+	mux        *http.ServeMux
+}
+
+// route is an endpoint of the API.
+type route struct {
+	pattern   string
+	operation string
+	readOnly  bool
+	handler   func(w http.ResponseWriter, r *http.Request, body []byte) error
+}
+
+// New returns a Server. Every request is authorized by authorizer, unless it is nil.
+func New(backend Backend, authorizer Authorizer) *Server {
+	s := &Server{
+		backend:    backend,
+		authorizer: authorizer,
+		mux:        http.NewServeMux(),
+	}
+	for _, rt := range s.routes() {
+		s.mux.HandleFunc(rt.pattern, s.wrap(rt))
+	}
+	return s
+}
+
+func (s *Server) routes() []route {
+	p := "/" + APIVersion
+	return []route{
+		{"GET " + p + "/version", "version", true, s.version},
+		{"GET " + p + "/images", "image.list", true, s.imageList},
+		{"POST " + p + "/images/pull", "image.pull", false, s.imagePull},
+		{"DELETE " + p + "/images/{name...}", "image.remove", false, s.imageRemove},
+		{"GET " + p + "/containers", "container.list", true, s.containerList},
+		{"POST " + p + "/containers/{id}/start", "container.start", false, s.containerStart},
+		{"POST " + p + "/containers/{id}/stop", "container.stop", false, s.containerStop},
+		{"DELETE " + p + "/containers/{id}", "container.remove", false, s.containerRemove},
+		{"POST " + p + "/compose/up", "compose.up", false, s.composeUp},
+		{"POST " + p + "/compose/down", "compose.down", false, s.composeDown},
+		{"POST " + p + "/compose/ps", "compose.ps", true, s.composePs},
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Serve serves the API on l until ctx is done.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	srv := &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+		ConnContext: func(ctx context.Context, c net.Conn) context.Context {
+			return withPeer(ctx, peerCredentials(c))
+		},
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.G(ctx).WithError(err).Warn("failed to shut down the API server")
+		}
+	}()
+	if err := srv.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// ListenUnix listens on a unix socket at path, replacing a stale socket.
+// The socket is only accessible by the owner, and by the group gid unless gid is negative.
+func ListenUnix(path string, gid int) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o711); err != nil {
+		return nil, err
+	}
+	if c, err := net.Dial("unix", path); err == nil {
+		c.Close()
+		return nil, fmt.Errorf("%s is already in use: %w", path, errdefs.ErrAlreadyExists)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	mode := os.FileMode(0o600)
+	if gid >= 0 {
+		if err := os.Chown(path, -1, gid); err != nil {
+			l.Close()
+			return nil, err
+		}
+		mode = 0o660
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+func (s *Server) wrap(rt route) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+		if err != nil {
+			writeError(w, fmt.Errorf("failed to read the request body: %v: %w", err, errdefs.ErrInvalidArgument))
+			return
+		}
+		peer := peerFromContext(ctx)
+		if s.authorizer != nil {
+			req := &AuthzRequest{
+				Operation: rt.operation,
+				ReadOnly:  rt.readOnly,
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				Query:     r.URL.RawQuery,
+				UID:       peer.uid,
+				GID:       peer.gid,
+				PID:       peer.pid,
+			}
+			if len(body) > 0 && json.Valid(body) {
+				req.Body = body
+			}
+			if err := s.authorizer.Authorize(ctx, req); err != nil {
+				log.G(ctx).WithError(err).Warnf("denied %s (uid=%d, pid=%d)", rt.operation, peer.uid, peer.pid)
+				writeError(w, fmt.Errorf("%v: %w", err, errdefs.ErrPermissionDenied))
+				return
+			}
+		}
+		log.G(ctx).Debugf("%s (uid=%d, pid=%d)", rt.operation, peer.uid, peer.pid)
+		if err := rt.handler(w, r, body); err != nil {
+			log.G(ctx).WithError(err).Debugf("%s failed", rt.operation)
+			writeError(w, err)
+		}
+	}
+}
+
+func (s *Server) version(w http.ResponseWriter, _ *http.Request, _ []byte) error {
+	return writeJSON(w, http.StatusOK, Version{
+		Version:    version.GetVersion(),
+		Revision:   version.GetRevision(),
+		APIVersion: APIVersion,
+	})
+}
+
+func (s *Server) imageList(w http.ResponseWriter, r *http.Request, _ []byte) error {
+	images, err := s.backend.Images(r.Context())
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, images)
+}
+
+func (s *Server) imagePull(w http.ResponseWriter, r *http.Request, body []byte) error {
+	var req PullRequest
+	if err := decode(body, &req); err != nil {
+		return err
+	}
+	if req.Ref == "" {
+		return fmt.Errorf("Ref must be specified: %w", errdefs.ErrInvalidArgument)
+	}
+	var opts []sdk.PullOption
+	if len(req.Platforms) > 0 {
+		opts = append(opts, sdk.WithPlatforms(req.Platforms...))
+	}
+	if req.AllPlatforms {
+		opts = append(opts, sdk.WithAllPlatforms())
+	}
+	img, err := s.backend.Pull(r.Context(), req.Ref, opts...)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, img)
+}
+
+func (s *Server) imageRemove(w http.ResponseWriter, r *http.Request, _ []byte) error {
+	opts, err := removeOptions(r)
+	if err != nil {
+		return err
+	}
+	if err := s.backend.RemoveImage(r.Context(), r.PathValue("name"), opts...); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) containerList(w http.ResponseWriter, r *http.Request, _ []byte) error {
+	var opts []sdk.ListOption
+	all, err := queryBool(r, "all")
+	if err != nil {
+		return err
+	}
+	if all {
+		opts = append(opts, sdk.WithAll())
+	}
+	if filters := r.URL.Query()["filter"]; len(filters) > 0 {
+		opts = append(opts, sdk.WithFilters(filters...))
+	}
+	containers, err := s.backend.Containers(r.Context(), opts...)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, containers)
+}
+
+func (s *Server) containerStart(w http.ResponseWriter, r *http.Request, _ []byte) error {
+	if err := s.backend.StartContainer(r.Context(), r.PathValue("id")); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) containerStop(w http.ResponseWriter, r *http.Request, _ []byte) error {
+	var opts []sdk.StopOption
+	if v := r.URL.Query().Get("timeout"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid timeout %q: %w", v, errdefs.ErrInvalidArgument)
+		}
+		opts = append(opts, sdk.WithStopTimeout(timeout))
+	}
+	if v := r.URL.Query().Get("signal"); v != "" {
+		opts = append(opts, sdk.WithStopSignal(v))
+	}
+	if err := s.backend.StopContainer(r.Context(), r.PathValue("id"), opts...); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) containerRemove(w http.ResponseWriter, r *http.Request, _ []byte) error {
+	opts, err := removeOptions(r)
+	if err != nil {
+		return err
+	}
+	volumes, err := queryBool(r, "volumes")
+	if err != nil {
+		return err
+	}
+	if volumes {
+		opts = append(opts, sdk.WithAnonymousVolumes())
+	}
+	if err := s.backend.RemoveContainer(r.Context(), r.PathValue("id"), opts...); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) composeUp(w http.ResponseWriter, r *http.Request, body []byte) error {
+	opts, err := composeOptions(body)
+	if err != nil {
+		return err
+	}
+	containers, err := s.backend.ComposeUp(r.Context(), opts...)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, containers)
+}
+
+func (s *Server) composeDown(w http.ResponseWriter, r *http.Request, body []byte) error {
+	opts, err := composeOptions(body)
+	if err != nil {
+		return err
+	}
+	if err := s.backend.ComposeDown(r.Context(), opts...); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) composePs(w http.ResponseWriter, r *http.Request, body []byte) error {
+	opts, err := composeOptions(body)
+	if err != nil {
+		return err
+	}
+	containers, err := s.backend.ComposeContainers(r.Context(), opts...)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, containers)
+}
+
+func composeOptions(body []byte) ([]sdk.ComposeOption, error) {
+	var req ComposeRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	opts := []sdk.ComposeOption{
+		sdk.WithComposeFiles(req.Files...),
+		sdk.WithProjectDirectory(req.ProjectDirectory),
+		sdk.WithProjectName(req.ProjectName),
+		sdk.WithProfiles(req.Profiles...),
+		sdk.WithEnvFile(req.EnvFile),
+		sdk.WithServices(req.Services...),
+	}
+	if req.RemoveOrphans {
+		opts = append(opts, sdk.WithRemoveOrphans())
+	}
+	if req.ForceRecreate {
+		opts = append(opts, sdk.WithForceRecreate())
+	}
+	for service, replicas := range req.Scale {
+		opts = append(opts, sdk.WithScale(service, replicas))
+	}
+	if req.RemoveVolumes {
+		opts = append(opts, sdk.WithRemoveVolumes())
+	}
+	return opts, nil
+}
+
+func removeOptions(r *http.Request) ([]sdk.RemoveOption, error) {
+	force, err := queryBool(r, "force")
+	if err != nil {
+		return nil, err
+	}
+	if force {
+		return []sdk.RemoveOption{sdk.WithForce()}, nil
+	}
+	return nil, nil
+}
+
+func queryBool(r *http.Request, key string) (bool, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: %w", key, v, errdefs.ErrInvalidArgument)
+	}
+	return b, nil
+}
+
+// decode decodes a JSON request body. An empty body leaves v unchanged.
+func decode(body []byte, v any) error {
+	if len(body) == 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %v: %w", err, errdefs.ErrInvalidArgument)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errdefs.IsNotFound(err):
+		status = http.StatusNotFound
+	case errdefs.IsInvalidArgument(err):
+		status = http.StatusBadRequest
+	case errdefs.IsAlreadyExists(err), errdefs.IsConflict(err):
+		status = http.StatusConflict
+	case errdefs.IsPermissionDenied(err):
+		status = http.StatusForbidden
+	}
+	_ = writeJSON(w, status, Error{Message: err.Error()})
+}
@@ -0,0 +1,79 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestServeUnix(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "nerdctl.sock")
+	l, err := ListenUnix(socket, -1)
+	assert.NilError(t, err)
+	st, err := os.Stat(socket)
+	assert.NilError(t, err)
+	assert.Equal(t, st.Mode().Perm(), os.FileMode(0o600))
+
+	_, err = ListenUnix(socket, -1)
+	assert.ErrorContains(t, err, "already in use")
+
+	// the authorizer script allows the uid of the test, and denies pulling
+	script := filepath.Join(t.TempDir(), "authz.sh")
+	assert.NilError(t, os.WriteFile(script, []byte(`#!/bin/sh
+if grep -q '"Operation":"image.pull"'; then
+	echo "pulling is not allowed"
+	exit 1
+fi
+`), 0o755))
+	backend := &fakeBackend{}
+	s := New(backend, ChainAuthorizers(UIDAuthorizer(os.Getuid()), CommandAuthorizer(script)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Serve(ctx, l)
+	}()
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+
+	resp, err := client.Get("http://nerdctl/v1/images")
+	assert.NilError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+	resp, err = client.Post("http://nerdctl/v1/images/pull", "application/json", strings.NewReader(`{"Ref":"alpine"}`))
+	assert.NilError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusForbidden)
+	assert.DeepEqual(t, backend.calls, []string{"images"})
+
+	cancel()
+	assert.NilError(t, <-done)
+}
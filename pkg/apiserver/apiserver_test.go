@@ -0,0 +1,232 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/sdk"
+)
+
+// fakeBackend records the requests, and returns errNotFound for the containers and the images named "missing".
+type fakeBackend struct {
+	calls []string
+	pull  types.ImagePullOptions
+	stop  types.ContainerStopOptions
+}
+
+func (b *fakeBackend) Images(context.Context) ([]sdk.Image, error) {
+	b.calls = append(b.calls, "images")
+	return []sdk.Image{{Name: "docker.io/library/alpine:latest", Size: 42}}, nil
+}
+
+func (b *fakeBackend) Pull(_ context.Context, ref string, opts ...sdk.PullOption) (*sdk.Image, error) {
+	b.calls = append(b.calls, "pull "+ref)
+	for _, o := range opts {
+		if err := o(&b.pull); err != nil {
+			return nil, err
+		}
+	}
+	return &sdk.Image{Name: ref}, nil
+}
+
+func (b *fakeBackend) RemoveImage(_ context.Context, req string, opts ...sdk.RemoveOption) error {
+	b.calls = append(b.calls, fmt.Sprintf("rmi %s %d", req, len(opts)))
+	return notFound(req)
+}
+
+func (b *fakeBackend) Containers(_ context.Context, opts ...sdk.ListOption) ([]sdk.Container, error) {
+	var options types.ContainerListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	b.calls = append(b.calls, fmt.Sprintf("ps %v %v", options.All, options.Filters))
+	return []sdk.Container{{ID: "0123456789ab", Name: "foo"}}, nil
+}
+
+func (b *fakeBackend) StartContainer(_ context.Context, req string) error {
+	b.calls = append(b.calls, "start "+req)
+	return notFound(req)
+}
+
+func (b *fakeBackend) StopContainer(_ context.Context, req string, opts ...sdk.StopOption) error {
+	b.calls = append(b.calls, "stop "+req)
+	for _, o := range opts {
+		o(&b.stop)
+	}
+	return notFound(req)
+}
+
+func (b *fakeBackend) RemoveContainer(_ context.Context, req string, opts ...sdk.RemoveOption) error {
+	b.calls = append(b.calls, fmt.Sprintf("rm %s %d", req, len(opts)))
+	return notFound(req)
+}
+
+func (b *fakeBackend) ComposeUp(_ context.Context, opts ...sdk.ComposeOption) ([]sdk.ComposeContainer, error) {
+	b.calls = append(b.calls, fmt.Sprintf("compose up %d", len(opts)))
+	return []sdk.ComposeContainer{{ID: "0123456789ab", Name: "app-web-1", Service: "web"}}, nil
+}
+
+func (b *fakeBackend) ComposeDown(_ context.Context, opts ...sdk.ComposeOption) error {
+	b.calls = append(b.calls, fmt.Sprintf("compose down %d", len(opts)))
+	return nil
+}
+
+func (b *fakeBackend) ComposeContainers(_ context.Context, opts ...sdk.ComposeOption) ([]sdk.ComposeContainer, error) {
+	b.calls = append(b.calls, fmt.Sprintf("compose ps %d", len(opts)))
+	return nil, nil
+}
+
+func notFound(req string) error {
+	if req == "missing" {
+		return fmt.Errorf("no such object: %s: %w", req, errdefs.ErrNotFound)
+	}
+	return nil
+}
+
+func do(t *testing.T, handler http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer(t *testing.T) {
+	backend := &fakeBackend{}
+	s := New(backend, nil)
+
+	rec := do(t, s, "GET", "/v1/version", "")
+	assert.Equal(t, rec.Code, http.StatusOK)
+	var v Version
+	assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &v))
+	assert.Equal(t, v.APIVersion, APIVersion)
+
+	rec = do(t, s, "GET", "/v1/images", "")
+	assert.Equal(t, rec.Code, http.StatusOK)
+	var images []sdk.Image
+	assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &images))
+	assert.Equal(t, len(images), 1)
+	assert.Equal(t, images[0].Size, int64(42))
+
+	rec = do(t, s, "POST", "/v1/images/pull", `{"Ref":"alpine","Platforms":["linux/arm64"]}`)
+	assert.Equal(t, rec.Code, http.StatusOK, rec.Body.String())
+	assert.Equal(t, len(backend.pull.OCISpecPlatform), 1)
+	assert.Equal(t, backend.pull.OCISpecPlatform[0].Architecture, "arm64")
+
+	rec = do(t, s, "DELETE", "/v1/images/docker.io/library/alpine:latest?force=1", "")
+	assert.Equal(t, rec.Code, http.StatusNoContent)
+
+	rec = do(t, s, "GET", "/v1/containers?all=true&filter=label=foo&filter=status=exited", "")
+	assert.Equal(t, rec.Code, http.StatusOK)
+
+	rec = do(t, s, "POST", "/v1/containers/foo/stop?timeout=3s&signal=SIGINT", "")
+	assert.Equal(t, rec.Code, http.StatusNoContent)
+	assert.Equal(t, *backend.stop.Timeout, 3*time.Second)
+	assert.Equal(t, backend.stop.Signal, "SIGINT")
+
+	rec = do(t, s, "DELETE", "/v1/containers/foo?force=1&volumes=1", "")
+	assert.Equal(t, rec.Code, http.StatusNoContent)
+
+	rec = do(t, s, "POST", "/v1/compose/up", `{"Files":["compose.yaml"],"ProjectName":"app","Scale":{"web":2}}`)
+	assert.Equal(t, rec.Code, http.StatusOK, rec.Body.String())
+	var containers []sdk.ComposeContainer
+	assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &containers))
+	assert.Equal(t, containers[0].Service, "web")
+
+	rec = do(t, s, "POST", "/v1/compose/down", "")
+	assert.Equal(t, rec.Code, http.StatusNoContent)
+
+	assert.DeepEqual(t, backend.calls, []string{
+		"images",
+		"pull alpine",
+		"rmi docker.io/library/alpine:latest 1",
+		"ps true [label=foo status=exited]",
+		"stop foo",
+		"rm foo 2",
+		"compose up 7",
+		"compose down 6",
+	})
+}
+
+func TestServerErrors(t *testing.T) {
+	s := New(&fakeBackend{}, nil)
+	testCases := []struct {
+		method, target, body string
+		code                 int
+	}{
+		{"GET", "/v1/unknown", "", http.StatusNotFound},
+		{"PUT", "/v1/images", "", http.StatusMethodNotAllowed},
+		{"POST", "/v1/images/pull", `{"Ref":`, http.StatusBadRequest},
+		{"POST", "/v1/images/pull", `{"Reference":"alpine"}`, http.StatusBadRequest},
+		{"POST", "/v1/images/pull", `{}`, http.StatusBadRequest},
+		{"POST", "/v1/containers/missing/start", "", http.StatusNotFound},
+		{"POST", "/v1/containers/foo/stop?timeout=soon", "", http.StatusBadRequest},
+		{"DELETE", "/v1/containers/foo?force=maybe", "", http.StatusBadRequest},
+		{"DELETE", "/v1/images/missing", "", http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.method+" "+tc.target, func(t *testing.T) {
+			rec := do(t, s, tc.method, tc.target, tc.body)
+			assert.Equal(t, rec.Code, tc.code, rec.Body.String())
+		})
+	}
+
+	rec := do(t, s, "POST", "/v1/containers/missing/start", "")
+	var e Error
+	assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &e))
+	assert.ErrorContains(t, fmt.Errorf("%s", e.Message), "no such object: missing")
+}
+
+func TestAuthorizers(t *testing.T) {
+	backend := &fakeBackend{}
+	var got *AuthzRequest
+	record := AuthorizerFunc(func(_ context.Context, req *AuthzRequest) error {
+		got = req
+		return nil
+	})
+	s := New(backend, ChainAuthorizers(record, ReadOnlyAuthorizer()))
+
+	rec := do(t, s, "GET", "/v1/containers?all=1", "")
+	assert.Equal(t, rec.Code, http.StatusOK)
+	assert.Equal(t, got.Operation, "container.list")
+	assert.Equal(t, got.Query, "all=1")
+	assert.Equal(t, got.UID, -1)
+
+	rec = do(t, s, "POST", "/v1/images/pull", `{"Ref":"alpine"}`)
+	assert.Equal(t, rec.Code, http.StatusForbidden)
+	assert.Equal(t, got.Operation, "image.pull")
+	assert.Equal(t, string(got.Body), `{"Ref":"alpine"}`)
+	assert.DeepEqual(t, backend.calls, []string{"ps true []"})
+
+	s = New(backend, UIDAuthorizer(0))
+	rec = do(t, s, "GET", "/v1/images", "")
+	assert.Equal(t, rec.Code, http.StatusForbidden)
+	assert.Assert(t, strings.Contains(rec.Body.String(), "unknown uid"), rec.Body.String())
+}
@@ -0,0 +1,113 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// AuthzRequest is a request to authorize. It is also the JSON document written to the stdin of CommandAuthorizer.
+type AuthzRequest struct {
+	// Operation is the name of the operation, e.g., "image.pull".
+	Operation string
+	// ReadOnly is true for the operations that do not modify anything, e.g., "container.list".
+	ReadOnly bool
+	Method   string
+	Path     string
+	Query    string `json:",omitempty"`
+	// Body is the JSON request body, if any.
+	Body json.RawMessage `json:",omitempty"`
+	// UID, GID, and PID are the credentials of the peer process, or -1 when unknown (e.g., on non-Linux hosts).
+	UID int
+	GID int
+	PID int
+}
+
+// Authorizer authorizes the requests. Authorize returns an error for denying a request, with the reason.
+type Authorizer interface {
+	Authorize(ctx context.Context, req *AuthzRequest) error
+}
+
+// AuthorizerFunc is a function that implements Authorizer.
+type AuthorizerFunc func(ctx context.Context, req *AuthzRequest) error
+
+// Authorize implements Authorizer.
+func (f AuthorizerFunc) Authorize(ctx context.Context, req *AuthzRequest) error {
+	return f(ctx, req)
+}
+
+// ChainAuthorizers returns an Authorizer that allows a request only when all the authorizers allow it.
+func ChainAuthorizers(authorizers ...Authorizer) Authorizer {
+	return AuthorizerFunc(func(ctx context.Context, req *AuthzRequest) error {
+		for _, a := range authorizers {
+			if err := a.Authorize(ctx, req); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ReadOnlyAuthorizer denies the operations that modify images, containers, or compose projects.
+func ReadOnlyAuthorizer() Authorizer {
+	return AuthorizerFunc(func(_ context.Context, req *AuthzRequest) error {
+		if !req.ReadOnly {
+			return fmt.Errorf("%s is not allowed on a read-only server", req.Operation)
+		}
+		return nil
+	})
+}
+
+// UIDAuthorizer only allows the peer processes running as one of the uids.
+func UIDAuthorizer(uids ...int) Authorizer {
+	return AuthorizerFunc(func(_ context.Context, req *AuthzRequest) error {
+		if req.UID < 0 {
+			return fmt.Errorf("%s is not allowed for an unknown uid", req.Operation)
+		}
+		if !slices.Contains(uids, req.UID) {
+			return fmt.Errorf("%s is not allowed for uid %d", req.Operation, req.UID)
+		}
+		return nil
+	})
+}
+
+// CommandAuthorizer runs an external command for every request, with the AuthzRequest as JSON on its stdin.
+// The request is allowed when the command exits with 0. Otherwise, the output of the command is the reason.
+func CommandAuthorizer(name string, args ...string) Authorizer {
+	return AuthorizerFunc(func(ctx context.Context, req *AuthzRequest) error {
+		b, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Stdin = bytes.NewReader(b)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			if reason := strings.TrimSpace(string(out)); reason != "" {
+				return fmt.Errorf("%s is denied by %s: %s", req.Operation, name, reason)
+			}
+			return fmt.Errorf("%s is denied by %s: %w", req.Operation, name, err)
+		}
+		return nil
+	})
+}
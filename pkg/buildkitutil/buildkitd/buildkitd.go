@@ -0,0 +1,212 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package buildkitd manages buildkitd instances ("builders") launched by `nerdctl builder start`.
+//
+// The definition of each builder is stored in the data store, under builders/<namespace>/<name>,
+// along with the pid file of its supervisor process, the log of buildkitd, and the buildkitd root.
+package buildkitd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/identifiers"
+	"github.com/containerd/nerdctl/v2/pkg/store"
+)
+
+const (
+	// DefaultName is the name of the builder listening on the socket auto-detected by nerdctl.
+	DefaultName = "default"
+
+	// WorkerContainerd is the worker storing images and snapshots in containerd.
+	WorkerContainerd = "containerd"
+	// WorkerOCI is the worker storing images and snapshots in the buildkitd root.
+	WorkerOCI = "oci"
+
+	definitionFile = "builder.json"
+	pidFile        = "supervisor.pid"
+	logFile        = "buildkitd.log"
+	rootDir        = "root"
+)
+
+// Builder is the definition of a managed buildkitd instance.
+type Builder struct {
+	Name      string `json:"Name"`
+	Namespace string `json:"Namespace"`
+	// Worker is either WorkerContainerd or WorkerOCI.
+	Worker string `json:"Worker"`
+	// Address is the address buildkitd listens on, e.g., "unix:///run/buildkit-default/buildkitd.sock".
+	Address string `json:"Address"`
+	// Rootless is true when the builder runs in the namespaces of rootless containerd.
+	Rootless bool `json:"Rootless,omitempty"`
+	// ContainerdAddress and Snapshotter are used by the containerd worker.
+	ContainerdAddress string `json:"ContainerdAddress,omitempty"`
+	Snapshotter       string `json:"Snapshotter,omitempty"`
+	// Config is the path of buildkitd.toml, if any.
+	Config string `json:"Config,omitempty"`
+	// Flags are extra flags for buildkitd.
+	Flags []string `json:"Flags,omitempty"`
+}
+
+// Validate validates the definition of the builder.
+func (b *Builder) Validate() error {
+	if err := identifiers.ValidateDockerCompat(b.Name); err != nil {
+		return fmt.Errorf("invalid builder name: %w", err)
+	}
+	switch b.Worker {
+	case WorkerContainerd, WorkerOCI:
+	default:
+		return fmt.Errorf("invalid worker %q (supported values: %q, %q): %w", b.Worker, WorkerContainerd, WorkerOCI, errdefs.ErrInvalidArgument)
+	}
+	if b.Address == "" {
+		return fmt.Errorf("builder %q has no address: %w", b.Name, errdefs.ErrInvalidArgument)
+	}
+	return nil
+}
+
+// Args returns the arguments of buildkitd for the builder, using root as the state directory of buildkitd.
+func (b *Builder) Args(root string) []string {
+	args := []string{
+		"--addr=" + b.Address,
+		"--root=" + root,
+	}
+	switch b.Worker {
+	case WorkerContainerd:
+		args = append(args,
+			"--oci-worker=false",
+			"--containerd-worker=true",
+			"--containerd-worker-addr="+strings.TrimPrefix(b.ContainerdAddress, "unix://"),
+			"--containerd-worker-namespace="+b.Namespace,
+		)
+		if b.Snapshotter != "" {
+			args = append(args, "--containerd-worker-snapshotter="+b.Snapshotter)
+		}
+		if b.Rootless {
+			args = append(args, "--containerd-worker-rootless=true")
+		}
+	case WorkerOCI:
+		args = append(args,
+			"--oci-worker=true",
+			"--containerd-worker=false",
+		)
+		if b.Rootless {
+			args = append(args, "--rootless")
+		}
+	}
+	if b.Config != "" {
+		args = append(args, "--config="+b.Config)
+	}
+	return append(args, b.Flags...)
+}
+
+// Store stores the builders of a namespace.
+type Store struct {
+	st store.Store
+}
+
+// NewStore returns the Store for the builders of the namespace.
+func NewStore(dataStore, namespace string) (*Store, error) {
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace must be specified: %w", errdefs.ErrInvalidArgument)
+	}
+	st, err := store.New(filepath.Join(dataStore, "builders", namespace), 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{st: st}, nil
+}
+
+// WithLock runs fun while holding the lock of the store.
+func (s *Store) WithLock(fun func() error) error {
+	return s.st.WithLock(fun)
+}
+
+// Get returns the builder. The store must be locked.
+func (s *Store) Get(name string) (*Builder, error) {
+	b, err := s.st.Get(name, definitionFile)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, fmt.Errorf("builder %q not found: %w", name, errdefs.ErrNotFound)
+		}
+		return nil, err
+	}
+	var builder Builder
+	if err := json.Unmarshal(b, &builder); err != nil {
+		return nil, fmt.Errorf("failed to parse the definition of builder %q: %w", name, err)
+	}
+	return &builder, nil
+}
+
+// Save saves the builder. The store must be locked.
+func (s *Store) Save(builder *Builder) error {
+	if err := builder.Validate(); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(builder, "", "    ")
+	if err != nil {
+		return err
+	}
+	return s.st.Set(b, builder.Name, definitionFile)
+}
+
+// List returns all the builders. The store must be locked.
+func (s *Store) List() ([]*Builder, error) {
+	names, err := s.st.List()
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	builders := make([]*Builder, 0, len(names))
+	for _, name := range names {
+		builder, err := s.Get(name)
+		if err != nil {
+			if errors.Is(err, errdefs.ErrNotFound) {
+				// Not a builder (e.g., the lock file)
+				continue
+			}
+			return nil, err
+		}
+		builders = append(builders, builder)
+	}
+	return builders, nil
+}
+
+// Remove removes the builder, including the state of buildkitd. The store must be locked.
+func (s *Store) Remove(name string) error {
+	if err := s.st.Delete(name); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return fmt.Errorf("builder %q not found: %w", name, errdefs.ErrNotFound)
+		}
+		return err
+	}
+	return nil
+}
+
+// StateDir returns the directory of the builder, creating it if needed.
+func (s *Store) StateDir(name string) (string, error) {
+	if err := s.st.GroupEnsure(name); err != nil {
+		return "", err
+	}
+	return s.st.Location(name)
+}
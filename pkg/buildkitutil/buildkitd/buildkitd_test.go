@@ -0,0 +1,96 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package buildkitd
+
+import (
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/errdefs"
+)
+
+func TestBuilderArgs(t *testing.T) {
+	b := &Builder{
+		Name:              "foo",
+		Namespace:         "ns",
+		Worker:            WorkerContainerd,
+		Address:           "unix:///run/buildkit/buildkitd.sock",
+		Rootless:          true,
+		ContainerdAddress: "unix:///run/containerd/containerd.sock",
+		Snapshotter:       "overlayfs",
+		Flags:             []string{"--debug"},
+	}
+	assert.DeepEqual(t, b.Args("/root"), []string{
+		"--addr=unix:///run/buildkit/buildkitd.sock",
+		"--root=/root",
+		"--oci-worker=false",
+		"--containerd-worker=true",
+		"--containerd-worker-addr=/run/containerd/containerd.sock",
+		"--containerd-worker-namespace=ns",
+		"--containerd-worker-snapshotter=overlayfs",
+		"--containerd-worker-rootless=true",
+		"--debug",
+	})
+
+	b = &Builder{
+		Name:    "foo",
+		Worker:  WorkerOCI,
+		Address: "unix:///run/buildkit/buildkitd.sock",
+		Config:  "/etc/buildkit/buildkitd.toml",
+	}
+	assert.DeepEqual(t, b.Args("/root"), []string{
+		"--addr=unix:///run/buildkit/buildkitd.sock",
+		"--root=/root",
+		"--oci-worker=true",
+		"--containerd-worker=false",
+		"--config=/etc/buildkit/buildkitd.toml",
+	})
+}
+
+func TestStore(t *testing.T) {
+	st, err := NewStore(t.TempDir(), "ns")
+	assert.NilError(t, err)
+
+	foo := &Builder{Name: "foo", Namespace: "ns", Worker: WorkerContainerd, Address: "unix:///foo.sock"}
+	bar := &Builder{Name: "bar", Namespace: "ns", Worker: WorkerOCI, Address: "unix:///bar.sock"}
+	err = st.WithLock(func() error {
+		builders, err := st.List()
+		assert.NilError(t, err)
+		assert.Equal(t, len(builders), 0)
+
+		assert.NilError(t, st.Save(foo))
+		assert.NilError(t, st.Save(bar))
+		err = st.Save(&Builder{Name: "baz", Worker: "docker", Address: "unix:///baz.sock"})
+		assert.Assert(t, errors.Is(err, errdefs.ErrInvalidArgument))
+
+		got, err := st.Get("foo")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, foo)
+
+		builders, err = st.List()
+		assert.NilError(t, err)
+		assert.DeepEqual(t, builders, []*Builder{bar, foo})
+
+		assert.NilError(t, st.Remove("foo"))
+		_, err = st.Get("foo")
+		assert.Assert(t, errors.Is(err, errdefs.ErrNotFound))
+		return nil
+	})
+	assert.NilError(t, err)
+}
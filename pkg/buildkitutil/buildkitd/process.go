@@ -0,0 +1,176 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package buildkitd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/containerd/log"
+)
+
+const (
+	// minRestartDelay and maxRestartDelay bound the exponential backoff of restarting buildkitd.
+	minRestartDelay = time.Second
+	maxRestartDelay = 30 * time.Second
+	// healthyDuration is the run time after which buildkitd is considered to have started successfully,
+	// resetting the backoff.
+	healthyDuration = 10 * time.Second
+	// killTimeout is the time buildkitd is given to shut down gracefully.
+	killTimeout = 10 * time.Second
+)
+
+// Start spawns `nerdctl internal buildkitd-supervisor` for the builder, detached from the current process.
+// The pid of the supervisor is recorded in stateDir, so that the builder can be stopped with Stop.
+func Start(stateDir string, b *Builder) error {
+	buildkitd, err := exec.LookPath("buildkitd")
+	if err != nil {
+		return fmt.Errorf("buildkitd needs to be installed, see https://github.com/moby/buildkit: %w", err)
+	}
+	if sock, ok := strings.CutPrefix(b.Address, "unix://"); ok {
+		if err := os.MkdirAll(filepath.Dir(sock), 0o711); err != nil {
+			return err
+		}
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	args := append([]string{"internal", "buildkitd-supervisor", "--state-dir", stateDir, "--", buildkitd}, b.Args(filepath.Join(stateDir, rootDir))...)
+	cmd := exec.Command(exe, args...)
+	cmd.SysProcAttr = sysProcAttr()
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start builder %q: %w", b.Name, err)
+	}
+	tmp := filepath.Join(stateDir, "."+pidFile)
+	if err := os.WriteFile(tmp, []byte(strconv.Itoa(cmd.Process.Pid)), 0o644); err != nil {
+		cmd.Process.Kill()
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(stateDir, pidFile)); err != nil {
+		cmd.Process.Kill()
+		return err
+	}
+	log.L.Debugf("started the supervisor (pid=%d) of builder %q", cmd.Process.Pid, b.Name)
+	return cmd.Process.Release()
+}
+
+// Pid returns the pid of the supervisor of the builder, or 0 if the builder is not running.
+func Pid(stateDir string) int {
+	b, err := os.ReadFile(filepath.Join(stateDir, pidFile))
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil || !isSupervisorProcess(pid) {
+		return 0
+	}
+	return pid
+}
+
+// Stop stops the supervisor of the builder, and waits for it to exit.
+// Stopping a builder that is not running is a no-op.
+func Stop(stateDir string, timeout time.Duration) error {
+	if pid := Pid(stateDir); pid != 0 {
+		proc, err := os.FindProcess(pid)
+		if err != nil {
+			return err
+		}
+		if err := proc.Signal(syscall.SIGTERM); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return fmt.Errorf("failed to stop the supervisor (pid=%d): %w", pid, err)
+		}
+		deadline := time.Now().Add(timeout)
+		for isSupervisorProcess(pid) {
+			if time.Now().After(deadline) {
+				log.L.Warnf("the supervisor (pid=%d) did not exit in %s, killing", pid, timeout)
+				if err := proc.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+					return err
+				}
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	if err := os.Remove(filepath.Join(stateDir, pidFile)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// LogPath returns the path of the log of buildkitd.
+func LogPath(stateDir string) string {
+	return filepath.Join(stateDir, logFile)
+}
+
+// Supervise runs buildkitd with args (args[0] being the path of buildkitd), restarting it with an exponential
+// backoff when it exits, until ctx is cancelled.
+// The output of buildkitd is appended to the log in stateDir.
+func Supervise(ctx context.Context, stateDir string, args []string) error {
+	if len(args) == 0 {
+		return errors.New("no buildkitd command specified")
+	}
+	logF, err := os.OpenFile(LogPath(stateDir), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer logF.Close()
+
+	delay := minRestartDelay
+	for {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdout = logF
+		cmd.Stderr = logF
+		started := time.Now()
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+		exited := make(chan error, 1)
+		go func() {
+			exited <- cmd.Wait()
+		}()
+		select {
+		case <-ctx.Done():
+			cmd.Process.Signal(syscall.SIGTERM)
+			select {
+			case <-exited:
+			case <-time.After(killTimeout):
+				cmd.Process.Kill()
+				<-exited
+			}
+			return nil
+		case err := <-exited:
+			if time.Since(started) > healthyDuration {
+				delay = minRestartDelay
+			}
+			fmt.Fprintf(logF, "nerdctl: buildkitd exited (%v), restarting in %s\n", err, delay)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxRestartDelay)
+	}
+}
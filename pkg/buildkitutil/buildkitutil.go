@@ -39,6 +39,7 @@ import (
 
 	"github.com/containerd/log"
 
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
 )
 
@@ -79,6 +80,54 @@ func GetBuildkitHost(namespace string) (string, error) {
 	return "", fmt.Errorf("no buildkit host is available, tried %d candidates: %w", len(paths), allErr)
 }
 
+// GetBuildkitHostOverSSH is like GetBuildkitHost, but for the rootful BuildKit on the remote host of the SSH address
+// of containerd. The candidate sockets are forwarded to local sockets over SSH.
+func GetBuildkitHostOverSSH(address, namespace string) (string, error) {
+	if namespace == "" {
+		return "", fmt.Errorf("namespace must be specified")
+	}
+	d, _, err := clientutil.SSHSocket(address)
+	if err != nil {
+		return "", err
+	}
+	var remotes []string
+	if namespace != "default" {
+		remotes = append(remotes, fmt.Sprintf("/run/buildkit-%s/buildkitd.sock", namespace))
+	}
+	remotes = append(remotes, "/run/buildkit-default/buildkitd.sock", "/run/buildkit/buildkitd.sock")
+	locals, err := clientutil.SSHTunnel(d, remotes...)
+	if err != nil {
+		return "", err
+	}
+
+	var errs []error //nolint:prealloc
+	for i, local := range locals {
+		buildkitHost := "unix://" + local
+		_, err := pingBKDaemon(buildkitHost)
+		if err == nil {
+			log.L.Debugf("Chosen buildkit host %q on %q (forwarded to %q)", remotes[i], d.Host, buildkitHost)
+			return buildkitHost, nil
+		}
+		errs = append(errs, fmt.Errorf("failed to ping to host %s on %s: %w", remotes[i], d.Host, err))
+	}
+	return "", fmt.Errorf("no buildkit host is available on %s, tried %d candidates: %w", d.Host, len(remotes), errors.Join(errs...))
+}
+
+// LookupBuildkitHost is like GetBuildkitHost, but returns an empty string without logging errors
+// when no buildkit host is available.
+func LookupBuildkitHost(namespace string) string {
+	paths, err := getBuildkitHostCandidates(namespace)
+	if err != nil {
+		return ""
+	}
+	for _, buildkitHost := range paths {
+		if _, err := pingBKDaemon(buildkitHost); err == nil {
+			return buildkitHost
+		}
+	}
+	return ""
+}
+
 func GetWorkerLabels(buildkitHost string) (labels map[string]string, _ error) {
 	buildctlBinary, err := BuildctlBinary()
 	if err != nil {
@@ -114,6 +163,27 @@ func GetWorkerLabels(buildkitHost string) (labels map[string]string, _ error) {
 	return labels, nil
 }
 
+// GetWorkers returns the workers of the buildkit daemon listening on buildkitHost.
+func GetWorkers(buildkitHost string) ([]WorkerInfo, error) {
+	buildctlBinary, err := BuildctlBinary()
+	if err != nil {
+		return nil, err
+	}
+	args := BuildctlBaseArgs(buildkitHost)
+	args = append(args, "debug", "workers", "--format", "{{json .}}")
+	buildctlCheckCmd := exec.Command(buildctlBinary, args...)
+	buildctlCheckCmd.Env = os.Environ()
+	out, err := buildctlCheckCmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	var workers []WorkerInfo
+	if err := json.Unmarshal(out, &workers); err != nil {
+		return nil, err
+	}
+	return workers, nil
+}
+
 func getHint() string {
 	hint := "`buildctl` needs to be installed and `buildkitd` needs to be running, see https://github.com/moby/buildkit"
 	if rootlessutil.IsRootless() {
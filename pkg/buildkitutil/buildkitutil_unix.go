@@ -40,3 +40,19 @@ func getBuildkitHostCandidates(namespace string) ([]string, error) {
 
 	return candidates, nil
 }
+
+// ManagedBuildkitHost returns the address of the builder named name, launched by `nerdctl builder start`.
+// The default builder listens on the first candidate of GetBuildkitHost, so that it is auto-detected.
+func ManagedBuildkitHost(namespace, name string) (string, error) {
+	if namespace == "" {
+		return "", fmt.Errorf("namespace must be specified")
+	}
+	run, err := getRuntimeVariableDataDir()
+	if err != nil {
+		return "", err
+	}
+	if name == "default" {
+		return "unix://" + filepath.Join(run, fmt.Sprintf("buildkit-%s/buildkitd.sock", namespace)), nil
+	}
+	return "unix://" + filepath.Join(run, "nerdctl-buildkit", namespace, name, "buildkitd.sock"), nil
+}
@@ -16,6 +16,16 @@
 
 package buildkitutil
 
+import "fmt"
+
 func getBuildkitHostCandidates(namespace string) ([]string, error) {
 	return []string{"npipe:////./pipe/buildkitd"}, nil
 }
+
+// ManagedBuildkitHost returns the address of the builder named name, launched by `nerdctl builder start`.
+func ManagedBuildkitHost(namespace, name string) (string, error) {
+	if name == "default" {
+		return "npipe:////./pipe/buildkitd", nil
+	}
+	return fmt.Sprintf("npipe:////./pipe/buildkitd-%s-%s", namespace, name), nil
+}
@@ -22,7 +22,11 @@
 
 package buildkitutil
 
-import "time"
+import (
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
 
 // UsageInfo is from https://github.com/moby/buildkit/blob/v0.11.0/client/diskusage.go#L12-L25
 type UsageInfo struct {
@@ -42,3 +46,10 @@ type UsageInfo struct {
 
 // UsageRecordType is from https://github.com/moby/buildkit/blob/v0.11.0/client/diskusage.go#L75
 type UsageRecordType string
+
+// WorkerInfo is from https://github.com/moby/buildkit/blob/v0.11.0/client/workers.go#L15-L21
+type WorkerInfo struct {
+	ID        string             `json:"id"`
+	Labels    map[string]string  `json:"labels"`
+	Platforms []ocispec.Platform `json:"platforms"`
+}
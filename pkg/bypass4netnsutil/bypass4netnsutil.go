@@ -18,9 +18,12 @@ package bypass4netnsutil
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/opencontainers/runtime-spec/specs-go"
 	b4nnoci "github.com/rootless-containers/bypass4netns/pkg/oci"
@@ -108,6 +111,27 @@ func GetBypass4NetnsdDefaultSocketPath() (string, error) {
 	return filepath.Join(xdgRuntimeDir, "bypass4netnsd.sock"), nil
 }
 
+// FindBypass4NetnsdSocket returns the socket path of the running bypass4netnsd.
+func FindBypass4NetnsdSocket() (string, error) {
+	socketPath, err := GetBypass4NetnsdDefaultSocketPath()
+	if err != nil {
+		return "", err
+	}
+	if !isSocketListening(socketPath) {
+		return "", fmt.Errorf("bypass4netnsd not running on %s? (Hint: run `nerdctl system bypass4netnsd start`, or `containerd-rootless-setuptool.sh install-bypass4netnsd`)", socketPath)
+	}
+	return socketPath, nil
+}
+
+func isSocketListening(socketPath string) bool {
+	conn, err := net.DialTimeout("unix", socketPath, time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
 func GetSocketPathByID(id string) (string, error) {
 	xdgRuntimeDir, err := rootlessutil.XDGRuntimeDir()
 	if err != nil {
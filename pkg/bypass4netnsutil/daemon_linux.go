@@ -0,0 +1,179 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package bypass4netnsutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+// daemonStartTimeout is how long StartDaemon waits for bypass4netnsd to listen on the socket.
+var daemonStartTimeout = 10 * time.Second
+
+// DaemonStatus is the status of bypass4netnsd.
+type DaemonStatus struct {
+	Running bool
+	Socket  string
+	Pid     int
+}
+
+func daemonPidFilePath() (string, error) {
+	xdgRuntimeDir, err := rootlessutil.XDGRuntimeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(xdgRuntimeDir, "bypass4netnsd.pid"), nil
+}
+
+// GetDaemonStatus returns the status of bypass4netnsd listening on the default socket.
+func GetDaemonStatus() (*DaemonStatus, error) {
+	socketPath, err := GetBypass4NetnsdDefaultSocketPath()
+	if err != nil {
+		return nil, err
+	}
+	st := &DaemonStatus{
+		Socket:  socketPath,
+		Running: isSocketListening(socketPath),
+	}
+	pidFile, err := daemonPidFilePath()
+	if err != nil {
+		return nil, err
+	}
+	st.Pid = readDaemonPid(pidFile)
+	return st, nil
+}
+
+// readDaemonPid returns the pid in the pid file, or 0 if the file does not exist, or the process is gone.
+func readDaemonPid(pidFile string) int {
+	b, err := os.ReadFile(pidFile)
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil || pid <= 0 || syscall.Kill(pid, 0) != nil {
+		return 0
+	}
+	return pid
+}
+
+// StartDaemon starts bypass4netnsd in the background, unless it is already running.
+// StartDaemon must be called in the host namespaces, not in the RootlessKit child.
+func StartDaemon(ctx context.Context) (*DaemonStatus, error) {
+	if !rootlessutil.IsRootlessParent() {
+		return nil, errors.New("bypass4netnsd must be started as a non-root user, outside of the RootlessKit namespaces")
+	}
+	st, err := GetDaemonStatus()
+	if err != nil {
+		return nil, err
+	}
+	if st.Running {
+		return st, nil
+	}
+	b4nnd, err := exec.LookPath("bypass4netnsd")
+	if err != nil {
+		return nil, fmt.Errorf("bypass4netnsd not found (Hint: install it from https://github.com/rootless-containers/bypass4netns): %w", err)
+	}
+	pidFile, err := daemonPidFilePath()
+	if err != nil {
+		return nil, err
+	}
+	logFile := strings.TrimSuffix(pidFile, ".pid") + ".log"
+	cmd := exec.Command(b4nnd, "--socket="+st.Socket, "--pid-file="+pidFile, "--log-file="+logFile)
+	if err := startAndWaitDaemon(ctx, cmd, st.Socket, pidFile); err != nil {
+		return nil, fmt.Errorf("%w (see %s)", err, logFile)
+	}
+	return GetDaemonStatus()
+}
+
+// startAndWaitDaemon starts the daemon, and waits for it to listen on the socket.
+// On failure, the daemon is killed and reaped, and its pid file is removed.
+func startAndWaitDaemon(ctx context.Context, cmd *exec.Cmd, socket, pidFile string) error {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	log.G(ctx).Debugf("starting %v", cmd.Args)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+	cleanup := func() {
+		if pid := readDaemonPid(pidFile); pid == 0 || pid == cmd.Process.Pid {
+			if err := os.Remove(pidFile); err != nil && !os.IsNotExist(err) {
+				log.G(ctx).WithError(err).Warnf("failed to remove %s", pidFile)
+			}
+		}
+	}
+	for deadline := time.Now().Add(daemonStartTimeout); time.Now().Before(deadline); {
+		select {
+		case err := <-exited:
+			cleanup()
+			return fmt.Errorf("bypass4netnsd exited: %w", err)
+		case <-time.After(100 * time.Millisecond):
+		}
+		if isSocketListening(socket) {
+			return nil
+		}
+	}
+	if err := cmd.Process.Kill(); err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to kill bypass4netnsd (pid=%d)", cmd.Process.Pid)
+	}
+	<-exited
+	cleanup()
+	return fmt.Errorf("timed out waiting for bypass4netnsd to listen on %s", socket)
+}
+
+// StopDaemon stops bypass4netnsd started by StartDaemon.
+func StopDaemon() error {
+	st, err := GetDaemonStatus()
+	if err != nil {
+		return err
+	}
+	pidFile, err := daemonPidFilePath()
+	if err != nil {
+		return err
+	}
+	if st.Pid == 0 {
+		if st.Running {
+			return fmt.Errorf("bypass4netnsd listening on %s was not started by nerdctl (e.g., started by systemd)", st.Socket)
+		}
+		// Remove the stale pid file, if any
+		if err := os.Remove(pidFile); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := syscall.Kill(st.Pid, syscall.SIGTERM); err != nil {
+		return fmt.Errorf("failed to stop bypass4netnsd (pid=%d): %w", st.Pid, err)
+	}
+	if err := os.Remove(pidFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package bypass4netnsutil
+
+import (
+	"context"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+// deadPid returns the pid of a process that has exited.
+func deadPid(t *testing.T) int {
+	cmd := exec.Command("true")
+	assert.NilError(t, cmd.Run())
+	return cmd.Process.Pid
+}
+
+func TestReadDaemonPid(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "bypass4netnsd.pid")
+	assert.Equal(t, readDaemonPid(pidFile), 0)
+
+	assert.NilError(t, os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644))
+	assert.Equal(t, readDaemonPid(pidFile), os.Getpid())
+
+	assert.NilError(t, os.WriteFile(pidFile, []byte(strconv.Itoa(deadPid(t))), 0o644))
+	assert.Equal(t, readDaemonPid(pidFile), 0)
+
+	assert.NilError(t, os.WriteFile(pidFile, []byte("garbage"), 0o644))
+	assert.Equal(t, readDaemonPid(pidFile), 0)
+}
+
+func TestGetDaemonStatus(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", xdg)
+
+	st, err := GetDaemonStatus()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, *st, DaemonStatus{Socket: filepath.Join(xdg, "bypass4netnsd.sock")})
+
+	assert.NilError(t, os.WriteFile(filepath.Join(xdg, "bypass4netnsd.pid"), []byte(strconv.Itoa(os.Getpid())), 0o644))
+	l, err := net.Listen("unix", filepath.Join(xdg, "bypass4netnsd.sock"))
+	assert.NilError(t, err)
+	defer l.Close()
+	st, err = GetDaemonStatus()
+	assert.NilError(t, err)
+	assert.Assert(t, st.Running)
+	assert.Equal(t, st.Pid, os.Getpid())
+}
+
+func TestStopDaemonStalePidFile(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", xdg)
+	pidFile := filepath.Join(xdg, "bypass4netnsd.pid")
+	assert.NilError(t, os.WriteFile(pidFile, []byte(strconv.Itoa(deadPid(t))), 0o644))
+
+	assert.NilError(t, StopDaemon())
+	_, err := os.Stat(pidFile)
+	assert.Assert(t, os.IsNotExist(err))
+}
+
+func TestStartAndWaitDaemon(t *testing.T) {
+	orig := daemonStartTimeout
+	t.Cleanup(func() { daemonStartTimeout = orig })
+	daemonStartTimeout = 500 * time.Millisecond
+
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "bypass4netnsd.sock")
+	pidFile := filepath.Join(dir, "bypass4netnsd.pid")
+
+	// The daemon writes the pid file, but never listens on the socket
+	cmd := exec.Command("sh", "-c", `echo $$ >"$1"; exec sleep 60`, "sh", pidFile)
+	err := startAndWaitDaemon(context.Background(), cmd, socket, pidFile)
+	assert.ErrorContains(t, err, "timed out waiting for bypass4netnsd")
+	assert.Assert(t, cmd.ProcessState != nil, "the daemon must be reaped")
+	_, err = os.Stat(pidFile)
+	assert.Assert(t, os.IsNotExist(err), "the pid file must be removed")
+
+	// The daemon exits before listening on the socket
+	cmd = exec.Command("sh", "-c", `echo $$ >"$1"; exit 1`, "sh", pidFile)
+	err = startAndWaitDaemon(context.Background(), cmd, socket, pidFile)
+	assert.ErrorContains(t, err, "bypass4netnsd exited")
+	_, err = os.Stat(pidFile)
+	assert.Assert(t, os.IsNotExist(err), "the pid file must be removed")
+
+	// The daemon listens on the socket
+	cmd = exec.Command("sh", "-c", `echo $$ >"$1"; exec sleep 60`, "sh", pidFile)
+	l, err := net.Listen("unix", socket)
+	assert.NilError(t, err)
+	defer l.Close()
+	assert.NilError(t, startAndWaitDaemon(context.Background(), cmd, socket, pidFile))
+	assert.Assert(t, cmd.ProcessState == nil, "the daemon must be kept running")
+	assert.NilError(t, cmd.Process.Kill())
+}
@@ -32,12 +32,35 @@ import (
 	"github.com/containerd/platforms"
 
 	"github.com/containerd/nerdctl/v2/pkg/platformutil"
+	"github.com/containerd/nerdctl/v2/pkg/sshutil"
 	"github.com/containerd/nerdctl/v2/pkg/systemutil"
 )
 
 func NewClient(ctx context.Context, namespace, address string, opts ...containerd.Opt) (*containerd.Client, context.Context, context.CancelFunc, error) {
 	ctx = namespaces.WithNamespace(ctx, namespace)
 
+	if sshutil.IsSSH(address) {
+		// connect to the containerd on the remote host via the local socket forwarded over SSH
+		d, socket, err := SSHSocket(address)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		locals, err := SSHTunnel(d, socket)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to connect to %q: %w", address, err)
+		}
+		address = locals[0]
+	}
+	if IsTCP(address) {
+		// connect to the containerd exposed by a TLS-terminating proxy
+		client, err := newTCPClient(address, opts...)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		return client, ctx, cancel, nil
+	}
 	address = strings.TrimPrefix(address, "unix://")
 	const dockerContainerdaddress = "/var/run/docker/containerd/containerd.sock"
 	if err := systemutil.IsSocketAccessible(address); err != nil {
@@ -99,7 +122,8 @@ func DataStore(dataRoot, address string) (string, error) {
 func getAddrHash(addr string) (string, error) {
 	const addrHashLen = 8
 
-	if runtime.GOOS != "windows" {
+	// SSH and TCP addresses are hashed as is, so that each remote host has its own data store
+	if runtime.GOOS != "windows" && !sshutil.IsSSH(addr) && !IsTCP(addr) {
 		addr = strings.TrimPrefix(addr, "unix://")
 
 		var err error
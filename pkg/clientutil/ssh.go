@@ -0,0 +1,39 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package clientutil
+
+import (
+	"github.com/containerd/nerdctl/v2/pkg/sshutil"
+)
+
+// DefaultSSHSocket is the containerd socket on the remote host, when the path is omitted from the SSH address.
+// Only the rootful containerd is supported, as the socket of the rootless containerd is not accessible from sshd.
+const DefaultSSHSocket = "/run/containerd/containerd.sock"
+
+// SSHSocket returns the path of the containerd socket on the remote host of the SSH address,
+// i.e., "ssh://[USER@]HOST[:PORT][/PATH]".
+func SSHSocket(address string) (*sshutil.Destination, string, error) {
+	d, err := sshutil.Parse(address)
+	if err != nil {
+		return nil, "", err
+	}
+	socket := d.Path
+	if socket == "" {
+		socket = DefaultSSHSocket
+	}
+	return &sshutil.Destination{User: d.User, Host: d.Host, Port: d.Port}, socket, nil
+}
@@ -0,0 +1,171 @@
+//go:build unix
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package clientutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/sshutil"
+)
+
+// sshTunnelTimeout is long enough for typing the passphrase of the key
+const sshTunnelTimeout = 2 * time.Minute
+
+type sshTunnel struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	locals []string
+}
+
+var (
+	sshTunnelsMu sync.Mutex
+	sshTunnels   = make(map[string]*sshTunnel)
+	sshTunnelSeq int
+)
+
+// SSHTunnel forwards the unix sockets on the remote host to local unix sockets over SSH,
+// and returns the paths of the local sockets.
+// The tunnel is shared in the process, and does not outlive the process.
+func SSHTunnel(d *sshutil.Destination, remoteSockets ...string) ([]string, error) {
+	key := strings.Join(append(d.Args(), remoteSockets...), "\x00")
+	sshTunnelsMu.Lock()
+	defer sshTunnelsMu.Unlock()
+	if t, ok := sshTunnels[key]; ok {
+		return t.locals, nil
+	}
+
+	dir, err := sshTunnelDir()
+	if err != nil {
+		return nil, err
+	}
+	removeStaleSSHSockets(dir)
+	args := []string{"-T", "-o", "ExitOnForwardFailure=yes", "-o", "StreamLocalBindUnlink=yes"}
+	locals := make([]string, len(remoteSockets))
+	for i, remote := range remoteSockets {
+		sshTunnelSeq++
+		locals[i] = filepath.Join(dir, fmt.Sprintf("%d-%d.sock", os.Getpid(), sshTunnelSeq))
+		args = append(args, "-L", locals[i]+":"+remote)
+	}
+	// The remote `cat` exits when the stdin is closed, i.e., when this process exits.
+	args = append(append(args, d.Args()...), "cat")
+	cmd := exec.Command("ssh", args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stderr = os.Stderr
+	log.L.Debugf("Starting SSH tunnel %v", cmd.Args)
+	if err = cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start ssh: %w", err)
+	}
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+	if err = waitForSSHSockets(locals, exited); err != nil {
+		_ = cmd.Process.Kill()
+		return nil, err
+	}
+	sshTunnels[key] = &sshTunnel{cmd: cmd, stdin: stdin, locals: locals}
+	return locals, nil
+}
+
+func waitForSSHSockets(locals []string, exited <-chan error) error {
+	timeout := time.After(sshTunnelTimeout)
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-exited:
+			if err == nil {
+				err = errors.New("exit status 0")
+			}
+			return fmt.Errorf("ssh exited before forwarding the sockets: %w", err)
+		case <-timeout:
+			return fmt.Errorf("timed out waiting for ssh to forward the sockets %v", locals)
+		case <-ticker.C:
+			ready := true
+			for _, local := range locals {
+				if _, err := os.Stat(local); err != nil {
+					ready = false
+				}
+			}
+			if ready {
+				return nil
+			}
+		}
+	}
+}
+
+// sshTunnelDir returns the private directory for the local sockets of the SSH tunnels.
+func sshTunnelDir() (string, error) {
+	base := os.Getenv("XDG_RUNTIME_DIR")
+	if base == "" {
+		base = os.TempDir()
+	}
+	euid := os.Geteuid()
+	dir := filepath.Join(base, "nerdctl-ssh-"+strconv.Itoa(euid))
+	if err := os.Mkdir(dir, 0o700); err != nil && !errors.Is(err, os.ErrExist) {
+		return "", err
+	}
+	st, err := os.Lstat(dir)
+	if err != nil {
+		return "", err
+	}
+	// the directory may be created by another user in the shared temporary directory
+	if stat, ok := st.Sys().(*syscall.Stat_t); !st.IsDir() || !ok || int(stat.Uid) != euid || st.Mode().Perm() != 0o700 {
+		return "", fmt.Errorf("%q must be a directory owned by UID %d, with the permission 0700", dir, euid)
+	}
+	return dir, nil
+}
+
+// removeStaleSSHSockets removes the sockets of the processes that are no longer running.
+func removeStaleSSHSockets(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		pidStr, _, ok := strings.Cut(e.Name(), "-")
+		if !ok {
+			continue
+		}
+		pid, err := strconv.Atoi(pidStr)
+		if err != nil {
+			continue
+		}
+		if err := unix.Kill(pid, 0); errors.Is(err, unix.ESRCH) {
+			_ = os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+}
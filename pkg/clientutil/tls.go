@@ -0,0 +1,230 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package clientutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/credentials"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/defaults"
+)
+
+// TLSOptions specifies the TLS configuration for connecting to containerd at a "tcp://" address,
+// typically exposed by a TLS-terminating proxy.
+type TLSOptions struct {
+	// CACert is the CA certificate for verifying the server. Empty means the system roots.
+	CACert string
+	// Cert and Key are the client certificate and the key, for mutual TLS.
+	Cert string
+	Key  string
+	// SPIFFEID is the SPIFFE ID that the server certificate must have, e.g., "spiffe://example.org/containerd".
+	SPIFFEID string
+	// SPIFFETrustDomain is the trust domain that the SPIFFE ID of the server certificate must belong to, e.g., "example.org".
+	SPIFFETrustDomain string
+}
+
+var (
+	tlsOptions   TLSOptions
+	tlsOptionsMu sync.Mutex
+)
+
+// SetTLSOptions sets the TLS options used by NewClient for "tcp://" addresses.
+func SetTLSOptions(o TLSOptions) {
+	tlsOptionsMu.Lock()
+	tlsOptions = o
+	tlsOptionsMu.Unlock()
+}
+
+func getTLSOptions() TLSOptions {
+	tlsOptionsMu.Lock()
+	defer tlsOptionsMu.Unlock()
+	return tlsOptions
+}
+
+// IsTCP returns true if the containerd address is a "tcp://" address.
+func IsTCP(address string) bool {
+	return strings.HasPrefix(address, "tcp://")
+}
+
+// TCPHost returns "HOST:PORT" of the "tcp://HOST:PORT" address.
+func TCPHost(address string) (string, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q: %w", address, err)
+	}
+	if u.Scheme != "tcp" || u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("invalid address %q (expected \"tcp://HOST:PORT\")", address)
+	}
+	if u.Hostname() == "" || u.Port() == "" {
+		return "", fmt.Errorf("invalid address %q (expected \"tcp://HOST:PORT\")", address)
+	}
+	return u.Host, nil
+}
+
+// Validate validates the TLS options.
+func (o TLSOptions) Validate() error {
+	if (o.Cert == "") != (o.Key == "") {
+		return errors.New("the TLS certificate and the key must be specified together")
+	}
+	if o.SPIFFEID != "" {
+		if _, err := parseSPIFFEID(o.SPIFFEID); err != nil {
+			return err
+		}
+	}
+	if (o.SPIFFEID != "" || o.SPIFFETrustDomain != "") && o.CACert == "" {
+		return errors.New("the CA certificate must be specified for verifying the SPIFFE ID")
+	}
+	return nil
+}
+
+// TLSConfig returns the TLS config for connecting to serverName with the options.
+func (o TLSOptions) TLSConfig(serverName string) (*tls.Config, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+	if o.CACert != "" {
+		b, err := os.ReadFile(o.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the CA certificate: %w", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no certificate found in %q", o.CACert)
+		}
+	}
+	if o.Cert != "" {
+		cert, err := tls.LoadX509KeyPair(o.Cert, o.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the TLS certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	if o.SPIFFEID != "" || o.SPIFFETrustDomain != "" {
+		// SPIFFE certificates identify the server with the URI SAN, not with the host name,
+		// so the chain is verified in VerifyPeerCertificate without the host name.
+		roots := cfg.RootCAs
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return o.verifySPIFFE(roots, rawCerts)
+		}
+	}
+	return cfg, nil
+}
+
+func (o TLSOptions) verifySPIFFE(roots *x509.CertPool, rawCerts [][]byte) error {
+	if len(rawCerts) == 0 {
+		return errors.New("no server certificate")
+	}
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return err
+		}
+		certs[i] = cert
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return err
+	}
+	// An X509-SVID has exactly one URI SAN
+	if len(certs[0].URIs) != 1 {
+		return fmt.Errorf("expected exactly one URI SAN in the server certificate, got %d", len(certs[0].URIs))
+	}
+	id := certs[0].URIs[0]
+	if id.Scheme != "spiffe" {
+		return fmt.Errorf("the server certificate has no SPIFFE ID (got %q)", id)
+	}
+	if o.SPIFFEID != "" && id.String() != o.SPIFFEID {
+		return fmt.Errorf("unexpected SPIFFE ID %q (expected %q)", id, o.SPIFFEID)
+	}
+	if o.SPIFFETrustDomain != "" && id.Host != strings.TrimPrefix(o.SPIFFETrustDomain, "spiffe://") {
+		return fmt.Errorf("unexpected SPIFFE ID %q (expected the trust domain %q)", id, o.SPIFFETrustDomain)
+	}
+	return nil
+}
+
+func parseSPIFFEID(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme != "spiffe" || u.Host == "" || u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("invalid SPIFFE ID %q (expected \"spiffe://TRUST_DOMAIN/PATH\")", s)
+	}
+	return u, nil
+}
+
+// newTCPClient connects to the containerd at the "tcp://" address with TLS.
+func newTCPClient(address string, opts ...containerd.Opt) (*containerd.Client, error) {
+	host, err := TCPHost(address)
+	if err != nil {
+		return nil, err
+	}
+	serverName, _, err := net.SplitHostPort(host)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := getTLSOptions().TLSConfig(serverName)
+	if err != nil {
+		return nil, err
+	}
+	// same as the defaults of containerd.New, except the transport
+	const timeout = 10 * time.Second
+	backoffConfig := backoff.DefaultConfig
+	backoffConfig.MaxDelay = timeout
+	conn, err := grpc.NewClient("passthrough:///"+host,
+		grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoffConfig,
+			MinConnectTimeout: timeout,
+		}),
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(defaults.DefaultMaxRecvMsgSize),
+			grpc.MaxCallSendMsgSize(defaults.DefaultMaxSendMsgSize)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %q: %w", address, err)
+	}
+	client, err := containerd.NewWithConn(conn, opts...)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
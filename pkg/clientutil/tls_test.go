@@ -0,0 +1,143 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package clientutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestTCPHost(t *testing.T) {
+	host, err := TCPHost("tcp://example.com:2376")
+	assert.NilError(t, err)
+	assert.Equal(t, host, "example.com:2376")
+
+	host, err = TCPHost("tcp://[::1]:2376")
+	assert.NilError(t, err)
+	assert.Equal(t, host, "[::1]:2376")
+
+	for _, s := range []string{
+		"tcp://example.com",
+		"tcp://:2376",
+		"tcp://user@example.com:2376",
+		"tcp://example.com:2376/foo",
+		"unix:///run/containerd/containerd.sock",
+	} {
+		_, err = TCPHost(s)
+		assert.Assert(t, err != nil, s)
+	}
+}
+
+func TestTLSOptionsValidate(t *testing.T) {
+	for _, o := range []TLSOptions{
+		{Cert: "cert.pem"},
+		{Key: "key.pem"},
+		{CACert: "ca.pem", SPIFFEID: "https://example.org/containerd"},
+		{SPIFFETrustDomain: "example.org"},
+	} {
+		assert.Assert(t, o.Validate() != nil, "%+v", o)
+	}
+	for _, o := range []TLSOptions{
+		{},
+		{CACert: "ca.pem", Cert: "cert.pem", Key: "key.pem"},
+		{CACert: "ca.pem", SPIFFEID: "spiffe://example.org/containerd"},
+		{CACert: "ca.pem", SPIFFETrustDomain: "example.org"},
+	} {
+		assert.NilError(t, o.Validate(), "%+v", o)
+	}
+}
+
+func TestVerifySPIFFE(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	assert.NilError(t, err)
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NilError(t, os.WriteFile(caPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0o600))
+	caCert, err := x509.ParseCertificate(caDER)
+	assert.NilError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	id, err := url.Parse("spiffe://example.org/containerd")
+	assert.NilError(t, err)
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		URIs:         []*url.URL{id},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, caCert, &key.PublicKey, caKey)
+	assert.NilError(t, err)
+
+	for _, o := range []TLSOptions{
+		{CACert: caPath, SPIFFEID: "spiffe://example.org/containerd"},
+		{CACert: caPath, SPIFFETrustDomain: "example.org"},
+	} {
+		cfg, err := o.TLSConfig("containerd.example.com")
+		assert.NilError(t, err)
+		assert.NilError(t, cfg.VerifyPeerCertificate([][]byte{der}, nil), "%+v", o)
+	}
+	for _, o := range []TLSOptions{
+		{CACert: caPath, SPIFFEID: "spiffe://example.org/buildkitd"},
+		{CACert: caPath, SPIFFETrustDomain: "example.com"},
+	} {
+		cfg, err := o.TLSConfig("containerd.example.com")
+		assert.NilError(t, err)
+		assert.Assert(t, cfg.VerifyPeerCertificate([][]byte{der}, nil) != nil, "%+v", o)
+	}
+
+	// the certificate signed by another CA is rejected
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	otherDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &otherKey.PublicKey, otherKey)
+	assert.NilError(t, err)
+	otherCACert, err := x509.ParseCertificate(otherDER)
+	assert.NilError(t, err)
+	der, err = x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		URIs:         []*url.URL{id},
+	}, otherCACert, &key.PublicKey, otherKey)
+	assert.NilError(t, err)
+	cfg, err := TLSOptions{CACert: caPath, SPIFFEID: "spiffe://example.org/containerd"}.TLSConfig("containerd.example.com")
+	assert.NilError(t, err)
+	assert.Assert(t, cfg.VerifyPeerCertificate([][]byte{der}, nil) != nil)
+}
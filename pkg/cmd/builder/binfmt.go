@@ -0,0 +1,183 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+	"github.com/containerd/platforms"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/platformutil"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
+)
+
+// DefaultBinfmtImage is the image for registering QEMU emulators to binfmt_misc.
+const DefaultBinfmtImage = "tonistiigi/binfmt:master"
+
+type binfmtPrintable struct {
+	Platform string
+	Status   string
+}
+
+// BinfmtList lists how the platforms that can be emulated with QEMU are executed on the host.
+func BinfmtList(_ context.Context, options types.BuilderBinfmtListOptions) error {
+	w := options.Stdout
+	var tmpl *template.Template
+	switch options.Format {
+	case "", "table":
+		w = tabwriter.NewWriter(w, 4, 8, 4, ' ', 0)
+		fmt.Fprintln(w, "PLATFORM\tSTATUS")
+	case "raw":
+		return errors.New("unsupported format: \"raw\"")
+	default:
+		var err error
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+	emulators, err := platformutil.Emulators()
+	if err != nil {
+		return err
+	}
+	for _, e := range emulators {
+		p := binfmtPrintable{
+			Platform: e.Platform,
+			Status:   e.Handler,
+		}
+		switch {
+		case e.Native:
+			p.Status = "native"
+		case e.Handler == "":
+			p.Status = "not installed"
+		}
+		if tmpl != nil {
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, p); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w, b.String()); err != nil {
+				return err
+			}
+		} else {
+			fmt.Fprintf(w, "%s\t%s\n", p.Platform, p.Status)
+		}
+	}
+	if f, ok := w.(formatter.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// BinfmtInstall registers the QEMU emulators for the platforms (or "all") to binfmt_misc,
+// by running the tonistiigi/binfmt image in a privileged container.
+func BinfmtInstall(ctx context.Context, platformList []string, options types.BuilderBinfmtInstallOptions) error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("binfmt_misc is only available on Linux: %w", errdefs.ErrNotImplemented)
+	}
+	if rootlessutil.IsRootless() {
+		return errors.New("registering QEMU emulators requires rootful mode (hint: run `sudo nerdctl builder binfmt install`)")
+	}
+	archs, err := binfmtArchitectures(platformList)
+	if err != nil {
+		return err
+	}
+	image := options.Image
+	if image == "" {
+		image = DefaultBinfmtImage
+	}
+	args := append(slices.Clone(options.NerdctlArgs), "run", "--rm", "--privileged", image, "--install", strings.Join(archs, ","))
+	cmd := exec.CommandContext(ctx, options.NerdctlCmd, args...)
+	cmd.Stdout = options.Stdout
+	cmd.Stderr = options.Stderr
+	log.G(ctx).Debugf("running %v", cmd.Args)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to install QEMU emulators for %v: %w", archs, err)
+	}
+	return nil
+}
+
+// binfmtArchitectures converts the platforms to the architectures accepted by `tonistiigi/binfmt --install`.
+func binfmtArchitectures(platformList []string) ([]string, error) {
+	if len(platformList) == 0 {
+		return []string{"all"}, nil
+	}
+	var archs []string
+	for _, s := range platformList {
+		for _, s := range strings.Split(s, ",") {
+			if s == "all" {
+				return []string{"all"}, nil
+			}
+			p, err := platforms.Parse(s)
+			if err != nil {
+				return nil, err
+			}
+			if p.OS != "linux" {
+				return nil, fmt.Errorf("cannot emulate platform %q, only linux platforms can be emulated: %w", s, errdefs.ErrInvalidArgument)
+			}
+			archs = append(archs, p.Architecture)
+		}
+	}
+	return strutil.DedupeStrSlice(archs), nil
+}
+
+// ensureEmulators installs the QEMU emulators for the target platforms of the build that cannot be executed on
+// the host, so that RUN instructions for these platforms do not fail with "exec format error".
+// Failing to install the emulators is not fatal, as the Dockerfile might not need them (e.g., when cross-compiling).
+func ensureEmulators(ctx context.Context, options types.BuilderBuildOptions) error {
+	if len(options.Platform) == 0 || runtime.GOOS != "linux" {
+		return nil
+	}
+	missing, err := platformutil.MissingEmulators(options.Platform...)
+	if err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	hint := "sudo nerdctl builder binfmt install " + strings.Join(missing, " ")
+	// The emulators are registered on the host running nerdctl, so they are useless for a remote buildkitd.
+	if !options.BinfmtInstall || rootlessutil.IsRootless() || !strings.HasPrefix(options.BuildKitHost, "unix://") {
+		log.G(ctx).Warnf("platforms %v cannot be executed on this host, RUN instructions for them may fail with \"exec format error\" (hint: `%s`)", missing, hint)
+		return nil
+	}
+	log.G(ctx).Infof("installing QEMU emulators for platforms %v", missing)
+	err = BinfmtInstall(ctx, missing, types.BuilderBinfmtInstallOptions{
+		Stdout:      options.Stderr,
+		Stderr:      options.Stderr,
+		NerdctlCmd:  options.NerdctlCmd,
+		NerdctlArgs: options.NerdctlArgs,
+	})
+	if err != nil {
+		log.G(ctx).WithError(err).Warnf("RUN instructions for platforms %v may fail with \"exec format error\" (hint: `%s`)", missing, hint)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestBinfmtArchitectures(t *testing.T) {
+	testCases := []struct {
+		platforms []string
+		expected  []string
+		err       string
+	}{
+		{
+			platforms: nil,
+			expected:  []string{"all"},
+		},
+		{
+			platforms: []string{"linux/arm64", "linux/riscv64"},
+			expected:  []string{"arm64", "riscv64"},
+		},
+		{
+			platforms: []string{"linux/arm/v7,linux/arm/v6", "linux/arm64"},
+			expected:  []string{"arm", "arm64"},
+		},
+		{
+			platforms: []string{"linux/arm64", "all"},
+			expected:  []string{"all"},
+		},
+		{
+			platforms: []string{"windows/amd64"},
+			err:       "only linux platforms can be emulated",
+		},
+	}
+	for _, tc := range testCases {
+		archs, err := binfmtArchitectures(tc.platforms)
+		if tc.err != "" {
+			assert.ErrorContains(t, err, tc.err)
+			continue
+		}
+		assert.NilError(t, err)
+		assert.DeepEqual(t, archs, tc.expected)
+	}
+}
@@ -25,9 +25,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
+	dockercliopts "github.com/docker/cli/opts"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 
 	containerd "github.com/containerd/containerd/v2/client"
@@ -41,6 +43,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/buildkitutil"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
 	"github.com/containerd/nerdctl/v2/pkg/platformutil"
 	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
@@ -62,6 +65,13 @@ func (p platformParser) DefaultSpec() platforms.Platform {
 }
 
 func Build(ctx context.Context, client *containerd.Client, options types.BuilderBuildOptions) error {
+	if options.GOptions.Offline {
+		// BuildKit resolves the base images and the cache sources on its own, without nerdctl
+		return fmt.Errorf("nerdctl build is not supported in the offline mode: %w", dockerconfigresolver.ErrOffline)
+	}
+	if err := ensureEmulators(ctx, options); err != nil {
+		return err
+	}
 	buildctlBinary, buildctlArgs, needsLoading, metaFile, tags, cleanup, err := generateBuildctlArgs(ctx, client, options)
 	if err != nil {
 		return err
@@ -83,8 +93,11 @@ func Build(ctx context.Context, client *containerd.Client, options types.Builder
 	} else {
 		buildctlCmd.Stdout = options.Stdout
 	}
+	// The tail of the output is kept for diagnosing the error when the build fails
+	buildctlStderr := newTailBuffer(64 * 1024)
+	buildctlCmd.Stderr = buildctlStderr
 	if !options.Quiet {
-		buildctlCmd.Stderr = options.Stderr
+		buildctlCmd.Stderr = io.MultiWriter(options.Stderr, buildctlStderr)
 	}
 
 	if err := buildctlCmd.Start(); err != nil {
@@ -97,11 +110,17 @@ func Build(ctx context.Context, client *containerd.Client, options types.Builder
 			return err
 		}
 		if err = loadImage(ctx, buildctlStdout, options.GOptions.Namespace, options.GOptions.Address, options.GOptions.Snapshotter, options.Stdout, platMC, options.Quiet); err != nil {
+			// Loading fails when buildctl exits without writing the image, so buildctl has to be reaped for diagnosing its error
+			buildctlCmd.Process.Kill()
+			if buildctlCmd.Wait() != nil {
+				printBuildDiagnosis(options.Stderr, buildctlStderr.String(), buildctlArgs)
+			}
 			return err
 		}
 	}
 
 	if err = buildctlCmd.Wait(); err != nil {
+		printBuildDiagnosis(options.Stderr, buildctlStderr.String(), buildctlArgs)
 		return err
 	}
 
@@ -161,7 +180,8 @@ func loadImage(ctx context.Context, in io.Reader, namespace, address, snapshotte
 		client.Close()
 	}()
 	r := &readCounter{Reader: in}
-	imgs, err := client.Import(ctx, r, containerd.WithDigestRef(archive.DigestTranslator(snapshotter)), containerd.WithSkipDigestRef(func(name string) bool { return name != "" }), containerd.WithImportPlatform(platMC))
+	// The attestation manifests (provenance, SBOM) are imported too, but never unpacked.
+	imgs, err := client.Import(ctx, r, containerd.WithDigestRef(archive.DigestTranslator(snapshotter)), containerd.WithSkipDigestRef(func(name string) bool { return name != "" }), containerd.WithImportPlatform(platformutil.WithAttestations(platMC)))
 	if err != nil {
 		if r.N == 0 {
 			// Avoid confusing "unrecognized image format"
@@ -201,6 +221,15 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 		return "", nil, false, "", nil, nil, err
 	}
 
+	tags = strutil.DedupeStrSlice(options.Tag)
+	for idx, tag := range tags {
+		parsedReference, err := referenceutil.Parse(tag)
+		if err != nil {
+			return "", nil, false, "", nil, nil, err
+		}
+		tags[idx] = parsedReference.String()
+	}
+
 	output := options.Output
 	if output == "" {
 		info, err := client.Server(ctx)
@@ -215,43 +244,22 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 			output = "type=image,unpack=true" // ensure the target stage is unlazied (needed for any snapshotters)
 		} else {
 			output = "type=docker"
-			if len(options.Platform) > 1 {
+			if len(options.Platform) > 1 || len(options.Attest) > 0 {
 				// For avoiding `error: failed to solve: docker exporter does not currently support exporting manifest lists`
+				// Attestations are stored in an image index too.
 				// TODO: consider using type=oci for single-options.Platform build too
 				output = "type=oci"
 			}
-			needsLoading = true
-		}
-	} else {
-		if !strings.Contains(output, "type=") {
-			// should accept --output <DIR> as an alias of --output
-			// type=local,dest=<DIR>
-			output = fmt.Sprintf("type=local,dest=%s", output)
-		}
-		if strings.Contains(output, "type=docker") || strings.Contains(output, "type=oci") {
-			if !strings.Contains(output, "dest=") {
-				needsLoading = true
-			}
 		}
 	}
-	if tags = strutil.DedupeStrSlice(options.Tag); len(tags) > 0 {
-		ref := tags[0]
-		parsedReference, err := referenceutil.Parse(ref)
-		if err != nil {
-			return "", nil, false, "", nil, nil, err
-		}
-		output += ",name=" + parsedReference.String()
-
-		// pick the first tag and add it to output
-		for idx, tag := range tags {
-			parsedReference, err = referenceutil.Parse(tag)
-			if err != nil {
-				return "", nil, false, "", nil, nil, err
-			}
-			tags[idx] = parsedReference.String()
-		}
-	} else if len(tags) == 0 {
-		output = output + ",dangling-name-prefix=<none>"
+	out, err := parseOutputOption(output, tags)
+	if err != nil {
+		return "", nil, false, "", nil, nil, err
+	}
+	needsLoading = out.load
+	if !out.stored {
+		// no image to be tagged locally
+		tags = nil
 	}
 
 	buildctlArgs = buildkitutil.BuildctlBaseArgs(options.BuildKitHost)
@@ -261,7 +269,7 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 		"--progress=" + options.Progress,
 		"--frontend=dockerfile.v0",
 		"--local=context=" + options.BuildContext,
-		"--output=" + output,
+		"--output=" + out.output,
 	}...)
 
 	dir := options.BuildContext
@@ -375,7 +383,11 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 		}
 	}
 
-	for _, l := range strutil.DedupeStrSlice(options.Label) {
+	labels, err := buildLabels(options.DefaultLabels, options.LabelFile, options.Label)
+	if err != nil {
+		return "", nil, false, "", nil, nil, err
+	}
+	for _, l := range labels {
 		buildctlArgs = append(buildctlArgs, "--opt=label:"+l)
 	}
 
@@ -415,15 +427,17 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 	}
 
 	for _, s := range strutil.DedupeStrSlice(options.CacheFrom) {
-		if !strings.Contains(s, "type=") {
-			s = "type=registry,ref=" + s
+		s, err = parseCacheOption(s, false)
+		if err != nil {
+			return "", nil, false, "", nil, nil, err
 		}
 		buildctlArgs = append(buildctlArgs, "--import-cache="+s)
 	}
 
 	for _, s := range strutil.DedupeStrSlice(options.CacheTo) {
-		if !strings.Contains(s, "type=") {
-			s = "type=registry,ref=" + s
+		s, err = parseCacheOption(s, true)
+		if err != nil {
+			return "", nil, false, "", nil, nil, err
 		}
 		buildctlArgs = append(buildctlArgs, "--export-cache="+s)
 	}
@@ -465,6 +479,32 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 	return buildctlBinary, buildctlArgs, needsLoading, metaFile, tags, cleanup, nil
 }
 
+// buildLabels returns the labels of the image as "KEY=VALUE" strings sorted by the keys.
+// The labels of labelFile override defaultLabels, and label overrides them.
+func buildLabels(defaultLabels map[string]string, labelFile, label []string) ([]string, error) {
+	kvs, err := dockercliopts.ReadKVStrings(strutil.DedupeStrSlice(labelFile), label)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]string, len(defaultLabels)+len(kvs))
+	for k, v := range defaultLabels {
+		m[k] = v
+	}
+	for k, v := range strutil.ConvertKVStringsToMap(kvs) {
+		m[k] = v
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	res := make([]string, len(keys))
+	for i, k := range keys {
+		res[i] = k + "=" + m[k]
+	}
+	return res, nil
+}
+
 func getDigestFromMetaFile(path string) (string, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -18,6 +18,7 @@ package builder
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"reflect"
 	"runtime"
@@ -238,3 +239,21 @@ func TestParseBuildctlArgsForOCILayout(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildLabels(t *testing.T) {
+	labelFile := filepath.Join(t.TempDir(), "labels")
+	assert.NilError(t, os.WriteFile(labelFile, []byte("com.example.host=build-1\ncom.example.team=a\n"), 0600))
+
+	labels, err := buildLabels(
+		map[string]string{"com.example.owner": "alice", "com.example.host": "default"},
+		[]string{labelFile},
+		[]string{"com.example.team=b", "com.example.empty="},
+	)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, labels, []string{
+		"com.example.empty=",
+		"com.example.host=build-1",
+		"com.example.owner=alice",
+		"com.example.team=b",
+	})
+}
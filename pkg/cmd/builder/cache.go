@@ -0,0 +1,88 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/containerd/errdefs"
+)
+
+// parseCacheOption converts the value of --cache-from (export=false) or --cache-to (export=true)
+// to the value of `buildctl build --import-cache` or `--export-cache`, in the same way as `docker buildx build`:
+//   - a value without "type=" is a registry reference (e.g., "example.com/foo:cache")
+//   - the paths of type=local are made absolute
+//   - the GitHub Actions cache service is configured from the environment of the runner for type=gha
+func parseCacheOption(s string, export bool) (string, error) {
+	if !strings.Contains(s, "type=") {
+		return "type=registry,ref=" + s, nil
+	}
+	o, err := parseCSVOption(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse cache option %q: %w", s, err)
+	}
+
+	switch typ := o.typ; typ {
+	case "registry":
+		if o.attrs["ref"] == "" {
+			return "", fmt.Errorf("cache type %q requires \"ref\": %w", typ, errdefs.ErrInvalidArgument)
+		}
+	case "local":
+		pathKey := "src"
+		if export {
+			pathKey = "dest"
+		}
+		if o.attrs[pathKey] == "" {
+			return "", fmt.Errorf("cache type %q requires %q: %w", typ, pathKey, errdefs.ErrInvalidArgument)
+		}
+		if err := o.setAbs(pathKey); err != nil {
+			return "", err
+		}
+	case "gha":
+		// https://github.com/docker/buildx/blob/v0.20.0/util/buildflags/cache.go#L185-L205
+		if !o.has("url") {
+			if v, ok := os.LookupEnv("ACTIONS_CACHE_URL"); ok {
+				o.set("url", v)
+			}
+		}
+		if !o.has("url_v2") {
+			if v, ok := os.LookupEnv("ACTIONS_RESULTS_URL"); ok {
+				o.set("url_v2", v)
+			}
+		}
+		if !o.has("token") {
+			if v, ok := os.LookupEnv("ACTIONS_RUNTIME_TOKEN"); ok {
+				o.set("token", v)
+			}
+		}
+	case "inline":
+		if !export {
+			return "", fmt.Errorf("cache type %q can only be used for --cache-to (the cache is imported from the image with type=registry): %w",
+				typ, errdefs.ErrInvalidArgument)
+		}
+	case "s3", "azblob":
+	case "":
+		return "", fmt.Errorf("cache type is empty in %q: %w", s, errdefs.ErrInvalidArgument)
+	default:
+		return "", fmt.Errorf("unknown cache type %q (supported values: \"registry\", \"local\", \"gha\", \"inline\", \"s3\", \"azblob\"): %w",
+			typ, errdefs.ErrInvalidArgument)
+	}
+
+	return o.String()
+}
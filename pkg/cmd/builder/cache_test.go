@@ -0,0 +1,113 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseCacheOption(t *testing.T) {
+	t.Setenv("ACTIONS_CACHE_URL", "https://cache.example.com/")
+	t.Setenv("ACTIONS_RUNTIME_TOKEN", "secret")
+	wd, err := filepath.Abs(".")
+	assert.NilError(t, err)
+
+	testCases := []struct {
+		value    string
+		export   bool
+		expected string
+		err      string
+	}{
+		{
+			value:    "example.com/foo:cache",
+			expected: "type=registry,ref=example.com/foo:cache",
+		},
+		{
+			value:    "type=registry,ref=example.com/foo:cache,mode=max",
+			export:   true,
+			expected: "type=registry,ref=example.com/foo:cache,mode=max",
+		},
+		{
+			value: "type=registry",
+			err:   `requires "ref"`,
+		},
+		{
+			value:    "type=local,src=cache",
+			expected: "type=local,src=" + filepath.Join(wd, "cache"),
+		},
+		{
+			value:    "type=local,dest=/tmp/cache,mode=max",
+			export:   true,
+			expected: "type=local,dest=/tmp/cache,mode=max",
+		},
+		{
+			value:  "type=local,src=/tmp/cache",
+			export: true,
+			err:    `requires "dest"`,
+		},
+		{
+			value:    "type=gha,scope=foo",
+			export:   true,
+			expected: "type=gha,scope=foo,url=https://cache.example.com/,token=secret",
+		},
+		{
+			value:    "type=gha,url=https://other.example.com/",
+			expected: "type=gha,url=https://other.example.com/,token=secret",
+		},
+		{
+			value:    "type=inline",
+			export:   true,
+			expected: "type=inline",
+		},
+		{
+			value: "type=inline",
+			err:   "can only be used for --cache-to",
+		},
+		{
+			value: "type=foo",
+			err:   `unknown cache type "foo"`,
+		},
+		{
+			value: "type=local,src",
+			err:   "expected key=value",
+		},
+	}
+	for _, tc := range testCases {
+		got, err := parseCacheOption(tc.value, tc.export)
+		if tc.err != "" {
+			assert.ErrorContains(t, err, tc.err, tc.value)
+			continue
+		}
+		assert.NilError(t, err, tc.value)
+		assert.Equal(t, got, tc.expected, tc.value)
+	}
+}
+
+func TestPruneFilterArgs(t *testing.T) {
+	args, err := pruneFilterArgs([]string{"until=24h", "type=regular"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{"--keep-duration=24h0m0s", "--filter=type==regular"})
+
+	_, err = pruneFilterArgs([]string{"until=yesterday"})
+	assert.ErrorContains(t, err, "invalid filter")
+
+	_, err = pruneFilterArgs([]string{"label=foo"})
+	assert.ErrorContains(t, err, "unsupported filter")
+}
@@ -0,0 +1,91 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/containerd/errdefs"
+)
+
+// csvOption is a "type=TYPE,key=value,..." option of buildctl, such as --output and --export-cache.
+// The order of the keys is preserved.
+type csvOption struct {
+	typ   string
+	keys  []string
+	attrs map[string]string
+}
+
+func parseCSVOption(s string) (*csvOption, error) {
+	fields, err := csv.NewReader(strings.NewReader(s)).Read()
+	if err != nil {
+		return nil, err
+	}
+	o := &csvOption{attrs: make(map[string]string)}
+	for _, field := range fields {
+		k, v, ok := strings.Cut(field, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid field %q, expected key=value: %w", field, errdefs.ErrInvalidArgument)
+		}
+		k = strings.ToLower(strings.TrimSpace(k))
+		if k == "type" {
+			o.typ = v
+			continue
+		}
+		o.set(k, v)
+	}
+	return o, nil
+}
+
+func (o *csvOption) has(k string) bool {
+	_, ok := o.attrs[k]
+	return ok
+}
+
+func (o *csvOption) set(k, v string) {
+	if !o.has(k) {
+		o.keys = append(o.keys, k)
+	}
+	o.attrs[k] = v
+}
+
+// setAbs makes the path in the attribute k absolute.
+func (o *csvOption) setAbs(k string) error {
+	abs, err := filepath.Abs(o.attrs[k])
+	if err != nil {
+		return err
+	}
+	o.attrs[k] = abs
+	return nil
+}
+
+func (o *csvOption) String() (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	record := []string{"type=" + o.typ}
+	for _, k := range o.keys {
+		record = append(record, k+"="+o.attrs[k])
+	}
+	if err := w.Write(record); err != nil {
+		return "", err
+	}
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n"), w.Error()
+}
@@ -0,0 +1,185 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// tailBuffer is an io.Writer that keeps the last max bytes written to it.
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+	max int
+}
+
+func newTailBuffer(max int) *tailBuffer {
+	return &tailBuffer{max: max}
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.max; over > 0 {
+		t.buf = t.buf[over:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return string(t.buf)
+}
+
+var (
+	// e.g., "dockerfile parse error on line 2: unknown instruction: FORM"
+	parseErrorLineRegexp = regexp.MustCompile(`(?i)parse error on line (\d+)`)
+	// e.g., " > [stage 2/3] RUN exit 1:" in the error summary of the progress output
+	failedStepRegexp = regexp.MustCompile(`(?m)^\s*> \[(?:[^\]]*\s)?\d+/\d+\] (.+?):\s*$`)
+	// e.g., "secret foo: not found"
+	secretNotFoundRegexp = regexp.MustCompile(`secret ([^\s:]+):? not found`)
+	// e.g., `no SSH key "default" forwarded from the client`
+	sshNotForwardedRegexp = regexp.MustCompile(`no SSH key "?([^"\s]+)"? forwarded`)
+	// e.g., "granting entitlement network.host is not allowed by build daemon configuration"
+	entitlementRegexp = regexp.MustCompile(`entitlement ([a-z.]+) is not allowed`)
+	// e.g., `failed to compute cache key: failed to calculate checksum of ref ...: "/foo": not found`
+	contextNotFoundRegexp = regexp.MustCompile(`failed to compute cache key: .*"([^"]+)": not found`)
+	// e.g., "unknown flag: mount"
+	unknownFlagRegexp     = regexp.MustCompile(`unknown flag: (\S+)`)
+	syntaxDirectiveRegexp = regexp.MustCompile(`(?im)^#\s*syntax\s*=`)
+	heredocRegexp         = regexp.MustCompile(`<<-?["']?[A-Za-z_]`)
+)
+
+// buildDiagnosis is the result of diagnosing the output of a failed buildctl.
+type buildDiagnosis struct {
+	// line is the 1-based line number of the Dockerfile that caused the error, or 0 if unknown.
+	line  int
+	hints []string
+}
+
+// diagnoseBuildError maps the error in the output of buildctl back to the line of the Dockerfile,
+// and suggests the likely causes.
+func diagnoseBuildError(output string, dockerfile []byte) buildDiagnosis {
+	var d buildDiagnosis
+	if m := parseErrorLineRegexp.FindStringSubmatch(output); m != nil {
+		d.line, _ = strconv.Atoi(m[1])
+	} else if ms := failedStepRegexp.FindAllStringSubmatch(output, -1); ms != nil {
+		d.line = findInstruction(dockerfile, ms[len(ms)-1][1])
+	}
+
+	if m := secretNotFoundRegexp.FindStringSubmatch(output); m != nil {
+		d.hints = append(d.hints, fmt.Sprintf("the secret %q is not provided: specify it with `--secret id=%s,src=PATH` or `--secret id=%s,env=VAR`", m[1], m[1], m[1]))
+	}
+	if m := sshNotForwardedRegexp.FindStringSubmatch(output); m != nil {
+		d.hints = append(d.hints, fmt.Sprintf("the SSH agent %q is not forwarded: specify it with `--ssh %s` (uses $SSH_AUTH_SOCK) or `--ssh %s=PATH`", m[1], m[1], m[1]))
+	}
+	if m := entitlementRegexp.FindStringSubmatch(output); m != nil {
+		d.hints = append(d.hints, fmt.Sprintf("the entitlement %q has to be allowed with `--allow %s`, and buildkitd has to be started with `--allow-insecure-entitlement %s`", m[1], m[1], m[1]))
+	}
+	if m := contextNotFoundRegexp.FindStringSubmatch(output); m != nil {
+		d.hints = append(d.hints, fmt.Sprintf("%q does not exist in the build context, or is excluded by .dockerignore", m[1]))
+	}
+	if !syntaxDirectiveRegexp.Match(dockerfile) {
+		if m := unknownFlagRegexp.FindStringSubmatch(output); m != nil {
+			d.hints = append(d.hints, fmt.Sprintf("the flag %q may not be supported by the Dockerfile frontend of the BuildKit daemon: add `# syntax=docker/dockerfile:1` as the first line of the Dockerfile to use the latest frontend", m[1]))
+		} else if parseErrorLineRegexp.MatchString(output) && heredocRegexp.Match(dockerfile) {
+			d.hints = append(d.hints, "heredocs may not be supported by the Dockerfile frontend of the BuildKit daemon: add `# syntax=docker/dockerfile:1` as the first line of the Dockerfile to use the latest frontend")
+		}
+	}
+	return d
+}
+
+// findInstruction returns the 1-based line number of the instruction in the Dockerfile, or 0 if not found.
+// Line continuations and the spaces are ignored for comparison.
+func findInstruction(dockerfile []byte, instruction string) int {
+	want := strings.Fields(instruction)
+	if len(want) == 0 {
+		return 0
+	}
+	lines := strings.Split(string(dockerfile), "\n")
+	for i := 0; i < len(lines); i++ {
+		start := i
+		logical := lines[i]
+		for strings.HasSuffix(strings.TrimRight(logical, " \t\r"), "\\") && i+1 < len(lines) {
+			logical = strings.TrimSuffix(strings.TrimRight(logical, " \t\r"), "\\") + " " + lines[i+1]
+			i++
+		}
+		got := strings.Fields(logical)
+		if len(got) == len(want) && strings.EqualFold(got[0], want[0]) && slices.Equal(got[1:], want[1:]) {
+			return start + 1
+		}
+	}
+	return 0
+}
+
+// dockerfileFromBuildctlArgs returns the path of the Dockerfile passed to buildctl.
+func dockerfileFromBuildctlArgs(buildctlArgs []string) string {
+	var dir, file string
+	for _, a := range buildctlArgs {
+		if v, ok := strings.CutPrefix(a, "--local=dockerfile="); ok {
+			dir = v
+		} else if v, ok := strings.CutPrefix(a, "--opt=filename="); ok {
+			file = v
+		}
+	}
+	if dir == "" || file == "" {
+		return ""
+	}
+	return filepath.Join(dir, file)
+}
+
+// printBuildDiagnosis prints the diagnosis of the failed build, with the source snippet of the Dockerfile.
+// Nothing is printed if the error could not be diagnosed.
+func printBuildDiagnosis(w io.Writer, output string, buildctlArgs []string) {
+	var dockerfile []byte
+	path := dockerfileFromBuildctlArgs(buildctlArgs)
+	if path != "" {
+		dockerfile, _ = os.ReadFile(path)
+	}
+	d := diagnoseBuildError(output, dockerfile)
+	if d.line == 0 && len(d.hints) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	if lines := strings.Split(string(bytes.TrimRight(dockerfile, "\n")), "\n"); d.line > 0 && d.line <= len(lines) {
+		const snippetLines = 2
+		fmt.Fprintf(w, "%s:%d\n", filepath.Base(path), d.line)
+		fmt.Fprintln(w, "--------------------")
+		for i := max(d.line-snippetLines, 1); i <= min(d.line+snippetLines, len(lines)); i++ {
+			marker := "   "
+			if i == d.line {
+				marker = ">>>"
+			}
+			fmt.Fprintf(w, "%4d | %s %s\n", i, marker, strings.TrimRight(lines[i-1], "\r"))
+		}
+		fmt.Fprintln(w, "--------------------")
+	}
+	for _, h := range d.hints {
+		fmt.Fprintf(w, "hint: %s\n", h)
+	}
+}
@@ -0,0 +1,149 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+const testDockerfile = `FROM alpine
+RUN apk add --no-cache \
+    curl
+RUN --mount=type=secret,id=token cat /run/secrets/token
+COPY foo /foo
+`
+
+func TestDiagnoseBuildError(t *testing.T) {
+	testCases := []struct {
+		name       string
+		output     string
+		dockerfile string
+		line       int
+		hint       string
+	}{
+		{
+			name:       "parse error",
+			output:     "error: failed to solve: dockerfile parse error on line 3: unknown instruction: CURL",
+			dockerfile: testDockerfile,
+			line:       3,
+		},
+		{
+			name: "failed step with continuation",
+			output: `#6 ERROR: process "/bin/sh -c apk add --no-cache     curl" did not complete successfully: exit code: 1
+------
+ > [2/4] RUN apk add --no-cache     curl:
+------
+error: failed to solve: process "/bin/sh -c apk add --no-cache     curl" did not complete successfully: exit code: 1`,
+			dockerfile: testDockerfile,
+			line:       2,
+		},
+		{
+			name: "secret",
+			output: `------
+ > [stage-0 3/4] RUN --mount=type=secret,id=token cat /run/secrets/token:
+------
+error: failed to solve: failed to prepare ...: secret token: not found`,
+			dockerfile: testDockerfile,
+			line:       4,
+			hint:       "`--secret id=token,src=PATH`",
+		},
+		{
+			name:       "ssh",
+			output:     `error: failed to solve: no SSH key "default" forwarded from the client`,
+			dockerfile: testDockerfile,
+			hint:       "`--ssh default`",
+		},
+		{
+			name:       "entitlement",
+			output:     "error: failed to solve: granting entitlement network.host is not allowed by build daemon configuration",
+			dockerfile: testDockerfile,
+			hint:       "`--allow network.host`",
+		},
+		{
+			name:       "context",
+			output:     `error: failed to solve: failed to compute cache key: failed to calculate checksum of ref abc::def: "/foo": not found`,
+			dockerfile: testDockerfile,
+			hint:       `"/foo" does not exist in the build context`,
+		},
+		{
+			name:       "heredoc",
+			output:     "error: failed to solve: dockerfile parse error on line 3: unknown instruction: EOF",
+			dockerfile: "FROM alpine\nRUN <<EOF\nEOF\n",
+			line:       3,
+			hint:       "# syntax=docker/dockerfile:1",
+		},
+		{
+			name:       "heredoc with syntax directive",
+			output:     "error: failed to solve: dockerfile parse error on line 3: unknown instruction: EOF",
+			dockerfile: "# syntax=docker/dockerfile:1\nFROM alpine\nRUN <<EOF\nEOF\n",
+			line:       3,
+		},
+		{
+			name:       "unknown",
+			output:     "error: failed to solve: something went wrong",
+			dockerfile: testDockerfile,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := diagnoseBuildError(tc.output, []byte(tc.dockerfile))
+			assert.Equal(t, d.line, tc.line)
+			if tc.hint == "" {
+				assert.Equal(t, len(d.hints), 0, "%v", d.hints)
+			} else {
+				assert.Equal(t, len(d.hints), 1, "%v", d.hints)
+				assert.Assert(t, strings.Contains(d.hints[0], tc.hint), d.hints[0])
+			}
+		})
+	}
+}
+
+func TestPrintBuildDiagnosis(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte(testDockerfile), 0o644))
+	args := []string{"build", "--local=dockerfile=" + dir, "--opt=filename=Dockerfile"}
+
+	var b bytes.Buffer
+	printBuildDiagnosis(&b, "error: failed to solve: dockerfile parse error on line 3: unknown instruction: CURL", args)
+	assert.Equal(t, b.String(), `
+Dockerfile:3
+--------------------
+   1 |     FROM alpine
+   2 |     RUN apk add --no-cache \
+   3 | >>>     curl
+   4 |     RUN --mount=type=secret,id=token cat /run/secrets/token
+   5 |     COPY foo /foo
+--------------------
+`)
+
+	b.Reset()
+	printBuildDiagnosis(&b, "error: failed to solve: something went wrong", args)
+	assert.Equal(t, b.String(), "")
+}
+
+func TestTailBuffer(t *testing.T) {
+	tb := newTailBuffer(4)
+	tb.Write([]byte("foo"))
+	tb.Write([]byte("bar"))
+	assert.Equal(t, tb.String(), "obar")
+}
@@ -0,0 +1,172 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/containerd/platforms"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/buildkitutil"
+	"github.com/containerd/nerdctl/v2/pkg/buildkitutil/buildkitd"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+)
+
+const (
+	statusRunning     = "running"
+	statusUnreachable = "unreachable"
+	statusStopped     = "stopped"
+)
+
+type builderPrintable struct {
+	Name   string
+	Worker string
+	Status string
+	// Managed is false for a buildkitd that was not started by `nerdctl builder start`
+	Managed   bool
+	Address   string
+	Platforms string
+}
+
+// List lists the builders started by `nerdctl builder start`, along with the buildkitd
+// auto-detected as the default builder, if it was not started by nerdctl.
+func List(_ context.Context, options types.BuilderListOptions) error {
+	w := options.Stdout
+	var tmpl *template.Template
+	switch options.Format {
+	case "", "table":
+		w = tabwriter.NewWriter(w, 4, 8, 4, ' ', 0)
+		if !options.Quiet {
+			fmt.Fprintln(w, "NAME\tWORKER\tSTATUS\tADDRESS\tPLATFORMS")
+		}
+	case "raw":
+		return errors.New("unsupported format: \"raw\"")
+	default:
+		if options.Quiet {
+			return errors.New("format and quiet must not be specified together")
+		}
+		var err error
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+
+	pp, err := listBuilders(options.GOptions)
+	if err != nil {
+		return err
+	}
+	for _, p := range pp {
+		if tmpl != nil {
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, p); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w, b.String()); err != nil {
+				return err
+			}
+		} else if options.Quiet {
+			fmt.Fprintln(w, p.Name)
+		} else {
+			status := p.Status
+			if !p.Managed {
+				status += " (unmanaged)"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.Name, p.Worker, status, p.Address, p.Platforms)
+		}
+	}
+	if f, ok := w.(formatter.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func listBuilders(globalOptions types.GlobalCommandOptions) ([]builderPrintable, error) {
+	st, err := builderStore(globalOptions)
+	if err != nil {
+		return nil, err
+	}
+	var pp []builderPrintable
+	err = st.WithLock(func() error {
+		builders, err := st.List()
+		if err != nil {
+			return err
+		}
+		for _, b := range builders {
+			stateDir, err := st.StateDir(b.Name)
+			if err != nil {
+				return err
+			}
+			p := builderPrintable{
+				Name:    b.Name,
+				Worker:  b.Worker,
+				Status:  statusStopped,
+				Managed: true,
+				Address: b.Address,
+			}
+			if buildkitd.Pid(stateDir) != 0 {
+				p.Status = statusUnreachable
+				if workers, err := buildkitutil.GetWorkers(b.Address); err == nil {
+					p.Status = statusRunning
+					p.Platforms = formatPlatforms(workers)
+				}
+			}
+			pp = append(pp, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !slices.ContainsFunc(pp, func(p builderPrintable) bool { return p.Name == buildkitd.DefaultName }) {
+		if address := buildkitutil.LookupBuildkitHost(globalOptions.Namespace); address != "" {
+			p := builderPrintable{
+				Name:    buildkitd.DefaultName,
+				Status:  statusRunning,
+				Address: address,
+			}
+			if workers, err := buildkitutil.GetWorkers(address); err == nil {
+				p.Platforms = formatPlatforms(workers)
+				if len(workers) > 0 {
+					p.Worker = workers[0].Labels["org.mobyproject.buildkit.worker.executor"]
+				}
+			}
+			pp = append([]builderPrintable{p}, pp...)
+		}
+	}
+	return pp, nil
+}
+
+func formatPlatforms(workers []buildkitutil.WorkerInfo) string {
+	var ss []string
+	for _, w := range workers {
+		for _, p := range w.Platforms {
+			if s := platforms.Format(p); !slices.Contains(ss, s) {
+				ss = append(ss, s)
+			}
+		}
+	}
+	return strings.Join(ss, ", ")
+}
@@ -0,0 +1,118 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/containerd/errdefs"
+)
+
+// buildOutput is a parsed value of --output.
+type buildOutput struct {
+	// output is the value of `buildctl build --output`.
+	output string
+	// load is true if buildctl writes the image to the stdout, to be loaded into containerd.
+	load bool
+	// push is true if BuildKit pushes the image to a registry.
+	push bool
+	// stored is true if the image is stored in the image store, so that the extra tags can be created locally.
+	stored bool
+}
+
+// parseOutputOption converts the value of --output to the value of `buildctl build --output`,
+// in the same way as `docker buildx build`:
+//   - "-" is a tar archive written to the stdout
+//   - a value without "type=" is a local directory
+//   - type=registry is type=image,push=true
+//   - the destination paths are made absolute
+//
+// tags are set as the name of the image for the exporters that create an image.
+func parseOutputOption(s string, tags []string) (*buildOutput, error) {
+	if s == "-" {
+		s = "type=tar,dest=-"
+	} else if !strings.Contains(s, "type=") {
+		s = "type=local,dest=" + s
+	}
+	o, err := parseCSVOption(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse output %q: %w", s, err)
+	}
+
+	out := &buildOutput{}
+	isImage := false
+	switch typ := o.typ; typ {
+	case "local", "tar":
+		dest := o.attrs["dest"]
+		if dest == "" {
+			return nil, fmt.Errorf("output type %q requires \"dest\" (use \"dest=-\" for the stdout): %w", typ, errdefs.ErrInvalidArgument)
+		}
+		if dest != "-" {
+			if err := o.setAbs("dest"); err != nil {
+				return nil, err
+			}
+		}
+	case "oci", "docker":
+		isImage = true
+		switch dest := o.attrs["dest"]; dest {
+		case "":
+			out.load = true
+			out.stored = true
+		case "-":
+		default:
+			if err := o.setAbs("dest"); err != nil {
+				return nil, err
+			}
+		}
+	case "registry":
+		o.typ = "image"
+		o.set("push", "true")
+		fallthrough
+	case "image":
+		isImage = true
+		out.push = o.attrs["push"] == "true"
+		out.stored = !out.push
+		if out.push && !o.has("name") && len(tags) == 0 {
+			return nil, fmt.Errorf("output type %q requires an image name to push (specify -t or \"name\"): %w", typ, errdefs.ErrInvalidArgument)
+		}
+	case "cacheonly":
+	case "":
+		return nil, fmt.Errorf("output type is empty in %q: %w", s, errdefs.ErrInvalidArgument)
+	default:
+		return nil, fmt.Errorf("unknown output type %q (supported values: \"local\", \"tar\", \"oci\", \"docker\", \"image\", \"registry\", \"cacheonly\"): %w",
+			typ, errdefs.ErrInvalidArgument)
+	}
+
+	if isImage && !o.has("name") {
+		switch {
+		case len(tags) == 0:
+			if !o.has("dangling-name-prefix") {
+				o.set("dangling-name-prefix", "<none>")
+			}
+		case out.push:
+			// push all the tags, as they cannot be created locally afterward
+			o.set("name", strings.Join(tags, ","))
+		default:
+			// the other tags are created locally after the build
+			o.set("name", tags[0])
+		}
+	}
+
+	out.output, err = o.String()
+	return out, err
+}
@@ -0,0 +1,99 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseOutputOption(t *testing.T) {
+	wd, err := filepath.Abs(".")
+	assert.NilError(t, err)
+
+	testCases := []struct {
+		value    string
+		tags     []string
+		expected buildOutput
+		err      string
+	}{
+		{
+			value:    "out",
+			expected: buildOutput{output: "type=local,dest=" + filepath.Join(wd, "out")},
+		},
+		{
+			value:    "-",
+			expected: buildOutput{output: "type=tar,dest=-"},
+		},
+		{
+			value:    "type=tar,dest=out.tar",
+			tags:     []string{"docker.io/library/foo:latest"},
+			expected: buildOutput{output: "type=tar,dest=" + filepath.Join(wd, "out.tar")},
+		},
+		{
+			value: "type=local",
+			err:   `requires "dest"`,
+		},
+		{
+			value:    "type=oci",
+			tags:     []string{"docker.io/library/foo:latest", "docker.io/library/foo:v1"},
+			expected: buildOutput{output: "type=oci,name=docker.io/library/foo:latest", load: true, stored: true},
+		},
+		{
+			value:    "type=docker,dest=/tmp/out.tar",
+			expected: buildOutput{output: "type=docker,dest=/tmp/out.tar,dangling-name-prefix=<none>"},
+		},
+		{
+			value:    "type=image,unpack=true",
+			tags:     []string{"docker.io/library/foo:latest"},
+			expected: buildOutput{output: "type=image,unpack=true,name=docker.io/library/foo:latest", stored: true},
+		},
+		{
+			value:    "type=registry",
+			tags:     []string{"example.com/foo:latest", "example.com/foo:v1"},
+			expected: buildOutput{output: `type=image,push=true,"name=example.com/foo:latest,example.com/foo:v1"`, push: true},
+		},
+		{
+			value:    "type=registry,name=example.com/bar",
+			tags:     []string{"example.com/foo:latest"},
+			expected: buildOutput{output: "type=image,name=example.com/bar,push=true", push: true},
+		},
+		{
+			value: "type=registry",
+			err:   "requires an image name",
+		},
+		{
+			value: "type=foo",
+			err:   `unknown output type "foo"`,
+		},
+		{
+			value: "type=local,foo",
+			err:   "expected key=value",
+		},
+	}
+	for _, tc := range testCases {
+		got, err := parseOutputOption(tc.value, tc.tags)
+		if tc.err != "" {
+			assert.ErrorContains(t, err, tc.err, tc.value)
+			continue
+		}
+		assert.NilError(t, err, tc.value)
+		assert.Equal(t, *got, tc.expected, tc.value)
+	}
+}
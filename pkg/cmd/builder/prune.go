@@ -22,7 +22,11 @@ import (
 	"fmt"
 	"io"
 	"os/exec"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/containerd/errdefs"
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
@@ -40,6 +44,15 @@ func Prune(ctx context.Context, options types.BuilderPruneOptions) ([]buildkitut
 	if options.All {
 		buildctlArgs = append(buildctlArgs, "--all")
 	}
+	if options.KeepStorage > 0 {
+		// buildctl takes the size in MB
+		buildctlArgs = append(buildctlArgs, "--keep-storage="+strconv.FormatFloat(float64(options.KeepStorage)/1e6, 'f', -1, 64))
+	}
+	filterArgs, err := pruneFilterArgs(options.Filters)
+	if err != nil {
+		return nil, err
+	}
+	buildctlArgs = append(buildctlArgs, filterArgs...)
 	buildctlCmd := exec.Command(buildctlBinary, buildctlArgs...)
 	log.G(ctx).Debugf("running %v", buildctlCmd.Args)
 	buildctlCmd.Stderr = options.Stderr
@@ -68,3 +81,28 @@ func Prune(ctx context.Context, options types.BuilderPruneOptions) ([]buildkitut
 
 	return result, nil
 }
+
+// pruneFilterArgs converts the filters of `docker builder prune` to the flags of `buildctl prune`.
+// "until" is converted to --keep-duration, and the other filters are converted to the filter syntax of buildctl.
+func pruneFilterArgs(filters []string) ([]string, error) {
+	var args []string
+	for _, f := range filters {
+		k, v, ok := strings.Cut(f, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid filter %q, expected key=value: %w", f, errdefs.ErrInvalidArgument)
+		}
+		switch k {
+		case "until":
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter %q: %w", f, err)
+			}
+			args = append(args, "--keep-duration="+d.String())
+		case "id", "parent", "type", "description", "inuse", "shared", "private":
+			args = append(args, "--filter="+k+"=="+v)
+		default:
+			return nil, fmt.Errorf("unsupported filter %q: %w", f, errdefs.ErrInvalidArgument)
+		}
+	}
+	return args, nil
+}
@@ -0,0 +1,67 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/buildkitutil/buildkitd"
+)
+
+// Remove removes the builders, including their build cache.
+func Remove(_ context.Context, names []string, options types.BuilderRemoveOptions) error {
+	st, err := builderStore(options.GOptions)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, name := range names {
+		err := st.WithLock(func() error {
+			if _, err := st.Get(name); err != nil {
+				return err
+			}
+			stateDir, err := st.StateDir(name)
+			if err != nil {
+				return err
+			}
+			if buildkitd.Pid(stateDir) != 0 {
+				if !options.Force {
+					return fmt.Errorf("builder %q is running, stop it first or use --force: %w", name, errdefs.ErrFailedPrecondition)
+				}
+				if err := buildkitd.Stop(stateDir, 10*time.Second); err != nil {
+					return err
+				}
+			}
+			return st.Remove(name)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove builder %q: %w", name, err))
+			continue
+		}
+		fmt.Fprintln(options.Stdout, name)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
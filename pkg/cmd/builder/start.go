@@ -0,0 +1,174 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/buildkitutil"
+	"github.com/containerd/nerdctl/v2/pkg/buildkitutil/buildkitd"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+// Start starts the builder, defining it on the first start.
+func Start(ctx context.Context, options types.BuilderStartOptions) error {
+	if runtime.GOOS == "windows" {
+		return fmt.Errorf("`nerdctl builder start` is not supported on Windows: %w", errdefs.ErrNotImplemented)
+	}
+	if _, err := buildkitutil.BuildctlBinary(); err != nil {
+		return fmt.Errorf("buildctl needs to be installed, see https://github.com/moby/buildkit: %w", err)
+	}
+	st, err := builderStore(options.GOptions)
+	if err != nil {
+		return err
+	}
+	var (
+		b        *buildkitd.Builder
+		stateDir string
+		started  bool
+	)
+	err = st.WithLock(func() error {
+		b, err = st.Get(options.Name)
+		switch {
+		case err == nil:
+			if options.Worker != "" || options.Config != "" || len(options.BuildkitdFlags) > 0 {
+				return fmt.Errorf("builder %q already exists, remove it with `nerdctl builder rm` to change its configuration: %w",
+					options.Name, errdefs.ErrAlreadyExists)
+			}
+		case errors.Is(err, errdefs.ErrNotFound):
+			if b, err = newBuilder(options); err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+		if stateDir, err = st.StateDir(b.Name); err != nil {
+			return err
+		}
+		if buildkitd.Pid(stateDir) != 0 {
+			log.G(ctx).Debugf("builder %q is already running", b.Name)
+			return nil
+		}
+		if _, err := buildkitutil.GetWorkers(b.Address); err == nil {
+			return fmt.Errorf("another buildkitd is already listening on %s: %w", b.Address, errdefs.ErrAlreadyExists)
+		}
+		if err := st.Save(b); err != nil {
+			return err
+		}
+		started = true
+		return buildkitd.Start(stateDir, b)
+	})
+	if err != nil {
+		return err
+	}
+	if started {
+		if err := waitReady(ctx, stateDir, b.Address, options.Timeout); err != nil {
+			if stopErr := buildkitd.Stop(stateDir, 10*time.Second); stopErr != nil {
+				log.G(ctx).WithError(stopErr).Warnf("failed to stop builder %q", b.Name)
+			}
+			return fmt.Errorf("builder %q failed to start (see %s): %w", b.Name, buildkitd.LogPath(stateDir), err)
+		}
+	}
+	_, err = fmt.Fprintln(options.Stdout, b.Name)
+	return err
+}
+
+func newBuilder(options types.BuilderStartOptions) (*buildkitd.Builder, error) {
+	address, err := buildkitutil.ManagedBuildkitHost(options.GOptions.Namespace, options.Name)
+	if err != nil {
+		return nil, err
+	}
+	b := &buildkitd.Builder{
+		Name:              options.Name,
+		Namespace:         options.GOptions.Namespace,
+		Worker:            options.Worker,
+		Address:           address,
+		Rootless:          rootlessutil.IsRootless(),
+		ContainerdAddress: options.GOptions.Address,
+		Snapshotter:       options.GOptions.Snapshotter,
+		Flags:             options.BuildkitdFlags,
+	}
+	if b.Worker == "" {
+		b.Worker = buildkitd.WorkerContainerd
+	}
+	if options.Config != "" {
+		if b.Config, err = filepath.Abs(options.Config); err != nil {
+			return nil, err
+		}
+	}
+	return b, b.Validate()
+}
+
+// waitReady waits for buildkitd to respond on address, failing early if the supervisor has exited.
+func waitReady(ctx context.Context, stateDir, address string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		if _, err := buildkitutil.GetWorkers(address); err == nil {
+			return nil
+		}
+		if buildkitd.Pid(stateDir) == 0 {
+			return errors.New("the supervisor of buildkitd has exited")
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("buildkitd did not become ready on %s in %s", address, timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+// BuildkitHost returns the address of the builder started by `nerdctl builder start`.
+// When the default builder was not started by nerdctl, its address is auto-detected.
+func BuildkitHost(globalOptions types.GlobalCommandOptions, name string) (string, error) {
+	st, err := builderStore(globalOptions)
+	if err != nil {
+		return "", err
+	}
+	var b *buildkitd.Builder
+	err = st.WithLock(func() error {
+		b, err = st.Get(name)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, errdefs.ErrNotFound) && name == buildkitd.DefaultName {
+			return buildkitutil.GetBuildkitHost(globalOptions.Namespace)
+		}
+		return "", err
+	}
+	return b.Address, nil
+}
+
+func builderStore(globalOptions types.GlobalCommandOptions) (*buildkitd.Store, error) {
+	dataStore, err := clientutil.DataStore(globalOptions.DataRoot, globalOptions.Address)
+	if err != nil {
+		return nil, err
+	}
+	return buildkitd.NewStore(dataStore, globalOptions.Namespace)
+}
@@ -0,0 +1,56 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/buildkitutil/buildkitd"
+)
+
+// Stop stops the builders. Stopping a builder that is not running is a no-op.
+func Stop(_ context.Context, names []string, options types.BuilderStopOptions) error {
+	st, err := builderStore(options.GOptions)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, name := range names {
+		err := st.WithLock(func() error {
+			if _, err := st.Get(name); err != nil {
+				return err
+			}
+			stateDir, err := st.StateDir(name)
+			if err != nil {
+				return err
+			}
+			return buildkitd.Stop(stateDir, options.Timeout)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop builder %q: %w", name, err))
+			continue
+		}
+		fmt.Fprintln(options.Stdout, name)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
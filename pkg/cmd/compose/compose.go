@@ -21,12 +21,14 @@ import (
 	"errors"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
 	"github.com/containerd/platforms"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
@@ -34,10 +36,12 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/composer"
 	"github.com/containerd/nerdctl/v2/pkg/composer/serviceparser"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
 	"github.com/containerd/nerdctl/v2/pkg/ipfs"
 	"github.com/containerd/nerdctl/v2/pkg/netutil"
 	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
 	"github.com/containerd/nerdctl/v2/pkg/signutil"
+	"github.com/containerd/nerdctl/v2/pkg/sshutil"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
@@ -49,22 +53,42 @@ func New(client *containerd.Client, globalOptions types.GlobalCommandOptions, op
 	if err := composer.Lock(globalOptions.DataRoot, globalOptions.Address); err != nil {
 		return nil, err
 	}
-
-	cniEnv, err := netutil.NewCNIEnv(globalOptions.CNIPath, globalOptions.CNINetConfPath, netutil.WithNamespace(globalOptions.Namespace), netutil.WithDefaultNetwork(globalOptions.BridgeIP))
-	if err != nil {
-		return nil, err
-	}
-	networkConfigs, err := cniEnv.NetworkList()
-	if err != nil {
-		return nil, err
-	}
-	options.NetworkExists = func(netName string) (bool, error) {
-		for _, f := range networkConfigs {
-			if f.Name == netName {
-				return true, nil
+	options.Stdout = stdout
+	options.Stderr = stderr
+	options.Offline = globalOptions.Offline
+
+	if sshutil.IsSSH(globalOptions.Address) {
+		// The networks and the volumes are managed by nerdctl on the remote host
+		options.NetworkExists = func(netName string) (bool, error) {
+			return remoteObjectExists(options, "network", netName)
+		}
+		options.VolumeExists = func(volName string) (bool, error) {
+			return remoteObjectExists(options, "volume", volName)
+		}
+	} else {
+		cniEnv, err := netutil.NewCNIEnv(globalOptions.CNIPath, globalOptions.CNINetConfPath, netutil.WithNamespace(globalOptions.Namespace), netutil.WithDefaultNetwork(globalOptions.BridgeIP))
+		if err != nil {
+			return nil, err
+		}
+		networkConfigs, err := cniEnv.NetworkList()
+		if err != nil {
+			return nil, err
+		}
+		options.NetworkExists = func(netName string) (bool, error) {
+			for _, f := range networkConfigs {
+				if f.Name == netName {
+					return true, nil
+				}
 			}
+			return false, nil
 		}
-		return false, nil
+
+		volStore, err := volume.Store(globalOptions.Namespace, globalOptions.DataRoot, globalOptions.Address)
+		if err != nil {
+			return nil, err
+		}
+		// FIXME: this is racy. See note in up_volume.go
+		options.VolumeExists = volStore.Exists
 	}
 
 	options.NetworkInUse = func(ctx context.Context, netName string) (bool, error) {
@@ -80,13 +104,6 @@ func New(client *containerd.Client, globalOptions types.GlobalCommandOptions, op
 		return false, nil
 	}
 
-	volStore, err := volume.Store(globalOptions.Namespace, globalOptions.DataRoot, globalOptions.Address)
-	if err != nil {
-		return nil, err
-	}
-	// FIXME: this is racy. See note in up_volume.go
-	options.VolumeExists = volStore.Exists
-
 	options.ImageExists = func(ctx context.Context, rawRef string) (bool, error) {
 		parsedReference, err := referenceutil.Parse(rawRef)
 		if err != nil {
@@ -115,6 +132,7 @@ func New(client *containerd.Client, globalOptions types.GlobalCommandOptions, op
 		imgPullOpts := types.ImagePullOptions{
 			GOptions:        globalOptions,
 			OCISpecPlatform: ocispecPlatforms,
+			RequirePlatform: platform != "",
 			Unpack:          nil,
 			Mode:            pullMode,
 			Quiet:           quiet,
@@ -146,7 +164,7 @@ func New(client *containerd.Client, globalOptions types.GlobalCommandOptions, op
 		}
 
 		imageVerifyOptions := imageVerifyOptionsFromCompose(ps)
-		ref, err := signutil.Verify(ctx, imageName, globalOptions.HostsDir, globalOptions.Experimental, imageVerifyOptions)
+		ref, err := signutil.Verify(ctx, imageName, globalOptions.HostsDir, globalOptions.Experimental, globalOptions.Offline, imageVerifyOptions)
 		if err != nil {
 			return err
 		}
@@ -155,6 +173,11 @@ func New(client *containerd.Client, globalOptions types.GlobalCommandOptions, op
 		return err
 	}
 
+	options.ResolveDigest = func(ctx context.Context, imageName string) (string, error) {
+		return imgutil.ResolveDigest(ctx, imageName, globalOptions.IsInsecureRegistry, globalOptions.HostsDir,
+			dockerconfigresolver.WithOffline(globalOptions.Offline), dockerconfigresolver.WithRegistryConfigs(globalOptions.Registries), dockerconfigresolver.WithSystemKeychain(globalOptions.Credentials.Keychain))
+	}
+
 	return composer.New(options, client)
 }
 
@@ -184,3 +207,18 @@ func imageVerifyOptionsFromCompose(ps *serviceparser.Service) types.ImageVerifyO
 	}
 	return opt
 }
+
+// remoteObjectExists checks whether the network or the volume exists on the remote host of the SSH address,
+// with `nerdctl (network|volume) inspect`.
+func remoteObjectExists(options composer.Options, kind, name string) (bool, error) {
+	cmd := exec.Command(options.NerdctlCmd, append(options.NerdctlArgs, kind, "inspect", name)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			log.L.Debugf("%s %q does not exist on the remote host: %s", kind, name, out)
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
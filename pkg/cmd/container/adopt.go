@@ -0,0 +1,230 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/containerinspector"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/namestore"
+	"github.com/containerd/nerdctl/v2/pkg/platformutil"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
+)
+
+// Adopt makes a container created by another tool (e.g., ctr or CRI) manageable by nerdctl,
+// by synthesizing the nerdctl labels and the state directory of the container.
+//
+// The network of the container is recorded as "host" or "none", as the network namespace
+// is still owned by the tool that created it; the addresses are discovered from the netns
+// of the running task. The log driver, if specified, takes effect on the next `nerdctl start`.
+func Adopt(ctx context.Context, client *containerd.Client, req string, options types.ContainerAdoptOptions) error {
+	var c containerd.Container
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+			}
+			c = found.Container
+			return nil
+		},
+	}
+	if n, err := walker.Walk(ctx, req); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("no such container %s", req)
+	}
+
+	l, err := c.Labels(ctx)
+	if err != nil {
+		return err
+	}
+	if l[labels.Namespace] != "" {
+		return fmt.Errorf("container %s is already managed by nerdctl", c.ID())
+	}
+	info, err := c.Info(ctx, containerd.WithoutRefreshedMetadata)
+	if err != nil {
+		return err
+	}
+	spec, err := c.Spec(ctx)
+	if err != nil {
+		return err
+	}
+
+	var netNS *native.NetNS
+	if hasNetworkNamespace(spec) {
+		if task, err := c.Task(ctx, nil); err == nil {
+			if status, err := task.Status(ctx); err == nil && status.Status == containerd.Running {
+				netNS, err = containerinspector.InspectNetNS(ctx, int(task.Pid()))
+				if err != nil {
+					log.G(ctx).WithError(err).Warnf("failed to inspect the network namespace of container %s", c.ID())
+				}
+			}
+		}
+	}
+
+	name := options.Name
+	if name == "" {
+		parsedReference, err := referenceutil.Parse(info.Image)
+		// Ignore cases where the image is "" (e.g., containers created with `ctr run --rootfs`)
+		if err != nil && info.Image != "" {
+			return err
+		}
+		name = parsedReference.SuggestContainerName(c.ID())
+	}
+
+	ns := options.GOptions.Namespace
+	dataStore, err := clientutil.DataStore(options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	stateDir, err := containerutil.ContainerStateDirPath(ns, dataStore, c.ID())
+	if err != nil {
+		return err
+	}
+	m, err := adoptLabels(ns, name, stateDir, spec, netNS)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(stateDir, 0700); err != nil {
+		return err
+	}
+	nameStore, err := namestore.New(dataStore, ns)
+	if err != nil {
+		return err
+	}
+	if err := nameStore.Acquire(name, c.ID()); err != nil {
+		return err
+	}
+	if err := adoptContainer(ctx, c, m, dataStore, ns, options); err != nil {
+		if relErr := nameStore.Release(name, c.ID()); relErr != nil {
+			log.G(ctx).WithError(relErr).Warnf("failed to release container name %s", name)
+		}
+		return errors.Join(err, os.RemoveAll(stateDir))
+	}
+	_, err = fmt.Fprintln(options.Stdout, c.ID())
+	return err
+}
+
+func adoptContainer(ctx context.Context, c containerd.Container, m map[string]string, dataStore, ns string, options types.ContainerAdoptOptions) error {
+	if options.LogDriver != "" {
+		logConfig, err := generateLogConfig(dataStore, c.ID(), options.LogDriver, options.LogOpt, ns, options.GOptions.Address)
+		if err != nil {
+			return err
+		}
+		if logConfig.LogURI != "" {
+			m[labels.LogURI] = logConfig.LogURI
+			logConfigJSON, err := json.Marshal(logConfig)
+			if err != nil {
+				return err
+			}
+			m[labels.LogConfig] = string(logConfigJSON)
+		}
+	}
+	return c.Update(ctx, containerd.UpdateContainerOpts(containerd.WithAdditionalContainerLabels(m)))
+}
+
+// adoptLabels returns the nerdctl labels synthesized for an adopted container.
+// netNS is nil when the container has no running task, or does not have its own network namespace.
+func adoptLabels(ns, name, stateDir string, spec *specs.Spec, netNS *native.NetNS) (map[string]string, error) {
+	networks := []string{"none"}
+	if !hasNetworkNamespace(spec) {
+		networks = []string{"host"}
+	}
+	networksJSON, err := json.Marshal(networks)
+	if err != nil {
+		return nil, err
+	}
+	platform, err := platformutil.NormalizeString("")
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]string{
+		labels.Namespace:  ns,
+		labels.Name:       name,
+		labels.Hostname:   spec.Hostname,
+		labels.ExtraHosts: "null",
+		labels.StateDir:   stateDir,
+		labels.Networks:   string(networksJSON),
+		labels.Platform:   platform,
+		labels.Adopted:    "true",
+	}
+	ip, ip6 := primaryAddresses(netNS)
+	if ip != "" {
+		m[labels.IPAddress] = ip
+	}
+	if ip6 != "" {
+		m[labels.IP6Address] = ip6
+	}
+	return m, nil
+}
+
+// hasNetworkNamespace returns whether the container does not share the network namespace of the host.
+func hasNetworkNamespace(spec *specs.Spec) bool {
+	if spec.Linux == nil {
+		return spec.Windows != nil && spec.Windows.Network != nil
+	}
+	for _, n := range spec.Linux.Namespaces {
+		if n.Type == specs.NetworkNamespace {
+			return true
+		}
+	}
+	return false
+}
+
+// primaryAddresses returns the global unicast IPv4 and IPv6 addresses of the primary interface.
+func primaryAddresses(netNS *native.NetNS) (ip, ip6 string) {
+	if netNS == nil {
+		return "", ""
+	}
+	for _, intf := range netNS.Interfaces {
+		if intf.Index != netNS.PrimaryInterface {
+			continue
+		}
+		for _, a := range intf.Addrs {
+			addr, _, err := net.ParseCIDR(a)
+			if err != nil || !addr.IsGlobalUnicast() {
+				continue
+			}
+			if addr.To4() != nil {
+				if ip == "" {
+					ip = addr.String()
+				}
+			} else if ip6 == "" {
+				ip6 = addr.String()
+			}
+		}
+	}
+	return ip, ip6
+}
@@ -0,0 +1,65 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"net"
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+func TestAdoptLabels(t *testing.T) {
+	netNS := &native.NetNS{
+		PrimaryInterface: 2,
+		Interfaces: []native.NetInterface{
+			{Interface: net.Interface{Index: 1, Name: "lo"}, Addrs: []string{"127.0.0.1/8", "::1/128"}},
+			{Interface: net.Interface{Index: 2, Name: "eth0"}, Addrs: []string{"fe80::1/64", "10.4.0.2/24", "fd00::2/64"}},
+		},
+	}
+	isolated := &specs.Spec{
+		Hostname: "foo",
+		Linux: &specs.Linux{
+			Namespaces: []specs.LinuxNamespace{{Type: specs.PIDNamespace}, {Type: specs.NetworkNamespace}},
+		},
+	}
+	m, err := adoptLabels("default", "foo-1", "/state/foo", isolated, netNS)
+	assert.NilError(t, err)
+	assert.Equal(t, m[labels.Namespace], "default")
+	assert.Equal(t, m[labels.Name], "foo-1")
+	assert.Equal(t, m[labels.Hostname], "foo")
+	assert.Equal(t, m[labels.StateDir], "/state/foo")
+	assert.Equal(t, m[labels.Networks], `["none"]`)
+	assert.Equal(t, m[labels.IPAddress], "10.4.0.2")
+	assert.Equal(t, m[labels.IP6Address], "fd00::2")
+	assert.Equal(t, m[labels.Adopted], "true")
+
+	host := &specs.Spec{
+		Linux: &specs.Linux{
+			Namespaces: []specs.LinuxNamespace{{Type: specs.PIDNamespace}},
+		},
+	}
+	m, err = adoptLabels("default", "bar", "/state/bar", host, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, m[labels.Networks], `["host"]`)
+	_, ok := m[labels.IPAddress]
+	assert.Assert(t, !ok)
+}
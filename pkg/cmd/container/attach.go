@@ -60,6 +60,9 @@ func Attach(ctx context.Context, client *containerd.Client, req string, options
 	} else if n > 1 {
 		return fmt.Errorf("more than one containers are found given the string: %s", req)
 	}
+	if err := containerutil.EnsureNotPaused(ctx, container, "attach"); err != nil {
+		return err
+	}
 
 	defer func() {
 		containerLabels, err := container.Labels(ctx)
@@ -134,9 +137,11 @@ func Attach(ctx context.Context, client *containerd.Client, req string, options
 		if err := consoleutil.HandleConsoleResize(ctx, task, con); err != nil {
 			log.G(ctx).WithError(err).Error("console resize")
 		}
+	} else if options.SigProxy {
+		// Like Docker, the signals are not proxied to a TTY, which receives ^C etc. as input.
+		sigC := signalutil.ForwardAllSignals(ctx, task)
+		defer signalutil.StopCatch(sigC)
 	}
-	sigC := signalutil.ForwardAllSignals(ctx, task)
-	defer signalutil.StopCatch(sigC)
 
 	// Wait for the container to exit.
 	statusC, err := task.Wait(ctx)
@@ -0,0 +1,436 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+	"github.com/containerd/platforms"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+// Policies of the io.containers.autoupdate label.
+const (
+	// AutoUpdatePolicyRegistry updates the container when its image tag points to a new digest in the registry
+	AutoUpdatePolicyRegistry = "registry"
+	// AutoUpdatePolicyLocal updates the container when its image tag points to a new local image
+	AutoUpdatePolicyLocal = "local"
+	// AutoUpdatePolicyDisabled is the same as not setting the label
+	AutoUpdatePolicyDisabled = "disabled"
+)
+
+// Values of the UPDATED column of `nerdctl container auto-update`.
+const (
+	AutoUpdateStatusFalse      = "false"
+	AutoUpdateStatusTrue       = "true"
+	AutoUpdateStatusPending    = "pending"
+	AutoUpdateStatusFailed     = "failed"
+	AutoUpdateStatusRolledBack = "rolled back"
+)
+
+const autoUpdateBackupSuffix = "-autoupdate-backup"
+
+// autoUpdateRecord is stored in the labels.AutoUpdate label, to re-create the container with the same command line.
+type autoUpdateRecord struct {
+	// Flags are the flags of `nerdctl create` or `nerdctl run`, in the "--key=value" form
+	Flags []string `json:"flags,omitempty"`
+	// Args are the command and the arguments after the image
+	Args []string `json:"args,omitempty"`
+	// Dir is the working directory of `nerdctl create`, for the relative paths in Flags
+	Dir string `json:"dir,omitempty"`
+	// ImageDigest is the digest of the image the container was created from
+	ImageDigest string `json:"imageDigest"`
+}
+
+type autoUpdateEntry struct {
+	Container string
+	Image     string
+	Policy    string
+	Updated   string
+	Error     string `json:",omitempty"`
+}
+
+// autoUpdatePolicy returns the auto-update policy of the container labels, falling back to the image labels.
+func autoUpdatePolicy(containerLabels, imageLabels map[string]string) (string, error) {
+	policy, ok := containerLabels[labels.AutoUpdatePolicy]
+	if !ok {
+		policy = imageLabels[labels.AutoUpdatePolicy]
+	}
+	switch policy {
+	case "", AutoUpdatePolicyDisabled:
+		return "", nil
+	case AutoUpdatePolicyRegistry, "image":
+		// "image" is the former name of "registry" in Podman
+		return AutoUpdatePolicyRegistry, nil
+	case AutoUpdatePolicyLocal:
+		return AutoUpdatePolicyLocal, nil
+	default:
+		return "", fmt.Errorf("unknown %s policy %q, must be %q, %q or %q",
+			labels.AutoUpdatePolicy, policy, AutoUpdatePolicyRegistry, AutoUpdatePolicyLocal, AutoUpdatePolicyDisabled)
+	}
+}
+
+// newAutoUpdateRecord returns the record of the command line for the containers with an auto-update policy,
+// or nil for the other containers.
+func newAutoUpdateRecord(options types.ContainerCreateOptions, args []string, ensuredImage *imgutil.EnsuredImage) (*autoUpdateRecord, error) {
+	containerLabels, err := readKVStringsMapfFromLabel(options.Label, options.LabelFile)
+	if err != nil {
+		return nil, err
+	}
+	var imageLabels map[string]string
+	if ensuredImage != nil {
+		imageLabels = ensuredImage.ImageConfig.Labels
+	}
+	policy, err := autoUpdatePolicy(containerLabels, imageLabels)
+	if err != nil || policy == "" {
+		return nil, err
+	}
+	if ensuredImage == nil {
+		return nil, fmt.Errorf("label %s cannot be used with --rootfs", labels.AutoUpdatePolicy)
+	}
+	if options.Rm {
+		// The container would be removed when it is stopped for the update
+		return nil, fmt.Errorf("label %s cannot be used with --rm", labels.AutoUpdatePolicy)
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	return &autoUpdateRecord{
+		Flags:       options.CreateFlags,
+		Args:        args[1:],
+		Dir:         dir,
+		ImageDigest: ensuredImage.Image.Target().Digest.String(),
+	}, nil
+}
+
+// createArgs returns the arguments of `nerdctl create` to re-create the container with the name and the image.
+func (r *autoUpdateRecord) createArgs(name, image string) []string {
+	args := []string{"create"}
+	for _, f := range r.Flags {
+		if !strings.HasPrefix(f, "--name=") {
+			args = append(args, f)
+		}
+	}
+	args = append(args, "--name="+name, image)
+	return append(args, r.Args...)
+}
+
+// AutoUpdate re-creates the running containers that have an auto-update policy, when their image was updated.
+// When Rollback is set, a new container that does not keep running for HealthWindow is replaced by the previous one.
+func AutoUpdate(ctx context.Context, client *containerd.Client, options types.ContainerAutoUpdateOptions) error {
+	var tmpl *template.Template
+	switch options.Format {
+	case "", "table":
+	case "raw", "wide":
+		return errors.New("unsupported format: \"raw\" and \"wide\"")
+	default:
+		var err error
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+
+	containers, err := client.Containers(ctx)
+	if err != nil {
+		return err
+	}
+	var (
+		entries []autoUpdateEntry
+		errs    []error
+	)
+	for _, c := range containers {
+		info, err := c.Info(ctx, containerd.WithoutRefreshedMetadata)
+		if err != nil {
+			// The container may have been removed in the meantime
+			continue
+		}
+		recordJSON, ok := info.Labels[labels.AutoUpdate]
+		if !ok {
+			continue
+		}
+		if status, err := containerutil.ContainerStatus(ctx, c); err != nil || status.Status != containerd.Running {
+			continue
+		}
+		entry := autoUpdateEntry{
+			Container: info.Labels[labels.Name],
+			Image:     info.Image,
+			Updated:   AutoUpdateStatusFalse,
+		}
+		if entry.Container == "" {
+			entry.Container = info.ID
+		}
+		if err := autoUpdateContainer(ctx, client, c, info.Labels, recordJSON, &entry, options); err != nil {
+			entry.Error = err.Error()
+			errs = append(errs, fmt.Errorf("failed to auto-update container %s: %w", entry.Container, err))
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := printAutoUpdate(entries, tmpl, options); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+func autoUpdateContainer(ctx context.Context, client *containerd.Client, c containerd.Container, containerLabels map[string]string,
+	recordJSON string, entry *autoUpdateEntry, options types.ContainerAutoUpdateOptions) error {
+	var record autoUpdateRecord
+	if err := json.Unmarshal([]byte(recordJSON), &record); err != nil {
+		return fmt.Errorf("failed to parse label %s: %w", labels.AutoUpdate, err)
+	}
+	// The labels of the image were copied to the container on creation
+	policy, err := autoUpdatePolicy(containerLabels, nil)
+	if err != nil || policy == "" {
+		return err
+	}
+	entry.Policy = policy
+
+	var latest string
+	switch policy {
+	case AutoUpdatePolicyRegistry:
+		latest, err = imgutil.ResolveDigest(ctx, entry.Image, options.GOptions.IsInsecureRegistry, options.GOptions.HostsDir,
+			dockerconfigresolver.WithOffline(options.GOptions.Offline), dockerconfigresolver.WithRegistryConfigs(options.GOptions.Registries), dockerconfigresolver.WithSystemKeychain(options.GOptions.Credentials.Keychain))
+	case AutoUpdatePolicyLocal:
+		var img containerd.Image
+		img, err = client.GetImage(ctx, entry.Image)
+		if err == nil {
+			latest = img.Target().Digest.String()
+		}
+	}
+	if err != nil {
+		entry.Updated = AutoUpdateStatusFailed
+		return err
+	}
+	if latest == record.ImageDigest {
+		return nil
+	}
+	if options.DryRun {
+		entry.Updated = AutoUpdateStatusPending
+		return nil
+	}
+
+	if policy == AutoUpdatePolicyRegistry {
+		if err := pullForContainer(ctx, client, entry.Image, containerLabels, options); err != nil {
+			entry.Updated = AutoUpdateStatusFailed
+			return err
+		}
+	}
+
+	err = recreateContainer(ctx, client, c, entry.Container, &record, options)
+	switch {
+	case err == nil:
+		entry.Updated = AutoUpdateStatusTrue
+	case errors.Is(err, errRolledBack):
+		entry.Updated = AutoUpdateStatusRolledBack
+	default:
+		entry.Updated = AutoUpdateStatusFailed
+	}
+	return err
+}
+
+func pullForContainer(ctx context.Context, client *containerd.Client, ref string, containerLabels map[string]string, options types.ContainerAutoUpdateOptions) error {
+	platform := platforms.DefaultSpec()
+	if p := containerLabels[labels.Platform]; p != "" {
+		var err error
+		platform, err = platforms.Parse(p)
+		if err != nil {
+			return err
+		}
+	}
+	return image.Pull(ctx, client, ref, types.ImagePullOptions{
+		Stdout:          options.Stdout,
+		Stderr:          options.Stderr,
+		GOptions:        options.GOptions,
+		VerifyOptions:   types.ImageVerifyOptions{Provider: "none"},
+		OCISpecPlatform: []ocispec.Platform{platform},
+		Mode:            "always",
+		Quiet:           true,
+	})
+}
+
+// errRolledBack is returned when the new container was replaced by the previous one.
+var errRolledBack = errors.New("the new container did not stay running, rolled back to the previous container")
+
+// recreateContainer replaces the container by a new one created with the same command line.
+// The previous container is kept stopped under a backup name until the new container passes the health window.
+func recreateContainer(ctx context.Context, client *containerd.Client, old containerd.Container, name string,
+	record *autoUpdateRecord, options types.ContainerAutoUpdateOptions) error {
+	backupName := name + autoUpdateBackupSuffix
+	renameOptions := types.ContainerRenameOptions{GOptions: options.GOptions}
+	globalOptions := options.GOptions
+
+	log.G(ctx).Infof("Re-creating container %s", name)
+	if err := containerutil.Stop(ctx, old, nil, ""); err != nil {
+		return err
+	}
+	if err := Rename(ctx, client, old.ID(), backupName, renameOptions); err != nil {
+		return restartContainer(ctx, client, old, err)
+	}
+
+	restore := func(cause error) error {
+		if err := Rename(ctx, client, old.ID(), name, renameOptions); err != nil {
+			return errors.Join(cause, fmt.Errorf("failed to rename container %s back to %s: %w", backupName, name, err))
+		}
+		return restartContainer(ctx, client, old, cause)
+	}
+
+	info, err := old.Info(ctx, containerd.WithoutRefreshedMetadata)
+	if err != nil {
+		return restore(err)
+	}
+	newID, err := createWithNerdctl(ctx, record, name, info.Image, options)
+	if err != nil {
+		return restore(err)
+	}
+	newContainer, err := client.LoadContainer(ctx, newID)
+	if err != nil {
+		return restore(err)
+	}
+
+	healthErr := containerutil.Start(ctx, newContainer, false, false, client, "")
+	if healthErr == nil {
+		healthErr = waitHealthy(ctx, newContainer, options.HealthWindow)
+	}
+	if healthErr != nil {
+		if !options.Rollback {
+			log.G(ctx).WithError(healthErr).Warnf("Container %s is unhealthy, the previous container is kept as %s", name, backupName)
+			return healthErr
+		}
+		log.G(ctx).WithError(healthErr).Warnf("Container %s is unhealthy, rolling back", name)
+		if err := RemoveContainer(ctx, newContainer, globalOptions, true, true, client); err != nil {
+			return errors.Join(healthErr, err)
+		}
+		if err := restore(nil); err != nil {
+			return errors.Join(healthErr, err)
+		}
+		return fmt.Errorf("%w: %w", errRolledBack, healthErr)
+	}
+
+	return RemoveContainer(ctx, old, globalOptions, true, false, client)
+}
+
+// restartContainer starts the previous container again after a failed update, and returns cause.
+func restartContainer(ctx context.Context, client *containerd.Client, c containerd.Container, cause error) error {
+	if err := containerutil.Start(ctx, c, false, false, client, ""); err != nil {
+		return errors.Join(cause, fmt.Errorf("failed to restart the previous container: %w", err))
+	}
+	return cause
+}
+
+// createWithNerdctl runs `nerdctl create` with the recorded command line, and returns the ID of the new container.
+func createWithNerdctl(ctx context.Context, record *autoUpdateRecord, name, image string, options types.ContainerAutoUpdateOptions) (string, error) {
+	args := append(append([]string{}, options.NerdctlArgs...), record.createArgs(name, image)...)
+	cmd := exec.CommandContext(ctx, options.NerdctlCmd, args...)
+	cmd.Dir = record.Dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	log.G(ctx).Debugf("Running %v", cmd.Args)
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to create the new container: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	return strings.TrimSpace(lines[len(lines)-1]), nil
+}
+
+// waitHealthy returns an error when the task of the container stops or restarts within the window.
+func waitHealthy(ctx context.Context, c containerd.Container, window time.Duration) error {
+	task, err := c.Task(ctx, nil)
+	if err != nil {
+		return err
+	}
+	pid := task.Pid()
+	deadline := time.Now().Add(window)
+	for {
+		task, err := c.Task(ctx, nil)
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				return errors.New("the container exited")
+			}
+			return err
+		}
+		st, err := task.Status(ctx)
+		if err != nil {
+			return err
+		}
+		if st.Status != containerd.Running {
+			return fmt.Errorf("the container is %s (exit code %d)", st.Status, st.ExitStatus)
+		}
+		if task.Pid() != pid {
+			return errors.New("the container was restarted")
+		}
+		if !time.Now().Before(deadline) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(min(time.Second, time.Until(deadline))):
+		}
+	}
+}
+
+func printAutoUpdate(entries []autoUpdateEntry, tmpl *template.Template, options types.ContainerAutoUpdateOptions) error {
+	w := options.Stdout
+	if tmpl == nil {
+		w = tabwriter.NewWriter(w, 4, 8, 4, ' ', 0)
+		fmt.Fprintln(w, "CONTAINER\tIMAGE\tPOLICY\tUPDATED")
+	}
+	for _, e := range entries {
+		if tmpl != nil {
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, e); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w, b.String()); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Container, e.Image, e.Policy, e.Updated); err != nil {
+			return err
+		}
+	}
+	if f, ok := w.(formatter.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
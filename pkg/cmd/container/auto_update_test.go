@@ -0,0 +1,58 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+func TestAutoUpdatePolicy(t *testing.T) {
+	policy, err := autoUpdatePolicy(nil, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, policy, "")
+
+	policy, err = autoUpdatePolicy(nil, map[string]string{labels.AutoUpdatePolicy: "image"})
+	assert.NilError(t, err)
+	assert.Equal(t, policy, AutoUpdatePolicyRegistry)
+
+	// The container label overrides the image label
+	policy, err = autoUpdatePolicy(map[string]string{labels.AutoUpdatePolicy: "local"}, map[string]string{labels.AutoUpdatePolicy: "registry"})
+	assert.NilError(t, err)
+	assert.Equal(t, policy, AutoUpdatePolicyLocal)
+
+	policy, err = autoUpdatePolicy(map[string]string{labels.AutoUpdatePolicy: "disabled"}, map[string]string{labels.AutoUpdatePolicy: "registry"})
+	assert.NilError(t, err)
+	assert.Equal(t, policy, "")
+
+	_, err = autoUpdatePolicy(map[string]string{labels.AutoUpdatePolicy: "always"}, nil)
+	assert.ErrorContains(t, err, `unknown io.containers.autoupdate policy "always"`)
+}
+
+func TestAutoUpdateRecordCreateArgs(t *testing.T) {
+	record := autoUpdateRecord{
+		Flags: []string{"--name=web", "--publish=8080:80", "--env=A=1", "--label=io.containers.autoupdate=registry"},
+		Args:  []string{"nginx", "-g", "daemon off;"},
+	}
+	assert.DeepEqual(t, record.createArgs("web", "docker.io/library/nginx:latest"), []string{
+		"create", "--publish=8080:80", "--env=A=1", "--label=io.containers.autoupdate=registry",
+		"--name=web", "docker.io/library/nginx:latest", "nginx", "-g", "daemon off;",
+	})
+}
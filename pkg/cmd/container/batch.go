@@ -0,0 +1,46 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"fmt"
+
+	containerd "github.com/containerd/containerd/v2/client"
+)
+
+// FilterTargets returns the IDs of the containers matching the filters, for the batch operations
+// such as `nerdctl stop --filter`.
+// Matching more than one container is an error unless allMatched is set, so that a loose filter
+// does not stop or remove a whole fleet by mistake.
+func FilterTargets(ctx context.Context, client *containerd.Client, filters []string, allMatched bool) ([]string, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	containers, _, err := filterContainers(ctx, client, filters, 0, true, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(containers) > 1 && !allMatched {
+		return nil, fmt.Errorf("the filters matched %d containers, specify --all-matched to apply to all of them", len(containers))
+	}
+	ids := make([]string, len(containers))
+	for i, c := range containers {
+		ids[i] = c.ID()
+	}
+	return ids, nil
+}
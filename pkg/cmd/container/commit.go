@@ -18,16 +18,14 @@ package container
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"strings"
 
 	containerd "github.com/containerd/containerd/v2/client"
-	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil/commit"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/imgconfig"
 	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
 )
 
@@ -38,7 +36,7 @@ func Commit(ctx context.Context, client *containerd.Client, rawRef string, req s
 		return err
 	}
 
-	changes, err := parseChanges(options.Change)
+	changes, err := imgconfig.ParseChanges(options.Change)
 	if err != nil {
 		return err
 	}
@@ -49,6 +47,8 @@ func Commit(ctx context.Context, client *containerd.Client, rawRef string, req s
 		Ref:     parsedReference.String(),
 		Pause:   options.Pause,
 		Changes: changes,
+
+		LeaseDuration: options.LeaseDuration,
 	}
 
 	walker := &containerwalker.ContainerWalker{
@@ -73,45 +73,3 @@ func Commit(ctx context.Context, client *containerd.Client, rawRef string, req s
 	}
 	return nil
 }
-
-func parseChanges(userChanges []string) (commit.Changes, error) {
-	const (
-		// XXX: Where can I get a constants for this?
-		commandDirective    = "CMD"
-		entrypointDirective = "ENTRYPOINT"
-	)
-	if userChanges == nil {
-		return commit.Changes{}, nil
-	}
-	var changes commit.Changes
-	for _, change := range userChanges {
-		if change == "" {
-			return commit.Changes{}, fmt.Errorf("received an empty value in change flag")
-		}
-		changeFields := strings.Fields(change)
-
-		switch changeFields[0] {
-		case commandDirective:
-			var overrideCMD []string
-			if err := json.Unmarshal([]byte(change[len(changeFields[0]):]), &overrideCMD); err != nil {
-				return commit.Changes{}, fmt.Errorf("malformed json in change flag value %q", change)
-			}
-			if changes.CMD != nil {
-				log.L.Warn("multiple change flags supplied for the CMD directive, overriding with last supplied")
-			}
-			changes.CMD = overrideCMD
-		case entrypointDirective:
-			var overrideEntrypoint []string
-			if err := json.Unmarshal([]byte(change[len(changeFields[0]):]), &overrideEntrypoint); err != nil {
-				return commit.Changes{}, fmt.Errorf("malformed json in change flag value %q", change)
-			}
-			if changes.Entrypoint != nil {
-				log.L.Warnf("multiple change flags supplied for the Entrypoint directive, overriding with last supplied")
-			}
-			changes.Entrypoint = overrideEntrypoint
-		default: // TODO: Support the rest of the change directives
-			return commit.Changes{}, fmt.Errorf("unknown change directive %q", changeFields[0])
-		}
-	}
-	return changes, nil
-}
@@ -27,22 +27,40 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
 )
 
-// Cp copies files/folders between a running container and the local filesystem.
+// Cp copies files/folders between a container and the local filesystem, or between two containers.
 func Cp(ctx context.Context, client *containerd.Client, options types.ContainerCpOptions) error {
+	var srcContainer, destContainer containerd.Container
+	var err error
+	if options.SrcContainerReq != "" {
+		if srcContainer, err = findCpContainer(ctx, client, options.SrcContainerReq); err != nil {
+			return err
+		}
+	}
+	if options.DestContainerReq != "" {
+		if destContainer, err = findCpContainer(ctx, client, options.DestContainerReq); err != nil {
+			return err
+		}
+		if err = containerutil.EnsureNotImmutable(ctx, destContainer, "copying files into it"); err != nil {
+			return err
+		}
+	}
+	return containerutil.CopyFiles(ctx, client, srcContainer, destContainer, options)
+}
+
+// findCpContainer returns the single container matching req.
+func findCpContainer(ctx context.Context, client *containerd.Client, req string) (containerd.Container, error) {
+	var container containerd.Container
 	walker := &containerwalker.ContainerWalker{
 		Client: client,
 		OnFound: func(ctx context.Context, found containerwalker.Found) error {
 			if found.MatchCount > 1 {
 				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
 			}
-			return containerutil.CopyFiles(
-				ctx,
-				client,
-				found.Container,
-				options)
+			container = found.Container
+			return nil
 		},
 	}
-	count, err := walker.Walk(ctx, options.ContainerReq)
+	count, err := walker.Walk(ctx, req)
 
 	if count == -1 {
 		if err == nil {
@@ -53,9 +71,9 @@ func Cp(ctx context.Context, client *containerd.Client, options types.ContainerC
 		if err != nil {
 			err = fmt.Errorf("unable to retrieve containers with error: %w", err)
 		} else {
-			err = fmt.Errorf("no container found for: %s", options.ContainerReq)
+			err = fmt.Errorf("no container found for: %s", req)
 		}
 	}
 
-	return err
+	return container, err
 }
@@ -30,6 +30,7 @@ import (
 	"strings"
 
 	dockercliopts "github.com/docker/cli/opts"
+	"github.com/moby/sys/signal"
 	"github.com/opencontainers/runtime-spec/specs-go"
 
 	containerd "github.com/containerd/containerd/v2/client"
@@ -41,12 +42,14 @@ import (
 
 	"github.com/containerd/nerdctl/v2/pkg/annotations"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/bypass4netnsutil"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
 	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
 	"github.com/containerd/nerdctl/v2/pkg/flagutil"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
 	"github.com/containerd/nerdctl/v2/pkg/idgen"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil/load"
@@ -56,12 +59,18 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/logging"
 	"github.com/containerd/nerdctl/v2/pkg/maputil"
 	"github.com/containerd/nerdctl/v2/pkg/mountutil"
+	"github.com/containerd/nerdctl/v2/pkg/namespaceutil"
 	"github.com/containerd/nerdctl/v2/pkg/namestore"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+	"github.com/containerd/nerdctl/v2/pkg/ocihook/state"
 	"github.com/containerd/nerdctl/v2/pkg/platformutil"
+	"github.com/containerd/nerdctl/v2/pkg/portutil"
 	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+	"github.com/containerd/nerdctl/v2/pkg/secretstore"
 	"github.com/containerd/nerdctl/v2/pkg/store"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
+	"github.com/containerd/nerdctl/v2/pkg/watchconfig"
 )
 
 // Create will create a container.
@@ -72,6 +81,14 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 	if err != nil {
 		return nil, nil, err
 	}
+	if options.DryRun {
+		existingVolumes, err := volStore.List(false)
+		if err != nil {
+			return nil, nil, err
+		}
+		// deferred before releasing the lock, so as to be called after releasing it
+		defer removeDryRunVolumes(ctx, volStore, existingVolumes)
+	}
 	err = volStore.Lock()
 	if err != nil {
 		return nil, nil, err
@@ -85,6 +102,28 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 		newArg = append(newArg, args[2:]...)
 		args = newArg
 	}
+	if options.NetAccel {
+		a, err := netAccelAnnotation(rootlessutil.IsRootlessChild())
+		if err != nil {
+			return nil, nil, err
+		}
+		options.Annotations = append(options.Annotations, a)
+	}
+
+	nsLimits, err := namespaceutil.Get(ctx, client, options.GOptions.Namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := applyNamespaceLimits(nsLimits, &options); err != nil {
+		return nil, nil, err
+	}
+	if options.Immutable {
+		options.ReadOnly = true
+	}
+	if runtime.GOOS != "windows" && (options.CPUCount != 0 || options.CPUPercent != 0 || options.IOMaximumBandwidth != "" || options.IOMaximumIOps != 0) {
+		return nil, nil, errors.New("--cpu-count, --cpu-percent, --io-maxbandwidth, and --io-maxiops are only supported on Windows")
+	}
+
 	var internalLabels internalLabels
 	internalLabels.platform = options.Platform
 	internalLabels.namespace = options.GOptions.Namespace
@@ -96,8 +135,10 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 	)
 
 	if options.CidFile != "" {
-		if err := writeCIDFile(options.CidFile, id); err != nil {
-			return nil, nil, err
+		if !options.DryRun {
+			if err := writeCIDFile(options.CidFile, id); err != nil {
+				return nil, nil, err
+			}
 		}
 		internalLabels.cidFile = options.CidFile
 	}
@@ -114,6 +155,12 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 		return nil, nil, err
 	}
 
+	var storageSize uint64
+	internalLabels.storageOpt, storageSize, err = parseStorageOpts(options)
+	if err != nil {
+		return nil, generateRemoveStateDirFunc(ctx, id, internalLabels), err
+	}
+
 	opts = append(opts,
 		oci.WithDefaultSpec(),
 	)
@@ -173,6 +220,7 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 
 		options.ImagePullOpt.Mode = options.Pull
 		options.ImagePullOpt.OCISpecPlatform = ocispecPlatforms
+		options.ImagePullOpt.RequirePlatform = options.Platform != ""
 		options.ImagePullOpt.Unpack = nil
 
 		ensuredImage, err = image.EnsureImage(ctx, client, rawRef, options.ImagePullOpt)
@@ -230,11 +278,24 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 		opts = append(opts, oci.WithProcessCwd(options.Workdir))
 	}
 
-	envs, err := flagutil.MergeEnvFileAndOSEnv(options.EnvFile, options.Env)
+	envs, err := flagutil.MergeEnvFileAndOSEnv(options.EnvFile, options.Env, flagutil.EnvFileOptions{
+		Expand: options.EnvFileExpand,
+		Strict: options.EnvFileStrict,
+	})
 	if err != nil {
 		return nil, generateRemoveStateDirFunc(ctx, id, internalLabels), err
 	}
 
+	tzOpt, tzEnvs, err := withTimezone(options.TZ)
+	if err != nil {
+		return nil, generateRemoveStateDirFunc(ctx, id, internalLabels), err
+	}
+	if tzOpt != nil {
+		opts = append(opts, tzOpt)
+	}
+	// -e TZ=... takes precedence over --tz.
+	envs = append(tzEnvs, envs...)
+
 	if options.Interactive {
 		if options.Detach {
 			return nil, generateRemoveStateDirFunc(ctx, id, internalLabels), errors.New("currently flag -i and -d cannot be specified together (FIXME)")
@@ -290,16 +351,31 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 		return nil, generateRemoveOrphanedDirsFunc(ctx, id, dataStore, internalLabels), fmt.Errorf("failed to generate internal networking labels: %w", err)
 	}
 
+	if netLabelOpts.PublishAll && ensuredImage != nil {
+		for _, p := range portutil.ExposedPortsToPublish(ensuredImage.ImageConfig.ExposedPorts, netLabelOpts.PortMappings) {
+			pm, err := portutil.ParseFlagP(p)
+			if err != nil {
+				return nil, generateRemoveOrphanedDirsFunc(ctx, id, dataStore, internalLabels), fmt.Errorf("failed to publish exposed port %q: %w", p, err)
+			}
+			netLabelOpts.PortMappings = append(netLabelOpts.PortMappings, pm...)
+		}
+	}
+
+	internalLabels.networkShaping, err = netutil.NewShaping(netLabelOpts.NetworkBandwidth, netLabelOpts.NetworkDelay)
+	if err != nil {
+		return nil, generateRemoveOrphanedDirsFunc(ctx, id, dataStore, internalLabels), err
+	}
+
 	envs = append(envs, "HOSTNAME="+netLabelOpts.Hostname)
 	opts = append(opts, oci.WithEnv(envs))
 
 	internalLabels.loadNetOpts(netLabelOpts)
 
-	// NOTE: OCI hooks are currently not supported on Windows so we skip setting them altogether.
+	// NOTE: OCI hooks are currently not supported on Windows and FreeBSD so we skip setting them altogether.
 	// The OCI hooks we define (whose logic can be found in pkg/ocihook) primarily
 	// perform network setup and teardown when using CNI networking.
-	// On Windows, we are forced to set up and tear down the networking from within nerdctl.
-	if runtime.GOOS != "windows" {
+	// On Windows and FreeBSD, we are forced to set up and tear down the networking from within nerdctl.
+	if runtime.GOOS != "windows" && runtime.GOOS != "freebsd" {
 		hookOpt, err := withNerdctlOCIHook(options.NerdctlCmd, options.NerdctlArgs)
 		if err != nil {
 			return nil, generateRemoveOrphanedDirsFunc(ctx, id, dataStore, internalLabels), err
@@ -307,18 +383,12 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 		opts = append(opts, hookOpt)
 	}
 
-	uOpts, err := generateUserOpts(options.User)
-	if err != nil {
-		return nil, generateRemoveOrphanedDirsFunc(ctx, id, dataStore, internalLabels), err
-	}
-
-	opts = append(opts, uOpts...)
-	gOpts, err := generateGroupsOpts(options.GroupAdd)
+	uOpts, err := generateUserOpts(options.User, options.GroupAdd)
 	internalLabels.groupAdd = options.GroupAdd
 	if err != nil {
 		return nil, generateRemoveOrphanedDirsFunc(ctx, id, dataStore, internalLabels), err
 	}
-	opts = append(opts, gOpts...)
+	opts = append(opts, uOpts...)
 
 	umaskOpts, err := generateUmaskOpts(options.Umask)
 	if err != nil {
@@ -332,12 +402,17 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 	}
 	cOpts = append(cOpts, rtCOpts...)
 
-	lCOpts, err := withContainerLabels(options.Label, options.LabelFile, ensuredImage)
+	lCOpts, err := withContainerLabels(options.Label, options.LabelFile, nsLimits.Labels, options.DefaultLabels, ensuredImage)
 	if err != nil {
 		return nil, generateRemoveOrphanedDirsFunc(ctx, id, dataStore, internalLabels), err
 	}
 	cOpts = append(cOpts, lCOpts...)
 
+	internalLabels.autoUpdate, err = newAutoUpdateRecord(options, args, ensuredImage)
+	if err != nil {
+		return nil, generateRemoveOrphanedDirsFunc(ctx, id, dataStore, internalLabels), err
+	}
+
 	var containerNameStore namestore.NameStore
 	if options.Name == "" && !options.NameChanged {
 		// Automatically set the container name, unless `--name=""` was explicitly specified.
@@ -371,6 +446,35 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 	internalLabels.extraHosts = extraHosts
 
 	internalLabels.rm = containerutil.EncodeContainerRmOptLabel(options.Rm)
+	internalLabels.immutable = options.Immutable
+
+	hooks, err := containerutil.NewHooks(options.OnStart, options.OnPostStart, options.OnStop)
+	if err != nil {
+		return nil, generateRemoveOrphanedDirsFunc(ctx, id, dataStore, internalLabels), err
+	}
+	internalLabels.hooks = hooks
+
+	if len(options.WatchConfig) > 0 && (runtime.GOOS == "windows" || runtime.GOOS == "freebsd") {
+		return nil, generateRemoveOrphanedDirsFunc(ctx, id, dataStore, internalLabels), fmt.Errorf("--watch-config is not supported on %s", runtime.GOOS)
+	}
+	internalLabels.watchConfig, err = watchconfig.New(options.WatchConfig, options.WatchConfigSignal, internalLabels.mountPoints)
+	if err != nil {
+		return nil, generateRemoveOrphanedDirsFunc(ctx, id, dataStore, internalLabels), err
+	}
+
+	if len(options.Secret) > 0 && options.Restart != "" && options.Restart != "no" && internalLabels.logConfig.Driver == "" {
+		// The secrets are staged by the logging process of nerdctl when the containerd restart manager restarts the container
+		return nil, generateRemoveOrphanedDirsFunc(ctx, id, dataStore, internalLabels),
+			fmt.Errorf("--secret with --restart=%s requires a nerdctl log driver, not %q", options.Restart, options.LogDriver)
+	}
+	secrets, secretOpt, err := withSecrets(dataStore, options.GOptions.Namespace, id, options.Secret)
+	if err != nil {
+		return nil, generateRemoveOrphanedDirsFunc(ctx, id, dataStore, internalLabels), err
+	}
+	if secretOpt != nil {
+		opts = append(opts, secretOpt)
+	}
+	internalLabels.secrets = secrets
 
 	// TODO: abolish internal labels and only use annotations
 	ilOpt, err := withInternalLabels(internalLabels)
@@ -385,9 +489,25 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 	var s specs.Spec
 	spec := containerd.WithSpec(&s, opts...)
 
-	cOpts = append(cOpts, spec)
+	cOpts = append(cOpts, spec, withAdmission(&s, options))
+
+	if options.DryRun {
+		err = writeDryRunPlan(ctx, client, id, args, cOpts, &s, internalLabels, options)
+		cleanupDryRun(ctx, id, dataStore, containerNameStore, internalLabels)
+		if err == nil && options.ConfigOut != "" {
+			err = writeRunConfig(options.ConfigOut, args, options)
+		}
+		return nil, nil, err
+	}
 
 	c, containerErr := client.NewContainer(ctx, id, cOpts...)
+	if containerErr == nil && storageSize > 0 {
+		if containerErr = setWritableLayerQuota(ctx, client, c, storageSize); containerErr != nil {
+			if delErr := c.Delete(ctx, containerd.WithSnapshotCleanup); delErr != nil {
+				log.G(ctx).WithError(delErr).Warnf("failed to remove container %q", id)
+			}
+		}
+	}
 	var netSetupErr error
 	if containerErr == nil {
 		netSetupErr = netManager.SetupNetworking(ctx, id)
@@ -404,6 +524,22 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 		return nil, generateGcFunc(ctx, c, options.GOptions.Namespace, id, options.Name, dataStore, containerErr, containerNameStore, netManager, internalLabels), returnedError
 	}
 
+	// Cache the command for `nerdctl ps`, so that it does not need to unmarshal the spec
+	if lf, err := state.New(internalLabels.stateDir); err != nil {
+		log.G(ctx).WithError(err).Warn("failed to open the lifecycle state")
+	} else if err := lf.Transform(func(lf *state.Store) error {
+		lf.Command = formatter.InspectContainerCommand(&s, false, false)
+		return nil
+	}); err != nil {
+		log.G(ctx).WithError(err).Warn("failed to cache the command")
+	}
+
+	if options.ConfigOut != "" {
+		if err := writeRunConfig(options.ConfigOut, args, options); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to write %s", options.ConfigOut)
+		}
+	}
+
 	return c, nil, nil
 }
 
@@ -496,7 +632,9 @@ func generateRootfsOpts(args []string, id string, ensured *imgutil.EnsuredImage,
 				{Type: "tmpfs", Source: "tmpfs", Destination: "/var/lib/journal"},
 			}),
 		)
-		stopSignal = "SIGRTMIN+3"
+		if stopSignal == "" {
+			stopSignal = "SIGRTMIN+3"
+		}
 	}
 
 	cOpts = append(cOpts, withStop(stopSignal, options.StopTimeout, ensured))
@@ -505,24 +643,15 @@ func generateRootfsOpts(args []string, id string, ensured *imgutil.EnsuredImage,
 		options.InitProcessFlag = true
 	}
 	if options.InitProcessFlag {
-		binaryPath, err := exec.LookPath(*options.InitBinary)
+		initBinary := defaultInitBinary
+		if options.InitBinary != nil {
+			initBinary = *options.InitBinary
+		}
+		initOpt, err := withInit(initBinary)
 		if err != nil {
-			if errors.Is(err, exec.ErrNotFound) {
-				return nil, nil, fmt.Errorf(`init binary %q not found`, *options.InitBinary)
-			}
 			return nil, nil, err
 		}
-		inContainerPath := filepath.Join("/sbin", filepath.Base(*options.InitBinary))
-		opts = append(opts, func(_ context.Context, _ oci.Client, _ *containers.Container, spec *oci.Spec) error {
-			spec.Process.Args = append([]string{inContainerPath, "--"}, spec.Process.Args...)
-			spec.Mounts = append([]specs.Mount{{
-				Destination: inContainerPath,
-				Type:        "bind",
-				Source:      binaryPath,
-				Options:     []string{"bind", "ro"},
-			}}, spec.Mounts...)
-			return nil
-		})
+		opts = append(opts, initOpt)
 	}
 	if options.ReadOnly {
 		opts = append(opts, oci.WithRootFSReadonly())
@@ -591,7 +720,7 @@ func withNerdctlOCIHook(cmd string, args []string) (oci.SpecOpts, error) {
 	}, nil
 }
 
-func withContainerLabels(label, labelFile []string, ensuredImage *imgutil.EnsuredImage) ([]containerd.NewContainerOpts, error) {
+func withContainerLabels(label, labelFile []string, nsLabels, defaultLabels map[string]string, ensuredImage *imgutil.EnsuredImage) ([]containerd.NewContainerOpts, error) {
 	var opts []containerd.NewContainerOpts
 
 	// add labels defined by image
@@ -600,6 +729,16 @@ func withContainerLabels(label, labelFile []string, ensuredImage *imgutil.Ensure
 		opts = append(opts, imageLabelOpts)
 	}
 
+	// add the default labels of the namespace
+	if len(nsLabels) > 0 {
+		opts = append(opts, containerd.WithAdditionalContainerLabels(nsLabels))
+	}
+
+	// add the default labels of nerdctl.toml
+	if len(defaultLabels) > 0 {
+		opts = append(opts, containerd.WithAdditionalContainerLabels(defaultLabels))
+	}
+
 	labelMap, err := readKVStringsMapfFromLabel(label, labelFile)
 	if err != nil {
 		return nil, err
@@ -647,14 +786,24 @@ func withStop(stopSignal string, stopTimeout int, ensuredImage *imgutil.EnsuredI
 		if c.Labels == nil {
 			c.Labels = make(map[string]string)
 		}
-		var err error
-		if ensuredImage != nil {
-			stopSignal, err = containerd.GetOCIStopSignal(ctx, ensuredImage.Image, stopSignal)
-			if err != nil {
-				return err
+		// Like Docker, --stop-signal takes precedence over STOPSIGNAL of the image.
+		if stopSignal == "" {
+			stopSignal = "SIGTERM"
+			if ensuredImage != nil {
+				var err error
+				stopSignal, err = containerd.GetOCIStopSignal(ctx, ensuredImage.Image, stopSignal)
+				if err != nil {
+					return err
+				}
 			}
 		}
+		if _, err := signal.ParseSignal(stopSignal); err != nil {
+			return fmt.Errorf("invalid stop signal %q: %w", stopSignal, err)
+		}
 		c.Labels[containerd.StopSignalLabel] = stopSignal
+		if stopTimeout < -1 {
+			return fmt.Errorf("invalid stop timeout %d: must be -1 or greater", stopTimeout)
+		}
 		if stopTimeout != 0 {
 			c.Labels[labels.StopTimeout] = strconv.Itoa(stopTimeout)
 		}
@@ -679,6 +828,9 @@ type internalLabels struct {
 	ipAddress            string
 	ip6Address           string
 	ports                []cni.PortMapping
+	userlandProxy        bool
+	networkShaping       *netutil.Shaping
+	allowFrom            []string
 	macAddress           string
 	dnsServers           []string
 	dnsSearchDomains     []string
@@ -699,12 +851,30 @@ type internalLabels struct {
 	// a label to chek if --cidfile is set
 	cidFile string
 
+	// label for the lifecycle hooks set by --on-start, --on-post-start, and --on-stop
+	hooks *containerutil.Hooks
+
+	// label for the config files watched by --watch-config
+	watchConfig *watchconfig.Config
+
 	// label to check if --group-add is set
 	groupAdd []string
 
+	// label for the secrets set by --secret
+	secrets *secretstore.ContainerSecrets
+
+	// label for the command line recorded for `nerdctl container auto-update`
+	autoUpdate *autoUpdateRecord
+
+	// label to check if --immutable is set
+	immutable bool
+
 	// label for device mapping set by the --device flag
 	deviceMapping []dockercompat.DeviceMapping
 
+	// label for the storage options set by the --storage-opt flag
+	storageOpt map[string]string
+
 	user string
 }
 
@@ -736,6 +906,23 @@ func withInternalLabels(internalLabels internalLabels) (containerd.NewContainerO
 			return nil, err
 		}
 		m[labels.Ports] = string(portsJSON)
+		if internalLabels.userlandProxy {
+			m[labels.UserlandProxy] = "true"
+		}
+	}
+	if len(internalLabels.allowFrom) > 0 {
+		allowFromJSON, err := json.Marshal(internalLabels.allowFrom)
+		if err != nil {
+			return nil, err
+		}
+		m[labels.IngressAllowFrom] = string(allowFromJSON)
+	}
+	if internalLabels.networkShaping != nil {
+		shapingJSON, err := json.Marshal(internalLabels.networkShaping)
+		if err != nil {
+			return nil, err
+		}
+		m[labels.NetworkShaping] = string(shapingJSON)
 	}
 	if internalLabels.logURI != "" {
 		m[labels.LogURI] = internalLabels.logURI
@@ -795,6 +982,42 @@ func withInternalLabels(internalLabels internalLabels) (containerd.NewContainerO
 		m[labels.ContainerAutoRemove] = internalLabels.rm
 	}
 
+	if !internalLabels.hooks.IsEmpty() {
+		hooksJSON, err := json.Marshal(internalLabels.hooks)
+		if err != nil {
+			return nil, err
+		}
+		m[labels.Hooks] = string(hooksJSON)
+	}
+
+	if internalLabels.watchConfig != nil {
+		watchConfigJSON, err := json.Marshal(internalLabels.watchConfig)
+		if err != nil {
+			return nil, err
+		}
+		m[labels.WatchConfig] = string(watchConfigJSON)
+	}
+
+	if internalLabels.secrets != nil {
+		secretsJSON, err := json.Marshal(internalLabels.secrets)
+		if err != nil {
+			return nil, err
+		}
+		m[labels.Secrets] = string(secretsJSON)
+	}
+
+	if internalLabels.autoUpdate != nil {
+		autoUpdateJSON, err := json.Marshal(internalLabels.autoUpdate)
+		if err != nil {
+			return nil, err
+		}
+		m[labels.AutoUpdate] = string(autoUpdateJSON)
+	}
+
+	if internalLabels.immutable {
+		m[labels.Immutable] = "true"
+	}
+
 	if internalLabels.cidFile != "" {
 		hostConfigLabel.CidFile = internalLabels.cidFile
 	}
@@ -815,6 +1038,14 @@ func withInternalLabels(internalLabels internalLabels) (containerd.NewContainerO
 		hostConfigLabel.Devices = append(hostConfigLabel.Devices, internalLabels.deviceMapping...)
 	}
 
+	if len(internalLabels.storageOpt) > 0 {
+		hostConfigLabel.StorageOpt = internalLabels.storageOpt
+	}
+
+	if len(internalLabels.groupAdd) > 0 {
+		hostConfigLabel.GroupAdd = internalLabels.groupAdd
+	}
+
 	hostConfigJSON, err := json.Marshal(hostConfigLabel)
 	if err != nil {
 		return nil, err
@@ -839,6 +1070,8 @@ func (il *internalLabels) loadNetOpts(opts types.NetworkOptions) {
 	il.hostname = opts.Hostname
 	il.domainname = opts.Domainname
 	il.ports = opts.PortMappings
+	il.userlandProxy = opts.UserlandProxy
+	il.allowFrom = opts.AllowFrom
 	il.ipAddress = opts.IPAddress
 	il.ip6Address = opts.IP6Address
 	il.networks = opts.NetworkSlice
@@ -867,9 +1100,11 @@ func dockercompatMounts(mountPoints []*mountutil.Processed) []dockercompat.Mount
 			result[i].Name = mp.AnonymousVolume
 		}
 
-		// volume only support local driver
 		if mp.Type == "volume" {
 			result[i].Driver = "local"
+			if mp.Driver != "" {
+				result[i].Driver = mp.Driver
+			}
 		}
 	}
 	return result
@@ -892,6 +1127,17 @@ func processeds(mountPoints []dockercompat.MountPoint) []*mountutil.Processed {
 	return result
 }
 
+// netAccelAnnotation returns the annotation of --net-accel, after checking that bypass4netnsd is running.
+func netAccelAnnotation(rootlessChild bool) (string, error) {
+	if !rootlessChild {
+		return "", errors.New("--net-accel is only supported in rootless mode")
+	}
+	if _, err := bypass4netnsutil.FindBypass4NetnsdSocket(); err != nil {
+		return "", err
+	}
+	return annotations.Bypass4netns + "=true", nil
+}
+
 func propagateInternalContainerdLabelsToOCIAnnotations() oci.SpecOpts {
 	return func(ctx context.Context, oc oci.Client, c *containers.Container, s *oci.Spec) error {
 		allowed := make(map[string]string)
@@ -990,6 +1236,12 @@ func generateRemoveOrphanedDirsFunc(ctx context.Context, id, dataStore string, i
 		} else if err = hs.Delete(id); err != nil {
 			log.G(ctx).WithError(err).Warnf("failed to remove an etchosts directory for container %q", id)
 		}
+
+		if internalLabels.secrets != nil {
+			if err := internalLabels.secrets.Unstage(); err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to remove the secrets of container %q", id)
+			}
+		}
 	}
 }
 
@@ -1023,6 +1275,11 @@ func generateGcFunc(ctx context.Context, container containerd.Container, ns, id,
 		if rmErr := os.RemoveAll(internalLabels.stateDir); rmErr != nil {
 			log.G(ctx).WithError(rmErr).Warnf("failed to remove container %q state dir %q", id, internalLabels.stateDir)
 		}
+		if internalLabels.secrets != nil {
+			if err := internalLabels.secrets.Unstage(); err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to remove the secrets of container %q", id)
+			}
+		}
 
 		if name != "" {
 			var errE error
@@ -0,0 +1,75 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/docker/go-units"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/namespaceutil"
+)
+
+// applyNamespaceLimits sets the default resource constraints of the namespace to options,
+// and returns an error when options request more resources than the namespace allows.
+func applyNamespaceLimits(limits *namespaceutil.Limits, options *types.ContainerCreateOptions) error {
+	ns := options.GOptions.Namespace
+	if limits.CPUQuota > 0 {
+		period := int64(options.CPUPeriod)
+		if period == 0 {
+			period = namespaceutil.DefaultCPUPeriod
+		}
+		// the quota per the default period
+		var quota int64
+		switch {
+		case options.CPUs > 0:
+			quota = int64(options.CPUs * namespaceutil.DefaultCPUPeriod)
+		case options.CPUQuota > 0:
+			quota = options.CPUQuota * namespaceutil.DefaultCPUPeriod / period
+		}
+		if quota == 0 {
+			options.CPUQuota = limits.CPUQuota * period / namespaceutil.DefaultCPUPeriod
+		} else if quota > limits.CPUQuota {
+			return fmt.Errorf("the CPU quota (%.2f CPUs) exceeds the limit of namespace %q (%.2f CPUs)",
+				float64(quota)/namespaceutil.DefaultCPUPeriod, ns, float64(limits.CPUQuota)/namespaceutil.DefaultCPUPeriod)
+		}
+	}
+	if limits.Memory > 0 {
+		if options.Memory == "" {
+			options.Memory = strconv.FormatInt(limits.Memory, 10)
+		} else {
+			mem, err := units.RAMInBytes(options.Memory)
+			if err != nil {
+				return fmt.Errorf("failed to parse memory bytes %q: %w", options.Memory, err)
+			}
+			if mem <= 0 || mem > limits.Memory {
+				return fmt.Errorf("the memory limit %q exceeds the limit of namespace %q (%s)",
+					options.Memory, ns, units.BytesSize(float64(limits.Memory)))
+			}
+		}
+	}
+	if limits.PidsLimit > 0 {
+		if options.PidsLimit <= 0 {
+			options.PidsLimit = limits.PidsLimit
+		} else if options.PidsLimit > limits.PidsLimit {
+			return fmt.Errorf("the pids limit %d exceeds the limit of namespace %q (%d)", options.PidsLimit, ns, limits.PidsLimit)
+		}
+	}
+	return nil
+}
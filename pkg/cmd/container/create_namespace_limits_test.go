@@ -0,0 +1,90 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/namespaceutil"
+)
+
+func TestApplyNamespaceLimits(t *testing.T) {
+	t.Parallel()
+	limits := &namespaceutil.Limits{
+		CPUQuota:  200000, // 2 CPUs
+		Memory:    1 << 30,
+		PidsLimit: 100,
+	}
+	tests := []struct {
+		name        string
+		options     types.ContainerCreateOptions
+		expected    types.ContainerCreateOptions
+		expectError string
+	}{
+		{
+			name:     "defaults",
+			options:  types.ContainerCreateOptions{CPUQuota: -1, PidsLimit: -1},
+			expected: types.ContainerCreateOptions{CPUQuota: 200000, Memory: "1073741824", PidsLimit: 100},
+		},
+		{
+			name:     "defaults with cpu-period",
+			options:  types.ContainerCreateOptions{CPUQuota: -1, CPUPeriod: 50000, PidsLimit: -1},
+			expected: types.ContainerCreateOptions{CPUQuota: 100000, CPUPeriod: 50000, Memory: "1073741824", PidsLimit: 100},
+		},
+		{
+			name:     "within the limits",
+			options:  types.ContainerCreateOptions{CPUs: 1.5, CPUQuota: -1, Memory: "512m", PidsLimit: 50},
+			expected: types.ContainerCreateOptions{CPUs: 1.5, CPUQuota: -1, Memory: "512m", PidsLimit: 50},
+		},
+		{
+			name:        "too many CPUs",
+			options:     types.ContainerCreateOptions{CPUs: 3, CPUQuota: -1},
+			expectError: "exceeds the limit of namespace",
+		},
+		{
+			name:        "too large cpu-quota",
+			options:     types.ContainerCreateOptions{CPUQuota: 150000, CPUPeriod: 50000},
+			expectError: "exceeds the limit of namespace",
+		},
+		{
+			name:        "too much memory",
+			options:     types.ContainerCreateOptions{CPUQuota: -1, Memory: "2g"},
+			expectError: "exceeds the limit of namespace",
+		},
+		{
+			name:        "too large pids-limit",
+			options:     types.ContainerCreateOptions{CPUQuota: -1, PidsLimit: 200},
+			expectError: "exceeds the limit of namespace",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			options := tt.options
+			err := applyNamespaceLimits(limits, &options)
+			if tt.expectError != "" {
+				assert.ErrorContains(t, err, tt.expectError)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, options, tt.expected)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/annotations"
+)
+
+func TestNetAccelAnnotation(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", xdg)
+
+	_, err := netAccelAnnotation(false)
+	assert.ErrorContains(t, err, "only supported in rootless mode")
+
+	_, err = netAccelAnnotation(true)
+	assert.ErrorContains(t, err, "bypass4netnsd not running")
+
+	l, err := net.Listen("unix", filepath.Join(xdg, "bypass4netnsd.sock"))
+	assert.NilError(t, err)
+	defer l.Close()
+	a, err := netAccelAnnotation(true)
+	assert.NilError(t, err)
+	assert.Equal(t, a, annotations.Bypass4netns+"=true")
+}
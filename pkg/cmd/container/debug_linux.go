@@ -0,0 +1,122 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/ipcutil"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+// Debug runs an ephemeral debug container that joins the PID, network, and IPC namespaces of the target container,
+// and mounts the root filesystem of the target at options.TargetMount.
+// As the PID namespace is shared, the root filesystem of the target is also visible at /proc/1/root.
+//
+// The debug container is run with `nerdctl run --rm`, so that it is attached to the terminal like `nerdctl run -it`.
+func Debug(ctx context.Context, client *containerd.Client, req string, command []string, options types.ContainerDebugOptions) error {
+	var target containerd.Container
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+			}
+			target = found.Container
+			return nil
+		},
+	}
+	n, err := walker.Walk(ctx, req)
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("no such container %s", req)
+	}
+	if err := containerutil.EnsureNotImmutable(ctx, target, "debug"); err != nil {
+		return err
+	}
+	args, err := debugArgs(ctx, target, command, options)
+	if err != nil {
+		return err
+	}
+	log.G(ctx).Debugf("running the debug container: %v", args)
+	cmd := exec.CommandContext(ctx, options.NerdctlCmd, append(append([]string{}, options.NerdctlArgs...), args...)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = options.Stdin, options.Stdout, options.Stderr
+	// *exec.ExitError propagates the exit code of the debug container
+	return cmd.Run()
+}
+
+// debugArgs returns the arguments of `nerdctl run` for debugging the target container.
+func debugArgs(ctx context.Context, target containerd.Container, command []string, options types.ContainerDebugOptions) ([]string, error) {
+	task, err := target.Task(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("container %s is not running: %w", target.ID(), err)
+	}
+	status, err := task.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if status.Status != containerd.Running {
+		return nil, fmt.Errorf("container %s is not running", target.ID())
+	}
+	targetLabels, err := target.Labels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id := target.ID()
+	args := []string{
+		"run",
+		"--rm",
+		"--pid=container:" + id,
+		"--network=container:" + id,
+		// for strace, gdb, and for reading /proc/1/root
+		"--cap-add=SYS_PTRACE",
+	}
+	// The IPC namespace can only be joined when the target shares it
+	if ipc, err := ipcutil.DecodeIPCLabel(targetLabels[labels.IPC]); err == nil && (ipc.Mode == ipcutil.Shareable || ipc.Mode == ipcutil.Host) {
+		args = append(args, "--ipc=container:"+id)
+	}
+	if options.TargetMount != "" {
+		args = append(args, fmt.Sprintf("--mount=type=bind,source=/proc/%d/root,target=%s", task.Pid(), options.TargetMount))
+	}
+	if options.Name != "" {
+		args = append(args, "--name="+options.Name)
+	}
+	if options.Interactive {
+		args = append(args, "--interactive")
+	}
+	if options.TTY {
+		args = append(args, "--tty")
+	}
+	if options.Privileged {
+		args = append(args, "--privileged")
+	}
+	if options.Pull != "" {
+		args = append(args, "--pull="+options.Pull)
+	}
+	args = append(args, options.Image)
+	return append(args, command...), nil
+}
@@ -0,0 +1,242 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/template"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/diff"
+	"github.com/containerd/containerd/v2/core/leases"
+	"github.com/containerd/containerd/v2/core/mount"
+	"github.com/containerd/continuity/fs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/idgen"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+)
+
+const (
+	// whiteoutPrefix is the prefix of the files marking the deletions in the OCI layers
+	whiteoutPrefix = ".wh."
+	// whiteoutOpaqueDir marks a directory whose entries in the lower layers are deleted
+	whiteoutOpaqueDir = whiteoutPrefix + whiteoutPrefix + ".opq"
+)
+
+// DiffChange is a change of the filesystem of a container, printed by `nerdctl diff`.
+type DiffChange struct {
+	// Kind is "A" (added), "C" (changed), or "D" (deleted)
+	Kind string
+	Path string
+}
+
+// Diff prints the changes of the filesystem of a container from its image.
+func Diff(ctx context.Context, client *containerd.Client, req string, options types.ContainerDiffOptions) error {
+	var tmpl *template.Template
+	if options.Format != "" {
+		var err error
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+			}
+			return containerChanges(ctx, client, found.Container, func(change DiffChange) error {
+				if tmpl == nil {
+					_, err := fmt.Fprintln(options.Stdout, change.Kind, change.Path)
+					return err
+				}
+				var b bytes.Buffer
+				if err := tmpl.Execute(&b, change); err != nil {
+					return err
+				}
+				_, err := fmt.Fprintln(options.Stdout, b.String())
+				return err
+			})
+		},
+	}
+	n, err := walker.Walk(ctx, req)
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("no such container %s", req)
+	}
+	return nil
+}
+
+// containerChanges calls fn for each change of the filesystem of the container from the parent snapshot.
+// The diff is computed by the diff service of containerd, as an uncompressed layer.
+// Only the headers of the layer are read, the contents of the files are skipped.
+func containerChanges(ctx context.Context, client *containerd.Client, container containerd.Container, fn func(DiffChange) error) error {
+	info, err := container.Info(ctx)
+	if err != nil {
+		return err
+	}
+	if info.SnapshotKey == "" {
+		return fmt.Errorf("container %s has no rootfs snapshot", container.ID())
+	}
+	sn := client.SnapshotService(info.Snapshotter)
+	snInfo, err := sn.Stat(ctx, info.SnapshotKey)
+	if err != nil {
+		return err
+	}
+	upper, err := sn.Mounts(ctx, info.SnapshotKey)
+	if err != nil {
+		return err
+	}
+
+	// the layer of the diff is removed by the gc after the lease
+	ctx, done, err := client.WithLease(ctx, leases.WithRandomID(), leases.WithExpiration(1*time.Hour))
+	if err != nil {
+		return fmt.Errorf("failed to create lease for diff: %w", err)
+	}
+	defer done(context.WithoutCancel(ctx))
+
+	var lower []mount.Mount
+	if snInfo.Parent != "" {
+		viewKey := "nerdctl-diff-" + idgen.GenerateID()
+		lower, err = sn.View(ctx, viewKey, snInfo.Parent)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := sn.Remove(context.WithoutCancel(ctx), viewKey); err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to remove snapshot %s", viewKey)
+			}
+		}()
+	}
+
+	desc, err := client.DiffService().Compare(ctx, lower, upper, diff.WithMediaType(ocispec.MediaTypeImageLayer))
+	if err != nil {
+		return fmt.Errorf("failed to compute the diff: %w", err)
+	}
+	ra, err := client.ContentStore().ReaderAt(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer ra.Close()
+
+	// the lower snapshot is mounted to tell the added files from the changed ones
+	return mount.WithReadonlyTempMount(ctx, lower, func(lowerRoot string) error {
+		// tar.Reader seeks over the contents of the files, as io.SectionReader implements io.Seeker
+		return readLayerChanges(tar.NewReader(io.NewSectionReader(ra, 0, ra.Size())), func(p string) (bool, error) {
+			return existsInRoot(lowerRoot, p)
+		}, fn)
+	})
+}
+
+// readLayerChanges reads the headers of an uncompressed layer, and calls fn for each change.
+// The parent directories of the changes are reported as changed, as `docker diff` does.
+func readLayerChanges(tr *tar.Reader, existsInLower func(string) (bool, error), fn func(DiffChange) error) error {
+	seen := make(map[string]bool)
+	emit := func(change DiffChange) error {
+		for _, dir := range parentDirs(change.Path) {
+			if seen[dir] {
+				continue
+			}
+			seen[dir] = true
+			if err := fn(DiffChange{Kind: "C", Path: dir}); err != nil {
+				return err
+			}
+		}
+		if seen[change.Path] {
+			return nil
+		}
+		seen[change.Path] = true
+		return fn(change)
+	}
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		p := path.Clean("/" + hdr.Name)
+		if p == "/" {
+			continue
+		}
+		dir, base := path.Split(p)
+		switch {
+		case base == whiteoutOpaqueDir:
+			// the directory itself is reported by its own header
+			continue
+		case strings.HasPrefix(base, whiteoutPrefix):
+			if err := emit(DiffChange{Kind: "D", Path: path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix))}); err != nil {
+				return err
+			}
+		default:
+			kind := "A"
+			if exists, err := existsInLower(p); err != nil {
+				return err
+			} else if exists {
+				kind = "C"
+			}
+			if err := emit(DiffChange{Kind: kind, Path: p}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// parentDirs returns the parent directories of p, from the top, except "/".
+func parentDirs(p string) []string {
+	var dirs []string
+	for dir := path.Dir(p); dir != "/" && dir != "."; dir = path.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+	}
+	return dirs
+}
+
+// existsInRoot returns whether p exists in root, resolving the symlinks in the parent directories within root.
+func existsInRoot(root, p string) (bool, error) {
+	if root == "" {
+		return false, nil
+	}
+	dir, err := fs.RootPath(root, path.Dir(p))
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Lstat(filepath.Join(dir, path.Base(p))); err != nil {
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.ENOTDIR) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,65 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestReadLayerChanges(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, hdr := range []*tar.Header{
+		{Name: "a", Typeflag: tar.TypeReg},
+		{Name: "bin/", Typeflag: tar.TypeDir},
+		{Name: "bin/b", Typeflag: tar.TypeReg, Size: 3},
+		{Name: "bin/.wh.base64", Typeflag: tar.TypeReg},
+		{Name: "etc/foo/", Typeflag: tar.TypeDir},
+		{Name: "etc/foo/.wh..wh..opq", Typeflag: tar.TypeReg},
+		{Name: "etc/foo/bar", Typeflag: tar.TypeReg},
+	} {
+		assert.NilError(t, tw.WriteHeader(hdr))
+		if hdr.Size > 0 {
+			_, err := tw.Write([]byte("foo"))
+			assert.NilError(t, err)
+		}
+	}
+	assert.NilError(t, tw.Close())
+
+	lower := map[string]bool{"/bin": true, "/etc": true, "/etc/foo": true, "/etc/foo/bar": true}
+	var changes []DiffChange
+	err := readLayerChanges(tar.NewReader(&buf), func(p string) (bool, error) {
+		return lower[p], nil
+	}, func(change DiffChange) error {
+		changes = append(changes, change)
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, changes, []DiffChange{
+		{Kind: "A", Path: "/a"},
+		{Kind: "C", Path: "/bin"},
+		{Kind: "A", Path: "/bin/b"},
+		{Kind: "D", Path: "/bin/base64"},
+		{Kind: "C", Path: "/etc"},
+		{Kind: "C", Path: "/etc/foo"},
+		{Kind: "C", Path: "/etc/foo/bar"},
+	})
+}
@@ -0,0 +1,194 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/containerd/go-cni"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+	"github.com/containerd/nerdctl/v2/pkg/ipcutil"
+	"github.com/containerd/nerdctl/v2/pkg/logging"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil/volumestore"
+	"github.com/containerd/nerdctl/v2/pkg/namestore"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+	"github.com/containerd/nerdctl/v2/pkg/netutil/nettype"
+	"github.com/containerd/nerdctl/v2/pkg/sshutil"
+)
+
+// DryRunPlan is the plan of a container printed by `nerdctl run --dry-run`.
+type DryRunPlan struct {
+	// Command is the command line with the presets expanded.
+	Command string `json:"Command"`
+	Name    string `json:"Name"`
+	Image   string `json:"Image"`
+	// Labels are the containerd labels of the container, including the internal labels of nerdctl.
+	Labels    map[string]string         `json:"Labels"`
+	Spec      *specs.Spec               `json:"Spec"`
+	Mounts    []dockercompat.MountPoint `json:"Mounts"`
+	Networks  []DryRunNetwork           `json:"Networks"`
+	Ports     []cni.PortMapping         `json:"Ports,omitempty"`
+	LogConfig logging.LogConfig         `json:"LogConfig"`
+}
+
+// DryRunNetwork is a network of DryRunPlan.
+type DryRunNetwork struct {
+	Name string `json:"Name"`
+	// Config is the CNI network configuration list, for the CNI networks.
+	Config json.RawMessage `json:"Config,omitempty"`
+}
+
+// writeDryRunPlan generates the container record and the spec from cOpts without creating the container,
+// and writes the plan to options.Stdout.
+// The snapshot prepared by cOpts for generating the spec is removed.
+func writeDryRunPlan(ctx context.Context, client *containerd.Client, id string, args []string, cOpts []containerd.NewContainerOpts, s *specs.Spec,
+	internalLabels internalLabels, options types.ContainerCreateOptions) error {
+	c := containers.Container{
+		ID: id,
+		Runtime: containers.RuntimeInfo{
+			Name: client.Runtime(),
+		},
+	}
+	defer func() {
+		if c.SnapshotKey == "" {
+			return
+		}
+		if err := client.SnapshotService(c.Snapshotter).Remove(ctx, c.SnapshotKey); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to remove the snapshot %q", c.SnapshotKey)
+		}
+	}()
+	for _, o := range cOpts {
+		if err := o(ctx, client, &c); err != nil {
+			return err
+		}
+	}
+
+	subcommand := "create"
+	if options.InRun {
+		subcommand = "run"
+	}
+	cmdline := append([]string{"nerdctl", subcommand}, options.CreateFlags...)
+	plan := DryRunPlan{
+		Command:   sshutil.ShellJoin(append(cmdline, args...)),
+		Name:      internalLabels.name,
+		Image:     c.Image,
+		Labels:    c.Labels,
+		Spec:      s,
+		Mounts:    dockercompatMounts(internalLabels.mountPoints),
+		Ports:     internalLabels.ports,
+		LogConfig: internalLabels.logConfig,
+	}
+	networks, err := dryRunNetworks(internalLabels.networks, options.GOptions)
+	if err != nil {
+		return err
+	}
+	plan.Networks = networks
+
+	b, err := json.MarshalIndent(plan, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(options.Stdout, string(b))
+	return err
+}
+
+func dryRunNetworks(names []string, globalOptions types.GlobalCommandOptions) ([]DryRunNetwork, error) {
+	networks := make([]DryRunNetwork, len(names))
+	for i, name := range names {
+		networks[i].Name = name
+	}
+	if netType, err := nettype.Detect(names); err != nil || netType != nettype.CNI {
+		return networks, err
+	}
+	// the default network has been created in verifying the network options
+	e, err := netutil.NewCNIEnv(globalOptions.CNIPath, globalOptions.CNINetConfPath, netutil.WithNamespace(globalOptions.Namespace))
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range names {
+		netConfig, err := e.NetworkByNameOrID(name)
+		if err != nil {
+			return nil, err
+		}
+		networks[i].Config = netConfig.Bytes
+	}
+	return networks, nil
+}
+
+// cleanupDryRun removes the state of the container generated for the dry run.
+func cleanupDryRun(ctx context.Context, id, dataStore string, containerNameStore namestore.NameStore, internalLabels internalLabels) {
+	generateRemoveStateDirFunc(ctx, id, internalLabels)()
+	if hs, err := hostsstore.New(dataStore, internalLabels.namespace); err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to instantiate hostsstore for %q", internalLabels.namespace)
+	} else if _, err := hs.HostsPath(id); err == nil {
+		if err = hs.Delete(id); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to remove an etchosts directory for container %q", id)
+		}
+	}
+	if ipc, err := ipcutil.DecodeIPCLabel(internalLabels.ipc); err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to decode ipc label for container %q", id)
+	} else if err = ipcutil.CleanUp(ipc); err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to clean up ipc for container %q", id)
+	}
+	if internalLabels.secrets != nil {
+		if err := internalLabels.secrets.Unstage(); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to remove the secrets of container %q", id)
+		}
+	}
+	if containerNameStore != nil {
+		if err := containerNameStore.Release(internalLabels.name, id); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to release the name %q of container %q", internalLabels.name, id)
+		}
+	}
+}
+
+// removeDryRunVolumes removes the volumes created for the dry run, i.e., the volumes not in existing.
+// It must be called after releasing the lock of volStore.
+func removeDryRunVolumes(ctx context.Context, volStore volumestore.VolumeStore, existing map[string]native.Volume) {
+	volumes, err := volStore.List(false)
+	if err != nil {
+		log.G(ctx).WithError(err).Warn("failed to list the volumes")
+		return
+	}
+	var created []string
+	for name := range volumes {
+		if _, ok := existing[name]; !ok {
+			created = append(created, name)
+		}
+	}
+	if len(created) == 0 {
+		return
+	}
+	_, warns, err := volStore.Remove(func() ([]string, []error, error) {
+		return created, nil, nil
+	})
+	if err = errors.Join(append(warns, err)...); err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to remove the volumes %v", created)
+	}
+}
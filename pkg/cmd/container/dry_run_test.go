@@ -0,0 +1,56 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+)
+
+func TestDryRunNetworks(t *testing.T) {
+	globalOptions := types.GlobalCommandOptions{
+		Namespace:      "default",
+		CNIPath:        t.TempDir(),
+		CNINetConfPath: t.TempDir(),
+	}
+	conf := `{"cniVersion": "1.0.0", "name": "foo", "nerdctlID": "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", "plugins": [{"type": "bridge"}]}`
+	assert.NilError(t, os.WriteFile(filepath.Join(globalOptions.CNINetConfPath, "nerdctl-foo.conflist"), []byte(conf), 0o644))
+
+	networks, err := dryRunNetworks([]string{"foo"}, globalOptions)
+	assert.NilError(t, err)
+	assert.Equal(t, len(networks), 1)
+	assert.Equal(t, networks[0].Name, "foo")
+	var decoded map[string]any
+	assert.NilError(t, json.Unmarshal(networks[0].Config, &decoded))
+	assert.Equal(t, decoded["name"], "foo")
+
+	// the pseudo networks have no CNI configuration
+	for _, name := range []string{"host", "none", "container:foo"} {
+		networks, err = dryRunNetworks([]string{name}, globalOptions)
+		assert.NilError(t, err, name)
+		assert.DeepEqual(t, networks, []DryRunNetwork{{Name: name}})
+	}
+
+	_, err = dryRunNetworks([]string{"bar"}, globalOptions)
+	assert.ErrorContains(t, err, "bar")
+}
@@ -18,9 +18,9 @@ package container
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
-	"os"
 
 	"github.com/opencontainers/runtime-spec/specs-go"
 	"golang.org/x/term"
@@ -32,13 +32,25 @@ import (
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/consoleutil"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
 	"github.com/containerd/nerdctl/v2/pkg/flagutil"
 	"github.com/containerd/nerdctl/v2/pkg/idgen"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/signalutil"
 	"github.com/containerd/nerdctl/v2/pkg/taskutil"
 )
 
+// ExecExitError is returned by Exec when the process exits with a non-zero code.
+type ExecExitError struct {
+	Code int
+}
+
+func (e *ExecExitError) Error() string {
+	return fmt.Sprintf("exec failed with exit code %d", e.Code)
+}
+
 // Exec will find the right running container to run a new command.
 func Exec(ctx context.Context, client *containerd.Client, args []string, options types.ContainerExecOptions) error {
 	walker := &containerwalker.ContainerWalker{
@@ -61,6 +73,12 @@ func Exec(ctx context.Context, client *containerd.Client, args []string, options
 }
 
 func execActionWithContainer(ctx context.Context, client *containerd.Client, container containerd.Container, args []string, options types.ContainerExecOptions) error {
+	if err := containerutil.EnsureNotPaused(ctx, container, "exec"); err != nil {
+		return err
+	}
+	if err := containerutil.EnsureNotImmutable(ctx, container, "exec"); err != nil {
+		return err
+	}
 	pspec, err := generateExecProcessSpec(ctx, client, container, args, options)
 	if err != nil {
 		return err
@@ -74,14 +92,14 @@ func execActionWithContainer(ctx context.Context, client *containerd.Client, con
 		ioCreator cio.Creator
 		in        io.Reader
 		stdinC    = &taskutil.StdinCloser{
-			Stdin: os.Stdin,
+			Stdin: options.Stdin,
 		}
 	)
 
 	if options.Interactive {
 		in = stdinC
 	}
-	cioOpts := []cio.Opt{cio.WithStreams(in, os.Stdout, os.Stderr)}
+	cioOpts := []cio.Opt{cio.WithStreams(in, options.Stdout, options.Stderr)}
 	if options.TTY {
 		cioOpts = append(cioOpts, cio.WithTerminal)
 	}
@@ -121,7 +139,7 @@ func execActionWithContainer(ctx context.Context, client *containerd.Client, con
 			if err := consoleutil.HandleConsoleResize(ctx, process, con); err != nil {
 				log.G(ctx).WithError(err).Error("console resize")
 			}
-		} else {
+		} else if options.SigProxy {
 			sigc := signalutil.ForwardAllSignals(ctx, process)
 			defer signalutil.StopCatch(sigc)
 		}
@@ -139,7 +157,7 @@ func execActionWithContainer(ctx context.Context, client *containerd.Client, con
 		return err
 	}
 	if code != 0 {
-		return fmt.Errorf("exec failed with exit code %d", code)
+		return &ExecExitError{Code: int(code)}
 	}
 	return nil
 }
@@ -149,20 +167,22 @@ func generateExecProcessSpec(ctx context.Context, client *containerd.Client, con
 	if err != nil {
 		return nil, err
 	}
-	userOpts, err := generateUserOpts(options.User)
-	if err != nil {
-		return nil, err
-	}
-	if userOpts != nil {
+	if options.User != "" {
 		c, err := container.Info(ctx)
 		if err != nil {
 			return nil, err
 		}
-		for _, opt := range userOpts {
-			if err := opt(ctx, client, &c, spec); err != nil {
+		// Like `docker exec`, the user also joins the groups of `run --group-add`.
+		var hostConfig dockercompat.HostConfigLabel
+		if hostConfigJSON, ok := c.Labels[labels.HostConfigLabel]; ok {
+			if err := json.Unmarshal([]byte(hostConfigJSON), &hostConfig); err != nil {
 				return nil, err
 			}
 		}
+		userOpt := withUser(options.User, hostConfig.GroupAdd, execRootfs(ctx, container))
+		if err := userOpt(ctx, client, &c, spec); err != nil {
+			return nil, err
+		}
 	}
 
 	pspec := spec.Process
@@ -181,7 +201,10 @@ func generateExecProcessSpec(ctx context.Context, client *containerd.Client, con
 	if options.Workdir != "" {
 		pspec.Cwd = options.Workdir
 	}
-	envs, err := flagutil.MergeEnvFileAndOSEnv(options.EnvFile, options.Env)
+	envs, err := flagutil.MergeEnvFileAndOSEnv(options.EnvFile, options.Env, flagutil.EnvFileOptions{
+		Expand: options.EnvFileExpand,
+		Strict: options.EnvFileStrict,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -17,11 +17,31 @@
 package container
 
 import (
+	"context"
+	"fmt"
+	"os"
+
 	"github.com/opencontainers/runtime-spec/specs-go"
 
+	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/pkg/cap"
 )
 
+// execRootfs returns the root filesystem of the running task, so that the user of
+// the exec process is resolved against the live /etc/passwd and /etc/group.
+// An empty string is returned when it is not accessible, e.g., in a different PID namespace.
+func execRootfs(ctx context.Context, container containerd.Container) string {
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		return ""
+	}
+	root := fmt.Sprintf("/proc/%d/root", task.Pid())
+	if _, err := os.Stat(root); err != nil {
+		return ""
+	}
+	return root
+}
+
 func setExecCapabilities(pspec *specs.Process) error {
 	if pspec.Capabilities == nil {
 		pspec.Capabilities = &specs.LinuxCapabilities{}
@@ -19,9 +19,17 @@
 package container
 
 import (
+	"context"
+
 	"github.com/opencontainers/runtime-spec/specs-go"
+
+	containerd "github.com/containerd/containerd/v2/client"
 )
 
+func execRootfs(ctx context.Context, container containerd.Container) string {
+	return ""
+}
+
 func setExecCapabilities(pspec *specs.Process) error {
 	//no op freebsd
 	return nil
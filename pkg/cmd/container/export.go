@@ -0,0 +1,254 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/leases"
+	"github.com/containerd/containerd/v2/core/mount"
+	"github.com/containerd/containerd/v2/pkg/archive/compression"
+	"github.com/containerd/containerd/v2/pkg/rootfs"
+	"github.com/containerd/continuity/fs"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/idgen"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/commit"
+	"github.com/containerd/nerdctl/v2/pkg/tarutil"
+)
+
+// ExportCompressionFromPath infers the compression of the archive from the extension of the output file.
+func ExportCompressionFromPath(output string) string {
+	switch {
+	case strings.HasSuffix(output, ".tar.gz"), strings.HasSuffix(output, ".tgz"):
+		return "gzip"
+	case strings.HasSuffix(output, ".tar.zst"), strings.HasSuffix(output, ".tar.zstd"):
+		return "zstd"
+	default:
+		return "none"
+	}
+}
+
+func parseExportCompression(s string) (compression.Compression, error) {
+	switch s {
+	case "", "none":
+		return compression.Uncompressed, nil
+	case "gzip":
+		return compression.Gzip, nil
+	case "zstd":
+		return compression.Zstd, nil
+	default:
+		return compression.Uncompressed, fmt.Errorf("unknown compression %q, must be one of \"none\", \"gzip\", or \"zstd\"", s)
+	}
+}
+
+// Export writes the filesystem of a container to options.Stdout, as a tar archive.
+func Export(ctx context.Context, client *containerd.Client, req string, options types.ContainerExportOptions) error {
+	comp, err := parseExportCompression(options.Compression)
+	if err != nil {
+		return err
+	}
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+			}
+			return exportContainer(ctx, client, found.Container, comp, options)
+		},
+	}
+	n, err := walker.Walk(ctx, req)
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("no such container %s", req)
+	}
+	return nil
+}
+
+func exportContainer(ctx context.Context, client *containerd.Client, container containerd.Container, comp compression.Compression, options types.ContainerExportOptions) error {
+	info, err := container.Info(ctx)
+	if err != nil {
+		return err
+	}
+	if info.SnapshotKey == "" {
+		return fmt.Errorf("container %s has no rootfs snapshot", container.ID())
+	}
+	sn := client.SnapshotService(info.Snapshotter)
+
+	// the snapshots created for the export are removed by the gc, even if the cleanup fails
+	ctx, done, err := client.WithLease(ctx, leases.WithRandomID(), leases.WithExpiration(1*time.Hour))
+	if err != nil {
+		return fmt.Errorf("failed to create lease for export: %w", err)
+	}
+	defer done(context.WithoutCancel(ctx))
+
+	running := false
+	if task, err := container.Task(ctx, nil); err == nil {
+		if st, err := task.Status(ctx); err == nil {
+			running = st.Status == containerd.Running || st.Status == containerd.Paused
+		}
+	}
+
+	var mounts []mount.Mount
+	if running && options.Live {
+		viewKey, cleanup, err := liveExportView(ctx, client, container, info.Snapshotter, info.SnapshotKey)
+		if err != nil {
+			return fmt.Errorf("failed to snapshot the running container: %w", err)
+		}
+		defer cleanup()
+		mounts, err = sn.Mounts(ctx, viewKey)
+		if err != nil {
+			return err
+		}
+	} else {
+		if running {
+			log.G(ctx).Warnf("Container %s is running, the files being written during the export may be inconsistent (Hint: use --live)", container.ID())
+		}
+		mounts, err = sn.Mounts(ctx, info.SnapshotKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	w, err := compression.CompressStream(options.Stdout, comp)
+	if err != nil {
+		return err
+	}
+	if err := mount.WithReadonlyTempMount(ctx, mounts, func(root string) error {
+		paths, err := exportPaths(root, options.Paths)
+		if err != nil {
+			return err
+		}
+		return tarutil.Create(ctx, root, paths, w)
+	}); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// liveExportView creates a read-only view of the filesystem of a running container at a point in time.
+// The container is paused only while the diff of its snapshot is taken.
+// The diff is applied onto the parent of the snapshot, and the result is committed to make the view.
+func liveExportView(ctx context.Context, client *containerd.Client, container containerd.Container, snapshotter, snapshotKey string) (string, func(), error) {
+	sn := client.SnapshotService(snapshotter)
+	snInfo, err := sn.Stat(ctx, snapshotKey)
+	if err != nil {
+		return "", nil, err
+	}
+
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	st, err := task.Status(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	if st.Status == containerd.Running {
+		if err := task.Pause(ctx); err != nil {
+			return "", nil, fmt.Errorf("failed to pause container: %w", err)
+		}
+	}
+	commit.Sync()
+	diffDesc, err := rootfs.CreateDiff(ctx, snapshotKey, sn, client.DiffService())
+	if st.Status == containerd.Running {
+		if resumeErr := task.Resume(ctx); resumeErr != nil {
+			log.G(ctx).WithError(resumeErr).Warnf("failed to unpause container %s", container.ID())
+		}
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	var (
+		id          = idgen.GenerateID()
+		activeKey   = "nerdctl-export-active-" + id
+		committed   = "nerdctl-export-" + id
+		viewKey     = "nerdctl-export-view-" + id
+		cleanupKeys []string
+	)
+	cleanup := func() {
+		ctx := context.WithoutCancel(ctx)
+		for i := len(cleanupKeys) - 1; i >= 0; i-- {
+			if err := sn.Remove(ctx, cleanupKeys[i]); err != nil && !errdefs.IsNotFound(err) {
+				log.G(ctx).WithError(err).Warnf("failed to remove snapshot %s", cleanupKeys[i])
+			}
+		}
+	}
+	activeMounts, err := sn.Prepare(ctx, activeKey, snInfo.Parent)
+	if err != nil {
+		return "", nil, err
+	}
+	cleanupKeys = append(cleanupKeys, activeKey)
+	if _, err := client.DiffService().Apply(ctx, diffDesc, activeMounts); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	if err := sn.Commit(ctx, committed, activeKey); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	cleanupKeys = []string{committed}
+	if _, err := sn.View(ctx, viewKey, committed); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	cleanupKeys = append(cleanupKeys, viewKey)
+	return viewKey, cleanup, nil
+}
+
+// exportPaths resolves the paths in the container to the paths relative to root, for the tar binary.
+// The symlinks in the paths are resolved within root, so that the archive does not contain files out of the container.
+func exportPaths(root string, paths []string) ([]string, error) {
+	res := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if !path.IsAbs(p) {
+			return nil, fmt.Errorf("path %q must be absolute", p)
+		}
+		p = path.Clean(p)
+		if p == "/" {
+			res = append(res, ".")
+			continue
+		}
+		// the last component is not resolved, so that a symlink is exported as a symlink
+		resolved, err := fs.RootPath(root, path.Dir(p))
+		if err != nil {
+			return nil, err
+		}
+		resolved = filepath.Join(resolved, path.Base(p))
+		rel, err := filepath.Rel(root, resolved)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Lstat(resolved); err != nil {
+			return nil, fmt.Errorf("path %q does not exist in the container", p)
+		}
+		res = append(res, "./"+filepath.ToSlash(rel))
+	}
+	return res, nil
+}
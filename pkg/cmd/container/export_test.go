@@ -0,0 +1,62 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestExportCompressionFromPath(t *testing.T) {
+	for output, expected := range map[string]string{
+		"":             "none",
+		"foo.tar":      "none",
+		"foo.tar.gz":   "gzip",
+		"foo.tgz":      "gzip",
+		"foo.tar.zst":  "zstd",
+		"foo.tar.zstd": "zstd",
+	} {
+		assert.Equal(t, ExportCompressionFromPath(output), expected, output)
+	}
+	_, err := parseExportCompression("bzip2")
+	assert.ErrorContains(t, err, "unknown compression")
+}
+
+func TestExportPaths(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks are not tested on Windows")
+	}
+	root := t.TempDir()
+	assert.NilError(t, os.MkdirAll(filepath.Join(root, "etc", "nginx"), 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join(root, "etc", "nginx", "nginx.conf"), nil, 0o644))
+	// a symlink to the host /etc must be resolved to the /etc of the container
+	assert.NilError(t, os.Symlink("/etc", filepath.Join(root, "conf")))
+	assert.NilError(t, os.Symlink("nginx.conf", filepath.Join(root, "etc", "nginx", "link.conf")))
+
+	paths, err := exportPaths(root, []string{"/", "/etc/nginx/", "/conf/nginx/nginx.conf", "/etc/nginx/link.conf"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, paths, []string{".", "./etc/nginx", "./etc/nginx/nginx.conf", "./etc/nginx/link.conf"})
+
+	_, err = exportPaths(root, []string{"etc"})
+	assert.ErrorContains(t, err, "must be absolute")
+	_, err = exportPaths(root, []string{"/etc/passwd"})
+	assert.ErrorContains(t, err, "does not exist in the container")
+}
@@ -21,7 +21,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"strings"
 	"syscall"
 
 	"github.com/moby/sys/signal"
@@ -44,17 +43,15 @@ import (
 
 // Kill kills a list of containers
 func Kill(ctx context.Context, client *containerd.Client, reqs []string, options types.ContainerKillOptions) error {
-	if !strings.HasPrefix(options.KillSignal, "SIG") {
-		options.KillSignal = "SIG" + options.KillSignal
-	}
-
+	// "SIGTERM", "TERM", "sigterm", and "15" are all accepted.
 	parsedSignal, err := signal.ParseSignal(options.KillSignal)
 	if err != nil {
 		return err
 	}
 
 	walker := &containerwalker.ContainerWalker{
-		Client: client,
+		Client:   client,
+		Parallel: options.Parallel,
 		OnFound: func(ctx context.Context, found containerwalker.Found) error {
 			if found.MatchCount > 1 {
 				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
@@ -62,7 +59,7 @@ func Kill(ctx context.Context, client *containerd.Client, reqs []string, options
 			if err := cleanupNetwork(ctx, found.Container, options.GOptions); err != nil {
 				return fmt.Errorf("unable to cleanup network for container: %s, %q", found.Req, err)
 			}
-			if err := killContainer(ctx, found.Container, parsedSignal); err != nil {
+			if err := killContainer(ctx, found.Container, parsedSignal, options.AllProcesses); err != nil {
 				if errdefs.IsNotFound(err) {
 					fmt.Fprintf(options.Stderr, "No such container: %s\n", found.Req)
 					os.Exit(1)
@@ -77,7 +74,7 @@ func Kill(ctx context.Context, client *containerd.Client, reqs []string, options
 	return walker.WalkAll(ctx, reqs, true)
 }
 
-func killContainer(ctx context.Context, container containerd.Container, signal syscall.Signal) (err error) {
+func killContainer(ctx context.Context, container containerd.Container, signal syscall.Signal, allProcesses bool) (err error) {
 	defer func() {
 		if err != nil {
 			containerutil.UpdateErrorLabel(ctx, container, err)
@@ -106,7 +103,11 @@ func killContainer(ctx context.Context, container containerd.Container, signal s
 	default:
 	}
 
-	if err := task.Kill(ctx, signal); err != nil {
+	var killOpts []containerd.KillOpts
+	if allProcesses {
+		killOpts = append(killOpts, containerd.WithKillAll)
+	}
+	if err := task.Kill(ctx, signal, killOpts...); err != nil {
 		return err
 	}
 
@@ -152,7 +153,7 @@ func cleanupNetwork(ctx context.Context, container containerd.Container, globalO
 		}
 
 		switch netType {
-		case nettype.Host, nettype.None, nettype.Container, nettype.Namespace:
+		case nettype.Host, nettype.None, nettype.Container, nettype.Namespace, nettype.Pasta:
 			// NOP
 		case nettype.CNI:
 			e, err := netutil.NewCNIEnv(globalOpts.CNIPath, globalOpts.CNINetConfPath, netutil.WithNamespace(globalOpts.Namespace), netutil.WithDefaultNetwork(globalOpts.BridgeIP))
@@ -37,11 +37,12 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/formatter"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/ocihook/state"
 )
 
 // List prints containers according to `options`.
 func List(ctx context.Context, client *containerd.Client, options types.ContainerListOptions) ([]ListItem, error) {
-	containers, cMap, err := filterContainers(ctx, client, options.Filters, options.LastN, options.All)
+	containers, cMap, err := filterContainers(ctx, client, options.Filters, options.LastN, options.All, options.Sync)
 	if err != nil {
 		return nil, err
 	}
@@ -54,8 +55,13 @@ func List(ctx context.Context, client *containerd.Client, options types.Containe
 //   - all means showing all containers (default shows just running).
 //   - lastN means only showing n last created containers (includes all states). Non-positive values are ignored.
 //     In other words, if lastN is positive, all will be set to true.
-func filterContainers(ctx context.Context, client *containerd.Client, filters []string, lastN int, all bool) ([]containerd.Container, map[string]string, error) {
-	containers, err := client.Containers(ctx)
+//   - syncState means querying containerd for the status of every container, instead of the lifecycle state index.
+func filterContainers(ctx context.Context, client *containerd.Client, filters []string, lastN int, all, syncState bool) ([]containerd.Container, map[string]containerState, error) {
+	var containerdFilters []string
+	if f := containerdLabelFilter(filters); f != "" {
+		containerdFilters = append(containerdFilters, f)
+	}
+	containers, err := client.Containers(ctx, containerdFilters...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -79,7 +85,7 @@ func filterContainers(ctx context.Context, client *containerd.Client, filters []
 	}
 
 	var wg sync.WaitGroup
-	statusPerContainer := make(map[string]string)
+	statusPerContainer := make(map[string]containerState)
 	var mu sync.Mutex
 	// formatter.ContainerStatus(ctx, c) is time consuming so we do it in goroutines and return the container's id with status as a map.
 	// prepareContainers func will use this map to avoid call formatter.ContainerStatus again.
@@ -90,9 +96,9 @@ func filterContainers(ctx context.Context, client *containerd.Client, filters []
 		wg.Add(1)
 		go func(ctx context.Context, c containerd.Container) {
 			defer wg.Done()
-			cStatus := formatter.ContainerStatus(ctx, c)
+			cState := loadContainerState(ctx, c, syncState)
 			mu.Lock()
-			statusPerContainer[c.ID()] = cStatus
+			statusPerContainer[c.ID()] = cState
 			mu.Unlock()
 		}(ctx, c)
 	}
@@ -103,7 +109,7 @@ func filterContainers(ctx context.Context, client *containerd.Client, filters []
 
 	var upContainers []containerd.Container
 	for _, c := range containers {
-		cStatus := statusPerContainer[c.ID()]
+		cStatus := statusPerContainer[c.ID()].status
 		if strings.HasPrefix(cStatus, "Up") {
 			upContainers = append(upContainers, c)
 		}
@@ -111,6 +117,37 @@ func filterContainers(ctx context.Context, client *containerd.Client, filters []
 	return upContainers, statusPerContainer, nil
 }
 
+// containerState is the status of a container, and its command when it is cached in the lifecycle state.
+type containerState struct {
+	status  string
+	command string
+}
+
+// loadContainerState reads the status and the command of the container from its lifecycle state,
+// which the oci-hooks keep up to date, and only asks containerd when the state cannot tell whether
+// the container is running.
+func loadContainerState(ctx context.Context, c containerd.Container, syncState bool) containerState {
+	if syncState {
+		return containerState{status: formatter.ContainerStatus(ctx, c)}
+	}
+	var cState containerState
+	info, err := c.Info(ctx, containerd.WithoutRefreshedMetadata)
+	if err == nil && info.Labels[labels.StateDir] != "" {
+		if lf, err := state.New(info.Labels[labels.StateDir]); err == nil && lf.Load() == nil {
+			cState.command = lf.Command
+			if lf.Running() {
+				cState.status = "Up"
+				if lf.Paused {
+					cState.status = "Paused"
+				}
+				return cState
+			}
+		}
+	}
+	cState.status = formatter.ContainerStatus(ctx, c)
+	return cState
+}
+
 type ListItem struct {
 	Command   string
 	CreatedAt time.Time
@@ -132,7 +169,7 @@ func (x *ListItem) Label(s string) string {
 	return x.LabelsMap[s]
 }
 
-func prepareContainers(ctx context.Context, client *containerd.Client, containers []containerd.Container, statusPerContainer map[string]string, options types.ContainerListOptions) ([]ListItem, error) {
+func prepareContainers(ctx context.Context, client *containerd.Client, containers []containerd.Container, statusPerContainer map[string]containerState, options types.ContainerListOptions) ([]ListItem, error) {
 	listItems := make([]ListItem, len(containers))
 	snapshottersCache := map[string]snapshots.Snapshotter{}
 	for i, c := range containers {
@@ -144,33 +181,36 @@ func prepareContainers(ctx context.Context, client *containerd.Client, container
 			}
 			return nil, err
 		}
-		spec, err := c.Spec(ctx)
-		if err != nil {
-			if errdefs.IsNotFound(err) {
-				log.G(ctx).Warn(err)
-				continue
+		cState, ok := statusPerContainer[c.ID()]
+		if !ok {
+			return nil, fmt.Errorf("can't get container %s status", c.ID())
+		}
+		command := formatter.FormatContainerCommand(cState.command, options.Truncate, true)
+		if cState.command == "" {
+			// Containers created by older versions do not have their command cached in the lifecycle state.
+			spec, err := c.Spec(ctx)
+			if err != nil {
+				if errdefs.IsNotFound(err) {
+					log.G(ctx).Warn(err)
+					continue
+				}
+				return nil, err
 			}
-			return nil, err
+			command = formatter.InspectContainerCommand(spec, options.Truncate, true)
 		}
 		id := c.ID()
 		if options.Truncate && len(id) > 12 {
 			id = id[:12]
 		}
-		var status string
-		if s, ok := statusPerContainer[c.ID()]; ok {
-			status = s
-		} else {
-			return nil, fmt.Errorf("can't get container %s status", c.ID())
-		}
 		li := ListItem{
-			Command:   formatter.InspectContainerCommand(spec, options.Truncate, true),
+			Command:   command,
 			CreatedAt: info.CreatedAt,
 			ID:        id,
 			Image:     info.Image,
 			Platform:  info.Labels[labels.Platform],
 			Names:     containerutil.GetContainerName(info.Labels),
 			Ports:     formatter.FormatPorts(info.Labels),
-			Status:    status,
+			Status:    cState.status,
 			Runtime:   info.Runtime.Name,
 			Labels:    formatter.FormatLabels(info.Labels),
 			LabelsMap: info.Labels,
@@ -192,6 +192,34 @@ func (cl *containerFilterContext) foldLabelFilter(_ context.Context, filter, val
 	return nil
 }
 
+// containerdLabelFilter returns the containerd filter of the "label" filters, so that the containers are
+// filtered by containerd, instead of fetching the info of all the containers.
+// An empty string is returned when there is no label filter, or when the containers cannot be filtered by containerd.
+func containerdLabelFilter(filters []string) string {
+	var exprs []string
+	for _, filter := range filters {
+		if strings.HasPrefix(filter, "before") || strings.HasPrefix(filter, "since") {
+			// "before" and "since" refer to the other containers
+			return ""
+		}
+		value, ok := strings.CutPrefix(filter, "label=")
+		if !ok {
+			continue
+		}
+		k, v, hasValue := strings.Cut(value, "=")
+		if k == "" {
+			continue
+		}
+		expr := "labels." + strconv.Quote(k)
+		if hasValue {
+			expr += "==" + strconv.Quote(v)
+		}
+		exprs = append(exprs, expr)
+	}
+	// The expressions separated by "," are ANDed.
+	return strings.Join(exprs, ",")
+}
+
 func (cl *containerFilterContext) foldVolumeFilter(_ context.Context, filter, value string) error {
 	cl.volumeFilterFuncs = append(cl.volumeFilterFuncs, func(vols []*containerutil.ContainerVolume) bool {
 		for _, vol := range vols {
@@ -0,0 +1,56 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/containerd/v2/pkg/filters"
+)
+
+func TestContainerdLabelFilter(t *testing.T) {
+	assert.Equal(t, containerdLabelFilter([]string{"name=foo"}), "")
+	assert.Equal(t, containerdLabelFilter([]string{"label=com.example.owner", "before=foo"}), "")
+
+	f := containerdLabelFilter([]string{"label=com.example.owner", "label=com.example.commit=0123 abc=", "name=foo"})
+	assert.Equal(t, f, `labels."com.example.owner",labels."com.example.commit"=="0123 abc="`)
+
+	filter, err := filters.Parse(f)
+	assert.NilError(t, err)
+	labelsAdaptor := func(labels map[string]string) filters.Adaptor {
+		return filters.AdapterFunc(func(fieldpath []string) (string, bool) {
+			if len(fieldpath) == 2 && fieldpath[0] == "labels" {
+				v, ok := labels[fieldpath[1]]
+				return v, ok
+			}
+			return "", false
+		})
+	}
+	assert.Assert(t, filter.Match(labelsAdaptor(map[string]string{
+		"com.example.owner":  "alice",
+		"com.example.commit": "0123 abc=",
+	})))
+	assert.Assert(t, !filter.Match(labelsAdaptor(map[string]string{
+		"com.example.commit": "0123 abc=",
+	})))
+	assert.Assert(t, !filter.Match(labelsAdaptor(map[string]string{
+		"com.example.owner":  "alice",
+		"com.example.commit": "0123",
+	})))
+}
@@ -21,30 +21,64 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
 
 	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/containerd/errdefs"
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil"
 )
 
 // Prune remove all stopped containers
 func Prune(ctx context.Context, client *containerd.Client, options types.ContainerPruneOptions) error {
+	filter, err := parsePruneFilters(options.Filters)
+	if err != nil {
+		return err
+	}
+
 	containers, err := client.Containers(ctx)
 	if err != nil {
 		return err
 	}
 
+	// removed is indexed by containers, so that the deleted containers are printed in the order of the list
+	removed := make([]bool, len(containers))
+	var eg errgroup.Group
+	eg.SetLimit(max(options.Parallel, 1))
+	for i, c := range containers {
+		eg.Go(func() error {
+			if !filter.isZero() {
+				info, err := c.Info(ctx, containerd.WithoutRefreshedMetadata)
+				if err != nil {
+					log.G(ctx).WithError(err).Warnf("failed to get the info of container %s", c.ID())
+					return nil
+				}
+				if !filter.match(info) {
+					return nil
+				}
+			}
+			// Like `docker container prune`, anonymous volumes are kept; `nerdctl volume prune` removes them.
+			err := RemoveContainer(ctx, c, options.GOptions, false, false, client)
+			if err == nil {
+				removed[i] = true
+			} else if !errors.As(err, &ErrContainerStatus{}) {
+				log.G(ctx).WithError(err).Warnf("failed to remove container %s", c.ID())
+			}
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
 	var deleted []string
-	for _, c := range containers {
-		if err = RemoveContainer(ctx, c, options.GOptions, false, true, client); err == nil {
+	for i, c := range containers {
+		if removed[i] {
 			deleted = append(deleted, c.ID())
-			continue
-		}
-		if errors.As(err, &ErrContainerStatus{}) {
-			continue
 		}
-		log.G(ctx).WithError(err).Warnf("failed to remove container %s", c.ID())
 	}
 
 	if len(deleted) > 0 {
@@ -54,3 +88,63 @@ func Prune(ctx context.Context, client *containerd.Client, options types.Contain
 
 	return nil
 }
+
+// pruneFilter holds the parsed `--filter` values of `nerdctl container prune`.
+type pruneFilter struct {
+	until  *time.Time
+	labels []func(map[string]string) bool
+}
+
+// parsePruneFilters parses the filters of `nerdctl container prune`.
+//
+// Supported filters:
+//   - until=<timestamp>: Only prune containers created before the timestamp, or before the duration ago (e.g., "24h").
+//   - label=<key>[=<value>]: Only prune containers with the label.
+//   - label!=<key>[=<value>]: Only prune containers without the label.
+func parsePruneFilters(filters []string) (*pruneFilter, error) {
+	f := &pruneFilter{}
+	for _, filter := range filters {
+		key, value, ok := strings.Cut(filter, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid filter %q: %w", filter, errdefs.ErrInvalidArgument)
+		}
+		switch key {
+		case "until":
+			if f.until != nil {
+				return nil, fmt.Errorf("more than one until filter provided: %w", errdefs.ErrInvalidArgument)
+			}
+			t, err := imgutil.ParseUntil(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter %q: %w", filter, err)
+			}
+			f.until = &t
+		case "label", "label!":
+			negate := key == "label!"
+			k, v, hasValue := strings.Cut(value, "=")
+			f.labels = append(f.labels, func(labels map[string]string) bool {
+				val, ok := labels[k]
+				matched := ok && (!hasValue || val == v)
+				return matched != negate
+			})
+		default:
+			return nil, fmt.Errorf("unsupported filter %q: %w", filter, errdefs.ErrInvalidArgument)
+		}
+	}
+	return f, nil
+}
+
+func (f *pruneFilter) isZero() bool {
+	return f.until == nil && len(f.labels) == 0
+}
+
+func (f *pruneFilter) match(info containers.Container) bool {
+	if f.until != nil && !info.CreatedAt.Before(*f.until) {
+		return false
+	}
+	for _, match := range f.labels {
+		if !match(info.Labels) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/containerd/v2/core/containers"
+)
+
+func TestPruneFilters(t *testing.T) {
+	old := containers.Container{CreatedAt: time.Now().Add(-48 * time.Hour), Labels: map[string]string{"app": "web"}}
+	recent := containers.Container{CreatedAt: time.Now(), Labels: map[string]string{"app": "db"}}
+
+	f, err := parsePruneFilters(nil)
+	assert.NilError(t, err)
+	assert.Assert(t, f.isZero())
+
+	f, err = parsePruneFilters([]string{"until=24h"})
+	assert.NilError(t, err)
+	assert.Assert(t, f.match(old))
+	assert.Assert(t, !f.match(recent))
+
+	f, err = parsePruneFilters([]string{"label=app=web"})
+	assert.NilError(t, err)
+	assert.Assert(t, f.match(old))
+	assert.Assert(t, !f.match(recent))
+
+	f, err = parsePruneFilters([]string{"label!=app=web", "label=app"})
+	assert.NilError(t, err)
+	assert.Assert(t, !f.match(old))
+	assert.Assert(t, f.match(recent))
+
+	_, err = parsePruneFilters([]string{"until=24h", "until=1h"})
+	assert.ErrorContains(t, err, "more than one until filter")
+	_, err = parsePruneFilters([]string{"until=yesterday"})
+	assert.ErrorContains(t, err, "unable to parse until timestamp")
+	_, err = parsePruneFilters([]string{"status=exited"})
+	assert.ErrorContains(t, err, "unsupported filter")
+}
@@ -0,0 +1,361 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	types100 "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/go-cni"
+	"github.com/containerd/log"
+	"github.com/containerd/typeurl/v2"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+	"github.com/containerd/nerdctl/v2/pkg/netutil/nettype"
+	"github.com/containerd/nerdctl/v2/pkg/ocihook"
+	"github.com/containerd/nerdctl/v2/pkg/ocihook/state"
+	"github.com/containerd/nerdctl/v2/pkg/portutil"
+	"github.com/containerd/nerdctl/v2/pkg/portutil/userlandproxy"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+// Publish adds host port mappings to a container, e.g., "8080:80/tcp".
+// The mappings of a running container are applied without restarting it.
+func Publish(ctx context.Context, client *containerd.Client, req string, portSpecs []string, options types.ContainerPublishOptions) error {
+	var added []cni.PortMapping
+	for _, s := range portSpecs {
+		pm, err := portutil.ParseFlagP(s)
+		if err != nil {
+			return err
+		}
+		added = append(added, pm...)
+	}
+	return walkOneContainer(ctx, client, req, func(ctx context.Context, c containerd.Container) error {
+		err := updateContainerPorts(ctx, c, options.GOptions, func(ports []cni.PortMapping) ([]cni.PortMapping, error) {
+			res, err := addPortMappings(ports, added)
+			if err != nil {
+				return nil, err
+			}
+			// The host ports of the other containers, and of the host processes
+			for _, a := range added {
+				inUse, err := portutil.PortInUse(a.Protocol, a.HostIP, uint64(a.HostPort))
+				if err != nil {
+					return nil, err
+				}
+				if inUse {
+					return nil, fmt.Errorf("host port %s:%d/%s is already in use", a.HostIP, a.HostPort, a.Protocol)
+				}
+			}
+			return res, nil
+		})
+		if err != nil {
+			return err
+		}
+		// Print the mappings, as the host ports may have been allocated automatically
+		for _, a := range added {
+			fmt.Fprintf(options.Stdout, "%d/%s -> %s:%d\n", a.ContainerPort, a.Protocol, a.HostIP, a.HostPort)
+		}
+		return nil
+	})
+}
+
+// Unpublish removes all the host port mappings of the given container ports, e.g., "80/tcp".
+func Unpublish(ctx context.Context, client *containerd.Client, req string, containerPorts []string, options types.ContainerUnpublishOptions) error {
+	removed, err := parseContainerPorts(containerPorts)
+	if err != nil {
+		return err
+	}
+	return walkOneContainer(ctx, client, req, func(ctx context.Context, c containerd.Container) error {
+		return updateContainerPorts(ctx, c, options.GOptions, func(ports []cni.PortMapping) ([]cni.PortMapping, error) {
+			res, err := removePortMappings(ports, removed)
+			if err != nil {
+				return nil, fmt.Errorf("%w for %q", err, c.ID())
+			}
+			return res, nil
+		})
+	})
+}
+
+// containerPort is a container port with its protocol, e.g., "80/tcp".
+type containerPort struct {
+	port  int32
+	proto string
+}
+
+// parseContainerPorts parses the arguments of `nerdctl container unpublish`, e.g., "80", "53/udp".
+func parseContainerPorts(containerPorts []string) ([]containerPort, error) {
+	var res []containerPort
+	for _, s := range containerPorts {
+		portStr, proto, _ := strings.Cut(s, "/")
+		if proto == "" {
+			proto = "tcp"
+		}
+		port, err := strconv.ParseInt(portStr, 10, 32)
+		if err != nil || port <= 0 {
+			return nil, fmt.Errorf("invalid container port %q, expected PRIVATE_PORT[/PROTO]", s)
+		}
+		res = append(res, containerPort{int32(port), strings.ToLower(proto)})
+	}
+	return res, nil
+}
+
+// addPortMappings appends the added mappings, and rejects the host ports that are already published by the container.
+func addPortMappings(ports, added []cni.PortMapping) ([]cni.PortMapping, error) {
+	res := append([]cni.PortMapping{}, ports...)
+	for _, a := range added {
+		for _, p := range res {
+			if p.HostPort == a.HostPort && p.HostIP == a.HostIP && p.Protocol == a.Protocol {
+				return nil, fmt.Errorf("host port %s:%d/%s is already published", a.HostIP, a.HostPort, a.Protocol)
+			}
+		}
+		res = append(res, a)
+	}
+	return res, nil
+}
+
+// removePortMappings removes all the mappings of the container ports, and fails if a container port is not published.
+func removePortMappings(ports []cni.PortMapping, removed []containerPort) ([]cni.PortMapping, error) {
+	res := []cni.PortMapping{}
+	found := make(map[containerPort]bool, len(removed))
+	for _, p := range ports {
+		cp := containerPort{p.ContainerPort, p.Protocol}
+		if slices.Contains(removed, cp) {
+			found[cp] = true
+			continue
+		}
+		res = append(res, p)
+	}
+	for _, r := range removed {
+		if !found[r] {
+			return nil, fmt.Errorf("no public port %d/%s published", r.port, r.proto)
+		}
+	}
+	return res, nil
+}
+
+func walkOneContainer(ctx context.Context, client *containerd.Client, req string, f func(context.Context, containerd.Container) error) error {
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+			}
+			return f(ctx, found.Container)
+		},
+	}
+	if n, err := walker.Walk(ctx, req); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("no such container %s", req)
+	}
+	return nil
+}
+
+// updateContainerPorts reprograms the port mappings of the container when its task is running,
+// and persists them in the labels and the spec annotations of the container.
+func updateContainerPorts(ctx context.Context, c containerd.Container, globalOptions types.GlobalCommandOptions,
+	update func([]cni.PortMapping) ([]cni.PortMapping, error)) error {
+	l, err := c.Labels(ctx)
+	if err != nil {
+		return err
+	}
+	var networks []string
+	if err := json.Unmarshal([]byte(l[labels.Networks]), &networks); err != nil {
+		return err
+	}
+	netType, err := nettype.Detect(networks)
+	if err != nil {
+		return err
+	}
+	if netType != nettype.CNI {
+		return fmt.Errorf("ports can only be published for containers connected to CNI networks, not %v", networks)
+	}
+	oldPorts, err := portutil.ParsePortsLabel(l)
+	if err != nil {
+		return err
+	}
+	newPorts, err := update(append([]cni.PortMapping{}, oldPorts...))
+	if err != nil {
+		return err
+	}
+
+	task, err := c.Task(ctx, nil)
+	if err != nil && !errdefs.IsNotFound(err) {
+		return err
+	}
+	if task != nil {
+		status, err := task.Status(ctx)
+		if err != nil {
+			return err
+		}
+		if status.Status != containerd.Stopped {
+			if err := applyContainerPorts(ctx, c, task.Pid(), l, networks, globalOptions, oldPorts, newPorts); err != nil {
+				return err
+			}
+		}
+	}
+	return persistContainerPorts(ctx, c, newPorts)
+}
+
+func applyContainerPorts(ctx context.Context, c containerd.Container, pid uint32, l map[string]string, networks []string,
+	globalOptions types.GlobalCommandOptions, oldPorts, newPorts []cni.PortMapping) error {
+	if rootlessutil.IsRootless() {
+		return errors.New("updating the ports of a running container is not supported in rootless mode yet")
+	}
+	dataStore, err := clientutil.DataStore(globalOptions.DataRoot, globalOptions.Address)
+	if err != nil {
+		return err
+	}
+	hs, err := hostsstore.New(dataStore, globalOptions.Namespace)
+	if err != nil {
+		return err
+	}
+	meta, err := hs.Get(c.ID())
+	if err != nil {
+		return fmt.Errorf("failed to get the network results of container %s: %w", c.ID(), err)
+	}
+	e, err := netutil.NewCNIEnv(globalOptions.CNIPath, globalOptions.CNINetConfPath,
+		netutil.WithNamespace(globalOptions.Namespace),
+		netutil.WithPortForwardingBackend(globalOptions.PortForwardingBackend),
+		netutil.WithDefaultNetwork(globalOptions.BridgeIP))
+	if err != nil {
+		return err
+	}
+
+	netnsPath := l[ocihook.NetworkNamespace]
+	if netnsPath == "" {
+		netnsPath = fmt.Sprintf("/proc/%d/ns/net", pid)
+	}
+	cniID := globalOptions.Namespace + "-" + c.ID()
+	results := make([]*types100.Result, len(networks))
+	var done []int
+	rollback := func() {
+		for _, i := range done {
+			netw, err := e.NetworkByNameOrID(networks[i])
+			if err == nil {
+				err = e.UpdatePortMappings(ctx, netw, cniID, netnsPath, fmt.Sprintf("eth%d", i), results[i], newPorts, oldPorts)
+			}
+			if err != nil {
+				log.G(ctx).WithError(err).Errorf("failed to restore the port mappings of network %q", networks[i])
+			}
+		}
+	}
+	for i, name := range networks {
+		netw, err := e.NetworkByNameOrID(name)
+		if err != nil {
+			rollback()
+			return err
+		}
+		results[i] = meta.Networks[name]
+		if results[i] == nil {
+			rollback()
+			return fmt.Errorf("no network result for network %q of container %s", name, c.ID())
+		}
+		// go-cni names the interfaces after the index of the network
+		if err := e.UpdatePortMappings(ctx, netw, cniID, netnsPath, fmt.Sprintf("eth%d", i), results[i], oldPorts, newPorts); err != nil {
+			rollback()
+			return err
+		}
+		done = append(done, i)
+	}
+
+	stateDir := l[labels.StateDir]
+	if l[labels.UserlandProxy] == "true" {
+		for _, p := range portsDifference(oldPorts, newPorts) {
+			userlandproxy.Stop(stateDir, &userlandproxy.Proxy{Protocol: p.Protocol, HostIP: p.HostIP, HostPort: int(p.HostPort)})
+		}
+		proxies, err := userlandproxy.FromPortMappings(portsDifference(newPorts, oldPorts), results)
+		if err != nil {
+			return err
+		}
+		for _, p := range proxies {
+			if err := userlandproxy.Start(stateDir, p); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The OCI hooks of the running task still see the original spec annotations
+	lf, err := state.New(stateDir)
+	if err != nil {
+		return err
+	}
+	return lf.Transform(func(lf *state.Store) error {
+		lf.Ports = &newPorts
+		return nil
+	})
+}
+
+func persistContainerPorts(ctx context.Context, c containerd.Container, ports []cni.PortMapping) error {
+	portsJSON, err := json.Marshal(ports)
+	if err != nil {
+		return err
+	}
+	return c.Update(ctx, func(ctx context.Context, client *containerd.Client, ci *containers.Container) error {
+		v, err := typeurl.UnmarshalAny(ci.Spec)
+		if err != nil {
+			return err
+		}
+		spec, ok := v.(*specs.Spec)
+		if !ok {
+			return fmt.Errorf("unexpected spec type %T", v)
+		}
+		if spec.Annotations == nil {
+			spec.Annotations = make(map[string]string)
+		}
+		if len(ports) == 0 {
+			delete(ci.Labels, labels.Ports)
+			delete(spec.Annotations, labels.Ports)
+		} else {
+			ci.Labels[labels.Ports] = string(portsJSON)
+			spec.Annotations[labels.Ports] = string(portsJSON)
+		}
+		a, err := typeurl.MarshalAny(spec)
+		if err != nil {
+			return err
+		}
+		ci.Spec = a
+		return nil
+	})
+}
+
+// portsDifference returns the mappings of a that are not in b.
+func portsDifference(a, b []cni.PortMapping) []cni.PortMapping {
+	var res []cni.PortMapping
+	for _, p := range a {
+		if !slices.Contains(b, p) {
+			res = append(res, p)
+		}
+	}
+	return res
+}
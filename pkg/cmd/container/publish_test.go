@@ -0,0 +1,99 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/go-cni"
+)
+
+func TestParseContainerPorts(t *testing.T) {
+	for _, tc := range []struct {
+		s        string
+		expected containerPort
+		errMsg   string
+	}{
+		{"80", containerPort{80, "tcp"}, ""},
+		{"80/tcp", containerPort{80, "tcp"}, ""},
+		{"53/UDP", containerPort{53, "udp"}, ""},
+		{"0", containerPort{}, "invalid container port"},
+		{"-1/tcp", containerPort{}, "invalid container port"},
+		{"http", containerPort{}, "invalid container port"},
+		{"8080:80", containerPort{}, "invalid container port"},
+	} {
+		ports, err := parseContainerPorts([]string{tc.s})
+		if tc.errMsg != "" {
+			assert.ErrorContains(t, err, tc.errMsg, tc.s)
+			continue
+		}
+		assert.NilError(t, err, tc.s)
+		assert.Equal(t, len(ports), 1, tc.s)
+		assert.Equal(t, ports[0], tc.expected, tc.s)
+	}
+}
+
+func TestAddPortMappings(t *testing.T) {
+	ports := []cni.PortMapping{
+		{HostPort: 8080, ContainerPort: 80, Protocol: "tcp", HostIP: "0.0.0.0"},
+	}
+	res, err := addPortMappings(ports, []cni.PortMapping{
+		{HostPort: 8080, ContainerPort: 80, Protocol: "udp", HostIP: "0.0.0.0"},
+		{HostPort: 8080, ContainerPort: 80, Protocol: "tcp", HostIP: "127.0.0.1"},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(res), 3)
+	assert.Equal(t, len(ports), 1, "the original mappings must not be modified")
+
+	// The host port is already published by the container
+	_, err = addPortMappings(ports, []cni.PortMapping{
+		{HostPort: 8080, ContainerPort: 81, Protocol: "tcp", HostIP: "0.0.0.0"},
+	})
+	assert.ErrorContains(t, err, "host port 0.0.0.0:8080/tcp is already published")
+
+	// The host port is specified twice
+	_, err = addPortMappings(nil, []cni.PortMapping{
+		{HostPort: 9090, ContainerPort: 80, Protocol: "tcp", HostIP: "0.0.0.0"},
+		{HostPort: 9090, ContainerPort: 81, Protocol: "tcp", HostIP: "0.0.0.0"},
+	})
+	assert.ErrorContains(t, err, "host port 0.0.0.0:9090/tcp is already published")
+}
+
+func TestRemovePortMappings(t *testing.T) {
+	ports := []cni.PortMapping{
+		{HostPort: 8080, ContainerPort: 80, Protocol: "tcp", HostIP: "0.0.0.0"},
+		{HostPort: 8081, ContainerPort: 80, Protocol: "tcp", HostIP: "::"},
+		{HostPort: 5353, ContainerPort: 53, Protocol: "udp", HostIP: "0.0.0.0"},
+	}
+	res, err := removePortMappings(ports, []containerPort{{80, "tcp"}})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, res, ports[2:])
+
+	_, err = removePortMappings(ports, []containerPort{{53, "tcp"}})
+	assert.ErrorContains(t, err, "no public port 53/tcp published")
+}
+
+func TestPortsDifference(t *testing.T) {
+	a := cni.PortMapping{HostPort: 8080, ContainerPort: 80, Protocol: "tcp", HostIP: "0.0.0.0"}
+	b := cni.PortMapping{HostPort: 8081, ContainerPort: 80, Protocol: "tcp", HostIP: "0.0.0.0"}
+	c := cni.PortMapping{HostPort: 8080, ContainerPort: 80, Protocol: "udp", HostIP: "0.0.0.0"}
+	assert.DeepEqual(t, portsDifference([]cni.PortMapping{a, b, c}, []cni.PortMapping{b}), []cni.PortMapping{a, c})
+	assert.DeepEqual(t, portsDifference([]cni.PortMapping{a}, []cni.PortMapping{a}), []cni.PortMapping(nil))
+	assert.DeepEqual(t, portsDifference(nil, []cni.PortMapping{a}), []cni.PortMapping(nil))
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"os"
 	"syscall"
+	"time"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/pkg/cio"
@@ -32,6 +33,7 @@ import (
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
 	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
@@ -39,11 +41,15 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/mountutil/volumestore"
 	"github.com/containerd/nerdctl/v2/pkg/namestore"
+	"github.com/containerd/nerdctl/v2/pkg/secretstore"
 	"github.com/containerd/nerdctl/v2/pkg/store"
 )
 
 var _ error = ErrContainerStatus{}
 
+// pausedTaskExitTimeout is how long `nerdctl rm -f` waits for a paused task to exit after SIGKILL.
+const pausedTaskExitTimeout = 10 * time.Second
+
 // ErrContainerStatus represents an error that container is in a status unexpected
 // by the caller. E.g., remove a non-stoped/non-created container without force.
 type ErrContainerStatus struct {
@@ -66,7 +72,8 @@ func NewStatusError(id string, status containerd.ProcessStatus) error {
 // Remove removes a list of `containers`.
 func Remove(ctx context.Context, client *containerd.Client, containers []string, options types.ContainerRemoveOptions) error {
 	walker := &containerwalker.ContainerWalker{
-		Client: client,
+		Client:   client,
+		Parallel: options.Parallel,
 		OnFound: func(ctx context.Context, found containerwalker.Found) error {
 			if found.MatchCount > 1 {
 				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
@@ -237,6 +244,15 @@ func RemoveContainer(ctx context.Context, c containerd.Container, globalOptions
 			log.G(ctx).WithError(err).Warnf("failed to remove hosts file for container %q", id)
 		}
 
+		// Remove the staged secrets - soft failure
+		if cs, err := secretstore.DecodeContainerSecrets(containerLabels[labels.Secrets]); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to decode the secrets of container %q", id)
+		} else if cs != nil {
+			if err = cs.Unstage(); err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to remove the secrets of container %q", id)
+			}
+		}
+
 		// Volume removal is not handled by the poststop hook lifecycle because it depends on removeAnonVolumes option
 		// Note that the anonymous volume list has been obtained earlier, without locking the volume store.
 		// Technically, a concurrent operation MAY have deleted these anonymous volumes already at this point, which
@@ -249,7 +265,25 @@ func RemoveContainer(ctx context.Context, c containerd.Container, globalOptions
 			} else {
 				var errs []error
 				_, errs, err = volStore.Remove(func() ([]string, []error, error) {
-					return anonVolumes, nil, nil
+					// Like Docker, keep the anonymous volumes that are still mounted by other containers
+					// (e.g., with --volumes-from). They can be removed later with `nerdctl volume prune`.
+					containers, err := client.Containers(ctx)
+					if err != nil {
+						return nil, nil, err
+					}
+					used, err := volume.UsedVolumes(ctx, containers)
+					if err != nil {
+						return nil, nil, err
+					}
+					var toRemove []string
+					for _, name := range anonVolumes {
+						if _, ok := used[name]; ok {
+							log.G(ctx).Debugf("anonymous volume %q is still in use, not removing", name)
+							continue
+						}
+						toRemove = append(toRemove, name)
+					}
+					return toRemove, nil, nil
 				})
 				if err != nil || len(errs) > 0 {
 					log.G(ctx).WithError(err).Warnf("failed to remove anonymous volumes %v", anonVolumes)
@@ -277,6 +311,25 @@ func RemoveContainer(ctx context.Context, c containerd.Container, globalOptions
 		if !force {
 			return NewStatusError(id, status.Status)
 		}
+		// Like `nerdctl stop`, resume the task after sending the signal, as the frozen processes
+		// may not be killed until thawed (cgroup v1).
+		es, err := task.Wait(ctx)
+		if err != nil {
+			return err
+		}
+		if err := task.Kill(ctx, syscall.SIGKILL); err != nil && !errdefs.IsNotFound(err) {
+			log.G(ctx).WithError(err).Warnf("failed to send SIGKILL to task %v", id)
+		}
+		if err := task.Resume(ctx); err != nil && !errdefs.IsNotFound(err) {
+			return fmt.Errorf("failed to resume task %v: %w", id, err)
+		}
+		waitCtx, waitCancel := context.WithTimeout(ctx, pausedTaskExitTimeout)
+		defer waitCancel()
+		select {
+		case <-es:
+		case <-waitCtx.Done():
+			return fmt.Errorf("task %v did not exit within %v after SIGKILL: %w", id, pausedTaskExitTimeout, waitCtx.Err())
+		}
 	case containerd.Running:
 		// Running containers only get removed if we force
 		if !force {
@@ -0,0 +1,187 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"text/tabwriter"
+	"text/template"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/runtime/restart"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+// RestartPolicyItem is an entry of `nerdctl container restart-policy ls`.
+type RestartPolicyItem struct {
+	ID   string
+	Name string
+	// Policy is the restart policy, "no" when not set
+	Policy string
+	// DesiredStatus is the status the restart manager keeps the container in, empty when not managed
+	DesiredStatus string
+}
+
+// ListRestartPolicies prints the restart policies of the containers specified by reqs and filters,
+// or of all the containers when none are specified.
+func ListRestartPolicies(ctx context.Context, client *containerd.Client, reqs []string, options types.ContainerRestartPolicyListOptions) error {
+	var tmpl *template.Template
+	switch options.Format {
+	case "", "table":
+	case "raw", "wide":
+		return errors.New("unsupported format: \"raw\" and \"wide\"")
+	default:
+		var err error
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+
+	containers, err := restartPolicyContainers(ctx, client, reqs, options.Filters, true)
+	if err != nil {
+		return err
+	}
+	w := options.Stdout
+	if tmpl == nil {
+		w = tabwriter.NewWriter(w, 4, 8, 4, ' ', 0)
+		fmt.Fprintln(w, "CONTAINER ID\tNAME\tRESTART POLICY\tDESIRED STATUS")
+	}
+	for _, c := range containers {
+		item, err := restartPolicyItem(ctx, c)
+		if err != nil {
+			return err
+		}
+		if tmpl != nil {
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, item); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w, b.String()); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", item.ID[:12], item.Name, item.Policy, item.DesiredStatus); err != nil {
+			return err
+		}
+	}
+	if f, ok := w.(formatter.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// SetRestartPolicy updates the restart policy of the containers specified by reqs and filters,
+// without re-creating them. The IDs of the updated containers are printed.
+func SetRestartPolicy(ctx context.Context, client *containerd.Client, reqs []string, options types.ContainerRestartPolicySetOptions) error {
+	if len(reqs) == 0 && len(options.Filters) == 0 {
+		return errors.New("requires at least one container or --filter")
+	}
+	if _, err := restart.NewPolicy(options.Policy); err != nil {
+		return err
+	}
+	if _, err := checkRestartCapabilities(ctx, client, options.Policy); err != nil {
+		return err
+	}
+	containers, err := restartPolicyContainers(ctx, client, reqs, options.Filters, false)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, c := range containers {
+		if err := UpdateContainerRestartPolicyLabel(ctx, client, c, options.Policy); err != nil {
+			errs = append(errs, fmt.Errorf("failed to update the restart policy of container %s: %w", c.ID(), err))
+			continue
+		}
+		fmt.Fprintln(options.Stdout, c.ID())
+	}
+	return errors.Join(errs...)
+}
+
+// restartPolicyContainers returns the containers specified by reqs, and the ones matching the filters.
+// When allByDefault is set and neither are specified, all the containers are returned.
+func restartPolicyContainers(ctx context.Context, client *containerd.Client, reqs, filters []string, allByDefault bool) ([]containerd.Container, error) {
+	if len(reqs) == 0 && len(filters) == 0 {
+		if !allByDefault {
+			return nil, nil
+		}
+		containers, _, err := filterContainers(ctx, client, nil, 0, true, false)
+		return containers, err
+	}
+
+	var res []containerd.Container
+	seen := make(map[string]bool)
+	add := func(c containerd.Container) {
+		if !seen[c.ID()] {
+			seen[c.ID()] = true
+			res = append(res, c)
+		}
+	}
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+			}
+			add(found.Container)
+			return nil
+		},
+	}
+	for _, req := range reqs {
+		n, err := walker.Walk(ctx, req)
+		if err != nil {
+			return nil, err
+		} else if n == 0 {
+			return nil, fmt.Errorf("no such container %s", req)
+		}
+	}
+	if len(filters) > 0 {
+		containers, _, err := filterContainers(ctx, client, filters, 0, true, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range containers {
+			add(c)
+		}
+	}
+	return res, nil
+}
+
+func restartPolicyItem(ctx context.Context, c containerd.Container) (RestartPolicyItem, error) {
+	l, err := c.Labels(ctx)
+	if err != nil {
+		return RestartPolicyItem{}, err
+	}
+	policy := l[restart.PolicyLabel]
+	if policy == "" {
+		policy = "no"
+	}
+	return RestartPolicyItem{
+		ID:            c.ID(),
+		Name:          l[labels.Name],
+		Policy:        policy,
+		DesiredStatus: l[restart.StatusLabel],
+	}, nil
+}
@@ -0,0 +1,58 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/containerd/containerd/v2/pkg/oci"
+	"github.com/containerd/typeurl/v2"
+
+	"github.com/containerd/nerdctl/v2/pkg/admission"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+// withAdmission admits the container with the admission policy of nerdctl.toml.
+// It must be applied after generating the spec s, as the admission hooks may mutate the labels and s.
+func withAdmission(s *oci.Spec, options types.ContainerCreateOptions) containerd.NewContainerOpts {
+	return func(ctx context.Context, client *containerd.Client, c *containers.Container) error {
+		cfg := options.Admission
+		if !cfg.DenyPrivileged && len(cfg.AllowedRegistries) == 0 && len(cfg.Hooks) == 0 {
+			return nil
+		}
+		req := &admission.Request{
+			Namespace:  options.GOptions.Namespace,
+			ID:         c.ID,
+			Name:       c.Labels[labels.Name],
+			Image:      c.Image,
+			Privileged: options.Privileged,
+			Labels:     c.Labels,
+			Spec:       s,
+		}
+		if err := admission.Admit(ctx, cfg, req); err != nil {
+			return err
+		}
+		c.Labels = req.Labels
+		*s = *req.Spec
+		var err error
+		c.Spec, err = typeurl.MarshalAnyToProto(s)
+		return err
+	}
+}
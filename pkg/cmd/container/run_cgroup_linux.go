@@ -67,6 +67,10 @@ func generateCgroupOpts(id string, options types.ContainerCreateOptions, interna
 		return []oci.SpecOpts{oci.WithCgroup("")}, nil
 	}
 
+	if err := resolveCPUSet(&options); err != nil {
+		return nil, err
+	}
+
 	var opts []oci.SpecOpts // nolint: prealloc
 	path, err := generateCgroupPath(id, options.GOptions.CgroupManager, options.CgroupParent)
 	if err != nil {
@@ -166,7 +170,10 @@ func generateCgroupOpts(id string, options types.ContainerCreateOptions, interna
 	if memReserve64 >= 0 && options.MemoryReservationChanged {
 		customMemRes.MemoryReservation = &memReserve64
 	}
-	if options.MemorySwappiness64 >= 0 && options.MemorySwappiness64Changed {
+	if options.MemorySwappiness64 >= 0 && options.MemorySwappiness64Changed && !infoutil.MemorySwappiness(options.GOptions.CgroupManager) {
+		// e.g., cgroup v2 has no equivalent of memory.swappiness
+		log.L.Warn("The kernel does not support memory swappiness, discarding --memory-swappiness")
+	} else if options.MemorySwappiness64 >= 0 && options.MemorySwappiness64Changed {
 		memSwapinessUint64 := uint64(options.MemorySwappiness64)
 		customMemRes.MemorySwappiness = &memSwapinessUint64
 	}
@@ -0,0 +1,71 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+)
+
+// RunConfig is the file written by `nerdctl run --config-out` and `nerdctl create --config-out`,
+// to be replayed with `nerdctl create --from-config` without depending on the quoting of a shell.
+type RunConfig struct {
+	// Flags are the flags of `nerdctl create` or `nerdctl run`, in the "--key=value" form
+	Flags []string `json:"flags,omitempty"`
+	// Image is the image (or the rootfs with --rootfs)
+	Image string `json:"image"`
+	// Args are the command and the arguments after the image
+	Args []string `json:"args,omitempty"`
+	// Dir is the working directory of the command, for reference.
+	// The relative paths in Flags are resolved from the working directory of the replaying command.
+	Dir string `json:"dir,omitempty"`
+}
+
+// ReadRunConfig reads the file written by --config-out.
+func ReadRunConfig(path string) (*RunConfig, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rc RunConfig
+	if err := json.Unmarshal(b, &rc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &rc, nil
+}
+
+// writeRunConfig writes the file of --config-out.
+func writeRunConfig(path string, args []string, options types.ContainerCreateOptions) error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	rc := RunConfig{
+		Flags: options.CreateFlags,
+		Image: args[0],
+		Args:  args[1:],
+		Dir:   dir,
+	}
+	b, err := json.MarshalIndent(rc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
@@ -0,0 +1,50 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+)
+
+func TestRunConfigRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.json")
+	options := types.ContainerCreateOptions{
+		CreateFlags: []string{"--name=foo", "--env=A=1,2", "--label=x=y"},
+	}
+	assert.NilError(t, writeRunConfig(path, []string{"alpine", "sh", "-c", "echo 'hello world'"}, options))
+
+	rc, err := ReadRunConfig(path)
+	assert.NilError(t, err)
+	wd, err := os.Getwd()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, rc, &RunConfig{
+		Flags: []string{"--name=foo", "--env=A=1,2", "--label=x=y"},
+		Image: "alpine",
+		Args:  []string{"sh", "-c", "echo 'hello world'"},
+		Dir:   wd,
+	})
+
+	assert.NilError(t, os.WriteFile(path, []byte("{"), 0o644))
+	_, err = ReadRunConfig(path)
+	assert.ErrorContains(t, err, "failed to parse")
+}
@@ -0,0 +1,163 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+)
+
+// sysfsRoot is the root of sysfs, replaced in tests.
+var sysfsRoot = "/sys"
+
+// parseCPUSet parses a list like "0-3,7" in the format of cpuset.cpus and cpuset.mems, and returns the sorted unique numbers.
+func parseCPUSet(s string) ([]int, error) {
+	var res []int
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return res, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		startStr, endStr, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(startStr)
+		if err != nil || start < 0 {
+			return nil, fmt.Errorf("invalid cpuset %q", s)
+		}
+		end := start
+		if isRange {
+			end, err = strconv.Atoi(endStr)
+			if err != nil || end < start {
+				return nil, fmt.Errorf("invalid cpuset %q", s)
+			}
+		}
+		for i := start; i <= end; i++ {
+			res = append(res, i)
+		}
+	}
+	slices.Sort(res)
+	return slices.Compact(res), nil
+}
+
+// formatCPUSet formats the sorted unique numbers into the shortest list like "0-3,7".
+func formatCPUSet(set []int) string {
+	var parts []string
+	for i := 0; i < len(set); {
+		j := i
+		for j+1 < len(set) && set[j+1] == set[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, strconv.Itoa(set[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", set[i], set[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
+// readSysfsCPUSet reads a list file of sysfs, e.g., "devices/system/cpu/online".
+func readSysfsCPUSet(rel string) ([]int, error) {
+	b, err := os.ReadFile(filepath.Join(sysfsRoot, rel))
+	if err != nil {
+		return nil, err
+	}
+	return parseCPUSet(string(b))
+}
+
+// validateCPUSet checks that the cpuset is parsable, and that its numbers are online on the host.
+// The check against the host is skipped when the list of the online ones is not readable.
+func validateCPUSet(flagName, s, onlineRel string) error {
+	set, err := parseCPUSet(s)
+	if err != nil {
+		return fmt.Errorf("invalid --%s: %w", flagName, err)
+	}
+	online, err := readSysfsCPUSet(onlineRel)
+	if err != nil {
+		return nil
+	}
+	var unavailable []int
+	for _, i := range set {
+		if _, found := slices.BinarySearch(online, i); !found {
+			unavailable = append(unavailable, i)
+		}
+	}
+	if len(unavailable) > 0 {
+		return fmt.Errorf("invalid --%s %q: %s not available on the host (available: %s)", flagName, s, formatCPUSet(unavailable), formatCPUSet(online))
+	}
+	return nil
+}
+
+// numaNodeCPUSet returns the cpuset.cpus and cpuset.mems of the NUMA nodes, derived from the host topology.
+func numaNodeCPUSet(nodes string) (string, string, error) {
+	nodeSet, err := parseCPUSet(nodes)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid --numa-node: %w", err)
+	}
+	if len(nodeSet) == 0 {
+		return "", "", errors.New("invalid --numa-node: no node specified")
+	}
+	var cpus []int
+	for _, node := range nodeSet {
+		nodeCPUs, err := readSysfsCPUSet(fmt.Sprintf("devices/system/node/node%d/cpulist", node))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return "", "", fmt.Errorf("invalid --numa-node %q: node %d does not exist on the host", nodes, node)
+			}
+			return "", "", err
+		}
+		cpus = append(cpus, nodeCPUs...)
+	}
+	slices.Sort(cpus)
+	cpus = slices.Compact(cpus)
+	if len(cpus) == 0 {
+		return "", "", fmt.Errorf("invalid --numa-node %q: no CPU in the nodes", nodes)
+	}
+	return formatCPUSet(cpus), formatCPUSet(nodeSet), nil
+}
+
+// resolveCPUSet applies --numa-node to the cpuset options, and validates them.
+func resolveCPUSet(options *types.ContainerCreateOptions) error {
+	if options.NumaNode != "" {
+		if options.CPUSetCPUs != "" || options.CPUSetMems != "" {
+			return errors.New("--numa-node cannot be specified with --cpuset-cpus or --cpuset-mems")
+		}
+		cpus, mems, err := numaNodeCPUSet(options.NumaNode)
+		if err != nil {
+			return err
+		}
+		options.CPUSetCPUs, options.CPUSetMems = cpus, mems
+	}
+	if options.CPUSetCPUs != "" {
+		if err := validateCPUSet("cpuset-cpus", options.CPUSetCPUs, "devices/system/cpu/online"); err != nil {
+			return err
+		}
+	}
+	if options.CPUSetMems != "" {
+		if err := validateCPUSet("cpuset-mems", options.CPUSetMems, "devices/system/node/online"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
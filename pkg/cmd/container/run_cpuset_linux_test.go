@@ -0,0 +1,97 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+)
+
+func TestParseCPUSet(t *testing.T) {
+	for _, tc := range []struct {
+		s      string
+		set    []int
+		format string
+	}{
+		{"", nil, ""},
+		{"0", []int{0}, "0"},
+		{"0-3", []int{0, 1, 2, 3}, "0-3"},
+		{"3,1,0-1,5-6", []int{0, 1, 3, 5, 6}, "0-1,3,5-6"},
+		{"0-3\n", []int{0, 1, 2, 3}, "0-3"},
+	} {
+		set, err := parseCPUSet(tc.s)
+		assert.NilError(t, err, tc.s)
+		assert.DeepEqual(t, set, tc.set)
+		assert.Equal(t, formatCPUSet(set), tc.format)
+	}
+	for _, s := range []string{"a", "1-", "3-1", "-1", "0,,1"} {
+		_, err := parseCPUSet(s)
+		assert.ErrorContains(t, err, "invalid cpuset", s)
+	}
+}
+
+func writeSysfs(t *testing.T, root string, files map[string]string) {
+	for rel, content := range files {
+		p := filepath.Join(root, rel)
+		assert.NilError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		assert.NilError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+}
+
+func TestResolveCPUSet(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, map[string]string{
+		"devices/system/cpu/online":         "0-7\n",
+		"devices/system/node/online":        "0-1\n",
+		"devices/system/node/node0/cpulist": "0-3\n",
+		"devices/system/node/node1/cpulist": "4-7\n",
+	})
+	orig := sysfsRoot
+	sysfsRoot = root
+	t.Cleanup(func() { sysfsRoot = orig })
+
+	for _, tc := range []struct {
+		options types.ContainerCreateOptions
+		cpus    string
+		mems    string
+		errMsg  string
+	}{
+		{options: types.ContainerCreateOptions{NumaNode: "1"}, cpus: "4-7", mems: "1"},
+		{options: types.ContainerCreateOptions{NumaNode: "0,1"}, cpus: "0-7", mems: "0-1"},
+		{options: types.ContainerCreateOptions{NumaNode: "2"}, errMsg: "node 2 does not exist"},
+		{options: types.ContainerCreateOptions{NumaNode: "0", CPUSetCPUs: "0"}, errMsg: "cannot be specified with"},
+		{options: types.ContainerCreateOptions{CPUSetCPUs: "2-3", CPUSetMems: "0"}, cpus: "2-3", mems: "0"},
+		{options: types.ContainerCreateOptions{CPUSetCPUs: "6-9"}, errMsg: "8-9 not available on the host (available: 0-7)"},
+		{options: types.ContainerCreateOptions{CPUSetMems: "2"}, errMsg: "invalid --cpuset-mems"},
+		{options: types.ContainerCreateOptions{CPUSetCPUs: "x"}, errMsg: "invalid --cpuset-cpus"},
+	} {
+		options := tc.options
+		err := resolveCPUSet(&options)
+		if tc.errMsg != "" {
+			assert.ErrorContains(t, err, tc.errMsg)
+			continue
+		}
+		assert.NilError(t, err)
+		assert.Equal(t, options.CPUSetCPUs, tc.cpus)
+		assert.Equal(t, options.CPUSetMems, tc.mems)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/containerd/containerd/v2/pkg/oci"
+	"github.com/containerd/log"
+)
+
+// defaultInitBinary is the default of --init-binary.
+const defaultInitBinary = "tini"
+
+// dockerInitBinaries are tini builds installed by Docker, used when defaultInitBinary is not found.
+var dockerInitBinaries = []string{"docker-init", "/usr/libexec/docker/docker-init"}
+
+// withInit runs the process under the init binary, bind-mounted to /sbin.
+func withInit(initBinary string) (oci.SpecOpts, error) {
+	var dirs []string
+	if self, err := os.Executable(); err == nil {
+		if self, err = filepath.EvalSymlinks(self); err == nil {
+			// nerdctl-full ships tini next to nerdctl, e.g., in /usr/local/bin.
+			dirs = append(dirs, filepath.Dir(self))
+		}
+	}
+	binaryPath, err := lookInitBinary(initBinary, dirs)
+	if err != nil {
+		return nil, err
+	}
+	inContainerPath := filepath.Join("/sbin", filepath.Base(initBinary))
+	return func(ctx context.Context, _ oci.Client, _ *containers.Container, spec *oci.Spec) error {
+		if len(spec.Process.Args) > 0 && filepath.Base(spec.Process.Args[0]) == filepath.Base(initBinary) {
+			// The entrypoint of the image is the init already, e.g., `ENTRYPOINT ["/sbin/tini", "--"]`.
+			log.G(ctx).Debugf("not wrapping %v with %q, as it is the init already", spec.Process.Args, inContainerPath)
+			return nil
+		}
+		spec.Process.Args = append([]string{inContainerPath, "--"}, spec.Process.Args...)
+		spec.Mounts = append([]specs.Mount{{
+			Destination: inContainerPath,
+			Type:        "bind",
+			Source:      binaryPath,
+			Options:     []string{"bind", "ro"},
+		}}, spec.Mounts...)
+		return nil
+	}, nil
+}
+
+// lookInitBinary looks for the init binary in $PATH, and then in dirs.
+// When the default init binary is not found, Docker's tini build is used if installed.
+func lookInitBinary(initBinary string, dirs []string) (string, error) {
+	if strings.ContainsRune(initBinary, os.PathSeparator) {
+		return exec.LookPath(initBinary)
+	}
+	candidates := []string{initBinary}
+	for _, dir := range dirs {
+		candidates = append(candidates, filepath.Join(dir, initBinary))
+	}
+	if initBinary == defaultInitBinary {
+		candidates = append(candidates, dockerInitBinaries...)
+	}
+	for _, candidate := range candidates {
+		binaryPath, err := exec.LookPath(candidate)
+		if err == nil {
+			return binaryPath, nil
+		}
+		if !errors.Is(err, exec.ErrNotFound) && !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("init binary %q not found in $PATH, nor in %v (Hint: install tini, or specify --init-binary)", initBinary, dirs)
+}
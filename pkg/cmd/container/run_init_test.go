@@ -0,0 +1,45 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLookInitBinary(t *testing.T) {
+	dir := t.TempDir()
+	binaryPath := filepath.Join(dir, "nerdctl-test-init")
+	assert.NilError(t, os.WriteFile(binaryPath, []byte("#!/bin/sh\n"), 0o755))
+
+	found, err := lookInitBinary("nerdctl-test-init", []string{t.TempDir(), dir})
+	assert.NilError(t, err)
+	assert.Equal(t, found, binaryPath)
+
+	found, err = lookInitBinary(binaryPath, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, found, binaryPath)
+
+	_, err = lookInitBinary("nerdctl-test-init", nil)
+	assert.ErrorContains(t, err, `init binary "nerdctl-test-init" not found`)
+
+	_, err = lookInitBinary(filepath.Join(dir, "nonexistent"), nil)
+	assert.Assert(t, err != nil)
+}
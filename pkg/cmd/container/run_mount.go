@@ -24,11 +24,13 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
 	securejoin "github.com/cyphar/filepath-securejoin"
+	"github.com/docker/go-units"
 	"github.com/moby/sys/userns"
 	"github.com/opencontainers/image-spec/identity"
 	"github.com/opencontainers/runtime-spec/specs-go"
@@ -43,12 +45,14 @@ import (
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
 	"github.com/containerd/nerdctl/v2/pkg/idgen"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/mountutil"
 	"github.com/containerd/nerdctl/v2/pkg/mountutil/volumestore"
+	"github.com/containerd/nerdctl/v2/pkg/platformutil"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
@@ -139,7 +143,7 @@ func generateMountOpts(ctx context.Context, client *containerd.Client, ensuredIm
 	if ensuredImage != nil {
 		imageVolumes = ensuredImage.ImageConfig.Volumes
 
-		if err := ensuredImage.Image.Unpack(ctx, options.GOptions.Snapshotter); err != nil {
+		if err := ensuredImage.Image.Unpack(ctx, ensuredImage.Snapshotter); err != nil {
 			return nil, nil, nil, fmt.Errorf("error unpacking image: %w", err)
 		}
 
@@ -149,7 +153,7 @@ func generateMountOpts(ctx context.Context, client *containerd.Client, ensuredIm
 		}
 		chainID := identity.ChainID(diffIDs).String()
 
-		s := client.SnapshotService(options.GOptions.Snapshotter)
+		s := client.SnapshotService(ensuredImage.Snapshotter)
 		tempDir, err = os.MkdirTemp("", "initialC")
 		if err != nil {
 			return nil, nil, nil, err
@@ -217,10 +221,20 @@ func generateMountOpts(ctx context.Context, client *containerd.Client, ensuredIm
 	if parsed, err := parseMountFlags(volStore, options); err != nil {
 		return nil, nil, nil, err
 	} else if len(parsed) > 0 {
-		ociMounts := make([]specs.Mount, len(parsed))
-		for i, x := range parsed {
-			ociMounts[i] = x.Mount
+		ociMounts := make([]specs.Mount, 0, len(parsed))
+		imageMounts := 0
+		for _, x := range parsed {
 			mounted[filepath.Clean(x.Mount.Destination)] = struct{}{}
+			if x.Type == mountutil.Image {
+				opt, err := generateImageMountOpt(ctx, client, x, imageMounts, options)
+				if err != nil {
+					return nil, nil, nil, err
+				}
+				opts = append(opts, opt)
+				imageMounts++
+				continue
+			}
+			ociMounts = append(ociMounts, x.Mount)
 
 			target, err := securejoin.SecureJoin(tempDir, x.Mount.Destination)
 			if err != nil {
@@ -290,6 +304,17 @@ func generateMountOpts(ctx context.Context, client *containerd.Client, ensuredIm
 		mountPoints = append(mountPoints, mountPoint)
 	}
 
+	if options.ReadOnly && runtime.GOOS == "linux" {
+		tmpfsMounts, err := readOnlyTmpfsMounts(options.ReadOnlyTmpfsSize, userMounts)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for _, x := range tmpfsMounts {
+			userMounts = append(userMounts, x.Mount)
+			mountPoints = append(mountPoints, x)
+		}
+	}
+
 	opts = append(opts, withMounts(userMounts))
 
 	containers, err := client.Containers(ctx)
@@ -299,7 +324,6 @@ func generateMountOpts(ctx context.Context, client *containerd.Client, ensuredIm
 
 	vfSet := strutil.SliceToSet(options.VolumesFrom)
 	var vfMountPoints []dockercompat.MountPoint
-	var vfAnonVolumes []string
 
 	for _, c := range containers {
 		ls, err := c.Labels(ctx)
@@ -319,12 +343,6 @@ func generateMountOpts(ctx context.Context, client *containerd.Client, ensuredIm
 		}
 
 		if idMatch || nameMatch {
-			if av, found := ls[labels.AnonymousVolumes]; found {
-				err = json.Unmarshal([]byte(av), &vfAnonVolumes)
-				if err != nil {
-					return nil, nil, nil, err
-				}
-			}
 			if m, found := ls[labels.Mounts]; found {
 				err = json.Unmarshal([]byte(m), &vfMountPoints)
 				if err != nil {
@@ -337,8 +355,9 @@ func generateMountOpts(ctx context.Context, client *containerd.Client, ensuredIm
 			if err != nil {
 				return nil, nil, nil, err
 			}
+			// The anonymous volumes of the source container are not added to anonVolumes,
+			// as they are owned by the source container, not by this one.
 			opts = append(opts, withMounts(s.Mounts))
-			anonVolumes = append(anonVolumes, vfAnonVolumes...)
 			mountPoints = append(mountPoints, ps...)
 		}
 	}
@@ -346,6 +365,45 @@ func generateMountOpts(ctx context.Context, client *containerd.Client, ensuredIm
 	return opts, anonVolumes, mountPoints, nil
 }
 
+// readOnlyTmpfsMounts returns the tmpfs mounts on /run and /tmp for `--read-only`, unless they are already mounted.
+// The size "0" disables the tmpfs mounts, and the empty size does not limit the size.
+func readOnlyTmpfsMounts(size string, mounts []specs.Mount) ([]*mountutil.Processed, error) {
+	if size == "0" {
+		return nil, nil
+	}
+	var sizeOpt []string
+	if size != "" {
+		if _, err := units.RAMInBytes(size); err != nil {
+			return nil, fmt.Errorf("invalid --read-only-tmpfs-size %q: %w", size, err)
+		}
+		sizeOpt = []string{"size=" + size}
+	}
+	var res []*mountutil.Processed
+	for _, dir := range []struct {
+		dst  string
+		mode string
+	}{
+		{"/run", "755"},
+		{"/tmp", "1777"},
+	} {
+		if slices.ContainsFunc(mounts, func(m specs.Mount) bool { return filepath.Clean(m.Destination) == dir.dst }) {
+			continue
+		}
+		options := append([]string{"nosuid", "nodev", "mode=" + dir.mode}, sizeOpt...)
+		res = append(res, &mountutil.Processed{
+			Type: mountutil.Tmpfs,
+			Mount: specs.Mount{
+				Type:        "tmpfs",
+				Source:      "tmpfs",
+				Destination: dir.dst,
+				Options:     options,
+			},
+			Mode: strings.Join(options, ","),
+		})
+	}
+	return res, nil
+}
+
 // copyExistingContents copies from the source to the destination and
 // ensures the ownership is appropriately set.
 func copyExistingContents(source, destination string) error {
@@ -362,3 +420,79 @@ func copyExistingContents(source, destination string) error {
 	}
 	return fs.CopyDir(destination, source)
 }
+
+// generateImageMountOpt ensures the image of `--mount type=image`, and returns the SpecOpts that mounts its rootfs.
+// The snapshot is created when the container is created (under the lease of client.NewContainer),
+// and it is referenced by a label of the container so that it is garbage collected with the container.
+func generateImageMountOpt(ctx context.Context, client *containerd.Client, x *mountutil.Processed, index int, options types.ContainerCreateOptions) (oci.SpecOpts, error) {
+	var platformSS []string // len: 0 or 1
+	if options.Platform != "" {
+		platformSS = append(platformSS, options.Platform)
+	}
+	ocispecPlatforms, err := platformutil.NewOCISpecPlatformSlice(false, platformSS)
+	if err != nil {
+		return nil, err
+	}
+	pullOpt := options.ImagePullOpt
+	pullOpt.Mode = options.Pull
+	pullOpt.OCISpecPlatform = ocispecPlatforms
+	pullOpt.RequirePlatform = options.Platform != ""
+	pullOpt.Unpack = nil
+	ensured, err := image.EnsureImage(ctx, client, x.Mount.Source, pullOpt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ensure the image of the image mount %q: %w", x.Mount.Destination, err)
+	}
+	snapshotter := ensured.Snapshotter
+	if err := ensured.Image.Unpack(ctx, snapshotter); err != nil {
+		return nil, fmt.Errorf("error unpacking image: %w", err)
+	}
+	diffIDs, err := ensured.Image.RootFS(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chainID := identity.ChainID(diffIDs).String()
+	readonly := x.Mode != "rw"
+	// Record the resolved reference for inspect
+	x.Mount.Source = ensured.Ref
+
+	return func(ctx context.Context, client oci.Client, c *containers.Container, s *specs.Spec) error {
+		key := fmt.Sprintf("%s-image-mount-%d", c.ID, index)
+		sn := client.SnapshotService(snapshotter)
+		var (
+			mounts []mount.Mount
+			err    error
+		)
+		if readonly {
+			mounts, err = sn.View(ctx, key, chainID)
+		} else {
+			// A writable mount gets its own layer, which is discarded with the container
+			mounts, err = sn.Prepare(ctx, key, chainID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create the snapshot of the image mount %q: %w", x.Mount.Destination, err)
+		}
+		if len(mounts) != 1 || strings.Contains(mounts[0].Type, "/") {
+			return fmt.Errorf("snapshotter %q is not supported for image mounts (mounts: %+v)", snapshotter, mounts)
+		}
+		m := mounts[0]
+		if m.Type == "bind" && userns.RunningInUserNS() {
+			// For https://github.com/containerd/nerdctl/issues/2056
+			unpriv, err := mountutil.UnprivilegedMountFlags(m.Source)
+			if err != nil {
+				return err
+			}
+			m.Options = strutil.DedupeStrSlice(append(m.Options, unpriv...))
+		}
+		if c.Labels == nil {
+			c.Labels = make(map[string]string)
+		}
+		c.Labels[fmt.Sprintf("containerd.io/gc.ref.snapshot.%s/image-mount-%d", snapshotter, index)] = key
+		s.Mounts = append(s.Mounts, specs.Mount{
+			Type:        m.Type,
+			Source:      m.Source,
+			Destination: x.Mount.Destination,
+			Options:     m.Options,
+		})
+		return nil
+	}, nil
+}
@@ -0,0 +1,46 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gotest.tools/v3/assert"
+)
+
+func TestReadOnlyTmpfsMounts(t *testing.T) {
+	res, err := readOnlyTmpfsMounts("64m", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(res), 2)
+	assert.Equal(t, res[0].Mount.Destination, "/run")
+	assert.DeepEqual(t, res[0].Mount.Options, []string{"nosuid", "nodev", "mode=755", "size=64m"})
+	assert.Equal(t, res[1].Mount.Destination, "/tmp")
+	assert.Equal(t, res[1].Mode, "nosuid,nodev,mode=1777,size=64m")
+
+	res, err = readOnlyTmpfsMounts("", []specs.Mount{{Destination: "/tmp/"}})
+	assert.NilError(t, err)
+	assert.Equal(t, len(res), 1)
+	assert.DeepEqual(t, res[0].Mount.Options, []string{"nosuid", "nodev", "mode=755"})
+
+	res, err = readOnlyTmpfsMounts("0", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(res), 0)
+
+	_, err = readOnlyTmpfsMounts("lots", nil)
+	assert.ErrorContains(t, err, "invalid --read-only-tmpfs-size")
+}
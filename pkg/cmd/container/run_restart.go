@@ -23,6 +23,7 @@ import (
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/runtime/restart"
+	"github.com/containerd/errdefs"
 
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
@@ -92,18 +93,23 @@ func UpdateContainerRestartPolicyLabel(ctx context.Context, client *containerd.C
 	}
 	_, statusLabelExist := lables[restart.StatusLabel]
 	if !statusLabelExist {
+		desireStatus := containerd.Running
 		task, err := container.Task(ctx, nil)
 		if err != nil {
-			return fmt.Errorf("failed to get task:%w", err)
-		}
-		desireStatus := containerd.Running
-		status, err := task.Status(ctx)
-		if err == nil {
-			switch status.Status {
-			case containerd.Stopped:
-				desireStatus = containerd.Stopped
-			case containerd.Created:
-				desireStatus = containerd.Created
+			if !errdefs.IsNotFound(err) {
+				return fmt.Errorf("failed to get task:%w", err)
+			}
+			// the container has not been started yet, e.g., with `nerdctl create`
+			desireStatus = containerd.Created
+		} else {
+			status, err := task.Status(ctx)
+			if err == nil {
+				switch status.Status {
+				case containerd.Stopped:
+					desireStatus = containerd.Stopped
+				case containerd.Created:
+					desireStatus = containerd.Created
+				}
 			}
 		}
 		updateOpts = append(updateOpts, restart.WithStatus(desireStatus))
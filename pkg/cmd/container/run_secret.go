@@ -0,0 +1,62 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/containerd/containerd/v2/pkg/oci"
+
+	"github.com/containerd/nerdctl/v2/pkg/secretstore"
+)
+
+// withSecrets stages the secrets of --secret on the host, and bind-mounts them to the container, read-only.
+func withSecrets(dataStore, namespace, id string, secrets []string) (*secretstore.ContainerSecrets, oci.SpecOpts, error) {
+	if len(secrets) == 0 {
+		return nil, nil, nil
+	}
+	stagingDir, err := secretstore.StagingDir(namespace, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	cs := &secretstore.ContainerSecrets{
+		DataStore:  dataStore,
+		StagingDir: stagingDir,
+	}
+	targets := make(map[string]string)
+	for _, s := range secrets {
+		ref, err := secretstore.ParseReference(s)
+		if err != nil {
+			return nil, nil, err
+		}
+		if name, ok := targets[ref.Target]; ok {
+			return nil, nil, fmt.Errorf("secrets %q and %q have the same target %q", name, ref.Name, ref.Target)
+		}
+		targets[ref.Target] = ref.Name
+		cs.References = append(cs.References, ref)
+	}
+	if err := cs.Stage(namespace); err != nil {
+		return nil, nil, errors.Join(err, cs.Unstage())
+	}
+	return cs, func(_ context.Context, _ oci.Client, _ *containers.Container, spec *oci.Spec) error {
+		spec.Mounts = append(spec.Mounts, cs.Mounts()...)
+		return nil
+	}, nil
+}
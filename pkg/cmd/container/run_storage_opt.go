@@ -0,0 +1,95 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	containerd "github.com/containerd/containerd/v2/client"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/quota"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+// parseStorageOpts parses the --storage-opt flags, and returns them with the size limit of the writable layer.
+// Only "size" is supported, which is implemented with the project quota of the snapshot directory.
+func parseStorageOpts(options types.ContainerCreateOptions) (map[string]string, uint64, error) {
+	if len(options.StorageOpt) == 0 {
+		return nil, 0, nil
+	}
+	res := make(map[string]string)
+	var size uint64
+	for _, opt := range options.StorageOpt {
+		k, v, ok := strings.Cut(opt, "=")
+		if !ok {
+			return nil, 0, fmt.Errorf("invalid storage option %q, expected KEY=VALUE", opt)
+		}
+		if k != "size" {
+			return nil, 0, fmt.Errorf("unsupported storage option %q", k)
+		}
+		var err error
+		if size, err = quota.ParseSize(v); err != nil {
+			return nil, 0, err
+		}
+		res[k] = v
+	}
+	if options.Rootfs {
+		return nil, 0, errors.New("--storage-opt size cannot be used with --rootfs")
+	}
+	if rootlessutil.IsRootless() {
+		return nil, 0, errors.New("--storage-opt size is not supported in rootless mode, as setting project quotas requires the root privileges")
+	}
+	return res, size, nil
+}
+
+// setWritableLayerQuota limits the size of the writable layer of the container.
+// The snapshotter has to expose the writable layer as a directory ("overlayfs" or "native"),
+// on a file system with project quotas enabled.
+func setWritableLayerQuota(ctx context.Context, client *containerd.Client, c containerd.Container, size uint64) error {
+	info, err := c.Info(ctx, containerd.WithoutRefreshedMetadata)
+	if err != nil {
+		return err
+	}
+	mounts, err := client.SnapshotService(info.Snapshotter).Mounts(ctx, info.SnapshotKey)
+	if err != nil {
+		return err
+	}
+	var dir string
+	for _, m := range mounts {
+		switch m.Type {
+		case "overlay":
+			for _, o := range m.Options {
+				if s, ok := strings.CutPrefix(o, "upperdir="); ok {
+					dir = s
+				}
+			}
+		case "bind":
+			dir = m.Source
+		}
+	}
+	if dir == "" {
+		return fmt.Errorf("--storage-opt size is not supported with snapshotter %q", info.Snapshotter)
+	}
+	if err := quota.Set(dir, size); err != nil {
+		return fmt.Errorf("failed to limit the size of the writable layer: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/containerd/containerd/v2/pkg/oci"
+)
+
+// localTimezone is the value of --tz for using the timezone of the host.
+const localTimezone = "local"
+
+const hostLocaltime = "/etc/localtime"
+
+// zoneinfoDirs are the directories of the tz database on the host, from Go's time package.
+var zoneinfoDirs = []string{
+	"/usr/share/zoneinfo",
+	"/usr/share/lib/zoneinfo",
+	"/usr/lib/locale/TZ",
+	"/etc/zoneinfo",
+}
+
+// posixTZRegexp matches the POSIX TZ strings that have no file in the tz database, e.g., "JST-9" or "<+0330>-3:30".
+var posixTZRegexp = regexp.MustCompile(`^([A-Za-z]{3,}|<[A-Za-z0-9+-]{3,}>)[+-]?[0-9]`)
+
+// withTimezone sets the timezone of the container.
+//
+// The zone file is bind-mounted to /etc/localtime, so that the timezone works even for the images
+// without the tz database (e.g., distroless, alpine).
+// A POSIX TZ string that is not in the tz database is set as $TZ instead, which is returned as envs.
+func withTimezone(tz string) (oci.SpecOpts, []string, error) {
+	if tz == "" {
+		return nil, nil, nil
+	}
+	zoneFile, err := resolveTimezone(tz, zoneinfoDirs, hostLocaltime)
+	if err != nil {
+		return nil, nil, err
+	}
+	if zoneFile == "" {
+		return nil, []string{"TZ=" + tz}, nil
+	}
+	return func(_ context.Context, _ oci.Client, _ *containers.Container, spec *oci.Spec) error {
+		spec.Mounts = append(spec.Mounts, specs.Mount{
+			Destination: "/etc/localtime",
+			Type:        "bind",
+			Source:      zoneFile,
+			Options:     []string{"rbind", "ro"},
+		})
+		return nil
+	}, nil, nil
+}
+
+// resolveTimezone returns the zone file of tz on the host, or "" for a POSIX TZ string.
+func resolveTimezone(tz string, dirs []string, localtime string) (string, error) {
+	if tz == localTimezone {
+		zoneFile, err := filepath.EvalSymlinks(localtime)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve the timezone of the host: %w", err)
+		}
+		if err := checkZoneFile(zoneFile); err != nil {
+			return "", fmt.Errorf("failed to resolve the timezone of the host: %w", err)
+		}
+		return zoneFile, nil
+	}
+	if filepath.IsLocal(tz) {
+		for _, dir := range dirs {
+			zoneFile, err := filepath.EvalSymlinks(filepath.Join(dir, tz))
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					continue
+				}
+				return "", err
+			}
+			if err := checkZoneFile(zoneFile); err != nil {
+				return "", fmt.Errorf("invalid timezone %q: %w", tz, err)
+			}
+			return zoneFile, nil
+		}
+	}
+	if posixTZRegexp.MatchString(tz) {
+		return "", nil
+	}
+	return "", fmt.Errorf("unknown timezone %q (Hint: specify a name in the tz database like \"Asia/Tokyo\", or %q)", tz, localTimezone)
+}
+
+// checkZoneFile checks that the file is in the TZif format.
+func checkZoneFile(zoneFile string) error {
+	f, err := os.Open(zoneFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(f, magic); err != nil || !bytes.Equal(magic, []byte("TZif")) {
+		return fmt.Errorf("%s is not a zone file", zoneFile)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestResolveTimezone(t *testing.T) {
+	dir := t.TempDir()
+	zoneFile := filepath.Join(dir, "Asia", "Tokyo")
+	assert.NilError(t, os.MkdirAll(filepath.Dir(zoneFile), 0o755))
+	assert.NilError(t, os.WriteFile(zoneFile, []byte("TZif2\n"), 0o644))
+	localtime := filepath.Join(t.TempDir(), "localtime")
+	assert.NilError(t, os.Symlink(zoneFile, localtime))
+	dirs := []string{t.TempDir(), dir}
+
+	resolved, err := resolveTimezone("Asia/Tokyo", dirs, localtime)
+	assert.NilError(t, err)
+	assert.Equal(t, resolved, zoneFile)
+
+	resolved, err = resolveTimezone("local", dirs, localtime)
+	assert.NilError(t, err)
+	assert.Equal(t, resolved, zoneFile)
+
+	// POSIX TZ strings are set as $TZ
+	for _, tz := range []string{"JST-9", "EST5EDT", "<+0330>-3:30"} {
+		resolved, err = resolveTimezone(tz, dirs, localtime)
+		assert.NilError(t, err, tz)
+		assert.Equal(t, resolved, "", tz)
+	}
+
+	_, err = resolveTimezone("Asia", dirs, localtime)
+	assert.ErrorContains(t, err, "is not a zone file")
+
+	for _, tz := range []string{"Mars/Olympus_Mons", "../Asia/Tokyo", "/etc/passwd"} {
+		_, err = resolveTimezone(tz, dirs, localtime)
+		assert.ErrorContains(t, err, "unknown timezone", tz)
+	}
+}
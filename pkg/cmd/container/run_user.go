@@ -18,21 +18,133 @@ package container
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"runtime"
+	"slices"
 	"strconv"
 
+	"github.com/moby/sys/user"
+	"github.com/opencontainers/runtime-spec/specs-go"
+
 	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/containerd/containerd/v2/core/mount"
 	"github.com/containerd/containerd/v2/pkg/oci"
+	"github.com/containerd/continuity/fs"
 )
 
-func generateUserOpts(user string) ([]oci.SpecOpts, error) {
+func generateUserOpts(user string, groupAdd []string) ([]oci.SpecOpts, error) {
 	var opts []oci.SpecOpts
-	if user != "" {
-		opts = append(opts, oci.WithUser(user), withResetAdditionalGIDs(), oci.WithAdditionalGIDs(user))
+	if user != "" || len(groupAdd) != 0 {
+		opts = append(opts, withUser(user, groupAdd, ""))
 	}
 	return opts, nil
 }
 
+// withUser sets the user of the process and its additional groups, like Docker does.
+//
+// The user ("name", "uid", "name:group", "uid:gid", ...) and the groups of groupAdd
+// are resolved against /etc/passwd and /etc/group of the rootfs. The user also joins
+// its primary group and the groups listing it as a member, unless the group is explicit.
+// When root is empty, the rootfs snapshot of the container is mounted read-only.
+// When user is empty, groupAdd is appended to the current additional groups.
+func withUser(userstr string, groupAdd []string, root string) oci.SpecOpts {
+	return func(ctx context.Context, client oci.Client, c *containers.Container, s *oci.Spec) error {
+		// Windows and LCOW containers, and Darwin hosts, cannot read the rootfs here.
+		if s.Linux == nil || s.Windows != nil || runtime.GOOS == "darwin" {
+			var opts []oci.SpecOpts
+			if userstr != "" {
+				opts = append(opts, oci.WithUser(userstr), withResetAdditionalGIDs(), oci.WithAdditionalGIDs(userstr))
+			}
+			if len(groupAdd) != 0 {
+				opts = append(opts, oci.WithAppendAdditionalGroups(groupAdd...))
+			}
+			for _, opt := range opts {
+				if err := opt(ctx, client, c, s); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if s.Process == nil {
+			s.Process = &specs.Process{}
+		}
+		f := func(root string) error {
+			u, err := resolveUser(root, userstr, groupAdd, s.Process.User)
+			if err != nil {
+				return err
+			}
+			s.Process.User = u
+			return nil
+		}
+		if root != "" {
+			return f(root)
+		}
+		if c.Snapshotter == "" && c.SnapshotKey == "" {
+			if !filepath.IsAbs(s.Root.Path) {
+				return errors.New("rootfs absolute path is required")
+			}
+			return f(s.Root.Path)
+		}
+		if c.Snapshotter == "" {
+			return errors.New("no snapshotter set for container")
+		}
+		if c.SnapshotKey == "" {
+			return errors.New("rootfs snapshot not created for container")
+		}
+		mounts, err := client.SnapshotService(c.Snapshotter).Mounts(ctx, c.SnapshotKey)
+		if err != nil {
+			return err
+		}
+		return mount.WithReadonlyTempMount(ctx, mounts, f)
+	}
+}
+
+// resolveUser resolves userstr and groupAdd against /etc/passwd and /etc/group under root.
+// Numeric IDs without an entry (or without the files at all) are used as-is.
+func resolveUser(root, userstr string, groupAdd []string, current specs.User) (specs.User, error) {
+	passwdPath, err := fs.RootPath(root, "/etc/passwd")
+	if err != nil {
+		return current, err
+	}
+	groupPath, err := fs.RootPath(root, "/etc/group")
+	if err != nil {
+		return current, err
+	}
+	res := current
+	if userstr != "" {
+		execUser, err := user.GetExecUserPath(userstr, &user.ExecUser{Home: "/"}, passwdPath, groupPath)
+		if err != nil {
+			return current, fmt.Errorf("failed to resolve user %q: %w", userstr, err)
+		}
+		res.UID = uint32(execUser.Uid)
+		res.GID = uint32(execUser.Gid)
+		res.AdditionalGids = []uint32{res.GID}
+		for _, gid := range execUser.Sgids {
+			res.AdditionalGids = append(res.AdditionalGids, uint32(gid))
+		}
+	}
+	if len(groupAdd) != 0 {
+		gids, err := user.GetAdditionalGroupsPath(groupAdd, groupPath)
+		if err != nil {
+			return current, fmt.Errorf("failed to resolve additional groups %v: %w", groupAdd, err)
+		}
+		slices.Sort(gids)
+		for _, gid := range gids {
+			res.AdditionalGids = append(res.AdditionalGids, uint32(gid))
+		}
+	}
+	var gids []uint32
+	for _, gid := range res.AdditionalGids {
+		if !slices.Contains(gids, gid) {
+			gids = append(gids, gid)
+		}
+	}
+	res.AdditionalGids = gids
+	return res, nil
+}
+
 func generateUmaskOpts(umask string) ([]oci.SpecOpts, error) {
 	var opts []oci.SpecOpts
 
@@ -46,15 +158,6 @@ func generateUmaskOpts(umask string) ([]oci.SpecOpts, error) {
 	return opts, nil
 }
 
-func generateGroupsOpts(groups []string) ([]oci.SpecOpts, error) {
-	var opts []oci.SpecOpts
-
-	if len(groups) != 0 {
-		opts = append(opts, oci.WithAppendAdditionalGroups(groups...))
-	}
-	return opts, nil
-}
-
 func withResetAdditionalGIDs() oci.SpecOpts {
 	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
 		s.Process.User.AdditionalGids = nil
@@ -0,0 +1,66 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gotest.tools/v3/assert"
+)
+
+func TestResolveUser(t *testing.T) {
+	root := t.TempDir()
+	assert.NilError(t, os.Mkdir(filepath.Join(root, "etc"), 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join(root, "etc", "passwd"), []byte(
+		"root:x:0:0:root:/root:/bin/sh\nguest:x:405:100:guest:/dev/null:/sbin/nologin\n"), 0o644))
+	assert.NilError(t, os.WriteFile(filepath.Join(root, "etc", "group"), []byte(
+		"root:x:0:root\nwheel:x:10:root,guest\nusers:x:100:games\nnogroup:x:65533:\n"), 0o644))
+
+	current := specs.User{UID: 0, GID: 0, AdditionalGids: []uint32{0, 10}}
+	testCases := []struct {
+		user     string
+		groupAdd []string
+		expected specs.User
+	}{
+		{"", []string{"nogroup", "1234"}, specs.User{AdditionalGids: []uint32{0, 10, 1234, 65533}}},
+		{"guest", nil, specs.User{UID: 405, GID: 100, AdditionalGids: []uint32{100, 10}}},
+		{"guest:nogroup", []string{"0"}, specs.User{UID: 405, GID: 65533, AdditionalGids: []uint32{65533, 0}}},
+		{"1000", nil, specs.User{UID: 1000, AdditionalGids: []uint32{0}}},
+		{"1000:1000", []string{"wheel", "1000"}, specs.User{UID: 1000, GID: 1000, AdditionalGids: []uint32{1000, 10}}},
+	}
+	for _, tc := range testCases {
+		u, err := resolveUser(root, tc.user, tc.groupAdd, current)
+		assert.NilError(t, err, tc.user)
+		assert.DeepEqual(t, u, tc.expected)
+	}
+
+	_, err := resolveUser(root, "unknown", nil, current)
+	assert.ErrorContains(t, err, "unable to find user unknown")
+	_, err = resolveUser(root, "", []string{"unknown"}, current)
+	assert.ErrorContains(t, err, "find group unknown")
+
+	// Without /etc/passwd and /etc/group, only numeric IDs can be resolved.
+	empty := t.TempDir()
+	u, err := resolveUser(empty, "1000:1000", []string{"2000"}, current)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, u, specs.User{UID: 1000, GID: 1000, AdditionalGids: []uint32{1000, 2000}})
+	_, err = resolveUser(empty, "guest", nil, current)
+	assert.ErrorContains(t, err, "unable to find user guest")
+}
@@ -20,6 +20,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"runtime"
+	"strconv"
 	"strings"
 
 	"github.com/docker/go-units"
@@ -49,13 +52,14 @@ func setPlatformOptions(
 	internalLabels *internalLabels,
 	options types.ContainerCreateOptions,
 ) ([]oci.SpecOpts, error) {
-	opts := []oci.SpecOpts{}
-	if options.CPUs > 0.0 {
-		opts = append(opts, oci.WithWindowsCPUCount(uint64(options.CPUs)))
+	opts, err := generateWindowsResourceOpts(options, runtime.NumCPU())
+	if err != nil {
+		return nil, err
 	}
 
+	var mem64 int64
 	if options.Memory != "" {
-		mem64, err := units.RAMInBytes(options.Memory)
+		mem64, err = units.RAMInBytes(options.Memory)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse memory bytes %q: %w", options.Memory, err)
 		}
@@ -64,24 +68,15 @@ func setPlatformOptions(
 
 	switch options.Isolation {
 	case "hyperv":
-		if options.Memory != "" {
-			mem64, err := units.RAMInBytes(options.Memory)
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse memory bytes %q: %w", options.Memory, err)
-			}
-			uvmMemmory := map[string]string{
-				uvmMemorySizeInMB: fmt.Sprintf("%v", mem64),
-			}
-			opts = append(opts, oci.WithAnnotations(uvmMemmory))
+		// size the utility VM for the limits of the container
+		uvmAnnotations := map[string]string{}
+		if mem64 > 0 {
+			uvmAnnotations[uvmMemorySizeInMB] = strconv.FormatInt(mem64/units.MiB, 10)
 		}
-
-		if options.CPUs > 0.0 {
-			uvmCPU := map[string]string{
-				uvmCPUCount: fmt.Sprintf("%v", options.CPUs),
-			}
-			opts = append(opts, oci.WithAnnotations(uvmCPU))
+		if count := hyperVCPUCount(options); count > 0 {
+			uvmAnnotations[uvmCPUCount] = strconv.FormatUint(count, 10)
 		}
-		opts = append(opts, oci.WithWindowsHyperV)
+		opts = append(opts, oci.WithAnnotations(uvmAnnotations), oci.WithWindowsHyperV)
 	case "host":
 		hpAnnotations := map[string]string{
 			hostProcessContainer: "true",
@@ -102,7 +97,7 @@ func setPlatformOptions(
 		// no op
 		// use containerd's default runtime option `default_runtime` set in the config.toml
 	default:
-		return nil, fmt.Errorf("unknown isolation value %q. valid values are 'host', 'process' or 'default'", options.Isolation)
+		return nil, fmt.Errorf("unknown isolation value %q. valid values are 'host', 'process', 'hyperv' or 'default'", options.Isolation)
 	}
 
 	opts = append(opts,
@@ -123,6 +118,102 @@ func setPlatformOptions(
 	return opts, nil
 }
 
+// generateWindowsResourceOpts returns the options for the CPU and the IO limits, compatible with Docker.
+// --cpus limits the CPU usage relative to all the CPUs of the host (or of the utility VM for Hyper-V),
+// --cpu-count limits the number of the CPUs, and --cpu-percent limits the CPU usage in percent.
+// They are mutually exclusive.
+func generateWindowsResourceOpts(options types.ContainerCreateOptions, numCPU int) ([]oci.SpecOpts, error) {
+	var cpuFlags []string
+	if options.CPUs > 0 {
+		cpuFlags = append(cpuFlags, "--cpus")
+	}
+	if options.CPUCount > 0 {
+		cpuFlags = append(cpuFlags, "--cpu-count")
+	}
+	if options.CPUPercent > 0 {
+		cpuFlags = append(cpuFlags, "--cpu-percent")
+	}
+	if len(cpuFlags) > 1 {
+		return nil, fmt.Errorf("conflicting options: %s must not be specified together on Windows", strings.Join(cpuFlags, ", "))
+	}
+
+	var opts []oci.SpecOpts
+	switch {
+	case options.CPUCount > 0:
+		opts = append(opts, oci.WithWindowsCPUCount(options.CPUCount))
+	case options.CPUPercent > 0:
+		if options.CPUPercent > 100 {
+			return nil, fmt.Errorf("invalid --cpu-percent %d: must be between 1 and 100", options.CPUPercent)
+		}
+		opts = append(opts, oci.WithWindowsCPUMaximum(uint16(options.CPUPercent*100)))
+	case options.CPUs > 0:
+		// CPU.Maximum is the usable portion of the CPUs in 1/10000
+		count := float64(numCPU)
+		if options.Isolation == "hyperv" {
+			count = float64(hyperVCPUCount(options))
+			opts = append(opts, oci.WithWindowsCPUCount(uint64(count)))
+		} else if options.CPUs > count {
+			return nil, fmt.Errorf("invalid --cpus %v: the host has %d CPUs", options.CPUs, numCPU)
+		}
+		if options.CPUs != count {
+			maximum := uint16(options.CPUs / count * 10000)
+			if maximum < 1 {
+				maximum = 1
+			}
+			opts = append(opts, oci.WithWindowsCPUMaximum(maximum))
+		}
+	}
+	if options.CPUShares > 0 {
+		if options.CPUShares > 10000 {
+			return nil, fmt.Errorf("invalid --cpu-shares %d: must be between 1 and 10000 on Windows", options.CPUShares)
+		}
+		opts = append(opts, oci.WithWindowsCPUShares(uint16(options.CPUShares)))
+	}
+
+	var bps uint64
+	if options.IOMaximumBandwidth != "" {
+		b, err := units.RAMInBytes(options.IOMaximumBandwidth)
+		if err != nil || b <= 0 {
+			return nil, fmt.Errorf("invalid --io-maxbandwidth %q", options.IOMaximumBandwidth)
+		}
+		bps = uint64(b)
+	}
+	if bps > 0 || options.IOMaximumIOps > 0 {
+		opts = append(opts, withWindowsStorageLimits(options.IOMaximumIOps, bps))
+	}
+	return opts, nil
+}
+
+// hyperVCPUCount returns the number of the CPUs of the utility VM, or zero for the default.
+func hyperVCPUCount(options types.ContainerCreateOptions) uint64 {
+	if options.CPUCount > 0 {
+		return options.CPUCount
+	}
+	return uint64(math.Ceil(options.CPUs))
+}
+
+// withWindowsStorageLimits sets the IO limits of the system drive. Zero means unlimited.
+func withWindowsStorageLimits(iops, bps uint64) oci.SpecOpts {
+	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *specs.Spec) error {
+		if s.Windows == nil {
+			s.Windows = &specs.Windows{}
+		}
+		if s.Windows.Resources == nil {
+			s.Windows.Resources = &specs.WindowsResources{}
+		}
+		if s.Windows.Resources.Storage == nil {
+			s.Windows.Resources.Storage = &specs.WindowsStorageResources{}
+		}
+		if iops > 0 {
+			s.Windows.Resources.Storage.Iops = &iops
+		}
+		if bps > 0 {
+			s.Windows.Resources.Storage.Bps = &bps
+		}
+		return nil
+	}
+}
+
 func WithWindowsProcessIsolated() oci.SpecOpts {
 	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *specs.Spec) error {
 		if s.Windows == nil {
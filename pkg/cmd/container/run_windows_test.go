@@ -0,0 +1,105 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+)
+
+func TestGenerateWindowsResourceOpts(t *testing.T) {
+	t.Parallel()
+	u16 := func(v uint16) *uint16 { return &v }
+	u64 := func(v uint64) *uint64 { return &v }
+	tests := []struct {
+		name        string
+		options     types.ContainerCreateOptions
+		expected    *specs.WindowsResources
+		expectError string
+	}{
+		{
+			name:     "no limits",
+			expected: nil,
+		},
+		{
+			name:     "cpus",
+			options:  types.ContainerCreateOptions{CPUs: 1},
+			expected: &specs.WindowsResources{CPU: &specs.WindowsCPUResources{Maximum: u16(2500)}},
+		},
+		{
+			name:     "cpus with hyperv",
+			options:  types.ContainerCreateOptions{CPUs: 1.5, Isolation: "hyperv"},
+			expected: &specs.WindowsResources{CPU: &specs.WindowsCPUResources{Count: u64(2), Maximum: u16(7500)}},
+		},
+		{
+			name:     "cpu-count and cpu-shares",
+			options:  types.ContainerCreateOptions{CPUCount: 2, CPUShares: 500},
+			expected: &specs.WindowsResources{CPU: &specs.WindowsCPUResources{Count: u64(2), Shares: u16(500)}},
+		},
+		{
+			name:     "cpu-percent",
+			options:  types.ContainerCreateOptions{CPUPercent: 50},
+			expected: &specs.WindowsResources{CPU: &specs.WindowsCPUResources{Maximum: u16(5000)}},
+		},
+		{
+			name:     "io limits",
+			options:  types.ContainerCreateOptions{IOMaximumBandwidth: "1M", IOMaximumIOps: 100},
+			expected: &specs.WindowsResources{Storage: &specs.WindowsStorageResources{Iops: u64(100), Bps: u64(1 << 20)}},
+		},
+		{
+			name:        "conflicting cpu flags",
+			options:     types.ContainerCreateOptions{CPUs: 1, CPUCount: 1},
+			expectError: "conflicting options",
+		},
+		{
+			name:        "too many cpus",
+			options:     types.ContainerCreateOptions{CPUs: 8},
+			expectError: "the host has 4 CPUs",
+		},
+		{
+			name:        "invalid cpu-percent",
+			options:     types.ContainerCreateOptions{CPUPercent: 101},
+			expectError: "must be between 1 and 100",
+		},
+		{
+			name:        "invalid cpu-shares",
+			options:     types.ContainerCreateOptions{CPUShares: 10001},
+			expectError: "must be between 1 and 10000",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			opts, err := generateWindowsResourceOpts(tc.options, 4)
+			if tc.expectError != "" {
+				assert.ErrorContains(t, err, tc.expectError)
+				return
+			}
+			assert.NilError(t, err)
+			s := &specs.Spec{Windows: &specs.Windows{}}
+			for _, o := range opts {
+				assert.NilError(t, o(context.Background(), nil, nil, s))
+			}
+			assert.DeepEqual(t, s.Windows.Resources, tc.expected)
+		})
+	}
+}
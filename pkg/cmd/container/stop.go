@@ -31,7 +31,8 @@ import (
 // Stop stops a list of containers specified by `reqs`.
 func Stop(ctx context.Context, client *containerd.Client, reqs []string, opt types.ContainerStopOptions) error {
 	walker := &containerwalker.ContainerWalker{
-		Client: client,
+		Client:   client,
+		Parallel: opt.Parallel,
 		OnFound: func(ctx context.Context, found containerwalker.Found) error {
 			if found.MatchCount > 1 {
 				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
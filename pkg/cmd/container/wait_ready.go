@@ -0,0 +1,226 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/pkg/cio"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/idgen"
+)
+
+// waitReadyInterval is the interval of probing the container for WaitHealthy and WaitPort,
+// unless the HEALTHCHECK of the image specifies the start interval.
+const waitReadyInterval = time.Second
+
+// healthConfig is the "Healthcheck" of the Docker image config, which is not a part of the OCI image config.
+type healthConfig struct {
+	Test          []string      `json:",omitempty"`
+	Interval      time.Duration `json:",omitempty"`
+	Timeout       time.Duration `json:",omitempty"`
+	StartPeriod   time.Duration `json:",omitempty"`
+	StartInterval time.Duration `json:",omitempty"`
+	Retries       int           `json:",omitempty"`
+}
+
+// ParseWaitPort parses the value of `--wait-port`, like "8080" or "8080/tcp".
+func ParseWaitPort(s string) (int, error) {
+	portStr, proto, ok := strings.Cut(s, "/")
+	if ok && !strings.EqualFold(proto, "tcp") {
+		return 0, fmt.Errorf("invalid --wait-port %q: only tcp is supported", s)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 || port > 65535 {
+		return 0, fmt.Errorf("invalid --wait-port %q", s)
+	}
+	return port, nil
+}
+
+// healthcheckArgs returns the command of the healthcheck test, or nil if the healthcheck is disabled.
+func healthcheckArgs(test []string) ([]string, error) {
+	if len(test) == 0 {
+		return nil, nil
+	}
+	switch test[0] {
+	case "NONE":
+		return nil, nil
+	case "CMD":
+		if len(test) < 2 {
+			return nil, errors.New("invalid HEALTHCHECK: no command")
+		}
+		return test[1:], nil
+	case "CMD-SHELL":
+		if len(test) != 2 {
+			return nil, errors.New("invalid HEALTHCHECK: CMD-SHELL requires exactly one command string")
+		}
+		return []string{"/bin/sh", "-c", test[1]}, nil
+	default:
+		return nil, fmt.Errorf("invalid HEALTHCHECK: unknown test type %q", test[0])
+	}
+}
+
+// readHealthConfig reads the HEALTHCHECK of the image of the container.
+func readHealthConfig(ctx context.Context, c containerd.Container) (*healthConfig, error) {
+	img, err := c.Image(ctx)
+	if err != nil {
+		return nil, err
+	}
+	configDesc, err := img.Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p, err := content.ReadBlob(ctx, img.ContentStore(), configDesc)
+	if err != nil {
+		return nil, err
+	}
+	var config struct {
+		Config struct {
+			Healthcheck *healthConfig `json:",omitempty"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal(p, &config); err != nil {
+		return nil, err
+	}
+	return config.Config.Healthcheck, nil
+}
+
+// WaitReady blocks until the HEALTHCHECK of the image passes (options.WaitHealthy),
+// and the TCP port of the container accepts connections (options.WaitPort).
+// WaitReady fails when the container exits, or when options.WaitTimeout elapses.
+func WaitReady(ctx context.Context, client *containerd.Client, c containerd.Container, options types.ContainerCreateOptions) error {
+	var (
+		healthArgs   []string
+		probeTimeout = 30 * time.Second
+		interval     = waitReadyInterval
+	)
+	if options.WaitHealthy {
+		hc, err := readHealthConfig(ctx, c)
+		if err != nil {
+			return fmt.Errorf("failed to read the HEALTHCHECK of the image: %w", err)
+		}
+		if hc != nil {
+			healthArgs, err = healthcheckArgs(hc.Test)
+			if err != nil {
+				return err
+			}
+			if hc.Timeout > 0 {
+				probeTimeout = hc.Timeout
+			}
+			if hc.StartInterval > 0 {
+				interval = hc.StartInterval
+			}
+		}
+		if healthArgs == nil {
+			return errors.New("the image has no HEALTHCHECK (hint: use --wait-port)")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, options.WaitTimeout)
+	defer cancel()
+	var lastErr error
+	for {
+		task, err := c.Task(ctx, nil)
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				return errors.New("the container exited before becoming ready")
+			}
+			return waitReadyError(ctx, err, lastErr)
+		}
+		st, err := task.Status(ctx)
+		if err != nil {
+			return waitReadyError(ctx, err, lastErr)
+		}
+		if st.Status == containerd.Stopped {
+			return fmt.Errorf("the container exited before becoming ready (exit code %d)", st.ExitStatus)
+		}
+
+		lastErr = nil
+		if options.WaitPort != 0 {
+			lastErr = dialContainerPort(ctx, task.Pid(), options.WaitPort, min(interval, probeTimeout))
+		}
+		if lastErr == nil && healthArgs != nil {
+			lastErr = runHealthcheck(ctx, client, c, task, healthArgs, probeTimeout)
+		}
+		if lastErr == nil {
+			return nil
+		}
+		log.G(ctx).WithError(lastErr).Debugf("container %s is not ready yet", c.ID())
+
+		select {
+		case <-ctx.Done():
+			return waitReadyError(ctx, ctx.Err(), lastErr)
+		case <-time.After(interval):
+		}
+	}
+}
+
+func waitReadyError(ctx context.Context, err, lastErr error) error {
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		err = errors.New("timed out waiting for the container to become ready")
+	}
+	if lastErr != nil {
+		return fmt.Errorf("%w: %w", err, lastErr)
+	}
+	return err
+}
+
+// runHealthcheck runs the healthcheck command inside the container, and returns an error if it does not exit with 0 within the timeout.
+func runHealthcheck(ctx context.Context, client *containerd.Client, c containerd.Container, task containerd.Task, args []string, timeout time.Duration) error {
+	pspec, err := generateExecProcessSpec(ctx, client, c, args, types.ContainerExecOptions{})
+	if err != nil {
+		return err
+	}
+	process, err := task.Exec(ctx, "health-"+idgen.GenerateID(), pspec, cio.NullIO)
+	if err != nil {
+		return err
+	}
+	defer process.Delete(context.WithoutCancel(ctx), containerd.WithProcessKill)
+	statusC, err := process.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	if err := process.Start(ctx); err != nil {
+		return err
+	}
+	select {
+	case status := <-statusC:
+		code, _, err := status.Result()
+		if err != nil {
+			return err
+		}
+		if code != 0 {
+			return fmt.Errorf("the healthcheck exited with code %d", code)
+		}
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("the healthcheck timed out after %s", timeout)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,70 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseWaitPort(t *testing.T) {
+	for _, tc := range []struct {
+		s      string
+		port   int
+		errMsg string
+	}{
+		{"8080", 8080, ""},
+		{"8080/tcp", 8080, ""},
+		{"53/udp", 0, "only tcp is supported"},
+		{"0", 0, "invalid --wait-port"},
+		{"65536", 0, "invalid --wait-port"},
+		{"http", 0, "invalid --wait-port"},
+	} {
+		port, err := ParseWaitPort(tc.s)
+		if tc.errMsg != "" {
+			assert.ErrorContains(t, err, tc.errMsg, tc.s)
+			continue
+		}
+		assert.NilError(t, err, tc.s)
+		assert.Equal(t, port, tc.port, tc.s)
+	}
+}
+
+func TestHealthcheckArgs(t *testing.T) {
+	for _, tc := range []struct {
+		test   []string
+		args   []string
+		errMsg string
+	}{
+		{nil, nil, ""},
+		{[]string{"NONE"}, nil, ""},
+		{[]string{"CMD", "curl", "-f", "http://localhost"}, []string{"curl", "-f", "http://localhost"}, ""},
+		{[]string{"CMD-SHELL", "curl -f http://localhost || exit 1"}, []string{"/bin/sh", "-c", "curl -f http://localhost || exit 1"}, ""},
+		{[]string{"CMD"}, nil, "no command"},
+		{[]string{"CMD-SHELL", "a", "b"}, nil, "exactly one command"},
+		{[]string{"FOO"}, nil, "unknown test type"},
+	} {
+		args, err := healthcheckArgs(tc.test)
+		if tc.errMsg != "" {
+			assert.ErrorContains(t, err, tc.errMsg)
+			continue
+		}
+		assert.NilError(t, err)
+		assert.DeepEqual(t, args, tc.args)
+	}
+}
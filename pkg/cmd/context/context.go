@@ -0,0 +1,160 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package context
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/contextstore"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
+)
+
+// Create creates a new context.
+func Create(cs contextstore.ContextStore, name string, options types.ContextCreateOptions) error {
+	c := &contextstore.Context{
+		Name:        name,
+		Description: options.Description,
+		SSH:         options.SSH,
+		Sudo:        options.Sudo,
+	}
+	if options.Containerd != "" {
+		m, err := strutil.ParseCSVMap(options.Containerd)
+		if err != nil {
+			return fmt.Errorf("failed to parse the containerd endpoint %q: %w", options.Containerd, err)
+		}
+		for k, v := range m {
+			switch k {
+			case "address", "host":
+				c.Address = v
+			case "namespace":
+				c.Namespace = v
+			case "snapshotter":
+				c.Snapshotter = v
+			default:
+				return fmt.Errorf("unknown key %q in the containerd endpoint (supported keys: \"address\", \"namespace\", \"snapshotter\")", k)
+			}
+		}
+	}
+	if err := cs.Create(c); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(options.Stdout, name)
+	return err
+}
+
+// Use sets the current context.
+func Use(cs contextstore.ContextStore, name string, options types.ContextUseOptions) error {
+	if err := cs.Use(name); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(options.Stdout, name)
+	return err
+}
+
+type contextPrintable struct {
+	contextstore.Context
+	Current bool
+}
+
+// List prints the contexts. The current context is marked with "*".
+func List(cs contextstore.ContextStore, options types.ContextListOptions) error {
+	contexts, err := cs.List()
+	if err != nil {
+		return err
+	}
+	current, err := cs.Current()
+	if err != nil {
+		return err
+	}
+
+	w := options.Stdout
+	var tmpl *template.Template
+	switch options.Format {
+	case "", "table", "wide":
+		w = tabwriter.NewWriter(w, 4, 8, 4, ' ', 0)
+		if !options.Quiet {
+			fmt.Fprintln(w, "NAME\tDESCRIPTION\tADDRESS\tNAMESPACE\tSNAPSHOTTER\tSSH\tSUDO")
+		}
+	case "raw":
+		return errors.New("unsupported format: \"raw\"")
+	default:
+		if options.Quiet {
+			return errors.New("format and quiet must not be specified together")
+		}
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, c := range contexts {
+		p := contextPrintable{Context: *c, Current: c.Name == current}
+		if tmpl != nil {
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, p); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w, b.String()); err != nil {
+				return err
+			}
+		} else if options.Quiet {
+			fmt.Fprintln(w, p.Name)
+		} else {
+			name := p.Name
+			if p.Current {
+				name += " *"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%v\n", name, p.Description, p.Address, p.Namespace, p.Snapshotter, p.SSH, p.Sudo)
+		}
+	}
+	if f, ok := w.(formatter.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Inspect prints the contexts.
+func Inspect(cs contextstore.ContextStore, names []string, options types.ContextInspectOptions) error {
+	result := make([]interface{}, len(names))
+	for i, name := range names {
+		c, err := cs.Get(name)
+		if err != nil {
+			return err
+		}
+		result[i] = c
+	}
+	return formatter.FormatSlice(options.Format, options.Stdout, result)
+}
+
+// Remove removes the contexts.
+func Remove(cs contextstore.ContextStore, names []string, options types.ContextRemoveOptions) error {
+	var errs []error
+	for _, name := range names {
+		if err := cs.Remove(name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Fprintln(options.Stdout, name)
+	}
+	return errors.Join(errs...)
+}
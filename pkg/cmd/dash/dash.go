@@ -0,0 +1,389 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package dash implements `nerdctl dash`, a terminal UI for the containers, the images, the logs, and the stats.
+package dash
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+
+	containerd "github.com/containerd/containerd/v2/client"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+	"github.com/containerd/nerdctl/v2/pkg/statsutil"
+)
+
+const (
+	enterAltScreen = "\033[?1049h\033[?25l"
+	leaveAltScreen = "\033[?25h\033[?1049l"
+	// logsTail is the number of the log lines loaded in the logs pane
+	logsTail = 1000
+)
+
+type dash struct {
+	client  *containerd.Client
+	options types.DashOptions
+	m       model
+	// msgs are the updates of the model, sent by the goroutines loading the data or running the actions
+	msgs chan func(*model)
+}
+
+// Run runs the dashboard until the user quits or ctx is done.
+func Run(ctx context.Context, client *containerd.Client, options types.DashOptions) error {
+	if !term.IsTerminal(int(options.Stdin.Fd())) || !term.IsTerminal(int(options.Stdout.Fd())) {
+		return errors.New("nerdctl dash requires a terminal")
+	}
+	if options.Interval <= 0 {
+		return fmt.Errorf("invalid interval %v", options.Interval)
+	}
+	d := &dash{
+		client:  client,
+		options: options,
+		m:       model{namespace: options.GOptions.Namespace},
+		msgs:    make(chan func(*model)),
+	}
+	state, err := term.MakeRaw(int(options.Stdin.Fd()))
+	if err != nil {
+		return err
+	}
+	defer term.Restore(int(options.Stdin.Fd()), state)
+	fmt.Fprint(options.Stdout, enterAltScreen)
+	defer fmt.Fprint(options.Stdout, leaveAltScreen)
+
+	reader := newKeyReader(options.Stdin)
+	defer reader.Close()
+	ticker := time.NewTicker(options.Interval)
+	defer ticker.Stop()
+
+	d.refresh(ctx)
+	for {
+		d.draw()
+		select {
+		case <-ctx.Done():
+			return nil
+		case k, ok := <-reader.Keys():
+			if !ok || d.handleKey(ctx, reader, state, k) {
+				return nil
+			}
+		case update := <-d.msgs:
+			update(&d.m)
+		case <-ticker.C:
+			d.refresh(ctx)
+		}
+	}
+}
+
+func (d *dash) draw() {
+	width, height, err := term.GetSize(int(d.options.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 2 {
+		width, height = 80, 24
+	}
+	var b strings.Builder
+	b.WriteString("\033[H")
+	for i, line := range render(&d.m, width, height) {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+		b.WriteString("\033[K")
+	}
+	b.WriteString("\033[J")
+	io.WriteString(d.options.Stdout, b.String())
+}
+
+// send sends an update of the model to the event loop, unless ctx is done.
+func (d *dash) send(ctx context.Context, update func(*model)) {
+	select {
+	case d.msgs <- update:
+	case <-ctx.Done():
+	}
+}
+
+// handleKey handles a key, and returns true when the user quits.
+func (d *dash) handleKey(ctx context.Context, reader *keyReader, state *term.State, k key) bool {
+	m := &d.m
+	if m.confirm != nil {
+		confirm := m.confirm
+		m.confirm = nil
+		if k == 'y' || k == 'Y' {
+			confirm.action()
+		} else {
+			m.message = "Cancelled"
+		}
+		return false
+	}
+	m.message = ""
+	switch k {
+	case 'q', keyCtrlC:
+		return true
+	case keyTab, keyRight:
+		m.pane = (m.pane + 1) % numPanes
+		d.refresh(ctx)
+	case keyBackTab, keyLeft:
+		m.pane = (m.pane + numPanes - 1) % numPanes
+		d.refresh(ctx)
+	case '1', '2', '3', '4':
+		m.pane = pane(k - '1')
+		d.refresh(ctx)
+	case keyUp, 'k':
+		m.move(-1)
+	case keyDown, 'j':
+		m.move(1)
+	case keyPageUp:
+		m.move(-10)
+	case keyPageDown:
+		m.move(10)
+	case keyHome, 'g':
+		m.move(-m.rows())
+	case keyEnd, 'G':
+		m.move(m.rows())
+	case keyEnter, 'l':
+		if id, name, ok := m.selectedContainer(); ok && m.pane != paneLogs {
+			m.logsID, m.logsName, m.logs = id, name, nil
+			m.selected[paneLogs] = 0
+			m.pane = paneLogs
+			d.refresh(ctx)
+		}
+	case 'e':
+		if id, name, ok := m.selectedContainer(); ok {
+			d.exec(ctx, reader, state, id, name)
+			d.refresh(ctx)
+		}
+	case 's':
+		if id, name, ok := m.selectedContainer(); ok {
+			d.run(ctx, "Stopping "+name, "Stopped "+name, func() error {
+				return container.Stop(ctx, d.client, []string{id}, types.ContainerStopOptions{
+					Stdout:   io.Discard,
+					Stderr:   io.Discard,
+					GOptions: d.options.GOptions,
+				})
+			})
+		}
+	case 'd':
+		if img, ok := m.selectedImage(); ok {
+			ref := img.ref()
+			m.confirm = &confirmation{
+				prompt: "Remove image " + ref + "?",
+				action: func() {
+					d.run(ctx, "Removing "+ref, "Removed "+ref, func() error {
+						return image.Remove(ctx, d.client, []string{ref}, types.ImageRemoveOptions{
+							Stdout:   io.Discard,
+							GOptions: d.options.GOptions,
+						})
+					})
+				},
+			}
+		} else if id, name, ok := m.selectedContainer(); ok && m.pane == paneContainers {
+			m.confirm = &confirmation{
+				prompt: "Remove container " + name + " (force)?",
+				action: func() {
+					d.run(ctx, "Removing "+name, "Removed "+name, func() error {
+						return container.Remove(ctx, d.client, []string{id}, types.ContainerRemoveOptions{
+							Stdout:   io.Discard,
+							GOptions: d.options.GOptions,
+							Force:    true,
+						})
+					})
+				},
+			}
+		}
+	}
+	return false
+}
+
+// run runs an action in the background, and refreshes the panes when it is done.
+func (d *dash) run(ctx context.Context, doing, done string, action func() error) {
+	d.m.message = doing + "..."
+	go func() {
+		err := action()
+		d.send(ctx, func(m *model) {
+			if err != nil {
+				m.message = fmt.Sprintf("%s: %v", doing, err)
+			} else {
+				m.message = done
+			}
+			d.refresh(ctx)
+		})
+	}()
+}
+
+// exec runs the shell in the container, in place of the dashboard.
+func (d *dash) exec(ctx context.Context, reader *keyReader, state *term.State, id, name string) {
+	if err := reader.Pause(); err != nil {
+		d.m.message = err.Error()
+		return
+	}
+	defer reader.Resume()
+	fd := int(d.options.Stdin.Fd())
+	fmt.Fprint(d.options.Stdout, leaveAltScreen)
+	term.Restore(fd, state)
+
+	args := append(append([]string{}, d.options.NerdctlArgs...), "exec", "-it", id, d.options.Shell)
+	cmd := exec.CommandContext(ctx, d.options.NerdctlCmd, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = d.options.Stdin, d.options.Stdout, d.options.Stdout
+	err := cmd.Run()
+
+	if _, rawErr := term.MakeRaw(fd); rawErr != nil && err == nil {
+		err = rawErr
+	}
+	fmt.Fprint(d.options.Stdout, enterAltScreen)
+	if err != nil {
+		d.m.message = fmt.Sprintf("exec %s in %s: %v", d.options.Shell, name, err)
+	}
+}
+
+// refresh reloads the data of the panes in the background, unless a refresh is already running.
+// The stats and the logs are only loaded when their panes are shown.
+func (d *dash) refresh(ctx context.Context) {
+	if d.m.refreshing {
+		return
+	}
+	d.m.refreshing = true
+	current, logsID := d.m.pane, d.m.logsID
+	go func() {
+		var (
+			containers []container.ListItem
+			images     []imageRow
+			stats      []statsutil.FormattedStatsEntry
+			logs       []string
+			errs       []error
+			err        error
+		)
+		if containers, err = container.List(ctx, d.client, types.ContainerListOptions{GOptions: d.options.GOptions, All: true}); err != nil {
+			errs = append(errs, err)
+		}
+		if images, err = d.loadImages(ctx); err != nil {
+			errs = append(errs, err)
+		}
+		if current == paneStats {
+			if stats, err = d.loadStats(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if current == paneLogs && logsID != "" {
+			if logs, err = d.loadLogs(ctx, logsID); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		d.send(ctx, func(m *model) {
+			m.refreshing = false
+			m.containers, m.images = containers, images
+			if current == paneStats {
+				m.stats = stats
+			}
+			if current == paneLogs && logsID == m.logsID {
+				// keep the scrolled position on the same line
+				if m.selected[paneLogs] > 0 {
+					m.selected[paneLogs] += len(logs) - len(m.logs)
+				}
+				m.logs = logs
+			}
+			m.clamp()
+			if len(errs) > 0 && m.message == "" {
+				m.message = errors.Join(errs...).Error()
+			}
+		})
+	}()
+}
+
+func (d *dash) loadImages(ctx context.Context) ([]imageRow, error) {
+	var buf bytes.Buffer
+	if err := image.ListCommandHandler(ctx, d.client, &types.ImageListOptions{
+		Stdout:   &buf,
+		GOptions: d.options.GOptions,
+		Format:   "{{json .}}",
+	}); err != nil {
+		return nil, err
+	}
+	return decodeLines[imageRow](&buf)
+}
+
+func (d *dash) loadStats(ctx context.Context) ([]statsutil.FormattedStatsEntry, error) {
+	var buf bytes.Buffer
+	if err := container.Stats(ctx, d.client, nil, types.ContainerStatsOptions{
+		Stdout:   &buf,
+		Stderr:   io.Discard,
+		GOptions: d.options.GOptions,
+		Format:   "{{json .}}",
+		NoStream: true,
+	}); err != nil {
+		return nil, err
+	}
+	return decodeLines[statsutil.FormattedStatsEntry](&buf)
+}
+
+func (d *dash) loadLogs(ctx context.Context, id string) ([]string, error) {
+	// the stdout and the stderr may be written concurrently
+	var (
+		mu  sync.Mutex
+		buf bytes.Buffer
+	)
+	w := writerFunc(func(p []byte) (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return buf.Write(p)
+	})
+	if err := container.Logs(ctx, d.client, id, types.ContainerLogsOptions{
+		Stdout:   w,
+		Stderr:   w,
+		GOptions: d.options.GOptions,
+		Tail:     logsTail,
+	}); err != nil {
+		return nil, err
+	}
+	var lines []string
+	scanner := bufio.NewScanner(&buf)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, sanitize(scanner.Text()))
+	}
+	return lines, scanner.Err()
+}
+
+// decodeLines decodes the JSON lines printed with `--format '{{json .}}'`.
+func decodeLines[T any](r io.Reader) ([]T, error) {
+	var result []T
+	dec := json.NewDecoder(r)
+	for {
+		var v T
+		if err := dec.Decode(&v); errors.Is(err, io.EOF) {
+			return result, nil
+		} else if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
@@ -0,0 +1,117 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dash
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+)
+
+func TestParseKeys(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected []key
+	}{
+		{"q", []key{'q'}},
+		{"jk\r\t", []key{'j', 'k', keyEnter, keyTab}},
+		{"\x1b[A\x1b[B\x1bOC\x1b[D", []key{keyUp, keyDown, keyRight, keyLeft}},
+		{"\x1b[5~\x1b[6~\x1b[Z", []key{keyPageUp, keyPageDown, keyBackTab}},
+		{"\x1b", []key{keyEscape}},
+		// unknown escape sequences are ignored
+		{"\x1b[1;5Ax", []key{'x'}},
+		{"\x03é", []key{keyCtrlC, 'é'}},
+	}
+	for _, tc := range testCases {
+		assert.DeepEqual(t, parseKeys([]byte(tc.input)), tc.expected)
+	}
+}
+
+func TestModel(t *testing.T) {
+	m := &model{
+		containers: []container.ListItem{{ID: "aaaa", Names: "a"}, {ID: "bbbb", Names: "b"}, {ID: "cccc", Names: "c"}},
+		logs:       []string{"1", "2", "3", "4"},
+	}
+	m.move(1)
+	m.move(5)
+	assert.Equal(t, m.selected[paneContainers], 2)
+	id, name, ok := m.selectedContainer()
+	assert.Assert(t, ok)
+	assert.Equal(t, id, "cccc")
+	assert.Equal(t, name, "c")
+
+	m.containers = m.containers[:1]
+	m.clamp()
+	assert.Equal(t, m.selected[paneContainers], 0)
+
+	// scrolling up the logs
+	m.pane = paneLogs
+	m.move(-2)
+	assert.Equal(t, m.selected[paneLogs], 2)
+	m.move(10)
+	assert.Equal(t, m.selected[paneLogs], 0)
+
+	_, ok = m.selectedImage()
+	assert.Assert(t, !ok)
+}
+
+func TestRender(t *testing.T) {
+	m := &model{
+		namespace: "default",
+		containers: []container.ListItem{
+			{ID: "0123456789abcdef", Names: "web", Image: "docker.io/library/nginx:alpine", Status: "Up"},
+			{ID: "fedcba9876543210", Names: "db", Image: "docker.io/library/postgres:17", Status: "Exited (0) 1 minute ago"},
+		},
+	}
+	m.selected[paneContainers] = 1
+	lines := render(m, 100, 6)
+	assert.Equal(t, len(lines), 6)
+	assert.Assert(t, strings.Contains(lines[0], styleReverse+" 1 Containers "+styleReset), lines[0])
+	assert.Assert(t, strings.Contains(lines[0], "namespace: default"), lines[0])
+	assert.Equal(t, lines[1], styleBold+"CONTAINER ID   NAME   IMAGE                            STATUS                    PORTS"+styleReset)
+	assert.Equal(t, lines[2], "0123456789ab   web    docker.io/library/nginx:alpine   Up")
+	assert.Assert(t, strings.HasPrefix(lines[3], styleReverse+"fedcba987654   db"), lines[3])
+	assert.Equal(t, lines[4], "")
+	assert.Equal(t, lines[5], help[paneContainers])
+
+	// the selected row is scrolled into the view
+	lines = render(m, 100, 4)
+	assert.Equal(t, len(lines), 4)
+	assert.Assert(t, strings.HasPrefix(lines[2], styleReverse+"fedcba987654"), lines[2])
+
+	m.confirm = &confirmation{prompt: "Remove container db (force)?"}
+	lines = render(m, 20, 4)
+	assert.Equal(t, lines[3], styleBold+"Remove container db…"+styleReset)
+
+	m.confirm = nil
+	m.pane = paneLogs
+	lines = render(m, 100, 4)
+	assert.Assert(t, strings.HasPrefix(lines[1], "Select a container"), lines[1])
+	m.logsID, m.logsName, m.logs = "fedcba9876543210", "db", []string{"1", "2", "3"}
+	m.selected[paneLogs] = 1
+	lines = render(m, 100, 5)
+	assert.DeepEqual(t, lines[1:4], []string{styleBold + "Logs of db" + styleReset, "1", "2"})
+}
+
+func TestSanitize(t *testing.T) {
+	assert.Equal(t, sanitize("a\tb\x1b[31mc\r"), "a b?[31mc")
+	assert.Equal(t, truncate("héllo", 3), "hé…")
+	assert.Equal(t, truncate("héllo", 5), "héllo")
+}
@@ -0,0 +1,126 @@
+//go:build unix
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dash
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// pollTimeout is the timeout of polling the terminal, in milliseconds, which bounds the latency of Pause.
+const pollTimeout = 100
+
+// keyBuffer is the number of the keys buffered for the event loop.
+const keyBuffer = 64
+
+// keyReader reads the keys from a terminal in raw mode.
+// It can be paused, so that another process (e.g., `nerdctl exec`) can read the terminal.
+type keyReader struct {
+	f       *os.File
+	keys    chan key
+	pause   chan chan struct{}
+	resume  chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func newKeyReader(f *os.File) *keyReader {
+	r := &keyReader{
+		f:       f,
+		keys:    make(chan key, keyBuffer),
+		pause:   make(chan chan struct{}),
+		resume:  make(chan struct{}),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go r.loop()
+	return r
+}
+
+func (r *keyReader) loop() {
+	defer close(r.stopped)
+	defer close(r.keys)
+	buf := make([]byte, 256)
+	for {
+		select {
+		case ack := <-r.pause:
+			close(ack)
+			select {
+			case <-r.resume:
+			case <-r.done:
+				return
+			}
+		case <-r.done:
+			return
+		default:
+		}
+		fds := []unix.PollFd{{Fd: int32(r.f.Fd()), Events: unix.POLLIN}}
+		n, err := unix.Poll(fds, pollTimeout)
+		if errors.Is(err, unix.EINTR) || n == 0 {
+			continue
+		}
+		if err != nil {
+			return
+		}
+		n, err = r.f.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, k := range parseKeys(buf[:n]) {
+			select {
+			case r.keys <- k:
+			default:
+				// the event loop is busy, e.g., with a pasted text
+			}
+		}
+	}
+}
+
+// Keys returns the channel of the keys, which is closed when the terminal cannot be read anymore.
+func (r *keyReader) Keys() <-chan key {
+	return r.keys
+}
+
+// Pause stops reading the terminal until Resume is called.
+func (r *keyReader) Pause() error {
+	ack := make(chan struct{})
+	select {
+	case r.pause <- ack:
+		<-ack
+		return nil
+	case <-r.stopped:
+		return errors.New("the terminal is closed")
+	}
+}
+
+// Resume resumes reading the terminal after Pause.
+func (r *keyReader) Resume() {
+	select {
+	case r.resume <- struct{}{}:
+	case <-r.stopped:
+	}
+}
+
+// Close stops reading the terminal.
+func (r *keyReader) Close() {
+	close(r.done)
+	<-r.stopped
+}
@@ -0,0 +1,77 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dash
+
+import (
+	"errors"
+	"os"
+)
+
+// keyBuffer is the number of the keys buffered for the event loop.
+const keyBuffer = 64
+
+// keyReader reads the keys from a console in raw mode.
+// Unlike on Unix, reading the console cannot be interrupted, so it cannot be paused.
+type keyReader struct {
+	keys chan key
+	done chan struct{}
+}
+
+func newKeyReader(f *os.File) *keyReader {
+	r := &keyReader{
+		keys: make(chan key, keyBuffer),
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(r.keys)
+		buf := make([]byte, 256)
+		for {
+			n, err := f.Read(buf)
+			if err != nil {
+				return
+			}
+			for _, k := range parseKeys(buf[:n]) {
+				select {
+				case r.keys <- k:
+				case <-r.done:
+					return
+				default:
+				}
+			}
+		}
+	}()
+	return r
+}
+
+// Keys returns the channel of the keys, which is closed when the console cannot be read anymore.
+func (r *keyReader) Keys() <-chan key {
+	return r.keys
+}
+
+// Pause is not supported on Windows.
+func (r *keyReader) Pause() error {
+	return errors.New("running a command from the dashboard is not supported on Windows")
+}
+
+// Resume is not supported on Windows.
+func (r *keyReader) Resume() {
+}
+
+// Close stops delivering the keys. The pending read of the console is left behind.
+func (r *keyReader) Close() {
+	close(r.done)
+}
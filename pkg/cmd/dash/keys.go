@@ -0,0 +1,112 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dash
+
+// key is a key pressed on the terminal.
+// The printable keys are their runes, and the special keys are the negative constants below.
+type key rune
+
+const (
+	keyUp key = -(iota + 1)
+	keyDown
+	keyLeft
+	keyRight
+	keyPageUp
+	keyPageDown
+	keyHome
+	keyEnd
+	keyEnter
+	keyTab
+	keyBackTab
+	keyEscape
+	keyCtrlC
+)
+
+// escapeSequences are the escape sequences of the special keys, sent by the terminals in raw mode.
+var escapeSequences = map[string]key{
+	"[A":  keyUp,
+	"[B":  keyDown,
+	"[C":  keyRight,
+	"[D":  keyLeft,
+	"OA":  keyUp,
+	"OB":  keyDown,
+	"OC":  keyRight,
+	"OD":  keyLeft,
+	"[5~": keyPageUp,
+	"[6~": keyPageDown,
+	"[H":  keyHome,
+	"[F":  keyEnd,
+	"[1~": keyHome,
+	"[4~": keyEnd,
+	"[Z":  keyBackTab,
+}
+
+// parseKeys parses the bytes read from a terminal in raw mode.
+// The unknown escape sequences are ignored.
+func parseKeys(b []byte) []key {
+	var keys []key
+	s := string(b)
+	for len(s) > 0 {
+		if s[0] == 0x1b {
+			if len(s) == 1 {
+				keys = append(keys, keyEscape)
+				break
+			}
+			n := escapeSequenceLen(s[1:])
+			if k, ok := escapeSequences[s[1:1+n]]; ok {
+				keys = append(keys, k)
+			}
+			s = s[1+n:]
+			continue
+		}
+		r := []rune(s)[0]
+		s = s[len(string(r)):]
+		switch r {
+		case '\r', '\n':
+			keys = append(keys, keyEnter)
+		case '\t':
+			keys = append(keys, keyTab)
+		case 0x03:
+			keys = append(keys, keyCtrlC)
+		default:
+			if r >= 0x20 && r != 0x7f {
+				keys = append(keys, key(r))
+			}
+		}
+	}
+	return keys
+}
+
+// escapeSequenceLen returns the length of the escape sequence at the head of s, without the leading ESC.
+func escapeSequenceLen(s string) int {
+	switch s[0] {
+	case '[':
+		// CSI: parameters, then a final byte in 0x40-0x7e
+		for i := 1; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(s)
+	case 'O':
+		// SS3: a single byte
+		return min(2, len(s))
+	default:
+		// Alt+key
+		return 1
+	}
+}
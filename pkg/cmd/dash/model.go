@@ -0,0 +1,146 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dash
+
+import (
+	"strings"
+
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/statsutil"
+)
+
+type pane int
+
+const (
+	paneContainers pane = iota
+	paneImages
+	paneLogs
+	paneStats
+	numPanes
+)
+
+func (p pane) String() string {
+	return [...]string{"Containers", "Images", "Logs", "Stats"}[p]
+}
+
+// imageRow is a row of the images pane, decoded from `nerdctl images --format '{{json .}}'`.
+type imageRow struct {
+	Repository   string
+	Tag          string
+	ID           string
+	CreatedSince string
+	Size         string
+	Platform     string
+}
+
+func (i imageRow) ref() string {
+	if i.Repository == "<none>" {
+		return i.ID
+	}
+	return i.Repository + ":" + i.Tag
+}
+
+// confirmation is a pending action, run when the user answers "y".
+type confirmation struct {
+	prompt string
+	action func()
+}
+
+// model is the state of the dashboard. It is only accessed by the goroutine that runs the event loop.
+type model struct {
+	namespace string
+	pane      pane
+	// selected is the selected row of each pane. For the logs pane, it is the number of lines scrolled up.
+	selected [numPanes]int
+
+	containers []container.ListItem
+	images     []imageRow
+	stats      []statsutil.FormattedStatsEntry
+	// logsID and logsName are the container shown in the logs pane
+	logsID   string
+	logsName string
+	logs     []string
+
+	message    string
+	confirm    *confirmation
+	refreshing bool
+}
+
+// rows returns the number of the selectable rows of the current pane.
+func (m *model) rows() int {
+	switch m.pane {
+	case paneContainers:
+		return len(m.containers)
+	case paneImages:
+		return len(m.images)
+	case paneLogs:
+		return len(m.logs)
+	case paneStats:
+		return len(m.stats)
+	}
+	return 0
+}
+
+// move moves the selection of the current pane by delta rows.
+func (m *model) move(delta int) {
+	if m.pane == paneLogs {
+		// scrolling up the logs is moving the selection up
+		delta = -delta
+	}
+	m.selected[m.pane] = max(0, min(m.selected[m.pane]+delta, m.rows()-1))
+}
+
+// clamp keeps the selections within the rows, after the rows are reloaded.
+func (m *model) clamp() {
+	current := m.pane
+	for p := range numPanes {
+		m.pane = p
+		m.move(0)
+	}
+	m.pane = current
+}
+
+// selectedContainer returns the container selected in the containers pane or the stats pane.
+func (m *model) selectedContainer() (id, name string, ok bool) {
+	switch m.pane {
+	case paneContainers:
+		if i := m.selected[paneContainers]; i < len(m.containers) {
+			return m.containers[i].ID, m.containers[i].Names, true
+		}
+	case paneStats:
+		if i := m.selected[paneStats]; i < len(m.stats) {
+			// the stats have short IDs
+			for _, c := range m.containers {
+				if strings.HasPrefix(c.ID, m.stats[i].ID) {
+					return c.ID, c.Names, true
+				}
+			}
+		}
+	case paneLogs:
+		if m.logsID != "" {
+			return m.logsID, m.logsName, true
+		}
+	}
+	return "", "", false
+}
+
+func (m *model) selectedImage() (imageRow, bool) {
+	if i := m.selected[paneImages]; m.pane == paneImages && i < len(m.images) {
+		return m.images[i], true
+	}
+	return imageRow{}, false
+}
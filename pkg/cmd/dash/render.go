@@ -0,0 +1,188 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dash
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	styleReset   = "\033[0m"
+	styleBold    = "\033[1m"
+	styleReverse = "\033[7m"
+)
+
+var help = map[pane]string{
+	paneContainers: "↑↓ select  ←→ pane  enter logs  e exec  s stop  d remove  q quit",
+	paneImages:     "↑↓ select  ←→ pane  d remove  q quit",
+	paneLogs:       "↑↓ scroll  ←→ pane  e exec  s stop  q quit",
+	paneStats:      "↑↓ select  ←→ pane  enter logs  e exec  s stop  q quit",
+}
+
+// render returns the lines of the screen, without the trailing newlines.
+// The lines may contain the escape sequences of the styles, which do not count in width.
+func render(m *model, width, height int) []string {
+	lines := []string{titleBar(m, width)}
+	body := height - 2
+	switch m.pane {
+	case paneContainers:
+		rows := make([][]string, len(m.containers))
+		for i, c := range m.containers {
+			rows[i] = []string{shortID(c.ID), c.Names, c.Image, c.Status, c.Ports}
+		}
+		lines = append(lines, table([]string{"CONTAINER ID", "NAME", "IMAGE", "STATUS", "PORTS"}, rows, m.selected[m.pane], width, body)...)
+	case paneImages:
+		rows := make([][]string, len(m.images))
+		for i, img := range m.images {
+			rows[i] = []string{img.Repository, img.Tag, img.ID, img.CreatedSince, img.Platform, img.Size}
+		}
+		lines = append(lines, table([]string{"REPOSITORY", "TAG", "IMAGE ID", "CREATED", "PLATFORM", "SIZE"}, rows, m.selected[m.pane], width, body)...)
+	case paneStats:
+		rows := make([][]string, len(m.stats))
+		for i, s := range m.stats {
+			rows[i] = []string{s.ID, s.Name, s.CPUPerc, s.MemUsage, s.MemPerc, s.NetIO, s.BlockIO, s.PIDs}
+		}
+		lines = append(lines, table([]string{"CONTAINER ID", "NAME", "CPU %", "MEM USAGE / LIMIT", "MEM %", "NET I/O", "BLOCK I/O", "PIDS"}, rows, m.selected[m.pane], width, body)...)
+	case paneLogs:
+		lines = append(lines, logsView(m, width, body)...)
+	}
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+	return append(lines[:height-1], statusBar(m, width))
+}
+
+func titleBar(m *model, width int) string {
+	var b strings.Builder
+	plain := 0
+	write := func(s, style string) {
+		s = truncate(s, width-plain)
+		plain += utf8.RuneCountInString(s)
+		if style != "" && s != "" {
+			s = style + s + styleReset
+		}
+		b.WriteString(s)
+	}
+	write(" nerdctl dash ", styleBold)
+	for p := range numPanes {
+		style := ""
+		if p == m.pane {
+			style = styleReverse
+		}
+		write(" ", "")
+		write(fmt.Sprintf(" %d %s ", p+1, p), style)
+	}
+	write("  namespace: "+m.namespace, "")
+	return b.String()
+}
+
+func statusBar(m *model, width int) string {
+	switch {
+	case m.confirm != nil:
+		return styleBold + truncate(m.confirm.prompt+" [y/N]", width) + styleReset
+	case m.message != "":
+		return truncate(m.message, width)
+	default:
+		return truncate(help[m.pane], width)
+	}
+}
+
+// table formats the rows as columns, scrolled so that the selected row is visible.
+// It returns at most height lines, including the header.
+func table(header []string, rows [][]string, selected, width, height int) []string {
+	if height < 1 {
+		return nil
+	}
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	format := func(row []string) string {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = cell + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+		}
+		return truncate(strings.TrimRight(strings.Join(cells, "   "), " "), width)
+	}
+	lines := []string{styleBold + format(header) + styleReset}
+	first := max(0, selected-(height-2))
+	for i := first; i < len(rows) && len(lines) < height; i++ {
+		line := format(rows[i])
+		if i == selected {
+			line = styleReverse + line + strings.Repeat(" ", max(0, width-utf8.RuneCountInString(line))) + styleReset
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// logsView returns the last lines of the logs that fit in height, scrolled up by the selection.
+func logsView(m *model, width, height int) []string {
+	if height < 1 {
+		return nil
+	}
+	if m.logsID == "" {
+		return []string{"Select a container in the containers pane, and press enter to show its logs."}
+	}
+	lines := []string{styleBold + truncate("Logs of "+m.logsName, width) + styleReset}
+	end := len(m.logs) - m.selected[paneLogs]
+	start := max(0, end-(height-1))
+	for _, l := range m.logs[start:end] {
+		lines = append(lines, truncate(l, width))
+	}
+	return lines
+}
+
+// truncate truncates s to width runes.
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	r := []rune(s)
+	if width == 1 {
+		return string(r[:1])
+	}
+	return string(r[:width-1]) + "…"
+}
+
+// sanitize replaces the control characters in a log line, which would break the screen.
+func sanitize(s string) string {
+	s = strings.TrimRight(s, "\r")
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t':
+			return ' '
+		case r < 0x20, r == 0x7f:
+			return '?'
+		}
+		return r
+	}, s)
+}
+
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
@@ -25,7 +25,9 @@ import (
 	"os"
 	"strings"
 
+	"github.com/docker/go-units"
 	"github.com/klauspost/compress/zstd"
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 
 	overlaybdconvert "github.com/containerd/accelerated-container-image/pkg/convertor"
@@ -87,6 +89,7 @@ func Convert(ctx context.Context, client *containerd.Client, srcRawRef, targetRa
 	overlaybd := options.Overlaybd
 	nydus := options.Nydus
 	var finalize func(ctx context.Context, cs content.Store, ref string, desc *ocispec.Descriptor) (*images.Image, error)
+	var nydusDictBlobs map[digest.Digest]int64
 	if estargz || zstd || zstdchunked || overlaybd || nydus {
 		convertCount := 0
 		if estargz {
@@ -145,6 +148,15 @@ func Convert(ctx context.Context, client *containerd.Client, srcRawRef, targetRa
 			if err != nil {
 				return err
 			}
+			if options.NydusChunkDict != "" {
+				chunkDictPath, blobs, err := nydusChunkDict(ctx, client, options.NydusChunkDict, nydusOpts.WorkDir, platMC)
+				if err != nil {
+					return err
+				}
+				defer os.Remove(chunkDictPath)
+				nydusOpts.ChunkDictPath = chunkDictPath
+				nydusDictBlobs = blobs
+			}
 			convertHooks := converter.ConvertHooks{
 				PostConvertHook: nydusconvert.ConvertHookFunc(nydusconvert.MergeOption{
 					WorkDir:          nydusOpts.WorkDir,
@@ -214,6 +226,16 @@ func Convert(ctx context.Context, client *containerd.Client, srcRawRef, targetRa
 		}
 		res.ExtraImages = append(res.ExtraImages, finimg.Name+"@"+finimg.Target.Digest.String())
 	}
+	if nydus {
+		srcImg, err := client.ImageService().Get(ctx, srcRef)
+		if err != nil {
+			return err
+		}
+		res.NydusStats, err = converterutil.NewNydusStats(ctx, client.ContentStore(), srcImg.Target, newImg.Target, platMC, nydusDictBlobs)
+		if err != nil {
+			return err
+		}
+	}
 	return printConvertedImage(options.Stdout, options, res)
 }
 
@@ -309,13 +331,27 @@ func getNydusConvertOpts(options types.ImageConvertOptions) (*nydusconvert.PackO
 			return nil, err
 		}
 	}
+	prefetchPatterns := options.NydusPrefetchPatterns
+	if options.NydusPrefetchRecordIn != "" {
+		if !options.GOptions.Experimental {
+			return nil, fmt.Errorf("nydus-prefetch-record-in requires experimental mode to be enabled")
+		}
+
+		log.L.Warn("--nydus-prefetch-record-in flag is experimental and subject to change")
+		paths, err := readPathsFromRecordFile(options.NydusPrefetchRecordIn)
+		if err != nil {
+			return nil, err
+		}
+		// nydus-image takes the prefetch patterns separated by newlines
+		prefetchPatterns = strings.TrimSpace(strings.Join(append([]string{prefetchPatterns}, paths...), "\n"))
+	}
 	return &nydusconvert.PackOption{
 		BuilderPath: options.NydusBuilderPath,
 		// the path will finally be used is <NERDCTL_DATA_ROOT>/nydus-converter-<hash>,
 		// for example: /var/lib/nerdctl/1935db59/nydus-converter-3269662176/,
 		// and it will be deleted after the conversion
 		WorkDir:          workDir,
-		PrefetchPatterns: options.NydusPrefetchPatterns,
+		PrefetchPatterns: prefetchPatterns,
 		Compressor:       options.NydusCompressor,
 		FsVersion:        "6",
 	}, nil
@@ -368,6 +404,12 @@ func printConvertedImage(stdout io.Writer, options types.ImageConvertOptions, im
 				log.L.Infof("Extra image(%d) %s", i, elems[0])
 			}
 		}
+		if st := img.NydusStats; st != nil {
+			log.L.Infof("Nydus: source layers %s, bootstrap %s, data blobs %s",
+				units.HumanSize(float64(st.SourceSize)), units.HumanSize(float64(st.BootstrapSize)), units.HumanSize(float64(st.BlobSize)))
+			log.L.Infof("Nydus: %s (%.1f%%) of the data blobs deduplicated with the chunk dictionary, estimated cold-start savings %.1f%%",
+				units.HumanSize(float64(st.DedupSize)), st.DedupRatio*100, st.ColdStartSavings*100)
+		}
 		elems := strings.SplitN(img.Image, "@", 2)
 		if len(elems) < 2 {
 			log.L.Errorf("reference %q doesn't contain digest", img.Image)
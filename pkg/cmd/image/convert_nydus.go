@@ -0,0 +1,111 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	nydusconvert "github.com/containerd/nydus-snapshotter/pkg/converter"
+	"github.com/containerd/platforms"
+
+	converterutil "github.com/containerd/nerdctl/v2/pkg/imgutil/converter"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
+)
+
+// nydusChunkDict extracts the bootstrap of the local nydus image rawRef into workDir, for using it as a chunk dictionary.
+// The data blobs of the image are returned too, for computing the deduplication statistics.
+// The caller should remove the bootstrap file.
+func nydusChunkDict(ctx context.Context, client *containerd.Client, rawRef, workDir string, platMC platforms.MatchComparer) (string, map[digest.Digest]int64, error) {
+	parsedReference, err := referenceutil.Parse(rawRef)
+	if err != nil {
+		return "", nil, err
+	}
+	img, err := client.ImageService().Get(ctx, parsedReference.String())
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to find the chunk dictionary image %q: %w", rawRef, err)
+	}
+	cs := client.ContentStore()
+	manifest, err := images.Manifest(ctx, cs, img.Target, platMC)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read the manifest of the chunk dictionary image %q: %w", rawRef, err)
+	}
+	bootstrapIdx := -1
+	for i, l := range manifest.Layers {
+		if nydusconvert.IsNydusBootstrap(l) {
+			bootstrapIdx = i
+		}
+	}
+	if bootstrapIdx < 0 {
+		return "", nil, fmt.Errorf("the chunk dictionary image %q is not a nydus image (hint: convert it with `nerdctl image convert --nydus --oci` first)", rawRef)
+	}
+	ra, err := cs.ReaderAt(ctx, manifest.Layers[bootstrapIdx])
+	if err != nil {
+		return "", nil, err
+	}
+	defer ra.Close()
+
+	f, err := os.CreateTemp(workDir, "nydus-chunk-dict-")
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+	if err := extractNydusBootstrap(content.NewReader(ra), f); err != nil {
+		os.Remove(f.Name())
+		return "", nil, fmt.Errorf("failed to extract the bootstrap of the chunk dictionary image %q: %w", rawRef, err)
+	}
+	blobs, err := converterutil.NydusBlobs(ctx, cs, img.Target, platMC)
+	if err != nil {
+		os.Remove(f.Name())
+		return "", nil, err
+	}
+	return f.Name(), blobs, nil
+}
+
+// extractNydusBootstrap extracts the bootstrap file from the tar.gz bootstrap layer r.
+func extractNydusBootstrap(r io.Reader, w io.Writer) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s not found in the bootstrap layer", nydusconvert.BootstrapFileNameInLayer)
+		}
+		if err != nil {
+			return err
+		}
+		if strings.TrimPrefix(hdr.Name, "./") == nydusconvert.BootstrapFileNameInLayer {
+			_, err = io.Copy(w, tr)
+			return err
+		}
+	}
+}
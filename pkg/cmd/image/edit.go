@@ -0,0 +1,306 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/images/converter"
+	"github.com/containerd/platforms"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	nerdconverter "github.com/containerd/nerdctl/v2/pkg/imgutil/converter"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/imgconfig"
+	"github.com/containerd/nerdctl/v2/pkg/platformutil"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
+)
+
+// editedConfigKeys are the keys of the image config that may be modified by imgconfig.ApplyChanges.
+// The other keys, including the non-OCI ones such as "Healthcheck", are preserved as they are.
+var editedConfigKeys = []string{"User", "ExposedPorts", "Env", "Entrypoint", "Cmd", "Volumes", "WorkingDir", "Labels", "StopSignal"}
+
+// Edit creates targetRawRef from srcRawRef, by rewriting only the config of the image.
+// The layers are reused as they are, and do not need to be present in the content store.
+func Edit(ctx context.Context, client *containerd.Client, srcRawRef, targetRawRef string, options types.ImageEditOptions) error {
+	changes, err := editChanges(options)
+	if err != nil {
+		return err
+	}
+
+	parsedReference, err := referenceutil.Parse(srcRawRef)
+	if err != nil {
+		return err
+	}
+	srcRef := parsedReference.String()
+
+	parsedReference, err = referenceutil.Parse(targetRawRef)
+	if err != nil {
+		return err
+	}
+	targetRef := parsedReference.String()
+
+	platMC, err := platformutil.NewMatchComparer(options.AllPlatforms, options.Platforms)
+	if err != nil {
+		return err
+	}
+
+	e := &imageEditor{changes: changes, platformMC: platMC}
+	img, err := nerdconverter.Convert(ctx, client, targetRef, srcRef, converter.WithIndexConvertFunc(e.convert))
+	if err != nil {
+		return fmt.Errorf("failed to edit %q: %w", srcRef, err)
+	}
+	_, err = fmt.Fprintln(options.Stdout, img.Target.Digest.String())
+	return err
+}
+
+// editChanges returns the changes specified by --change and the other flags.
+// The other flags take precedence over --change.
+func editChanges(options types.ImageEditOptions) (imgconfig.Changes, error) {
+	changes, err := imgconfig.ParseChanges(options.Change)
+	if err != nil {
+		return imgconfig.Changes{}, err
+	}
+	for _, l := range options.Label {
+		k, v, ok := strings.Cut(l, "=")
+		if !ok || k == "" {
+			return imgconfig.Changes{}, fmt.Errorf("invalid label %q, expected KEY=VALUE", l)
+		}
+		if changes.Labels == nil {
+			changes.Labels = make(map[string]string)
+		}
+		changes.Labels[k] = v
+	}
+	for _, e := range options.Env {
+		if k, _, ok := strings.Cut(e, "="); !ok || k == "" {
+			return imgconfig.Changes{}, fmt.Errorf("invalid environment variable %q, expected KEY=VALUE", e)
+		}
+		changes.Env = append(changes.Env, e)
+	}
+	if options.Entrypoint != "" {
+		changes.Entrypoint = imgconfig.ParseCommand(options.Entrypoint)
+	}
+	if options.Cmd != "" {
+		changes.CMD = imgconfig.ParseCommand(options.Cmd)
+	}
+	if options.User != "" {
+		changes.User = options.User
+	}
+	if options.Workdir != "" {
+		changes.WorkingDir = options.Workdir
+	}
+	if len(options.Expose) > 0 {
+		exposed, _, err := nat.ParsePortSpecs(options.Expose)
+		if err != nil {
+			return imgconfig.Changes{}, err
+		}
+		for p := range exposed {
+			changes.ExposedPorts = append(changes.ExposedPorts, string(p))
+		}
+		slices.Sort(changes.ExposedPorts)
+	}
+	if changes.CMD == nil && changes.Entrypoint == nil && len(changes.Env) == 0 && len(changes.ExposedPorts) == 0 &&
+		len(changes.Labels) == 0 && changes.User == "" && changes.WorkingDir == "" && len(changes.Volumes) == 0 && changes.StopSignal == "" {
+		return imgconfig.Changes{}, errors.New("no changes specified")
+	}
+	return changes, nil
+}
+
+// imageEditor implements converter.ConvertFunc for rewriting the image configs.
+type imageEditor struct {
+	changes    imgconfig.Changes
+	platformMC platforms.MatchComparer
+}
+
+func (e *imageEditor) convert(ctx context.Context, cs content.Store, desc ocispec.Descriptor) (*ocispec.Descriptor, error) {
+	switch {
+	case images.IsIndexType(desc.MediaType):
+		return e.convertIndex(ctx, cs, desc)
+	case images.IsManifestType(desc.MediaType):
+		return e.convertManifest(ctx, cs, desc)
+	default:
+		return nil, fmt.Errorf("unsupported media type %q", desc.MediaType)
+	}
+}
+
+// convertIndex edits the manifests of the platforms matched by platformMC, and removes the other manifests.
+// The attestation manifests of the edited manifests are kept, with their references updated.
+func (e *imageEditor) convertIndex(ctx context.Context, cs content.Store, desc ocispec.Descriptor) (*ocispec.Descriptor, error) {
+	var index ocispec.Index
+	labels, err := readJSONWithLabels(ctx, cs, desc, &index)
+	if err != nil {
+		return nil, err
+	}
+
+	edited := make(map[string]string) // key: old digest, value: new digest
+	var manifests []ocispec.Descriptor
+	for _, m := range index.Manifests {
+		if nerdconverter.IsAttestationManifest(m) {
+			manifests = append(manifests, m)
+			continue
+		}
+		if m.Platform != nil && !e.platformMC.Match(*m.Platform) {
+			continue
+		}
+		newDesc, err := e.convert(ctx, cs, m)
+		if err != nil {
+			if m.Platform != nil {
+				return nil, fmt.Errorf("failed to edit the manifest for %s (hint: specify --platform): %w", platforms.Format(*m.Platform), err)
+			}
+			return nil, err
+		}
+		edited[m.Digest.String()] = newDesc.Digest.String()
+		manifests = append(manifests, *newDesc)
+	}
+	if len(edited) == 0 {
+		return nil, errors.New("no manifest matched the platform")
+	}
+
+	index.Manifests = nil
+	for _, m := range manifests {
+		if nerdconverter.IsAttestationManifest(m) {
+			subject, ok := edited[m.Annotations[nerdconverter.AttestationReferenceDigestAnnotation]]
+			if !ok {
+				continue
+			}
+			m.Annotations = maps.Clone(m.Annotations)
+			m.Annotations[nerdconverter.AttestationReferenceDigestAnnotation] = subject
+		}
+		index.Manifests = append(index.Manifests, m)
+	}
+
+	for k := range labels {
+		if strings.HasPrefix(k, "containerd.io/gc.ref.content.m.") {
+			delete(labels, k)
+		}
+	}
+	for i, m := range index.Manifests {
+		labels[fmt.Sprintf("containerd.io/gc.ref.content.m.%d", i)] = m.Digest.String()
+	}
+	return writeJSONWithLabels(ctx, cs, desc, &index, labels)
+}
+
+// convertManifest edits the config of the manifest.
+func (e *imageEditor) convertManifest(ctx context.Context, cs content.Store, desc ocispec.Descriptor) (*ocispec.Descriptor, error) {
+	var manifest ocispec.Manifest
+	labels, err := readJSONWithLabels(ctx, cs, desc, &manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg map[string]json.RawMessage
+	cfgLabels, err := readJSONWithLabels(ctx, cs, manifest.Config, &cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.editConfig(cfg); err != nil {
+		return nil, err
+	}
+	newConfig, err := writeJSONWithLabels(ctx, cs, manifest.Config, cfg, cfgLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest.Config = *newConfig
+	labels["containerd.io/gc.ref.content.config"] = newConfig.Digest.String()
+	return writeJSONWithLabels(ctx, cs, desc, &manifest, labels)
+}
+
+// editConfig applies the changes to the "config" field of the image config.
+func (e *imageEditor) editConfig(cfg map[string]json.RawMessage) error {
+	var (
+		raw       map[string]json.RawMessage
+		imgConfig ocispec.ImageConfig
+	)
+	if b, ok := cfg["config"]; ok && string(b) != "null" {
+		if err := json.Unmarshal(b, &raw); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(b, &imgConfig); err != nil {
+			return err
+		}
+	}
+	if raw == nil {
+		raw = make(map[string]json.RawMessage)
+	}
+
+	imgconfig.ApplyChanges(&imgConfig, e.changes)
+
+	b, err := json.Marshal(imgConfig)
+	if err != nil {
+		return err
+	}
+	var newRaw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &newRaw); err != nil {
+		return err
+	}
+	for _, k := range editedConfigKeys {
+		if v, ok := newRaw[k]; ok {
+			raw[k] = v
+		} else {
+			delete(raw, k)
+		}
+	}
+	cfg["config"], err = json.Marshal(raw)
+	return err
+}
+
+func readJSONWithLabels(ctx context.Context, cs content.Store, desc ocispec.Descriptor, v any) (map[string]string, error) {
+	info, err := cs.Info(ctx, desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+	b, err := content.ReadBlob(ctx, cs, desc)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return nil, err
+	}
+	labels := info.Labels
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	return labels, nil
+}
+
+func writeJSONWithLabels(ctx context.Context, cs content.Store, desc ocispec.Descriptor, v any, labels map[string]string) (*ocispec.Descriptor, error) {
+	b, err := json.MarshalIndent(v, "", "   ")
+	if err != nil {
+		return nil, err
+	}
+	newDesc := desc
+	newDesc.Digest = digest.FromBytes(b)
+	newDesc.Size = int64(len(b))
+	if err := content.WriteBlob(ctx, cs, newDesc.Digest.String(), bytes.NewReader(b), newDesc, content.WithLabels(labels)); err != nil {
+		return nil, err
+	}
+	return &newDesc, nil
+}
@@ -0,0 +1,133 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/plugins/content/local"
+	"github.com/containerd/platforms"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	nerdconverter "github.com/containerd/nerdctl/v2/pkg/imgutil/converter"
+	"github.com/containerd/nerdctl/v2/pkg/platformutil"
+)
+
+func writeTestJSON(t *testing.T, cs content.Store, mediaType string, v any, labels map[string]string) ocispec.Descriptor {
+	t.Helper()
+	b, err := json.Marshal(v)
+	assert.NilError(t, err)
+	desc := ocispec.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(b), Size: int64(len(b))}
+	assert.NilError(t, content.WriteBlob(context.Background(), cs, desc.Digest.String(), bytes.NewReader(b), desc, content.WithLabels(labels)))
+	return desc
+}
+
+func readTestJSON(t *testing.T, cs content.Store, desc ocispec.Descriptor, v any) {
+	t.Helper()
+	b, err := content.ReadBlob(context.Background(), cs, desc)
+	assert.NilError(t, err)
+	assert.NilError(t, json.Unmarshal(b, v))
+}
+
+func TestImageEditor(t *testing.T) {
+	ctx := context.Background()
+	cs, err := local.NewStore(t.TempDir())
+	assert.NilError(t, err)
+
+	config := writeTestJSON(t, cs, ocispec.MediaTypeImageConfig, map[string]any{
+		"architecture": "amd64",
+		"os":           "linux",
+		"config": map[string]any{
+			"Env":         []string{"PATH=/usr/bin"},
+			"Cmd":         []string{"sh"},
+			"Healthcheck": map[string]any{"Test": []string{"CMD", "true"}},
+		},
+		"rootfs": map[string]any{"type": "layers", "diff_ids": []string{}},
+	}, nil)
+	layer := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayerGzip, Digest: digest.FromString("layer, not present in the content store"), Size: 42}
+	manifest := writeTestJSON(t, cs, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    []ocispec.Descriptor{layer},
+	}, nil)
+	manifest.Platform = &ocispec.Platform{OS: "linux", Architecture: "amd64"}
+	other := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromString("other platform, not present in the content store"),
+		Size:      42,
+		Platform:  &ocispec.Platform{OS: "linux", Architecture: "arm64"},
+	}
+	attestation := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromString("attestation"),
+		Size:      42,
+		Platform:  &platformutil.AttestationPlatform,
+		Annotations: map[string]string{
+			nerdconverter.AttestationReferenceTypeAnnotation:   nerdconverter.AttestationManifestType,
+			nerdconverter.AttestationReferenceDigestAnnotation: manifest.Digest.String(),
+		},
+	}
+	index := writeTestJSON(t, cs, ocispec.MediaTypeImageIndex, ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{manifest, other, attestation},
+	}, nil)
+
+	changes, err := editChanges(types.ImageEditOptions{
+		Label: []string{"org.example.build-id=42"},
+		Env:   []string{"FOO=bar"},
+	})
+	assert.NilError(t, err)
+	e := &imageEditor{changes: changes, platformMC: platforms.Only(*manifest.Platform)}
+	newIndexDesc, err := e.convert(ctx, cs, index)
+	assert.NilError(t, err)
+
+	var newIndex ocispec.Index
+	readTestJSON(t, cs, *newIndexDesc, &newIndex)
+	assert.Equal(t, len(newIndex.Manifests), 2)
+	newManifestDesc := newIndex.Manifests[0]
+	assert.Assert(t, newManifestDesc.Digest != manifest.Digest)
+	assert.Equal(t, newIndex.Manifests[1].Annotations[nerdconverter.AttestationReferenceDigestAnnotation], newManifestDesc.Digest.String())
+
+	var newManifest ocispec.Manifest
+	readTestJSON(t, cs, newManifestDesc, &newManifest)
+	assert.DeepEqual(t, newManifest.Layers, []ocispec.Descriptor{layer})
+
+	var newConfig struct {
+		Config struct {
+			ocispec.ImageConfig
+			Healthcheck map[string]any
+		} `json:"config"`
+	}
+	readTestJSON(t, cs, newManifest.Config, &newConfig)
+	assert.DeepEqual(t, newConfig.Config.Env, []string{"PATH=/usr/bin", "FOO=bar"})
+	assert.DeepEqual(t, newConfig.Config.Cmd, []string{"sh"})
+	assert.DeepEqual(t, newConfig.Config.Labels, map[string]string{"org.example.build-id": "42"})
+	assert.Assert(t, newConfig.Config.Healthcheck != nil, "unknown fields must be preserved")
+}
+
+func TestEditChangesEmpty(t *testing.T) {
+	_, err := editChanges(types.ImageEditOptions{})
+	assert.ErrorContains(t, err, "no changes specified")
+}
@@ -79,11 +79,12 @@ func ensureOne(ctx context.Context, client *containerd.Client, rawRef string, ta
 	if len(missing) > 0 {
 		// Get a resolver
 		var dOpts []dockerconfigresolver.Opt
-		if options.InsecureRegistry {
+		if options.IsInsecureRegistry(parsedReference.Domain) {
 			log.G(ctx).Warnf("skipping verifying HTTPS certs for %q", parsedReference.Domain)
 			dOpts = append(dOpts, dockerconfigresolver.WithSkipVerifyCerts(true))
 		}
-		dOpts = append(dOpts, dockerconfigresolver.WithHostsDirs(options.HostsDir))
+		dOpts = append(dOpts, dockerconfigresolver.WithHostsDirs(options.HostsDir), dockerconfigresolver.WithOffline(options.Offline),
+			dockerconfigresolver.WithRegistryConfigs(options.Registries), dockerconfigresolver.WithSystemKeychain(options.Credentials.Keychain))
 		resolver, err := dockerconfigresolver.New(ctx, parsedReference.Domain, dOpts...)
 		if err != nil {
 			return err
@@ -102,7 +103,7 @@ func ensureOne(ctx context.Context, client *containerd.Client, rawRef string, ta
 			if !errors.Is(err, http.ErrSchemeMismatch) && !errutil.IsErrConnectionRefused(err) {
 				return err
 			}
-			if options.InsecureRegistry {
+			if options.IsInsecureRegistry(parsedReference.Domain) {
 				log.G(ctx).WithError(err).Warnf("server %q does not seem to support HTTPS, falling back to plain HTTP", parsedReference.Domain)
 				dOpts = append(dOpts, dockerconfigresolver.WithPlainHTTP(true))
 				resolver, err = dockerconfigresolver.New(ctx, parsedReference.Domain, dOpts...)
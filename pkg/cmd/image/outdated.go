@@ -0,0 +1,325 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/Masterminds/semver/v3"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/log"
+	"github.com/containerd/platforms"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
+)
+
+// Policies of `nerdctl image outdated --policy`.
+const (
+	OutdatedPolicyDigest = "digest"
+	OutdatedPolicyPatch  = "patch"
+	OutdatedPolicyMinor  = "minor"
+	OutdatedPolicyMajor  = "major"
+)
+
+// Statuses of `nerdctl image outdated`.
+const (
+	OutdatedStatusUpToDate = "up-to-date"
+	// OutdatedStatusOutdated is for the tags that point to a new digest in the registry
+	OutdatedStatusOutdated = "outdated"
+	// OutdatedStatusNewerTag is for the tags that are up to date, but have a newer tag allowed by the policy
+	OutdatedStatusNewerTag = "newer-tag"
+	// OutdatedStatusUnknown is for the images that could not be checked, e.g., when the registry is not reachable
+	OutdatedStatusUnknown = "unknown"
+)
+
+type outdatedImage struct {
+	Name string
+	// Digest is the digest of the local image
+	Digest string
+	// Remote is the digest of the same tag in the registry
+	Remote   string
+	Status   string
+	NewerTag string `json:",omitempty"`
+	// Containers are the names of the containers created from the local image
+	Containers []string
+	Error      string `json:",omitempty"`
+}
+
+// Outdated compares the tagged images with their registries, and prints the images that are stale
+// along with the containers created from them.
+func Outdated(ctx context.Context, client *containerd.Client, args []string, options types.ImageOutdatedOptions) error {
+	switch options.Policy {
+	case OutdatedPolicyDigest, OutdatedPolicyPatch, OutdatedPolicyMinor, OutdatedPolicyMajor:
+	default:
+		return fmt.Errorf("unknown policy %q, must be one of %q, %q, %q, %q",
+			options.Policy, OutdatedPolicyDigest, OutdatedPolicyPatch, OutdatedPolicyMinor, OutdatedPolicyMajor)
+	}
+
+	imageList, err := List(ctx, client, nil, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 && len(imageList) == 0 {
+		return fmt.Errorf("no such image: %s", strings.Join(args, ", "))
+	}
+	containersByImage, err := containersByImage(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	var entries []outdatedImage
+	for _, img := range imageList {
+		parsed, err := referenceutil.Parse(img.Name)
+		if err != nil || parsed.Protocol != "" || parsed.Tag == "" || parsed.Digest != "" {
+			// Only the tags can go stale
+			continue
+		}
+		entries = append(entries, checkOutdated(ctx, img, parsed, containersByImage[img.Name], options))
+	}
+
+	if err := printOutdated(entries, options); err != nil {
+		return err
+	}
+
+	if options.Update {
+		return updateOutdated(ctx, client, entries, options)
+	}
+	return nil
+}
+
+func checkOutdated(ctx context.Context, img images.Image, parsed *referenceutil.ImageReference, containers []string, options types.ImageOutdatedOptions) outdatedImage {
+	entry := outdatedImage{
+		Name:       img.Name,
+		Digest:     img.Target.Digest.String(),
+		Containers: containers,
+		Status:     OutdatedStatusUpToDate,
+	}
+	if entry.Containers == nil {
+		entry.Containers = []string{}
+	}
+	remote, err := imgutil.ResolveDigest(ctx, img.Name, options.GOptions.IsInsecureRegistry, options.GOptions.HostsDir,
+		dockerconfigresolver.WithOffline(options.GOptions.Offline), dockerconfigresolver.WithRegistryConfigs(options.GOptions.Registries), dockerconfigresolver.WithSystemKeychain(options.GOptions.Credentials.Keychain))
+	if err != nil {
+		entry.Status = OutdatedStatusUnknown
+		entry.Error = err.Error()
+		return entry
+	}
+	entry.Remote = remote
+	if remote != entry.Digest {
+		entry.Status = OutdatedStatusOutdated
+	}
+	if options.Policy == OutdatedPolicyDigest {
+		return entry
+	}
+	tags, err := imgutil.ListTags(ctx, img.Name, options.GOptions.IsInsecureRegistry, options.GOptions.HostsDir,
+		dockerconfigresolver.WithOffline(options.GOptions.Offline), dockerconfigresolver.WithRegistryConfigs(options.GOptions.Registries), dockerconfigresolver.WithSystemKeychain(options.GOptions.Credentials.Keychain))
+	if err != nil {
+		// The digest check is still meaningful
+		log.G(ctx).WithError(err).Warnf("failed to list the tags of %s", img.Name)
+		return entry
+	}
+	if newer := newerTag(parsed.Tag, tags, options.Policy); newer != "" {
+		entry.NewerTag = newer
+		if entry.Status == OutdatedStatusUpToDate {
+			entry.Status = OutdatedStatusNewerTag
+		}
+	}
+	return entry
+}
+
+// newerTag returns the highest tag in tags that is newer than current, and allowed by policy.
+//
+// Only the tags of the same shape are considered, e.g., "1.19-alpine" can be followed by "1.21-alpine",
+// but not by "1.21" nor by "1.21.0-alpine", so that the variant of the image is kept.
+// A tag that is not a version, such as "latest", is never followed.
+func newerTag(current string, tags []string, policy string) string {
+	cur, err := semver.NewVersion(current)
+	if err != nil {
+		return ""
+	}
+	var best *semver.Version
+	bestTag := ""
+	for _, tag := range tags {
+		if tagShape(tag) != tagShape(current) {
+			continue
+		}
+		v, err := semver.NewVersion(tag)
+		if err != nil || v.Prerelease() != cur.Prerelease() || !v.GreaterThan(cur) {
+			continue
+		}
+		switch policy {
+		case OutdatedPolicyPatch:
+			if v.Major() != cur.Major() || v.Minor() != cur.Minor() {
+				continue
+			}
+		case OutdatedPolicyMinor:
+			if v.Major() != cur.Major() {
+				continue
+			}
+		case OutdatedPolicyMajor:
+		default:
+			return ""
+		}
+		if best == nil || v.GreaterThan(best) {
+			best = v
+			bestTag = tag
+		}
+	}
+	return bestTag
+}
+
+// tagShape returns the tag with the digits replaced, e.g., "v1.19-alpine" becomes "v0.0-alpine".
+func tagShape(tag string) string {
+	var b strings.Builder
+	prevDigit := false
+	for _, r := range tag {
+		isDigit := '0' <= r && r <= '9'
+		if !isDigit {
+			b.WriteRune(r)
+		} else if !prevDigit {
+			b.WriteRune('0')
+		}
+		prevDigit = isDigit
+	}
+	return b.String()
+}
+
+// containersByImage returns the names of the containers, by the name of their image.
+func containersByImage(ctx context.Context, client *containerd.Client) (map[string][]string, error) {
+	containers, err := client.Containers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string][]string)
+	for _, c := range containers {
+		info, err := c.Info(ctx, containerd.WithoutRefreshedMetadata)
+		if err != nil {
+			// The container may have been removed in the meantime
+			continue
+		}
+		name := info.Labels[labels.Name]
+		if name == "" {
+			name = info.ID
+		}
+		res[info.Image] = append(res[info.Image], name)
+	}
+	for _, names := range res {
+		slices.Sort(names)
+	}
+	return res, nil
+}
+
+func printOutdated(entries []outdatedImage, options types.ImageOutdatedOptions) error {
+	w := options.Stdout
+	var tmpl *template.Template
+	switch options.Format {
+	case "", "table":
+		w = tabwriter.NewWriter(w, 4, 8, 4, ' ', 0)
+		fmt.Fprintln(w, "NAME\tSTATUS\tNEWER TAG\tCONTAINERS")
+	case "raw", "wide":
+		return errors.New("unsupported format: \"raw\" and \"wide\"")
+	default:
+		var err error
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, e := range entries {
+		if tmpl != nil {
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, e); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w, b.String()); err != nil {
+				return err
+			}
+			continue
+		}
+		newer := e.NewerTag
+		if newer == "" {
+			newer = "-"
+		}
+		containers := strings.Join(e.Containers, ",")
+		if containers == "" {
+			containers = "-"
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Name, e.Status, newer, containers); err != nil {
+			return err
+		}
+	}
+	if f, ok := w.(formatter.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// updateOutdated pulls the updated tags and the newer tags.
+// The containers keep running the image they were created from, until they are recreated.
+func updateOutdated(ctx context.Context, client *containerd.Client, entries []outdatedImage, options types.ImageOutdatedOptions) error {
+	pullOptions := types.ImagePullOptions{
+		Stdout:          options.Stdout,
+		Stderr:          options.Stderr,
+		GOptions:        options.GOptions,
+		VerifyOptions:   types.ImageVerifyOptions{Provider: "none"},
+		OCISpecPlatform: []ocispec.Platform{platforms.DefaultSpec()},
+		Mode:            "always",
+		Quiet:           true,
+	}
+	var errs []error
+	for _, e := range entries {
+		var refs []string
+		if e.Status == OutdatedStatusOutdated {
+			refs = append(refs, e.Name)
+		}
+		if e.NewerTag != "" {
+			parsed, err := referenceutil.Parse(e.Name)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			refs = append(refs, parsed.Name()+":"+e.NewerTag)
+		}
+		for _, ref := range refs {
+			log.G(ctx).Infof("Pulling %s", ref)
+			if err := Pull(ctx, client, ref, pullOptions); err != nil {
+				errs = append(errs, fmt.Errorf("failed to pull %s: %w", ref, err))
+				continue
+			}
+			if len(e.Containers) > 0 {
+				log.G(ctx).Infof("Containers %s must be recreated to use the new image", strings.Join(e.Containers, ", "))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,56 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestNewerTag(t *testing.T) {
+	tags := []string{
+		"latest", "1", "1.19", "1.19-alpine", "1.19.1-alpine", "1.19.3-alpine",
+		"1.20-alpine", "1.21-alpine", "1.21", "2.0-alpine", "v2.1-alpine", "1.21-alpine-slim",
+	}
+	testCases := []struct {
+		current  string
+		policy   string
+		expected string
+	}{
+		{"1.19-alpine", OutdatedPolicyDigest, ""},
+		{"1.19-alpine", OutdatedPolicyPatch, ""},
+		{"1.19.1-alpine", OutdatedPolicyPatch, "1.19.3-alpine"},
+		{"1.19-alpine", OutdatedPolicyMinor, "1.21-alpine"},
+		{"1.19-alpine", OutdatedPolicyMajor, "2.0-alpine"},
+		{"1.19", OutdatedPolicyMajor, "1.21"},
+		{"1.21-alpine", OutdatedPolicyMinor, ""},
+		{"latest", OutdatedPolicyMajor, ""},
+		{"1", OutdatedPolicyMajor, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.current+"/"+tc.policy, func(t *testing.T) {
+			assert.Equal(t, newerTag(tc.current, tags, tc.policy), tc.expected)
+		})
+	}
+}
+
+func TestTagShape(t *testing.T) {
+	assert.Equal(t, tagShape("1.19-alpine"), "0.0-alpine")
+	assert.Equal(t, tagShape("v12.3.40"), "v0.0.0")
+	assert.Equal(t, tagShape("latest"), "latest")
+}
@@ -0,0 +1,330 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/converter"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
+	"github.com/containerd/nerdctl/v2/pkg/signutil"
+)
+
+// sbomPredicateTypes are the prefixes of the in-toto predicate types of SBOM attestations.
+var sbomPredicateTypes = []string{
+	"https://spdx.dev/Document",
+	"https://cyclonedx.org/bom",
+}
+
+// severities are the severities of vulnerabilities, from the lowest.
+// "negligible" is used by grype.
+var severities = []string{"unknown", "negligible", "low", "medium", "high", "critical"}
+
+// Promote copies the image `source` to `target` (e.g., from a staging repository to a production repository),
+// after checking the policy gates specified in options.
+//
+// The image is copied as it is, with all the platforms and the attestations, so the digest is kept.
+func Promote(ctx context.Context, client *containerd.Client, source, target string, options types.ImagePromoteOptions) error {
+	srcRef, err := referenceutil.Parse(source)
+	if err != nil {
+		return err
+	}
+	if srcRef.Protocol != "" {
+		return errors.New("promoting an image on IPFS is not supported")
+	}
+	targetRef, err := referenceutil.Parse(target)
+	if err != nil {
+		return err
+	}
+	if targetRef.Protocol != "" {
+		return errors.New("promoting an image to IPFS is not supported")
+	}
+	threshold := -1
+	if options.Scanner != "" || options.ScanReport != "" {
+		if threshold = severityIndex(options.SeverityThreshold); threshold < 0 {
+			return fmt.Errorf("invalid severity threshold %q (must be one of low, medium, high, critical)", options.SeverityThreshold)
+		}
+	}
+	if options.Scanner != "" && options.ScanReport != "" {
+		return errors.New("--scanner and --scan-report cannot be specified together")
+	}
+
+	unpack := false
+	pullOptions := types.ImagePullOptions{
+		Stdout:        options.Stdout,
+		Stderr:        options.Stderr,
+		GOptions:      options.GOptions,
+		VerifyOptions: options.VerifyOptions,
+		Unpack:        &unpack,
+		Mode:          "always",
+		Quiet:         options.Quiet,
+	}
+	// Signature gate
+	ensured, err := EnsureImage(ctx, client, srcRef.String(), pullOptions)
+	if err != nil {
+		return fmt.Errorf("failed to pull (or verify) %s: %w", srcRef, err)
+	}
+	desc := ensured.Image.Target()
+	if options.VerifyOptions.Provider != "none" && options.VerifyOptions.Provider != "" {
+		log.G(ctx).Infof("policy: %s signature of %s verified", options.VerifyOptions.Provider, desc.Digest)
+	}
+
+	// SBOM gate
+	if options.RequireSBOM {
+		ok, err := hasSBOM(ctx, client.ContentStore(), desc)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("policy: no SBOM attestation attached to %s", srcRef)
+		}
+		log.G(ctx).Infof("policy: SBOM attestation of %s found", desc.Digest)
+	}
+
+	// Vulnerability gate
+	if threshold >= 0 {
+		var report []byte
+		if options.Scanner != "" {
+			report, err = runScanner(options.Scanner, srcRef.Name()+"@"+desc.Digest.String())
+		} else {
+			report, err = os.ReadFile(options.ScanReport)
+		}
+		if err != nil {
+			return err
+		}
+		if err := checkScanReport(report, desc.Digest.String(), threshold); err != nil {
+			return fmt.Errorf("policy: %s: %w", srcRef, err)
+		}
+		log.G(ctx).Infof("policy: no vulnerabilities of %s severity or higher found", severities[threshold])
+	}
+
+	if options.DryRun {
+		log.G(ctx).Infof("policy: all checks passed, not promoting %s to %s (dry run)", srcRef, targetRef)
+		return nil
+	}
+
+	if options.CopySignatures {
+		if err := pullCosignSignature(ctx, client, srcRef.Name(), desc.Digest, pullOptions); err != nil {
+			if options.VerifyOptions.Provider == "cosign" {
+				return err
+			}
+			log.G(ctx).WithError(err).Debug("no cosign signature to copy")
+		}
+	}
+
+	if err := Tag(ctx, client, types.ImageTagOptions{
+		GOptions: options.GOptions,
+		Source:   ensured.Ref,
+		Target:   targetRef.String(),
+	}); err != nil {
+		return err
+	}
+	// The cosign signature pulled above is pushed together with the image.
+	if err := Push(ctx, client, targetRef.String(), types.ImagePushOptions{
+		Stdout:       options.Stdout,
+		GOptions:     options.GOptions,
+		AllPlatforms: true,
+		Quiet:        options.Quiet,
+	}); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(options.Stdout, "%s@%s\n", targetRef.Name(), desc.Digest)
+	return err
+}
+
+// pullCosignSignature pulls the cosign signature of the manifest dgst in the repository repo, and marks it
+// as a local signature of the manifest, to push it together with the image.
+func pullCosignSignature(ctx context.Context, client *containerd.Client, repo string, dgst digest.Digest, pullOptions types.ImagePullOptions) error {
+	pullOptions.VerifyOptions = types.ImageVerifyOptions{Provider: "none"}
+	sigRef := repo + ":" + signutil.CosignSignatureTag(dgst)
+	ensured, err := EnsureImage(ctx, client, sigRef, pullOptions)
+	if err != nil {
+		return fmt.Errorf("failed to pull the cosign signature %s: %w", sigRef, err)
+	}
+	img, err := client.ImageService().Get(ctx, ensured.Ref)
+	if err != nil {
+		return err
+	}
+	if img.Labels == nil {
+		img.Labels = make(map[string]string)
+	}
+	img.Labels[signutil.LabelSignatureSubject] = dgst.String()
+	img.Labels[signutil.LabelSignatureProvider] = "cosign"
+	_, err = client.ImageService().Update(ctx, img, "labels."+signutil.LabelSignatureSubject, "labels."+signutil.LabelSignatureProvider)
+	return err
+}
+
+// hasSBOM returns true when the image index desc contains an SBOM attestation.
+func hasSBOM(ctx context.Context, cs content.Provider, desc ocispec.Descriptor) (bool, error) {
+	if !images.IsIndexType(desc.MediaType) {
+		return false, nil
+	}
+	var index ocispec.Index
+	if err := readJSONBlob(ctx, cs, desc, &index); err != nil {
+		return false, err
+	}
+	for _, m := range index.Manifests {
+		if !converter.IsAttestationManifest(m) {
+			continue
+		}
+		var manifest ocispec.Manifest
+		if err := readJSONBlob(ctx, cs, m, &manifest); err != nil {
+			return false, err
+		}
+		for _, l := range manifest.Layers {
+			predicateType := l.Annotations["in-toto.io/predicate-type"]
+			for _, t := range sbomPredicateTypes {
+				if strings.HasPrefix(predicateType, t) {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+func readJSONBlob(ctx context.Context, cs content.Provider, desc ocispec.Descriptor, v any) error {
+	b, err := content.ReadBlob(ctx, cs, desc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// runScanner runs the vulnerability scanner against the image ref in the registry, and returns the JSON report.
+func runScanner(scanner, ref string) ([]byte, error) {
+	var args []string
+	switch scanner {
+	case "trivy":
+		args = []string{"image", "--quiet", "--format", "json", ref}
+	case "grype":
+		args = []string{"--quiet", "--output", "json", "registry:" + ref}
+	default:
+		return nil, fmt.Errorf("unknown scanner %q (must be trivy or grype)", scanner)
+	}
+	scannerExecutable, err := exec.LookPath(scanner)
+	if err != nil {
+		return nil, fmt.Errorf("%s executable not found in path $PATH: %w", scanner, err)
+	}
+	cmd := exec.Command(scannerExecutable, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	log.L.Debugf("running %s %v", scannerExecutable, cmd.Args)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s: %w (stderr: %q)", scanner, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// scanReport is the subset of the JSON reports of trivy and grype.
+type scanReport struct {
+	// trivy
+	Metadata struct {
+		RepoDigests []string `json:"RepoDigests"`
+	} `json:"Metadata"`
+	Results []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID string `json:"VulnerabilityID"`
+			Severity        string `json:"Severity"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+	// grype
+	Matches []struct {
+		Vulnerability struct {
+			ID       string `json:"id"`
+			Severity string `json:"severity"`
+		} `json:"vulnerability"`
+	} `json:"matches"`
+}
+
+// checkScanReport returns an error when the report contains vulnerabilities of the severity threshold or higher,
+// or when the report is not about the image of the digest dgst.
+func checkScanReport(b []byte, dgst string, threshold int) error {
+	var report scanReport
+	if err := json.Unmarshal(b, &report); err != nil {
+		return fmt.Errorf("failed to parse the scan report (must be a JSON report of trivy or grype): %w", err)
+	}
+	if digests := report.Metadata.RepoDigests; len(digests) > 0 {
+		found := false
+		for _, d := range digests {
+			if strings.HasSuffix(d, "@"+dgst) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("the scan report is not about %s (the report is about %v)", dgst, digests)
+		}
+	}
+
+	blocking := make(map[string][]string)
+	add := func(id, severity string) {
+		severity = strings.ToLower(severity)
+		if severityIndex(severity) >= threshold {
+			blocking[severity] = append(blocking[severity], id)
+		}
+	}
+	for _, r := range report.Results {
+		for _, v := range r.Vulnerabilities {
+			add(v.VulnerabilityID, v.Severity)
+		}
+	}
+	for _, m := range report.Matches {
+		add(m.Vulnerability.ID, m.Vulnerability.Severity)
+	}
+	if len(blocking) == 0 {
+		return nil
+	}
+	var found []string
+	for i := len(severities) - 1; i >= threshold; i-- {
+		ids := blocking[severities[i]]
+		if len(ids) == 0 {
+			continue
+		}
+		sort.Strings(ids)
+		found = append(found, fmt.Sprintf("%d %s (%s)", len(ids), severities[i], strings.Join(ids, ", ")))
+	}
+	return fmt.Errorf("vulnerabilities of %s severity or higher found: %s", severities[threshold], strings.Join(found, "; "))
+}
+
+// severityIndex returns the index of the severity s in severities, or -1 when s is unknown.
+func severityIndex(s string) int {
+	for i, severity := range severities {
+		if strings.EqualFold(s, severity) {
+			return i
+		}
+	}
+	return -1
+}
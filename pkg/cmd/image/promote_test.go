@@ -0,0 +1,110 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/containerd/v2/plugins/content/local"
+
+	nerdconverter "github.com/containerd/nerdctl/v2/pkg/imgutil/converter"
+	"github.com/containerd/nerdctl/v2/pkg/platformutil"
+)
+
+func TestCheckScanReport(t *testing.T) {
+	dgst := digest.FromString("image").String()
+	trivy := `{
+  "Metadata": {"RepoDigests": ["example.com/app@` + dgst + `"]},
+  "Results": [
+    {"Vulnerabilities": [
+      {"VulnerabilityID": "CVE-2024-0003", "Severity": "MEDIUM"},
+      {"VulnerabilityID": "CVE-2024-0002", "Severity": "HIGH"},
+      {"VulnerabilityID": "CVE-2024-0001", "Severity": "HIGH"}
+    ]},
+    {"Vulnerabilities": [{"VulnerabilityID": "CVE-2024-0004", "Severity": "LOW"}]}
+  ]
+}`
+	grype := `{"matches": [
+  {"vulnerability": {"id": "CVE-2024-0005", "severity": "Critical"}},
+  {"vulnerability": {"id": "CVE-2024-0006", "severity": "Negligible"}}
+]}`
+
+	assert.NilError(t, checkScanReport([]byte(trivy), dgst, severityIndex("critical")))
+	assert.Error(t, checkScanReport([]byte(trivy), dgst, severityIndex("high")),
+		"vulnerabilities of high severity or higher found: 2 high (CVE-2024-0001, CVE-2024-0002)")
+	assert.Error(t, checkScanReport([]byte(trivy), dgst, severityIndex("medium")),
+		"vulnerabilities of medium severity or higher found: 2 high (CVE-2024-0001, CVE-2024-0002); 1 medium (CVE-2024-0003)")
+	assert.ErrorContains(t, checkScanReport([]byte(trivy), digest.FromString("other").String(), severityIndex("critical")),
+		"the scan report is not about")
+
+	assert.ErrorContains(t, checkScanReport([]byte(grype), dgst, severityIndex("critical")), "1 critical (CVE-2024-0005)")
+	assert.NilError(t, checkScanReport([]byte(`{"matches": []}`), dgst, severityIndex("low")))
+
+	assert.ErrorContains(t, checkScanReport([]byte("not json"), dgst, severityIndex("low")), "failed to parse the scan report")
+	assert.Equal(t, severityIndex("moderate"), -1)
+}
+
+func TestHasSBOM(t *testing.T) {
+	ctx := context.Background()
+	cs, err := local.NewStore(t.TempDir())
+	assert.NilError(t, err)
+
+	manifest := writeTestJSON(t, cs, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+	}, nil)
+	ok, err := hasSBOM(ctx, cs, manifest)
+	assert.NilError(t, err)
+	assert.Assert(t, !ok)
+
+	attestation := func(predicateType string) ocispec.Descriptor {
+		desc := writeTestJSON(t, cs, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+			MediaType: ocispec.MediaTypeImageManifest,
+			Layers: []ocispec.Descriptor{{
+				MediaType:   "application/vnd.in-toto+json",
+				Digest:      digest.FromString(predicateType),
+				Annotations: map[string]string{"in-toto.io/predicate-type": predicateType},
+			}},
+		}, nil)
+		desc.Platform = &platformutil.AttestationPlatform
+		desc.Annotations = map[string]string{
+			nerdconverter.AttestationReferenceTypeAnnotation:   nerdconverter.AttestationManifestType,
+			nerdconverter.AttestationReferenceDigestAnnotation: manifest.Digest.String(),
+		}
+		return desc
+	}
+
+	provenance := writeTestJSON(t, cs, ocispec.MediaTypeImageIndex, ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{manifest, attestation("https://slsa.dev/provenance/v0.2")},
+	}, nil)
+	ok, err = hasSBOM(ctx, cs, provenance)
+	assert.NilError(t, err)
+	assert.Assert(t, !ok)
+
+	sbom := writeTestJSON(t, cs, ocispec.MediaTypeImageIndex, ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{manifest, attestation("https://spdx.dev/Document")},
+	}, nil)
+	ok, err = hasSBOM(ctx, cs, sbom)
+	assert.NilError(t, err)
+	assert.Assert(t, ok)
+}
@@ -19,8 +19,10 @@ package image
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/opencontainers/go-digest"
+	"golang.org/x/sync/errgroup"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/images"
@@ -70,17 +72,28 @@ func Prune(ctx context.Context, client *containerd.Client, options types.ImagePr
 
 	delOpts := []images.DeleteOpt{images.SynchronousDelete()}
 	removedImages := make(map[string][]digest.Digest)
+	var (
+		mu sync.Mutex
+		eg errgroup.Group
+	)
+	eg.SetLimit(max(options.Parallel, 1))
 	for _, image := range imagesToBeRemoved {
-		digests, err := image.RootFS(ctx, contentStore, platforms.DefaultStrict())
-		if err != nil {
-			log.G(ctx).WithError(err).Warnf("failed to enumerate rootfs")
-		}
-		if err := imageStore.Delete(ctx, image.Name, delOpts...); err != nil {
-			log.G(ctx).WithError(err).Warnf("failed to delete image %s", image.Name)
-			continue
-		}
-		removedImages[image.Name] = digests
+		eg.Go(func() error {
+			digests, err := image.RootFS(ctx, contentStore, platforms.DefaultStrict())
+			if err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to enumerate rootfs")
+			}
+			if err := imageStore.Delete(ctx, image.Name, delOpts...); err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to delete image %s", image.Name)
+				return nil
+			}
+			mu.Lock()
+			removedImages[image.Name] = digests
+			mu.Unlock()
+			return nil
+		})
 	}
+	_ = eg.Wait()
 
 	if len(removedImages) > 0 {
 		fmt.Fprintln(options.Stdout, "Deleted Images:")
@@ -19,6 +19,7 @@ package image
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -33,8 +34,16 @@ import (
 
 // Pull pulls an image specified by `rawRef`.
 func Pull(ctx context.Context, client *containerd.Client, rawRef string, options types.ImagePullOptions) error {
+	if options.MaxWait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.MaxWait)
+		defer cancel()
+	}
 	_, err := EnsureImage(ctx, client, rawRef, options)
 	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("failed to pull %q within --max-wait=%s: %w", rawRef, options.MaxWait, err)
+		}
 		return err
 	}
 
@@ -75,7 +84,7 @@ func EnsureImage(ctx context.Context, client *containerd.Client, rawRef string,
 		return ensured, nil
 	}
 
-	ref, err := signutil.Verify(ctx, rawRef, options.GOptions.HostsDir, options.GOptions.Experimental, options.VerifyOptions)
+	ref, err := signutil.Verify(ctx, rawRef, options.GOptions.HostsDir, options.GOptions.Experimental, options.GOptions.Offline, options.VerifyOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -37,6 +37,7 @@ import (
 	dockerconfig "github.com/containerd/containerd/v2/core/remotes/docker/config"
 	"github.com/containerd/containerd/v2/pkg/reference"
 	"github.com/containerd/log"
+	"github.com/containerd/platforms"
 	"github.com/containerd/stargz-snapshotter/estargz"
 	"github.com/containerd/stargz-snapshotter/estargz/zstdchunked"
 	estargzconvert "github.com/containerd/stargz-snapshotter/nativeconverter/estargz"
@@ -125,7 +126,8 @@ func Push(ctx context.Context, client *containerd.Client, rawRef string, options
 		if err != nil {
 			return err
 		}
-		platImg, err := nerdconverter.Convert(ctx, client, pushRef, ref, converter.WithPlatform(platMC))
+		// The attestation manifests (provenance, SBOM) of the remaining platforms are kept.
+		platImg, err := nerdconverter.Convert(ctx, client, pushRef, ref, converter.WithIndexConvertFunc(nerdconverter.IndexConvertFuncWithAttestations(platMC)))
 		if err != nil {
 			if len(options.Platforms) == 0 {
 				return fmt.Errorf("failed to create a tmp single-platform image %q: %w", pushRef, err)
@@ -154,15 +156,19 @@ func Push(ctx context.Context, client *containerd.Client, rawRef string, options
 	pushTracker := docker.NewInMemoryTracker()
 
 	pushFunc := func(r remotes.Resolver) error {
-		return push.Push(ctx, client, r, pushTracker, options.Stdout, pushRef, ref, platMC, options.AllowNondistributableArtifacts, options.Quiet)
+		if err := push.Push(ctx, client, r, pushTracker, options.Stdout, pushRef, ref, platformutil.WithAttestations(platMC), options.AllowNondistributableArtifacts, options.Quiet); err != nil {
+			return err
+		}
+		return pushSignatures(ctx, client, r, pushTracker, pushRef, ref, options)
 	}
 
 	var dOpts []dockerconfigresolver.Opt
-	if options.GOptions.InsecureRegistry {
+	if options.GOptions.IsInsecureRegistry(refDomain) {
 		log.G(ctx).Warnf("skipping verifying HTTPS certs for %q", refDomain)
 		dOpts = append(dOpts, dockerconfigresolver.WithSkipVerifyCerts(true))
 	}
-	dOpts = append(dOpts, dockerconfigresolver.WithHostsDirs(options.GOptions.HostsDir))
+	dOpts = append(dOpts, dockerconfigresolver.WithHostsDirs(options.GOptions.HostsDir), dockerconfigresolver.WithOffline(options.GOptions.Offline),
+		dockerconfigresolver.WithRegistryConfigs(options.GOptions.Registries), dockerconfigresolver.WithSystemKeychain(options.GOptions.Credentials.Keychain))
 
 	ho, err := dockerconfigresolver.NewHostOptions(ctx, refDomain, dOpts...)
 	if err != nil {
@@ -180,7 +186,7 @@ func Push(ctx context.Context, client *containerd.Client, rawRef string, options
 		if !errors.Is(err, http.ErrSchemeMismatch) && !errutil.IsErrConnectionRefused(err) {
 			return err
 		}
-		if options.GOptions.InsecureRegistry {
+		if options.GOptions.IsInsecureRegistry(refDomain) {
 			log.G(ctx).WithError(err).Warnf("server %q does not seem to support HTTPS, falling back to plain HTTP", refDomain)
 			dOpts = append(dOpts, dockerconfigresolver.WithPlainHTTP(true))
 			resolver, err = dockerconfigresolver.New(ctx, refDomain, dOpts...)
@@ -205,6 +211,7 @@ func Push(ctx context.Context, client *containerd.Client, rawRef string, options
 	signRef := fmt.Sprintf("%s@%s", refSpec.String(), img.Target.Digest.String())
 	if err = signutil.Sign(signRef,
 		options.GOptions.Experimental,
+		options.GOptions.Offline,
 		options.SignOptions); err != nil {
 		return err
 	}
@@ -222,6 +229,41 @@ func Push(ctx context.Context, client *containerd.Client, rawRef string, options
 	return nil
 }
 
+// pushSignatures pushes the signature images stored by `nerdctl image sign` for the image pushRef
+// to the repository of ref.
+func pushSignatures(ctx context.Context, client *containerd.Client, resolver remotes.Resolver, pushTracker docker.StatusTracker,
+	pushRef, ref string, options types.ImagePushOptions) error {
+	img, err := client.ImageService().Get(ctx, pushRef)
+	if err != nil {
+		return err
+	}
+	sigs, err := signutil.LocalSignatures(ctx, client, img.Target.Digest)
+	if err != nil {
+		return err
+	}
+	if len(sigs) == 0 && pushRef != ref {
+		// The signatures of the original image do not apply to the converted one.
+		if orig, err := client.ImageService().Get(ctx, ref); err == nil && orig.Target.Digest != img.Target.Digest {
+			if origSigs, err := signutil.LocalSignatures(ctx, client, orig.Target.Digest); err == nil && len(origSigs) > 0 {
+				log.G(ctx).Warnf("not pushing the local signatures of %s, as the pushed manifest %s differs from the signed one",
+					orig.Target.Digest, img.Target.Digest)
+			}
+		}
+	}
+	refSpec, err := reference.Parse(ref)
+	if err != nil {
+		return err
+	}
+	for _, sig := range sigs {
+		remoteRef := signutil.RemoteSignatureRef(sig, refSpec.Locator)
+		log.G(ctx).Infof("pushing signature %s", remoteRef)
+		if err := push.Push(ctx, client, resolver, pushTracker, options.Stdout, sig.Name, remoteRef, platforms.All, false, options.Quiet); err != nil {
+			return fmt.Errorf("failed to push the signature %s: %w", sig.Name, err)
+		}
+	}
+	return nil
+}
+
 func eStargzConvertFunc() converter.ConvertFunc {
 	convertToESGZ := estargzconvert.LayerConvertFunc()
 	return func(ctx context.Context, cs content.Store, desc ocispec.Descriptor) (*ocispec.Descriptor, error) {
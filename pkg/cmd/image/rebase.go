@@ -0,0 +1,251 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/identity"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/leases"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/platforms"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/imagewalker"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
+)
+
+// rebaseImage is the platform-specific manifest and config of an image taking part in a rebase.
+type rebaseImage struct {
+	name         string
+	manifestDesc ocispec.Descriptor
+	manifest     ocispec.Manifest
+	config       ocispec.Image
+	// rawConfig is used for preserving the fields unknown to ocispec.Image
+	rawConfig map[string]json.RawMessage
+}
+
+// Rebase creates targetRawRef from appRawRef, by replacing the layers of oldBaseRawRef
+// at the bottom of appRawRef with the layers of newBaseRawRef.
+//
+// The application layers are reused as they are, so appRawRef must have been built on top of oldBaseRawRef,
+// i.e., the diff IDs of oldBaseRawRef must be a prefix of the diff IDs of appRawRef.
+func Rebase(ctx context.Context, client *containerd.Client, appRawRef, oldBaseRawRef, newBaseRawRef, targetRawRef string, options types.ImageRebaseOptions) error {
+	platform := platforms.DefaultSpec()
+	if options.Platform != "" {
+		p, err := platforms.Parse(options.Platform)
+		if err != nil {
+			return err
+		}
+		platform = p
+	}
+	platMC := platforms.Only(platform)
+
+	parsedReference, err := referenceutil.Parse(targetRawRef)
+	if err != nil {
+		return err
+	}
+	targetRef := parsedReference.String()
+
+	app, err := readRebaseImage(ctx, client, appRawRef, platMC)
+	if err != nil {
+		return err
+	}
+	oldBase, err := readRebaseImage(ctx, client, oldBaseRawRef, platMC)
+	if err != nil {
+		return err
+	}
+	newBase, err := readRebaseImage(ctx, client, newBaseRawRef, platMC)
+	if err != nil {
+		return err
+	}
+	layers, diffIDs, history, err := rebaseLayers(app, oldBase, newBase)
+	if err != nil {
+		return err
+	}
+
+	// Don't gc me and clean the dirty data after 1 hour!
+	ctx, done, err := client.WithLease(ctx, leases.WithRandomID(), leases.WithExpiration(1*time.Hour))
+	if err != nil {
+		return fmt.Errorf("failed to create lease for rebase: %w", err)
+	}
+	defer done(ctx)
+
+	cs := client.ContentStore()
+	manifestDesc, err := writeRebasedImage(ctx, cs, options.GOptions.Snapshotter, app, newBase, layers, diffIDs, history)
+	if err != nil {
+		return err
+	}
+
+	img := images.Image{
+		Name:      targetRef,
+		Target:    *manifestDesc,
+		CreatedAt: time.Now(),
+	}
+	if _, err := client.ImageService().Update(ctx, img); err != nil {
+		if !errdefs.IsNotFound(err) {
+			return err
+		}
+		if _, err := client.ImageService().Create(ctx, img); err != nil {
+			return fmt.Errorf("failed to create new image %s: %w", targetRef, err)
+		}
+	}
+	cimg := containerd.NewImageWithPlatform(client, img, platMC)
+	if err := cimg.Unpack(ctx, options.GOptions.Snapshotter); err != nil {
+		return fmt.Errorf("failed to unpack %s: %w", targetRef, err)
+	}
+	_, err = fmt.Fprintln(options.Stdout, manifestDesc.Digest.String())
+	return err
+}
+
+// readRebaseImage reads the manifest and the config of rawRef for the platform matched by platMC.
+func readRebaseImage(ctx context.Context, client *containerd.Client, rawRef string, platMC platforms.MatchComparer) (*rebaseImage, error) {
+	var name string
+	walker := &imagewalker.ImageWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found imagewalker.Found) error {
+			if name == "" {
+				name = found.Image.Name
+			}
+			return nil
+		},
+	}
+	n, err := walker.Walk(ctx, rawRef)
+	if err != nil {
+		return nil, err
+	}
+	if n < 1 {
+		return nil, fmt.Errorf("%s: not found", rawRef)
+	}
+	if n > 1 {
+		return nil, fmt.Errorf("multiple IDs found with provided prefix: %s", rawRef)
+	}
+	img, err := client.ImageService().Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	cimg := containerd.NewImageWithPlatform(client, img, platMC)
+	manifest, manifestDesc, err := imgutil.ReadManifest(ctx, cimg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the manifest of %s: %w", rawRef, err)
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("%s: no manifest found for the platform", rawRef)
+	}
+	configDesc, err := cimg.Config(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the config of %s: %w", rawRef, err)
+	}
+	b, err := content.ReadBlob(ctx, cimg.ContentStore(), configDesc)
+	if err != nil {
+		return nil, err
+	}
+	ri := &rebaseImage{name: name, manifestDesc: *manifestDesc, manifest: *manifest}
+	if err := json.Unmarshal(b, &ri.config); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &ri.rawConfig); err != nil {
+		return nil, err
+	}
+	if !platMC.Match(ri.config.Platform) {
+		return nil, fmt.Errorf("%s: platform %s does not match", rawRef, platforms.Format(ri.config.Platform))
+	}
+	if len(ri.manifest.Layers) != len(ri.config.RootFS.DiffIDs) {
+		return nil, fmt.Errorf("%s: the number of layers (%d) does not match the number of diff IDs (%d)",
+			rawRef, len(ri.manifest.Layers), len(ri.config.RootFS.DiffIDs))
+	}
+	return ri, nil
+}
+
+// rebaseLayers returns the layers, the diff IDs, and the history of app rebased from oldBase onto newBase.
+func rebaseLayers(app, oldBase, newBase *rebaseImage) ([]ocispec.Descriptor, []digest.Digest, []ocispec.History, error) {
+	appDiffIDs, oldDiffIDs := app.config.RootFS.DiffIDs, oldBase.config.RootFS.DiffIDs
+	if len(oldDiffIDs) > len(appDiffIDs) {
+		return nil, nil, nil, fmt.Errorf("%s has fewer layers than %s, so it cannot be based on it", app.name, oldBase.name)
+	}
+	for i, d := range oldDiffIDs {
+		if appDiffIDs[i] != d {
+			return nil, nil, nil, fmt.Errorf("%s is not based on %s: layer %d has diff ID %s, expected %s", app.name, oldBase.name, i, appDiffIDs[i], d)
+		}
+	}
+	n := len(oldDiffIDs)
+
+	layers := append(append([]ocispec.Descriptor{}, newBase.manifest.Layers...), app.manifest.Layers[n:]...)
+	diffIDs := append(append([]digest.Digest{}, newBase.config.RootFS.DiffIDs...), appDiffIDs[n:]...)
+
+	// The history of the old base is only dropped when it is consistent with the history of app.
+	// Otherwise the history of app is kept as it is, as it cannot be split reliably.
+	history := app.config.History
+	if len(oldBase.config.History) <= len(history) {
+		history = append(append([]ocispec.History{}, newBase.config.History...), history[len(oldBase.config.History):]...)
+	}
+	return layers, diffIDs, history, nil
+}
+
+// writeRebasedImage writes the config and the manifest of the rebased image, and returns the manifest descriptor.
+// The fields of the config unknown to the OCI spec (e.g., Healthcheck) are preserved.
+func writeRebasedImage(ctx context.Context, cs content.Store, snapshotter string, app, newBase *rebaseImage,
+	layers []ocispec.Descriptor, diffIDs []digest.Digest, history []ocispec.History) (*ocispec.Descriptor, error) {
+	cfg := make(map[string]json.RawMessage, len(app.rawConfig))
+	for k, v := range app.rawConfig {
+		cfg[k] = v
+	}
+	var err error
+	if cfg["rootfs"], err = json.Marshal(ocispec.RootFS{Type: "layers", DiffIDs: diffIDs}); err != nil {
+		return nil, err
+	}
+	if cfg["history"], err = json.Marshal(history); err != nil {
+		return nil, err
+	}
+	configDesc, err := writeJSONWithLabels(ctx, cs, app.manifest.Config, cfg, map[string]string{
+		fmt.Sprintf("containerd.io/gc.ref.snapshot.%s", snapshotter): identity.ChainID(diffIDs).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := app.manifest
+	manifest.Config = *configDesc
+	manifest.Layers = layers
+	manifest.Annotations = make(map[string]string, len(app.manifest.Annotations)+2)
+	for k, v := range app.manifest.Annotations {
+		manifest.Annotations[k] = v
+	}
+	manifest.Annotations[ocispec.AnnotationBaseImageName] = newBase.name
+	manifest.Annotations[ocispec.AnnotationBaseImageDigest] = newBase.manifestDesc.Digest.String()
+	labels := map[string]string{
+		"containerd.io/gc.ref.content.config": configDesc.Digest.String(),
+	}
+	for i, l := range layers {
+		labels[fmt.Sprintf("containerd.io/gc.ref.content.l.%d", i)] = l.Digest.String()
+	}
+	desc := app.manifestDesc
+	desc.Platform = nil
+	desc.Annotations = nil
+	return writeJSONWithLabels(ctx, cs, desc, &manifest, labels)
+}
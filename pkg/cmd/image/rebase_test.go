@@ -0,0 +1,71 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+func testRebaseImage(name string, layers ...string) *rebaseImage {
+	img := &rebaseImage{name: name}
+	for _, l := range layers {
+		img.manifest.Layers = append(img.manifest.Layers, ocispec.Descriptor{Digest: digest.FromString("blob-" + l)})
+		img.config.RootFS.DiffIDs = append(img.config.RootFS.DiffIDs, digest.FromString(l))
+		img.config.History = append(img.config.History, ocispec.History{CreatedBy: l})
+	}
+	return img
+}
+
+func TestRebaseLayers(t *testing.T) {
+	app := testRebaseImage("app", "base-1", "base-2", "app-1", "app-2")
+	oldBase := testRebaseImage("old", "base-1", "base-2")
+	newBase := testRebaseImage("new", "patched-1")
+
+	layers, diffIDs, history, err := rebaseLayers(app, oldBase, newBase)
+	assert.NilError(t, err)
+	expected := testRebaseImage("", "patched-1", "app-1", "app-2")
+	assert.DeepEqual(t, layers, expected.manifest.Layers)
+	assert.DeepEqual(t, diffIDs, expected.config.RootFS.DiffIDs)
+	assert.DeepEqual(t, history, expected.config.History)
+}
+
+func TestRebaseLayersKeepsInconsistentHistory(t *testing.T) {
+	app := testRebaseImage("app", "base-1", "app-1")
+	app.config.History = nil
+	oldBase := testRebaseImage("old", "base-1")
+	newBase := testRebaseImage("new", "patched-1")
+
+	_, diffIDs, history, err := rebaseLayers(app, oldBase, newBase)
+	assert.NilError(t, err)
+	assert.Equal(t, len(diffIDs), 2)
+	assert.Assert(t, history == nil)
+}
+
+func TestRebaseLayersNotBased(t *testing.T) {
+	app := testRebaseImage("app", "base-1", "app-1")
+	newBase := testRebaseImage("new", "patched-1")
+
+	_, _, _, err := rebaseLayers(app, testRebaseImage("old", "other-1"), newBase)
+	assert.ErrorContains(t, err, "app is not based on old: layer 0 has diff ID")
+
+	_, _, _, err = rebaseLayers(app, testRebaseImage("old", "base-1", "app-1", "more-1"), newBase)
+	assert.ErrorContains(t, err, "app has fewer layers than old")
+}
@@ -0,0 +1,325 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/identity"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/leases"
+	"github.com/containerd/containerd/v2/core/mount"
+	"github.com/containerd/containerd/v2/pkg/cio"
+	"github.com/containerd/containerd/v2/pkg/oci"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+	"github.com/containerd/platforms"
+	"github.com/containerd/stargz-snapshotter/analyzer/recorder"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/fanotifyutil"
+	"github.com/containerd/nerdctl/v2/pkg/idgen"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/imagewalker"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+// RecordAccess runs rawRef with args, and records the files accessed by the container.
+// The record is written to options.RecordOut, and used for converting the image to eStargz
+// when options.Convert is set. Otherwise, the record is printed to options.Stdout.
+func RecordAccess(ctx context.Context, client *containerd.Client, rawRef string, args []string, options types.ImageRecordAccessOptions) error {
+	if !options.GOptions.Experimental {
+		return errors.New("image record-access requires experimental mode to be enabled")
+	}
+	if rootlessutil.IsRootless() {
+		return errors.New("image record-access is not supported in rootless mode, as fanotify requires the initial user namespace")
+	}
+
+	var name string
+	walker := &imagewalker.ImageWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found imagewalker.Found) error {
+			if name == "" {
+				name = found.Image.Name
+			}
+			return nil
+		},
+	}
+	n, err := walker.Walk(ctx, rawRef)
+	if err != nil {
+		return err
+	}
+	if n < 1 {
+		return fmt.Errorf("%s: not found", rawRef)
+	}
+	if n > 1 {
+		return fmt.Errorf("multiple IDs found with provided prefix: %s", rawRef)
+	}
+	img, err := client.ImageService().Get(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	// The record is stored in the content store until it is read
+	ctx, done, err := client.WithLease(ctx, leases.WithRandomID(), leases.WithExpiration(1*time.Hour))
+	if err != nil {
+		return fmt.Errorf("failed to create lease for recording: %w", err)
+	}
+	defer done(ctx)
+
+	recordDgst, err := recordAccess(ctx, client, img, args, options)
+	if err != nil {
+		return err
+	}
+	record, err := content.ReadBlob(ctx, client.ContentStore(), ocispec.Descriptor{Digest: recordDgst})
+	if err != nil {
+		return fmt.Errorf("failed to read the record: %w", err)
+	}
+
+	recordOut := options.RecordOut
+	if recordOut != "" {
+		if err := os.WriteFile(recordOut, record, 0o644); err != nil {
+			return err
+		}
+	}
+	if options.Convert == "" {
+		if recordOut == "" {
+			_, err = options.Stdout.Write(record)
+		}
+		return err
+	}
+	if recordOut == "" {
+		f, err := os.CreateTemp("", "nerdctl-record-")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(f.Name())
+		if _, err := f.Write(record); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		recordOut = f.Name()
+	}
+	return Convert(ctx, client, name, options.Convert, types.ImageConvertOptions{
+		Stdout:                  options.Stdout,
+		GOptions:                options.GOptions,
+		Oci:                     true,
+		Estargz:                 true,
+		EstargzRecordIn:         recordOut,
+		EstargzCompressionLevel: options.EstargzCompressionLevel,
+		EstargzChunkSize:        options.EstargzChunkSize,
+	})
+}
+
+// recordAccess runs the container and returns the digest of the record in the content store.
+//
+// The rootfs is mounted by nerdctl rather than containerd, before the fanotify monitor creates its
+// mount namespace, so that the rootfs is visible in the namespace that the container joins.
+func recordAccess(ctx context.Context, client *containerd.Client, img images.Image, args []string, options types.ImageRecordAccessOptions) (digest.Digest, error) {
+	platMC := platforms.Default()
+	snapshotter := options.GOptions.Snapshotter
+	cimg := containerd.NewImageWithPlatform(client, img, platMC)
+	unpacked, err := cimg.IsUnpacked(ctx, snapshotter)
+	if err != nil {
+		return "", err
+	}
+	if !unpacked {
+		if err := cimg.Unpack(ctx, snapshotter); err != nil {
+			return "", err
+		}
+	}
+
+	rootfs, err := os.MkdirTemp("", "nerdctl-record-access-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(rootfs)
+	unmount, err := mountImage(ctx, client, cimg, snapshotter, rootfs)
+	if err != nil {
+		return "", err
+	}
+	defer unmount()
+
+	fanotifier, err := fanotifyutil.Spawn()
+	if err != nil {
+		return "", err
+	}
+	defer fanotifier.Close()
+
+	var s specs.Spec
+	container, err := client.NewContainer(ctx, idgen.GenerateID(),
+		containerd.WithImage(cimg),
+		containerd.WithSnapshotter(snapshotter),
+		containerd.WithSpec(&s,
+			oci.WithDefaultSpec(),
+			oci.WithDefaultUnixDevices,
+			oci.WithRootFSPath(rootfs),
+			oci.WithImageConfigArgs(cimg, args),
+			oci.WithLinuxNamespace(specs.LinuxNamespace{
+				Type: specs.MountNamespace,
+				Path: fanotifier.MountNamespacePath(),
+			}),
+		),
+	)
+	if err != nil {
+		return "", err
+	}
+	defer container.Delete(ctx)
+
+	// The output of the container goes to stderr, as stdout may be used for printing the record
+	waitLineC := make(chan struct{}, 1)
+	stdout := io.Writer(options.Stderr)
+	if options.WaitLine != "" {
+		pr, pw := io.Pipe()
+		defer pw.Close()
+		go func() {
+			scanner := bufio.NewScanner(pr)
+			for scanner.Scan() {
+				if strings.Contains(scanner.Text(), options.WaitLine) {
+					select {
+					case waitLineC <- struct{}{}:
+					default:
+					}
+				}
+			}
+			io.Copy(io.Discard, pr)
+		}()
+		stdout = io.MultiWriter(options.Stderr, pw)
+	}
+	task, err := container.NewTask(ctx, cio.NewCreator(cio.WithStreams(nil, stdout, options.Stderr)))
+	if err != nil {
+		return "", err
+	}
+	defer task.Delete(ctx, containerd.WithProcessKill)
+
+	rc, err := recorder.NewImageRecorder(ctx, client.ContentStore(), img, platMC)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	if err := fanotifier.Start(); err != nil {
+		return "", fmt.Errorf("failed to start the fanotify monitor: %w", err)
+	}
+	recordDone := make(chan struct{})
+	go func() {
+		defer close(recordDone)
+		for {
+			path, err := fanotifier.GetPath()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					log.G(ctx).WithError(err).Debug("failed to get the accessed path")
+					continue
+				}
+				return
+			}
+			if err := rc.Record(path); err != nil {
+				log.G(ctx).WithError(err).Debugf("failed to record %q", path)
+			}
+		}
+	}()
+
+	statusC, err := task.Wait(ctx)
+	if err != nil {
+		return "", err
+	}
+	if err := task.Start(ctx); err != nil {
+		return "", err
+	}
+	period := options.Period
+	if period <= 0 {
+		period = 10 * time.Second
+	}
+	var status containerd.ExitStatus
+	select {
+	case status = <-statusC:
+	case <-waitLineC:
+		log.G(ctx).Infof("detected %q, killing the container", options.WaitLine)
+		status, err = killRecordingTask(ctx, task, statusC)
+	case <-time.After(period):
+		log.G(ctx).Infof("killing the container after %s", period)
+		status, err = killRecordingTask(ctx, task, statusC)
+	}
+	if err != nil {
+		return "", err
+	}
+	if code, _, err := status.Result(); err == nil {
+		log.G(ctx).Infof("container exited with code %d", code)
+	}
+
+	// Ensure no more records come in
+	if err := fanotifier.Close(); err != nil {
+		log.G(ctx).WithError(err).Warn("failed to close the fanotify monitor")
+	}
+	<-recordDone
+	return rc.Commit(ctx)
+}
+
+func killRecordingTask(ctx context.Context, task containerd.Task, statusC <-chan containerd.ExitStatus) (containerd.ExitStatus, error) {
+	if err := task.Kill(ctx, syscall.SIGKILL, containerd.WithKillAll); err != nil && !errdefs.IsNotFound(err) {
+		return containerd.ExitStatus{}, fmt.Errorf("failed to kill the container: %w", err)
+	}
+	select {
+	case status := <-statusC:
+		return status, nil
+	case <-time.After(5 * time.Second):
+		return containerd.ExitStatus{}, errors.New("timed out waiting for the container to exit")
+	}
+}
+
+// mountImage mounts the rootfs of img on a new writable snapshot at mountpoint.
+func mountImage(ctx context.Context, client *containerd.Client, img containerd.Image, snapshotter, mountpoint string) (func(), error) {
+	diffIDs, err := img.RootFS(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sn := client.SnapshotService(snapshotter)
+	key := idgen.GenerateID()
+	mounts, err := sn.Prepare(ctx, key, identity.ChainID(diffIDs).String())
+	if err != nil {
+		return nil, err
+	}
+	if err := mount.All(mounts, mountpoint); err != nil {
+		if err := sn.Remove(ctx, key); err != nil && !errdefs.IsNotFound(err) {
+			log.G(ctx).WithError(err).Warn("failed to remove the snapshot")
+		}
+		return nil, fmt.Errorf("failed to mount the rootfs at %q: %w", mountpoint, err)
+	}
+	return func() {
+		if err := mount.UnmountAll(mountpoint, 0); err != nil {
+			log.G(ctx).WithError(err).Warn("failed to unmount the rootfs")
+		}
+		if err := sn.Remove(ctx, key); err != nil && !errdefs.IsNotFound(err) {
+			log.G(ctx).WithError(err).Warn("failed to remove the snapshot")
+		}
+	}, nil
+}
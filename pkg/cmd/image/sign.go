@@ -0,0 +1,60 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"context"
+	"fmt"
+
+	containerd "github.com/containerd/containerd/v2/client"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/imagewalker"
+	"github.com/containerd/nerdctl/v2/pkg/signutil"
+)
+
+// Sign signs the image specified by `rawRef`, and stores the signature locally to be pushed together with the image.
+func Sign(ctx context.Context, client *containerd.Client, rawRef string, options types.ImageSignCommandOptions) error {
+	var srcName string
+	walker := &imagewalker.ImageWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found imagewalker.Found) error {
+			if srcName == "" {
+				srcName = found.Image.Name
+			}
+			return nil
+		},
+	}
+	matchCount, err := walker.Walk(ctx, rawRef)
+	if err != nil {
+		return err
+	}
+	if matchCount < 1 {
+		return fmt.Errorf("%s: not found", rawRef)
+	}
+
+	img, err := client.ImageService().Get(ctx, srcName)
+	if err != nil {
+		return err
+	}
+	sigImg, err := signutil.SignLocal(ctx, client, img, options.GOptions.Experimental, options.SignOptions)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(options.Stdout, sigImg.Name)
+	return err
+}
@@ -0,0 +1,48 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"context"
+	"fmt"
+
+	containerd "github.com/containerd/containerd/v2/client"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
+	"github.com/containerd/nerdctl/v2/pkg/snapshotterutil"
+)
+
+// SociCreate creates the SOCI index of a local image, and optionally pushes it to the registry of the image.
+// The index is pushed as a referrer of the image, so it is discovered by the soci snapshotter on lazy pulling.
+func SociCreate(ctx context.Context, client *containerd.Client, rawRef string, options types.ImageSociCreateOptions) error {
+	parsedReference, err := referenceutil.Parse(rawRef)
+	if err != nil {
+		return err
+	}
+	ref := parsedReference.String()
+	if _, err := client.ImageService().Get(ctx, ref); err != nil {
+		return fmt.Errorf("failed to find image %q (the image must be pulled or built first): %w", rawRef, err)
+	}
+	if err := snapshotterutil.CreateSoci(ref, options.GOptions, options.AllPlatforms, options.Platforms, options.SociOptions); err != nil {
+		return err
+	}
+	if options.Push {
+		return snapshotterutil.PushSoci(ref, options.GOptions, options.AllPlatforms, options.Platforms)
+	}
+	return nil
+}
@@ -24,6 +24,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"runtime"
+	"slices"
 	"strings"
 	"time"
 
@@ -38,6 +39,8 @@ import (
 	"github.com/containerd/containerd/v2/core/leases"
 	"github.com/containerd/containerd/v2/core/mount"
 	"github.com/containerd/containerd/v2/core/snapshots"
+	"github.com/containerd/containerd/v2/pkg/archive/compression"
+	"github.com/containerd/containerd/v2/pkg/labels"
 	"github.com/containerd/containerd/v2/pkg/namespaces"
 	"github.com/containerd/containerd/v2/pkg/rootfs"
 	"github.com/containerd/errdefs"
@@ -46,6 +49,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/imagewalker"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil"
+	"github.com/containerd/nerdctl/v2/pkg/leaseutil"
 )
 
 const (
@@ -132,7 +136,7 @@ func (sr *squashRuntime) createDiff(ctx context.Context, snapshotName string) (o
 	if err != nil {
 		return ocispec.Descriptor{}, "", err
 	}
-	diffIDStr, ok := info.Labels["containerd.io/uncompressed"]
+	diffIDStr, ok := info.Labels[labels.LabelUncompressed]
 	if !ok {
 		return ocispec.Descriptor{}, "", fmt.Errorf("invalid differ response with no diffID")
 	}
@@ -140,6 +144,13 @@ func (sr *squashRuntime) createDiff(ctx context.Context, snapshotName string) (o
 	if err != nil {
 		return ocispec.Descriptor{}, "", err
 	}
+	if existing, ok, err := sr.findExistingLayer(ctx, diffID, newDesc.Digest); err != nil {
+		return ocispec.Descriptor{}, "", err
+	} else if ok {
+		log.G(ctx).Infof("reusing the existing layer %s for the squashed diff %s", existing.Digest, diffID)
+		// the new blob is only held by the lease, and is removed by the gc
+		return existing, diffID, nil
+	}
 	return ocispec.Descriptor{
 		MediaType: images.MediaTypeDockerSchema2LayerGzip,
 		Digest:    newDesc.Digest,
@@ -147,6 +158,87 @@ func (sr *squashRuntime) createDiff(ctx context.Context, snapshotName string) (o
 	}, diffID, nil
 }
 
+// findExistingLayer finds a blob in the content store with the same uncompressed digest as the squashed diff,
+// other than the blob just written, so that repeated squashes don't store the same layer again
+// with a different compression.
+// The oldest blob is chosen, so that the result is stable. The blobs that cannot be referred by the Docker schema2
+// manifest of the squashed image, e.g., zstd, are skipped.
+// The chosen blob is added to the lease of ctx, so that it is not removed by the gc before the squashed image is created.
+func (sr *squashRuntime) findExistingLayer(ctx context.Context, diffID, written digest.Digest) (ocispec.Descriptor, bool, error) {
+	var candidates []content.Info
+	filter := fmt.Sprintf("labels.%q==%s", labels.LabelUncompressed, diffID)
+	if err := sr.contentStore.Walk(ctx, func(info content.Info) error {
+		if info.Digest != written {
+			candidates = append(candidates, info)
+		}
+		return nil
+	}, filter); err != nil {
+		return ocispec.Descriptor{}, false, err
+	}
+	slices.SortStableFunc(candidates, func(a, b content.Info) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	for _, info := range candidates {
+		desc := ocispec.Descriptor{Digest: info.Digest, Size: info.Size}
+		mediaType, ok, err := sr.detectLayerMediaType(ctx, desc)
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				continue
+			}
+			return ocispec.Descriptor{}, false, err
+		}
+		if !ok {
+			continue
+		}
+		desc.MediaType = mediaType
+		if l, ok := leases.FromContext(ctx); ok {
+			r := leases.Resource{ID: desc.Digest.String(), Type: "content"}
+			if err := sr.client.LeasesService().AddResource(ctx, leases.Lease{ID: l}, r); err != nil {
+				return ocispec.Descriptor{}, false, fmt.Errorf("failed to add the existing layer %s to the lease: %w", desc.Digest, err)
+			}
+		}
+		// the blob may have been removed by the gc before it was added to the lease
+		if _, err := sr.contentStore.Info(ctx, desc.Digest); err != nil {
+			if errdefs.IsNotFound(err) {
+				continue
+			}
+			return ocispec.Descriptor{}, false, err
+		}
+		return desc, true, nil
+	}
+	return ocispec.Descriptor{}, false, nil
+}
+
+// detectLayerMediaType returns the media type of a layer blob from its compression.
+// It returns false if the compression is not supported by the Docker schema2 manifest.
+func (sr *squashRuntime) detectLayerMediaType(ctx context.Context, desc ocispec.Descriptor) (string, bool, error) {
+	ra, err := sr.contentStore.ReaderAt(ctx, desc)
+	if err != nil {
+		return "", false, err
+	}
+	defer ra.Close()
+	magic := make([]byte, 10)
+	n, err := ra.ReadAt(magic, 0)
+	if err != nil && n == 0 {
+		return "", false, err
+	}
+	mediaType, ok := layerMediaType(compression.DetectCompression(magic[:n]))
+	return mediaType, ok, nil
+}
+
+// layerMediaType returns the media type of a layer compressed with c, in the Docker schema2 manifest of the squashed image.
+// It returns false for the compressions that the Docker schema2 manifest does not support, e.g., zstd.
+func layerMediaType(c compression.Compression) (string, bool) {
+	switch c {
+	case compression.Gzip:
+		return images.MediaTypeDockerSchema2LayerGzip, true
+	case compression.Uncompressed:
+		return images.MediaTypeDockerSchema2Layer, true
+	default:
+		return "", false
+	}
+}
+
 func (sr *squashRuntime) generateBaseImageConfig(ctx context.Context, image *squashImage, remainingLayerCount int) (ocispec.Image, error) {
 	// generate squash squashImage config
 	orginalConfig, _, err := imgutil.ReadImageConfig(ctx, image.clientImage) // aware of img.platform
@@ -238,11 +330,12 @@ func (sr *squashRuntime) writeContentsForImage(ctx context.Context, snName strin
 		return ocispec.Descriptor{}, emptyDigest, err
 	}
 
-	// config should reference to snapshotter
-	labelOpt := content.WithLabels(map[string]string{
-		fmt.Sprintf("containerd.io/gc.ref.snapshot.%s", snName): identity.ChainID(newConfig.RootFS.DiffIDs).String(),
-	})
-	err = content.WriteBlob(ctx, sr.contentStore, configDesc.Digest.String(), bytes.NewReader(newConfigJSON), configDesc, labelOpt)
+	// config should reference to snapshotter, unless the image is not unpacked
+	configLabels := map[string]string{}
+	if !sr.opt.NoUnpack {
+		configLabels[fmt.Sprintf("containerd.io/gc.ref.snapshot.%s", snName)] = identity.ChainID(newConfig.RootFS.DiffIDs).String()
+	}
+	err = content.WriteBlob(ctx, sr.contentStore, configDesc.Digest.String(), bytes.NewReader(newConfigJSON), configDesc, content.WithLabels(configLabels))
 	if err != nil {
 		return ocispec.Descriptor{}, emptyDigest, err
 	}
@@ -282,6 +375,20 @@ func (sr *squashRuntime) generateCommitImageConfig(ctx context.Context, baseImg
 		author = baseConfig.Author
 	}
 	comment := strings.TrimSpace(sr.opt.Message)
+	config := baseConfig.Config
+	if len(sr.opt.Label) > 0 {
+		lbls, err := parseSquashLabels(sr.opt.Label)
+		if err != nil {
+			return ocispec.Image{}, err
+		}
+		config.Labels = make(map[string]string, len(baseConfig.Config.Labels)+len(lbls))
+		for k, v := range baseConfig.Config.Labels {
+			config.Labels[k] = v
+		}
+		for k, v := range lbls {
+			config.Labels[k] = v
+		}
+	}
 
 	baseImageDigest := strings.Split(baseImg.Target.Digest.String(), ":")[1][:12]
 	return ocispec.Image{
@@ -292,7 +399,7 @@ func (sr *squashRuntime) generateCommitImageConfig(ctx context.Context, baseImg
 
 		Created: &createdTime,
 		Author:  author,
-		Config:  baseConfig.Config,
+		Config:  config,
 		RootFS: ocispec.RootFS{
 			Type:    "layers",
 			DiffIDs: append(baseConfig.RootFS.DiffIDs, diffID),
@@ -307,8 +414,24 @@ func (sr *squashRuntime) generateCommitImageConfig(ctx context.Context, baseImg
 	}, nil
 }
 
+// parseSquashLabels parses the KEY=VALUE labels of `nerdctl image squash --label`.
+func parseSquashLabels(label []string) (map[string]string, error) {
+	res := make(map[string]string, len(label))
+	for _, l := range label {
+		k, v, ok := strings.Cut(l, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid label %q, expected KEY=VALUE", l)
+		}
+		res[k] = v
+	}
+	return res, nil
+}
+
 // Squash will squash the image with the given options.
-func Squash(ctx context.Context, client *containerd.Client, option types.ImageSquashOptions) error {
+func Squash(ctx context.Context, client *containerd.Client, option types.ImageSquashOptions) (retErr error) {
+	if _, err := parseSquashLabels(option.Label); err != nil {
+		return err
+	}
 	var srcName string
 	walker := &imagewalker.ImageWalker{
 		Client: client,
@@ -344,12 +467,21 @@ func Squash(ctx context.Context, client *containerd.Client, option types.ImageSq
 		return err
 	}
 	remainingLayerCount := len(img.manifest.Layers) - len(sLayers)
-	// Don't gc me and clean the dirty data after 1 hour!
-	ctx, done, err := sr.client.WithLease(ctx, leases.WithRandomID(), leases.WithExpiration(1*time.Hour))
+	// Don't gc me while squashing, and clean the dirty data after the lease expires when aborted
+	ctx, done, err := leaseutil.WithLease(ctx, sr.client.LeasesService(), sr.opt.LeaseDuration)
 	if err != nil {
 		return fmt.Errorf("failed to create lease for squash: %w", err)
 	}
-	defer done(ctx)
+	defer func() {
+		var deleteOpts []leases.DeleteOpt
+		if retErr != nil {
+			// remove the intermediate content and snapshots now
+			deleteOpts = append(deleteOpts, leases.SynchronousDelete)
+		}
+		if err := done(context.WithoutCancel(ctx), deleteOpts...); err != nil {
+			log.G(ctx).WithError(err).Warn("failed to release the lease for squash")
+		}
+	}()
 
 	// generate remaining base squashImage config
 	baseImage, err := sr.generateBaseImageConfig(ctx, img, remainingLayerCount)
@@ -382,6 +514,9 @@ func Squash(ctx context.Context, client *containerd.Client, option types.ImageSq
 		log.G(ctx).WithError(err).Error("failed to create squash image")
 		return err
 	}
+	if sr.opt.NoUnpack {
+		return nil
+	}
 	cimg := containerd.NewImage(sr.client, nImg)
 	if err := cimg.Unpack(ctx, sr.opt.GOptions.Snapshotter, containerd.WithSnapshotterPlatformCheck()); err != nil {
 		log.G(ctx).WithError(err).Error("failed to unpack squash image")
@@ -404,7 +539,7 @@ func (sr *squashRuntime) applyDiffLayer(ctx context.Context, baseImg ocispec.Ima
 	}
 
 	defer func() {
-		if retErr != nil {
+		if retErr != nil || sr.opt.NoUnpack {
 			// NOTE: the snapshotter should be hold by lease. Even
 			// if the cleanup fails, the containerd gc can delete it.
 			if err := sn.Remove(ctx, key); err != nil {
@@ -422,6 +557,10 @@ func (sr *squashRuntime) applyDiffLayer(ctx context.Context, baseImg ocispec.Ima
 	if err != nil {
 		return diffLayerDesc, diffID, snapshotID, fmt.Errorf("failed to export layer: %w", err)
 	}
+	if sr.opt.NoUnpack {
+		// the working snapshot is removed, as the squashed image is not unpacked
+		return diffLayerDesc, diffID, "", nil
+	}
 
 	// commit snapshot
 	snapshotID = identity.ChainID(append(baseImg.RootFS.DiffIDs, diffID)).String()
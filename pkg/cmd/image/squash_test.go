@@ -0,0 +1,49 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/pkg/archive/compression"
+)
+
+func TestParseSquashLabels(t *testing.T) {
+	lbls, err := parseSquashLabels([]string{"foo=bar", "empty=", "a=b=c"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, lbls, map[string]string{"foo": "bar", "empty": "", "a": "b=c"})
+
+	_, err = parseSquashLabels([]string{"foo"})
+	assert.ErrorContains(t, err, "invalid label")
+	_, err = parseSquashLabels([]string{"=bar"})
+	assert.ErrorContains(t, err, "invalid label")
+}
+
+func TestLayerMediaType(t *testing.T) {
+	mediaType, ok := layerMediaType(compression.Gzip)
+	assert.Assert(t, ok)
+	assert.Equal(t, mediaType, images.MediaTypeDockerSchema2LayerGzip)
+	mediaType, ok = layerMediaType(compression.Uncompressed)
+	assert.Assert(t, ok)
+	assert.Equal(t, mediaType, images.MediaTypeDockerSchema2Layer)
+	// zstd layers cannot be referred by the Docker schema2 manifest of the squashed image
+	_, ok = layerMediaType(compression.Zstd)
+	assert.Assert(t, !ok)
+}
@@ -0,0 +1,118 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package ipfs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/ipfs"
+)
+
+// withIPFSPath calls fn with the IPFS_PATH that has the API address `ipfsAddress`.
+// An empty address uses the default IPFS_PATH.
+func withIPFSPath(ipfsAddress string, fn func(ipfsPath string) error) error {
+	if ipfsAddress == "" {
+		return fn("")
+	}
+	dir, err := os.MkdirTemp("", "apidirtmp")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "api"), []byte(ipfsAddress), 0600); err != nil {
+		return err
+	}
+	return fn(dir)
+}
+
+// ImageExport exports the image `rootCID` on IPFS to a CAR file.
+func ImageExport(ctx context.Context, rootCID string, options types.IPFSImageExportOptions) error {
+	return withIPFSPath(options.IPFSAddress, func(ipfsPath string) error {
+		if options.CAR == "-" {
+			return ipfs.ExportCAR(ctx, ipfsPath, rootCID, options.Stdout)
+		}
+		// Write to a temporary file first, so that a failure does not leave a partial CAR file
+		tmp, err := os.CreateTemp(filepath.Dir(options.CAR), ".nerdctl-ipfs-export-*")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name())
+		if err := ipfs.ExportCAR(ctx, ipfsPath, rootCID, tmp); err != nil {
+			tmp.Close()
+			return err
+		}
+		if err := tmp.Close(); err != nil {
+			return err
+		}
+		return os.Rename(tmp.Name(), options.CAR)
+	})
+}
+
+// ImageImport imports a CAR file exported by ImageExport to IPFS, and prints the root CID of the image.
+func ImageImport(ctx context.Context, options types.IPFSImageImportOptions) error {
+	var r io.Reader = options.Stdin
+	if options.CAR != "-" {
+		f, err := os.Open(options.CAR)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	return withIPFSPath(options.IPFSAddress, func(ipfsPath string) error {
+		rootCID, err := ipfs.ImportCAR(ctx, ipfsPath, r, options.Pin)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(options.Stdout, rootCID)
+		return err
+	})
+}
+
+// ImagePin pins the blobs of the image `rootCID` on IPFS, and prints their CIDs.
+func ImagePin(ctx context.Context, rootCID string, options types.IPFSImagePinOptions) error {
+	return updatePins(ctx, rootCID, options, ipfs.Pin)
+}
+
+// ImageUnpin unpins the blobs of the image `rootCID` on IPFS, and prints their CIDs.
+func ImageUnpin(ctx context.Context, rootCID string, options types.IPFSImagePinOptions) error {
+	return updatePins(ctx, rootCID, options, ipfs.Unpin)
+}
+
+func updatePins(ctx context.Context, rootCID string, options types.IPFSImagePinOptions, fn func(ctx context.Context, ipfsPath, rootCID string) ([]string, error)) error {
+	return withIPFSPath(options.IPFSAddress, func(ipfsPath string) error {
+		cids, err := fn(ctx, ipfsPath, rootCID)
+		if err != nil {
+			return err
+		}
+		if options.Quiet {
+			_, err = fmt.Fprintln(options.Stdout, rootCID)
+			return err
+		}
+		for _, c := range cids {
+			if _, err := fmt.Fprintln(options.Stdout, c); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -18,8 +18,6 @@ package ipfs
 
 import (
 	"net/http"
-	"os"
-	"path/filepath"
 
 	"github.com/containerd/log"
 
@@ -28,27 +26,17 @@ import (
 )
 
 func RegistryServe(options types.IPFSRegistryServeOptions) error {
-	var ipfsPath string
-	if options.IPFSAddress != "" {
-		dir, err := os.MkdirTemp("", "apidirtmp")
+	return withIPFSPath(options.IPFSAddress, func(ipfsPath string) error {
+		h, err := ipfs.NewRegistry(ipfs.RegistryOptions{
+			IpfsPath:     ipfsPath,
+			ReadRetryNum: options.ReadRetryNum,
+			ReadTimeout:  options.ReadTimeout,
+		})
 		if err != nil {
 			return err
 		}
-		defer os.RemoveAll(dir)
-		if err := os.WriteFile(filepath.Join(dir, "api"), []byte(options.IPFSAddress), 0600); err != nil {
-			return err
-		}
-		ipfsPath = dir
-	}
-	h, err := ipfs.NewRegistry(ipfs.RegistryOptions{
-		IpfsPath:     ipfsPath,
-		ReadRetryNum: options.ReadRetryNum,
-		ReadTimeout:  options.ReadTimeout,
+		log.L.Infof("serving on %v", options.ListenRegistry)
+		http.Handle("/", h)
+		return http.ListenAndServe(options.ListenRegistry, nil)
 	})
-	if err != nil {
-		return err
-	}
-	log.L.Infof("serving on %v", options.ListenRegistry)
-	http.Handle("/", h)
-	return http.ListenAndServe(options.ListenRegistry, nil)
 }
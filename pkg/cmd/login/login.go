@@ -18,6 +18,8 @@ package login
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
@@ -28,6 +30,7 @@ import (
 
 	"github.com/containerd/containerd/v2/core/remotes/docker"
 	"github.com/containerd/containerd/v2/core/remotes/docker/config"
+	remoteserrors "github.com/containerd/containerd/v2/core/remotes/errors"
 	"github.com/containerd/errdefs"
 	"github.com/containerd/log"
 
@@ -36,6 +39,16 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
 )
 
+// Errors returned when the registry rejects the login
+var (
+	// ErrUnauthorized is returned when the credentials are wrong (HTTP 401)
+	ErrUnauthorized = errors.New("unauthorized: incorrect username, password, or identity token")
+	// ErrForbidden is returned when the credentials are right, but not allowed to access the registry (HTTP 403)
+	ErrForbidden = errors.New("forbidden: the credentials are not allowed to access the registry")
+	// ErrTLS is returned when the certificate of the registry cannot be verified
+	ErrTLS = errors.New("TLS certificate verification failed (Hint: add the CA certificate to hosts.toml, or use --insecure-registry)")
+)
+
 const unencryptedPasswordWarning = `WARNING: Your password will be stored unencrypted in %s.
 Configure a credential helper to remove this warning. See
 https://docs.docker.com/engine/reference/commandline/login/#credentials-store
@@ -47,35 +60,66 @@ func Login(ctx context.Context, options types.LoginCommandOptions, stdout io.Wri
 		return err
 	}
 
-	credStore, err := dockerconfigresolver.NewCredentialsStore("")
+	credStore, err := dockerconfigresolver.NewCredentialsStore("", dockerconfigresolver.WithSystemKeychain(options.GOptions.Credentials.Keychain))
 	if err != nil {
 		return err
 	}
 
 	var responseIdentityToken string
 
-	credentials, err := credStore.Retrieve(registryURL, options.Username == "" && options.Password == "")
-	credentials.IdentityToken = ""
-
-	if err == nil && credentials.Username != "" && credentials.Password != "" {
-		responseIdentityToken, err = loginClientSide(ctx, options.GOptions, registryURL, credentials)
+	// The credential helper may be configured for this registry only, with `credHelpers`
+	helper := credStore.CredentialHelper(registryURL)
+	if helper != "" {
+		log.G(ctx).Debugf("using credential helper %q for %q", helper, registryURL.Host)
 	}
 
-	if err != nil || credentials.Username == "" || credentials.Password == "" {
-		err = promptUserForAuthentication(credentials, options.Username, options.Password, stdout)
+	var (
+		credentials       *dockerconfigresolver.Credentials
+		storedCredentials bool
+	)
+	if options.IdentityToken != "" {
+		credentials = &dockerconfigresolver.Credentials{IdentityToken: options.IdentityToken}
+		responseIdentityToken, err = loginClientSide(ctx, options.GOptions, registryURL, credentials)
 		if err != nil {
 			return err
 		}
+	} else {
+		credentials, err = credStore.Retrieve(registryURL, options.Username == "" && options.Password == "")
 
-		responseIdentityToken, err = loginClientSide(ctx, options.GOptions, registryURL, credentials)
-		if err != nil {
-			return err
+		// The stored credentials may be an identity token, e.g., from a previous login or from a credential helper
+		if err == nil && (credentials.IdentityToken != "" || (credentials.Username != "" && credentials.Password != "")) {
+			responseIdentityToken, err = loginClientSide(ctx, options.GOptions, registryURL, credentials)
+			if errors.Is(err, ErrTLS) {
+				// Other credentials would not help
+				return err
+			}
+			storedCredentials = err == nil
+		}
+
+		if !storedCredentials {
+			if err != nil {
+				log.G(ctx).WithError(err).Debug("failed to log in with the stored credentials")
+			}
+			credentials.IdentityToken = ""
+			err = promptUserForAuthentication(credentials, options.Username, options.Password, stdout)
+			if err != nil {
+				return err
+			}
+
+			responseIdentityToken, err = loginClientSide(ctx, options.GOptions, registryURL, credentials)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
 	if responseIdentityToken != "" {
 		credentials.Password = ""
 		credentials.IdentityToken = responseIdentityToken
+	} else if storedCredentials && helper != "" {
+		// The credentials are managed by the helper, which may not even support storing them (e.g., ecr-login)
+		_, err = fmt.Fprintln(stdout, "Login Succeeded")
+		return err
 	}
 
 	// Display a warning if we're storing the users password (not a token) and credentials store type is file.
@@ -111,14 +155,19 @@ func Login(ctx context.Context, options types.LoginCommandOptions, stdout io.Wri
 func loginClientSide(ctx context.Context, globalOptions types.GlobalCommandOptions, registryURL *dockerconfigresolver.RegistryURL, credentials *dockerconfigresolver.Credentials) (string, error) {
 	host := registryURL.Host
 	var dOpts []dockerconfigresolver.Opt
-	if globalOptions.InsecureRegistry {
+	if globalOptions.IsInsecureRegistry(host) {
 		log.G(ctx).Warnf("skipping verifying HTTPS certs for %q", host)
 		dOpts = append(dOpts, dockerconfigresolver.WithSkipVerifyCerts(true))
 	}
-	dOpts = append(dOpts, dockerconfigresolver.WithHostsDirs(globalOptions.HostsDir))
+	dOpts = append(dOpts, dockerconfigresolver.WithHostsDirs(globalOptions.HostsDir), dockerconfigresolver.WithOffline(globalOptions.Offline),
+		dockerconfigresolver.WithRegistryConfigs(globalOptions.Registries), dockerconfigresolver.WithSystemKeychain(globalOptions.Credentials.Keychain))
 
 	authCreds := func(acArg string) (string, string, error) {
 		if acArg == host {
+			if credentials.IdentityToken != "" {
+				// An empty username makes the authorizer exchange the identity token as an OAuth2 refresh token
+				return "", credentials.IdentityToken, nil
+			}
 			if credentials.RegistryToken != "" {
 				// Even containerd/CRI does not support RegistryToken as of v1.4.3,
 				// so, nobody is actually using RegistryToken?
@@ -150,7 +199,7 @@ func loginClientSide(ctx context.Context, globalOptions types.GlobalCommandOptio
 	}
 	for i, rh := range regHosts {
 		err = tryLoginWithRegHost(ctx, rh)
-		if err != nil && globalOptions.InsecureRegistry && (errors.Is(err, http.ErrSchemeMismatch) || errutil.IsErrConnectionRefused(err)) {
+		if err != nil && globalOptions.IsInsecureRegistry(host) && (errors.Is(err, http.ErrSchemeMismatch) || errutil.IsErrConnectionRefused(err)) {
 			rh.Scheme = "http"
 			err = tryLoginWithRegHost(ctx, rh)
 		}
@@ -190,11 +239,16 @@ func tryLoginWithRegHost(ctx context.Context, rh docker.RegistryHost) error {
 			}
 		}
 		if err := rh.Authorizer.Authorize(ctx, req); err != nil {
-			return fmt.Errorf("failed to call rh.Authorizer.Authorize: %w", err)
+			var statusErr remoteserrors.ErrUnexpectedStatus
+			if errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden) {
+				// The token server rejected the credentials
+				return fmt.Errorf("%w: %w", statusError(statusErr.StatusCode), err)
+			}
+			return fmt.Errorf("failed to call rh.Authorizer.Authorize: %w", transportError(err))
 		}
 		res, err := ctxhttp.Do(ctx, rh.Client, req)
 		if err != nil {
-			return fmt.Errorf("failed to call rh.Client.Do: %w", err)
+			return fmt.Errorf("failed to call rh.Client.Do: %w", transportError(err))
 		}
 		ress = append(ress, res)
 		if res.StatusCode == 401 {
@@ -204,11 +258,38 @@ func tryLoginWithRegHost(ctx context.Context, rh docker.RegistryHost) error {
 			continue
 		}
 		if res.StatusCode/100 != 2 {
-			return fmt.Errorf("unexpected status code %d", res.StatusCode)
+			return statusError(res.StatusCode)
 		}
 
 		return nil
 	}
 
-	return errors.New("too many 401 (probably)")
+	return fmt.Errorf("%w (too many 401 responses)", ErrUnauthorized)
+}
+
+// statusError returns the error for an unexpected HTTP status of the registry.
+func statusError(code int) error {
+	switch code {
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	default:
+		return fmt.Errorf("unexpected status code %d", code)
+	}
+}
+
+// transportError wraps the certificate verification errors with ErrTLS.
+func transportError(err error) error {
+	var (
+		unknownAuthorityErr x509.UnknownAuthorityError
+		hostnameErr         x509.HostnameError
+		invalidErr          x509.CertificateInvalidError
+		verificationErr     *tls.CertificateVerificationError
+	)
+	if errors.As(err, &unknownAuthorityErr) || errors.As(err, &hostnameErr) ||
+		errors.As(err, &invalidErr) || errors.As(err, &verificationErr) {
+		return fmt.Errorf("%w: %w", ErrTLS, err)
+	}
+	return err
 }
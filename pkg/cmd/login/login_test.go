@@ -0,0 +1,94 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package login
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
+)
+
+// newTokenRegistry returns a registry that only accepts the bearer token issued for the refresh token.
+func newTokenRegistry(t *testing.T, refreshToken string) *httptest.Server {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer access-token" {
+			return
+		}
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, srv.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.FormValue("grant_type") != "refresh_token" || r.FormValue("refresh_token") != refreshToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "access-token"})
+	})
+	return srv
+}
+
+func loginTo(t *testing.T, srv *httptest.Server, credentials *dockerconfigresolver.Credentials) error {
+	registryURL, err := dockerconfigresolver.Parse(strings.TrimPrefix(srv.URL, "http://"))
+	assert.NilError(t, err)
+	_, err = loginClientSide(context.Background(), types.GlobalCommandOptions{}, registryURL, credentials)
+	return err
+}
+
+func TestLoginIdentityToken(t *testing.T) {
+	srv := newTokenRegistry(t, "refresh-token")
+
+	err := loginTo(t, srv, &dockerconfigresolver.Credentials{IdentityToken: "refresh-token"})
+	assert.NilError(t, err)
+
+	err = loginTo(t, srv, &dockerconfigresolver.Credentials{IdentityToken: "revoked-token"})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestLoginErrors(t *testing.T) {
+	forbidden := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(forbidden.Close)
+	err := loginTo(t, forbidden, &dockerconfigresolver.Credentials{Username: "user", Password: "pass"})
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	unauthorized := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(unauthorized.Close)
+	err = loginTo(t, unauthorized, &dockerconfigresolver.Credentials{Username: "user", Password: "wrong"})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	err = transportError(fmt.Errorf("Get \"https://registry.example/v2/\": %w", x509.UnknownAuthorityError{}))
+	assert.ErrorIs(t, err, ErrTLS)
+	assert.ErrorIs(t, err, x509.UnknownAuthorityError{})
+	assert.Assert(t, transportError(http.ErrSchemeMismatch) == http.ErrSchemeMismatch)
+}
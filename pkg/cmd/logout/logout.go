@@ -19,16 +19,17 @@ package logout
 import (
 	"context"
 
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
 )
 
-func Logout(ctx context.Context, logoutServer string) (map[string]error, error) {
+func Logout(ctx context.Context, logoutServer string, globalOptions types.GlobalCommandOptions) (map[string]error, error) {
 	reg, err := dockerconfigresolver.Parse(logoutServer)
 	if err != nil {
 		return nil, err
 	}
 
-	credentialsStore, err := dockerconfigresolver.NewCredentialsStore("")
+	credentialsStore, err := dockerconfigresolver.NewCredentialsStore("", dockerconfigresolver.WithSystemKeychain(globalOptions.Credentials.Keychain))
 	if err != nil {
 		return nil, err
 	}
@@ -36,8 +37,18 @@ func Logout(ctx context.Context, logoutServer string) (map[string]error, error)
 	return credentialsStore.Erase(reg)
 }
 
-func ShellCompletion() ([]string, error) {
-	credentialsStore, err := dockerconfigresolver.NewCredentialsStore("")
+// LogoutAll erases the credentials of all the registries, including the ones managed by credential helpers.
+func LogoutAll(ctx context.Context, globalOptions types.GlobalCommandOptions) (map[string]error, error) {
+	credentialsStore, err := dockerconfigresolver.NewCredentialsStore("", dockerconfigresolver.WithSystemKeychain(globalOptions.Credentials.Keychain))
+	if err != nil {
+		return nil, err
+	}
+
+	return credentialsStore.EraseAll()
+}
+
+func ShellCompletion(globalOptions types.GlobalCommandOptions) ([]string, error) {
+	credentialsStore, err := dockerconfigresolver.NewCredentialsStore("", dockerconfigresolver.WithSystemKeychain(globalOptions.Credentials.Keychain))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,307 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package machine manages the Linux virtual machines with containerd for `nerdctl machine`,
+// using Lima (https://lima-vm.io) as the backend.
+// Each machine is paired with an SSH context ("lima-<NAME>"), so that the nerdctl commands
+// are executed by the nerdctl in the machine.
+package machine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/docker/go-units"
+
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/contextstore"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/identifiers"
+	"github.com/containerd/nerdctl/v2/pkg/store"
+)
+
+// DefaultName is the name of the machine when the name is omitted.
+const DefaultName = "nerdctl"
+
+// template of Lima, with containerd and nerdctl installed
+const limaTemplate = "template://default"
+
+// Machine is a Lima instance, as printed by `limactl list --json`.
+type Machine struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Dir    string `json:"dir"`
+	VMType string `json:"vmType"`
+	Arch   string `json:"arch"`
+	CPUs   int    `json:"cpus"`
+	Memory int64  `json:"memory"`
+	Disk   int64  `json:"disk"`
+	// Context is the name of the context of the machine, if exists
+	Context string `json:"-"`
+}
+
+// ContextName returns the name of the context of the machine.
+// The name is same as the host name in the ssh_config generated by Lima.
+func ContextName(name string) string {
+	return "lima-" + name
+}
+
+func limactl(ctx context.Context, stdout, stderr io.Writer, args ...string) error {
+	bin, err := exec.LookPath("limactl")
+	if err != nil {
+		return fmt.Errorf("failed to find limactl (Hint: install Lima, see https://lima-vm.io/docs/installation/): %w", err)
+	}
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	log.G(ctx).Debugf("Executing %v", cmd.Args)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to execute %v: %w", cmd.Args, err)
+	}
+	return nil
+}
+
+// Create creates and starts the machine, and creates the context of the machine.
+func Create(ctx context.Context, cs contextstore.ContextStore, name string, options types.MachineCreateOptions) error {
+	if err := identifiers.ValidateDockerCompat(name); err != nil {
+		return fmt.Errorf("invalid machine name %q: %w", name, err)
+	}
+	args := []string{"create", "--tty=false", "--name=" + name}
+	if options.CPUs != 0 {
+		args = append(args, "--cpus="+strconv.Itoa(options.CPUs))
+	}
+	if options.Memory != 0 {
+		args = append(args, "--memory="+strconv.FormatFloat(options.Memory, 'f', -1, 64))
+	}
+	if options.Disk != 0 {
+		args = append(args, "--disk="+strconv.FormatFloat(options.Disk, 'f', -1, 64))
+	}
+	if options.VMType != "" {
+		args = append(args, "--vm-type="+options.VMType)
+	}
+	args = append(args, limaTemplate)
+	if err := limactl(ctx, options.Stdout, options.Stderr, args...); err != nil {
+		return err
+	}
+	if err := limactl(ctx, options.Stdout, options.Stderr, "start", "--tty=false", name); err != nil {
+		return err
+	}
+	m, err := get(ctx, name)
+	if err != nil {
+		return err
+	}
+	c := &contextstore.Context{
+		Name:        ContextName(name),
+		Description: fmt.Sprintf("nerdctl machine %q", name),
+		SSH:         "ssh://" + ContextName(name),
+		SSHConfig:   filepath.Join(m.Dir, "ssh.config"),
+	}
+	if err := cs.Create(c); err != nil {
+		if !errors.Is(err, errdefs.ErrAlreadyExists) {
+			return err
+		}
+		log.G(ctx).Warnf("context %q already exists, not overwriting", c.Name)
+	}
+	if options.Use {
+		if err := cs.Use(c.Name); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintln(options.Stdout, name)
+	return err
+}
+
+// Start starts the machine.
+func Start(ctx context.Context, name string, options types.MachineStartOptions) error {
+	return limactl(ctx, options.Stdout, options.Stderr, "start", "--tty=false", name)
+}
+
+// Stop stops the machine.
+func Stop(ctx context.Context, name string, options types.MachineStopOptions) error {
+	args := []string{"stop"}
+	if options.Force {
+		args = append(args, "--force")
+	}
+	return limactl(ctx, options.Stdout, options.Stderr, append(args, name)...)
+}
+
+// Remove removes the machines and their contexts.
+// The current context is reset to the default context, if it is the context of a removed machine.
+func Remove(ctx context.Context, cs contextstore.ContextStore, names []string, options types.MachineRemoveOptions) error {
+	var errs []error
+	for _, name := range names {
+		if err := remove(ctx, cs, name, options); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Fprintln(options.Stdout, name)
+	}
+	return errors.Join(errs...)
+}
+
+func remove(ctx context.Context, cs contextstore.ContextStore, name string, options types.MachineRemoveOptions) error {
+	args := []string{"delete"}
+	if options.Force {
+		args = append(args, "--force")
+	}
+	if err := limactl(ctx, io.Discard, options.Stderr, append(args, name)...); err != nil {
+		return err
+	}
+	current, err := cs.Current()
+	if err != nil {
+		return err
+	}
+	if current == ContextName(name) {
+		if err := cs.Use(contextstore.DefaultName); err != nil {
+			return err
+		}
+	}
+	if err := cs.Remove(ContextName(name)); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// Machines returns the machines.
+func Machines(ctx context.Context, cs contextstore.ContextStore) ([]Machine, error) {
+	var stdout bytes.Buffer
+	if err := limactl(ctx, &stdout, nil, "list", "--json"); err != nil {
+		return nil, err
+	}
+	machines, err := parseList(&stdout)
+	if err != nil {
+		return nil, err
+	}
+	contexts, err := cs.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range machines {
+		for _, c := range contexts {
+			if c.Name == ContextName(machines[i].Name) {
+				machines[i].Context = c.Name
+			}
+		}
+	}
+	return machines, nil
+}
+
+// parseList parses the output of `limactl list --json`, i.e., a JSON object per line.
+func parseList(r io.Reader) ([]Machine, error) {
+	var machines []Machine
+	dec := json.NewDecoder(r)
+	for {
+		var m Machine
+		if err := dec.Decode(&m); err != nil {
+			if errors.Is(err, io.EOF) {
+				return machines, nil
+			}
+			return nil, fmt.Errorf("failed to parse the output of `limactl list --json`: %w", err)
+		}
+		machines = append(machines, m)
+	}
+}
+
+func get(ctx context.Context, name string) (*Machine, error) {
+	var stdout bytes.Buffer
+	if err := limactl(ctx, &stdout, nil, "list", "--json", name); err != nil {
+		return nil, err
+	}
+	machines, err := parseList(&stdout)
+	if err != nil {
+		return nil, err
+	}
+	if len(machines) != 1 {
+		return nil, fmt.Errorf("machine %q: %w", name, errdefs.ErrNotFound)
+	}
+	return &machines[0], nil
+}
+
+type machinePrintable struct {
+	Machine
+	// Current is true if the context of the machine is the current context
+	Current bool
+}
+
+// List prints the machines. The machine of the current context is marked with "*".
+func List(ctx context.Context, cs contextstore.ContextStore, options types.MachineListOptions) error {
+	machines, err := Machines(ctx, cs)
+	if err != nil {
+		return err
+	}
+	current, err := cs.Current()
+	if err != nil {
+		return err
+	}
+
+	w := options.Stdout
+	var tmpl *template.Template
+	switch options.Format {
+	case "", "table", "wide":
+		w = tabwriter.NewWriter(w, 4, 8, 4, ' ', 0)
+		if !options.Quiet {
+			fmt.Fprintln(w, "NAME\tSTATUS\tVMTYPE\tARCH\tCPUS\tMEMORY\tDISK\tCONTEXT")
+		}
+	case "raw":
+		return errors.New("unsupported format: \"raw\"")
+	default:
+		if options.Quiet {
+			return errors.New("format and quiet must not be specified together")
+		}
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, m := range machines {
+		p := machinePrintable{Machine: m, Current: m.Context != "" && m.Context == current}
+		if tmpl != nil {
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, p); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w, b.String()); err != nil {
+				return err
+			}
+		} else if options.Quiet {
+			fmt.Fprintln(w, p.Name)
+		} else {
+			name := p.Name
+			if p.Current {
+				name += " *"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n", name, p.Status, p.VMType, p.Arch, p.CPUs,
+				units.BytesSize(float64(p.Memory)), units.BytesSize(float64(p.Disk)), p.Context)
+		}
+	}
+	if f, ok := w.(formatter.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
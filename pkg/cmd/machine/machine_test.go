@@ -0,0 +1,44 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package machine
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseList(t *testing.T) {
+	// trimmed output of `limactl list --json`
+	const out = `{"name":"default","status":"Running","dir":"/Users/user/.lima/default","vmType":"vz","arch":"aarch64","cpus":4,"memory":4294967296,"disk":107374182400,"sshLocalPort":60022}
+{"name":"nerdctl","status":"Stopped","dir":"/Users/user/.lima/nerdctl","vmType":"qemu","arch":"x86_64","cpus":2,"memory":2147483648,"disk":53687091200}
+`
+	machines, err := parseList(strings.NewReader(out))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, machines, []Machine{
+		{Name: "default", Status: "Running", Dir: "/Users/user/.lima/default", VMType: "vz", Arch: "aarch64", CPUs: 4, Memory: 4294967296, Disk: 107374182400},
+		{Name: "nerdctl", Status: "Stopped", Dir: "/Users/user/.lima/nerdctl", VMType: "qemu", Arch: "x86_64", CPUs: 2, Memory: 2147483648, Disk: 53687091200},
+	})
+
+	machines, err = parseList(strings.NewReader(""))
+	assert.NilError(t, err)
+	assert.Equal(t, len(machines), 0)
+
+	_, err = parseList(strings.NewReader("time=... level=fatal"))
+	assert.ErrorContains(t, err, "failed to parse")
+}
@@ -26,6 +26,19 @@ import (
 
 func Create(ctx context.Context, client *containerd.Client, namespace string, options types.NamespaceCreateOptions) error {
 	labelsArg := objectWithLabelArgs(options.Labels)
+	limits, err := limitLabels(options)
+	if err != nil {
+		return err
+	}
+	for k, v := range limits {
+		if v == "" {
+			continue
+		}
+		if labelsArg == nil {
+			labelsArg = make(map[string]string)
+		}
+		labelsArg[k] = v
+	}
 	namespaces := client.NamespaceService()
 	return namespaces.Create(ctx, namespace, labelsArg)
 }
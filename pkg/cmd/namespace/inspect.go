@@ -28,12 +28,21 @@ import (
 )
 
 func Inspect(ctx context.Context, client *containerd.Client, inspectedNamespaces []string, options types.NamespaceInspectOptions) error {
-	result := make([]interface{}, len(inspectedNamespaces))
+	result, err := InspectEntries(ctx, client, inspectedNamespaces)
+	if err != nil {
+		return err
+	}
+	return formatter.FormatSlice(options.Format, options.Stdout, result)
+}
+
+// InspectEntries returns the inspected namespaces without formatting them.
+func InspectEntries(ctx context.Context, client *containerd.Client, inspectedNamespaces []string) ([]any, error) {
+	result := make([]any, len(inspectedNamespaces))
 	for index, ns := range inspectedNamespaces {
 		ctx = namespaces.WithNamespace(ctx, ns)
 		labels, err := client.NamespaceService().Labels(ctx, ns)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		nsInspect := native.Namespace{
 			Name:   ns,
@@ -41,5 +50,5 @@ func Inspect(ctx context.Context, client *containerd.Client, inspectedNamespaces
 		}
 		result[index] = nsInspect
 	}
-	return formatter.FormatSlice(options.Format, options.Stdout, result)
+	return result, nil
 }
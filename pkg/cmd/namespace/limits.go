@@ -0,0 +1,82 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package namespace
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-units"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/namespaceutil"
+)
+
+// limitLabels returns the namespace labels for the resource constraints and the default labels
+// of the containers. An empty value means removing the label.
+func limitLabels(options types.NamespaceCreateOptions) (map[string]string, error) {
+	res := make(map[string]string)
+	switch {
+	case options.CPUs > 0 && options.CPUQuota != -1:
+		return nil, errors.New("cpus and cpu-quota should be used separately")
+	case options.CPUs < 0:
+		return nil, fmt.Errorf("invalid cpus %v", options.CPUs)
+	case options.CPUs > 0:
+		res[labels.NamespaceCPUQuota] = strconv.FormatInt(int64(options.CPUs*namespaceutil.DefaultCPUPeriod), 10)
+	case options.CPUQuota == 0:
+		res[labels.NamespaceCPUQuota] = ""
+	case options.CPUQuota > 0:
+		res[labels.NamespaceCPUQuota] = strconv.FormatInt(options.CPUQuota, 10)
+	case options.CPUQuota < -1:
+		return nil, fmt.Errorf("invalid cpu-quota %d", options.CPUQuota)
+	}
+
+	switch options.Memory {
+	case "":
+	case "0":
+		res[labels.NamespaceMemory] = ""
+	default:
+		mem, err := units.RAMInBytes(options.Memory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse memory bytes %q: %w", options.Memory, err)
+		}
+		if mem <= 0 {
+			return nil, fmt.Errorf("invalid memory %q", options.Memory)
+		}
+		res[labels.NamespaceMemory] = strconv.FormatInt(mem, 10)
+	}
+
+	switch {
+	case options.PidsLimit == 0:
+		res[labels.NamespacePidsLimit] = ""
+	case options.PidsLimit > 0:
+		res[labels.NamespacePidsLimit] = strconv.FormatInt(options.PidsLimit, 10)
+	case options.PidsLimit < -1:
+		return nil, fmt.Errorf("invalid pids-limit %d", options.PidsLimit)
+	}
+
+	for k, v := range labelArgs(options.DefaultLabels) {
+		if strings.HasPrefix(k, labels.Prefix) {
+			return nil, fmt.Errorf("internal label %q must not be specified as a default label", k)
+		}
+		res[labels.NamespaceDefaultLabelPrefix+k] = v
+	}
+	return res, nil
+}
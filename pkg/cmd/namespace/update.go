@@ -26,6 +26,16 @@ import (
 
 func Update(ctx context.Context, client *containerd.Client, namespace string, options types.NamespaceUpdateOptions) error {
 	labelsArg := objectWithLabelArgs(options.Labels)
+	limits, err := limitLabels(types.NamespaceCreateOptions(options))
+	if err != nil {
+		return err
+	}
+	if labelsArg == nil {
+		labelsArg = make(map[string]string)
+	}
+	for k, v := range limits {
+		labelsArg[k] = v
+	}
 	namespaces := client.NamespaceService()
 	for k, v := range labelsArg {
 		if err := namespaces.SetLabel(ctx, namespace, k, v); err != nil {
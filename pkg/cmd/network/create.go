@@ -34,7 +34,9 @@ func Create(options types.NetworkCreateOptions, stdout io.Writer) error {
 		options.Subnets = []string{""}
 	}
 
-	e, err := netutil.NewCNIEnv(options.GOptions.CNIPath, options.GOptions.CNINetConfPath, netutil.WithNamespace(options.GOptions.Namespace))
+	e, err := netutil.NewCNIEnv(options.GOptions.CNIPath, options.GOptions.CNINetConfPath,
+		netutil.WithNamespace(options.GOptions.Namespace),
+		netutil.WithPortForwardingBackend(options.GOptions.PortForwardingBackend))
 	if err != nil {
 		return err
 	}
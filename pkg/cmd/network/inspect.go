@@ -21,12 +21,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+
+	types100 "github.com/containernetworking/cni/pkg/types/100"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/containerinspector"
+	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
 	"github.com/containerd/nerdctl/v2/pkg/formatter"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
@@ -35,16 +42,47 @@ import (
 )
 
 func Inspect(ctx context.Context, client *containerd.Client, options types.NetworkInspectOptions) error {
+	result, errs, err := InspectEntries(ctx, client, options)
+	if err != nil {
+		return err
+	}
+
+	if len(result) > 0 {
+		if formatErr := formatter.FormatSlice(options.Format, options.Stdout, result); formatErr != nil {
+			log.G(ctx).Error(formatErr)
+		}
+	} else {
+		err = errors.New("unable to find any network matching the provided request")
+	}
+
+	for _, unErr := range errs {
+		log.G(ctx).Error(unErr)
+	}
+
+	return err
+}
+
+// InspectEntries returns the inspected networks without formatting them.
+// The networks that could not be resolved are returned as errs.
+func InspectEntries(ctx context.Context, client *containerd.Client, options types.NetworkInspectOptions) (result []any, errs []error, err error) {
 	if options.Mode != "native" && options.Mode != "dockercompat" {
-		return fmt.Errorf("unknown mode %q", options.Mode)
+		return nil, nil, fmt.Errorf("unknown mode %q", options.Mode)
 	}
 
 	cniEnv, err := netutil.NewCNIEnv(options.GOptions.CNIPath, options.GOptions.CNINetConfPath, netutil.WithNamespace(options.GOptions.Namespace))
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+
+	dataStore, err := clientutil.DataStore(options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return nil, nil, err
+	}
+	hs, err := hostsstore.New(dataStore, options.GOptions.Namespace)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	var result []interface{}
 	netLists, errs := cniEnv.ListNetworksMatch(options.Networks, true)
 
 	for req, netList := range netLists {
@@ -58,15 +96,19 @@ func Inspect(ctx context.Context, client *containerd.Client, options types.Netwo
 		}
 
 		network := netList[0]
-		var filters = []string{fmt.Sprintf("labels.%q==%q", labels.Networks, []string{network.Name})}
+		// The label is a JSON array, so containers attached to multiple networks cannot be matched with a filter.
+		var filters = []string{fmt.Sprintf("labels.%q~=%q", labels.Networks, regexp.QuoteMeta(strconv.Quote(network.Name)))}
 
 		filteredContainers, err := client.Containers(ctx, filters...)
 
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
-		var containers []*native.Container
+		var (
+			containers []*native.Container
+			endpoints  []*native.NetworkEndpoint
+		)
 
 		for _, container := range filteredContainers {
 			nativeContainer, err := containerinspector.Inspect(ctx, container)
@@ -76,7 +118,14 @@ func Inspect(ctx context.Context, client *containerd.Client, options types.Netwo
 			if nativeContainer.Process == nil || nativeContainer.Process.Status.Status != containerd.Running {
 				continue
 			}
+			var networks []string
+			if err := json.Unmarshal([]byte(nativeContainer.Labels[labels.Networks]), &networks); err != nil || !slices.Contains(networks, network.Name) {
+				continue
+			}
 			containers = append(containers, nativeContainer)
+			if ep := inspectEndpoint(ctx, hs, nativeContainer, network.Name); ep != nil {
+				endpoints = append(endpoints, ep)
+			}
 		}
 
 		r := &native.Network{
@@ -85,6 +134,7 @@ func Inspect(ctx context.Context, client *containerd.Client, options types.Netwo
 			NerdctlLabels: network.NerdctlLabels,
 			File:          network.File,
 			Containers:    containers,
+			Endpoints:     endpoints,
 		}
 		switch options.Mode {
 		case "native":
@@ -92,24 +142,63 @@ func Inspect(ctx context.Context, client *containerd.Client, options types.Netwo
 		case "dockercompat":
 			compat, err := dockercompat.NetworkFromNative(r)
 			if err != nil {
-				return err
+				return nil, nil, err
 			}
 			result = append(result, compat)
 		}
 	}
 
-	if len(result) > 0 {
-		if formatErr := formatter.FormatSlice(options.Format, options.Stdout, result); formatErr != nil {
-			log.G(ctx).Error(formatErr)
+	return result, errs, nil
+}
+
+// inspectEndpoint returns the endpoint of the running container on the network,
+// from the CNI result recorded by the OCI hook and the counters of the interface.
+func inspectEndpoint(ctx context.Context, hs hostsstore.Store, c *native.Container, networkName string) *native.NetworkEndpoint {
+	meta, err := hs.Get(c.ID)
+	if err != nil {
+		log.G(ctx).WithError(err).Debugf("failed to get the network results of container %s", c.ID)
+		return nil
+	}
+	result, ok := meta.Networks[networkName]
+	if !ok || result == nil {
+		return nil
+	}
+	ep := endpointFromResult(c.ID, result)
+	if ep.IfName != "" && c.Process.Pid > 0 {
+		nsPath := fmt.Sprintf("/proc/%d/ns/net", c.Process.Pid)
+		ep.RxBytes, ep.TxBytes, err = netutil.InterfaceCounters(nsPath, ep.IfName)
+		if err != nil {
+			log.G(ctx).WithError(err).Debugf("failed to read the counters of container %s", c.ID)
 		}
-		err = nil
-	} else {
-		err = errors.New("unable to find any network matching the provided request")
 	}
+	return ep
+}
 
-	for _, unErr := range errs {
-		log.G(ctx).Error(unErr)
+// endpointFromResult returns the endpoint described by the container-side interface of the CNI result.
+func endpointFromResult(containerID string, result *types100.Result) *native.NetworkEndpoint {
+	ep := &native.NetworkEndpoint{
+		ContainerID: containerID,
 	}
-
-	return err
+	ifIndex := -1
+	for i, intf := range result.Interfaces {
+		if intf.Sandbox != "" {
+			ifIndex = i
+			ep.IfName = intf.Name
+			ep.MacAddress = intf.Mac
+			break
+		}
+	}
+	for _, ipc := range result.IPs {
+		if ifIndex >= 0 && ipc.Interface != nil && *ipc.Interface != ifIndex {
+			continue
+		}
+		if ipc.Address.IP.To4() != nil {
+			if ep.IPv4Address == "" {
+				ep.IPv4Address = ipc.Address.String()
+			}
+		} else if ep.IPv6Address == "" {
+			ep.IPv6Address = ipc.Address.String()
+		}
+	}
+	return ep
 }
@@ -0,0 +1,55 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package network
+
+import (
+	"net"
+	"testing"
+
+	types100 "github.com/containernetworking/cni/pkg/types/100"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+)
+
+func TestEndpointFromResult(t *testing.T) {
+	ifIndex := 2
+	result := &types100.Result{
+		Interfaces: []*types100.Interface{
+			{Name: "nerdctl0", Mac: "11:11:11:11:11:11"},
+			{Name: "veth1234", Mac: "22:22:22:22:22:22"},
+			{Name: "eth0", Mac: "aa:bb:cc:dd:ee:ff", Sandbox: "/proc/42/ns/net"},
+		},
+		IPs: []*types100.IPConfig{
+			{
+				Interface: &ifIndex,
+				Address:   net.IPNet{IP: net.ParseIP("10.4.0.2").To4(), Mask: net.CIDRMask(24, 32)},
+			},
+			{
+				Interface: &ifIndex,
+				Address:   net.IPNet{IP: net.ParseIP("fd00::2"), Mask: net.CIDRMask(64, 128)},
+			},
+		},
+	}
+	assert.DeepEqual(t, endpointFromResult("c1", result), &native.NetworkEndpoint{
+		ContainerID: "c1",
+		IfName:      "eth0",
+		MacAddress:  "aa:bb:cc:dd:ee:ff",
+		IPv4Address: "10.4.0.2/24",
+		IPv6Address: "fd00::2/64",
+	})
+}
@@ -19,16 +19,26 @@ package network
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil"
 	"github.com/containerd/nerdctl/v2/pkg/netutil"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
 func Prune(ctx context.Context, client *containerd.Client, options types.NetworkPruneOptions) error {
+	filter, err := parsePruneFilters(options.Filters)
+	if err != nil {
+		return err
+	}
+
 	e, err := netutil.NewCNIEnv(options.GOptions.CNIPath, options.GOptions.CNINetConfPath, netutil.WithNamespace(options.GOptions.Namespace))
 	if err != nil {
 		return err
@@ -55,6 +65,9 @@ func Prune(ctx context.Context, client *containerd.Client, options types.Network
 		if _, ok := usedNetworks[net.Name]; ok {
 			continue
 		}
+		if !filter.match(net) {
+			continue
+		}
 		if err := e.RemoveNetwork(net); err != nil {
 			log.G(ctx).WithError(err).Errorf("failed to remove network %s", net.Name)
 			continue
@@ -71,3 +84,68 @@ func Prune(ctx context.Context, client *containerd.Client, options types.Network
 	}
 	return nil
 }
+
+// pruneFilter holds the parsed `--filter` values of `nerdctl network prune`.
+type pruneFilter struct {
+	until  *time.Time
+	labels []func(map[string]string) bool
+}
+
+// parsePruneFilters parses the filters of `nerdctl network prune`.
+//
+// Supported filters:
+//   - until=<timestamp>: Only prune networks created before the timestamp, or before the duration ago (e.g., "24h").
+//   - label=<key>[=<value>]: Only prune networks with the label.
+//   - label!=<key>[=<value>]: Only prune networks without the label.
+func parsePruneFilters(filters []string) (*pruneFilter, error) {
+	f := &pruneFilter{}
+	for _, filter := range filters {
+		key, value, ok := strings.Cut(filter, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid filter %q: %w", filter, errdefs.ErrInvalidArgument)
+		}
+		switch key {
+		case "until":
+			if f.until != nil {
+				return nil, fmt.Errorf("more than one until filter provided: %w", errdefs.ErrInvalidArgument)
+			}
+			t, err := imgutil.ParseUntil(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter %q: %w", filter, err)
+			}
+			f.until = &t
+		case "label", "label!":
+			negate := key == "label!"
+			k, v, hasValue := strings.Cut(value, "=")
+			f.labels = append(f.labels, func(labels map[string]string) bool {
+				val, ok := labels[k]
+				matched := ok && (!hasValue || val == v)
+				return matched != negate
+			})
+		default:
+			return nil, fmt.Errorf("unsupported filter %q: %w", filter, errdefs.ErrInvalidArgument)
+		}
+	}
+	return f, nil
+}
+
+// match returns whether the network matches the filters.
+// The creation time of a network is the modification time of its config file.
+func (f *pruneFilter) match(net *netutil.NetworkConfig) bool {
+	if f.until != nil {
+		st, err := os.Stat(net.File)
+		if err != nil || !st.ModTime().Before(*f.until) {
+			return false
+		}
+	}
+	var labels map[string]string
+	if net.NerdctlLabels != nil {
+		labels = *net.NerdctlLabels
+	}
+	for _, match := range f.labels {
+		if !match(labels) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package p2p
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/p2p"
+)
+
+// Serve serves the blobs of the content store to the peers, until ctx is done.
+func Serve(ctx context.Context, client *containerd.Client, options types.P2PServeOptions) error {
+	l, err := net.Listen("tcp", options.Listen)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: p2p.NewServer(client.ContentStore(), options.GOptions.Namespace)}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if options.MDNS {
+		port := l.Addr().(*net.TCPAddr).Port
+		go func() {
+			if err := p2p.Advertise(ctx, port); err != nil {
+				log.G(ctx).WithError(err).Error("failed to advertise with mDNS")
+			}
+		}()
+	}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	log.G(ctx).Infof("serving the blobs of namespace %q on %s", options.GOptions.Namespace, l.Addr())
+	if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
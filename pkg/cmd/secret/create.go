@@ -0,0 +1,66 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package secret
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+	"github.com/containerd/nerdctl/v2/pkg/secretstore"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
+)
+
+// Create creates a secret from the file, or from stdin.
+func Create(name string, options types.SecretCreateOptions) (*native.Secret, error) {
+	var (
+		data []byte
+		err  error
+	)
+	// One more byte than the maximum is read, so that a too large secret is rejected by the store
+	if options.File == "-" {
+		data, err = io.ReadAll(io.LimitReader(options.Stdin, secretstore.MaxSize+1))
+	} else {
+		var f *os.File
+		if f, err = os.Open(options.File); err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		data, err = io.ReadAll(io.LimitReader(f, secretstore.MaxSize+1))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	secretStore, err := Store(options.GOptions.Namespace, options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return nil, err
+	}
+	labels := strutil.ConvertKVStringsToMap(strutil.DedupeStrSlice(options.Labels))
+	crypt := secretstore.Crypt{
+		EncryptCommand: options.EncryptCommand,
+		DecryptCommand: options.DecryptCommand,
+	}
+	secret, err := secretStore.Create(name, data, labels, crypt)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintln(options.Stdout, name)
+	return secret, nil
+}
@@ -0,0 +1,99 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package secret
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+)
+
+type secretPrintable struct {
+	Name      string
+	CreatedAt string
+	Encrypted bool
+	Labels    string
+	Size      int64
+}
+
+// List prints the secrets. The data of the secrets is never printed.
+func List(options types.SecretListOptions) error {
+	secretStore, err := Store(options.GOptions.Namespace, options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	secrets, err := secretStore.List()
+	if err != nil {
+		return err
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].Name < secrets[j].Name
+	})
+
+	w := options.Stdout
+	var tmpl *template.Template
+	switch options.Format {
+	case "", "table", "wide":
+		w = tabwriter.NewWriter(w, 4, 8, 4, ' ', 0)
+		if !options.Quiet {
+			fmt.Fprintln(w, "NAME\tENCRYPTED\tCREATED")
+		}
+	case "raw":
+		return errors.New("unsupported format: \"raw\"")
+	default:
+		if options.Quiet {
+			return errors.New("format and quiet must not be specified together")
+		}
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, s := range secrets {
+		p := secretPrintable{
+			Name:      s.Name,
+			CreatedAt: s.CreatedAt.Local().String(),
+			Encrypted: s.Encrypted,
+			Labels:    formatter.FormatLabels(s.Labels),
+			Size:      s.Size,
+		}
+		if tmpl != nil {
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, p); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w, b.String()); err != nil {
+				return err
+			}
+		} else if options.Quiet {
+			fmt.Fprintln(w, p.Name)
+		} else {
+			fmt.Fprintf(w, "%s\t%t\t%s\n", p.Name, p.Encrypted, formatter.TimeSinceInHuman(s.CreatedAt))
+		}
+	}
+	if f, ok := w.(formatter.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
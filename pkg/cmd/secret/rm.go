@@ -0,0 +1,102 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package secret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/secretstore"
+)
+
+func Remove(ctx context.Context, client *containerd.Client, secrets []string, options types.SecretRemoveOptions) error {
+	secretStore, err := Store(options.GOptions.Namespace, options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+
+	containers, err := client.Containers(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Note: to avoid racy behavior, this is called by secretStore.Remove *inside a lock*
+	removableSecrets := func() (secretNames []string, cannotRemove []error, err error) {
+		usedSecretsList, err := UsedSecrets(ctx, containers)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, name := range secrets {
+			if _, ok := usedSecretsList[name]; ok {
+				cannotRemove = append(cannotRemove, fmt.Errorf("secret %q is in use (%w)", name, errdefs.ErrFailedPrecondition))
+				continue
+			}
+			secretNames = append(secretNames, name)
+		}
+
+		return secretNames, cannotRemove, nil
+	}
+
+	removedNames, cannotRemove, err := secretStore.Remove(removableSecrets)
+	if err != nil {
+		return err
+	}
+	for _, name := range removedNames {
+		fmt.Fprintln(options.Stdout, name)
+	}
+	for _, secretErr := range cannotRemove {
+		log.G(ctx).Warn(secretErr)
+	}
+	if len(cannotRemove) > 0 {
+		return errors.New("some secrets could not be removed")
+	}
+	return nil
+}
+
+// UsedSecrets returns the names of the secrets used by the containers, with the number of the containers using them.
+func UsedSecrets(ctx context.Context, containers []containerd.Container) (map[string]int, error) {
+	usedSecretsList := make(map[string]int)
+	for _, c := range containers {
+		l, err := c.Labels(ctx)
+		if err != nil {
+			if errors.Is(err, errdefs.ErrNotFound) {
+				log.G(ctx).Debugf("container %q is gone - ignoring", c.ID())
+				continue
+			}
+			return nil, err
+		}
+		cs, err := secretstore.DecodeContainerSecrets(l[labels.Secrets])
+		if err != nil {
+			return nil, err
+		}
+		if cs == nil {
+			continue
+		}
+		for _, ref := range cs.References {
+			usedSecretsList[ref.Name]++
+		}
+	}
+	return usedSecretsList, nil
+}
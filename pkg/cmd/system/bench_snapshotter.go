@@ -0,0 +1,279 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/pkg/cio"
+	"github.com/containerd/containerd/v2/pkg/namespaces"
+	"github.com/containerd/containerd/v2/pkg/oci"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+	"github.com/containerd/platforms"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/idgen"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil"
+	"github.com/containerd/nerdctl/v2/pkg/infoutil"
+)
+
+// benchSnapshotters are the snapshotters benchmarked by default, if available.
+var benchSnapshotters = []string{"overlayfs", "stargz", "nydus", "overlaybd", "soci"}
+
+// BenchSnapshotterResult is a row of `nerdctl system bench-snapshotter`.
+type BenchSnapshotterResult struct {
+	Snapshotter string
+	// Pull is the time to pull the image. Remote snapshotters unpack the layers while pulling.
+	Pull string
+	// Unpack is the time to unpack the layers after pulling them, for non-remote snapshotters.
+	Unpack string
+	// ColdStart is the time from creating the container until it exits, or prints the wait line.
+	ColdStart string
+	Total     string
+	Error     string `json:",omitempty"`
+}
+
+// BenchSnapshotter pulls and runs rawRef with each snapshotter, and prints the time taken by each step.
+//
+// Each snapshotter is benchmarked in a temporary namespace, which is removed afterward.
+// The blobs already present in the content store are not fetched again, so the image should not
+// exist locally for measuring the pull time of non-remote snapshotters.
+func BenchSnapshotter(ctx context.Context, client *containerd.Client, rawRef string, args []string, options types.SystemBenchSnapshotterOptions) error {
+	var tmpl *template.Template
+	switch options.Format {
+	case "", "table":
+	case "raw":
+		return errors.New("unsupported format: \"raw\"")
+	default:
+		var err error
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+
+	available, err := infoutil.GetSnapshotterNames(ctx, client.IntrospectionService())
+	if err != nil {
+		return err
+	}
+	snapshotters := options.Snapshotters
+	if len(snapshotters) == 0 {
+		for _, sn := range benchSnapshotters {
+			if slices.Contains(available, sn) {
+				snapshotters = append(snapshotters, sn)
+			}
+		}
+	}
+	if len(snapshotters) == 0 {
+		return errors.New("no snapshotter to benchmark is available")
+	}
+
+	var results []BenchSnapshotterResult
+	for _, sn := range snapshotters {
+		res := BenchSnapshotterResult{Snapshotter: sn, Pull: "-", Unpack: "-", ColdStart: "-", Total: "-"}
+		if !slices.Contains(available, sn) {
+			res.Error = "snapshotter is not available"
+		} else if err := benchSnapshotter(ctx, client, sn, rawRef, args, options, &res); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to benchmark snapshotter %q", sn)
+			res.Error = err.Error()
+		}
+		results = append(results, res)
+	}
+
+	if tmpl != nil {
+		for _, r := range results {
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, r); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(options.Stdout, b.String()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	w := tabwriter.NewWriter(options.Stdout, 4, 8, 4, ' ', 0)
+	fmt.Fprintln(w, "SNAPSHOTTER\tPULL\tUNPACK\tCOLD START\tTOTAL\tERROR")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Snapshotter, r.Pull, r.Unpack, r.ColdStart, r.Total, r.Error)
+	}
+	return w.Flush()
+}
+
+func benchSnapshotter(ctx context.Context, client *containerd.Client, snapshotter, rawRef string, args []string, options types.SystemBenchSnapshotterOptions, res *BenchSnapshotterResult) error {
+	ns := "nerdctl-bench-" + idgen.GenerateID()[:12]
+	ctx = namespaces.WithNamespace(ctx, ns)
+	defer cleanupBenchNamespace(ctx, client, ns)
+
+	gOptions := options.GOptions
+	gOptions.Namespace = ns
+	gOptions.Snapshotter = snapshotter
+	remote := imgutil.IsRemoteSnapshotter(snapshotter)
+	start := time.Now()
+	ensured, err := imgutil.EnsureImage(ctx, client, rawRef, types.ImagePullOptions{
+		Stdout:          options.Stdout,
+		Stderr:          options.Stderr,
+		GOptions:        gOptions,
+		Unpack:          &remote,
+		OCISpecPlatform: []ocispec.Platform{platforms.DefaultSpec()},
+		Mode:            "always",
+		Quiet:           true,
+	})
+	if err != nil {
+		return err
+	}
+	pulled := time.Now()
+	res.Pull = formatBenchDuration(pulled.Sub(start))
+	if !remote {
+		if err := ensured.Image.Unpack(ctx, snapshotter); err != nil {
+			return err
+		}
+	}
+	unpacked := time.Now()
+	res.Unpack = formatBenchDuration(unpacked.Sub(pulled))
+
+	if err := benchColdStart(ctx, client, ensured.Image, snapshotter, args, options); err != nil {
+		return err
+	}
+	started := time.Now()
+	res.ColdStart = formatBenchDuration(started.Sub(unpacked))
+	res.Total = formatBenchDuration(started.Sub(start))
+	return nil
+}
+
+// benchColdStart creates a container of img and runs it until it exits, or until it prints options.WaitLine.
+// The container runs without networking, and its output is discarded.
+func benchColdStart(ctx context.Context, client *containerd.Client, img containerd.Image, snapshotter string, args []string, options types.SystemBenchSnapshotterOptions) error {
+	id := idgen.GenerateID()
+	var s specs.Spec
+	container, err := client.NewContainer(ctx, id,
+		containerd.WithImage(img),
+		containerd.WithSnapshotter(snapshotter),
+		containerd.WithNewSnapshot(id, img),
+		containerd.WithSpec(&s,
+			oci.WithDefaultSpec(),
+			oci.WithDefaultUnixDevices,
+			oci.WithImageConfigArgs(img, args),
+		),
+	)
+	if err != nil {
+		return err
+	}
+	defer container.Delete(ctx, containerd.WithSnapshotCleanup)
+
+	waitLineC := make(chan struct{}, 1)
+	stdout := io.Discard
+	if options.WaitLine != "" {
+		pr, pw := io.Pipe()
+		defer pw.Close()
+		go func() {
+			scanner := bufio.NewScanner(pr)
+			for scanner.Scan() {
+				if strings.Contains(scanner.Text(), options.WaitLine) {
+					select {
+					case waitLineC <- struct{}{}:
+					default:
+					}
+				}
+			}
+			io.Copy(io.Discard, pr)
+		}()
+		stdout = pw
+	}
+	task, err := container.NewTask(ctx, cio.NewCreator(cio.WithStreams(nil, stdout, io.Discard)))
+	if err != nil {
+		return err
+	}
+	defer task.Delete(ctx, containerd.WithProcessKill)
+	statusC, err := task.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	if err := task.Start(ctx); err != nil {
+		return err
+	}
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+	select {
+	case status := <-statusC:
+		code, _, err := status.Result()
+		if err != nil {
+			return err
+		}
+		if options.WaitLine != "" {
+			select {
+			case <-waitLineC:
+				return nil
+			default:
+				return fmt.Errorf("the container exited with code %d before printing %q", code, options.WaitLine)
+			}
+		}
+		if code != 0 {
+			return fmt.Errorf("the container exited with code %d", code)
+		}
+		return nil
+	case <-waitLineC:
+		if err := task.Kill(ctx, syscall.SIGKILL); err != nil && !errdefs.IsNotFound(err) {
+			log.G(ctx).WithError(err).Warn("failed to kill the container")
+		}
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+}
+
+// cleanupBenchNamespace removes the images of ns synchronously, so that the blobs and the snapshots
+// are garbage-collected before benchmarking the next snapshotter, and removes ns.
+func cleanupBenchNamespace(ctx context.Context, client *containerd.Client, ns string) {
+	is := client.ImageService()
+	imgs, err := is.List(ctx)
+	if err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to list the images in namespace %q", ns)
+	}
+	for _, img := range imgs {
+		if err := is.Delete(ctx, img.Name, images.SynchronousDelete()); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to remove image %q", img.Name)
+		}
+	}
+	if err := client.NamespaceService().Delete(ctx, ns); err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to remove namespace %q", ns)
+	}
+}
+
+func formatBenchDuration(d time.Duration) string {
+	return d.Round(time.Millisecond).String()
+}
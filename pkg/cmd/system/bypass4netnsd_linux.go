@@ -0,0 +1,74 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/bypass4netnsutil"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+)
+
+func Bypass4netnsdStart(ctx context.Context, options types.SystemBypass4netnsdOptions) error {
+	st, err := bypass4netnsutil.StartDaemon(ctx)
+	if err != nil {
+		return err
+	}
+	log.G(ctx).Infof("bypass4netnsd is listening on %s", st.Socket)
+	return nil
+}
+
+func Bypass4netnsdStop(_ context.Context, _ types.SystemBypass4netnsdOptions) error {
+	return bypass4netnsutil.StopDaemon()
+}
+
+func Bypass4netnsdStatus(_ context.Context, options types.SystemBypass4netnsdOptions) error {
+	st, err := bypass4netnsutil.GetDaemonStatus()
+	if err != nil {
+		return err
+	}
+	if options.Format != "" {
+		tmpl, err := formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+		if err := tmpl.Execute(options.Stdout, st); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(options.Stdout)
+		return err
+	}
+	w := tabwriter.NewWriter(options.Stdout, 4, 8, 4, ' ', 0)
+	fmt.Fprintf(w, "Running:\t%v\n", st.Running)
+	fmt.Fprintf(w, "Socket:\t%s\n", st.Socket)
+	if st.Pid != 0 {
+		fmt.Fprintf(w, "Pid:\t%d\n", st.Pid)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if !st.Running {
+		return errors.New("bypass4netnsd is not running")
+	}
+	return nil
+}
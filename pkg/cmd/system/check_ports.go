@@ -0,0 +1,145 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/pkg/namespaces"
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+)
+
+// PortForwardingRule is a port forwarding rule of the CNI portmap plugin, as printed by `nerdctl system check-ports`.
+type PortForwardingRule struct {
+	Backend string // "iptables" or "nftables"
+	Family  string // "ip" or "ip6"
+	// Container is the CNI container ID, i.e., "<namespace>-<container ID>"
+	Container string
+	Ports     []uint64
+	Stale     bool
+}
+
+// CheckPorts audits the port forwarding rules of the CNI portmap plugin, and reports the rules
+// that are not owned by any running container.
+// Such stale rules may be left behind when a container was killed without running the CNI DEL command.
+func CheckPorts(ctx context.Context, client *containerd.Client, options types.SystemCheckPortsOptions) error {
+	var (
+		w    = options.Stdout
+		tmpl *template.Template
+	)
+	switch options.Format {
+	case "", "table", "wide":
+		w = tabwriter.NewWriter(w, 4, 8, 4, ' ', 0)
+		fmt.Fprintln(w, "BACKEND\tFAMILY\tCONTAINER\tPORTS\tSTATUS")
+	case "raw":
+		return errors.New("unsupported format: \"raw\"")
+	default:
+		var err error
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+
+	running, err := runningCNIContainerIDs(ctx, client)
+	if err != nil {
+		return err
+	}
+	rules, err := readPortForwardingRules()
+	if err != nil {
+		return err
+	}
+	for _, r := range rules {
+		_, ok := running[r.Container]
+		r.Stale = !ok
+		if !r.Stale && !options.All {
+			continue
+		}
+		if tmpl != nil {
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, r); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w, b.String()); err != nil {
+				return err
+			}
+			continue
+		}
+		status := "ok"
+		if r.Stale {
+			status = "stale"
+		}
+		ports := make([]string, len(r.Ports))
+		for i, p := range r.Ports {
+			ports[i] = strconv.FormatUint(p, 10)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Backend, r.Family, r.Container, strings.Join(ports, ","), status)
+	}
+	if f, ok := w.(formatter.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// runningCNIContainerIDs returns the CNI container IDs ("<namespace>-<container ID>") of the running
+// and paused containers of all the namespaces.
+func runningCNIContainerIDs(ctx context.Context, client *containerd.Client) (map[string]struct{}, error) {
+	nsList, err := client.NamespaceService().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]struct{})
+	for _, ns := range nsList {
+		nsCtx := namespaces.WithNamespace(ctx, ns)
+		containers, err := client.Containers(nsCtx)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range containers {
+			task, err := c.Task(nsCtx, nil)
+			if err != nil {
+				if errdefs.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			status, err := task.Status(nsCtx)
+			if err != nil {
+				if errdefs.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			switch status.Status {
+			case containerd.Running, containerd.Paused:
+				res[ns+"-"+c.ID()] = struct{}{}
+			}
+		}
+	}
+	return res, nil
+}
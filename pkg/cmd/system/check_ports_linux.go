@@ -0,0 +1,76 @@
+//go:build linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/portutil/iptable"
+	"github.com/containerd/nerdctl/v2/pkg/portutil/nftable"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+func readPortForwardingRules() ([]*PortForwardingRule, error) {
+	var res []*PortForwardingRule
+	err := rootlessutil.WithDetachedNetNSIfAny(func() error {
+		for _, x := range []struct {
+			family string
+			read   func(string) ([]string, error)
+		}{
+			{"ip", iptable.ReadIPTables},
+			{"ip6", iptable.ReadIP6Tables},
+		} {
+			family := x.family
+			rules, err := x.read("nat")
+			if err != nil {
+				log.L.WithError(err).Debugf("failed to read the %s rules of iptables", family)
+				continue
+			}
+			for _, r := range iptable.ParseDNATRules(rules) {
+				res = append(res, &PortForwardingRule{
+					Backend:   "iptables",
+					Family:    family,
+					Container: r.ContainerID,
+					Ports:     r.Ports,
+				})
+			}
+		}
+		for _, family := range []string{"ip", "ip6"} {
+			rules, err := nftable.ReadRules(family)
+			if err != nil {
+				log.L.WithError(err).Debugf("failed to read the %s rules of nftables", family)
+				continue
+			}
+			for _, r := range rules {
+				// The "masquerading" chain duplicates the rules of the "hostports" and "hostip_hostports" chains.
+				if r.Chain == "masquerading" {
+					continue
+				}
+				res = append(res, &PortForwardingRule{
+					Backend:   "nftables",
+					Family:    family,
+					Container: r.ContainerID,
+					Ports:     r.Ports,
+				})
+			}
+		}
+		return nil
+	})
+	return res, err
+}
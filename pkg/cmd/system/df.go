@@ -0,0 +1,270 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/go-units"
+	"github.com/opencontainers/image-spec/identity"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+// DiskUsageSummary is a row of `nerdctl system df`, compatible with `docker system df --format`.
+type DiskUsageSummary struct {
+	Type        string
+	TotalCount  int
+	Active      int
+	Size        string
+	Reclaimable string
+}
+
+type containerDiskUsage struct {
+	ID      string
+	Name    string
+	Image   string
+	Running bool
+	Size    int64
+	// SizeLimit is the "size" option of --storage-opt
+	SizeLimit string
+}
+
+// DiskUsage shows the disk usage of the images, the containers, and the volumes.
+func DiskUsage(ctx context.Context, client *containerd.Client, options types.SystemDiskUsageOptions) error {
+	var tmpl *template.Template
+	switch options.Format {
+	case "", "table":
+	case "raw":
+		return errors.New("unsupported format: \"raw\"")
+	default:
+		var err error
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+
+	containers, err := client.Containers(ctx)
+	if err != nil {
+		return err
+	}
+	containerUsages, err := containersDiskUsage(ctx, client, containers)
+	if err != nil {
+		return err
+	}
+	imagesTotal, imagesActive, imagesSize, imagesReclaimable, err := imagesDiskUsage(ctx, client, options.GOptions.Snapshotter, containerUsages)
+	if err != nil {
+		return err
+	}
+	vols, err := volume.Volumes(options.GOptions.Namespace, options.GOptions.DataRoot, options.GOptions.Address, true, nil)
+	if err != nil {
+		return err
+	}
+	usedVolumes, err := volume.UsedVolumes(ctx, containers)
+	if err != nil {
+		return err
+	}
+
+	var containersSize, containersReclaimable int64
+	containersActive := 0
+	for _, c := range containerUsages {
+		containersSize += c.Size
+		if c.Running {
+			containersActive++
+		} else {
+			containersReclaimable += c.Size
+		}
+	}
+	var volumesSize, volumesReclaimable int64
+	volumesActive := 0
+	for name, v := range vols {
+		volumesSize += v.Size
+		if usedVolumes[name] > 0 {
+			volumesActive++
+		} else {
+			volumesReclaimable += v.Size
+		}
+	}
+	summaries := []DiskUsageSummary{
+		newDiskUsageSummary("Images", imagesTotal, imagesActive, imagesSize, imagesReclaimable),
+		newDiskUsageSummary("Containers", len(containerUsages), containersActive, containersSize, containersReclaimable),
+		newDiskUsageSummary("Local Volumes", len(vols), volumesActive, volumesSize, volumesReclaimable),
+	}
+
+	if tmpl != nil {
+		for _, s := range summaries {
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, s); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(options.Stdout, b.String()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(options.Stdout, 4, 8, 4, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tTOTAL\tACTIVE\tSIZE\tRECLAIMABLE")
+	for _, s := range summaries {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n", s.Type, s.TotalCount, s.Active, s.Size, s.Reclaimable)
+	}
+	if options.Verbose {
+		fmt.Fprint(w, "\nContainers space usage:\n\n")
+		fmt.Fprintln(w, "CONTAINER ID\tIMAGE\tSIZE\tLIMIT\tNAMES")
+		for _, c := range containerUsages {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", stringid.TruncateID(c.ID), c.Image, units.HumanSize(float64(c.Size)), orNone(c.SizeLimit), c.Name)
+		}
+		fmt.Fprint(w, "\nLocal Volumes space usage:\n\n")
+		fmt.Fprintln(w, "VOLUME NAME\tLINKS\tSIZE\tLIMIT")
+		names := make([]string, 0, len(vols))
+		for name := range vols {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			v := vols[name]
+			limit := ""
+			if v.SizeLimit > 0 {
+				limit = units.HumanSize(float64(v.SizeLimit))
+			}
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", name, usedVolumes[name], units.HumanSize(float64(v.Size)), orNone(limit))
+		}
+	}
+	return w.Flush()
+}
+
+func newDiskUsageSummary(typ string, total, active int, size, reclaimable int64) DiskUsageSummary {
+	s := DiskUsageSummary{
+		Type:        typ,
+		TotalCount:  total,
+		Active:      active,
+		Size:        units.HumanSize(float64(size)),
+		Reclaimable: units.HumanSize(float64(reclaimable)),
+	}
+	if size > 0 {
+		s.Reclaimable = fmt.Sprintf("%s (%d%%)", s.Reclaimable, reclaimable*100/size)
+	}
+	return s
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func containersDiskUsage(ctx context.Context, client *containerd.Client, containers []containerd.Container) ([]containerDiskUsage, error) {
+	res := make([]containerDiskUsage, 0, len(containers))
+	for _, c := range containers {
+		info, err := c.Info(ctx, containerd.WithoutRefreshedMetadata)
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		u := containerDiskUsage{
+			ID:    c.ID(),
+			Name:  info.Labels[labels.Name],
+			Image: info.Image,
+		}
+		if task, err := c.Task(ctx, nil); err == nil {
+			if status, err := task.Status(ctx); err == nil {
+				u.Running = status.Status == containerd.Running || status.Status == containerd.Paused
+			}
+		}
+		if info.SnapshotKey != "" {
+			usage, err := client.SnapshotService(info.Snapshotter).Usage(ctx, info.SnapshotKey)
+			if err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to get the disk usage of container %q", c.ID())
+			}
+			u.Size = usage.Size
+		}
+		var hostConfig dockercompat.HostConfigLabel
+		if s, ok := info.Labels[labels.HostConfigLabel]; ok {
+			if err := json.Unmarshal([]byte(s), &hostConfig); err == nil {
+				u.SizeLimit = hostConfig.StorageOpt["size"]
+			}
+		}
+		res = append(res, u)
+	}
+	return res, nil
+}
+
+// imagesDiskUsage returns the number of the images, the number of the images used by the containers,
+// the size of the unpacked images, and the size of the unpacked images not used by the containers.
+// The images sharing the same unpacked snapshot are counted once.
+func imagesDiskUsage(ctx context.Context, client *containerd.Client, snapshotter string, containers []containerDiskUsage) (total, active int, size, reclaimable int64, err error) {
+	imgs, err := client.ImageService().List(ctx)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	activeImages := make(map[string]struct{})
+	for _, c := range containers {
+		activeImages[c.Image] = struct{}{}
+	}
+	s := client.SnapshotService(snapshotter)
+	sizes := make(map[string]int64)
+	activeChains := make(map[string]struct{})
+	for _, img := range imgs {
+		diffIDs, err := containerd.NewImage(client, img).RootFS(ctx)
+		if err != nil {
+			log.G(ctx).WithError(err).Debugf("failed to get the rootfs of image %q", img.Name)
+			continue
+		}
+		chainID := identity.ChainID(diffIDs).String()
+		if _, ok := sizes[chainID]; !ok {
+			_, total, err := imgutil.ResourceUsage(ctx, s, chainID)
+			if err != nil {
+				// The image is not unpacked
+				log.G(ctx).WithError(err).Debugf("failed to get the disk usage of image %q", img.Name)
+			}
+			sizes[chainID] = total.Size
+		}
+		if _, ok := activeImages[img.Name]; ok {
+			active++
+			activeChains[chainID] = struct{}{}
+		}
+	}
+	for chainID, sz := range sizes {
+		size += sz
+		if _, ok := activeChains[chainID]; !ok {
+			reclaimable += sz
+		}
+	}
+	return len(imgs), active, size, reclaimable, nil
+}
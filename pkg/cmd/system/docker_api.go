@@ -0,0 +1,56 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/apiserver"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/dockerapi"
+)
+
+// DockerAPI serves the Docker Engine API of pkg/dockerapi on options.Socket until ctx is done.
+func DockerAPI(ctx context.Context, options types.SystemDockerAPIOptions) error {
+	gid := -1
+	if options.Group != "" {
+		var err error
+		gid, err = lookupGroup(options.Group)
+		if err != nil {
+			return err
+		}
+	}
+	client, ctx, cancel, err := clientutil.NewClient(ctx, options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	l, err := apiserver.ListenUnix(options.Socket, gid)
+	if err != nil {
+		return err
+	}
+	log.G(ctx).Infof("Serving the Docker Engine API %s on %s", dockerapi.APIVersion, options.Socket)
+	server := dockerapi.New(client, options.GOptions, dockerapi.Options{
+		NerdctlCmd:  options.NerdctlCmd,
+		NerdctlArgs: options.NerdctlArgs,
+	})
+	return server.Serve(ctx, l)
+}
@@ -0,0 +1,266 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/pkg/namespaces"
+	ctdversion "github.com/containerd/containerd/v2/version"
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+// DoctorCheck is the result of an environment check, as printed by `nerdctl system doctor`.
+type DoctorCheck struct {
+	Name string
+	// Status is "ok", "warn", or "fail"
+	Status  string
+	Message string
+	// Hint is the remediation for the warned or failed check
+	Hint string `json:",omitempty"`
+	// Fixable is true when the problem can be fixed by `nerdctl system doctor --fix`
+	Fixable bool `json:",omitempty"`
+	Fixed   bool `json:",omitempty"`
+
+	fix func() error
+}
+
+const (
+	doctorOK   = "ok"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+// minContainerdVersion is the minimum version of containerd supported by nerdctl.
+const minContainerdVersion = "1.7.0"
+
+// doctorStaleGracePeriod is the period during which a state entry is not regarded as stale,
+// as it may belong to a container that is being created.
+const doctorStaleGracePeriod = time.Minute
+
+// containerIDRegexp matches the container IDs generated by nerdctl.
+var containerIDRegexp = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Doctor runs the environment checks and reports the results with the remediation hints.
+// With options.Fix, the safe fixes (e.g., removing the state left behind by removed containers) are applied.
+func Doctor(ctx context.Context, options types.SystemDoctorOptions) error {
+	var tmpl *template.Template
+	switch options.Format {
+	case "", "table", "wide":
+	case "raw":
+		return errors.New("unsupported format: \"raw\"")
+	default:
+		var err error
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+
+	var failed int
+	for _, c := range doctorChecks(ctx, options) {
+		if c.Status != doctorOK && c.fix != nil {
+			c.Fixable = true
+			if options.Fix {
+				if err := c.fix(); err != nil {
+					c.Message = fmt.Sprintf("%s (failed to fix: %v)", c.Message, err)
+				} else {
+					c.Fixed = true
+				}
+			}
+		}
+		if c.Status == doctorFail && !c.Fixed {
+			failed++
+		}
+		if tmpl != nil {
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, c); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(options.Stdout, b.String()); err != nil {
+				return err
+			}
+			continue
+		}
+		printDoctorCheck(options.Stdout, c)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed, see the hints above", failed)
+	}
+	return nil
+}
+
+func printDoctorCheck(w io.Writer, c *DoctorCheck) {
+	status := "OK"
+	switch c.Status {
+	case doctorWarn:
+		status = "WARN"
+	case doctorFail:
+		status = "FAIL"
+	}
+	fmt.Fprintf(w, "[%s]\t%s: %s\n", status, c.Name, c.Message)
+	switch {
+	case c.Fixed:
+		fmt.Fprintln(w, "\tFixed")
+	case c.Status == doctorOK:
+	case c.Fixable:
+		fmt.Fprintln(w, "\tHint: run `nerdctl system doctor --fix`")
+	case c.Hint != "":
+		fmt.Fprintf(w, "\tHint: %s\n", c.Hint)
+	}
+}
+
+func doctorChecks(ctx context.Context, options types.SystemDoctorOptions) []*DoctorCheck {
+	var checks []*DoctorCheck
+	client, clientCtx, cancel, err := clientutil.NewClient(ctx, options.GOptions.Namespace, options.GOptions.Address)
+	if err == nil {
+		defer cancel()
+		ctx = clientCtx
+	}
+	containerdCheck := checkContainerd(ctx, client, err)
+	checks = append(checks, containerdCheck...)
+	if containerdCheck[0].Status != doctorOK {
+		client = nil
+	}
+	checks = append(checks, platformDoctorChecks(ctx, client, options.GOptions)...)
+	if client != nil {
+		checks = append(checks, checkStaleStateDirs(ctx, client, options.GOptions))
+	}
+	return checks
+}
+
+// checkContainerd checks the connectivity to containerd, and the version skew between containerd
+// and the containerd client library that nerdctl is built with.
+func checkContainerd(ctx context.Context, client *containerd.Client, clientErr error) []*DoctorCheck {
+	hint := "run `sudo systemctl start containerd`, or specify the socket address with --address"
+	if rootlessutil.IsRootless() {
+		hint = "run `systemctl --user start containerd`, or `nerdctl system rootless setup`"
+	}
+	if clientErr != nil {
+		return []*DoctorCheck{{Name: "containerd", Status: doctorFail, Message: clientErr.Error(), Hint: hint}}
+	}
+	versionCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	v, err := client.Version(versionCtx)
+	if err != nil {
+		return []*DoctorCheck{{Name: "containerd", Status: doctorFail, Message: fmt.Sprintf("failed to connect to containerd: %v", err), Hint: hint}}
+	}
+	checks := []*DoctorCheck{{Name: "containerd", Status: doctorOK, Message: fmt.Sprintf("connected (version %s)", v.Version)}}
+
+	server, err := semver.NewVersion(v.Version)
+	if err != nil {
+		return append(checks, &DoctorCheck{Name: "containerd version", Status: doctorWarn, Message: fmt.Sprintf("failed to parse the containerd version %q: %v", v.Version, err)})
+	}
+	switch {
+	case server.LessThan(semver.MustParse(minContainerdVersion)):
+		checks = append(checks, &DoctorCheck{Name: "containerd version", Status: doctorFail,
+			Message: fmt.Sprintf("containerd %s is not supported, v%s or later is required", v.Version, minContainerdVersion),
+			Hint:    "upgrade containerd, see https://github.com/containerd/containerd/releases"})
+	case isOlderMajor(server, ctdversion.Version):
+		checks = append(checks, &DoctorCheck{Name: "containerd version", Status: doctorWarn,
+			Message: fmt.Sprintf("containerd %s is older than the containerd client (%s) that nerdctl is built with, some features may be unavailable", v.Version, ctdversion.Version),
+			Hint:    "upgrade containerd, see https://github.com/containerd/containerd/releases"})
+	default:
+		checks = append(checks, &DoctorCheck{Name: "containerd version", Status: doctorOK, Message: v.Version})
+	}
+	return checks
+}
+
+func isOlderMajor(server *semver.Version, client string) bool {
+	c, err := semver.NewVersion(client)
+	if err != nil {
+		return false
+	}
+	return server.Major() < c.Major()
+}
+
+// checkStaleStateDirs checks the state directories of the containers that no longer exist in containerd.
+func checkStaleStateDirs(ctx context.Context, client *containerd.Client, globalOptions types.GlobalCommandOptions) *DoctorCheck {
+	const name = "state directories"
+	dataStore, err := clientutil.DataStore(globalOptions.DataRoot, globalOptions.Address)
+	if err != nil {
+		return &DoctorCheck{Name: name, Status: doctorWarn, Message: err.Error()}
+	}
+	var stale []string
+	for _, base := range []string{"containers", "etchosts"} {
+		nsEntries, err := os.ReadDir(filepath.Join(dataStore, base))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return &DoctorCheck{Name: name, Status: doctorWarn, Message: err.Error()}
+		}
+		for _, nsEntry := range nsEntries {
+			if !nsEntry.IsDir() {
+				continue
+			}
+			nsCtx := namespaces.WithNamespace(ctx, nsEntry.Name())
+			nsDir := filepath.Join(dataStore, base, nsEntry.Name())
+			entries, err := os.ReadDir(nsDir)
+			if err != nil {
+				return &DoctorCheck{Name: name, Status: doctorWarn, Message: err.Error()}
+			}
+			for _, e := range entries {
+				if !e.IsDir() || !containerIDRegexp.MatchString(e.Name()) || isRecent(e) {
+					continue
+				}
+				if _, err := client.ContainerService().Get(nsCtx, e.Name()); errdefs.IsNotFound(err) {
+					stale = append(stale, filepath.Join(nsDir, e.Name()))
+				}
+			}
+		}
+	}
+	if len(stale) == 0 {
+		return &DoctorCheck{Name: name, Status: doctorOK, Message: "no stale state directories in " + dataStore}
+	}
+	return &DoctorCheck{Name: name, Status: doctorWarn,
+		Message: fmt.Sprintf("%d state directories of removed containers are left in %s", len(stale), dataStore),
+		fix:     func() error { return removeAll(stale) },
+	}
+}
+
+func isRecent(e os.DirEntry) bool {
+	fi, err := e.Info()
+	return err != nil || time.Since(fi.ModTime()) < doctorStaleGracePeriod
+}
+
+func removeAll(paths []string) error {
+	var errs []error
+	for _, p := range paths {
+		if err := os.RemoveAll(p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,200 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	containerd "github.com/containerd/containerd/v2/client"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+// requiredCNIPlugins are the CNI plugins used by the default "bridge" network.
+var requiredCNIPlugins = []string{"bridge", "host-local", "loopback", "portmap", "firewall", "tuning"}
+
+// hostLocalDataDir is the default directory of the IP address leases of the CNI "host-local" IPAM plugin.
+const hostLocalDataDir = "/var/lib/cni/networks"
+
+func platformDoctorChecks(ctx context.Context, client *containerd.Client, globalOptions types.GlobalCommandOptions) []*DoctorCheck {
+	checks := []*DoctorCheck{checkCNIPlugins(globalOptions.CNIPath)}
+	checks = append(checks, checkIptables(ctx)...)
+	if globalOptions.PortForwardingBackend == "nftables" {
+		checks = append(checks, checkNft())
+	}
+	if rootlessutil.IsRootlessChild() {
+		for _, c := range rootlessutil.CheckChildSetup(ctx, globalOptions.RootlessKitPortDriver) {
+			dc := &DoctorCheck{Name: "rootless " + c.Name, Status: doctorOK, Message: c.Message, Hint: c.Hint}
+			switch {
+			case c.OK:
+			case c.Warning:
+				dc.Status = doctorWarn
+			default:
+				dc.Status = doctorFail
+			}
+			checks = append(checks, dc)
+		}
+	}
+	if client != nil {
+		checks = append(checks, checkStaleIPAMLeases(ctx, client))
+	}
+	return checks
+}
+
+func checkCNIPlugins(cniPath string) *DoctorCheck {
+	var missing []string
+	for _, p := range requiredCNIPlugins {
+		if _, err := exec.LookPath(filepath.Join(cniPath, p)); err != nil {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) > 0 {
+		return &DoctorCheck{Name: "CNI plugins", Status: doctorFail,
+			Message: fmt.Sprintf("%v are not found in %s", missing, cniPath),
+			Hint:    fmt.Sprintf("install the CNI plugins from https://github.com/containernetworking/plugins/releases into %s, or specify --cni-path", cniPath)}
+	}
+	return &DoctorCheck{Name: "CNI plugins", Status: doctorOK, Message: "found in " + cniPath}
+}
+
+// checkIptables checks that iptables and ip6tables are runnable, and use the same backend.
+// A broken iptables is often caused by a dangling symlink of update-alternatives.
+func checkIptables(ctx context.Context) []*DoctorCheck {
+	var checks []*DoctorCheck
+	backends := make(map[string]string)
+	for _, bin := range []string{"iptables", "ip6tables"} {
+		hint := fmt.Sprintf("run `sudo update-alternatives --config %s` to select a working backend (e.g., %s-nft)", bin, bin)
+		p, err := exec.LookPath(bin)
+		if err != nil {
+			checks = append(checks, &DoctorCheck{Name: bin, Status: doctorFail, Message: "not found", Hint: "install the `iptables` package"})
+			continue
+		}
+		resolved, err := filepath.EvalSymlinks(p)
+		if err != nil {
+			checks = append(checks, &DoctorCheck{Name: bin, Status: doctorFail, Message: fmt.Sprintf("%s is a broken symlink: %v", p, err), Hint: hint})
+			continue
+		}
+		out, err := exec.CommandContext(ctx, p, "--version").CombinedOutput()
+		if err != nil {
+			checks = append(checks, &DoctorCheck{Name: bin, Status: doctorFail, Message: fmt.Sprintf("failed to run %s: %v (output=%q)", resolved, err, string(out)), Hint: hint})
+			continue
+		}
+		backends[bin] = iptablesBackend(string(out))
+		checks = append(checks, &DoctorCheck{Name: bin, Status: doctorOK, Message: fmt.Sprintf("%s (%s)", strings.TrimSpace(string(out)), resolved)})
+	}
+	if len(backends) == 2 && backends["iptables"] != backends["ip6tables"] {
+		checks = append(checks, &DoctorCheck{Name: "iptables backend", Status: doctorWarn,
+			Message: fmt.Sprintf("iptables uses %q but ip6tables uses %q", backends["iptables"], backends["ip6tables"]),
+			Hint:    "run `sudo update-alternatives --config iptables` and `sudo update-alternatives --config ip6tables` to select the same backend"})
+	}
+	return checks
+}
+
+// iptablesBackend returns "nf_tables" or "legacy" from the output of `iptables --version`,
+// e.g., "iptables v1.8.10 (nf_tables)".
+func iptablesBackend(version string) string {
+	if strings.Contains(version, "(nf_tables)") {
+		return "nf_tables"
+	}
+	return "legacy"
+}
+
+func checkNft() *DoctorCheck {
+	p, err := exec.LookPath("nft")
+	if err != nil {
+		return &DoctorCheck{Name: "nft", Status: doctorFail, Message: "not found, but required by port_forwarding_backend=\"nftables\"",
+			Hint: "install the `nftables` package"}
+	}
+	return &DoctorCheck{Name: "nft", Status: doctorOK, Message: p}
+}
+
+// checkStaleIPAMLeases checks the IP address leases of the CNI "host-local" IPAM plugin
+// that are not owned by any running container.
+// Such leases may be left behind when a container was killed without running the CNI DEL command,
+// and eventually exhaust the subnet.
+func checkStaleIPAMLeases(ctx context.Context, client *containerd.Client) *DoctorCheck {
+	const name = "IPAM leases"
+	netDirs, err := os.ReadDir(hostLocalDataDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &DoctorCheck{Name: name, Status: doctorOK, Message: "no leases"}
+		}
+		return &DoctorCheck{Name: name, Status: doctorWarn, Message: err.Error()}
+	}
+	running, err := runningCNIContainerIDs(ctx, client)
+	if err != nil {
+		return &DoctorCheck{Name: name, Status: doctorWarn, Message: err.Error()}
+	}
+	var stale []string
+	for _, netDir := range netDirs {
+		if !netDir.IsDir() {
+			continue
+		}
+		dir := filepath.Join(hostLocalDataDir, netDir.Name())
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return &DoctorCheck{Name: name, Status: doctorWarn, Message: err.Error()}
+		}
+		for _, e := range entries {
+			if e.IsDir() || e.Name() == "lock" || strings.HasPrefix(e.Name(), "last_reserved_ip.") || isRecent(e) {
+				continue
+			}
+			p := filepath.Join(dir, e.Name())
+			id, err := readLeaseContainerID(p)
+			if err != nil || !isNerdctlCNIContainerID(id) {
+				// not created by nerdctl, e.g., by the CRI plugin
+				continue
+			}
+			if _, ok := running[id]; !ok {
+				stale = append(stale, p)
+			}
+		}
+	}
+	if len(stale) == 0 {
+		return &DoctorCheck{Name: name, Status: doctorOK, Message: "no stale leases in " + hostLocalDataDir}
+	}
+	return &DoctorCheck{Name: name, Status: doctorWarn,
+		Message: fmt.Sprintf("%d IP address leases in %s are not owned by any running container", len(stale), hostLocalDataDir),
+		fix:     func() error { return removeAll(stale) },
+	}
+}
+
+// isNerdctlCNIContainerID returns whether id is a CNI container ID used by nerdctl ("<namespace>-<container ID>").
+func isNerdctlCNIContainerID(id string) bool {
+	i := strings.LastIndex(id, "-")
+	return i > 0 && containerIDRegexp.MatchString(id[i+1:])
+}
+
+// readLeaseContainerID reads the container ID from the first line of a lease file of the "host-local" IPAM plugin.
+func readLeaseContainerID(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	if !sc.Scan() {
+		return "", sc.Err()
+	}
+	return strings.TrimSpace(sc.Text()), nil
+}
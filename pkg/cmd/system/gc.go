@@ -0,0 +1,249 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
+)
+
+// gcCandidate is an image considered by the garbage collection.
+type gcCandidate struct {
+	name string
+	// updatedAt is when the image was last pulled or tagged
+	updatedAt time.Time
+	blobs     []digest.Digest
+	// protected is true for the images used by containers, and for the images in the keep list
+	protected bool
+}
+
+// gcDecision is an image to be removed, with the reason.
+type gcDecision struct {
+	name   string
+	reason string
+}
+
+// GC removes the images that violate the garbage collection policy:
+// first the unused images older than MaxImageAge, then the oldest unused images until the content store fits in MaxContentSize.
+// The images used by containers and the images matching Keep are never removed.
+func GC(ctx context.Context, client *containerd.Client, options types.SystemGCOptions) error {
+	for _, pattern := range options.Keep {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid keep pattern %q: %w", pattern, err)
+		}
+	}
+
+	containerList, err := client.ContainerService().List(ctx)
+	if err != nil {
+		return err
+	}
+	usedImages := make(map[string]struct{})
+	for _, c := range containerList {
+		usedImages[c.Image] = struct{}{}
+	}
+
+	cs := client.ContentStore()
+	blobSizes, total, err := contentSizes(ctx, cs)
+	if err != nil {
+		return err
+	}
+
+	imageList, err := client.ImageService().List(ctx)
+	if err != nil {
+		return err
+	}
+	candidates := make([]gcCandidate, 0, len(imageList))
+	for _, img := range imageList {
+		blobs, err := imageBlobs(ctx, cs, img.Target)
+		if err != nil {
+			return fmt.Errorf("failed to enumerate the blobs of image %q: %w", img.Name, err)
+		}
+		_, used := usedImages[img.Name]
+		candidates = append(candidates, gcCandidate{
+			name:      img.Name,
+			updatedAt: img.UpdatedAt,
+			blobs:     blobs,
+			protected: used || matchKeepPatterns(img.Name, options.Keep),
+		})
+	}
+
+	decisions, estimated := planGC(candidates, blobSizes, options.MaxContentSize, options.MaxImageAge, time.Now())
+	if options.DryRun {
+		for _, d := range decisions {
+			fmt.Fprintf(options.Stdout, "Would remove: %s (%s)\n", d.name, d.reason)
+		}
+		fmt.Fprintf(options.Stdout, "Content store size: %s -> %s (estimated)\n", units.HumanSize(float64(total)), units.HumanSize(float64(estimated)))
+		return nil
+	}
+
+	imageStore := client.ImageService()
+	for _, d := range decisions {
+		if err := imageStore.Delete(ctx, d.name, images.SynchronousDelete()); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to delete image %s", d.name)
+			continue
+		}
+		fmt.Fprintf(options.Stdout, "Removed: %s (%s)\n", d.name, d.reason)
+	}
+	_, after, err := contentSizes(ctx, cs)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(options.Stdout, "Content store size: %s -> %s\n", units.HumanSize(float64(total)), units.HumanSize(float64(after)))
+	if options.MaxContentSize > 0 && after > options.MaxContentSize {
+		log.G(ctx).Warnf("the content store (%s) is still larger than the budget (%s), as the rest of the images are used by containers or kept",
+			units.HumanSize(float64(after)), units.HumanSize(float64(options.MaxContentSize)))
+	}
+	return nil
+}
+
+// GCSchedule runs GC every interval until ctx is done.
+// A failed run is logged, so that a transient error does not stop the schedule.
+func GCSchedule(ctx context.Context, client *containerd.Client, options types.SystemGCOptions, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := GC(ctx, client, options); err != nil {
+			log.G(ctx).WithError(err).Error("garbage collection failed")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// planGC decides the images to be removed, and estimates the size of the content store after removing them.
+// A blob is estimated to be reclaimed when no remaining image refers to it.
+func planGC(candidates []gcCandidate, blobSizes map[digest.Digest]int64, maxContentSize int64, maxImageAge time.Duration, now time.Time) ([]gcDecision, int64) {
+	var total int64
+	for _, size := range blobSizes {
+		total += size
+	}
+	refs := make(map[digest.Digest]int)
+	for _, c := range candidates {
+		for _, blob := range c.blobs {
+			refs[blob]++
+		}
+	}
+
+	var removable []gcCandidate
+	for _, c := range candidates {
+		if !c.protected {
+			removable = append(removable, c)
+		}
+	}
+	slices.SortStableFunc(removable, func(a, b gcCandidate) int {
+		return a.updatedAt.Compare(b.updatedAt)
+	})
+
+	var decisions []gcDecision
+	removed := make([]bool, len(removable))
+	remove := func(i int, reason string) {
+		removed[i] = true
+		for _, blob := range removable[i].blobs {
+			refs[blob]--
+			if refs[blob] == 0 {
+				total -= blobSizes[blob]
+			}
+		}
+		decisions = append(decisions, gcDecision{name: removable[i].name, reason: reason})
+	}
+
+	if maxImageAge > 0 {
+		for i, c := range removable {
+			if now.Sub(c.updatedAt) > maxImageAge {
+				remove(i, fmt.Sprintf("older than %s", maxImageAge))
+			}
+		}
+	}
+	if maxContentSize > 0 {
+		for i := range removable {
+			if total <= maxContentSize {
+				break
+			}
+			if !removed[i] {
+				remove(i, fmt.Sprintf("content store larger than %s", units.HumanSize(float64(maxContentSize))))
+			}
+		}
+	}
+	return decisions, total
+}
+
+// contentSizes returns the size of each blob in the content store, and the total size.
+func contentSizes(ctx context.Context, cs content.Store) (map[digest.Digest]int64, int64, error) {
+	sizes := make(map[digest.Digest]int64)
+	var total int64
+	err := cs.Walk(ctx, func(info content.Info) error {
+		sizes[info.Digest] = info.Size
+		total += info.Size
+		return nil
+	})
+	return sizes, total, err
+}
+
+// imageBlobs returns the digests of the blobs that an image refers to, without duplicates.
+func imageBlobs(ctx context.Context, cs content.Store, target ocispec.Descriptor) ([]digest.Digest, error) {
+	seen := make(map[digest.Digest]struct{})
+	var blobs []digest.Digest
+	handler := images.HandlerFunc(func(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		if _, ok := seen[desc.Digest]; ok {
+			return nil, nil
+		}
+		seen[desc.Digest] = struct{}{}
+		blobs = append(blobs, desc.Digest)
+		children, err := images.Children(ctx, cs, desc)
+		if errdefs.IsNotFound(err) {
+			// the other platforms of a multi-platform image are usually not pulled
+			return nil, nil
+		}
+		return children, err
+	})
+	return blobs, images.Walk(ctx, handler, target)
+}
+
+// matchKeepPatterns returns true if the image name, or its familiar form (e.g., "alpine:3.20"), matches any of the patterns.
+func matchKeepPatterns(name string, patterns []string) bool {
+	ref, err := referenceutil.Parse(name)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if err == nil {
+			if ok, _ := ref.FamiliarMatch(pattern); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
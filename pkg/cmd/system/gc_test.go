@@ -0,0 +1,102 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"gotest.tools/v3/assert"
+)
+
+func TestPlanGC(t *testing.T) {
+	now := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	blobSizes := map[digest.Digest]int64{
+		"sha256:shared": 100,
+		"sha256:old":    10,
+		"sha256:mid":    20,
+		"sha256:new":    30,
+		"sha256:used":   40,
+	}
+	candidates := []gcCandidate{
+		{name: "new", updatedAt: now.Add(-1 * day), blobs: []digest.Digest{"sha256:shared", "sha256:new"}},
+		{name: "old", updatedAt: now.Add(-30 * day), blobs: []digest.Digest{"sha256:shared", "sha256:old"}},
+		{name: "mid", updatedAt: now.Add(-10 * day), blobs: []digest.Digest{"sha256:mid"}},
+		{name: "used", updatedAt: now.Add(-60 * day), blobs: []digest.Digest{"sha256:used"}, protected: true},
+	}
+	names := func(decisions []gcDecision) []string {
+		var ret []string
+		for _, d := range decisions {
+			ret = append(ret, d.name)
+		}
+		return ret
+	}
+
+	t.Run("no policy", func(t *testing.T) {
+		decisions, size := planGC(candidates, blobSizes, 0, 0, now)
+		assert.Equal(t, len(decisions), 0)
+		assert.Equal(t, size, int64(200))
+	})
+
+	t.Run("max image age", func(t *testing.T) {
+		decisions, size := planGC(candidates, blobSizes, 0, 7*day, now)
+		assert.DeepEqual(t, names(decisions), []string{"old", "mid"})
+		// the shared blob is still referred to by "new"
+		assert.Equal(t, size, int64(170))
+	})
+
+	t.Run("max content size removes the oldest first", func(t *testing.T) {
+		decisions, size := planGC(candidates, blobSizes, 175, 0, now)
+		assert.DeepEqual(t, names(decisions), []string{"old", "mid"})
+		assert.Equal(t, size, int64(170))
+	})
+
+	t.Run("max content size reclaims shared blobs", func(t *testing.T) {
+		decisions, size := planGC(candidates, blobSizes, 50, 0, now)
+		assert.DeepEqual(t, names(decisions), []string{"old", "mid", "new"})
+		// the budget cannot be met, as "used" is protected
+		assert.Equal(t, size, int64(40))
+	})
+
+	t.Run("age and size combined", func(t *testing.T) {
+		decisions, _ := planGC(candidates, blobSizes, 160, 20*day, now)
+		assert.DeepEqual(t, names(decisions), []string{"old", "mid", "new"})
+		assert.Equal(t, decisions[0].reason, "older than 480h0m0s")
+		assert.Equal(t, decisions[1].reason, "content store larger than 160B")
+	})
+}
+
+func TestMatchKeepPatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		want     bool
+	}{
+		{name: "docker.io/library/alpine:3.20", patterns: []string{"alpine:*"}, want: true},
+		{name: "docker.io/library/alpine:3.20", patterns: []string{"alpine"}, want: true},
+		{name: "docker.io/library/alpine:3.20", patterns: []string{"docker.io/library/alpine:3.*"}, want: true},
+		{name: "docker.io/library/alpine:3.20", patterns: []string{"busybox:*", "alpine:3.19"}, want: false},
+		{name: "ghcr.io/example/app:v1", patterns: []string{"ghcr.io/example/*"}, want: true},
+		{name: "ghcr.io/example/app:v1", patterns: []string{"ghcr.io/*"}, want: false},
+		{name: "docker.io/library/alpine:3.20", patterns: nil, want: false},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, matchKeepPatterns(tc.name, tc.patterns), tc.want, "name=%q patterns=%v", tc.name, tc.patterns)
+	}
+}
@@ -74,6 +74,14 @@ func Info(ctx context.Context, client *containerd.Client, options types.SystemIn
 			return err
 		}
 		infoCompat.Plugins.Log = logging.Drivers()
+		// The capability report is only included in the formatted output,
+		// as collecting it requires running the CNI plugin binaries.
+		if tmpl != nil {
+			infoCompat.Capabilities, err = infoutil.Capabilities(ctx, client, options.GOptions.CNIPath)
+			if err != nil {
+				return err
+			}
+		}
 	default:
 		return fmt.Errorf("unknown mode %q", options.Mode)
 	}
@@ -19,8 +19,14 @@ package system
 import (
 	"context"
 	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/docker/go-units"
 
 	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/builder"
@@ -30,60 +36,220 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
 )
 
+// ResolvePruneTargets returns the options with the objects to prune.
+// The containers, the networks, the images, and the build cache are pruned unless some of them are selected.
+func ResolvePruneTargets(options types.SystemPruneOptions) (types.SystemPruneOptions, error) {
+	switch options.Images {
+	case "", "dangling", "all":
+	default:
+		return options, fmt.Errorf("invalid value %q for images, must be \"dangling\" or \"all\": %w", options.Images, errdefs.ErrInvalidArgument)
+	}
+	if !options.Containers && !options.Networks && options.Images == "" && !options.BuildCache {
+		options.Containers, options.Networks, options.BuildCache = true, true, true
+		options.Images = "dangling"
+		if options.All {
+			options.Images = "all"
+		}
+	} else if options.All && options.Images == "dangling" {
+		return options, fmt.Errorf("conflicting options: --all and --images=dangling: %w", errdefs.ErrInvalidArgument)
+	} else if options.All && options.Images == "" && !options.BuildCache {
+		return options, fmt.Errorf("--all needs --images or --build-cache: %w", errdefs.ErrInvalidArgument)
+	}
+	return options, nil
+}
+
+// validatePruneFilters checks the filters of `nerdctl system prune` against the objects to prune.
+//
+// Supported filters:
+//   - until=<timestamp>: Only prune the objects created before the timestamp, or before the duration ago (e.g., "24h").
+//     Not supported for volumes.
+//   - label=<key>[=<value>]: Only prune the objects with the label. Not applied to the build cache.
+//   - label!=<key>[=<value>]: Only prune the objects without the label. Not supported for images.
+func validatePruneFilters(options types.SystemPruneOptions) error {
+	for _, filter := range options.Filters {
+		key, _, ok := strings.Cut(filter, "=")
+		if !ok {
+			return fmt.Errorf("invalid filter %q: %w", filter, errdefs.ErrInvalidArgument)
+		}
+		switch key {
+		case "until":
+			if options.Volumes {
+				return fmt.Errorf("the \"until\" filter is not supported with --volumes: %w", errdefs.ErrInvalidArgument)
+			}
+		case "label":
+		case "label!":
+			if options.Images != "" {
+				return fmt.Errorf("the \"label!\" filter is not supported for images: %w", errdefs.ErrInvalidArgument)
+			}
+		default:
+			return fmt.Errorf("unsupported filter %q: %w", filter, errdefs.ErrInvalidArgument)
+		}
+	}
+	return nil
+}
+
+// buildCacheFilters returns the filters applicable to the build cache.
+func buildCacheFilters(filters []string) []string {
+	var res []string
+	for _, filter := range filters {
+		if strings.HasPrefix(filter, "until=") {
+			res = append(res, filter)
+		}
+	}
+	return res
+}
+
+// reclaimedSpace is the space reclaimed by pruning a type of the objects.
+type reclaimedSpace struct {
+	Type string
+	Size int64
+}
+
 // Prune will remove all unused containers, networks,
-// images (dangling only or both dangling and unreferenced), and optionally, volumes.
+// images (dangling only or both dangling and unreferenced), the build cache, and optionally, volumes.
+// The types of the objects to prune can be selected, and the objects can be filtered.
+// The space reclaimed for each type of the objects is printed at the end.
 func Prune(ctx context.Context, client *containerd.Client, options types.SystemPruneOptions) error {
-	if err := container.Prune(ctx, client, types.ContainerPruneOptions{
-		GOptions: options.GOptions,
-		Stdout:   options.Stdout,
-	}); err != nil {
+	options, err := ResolvePruneTargets(options)
+	if err != nil {
 		return err
 	}
-	if err := network.Prune(ctx, client, types.NetworkPruneOptions{
-		GOptions:             options.GOptions,
-		NetworkDriversToKeep: options.NetworkDriversToKeep,
-		Stdout:               options.Stdout,
-	}); err != nil {
+	if err := validatePruneFilters(options); err != nil {
 		return err
 	}
+
+	var reclaimed []reclaimedSpace
+	if options.Containers {
+		before := containersSize(ctx, client)
+		if err := container.Prune(ctx, client, types.ContainerPruneOptions{
+			GOptions: options.GOptions,
+			Stdout:   options.Stdout,
+			Filters:  options.Filters,
+			Parallel: options.Parallel,
+		}); err != nil {
+			return err
+		}
+		reclaimed = append(reclaimed, reclaimedSpace{"Containers", before - containersSize(ctx, client)})
+	}
+	if options.Networks {
+		if err := network.Prune(ctx, client, types.NetworkPruneOptions{
+			GOptions:             options.GOptions,
+			NetworkDriversToKeep: options.NetworkDriversToKeep,
+			Stdout:               options.Stdout,
+			Filters:              options.Filters,
+		}); err != nil {
+			return err
+		}
+	}
 	if options.Volumes {
+		before := volumesSize(options.GOptions)
 		if err := volume.Prune(ctx, client, types.VolumePruneOptions{
 			GOptions: options.GOptions,
 			All:      false,
 			Force:    true,
 			Stdout:   options.Stdout,
+			Filters:  options.Filters,
 		}); err != nil {
 			return err
 		}
+		reclaimed = append(reclaimed, reclaimedSpace{"Volumes", before - volumesSize(options.GOptions)})
 	}
-	if err := image.Prune(ctx, client, types.ImagePruneOptions{
-		Stdout:   options.Stdout,
-		GOptions: options.GOptions,
-		All:      options.All,
-	}); err != nil {
-		return nil
+	if options.Images != "" {
+		before := imagesSize(ctx, client, options.GOptions.Snapshotter)
+		if err := image.Prune(ctx, client, types.ImagePruneOptions{
+			Stdout:   options.Stdout,
+			GOptions: options.GOptions,
+			All:      options.Images == "all",
+			Filters:  options.Filters,
+			Parallel: options.Parallel,
+		}); err != nil {
+			return err
+		}
+		reclaimed = append(reclaimed, reclaimedSpace{"Images", before - imagesSize(ctx, client, options.GOptions.Snapshotter)})
 	}
 
-	if options.BuildKitHost != "" {
+	if options.BuildCache && options.BuildKitHost != "" {
 		prunedObjects, err := builder.Prune(ctx, types.BuilderPruneOptions{
 			Stderr:       options.Stderr,
 			GOptions:     options.GOptions,
 			All:          options.All,
 			BuildKitHost: options.BuildKitHost,
+			Filters:      buildCacheFilters(options.Filters),
 		})
 		if err != nil {
 			return err
 		}
 
+		var size int64
 		if len(prunedObjects) > 0 {
 			fmt.Fprintln(options.Stdout, "Deleted build cache objects:")
 			for _, item := range prunedObjects {
 				fmt.Fprintln(options.Stdout, item.ID)
+				size += item.Size
 			}
+			fmt.Fprintln(options.Stdout, "")
 		}
+		reclaimed = append(reclaimed, reclaimedSpace{"Build Cache", size})
 	}
 
-	// TODO: print total reclaimed space
+	return printReclaimedSpace(options, reclaimed)
+}
 
-	return nil
+func printReclaimedSpace(options types.SystemPruneOptions, reclaimed []reclaimedSpace) error {
+	var total int64
+	w := tabwriter.NewWriter(options.Stdout, 4, 8, 4, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tRECLAIMED")
+	for _, r := range reclaimed {
+		// The usage may grow while pruning, e.g., with a running container writing files
+		total += max(r.Size, 0)
+		fmt.Fprintf(w, "%s\t%s\n", r.Type, units.HumanSize(float64(max(r.Size, 0))))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(options.Stdout, "\nTotal reclaimed space: %s\n", units.HumanSize(float64(total)))
+	return err
+}
+
+// containersSize returns the total size of the writable layers of the containers.
+func containersSize(ctx context.Context, client *containerd.Client) int64 {
+	containers, err := client.Containers(ctx)
+	if err != nil {
+		log.G(ctx).WithError(err).Warn("failed to list the containers for computing the reclaimed space")
+		return 0
+	}
+	usages, err := containersDiskUsage(ctx, client, containers)
+	if err != nil {
+		log.G(ctx).WithError(err).Warn("failed to get the disk usage of the containers")
+		return 0
+	}
+	var size int64
+	for _, u := range usages {
+		size += u.Size
+	}
+	return size
+}
+
+// imagesSize returns the total size of the unpacked images.
+func imagesSize(ctx context.Context, client *containerd.Client, snapshotter string) int64 {
+	_, _, size, _, err := imagesDiskUsage(ctx, client, snapshotter, nil)
+	if err != nil {
+		log.G(ctx).WithError(err).Warn("failed to get the disk usage of the images")
+		return 0
+	}
+	return size
+}
+
+// volumesSize returns the total size of the volumes.
+func volumesSize(globalOptions types.GlobalCommandOptions) int64 {
+	vols, err := volume.Volumes(globalOptions.Namespace, globalOptions.DataRoot, globalOptions.Address, true, nil)
+	if err != nil {
+		log.L.WithError(err).Warn("failed to get the disk usage of the volumes")
+		return 0
+	}
+	var size int64
+	for _, v := range vols {
+		size += v.Size
+	}
+	return size
 }
@@ -0,0 +1,97 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+)
+
+func TestResolvePruneTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		options types.SystemPruneOptions
+		want    types.SystemPruneOptions
+		err     string
+	}{
+		{
+			name:    "default",
+			options: types.SystemPruneOptions{},
+			want:    types.SystemPruneOptions{Containers: true, Networks: true, Images: "dangling", BuildCache: true},
+		},
+		{
+			name:    "default with all and volumes",
+			options: types.SystemPruneOptions{All: true, Volumes: true},
+			want:    types.SystemPruneOptions{All: true, Containers: true, Networks: true, Volumes: true, Images: "all", BuildCache: true},
+		},
+		{
+			name:    "networks only",
+			options: types.SystemPruneOptions{Networks: true},
+			want:    types.SystemPruneOptions{Networks: true},
+		},
+		{
+			name:    "images and build cache",
+			options: types.SystemPruneOptions{Images: "all", BuildCache: true, Volumes: true},
+			want:    types.SystemPruneOptions{Volumes: true, Images: "all", BuildCache: true},
+		},
+		{
+			name:    "invalid images",
+			options: types.SystemPruneOptions{Images: "some"},
+			err:     `invalid value "some" for images`,
+		},
+		{
+			name:    "all conflicts with dangling images",
+			options: types.SystemPruneOptions{All: true, Images: "dangling"},
+			err:     "conflicting options",
+		},
+		{
+			name:    "all without images or build cache",
+			options: types.SystemPruneOptions{All: true, Networks: true},
+			err:     "--all needs --images or --build-cache",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolvePruneTargets(tt.options)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestValidatePruneFilters(t *testing.T) {
+	all := types.SystemPruneOptions{Containers: true, Networks: true, Images: "dangling", BuildCache: true}
+	withFilters := func(o types.SystemPruneOptions, filters ...string) types.SystemPruneOptions {
+		o.Filters = filters
+		return o
+	}
+	assert.NilError(t, validatePruneFilters(withFilters(all, "until=24h", "label=foo=bar")))
+	assert.ErrorContains(t, validatePruneFilters(withFilters(types.SystemPruneOptions{Volumes: true}, "until=24h")), "not supported with --volumes")
+	assert.ErrorContains(t, validatePruneFilters(withFilters(all, "label!=foo")), "not supported for images")
+	assert.NilError(t, validatePruneFilters(withFilters(types.SystemPruneOptions{Containers: true, Volumes: true}, "label!=foo")))
+	assert.ErrorContains(t, validatePruneFilters(withFilters(all, "dangling=true")), "unsupported filter")
+	assert.ErrorContains(t, validatePruneFilters(withFilters(all, "until")), "invalid filter")
+
+	assert.DeepEqual(t, buildCacheFilters([]string{"label=foo", "until=1h"}), []string{"until=1h"})
+}
@@ -0,0 +1,118 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+// RootlessSetup checks the prerequisites of rootless containerd, and installs its systemd user unit.
+func RootlessSetup(ctx context.Context, options types.SystemRootlessSetupOptions) error {
+	checks, net := rootlessutil.CheckSetup(options.Net, options.PortDriver)
+	if failed := printSetupChecks(options.Stdout, checks); failed > 0 {
+		return fmt.Errorf("%d requirement(s) not satisfied, see the hints above", failed)
+	}
+	if err := checkRootlessKit(ctx, net); err != nil {
+		return err
+	}
+	if options.CheckOnly {
+		fmt.Fprintln(options.Stdout, "Requirements are satisfied")
+		return nil
+	}
+	return installContainerdUnit(ctx, options, net)
+}
+
+// printSetupChecks prints the checks and returns the number of the failed ones.
+func printSetupChecks(w io.Writer, checks []rootlessutil.SetupCheck) int {
+	var failed int
+	for _, c := range checks {
+		status := "OK"
+		switch {
+		case c.OK:
+		case c.Warning:
+			status = "WARN"
+		default:
+			status = "FAIL"
+			failed++
+		}
+		fmt.Fprintf(w, "[%s]\t%s: %s\n", status, c.Name, c.Message)
+		if !c.OK && c.Hint != "" {
+			fmt.Fprintf(w, "\tHint: %s\n", c.Hint)
+		}
+	}
+	return failed
+}
+
+// checkRootlessKit checks that RootlessKit can create the namespaces with the network driver.
+func checkRootlessKit(ctx context.Context, net string) error {
+	cmd := exec.CommandContext(ctx, "rootlesskit", "--net="+net, "--disable-host-loopback",
+		"--copy-up=/etc", "--copy-up=/run", "--copy-up=/var/lib", "true")
+	log.G(ctx).Debugf("running %v", cmd.Args)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("RootlessKit failed, see https://rootlesscontaine.rs/getting-started/common/ : %w (output=%q)", err, string(out))
+	}
+	return nil
+}
+
+func installContainerdUnit(ctx context.Context, options types.SystemRootlessSetupOptions, net string) error {
+	script, err := exec.LookPath("containerd-rootless.sh")
+	if err != nil {
+		return err
+	}
+	configHome, err := rootlessutil.XDGConfigHome()
+	if err != nil {
+		return err
+	}
+	unitFile := filepath.Join(configHome, "systemd", "user", rootlessutil.ContainerdUnit)
+	if _, err := os.Stat(unitFile); err == nil && !options.Force {
+		log.G(ctx).Warnf("%s already exists, skipping (use --force to overwrite)", unitFile)
+	} else {
+		unit := rootlessutil.GenerateContainerdUnit(filepath.Dir(script), os.Getenv("PATH"), net, options.PortDriver)
+		if err := os.MkdirAll(filepath.Dir(unitFile), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(unitFile, []byte(unit), 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintf(options.Stdout, "Created %s\n", unitFile)
+	}
+	for _, args := range [][]string{
+		{"--user", "daemon-reload"},
+		{"--user", "enable", "--now", rootlessutil.ContainerdUnit},
+	} {
+		cmd := exec.CommandContext(ctx, "systemctl", args...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to run %v: %w (output=%q). "+
+				"Run `journalctl -n 20 --no-pager --user --unit %s` to show the error log",
+				cmd.Args, err, strings.TrimSpace(string(out)), rootlessutil.ContainerdUnit)
+		}
+	}
+	fmt.Fprintf(options.Stdout, "Installed %s successfully (network driver: %s)\n", rootlessutil.ContainerdUnit, net)
+	fmt.Fprintf(options.Stdout, "To run %s on system startup automatically, run: `sudo loginctl enable-linger $(id -un)`\n", rootlessutil.ContainerdUnit)
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"fmt"
+	"os/user"
+	"strconv"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/apiserver"
+	"github.com/containerd/nerdctl/v2/pkg/config"
+	"github.com/containerd/nerdctl/v2/pkg/sdk"
+)
+
+// Serve serves the API of pkg/apiserver on options.Socket until ctx is done.
+func Serve(ctx context.Context, options types.SystemServeOptions) error {
+	gid := -1
+	if options.Group != "" {
+		var err error
+		gid, err = lookupGroup(options.Group)
+		if err != nil {
+			return err
+		}
+	}
+	var authorizers []apiserver.Authorizer
+	if options.ReadOnly {
+		authorizers = append(authorizers, apiserver.ReadOnlyAuthorizer())
+	}
+	if len(options.AllowUIDs) > 0 {
+		authorizers = append(authorizers, apiserver.UIDAuthorizer(options.AllowUIDs...))
+	}
+	if options.AuthzCommand != "" {
+		authorizers = append(authorizers, apiserver.CommandAuthorizer(options.AuthzCommand))
+	}
+
+	client, err := sdk.New(ctx, sdk.WithConfig(config.Config(options.GOptions)))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	l, err := apiserver.ListenUnix(options.Socket, gid)
+	if err != nil {
+		return err
+	}
+	log.G(ctx).Infof("Serving the nerdctl API %s on %s", apiserver.APIVersion, options.Socket)
+	return apiserver.New(client, apiserver.ChainAuthorizers(authorizers...)).Serve(ctx, l)
+}
+
+// lookupGroup returns the ID of a group specified by its name or its ID.
+func lookupGroup(group string) (int, error) {
+	if gid, err := strconv.Atoi(group); err == nil {
+		return gid, nil
+	}
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return -1, err
+	}
+	gid, err := strconv.Atoi(g.Gid)
+	if err != nil {
+		return -1, fmt.Errorf("group %q has a non-numeric ID %q: %w", group, g.Gid, err)
+	}
+	return gid, nil
+}
@@ -0,0 +1,279 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/docker/go-units"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/log"
+	"github.com/containerd/typeurl/v2"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+const (
+	// WatchdogPressureTopic is the topic of the event published when the filesystem usage exceeds the threshold.
+	WatchdogPressureTopic = "/nerdctl/watchdog/pressure"
+	// WatchdogEvictTopic is the topic of the event published when a container is evicted.
+	WatchdogEvictTopic = "/nerdctl/watchdog/evict"
+)
+
+// WatchdogEvent is the event published by the watchdog.
+type WatchdogEvent struct {
+	Root      string  `json:"root"`
+	Usage     float64 `json:"usage"`
+	Threshold float64 `json:"threshold"`
+	// ContainerID and Priority are set for WatchdogEvictTopic
+	ContainerID string `json:"container_id,omitempty"`
+	Priority    int    `json:"priority,omitempty"`
+}
+
+func init() {
+	typeurl.Register(&WatchdogEvent{}, "nerdctl", "watchdog", "v1", "WatchdogEvent")
+}
+
+// fsStat is the capacity and the available space of a filesystem, in bytes.
+type fsStat struct {
+	total uint64
+	avail uint64
+}
+
+func (st fsStat) used() uint64 {
+	return st.total - min(st.avail, st.total)
+}
+
+// usage returns the usage in percent.
+func (st fsStat) usage() float64 {
+	if st.total == 0 {
+		return 0
+	}
+	return 100 * float64(st.used()) / float64(st.total)
+}
+
+// evictionCandidate is a container that may be evicted.
+type evictionCandidate struct {
+	container containerd.Container
+	id        string
+	name      string
+	priority  int
+	running   bool
+	createdAt time.Time
+}
+
+// Watchdog monitors the usage of the filesystem of options.Root.
+// When the usage exceeds options.Threshold, the unused images are pruned, and then the containers are stopped and
+// removed in the order of the eviction priority, until the usage goes below options.Target.
+func Watchdog(ctx context.Context, client *containerd.Client, options types.SystemWatchdogOptions) error {
+	if options.Once {
+		return watchdogCheck(ctx, client, options)
+	}
+	ticker := time.NewTicker(options.Interval)
+	defer ticker.Stop()
+	for {
+		if err := watchdogCheck(ctx, client, options); err != nil {
+			log.G(ctx).WithError(err).Error("watchdog check failed")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func watchdogCheck(ctx context.Context, client *containerd.Client, options types.SystemWatchdogOptions) error {
+	st, err := statFS(options.Root)
+	if err != nil {
+		return err
+	}
+	if st.usage() < options.Threshold {
+		log.G(ctx).Debugf("filesystem usage of %s is %.1f%%", options.Root, st.usage())
+		return nil
+	}
+	log.G(ctx).Warnf("filesystem usage of %s is %.1f%%, above the threshold %.1f%%", options.Root, st.usage(), options.Threshold)
+	publishWatchdogEvent(ctx, client, WatchdogPressureTopic, &WatchdogEvent{
+		Root:      options.Root,
+		Usage:     st.usage(),
+		Threshold: options.Threshold,
+	})
+
+	if err := watchdogPruneImages(ctx, client, options, st); err != nil {
+		log.G(ctx).WithError(err).Warn("failed to prune images")
+	}
+	if st, err = statFS(options.Root); err != nil {
+		return err
+	}
+
+	candidates, err := evictionCandidates(ctx, client, options.PriorityLabel)
+	if err != nil {
+		return err
+	}
+	evicted := 0
+	for _, cand := range candidates {
+		if st.usage() < options.Target {
+			break
+		}
+		if err := evictContainer(ctx, client, options, cand); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to evict container %s", cand.name)
+			continue
+		}
+		evicted++
+		fmt.Fprintf(options.Stdout, "Evicted: %s (priority %d)\n", cand.name, cand.priority)
+		publishWatchdogEvent(ctx, client, WatchdogEvictTopic, &WatchdogEvent{
+			Root:        options.Root,
+			Usage:       st.usage(),
+			Threshold:   options.Threshold,
+			ContainerID: cand.id,
+			Priority:    cand.priority,
+		})
+		if st, err = statFS(options.Root); err != nil {
+			return err
+		}
+	}
+
+	// The images of the evicted containers are no longer used
+	if evicted > 0 && st.usage() >= options.Target {
+		if err := watchdogPruneImages(ctx, client, options, st); err != nil {
+			log.G(ctx).WithError(err).Warn("failed to prune images")
+		}
+		if st, err = statFS(options.Root); err != nil {
+			return err
+		}
+	}
+	if st.usage() >= options.Target {
+		log.G(ctx).Warnf("filesystem usage of %s is still %.1f%%, above the target %.1f%%", options.Root, st.usage(), options.Target)
+	}
+	return nil
+}
+
+// watchdogPruneImages removes the oldest unused images, until the content store shrinks by the excess of the usage over the target.
+func watchdogPruneImages(ctx context.Context, client *containerd.Client, options types.SystemWatchdogOptions, st fsStat) error {
+	excess := int64(st.used()) - int64(options.Target/100*float64(st.total))
+	if excess <= 0 {
+		return nil
+	}
+	_, total, err := contentSizes(ctx, client.ContentStore())
+	if err != nil {
+		return err
+	}
+	log.G(ctx).Infof("pruning images to reclaim %s", units.HumanSize(float64(excess)))
+	return GC(ctx, client, types.SystemGCOptions{
+		Stdout:         options.Stdout,
+		GOptions:       options.GOptions,
+		MaxContentSize: max(total-excess, 1),
+		Keep:           options.Keep,
+	})
+}
+
+func evictionCandidates(ctx context.Context, client *containerd.Client, priorityLabel string) ([]evictionCandidate, error) {
+	containers, err := client.Containers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []evictionCandidate
+	for _, c := range containers {
+		info, err := c.Info(ctx, containerd.WithoutRefreshedMetadata)
+		if err != nil {
+			log.G(ctx).WithError(err).Debugf("failed to get the info of container %s", c.ID())
+			continue
+		}
+		priority, evictable, err := parseEvictionPriority(info.Labels[priorityLabel])
+		if err != nil {
+			log.G(ctx).WithError(err).Warnf("container %s has an invalid eviction priority, assuming 0", c.ID())
+		}
+		if !evictable {
+			continue
+		}
+		name := info.Labels[labels.Name]
+		if name == "" {
+			name = c.ID()
+		}
+		cand := evictionCandidate{
+			container: c,
+			id:        c.ID(),
+			name:      name,
+			priority:  priority,
+			createdAt: info.CreatedAt,
+		}
+		if status, err := containerutil.ContainerStatus(ctx, c); err == nil {
+			cand.running = status.Status == containerd.Running || status.Status == containerd.Paused
+		}
+		candidates = append(candidates, cand)
+	}
+	sortEvictionCandidates(candidates)
+	return candidates, nil
+}
+
+// sortEvictionCandidates sorts the candidates in the eviction order:
+// lower priorities first, then stopped containers before running ones, then newer containers first.
+func sortEvictionCandidates(candidates []evictionCandidate) {
+	slices.SortStableFunc(candidates, func(a, b evictionCandidate) int {
+		if c := cmp.Compare(a.priority, b.priority); c != 0 {
+			return c
+		}
+		if a.running != b.running {
+			if a.running {
+				return 1
+			}
+			return -1
+		}
+		return b.createdAt.Compare(a.createdAt)
+	})
+}
+
+// parseEvictionPriority parses the value of the eviction priority label.
+// An empty value is priority 0, and "never" is not evictable.
+func parseEvictionPriority(v string) (int, bool, error) {
+	switch v {
+	case "":
+		return 0, true, nil
+	case "never":
+		return 0, false, nil
+	}
+	priority, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, true, fmt.Errorf("invalid eviction priority %q: %w", v, err)
+	}
+	return priority, true, nil
+}
+
+func evictContainer(ctx context.Context, client *containerd.Client, options types.SystemWatchdogOptions, cand evictionCandidate) error {
+	if cand.running {
+		timeout := options.StopTimeout
+		if err := containerutil.Stop(ctx, cand.container, &timeout, ""); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to stop container %s, killing it", cand.name)
+		}
+	}
+	return container.RemoveContainer(ctx, cand.container, options.GOptions, true, true, client)
+}
+
+func publishWatchdogEvent(ctx context.Context, client *containerd.Client, topic string, event *WatchdogEvent) {
+	if err := client.EventService().Publish(ctx, topic, event); err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to publish event %s", topic)
+	}
+}
@@ -0,0 +1,67 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseEvictionPriority(t *testing.T) {
+	priority, evictable, err := parseEvictionPriority("")
+	assert.NilError(t, err)
+	assert.Equal(t, priority, 0)
+	assert.Equal(t, evictable, true)
+
+	priority, evictable, err = parseEvictionPriority("-10")
+	assert.NilError(t, err)
+	assert.Equal(t, priority, -10)
+	assert.Equal(t, evictable, true)
+
+	_, evictable, err = parseEvictionPriority("never")
+	assert.NilError(t, err)
+	assert.Equal(t, evictable, false)
+
+	_, evictable, err = parseEvictionPriority("high")
+	assert.ErrorContains(t, err, "invalid eviction priority")
+	assert.Equal(t, evictable, true)
+}
+
+func TestSortEvictionCandidates(t *testing.T) {
+	now := time.Now()
+	candidates := []evictionCandidate{
+		{name: "important", priority: 10, createdAt: now.Add(-time.Hour)},
+		{name: "running-old", running: true, createdAt: now.Add(-2 * time.Hour)},
+		{name: "running-new", running: true, createdAt: now.Add(-time.Minute)},
+		{name: "stopped", createdAt: now.Add(-3 * time.Hour)},
+		{name: "expendable", priority: -1, running: true, createdAt: now.Add(-time.Hour)},
+	}
+	sortEvictionCandidates(candidates)
+	var names []string
+	for _, c := range candidates {
+		names = append(names, c.name)
+	}
+	assert.DeepEqual(t, names, []string{"expendable", "stopped", "running-new", "running-old", "important"})
+}
+
+func TestFSStatUsage(t *testing.T) {
+	assert.Equal(t, fsStat{total: 200, avail: 50}.usage(), 75.0)
+	assert.Equal(t, fsStat{total: 200, avail: 50}.used(), uint64(150))
+	assert.Equal(t, fsStat{}.usage(), 0.0)
+}
@@ -0,0 +1,34 @@
+//go:build unix
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+func statFS(path string) (fsStat, error) {
+	var sfs unix.Statfs_t
+	if err := unix.Statfs(path, &sfs); err != nil {
+		return fsStat{}, err
+	}
+	return fsStat{
+		total: uint64(sfs.Blocks) * uint64(sfs.Bsize),
+		avail: uint64(sfs.Bavail) * uint64(sfs.Bsize),
+	}, nil
+}
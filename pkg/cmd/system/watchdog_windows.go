@@ -0,0 +1,33 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+func statFS(path string) (fsStat, error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return fsStat{}, err
+	}
+	var st fsStat
+	if err := windows.GetDiskFreeSpaceEx(p, &st.avail, &st.total, nil); err != nil {
+		return fsStat{}, err
+	}
+	return st, nil
+}
@@ -0,0 +1,156 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volume
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/mattn/go-isatty"
+
+	"github.com/containerd/containerd/v2/pkg/archive/compression"
+	"github.com/containerd/continuity/fs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil/volumestore"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
+	"github.com/containerd/nerdctl/v2/pkg/tarutil"
+)
+
+// Export writes the content of a volume to options.Stdout, as a tar archive.
+func Export(ctx context.Context, name string, options types.VolumeExportOptions) error {
+	volStore, err := Store(options.GOptions.Namespace, options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	vol, err := volStore.Get(name, false)
+	if err != nil {
+		return err
+	}
+	if err := checkLocalVolume(vol); err != nil {
+		return err
+	}
+	return tarutil.Create(ctx, vol.Mountpoint, nil, options.Stdout)
+}
+
+// Import extracts a tar archive into a volume, creating the volume if it does not exist.
+func Import(ctx context.Context, name string, options types.VolumeImportOptions) error {
+	in := options.Stdin
+	if options.Input != "" {
+		f, err := os.Open(options.Input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	} else if f, ok := in.(*os.File); ok && isatty.IsTerminal(f.Fd()) {
+		return errors.New("cowardly refusing to read from a terminal. Use the -i flag or redirect")
+	}
+	decompressor, err := compression.DecompressStream(in)
+	if err != nil {
+		return err
+	}
+	defer decompressor.Close()
+
+	volStore, err := Store(options.GOptions.Namespace, options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	var vol *native.Volume
+	if exists, err := volStore.Exists(name); err != nil {
+		return err
+	} else if exists {
+		if vol, err = volStore.Get(name, false); err != nil {
+			return err
+		}
+	} else if vol, err = volStore.Create(name, strutil.DedupeStrSlice(options.Labels)); err != nil {
+		return err
+	}
+	if err := checkLocalVolume(vol); err != nil {
+		return err
+	}
+	if err := tarutil.Extract(ctx, vol.Mountpoint, decompressor); err != nil {
+		return err
+	}
+	fmt.Fprintln(options.Stdout, name)
+	return nil
+}
+
+// Clone creates a new volume with the content, the labels, and the options of an existing volume.
+// The files are copied with copy_file_range(2), which makes reflinks on the file systems supporting them (btrfs, xfs).
+func Clone(ctx context.Context, source, target string, options types.VolumeCloneOptions) (err error) {
+	volStore, err := Store(options.GOptions.Namespace, options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	src, err := volStore.Get(source, false)
+	if err != nil {
+		return err
+	}
+	if err := checkLocalVolume(src); err != nil {
+		return err
+	}
+	if exists, err := volStore.Exists(target); err != nil {
+		return err
+	} else if exists {
+		return fmt.Errorf("volume %q already exists", target)
+	}
+
+	var lbls []string
+	if src.Labels != nil {
+		for k, v := range *src.Labels {
+			// The clone is a named volume, even if the source is an anonymous one
+			if k == labels.AnonymousVolumes {
+				continue
+			}
+			lbls = append(lbls, k+"="+v)
+		}
+	}
+	lbls = strutil.DedupeStrSlice(append(lbls, options.Labels...))
+	dst, err := volStore.CreateWithDriver(target, lbls, volumestore.LocalDriverName, src.Options)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if _, _, rmErr := volStore.Remove(func() ([]string, []error, error) {
+			return []string{target}, nil, nil
+		}); rmErr != nil {
+			log.G(ctx).WithError(rmErr).Warnf("failed to remove volume %q", target)
+		}
+	}()
+	if err = fs.CopyDir(dst.Mountpoint, src.Mountpoint); err != nil {
+		return fmt.Errorf("failed to copy volume %q to %q: %w", source, target, err)
+	}
+	fmt.Fprintln(options.Stdout, target)
+	return nil
+}
+
+// checkLocalVolume returns an error for the volumes that are not stored in the nerdctl data root.
+func checkLocalVolume(vol *native.Volume) error {
+	if vol.Driver != "" && vol.Driver != volumestore.LocalDriverName {
+		return fmt.Errorf("volume %q uses the driver %q, only the volumes of the %q driver are supported", vol.Name, vol.Driver, volumestore.LocalDriverName)
+	}
+	return nil
+}
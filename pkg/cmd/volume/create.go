@@ -17,6 +17,7 @@
 package volume
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/docker/docker/pkg/stringid"
@@ -24,6 +25,8 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil/volumestore"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
@@ -32,12 +35,31 @@ func Create(name string, options types.VolumeCreateOptions) (*native.Volume, err
 		name = stringid.GenerateRandomID()
 		options.Labels = append(options.Labels, labels.AnonymousVolumes+"=")
 	}
+	if (options.Driver == volumestore.NFSDriverName || options.Driver == volumestore.CIFSDriverName || options.Driver == volumestore.ZFSDriverName) && rootlessutil.IsRootless() {
+		return nil, fmt.Errorf("volume driver %q is not supported in rootless mode, as the kernel does not allow mounting %s without the root privileges",
+			options.Driver, options.Driver)
+	}
+	if options.Size != "" {
+		if options.Driver != "" && options.Driver != volumestore.LocalDriverName && options.Driver != volumestore.ZFSDriverName {
+			return nil, fmt.Errorf("--size is only supported with the %q and %q volume drivers", volumestore.LocalDriverName, volumestore.ZFSDriverName)
+		}
+		if s, ok := options.DriverOpts["size"]; ok && s != options.Size {
+			return nil, fmt.Errorf("conflicting sizes %q and %q", options.Size, s)
+		}
+		if options.DriverOpts == nil {
+			options.DriverOpts = make(map[string]string)
+		}
+		options.DriverOpts["size"] = options.Size
+	}
+	if _, ok := options.DriverOpts["size"]; ok && rootlessutil.IsRootless() && (options.Driver == "" || options.Driver == volumestore.LocalDriverName) {
+		return nil, errors.New("the size limit of volumes is not supported in rootless mode, as setting project quotas requires the root privileges")
+	}
 	volStore, err := Store(options.GOptions.Namespace, options.GOptions.DataRoot, options.GOptions.Address)
 	if err != nil {
 		return nil, err
 	}
 	labels := strutil.DedupeStrSlice(options.Labels)
-	vol, err := volStore.Create(name, labels)
+	vol, err := volStore.CreateWithDriver(name, labels, options.Driver, options.DriverOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -27,21 +27,10 @@ import (
 )
 
 func Inspect(ctx context.Context, volumes []string, options types.VolumeInspectOptions) error {
-	volStore, err := Store(options.GOptions.Namespace, options.GOptions.DataRoot, options.GOptions.Address)
+	result, warns, err := InspectEntries(volumes, options)
 	if err != nil {
 		return err
 	}
-	result := []interface{}{}
-
-	warns := []error{}
-	for _, name := range volumes {
-		var vol, err = volStore.Get(name, options.Size)
-		if err != nil {
-			warns = append(warns, err)
-			continue
-		}
-		result = append(result, vol)
-	}
 	err = formatter.FormatSlice(options.Format, options.Stdout, result)
 	if err != nil {
 		return err
@@ -55,3 +44,22 @@ func Inspect(ctx context.Context, volumes []string, options types.VolumeInspectO
 	}
 	return nil
 }
+
+// InspectEntries returns the inspected volumes without formatting them.
+// The volumes that could not be inspected are returned as warns.
+func InspectEntries(volumes []string, options types.VolumeInspectOptions) (result []any, warns []error, err error) {
+	volStore, err := Store(options.GOptions.Namespace, options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return nil, nil, err
+	}
+	result = []any{}
+	for _, name := range volumes {
+		vol, err := volStore.Get(name, options.Size)
+		if err != nil {
+			warns = append(warns, err)
+			continue
+		}
+		result = append(result, vol)
+	}
+	return result, warns, nil
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/formatter"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil/volumestore"
 )
 
 type volumePrintable struct {
@@ -118,12 +119,15 @@ func lsPrintOutput(vols map[string]native.Volume, options types.VolumeListOption
 
 	for _, v := range vols {
 		p := volumePrintable{
-			Driver:     "local",
+			Driver:     v.Driver,
 			Labels:     "",
 			Mountpoint: v.Mountpoint,
 			Name:       v.Name,
 			Scope:      "local",
 		}
+		if p.Driver == "" {
+			p.Driver = volumestore.LocalDriverName
+		}
 		if v.Labels != nil {
 			p.Labels = formatter.FormatLabels(*v.Labels)
 		}
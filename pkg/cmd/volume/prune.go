@@ -19,9 +19,11 @@ package volume
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
@@ -36,6 +38,11 @@ func Prune(ctx context.Context, client *containerd.Client, options types.VolumeP
 		return err
 	}
 
+	filter, err := parsePruneFilters(options.Filters)
+	if err != nil {
+		return err
+	}
+
 	var toRemove []string // nolint: prealloc
 
 	err = volStore.Prune(func(volumes []*native.Volume) ([]string, error) {
@@ -45,7 +52,7 @@ func Prune(ctx context.Context, client *containerd.Client, options types.VolumeP
 			return nil, err
 		}
 
-		usedVolumesList, err := usedVolumes(ctx, containers)
+		usedVolumesList, err := UsedVolumes(ctx, containers)
 		if err != nil {
 			return nil, err
 		}
@@ -54,15 +61,17 @@ func Prune(ctx context.Context, client *containerd.Client, options types.VolumeP
 			if _, ok := usedVolumesList[volume.Name]; ok {
 				continue
 			}
-			if !options.All {
-				if volume.Labels == nil {
+			anonymous := isAnonymousVolume(volume)
+			if filter.anonymous != nil {
+				if anonymous != *filter.anonymous {
 					continue
 				}
-				val, ok := (*volume.Labels)[labels.AnonymousVolumes]
+			} else if !options.All && !anonymous {
 				// skip the named volume and only remove the anonymous volume
-				if !ok || val != "" {
-					continue
-				}
+				continue
+			}
+			if !filter.matchLabels(volume.Labels) {
+				continue
 			}
 			toRemove = append(toRemove, volume.Name)
 		}
@@ -82,3 +91,67 @@ func Prune(ctx context.Context, client *containerd.Client, options types.VolumeP
 
 	return nil
 }
+
+// pruneFilter holds the parsed `--filter` values of `nerdctl volume prune`.
+type pruneFilter struct {
+	// anonymous is nil when the filter is not specified
+	anonymous *bool
+	labels    []func(*map[string]string) bool
+}
+
+// parsePruneFilters parses the filters of `nerdctl volume prune`.
+//
+// Supported filters:
+//   - anonymous=<bool>: Only prune anonymous (true) or named (false) volumes.
+//     Specifying this filter overrides the default of pruning anonymous volumes only.
+//   - label=<key>[=<value>]: Only prune volumes with the label.
+//   - label!=<key>[=<value>]: Only prune volumes without the label.
+func parsePruneFilters(filters []string) (*pruneFilter, error) {
+	f := &pruneFilter{}
+	for _, filter := range filters {
+		key, value, ok := strings.Cut(filter, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid filter %q: %w", filter, errdefs.ErrInvalidArgument)
+		}
+		switch key {
+		case "anonymous":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for filter %q: %w", filter, errdefs.ErrInvalidArgument)
+			}
+			f.anonymous = &b
+		case "label", "label!":
+			negate := key == "label!"
+			k, v, hasValue := strings.Cut(value, "=")
+			f.labels = append(f.labels, func(labels *map[string]string) bool {
+				matched := false
+				if labels != nil {
+					val, ok := (*labels)[k]
+					matched = ok && (!hasValue || val == v)
+				}
+				return matched != negate
+			})
+		default:
+			return nil, fmt.Errorf("unsupported filter %q: %w", filter, errdefs.ErrInvalidArgument)
+		}
+	}
+	return f, nil
+}
+
+func (f *pruneFilter) matchLabels(labels *map[string]string) bool {
+	for _, match := range f.labels {
+		if !match(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// isAnonymousVolume returns whether the volume was created without a name.
+func isAnonymousVolume(vol *native.Volume) bool {
+	if vol.Labels == nil {
+		return false
+	}
+	val, ok := (*vol.Labels)[labels.AnonymousVolumes]
+	return ok && val == ""
+}
@@ -0,0 +1,184 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volume
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+)
+
+// SnapshotCreate takes a snapshot of a volume.
+// When the snapshot name is empty, the current time (e.g., "20240102T150405Z") is used.
+func SnapshotCreate(ctx context.Context, name, snapshot string, options types.VolumeSnapshotCreateOptions) error {
+	if options.Keep < 0 {
+		return fmt.Errorf("invalid number of snapshots to keep: %d", options.Keep)
+	}
+	volStore, err := Store(options.GOptions.Namespace, options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	if snapshot == "" {
+		snapshot = time.Now().UTC().Format("20060102T150405Z")
+	}
+	if _, err := volStore.CreateSnapshot(name, snapshot); err != nil {
+		return err
+	}
+	fmt.Fprintln(options.Stdout, snapshot)
+
+	if options.Keep == 0 {
+		return nil
+	}
+	snaps, err := volStore.ListSnapshots(name)
+	if err != nil {
+		return err
+	}
+	for len(snaps) > options.Keep {
+		if err := volStore.RemoveSnapshot(name, snaps[0].Name); err != nil {
+			return err
+		}
+		snaps = snaps[1:]
+	}
+	return nil
+}
+
+type snapshotPrintable struct {
+	Name      string
+	Volume    string
+	CreatedAt string
+	Method    string
+}
+
+// SnapshotList lists the snapshots of a volume, the oldest first.
+func SnapshotList(ctx context.Context, name string, options types.VolumeSnapshotListOptions) error {
+	volStore, err := Store(options.GOptions.Namespace, options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	snaps, err := volStore.ListSnapshots(name)
+	if err != nil {
+		return err
+	}
+
+	w := options.Stdout
+	var tmpl *template.Template
+	switch options.Format {
+	case "", "table", "wide":
+		w = tabwriter.NewWriter(w, 4, 8, 4, ' ', 0)
+		if !options.Quiet {
+			fmt.Fprintln(w, "SNAPSHOT\tCREATED\tMETHOD")
+		}
+	case "raw":
+		return errors.New("unsupported format: \"raw\"")
+	default:
+		if options.Quiet {
+			return errors.New("format and quiet must not be specified together")
+		}
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, s := range snaps {
+		p := snapshotPrintable{
+			Name:      s.Name,
+			Volume:    s.Volume,
+			CreatedAt: s.CreatedAt.Round(time.Second).Local().String(),
+			Method:    s.Method,
+		}
+		if tmpl != nil {
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, p); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w, b.String()); err != nil {
+				return err
+			}
+		} else if options.Quiet {
+			fmt.Fprintln(w, p.Name)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, formatter.TimeSinceInHuman(s.CreatedAt), p.Method)
+		}
+	}
+	if f, ok := w.(formatter.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// SnapshotRestore replaces the content of a volume with one of its snapshots.
+// The volume must not be used by running containers.
+func SnapshotRestore(ctx context.Context, client *containerd.Client, name, snapshot string, options types.VolumeSnapshotRestoreOptions) error {
+	volStore, err := Store(options.GOptions.Namespace, options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	containers, err := client.Containers(ctx)
+	if err != nil {
+		return err
+	}
+	var running []containerd.Container
+	for _, c := range containers {
+		task, err := c.Task(ctx, nil)
+		if err != nil {
+			continue
+		}
+		if status, err := task.Status(ctx); err == nil && status.Status != containerd.Stopped {
+			running = append(running, c)
+		}
+	}
+	used, err := UsedVolumes(ctx, running)
+	if err != nil {
+		return err
+	}
+	if _, ok := used[name]; ok {
+		return fmt.Errorf("volume %q is used by running containers, stop them first (%w)", name, errdefs.ErrFailedPrecondition)
+	}
+	if err := volStore.RestoreSnapshot(name, snapshot); err != nil {
+		return err
+	}
+	fmt.Fprintln(options.Stdout, snapshot)
+	return nil
+}
+
+// SnapshotRemove removes snapshots of a volume.
+func SnapshotRemove(ctx context.Context, name string, snapshots []string, options types.VolumeSnapshotRemoveOptions) error {
+	volStore, err := Store(options.GOptions.Namespace, options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, snapshot := range snapshots {
+		if err := volStore.RemoveSnapshot(name, snapshot); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Fprintln(options.Stdout, snapshot)
+	}
+	return errors.Join(errs...)
+}
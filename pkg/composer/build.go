@@ -19,9 +19,9 @@ package composer
 import (
 	"context"
 	"fmt"
-	"os"
 
 	"github.com/compose-spec/compose-go/v2/types"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/containerd/log"
 
@@ -35,25 +35,56 @@ type BuildOptions struct {
 }
 
 func (c *Composer) Build(ctx context.Context, bo BuildOptions, services []string) error {
-	return c.project.ForEachService(services, func(names string, svc *types.ServiceConfig) error {
+	var toBuild []*serviceparser.Service
+	if err := c.project.ForEachService(services, func(names string, svc *types.ServiceConfig) error {
 		ps, err := serviceparser.Parse(c.project, *svc)
 		if err != nil {
 			return err
 		}
 		if ps.Build != nil {
-			return c.buildServiceImage(ctx, ps.Image, ps.Build, ps.Unparsed.Platform, bo)
+			toBuild = append(toBuild, ps)
 		}
 		return nil
-	}, types.IgnoreDependencies)
-}
+	}, types.IgnoreDependencies); err != nil {
+		return err
+	}
 
-func (c *Composer) buildServiceImage(ctx context.Context, image string, b *serviceparser.Build, platform string, bo BuildOptions) error {
-	log.G(ctx).Infof("Building image %s", image)
+	if len(toBuild) <= 1 || c.Parallel == 1 {
+		for _, ps := range toBuild {
+			if err := c.buildServiceImage(ctx, ps.Image, ps.Build, bo); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 
-	var args []string // nolint: prealloc
-	if platform != "" {
-		args = append(args, "--platform="+platform)
+	// The output of the concurrent builds is interleaved line by line, prefixed with the service names.
+	// The progress is shown in the plain mode by default, as the tty mode cannot be interleaved.
+	if bo.Progress == "" {
+		bo.Progress = "plain"
+	}
+	tagWidth := 0
+	for _, ps := range toBuild {
+		tagWidth = max(tagWidth, len(ps.Unparsed.Name))
 	}
+	eg, ctx := errgroup.WithContext(ctx)
+	if c.Parallel > 0 {
+		eg.SetLimit(c.Parallel)
+	}
+	for _, ps := range toBuild {
+		eg.Go(func() error {
+			log.G(ctx).Infof("Building image %s", ps.Image)
+			if err := c.runNerdctlCmdTagged(ctx, ps.Unparsed.Name, tagWidth+1, buildArgs(ps.Build, bo)...); err != nil {
+				return fmt.Errorf("error while building image %s: %w", ps.Image, err)
+			}
+			return nil
+		})
+	}
+	return eg.Wait()
+}
+
+func buildArgs(b *serviceparser.Build, bo BuildOptions) []string {
+	var args []string // nolint: prealloc
 	for _, a := range bo.Args {
 		args = append(args, "--build-arg="+a)
 	}
@@ -64,13 +95,18 @@ func (c *Composer) buildServiceImage(ctx context.Context, image string, b *servi
 		args = append(args, "--progress="+bo.Progress)
 	}
 	args = append(args, b.BuildArgs...)
+	return append([]string{"build"}, args...)
+}
+
+func (c *Composer) buildServiceImage(ctx context.Context, image string, b *serviceparser.Build, bo BuildOptions) error {
+	log.G(ctx).Infof("Building image %s", image)
 
-	cmd := c.createNerdctlCmd(ctx, append([]string{"build"}, args...)...)
+	cmd := c.createNerdctlCmd(ctx, buildArgs(b, bo)...)
 	if c.DebugPrintFull {
 		log.G(ctx).Debugf("Running %v", cmd.Args)
 	}
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stdout = c.stdout()
+	cmd.Stderr = c.stderr()
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("error while building image %s: %w", image, err)
 	}
@@ -21,7 +21,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"sync"
 
 	composecli "github.com/compose-spec/compose-go/v2/cli"
 	compose "github.com/compose-spec/compose-go/v2/types"
@@ -29,6 +32,7 @@ import (
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/log"
 
+	"github.com/containerd/nerdctl/v2/pkg/composer/pipetagger"
 	"github.com/containerd/nerdctl/v2/pkg/composer/serviceparser"
 	"github.com/containerd/nerdctl/v2/pkg/identifiers"
 	"github.com/containerd/nerdctl/v2/pkg/reflectutil"
@@ -49,9 +53,20 @@ type Options struct {
 	VolumeExists     func(string) (bool, error)
 	ImageExists      func(ctx context.Context, imageName string) (bool, error)
 	EnsureImage      func(ctx context.Context, imageName, pullMode, platform string, ps *serviceparser.Service, quiet bool) error
+	ResolveDigest    func(ctx context.Context, imageName string) (string, error)
 	DebugPrintFull   bool // full debug print, may leak secret env var to logs
 	Experimental     bool // enable experimental features
 	IPFSAddress      string
+	// Parallel is the maximum number of the concurrent operations, such as pulling images and stopping services.
+	// Zero or a negative value means no limit.
+	Parallel int
+	// Offline disables the registry access (`nerdctl --offline`). The services that would require the network
+	// are reported before creating anything.
+	Offline bool
+	// Stdout and Stderr receive the output of the nerdctl commands run by the composer,
+	// such as `nerdctl build` and the attached containers. They default to os.Stdout and os.Stderr.
+	Stdout io.Writer
+	Stderr io.Writer
 }
 
 func New(o Options, client *containerd.Client) (*Composer, error) {
@@ -130,6 +145,20 @@ type Composer struct {
 	client  *containerd.Client
 }
 
+func (c *Composer) stdout() io.Writer {
+	if c.Stdout == nil {
+		return os.Stdout
+	}
+	return c.Stdout
+}
+
+func (c *Composer) stderr() io.Writer {
+	if c.Stderr == nil {
+		return os.Stderr
+	}
+	return c.Stderr
+}
+
 func (c *Composer) createNerdctlCmd(ctx context.Context, args ...string) *exec.Cmd {
 	return exec.CommandContext(ctx, c.NerdctlCmd, append(c.NerdctlArgs, args...)...)
 }
@@ -145,6 +174,39 @@ func (c *Composer) runNerdctlCmd(ctx context.Context, args ...string) error {
 	return nil
 }
 
+// runNerdctlCmdTagged runs nerdctl with the output prefixed with the tag, so that the output of
+// concurrent commands can be interleaved line by line.
+func (c *Composer) runNerdctlCmdTagged(ctx context.Context, tag string, tagWidth int, args ...string) error {
+	cmd := c.createNerdctlCmd(ctx, args...)
+	if c.DebugPrintFull {
+		log.G(ctx).Debugf("Running %v", cmd.Args)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		pipetagger.New(c.stdout(), stdout, tag, tagWidth, false).Run()
+	}()
+	go func() {
+		defer wg.Done()
+		pipetagger.New(c.stderr(), stderr, tag, tagWidth, false).Run()
+	}()
+	// the pipes must be drained before calling Wait
+	wg.Wait()
+	return cmd.Wait()
+}
+
 // Services returns the parsed Service objects in dependency order.
 func (c *Composer) Services(ctx context.Context, svcs ...string) ([]*serviceparser.Service, error) {
 	var services []*serviceparser.Service
@@ -19,10 +19,14 @@ package composer
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/log"
 
+	"github.com/containerd/nerdctl/v2/pkg/composer/serviceparser"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 )
 
@@ -43,6 +47,56 @@ func (c *Composer) Containers(ctx context.Context, services ...string) ([]contai
 	return containers, nil
 }
 
+// ServiceContainer returns the container of the service with the index (starting from 1), like `--index` of
+// `docker compose exec`. The index is the number at the end of the container name (e.g., 2 for "project-svc-2").
+func (c *Composer) ServiceContainer(ctx context.Context, service string, index int) (containerd.Container, error) {
+	containers, err := c.Containers(ctx, service)
+	if err != nil {
+		return nil, fmt.Errorf("fail to get containers for service %s: %w", service, err)
+	}
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("no running containers from service %s", service)
+	}
+	for _, container := range containers {
+		if containerIndex(ctx, container) == index {
+			return container, nil
+		}
+	}
+	return nil, fmt.Errorf("index (%d) out of range: no such instance in the %d running instances from service %s",
+		index, len(containers), service)
+}
+
+// sortContainersByIndex sorts the containers by their indexes (see ServiceContainer).
+func sortContainersByIndex(ctx context.Context, containers []containerd.Container) {
+	indexes := make(map[string]int, len(containers))
+	for _, container := range containers {
+		indexes[container.ID()] = containerIndex(ctx, container)
+	}
+	sort.SliceStable(containers, func(i, j int) bool {
+		return indexes[containers[i].ID()] < indexes[containers[j].ID()]
+	})
+}
+
+// containerIndex returns the index of the container, parsed from the end of the name.
+// The index of a container named with `container_name` is 1, as such a service cannot be scaled.
+func containerIndex(ctx context.Context, container containerd.Container) int {
+	containerLabels, err := container.Labels(ctx)
+	if err != nil {
+		log.G(ctx).WithError(err).Debugf("failed to get the labels of container %s", container.ID())
+		return 0
+	}
+	prefix := serviceparser.DefaultContainerName(containerLabels[labels.ComposeProject], containerLabels[labels.ComposeService], "")
+	suffix, ok := strings.CutPrefix(containerLabels[labels.Name], prefix)
+	if !ok {
+		return 1
+	}
+	index, err := strconv.Atoi(suffix)
+	if err != nil {
+		return 1
+	}
+	return index
+}
+
 func (c *Composer) containerExists(ctx context.Context, name, service string) (bool, error) {
 	// get list of containers for service
 	containers, err := c.Containers(ctx, service)
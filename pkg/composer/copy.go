@@ -35,6 +35,7 @@ type CopyOptions struct {
 	Destination string
 	Index       int
 	FollowLink  bool
+	Archive     bool
 	DryRun      bool
 }
 
@@ -77,6 +78,9 @@ func (c *Composer) Copy(ctx context.Context, co CopyOptions) error {
 		if co.FollowLink {
 			args = append(args, "--follow-link")
 		}
+		if co.Archive {
+			args = append(args, "--archive")
+		}
 		if direction == fromService {
 			args = append(args, fmt.Sprintf("%s:%s", container.ID(), srcPath), dstPath)
 		}
@@ -120,23 +124,22 @@ func (c *Composer) listContainersTargetedForCopy(ctx context.Context, index int,
 	var containers []containerd.Container
 	var err error
 
-	containers, err = c.Containers(ctx, serviceName)
-	if err != nil {
-		return nil, err
-	}
-
 	if index > 0 {
-		if index > len(containers) {
-			return nil, fmt.Errorf("index (%d) out of range: only %d running instances from service %s",
-				index, len(containers), serviceName)
+		container, err := c.ServiceContainer(ctx, serviceName, index)
+		if err != nil {
+			return nil, err
 		}
-		container := containers[index-1]
 		return []containerd.Container{container}, nil
 	}
 
+	containers, err = c.Containers(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
 	if len(containers) < 1 {
 		return nil, fmt.Errorf("no container found for service %q", serviceName)
 	}
+	sortContainersByIndex(ctx, containers)
 	if direction == fromService {
 		return containers[:1], err
 
@@ -118,6 +118,9 @@ func (c *Composer) Create(ctx context.Context, opt CreateOptions, services []str
 	if err != nil {
 		return err
 	}
+	if err := c.checkOffline(ctx, parsedServices, !opt.NoBuild, opt.Build, ""); err != nil {
+		return err
+	}
 	for _, ps := range parsedServices {
 		if err := c.ensureServiceImage(ctx, ps, !opt.NoBuild, opt.Build, BuildOptions{}, false, ""); err != nil {
 			return err
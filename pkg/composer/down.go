@@ -19,35 +19,36 @@ package composer
 import (
 	"context"
 	"fmt"
+	"time"
 
-	"github.com/containerd/log"
+	"github.com/compose-spec/compose-go/v2/graph"
+	"github.com/compose-spec/compose-go/v2/types"
 
-	"github.com/containerd/nerdctl/v2/pkg/strutil"
+	"github.com/containerd/log"
 )
 
 type DownOptions struct {
 	RemoveVolumes bool
 	RemoveOrphans bool
+	// Timeout overrides the stop timeouts of the services (`stop_grace_period`)
+	Timeout *uint
 }
 
 func (c *Composer) Down(ctx context.Context, downOptions DownOptions) error {
-	serviceNames, err := c.ServiceNames()
-	if err != nil {
-		return err
-	}
-	// reverse dependency order
-	for _, svc := range strutil.ReverseStrSlice(serviceNames) {
-		containers, err := c.Containers(ctx, svc)
+	// The services are torn down in reverse dependency order: a service is stopped after all the services
+	// depending on it, and the independent services are stopped concurrently, up to Options.Parallel.
+	err := graph.InDependencyOrder(ctx, c.project, func(ctx context.Context, name string, svc types.ServiceConfig) error {
+		containers, err := c.Containers(ctx, name)
 		if err != nil {
 			return err
 		}
-		// use default Options to stop service containers.
-		if err := c.stopContainers(ctx, containers, StopOptions{}); err != nil {
-			return err
-		}
-		if err := c.removeContainers(ctx, containers, RemoveOptions{Stop: true, Volumes: downOptions.RemoveVolumes}); err != nil {
+		if err := c.stopContainers(ctx, containers, StopOptions{Timeout: stopTimeout(svc, downOptions.Timeout)}); err != nil {
 			return err
 		}
+		return c.removeContainers(ctx, containers, RemoveOptions{Stop: true, Volumes: downOptions.RemoveVolumes})
+	}, graph.InReverseOrder, graph.WithMaxConcurrency(c.Parallel))
+	if err != nil {
+		return err
 	}
 
 	// remove orphan containers
@@ -86,6 +87,16 @@ func (c *Composer) Down(ctx context.Context, downOptions DownOptions) error {
 	return nil
 }
 
+// stopTimeout returns the stop timeout of the service: override if set, or `stop_grace_period`.
+// nil is returned when neither is set, so that the stop timeout of the container is used.
+func stopTimeout(svc types.ServiceConfig, override *uint) *uint {
+	if override != nil || svc.StopGracePeriod == nil {
+		return override
+	}
+	timeout := uint(time.Duration(*svc.StopGracePeriod).Seconds())
+	return &timeout
+}
+
 func (c *Composer) downNetwork(ctx context.Context, shortName string) error {
 	net, ok := c.project.Networks[shortName]
 	if !ok {
@@ -20,15 +20,9 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"sort"
-	"strconv"
-	"strings"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/log"
-
-	"github.com/containerd/nerdctl/v2/pkg/composer/serviceparser"
-	"github.com/containerd/nerdctl/v2/pkg/labels"
 )
 
 // ExecOptions stores options passed from users as flags and args.
@@ -49,32 +43,11 @@ type ExecOptions struct {
 // Exec executes a given command on a running container specified by
 // `ServiceName` (and `Index` if it has multiple instances).
 func (c *Composer) Exec(ctx context.Context, eo ExecOptions) error {
-	containers, err := c.Containers(ctx, eo.ServiceName)
+	container, err := c.ServiceContainer(ctx, eo.ServiceName, eo.Index)
 	if err != nil {
-		return fmt.Errorf("fail to get containers for service %s: %w", eo.ServiceName, err)
-	}
-	if len(containers) == 0 {
-		return fmt.Errorf("no running containers from service %s", eo.ServiceName)
-	}
-	if eo.Index > len(containers) {
-		return fmt.Errorf("index (%d) out of range: only %d running instances from service %s",
-			eo.Index, len(containers), eo.ServiceName)
-	}
-	if len(containers) == 1 {
-		return c.exec(ctx, containers[0], eo)
+		return err
 	}
-	// The order of the containers is not consistently ascending
-	// we need to re-sort them.
-	sort.SliceStable(containers, func(i, j int) bool {
-		infoI, _ := containers[i].Info(ctx, containerd.WithoutRefreshedMetadata)
-		infoJ, _ := containers[j].Info(ctx, containerd.WithoutRefreshedMetadata)
-		segsI := strings.Split(infoI.Labels[labels.Name], serviceparser.Separator)
-		segsJ := strings.Split(infoJ.Labels[labels.Name], serviceparser.Separator)
-		indexI, _ := strconv.Atoi(segsI[len(segsI)-1])
-		indexJ, _ := strconv.Atoi(segsJ[len(segsJ)-1])
-		return indexI < indexJ
-	})
-	return c.exec(ctx, containers[eo.Index-1], eo)
+	return c.exec(ctx, container, eo)
 }
 
 // exec constructs/executes the `nerdctl exec` command to be executed on the given container.
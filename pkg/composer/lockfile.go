@@ -0,0 +1,257 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package composer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/compose-spec/compose-go/v2/types"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
+)
+
+// LockFileName is the name of the lockfile written by `nerdctl compose lock` in the project directory.
+const LockFileName = "compose.lock.json"
+
+const lockFileVersion = 1
+
+// LockFile pins the images of the services to digests.
+type LockFile struct {
+	Version  int                    `json:"version"`
+	Services map[string]LockedImage `json:"services"`
+}
+
+// LockedImage is the image of a service resolved by `nerdctl compose lock`.
+type LockedImage struct {
+	// Image is the image reference in the compose file.
+	Image string `json:"image"`
+	// Digest is the digest the reference resolved to, i.e., the digest of the index for multi-platform images.
+	Digest string `json:"digest"`
+}
+
+// Pinned returns the reference of the image pinned to the digest, e.g., "nginx@sha256:...".
+func (l LockedImage) Pinned() (string, error) {
+	parsed, err := referenceutil.Parse(l.Image)
+	if err != nil {
+		return "", err
+	}
+	return parsed.FamiliarName() + "@" + l.Digest, nil
+}
+
+// LockFilePath returns path, or the default lockfile of the project when path is empty.
+func (c *Composer) LockFilePath(path string) string {
+	if path == "" {
+		return filepath.Join(c.project.WorkingDir, LockFileName)
+	}
+	return path
+}
+
+// ReadLockFile reads the lockfile at path.
+func ReadLockFile(path string) (*LockFile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var lf LockFile
+	if err := json.Unmarshal(b, &lf); err != nil {
+		return nil, fmt.Errorf("failed to parse lockfile %q: %w", path, err)
+	}
+	if lf.Version != lockFileVersion {
+		return nil, fmt.Errorf("unsupported lockfile version %d in %q", lf.Version, path)
+	}
+	if lf.Services == nil {
+		lf.Services = make(map[string]LockedImage)
+	}
+	return &lf, nil
+}
+
+func writeLockFile(path string, lf *LockFile) error {
+	b, err := json.MarshalIndent(lf, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// lockable returns whether the image of the service can be pinned to a digest.
+// The images built by compose, and the images on IPFS, are not pulled from a registry.
+func lockable(svc *types.ServiceConfig) bool {
+	if svc.Build != nil || svc.Image == "" {
+		return false
+	}
+	parsed, err := referenceutil.Parse(svc.Image)
+	return err == nil && parsed.Protocol == ""
+}
+
+// resolveImage returns the digest of the image of the service, resolving the reference unless it is already a digest.
+func (c *Composer) resolveImage(ctx context.Context, image string) (string, error) {
+	parsed, err := referenceutil.Parse(image)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Digest != "" {
+		return parsed.Digest.String(), nil
+	}
+	if c.ResolveDigest == nil {
+		return "", errors.New("got empty ResolveDigest function")
+	}
+	return c.ResolveDigest(ctx, image)
+}
+
+type LockOptions struct {
+	// LockFile is the path of the lockfile, the default lockfile of the project if empty.
+	LockFile string
+}
+
+// LockImages resolves the images of the services to digests, and writes them to the lockfile.
+// When services are specified, the other services in an existing lockfile are kept as is.
+func (c *Composer) LockImages(ctx context.Context, lo LockOptions, services []string) error {
+	path := c.LockFilePath(lo.LockFile)
+	lf := &LockFile{
+		Version:  lockFileVersion,
+		Services: make(map[string]LockedImage),
+	}
+	if len(services) > 0 {
+		existing, err := ReadLockFile(path)
+		if err == nil {
+			lf = existing
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	if err := c.project.ForEachService(services, func(name string, svc *types.ServiceConfig) error {
+		if !lockable(svc) {
+			log.G(ctx).Infof("Skipping service %s: the image is not pulled from a registry", name)
+			delete(lf.Services, name)
+			return nil
+		}
+		dgst, err := c.resolveImage(ctx, svc.Image)
+		if err != nil {
+			return fmt.Errorf("service %s: failed to resolve image %s: %w", name, svc.Image, err)
+		}
+		log.G(ctx).Infof("Locked image %s of service %s to %s", svc.Image, name, dgst)
+		lf.Services[name] = LockedImage{
+			Image:  svc.Image,
+			Digest: dgst,
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return writeLockFile(path, lf)
+}
+
+// pinImage replaces the image of the service with the one pinned in the lockfile at path.
+// It fails if the service is missing from the lockfile, or if its image was changed after locking.
+func pinImage(lf *LockFile, path string, svc *types.ServiceConfig) error {
+	if !lockable(svc) {
+		return nil
+	}
+	locked, ok := lf.Services[svc.Name]
+	if !ok {
+		return fmt.Errorf("service %s is not in lockfile %q (hint: run `nerdctl compose lock`)", svc.Name, path)
+	}
+	if locked.Image != svc.Image {
+		return fmt.Errorf("image of service %s was changed from %s to %s after locking (hint: run `nerdctl compose lock`)", svc.Name, locked.Image, svc.Image)
+	}
+	pinned, err := locked.Pinned()
+	if err != nil {
+		return err
+	}
+	svc.Image = pinned
+	return nil
+}
+
+// Outdated statuses of the images.
+const (
+	OutdatedStatusUpToDate  = "up-to-date"
+	OutdatedStatusOutdated  = "outdated"
+	OutdatedStatusNotLocked = "not-locked"
+	OutdatedStatusChanged   = "changed"
+)
+
+// OutdatedEntry reports whether the locked image of a service differs from the upstream one.
+type OutdatedEntry struct {
+	Service string
+	Image   string
+	// Locked is the digest in the lockfile, empty if the service is not locked.
+	Locked string
+	// Latest is the digest the image reference currently resolves to.
+	Latest string
+	Status string
+}
+
+type OutdatedOptions struct {
+	// LockFile is the path of the lockfile, the default lockfile of the project if empty.
+	LockFile string
+}
+
+// Outdated compares the lockfile with the digests the image references currently resolve to.
+func (c *Composer) Outdated(ctx context.Context, oo OutdatedOptions, services []string) ([]OutdatedEntry, error) {
+	path := c.LockFilePath(oo.LockFile)
+	lf, err := ReadLockFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile (hint: run `nerdctl compose lock`): %w", err)
+	}
+
+	var entries []OutdatedEntry
+	if err := c.project.ForEachService(services, func(name string, svc *types.ServiceConfig) error {
+		if !lockable(svc) {
+			return nil
+		}
+		latest, err := c.resolveImage(ctx, svc.Image)
+		if err != nil {
+			return fmt.Errorf("service %s: failed to resolve image %s: %w", name, svc.Image, err)
+		}
+		entry := OutdatedEntry{
+			Service: name,
+			Image:   svc.Image,
+			Latest:  latest,
+		}
+		locked, ok := lf.Services[name]
+		switch {
+		case !ok:
+			entry.Status = OutdatedStatusNotLocked
+		case locked.Image != svc.Image:
+			entry.Locked = locked.Digest
+			entry.Status = OutdatedStatusChanged
+		case locked.Digest != latest:
+			entry.Locked = locked.Digest
+			entry.Status = OutdatedStatusOutdated
+		default:
+			entry.Locked = locked.Digest
+			entry.Status = OutdatedStatusUpToDate
+		}
+		entries = append(entries, entry)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
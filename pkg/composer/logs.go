@@ -18,6 +18,7 @@ package composer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -42,6 +43,8 @@ type LogsOptions struct {
 	NoColor              bool
 	NoLogPrefix          bool
 	LatestRun            bool
+	// Index selects the container of the service, like ExecOptions.Index. All the containers are selected when 0.
+	Index int
 }
 
 func (c *Composer) Logs(ctx context.Context, lo LogsOptions, services []string) error {
@@ -53,6 +56,20 @@ func (c *Composer) Logs(ctx context.Context, lo LogsOptions, services []string)
 		return err
 	}
 
+	if lo.Index > 0 {
+		if len(services) != 1 {
+			return errors.New("--index requires exactly one service to be selected")
+		}
+		if _, err := c.project.GetService(services[0]); err != nil {
+			return err
+		}
+		container, err := c.ServiceContainer(ctx, services[0], lo.Index)
+		if err != nil {
+			return err
+		}
+		return c.logs(ctx, []containerd.Container{container}, lo)
+	}
+
 	var serviceNames []string
 	err := c.project.ForEachService(services, func(name string, svc *types.ServiceConfig) error {
 		serviceNames = append(serviceNames, svc.Name)
@@ -132,12 +149,12 @@ func (c *Composer) logs(ctx context.Context, containers []containerd.Container,
 		if lo.NoLogPrefix {
 			logWidth = -1
 		}
-		stdoutTagger := pipetagger.New(os.Stdout, stdout, state.logTag, logWidth, lo.NoColor)
+		stdoutTagger := pipetagger.New(c.stdout(), stdout, state.logTag, logWidth, lo.NoColor)
 		stderr, err := state.logCmd.StderrPipe()
 		if err != nil {
 			return err
 		}
-		stderrTagger := pipetagger.New(os.Stderr, stderr, state.logTag, logWidth, lo.NoColor)
+		stderrTagger := pipetagger.New(c.stderr(), stderr, state.logTag, logWidth, lo.NoColor)
 		if c.DebugPrintFull {
 			log.G(ctx).Debugf("Running %v", state.logCmd.Args)
 		}
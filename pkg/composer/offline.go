@@ -0,0 +1,77 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package composer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/containerd/nerdctl/v2/pkg/composer/serviceparser"
+)
+
+// checkOffline returns an error listing all the services that would require the network to ensure their images,
+// when the offline mode is enabled. The arguments are the same as ensureServiceImage.
+func (c *Composer) checkOffline(ctx context.Context, parsedServices []*serviceparser.Service, allowBuild, forceBuild bool, pullModeArg string) error {
+	if !c.Offline {
+		return nil
+	}
+	var reasons []string
+	for _, ps := range parsedServices {
+		reason, err := c.offlineReason(ctx, ps, allowBuild, forceBuild, pullModeArg)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			reasons = append(reasons, fmt.Sprintf("  %s: %s", ps.Unparsed.Name, reason))
+		}
+	}
+	return offlineError(reasons)
+}
+
+// offlineReason returns why ensuring the image of the service would require the network, or "" if it would not.
+func (c *Composer) offlineReason(ctx context.Context, ps *serviceparser.Service, allowBuild, forceBuild bool, pullModeArg string) (string, error) {
+	if ps.Build != nil && allowBuild && (ps.Build.Force || forceBuild) {
+		return fmt.Sprintf("builds image %s", ps.Image), nil
+	}
+	exists, err := c.ImageExists(ctx, ps.Image)
+	if err != nil {
+		return "", err
+	}
+	if !exists && ps.Build != nil && allowBuild {
+		return fmt.Sprintf("builds image %s, which is not available locally", ps.Image), nil
+	}
+	pullMode := pullModeArg
+	if pullMode == "" {
+		pullMode = ps.PullMode
+	}
+	switch {
+	case pullMode == "always":
+		return fmt.Sprintf("pulls image %s (pull policy %q)", ps.Image, pullMode), nil
+	case !exists && pullMode != "never":
+		return fmt.Sprintf("pulls image %s, which is not available locally", ps.Image), nil
+	}
+	return "", nil
+}
+
+func offlineError(reasons []string) error {
+	if len(reasons) == 0 {
+		return nil
+	}
+	return fmt.Errorf("the following services require network access, which is disabled in the offline mode (--offline):\n%s",
+		strings.Join(reasons, "\n"))
+}
@@ -22,6 +22,7 @@ import (
 	"os"
 
 	"github.com/compose-spec/compose-go/v2/types"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/containerd/log"
 
@@ -33,18 +34,78 @@ type PullOptions struct {
 }
 
 func (c *Composer) Pull(ctx context.Context, po PullOptions, services []string) error {
-	return c.project.ForEachService(services, func(name string, svc *types.ServiceConfig) error {
+	var toPull []*serviceparser.Service
+	if err := c.project.ForEachService(services, func(name string, svc *types.ServiceConfig) error {
 		ps, err := serviceparser.Parse(c.project, *svc)
 		if err != nil {
 			return err
 		}
-		return c.pullServiceImage(ctx, ps.Image, ps.Unparsed.Platform, ps, po)
-	})
+		if ps.PullMode == "never" {
+			// pull_policy "never" and "build"
+			log.G(ctx).Infof("Skipping image %s of service %s (pull_policy %q)", ps.Image, name, svc.PullPolicy)
+			return nil
+		}
+		toPull = append(toPull, ps)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if c.Offline && len(toPull) > 0 {
+		var reasons []string
+		for _, ps := range toPull {
+			reasons = append(reasons, fmt.Sprintf("  %s: pulls image %s", ps.Unparsed.Name, ps.Image))
+		}
+		return offlineError(reasons)
+	}
+
+	if len(toPull) <= 1 || c.Parallel == 1 {
+		for _, ps := range toPull {
+			if err := c.pullServiceImage(ctx, ps.Image, ps.Unparsed.Platform, ps, po); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// The output of the concurrent pulls is interleaved line by line, prefixed with the service names.
+	tagWidth := 0
+	for _, ps := range toPull {
+		tagWidth = max(tagWidth, len(ps.Unparsed.Name))
+	}
+	eg, ctx := errgroup.WithContext(ctx)
+	if c.Parallel > 0 {
+		eg.SetLimit(c.Parallel)
+	}
+	for _, ps := range toPull {
+		eg.Go(func() error {
+			log.G(ctx).Infof("Pulling image %s", ps.Image)
+			if err := c.runNerdctlCmdTagged(ctx, ps.Unparsed.Name, tagWidth+1, c.pullArgs(ps.Image, ps.Unparsed.Platform, ps, po)...); err != nil {
+				return fmt.Errorf("error while pulling image %s: %w", ps.Image, err)
+			}
+			return nil
+		})
+	}
+	return eg.Wait()
 }
 
 func (c *Composer) pullServiceImage(ctx context.Context, image string, platform string, ps *serviceparser.Service, po PullOptions) error {
 	log.G(ctx).Infof("Pulling image %s", image)
 
+	cmd := c.createNerdctlCmd(ctx, c.pullArgs(image, platform, ps, po)...)
+	if c.DebugPrintFull {
+		log.G(ctx).Debugf("Running %v", cmd.Args)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = c.stdout()
+	cmd.Stderr = c.stderr()
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error while pulling image %s: %w", image, err)
+	}
+	return nil
+}
+
+func (c *Composer) pullArgs(image string, platform string, ps *serviceparser.Service, po PullOptions) []string {
 	var args []string // nolint: prealloc
 	if platform != "" {
 		args = append(args, "--platform="+platform)
@@ -76,16 +137,5 @@ func (c *Composer) pullServiceImage(ctx context.Context, image string, platform
 	}
 
 	args = append(args, image)
-
-	cmd := c.createNerdctlCmd(ctx, append([]string{"pull"}, args...)...)
-	if c.DebugPrintFull {
-		log.G(ctx).Debugf("Running %v", cmd.Args)
-	}
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("error while pulling image %s: %w", image, err)
-	}
-	return nil
+	return append([]string{"pull"}, args...)
 }
@@ -65,8 +65,8 @@ func (c *Composer) pushServiceImage(ctx context.Context, image string, platform
 		log.G(ctx).Debugf("Running %v", cmd.Args)
 	}
 	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stdout = c.stdout()
+	cmd.Stderr = c.stderr()
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("error while pushing image %s: %w", image, err)
 	}
@@ -52,7 +52,7 @@ func (c *Composer) restartContainers(ctx context.Context, containers []container
 	var timeoutArg string
 	if opt.Timeout != nil {
 		// `nerdctl restart` uses `--time` instead of `--timeout`
-		timeoutArg = fmt.Sprintf("--time=%d", *opt.Timeout)
+		timeoutArg = fmt.Sprintf("--timeout=%d", *opt.Timeout)
 	}
 
 	var rsWG sync.WaitGroup
@@ -213,6 +213,9 @@ func (c *Composer) runServices(ctx context.Context, parsedServices []*servicepar
 		return errors.New("no service was provided")
 	}
 
+	if err := c.checkOffline(ctx, parsedServices, !ro.NoBuild, ro.ForceBuild, ""); err != nil {
+		return err
+	}
 	// TODO: parallelize loop for ensuring images (make sure not to mess up tty)
 	for _, ps := range parsedServices {
 		if err := c.ensureServiceImage(ctx, ps, !ro.NoBuild, ro.ForceBuild, BuildOptions{}, ro.QuietPull, ""); err != nil {
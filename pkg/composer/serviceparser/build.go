@@ -19,7 +19,9 @@ package serviceparser
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/compose-spec/compose-go/v2/types"
@@ -32,9 +34,9 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/reflectutil"
 )
 
-func parseBuildConfig(c *types.BuildConfig, project *types.Project, imageName string) (*Build, error) {
+func parseBuildConfig(c *types.BuildConfig, project *types.Project, imageName, platform string) (*Build, error) {
 	if unknown := reflectutil.UnknownNonEmptyFields(c,
-		"Context", "Dockerfile", "Args", "CacheFrom", "Target", "Labels", "Secrets", "AdditionalContexts",
+		"Context", "Dockerfile", "Args", "CacheFrom", "CacheTo", "Target", "Labels", "Secrets", "AdditionalContexts", "Platforms",
 	); len(unknown) > 0 {
 		log.L.Warnf("Ignoring: build: %+v", unknown)
 	}
@@ -60,8 +62,17 @@ func parseBuildConfig(c *types.BuildConfig, project *types.Project, imageName st
 		}
 	}
 
-	for k, v := range c.Args {
-		if v == nil {
+	// https://github.com/compose-spec/compose-spec/blob/master/build.md#platforms
+	// (compose-go validates that build.platforms includes the service platform)
+	switch {
+	case len(c.Platforms) > 0:
+		b.BuildArgs = append(b.BuildArgs, "--platform="+strings.Join(c.Platforms, ","))
+	case platform != "":
+		b.BuildArgs = append(b.BuildArgs, "--platform="+platform)
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(c.Args)) {
+		if v := c.Args[k]; v == nil {
 			b.BuildArgs = append(b.BuildArgs, "--build-arg="+k)
 		} else {
 			b.BuildArgs = append(b.BuildArgs, "--build-arg="+k+"="+*v)
@@ -72,18 +83,20 @@ func parseBuildConfig(c *types.BuildConfig, project *types.Project, imageName st
 		b.BuildArgs = append(b.BuildArgs, "--cache-from="+s)
 	}
 
-	for k, v := range c.AdditionalContexts {
-		b.BuildArgs = append(b.BuildArgs, "--build-context="+k+"="+v)
+	for _, s := range c.CacheTo {
+		b.BuildArgs = append(b.BuildArgs, "--cache-to="+s)
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(c.AdditionalContexts)) {
+		b.BuildArgs = append(b.BuildArgs, "--build-context="+k+"="+c.AdditionalContexts[k])
 	}
 
 	if c.Target != "" {
 		b.BuildArgs = append(b.BuildArgs, "--target="+c.Target)
 	}
 
-	if c.Labels != nil {
-		for k, v := range c.Labels {
-			b.BuildArgs = append(b.BuildArgs, "--label="+k+"="+v)
-		}
+	for _, k := range slices.Sorted(maps.Keys(c.Labels)) {
+		b.BuildArgs = append(b.BuildArgs, "--label="+k+"="+c.Labels[k])
 	}
 
 	for _, s := range c.Secrets {
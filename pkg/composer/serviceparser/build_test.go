@@ -18,6 +18,7 @@ package serviceparser
 
 import (
 	"runtime"
+	"strings"
 	"testing"
 
 	"gotest.tools/v3/assert"
@@ -96,3 +97,52 @@ secrets:
 	assert.Assert(t, in(bar.Build.BuildArgs, "--secret=id=simple_secret,src="+secretPath+"/test_secret2"))
 	assert.Assert(t, in(bar.Build.BuildArgs, "--secret=id=absolute_secret,src=/tmp/absolute_secret"))
 }
+
+func TestParseBuildOptions(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("test is not compatible with windows")
+	}
+
+	const dockerComposeYAML = `
+services:
+  foo:
+    platform: linux/arm64
+    build:
+      context: ./fooctx
+      args:
+        B: b
+        A: a
+      cache_from:
+        - type=local,src=/tmp/cache
+      cache_to:
+        - type=local,dest=/tmp/cache,mode=max
+      platforms:
+        - linux/amd64
+        - linux/arm64
+`
+	comp := testutil.NewComposeDir(t, dockerComposeYAML)
+	defer comp.CleanUp()
+
+	project, err := testutil.LoadProject(comp.YAMLFullPath(), comp.ProjectName(), nil)
+	assert.NilError(t, err)
+
+	fooSvc, err := project.GetService("foo")
+	assert.NilError(t, err)
+
+	foo, err := Parse(project, fooSvc)
+	assert.NilError(t, err)
+
+	t.Logf("foo: %+v", foo)
+	assert.Assert(t, in(foo.Build.BuildArgs, "--platform=linux/amd64,linux/arm64"))
+	assert.Assert(t, in(foo.Build.BuildArgs, "--cache-from=type=local,src=/tmp/cache"))
+	assert.Assert(t, in(foo.Build.BuildArgs, "--cache-to=type=local,dest=/tmp/cache,mode=max"))
+	var buildArgs []string
+	for _, a := range foo.Build.BuildArgs {
+		if strings.HasPrefix(a, "--build-arg=") {
+			buildArgs = append(buildArgs, a)
+		}
+	}
+	assert.DeepEqual(t, buildArgs, []string{"--build-arg=A=a", "--build-arg=B=b"})
+}
@@ -31,9 +31,11 @@ import (
 	"github.com/compose-spec/compose-go/v2/types"
 
 	"github.com/containerd/containerd/v2/contrib/nvidia"
+	"github.com/containerd/go-cni"
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/identifiers"
+	"github.com/containerd/nerdctl/v2/pkg/portutil"
 	"github.com/containerd/nerdctl/v2/pkg/reflectutil"
 )
 
@@ -201,11 +203,14 @@ type Build struct {
 }
 
 type Service struct {
-	Image      string
-	PullMode   string
-	Containers []Container // length = replicas
-	Build      *Build
-	Unparsed   *types.ServiceConfig
+	Image    string
+	PullMode string
+	// PullRefresh is the interval of pulling the image again, for `pull_policy: daily`, `weekly`, and `every_<duration>`.
+	// PullMode is "missing" when PullRefresh is set, and the image is pulled when it is older than PullRefresh.
+	PullRefresh time.Duration
+	Containers  []Container // length = replicas
+	Build       *Build
+	Unparsed    *types.ServiceConfig
 }
 
 func getReplicas(svc types.ServiceConfig) (int, error) {
@@ -432,7 +437,7 @@ func Parse(project *types.Project, svc types.ServiceConfig) (*Service, error) {
 		if parsed.Image == "" {
 			parsed.Image = DefaultImageName(project.Name, svc.Name)
 		}
-		parsed.Build, err = parseBuildConfig(svc.Build, project, parsed.Image)
+		parsed.Build, err = parseBuildConfig(svc.Build, project, parsed.Image, svc.Platform)
 		if err != nil {
 			return nil, fmt.Errorf("service %s: failed to parse build: %w", svc.Name, err)
 		}
@@ -450,7 +455,15 @@ func Parse(project *types.Project, svc types.ServiceConfig) (*Service, error) {
 		parsed.Build.Force = true
 		parsed.PullMode = "never"
 	default:
-		log.L.Warnf("Ignoring: service %s: pull_policy: %q", svc.Name, svc.PullPolicy)
+		policy, refresh, err := svc.GetPullPolicy()
+		if err != nil {
+			return nil, fmt.Errorf("service %s: invalid pull_policy %q: %w", svc.Name, svc.PullPolicy, err)
+		}
+		if policy == types.PullPolicyRefresh {
+			parsed.PullRefresh = refresh
+		} else {
+			log.L.Warnf("Ignoring: service %s: pull_policy: %q", svc.Name, svc.PullPolicy)
+		}
 	}
 
 	for i := 0; i < replicas; i++ {
@@ -545,8 +558,9 @@ func newContainer(project *types.Project, parsed *Service, i int) (*Container, e
 		}
 	}
 
-	if svc.Init != nil && *svc.Init {
-		c.RunArgs = append(c.RunArgs, "--init")
+	if svc.Init != nil {
+		// `init: false` is passed too, to override `init` in nerdctl.toml
+		c.RunArgs = append(c.RunArgs, fmt.Sprintf("--init=%t", *svc.Init))
 	}
 
 	if memLimit, err := getMemLimit(svc); err != nil {
@@ -769,6 +783,27 @@ func servicePortConfigToFlagP(c types.ServicePortConfig) (string, error) {
 	return s, nil
 }
 
+// PublishedPorts returns the port mappings of the service that have explicit host ports.
+// Ports without a published host port are allocated on `nerdctl run`, and are not included.
+func PublishedPorts(svc types.ServiceConfig) ([]cni.PortMapping, error) {
+	var res []cni.PortMapping
+	for _, p := range svc.Ports {
+		if p.Published == "" {
+			continue
+		}
+		pStr, err := servicePortConfigToFlagP(p)
+		if err != nil {
+			return nil, err
+		}
+		mappings, err := portutil.ParseFlagP(pStr)
+		if err != nil {
+			return nil, fmt.Errorf("service %s: invalid port %q: %w", svc.Name, pStr, err)
+		}
+		res = append(res, mappings...)
+	}
+	return res, nil
+}
+
 func serviceVolumeConfigToFlagV(c types.ServiceVolumeConfig, project *types.Project) (flagV string, mkdir []string, err error) {
 	if unknown := reflectutil.UnknownNonEmptyFields(&c,
 		"Type",
@@ -23,6 +23,7 @@ import (
 	"runtime"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/compose-spec/compose-go/v2/types"
 	"gotest.tools/v3/assert"
@@ -586,3 +587,49 @@ services:
 	c = getContainersFromService("unless_stopped")[0]
 	assert.Assert(t, in(c.RunArgs, "--restart=unless-stopped"))
 }
+
+func TestParsePullPolicyAndInit(t *testing.T) {
+	t.Parallel()
+	const dockerComposeYAML = `
+services:
+  foo:
+    image: nginx:alpine
+    pull_policy: daily
+    init: true
+  bar:
+    image: nginx:alpine
+    pull_policy: every_2h
+    init: false
+  baz:
+    image: nginx:alpine
+    pull_policy: always
+`
+	comp := testutil.NewComposeDir(t, dockerComposeYAML)
+	defer comp.CleanUp()
+
+	project, err := testutil.LoadProject(comp.YAMLFullPath(), comp.ProjectName(), nil)
+	assert.NilError(t, err)
+
+	for _, tc := range []struct {
+		service     string
+		pullMode    string
+		pullRefresh time.Duration
+		initArg     string
+	}{
+		{"foo", "missing", 24 * time.Hour, "--init=true"},
+		{"bar", "missing", 2 * time.Hour, "--init=false"},
+		{"baz", "always", 0, ""},
+	} {
+		svc, err := project.GetService(tc.service)
+		assert.NilError(t, err)
+		ps, err := Parse(project, svc)
+		assert.NilError(t, err)
+		assert.Equal(t, ps.PullMode, tc.pullMode, tc.service)
+		assert.Equal(t, ps.PullRefresh, tc.pullRefresh, tc.service)
+		if tc.initArg != "" {
+			assert.Assert(t, in(ps.Containers[0].RunArgs, tc.initArg), tc.service)
+		} else {
+			assert.Assert(t, !in(ps.Containers[0].RunArgs, "--init=true") && !in(ps.Containers[0].RunArgs, "--init=false"), tc.service)
+		}
+	}
+}
@@ -58,7 +58,7 @@ func (c *Composer) stopContainers(ctx context.Context, containers []containerd.C
 	var timeoutArg string
 	if opt.Timeout != nil {
 		// `nerdctl stop` uses `--time` instead of `--timeout`
-		timeoutArg = fmt.Sprintf("--time=%d", *opt.Timeout)
+		timeoutArg = fmt.Sprintf("--timeout=%d", *opt.Timeout)
 	}
 
 	var rmWG sync.WaitGroup
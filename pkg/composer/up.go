@@ -43,6 +43,10 @@ type UpOptions struct {
 	NoRecreate           bool
 	Scale                map[string]int // map of service name to replicas
 	Pull                 string
+	// Locked pins the images to the digests in the lockfile written by `nerdctl compose lock`
+	Locked bool
+	// LockFile is the path of the lockfile, the default lockfile of the project if empty
+	LockFile string
 }
 
 func (opts UpOptions) recreateStrategy() string {
@@ -83,6 +87,16 @@ func (c *Composer) Up(ctx context.Context, uo UpOptions, services []string) erro
 		}
 	}
 
+	var lockFile *LockFile
+	lockFilePath := c.LockFilePath(uo.LockFile)
+	if uo.Locked {
+		var err error
+		lockFile, err = ReadLockFile(lockFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to read lockfile (hint: run `nerdctl compose lock`): %w", err)
+		}
+	}
+
 	var parsedServices []*serviceparser.Service
 	// use WithServices to sort the services in dependency order
 	if err := c.project.ForEachService(services, func(name string, svc *types.ServiceConfig) error {
@@ -92,6 +106,11 @@ func (c *Composer) Up(ctx context.Context, uo UpOptions, services []string) erro
 			}
 			svc.Deploy.Replicas = &replicas
 		}
+		if lockFile != nil {
+			if err := pinImage(lockFile, lockFilePath, svc); err != nil {
+				return err
+			}
+		}
 		ps, err := serviceparser.Parse(c.project, *svc)
 		if err != nil {
 			return err
@@ -102,6 +121,10 @@ func (c *Composer) Up(ctx context.Context, uo UpOptions, services []string) erro
 		return err
 	}
 
+	if err := c.checkPortConflicts(ctx, parsedServices); err != nil {
+		return err
+	}
+
 	// remove orphan containers before the service has be started
 	// FYI: https://github.com/docker/compose/blob/v2.3.4/pkg/compose/create.go#L91-L112
 	orphans, err := c.getOrphanContainers(ctx, parsedServices)
@@ -0,0 +1,160 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package composer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/go-cni"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/composer/serviceparser"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/portutil"
+)
+
+// portBinding is a host port bound by a service or by an existing container.
+type portBinding struct {
+	owner   string
+	mapping cni.PortMapping
+}
+
+func (b portBinding) String() string {
+	return fmt.Sprintf("%s/%s", net.JoinHostPort(b.mapping.HostIP, fmt.Sprint(b.mapping.HostPort)), b.mapping.Protocol)
+}
+
+// checkPortConflicts validates the published host ports of the services before creating any container,
+// so that `compose up` does not fail midway through the startup.
+// The host ports are checked against each other, and against the running containers that are not replaced by `compose up`.
+// All the conflicts are reported at once.
+func (c *Composer) checkPortConflicts(ctx context.Context, parsedServices []*serviceparser.Service) error {
+	var bindings []portBinding
+	parsedSvcNames := make(map[string]bool)
+	for _, ps := range parsedServices {
+		parsedSvcNames[ps.Unparsed.Name] = true
+		mappings, err := serviceparser.PublishedPorts(*ps.Unparsed)
+		if err != nil {
+			return err
+		}
+		for i := range ps.Containers {
+			owner := fmt.Sprintf("service %q", ps.Unparsed.Name)
+			if len(ps.Containers) > 1 {
+				owner = fmt.Sprintf("service %q (replica %d)", ps.Unparsed.Name, i+1)
+			}
+			for _, m := range mappings {
+				bindings = append(bindings, portBinding{owner: owner, mapping: m})
+			}
+		}
+	}
+	if len(bindings) == 0 {
+		return nil
+	}
+
+	existing, err := c.runningPortBindings(ctx, parsedSvcNames)
+	if err != nil {
+		return err
+	}
+
+	var conflicts []string
+	for i, b := range bindings {
+		for _, other := range bindings[:i] {
+			if portBindingsConflict(b.mapping, other.mapping) {
+				conflicts = append(conflicts, fmt.Sprintf("%s: %s and %s", b, other.owner, b.owner))
+			}
+		}
+		for _, other := range existing {
+			if portBindingsConflict(b.mapping, other.mapping) {
+				conflicts = append(conflicts, fmt.Sprintf("%s: %s is already allocated by %s", b, b.owner, other.owner))
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("published ports conflict:\n  %s", strings.Join(conflicts, "\n  "))
+	}
+	return nil
+}
+
+// runningPortBindings returns the host ports bound by the running containers,
+// except the containers of this project that belong to the given services.
+func (c *Composer) runningPortBindings(ctx context.Context, skipServices map[string]bool) ([]portBinding, error) {
+	containers, err := c.client.Containers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var res []portBinding
+	for _, container := range containers {
+		containerLabels, err := container.Labels(ctx)
+		if err != nil {
+			// the container may have been removed concurrently
+			log.G(ctx).WithError(err).Debugf("failed to get the labels of container %s", container.ID())
+			continue
+		}
+		if containerLabels[labels.ComposeProject] == c.project.Name && skipServices[containerLabels[labels.ComposeService]] {
+			continue
+		}
+		if containerLabels[labels.Ports] == "" {
+			continue
+		}
+		status, err := containerutil.ContainerStatus(ctx, container)
+		if err != nil || (status.Status != containerd.Running && status.Status != containerd.Paused) {
+			continue
+		}
+		mappings, err := portutil.ParsePortsLabel(containerLabels)
+		if err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to parse the ports of container %s", container.ID())
+			continue
+		}
+		owner := fmt.Sprintf("container %q", containerLabels[labels.Name])
+		if containerLabels[labels.Name] == "" {
+			owner = fmt.Sprintf("container %s", container.ID())
+		}
+		for _, m := range mappings {
+			res = append(res, portBinding{owner: owner, mapping: m})
+		}
+	}
+	return res, nil
+}
+
+// portBindingsConflict returns whether the two port mappings bind the same host port.
+func portBindingsConflict(a, b cni.PortMapping) bool {
+	if a.HostPort != b.HostPort || !strings.EqualFold(a.Protocol, b.Protocol) {
+		return false
+	}
+	return hostIPsOverlap(a.HostIP, b.HostIP) || hostIPsOverlap(b.HostIP, a.HostIP)
+}
+
+// hostIPsOverlap returns whether binding x overlaps binding y.
+// The unspecified address of IPv4 overlaps all the IPv4 addresses,
+// and the unspecified address of IPv6 overlaps all the addresses.
+func hostIPsOverlap(x, y string) bool {
+	xIP, yIP := net.ParseIP(x), net.ParseIP(y)
+	if xIP == nil || yIP == nil {
+		return x == y
+	}
+	if xIP.Equal(yIP) {
+		return true
+	}
+	if !xIP.IsUnspecified() {
+		return false
+	}
+	return xIP.To4() == nil || yIP.To4() != nil
+}
@@ -25,13 +25,16 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/containerd/errdefs"
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/composer/serviceparser"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
 )
 
 func (c *Composer) upServices(ctx context.Context, parsedServices []*serviceparser.Service, uo UpOptions) error {
@@ -39,6 +42,9 @@ func (c *Composer) upServices(ctx context.Context, parsedServices []*servicepars
 		return errors.New("no service was provided")
 	}
 
+	if err := c.checkOffline(ctx, parsedServices, !uo.NoBuild, uo.ForceBuild, uo.Pull); err != nil {
+		return err
+	}
 	// TODO: parallelize loop for ensuring images (make sure not to mess up tty)
 	for _, ps := range parsedServices {
 		if err := c.ensureServiceImage(ctx, ps, !uo.NoBuild, uo.ForceBuild, BuildOptions{}, uo.QuietPull, uo.Pull); err != nil {
@@ -56,6 +62,9 @@ func (c *Composer) upServices(ctx context.Context, parsedServices []*servicepars
 	for _, ps := range parsedServices {
 		ps := ps
 		var runEG errgroup.Group
+		if c.Parallel > 0 {
+			runEG.SetLimit(c.Parallel)
+		}
 		services = append(services, ps.Unparsed.Name)
 		for _, container := range ps.Containers {
 			container := container
@@ -104,12 +113,12 @@ func (c *Composer) upServices(ctx context.Context, parsedServices []*servicepars
 func (c *Composer) ensureServiceImage(ctx context.Context, ps *serviceparser.Service, allowBuild, forceBuild bool, bo BuildOptions, quiet bool, pullModeArg string) error {
 	if ps.Build != nil && allowBuild {
 		if ps.Build.Force || forceBuild {
-			return c.buildServiceImage(ctx, ps.Image, ps.Build, ps.Unparsed.Platform, bo)
+			return c.buildServiceImage(ctx, ps.Image, ps.Build, bo)
 		}
 		if ok, err := c.ImageExists(ctx, ps.Image); err != nil {
 			return err
 		} else if !ok {
-			return c.buildServiceImage(ctx, ps.Image, ps.Build, ps.Unparsed.Platform, bo)
+			return c.buildServiceImage(ctx, ps.Image, ps.Build, bo)
 		}
 		// even when c.ImageExists returns true, we need to call c.EnsureImage
 		// because ps.PullMode can be "always". So no return here.
@@ -120,7 +129,36 @@ func (c *Composer) ensureServiceImage(ctx context.Context, ps *serviceparser.Ser
 	if pullModeArg != "" {
 		return c.EnsureImage(ctx, ps.Image, pullModeArg, ps.Unparsed.Platform, ps, quiet)
 	}
-	return c.EnsureImage(ctx, ps.Image, ps.PullMode, ps.Unparsed.Platform, ps, quiet)
+	pullMode := ps.PullMode
+	// The refresh is skipped in the offline mode, so the local image is used
+	if ps.PullRefresh > 0 && !c.Offline {
+		outdated, err := c.imageOlderThan(ctx, ps.Image, ps.PullRefresh)
+		if err != nil {
+			return err
+		}
+		if outdated {
+			log.G(ctx).Infof("Image %s is older than %v (pull_policy %q), pulling", ps.Image, ps.PullRefresh, ps.Unparsed.PullPolicy)
+			pullMode = "always"
+		}
+	}
+	return c.EnsureImage(ctx, ps.Image, pullMode, ps.Unparsed.Platform, ps, quiet)
+}
+
+// imageOlderThan returns true if the local image was pulled (or created) earlier than d ago.
+// A missing image is not older, as it is pulled anyway.
+func (c *Composer) imageOlderThan(ctx context.Context, rawRef string, d time.Duration) (bool, error) {
+	parsed, err := referenceutil.Parse(rawRef)
+	if err != nil {
+		return false, err
+	}
+	img, err := c.client.ImageService().Get(ctx, parsed.String())
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return time.Since(img.UpdatedAt) > d, nil
 }
 
 // upServiceContainer must be called after ensureServiceImage
@@ -215,10 +253,10 @@ func (c *Composer) executeUpCmd(ctx context.Context, cmd *exec.Cmd, containerNam
 		cmd.Stdin = os.Stdin
 	}
 	if !runFlagD {
-		cmd.Stdout = os.Stdout
+		cmd.Stdout = c.stdout()
 	}
 	// Always propagate stderr to print detailed error messages (https://github.com/containerd/nerdctl/issues/1942)
-	cmd.Stderr = os.Stderr
+	cmd.Stderr = c.stderr()
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("error while creating container %s: %w", containerName, err)
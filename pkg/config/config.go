@@ -41,9 +41,118 @@ type Config struct {
 	HostGatewayIP    string   `toml:"host_gateway_ip"`
 	BridgeIP         string   `toml:"bridge_ip, omitempty"`
 	KubeHideDupe     bool     `toml:"kube_hide_dupe"`
+	// Offline disables the registry access: the pulls fail, and only the local images are used.
+	Offline bool `toml:"offline,omitempty"`
+	// KubeReadWrite allows the operations that are safe alongside kubelet (e.g., exec, logs, and cp)
+	// in the "k8s.io" namespace. Creating and removing containers there is refused regardless.
+	KubeReadWrite bool `toml:"kube_read_write,omitempty"`
 	// CDISpecDirs is a list of directories in which CDI specifications can be found.
 	CDISpecDirs []string `toml:"cdi_spec_dirs,omitempty"`
 	UsernsRemap string   `toml:"userns_remap, omitempty"`
+	// InsecureRegistries are the registries for which InsecureRegistry is enabled, see MatchRegistry for the syntax.
+	InsecureRegistries []string `toml:"insecure_registries,omitempty"`
+	// PortForwardingBackend is the backend of the CNI "portmap" plugin ("iptables" or "nftables").
+	// Empty means the default of the plugin.
+	PortForwardingBackend string `toml:"port_forwarding_backend,omitempty"`
+	// RootlessKitPortDriver is the port driver of RootlessKit ("builtin", "slirp4netns", or "implicit"),
+	// used for setting up rootless containerd. Empty means the default of containerd-rootless.sh ("builtin").
+	RootlessKitPortDriver string `toml:"rootlesskit_port_driver,omitempty"`
+	// SnapshotterFallback is the snapshotter to fall back to, when the remote snapshotter (e.g., "stargz")
+	// fails to prepare the snapshots of an image. Empty means no fallback.
+	SnapshotterFallback string `toml:"snapshotter_fallback,omitempty"`
+	// TLSCACert, TLSCert, and TLSKey are the CA certificate, the client certificate, and the client key,
+	// for connecting to containerd at a "tcp://" address with (mutual) TLS.
+	TLSCACert string `toml:"tlscacert,omitempty"`
+	TLSCert   string `toml:"tlscert,omitempty"`
+	TLSKey    string `toml:"tlskey,omitempty"`
+	// TLSSPIFFEID and TLSSPIFFETrustDomain restrict the SPIFFE ID of the server certificate of a "tcp://" address.
+	TLSSPIFFEID          string `toml:"tls_spiffe_id,omitempty"`
+	TLSSPIFFETrustDomain string `toml:"tls_spiffe_trust_domain,omitempty"`
+	// GC is the garbage collection policy for `nerdctl system gc`.
+	GC GCConfig `toml:"gc,omitempty"`
+	// Output is the output format of the commands that support it ("json"). Empty means human-readable text.
+	Output string `toml:"output,omitempty"`
+	// Init is the default of `nerdctl run --init`.
+	Init bool `toml:"init,omitempty"`
+	// InitBinary is the default of `nerdctl run --init-binary`. Empty means "tini".
+	InitBinary string `toml:"init_binary,omitempty"`
+	// TZ is the default of `nerdctl run --tz`.
+	TZ string `toml:"tz,omitempty"`
+	// Secret is the configuration of `nerdctl secret`.
+	Secret SecretConfig `toml:"secret,omitempty"`
+	// Registries is the TLS configuration of the registries, by host.
+	Registries map[string]RegistryConfig `toml:"registries,omitempty"`
+	// Credentials is the configuration of the registry credentials stored by `nerdctl login`.
+	Credentials CredentialsConfig `toml:"credentials,omitempty"`
+	// P2P is the configuration of the peer-to-peer blob distribution.
+	P2P P2PConfig `toml:"p2p,omitempty"`
+	// LabelFiles are the files of the labels (in the format of `--label-file`) applied to every container
+	// created and every image built, e.g., for traceability.
+	LabelFiles []string `toml:"label_files,omitempty"`
+	// Labels are the labels applied like LabelFiles, overriding them, e.g., {"com.example.owner" = "team-a"}.
+	Labels map[string]string `toml:"labels,omitempty"`
+	// Presets are the named bundles of the flags of `nerdctl run` and `nerdctl create`, applied with `--preset`,
+	// e.g., {hardened = ["--read-only", "--cap-drop=ALL"]}.
+	Presets map[string][]string `toml:"presets,omitempty"`
+	// Admission is the admission policy of the containers, evaluated before creating them.
+	Admission AdmissionConfig `toml:"admission,omitempty"`
+}
+
+// AdmissionConfig corresponds to the [admission] table of nerdctl.toml .
+type AdmissionConfig struct {
+	// DenyPrivileged rejects the containers with `--privileged`.
+	DenyPrivileged bool `toml:"deny_privileged,omitempty"`
+	// AllowedRegistries restricts the images of the containers to the registries or the repository prefixes,
+	// e.g., "registry.example.com", "docker.io/library".
+	AllowedRegistries []string `toml:"allowed_registries,omitempty"`
+	// Hooks are the executables that admit, mutate, or reject the containers. See docs/admission.md.
+	Hooks []string `toml:"hooks,omitempty"`
+}
+
+// P2PConfig corresponds to the [p2p] table of nerdctl.toml .
+type P2PConfig struct {
+	// Enabled fetches the blobs from the peers before the registries.
+	Enabled bool `toml:"enabled,omitempty"`
+	// Peers is the list of the static peers, e.g., "192.168.1.10:5055".
+	Peers []string `toml:"peers,omitempty"`
+	// MDNS discovers the peers on the LAN with mDNS, in addition to Peers.
+	MDNS bool `toml:"mdns,omitempty"`
+	// Listen is the address of `nerdctl p2p serve`, e.g., ":5055".
+	Listen string `toml:"listen,omitempty"`
+}
+
+// CredentialsConfig corresponds to the [credentials] table of nerdctl.toml .
+type CredentialsConfig struct {
+	// Keychain stores the registry credentials in the keychain of the OS, instead of config.json,
+	// for the registries without a docker credential helper.
+	// The plain text credentials of config.json are moved to the keychain.
+	Keychain bool `toml:"keychain,omitempty"`
+}
+
+// SecretConfig corresponds to the [secret] table of nerdctl.toml .
+type SecretConfig struct {
+	// EncryptCommand encrypts the secrets created with `nerdctl secret create`, from stdin to stdout,
+	// e.g., ["age", "-e", "-r", "age1..."].
+	// Empty means the secrets are stored unencrypted.
+	EncryptCommand []string `toml:"encrypt_command,omitempty"`
+	// DecryptCommand decrypts the secrets encrypted by EncryptCommand, from stdin to stdout,
+	// e.g., ["age", "-d", "-i", "/etc/nerdctl/age.key"].
+	// The command is recorded in the secret on creation.
+	DecryptCommand []string `toml:"decrypt_command,omitempty"`
+}
+
+// GCConfig corresponds to the [gc] table of nerdctl.toml .
+type GCConfig struct {
+	// MaxContentSize is the size budget of the content store, e.g., "10GiB".
+	// Empty means no budget.
+	MaxContentSize string `toml:"max_content_size,omitempty"`
+	// MaxImageAge is the age after which unused images are removed, e.g., "720h".
+	// Empty means no age limit.
+	MaxImageAge string `toml:"max_image_age,omitempty"`
+	// Keep is the list of image reference patterns that are never removed, e.g., "alpine:*".
+	Keep []string `toml:"keep,omitempty"`
+	// Interval is the interval of `nerdctl system gc --schedule`, e.g., "1h".
+	Interval string `toml:"interval,omitempty"`
 }
 
 // New creates a default Config object statically,
@@ -66,5 +175,11 @@ func New() *Config {
 		KubeHideDupe:     false,
 		CDISpecDirs:      ncdefaults.CDISpecDirs(),
 		UsernsRemap:      "",
+		GC: GCConfig{
+			Interval: "1h",
+		},
+		P2P: P2PConfig{
+			Listen: ":5055",
+		},
 	}
 }
@@ -0,0 +1,55 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	dockercliopts "github.com/docker/cli/opts"
+
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
+)
+
+// DefaultLabels returns the labels of LabelFiles and Labels, to be applied to every container created
+// and every image built. The labels of the command line override them.
+//
+// The values are expanded with the environment variables, e.g., "${GIT_COMMIT}".
+// A label whose value is expanded to an empty string is not applied.
+func (c *Config) DefaultLabels() (map[string]string, error) {
+	kvs, err := dockercliopts.ReadKVStrings(c.LabelFiles, nil)
+	if err != nil {
+		return nil, err
+	}
+	m := strutil.ConvertKVStringsToMap(kvs)
+	for k, v := range c.Labels {
+		m[k] = v
+	}
+	for k, v := range m {
+		if strings.HasPrefix(k, labels.Prefix) {
+			return nil, fmt.Errorf("internal label %q must not be specified in nerdctl.toml", k)
+		}
+		if v = os.ExpandEnv(v); v == "" {
+			delete(m, k)
+		} else {
+			m[k] = v
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,55 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestDefaultLabels(t *testing.T) {
+	t.Setenv("TEST_GIT_COMMIT", "0123abc")
+	labelFile := filepath.Join(t.TempDir(), "labels")
+	assert.NilError(t, os.WriteFile(labelFile, []byte("# comment\ncom.example.owner=team-a\ncom.example.host=build-1\n"), 0600))
+
+	c := &Config{
+		LabelFiles: []string{labelFile},
+		Labels: map[string]string{
+			"com.example.host":                  "build-2",
+			"org.opencontainers.image.revision": "${TEST_GIT_COMMIT}",
+			"com.example.unset":                 "${TEST_UNSET}",
+		},
+	}
+	m, err := c.DefaultLabels()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, m, map[string]string{
+		"com.example.owner":                 "team-a",
+		"com.example.host":                  "build-2",
+		"org.opencontainers.image.revision": "0123abc",
+	})
+
+	c = &Config{Labels: map[string]string{"nerdctl/name": "foo"}}
+	_, err = c.DefaultLabels()
+	assert.ErrorContains(t, err, "internal label")
+
+	c = &Config{LabelFiles: []string{filepath.Join(t.TempDir(), "nonexistent")}}
+	_, err = c.DefaultLabels()
+	assert.Assert(t, err != nil)
+}
@@ -0,0 +1,61 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package config
+
+import (
+	"net"
+	"strings"
+)
+
+// RegistryConfig corresponds to a [registries."<HOST>"] table of nerdctl.toml .
+type RegistryConfig struct {
+	// MinTLSVersion is the minimum TLS version accepted from the registry, e.g., "1.2".
+	// Empty means the default of Go.
+	MinTLSVersion string `toml:"min_tls_version,omitempty"`
+	// CADir is a directory in the layout of docker certs.d: the "*.crt" files are CA certificates,
+	// and the "*.cert" files are client certificates, along with the "*.key" files of the same name.
+	CADir string `toml:"ca_dir,omitempty"`
+	// ClientCert is the client certificate file.
+	ClientCert string `toml:"client_cert,omitempty"`
+	// ClientKey is the private key file of ClientCert.
+	ClientKey string `toml:"client_key,omitempty"`
+}
+
+// MatchRegistry returns whether the registry host (e.g., "registry.example.com:5000") matches one of the patterns.
+//
+// A pattern is either a host with a port, which only matches that port,
+// a host without a port, which matches all the ports,
+// or a CIDR (e.g., "10.0.0.0/8"), which matches all the IP addresses of the network.
+func MatchRegistry(patterns []string, host string) bool {
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if _, ipNet, err := net.ParseCIDR(pattern); err == nil {
+			if ip := net.ParseIP(hostname); ip != nil && ipNet.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if pattern == host || pattern == hostname {
+			return true
+		}
+	}
+	return false
+}
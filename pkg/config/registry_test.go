@@ -0,0 +1,42 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestMatchRegistry(t *testing.T) {
+	patterns := []string{"registry.example.com:5000", "mirror.example.com", "10.0.0.0/8", "[fd00::1]:443"}
+
+	assert.Assert(t, MatchRegistry(patterns, "registry.example.com:5000"))
+	assert.Assert(t, !MatchRegistry(patterns, "registry.example.com"))
+	assert.Assert(t, !MatchRegistry(patterns, "registry.example.com:5001"))
+
+	assert.Assert(t, MatchRegistry(patterns, "mirror.example.com"))
+	assert.Assert(t, MatchRegistry(patterns, "mirror.example.com:8443"))
+
+	assert.Assert(t, MatchRegistry(patterns, "10.1.2.3:5000"))
+	assert.Assert(t, MatchRegistry(patterns, "10.1.2.3"))
+	assert.Assert(t, !MatchRegistry(patterns, "192.168.1.1:5000"))
+
+	assert.Assert(t, MatchRegistry(patterns, "[fd00::1]:443"))
+	assert.Assert(t, !MatchRegistry(patterns, "docker.io"))
+	assert.Assert(t, !MatchRegistry(nil, "docker.io"))
+}
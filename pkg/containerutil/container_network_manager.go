@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"reflect"
 	"runtime"
@@ -164,6 +165,8 @@ func NewNetworkingOptionsManager(globalOptions types.GlobalCommandOptions, netOp
 		manager = &containerNetworkManager{globalOptions, netOpts, client}
 	case nettype.CNI:
 		manager = &cniNetworkManager{globalOptions, netOpts, client, cniNetworkManagerPlatform{}}
+	case nettype.Pasta:
+		manager = &pastaNetworkManager{noneNetworkManager{globalOptions, netOpts, client}}
 	case nettype.Namespace:
 		// We'll handle Namespace networking identically to Host-mode networking, but
 		// put the container in the specified network namespace instead of the root.
@@ -273,6 +276,8 @@ func (m *noneNetworkManager) InternalNetworkingOptionLabels(_ context.Context) (
 	opts := m.netOpts
 	// Cannot have a MAC address in host networking mode.
 	opts.MACAddress = ""
+	// There are no ports to publish without a network, like Docker.
+	opts.PublishAll = false
 	return opts, nil
 }
 
@@ -342,6 +347,43 @@ func (m *noneNetworkManager) ContainerNetworkingOpts(_ context.Context, containe
 	return specs, []containerd.NewContainerOpts{}, nil
 }
 
+// types.NetworkOptionsManager implementation for rootless-friendly networking with pasta(1).
+// pasta is started by the OCI hook, so the rest is identical to the none network.
+type pastaNetworkManager struct {
+	noneNetworkManager
+}
+
+// VerifyNetworkOptions Verifies that the internal network settings are correct.
+func (m *pastaNetworkManager) VerifyNetworkOptions(ctx context.Context) error {
+	if len(m.netOpts.NetworkSlice) > 1 {
+		return errors.New("conflicting options: pasta network cannot be combined with other networks")
+	}
+	if _, err := netutil.ParsePastaNetwork(m.netOpts.NetworkSlice[0]); err != nil {
+		return err
+	}
+	nonZeroArgs := nonZeroMapValues(map[string]interface{}{
+		"--ip":          m.netOpts.IPAddress,
+		"--ip6":         m.netOpts.IP6Address,
+		"--mac-address": m.netOpts.MACAddress,
+		"--allow-from":  strings.Join(m.netOpts.AllowFrom, ","),
+	})
+	if len(nonZeroArgs) != 0 {
+		return fmt.Errorf("conflicting options: the following arguments are not supported with the pasta network: %s", nonZeroArgs)
+	}
+	if _, err := exec.LookPath("pasta"); err != nil {
+		return fmt.Errorf("pasta needs to be installed for --network=pasta (e.g., the \"passt\" package): %w", err)
+	}
+	return m.noneNetworkManager.VerifyNetworkOptions(ctx)
+}
+
+// InternalNetworkingOptionLabels Returns the set of NetworkingOptions which should be set as labels on the container.
+func (m *pastaNetworkManager) InternalNetworkingOptionLabels(_ context.Context) (types.NetworkOptions, error) {
+	opts := m.netOpts
+	// pasta copies the MAC address of the host interface.
+	opts.MACAddress = ""
+	return opts, nil
+}
+
 // types.NetworkOptionsManager implementation for container networking settings.
 type containerNetworkManager struct {
 	globalOptions types.GlobalCommandOptions
@@ -371,9 +413,13 @@ func (m *containerNetworkManager) VerifyNetworkOptions(_ context.Context) error
 		"--hostname":   m.netOpts.Hostname,
 		"--domainname": m.netOpts.Domainname,
 		// NOTE: an empty slice still counts as a non-zero value so we check its length:
-		"-p/--publish": len(m.netOpts.PortMappings) != 0,
-		"--dns":        len(m.netOpts.DNSServers) != 0,
-		"--add-host":   len(m.netOpts.AddHost) != 0,
+		"-p/--publish":        len(m.netOpts.PortMappings) != 0,
+		"-P/--publish-all":    m.netOpts.PublishAll,
+		"--network-bandwidth": m.netOpts.NetworkBandwidth,
+		"--network-delay":     m.netOpts.NetworkDelay,
+		"--allow-from":        len(m.netOpts.AllowFrom) != 0,
+		"--dns":               len(m.netOpts.DNSServers) != 0,
+		"--add-host":          len(m.netOpts.AddHost) != 0,
 	})
 
 	if len(nonZeroParams) != 0 {
@@ -617,6 +663,8 @@ func (m *hostNetworkManager) InternalNetworkingOptionLabels(_ context.Context) (
 	opts := m.netOpts
 	// Cannot have a MAC address in host networking mode.
 	opts.MACAddress = ""
+	// The exposed ports are already reachable in host networking mode, like Docker.
+	opts.PublishAll = false
 	return opts, nil
 }
 
@@ -0,0 +1,297 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package containerutil
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/pkg/oci"
+	"github.com/containerd/go-cni"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+	"github.com/containerd/nerdctl/v2/pkg/ocihook"
+	"github.com/containerd/nerdctl/v2/pkg/portutil/pf"
+	"github.com/containerd/nerdctl/v2/pkg/resolvconf"
+)
+
+// parentJailAnnotation is the annotation for the runtime to create the container jail as
+// a child of the jail. The container jail then shares the vnet of the parent jail.
+const parentJailAnnotation = "org.freebsd.parentJail"
+
+type cniNetworkManagerPlatform struct {
+}
+
+// vnetJailName returns the name of the vnet jail holding the network stack of the container,
+// i.e., the counterpart of the network namespace on Linux.
+func vnetJailName(containerID string) string {
+	return "nerdctl-" + containerID
+}
+
+// Verifies that the internal network settings are correct.
+func (m *cniNetworkManager) VerifyNetworkOptions(_ context.Context) error {
+	e, err := netutil.NewCNIEnv(m.globalOptions.CNIPath, m.globalOptions.CNINetConfPath, netutil.WithNamespace(m.globalOptions.Namespace), netutil.WithDefaultNetwork(m.globalOptions.BridgeIP))
+	if err != nil {
+		return err
+	}
+
+	// NOTE: only currently supported network type on FreeBSD is bridge:
+	validNetworkTypes := []string{"bridge"}
+	if _, err := verifyNetworkTypes(e, m.netOpts.NetworkSlice, validNetworkTypes); err != nil {
+		return err
+	}
+
+	for _, p := range m.netOpts.PortMappings {
+		if p.Protocol != "tcp" && p.Protocol != "udp" {
+			return fmt.Errorf("protocol %q is not supported for publishing ports on FreeBSD", p.Protocol)
+		}
+	}
+
+	nonZeroArgs := nonZeroMapValues(map[string]interface{}{
+		"--mac-address": m.netOpts.MACAddress,
+		"--ip6":         m.netOpts.IP6Address,
+		// NOTE: zero-length slices count as a non-zero-value so we explicitly check length:
+		"--add-host": len(m.netOpts.AddHost) != 0,
+	})
+	if len(nonZeroArgs) != 0 {
+		return fmt.Errorf("the following networking arguments are not supported on FreeBSD: %+v", nonZeroArgs)
+	}
+
+	return validateUtsSettings(m.netOpts)
+}
+
+func (m *cniNetworkManager) getCNI() (cni.CNI, error) {
+	e, err := netutil.NewCNIEnv(m.globalOptions.CNIPath, m.globalOptions.CNINetConfPath, netutil.WithNamespace(m.globalOptions.Namespace), netutil.WithDefaultNetwork(m.globalOptions.BridgeIP))
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate CNI env: %w", err)
+	}
+
+	cniOpts := []cni.Opt{
+		cni.WithPluginDir([]string{m.globalOptions.CNIPath}),
+		cni.WithPluginConfDir(m.globalOptions.CNINetConfPath),
+	}
+
+	if netMap, err := verifyNetworkTypes(e, m.netOpts.NetworkSlice, nil); err == nil {
+		for _, netConf := range netMap {
+			cniOpts = append(cniOpts, cni.WithConfListBytes(netConf.Bytes))
+		}
+	} else {
+		return nil, err
+	}
+
+	return cni.New(cniOpts...)
+}
+
+// Performs setup actions required for the container with the given ID.
+// The vnet jail is created and attached to the networks, and the ports are published with pf.
+func (m *cniNetworkManager) SetupNetworking(ctx context.Context, containerID string) (retErr error) {
+	cniObj, err := m.getCNI()
+	if err != nil {
+		return fmt.Errorf("failed to get container networking for setup: %w", err)
+	}
+
+	jail := vnetJailName(containerID)
+	if err := createVnetJail(jail); err != nil {
+		return err
+	}
+	defer func() {
+		if retErr != nil {
+			if err := removeVnetJail(jail); err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to remove the vnet jail %q", jail)
+			}
+		}
+	}()
+
+	result, err := cniObj.Setup(ctx, containerID, jail, m.getCNINamespaceOpts()...)
+	if err != nil {
+		return err
+	}
+
+	if len(m.netOpts.PortMappings) == 0 {
+		return nil
+	}
+	rules, err := pf.RedirectRules(m.netOpts.PortMappings, containerIPFromResult(result))
+	if err != nil {
+		return err
+	}
+	if err := pf.Load(pf.Anchor(containerID), rules); err != nil {
+		return fmt.Errorf("failed to publish the ports (hint: enable pf with `rdr-anchor \"%s/*\"` in pf.conf): %w", pf.RootAnchor, err)
+	}
+	return nil
+}
+
+// Performs any required cleanup actions for the given container.
+// Should only be called to revert any setup steps performed in setupNetworking.
+func (m *cniNetworkManager) CleanupNetworking(ctx context.Context, container containerd.Container) error {
+	containerID := container.ID()
+	cniObj, err := m.getCNI()
+	if err != nil {
+		return fmt.Errorf("failed to get container networking for cleanup: %w", err)
+	}
+
+	spec, err := container.Spec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get container specs for networking cleanup: %w", err)
+	}
+
+	jail, found := spec.Annotations[ocihook.NetworkNamespace]
+	if !found {
+		return fmt.Errorf("no %q annotation present on container with ID %s", ocihook.NetworkNamespace, containerID)
+	}
+
+	if len(m.netOpts.PortMappings) != 0 {
+		if err := pf.Flush(pf.Anchor(containerID)); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to unpublish the ports of container %q", containerID)
+		}
+	}
+	if err := cniObj.Remove(ctx, containerID, jail, m.getCNINamespaceOpts()...); err != nil {
+		return err
+	}
+	return removeVnetJail(jail)
+}
+
+// Returns the set of NetworkingOptions which should be set as labels on the container.
+func (m *cniNetworkManager) InternalNetworkingOptionLabels(_ context.Context) (types.NetworkOptions, error) {
+	return m.netOpts, nil
+}
+
+// Returns a slice of `oci.SpecOpts` and `containerd.NewContainerOpts` which represent
+// the network specs which need to be applied to the container with the given ID.
+func (m *cniNetworkManager) ContainerNetworkingOpts(_ context.Context, containerID string) ([]oci.SpecOpts, []containerd.NewContainerOpts, error) {
+	dataStore, err := clientutil.DataStore(m.globalOptions.DataRoot, m.globalOptions.Address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stateDir, err := ContainerStateDirPath(m.globalOptions.Namespace, dataStore, containerID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resolvConfPath := filepath.Join(stateDir, "resolv.conf")
+	dns, dnsSearch, dnsOptions, err := fetchDNSResolverConfig(m.netOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := resolvconf.Build(resolvConfPath, dns, dnsSearch, dnsOptions); err != nil {
+		return nil, nil, err
+	}
+
+	jail := vnetJailName(containerID)
+	opts := []oci.SpecOpts{
+		withCustomResolvConf(resolvConfPath),
+		oci.WithAnnotations(map[string]string{parentJailAnnotation: jail}),
+	}
+
+	if m.netOpts.UTSNamespace != UtsNamespaceHost {
+		// If no hostname is set, default to first 12 characters of the container ID.
+		hostname := m.netOpts.Hostname
+		if hostname == "" {
+			hostname = containerID
+			if len(hostname) > 12 {
+				hostname = hostname[0:12]
+			}
+		}
+		m.netOpts.Hostname = hostname
+
+		hostnameOpts, err := writeEtcHostnameForContainer(m.globalOptions, m.netOpts.Hostname, containerID)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, hostnameOpts...)
+		if m.netOpts.Domainname != "" {
+			opts = append(opts, oci.WithDomainname(m.netOpts.Domainname))
+		}
+	}
+
+	cOpts := []containerd.NewContainerOpts{
+		containerd.WithAdditionalContainerLabels(
+			map[string]string{
+				ocihook.NetworkNamespace: jail,
+			},
+		),
+	}
+
+	return opts, cOpts, nil
+}
+
+// Returns the []cni.NamespaceOpts to be used for CNI setup/teardown.
+// The ports are not passed to CNI, as they are published with pf.
+func (m *cniNetworkManager) getCNINamespaceOpts() []cni.NamespaceOpts {
+	opts := []cni.NamespaceOpts{
+		cni.WithLabels(map[string]string{
+			// allow loose CNI argument verification
+			// FYI: https://github.com/containernetworking/cni/issues/560
+			"IgnoreUnknown": "1",
+		}),
+	}
+
+	if m.netOpts.IPAddress != "" {
+		opts = append(opts, cni.WithArgs("IP", m.netOpts.IPAddress))
+	}
+
+	return opts
+}
+
+// containerIPFromResult returns the first IP address assigned to an interface in the jail,
+// preferring IPv4.
+func containerIPFromResult(result *cni.Result) net.IP {
+	var ip6 net.IP
+	for _, iface := range result.Interfaces {
+		if iface.Sandbox == "" {
+			continue
+		}
+		for _, ipConf := range iface.IPConfigs {
+			if ipConf.IP.To4() != nil {
+				return ipConf.IP
+			}
+			if ip6 == nil {
+				ip6 = ipConf.IP
+			}
+		}
+	}
+	return ip6
+}
+
+// createVnetJail creates a persistent jail with its own vnet, to be the parent of the container jail.
+func createVnetJail(name string) error {
+	cmd := exec.Command("jail", "-c", "name="+name, "vnet", "children.max=1", "persist")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create the vnet jail %q: %w (output=%q)", name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// removeVnetJail removes the vnet jail. It is a no-op if the jail does not exist.
+func removeVnetJail(name string) error {
+	if err := exec.Command("jls", "-j", name, "jid").Run(); err != nil {
+		return nil
+	}
+	cmd := exec.Command("jail", "-r", name)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to remove the vnet jail %q: %w (output=%q)", name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -19,8 +19,12 @@ package containerutil
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io/fs"
 	"path/filepath"
+	"slices"
+
+	"github.com/containernetworking/cni/libcni"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/pkg/oci"
@@ -39,8 +43,11 @@ type cniNetworkManagerPlatform struct {
 }
 
 // Verifies that the internal network settings are correct.
-func (m *cniNetworkManager) VerifyNetworkOptions(_ context.Context) error {
-	e, err := netutil.NewCNIEnv(m.globalOptions.CNIPath, m.globalOptions.CNINetConfPath, netutil.WithNamespace(m.globalOptions.Namespace), netutil.WithDefaultNetwork(m.globalOptions.BridgeIP))
+func (m *cniNetworkManager) VerifyNetworkOptions(ctx context.Context) error {
+	e, err := netutil.NewCNIEnv(m.globalOptions.CNIPath, m.globalOptions.CNINetConfPath,
+		netutil.WithNamespace(m.globalOptions.Namespace),
+		netutil.WithPortForwardingBackend(m.globalOptions.PortForwardingBackend),
+		netutil.WithDefaultNetwork(m.globalOptions.BridgeIP))
 	if err != nil {
 		return err
 	}
@@ -52,9 +59,52 @@ func (m *cniNetworkManager) VerifyNetworkOptions(_ context.Context) error {
 		}
 	}
 
+	shaping, err := netutil.NewShaping(m.netOpts.NetworkBandwidth, m.netOpts.NetworkDelay)
+	if err != nil {
+		return err
+	}
+	if shaping != nil && shaping.Bandwidth > 0 {
+		netConfigs, err := verifyNetworkTypes(e, m.netOpts.NetworkSlice, nil)
+		if err != nil {
+			return err
+		}
+		for name, netConfig := range netConfigs {
+			if !slices.ContainsFunc(netConfig.Plugins, func(p *libcni.PluginConfig) bool { return p.Network.Type == "bandwidth" }) {
+				return fmt.Errorf("network %q does not support --network-bandwidth (CNI plugin \"bandwidth\" was not installed when the network was created)", name)
+			}
+		}
+	}
+
+	if _, err := netutil.ParseAllowFrom(m.netOpts.AllowFrom); err != nil {
+		return err
+	}
+
+	if len(m.netOpts.PortMappings) > 0 && m.globalOptions.RootlessKitPortDriver != "" && rootlessutil.IsRootlessChild() {
+		warnRootlessKitPortDriverMismatch(ctx, m.globalOptions.RootlessKitPortDriver)
+	}
+
 	return validateUtsSettings(m.netOpts)
 }
 
+// warnRootlessKitPortDriverMismatch warns if RootlessKit is running with a port driver other than the configured one,
+// as the port driver cannot be changed without restarting rootless containerd.
+func warnRootlessKitPortDriverMismatch(ctx context.Context, portDriver string) {
+	client, err := rootlessutil.NewRootlessKitClient()
+	if err != nil {
+		log.G(ctx).WithError(err).Debug("failed to create the RootlessKit client")
+		return
+	}
+	info, err := client.Info(ctx)
+	if err != nil || info.PortDriver == nil {
+		log.G(ctx).WithError(err).Debug("failed to get the port driver of RootlessKit")
+		return
+	}
+	if info.PortDriver.Driver != portDriver {
+		log.G(ctx).Warnf("RootlessKit is running with the port driver %q, not %q (rootlesskit_port_driver). "+
+			"Run `nerdctl system rootless setup --force && systemctl --user restart containerd` to apply the configuration", info.PortDriver.Driver, portDriver)
+	}
+}
+
 // Performs setup actions required for the container with the given ID.
 func (m *cniNetworkManager) SetupNetworking(_ context.Context, _ string) error {
 	// NOTE: on non-Windows systems which support OCI hooks, CNI networking setup
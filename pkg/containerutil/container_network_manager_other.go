@@ -1,4 +1,4 @@
-//go:build !(linux || windows)
+//go:build !(linux || windows || freebsd)
 
 /*
    Copyright The containerd Authors.
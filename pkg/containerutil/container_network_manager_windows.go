@@ -43,10 +43,22 @@ func (m *cniNetworkManager) VerifyNetworkOptions(_ context.Context) error {
 
 	// NOTE: only currently supported network type on Windows is nat:
 	validNetworkTypes := []string{"nat"}
-	if _, err := verifyNetworkTypes(e, m.netOpts.NetworkSlice, validNetworkTypes); err != nil {
+	netConfigs, err := verifyNetworkTypes(e, m.netOpts.NetworkSlice, validNetworkTypes)
+	if err != nil {
 		return err
 	}
 
+	// Port mappings are published through HNS NAT policies, which the nat plugin
+	// only creates when the network advertises the "portMappings" capability.
+	// Networks created by older versions of nerdctl lack it.
+	if len(m.netOpts.PortMappings) != 0 {
+		for name, netConfig := range netConfigs {
+			if !netConfig.Plugins[0].Network.Capabilities["portMappings"] {
+				return fmt.Errorf("network %q does not support port publishing (-p/--publish), recreate it with `nerdctl network rm %s && nerdctl network create %s`", name, name, name)
+			}
+		}
+	}
+
 	nonZeroArgs := nonZeroMapValues(map[string]interface{}{
 		"--hostname":   m.netOpts.Hostname,
 		"--domainname": m.netOpts.Domainname,
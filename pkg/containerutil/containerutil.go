@@ -50,8 +50,11 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/ipcutil"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/labels/k8slabels"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil/volumestore"
+	"github.com/containerd/nerdctl/v2/pkg/ocihook/state"
 	"github.com/containerd/nerdctl/v2/pkg/portutil"
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+	"github.com/containerd/nerdctl/v2/pkg/secretstore"
 	"github.com/containerd/nerdctl/v2/pkg/signalutil"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 	"github.com/containerd/nerdctl/v2/pkg/taskutil"
@@ -129,6 +132,40 @@ func UpdateExplicitlyStoppedLabel(ctx context.Context, container containerd.Cont
 	return container.Update(ctx, containerd.UpdateContainerOpts(opt))
 }
 
+// MountNetworkVolumes mounts the NFS and CIFS volumes of the container, unless already mounted by other containers.
+// The volumes are unmounted by the OCI hook when the last container using them stops.
+func MountNetworkVolumes(container containerd.Container, lab map[string]string) error {
+	mountsJSON := lab[labels.Mounts]
+	if mountsJSON == "" {
+		return nil
+	}
+	var mounts []struct {
+		Type   string
+		Source string
+	}
+	if err := json.Unmarshal([]byte(mountsJSON), &mounts); err != nil {
+		return err
+	}
+	for _, m := range mounts {
+		if m.Type != "volume" {
+			continue
+		}
+		if err := volumestore.MountNetworkVolume(m.Source, container.ID()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StageSecrets writes the secrets of `nerdctl run --secret` again, as they do not survive a reboot of the host.
+func StageSecrets(lab map[string]string) error {
+	cs, err := secretstore.DecodeContainerSecrets(lab[labels.Secrets])
+	if err != nil || cs == nil {
+		return err
+	}
+	return cs.Stage(lab[labels.Namespace])
+}
+
 // UpdateErrorLabel updates the "nerdctl/error"
 // label of the container according to the container error.
 func UpdateErrorLabel(ctx context.Context, container containerd.Container, err error) error {
@@ -237,6 +274,14 @@ func Start(ctx context.Context, container containerd.Container, flagA bool, flag
 		return err
 	}
 
+	if err := MountNetworkVolumes(container, lab); err != nil {
+		return err
+	}
+
+	if err := StageSecrets(lab); err != nil {
+		return err
+	}
+
 	process, err := container.Spec(ctx)
 	if err != nil {
 		return err
@@ -261,6 +306,9 @@ func Start(ctx context.Context, container containerd.Container, flagA bool, flag
 		log.G(ctx).Warnf("container %s is already running", container.ID())
 		return nil
 	}
+	if err := EnsureNotPaused(ctx, container, "start"); err != nil {
+		return err
+	}
 
 	_, restartPolicyExist := lab[restart.PolicyLabel]
 	if restartPolicyExist {
@@ -289,9 +337,18 @@ func Start(ctx context.Context, container containerd.Container, flagA bool, flag
 		return err
 	}
 
+	if err := RunHooks(ctx, container, lab, HookStart); err != nil {
+		if _, deleteErr := task.Delete(ctx); deleteErr != nil {
+			log.G(ctx).WithError(deleteErr).Debug("failed to delete task")
+		}
+		return err
+	}
 	if err := task.Start(ctx); err != nil {
 		return err
 	}
+	if err := RunHooks(ctx, container, lab, HookPostStart); err != nil {
+		log.G(ctx).WithError(err).Warn("post-start hook failed")
+	}
 	if !flagA {
 		return nil
 	}
@@ -299,9 +356,11 @@ func Start(ctx context.Context, container containerd.Container, flagA bool, flag
 		if err := consoleutil.HandleConsoleResize(ctx, task, con); err != nil {
 			log.G(ctx).WithError(err).Error("console resize")
 		}
+	} else {
+		// Like `nerdctl run`, the signals are not proxied to a TTY.
+		sigc := signalutil.ForwardAllSignals(ctx, task)
+		defer signalutil.StopCatch(sigc)
 	}
-	sigc := signalutil.ForwardAllSignals(ctx, task)
-	defer signalutil.StopCatch(sigc)
 
 	statusC, err := task.Wait(ctx)
 	if err != nil {
@@ -400,13 +459,20 @@ func Stop(ctx context.Context, container containerd.Container, timeout *time.Dur
 	default:
 	}
 
+	if status.Status == containerd.Running {
+		if err := RunHooks(ctx, container, l, HookPreStop); err != nil {
+			log.G(ctx).WithError(err).Warn("pre-stop hook failed")
+		}
+	}
+
 	// NOTE: ctx is main context so that it's ok to use for task.Wait().
 	exitCh, err := task.Wait(ctx)
 	if err != nil {
 		return err
 	}
 
-	if *timeout > 0 {
+	// A negative timeout waits for the container to stop indefinitely, without SIGKILL.
+	if *timeout != 0 {
 		sig, err := getSignal(signalValue, l)
 		if err != nil {
 			return err
@@ -426,8 +492,12 @@ func Stop(ctx context.Context, container containerd.Container, timeout *time.Dur
 			}
 		}
 
-		sigtermCtx, sigtermCtxCancel := context.WithTimeout(ctx, *timeout)
-		defer sigtermCtxCancel()
+		sigtermCtx := ctx
+		if *timeout > 0 {
+			var sigtermCtxCancel context.CancelFunc
+			sigtermCtx, sigtermCtxCancel = context.WithTimeout(ctx, *timeout)
+			defer sigtermCtxCancel()
+		}
 
 		err = waitContainerStop(sigtermCtx, exitCh, container.ID())
 		if err == nil {
@@ -504,7 +574,14 @@ func Pause(ctx context.Context, client *containerd.Client, id string) error {
 	case containerd.Created, containerd.Stopped:
 		return fmt.Errorf("container %s is not running", id)
 	default:
-		return task.Pause(ctx)
+		if err := checkPausable(ctx, container, task); err != nil {
+			return err
+		}
+		if err := task.Pause(ctx); err != nil {
+			return err
+		}
+		updatePausedState(ctx, container, true)
+		return nil
 	}
 }
 
@@ -527,12 +604,66 @@ func Unpause(ctx context.Context, client *containerd.Client, id string) error {
 
 	switch status.Status {
 	case containerd.Paused:
-		return task.Resume(ctx)
+		if err := task.Resume(ctx); err != nil {
+			return err
+		}
+		updatePausedState(ctx, container, false)
+		return nil
 	default:
 		return fmt.Errorf("container %s is not paused", id)
 	}
 }
 
+// EnsureNotPaused returns an error if the container is paused, so that the operations that need
+// its processes to run, such as `nerdctl exec`, fail with a clear error instead of hanging.
+func EnsureNotPaused(ctx context.Context, container containerd.Container, operation string) error {
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		// No task, no frozen processes.
+		return nil
+	}
+	status, err := task.Status(ctx)
+	if err != nil {
+		return err
+	}
+	switch status.Status {
+	case containerd.Paused, containerd.Pausing:
+		return fmt.Errorf("container %s is paused, unpause the container before %s", container.ID(), operation)
+	}
+	return nil
+}
+
+// EnsureNotImmutable returns an error if the container was created with `--immutable`,
+// so that the operations that modify the container (e.g., exec) are forbidden.
+func EnsureNotImmutable(ctx context.Context, container containerd.Container, operation string) error {
+	containerLabels, err := container.Labels(ctx)
+	if err != nil {
+		return err
+	}
+	if containerLabels[labels.Immutable] == "true" {
+		return fmt.Errorf("container %s is immutable, %s is not allowed", container.ID(), operation)
+	}
+	return nil
+}
+
+// updatePausedState records the paused state in the lifecycle state, for `nerdctl ps`.
+func updatePausedState(ctx context.Context, container containerd.Container, paused bool) {
+	containerLabels, err := container.Labels(ctx)
+	if err != nil || containerLabels[labels.StateDir] == "" {
+		return
+	}
+	lf, err := state.New(containerLabels[labels.StateDir])
+	if err == nil {
+		err = lf.Transform(func(lf *state.Store) error {
+			lf.Paused = paused
+			return nil
+		})
+	}
+	if err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to record the paused state of container %s", container.ID())
+	}
+}
+
 // ContainerStateDirPath returns the path to the Nerdctl-managed state directory for the container with the given ID.
 func ContainerStateDirPath(ns, dataStore, id string) (string, error) {
 	return filepath.Join(dataStore, "containers", ns, id), nil
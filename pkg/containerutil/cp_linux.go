@@ -27,9 +27,12 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/opencontainers/runtime-spec/specs-go"
+
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/containers"
 	"github.com/containerd/containerd/v2/core/mount"
+	"github.com/containerd/containerd/v2/pkg/oci"
 	"github.com/containerd/errdefs"
 	"github.com/containerd/log"
 
@@ -78,26 +81,22 @@ func getRoot(ctx context.Context, container containerd.Container) (string, int,
 	return fmt.Sprintf("/proc/%d/root", pid), pid, nil
 }
 
-// CopyFiles implements `nerdctl cp`
-// It currently depends on the following assumptions:
-// - linux only
-// - tar binary exists on the system
-// - nsenter binary exists on the system
-// - if rootless, the container is running (aka: /proc/pid/root)
-func CopyFiles(ctx context.Context, client *containerd.Client, container containerd.Container, options types.ContainerCpOptions) (err error) {
-	// We do rely on the tar binary as a shortcut - could also be replaced by archive/tar, though that would mean
-	// we need to replace nsenter calls with re-exec
-	tarBinary, isGNUTar, err := tarutil.FindTarBinary()
-	if err != nil {
-		return err
-	}
-
-	log.G(ctx).Debugf("Detected tar binary %q (GNU=%v)", tarBinary, isGNUTar)
+// containerRoot is the location of the filesystem of a container on the host
+type containerRoot struct {
+	spec *oci.Spec
+	// root is /proc/pid/root for a running container, or the mounted snapshot otherwise
+	root string
+	// pid is 0 when the container is not running
+	pid int
+}
 
+// openContainerRoot tentatively returns the root of the running container, and otherwise mounts its snapshot.
+// The returned cleanup function must be called when it is not nil.
+func openContainerRoot(ctx context.Context, client *containerd.Client, container containerd.Container, snapshotter string) (*containerRoot, func() error, error) {
 	// This can happen if the container being passed has been deleted since in a racy way
 	conSpec, err := container.Spec(ctx)
 	if err != nil {
-		return errors.Join(ErrContainerVanished, err)
+		return nil, nil, errors.Join(ErrContainerVanished, err)
 	}
 
 	// Try to get a running container root
@@ -105,11 +104,12 @@ func CopyFiles(ctx context.Context, client *containerd.Client, container contain
 	// If the task is "not found" (for example, if the container stopped), we will try to mount the snapshot
 	// Any other type of error from Task() is fatal here.
 	if err != nil && !errdefs.IsNotFound(err) {
-		return errors.Join(ErrContainerVanished, err)
+		return nil, nil, errors.Join(ErrContainerVanished, err)
 	}
 
 	log.G(ctx).Debugf("We have root %s and pid %d", root, pid)
 
+	var cleanup func() error
 	// If we have no root:
 	// - bail out for rootless
 	// - mount the snapshot for rootful
@@ -118,39 +118,96 @@ func CopyFiles(ctx context.Context, client *containerd.Client, container contain
 		// the user namespace of the pid of the running container with --preserve-credentials to preserve uid/gid
 		// mapping and copy files into the container.
 		if rootlessutil.IsRootless() {
-			return ErrRootlessCannotCp
+			return nil, nil, ErrRootlessCannotCp
 		}
 
 		// See similar situation above. This may happen if we are racing against container deletion
-		var conInfo containers.Container
-		conInfo, err = container.Info(ctx)
+		conInfo, err := container.Info(ctx)
+		if err != nil {
+			return nil, nil, errors.Join(ErrContainerVanished, err)
+		}
+
+		root, cleanup, err = mountSnapshotForContainer(ctx, client, conInfo, snapshotter)
 		if err != nil {
-			return errors.Join(ErrContainerVanished, err)
+			return nil, cleanup, errors.Join(ErrFailedMountingSnapshot, err)
 		}
 
+		log.G(ctx).Debugf("Got new root %s", root)
+	}
+
+	return &containerRoot{spec: conSpec, root: root, pid: pid}, cleanup, nil
+}
+
+// CopyFiles implements `nerdctl cp`
+// srcContainer (resp. destContainer) is nil when the source (resp. destination) is on the local filesystem,
+// or is a tar archive streamed from options.Stdin (resp. to options.Stdout).
+// It currently depends on the following assumptions:
+// - linux only
+// - tar binary exists on the system
+// - nsenter binary exists on the system
+// - if rootless, the containers are running (aka: /proc/pid/root)
+func CopyFiles(ctx context.Context, client *containerd.Client, srcContainer, destContainer containerd.Container, options types.ContainerCpOptions) (err error) {
+	// We do rely on the tar binary as a shortcut - could also be replaced by archive/tar, though that would mean
+	// we need to replace nsenter calls with re-exec
+	tarBinary, isGNUTar, err := tarutil.FindTarBinary()
+	if err != nil {
+		return err
+	}
+
+	log.G(ctx).Debugf("Detected tar binary %q (GNU=%v)", tarBinary, isGNUTar)
+
+	fromStdin := options.SrcPath == "-"
+	toStdout := options.DestPath == "-"
+	if fromStdin && options.Chown != "" {
+		return errors.New("cannot change the ownership of a tar archive read from stdin")
+	}
+
+	var srcRoot, destRoot *containerRoot
+	if srcContainer != nil {
 		var cleanup func() error
-		root, cleanup, err = mountSnapshotForContainer(ctx, client, conInfo, options.GOptions.Snapshotter)
+		srcRoot, cleanup, err = openContainerRoot(ctx, client, srcContainer, options.GOptions.Snapshotter)
 		if cleanup != nil {
 			defer func() {
 				err = errors.Join(err, cleanup())
 			}()
 		}
-
 		if err != nil {
-			return errors.Join(ErrFailedMountingSnapshot, err)
+			return err
+		}
+	}
+	if destContainer != nil {
+		if srcContainer != nil && srcContainer.ID() == destContainer.ID() {
+			// Do not mount the same snapshot twice
+			destRoot = srcRoot
+		} else {
+			var cleanup func() error
+			destRoot, cleanup, err = openContainerRoot(ctx, client, destContainer, options.GOptions.Snapshotter)
+			if cleanup != nil {
+				defer func() {
+					err = errors.Join(err, cleanup())
+				}()
+			}
+			if err != nil {
+				return err
+			}
 		}
-
-		log.G(ctx).Debugf("Got new root %s", root)
 	}
 
 	var sourceSpec, destinationSpec *pathSpecifier
 	var sourceErr, destErr error
-	if options.Container2Host {
-		sourceSpec, sourceErr = getPathSpecFromContainer(options.SrcPath, conSpec, root)
-		destinationSpec, destErr = getPathSpecFromHost(options.DestPath)
-	} else {
+	switch {
+	case fromStdin:
+	case srcRoot != nil:
+		sourceSpec, sourceErr = getPathSpecFromContainer(options.SrcPath, srcRoot.spec, srcRoot.root)
+	default:
 		sourceSpec, sourceErr = getPathSpecFromHost(options.SrcPath)
-		destinationSpec, destErr = getPathSpecFromContainer(options.DestPath, conSpec, root)
+	}
+	switch {
+	case toStdout:
+	case destRoot != nil:
+		destinationSpec, destErr = getPathSpecFromContainer(options.DestPath, destRoot.spec, destRoot.root)
+	default:
+		destinationSpec, destErr = getPathSpecFromHost(options.DestPath)
 	}
 
 	if destErr != nil {
@@ -175,152 +232,265 @@ func CopyFiles(ctx context.Context, client *containerd.Client, container contain
 
 	// Now, resolve cp shenanigans
 	// First, cannot copy a non-existent resource
-	if !sourceSpec.exists {
+	if sourceSpec != nil && !sourceSpec.exists {
 		return ErrSourceDoesNotExist
 	}
 
-	// Second, cannot copy into a readonly destination
-	if destinationSpec.readOnly {
-		return ErrTargetIsReadOnly
-	}
-
-	// Cannot copy a dir into a file
-	if sourceSpec.isADir && destinationSpec.exists && !destinationSpec.isADir {
-		return ErrCannotCopyDirToFile
-	}
-
-	// A file cannot be copied inside a non-existent directory with a trailing slash, or slash+dot
-	if !sourceSpec.isADir && !destinationSpec.exists && (destinationSpec.endsWithSeparator || destinationSpec.endsWithSeparatorDot) {
-		return ErrDestinationDirMustExist
-	}
-
-	// XXX FIXME: this seems wrong. What about ownership? We could be doing that inside a container
-	if !destinationSpec.exists {
-		if err = os.Mkdir(destinationSpec.resolvedPath, 0o755); err != nil {
-			return errors.Join(ErrFilesystem, err)
+	if destinationSpec != nil {
+		// Second, cannot copy into a readonly destination
+		if destinationSpec.readOnly {
+			return ErrTargetIsReadOnly
 		}
-	}
 
-	var tarCDir, tarCArg string
-	if sourceSpec.isADir {
-		if !destinationSpec.exists || sourceSpec.endsWithSeparatorDot {
-			// the content of the source directory is copied into this directory
-			tarCDir = sourceSpec.resolvedPath
-			tarCArg = "."
+		if sourceSpec == nil {
+			// A tar archive is extracted into an existing directory
+			if !destinationSpec.exists {
+				return ErrDestinationDirMustExist
+			}
+			if !destinationSpec.isADir {
+				return ErrDestinationIsNotADir
+			}
 		} else {
-			// the source directory is copied into this directory
-			tarCDir = filepath.Dir(sourceSpec.resolvedPath)
-			tarCArg = filepath.Base(sourceSpec.resolvedPath)
+			// Cannot copy a dir into a file
+			if sourceSpec.isADir && destinationSpec.exists && !destinationSpec.isADir {
+				return ErrCannotCopyDirToFile
+			}
+
+			// A file cannot be copied inside a non-existent directory with a trailing slash, or slash+dot
+			if !sourceSpec.isADir && !destinationSpec.exists && (destinationSpec.endsWithSeparator || destinationSpec.endsWithSeparatorDot) {
+				return ErrDestinationDirMustExist
+			}
+
+			// XXX FIXME: this seems wrong. What about ownership? We could be doing that inside a container
+			if !destinationSpec.exists {
+				if err = os.Mkdir(destinationSpec.resolvedPath, 0o755); err != nil {
+					return errors.Join(ErrFilesystem, err)
+				}
+			}
 		}
-	} else {
-		// Prepare a single-file directory to create an archive of the source file
-		td, err := os.MkdirTemp("", "nerdctl-cp")
-		if err != nil {
-			return err
+	}
+
+	var tarC, tarX []string
+	var tarCDir, tarXDir string
+	if sourceSpec != nil {
+		var tarCArg string
+		switch {
+		case destinationSpec == nil:
+			// Like `docker cp CONTAINER:SRC_PATH -`, the archive contains the source itself,
+			// or the content of the source directory if it ends with `/.`
+			if sourceSpec.isADir && sourceSpec.endsWithSeparatorDot {
+				tarCDir = sourceSpec.resolvedPath
+				tarCArg = "."
+			} else {
+				tarCDir = filepath.Dir(sourceSpec.resolvedPath)
+				tarCArg = filepath.Base(sourceSpec.resolvedPath)
+			}
+		case sourceSpec.isADir:
+			if !destinationSpec.exists || sourceSpec.endsWithSeparatorDot {
+				// the content of the source directory is copied into this directory
+				tarCDir = sourceSpec.resolvedPath
+				tarCArg = "."
+			} else {
+				// the source directory is copied into this directory
+				tarCDir = filepath.Dir(sourceSpec.resolvedPath)
+				tarCArg = filepath.Base(sourceSpec.resolvedPath)
+			}
+		default:
+			// Prepare a single-file directory to create an archive of the source file
+			td, err := os.MkdirTemp("", "nerdctl-cp")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(td)
+			tarCDir = td
+			cp := []string{"cp", "-a"}
+			if options.FollowSymLink {
+				cp = append(cp, "-L")
+			}
+			if destinationSpec.endsWithSeparator || (destinationSpec.exists && destinationSpec.isADir) {
+				tarCArg = filepath.Base(sourceSpec.resolvedPath)
+			} else {
+				// Handle `nerdctl cp /path/to/file some-container:/path/to/file-with-another-name`
+				tarCArg = filepath.Base(destinationSpec.resolvedPath)
+			}
+			cp = append(cp, sourceSpec.resolvedPath, filepath.Join(td, tarCArg))
+			cpCmd := exec.CommandContext(ctx, cp[0], cp[1:]...)
+			log.G(ctx).Debugf("executing %v", cpCmd.Args)
+			if out, err := cpCmd.CombinedOutput(); err != nil {
+				return fmt.Errorf("failed to execute %v: %w (out=%q)", cpCmd.Args, err, string(out))
+			}
 		}
-		defer os.RemoveAll(td)
-		tarCDir = td
-		cp := []string{"cp", "-a"}
+		tarC = []string{tarBinary}
 		if options.FollowSymLink {
-			cp = append(cp, "-L")
-		}
-		if destinationSpec.endsWithSeparator || (destinationSpec.exists && destinationSpec.isADir) {
-			tarCArg = filepath.Base(sourceSpec.resolvedPath)
-		} else {
-			// Handle `nerdctl cp /path/to/file some-container:/path/to/file-with-another-name`
-			tarCArg = filepath.Base(destinationSpec.resolvedPath)
+			tarC = append(tarC, "-h")
 		}
-		cp = append(cp, sourceSpec.resolvedPath, filepath.Join(td, tarCArg))
-		cpCmd := exec.CommandContext(ctx, cp[0], cp[1:]...)
-		log.G(ctx).Debugf("executing %v", cpCmd.Args)
-		if out, err := cpCmd.CombinedOutput(); err != nil {
-			return fmt.Errorf("failed to execute %v: %w (out=%q)", cpCmd.Args, err, string(out))
+		if options.Chown != "" {
+			var mappings *specs.Linux
+			if destRoot != nil && !rootlessutil.IsRootless() {
+				// Rootless extracts the archive in the user namespace of the container, so only rootful needs mapping
+				mappings = destRoot.spec.Linux
+			}
+			uid, gid, err := chownToHostIDs(options.Chown, mappings)
+			if err != nil {
+				return err
+			}
+			if isGNUTar {
+				// The empty user and group names force numeric IDs
+				tarC = append(tarC, fmt.Sprintf("--owner=:%d", uid), fmt.Sprintf("--group=:%d", gid), "--numeric-owner")
+			} else {
+				tarC = append(tarC, "--uid", strconv.FormatUint(uint64(uid), 10), "--gid", strconv.FormatUint(uint64(gid), 10))
+			}
 		}
+		tarC = append(tarC, "-c", "-f", "-", tarCArg)
 	}
-	tarC := []string{tarBinary}
-	if options.FollowSymLink {
-		tarC = append(tarC, "-h")
-	}
-	tarC = append(tarC, "-c", "-f", "-", tarCArg)
 
-	tarXDir := destinationSpec.resolvedPath
-	if !sourceSpec.isADir && !destinationSpec.endsWithSeparator && !(destinationSpec.exists && destinationSpec.isADir) {
-		tarXDir = filepath.Dir(destinationSpec.resolvedPath)
-	}
-	tarX := []string{tarBinary, "-x"}
-	if options.Container2Host && isGNUTar {
-		tarX = append(tarX, "--no-same-owner")
+	if destinationSpec != nil {
+		tarXDir = destinationSpec.resolvedPath
+		if sourceSpec != nil && !sourceSpec.isADir && !destinationSpec.endsWithSeparator && !(destinationSpec.exists && destinationSpec.isADir) {
+			tarXDir = filepath.Dir(destinationSpec.resolvedPath)
+		}
+		tarX = []string{tarBinary, "-x"}
+		if destRoot == nil && isGNUTar {
+			if options.Archive || options.Chown != "" {
+				tarX = append(tarX, "--same-owner")
+			} else {
+				tarX = append(tarX, "--no-same-owner")
+			}
+		}
+		tarX = append(tarX, "-f", "-")
 	}
-	tarX = append(tarX, "-f", "-")
 
 	if rootlessutil.IsRootless() {
-		nsenter := []string{"nsenter", "-t", strconv.Itoa(pid), "-U", "--preserve-credentials", "--"}
-		if options.Container2Host {
-			tarC = append(nsenter, tarC...)
-		} else {
-			tarX = append(nsenter, tarX...)
+		if srcRoot != nil && tarC != nil {
+			tarC = append([]string{"nsenter", "-t", strconv.Itoa(srcRoot.pid), "-U", "--preserve-credentials", "--"}, tarC...)
+		}
+		if destRoot != nil && tarX != nil {
+			tarX = append([]string{"nsenter", "-t", strconv.Itoa(destRoot.pid), "-U", "--preserve-credentials", "--"}, tarX...)
+		}
+	}
+
+	var tarCCmd, tarXCmd *exec.Cmd
+	if tarC != nil {
+		tarCCmd = exec.CommandContext(ctx, tarC[0], tarC[1:]...)
+		tarCCmd.Dir = tarCDir
+		tarCCmd.Stdin = nil
+		tarCCmd.Stderr = os.Stderr
+		if tarX == nil {
+			tarCCmd.Stdout = options.Stdout
 		}
 	}
 
 	// FIXME: moving to archive/tar should allow better error management than this
 	// WARNING: some of our testing on stderr might not be portable across different versions of tar
 	// In these cases (readonly target), we will just get the straight tar output instead
-	tarCCmd := exec.CommandContext(ctx, tarC[0], tarC[1:]...)
-	tarCCmd.Dir = tarCDir
-	tarCCmd.Stdin = nil
-	tarCCmd.Stderr = os.Stderr
-
-	tarXCmd := exec.CommandContext(ctx, tarX[0], tarX[1:]...)
-	tarXCmd.Dir = tarXDir
-	tarXCmd.Stdin, err = tarCCmd.StdoutPipe()
-	if err != nil {
-		return err
-	}
-	tarXCmd.Stdout = os.Stderr
 	var tarErr bytes.Buffer
-	tarXCmd.Stderr = &tarErr
-
-	log.G(ctx).Debugf("executing %v in %q", tarCCmd.Args, tarCCmd.Dir)
-	if err := tarCCmd.Start(); err != nil {
-		return errors.Join(fmt.Errorf("failed to execute %v", tarCCmd.Args), err)
+	if tarX != nil {
+		tarXCmd = exec.CommandContext(ctx, tarX[0], tarX[1:]...)
+		tarXCmd.Dir = tarXDir
+		if tarCCmd != nil {
+			tarXCmd.Stdin, err = tarCCmd.StdoutPipe()
+			if err != nil {
+				return err
+			}
+		} else {
+			tarXCmd.Stdin = options.Stdin
+		}
+		tarXCmd.Stdout = os.Stderr
+		tarXCmd.Stderr = &tarErr
 	}
 
-	log.G(ctx).Debugf("executing %v in %q", tarXCmd.Args, tarXCmd.Dir)
-	if err := tarXCmd.Start(); err != nil {
-		if strings.Contains(err.Error(), "permission denied") {
-			return ErrTargetIsReadOnly
+	if tarCCmd != nil {
+		log.G(ctx).Debugf("executing %v in %q", tarCCmd.Args, tarCCmd.Dir)
+		if err := tarCCmd.Start(); err != nil {
+			return errors.Join(fmt.Errorf("failed to execute %v", tarCCmd.Args), err)
 		}
+	}
 
-		// Other errors, just put them back on stderr
-		_, fpErr := fmt.Fprint(os.Stderr, tarErr.String())
-		if fpErr != nil {
-			return errors.Join(fpErr, err)
-		}
+	if tarXCmd != nil {
+		log.G(ctx).Debugf("executing %v in %q", tarXCmd.Args, tarXCmd.Dir)
+		if err := tarXCmd.Start(); err != nil {
+			if strings.Contains(err.Error(), "permission denied") {
+				return ErrTargetIsReadOnly
+			}
 
-		return errors.Join(fmt.Errorf("failed to execute %v", tarXCmd.Args), err)
-	}
+			// Other errors, just put them back on stderr
+			_, fpErr := fmt.Fprint(os.Stderr, tarErr.String())
+			if fpErr != nil {
+				return errors.Join(fpErr, err)
+			}
 
-	if err := tarCCmd.Wait(); err != nil {
-		return fmt.Errorf("failed to wait %v: %w", tarCCmd.Args, err)
+			return errors.Join(fmt.Errorf("failed to execute %v", tarXCmd.Args), err)
+		}
 	}
 
-	if err := tarXCmd.Wait(); err != nil {
-		if strings.Contains(tarErr.String(), "Read-only file system") {
-			return ErrTargetIsReadOnly
+	if tarCCmd != nil {
+		if err := tarCCmd.Wait(); err != nil {
+			return fmt.Errorf("failed to wait %v: %w", tarCCmd.Args, err)
 		}
+	}
 
-		// Other errors, just put them back on stderr
-		_, fpErr := fmt.Fprint(os.Stderr, tarErr.String())
-		if fpErr != nil {
-			return errors.Join(fpErr, err)
-		}
+	if tarXCmd != nil {
+		if err := tarXCmd.Wait(); err != nil {
+			if strings.Contains(tarErr.String(), "Read-only file system") {
+				return ErrTargetIsReadOnly
+			}
 
-		return errors.Join(fmt.Errorf("failed to wait %v", tarXCmd.Args), err)
+			// Other errors, just put them back on stderr
+			_, fpErr := fmt.Fprint(os.Stderr, tarErr.String())
+			if fpErr != nil {
+				return errors.Join(fpErr, err)
+			}
+
+			return errors.Join(fmt.Errorf("failed to wait %v", tarXCmd.Args), err)
+		}
 	}
 
 	return nil
 }
 
+// chownToHostIDs parses a UID[:GID] ownership, and translates it to the host IDs with the user namespace mappings
+// of the destination container, if any. The GID defaults to the UID.
+func chownToHostIDs(chown string, linux *specs.Linux) (uint32, uint32, error) {
+	uidStr, gidStr, hasGID := strings.Cut(chown, ":")
+	if !hasGID {
+		gidStr = uidStr
+	}
+	uid, err := strconv.ParseUint(uidStr, 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid UID in --chown %q (must be numeric): %w", chown, errdefs.ErrInvalidArgument)
+	}
+	gid, err := strconv.ParseUint(gidStr, 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid GID in --chown %q (must be numeric): %w", chown, errdefs.ErrInvalidArgument)
+	}
+	if linux == nil {
+		return uint32(uid), uint32(gid), nil
+	}
+	hostUID, err := toHostID(linux.UIDMappings, uint32(uid))
+	if err != nil {
+		return 0, 0, fmt.Errorf("UID %d: %w", uid, err)
+	}
+	hostGID, err := toHostID(linux.GIDMappings, uint32(gid))
+	if err != nil {
+		return 0, 0, fmt.Errorf("GID %d: %w", gid, err)
+	}
+	return hostUID, hostGID, nil
+}
+
+// toHostID translates an ID of a user namespace to the ID on the host.
+// The ID is returned as-is when there is no mapping.
+func toHostID(mappings []specs.LinuxIDMapping, id uint32) (uint32, error) {
+	if len(mappings) == 0 {
+		return id, nil
+	}
+	for _, m := range mappings {
+		if id >= m.ContainerID && id-m.ContainerID < m.Size {
+			return m.HostID + id - m.ContainerID, nil
+		}
+	}
+	return 0, fmt.Errorf("not mapped in the user namespace of the container: %w", errdefs.ErrInvalidArgument)
+}
+
 func mountSnapshotForContainer(ctx context.Context, client *containerd.Client, conInfo containers.Container, snapshotter string) (string, func() error, error) {
 	snapKey := conInfo.SnapshotKey
 	resp, err := client.SnapshotService(snapshotter).Mounts(ctx, snapKey)
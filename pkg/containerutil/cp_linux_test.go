@@ -0,0 +1,58 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package containerutil
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gotest.tools/v3/assert"
+)
+
+func TestChownToHostIDs(t *testing.T) {
+	userns := &specs.Linux{
+		UIDMappings: []specs.LinuxIDMapping{{ContainerID: 0, HostID: 100000, Size: 65536}},
+		GIDMappings: []specs.LinuxIDMapping{{ContainerID: 0, HostID: 200000, Size: 1000}},
+	}
+	tests := []struct {
+		chown       string
+		linux       *specs.Linux
+		expectedUID uint32
+		expectedGID uint32
+		expectedErr string
+	}{
+		{chown: "1000", expectedUID: 1000, expectedGID: 1000},
+		{chown: "1000:50", expectedUID: 1000, expectedGID: 50},
+		{chown: "0:0", linux: &specs.Linux{}, expectedUID: 0, expectedGID: 0},
+		{chown: "0", linux: userns, expectedUID: 100000, expectedGID: 200000},
+		{chown: "1000:999", linux: userns, expectedUID: 101000, expectedGID: 200999},
+		{chown: "1000", linux: userns, expectedErr: "GID 1000: not mapped"},
+		{chown: "user", expectedErr: "invalid UID"},
+		{chown: "0:group", expectedErr: "invalid GID"},
+		{chown: "-1", expectedErr: "invalid UID"},
+	}
+	for _, tc := range tests {
+		uid, gid, err := chownToHostIDs(tc.chown, tc.linux)
+		if tc.expectedErr != "" {
+			assert.ErrorContains(t, err, tc.expectedErr, tc.chown)
+			continue
+		}
+		assert.NilError(t, err, tc.chown)
+		assert.Equal(t, uid, tc.expectedUID, tc.chown)
+		assert.Equal(t, gid, tc.expectedGID, tc.chown)
+	}
+}
@@ -0,0 +1,201 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package containerutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/pkg/cio"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/idgen"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+// HookEvent is an event of the container lifecycle that triggers hooks.
+type HookEvent string
+
+const (
+	// HookStart is triggered before the task is started. Only host commands are supported.
+	// A failure aborts the start.
+	HookStart HookEvent = "start"
+	// HookPostStart is triggered after the task is started.
+	HookPostStart HookEvent = "post-start"
+	// HookPreStop is triggered before the stop signal is sent to the running task.
+	HookPreStop HookEvent = "pre-stop"
+)
+
+// HookExecPrefix is the prefix of the hook commands executed inside the container.
+// The other commands are executed on the host.
+const HookExecPrefix = "exec:"
+
+// HookTimeout is the timeout of each hook command.
+const HookTimeout = time.Minute
+
+// Hooks are the lifecycle hooks of a container, stored in the labels.Hooks label.
+// Each hook is a shell command.
+type Hooks struct {
+	Start     []string `json:"start,omitempty"`
+	PostStart []string `json:"postStart,omitempty"`
+	PreStop   []string `json:"preStop,omitempty"`
+}
+
+// NewHooks validates the hook commands.
+func NewHooks(start, postStart, preStop []string) (*Hooks, error) {
+	for _, c := range append(append(append([]string{}, start...), postStart...), preStop...) {
+		if strings.TrimSpace(strings.TrimPrefix(c, HookExecPrefix)) == "" {
+			return nil, errors.New("hook command must not be empty")
+		}
+	}
+	for _, c := range start {
+		if strings.HasPrefix(c, HookExecPrefix) {
+			return nil, fmt.Errorf("hook %q: %q is not supported for the %q event, as the container is not running yet", c, HookExecPrefix, HookStart)
+		}
+	}
+	return &Hooks{Start: start, PostStart: postStart, PreStop: preStop}, nil
+}
+
+// IsEmpty returns true if no hook is set.
+func (h *Hooks) IsEmpty() bool {
+	return h == nil || len(h.Start)+len(h.PostStart)+len(h.PreStop) == 0
+}
+
+// Commands returns the hook commands for the event.
+func (h *Hooks) Commands(event HookEvent) []string {
+	if h == nil {
+		return nil
+	}
+	switch event {
+	case HookStart:
+		return h.Start
+	case HookPostStart:
+		return h.PostStart
+	case HookPreStop:
+		return h.PreStop
+	}
+	return nil
+}
+
+// DecodeHooksLabel decodes the labels.Hooks label. An empty label returns nil.
+func DecodeHooksLabel(label string) (*Hooks, error) {
+	if label == "" {
+		return nil, nil
+	}
+	var h Hooks
+	if err := json.Unmarshal([]byte(label), &h); err != nil {
+		return nil, fmt.Errorf("failed to parse label %q: %w", labels.Hooks, err)
+	}
+	return &h, nil
+}
+
+// RunHooks runs the hooks of the container for the event, in order.
+// It stops at the first failure.
+func RunHooks(ctx context.Context, container containerd.Container, lab map[string]string, event HookEvent) error {
+	hooks, err := DecodeHooksLabel(lab[labels.Hooks])
+	if err != nil {
+		return err
+	}
+	for _, c := range hooks.Commands(event) {
+		log.G(ctx).Debugf("running %s hook %q of container %s", event, c, container.ID())
+		hookCtx, cancel := context.WithTimeout(ctx, HookTimeout)
+		if inner, ok := strings.CutPrefix(c, HookExecPrefix); ok {
+			err = runExecHook(hookCtx, container, inner)
+		} else {
+			err = runHostHook(hookCtx, container, lab, event, c)
+		}
+		cancel()
+		if err != nil {
+			return fmt.Errorf("%s hook %q of container %s failed: %w", event, c, container.ID(), err)
+		}
+	}
+	return nil
+}
+
+// runHostHook runs the command on the host, with the container identified by the environment variables.
+func runHostHook(ctx context.Context, container containerd.Container, lab map[string]string, event HookEvent, command string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd.exe", "/S", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(),
+		"NERDCTL_HOOK_EVENT="+string(event),
+		"NERDCTL_CONTAINER_ID="+container.ID(),
+		"NERDCTL_CONTAINER_NAME="+lab[labels.Name],
+		"NERDCTL_NAMESPACE="+lab[labels.Namespace],
+	)
+	// stdout is reserved for the output of nerdctl itself, e.g., the container ID of `nerdctl run -d`.
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.WaitDelay = time.Second
+	return cmd.Run()
+}
+
+// runExecHook runs the command inside the running container, with the process spec of the container.
+func runExecHook(ctx context.Context, container containerd.Container, command string) error {
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		return err
+	}
+	spec, err := container.Spec(ctx)
+	if err != nil {
+		return err
+	}
+	pspec := spec.Process
+	pspec.Terminal = false
+	pspec.Args = []string{"/bin/sh", "-c", command}
+	process, err := task.Exec(ctx, "hook-"+idgen.TruncateID(idgen.GenerateID()), pspec, cio.NewCreator(cio.WithStreams(nil, os.Stderr, os.Stderr)))
+	if err != nil {
+		return err
+	}
+	// The process has to be deleted even after ctx is done.
+	defer process.Delete(context.WithoutCancel(ctx), containerd.WithProcessKill)
+	statusC, err := process.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	if err := process.Start(ctx); err != nil {
+		return err
+	}
+	select {
+	case status := <-statusC:
+		code, _, err := status.Result()
+		if err != nil {
+			return err
+		}
+		if code != 0 {
+			return fmt.Errorf("exit code %d", code)
+		}
+		return nil
+	case <-ctx.Done():
+		if err := process.Kill(context.WithoutCancel(ctx), syscall.SIGKILL); err != nil {
+			log.G(ctx).WithError(err).Debug("failed to kill the hook process")
+		}
+		return ctx.Err()
+	}
+}
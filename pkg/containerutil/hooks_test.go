@@ -0,0 +1,57 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package containerutil
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestHooks(t *testing.T) {
+	hooks, err := NewHooks([]string{"echo start"}, []string{"exec:touch /started"}, []string{"echo stop", "exec:rm /started"})
+	assert.NilError(t, err)
+	assert.Assert(t, !hooks.IsEmpty())
+	assert.DeepEqual(t, hooks.Commands(HookStart), []string{"echo start"})
+	assert.DeepEqual(t, hooks.Commands(HookPostStart), []string{"exec:touch /started"})
+	assert.DeepEqual(t, hooks.Commands(HookPreStop), []string{"echo stop", "exec:rm /started"})
+
+	b, err := json.Marshal(hooks)
+	assert.NilError(t, err)
+	decoded, err := DecodeHooksLabel(string(b))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, decoded, hooks)
+
+	decoded, err = DecodeHooksLabel("")
+	assert.NilError(t, err)
+	assert.Assert(t, decoded.IsEmpty())
+	assert.Assert(t, decoded.Commands(HookStart) == nil)
+
+	_, err = DecodeHooksLabel("{")
+	assert.ErrorContains(t, err, "failed to parse label")
+
+	_, err = NewHooks([]string{"exec:true"}, nil, nil)
+	assert.ErrorContains(t, err, "not supported for the \"start\" event")
+
+	_, err = NewHooks(nil, []string{"exec: "}, nil)
+	assert.ErrorContains(t, err, "must not be empty")
+
+	empty, err := NewHooks(nil, nil, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, empty.IsEmpty())
+}
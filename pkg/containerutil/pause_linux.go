@@ -0,0 +1,81 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package containerutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/containerd/cgroups/v3"
+	containerd "github.com/containerd/containerd/v2/client"
+
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+const cgroupV2Hint = "see https://rootlesscontaine.rs/getting-started/common/cgroup2/"
+
+// checkPausable returns a clear error when the freezer cannot pause the task,
+// instead of the error of the OCI runtime.
+func checkPausable(ctx context.Context, container containerd.Container, task containerd.Task) error {
+	spec, err := container.Spec(ctx)
+	if err != nil {
+		return err
+	}
+	if spec.Linux == nil || spec.Linux.CgroupsPath == "" {
+		return fmt.Errorf("container %s cannot be paused, as it has no cgroup (--cgroup-manager=none)", container.ID())
+	}
+	if !rootlessutil.IsRootless() {
+		return nil
+	}
+	if cgroups.Mode() != cgroups.Unified {
+		return fmt.Errorf("pausing a container requires cgroup v2 in rootless mode (Hint: %s)", cgroupV2Hint)
+	}
+	// The freezer of cgroup v2 is cgroup.freeze of the cgroup of the task, which has to be delegated to the user.
+	freeze, err := cgroupFreezePath(task.Pid())
+	if err != nil {
+		// Let the OCI runtime try.
+		return nil
+	}
+	if err := unix.Access(freeze, unix.W_OK); err != nil {
+		return fmt.Errorf("cannot pause container %s, as %s is not writable: %w (Hint: enable cgroup delegation, %s)", container.ID(), freeze, err, cgroupV2Hint)
+	}
+	return nil
+}
+
+// cgroupFreezePath returns the path of cgroup.freeze of the cgroup v2 of the process.
+func cgroupFreezePath(pid uint32) (string, error) {
+	b, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if group, ok := strings.CutPrefix(line, "0::"); ok {
+			freeze := filepath.Join("/sys/fs/cgroup", group, "cgroup.freeze")
+			if _, err := os.Stat(freeze); err != nil {
+				return "", err
+			}
+			return freeze, nil
+		}
+	}
+	return "", errors.New("no cgroup v2 entry")
+}
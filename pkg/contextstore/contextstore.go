@@ -0,0 +1,350 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package contextstore provides the Docker-style contexts of nerdctl.
+// A context bundles the containerd address, the namespace, the snapshotter, and optionally the SSH destination
+// of a remote host, so that users can switch between them with `nerdctl context use`.
+// The contexts are stored in the "contexts" directory next to nerdctl.toml.
+package contextstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/identifiers"
+	"github.com/containerd/nerdctl/v2/pkg/sshutil"
+	"github.com/containerd/nerdctl/v2/pkg/store"
+)
+
+// DefaultName is the name of the built-in context, which uses the configuration of nerdctl.toml as is.
+const DefaultName = "default"
+
+const (
+	dirBasename  = "contexts"
+	metaGroup    = "meta"
+	currentKey   = "current"
+	remoteBinary = "nerdctl"
+)
+
+// ErrContextStore will wrap all errors here
+var ErrContextStore = errors.New("context-store error")
+
+// Context is a set of the global options, and optionally the SSH destination of a remote host.
+type Context struct {
+	Name        string
+	Description string `json:",omitempty"`
+	Address     string `json:",omitempty"`
+	Namespace   string `json:",omitempty"`
+	Snapshotter string `json:",omitempty"`
+	// SSH is the destination of the remote host, e.g., "ssh://user@host:22".
+	// The commands are executed by the nerdctl installed on the remote host.
+	SSH string `json:",omitempty"`
+	// SSHConfig is the ssh_config file for SSH, e.g., the one generated by Lima for `nerdctl machine`
+	SSHConfig string `json:",omitempty"`
+	// Sudo executes the commands with sudo, e.g., for using the rootful containerd from a non-root user
+	Sudo bool `json:",omitempty"`
+}
+
+// Delegated returns true when the commands have to be executed by another nerdctl process,
+// i.e., on the remote host over SSH, or with sudo.
+func (c *Context) Delegated() bool {
+	return c.SSH != "" || c.Sudo
+}
+
+// Validate validates the fields of the context.
+func (c *Context) Validate() error {
+	if c.Name == DefaultName {
+		return fmt.Errorf("context name %q is reserved", DefaultName)
+	}
+	if err := identifiers.ValidateDockerCompat(c.Name); err != nil {
+		return fmt.Errorf("invalid context name %q: %w", c.Name, err)
+	}
+	if sshutil.IsSSH(c.Address) {
+		if c.Delegated() {
+			return fmt.Errorf("address %q must not be specified together with --ssh or --sudo", c.Address)
+		}
+		if _, err := sshutil.Parse(c.Address); err != nil {
+			return err
+		}
+	} else if strings.Contains(c.Address, "://") && !strings.HasPrefix(c.Address, "unix://") {
+		return fmt.Errorf("invalid address %q", c.Address)
+	}
+	if c.Namespace != "" {
+		if err := store.IsFilesystemSafe(c.Namespace); err != nil {
+			return fmt.Errorf("invalid namespace %q: %w", c.Namespace, err)
+		}
+	}
+	if c.SSH != "" {
+		d, err := sshutil.Parse(c.SSH)
+		if err != nil {
+			return err
+		}
+		if d.Path != "" {
+			return fmt.Errorf("invalid SSH destination %q, must not have a path", c.SSH)
+		}
+	} else if c.SSHConfig != "" {
+		return errors.New("the ssh_config file must not be specified without the SSH destination")
+	}
+	return nil
+}
+
+// DelegateArgs returns the argv for executing the nerdctl command args with the context.
+// self is the path of the nerdctl binary for the sudo contexts, and tty allocates a TTY on the remote host.
+func (c *Context) DelegateArgs(self string, args []string, tty bool) ([]string, error) {
+	var flags []string
+	if c.Address != "" {
+		flags = append(flags, "--address="+c.Address)
+	}
+	if c.Namespace != "" {
+		flags = append(flags, "--namespace="+c.Namespace)
+	}
+	if c.Snapshotter != "" {
+		flags = append(flags, "--snapshotter="+c.Snapshotter)
+	}
+	if c.SSH == "" {
+		if !c.Sudo {
+			return nil, fmt.Errorf("context %q does not need to be delegated", c.Name)
+		}
+		// "--context=default" prevents the current context of root from being applied again
+		argv := append([]string{"sudo", self, "--context=" + DefaultName}, flags...)
+		return append(argv, args...), nil
+	}
+	d, err := sshutil.Parse(c.SSH)
+	if err != nil {
+		return nil, err
+	}
+	argv := []string{"ssh"}
+	if tty {
+		argv = append(argv, "-t")
+	}
+	if c.SSHConfig != "" {
+		argv = append(argv, "-F", c.SSHConfig)
+	}
+	argv = append(argv, d.Args()...)
+	var remote []string
+	if c.Sudo {
+		remote = append(remote, "sudo")
+	}
+	remote = append(remote, remoteBinary)
+	remote = append(remote, flags...)
+	remote = append(remote, args...)
+	return append(argv, sshutil.ShellJoin(remote)), nil
+}
+
+// ContextStore manages the contexts.
+type ContextStore interface {
+	// Create creates a new context
+	Create(c *Context) error
+	// Get returns the context, including the default context
+	Get(name string) (*Context, error)
+	// List returns the contexts, including the default context
+	List() ([]*Context, error)
+	// Remove removes the context
+	Remove(name string) error
+	// Current returns the name of the current context
+	Current() (string, error)
+	// Use sets the current context
+	Use(name string) error
+}
+
+// New returns the ContextStore in configDir, i.e., the directory of nerdctl.toml.
+func New(configDir string) (ContextStore, error) {
+	st, err := store.New(filepath.Join(configDir, dirBasename), 0o700, 0o600)
+	if err != nil {
+		return nil, errors.Join(ErrContextStore, err)
+	}
+	return &contextStore{safeStore: st}, nil
+}
+
+// Resolve returns the context with the name, or the current context if the name is empty.
+// Unlike New, Resolve does not create the directory of the store.
+func Resolve(configDir, name string) (*Context, error) {
+	if _, err := os.Stat(filepath.Join(configDir, dirBasename)); errors.Is(err, os.ErrNotExist) {
+		if name == "" || name == DefaultName {
+			return &Context{Name: DefaultName}, nil
+		}
+		return nil, errors.Join(ErrContextStore, store.ErrNotFound, fmt.Errorf("context %q does not exist", name))
+	}
+	cs, err := New(configDir)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name, err = cs.Current()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cs.Get(name)
+}
+
+type contextStore struct {
+	safeStore store.Store
+}
+
+func (x *contextStore) Create(c *Context) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrContextStore, err)
+		}
+	}()
+	if err := c.Validate(); err != nil {
+		return errors.Join(store.ErrInvalidArgument, err)
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return x.safeStore.WithLock(func() error {
+		if exists, err := x.safeStore.Exists(metaGroup, c.Name); err != nil {
+			return err
+		} else if exists {
+			return errors.Join(errdefs.ErrAlreadyExists, fmt.Errorf("context %q already exists", c.Name))
+		}
+		return x.safeStore.Set(b, metaGroup, c.Name)
+	})
+}
+
+func (x *contextStore) Get(name string) (c *Context, err error) {
+	if name == DefaultName {
+		return &Context{Name: DefaultName}, nil
+	}
+	err = x.safeStore.WithLock(func() error {
+		c, err = x.get(name)
+		return err
+	})
+	if err != nil {
+		err = errors.Join(ErrContextStore, err)
+	}
+	return c, err
+}
+
+func (x *contextStore) get(name string) (*Context, error) {
+	b, err := x.safeStore.Get(metaGroup, name)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, errors.Join(store.ErrNotFound, fmt.Errorf("context %q does not exist", name))
+		}
+		return nil, err
+	}
+	var c Context
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (x *contextStore) List() (res []*Context, err error) {
+	err = x.safeStore.WithLock(func() error {
+		exists, err := x.safeStore.Exists(metaGroup)
+		if err != nil || !exists {
+			return err
+		}
+		names, err := x.safeStore.List(metaGroup)
+		if err != nil {
+			return err
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			c, err := x.get(name)
+			if err != nil {
+				return err
+			}
+			res = append(res, c)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Join(ErrContextStore, err)
+	}
+	return append([]*Context{{Name: DefaultName}}, res...), nil
+}
+
+func (x *contextStore) Remove(name string) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrContextStore, err)
+		}
+	}()
+	if name == DefaultName {
+		return errors.Join(store.ErrInvalidArgument, fmt.Errorf("the %q context cannot be removed", DefaultName))
+	}
+	return x.safeStore.WithLock(func() error {
+		current, err := x.current()
+		if err != nil {
+			return err
+		}
+		if current == name {
+			return fmt.Errorf("context %q is in use, run `nerdctl context use %s` first", name, DefaultName)
+		}
+		if err := x.safeStore.Delete(metaGroup, name); err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return errors.Join(store.ErrNotFound, fmt.Errorf("context %q does not exist", name))
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+func (x *contextStore) Current() (name string, err error) {
+	err = x.safeStore.WithLock(func() error {
+		name, err = x.current()
+		return err
+	})
+	if err != nil {
+		err = errors.Join(ErrContextStore, err)
+	}
+	return name, err
+}
+
+func (x *contextStore) current() (string, error) {
+	b, err := x.safeStore.Get(currentKey)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return DefaultName, nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+func (x *contextStore) Use(name string) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrContextStore, err)
+		}
+	}()
+	return x.safeStore.WithLock(func() error {
+		if name == DefaultName {
+			if err := x.safeStore.Delete(currentKey); err != nil && !errors.Is(err, store.ErrNotFound) {
+				return err
+			}
+			return nil
+		}
+		if _, err := x.get(name); err != nil {
+			return err
+		}
+		return x.safeStore.Set([]byte(name), currentKey)
+	})
+}
@@ -44,6 +44,11 @@ func DataRoot() string {
 	return "/var/lib/nerdctl"
 }
 
+// ContainerdRoot returns the default root directory of containerd.
+func ContainerdRoot() string {
+	return "/var/lib/containerd"
+}
+
 func CgroupManager() string {
 	return ""
 }
@@ -56,6 +61,11 @@ func NerdctlTOML() string {
 	return "/etc/nerdctl/nerdctl.toml"
 }
 
+// APIServerSocket returns the default socket path of `nerdctl system serve`.
+func APIServerSocket() string {
+	return "/var/run/nerdctl/nerdctl.sock"
+}
+
 func HostsDirs() []string {
 	return []string{}
 }
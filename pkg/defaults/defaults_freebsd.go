@@ -30,6 +30,11 @@ func DataRoot() string {
 	return "/var/lib/nerdctl"
 }
 
+// ContainerdRoot returns the default root directory of containerd.
+func ContainerdRoot() string {
+	return "/var/lib/containerd"
+}
+
 func CNIPath() string {
 	// default: /opt/cni/bin
 	return cni.DefaultCNIDir
@@ -55,6 +60,11 @@ func NerdctlTOML() string {
 	return "/etc/nerdctl/nerdctl.toml"
 }
 
+// APIServerSocket returns the default socket path of `nerdctl system serve`.
+func APIServerSocket() string {
+	return "/var/run/nerdctl/nerdctl.sock"
+}
+
 func HostsDirs() []string {
 	return []string{"/etc/containerd/certs.d", "/etc/docker/certs.d"}
 }
@@ -45,6 +45,18 @@ func DataRoot() string {
 	return filepath.Join(xdh, "nerdctl")
 }
 
+// ContainerdRoot returns the default root directory of containerd.
+func ContainerdRoot() string {
+	if !rootlessutil.IsRootless() {
+		return "/var/lib/containerd"
+	}
+	xdh, err := rootlessutil.XDGDataHome()
+	if err != nil {
+		panic(err)
+	}
+	return filepath.Join(xdh, "containerd")
+}
+
 func CNIPath() string {
 	candidates := []string{
 		cni.DefaultCNIDir, // /opt/cni/bin
@@ -112,6 +124,18 @@ func NerdctlTOML() string {
 	return filepath.Join(xch, "nerdctl/nerdctl.toml")
 }
 
+// APIServerSocket returns the default socket path of `nerdctl system serve`.
+func APIServerSocket() string {
+	if !rootlessutil.IsRootless() {
+		return "/run/nerdctl/nerdctl.sock"
+	}
+	xdr, err := rootlessutil.XDGRuntimeDir()
+	if err != nil {
+		panic(err)
+	}
+	return filepath.Join(xdr, "nerdctl/nerdctl.sock")
+}
+
 func HostsDirs() []string {
 	if !rootlessutil.IsRootless() {
 		return []string{"/etc/containerd/certs.d", "/etc/docker/certs.d"}
@@ -31,6 +31,11 @@ func DataRoot() string {
 	return filepath.Join(os.Getenv("ProgramData"), "nerdctl")
 }
 
+// ContainerdRoot returns the default root directory of containerd.
+func ContainerdRoot() string {
+	return filepath.Join(os.Getenv("ProgramData"), "containerd", "root")
+}
+
 func CNIPath() string {
 	return filepath.Join(os.Getenv("ProgramFiles"), "containerd", "cni", "bin")
 }
@@ -63,6 +68,11 @@ func NerdctlTOML() string {
 	return filepath.Join(ucd, "nerdctl\\nerdctl.toml")
 }
 
+// APIServerSocket returns the default socket path of `nerdctl system serve`.
+func APIServerSocket() string {
+	return filepath.Join(os.Getenv("ProgramData"), "nerdctl\\nerdctl.sock")
+}
+
 func HostsDirs() []string {
 	programData := os.Getenv("ProgramData")
 	if programData == "" {
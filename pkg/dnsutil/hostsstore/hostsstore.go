@@ -89,6 +89,7 @@ type Store interface {
 	Acquire(Meta) error
 	Release(id string) error
 	Update(id, newName string) error
+	Get(id string) (*Meta, error)
 	HostsPath(id string) (location string, err error)
 	Delete(id string) (err error)
 	AllocHostsFile(id string, content []byte) (location string, err error)
@@ -258,6 +259,28 @@ func (x *hostsStore) Update(id, newName string) (err error) {
 	})
 }
 
+// Get returns the metadata of a container that has networks set up.
+func (x *hostsStore) Get(id string) (meta *Meta, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrHostsStore, err)
+		}
+	}()
+
+	err = x.safeStore.WithLock(func() error {
+		var content []byte
+		if content, err = x.safeStore.Get(id, metaJSON); err != nil {
+			return err
+		}
+		meta = &Meta{}
+		return json.Unmarshal(content, meta)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
 func (x *hostsStore) updateAllHosts() (err error) {
 	entries, err := x.safeStore.List()
 	if err != nil {
@@ -0,0 +1,616 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dockerapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-connections/nat"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+)
+
+// containerJSON is the response of `GET /containers/{id}/json`.
+// It adds the fields that the Docker clients expect to dockercompat.Container.
+type containerJSON struct {
+	*dockercompat.Container
+	// Name has a leading slash, like Docker
+	Name   string
+	Config *containerConfig
+}
+
+type containerConfig struct {
+	*dockercompat.Config
+	Image     string
+	Tty       bool
+	OpenStdin bool
+}
+
+// findContainer returns the container specified by its name, ID, or ID prefix.
+// The returned error wraps errdefs.ErrNotFound or errdefs.ErrInvalidArgument, for the status code.
+func (s *Server) findContainer(ctx context.Context, req string) (containerd.Container, error) {
+	var c containerd.Container
+	walker := &containerwalker.ContainerWalker{
+		Client: s.client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s: %w", found.Req, errdefs.ErrInvalidArgument)
+			}
+			c = found.Container
+			return nil
+		},
+	}
+	n, err := walker.Walk(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("No such container: %s: %w", req, errdefs.ErrNotFound)
+	}
+	return c, nil
+}
+
+// taskStatus returns the status of the task of c, or containerd.Unknown when c has no task.
+func taskStatus(ctx context.Context, c containerd.Container) (containerd.Status, error) {
+	task, err := c.Task(ctx, nil)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return containerd.Status{Status: containerd.Unknown}, nil
+		}
+		return containerd.Status{}, err
+	}
+	return task.Status(ctx)
+}
+
+func isRunning(st containerd.Status) bool {
+	return st.Status == containerd.Running || st.Status == containerd.Paused || st.Status == containerd.Pausing
+}
+
+// inspect returns the dockercompat representation of c.
+func (s *Server) inspect(ctx context.Context, c containerd.Container) (*containerJSON, error) {
+	entries, err := container.Inspect(ctx, s.client, []string{c.ID()}, types.ContainerInspectOptions{
+		GOptions: s.gOptions,
+		Mode:     "dockercompat",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) != 1 {
+		return nil, fmt.Errorf("No such container: %s: %w", c.ID(), errdefs.ErrNotFound)
+	}
+	d, ok := entries[0].(*dockercompat.Container)
+	if !ok {
+		return nil, fmt.Errorf("unexpected inspect result %T", entries[0])
+	}
+	res := &containerJSON{
+		Container: d,
+		Name:      "/" + d.Name,
+		Config: &containerConfig{
+			Config: d.Config,
+			Image:  d.Image,
+		},
+	}
+	if res.Config.Config == nil {
+		res.Config.Config = &dockercompat.Config{}
+	}
+	if spec, err := c.Spec(ctx); err == nil && spec.Process != nil {
+		res.Config.Tty = spec.Process.Terminal
+	}
+	return res, nil
+}
+
+func (s *Server) containerList(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	all, err := queryBool(r, "all")
+	if err != nil {
+		return err
+	}
+	filters, err := queryFilters(r)
+	if err != nil {
+		return err
+	}
+	options := types.ContainerListOptions{
+		GOptions: s.gOptions,
+		All:      all,
+		Filters:  filters,
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if options.LastN, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("invalid limit %q: %w", v, errdefs.ErrInvalidArgument)
+		}
+	}
+	items, err := container.List(ctx, s.client, options)
+	if err != nil {
+		return err
+	}
+	result := []dockercontainer.Summary{}
+	for _, item := range items {
+		if item.ID == "" {
+			// removed while listing
+			continue
+		}
+		c, err := s.client.LoadContainer(ctx, item.ID)
+		if err != nil {
+			continue
+		}
+		d, err := s.inspect(ctx, c)
+		if err != nil {
+			continue
+		}
+		result = append(result, containerSummary(item, d))
+	}
+	return writeJSON(w, http.StatusOK, result)
+}
+
+func containerSummary(item container.ListItem, d *containerJSON) dockercontainer.Summary {
+	command, err := strconv.Unquote(item.Command)
+	if err != nil {
+		command = item.Command
+	}
+	summary := dockercontainer.Summary{
+		ID:      d.ID,
+		Names:   []string{d.Name},
+		Image:   d.Image,
+		Command: command,
+		Created: item.CreatedAt.Unix(),
+		Ports:   []dockercontainer.Port{},
+		Labels:  item.LabelsMap,
+		Status:  item.Status,
+		Mounts:  []dockercontainer.MountPoint{},
+		NetworkSettings: &dockercontainer.NetworkSettingsSummary{
+			Networks: map[string]*network.EndpointSettings{},
+		},
+	}
+	if d.State != nil {
+		summary.State = d.State.Status
+	}
+	if d.NetworkSettings != nil {
+		if d.NetworkSettings.Ports != nil {
+			summary.Ports = containerPorts(*d.NetworkSettings.Ports)
+		}
+		for name, ep := range d.NetworkSettings.Networks {
+			summary.NetworkSettings.Networks[name] = &network.EndpointSettings{
+				IPAddress:           ep.IPAddress,
+				IPPrefixLen:         ep.IPPrefixLen,
+				GlobalIPv6Address:   ep.GlobalIPv6Address,
+				GlobalIPv6PrefixLen: ep.GlobalIPv6PrefixLen,
+				MacAddress:          ep.MacAddress,
+			}
+		}
+	}
+	for _, m := range d.Mounts {
+		summary.Mounts = append(summary.Mounts, dockercontainer.MountPoint{
+			Type:        mount.Type(m.Type),
+			Name:        m.Name,
+			Source:      m.Source,
+			Destination: m.Destination,
+			Driver:      m.Driver,
+			Mode:        m.Mode,
+			RW:          m.RW,
+			Propagation: mount.Propagation(m.Propagation),
+		})
+	}
+	return summary
+}
+
+func containerPorts(portMap nat.PortMap) []dockercontainer.Port {
+	ports := []dockercontainer.Port{}
+	for port, bindings := range portMap {
+		if len(bindings) == 0 {
+			ports = append(ports, dockercontainer.Port{PrivatePort: uint16(port.Int()), Type: port.Proto()})
+			continue
+		}
+		for _, b := range bindings {
+			publicPort, _ := strconv.ParseUint(b.HostPort, 10, 16)
+			ports = append(ports, dockercontainer.Port{
+				IP:          b.HostIP,
+				PrivatePort: uint16(port.Int()),
+				PublicPort:  uint16(publicPort),
+				Type:        port.Proto(),
+			})
+		}
+	}
+	return ports
+}
+
+func (s *Server) containerInspect(w http.ResponseWriter, r *http.Request) error {
+	c, err := s.findContainer(r.Context(), r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	d, err := s.inspect(r.Context(), c)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, d)
+}
+
+func (s *Server) containerStart(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	c, err := s.findContainer(ctx, r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	st, err := taskStatus(ctx, c)
+	if err != nil {
+		return err
+	}
+	if st.Status == containerd.Running {
+		return errNotModified
+	}
+	if err := container.Start(ctx, s.client, []string{c.ID()}, types.ContainerStartOptions{
+		Stdout:   io.Discard,
+		GOptions: s.gOptions,
+	}); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// stopTimeout parses the "t" query parameter, in seconds.
+func stopTimeout(r *http.Request) (*time.Duration, error) {
+	v := r.URL.Query().Get("t")
+	if v == "" {
+		return nil, nil
+	}
+	sec, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid t %q: %w", v, errdefs.ErrInvalidArgument)
+	}
+	timeout := time.Duration(sec) * time.Second
+	return &timeout, nil
+}
+
+func (s *Server) containerStop(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	c, err := s.findContainer(ctx, r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	timeout, err := stopTimeout(r)
+	if err != nil {
+		return err
+	}
+	st, err := taskStatus(ctx, c)
+	if err != nil {
+		return err
+	}
+	if !isRunning(st) {
+		return errNotModified
+	}
+	if err := container.Stop(ctx, s.client, []string{c.ID()}, types.ContainerStopOptions{
+		Stdout:   io.Discard,
+		Stderr:   io.Discard,
+		GOptions: s.gOptions,
+		Timeout:  timeout,
+		Signal:   r.URL.Query().Get("signal"),
+	}); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) containerRestart(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	c, err := s.findContainer(ctx, r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	timeout, err := stopTimeout(r)
+	if err != nil {
+		return err
+	}
+	if err := container.Restart(ctx, s.client, []string{c.ID()}, types.ContainerRestartOptions{
+		Stdout:  io.Discard,
+		GOption: s.gOptions,
+		Timeout: timeout,
+		Signal:  r.URL.Query().Get("signal"),
+	}); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) containerKill(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	c, err := s.findContainer(ctx, r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	st, err := taskStatus(ctx, c)
+	if err != nil {
+		return err
+	}
+	if !isRunning(st) {
+		return fmt.Errorf("container %s is not running: %w", c.ID(), errdefs.ErrConflict)
+	}
+	signal := r.URL.Query().Get("signal")
+	if signal == "" {
+		signal = "SIGKILL"
+	}
+	if err := container.Kill(ctx, s.client, []string{c.ID()}, types.ContainerKillOptions{
+		Stdout:     io.Discard,
+		Stderr:     io.Discard,
+		GOptions:   s.gOptions,
+		KillSignal: signal,
+	}); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) containerWait(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	c, err := s.findContainer(ctx, r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	condition := dockercontainer.WaitCondition(r.URL.Query().Get("condition"))
+	switch condition {
+	case "", dockercontainer.WaitConditionNotRunning, dockercontainer.WaitConditionNextExit, dockercontainer.WaitConditionRemoved:
+	default:
+		return fmt.Errorf("invalid condition %q: %w", condition, errdefs.ErrInvalidArgument)
+	}
+	// The headers are sent before waiting, so that the clients know that the wait has begun,
+	// e.g., before starting the container.
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	var resp dockercontainer.WaitResponse
+	code, err := s.waitContainer(ctx, c, condition)
+	if err != nil {
+		resp.Error = &dockercontainer.WaitExitError{Message: err.Error()}
+	}
+	resp.StatusCode = code
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// waitContainer waits for the condition, and returns the exit code of the container.
+func (s *Server) waitContainer(ctx context.Context, c containerd.Container, condition dockercontainer.WaitCondition) (int64, error) {
+	const pollInterval = 100 * time.Millisecond
+	var code int64
+	for {
+		task, err := c.Task(ctx, nil)
+		if err != nil && !errdefs.IsNotFound(err) {
+			return -1, err
+		}
+		if task != nil {
+			st, err := task.Status(ctx)
+			if err != nil {
+				return -1, err
+			}
+			if st.Status == containerd.Stopped && condition != dockercontainer.WaitConditionNextExit {
+				code = int64(st.ExitStatus)
+				break
+			}
+			if st.Status != containerd.Stopped {
+				statusC, err := task.Wait(ctx)
+				if err != nil {
+					return -1, err
+				}
+				select {
+				case status := <-statusC:
+					code = int64(status.ExitCode())
+				case <-ctx.Done():
+					return -1, ctx.Err()
+				}
+				break
+			}
+		} else if condition != dockercontainer.WaitConditionNextExit {
+			// created, or stopped and its task deleted
+			d, err := s.inspect(ctx, c)
+			if err != nil {
+				return -1, err
+			}
+			if d.State != nil {
+				code = int64(d.State.ExitCode)
+			}
+			break
+		}
+		select {
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+			return -1, ctx.Err()
+		}
+	}
+	if condition == dockercontainer.WaitConditionRemoved {
+		for {
+			if _, err := s.client.LoadContainer(ctx, c.ID()); errdefs.IsNotFound(err) {
+				break
+			}
+			select {
+			case <-time.After(pollInterval):
+			case <-ctx.Done():
+				return -1, ctx.Err()
+			}
+		}
+	}
+	return code, nil
+}
+
+func (s *Server) containerLogs(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	c, err := s.findContainer(ctx, r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	query := r.URL.Query()
+	var flags struct{ stdout, stderr, follow, timestamps bool }
+	for key, p := range map[string]*bool{"stdout": &flags.stdout, "stderr": &flags.stderr, "follow": &flags.follow, "timestamps": &flags.timestamps} {
+		if *p, err = queryBool(r, key); err != nil {
+			return err
+		}
+	}
+	if !flags.stdout && !flags.stderr {
+		return fmt.Errorf("you must choose at least one stream: %w", errdefs.ErrInvalidArgument)
+	}
+	var tail uint
+	if v := query.Get("tail"); v != "" && v != "all" {
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid tail %q: %w", v, errdefs.ErrInvalidArgument)
+		}
+		tail = uint(n)
+	}
+	since, until := query.Get("since"), query.Get("until")
+	if since == "0" {
+		since = ""
+	}
+	if until == "0" {
+		until = ""
+	}
+	var tty bool
+	if spec, err := c.Spec(ctx); err == nil && spec.Process != nil {
+		tty = spec.Process.Terminal
+	}
+
+	out := newStreamWriter(w)
+	stdout, stderr := io.Writer(out), io.Writer(out)
+	if tty {
+		w.Header().Set("Content-Type", "application/vnd.docker.raw-stream")
+	} else {
+		w.Header().Set("Content-Type", "application/vnd.docker.multiplexed-stream")
+		stdout, stderr = stdcopy.NewStdWriter(out, stdcopy.Stdout), stdcopy.NewStdWriter(out, stdcopy.Stderr)
+	}
+	if !flags.stdout {
+		stdout = io.Discard
+	}
+	if !flags.stderr {
+		stderr = io.Discard
+	}
+	w.WriteHeader(http.StatusOK)
+	out.flush()
+
+	if flags.follow {
+		// The log viewer of pkg/cmd/container only stops following on the exit of the container or on a signal,
+		// so following runs the nerdctl binary, which is killed when the client goes away.
+		args := []string{"logs", "--follow"}
+		if flags.timestamps {
+			args = append(args, "--timestamps")
+		}
+		if tail > 0 {
+			args = append(args, "--tail", strconv.FormatUint(uint64(tail), 10))
+		}
+		if since != "" {
+			args = append(args, "--since", since)
+		}
+		if until != "" {
+			args = append(args, "--until", until)
+		}
+		cmd := s.nerdctlCmd(ctx, append(args, c.ID())...)
+		cmd.Stdout, cmd.Stderr = stdout, stderr
+		return cmd.Run()
+	}
+	return container.Logs(ctx, s.client, c.ID(), types.ContainerLogsOptions{
+		Stdout:     stdout,
+		Stderr:     stderr,
+		GOptions:   s.gOptions,
+		Timestamps: flags.timestamps,
+		Tail:       tail,
+		Since:      since,
+		Until:      until,
+	})
+}
+
+func (s *Server) containerRemove(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	c, err := s.findContainer(ctx, r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	force, err := queryBool(r, "force")
+	if err != nil {
+		return err
+	}
+	volumes, err := queryBool(r, "v")
+	if err != nil {
+		return err
+	}
+	if !force {
+		st, err := taskStatus(ctx, c)
+		if err != nil {
+			return err
+		}
+		if isRunning(st) {
+			return fmt.Errorf("cannot remove container %s: container is %s: stop the container before removing or force remove: %w",
+				c.ID(), st.Status, errdefs.ErrConflict)
+		}
+	}
+	if err := container.Remove(ctx, s.client, []string{c.ID()}, types.ContainerRemoveOptions{
+		Stdout:   io.Discard,
+		GOptions: s.gOptions,
+		Force:    force,
+		Volumes:  volumes,
+	}); err != nil {
+		return err
+	}
+	s.removeExecs(c.ID())
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// streamWriter is an io.Writer for streaming a response body, safe for concurrent use.
+// Every write is flushed to the client.
+type streamWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func newStreamWriter(w io.Writer) *streamWriter {
+	return &streamWriter{w: w}
+}
+
+func (sw *streamWriter) Write(p []byte) (int, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	n, err := sw.w.Write(p)
+	if f, ok := sw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return n, err
+}
+
+func (sw *streamWriter) flush() {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if f, ok := sw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
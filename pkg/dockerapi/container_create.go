@@ -0,0 +1,466 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dockerapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/go-connections/nat"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/config"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/defaults"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+	"github.com/containerd/nerdctl/v2/pkg/portutil"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
+)
+
+func (s *Server) containerCreate(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	var req dockercontainer.CreateRequest
+	if err := decode(r, &req); err != nil {
+		return err
+	}
+	if req.Config == nil || req.Config.Image == "" {
+		return fmt.Errorf("config.Image is required: %w", errdefs.ErrInvalidArgument)
+	}
+	name := r.URL.Query().Get("name")
+	if name != "" {
+		if err := s.checkNameAvailable(ctx, name); err != nil {
+			return err
+		}
+	}
+	options, netOpts, warnings, err := createOptions(s.gOptions, name, r.URL.Query().Get("platform"), req)
+	if err != nil {
+		return err
+	}
+	options.NerdctlCmd, options.NerdctlArgs = s.options.NerdctlCmd, s.options.NerdctlArgs
+	netManager, err := containerutil.NewNetworkingOptionsManager(options.GOptions, netOpts, s.client)
+	if err != nil {
+		return err
+	}
+	args := append([]string{req.Config.Image}, req.Config.Cmd...)
+	c, gc, err := container.Create(ctx, s.client, args, netManager, options)
+	if err != nil {
+		if gc != nil {
+			gc()
+		}
+		return fmt.Errorf("failed to create the container: %w", err)
+	}
+	return writeJSON(w, http.StatusCreated, dockercontainer.CreateResponse{
+		ID:       c.ID(),
+		Warnings: warnings,
+	})
+}
+
+func (s *Server) checkNameAvailable(ctx context.Context, name string) error {
+	containers, err := s.client.Containers(ctx, fmt.Sprintf("labels.%q==%s", labels.Name, name))
+	if err != nil {
+		return err
+	}
+	if len(containers) > 0 {
+		return fmt.Errorf("the container name %q is already in use by container %q: %w", name, containers[0].ID(), errdefs.ErrConflict)
+	}
+	return nil
+}
+
+// createOptions translates a container create request into the options of `nerdctl create`, with the defaults of
+// its flags. The equivalent flags are recorded in CreateFlags, for `nerdctl container auto-update`.
+// The fields that cannot be translated are returned as warnings.
+func createOptions(gOptions types.GlobalCommandOptions, name, platform string, req dockercontainer.CreateRequest) (opt types.ContainerCreateOptions, netOpts types.NetworkOptions, warnings []string, err error) {
+	nerdctlConfig := config.Config(gOptions)
+	defaultLabels, err := nerdctlConfig.DefaultLabels()
+	if err != nil {
+		return opt, netOpts, nil, err
+	}
+	config := req.Config
+	hostConfig := req.HostConfig
+	if hostConfig == nil {
+		hostConfig = &dockercontainer.HostConfig{}
+	}
+	if (platform == "windows" || platform == "freebsd") && !gOptions.Experimental {
+		return opt, netOpts, nil, fmt.Errorf("%s requires experimental mode to be enabled: %w", platform, errdefs.ErrInvalidArgument)
+	}
+	opt = types.ContainerCreateOptions{
+		Stdout:             io.Discard,
+		Stderr:             io.Discard,
+		GOptions:           gOptions,
+		Detach:             true,
+		Restart:            "no",
+		Pull:               "missing",
+		Platform:           platform,
+		InitProcessFlag:    gOptions.Init,
+		Isolation:          "default",
+		CPUQuota:           -1,
+		MemorySwappiness64: -1,
+		PidsLimit:          -1,
+		Cgroupns:           defaults.CgroupnsMode(),
+		Systemd:            "false",
+		Runtime:            defaults.Runtime,
+		ReadOnlyTmpfsSize:  "64m",
+		TZ:                 gOptions.TZ,
+		Admission:          gOptions.Admission,
+		DefaultLabels:      defaultLabels,
+		LogDriver:          "json-file",
+		ImagePullOpt: types.ImagePullOptions{
+			GOptions:      gOptions,
+			VerifyOptions: types.ImageVerifyOptions{Provider: "none"},
+			Stdout:        io.Discard,
+			Stderr:        io.Discard,
+			Quiet:         true,
+		},
+	}
+	flag := func(name, value string) {
+		opt.CreateFlags = append(opt.CreateFlags, "--"+name+"="+value)
+	}
+	flagIf := func(cond bool, name string) {
+		if cond {
+			opt.CreateFlags = append(opt.CreateFlags, "--"+name)
+		}
+	}
+	ignored := func(field string) {
+		warnings = append(warnings, fmt.Sprintf("%s is not supported by nerdctl, ignored", field))
+	}
+
+	if name != "" {
+		opt.Name, opt.NameChanged = name, true
+		flag("name", name)
+	}
+	if platform != "" {
+		flag("platform", platform)
+	}
+
+	// Config
+	if config.Hostname != "" {
+		netOpts.Hostname = config.Hostname
+		flag("hostname", config.Hostname)
+	}
+	if config.Domainname != "" {
+		netOpts.Domainname = config.Domainname
+		flag("domainname", config.Domainname)
+	}
+	if config.User != "" {
+		opt.User = config.User
+		flag("user", config.User)
+	}
+	opt.TTY = config.Tty
+	flagIf(config.Tty, "tty")
+	// like `nerdctl create --interactive`, the stdin is not attached to the created container
+	flagIf(config.OpenStdin, "interactive")
+	for _, env := range config.Env {
+		opt.Env = append(opt.Env, env)
+		flag("env", env)
+	}
+	for _, k := range sortedKeys(config.Labels) {
+		opt.Label = append(opt.Label, k+"="+config.Labels[k])
+		flag("label", k+"="+config.Labels[k])
+	}
+	if config.WorkingDir != "" {
+		opt.Workdir = config.WorkingDir
+		flag("workdir", config.WorkingDir)
+	}
+	if config.StopSignal != "" {
+		opt.StopSignal = config.StopSignal
+		flag("stop-signal", config.StopSignal)
+	}
+	if config.StopTimeout != nil {
+		opt.StopTimeout = *config.StopTimeout
+		flag("stop-timeout", strconv.Itoa(*config.StopTimeout))
+	}
+	if config.Entrypoint != nil {
+		opt.EntrypointChanged = true
+		if len(config.Entrypoint) == 0 {
+			opt.Entrypoint = []string{""}
+			flag("entrypoint", "")
+		}
+		for _, e := range config.Entrypoint {
+			opt.Entrypoint = append(opt.Entrypoint, e)
+			flag("entrypoint", e)
+		}
+	}
+	if config.Healthcheck != nil {
+		ignored("Healthcheck")
+	}
+	for _, port := range sortedKeys(config.ExposedPorts) {
+		if _, ok := hostConfig.PortBindings[port]; !ok && !hostConfig.PublishAllPorts {
+			ignored("ExposedPorts " + string(port))
+		}
+	}
+
+	// HostConfig
+	for _, bind := range hostConfig.Binds {
+		opt.Volume = append(opt.Volume, bind)
+		flag("volume", bind)
+	}
+	for _, m := range hostConfig.Mounts {
+		opt.Mount = append(opt.Mount, mountFlag(m))
+		flag("mount", mountFlag(m))
+	}
+	for _, dst := range sortedKeys(hostConfig.Tmpfs) {
+		if opts := hostConfig.Tmpfs[dst]; opts != "" {
+			dst += ":" + opts
+		}
+		opt.Tmpfs = append(opt.Tmpfs, dst)
+		flag("tmpfs", dst)
+	}
+	for _, port := range sortedKeys(hostConfig.PortBindings) {
+		for _, b := range hostConfig.PortBindings[port] {
+			pm, err := portutil.ParseFlagP(publishFlag(port, b))
+			if err != nil {
+				return opt, netOpts, nil, fmt.Errorf("invalid port binding of %s: %w: %w", port, err, errdefs.ErrInvalidArgument)
+			}
+			netOpts.PortMappings = append(netOpts.PortMappings, pm...)
+			flag("publish", publishFlag(port, b))
+		}
+	}
+	netOpts.PublishAll = hostConfig.PublishAllPorts
+	flagIf(hostConfig.PublishAllPorts, "publish-all")
+	if mode := string(hostConfig.NetworkMode); mode != "" && mode != "default" {
+		netOpts.NetworkSlice = append(netOpts.NetworkSlice, mode)
+		flag("network", mode)
+	}
+	for _, host := range hostConfig.ExtraHosts {
+		netOpts.AddHost = append(netOpts.AddHost, host)
+		flag("add-host", host)
+	}
+	for _, dns := range hostConfig.DNS {
+		netOpts.DNSServers = append(netOpts.DNSServers, dns)
+		flag("dns", dns)
+	}
+	for _, o := range hostConfig.DNSOptions {
+		netOpts.DNSResolvConfOptions = append(netOpts.DNSResolvConfOptions, o)
+		flag("dns-opt", o)
+	}
+	for _, search := range hostConfig.DNSSearch {
+		netOpts.DNSSearchDomains = append(netOpts.DNSSearchDomains, search)
+		flag("dns-search", search)
+	}
+	opt.Rm = hostConfig.AutoRemove
+	flagIf(hostConfig.AutoRemove, "rm")
+	if policy := restartFlag(hostConfig.RestartPolicy); policy != "" {
+		opt.Restart = policy
+		flag("restart", policy)
+	}
+	if hostConfig.Init != nil {
+		opt.InitProcessFlag = *hostConfig.Init
+		flagIf(*hostConfig.Init, "init")
+	}
+	if opt.InitProcessFlag {
+		initBinary := "tini"
+		if gOptions.InitBinary != "" {
+			initBinary = gOptions.InitBinary
+		}
+		opt.InitBinary = &initBinary
+	}
+	opt.Privileged = hostConfig.Privileged
+	flagIf(hostConfig.Privileged, "privileged")
+	for _, c := range hostConfig.CapAdd {
+		opt.CapAdd = append(opt.CapAdd, c)
+		flag("cap-add", c)
+	}
+	for _, c := range hostConfig.CapDrop {
+		opt.CapDrop = append(opt.CapDrop, c)
+		flag("cap-drop", c)
+	}
+	opt.ReadOnly = hostConfig.ReadonlyRootfs
+	flagIf(hostConfig.ReadonlyRootfs, "read-only")
+	for _, o := range hostConfig.SecurityOpt {
+		opt.SecurityOpt = append(opt.SecurityOpt, o)
+		flag("security-opt", o)
+	}
+	if hostConfig.ShmSize > 0 {
+		opt.ShmSize = strconv.FormatInt(hostConfig.ShmSize, 10)
+		flag("shm-size", opt.ShmSize)
+	}
+	for _, k := range sortedKeys(hostConfig.Sysctls) {
+		opt.Sysctl = append(opt.Sysctl, k+"="+hostConfig.Sysctls[k])
+		flag("sysctl", k+"="+hostConfig.Sysctls[k])
+	}
+	if hostConfig.LogConfig.Type != "" {
+		opt.LogDriver = hostConfig.LogConfig.Type
+		flag("log-driver", hostConfig.LogConfig.Type)
+	}
+	for _, k := range sortedKeys(hostConfig.LogConfig.Config) {
+		opt.LogOpt = append(opt.LogOpt, k+"="+hostConfig.LogConfig.Config[k])
+		flag("log-opt", k+"="+hostConfig.LogConfig.Config[k])
+	}
+	if hostConfig.PidMode != "" {
+		opt.Pid = string(hostConfig.PidMode)
+		flag("pid", opt.Pid)
+	}
+	if hostConfig.IpcMode != "" {
+		opt.IPC = string(hostConfig.IpcMode)
+		flag("ipc", opt.IPC)
+	}
+	if hostConfig.UTSMode != "" {
+		netOpts.UTSNamespace = string(hostConfig.UTSMode)
+		flag("uts", netOpts.UTSNamespace)
+	}
+	if hostConfig.CgroupnsMode != "" {
+		opt.Cgroupns = string(hostConfig.CgroupnsMode)
+		flag("cgroupns", opt.Cgroupns)
+	}
+	for _, g := range hostConfig.GroupAdd {
+		opt.GroupAdd = append(opt.GroupAdd, g)
+		flag("group-add", g)
+	}
+	if hostConfig.OomScoreAdj != 0 {
+		opt.OomScoreAdj, opt.OomScoreAdjChanged = hostConfig.OomScoreAdj, true
+		flag("oom-score-adj", strconv.Itoa(hostConfig.OomScoreAdj))
+	}
+	if hostConfig.Runtime != "" {
+		opt.Runtime = hostConfig.Runtime
+		flag("runtime", hostConfig.Runtime)
+	}
+	if len(hostConfig.Links) > 0 {
+		ignored("Links")
+	}
+	if len(hostConfig.VolumesFrom) > 0 {
+		ignored("VolumesFrom")
+	}
+
+	// HostConfig.Resources
+	if hostConfig.Memory > 0 {
+		opt.Memory = strconv.FormatInt(hostConfig.Memory, 10)
+		flag("memory", opt.Memory)
+	}
+	if hostConfig.NanoCPUs > 0 {
+		opt.CPUs = float64(hostConfig.NanoCPUs) / 1e9
+		flag("cpus", strconv.FormatFloat(opt.CPUs, 'f', -1, 64))
+	}
+	if hostConfig.CPUShares > 0 {
+		opt.CPUShares = uint64(hostConfig.CPUShares)
+		flag("cpu-shares", strconv.FormatInt(hostConfig.CPUShares, 10))
+	}
+	if hostConfig.PidsLimit != nil && *hostConfig.PidsLimit > 0 {
+		opt.PidsLimit = *hostConfig.PidsLimit
+		flag("pids-limit", strconv.FormatInt(*hostConfig.PidsLimit, 10))
+	}
+	for _, u := range hostConfig.Ulimits {
+		ulimit := fmt.Sprintf("%s=%d:%d", u.Name, u.Soft, u.Hard)
+		opt.Ulimit = append(opt.Ulimit, ulimit)
+		flag("ulimit", ulimit)
+	}
+	for _, d := range hostConfig.Devices {
+		device := d.PathOnHost
+		if d.PathInContainer != "" {
+			device += ":" + d.PathInContainer
+		}
+		if d.CgroupPermissions != "" {
+			device += ":" + d.CgroupPermissions
+		}
+		opt.Device = append(opt.Device, device)
+		flag("device", device)
+	}
+
+	// NetworkingConfig
+	if req.NetworkingConfig != nil {
+		for _, netName := range sortedKeys(req.NetworkingConfig.EndpointsConfig) {
+			ep := req.NetworkingConfig.EndpointsConfig[netName]
+			if netName != string(hostConfig.NetworkMode) {
+				netOpts.NetworkSlice = append(netOpts.NetworkSlice, netName)
+				flag("network", netName)
+			}
+			if ep == nil {
+				continue
+			}
+			if ep.IPAMConfig != nil && ep.IPAMConfig.IPv4Address != "" {
+				netOpts.IPAddress = ep.IPAMConfig.IPv4Address
+				flag("ip", ep.IPAMConfig.IPv4Address)
+			}
+			if len(ep.Aliases) > 0 {
+				ignored("Aliases of network " + netName)
+			}
+		}
+	}
+	if len(netOpts.NetworkSlice) == 0 {
+		netOpts.NetworkSlice = []string{netutil.DefaultNetworkName}
+	}
+	netOpts.NetworkSlice = strutil.DedupeStrSlice(netOpts.NetworkSlice)
+	return opt, netOpts, warnings, nil
+}
+
+// mountFlag returns the value of `--mount` for m.
+func mountFlag(m mount.Mount) string {
+	fields := []string{"type=" + string(m.Type)}
+	if m.Source != "" {
+		fields = append(fields, "source="+m.Source)
+	}
+	fields = append(fields, "target="+m.Target)
+	if m.ReadOnly {
+		fields = append(fields, "readonly")
+	}
+	if m.BindOptions != nil && m.BindOptions.Propagation != "" {
+		fields = append(fields, "bind-propagation="+string(m.BindOptions.Propagation))
+	}
+	if m.TmpfsOptions != nil {
+		if m.TmpfsOptions.SizeBytes > 0 {
+			fields = append(fields, "tmpfs-size="+strconv.FormatInt(m.TmpfsOptions.SizeBytes, 10))
+		}
+		if m.TmpfsOptions.Mode != 0 {
+			fields = append(fields, fmt.Sprintf("tmpfs-mode=%o", m.TmpfsOptions.Mode))
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// publishFlag returns the value of `--publish` for a port binding, e.g., "127.0.0.1:8080:80/tcp".
+func publishFlag(port nat.Port, b nat.PortBinding) string {
+	s := port.Port() + "/" + port.Proto()
+	switch {
+	case b.HostIP != "":
+		s = b.HostIP + ":" + b.HostPort + ":" + s
+	case b.HostPort != "":
+		s = b.HostPort + ":" + s
+	}
+	return s
+}
+
+// restartFlag returns the value of `--restart` for p, or an empty string for the default policy.
+func restartFlag(p dockercontainer.RestartPolicy) string {
+	switch p.Name {
+	case "", dockercontainer.RestartPolicyDisabled:
+		return ""
+	case dockercontainer.RestartPolicyOnFailure:
+		if p.MaximumRetryCount > 0 {
+			return fmt.Sprintf("%s:%d", p.Name, p.MaximumRetryCount)
+		}
+	}
+	return string(p.Name)
+}
+
+func sortedKeys[K ~string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,253 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package dockerapi implements the server of `nerdctl system docker-api`, which serves the commonly used subset of the
+// Docker Engine API (containers, images, exec, volumes, and networks), so that the tools written for Docker
+// (e.g., Testcontainers) work against containerd. The supported subset is documented in docs/docker-api.md.
+//
+// The requests are served with the packages under pkg/cmd, except for following the logs, which runs the nerdctl
+// binary, like pkg/composer does.
+package dockerapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/filters"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/pkg/namespaces"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+)
+
+const (
+	// APIVersion is the version of the Docker Engine API that the server reports.
+	APIVersion = "1.43"
+	// MinAPIVersion is the minimum version of the Docker Engine API that the server accepts.
+	MinAPIVersion = "1.24"
+)
+
+// versionPrefix matches the optional version prefix of the paths, e.g., "/v1.43".
+var versionPrefix = regexp.MustCompile(`^/v[0-9]+\.[0-9]+/`)
+
+// Options specifies options for New.
+type Options struct {
+	// NerdctlCmd is the path of the nerdctl binary, for the OCI hooks of the created containers and following the logs.
+	NerdctlCmd string
+	// NerdctlArgs is the global flags passed to NerdctlCmd.
+	NerdctlArgs []string
+}
+
+// Server serves the Docker Engine API.
+type Server struct {
+	client   *containerd.Client
+	gOptions types.GlobalCommandOptions
+	options  Options
+	mux      *http.ServeMux
+
+	mu    sync.Mutex
+	execs map[string]*execInstance
+}
+
+// New returns a Server, which operates in the namespace of gOptions.
+func New(client *containerd.Client, gOptions types.GlobalCommandOptions, options Options) *Server {
+	s := &Server{
+		client:   client,
+		gOptions: gOptions,
+		options:  options,
+		mux:      http.NewServeMux(),
+		execs:    make(map[string]*execInstance),
+	}
+	s.routes()
+	return s
+}
+
+func (s *Server) routes() {
+	s.handle("GET /_ping", s.ping)
+	s.handle("HEAD /_ping", s.ping)
+	s.handle("GET /version", s.version)
+	s.handle("GET /info", s.info)
+
+	s.handle("GET /containers/json", s.containerList)
+	s.handle("POST /containers/create", s.containerCreate)
+	s.handle("GET /containers/{id}/json", s.containerInspect)
+	s.handle("POST /containers/{id}/start", s.containerStart)
+	s.handle("POST /containers/{id}/stop", s.containerStop)
+	s.handle("POST /containers/{id}/restart", s.containerRestart)
+	s.handle("POST /containers/{id}/kill", s.containerKill)
+	s.handle("POST /containers/{id}/wait", s.containerWait)
+	s.handle("GET /containers/{id}/logs", s.containerLogs)
+	s.handle("DELETE /containers/{id}", s.containerRemove)
+
+	s.handle("POST /containers/{id}/exec", s.execCreate)
+	s.handle("POST /exec/{id}/start", s.execStart)
+	s.handle("GET /exec/{id}/json", s.execInspect)
+
+	s.handle("GET /images/json", s.imageList)
+	s.handle("POST /images/create", s.imageCreate)
+	// image names contain slashes, so /images/{name}/json is parsed by the handler
+	s.handle("GET /images/{name...}", s.imageInspect)
+	s.handle("DELETE /images/{name...}", s.imageRemove)
+
+	s.handle("GET /volumes", s.volumeList)
+	s.handle("POST /volumes/create", s.volumeCreate)
+	s.handle("GET /volumes/{name}", s.volumeInspect)
+	s.handle("DELETE /volumes/{name}", s.volumeRemove)
+
+	s.handle("GET /networks", s.networkList)
+	s.handle("POST /networks/create", s.networkCreate)
+	s.handle("GET /networks/{id}", s.networkInspect)
+	s.handle("DELETE /networks/{id}", s.networkRemove)
+}
+
+func (s *Server) handle(pattern string, handler func(w http.ResponseWriter, r *http.Request) error) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if err := handler(w, r); err != nil {
+			log.G(r.Context()).WithError(err).Debugf("%s %s failed", r.Method, r.URL.Path)
+			writeError(w, err)
+		}
+	})
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// e.g., "/v1.43/containers/json" -> "/containers/json"
+	if loc := versionPrefix.FindStringIndex(r.URL.Path); loc != nil {
+		r.URL.Path = r.URL.Path[loc[1]-1:]
+		r.URL.RawPath = ""
+	}
+	w.Header().Set("Api-Version", APIVersion)
+	w.Header().Set("Server", "nerdctl")
+	ctx := namespaces.WithNamespace(r.Context(), s.gOptions.Namespace)
+	s.mux.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// Serve serves the API on l until ctx is done.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	srv := &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.G(ctx).WithError(err).Warn("failed to shut down the Docker API server")
+		}
+	}()
+	if err := srv.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) nerdctlCmd(ctx context.Context, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, s.options.NerdctlCmd, append(append([]string{}, s.options.NerdctlArgs...), args...)...)
+}
+
+// errNotModified is returned for starting a running container, or stopping a stopped one.
+var errNotModified = errors.New("not modified")
+
+func writeJSON(w http.ResponseWriter, status int, v any) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err in the format of the Docker Engine API.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, errNotModified):
+		w.WriteHeader(http.StatusNotModified)
+		return
+	case errdefs.IsNotFound(err):
+		status = http.StatusNotFound
+	case errdefs.IsInvalidArgument(err):
+		status = http.StatusBadRequest
+	case errdefs.IsAlreadyExists(err), errdefs.IsConflict(err), errdefs.IsFailedPrecondition(err):
+		status = http.StatusConflict
+	case errdefs.IsNotImplemented(err):
+		status = http.StatusNotImplemented
+	}
+	_ = writeJSON(w, status, map[string]string{"message": err.Error()})
+}
+
+// decode decodes a JSON request body. An empty body leaves v unchanged.
+func decode(r *http.Request, v any) error {
+	if r.Body == nil || r.ContentLength == 0 {
+		return nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid request body: %v: %w", err, errdefs.ErrInvalidArgument)
+	}
+	return nil
+}
+
+// queryBool parses a boolean query parameter, which the Docker clients send as "1", "true", "0", or "false".
+func queryBool(r *http.Request, key string) (bool, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: %w", key, v, errdefs.ErrInvalidArgument)
+	}
+	return b, nil
+}
+
+// queryFilters parses the "filters" query parameter, e.g., `{"label":{"foo=bar":true}}` or `{"label":["foo=bar"]}`,
+// into the nerdctl filters, e.g., "label=foo=bar".
+func queryFilters(r *http.Request) ([]string, error) {
+	v := r.URL.Query().Get("filters")
+	if v == "" {
+		return nil, nil
+	}
+	args, err := filters.FromJSON(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filters %q: %v: %w", v, err, errdefs.ErrInvalidArgument)
+	}
+	keys := args.Keys()
+	slices.Sort(keys)
+	var result []string
+	for _, key := range keys {
+		values := args.Get(key)
+		slices.Sort(values)
+		for _, value := range values {
+			result = append(result, key+"="+value)
+		}
+	}
+	return result, nil
+}
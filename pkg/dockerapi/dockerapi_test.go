@@ -0,0 +1,202 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dockerapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	dockerimage "github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+)
+
+func TestServer(t *testing.T) {
+	s := New(nil, types.GlobalCommandOptions{Namespace: "test"}, Options{})
+
+	for _, path := range []string{"/_ping", "/v1.43/_ping", "/v1.24/_ping"} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, rec.Code, http.StatusOK, path)
+		assert.Equal(t, rec.Body.String(), "OK", path)
+		assert.Equal(t, rec.Header().Get("Api-Version"), APIVersion, path)
+	}
+
+	testCases := []struct {
+		method, path string
+		status       int
+	}{
+		{http.MethodGet, "/v1.43/exec/missing/json", http.StatusNotFound},
+		{http.MethodPost, "/v1.43/exec/missing/start", http.StatusNotFound},
+		{http.MethodGet, "/v1.43/images/library/alpine", http.StatusNotFound},
+		{http.MethodGet, "/v1.43/containers/json?all=maybe", http.StatusBadRequest},
+		{http.MethodGet, "/v1.43/containers/json?filters=invalid", http.StatusBadRequest},
+	}
+	for _, tc := range testCases {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		assert.Equal(t, rec.Code, tc.status, tc.path)
+		var resp struct{ Message string }
+		assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &resp), tc.path)
+		assert.Assert(t, resp.Message != "", tc.path)
+	}
+}
+
+func TestQueryFilters(t *testing.T) {
+	testCases := []struct {
+		filters  string
+		expected []string
+	}{
+		{"", nil},
+		{`{"label":{"foo=bar":true},"name":{"web":true}}`, []string{"label=foo=bar", "name=web"}},
+		{`{"label":["b","a"]}`, []string{"label=a", "label=b"}},
+	}
+	for _, tc := range testCases {
+		r := httptest.NewRequest(http.MethodGet, "/containers/json?filters="+url.QueryEscape(tc.filters), nil)
+		got, err := queryFilters(r)
+		assert.NilError(t, err, tc.filters)
+		assert.DeepEqual(t, got, tc.expected)
+	}
+}
+
+func TestCreateOptions(t *testing.T) {
+	stopTimeout := 5
+	initProcess := true
+	req := dockercontainer.CreateRequest{
+		Config: &dockercontainer.Config{
+			Image:        "alpine",
+			Cmd:          []string{"sleep", "infinity"},
+			Env:          []string{"FOO=bar"},
+			Labels:       map[string]string{"b": "2", "a": "1"},
+			Tty:          true,
+			StopTimeout:  &stopTimeout,
+			Entrypoint:   []string{"/bin/sh", "-c"},
+			ExposedPorts: nat.PortSet{"80/tcp": {}, "443/tcp": {}},
+			Healthcheck:  &dockercontainer.HealthConfig{Test: []string{"CMD", "true"}},
+		},
+		HostConfig: &dockercontainer.HostConfig{
+			Binds:         []string{"/src:/dst:ro"},
+			PortBindings:  nat.PortMap{"80/tcp": {{HostIP: "127.0.0.1", HostPort: "8080"}}},
+			AutoRemove:    true,
+			RestartPolicy: dockercontainer.RestartPolicy{Name: dockercontainer.RestartPolicyOnFailure, MaximumRetryCount: 3},
+			Init:          &initProcess,
+			Mounts: []mount.Mount{
+				{Type: mount.TypeVolume, Source: "data", Target: "/data", ReadOnly: true},
+			},
+			Resources: dockercontainer.Resources{
+				Memory:   64 * 1024 * 1024,
+				NanoCPUs: 1500000000,
+			},
+		},
+		NetworkingConfig: &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				"mynet": {Aliases: []string{"db"}},
+			},
+		},
+	}
+	opt, netOpts, warnings, err := createOptions(types.GlobalCommandOptions{Namespace: "test"}, "test", "linux/amd64", req)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, opt.CreateFlags, []string{
+		"--name=test",
+		"--platform=linux/amd64",
+		"--tty",
+		"--env=FOO=bar",
+		"--label=a=1",
+		"--label=b=2",
+		"--stop-timeout=5",
+		"--entrypoint=/bin/sh",
+		"--entrypoint=-c",
+		"--volume=/src:/dst:ro",
+		"--mount=type=volume,source=data,target=/data,readonly",
+		"--publish=127.0.0.1:8080:80/tcp",
+		"--rm",
+		"--restart=on-failure:3",
+		"--init",
+		"--memory=67108864",
+		"--cpus=1.5",
+		"--network=mynet",
+	})
+	assert.Equal(t, opt.Name, "test")
+	assert.Assert(t, opt.TTY && opt.Rm && opt.EntrypointChanged)
+	assert.DeepEqual(t, opt.Entrypoint, []string{"/bin/sh", "-c"})
+	assert.DeepEqual(t, opt.Label, []string{"a=1", "b=2"})
+	assert.Equal(t, opt.StopTimeout, 5)
+	assert.Equal(t, opt.Restart, "on-failure:3")
+	assert.Equal(t, *opt.InitBinary, "tini")
+	assert.Equal(t, opt.Memory, "67108864")
+	assert.Equal(t, opt.CPUs, 1.5)
+	// the defaults of the flags of `nerdctl create`
+	assert.Equal(t, opt.Pull, "missing")
+	assert.Equal(t, opt.LogDriver, "json-file")
+	assert.Equal(t, opt.PidsLimit, int64(-1))
+	assert.DeepEqual(t, netOpts.NetworkSlice, []string{"mynet"})
+	assert.Equal(t, len(netOpts.PortMappings), 1)
+	assert.Equal(t, netOpts.PortMappings[0].HostIP, "127.0.0.1")
+	assert.Equal(t, netOpts.PortMappings[0].HostPort, int32(8080))
+	assert.Equal(t, netOpts.PortMappings[0].ContainerPort, int32(80))
+	assert.DeepEqual(t, warnings, []string{
+		"Healthcheck is not supported by nerdctl, ignored",
+		"ExposedPorts 443/tcp is not supported by nerdctl, ignored",
+		"Aliases of network mynet is not supported by nerdctl, ignored",
+	})
+}
+
+func TestParseRemoveOutput(t *testing.T) {
+	output := "Untagged: docker.io/library/alpine:latest\nDeleted: sha256:aaaa\nDeleted: sha256:bbbb\n"
+	assert.DeepEqual(t, parseRemoveOutput(output), []dockerimage.DeleteResponse{
+		{Untagged: "docker.io/library/alpine:latest"},
+		{Deleted: "sha256:aaaa"},
+		{Deleted: "sha256:bbbb"},
+	})
+}
+
+func TestExecOptions(t *testing.T) {
+	s := New(nil, types.GlobalCommandOptions{Namespace: "test"}, Options{})
+	e := &execInstance{
+		containerID: "abc",
+		options: dockercontainer.ExecOptions{
+			Cmd:          []string{"sh", "-c", "true"},
+			User:         "nobody",
+			AttachStdin:  true,
+			AttachStdout: true,
+		},
+	}
+	assert.DeepEqual(t, execArgs(e), []string{"abc", "sh", "-c", "true"})
+
+	var stdout, stderr bytes.Buffer
+	options := s.execOptions(e, false, strings.NewReader(""), &stdout, &stderr)
+	assert.Assert(t, options.Interactive && !options.Detach && !options.TTY && !options.SigProxy)
+	assert.Equal(t, options.User, "nobody")
+	assert.Equal(t, options.Stdout, io.Writer(&stdout))
+	// stderr is not attached
+	assert.Equal(t, options.Stderr, io.Discard)
+
+	options = s.execOptions(e, true, nil, nil, nil)
+	assert.Assert(t, !options.Interactive && options.Detach)
+	assert.Equal(t, options.Stdout, io.Discard)
+}
@@ -0,0 +1,227 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dockerapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/idgen"
+)
+
+// execInstance is an exec process created by `POST /containers/{id}/exec`.
+type execInstance struct {
+	id          string
+	containerID string
+	options     dockercontainer.ExecOptions
+
+	// the fields below are protected by Server.mu
+	started  bool
+	running  bool
+	exitCode int
+}
+
+func (s *Server) execCreate(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	c, err := s.findContainer(ctx, r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	var options dockercontainer.ExecOptions
+	if err := decode(r, &options); err != nil {
+		return err
+	}
+	if len(options.Cmd) == 0 {
+		return fmt.Errorf("no exec command specified: %w", errdefs.ErrInvalidArgument)
+	}
+	st, err := taskStatus(ctx, c)
+	if err != nil {
+		return err
+	}
+	if st.Status != containerd.Running {
+		return fmt.Errorf("container %s is not running: %w", c.ID(), errdefs.ErrConflict)
+	}
+	e := &execInstance{
+		id:          idgen.GenerateID(),
+		containerID: c.ID(),
+		options:     options,
+	}
+	s.mu.Lock()
+	s.execs[e.id] = e
+	s.mu.Unlock()
+	return writeJSON(w, http.StatusCreated, dockercontainer.ExecCreateResponse{ID: e.id})
+}
+
+func (s *Server) lookupExec(id string) (*execInstance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.execs[id]
+	if !ok {
+		return nil, fmt.Errorf("No such exec instance: %s: %w", id, errdefs.ErrNotFound)
+	}
+	return e, nil
+}
+
+// removeExecs forgets the exec instances of the container.
+func (s *Server) removeExecs(containerID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, e := range s.execs {
+		if e.containerID == containerID {
+			delete(s.execs, id)
+		}
+	}
+}
+
+// execOptions returns the options of `nerdctl exec` for e, with the streams of the client.
+// A pseudo terminal is never allocated, as the server has no terminal to attach to it, so
+// the output of a TTY exec is streamed raw, but without the terminal processing.
+// The signals of the server are not proxied to the process.
+func (s *Server) execOptions(e *execInstance, detach bool, stdin io.Reader, stdout, stderr io.Writer) types.ContainerExecOptions {
+	options := types.ContainerExecOptions{
+		Stdin:       stdin,
+		Stdout:      io.Discard,
+		Stderr:      io.Discard,
+		GOptions:    s.gOptions,
+		Interactive: !detach && e.options.AttachStdin,
+		Detach:      detach,
+		Workdir:     e.options.WorkingDir,
+		Env:         e.options.Env,
+		Privileged:  e.options.Privileged,
+		User:        e.options.User,
+	}
+	if e.options.AttachStdout && stdout != nil {
+		options.Stdout = stdout
+	}
+	if e.options.AttachStderr && stderr != nil {
+		options.Stderr = stderr
+	}
+	return options
+}
+
+// execArgs returns the container and the command of e, as the arguments of container.Exec.
+func execArgs(e *execInstance) []string {
+	return append([]string{e.containerID}, e.options.Cmd...)
+}
+
+func (s *Server) execStart(w http.ResponseWriter, r *http.Request) error {
+	e, err := s.lookupExec(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	var options dockercontainer.ExecStartOptions
+	if err := decode(r, &options); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	if e.started {
+		s.mu.Unlock()
+		return fmt.Errorf("exec %s has already been started: %w", e.id, errdefs.ErrConflict)
+	}
+	e.started, e.running = true, true
+	s.mu.Unlock()
+
+	ctx := r.Context()
+	if options.Detach {
+		err := container.Exec(ctx, s.client, execArgs(e), s.execOptions(e, true, nil, nil, nil))
+		s.finishExec(e, err)
+		if err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+
+	tty := e.options.Tty || options.Tty
+	contentType := "application/vnd.docker.multiplexed-stream"
+	if tty {
+		contentType = "application/vnd.docker.raw-stream"
+	}
+	var (
+		stdin io.Reader
+		out   io.Writer
+	)
+	if r.Header.Get("Upgrade") == "tcp" {
+		// The Docker clients hijack the connection for attaching the streams.
+		conn, buf, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		fmt.Fprintf(buf, "HTTP/1.1 101 UPGRADED\r\nContent-Type: %s\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n", contentType)
+		if err := buf.Flush(); err != nil {
+			return nil
+		}
+		stdin, out = buf.Reader, conn
+	} else {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusOK)
+		sw := newStreamWriter(w)
+		sw.flush()
+		stdin, out = r.Body, sw
+	}
+
+	stdout, stderr := out, out
+	if !tty {
+		stdout, stderr = stdcopy.NewStdWriter(out, stdcopy.Stdout), stdcopy.NewStdWriter(out, stdcopy.Stderr)
+	}
+	s.finishExec(e, container.Exec(ctx, s.client, execArgs(e), s.execOptions(e, false, stdin, stdout, stderr)))
+	// the error has already been reported as the exit code
+	return nil
+}
+
+// finishExec records the exit code of the exec process.
+func (s *Server) finishExec(e *execInstance, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.running = false
+	var exitErr *container.ExecExitError
+	switch {
+	case err == nil:
+		e.exitCode = 0
+	case errors.As(err, &exitErr):
+		e.exitCode = exitErr.Code
+	default:
+		e.exitCode = 126
+	}
+}
+
+func (s *Server) execInspect(w http.ResponseWriter, r *http.Request) error {
+	e, err := s.lookupExec(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	resp := dockercontainer.ExecInspect{
+		ExecID:      e.id,
+		ContainerID: e.containerID,
+		Running:     e.running,
+		ExitCode:    e.exitCode,
+	}
+	s.mu.Unlock()
+	return writeJSON(w, http.StatusOK, resp)
+}
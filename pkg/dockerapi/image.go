@@ -0,0 +1,228 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dockerapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	dockerimage "github.com/docker/docker/api/types/image"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/containerd/errdefs"
+	"github.com/containerd/platforms"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/imagewalker"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/platformutil"
+)
+
+// progressMessage is a message of the progress stream of `POST /images/create`.
+type progressMessage struct {
+	Status      string        `json:"status,omitempty"`
+	ID          string        `json:"id,omitempty"`
+	Error       string        `json:"error,omitempty"`
+	ErrorDetail *errorMessage `json:"errorDetail,omitempty"`
+}
+
+type errorMessage struct {
+	Message string `json:"message"`
+}
+
+// checkImage returns an error wrapping errdefs.ErrNotFound when no image matches req.
+func (s *Server) checkImage(ctx context.Context, req string) error {
+	walker := &imagewalker.ImageWalker{
+		Client: s.client,
+		OnFound: func(ctx context.Context, found imagewalker.Found) error {
+			return nil
+		},
+	}
+	n, err := walker.Walk(ctx, req)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("No such image: %s: %w", req, errdefs.ErrNotFound)
+	}
+	return nil
+}
+
+func (s *Server) inspectImage(ctx context.Context, req string) (*dockercompat.Image, error) {
+	entries, err := image.Inspect(ctx, s.client, []string{req}, types.ImageInspectOptions{
+		GOptions: s.gOptions,
+		Mode:     "dockercompat",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) != 1 {
+		return nil, fmt.Errorf("No such image: %s: %w", req, errdefs.ErrNotFound)
+	}
+	img, ok := entries[0].(*dockercompat.Image)
+	if !ok {
+		return nil, fmt.Errorf("unexpected inspect result %T", entries[0])
+	}
+	return img, nil
+}
+
+func (s *Server) imageList(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	filters, err := queryFilters(r)
+	if err != nil {
+		return err
+	}
+	imageList, err := image.List(ctx, s.client, filters, nil)
+	if err != nil {
+		return err
+	}
+	result := []dockerimage.Summary{}
+	seen := map[string]bool{}
+	for _, img := range imageList {
+		d, err := s.inspectImage(ctx, img.Name)
+		if err != nil || seen[d.ID] {
+			continue
+		}
+		seen[d.ID] = true
+		summary := dockerimage.Summary{
+			ID:          d.ID,
+			RepoTags:    d.RepoTags,
+			RepoDigests: d.RepoDigests,
+			Created:     img.CreatedAt.Unix(),
+			Size:        d.Size,
+			SharedSize:  -1,
+			Containers:  -1,
+			Labels:      map[string]string{},
+		}
+		if created, err := time.Parse(time.RFC3339Nano, d.Created); err == nil {
+			summary.Created = created.Unix()
+		}
+		if d.Config != nil && d.Config.Labels != nil {
+			summary.Labels = d.Config.Labels
+		}
+		if summary.RepoTags == nil {
+			summary.RepoTags = []string{}
+		}
+		if summary.RepoDigests == nil {
+			summary.RepoDigests = []string{}
+		}
+		result = append(result, summary)
+	}
+	return writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) imageCreate(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	query := r.URL.Query()
+	ref := query.Get("fromImage")
+	if ref == "" {
+		// importing from a tarball (fromSrc) is not supported
+		return fmt.Errorf("fromImage is required: %w", errdefs.ErrNotImplemented)
+	}
+	if tag := query.Get("tag"); tag != "" {
+		if strings.Contains(tag, ":") {
+			ref += "@" + tag
+		} else {
+			ref += ":" + tag
+		}
+	}
+	ociSpecPlatforms := []ocispec.Platform{platforms.DefaultSpec()}
+	if platform := query.Get("platform"); platform != "" {
+		var err error
+		if ociSpecPlatforms, err = platformutil.NewOCISpecPlatformSlice(false, []string{platform}); err != nil {
+			return fmt.Errorf("%v: %w", err, errdefs.ErrInvalidArgument)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	out := newStreamWriter(w)
+	enc := json.NewEncoder(out)
+	_ = enc.Encode(progressMessage{Status: "Pulling from " + ref})
+
+	ensured, err := image.EnsureImage(ctx, s.client, ref, types.ImagePullOptions{
+		Stdout:          io.Discard,
+		Stderr:          io.Discard,
+		GOptions:        s.gOptions,
+		OCISpecPlatform: ociSpecPlatforms,
+		Mode:            "always",
+		Quiet:           true,
+	})
+	if err != nil {
+		// the status has already been sent, so the error is reported in the stream
+		return enc.Encode(progressMessage{Error: err.Error(), ErrorDetail: &errorMessage{Message: err.Error()}})
+	}
+	_ = enc.Encode(progressMessage{Status: "Digest: " + ensured.Image.Target().Digest.String()})
+	return enc.Encode(progressMessage{Status: "Status: Downloaded newer image for " + ensured.Ref})
+}
+
+func (s *Server) imageInspect(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	name, ok := strings.CutSuffix(r.PathValue("name"), "/json")
+	if !ok {
+		return fmt.Errorf("page not found: %w", errdefs.ErrNotFound)
+	}
+	if err := s.checkImage(ctx, name); err != nil {
+		return err
+	}
+	img, err := s.inspectImage(ctx, name)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, img)
+}
+
+func (s *Server) imageRemove(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	name := r.PathValue("name")
+	if err := s.checkImage(ctx, name); err != nil {
+		return err
+	}
+	force, err := queryBool(r, "force")
+	if err != nil {
+		return err
+	}
+	var stdout bytes.Buffer
+	if err := image.Remove(ctx, s.client, []string{name}, types.ImageRemoveOptions{
+		Stdout:   &stdout,
+		GOptions: s.gOptions,
+		Force:    force,
+	}); err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, parseRemoveOutput(stdout.String()))
+}
+
+// parseRemoveOutput parses the output of `nerdctl rmi`, i.e., "Untagged: <ref>" and "Deleted: <digest>" lines.
+func parseRemoveOutput(output string) []dockerimage.DeleteResponse {
+	result := []dockerimage.DeleteResponse{}
+	for _, line := range strings.Split(output, "\n") {
+		if v, ok := strings.CutPrefix(line, "Untagged: "); ok {
+			result = append(result, dockerimage.DeleteResponse{Untagged: v})
+		} else if v, ok := strings.CutPrefix(line, "Deleted: "); ok {
+			result = append(result, dockerimage.DeleteResponse{Deleted: v})
+		}
+	}
+	return result
+}
@@ -0,0 +1,219 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dockerapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	dockernetwork "github.com/docker/docker/api/types/network"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/network"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+)
+
+// networkJSON is the response of `GET /networks/{id}`.
+// It adds the fields that the Docker clients expect to dockercompat.Network.
+type networkJSON struct {
+	*dockercompat.Network
+	Driver string
+	Scope  string
+}
+
+func (s *Server) cniEnv() (*netutil.CNIEnv, error) {
+	return netutil.NewCNIEnv(s.gOptions.CNIPath, s.gOptions.CNINetConfPath, netutil.WithNamespace(s.gOptions.Namespace))
+}
+
+// findNetwork returns the CNI network specified by its name, ID, or ID prefix.
+func (s *Server) findNetwork(req string) (*netutil.NetworkConfig, error) {
+	e, err := s.cniEnv()
+	if err != nil {
+		return nil, err
+	}
+	netLists, errs := e.ListNetworksMatch([]string{req}, false)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%v: %w", errs[0], errdefs.ErrInvalidArgument)
+	}
+	switch netList := netLists[req]; len(netList) {
+	case 0:
+		return nil, fmt.Errorf("network %s not found: %w", req, errdefs.ErrNotFound)
+	case 1:
+		return netList[0], nil
+	default:
+		return nil, fmt.Errorf("network %s is ambiguous (%d matches found based on ID prefix): %w", req, len(netList), errdefs.ErrInvalidArgument)
+	}
+}
+
+func (s *Server) inspectNetwork(r *http.Request, netConfig *netutil.NetworkConfig) (*networkJSON, error) {
+	var buf bytes.Buffer
+	if err := network.Inspect(r.Context(), s.client, types.NetworkInspectOptions{
+		Stdout:   &buf,
+		GOptions: s.gOptions,
+		Mode:     "dockercompat",
+		Format:   "{{json .}}",
+		Networks: []string{netConfig.Name},
+	}); err != nil {
+		return nil, err
+	}
+	var d dockercompat.Network
+	if err := json.Unmarshal(buf.Bytes(), &d); err != nil {
+		return nil, err
+	}
+	res := &networkJSON{
+		Network: &d,
+		Scope:   "local",
+	}
+	if len(netConfig.Plugins) > 0 {
+		res.Driver = netConfig.Plugins[0].Network.Type
+	}
+	if res.Labels == nil {
+		res.Labels = map[string]string{}
+	}
+	if res.Containers == nil {
+		res.Containers = map[string]dockercompat.EndpointResource{}
+	}
+	return res, nil
+}
+
+func (s *Server) networkList(w http.ResponseWriter, r *http.Request) error {
+	filters, err := queryFilters(r)
+	if err != nil {
+		return err
+	}
+	var names bytes.Buffer
+	if err := network.List(r.Context(), types.NetworkListOptions{
+		Stdout:   &names,
+		GOptions: s.gOptions,
+		Format:   "{{.Name}}",
+		Filters:  filters,
+	}); err != nil {
+		return err
+	}
+	result := []*networkJSON{}
+	for _, name := range bytes.Fields(names.Bytes()) {
+		netConfig, err := s.findNetwork(string(name))
+		if err != nil {
+			// the pseudo networks "host" and "none"
+			continue
+		}
+		d, err := s.inspectNetwork(r, netConfig)
+		if err != nil {
+			return err
+		}
+		result = append(result, d)
+	}
+	return writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) networkInspect(w http.ResponseWriter, r *http.Request) error {
+	netConfig, err := s.findNetwork(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	d, err := s.inspectNetwork(r, netConfig)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, d)
+}
+
+func (s *Server) networkCreate(w http.ResponseWriter, r *http.Request) error {
+	var req dockernetwork.CreateRequest
+	if err := decode(r, &req); err != nil {
+		return err
+	}
+	if req.Name == "" {
+		return fmt.Errorf("network name is required: %w", errdefs.ErrInvalidArgument)
+	}
+	if _, err := s.findNetwork(req.Name); err == nil {
+		return fmt.Errorf("network with name %s already exists: %w", req.Name, errdefs.ErrConflict)
+	}
+	options := types.NetworkCreateOptions{
+		GOptions: s.gOptions,
+		Name:     req.Name,
+		Driver:   req.Driver,
+		Options:  req.Options,
+	}
+	if options.Driver == "" {
+		options.Driver = "bridge"
+	}
+	if req.EnableIPv6 != nil {
+		options.IPv6 = *req.EnableIPv6
+	}
+	for _, k := range sortedKeys(req.Labels) {
+		options.Labels = append(options.Labels, k+"="+req.Labels[k])
+	}
+	if req.IPAM != nil {
+		options.IPAMDriver = req.IPAM.Driver
+		options.IPAMOptions = req.IPAM.Options
+		for _, c := range req.IPAM.Config {
+			options.Subnets = append(options.Subnets, c.Subnet)
+			// nerdctl supports a single gateway and IP range
+			if c.Gateway != "" {
+				options.Gateway = c.Gateway
+			}
+			if c.IPRange != "" {
+				options.IPRange = c.IPRange
+			}
+		}
+	}
+	if options.IPAMDriver == "" {
+		options.IPAMDriver = "default"
+	}
+	var warning string
+	if req.Internal {
+		warning = "Internal is not supported by nerdctl, ignored"
+	}
+	var stdout bytes.Buffer
+	if err := network.Create(options, &stdout); err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusCreated, dockernetwork.CreateResponse{
+		ID:      string(bytes.TrimSpace(stdout.Bytes())),
+		Warning: warning,
+	})
+}
+
+func (s *Server) networkRemove(w http.ResponseWriter, r *http.Request) error {
+	netConfig, err := s.findNetwork(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	used, err := netutil.UsedNetworks(r.Context(), s.client)
+	if err != nil {
+		return err
+	}
+	if containers, ok := used[netConfig.Name]; ok {
+		return fmt.Errorf("network %s is in use by containers %v: %w", netConfig.Name, containers, errdefs.ErrConflict)
+	}
+	if err := network.Remove(r.Context(), s.client, types.NetworkRemoveOptions{
+		Stdout:   io.Discard,
+		GOptions: s.gOptions,
+		Networks: []string{netConfig.Name},
+	}); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dockerapi
+
+import (
+	"net/http"
+	"runtime"
+
+	dockertypes "github.com/docker/docker/api/types"
+
+	"github.com/containerd/nerdctl/v2/pkg/infoutil"
+	"github.com/containerd/nerdctl/v2/pkg/version"
+)
+
+func (s *Server) ping(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Header().Set("Docker-Experimental", "false")
+	w.Header().Set("Ostype", runtime.GOOS)
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodHead {
+		return nil
+	}
+	_, err := w.Write([]byte("OK"))
+	return err
+}
+
+func (s *Server) version(w http.ResponseWriter, r *http.Request) error {
+	serverVersion, err := infoutil.ServerVersion(r.Context(), s.client)
+	if err != nil {
+		return err
+	}
+	v := dockertypes.Version{
+		Platform: struct{ Name string }{Name: "nerdctl"},
+		Components: []dockertypes.ComponentVersion{
+			{Name: "nerdctl", Version: version.GetVersion(), Details: map[string]string{"GitCommit": version.GetRevision()}},
+		},
+		Version:       version.GetVersion(),
+		APIVersion:    APIVersion,
+		MinAPIVersion: MinAPIVersion,
+		GitCommit:     version.GetRevision(),
+		GoVersion:     runtime.Version(),
+		Os:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		KernelVersion: infoutil.UnameR(),
+	}
+	for _, c := range serverVersion.Components {
+		v.Components = append(v.Components, dockertypes.ComponentVersion{Name: c.Name, Version: c.Version, Details: c.Details})
+	}
+	return writeJSON(w, http.StatusOK, v)
+}
+
+func (s *Server) info(w http.ResponseWriter, r *http.Request) error {
+	info, err := infoutil.Info(r.Context(), s.client, s.gOptions.Snapshotter, s.gOptions.CgroupManager)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, info)
+}
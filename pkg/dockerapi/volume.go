@@ -0,0 +1,148 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dockerapi
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	dockervolume "github.com/docker/docker/api/types/volume"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+)
+
+func dockerVolume(vol *native.Volume) *dockervolume.Volume {
+	v := &dockervolume.Volume{
+		Name:       vol.Name,
+		Driver:     vol.Driver,
+		Mountpoint: vol.Mountpoint,
+		Labels:     map[string]string{},
+		Options:    vol.Options,
+		Scope:      "local",
+	}
+	if v.Driver == "" {
+		v.Driver = "local"
+	}
+	if vol.Labels != nil {
+		v.Labels = *vol.Labels
+	}
+	if v.Options == nil {
+		v.Options = map[string]string{}
+	}
+	return v
+}
+
+func (s *Server) volumeList(w http.ResponseWriter, r *http.Request) error {
+	filters, err := queryFilters(r)
+	if err != nil {
+		return err
+	}
+	vols, err := volume.Volumes(s.gOptions.Namespace, s.gOptions.DataRoot, s.gOptions.Address, false, filters)
+	if err != nil {
+		return err
+	}
+	resp := dockervolume.ListResponse{
+		Volumes:  []*dockervolume.Volume{},
+		Warnings: []string{},
+	}
+	for _, name := range sortedKeys(vols) {
+		vol := vols[name]
+		resp.Volumes = append(resp.Volumes, dockerVolume(&vol))
+	}
+	return writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) volumeCreate(w http.ResponseWriter, r *http.Request) error {
+	var req dockervolume.CreateOptions
+	if err := decode(r, &req); err != nil {
+		return err
+	}
+	var labels []string
+	for _, k := range sortedKeys(req.Labels) {
+		labels = append(labels, k+"="+req.Labels[k])
+	}
+	driver := req.Driver
+	if driver == "local" {
+		driver = ""
+	}
+	vol, err := volume.Create(req.Name, types.VolumeCreateOptions{
+		Stdout:     io.Discard,
+		GOptions:   s.gOptions,
+		Labels:     labels,
+		Driver:     driver,
+		DriverOpts: req.DriverOpts,
+	})
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusCreated, dockerVolume(vol))
+}
+
+func (s *Server) getVolume(name string) (*native.Volume, error) {
+	volStore, err := volume.Store(s.gOptions.Namespace, s.gOptions.DataRoot, s.gOptions.Address)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := volStore.Exists(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("get %s: no such volume: %w", name, errdefs.ErrNotFound)
+	}
+	return volStore.Get(name, false)
+}
+
+func (s *Server) volumeInspect(w http.ResponseWriter, r *http.Request) error {
+	vol, err := s.getVolume(r.PathValue("name"))
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, dockerVolume(vol))
+}
+
+func (s *Server) volumeRemove(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	name := r.PathValue("name")
+	if _, err := s.getVolume(name); err != nil {
+		return err
+	}
+	containers, err := s.client.Containers(ctx)
+	if err != nil {
+		return err
+	}
+	used, err := volume.UsedVolumes(ctx, containers)
+	if err != nil {
+		return err
+	}
+	if _, ok := used[name]; ok {
+		return fmt.Errorf("remove %s: volume is in use: %w", name, errdefs.ErrConflict)
+	}
+	if err := volume.Remove(ctx, s.client, []string{name}, types.VolumeRemoveOptions{
+		Stdout:   io.Discard,
+		GOptions: s.gOptions,
+	}); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package fanotifyutil monitors the files accessed in a mount namespace, using fanotify.
+//
+// The monitor runs as a child process (`nerdctl internal fanotify`) in a new mount namespace,
+// and the processes to be monitored (e.g., a container) join that mount namespace.
+package fanotifyutil
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/containerd/stargz-snapshotter/analyzer/fanotify/conn"
+	"github.com/containerd/stargz-snapshotter/analyzer/fanotify/service"
+)
+
+// Fanotifier is the client of a fanotify monitor process.
+type Fanotifier struct {
+	cmd       *exec.Cmd
+	conn      *conn.Client
+	closeOnce sync.Once
+}
+
+// Spawn spawns `nerdctl internal fanotify /` in a new mount namespace.
+// The mounts made before calling Spawn are visible in the namespace.
+func Spawn() (*Fanotifier, error) {
+	cmd := exec.Command("/proc/self/exe", "internal", "fanotify", "/")
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: syscall.CLONE_NEWNS,
+	}
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to spawn the fanotify monitor: %w", err)
+	}
+	return &Fanotifier{
+		cmd:  cmd,
+		conn: conn.NewClient(stdout, stdin, cmd.Process.Pid, 5*time.Second),
+	}, nil
+}
+
+// Start lets the monitor start to watch the accesses.
+func (f *Fanotifier) Start() error {
+	return f.conn.Start()
+}
+
+// GetPath blocks until a file is accessed, and returns its path.
+// io.EOF is returned after the Fanotifier is closed.
+func (f *Fanotifier) GetPath() (string, error) {
+	return f.conn.GetPath()
+}
+
+// MountNamespacePath returns the path of the monitored mount namespace.
+func (f *Fanotifier) MountNamespacePath() string {
+	return fmt.Sprintf("/proc/%d/ns/mnt", f.cmd.Process.Pid)
+}
+
+// Close kills the monitor process.
+func (f *Fanotifier) Close() error {
+	var err error
+	f.closeOnce.Do(func() {
+		if err = f.cmd.Process.Kill(); err != nil {
+			return
+		}
+		// Wait closes the pipes, so the pending GetPath returns io.EOF
+		_ = f.cmd.Wait()
+	})
+	return err
+}
+
+// Serve is the body of `nerdctl internal fanotify TARGET`.
+// It talks to the Fanotifier over stdio, and notifies the files accessed under the mount point of TARGET.
+func Serve(target string) error {
+	return service.Serve(target, os.Stdin, os.Stdout)
+}
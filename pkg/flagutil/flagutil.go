@@ -21,8 +21,11 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
 
+	"github.com/containerd/log"
+
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
@@ -69,8 +72,28 @@ func ReplaceOrAppendEnvValues(defaults, overrides []string) []string {
 	return results
 }
 
-func parseEnvVars(paths []string) ([]string, error) {
+// EnvFileOptions specifies how the `--env-file` files are parsed.
+type EnvFileOptions struct {
+	// Expand expands "${VAR}" and "$VAR" in the values, with the variables defined by the preceding lines
+	// (including the preceding files), or with the host env.
+	// "${VAR:-default}" and "${VAR-default}" are supported too, and "$$" is a literal "$".
+	Expand bool
+	// Strict makes the malformed lines an error, instead of passing them to the container as they are.
+	Strict bool
+}
+
+// envNameRegexp matches the names of the variables in env files. Dots and hyphens are accepted, as Docker does.
+var envNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+func parseEnvVars(paths []string, opts EnvFileOptions) ([]string, error) {
 	vars := make([]string, 0)
+	defined := make(map[string]string)
+	lookup := func(name string) (string, bool) {
+		if v, ok := defined[name]; ok {
+			return v, true
+		}
+		return os.LookupEnv(name)
+	}
 	for _, path := range paths {
 		f, err := os.Open(path)
 		if err != nil {
@@ -79,12 +102,36 @@ func parseEnvVars(paths []string) ([]string, error) {
 		defer f.Close()
 
 		sc := bufio.NewScanner(f)
-		for sc.Scan() {
+		for lineNo := 1; sc.Scan(); lineNo++ {
 			line := strings.TrimSpace(sc.Text())
 			// skip comment lines and empty line
 			if len(line) == 0 || strings.HasPrefix(line, "#") {
 				continue
 			}
+			// "export FOO=bar" is accepted, so that the file can be sourced by shells too
+			if rest, ok := strings.CutPrefix(line, "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+				line = strings.TrimSpace(rest)
+			}
+			name, value, hasValue := strings.Cut(line, "=")
+			if !envNameRegexp.MatchString(name) {
+				if opts.Strict {
+					return nil, fmt.Errorf("%s:%d: invalid variable name %q", path, lineNo, name)
+				}
+				if name != "" {
+					log.L.Warnf("%s:%d: invalid variable name %q", path, lineNo, name)
+				}
+				vars = append(vars, line)
+				continue
+			}
+			if hasValue {
+				if opts.Expand {
+					if value, err = expandEnv(value, lookup); err != nil {
+						return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+					}
+					line = name + "=" + value
+				}
+				defined[name] = value
+			}
 			vars = append(vars, line)
 		}
 		if err = sc.Err(); err != nil {
@@ -94,6 +141,64 @@ func parseEnvVars(paths []string) ([]string, error) {
 	return vars, nil
 }
 
+// expandEnv expands "${VAR}", "${VAR:-default}", "${VAR-default}", and "$VAR" in s.
+// Undefined variables are expanded to empty strings, like shells do.
+func expandEnv(s string, lookup func(string) (string, bool)) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch next := s[i+1]; {
+		case next == '$':
+			b.WriteByte('$')
+			i++
+		case next == '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated \"${\" in %q", s)
+			}
+			expr := s[i+2 : i+2+end]
+			name, def, op := expr, "", ""
+			if j := strings.IndexAny(expr, ":-"); j >= 0 {
+				name = expr[:j]
+				switch {
+				case strings.HasPrefix(expr[j:], ":-"):
+					op, def = ":-", expr[j+2:]
+				case expr[j] == '-':
+					op, def = "-", expr[j+1:]
+				default:
+					return "", fmt.Errorf("invalid variable reference \"${%s}\"", expr)
+				}
+			}
+			if !envNameRegexp.MatchString(name) {
+				return "", fmt.Errorf("invalid variable reference \"${%s}\"", expr)
+			}
+			v, ok := lookup(name)
+			if (op == "-" && !ok) || (op == ":-" && v == "") {
+				var err error
+				if v, err = expandEnv(def, lookup); err != nil {
+					return "", err
+				}
+			}
+			b.WriteString(v)
+			i += 2 + end
+		case isShellNameByte(next) && !('0' <= next && next <= '9'):
+			j := i + 1
+			for j < len(s) && isShellNameByte(s[j]) {
+				j++
+			}
+			v, _ := lookup(s[i+1 : j])
+			b.WriteString(v)
+			i = j - 1
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String(), nil
+}
+
 func withOSEnv(envs []string) ([]string, error) {
 	newEnvs := make([]string, len(envs))
 
@@ -122,14 +227,21 @@ func withOSEnv(envs []string) ([]string, error) {
 	return newEnvs, nil
 }
 
+// isShellNameByte returns whether c can be a part of "$VAR" without the braces.
+func isShellNameByte(c byte) bool {
+	return c == '_' || ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9')
+}
+
 // MergeEnvFileAndOSEnv combines environment variables from `--env-file` and `--env`.
 // Pass an empty slice if any arg is not used.
-func MergeEnvFileAndOSEnv(envFile []string, env []string) ([]string, error) {
+// When a variable is set multiple times, the last one wins: the later files override the earlier ones,
+// and `--env` overrides the files.
+func MergeEnvFileAndOSEnv(envFile []string, env []string, opts EnvFileOptions) ([]string, error) {
 	var envs []string
 	var err error
 
 	if envFiles := strutil.DedupeStrSlice(envFile); len(envFiles) > 0 {
-		envs, err = parseEnvVars(envFiles)
+		envs, err = parseEnvVars(envFiles, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -143,5 +255,22 @@ func MergeEnvFileAndOSEnv(envFile []string, env []string) ([]string, error) {
 		return nil, err
 	}
 
-	return envs, nil
+	return dedupeEnv(envs), nil
+}
+
+// dedupeEnv keeps the last entry of each variable, at the position of its first entry.
+// An entry without "=" is kept too, as it unsets the variable of the image.
+func dedupeEnv(envs []string) []string {
+	index := make(map[string]int, len(envs))
+	res := make([]string, 0, len(envs))
+	for _, e := range envs {
+		k, _, _ := strings.Cut(e, "=")
+		if i, ok := index[k]; ok {
+			res[i] = e
+			continue
+		}
+		index[k] = len(res)
+		res = append(res, e)
+	}
+	return res
 }
@@ -115,7 +115,7 @@ and_underscore=working too`
 	content += "\n    \t  "
 	tmpFile := tmpFileWithContent(t, content)
 
-	lines, err := parseEnvVars([]string{tmpFile})
+	lines, err := parseEnvVars([]string{tmpFile}, EnvFileOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -138,7 +138,7 @@ func TestParseEnvFileEmptyFile(t *testing.T) {
 	tmpFile := tmpFileWithContent(t, "")
 
 	paths := []string{tmpFile}
-	lines, err := parseEnvVars(paths)
+	lines, err := parseEnvVars(paths, EnvFileOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -150,7 +150,7 @@ func TestParseEnvFileEmptyFile(t *testing.T) {
 
 // Test TestParseEnvFileNonExistentFile for a non existent file.
 func TestParseEnvFileNonExistentFile(t *testing.T) {
-	_, err := parseEnvVars([]string{"foo_bar_baz"})
+	_, err := parseEnvVars([]string{"foo_bar_baz"}, EnvFileOptions{})
 	if err == nil {
 		t.Fatal("ParseEnvFile succeeded; expected failure")
 	}
@@ -276,7 +276,7 @@ func TestParseEnvFileLineTooLongFile(t *testing.T) {
 	content := "foo=" + strings.Repeat("a", bufio.MaxScanTokenSize+42)
 	tmpFile := tmpFileWithContent(t, content)
 
-	_, err := MergeEnvFileAndOSEnv([]string{tmpFile}, nil)
+	_, err := MergeEnvFileAndOSEnv([]string{tmpFile}, nil, EnvFileOptions{})
 	if err == nil {
 		t.Fatal("ParseEnvFile succeeded; expected failure")
 	}
@@ -289,7 +289,7 @@ func TestParseEnvVariableWithNoNameFile(t *testing.T) {
 `
 	tmpFile := tmpFileWithContent(t, content)
 
-	_, err := MergeEnvFileAndOSEnv([]string{tmpFile}, nil)
+	_, err := MergeEnvFileAndOSEnv([]string{tmpFile}, nil, EnvFileOptions{})
 	if nil == err {
 		t.Fatal("if a variable has no name parsing an environment file must fail")
 	}
@@ -300,7 +300,7 @@ func TestMergeEnvFileAndOSEnv(t *testing.T) {
 	content := `HOME`
 	tmpFile := tmpFileWithContent(t, content)
 
-	variables, err := MergeEnvFileAndOSEnv([]string{tmpFile}, []string{"PATH"})
+	variables, err := MergeEnvFileAndOSEnv([]string{tmpFile}, []string{"PATH"}, EnvFileOptions{})
 	if nil != err {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -317,3 +317,63 @@ func TestMergeEnvFileAndOSEnv(t *testing.T) {
 		t.Fatal("the PATH variable is not properly imported as the second variable")
 	}
 }
+
+// Test TestMergeEnvFileAndOSEnvLayering for the later files and --env overriding the earlier files.
+func TestMergeEnvFileAndOSEnvLayering(t *testing.T) {
+	base := tmpFileWithContent(t, "FOO=base\nBAR=base\nexport BAZ=base\n")
+	override := tmpFileWithContent(t, "export\tFOO=override\n")
+
+	variables, err := MergeEnvFileAndOSEnv([]string{base, override}, []string{"BAR=flag"}, EnvFileOptions{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, variables, []string{"FOO=override", "BAR=flag", "BAZ=base"})
+}
+
+// Test TestParseEnvFileExpand for the variable expansion.
+func TestParseEnvFileExpand(t *testing.T) {
+	t.Setenv("NERDCTL_TEST_HOST", "host")
+	t.Setenv("NERDCTL_TEST_EMPTY", "")
+	base := tmpFileWithContent(t, "DIR=/srv\n")
+	content := `A=${DIR}/data
+B=$DIR/$NERDCTL_TEST_HOST
+C=${NERDCTL_TEST_UNDEFINED:-default}
+D=${NERDCTL_TEST_EMPTY:-default} ${NERDCTL_TEST_EMPTY-default}
+E=$$DIR $ ${NERDCTL_TEST_UNDEFINED}
+F=${NERDCTL_TEST_UNDEFINED:-$DIR}
+`
+	tmpFile := tmpFileWithContent(t, content)
+
+	lines, err := parseEnvVars([]string{base, tmpFile}, EnvFileOptions{Expand: true})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, lines, []string{
+		"DIR=/srv",
+		"A=/srv/data",
+		"B=/srv/host",
+		"C=default",
+		"D=default ",
+		"E=$DIR $ ",
+		"F=/srv",
+	})
+
+	// Not expanded by default
+	lines, err = parseEnvVars([]string{tmpFile}, EnvFileOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, lines[0], "A=${DIR}/data")
+
+	for _, content := range []string{"A=${DIR", "A=${DIR:+foo}", "A=${}"} {
+		_, err = parseEnvVars([]string{tmpFileWithContent(t, content)}, EnvFileOptions{Expand: true})
+		assert.Assert(t, err != nil, content)
+	}
+}
+
+// Test TestParseEnvFileStrict for the malformed lines.
+func TestParseEnvFileStrict(t *testing.T) {
+	content := "FOO=bar\nsome space=value\n"
+	tmpFile := tmpFileWithContent(t, content)
+
+	lines, err := parseEnvVars([]string{tmpFile}, EnvFileOptions{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, lines, []string{"FOO=bar", "some space=value"})
+
+	_, err = parseEnvVars([]string{tmpFile}, EnvFileOptions{Strict: true})
+	assert.ErrorContains(t, err, `:2: invalid variable name "some space"`)
+}
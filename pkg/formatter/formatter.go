@@ -79,7 +79,11 @@ func InspectContainerCommand(spec *oci.Spec, trunc, quote bool) string {
 		return ""
 	}
 
-	command := spec.Process.CommandLine + strings.Join(spec.Process.Args, " ")
+	return FormatContainerCommand(spec.Process.CommandLine+strings.Join(spec.Process.Args, " "), trunc, quote)
+}
+
+// FormatContainerCommand formats a command line for `ps` and `inspect`, e.g. one cached in the container lifecycle state.
+func FormatContainerCommand(command string, trunc, quote bool) string {
 	if trunc {
 		command = Ellipsis(command, 20)
 	}
@@ -87,3 +87,42 @@ func TestTimeSinceInHuman(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatContainerCommand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		command  string
+		trunc    bool
+		quote    bool
+		expected string
+	}{
+		{
+			name:     "short command",
+			command:  "sleep infinity",
+			trunc:    true,
+			quote:    true,
+			expected: `"sleep infinity"`,
+		},
+		{
+			name:     "truncated command",
+			command:  "/docker-entrypoint.sh nginx -g daemon off;",
+			trunc:    true,
+			quote:    true,
+			expected: `"/docker-entrypoint.…"`,
+		},
+		{
+			name:     "no-trunc without quotes",
+			command:  "/docker-entrypoint.sh nginx -g daemon off;",
+			expected: "/docker-entrypoint.sh nginx -g daemon off;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, FormatContainerCommand(tt.command, tt.trunc, tt.quote))
+		})
+	}
+}
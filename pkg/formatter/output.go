@@ -0,0 +1,217 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package formatter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Values of the global `--output` flag.
+const (
+	// OutputText is the default, human-readable output.
+	OutputText = "text"
+	// OutputJSON prints a Document instead of the human-readable output.
+	OutputJSON = "json"
+)
+
+// SchemaVersion is the version of the schema of Document.
+// It is incremented when a field is removed or renamed, or when the type of a field is changed.
+// Adding fields, kinds, or errors is not considered to be an incompatible change.
+const SchemaVersion = 1
+
+// Kinds of Document.
+const (
+	KindInspect              = "Inspect"
+	KindAppArmorList         = "AppArmorList"
+	KindBuilderList          = "BuilderList"
+	KindComposeContainerList = "ComposeContainerList"
+	KindComposeImageList     = "ComposeImageList"
+	KindContainerCreate      = "ContainerCreate"
+	KindContainerInspect     = "ContainerInspect"
+	KindContainerKill        = "ContainerKill"
+	KindContainerList        = "ContainerList"
+	KindContainerPause       = "ContainerPause"
+	KindContainerRemove      = "ContainerRemove"
+	KindContainerRestart     = "ContainerRestart"
+	KindContainerStart       = "ContainerStart"
+	KindContainerStop        = "ContainerStop"
+	KindContainerUnpause     = "ContainerUnpause"
+	KindContextInspect       = "ContextInspect"
+	KindContextList          = "ContextList"
+	KindDiskUsage            = "DiskUsage"
+	KindImageHistory         = "ImageHistory"
+	KindImageInspect         = "ImageInspect"
+	KindImageList            = "ImageList"
+	KindImageRemove          = "ImageRemove"
+	KindInfo                 = "Info"
+	KindMachineList          = "MachineList"
+	KindNamespaceInspect     = "NamespaceInspect"
+	KindNamespaceList        = "NamespaceList"
+	KindNetworkInspect       = "NetworkInspect"
+	KindNetworkList          = "NetworkList"
+	KindNetworkRemove        = "NetworkRemove"
+	KindRestartPolicyList    = "RestartPolicyList"
+	KindSecretList           = "SecretList"
+	KindVersion              = "Version"
+	KindVolumeInspect        = "VolumeInspect"
+	KindVolumeList           = "VolumeList"
+	KindVolumeRemove         = "VolumeRemove"
+	KindVolumeSnapshotList   = "VolumeSnapshotList"
+)
+
+// Document is printed by the commands with `--output json`.
+// See docs/output.md for the schema of the items of each kind.
+type Document struct {
+	SchemaVersion int               `json:"SchemaVersion"`
+	Kind          string            `json:"Kind"`
+	Items         []json.RawMessage `json:"Items"`
+	// Errors are the errors that made the command fail, if any.
+	// The items that were processed before the failure are still listed.
+	Errors []string `json:"Errors,omitempty"`
+}
+
+// ActionItem is the item of the Document of the commands acting on objects, e.g., `nerdctl rm`.
+type ActionItem struct {
+	// Target is the object the action was performed on, as printed in the human-readable output,
+	// i.e., the name or the ID given on the command line, or the ID of a created object.
+	Target string `json:"Target"`
+}
+
+// ItemsFrom tells how a DocumentWriter parses the output of a command into items.
+type ItemsFrom int
+
+const (
+	// ItemsFromJSONLines parses each line as an item, as printed by `--format '{{json .}}'`.
+	ItemsFromJSONLines ItemsFrom = iota
+	// ItemsFromJSONArray parses the elements of JSON arrays as items, as printed by the inspect commands.
+	ItemsFromJSONArray
+	// ItemsFromTextLines wraps each line in an ActionItem.
+	ItemsFromTextLines
+	// ItemsFromKeyValueLines parses each "Key: value" line as a {"Key": "value"} item, e.g., {"Deleted": "sha256:..."}.
+	ItemsFromKeyValueLines
+)
+
+// DocumentWriter collects the output of a command, and writes it as a Document on Close.
+// It is safe to write to a DocumentWriter concurrently, as long as each Write carries whole lines.
+type DocumentWriter struct {
+	w    io.Writer
+	kind string
+	from ItemsFrom
+	mu   sync.Mutex
+	buf  bytes.Buffer
+}
+
+// NewDocumentWriter returns a DocumentWriter writing a Document of kind to w.
+func NewDocumentWriter(w io.Writer, kind string, from ItemsFrom) *DocumentWriter {
+	return &DocumentWriter{
+		w:    w,
+		kind: kind,
+		from: from,
+	}
+}
+
+func (dw *DocumentWriter) Write(p []byte) (int, error) {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+	return dw.buf.Write(p)
+}
+
+// Close writes the Document, with the error of the command if any, and returns that error.
+func (dw *DocumentWriter) Close(cmdErr error) error {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+
+	doc := Document{
+		SchemaVersion: SchemaVersion,
+		Kind:          dw.kind,
+		Items:         []json.RawMessage{},
+	}
+	items, err := parseItems(dw.buf.Bytes(), dw.from)
+	if err != nil {
+		return errors.Join(cmdErr, fmt.Errorf("failed to parse the output of the command: %w", err))
+	}
+	doc.Items = append(doc.Items, items...)
+	if cmdErr != nil {
+		doc.Errors = []string{cmdErr.Error()}
+	}
+
+	// Avoid escaping "<", ">", "&", like FormatSlice
+	encoder := json.NewEncoder(dw.w)
+	encoder.SetIndent("", "    ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	return cmdErr
+}
+
+func parseItems(b []byte, from ItemsFrom) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	if from == ItemsFromJSONArray {
+		dec := json.NewDecoder(bytes.NewReader(b))
+		for {
+			var arr []json.RawMessage
+			if err := dec.Decode(&arr); err == io.EOF {
+				return items, nil
+			} else if err != nil {
+				return nil, err
+			}
+			items = append(items, arr...)
+		}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	scanner.Buffer(nil, len(b)+1)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var item any
+		switch from {
+		case ItemsFromJSONLines:
+			if !json.Valid([]byte(line)) {
+				return nil, fmt.Errorf("invalid JSON line %q", line)
+			}
+			items = append(items, json.RawMessage(line))
+			continue
+		case ItemsFromTextLines:
+			item = ActionItem{Target: line}
+		case ItemsFromKeyValueLines:
+			k, v, ok := strings.Cut(line, ": ")
+			if !ok {
+				return nil, fmt.Errorf("invalid line %q, expected \"Key: value\"", line)
+			}
+			item = map[string]string{k: v}
+		default:
+			return nil, fmt.Errorf("unknown items source %d", from)
+		}
+		raw, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, raw)
+	}
+	return items, scanner.Err()
+}
@@ -0,0 +1,121 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package formatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestDocumentWriter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		from     ItemsFrom
+		output   string
+		cmdErr   error
+		expected Document
+	}{
+		{
+			name:   "JSON lines",
+			from:   ItemsFromJSONLines,
+			output: "{\"Name\":\"foo\"}\n{\"Name\":\"bar\"}\n",
+			expected: Document{
+				SchemaVersion: SchemaVersion,
+				Kind:          "Test",
+				Items:         []json.RawMessage{json.RawMessage(`{"Name":"foo"}`), json.RawMessage(`{"Name":"bar"}`)},
+			},
+		},
+		{
+			name:   "JSON array",
+			from:   ItemsFromJSONArray,
+			output: "[\n    {\n        \"Name\": \"foo\"\n    }\n]\n\n",
+			expected: Document{
+				SchemaVersion: SchemaVersion,
+				Kind:          "Test",
+				Items:         []json.RawMessage{json.RawMessage(`{"Name":"foo"}`)},
+			},
+		},
+		{
+			name:   "text lines",
+			from:   ItemsFromTextLines,
+			output: "foo\nbar\n",
+			cmdErr: errors.New("1 errors:\nno such container: baz"),
+			expected: Document{
+				SchemaVersion: SchemaVersion,
+				Kind:          "Test",
+				Items:         []json.RawMessage{json.RawMessage(`{"Target":"foo"}`), json.RawMessage(`{"Target":"bar"}`)},
+				Errors:        []string{"1 errors:\nno such container: baz"},
+			},
+		},
+		{
+			name:   "key-value lines",
+			from:   ItemsFromKeyValueLines,
+			output: "Untagged: foo:latest\nDeleted: sha256:abc\n",
+			expected: Document{
+				SchemaVersion: SchemaVersion,
+				Kind:          "Test",
+				Items:         []json.RawMessage{json.RawMessage(`{"Untagged":"foo:latest"}`), json.RawMessage(`{"Deleted":"sha256:abc"}`)},
+			},
+		},
+		{
+			name: "no output",
+			from: ItemsFromJSONLines,
+			expected: Document{
+				SchemaVersion: SchemaVersion,
+				Kind:          "Test",
+				Items:         []json.RawMessage{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var b bytes.Buffer
+			dw := NewDocumentWriter(&b, "Test", tt.from)
+			_, err := fmt.Fprint(dw, tt.output)
+			assert.NilError(t, err)
+			assert.Equal(t, dw.Close(tt.cmdErr), tt.cmdErr)
+
+			var doc Document
+			assert.NilError(t, json.Unmarshal(b.Bytes(), &doc))
+			expected, err := json.Marshal(tt.expected)
+			assert.NilError(t, err)
+			actual, err := json.Marshal(doc)
+			assert.NilError(t, err)
+			assert.Equal(t, string(actual), string(expected))
+		})
+	}
+}
+
+func TestDocumentWriterInvalidOutput(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+	dw := NewDocumentWriter(&b, "Test", ItemsFromJSONLines)
+	_, err := fmt.Fprintln(dw, "not json")
+	assert.NilError(t, err)
+	assert.ErrorContains(t, dw.Close(nil), "failed to parse the output of the command")
+	assert.Equal(t, b.Len(), 0)
+}
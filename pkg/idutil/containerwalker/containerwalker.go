@@ -22,6 +22,8 @@ import (
 	"regexp"
 	"strings"
 
+	"golang.org/x/sync/errgroup"
+
 	containerd "github.com/containerd/containerd/v2/client"
 
 	"github.com/containerd/nerdctl/v2/pkg/labels"
@@ -39,6 +41,10 @@ type OnFound func(ctx context.Context, found Found) error
 type ContainerWalker struct {
 	Client  *containerd.Client
 	OnFound OnFound
+	// Parallel is the maximum number of reqs that WalkAll walks concurrently when `forceAll`.
+	// OnFound must be safe for concurrent use when Parallel > 1.
+	// Zero or one walks the reqs one by one.
+	Parallel int
 }
 
 // Walk walks containers and calls w.OnFound .
@@ -77,25 +83,57 @@ func (w *ContainerWalker) Walk(ctx context.Context, req string) (int, error) {
 //
 // It can be used when the matchCount is not important (e.g., only care if there
 // is any error or if matchCount == 0 (not found error) when walking all reqs).
-// If `forceAll`, it calls `Walk` on every req
+// If `forceAll`, it calls `Walk` on every req, up to w.Parallel reqs concurrently,
 // and return all errors joined by `\n`. If not `forceAll`, it returns the first error
 // encountered while calling `Walk`.
 func (w *ContainerWalker) WalkAll(ctx context.Context, reqs []string, forceAll bool) error {
-	var errs []string
+	if forceAll && w.Parallel > 1 {
+		return w.walkAllParallel(ctx, reqs)
+	}
+	var errs []error
 	for _, req := range reqs {
-		n, err := w.Walk(ctx, req)
-		if err == nil && n == 0 {
-			err = fmt.Errorf("no such container: %s", req)
-		}
-		if err != nil {
+		if err := w.walkOne(ctx, req); err != nil {
 			if !forceAll {
 				return err
 			}
-			errs = append(errs, err.Error())
+			errs = append(errs, err)
+		}
+	}
+	return joinErrors(errs)
+}
+
+func (w *ContainerWalker) walkAllParallel(ctx context.Context, reqs []string) error {
+	// errs is indexed by reqs, so that the errors are reported in the order of reqs
+	errs := make([]error, len(reqs))
+	var eg errgroup.Group
+	eg.SetLimit(w.Parallel)
+	for i, req := range reqs {
+		eg.Go(func() error {
+			errs[i] = w.walkOne(ctx, req)
+			return nil
+		})
+	}
+	_ = eg.Wait()
+	return joinErrors(errs)
+}
+
+func (w *ContainerWalker) walkOne(ctx context.Context, req string) error {
+	n, err := w.Walk(ctx, req)
+	if err == nil && n == 0 {
+		err = fmt.Errorf("no such container: %s", req)
+	}
+	return err
+}
+
+func joinErrors(errs []error) error {
+	var msgs []string
+	for _, err := range errs {
+		if err != nil {
+			msgs = append(msgs, err.Error())
 		}
 	}
-	if len(errs) > 0 {
-		return fmt.Errorf("%d errors:\n%s", len(errs), strings.Join(errs, "\n"))
+	if len(msgs) > 0 {
+		return fmt.Errorf("%d errors:\n%s", len(msgs), strings.Join(msgs, "\n"))
 	}
 	return nil
 }
@@ -49,19 +49,19 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/imgconfig"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/leaseutil"
 )
 
-type Changes struct {
-	CMD, Entrypoint []string
-}
-
 type Opts struct {
 	Author  string
 	Message string
 	Ref     string
 	Pause   bool
-	Changes Changes
+	Changes imgconfig.Changes
+	// LeaseDuration is the expiration of the lease, renewed during the commit
+	LeaseDuration time.Duration
 }
 
 var (
@@ -69,7 +69,7 @@ var (
 	emptyDigest  = digest.Digest("")
 )
 
-func Commit(ctx context.Context, client *containerd.Client, container containerd.Container, opts *Opts, globalOptions types.GlobalCommandOptions) (digest.Digest, error) {
+func Commit(ctx context.Context, client *containerd.Client, container containerd.Container, opts *Opts, globalOptions types.GlobalCommandOptions) (_ digest.Digest, retErr error) {
 	// Get labels
 	containerLabels, err := container.Labels(ctx)
 	if err != nil {
@@ -166,12 +166,21 @@ func Commit(ctx context.Context, client *containerd.Client, container containerd
 		sn     = client.SnapshotService(snName)
 	)
 
-	// Don't gc me and clean the dirty data after 1 hour!
-	ctx, done, err := client.WithLease(ctx, leases.WithRandomID(), leases.WithExpiration(1*time.Hour))
+	// Don't gc me while committing, and clean the dirty data after the lease expires when aborted
+	ctx, done, err := leaseutil.WithLease(ctx, client.LeasesService(), opts.LeaseDuration)
 	if err != nil {
 		return emptyDigest, fmt.Errorf("failed to create lease for commit: %w", err)
 	}
-	defer done(ctx)
+	defer func() {
+		var deleteOpts []leases.DeleteOpt
+		if retErr != nil {
+			// remove the intermediate content and snapshots now
+			deleteOpts = append(deleteOpts, leases.SynchronousDelete)
+		}
+		if err := done(context.WithoutCancel(ctx), deleteOpts...); err != nil {
+			log.G(ctx).WithError(err).Warn("failed to release the lease for commit")
+		}
+	}()
 
 	// Sync filesystem to make sure that all the data writes in container could be persisted to disk.
 	Sync()
@@ -234,13 +243,7 @@ func generateCommitImageConfig(ctx context.Context, container containerd.Contain
 		return ocispec.Image{}, err
 	}
 
-	// TODO(fuweid): support updating the USER/ENV/... fields?
-	if opts.Changes.CMD != nil {
-		baseConfig.Config.Cmd = opts.Changes.CMD
-	}
-	if opts.Changes.Entrypoint != nil {
-		baseConfig.Config.Entrypoint = opts.Changes.Entrypoint
-	}
+	imgconfig.ApplyChanges(&baseConfig.Config, opts.Changes)
 	if opts.Author == "" {
 		opts.Author = baseConfig.Author
 	}
@@ -0,0 +1,168 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package converter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/images/converter"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/platforms"
+
+	"github.com/containerd/nerdctl/v2/pkg/platformutil"
+)
+
+const (
+	// AttestationReferenceTypeAnnotation is the annotation of an attestation manifest descriptor
+	// that identifies it as an attestation.
+	AttestationReferenceTypeAnnotation = "vnd.docker.reference.type"
+	// AttestationReferenceDigestAnnotation is the annotation of an attestation manifest descriptor
+	// that points to the digest of the image manifest the attestation is about.
+	AttestationReferenceDigestAnnotation = "vnd.docker.reference.digest"
+	// AttestationManifestType is the value of AttestationReferenceTypeAnnotation for attestations.
+	AttestationManifestType = "attestation-manifest"
+)
+
+// IsAttestationManifest returns true if desc is an attestation manifest in an image index.
+func IsAttestationManifest(desc ocispec.Descriptor) bool {
+	return desc.Annotations[AttestationReferenceTypeAnnotation] == AttestationManifestType
+}
+
+// IndexConvertFuncWithAttestations returns a ConvertFunc that reduces an image index to the
+// platforms matched by platformMC, like converter.WithPlatform, while keeping the attestation
+// manifests of the remaining image manifests.
+// Attestation manifests that refer to a removed image manifest, or that are not fully available in
+// the content store, are removed.
+func IndexConvertFuncWithAttestations(platformMC platforms.MatchComparer) converter.ConvertFunc {
+	convert := converter.DefaultIndexConvertFunc(nil, false, platformutil.WithAttestations(platformMC))
+	return func(ctx context.Context, cs content.Store, desc ocispec.Descriptor) (*ocispec.Descriptor, error) {
+		if !images.IsIndexType(desc.MediaType) {
+			return convert(ctx, cs, desc)
+		}
+		pruned, err := pruneAttestations(ctx, cs, desc, platformMC)
+		if err != nil {
+			return nil, err
+		}
+		if pruned != nil {
+			desc = *pruned
+		}
+		newDesc, err := convert(ctx, cs, desc)
+		if err != nil || newDesc != nil {
+			return newDesc, err
+		}
+		return pruned, nil
+	}
+}
+
+// pruneAttestations removes the dangling or unavailable attestation manifests from the index desc.
+// It returns nil if the index does not need to be modified.
+func pruneAttestations(ctx context.Context, cs content.Store, desc ocispec.Descriptor, platformMC platforms.MatchComparer) (*ocispec.Descriptor, error) {
+	b, err := content.ReadBlob(ctx, cs, desc)
+	if err != nil {
+		return nil, err
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(b, &index); err != nil {
+		return nil, err
+	}
+
+	subjects := make(map[string]struct{})
+	for _, m := range index.Manifests {
+		if !IsAttestationManifest(m) && (m.Platform == nil || platformMC.Match(*m.Platform)) {
+			subjects[m.Digest.String()] = struct{}{}
+		}
+	}
+	var (
+		manifests []ocispec.Descriptor
+		removed   []ocispec.Descriptor
+	)
+	for _, m := range index.Manifests {
+		if IsAttestationManifest(m) {
+			_, ok := subjects[m.Annotations[AttestationReferenceDigestAnnotation]]
+			if ok {
+				ok, err = isAvailable(ctx, cs, m)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if !ok {
+				removed = append(removed, m)
+				continue
+			}
+		}
+		manifests = append(manifests, m)
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	info, err := cs.Info(ctx, desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+	labels := info.Labels
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	for _, m := range removed {
+		converter.ClearGCLabels(labels, m.Digest)
+	}
+	index.Manifests = manifests
+	b, err = json.MarshalIndent(index, "", "   ")
+	if err != nil {
+		return nil, err
+	}
+	newDesc := desc
+	newDesc.Digest = digest.FromBytes(b)
+	newDesc.Size = int64(len(b))
+	if err := content.WriteBlob(ctx, cs, newDesc.Digest.String(), bytes.NewReader(b), newDesc, content.WithLabels(labels)); err != nil {
+		return nil, err
+	}
+	return &newDesc, nil
+}
+
+// isAvailable returns true if the manifest desc and all the blobs it refers to are in the content store.
+func isAvailable(ctx context.Context, cs content.Store, desc ocispec.Descriptor) (bool, error) {
+	b, err := content.ReadBlob(ctx, cs, desc)
+	if err != nil {
+		if errors.Is(err, errdefs.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return false, err
+	}
+	for _, blob := range append([]ocispec.Descriptor{manifest.Config}, manifest.Layers...) {
+		if _, err := cs.Info(ctx, blob.Digest); err != nil {
+			if errors.Is(err, errdefs.ErrNotFound) {
+				return false, nil
+			}
+			return false, err
+		}
+	}
+	return true, nil
+}
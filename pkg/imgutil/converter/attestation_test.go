@@ -0,0 +1,99 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package converter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/plugins/content/local"
+	"github.com/containerd/platforms"
+)
+
+func writeTestBlob(t *testing.T, cs content.Store, mediaType string, v any) ocispec.Descriptor {
+	t.Helper()
+	b, err := json.Marshal(v)
+	assert.NilError(t, err)
+	desc := ocispec.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(b), Size: int64(len(b))}
+	assert.NilError(t, content.WriteBlob(context.Background(), cs, desc.Digest.String(), bytes.NewReader(b), desc))
+	return desc
+}
+
+func writeTestManifest(t *testing.T, cs content.Store, name string) ocispec.Descriptor {
+	t.Helper()
+	config := writeTestBlob(t, cs, ocispec.MediaTypeImageConfig, map[string]string{"name": name})
+	return writeTestBlob(t, cs, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    []ocispec.Descriptor{},
+	})
+}
+
+func attestationFor(desc, subject ocispec.Descriptor) ocispec.Descriptor {
+	desc.Platform = &ocispec.Platform{OS: "unknown", Architecture: "unknown"}
+	desc.Annotations = map[string]string{
+		AttestationReferenceTypeAnnotation:   AttestationManifestType,
+		AttestationReferenceDigestAnnotation: subject.Digest.String(),
+	}
+	return desc
+}
+
+func TestIndexConvertFuncWithAttestations(t *testing.T) {
+	ctx := context.Background()
+	cs, err := local.NewStore(t.TempDir())
+	assert.NilError(t, err)
+
+	amd64 := writeTestManifest(t, cs, "amd64")
+	amd64.Platform = &ocispec.Platform{OS: "linux", Architecture: "amd64"}
+	arm64 := writeTestManifest(t, cs, "arm64")
+	arm64.Platform = &ocispec.Platform{OS: "linux", Architecture: "arm64"}
+	amd64Attest := attestationFor(writeTestManifest(t, cs, "amd64-attestation"), amd64)
+	arm64Attest := attestationFor(writeTestManifest(t, cs, "arm64-attestation"), arm64)
+	// An attestation manifest that was not fetched.
+	missingAttest := attestationFor(ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromString("missing"),
+		Size:      7,
+	}, amd64)
+
+	index := writeTestBlob(t, cs, ocispec.MediaTypeImageIndex, ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{amd64, arm64, amd64Attest, arm64Attest, missingAttest},
+	})
+
+	convert := IndexConvertFuncWithAttestations(platforms.Only(*amd64.Platform))
+	newDesc, err := convert(ctx, cs, index)
+	assert.NilError(t, err)
+	assert.Assert(t, newDesc != nil)
+
+	b, err := content.ReadBlob(ctx, cs, *newDesc)
+	assert.NilError(t, err)
+	var newIndex ocispec.Index
+	assert.NilError(t, json.Unmarshal(b, &newIndex))
+	var got []digest.Digest
+	for _, m := range newIndex.Manifests {
+		got = append(got, m.Digest)
+	}
+	assert.DeepEqual(t, got, []digest.Digest{amd64.Digest, amd64Attest.Digest})
+}
@@ -25,4 +25,7 @@ type ConvertedImageInfo struct {
 	// ExtraImages is a set of converter-specific additional images (e.g. external TOC image of eStargz).
 	// The reference format is the same as the "Image" field.
 	ExtraImages []string `json:"ExtraImages"`
+
+	// NydusStats is the statistics of a nydus conversion.
+	NydusStats *NydusStats `json:"NydusStats,omitempty"`
 }
@@ -0,0 +1,158 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package converter
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	nydusconvert "github.com/containerd/nydus-snapshotter/pkg/converter"
+	"github.com/containerd/platforms"
+)
+
+// NydusStats is the statistics of a nydus conversion.
+// The sizes are summed up over the converted platforms, and the blobs shared between the platforms are counted once.
+type NydusStats struct {
+	// SourceSize is the total size of the layers of the source image.
+	SourceSize int64 `json:"SourceSize"`
+	// BootstrapSize is the total size of the nydus bootstraps, i.e., the metadata fetched before starting a container.
+	BootstrapSize int64 `json:"BootstrapSize"`
+	// BlobSize is the total size of the nydus data blobs referenced by the converted image.
+	BlobSize int64 `json:"BlobSize"`
+	// DedupSize is the size of the data blobs reused from the chunk dictionary.
+	DedupSize int64 `json:"DedupSize"`
+	// DedupRatio is DedupSize / BlobSize.
+	DedupRatio float64 `json:"DedupRatio"`
+	// ColdStartSavings is the estimated ratio of the data that does not need to be fetched before
+	// starting a container with lazy pulling, compared to pulling the source image, i.e., 1 - BootstrapSize / SourceSize.
+	// Prefetched files are fetched in the background, so they are not taken into account.
+	ColdStartSavings float64 `json:"ColdStartSavings"`
+}
+
+// NydusBlobs returns the nydus data blobs of the image desc for the platforms matched by platMC.
+func NydusBlobs(ctx context.Context, cs content.Store, desc ocispec.Descriptor, platMC platforms.MatchComparer) (map[digest.Digest]int64, error) {
+	manifests, err := platformManifests(ctx, cs, desc, platMC)
+	if err != nil {
+		return nil, err
+	}
+	blobs := make(map[digest.Digest]int64)
+	for _, m := range manifests {
+		for _, l := range m.Layers {
+			if nydusconvert.IsNydusBlob(l) {
+				blobs[l.Digest] = l.Size
+			}
+		}
+	}
+	return blobs, nil
+}
+
+// NewNydusStats computes the statistics of the conversion of src into the nydus image dst.
+// dictBlobs are the data blobs of the chunk dictionary image, if any.
+func NewNydusStats(ctx context.Context, cs content.Store, src, dst ocispec.Descriptor, platMC platforms.MatchComparer, dictBlobs map[digest.Digest]int64) (*NydusStats, error) {
+	srcManifests, err := platformManifests(ctx, cs, src, platMC)
+	if err != nil {
+		return nil, err
+	}
+	dstManifests, err := platformManifests(ctx, cs, dst, platMC)
+	if err != nil {
+		return nil, err
+	}
+	var stats NydusStats
+	for _, l := range uniqueLayers(srcManifests) {
+		stats.SourceSize += l.Size
+	}
+	for _, l := range uniqueLayers(dstManifests) {
+		switch {
+		case nydusconvert.IsNydusBootstrap(l):
+			stats.BootstrapSize += l.Size
+		case nydusconvert.IsNydusBlob(l):
+			stats.BlobSize += l.Size
+			if _, ok := dictBlobs[l.Digest]; ok {
+				stats.DedupSize += l.Size
+			}
+		}
+	}
+	if stats.BlobSize > 0 {
+		stats.DedupRatio = float64(stats.DedupSize) / float64(stats.BlobSize)
+	}
+	if stats.SourceSize > 0 {
+		stats.ColdStartSavings = 1 - float64(stats.BootstrapSize)/float64(stats.SourceSize)
+	}
+	return &stats, nil
+}
+
+// uniqueLayers returns the layers of the manifests, without duplicates.
+func uniqueLayers(manifests []ocispec.Manifest) []ocispec.Descriptor {
+	var res []ocispec.Descriptor
+	seen := make(map[digest.Digest]struct{})
+	for _, m := range manifests {
+		for _, l := range m.Layers {
+			if _, ok := seen[l.Digest]; !ok {
+				seen[l.Digest] = struct{}{}
+				res = append(res, l)
+			}
+		}
+	}
+	return res
+}
+
+// platformManifests returns the manifests of desc for the platforms matched by platMC.
+func platformManifests(ctx context.Context, cs content.Store, desc ocispec.Descriptor, platMC platforms.MatchComparer) ([]ocispec.Manifest, error) {
+	b, err := content.ReadBlob(ctx, cs, desc)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case images.IsManifestType(desc.MediaType):
+		var m ocispec.Manifest
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, err
+		}
+		return []ocispec.Manifest{m}, nil
+	case images.IsIndexType(desc.MediaType):
+		var index ocispec.Index
+		if err := json.Unmarshal(b, &index); err != nil {
+			return nil, err
+		}
+		var res []ocispec.Manifest
+		for _, child := range index.Manifests {
+			if child.Platform != nil && !platMC.Match(*child.Platform) {
+				continue
+			}
+			if IsAttestationManifest(child) {
+				continue
+			}
+			if _, err := cs.Info(ctx, child.Digest); err != nil {
+				// not pulled
+				continue
+			}
+			m, err := platformManifests(ctx, cs, child, platMC)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, m...)
+		}
+		return res, nil
+	default:
+		return nil, nil
+	}
+}
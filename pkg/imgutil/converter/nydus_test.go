@@ -0,0 +1,114 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package converter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/containerd/v2/plugins/content/local"
+	nydusconvert "github.com/containerd/nydus-snapshotter/pkg/converter"
+	"github.com/containerd/platforms"
+)
+
+func testLayer(name string, size int64, annotations map[string]string) ocispec.Descriptor {
+	return ocispec.Descriptor{
+		MediaType:   ocispec.MediaTypeImageLayerGzip,
+		Digest:      digest.FromString(name),
+		Size:        size,
+		Annotations: annotations,
+	}
+}
+
+func TestNewNydusStats(t *testing.T) {
+	ctx := context.Background()
+	cs, err := local.NewStore(t.TempDir())
+	assert.NilError(t, err)
+
+	nydusBlob := func(name string, size int64) ocispec.Descriptor {
+		l := testLayer(name, size, map[string]string{nydusconvert.LayerAnnotationNydusBlob: "true"})
+		l.MediaType = nydusconvert.MediaTypeNydusBlob
+		return l
+	}
+	config := writeTestBlob(t, cs, ocispec.MediaTypeImageConfig, map[string]string{})
+	src := writeTestBlob(t, cs, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    []ocispec.Descriptor{testLayer("base", 600, nil), testLayer("app", 400, nil)},
+	})
+	dictBlob := nydusBlob("dict", 300)
+	dst := writeTestBlob(t, cs, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers: []ocispec.Descriptor{
+			dictBlob,
+			nydusBlob("app", 100),
+			testLayer("bootstrap", 50, map[string]string{nydusconvert.LayerAnnotationNydusBootstrap: "true"}),
+		},
+	})
+
+	stats, err := NewNydusStats(ctx, cs, src, dst, platforms.All, map[digest.Digest]int64{dictBlob.Digest: dictBlob.Size})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, *stats, NydusStats{
+		SourceSize:       1000,
+		BootstrapSize:    50,
+		BlobSize:         400,
+		DedupSize:        300,
+		DedupRatio:       0.75,
+		ColdStartSavings: 0.95,
+	})
+
+	stats, err = NewNydusStats(ctx, cs, src, dst, platforms.All, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, stats.DedupSize, int64(0))
+	assert.Equal(t, stats.DedupRatio, 0.0)
+}
+
+func TestNydusBlobsIndex(t *testing.T) {
+	ctx := context.Background()
+	cs, err := local.NewStore(t.TempDir())
+	assert.NilError(t, err)
+
+	blob := testLayer("blob", 10, map[string]string{nydusconvert.LayerAnnotationNydusBlob: "true"})
+	blob.MediaType = nydusconvert.MediaTypeNydusBlob
+	config := writeTestBlob(t, cs, ocispec.MediaTypeImageConfig, map[string]string{})
+	amd64 := writeTestBlob(t, cs, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    []ocispec.Descriptor{blob},
+	})
+	amd64.Platform = &ocispec.Platform{OS: "linux", Architecture: "amd64"}
+	// A manifest for another platform, not pulled.
+	arm64 := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromString("arm64"),
+		Size:      5,
+		Platform:  &ocispec.Platform{OS: "linux", Architecture: "arm64"},
+	}
+	index := writeTestBlob(t, cs, ocispec.MediaTypeImageIndex, ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{amd64, arm64},
+	})
+
+	blobs, err := NydusBlobs(ctx, cs, index, platforms.All)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, blobs, map[digest.Digest]int64{blob.Digest: 10})
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/docker/cli/cli/config"
 	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/credentials"
 	"github.com/docker/cli/cli/config/types"
 )
 
@@ -31,15 +32,30 @@ type Credentials = types.AuthConfig
 // NewCredentialsStore returns a CredentialsStore from a directory
 // If path is left empty, the default docker `~/.docker/config.json` will be used
 // In case the docker call fails, we wrap the error with ErrUnableToInstantiate
-func NewCredentialsStore(path string) (*CredentialsStore, error) {
+// Only WithKeychain and WithSystemKeychain are relevant in optFuncs.
+func NewCredentialsStore(path string, optFuncs ...Opt) (*CredentialsStore, error) {
+	var o opts
+	for _, of := range optFuncs {
+		of(&o)
+	}
+	kc, err := keychainFor(o)
+	if err != nil {
+		return nil, err
+	}
 	dockerConfigFile, err := config.Load(path)
 	if err != nil {
 		return nil, errors.Join(ErrUnableToInstantiate, err)
 	}
 
-	return &CredentialsStore{
+	cs := &CredentialsStore{
 		dockerConfigFile: dockerConfigFile,
-	}, nil
+		keychain:         kc,
+	}
+	if cs.keychain != nil {
+		cs.migrateToKeychain()
+	}
+
+	return cs, nil
 }
 
 // CredentialsStore is an abstraction in front of docker config API manipulation
@@ -47,6 +63,26 @@ func NewCredentialsStore(path string) (*CredentialsStore, error) {
 // backward compatibility
 type CredentialsStore struct {
 	dockerConfigFile *configfile.ConfigFile
+	// keychain is used instead of the plain text config file, for the registries without a credential helper
+	keychain Keychain
+}
+
+// store returns the docker credentials store for a server address, honoring the credential helpers
+func (cs *CredentialsStore) store(serverAddress string) credentials.Store {
+	if cs.keychain != nil && cs.credentialHelperFor(serverAddress) == "" {
+		return newKeychainStore(cs.keychain, cs.dockerConfigFile)
+	}
+
+	return cs.dockerConfigFile.GetCredentialsStore(serverAddress)
+}
+
+// credentialHelperFor returns the credential helper configured for a server address, like the docker cli does
+func (cs *CredentialsStore) credentialHelperFor(serverAddress string) string {
+	if helper := cs.dockerConfigFile.CredentialHelpers[serverAddress]; helper != "" {
+		return helper
+	}
+
+	return cs.dockerConfigFile.CredentialsStore
 }
 
 // Erase will remove any and all stored credentials for that registry namespace (including all legacy variants)
@@ -58,7 +94,7 @@ func (cs *CredentialsStore) Erase(registryURL *RegistryURL) (map[string]error, e
 	// Iterate through and delete them one by one
 	errs := make(map[string]error)
 	for _, serverAddress := range logoutList {
-		if err := cs.dockerConfigFile.GetCredentialsStore(serverAddress).Erase(serverAddress); err != nil {
+		if err := cs.store(serverAddress).Erase(serverAddress); err != nil {
 			errs[serverAddress] = err
 		}
 	}
@@ -73,6 +109,44 @@ func (cs *CredentialsStore) Erase(registryURL *RegistryURL) (map[string]error, e
 	return nil, nil
 }
 
+// EraseAll will remove the stored credentials for all registries, from the file and from the credential helpers.
+// The errors are returned by server address. If nothing could be removed, this will error with ErrUnableToErase
+func (cs *CredentialsStore) EraseAll() (map[string]error, error) {
+	all, err := cs.dockerConfigFile.GetAllCredentials()
+	if err != nil {
+		return nil, errors.Join(ErrUnableToRetrieve, err)
+	}
+
+	errs := make(map[string]error)
+	for serverAddress := range all {
+		if err := cs.store(serverAddress).Erase(serverAddress); err != nil {
+			errs[serverAddress] = err
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil, nil
+	}
+	if len(errs) == len(all) {
+		return errs, ErrUnableToErase
+	}
+
+	return errs, nil
+}
+
+// CredentialHelper returns the name of the docker credential helper used for a given registry (e.g., "ecr-login"),
+// either configured for that registry with `credHelpers`, or for all registries with `credsStore`.
+// If the credentials are stored in the config file, the empty string is returned.
+func (cs *CredentialsStore) CredentialHelper(registryURL *RegistryURL) string {
+	for _, identifier := range registryURL.AllIdentifiers() {
+		if helper := cs.dockerConfigFile.CredentialHelpers[identifier]; helper != "" {
+			return helper
+		}
+	}
+
+	return cs.dockerConfigFile.CredentialsStore
+}
+
 // Store will save credentials for a given registry
 // On error, ErrUnableToStore
 func (cs *CredentialsStore) Store(registryURL *RegistryURL, credentials *Credentials) error {
@@ -87,7 +161,7 @@ func (cs *CredentialsStore) Store(registryURL *RegistryURL, credentials *Credent
 	}
 
 	// XXX future namespaced url likely require special handling here
-	if err := cs.dockerConfigFile.GetCredentialsStore(registryURL.CanonicalIdentifier()).Store(*(credentials)); err != nil {
+	if err := cs.store(registryURL.CanonicalIdentifier()).Store(*(credentials)); err != nil {
 		return errors.Join(ErrUnableToStore, err)
 	}
 
@@ -107,7 +181,7 @@ func (cs *CredentialsStore) ShellCompletion() []string {
 // FileStorageLocation will return the file where credentials are stored for a given registry, or the empty string
 // if it is stored / to be stored in a different place (like an OS keychain, with docker credential helpers)
 func (cs *CredentialsStore) FileStorageLocation(registryURL *RegistryURL) string {
-	if store, isFile := (cs.dockerConfigFile.GetCredentialsStore(registryURL.CanonicalIdentifier())).(isFileStore); isFile {
+	if store, isFile := (cs.store(registryURL.CanonicalIdentifier())).(isFileStore); isFile {
 		return store.GetFilename()
 	}
 
@@ -143,7 +217,7 @@ func (cs *CredentialsStore) Retrieve(registryURL *RegistryURL, checkCredStore bo
 	for _, identifier := range variants {
 		var credentials types.AuthConfig
 		// Note that Get does not raise an error on ENOENT
-		credentials, err = cs.dockerConfigFile.GetCredentialsStore(identifier).Get(identifier)
+		credentials, err = cs.store(identifier).Get(identifier)
 		if err != nil {
 			continue
 		}
@@ -399,3 +399,45 @@ func TestWorkingCredentialsStore(t *testing.T) {
 }
 
 // TODO: add more tests that write credentials (specifically to hub locations) to verify they use the canonical id properly
+
+func TestCredentialHelper(t *testing.T) {
+	dir := writeContent(t, `{
+	"credsStore": "desktop",
+	"credHelpers": {
+		"123456789012.dkr.ecr.us-east-1.amazonaws.com": "ecr-login"
+	}
+}`)
+	cs, err := NewCredentialsStore(dir)
+	assert.NilError(t, err)
+
+	ecr, err := Parse("123456789012.dkr.ecr.us-east-1.amazonaws.com")
+	assert.NilError(t, err)
+	assert.Equal(t, cs.CredentialHelper(ecr), "ecr-login")
+
+	other, err := Parse("registry.example")
+	assert.NilError(t, err)
+	assert.Equal(t, cs.CredentialHelper(other), "desktop")
+}
+
+func TestEraseAll(t *testing.T) {
+	dir := writeContent(t, fmt.Sprintf(`{
+	"auths": {
+		"registry.example:443": {
+			"auth": %q
+		},
+		"other.example:5000": {
+			"identitytoken": "token"
+		}
+	}
+}`, base64.StdEncoding.EncodeToString([]byte("username:password"))))
+	cs, err := NewCredentialsStore(dir)
+	assert.NilError(t, err)
+
+	errs, err := cs.EraseAll()
+	assert.NilError(t, err)
+	assert.Equal(t, len(errs), 0)
+
+	cs, err = NewCredentialsStore(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, len(cs.ShellCompletion()), 0)
+}
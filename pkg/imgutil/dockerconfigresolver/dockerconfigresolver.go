@@ -20,12 +20,15 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
 
 	"github.com/containerd/containerd/v2/core/remotes"
 	"github.com/containerd/containerd/v2/core/remotes/docker"
 	dockerconfig "github.com/containerd/containerd/v2/core/remotes/docker/config"
 	"github.com/containerd/errdefs"
 	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/config"
 )
 
 var PushTracker = docker.NewInMemoryTracker()
@@ -35,6 +38,10 @@ type opts struct {
 	skipVerifyCerts bool
 	hostsDirs       []string
 	authCreds       AuthCreds
+	offline         bool
+	registryConfigs map[string]config.RegistryConfig
+	keychain        Keychain
+	systemKeychain  bool
 }
 
 // Opt for New
@@ -78,6 +85,9 @@ func NewHostOptions(ctx context.Context, refHostname string, optFuncs ...Opt) (*
 	for _, of := range optFuncs {
 		of(&o)
 	}
+	if o.offline {
+		return nil, fmt.Errorf("cannot access registry %q: %w", refHostname, ErrOffline)
+	}
 	var ho dockerconfig.HostOptions
 
 	ho.HostDir = func(hostURL string) (string, error) {
@@ -105,7 +115,7 @@ func NewHostOptions(ctx context.Context, refHostname string, optFuncs ...Opt) (*
 	if o.authCreds != nil {
 		ho.Credentials = o.authCreds
 	} else {
-		authCreds, err := NewAuthCreds(refHostname)
+		authCreds, err := NewAuthCreds(refHostname, optFuncs...)
 		if err != nil {
 			return nil, err
 		}
@@ -119,6 +129,18 @@ func NewHostOptions(ctx context.Context, refHostname string, optFuncs ...Opt) (*
 		}
 	}
 
+	hostDir, err := ho.HostDir(refHostname)
+	if err != nil {
+		return nil, err
+	}
+	rt, err := newRegistryTLS(o.registryConfigs, refHostname, hostDir)
+	if err != nil {
+		return nil, err
+	}
+	if rt != nil {
+		ho.UpdateClient = rt.updateClient
+	}
+
 	if o.plainHTTP {
 		ho.DefaultScheme = "http"
 	} else {
@@ -158,11 +180,12 @@ func New(ctx context.Context, refHostname string, optFuncs ...Opt) (remotes.Reso
 // AuthCreds is for docker.WithAuthCreds
 type AuthCreds func(string) (string, string, error)
 
-// NewAuthCreds returns AuthCreds that uses $DOCKER_CONFIG/config.json .
+// NewAuthCreds returns AuthCreds that uses $DOCKER_CONFIG/config.json ,
+// and the keychain specified by WithKeychain or WithSystemKeychain.
 // AuthCreds can be nil.
-func NewAuthCreds(refHostname string) (AuthCreds, error) {
+func NewAuthCreds(refHostname string, optFuncs ...Opt) (AuthCreds, error) {
 	// Note: does not raise an error on ENOENT
-	credStore, err := NewCredentialsStore("")
+	credStore, err := NewCredentialsStore("", optFuncs...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,195 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dockerconfigresolver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/credentials"
+	"github.com/docker/cli/cli/config/types"
+
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+)
+
+// Keychain stores secrets in the keychain of the OS.
+type Keychain interface {
+	// Get returns the secret stored for the key, or an error wrapping errdefs.ErrNotFound
+	Get(key string) ([]byte, error)
+	// Set stores the secret for the key, replacing the existing one
+	Set(key string, secret []byte) error
+	// Delete removes the secret stored for the key. Deleting a missing key is not an error.
+	Delete(key string) error
+}
+
+// keychainService is the name of the service the secrets are stored for, in the keychain
+const keychainService = "nerdctl"
+
+// WithKeychain specifies the keychain used for the registries without a credential helper, instead of storing the
+// credentials in plain text in config.json. A nil keychain disables it.
+//
+// The plain text credentials of config.json are moved to the keychain by NewCredentialsStore.
+func WithKeychain(kc Keychain) Opt {
+	return func(o *opts) {
+		o.keychain = kc
+	}
+}
+
+// WithSystemKeychain enables the keychain of the OS (see NewSystemKeychain and WithKeychain),
+// as configured by the [credentials] table of nerdctl.toml.
+func WithSystemKeychain(b bool) Opt {
+	return func(o *opts) {
+		o.systemKeychain = b
+	}
+}
+
+// keychainFor returns the keychain specified by WithKeychain or WithSystemKeychain, or nil.
+func keychainFor(o opts) (Keychain, error) {
+	if o.keychain != nil || !o.systemKeychain {
+		return o.keychain, nil
+	}
+	kc, err := NewSystemKeychain()
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up the keychain for the registry credentials: %w", err)
+	}
+	return kc, nil
+}
+
+// keychainSecret is the JSON-marshalled secret stored in the keychain
+type keychainSecret struct {
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+	RegistryToken string `json:"registrytoken,omitempty"`
+}
+
+func marshalKeychainSecret(ac types.AuthConfig) ([]byte, error) {
+	return json.Marshal(keychainSecret{
+		Username:      ac.Username,
+		Password:      ac.Password,
+		IdentityToken: ac.IdentityToken,
+		RegistryToken: ac.RegistryToken,
+	})
+}
+
+func hasSecret(ac types.AuthConfig) bool {
+	return ac.Username != "" || ac.Password != "" || ac.IdentityToken != "" || ac.RegistryToken != ""
+}
+
+// keychainStore is a credentials.Store keeping the secrets in the keychain, and the server addresses in config.json
+// (like the docker credential helpers do).
+type keychainStore struct {
+	keychain  Keychain
+	fileStore credentials.Store
+}
+
+func newKeychainStore(kc Keychain, file *configfile.ConfigFile) credentials.Store {
+	return &keychainStore{
+		keychain:  kc,
+		fileStore: credentials.NewFileStore(file),
+	}
+}
+
+func (s *keychainStore) Erase(serverAddress string) error {
+	if err := s.keychain.Delete(serverAddress); err != nil {
+		return err
+	}
+	return s.fileStore.Erase(serverAddress)
+}
+
+func (s *keychainStore) Get(serverAddress string) (types.AuthConfig, error) {
+	b, err := s.keychain.Get(serverAddress)
+	if errors.Is(err, errdefs.ErrNotFound) {
+		// Not migrated yet, or not stored at all
+		return s.fileStore.Get(serverAddress)
+	}
+	if err != nil {
+		return types.AuthConfig{}, err
+	}
+	var secret keychainSecret
+	if err := json.Unmarshal(b, &secret); err != nil {
+		return types.AuthConfig{}, fmt.Errorf("failed to parse the keychain secret of %q: %w", serverAddress, err)
+	}
+	return types.AuthConfig{
+		ServerAddress: serverAddress,
+		Username:      secret.Username,
+		Password:      secret.Password,
+		IdentityToken: secret.IdentityToken,
+		RegistryToken: secret.RegistryToken,
+	}, nil
+}
+
+func (s *keychainStore) GetAll() (map[string]types.AuthConfig, error) {
+	all, err := s.fileStore.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]types.AuthConfig, len(all))
+	for serverAddress := range all {
+		ac, err := s.Get(serverAddress)
+		if err != nil {
+			return nil, err
+		}
+		res[serverAddress] = ac
+	}
+	return res, nil
+}
+
+func (s *keychainStore) Store(ac types.AuthConfig) error {
+	b, err := marshalKeychainSecret(ac)
+	if err != nil {
+		return err
+	}
+	if err := s.keychain.Set(ac.ServerAddress, b); err != nil {
+		return err
+	}
+	// Keep the server address in config.json, so that `nerdctl logout` can find it
+	return s.fileStore.Store(types.AuthConfig{ServerAddress: ac.ServerAddress, Email: ac.Email})
+}
+
+// migrateToKeychain moves the plain text credentials of config.json to the keychain,
+// for the registries without a credential helper.
+func (cs *CredentialsStore) migrateToKeychain() {
+	var migrated []string
+	for serverAddress, ac := range cs.dockerConfigFile.AuthConfigs {
+		if !hasSecret(ac) || cs.credentialHelperFor(serverAddress) != "" {
+			continue
+		}
+		b, err := marshalKeychainSecret(ac)
+		if err == nil {
+			err = cs.keychain.Set(serverAddress, b)
+		}
+		if err != nil {
+			log.L.WithError(err).Warnf("failed to move the credentials of %q to the keychain", serverAddress)
+			continue
+		}
+		cs.dockerConfigFile.AuthConfigs[serverAddress] = types.AuthConfig{Email: ac.Email}
+		migrated = append(migrated, serverAddress)
+	}
+	if len(migrated) == 0 {
+		return
+	}
+	if err := cs.dockerConfigFile.Save(); err != nil {
+		// The credentials are both in the keychain and in the file, until the next attempt
+		log.L.WithError(err).Warnf("failed to remove the credentials moved to the keychain from %s", cs.dockerConfigFile.Filename)
+		return
+	}
+	log.L.Infof("Moved the credentials of %v from %s to the keychain", migrated, cs.dockerConfigFile.Filename)
+}
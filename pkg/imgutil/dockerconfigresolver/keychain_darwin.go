@@ -0,0 +1,87 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dockerconfigresolver
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/errdefs"
+)
+
+// errItemNotFound is the exit code of `security` when the item does not exist
+const errItemNotFound = 44
+
+// NewSystemKeychain returns the login keychain of macOS.
+func NewSystemKeychain() (Keychain, error) {
+	path, err := exec.LookPath("security")
+	if err != nil {
+		return nil, err
+	}
+	return &securityKeychain{path: path}, nil
+}
+
+// securityKeychain uses the macOS keychain through the `security` command.
+// The secrets are hex-encoded, and passed on stdin so that they do not appear in the process list.
+type securityKeychain struct {
+	path string
+}
+
+func (k *securityKeychain) run(stdin string, args ...string) ([]byte, error) {
+	cmd := exec.Command(k.path, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == errItemNotFound {
+			return nil, errdefs.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to run security %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+func (k *securityKeychain) Get(key string) ([]byte, error) {
+	out, err := k.run("", "find-generic-password", "-s", keychainService, "-a", key, "-w")
+	if err != nil {
+		return nil, fmt.Errorf("no secret for %q in the keychain: %w", key, err)
+	}
+	return hex.DecodeString(strings.TrimSpace(string(out)))
+}
+
+func (k *securityKeychain) Set(key string, secret []byte) error {
+	// `security -i` reads the command from stdin
+	command := fmt.Sprintf("add-generic-password -U -s %s -a %s -l %s -w %s\n",
+		keychainService, strconv.Quote(key), strconv.Quote(keychainService+": "+key), hex.EncodeToString(secret))
+	_, err := k.run(command, "-i")
+	return err
+}
+
+func (k *securityKeychain) Delete(key string) error {
+	_, err := k.run("", "delete-generic-password", "-s", keychainService, "-a", key)
+	if errors.Is(err, errdefs.ErrNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,150 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dockerconfigresolver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/containerd/errdefs"
+)
+
+// NewSystemKeychain returns the secret service (e.g., GNOME Keyring, KWallet) when `secret-tool` and a D-Bus session
+// are available, or the persistent kernel keyring of the user otherwise.
+func NewSystemKeychain() (Keychain, error) {
+	if path, err := exec.LookPath("secret-tool"); err == nil && os.Getenv("DBUS_SESSION_BUS_ADDRESS") != "" {
+		return &secretToolKeychain{path: path}, nil
+	}
+	return &keyctlKeychain{}, nil
+}
+
+// secretToolKeychain uses the secret service through `secret-tool` of libsecret.
+type secretToolKeychain struct {
+	path string
+}
+
+func (k *secretToolKeychain) run(stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command(k.path, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run secret-tool %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+func (k *secretToolKeychain) Get(key string) ([]byte, error) {
+	out, err := k.run(nil, "lookup", "service", keychainService, "server", key)
+	if err != nil || len(out) == 0 {
+		// secret-tool exits with 1 when the secret does not exist
+		return nil, fmt.Errorf("no secret for %q in the secret service: %w", key, errors.Join(errdefs.ErrNotFound, err))
+	}
+	return out, nil
+}
+
+func (k *secretToolKeychain) Set(key string, secret []byte) error {
+	_, err := k.run(secret, "store", "--label", keychainService+": "+key, "service", keychainService, "server", key)
+	return err
+}
+
+func (k *secretToolKeychain) Delete(key string) error {
+	if _, err := k.Get(key); errors.Is(err, errdefs.ErrNotFound) {
+		return nil
+	}
+	_, err := k.run(nil, "clear", "service", keychainService, "server", key)
+	return err
+}
+
+// keyctlKeychain uses the persistent keyring of the user, which is kept in the kernel memory
+// across the sessions of the user, but not across reboots.
+type keyctlKeychain struct{}
+
+// keyring returns the persistent keyring of the user, or the user keyring when the kernel does not support
+// persistent keyrings.
+func (k *keyctlKeychain) keyring() (int, error) {
+	id, err := unix.KeyctlInt(unix.KEYCTL_GET_PERSISTENT, -1, unix.KEY_SPEC_PROCESS_KEYRING, 0, 0)
+	if err == nil {
+		return id, nil
+	}
+	if !errors.Is(err, unix.EOPNOTSUPP) && !errors.Is(err, unix.ENOSYS) {
+		return 0, fmt.Errorf("failed to get the persistent keyring: %w", err)
+	}
+	return unix.KeyctlGetKeyringID(unix.KEY_SPEC_USER_KEYRING, true)
+}
+
+func (k *keyctlKeychain) description(key string) string {
+	return keychainService + ":" + key
+}
+
+func (k *keyctlKeychain) search(key string) (keyring, id int, err error) {
+	keyring, err = k.keyring()
+	if err != nil {
+		return 0, 0, err
+	}
+	id, err = unix.KeyctlSearch(keyring, "user", k.description(key), 0)
+	if errors.Is(err, unix.ENOKEY) {
+		return keyring, 0, fmt.Errorf("no key %q in the kernel keyring: %w", k.description(key), errdefs.ErrNotFound)
+	}
+	return keyring, id, err
+}
+
+func (k *keyctlKeychain) Get(key string) ([]byte, error) {
+	_, id, err := k.search(key)
+	if err != nil {
+		return nil, err
+	}
+	size, err := unix.KeyctlBuffer(unix.KEYCTL_READ, id, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	n, err := unix.KeyctlBuffer(unix.KEYCTL_READ, id, buf, 0)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:min(n, size)], nil
+}
+
+func (k *keyctlKeychain) Set(key string, secret []byte) error {
+	keyring, err := k.keyring()
+	if err != nil {
+		return err
+	}
+	// The key of the same description is replaced
+	_, err = unix.AddKey("user", k.description(key), secret, keyring)
+	return err
+}
+
+func (k *keyctlKeychain) Delete(key string) error {
+	keyring, id, err := k.search(key)
+	if errors.Is(err, errdefs.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = unix.KeyctlInt(unix.KEYCTL_UNLINK, id, keyring, 0, 0)
+	return err
+}
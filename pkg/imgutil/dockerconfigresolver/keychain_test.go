@@ -0,0 +1,119 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dockerconfigresolver
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/errdefs"
+)
+
+type memoryKeychain map[string][]byte
+
+func (k memoryKeychain) Get(key string) ([]byte, error) {
+	b, ok := k[key]
+	if !ok {
+		return nil, fmt.Errorf("no secret for %q: %w", key, errdefs.ErrNotFound)
+	}
+	return b, nil
+}
+
+func (k memoryKeychain) Set(key string, secret []byte) error {
+	k[key] = secret
+	return nil
+}
+
+func (k memoryKeychain) Delete(key string) error {
+	delete(k, key)
+	return nil
+}
+
+func readConfig(t *testing.T, dir string) string {
+	b, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	assert.NilError(t, err)
+	return string(b)
+}
+
+func TestKeychainStore(t *testing.T) {
+	kc := memoryKeychain{}
+	dir := createTempDir(t, 0700)
+	registryURL, err := Parse("registry.example")
+	assert.NilError(t, err)
+
+	cs, err := NewCredentialsStore(dir, WithKeychain(kc))
+	assert.NilError(t, err)
+	assert.NilError(t, cs.Store(registryURL, &Credentials{Username: "username", Password: "password"}))
+	assert.Equal(t, cs.FileStorageLocation(registryURL), "")
+
+	// Only the server address is in the file
+	config := readConfig(t, dir)
+	assert.Assert(t, strings.Contains(config, "registry.example:443"), config)
+	assert.Assert(t, !strings.Contains(config, "auth\""), config)
+	assert.Equal(t, len(kc), 1)
+
+	cs, err = NewCredentialsStore(dir, WithKeychain(kc))
+	assert.NilError(t, err)
+	af, err := cs.Retrieve(registryURL, true)
+	assert.NilError(t, err)
+	assert.Equal(t, af.Username, "username")
+	assert.Equal(t, af.Password, "password")
+
+	_, err = cs.Erase(registryURL)
+	assert.NilError(t, err)
+	assert.Equal(t, len(kc), 0)
+	assert.Assert(t, !strings.Contains(readConfig(t, dir), "registry.example"))
+}
+
+func TestKeychainMigration(t *testing.T) {
+	kc := memoryKeychain{}
+	dir := writeContent(t, fmt.Sprintf(`{
+	"auths": {
+		"registry.example:443": {
+			"auth": %q
+		},
+		"helper.example:443": {
+			"auth": %q
+		}
+	},
+	"credHelpers": {
+		"helper.example:443": "missing-helper"
+	}
+}`, base64.StdEncoding.EncodeToString([]byte("username:password")), base64.StdEncoding.EncodeToString([]byte("other:secret"))))
+
+	cs, err := NewCredentialsStore(dir, WithKeychain(kc))
+	assert.NilError(t, err)
+
+	// The registries with a credential helper are left alone
+	assert.Equal(t, len(kc), 1)
+	config := readConfig(t, dir)
+	assert.Assert(t, !strings.Contains(config, base64.StdEncoding.EncodeToString([]byte("username:password"))), config)
+	assert.Assert(t, strings.Contains(config, base64.StdEncoding.EncodeToString([]byte("other:secret"))), config)
+
+	registryURL, err := Parse("registry.example")
+	assert.NilError(t, err)
+	af, err := cs.Retrieve(registryURL, true)
+	assert.NilError(t, err)
+	assert.Equal(t, af.Username, "username")
+	assert.Equal(t, af.Password, "password")
+}
@@ -0,0 +1,29 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dockerconfigresolver
+
+import "errors"
+
+// ErrOffline is returned for the registry access in the offline mode (`nerdctl --offline`).
+var ErrOffline = errors.New("registry access is disabled in the offline mode (--offline)")
+
+// WithOffline enables the offline mode, in which NewHostOptions (and so New) fail with ErrOffline.
+func WithOffline(b bool) Opt {
+	return func(o *opts) {
+		o.offline = b
+	}
+}
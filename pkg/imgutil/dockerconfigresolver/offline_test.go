@@ -0,0 +1,39 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dockerconfigresolver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestOffline(t *testing.T) {
+	_, err := New(context.Background(), "registry.example.com", WithOffline(true))
+	assert.Assert(t, errors.Is(err, ErrOffline))
+	assert.ErrorContains(t, err, `cannot access registry "registry.example.com"`)
+
+	_, err = NewHostOptions(context.Background(), "registry.example.com", WithOffline(true))
+	assert.Assert(t, errors.Is(err, ErrOffline))
+
+	_, err = New(context.Background(), "registry.example.com", WithOffline(false))
+	assert.NilError(t, err)
+	_, err = New(context.Background(), "registry.example.com")
+	assert.NilError(t, err)
+}
@@ -0,0 +1,248 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dockerconfigresolver
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/config"
+)
+
+// WithRegistryConfigs specifies the [registries."<HOST>"] tables of nerdctl.toml, keyed by the registry host.
+func WithRegistryConfigs(m map[string]config.RegistryConfig) Opt {
+	return func(o *opts) {
+		o.registryConfigs = m
+	}
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseTLSVersion parses a TLS version like "1.2".
+func ParseTLSVersion(s string) (uint16, error) {
+	v, ok := tlsVersions[strings.TrimPrefix(s, "v")]
+	if !ok {
+		return 0, fmt.Errorf("invalid TLS version %q (supported values: \"1.0\", \"1.1\", \"1.2\", \"1.3\")", s)
+	}
+	return v, nil
+}
+
+// registryConfigFor returns the nerdctl.toml configuration of the registry host.
+// An exact key is preferred to a key without the port or a CIDR.
+func registryConfigFor(registryConfigs map[string]config.RegistryConfig, host string) config.RegistryConfig {
+	if cfg, ok := registryConfigs[host]; ok {
+		return cfg
+	}
+	keys := make([]string, 0, len(registryConfigs))
+	for k := range registryConfigs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if config.MatchRegistry([]string{k}, host) {
+			return registryConfigs[k]
+		}
+	}
+	return config.RegistryConfig{}
+}
+
+// hostsTOMLExtension is the part of hosts.toml that containerd does not read.
+type hostsTOMLExtension struct {
+	MinTLSVersion string `toml:"min_tls_version"`
+}
+
+// registryTLS is the TLS configuration of a registry, on top of the configuration of containerd.
+type registryTLS struct {
+	minVersion  uint16
+	caCerts     [][]byte
+	clientCerts []tls.Certificate
+}
+
+// newRegistryTLS loads the TLS configuration of the registry host from nerdctl.toml,
+// and from the host directory `hostDir` (e.g., "/etc/containerd/certs.d/registry.example.com"), which may be empty.
+//
+// containerd ignores the certificate files of the host directory when there is a hosts.toml,
+// so they are loaded here in that case, like Docker does.
+//
+// nil is returned if there is nothing to configure.
+func newRegistryTLS(registryConfigs map[string]config.RegistryConfig, host, hostDir string) (*registryTLS, error) {
+	cfg := registryConfigFor(registryConfigs, host)
+	var certDirs []string
+	if hostDir != "" {
+		hostsTOML := filepath.Join(hostDir, "hosts.toml")
+		b, err := os.ReadFile(hostsTOML)
+		if err == nil {
+			var ext hostsTOMLExtension
+			if err := toml.Unmarshal(b, &ext); err != nil {
+				return nil, fmt.Errorf("failed to parse %q: %w", hostsTOML, err)
+			}
+			if cfg.MinTLSVersion == "" {
+				cfg.MinTLSVersion = ext.MinTLSVersion
+			}
+			certDirs = append(certDirs, hostDir)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	if cfg.CADir != "" {
+		certDirs = append(certDirs, cfg.CADir)
+	}
+
+	var (
+		rt         registryTLS
+		configured bool
+	)
+	if cfg.MinTLSVersion != "" {
+		v, err := ParseTLSVersion(cfg.MinTLSVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid min_tls_version for registry %q: %w", host, err)
+		}
+		rt.minVersion = v
+		configured = true
+	}
+	for _, dir := range certDirs {
+		if err := rt.loadCertDir(dir); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case cfg.ClientCert != "" && cfg.ClientKey != "":
+		if err := rt.loadClientCert(cfg.ClientCert, cfg.ClientKey); err != nil {
+			return nil, err
+		}
+	case cfg.ClientCert != "" || cfg.ClientKey != "":
+		return nil, fmt.Errorf("client_cert and client_key have to be specified together for registry %q", host)
+	}
+	if !configured && len(rt.caCerts) == 0 && len(rt.clientCerts) == 0 {
+		return nil, nil
+	}
+	return &rt, nil
+}
+
+// loadCertDir loads a directory in the layout of Docker certs.d:
+// the "*.crt" files are CA certificates, and the "*.cert" files are client certificates,
+// with the private key in the "*.key" file of the same name.
+func (rt *registryTLS) loadCertDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		p := filepath.Join(dir, e.Name())
+		switch filepath.Ext(e.Name()) {
+		case ".crt":
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return fmt.Errorf("unable to read CA cert %q: %w", p, err)
+			}
+			rt.caCerts = append(rt.caCerts, data)
+		case ".cert":
+			keyFile := strings.TrimSuffix(p, ".cert") + ".key"
+			if _, err := os.Stat(keyFile); err != nil {
+				return fmt.Errorf("missing key %q for client certificate %q: %w", keyFile, p, err)
+			}
+			if err := rt.loadClientCert(p, keyFile); err != nil {
+				return err
+			}
+		case ".key":
+			certFile := strings.TrimSuffix(p, ".key") + ".cert"
+			if _, err := os.Stat(certFile); err != nil {
+				log.L.Warnf("ignoring key %q without client certificate %q", p, certFile)
+			}
+		}
+	}
+	return nil
+}
+
+func (rt *registryTLS) loadClientCert(certFile, keyFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("unable to load client certificate %q: %w", certFile, err)
+	}
+	rt.clientCerts = append(rt.clientCerts, cert)
+	return nil
+}
+
+// apply applies the configuration to tlsConfig.
+// apply is idempotent, as containerd may clone a transport that has already been updated.
+func (rt *registryTLS) apply(tlsConfig *tls.Config) error {
+	if rt.minVersion > tlsConfig.MinVersion {
+		tlsConfig.MinVersion = rt.minVersion
+	}
+	if len(rt.caCerts) > 0 {
+		if tlsConfig.RootCAs == nil {
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				return fmt.Errorf("unable to initialize cert pool: %w", err)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		for _, data := range rt.caCerts {
+			// AppendCertsFromPEM ignores the certificates already in the pool
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(data) {
+				return errors.New("unable to load CA cert: no valid certificate in PEM")
+			}
+		}
+	}
+	for _, cert := range rt.clientCerts {
+		if !hasCertificate(tlsConfig.Certificates, cert) {
+			tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+		}
+	}
+	return nil
+}
+
+func hasCertificate(certs []tls.Certificate, cert tls.Certificate) bool {
+	for _, c := range certs {
+		if len(c.Certificate) > 0 && len(cert.Certificate) > 0 && bytes.Equal(c.Certificate[0], cert.Certificate[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// updateClient is a dockerconfig.UpdateClientFunc.
+func (rt *registryTLS) updateClient(client *http.Client) error {
+	tr, ok := client.Transport.(*http.Transport)
+	if !ok {
+		return nil
+	}
+	if tr.TLSClientConfig == nil {
+		tr.TLSClientConfig = &tls.Config{}
+	}
+	return rt.apply(tr.TLSClientConfig)
+}
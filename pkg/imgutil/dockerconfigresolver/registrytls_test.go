@@ -0,0 +1,216 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dockerconfigresolver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/config"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCert(t *testing.T, cn string, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	assert.NilError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NilError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NilError(t, err)
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func (c *testCert) tlsCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+	cert, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	assert.NilError(t, err)
+	return cert
+}
+
+// newTestRegistry starts a TLS server that requires a client certificate signed by ca.
+func newTestRegistry(t *testing.T, ca *testCert, maxVersion uint16) *httptest.Server {
+	t.Helper()
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{newTestCert(t, "registry", ca).tlsCertificate(t)},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+		MaxVersion:   maxVersion,
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func get(t *testing.T, rt *registryTLS, url string) error {
+	t.Helper()
+	client := &http.Client{Transport: http.DefaultTransport.(*http.Transport).Clone()}
+	assert.NilError(t, rt.updateClient(client))
+	// Applying twice, as containerd does for the cloned transports, must not break anything
+	assert.NilError(t, rt.updateClient(client))
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func TestRegistryTLSCADir(t *testing.T) {
+	ca := newTestCert(t, "ca", nil)
+	srv := newTestRegistry(t, ca, 0)
+	host := srv.Listener.Addr().String()
+
+	client := newTestCert(t, "client", ca)
+	caDir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(caDir, "ca.crt"), ca.certPEM, 0o644))
+	assert.NilError(t, os.WriteFile(filepath.Join(caDir, "client.cert"), client.certPEM, 0o644))
+	assert.NilError(t, os.WriteFile(filepath.Join(caDir, "client.key"), client.keyPEM, 0o600))
+
+	rt, err := newRegistryTLS(nil, host, "")
+	assert.NilError(t, err)
+	assert.Assert(t, rt == nil)
+
+	rt, err = newRegistryTLS(map[string]config.RegistryConfig{
+		"127.0.0.1": {CADir: caDir},
+	}, host, "")
+	assert.NilError(t, err)
+	assert.NilError(t, get(t, rt, srv.URL))
+}
+
+func TestRegistryTLSClientCert(t *testing.T) {
+	ca := newTestCert(t, "ca", nil)
+	srv := newTestRegistry(t, ca, 0)
+	host := srv.Listener.Addr().String()
+
+	client := newTestCert(t, "client", ca)
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.crt")
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+	assert.NilError(t, os.WriteFile(caFile, ca.certPEM, 0o644))
+	assert.NilError(t, os.WriteFile(certFile, client.certPEM, 0o644))
+	assert.NilError(t, os.WriteFile(keyFile, client.keyPEM, 0o600))
+
+	// The CA is in the certs.d layout, along with a hosts.toml that containerd reads
+	hostDir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(hostDir, "ca.crt"), ca.certPEM, 0o644))
+	assert.NilError(t, os.WriteFile(filepath.Join(hostDir, "hosts.toml"), []byte("server = \"https://"+host+"\"\n"), 0o644))
+
+	_, err := newRegistryTLS(map[string]config.RegistryConfig{
+		host: {ClientCert: certFile},
+	}, host, hostDir)
+	assert.ErrorContains(t, err, "client_cert and client_key have to be specified together")
+
+	rt, err := newRegistryTLS(map[string]config.RegistryConfig{
+		host: {ClientCert: certFile, ClientKey: keyFile},
+	}, host, hostDir)
+	assert.NilError(t, err)
+	assert.NilError(t, get(t, rt, srv.URL))
+}
+
+func TestRegistryTLSMinVersion(t *testing.T) {
+	ca := newTestCert(t, "ca", nil)
+	srv := newTestRegistry(t, ca, tls.VersionTLS12)
+	host := srv.Listener.Addr().String()
+
+	client := newTestCert(t, "client", ca)
+	hostDir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(hostDir, "ca.crt"), ca.certPEM, 0o644))
+	assert.NilError(t, os.WriteFile(filepath.Join(hostDir, "client.cert"), client.certPEM, 0o644))
+	assert.NilError(t, os.WriteFile(filepath.Join(hostDir, "client.key"), client.keyPEM, 0o600))
+	assert.NilError(t, os.WriteFile(filepath.Join(hostDir, "hosts.toml"), []byte("min_tls_version = \"1.2\"\n"), 0o644))
+
+	rt, err := newRegistryTLS(nil, host, hostDir)
+	assert.NilError(t, err)
+	assert.Equal(t, rt.minVersion, uint16(tls.VersionTLS12))
+	assert.NilError(t, get(t, rt, srv.URL))
+
+	// nerdctl.toml takes precedence over hosts.toml
+	rt, err = newRegistryTLS(map[string]config.RegistryConfig{
+		host: {MinTLSVersion: "1.3"},
+	}, host, hostDir)
+	assert.NilError(t, err)
+	assert.ErrorContains(t, get(t, rt, srv.URL), "protocol version")
+
+	_, err = newRegistryTLS(map[string]config.RegistryConfig{
+		host: {MinTLSVersion: "1.4"},
+	}, host, hostDir)
+	assert.ErrorContains(t, err, "invalid TLS version")
+}
+
+func TestNewHostOptionsRegistryConfigs(t *testing.T) {
+	noCreds := WithAuthCreds(func(string) (string, string, error) { return "", "", nil })
+
+	ho, err := NewHostOptions(context.Background(), "registry.example", noCreds)
+	assert.NilError(t, err)
+	assert.Assert(t, ho.UpdateClient == nil)
+
+	ho, err = NewHostOptions(context.Background(), "registry.example", noCreds, WithRegistryConfigs(map[string]config.RegistryConfig{
+		"registry.example": {MinTLSVersion: "1.3"},
+	}))
+	assert.NilError(t, err)
+	assert.Assert(t, ho.UpdateClient != nil)
+}
@@ -193,52 +193,63 @@ func FilterByCreatedAt(ctx context.Context, client *containerd.Client, before []
 // FilterUntil filters images created before the provided timestamp.
 func FilterUntil(until string) Filter {
 	return func(imageList []images.Image) ([]images.Image, error) {
-		if len(until) == 0 {
-			return []images.Image{}, errNoUntilTimestamp
+		parsedTime, err := ParseUntil(until)
+		if err != nil {
+			return []images.Image{}, err
 		}
 
-		var (
-			parsedTime time.Time
-			err        error
-		)
+		return filter(imageList, func(i images.Image) (bool, error) {
+			return imageCreatedBefore(i, parsedTime), nil
+		})
+	}
+}
 
-		type parseUntilFunc func(string) (time.Time, error)
-		parsingFuncs := []parseUntilFunc{
-			func(until string) (time.Time, error) {
-				return time.Parse(time.RFC3339, until)
-			},
-			func(until string) (time.Time, error) {
-				return time.Parse(time.RFC3339Nano, until)
-			},
-			func(until string) (time.Time, error) {
-				return time.Parse(time.DateOnly, until)
-			},
-			func(until string) (time.Time, error) {
-				// Go duration strings
-				d, err := time.ParseDuration(until)
-				if err != nil {
-					return time.Time{}, err
-				}
-				return time.Now().Add(-d), nil
-			},
-		}
+// ParseUntil parses the value of an "until" filter, which is a timestamp (RFC 3339 or date only),
+// or a Go duration string relative to the current time (e.g., "24h").
+func ParseUntil(until string) (time.Time, error) {
+	if len(until) == 0 {
+		return time.Time{}, errNoUntilTimestamp
+	}
 
-		for _, parse := range parsingFuncs {
-			parsedTime, err = parse(until)
+	var (
+		parsedTime time.Time
+		err        error
+	)
+
+	type parseUntilFunc func(string) (time.Time, error)
+	parsingFuncs := []parseUntilFunc{
+		func(until string) (time.Time, error) {
+			return time.Parse(time.RFC3339, until)
+		},
+		func(until string) (time.Time, error) {
+			return time.Parse(time.RFC3339Nano, until)
+		},
+		func(until string) (time.Time, error) {
+			return time.Parse(time.DateOnly, until)
+		},
+		func(until string) (time.Time, error) {
+			// Go duration strings
+			d, err := time.ParseDuration(until)
 			if err != nil {
-				continue
+				return time.Time{}, err
 			}
-			break
-		}
+			return time.Now().Add(-d), nil
+		},
+	}
 
+	for _, parse := range parsingFuncs {
+		parsedTime, err = parse(until)
 		if err != nil {
-			return []images.Image{}, errUnparsableUntilTimestamp
+			continue
 		}
+		break
+	}
 
-		return filter(imageList, func(i images.Image) (bool, error) {
-			return imageCreatedBefore(i, parsedTime), nil
-		})
+	if err != nil {
+		return time.Time{}, errUnparsableUntilTimestamp
 	}
+
+	return parsedTime, nil
 }
 
 // FilterByLabel filters an image list based on labels applied to the image's config specification for the platform.
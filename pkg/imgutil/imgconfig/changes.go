@@ -0,0 +1,255 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package imgconfig provides the Dockerfile-style changes of image configs, for `nerdctl commit --change` and `nerdctl image edit`.
+package imgconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/docker/go-connections/nat"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/containerd/log"
+)
+
+// Changes are the Dockerfile instructions applied to an image config.
+// The zero values mean no change.
+type Changes struct {
+	CMD, Entrypoint []string
+	// Env is the list of KEY=VALUE, which overrides the variables of the same keys
+	Env          []string
+	ExposedPorts []string // e.g., "80/tcp"
+	Labels       map[string]string
+	User         string
+	// WorkingDir is resolved relative to the current working dir if it is not absolute
+	WorkingDir string
+	Volumes    []string
+	StopSignal string
+}
+
+// ParseChanges parses the Dockerfile instructions of `--change`.
+// The supported instructions are the same as `docker commit --change`, except ONBUILD, which is not part of the OCI image config.
+func ParseChanges(userChanges []string) (Changes, error) {
+	var changes Changes
+	for _, change := range userChanges {
+		if strings.TrimSpace(change) == "" {
+			return Changes{}, fmt.Errorf("received an empty value in change flag")
+		}
+		directive, args, _ := strings.Cut(strings.TrimSpace(change), " ")
+		directive = strings.ToUpper(directive)
+		args = strings.TrimSpace(args)
+		if args == "" {
+			return Changes{}, fmt.Errorf("change directive %q requires at least one argument", directive)
+		}
+
+		switch directive {
+		case "CMD":
+			if changes.CMD != nil {
+				log.L.Warn("multiple change flags supplied for the CMD directive, overriding with last supplied")
+			}
+			changes.CMD = ParseCommand(args)
+		case "ENTRYPOINT":
+			if changes.Entrypoint != nil {
+				log.L.Warnf("multiple change flags supplied for the Entrypoint directive, overriding with last supplied")
+			}
+			changes.Entrypoint = ParseCommand(args)
+		case "ENV":
+			kvs, err := parseKeyValues(args)
+			if err != nil {
+				return Changes{}, fmt.Errorf("invalid change flag value %q: %w", change, err)
+			}
+			for _, kv := range kvs {
+				changes.Env = append(changes.Env, kv[0]+"="+kv[1])
+			}
+		case "LABEL":
+			kvs, err := parseKeyValues(args)
+			if err != nil {
+				return Changes{}, fmt.Errorf("invalid change flag value %q: %w", change, err)
+			}
+			if changes.Labels == nil {
+				changes.Labels = make(map[string]string)
+			}
+			for _, kv := range kvs {
+				changes.Labels[kv[0]] = kv[1]
+			}
+		case "EXPOSE":
+			exposed, _, err := nat.ParsePortSpecs(strings.Fields(args))
+			if err != nil {
+				return Changes{}, fmt.Errorf("invalid change flag value %q: %w", change, err)
+			}
+			for p := range exposed {
+				changes.ExposedPorts = append(changes.ExposedPorts, string(p))
+			}
+			slices.Sort(changes.ExposedPorts)
+		case "VOLUME":
+			var volumes []string
+			if err := json.Unmarshal([]byte(args), &volumes); err != nil {
+				volumes = strings.Fields(args)
+			}
+			changes.Volumes = append(changes.Volumes, volumes...)
+		case "USER":
+			changes.User = args
+		case "WORKDIR":
+			changes.WorkingDir = args
+		case "STOPSIGNAL":
+			changes.StopSignal = args
+		default:
+			return Changes{}, fmt.Errorf("unknown change directive %q (supported directives: [CMD, ENTRYPOINT, ENV, EXPOSE, LABEL, STOPSIGNAL, USER, VOLUME, WORKDIR])", directive)
+		}
+	}
+	return changes, nil
+}
+
+// ParseCommand parses the arguments of CMD and ENTRYPOINT.
+// The arguments in the JSON array form are used as they are, otherwise they are run with `/bin/sh -c`.
+func ParseCommand(args string) []string {
+	var command []string
+	if strings.HasPrefix(args, "[") {
+		if err := json.Unmarshal([]byte(args), &command); err == nil {
+			return command
+		}
+	}
+	return []string{"/bin/sh", "-c", args}
+}
+
+// parseKeyValues parses the arguments of ENV and LABEL,
+// in the form of `KEY=VALUE [KEY=VALUE...]`, or the legacy form of `KEY VALUE`.
+// The values may be quoted.
+func parseKeyValues(args string) ([][2]string, error) {
+	if first, _, _ := strings.Cut(args, " "); !strings.Contains(first, "=") {
+		k, v, ok := strings.Cut(args, " ")
+		if !ok {
+			return nil, fmt.Errorf("%q requires a value", k)
+		}
+		return [][2]string{{k, strings.TrimSpace(v)}}, nil
+	}
+	words, err := splitWords(args)
+	if err != nil {
+		return nil, err
+	}
+	kvs := make([][2]string, 0, len(words))
+	for _, w := range words {
+		k, v, ok := strings.Cut(w, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("expected KEY=VALUE, got %q", w)
+		}
+		kvs = append(kvs, [2]string{k, v})
+	}
+	return kvs, nil
+}
+
+// splitWords splits s by the spaces, with the quotes and the backslash escapes removed.
+func splitWords(s string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range s {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", s)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// ApplyChanges applies the changes to the image config.
+func ApplyChanges(config *ocispec.ImageConfig, changes Changes) {
+	if changes.CMD != nil {
+		config.Cmd = changes.CMD
+	}
+	if changes.Entrypoint != nil {
+		config.Entrypoint = changes.Entrypoint
+	}
+	for _, kv := range changes.Env {
+		k, _, _ := strings.Cut(kv, "=")
+		if i := slices.IndexFunc(config.Env, func(e string) bool {
+			ek, _, _ := strings.Cut(e, "=")
+			return ek == k
+		}); i >= 0 {
+			config.Env[i] = kv
+		} else {
+			config.Env = append(config.Env, kv)
+		}
+	}
+	for _, p := range changes.ExposedPorts {
+		if config.ExposedPorts == nil {
+			config.ExposedPorts = make(map[string]struct{})
+		}
+		config.ExposedPorts[p] = struct{}{}
+	}
+	for k, v := range changes.Labels {
+		if config.Labels == nil {
+			config.Labels = make(map[string]string)
+		}
+		config.Labels[k] = v
+	}
+	if changes.User != "" {
+		config.User = changes.User
+	}
+	if changes.WorkingDir != "" {
+		if path.IsAbs(changes.WorkingDir) {
+			config.WorkingDir = changes.WorkingDir
+		} else {
+			config.WorkingDir = path.Join("/", config.WorkingDir, changes.WorkingDir)
+		}
+	}
+	for _, v := range changes.Volumes {
+		if config.Volumes == nil {
+			config.Volumes = make(map[string]struct{})
+		}
+		config.Volumes[v] = struct{}{}
+	}
+	if changes.StopSignal != "" {
+		config.StopSignal = changes.StopSignal
+	}
+}
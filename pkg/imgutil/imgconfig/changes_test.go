@@ -0,0 +1,87 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package imgconfig
+
+import (
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+func TestParseChanges(t *testing.T) {
+	changes, err := ParseChanges([]string{
+		`CMD ["/bin/sh"]`,
+		`entrypoint echo hello`,
+		`ENV FOO=bar BAZ="hello world"`,
+		`ENV LEGACY value with spaces`,
+		`LABEL org.example.foo=bar`,
+		`EXPOSE 80 53/udp 8000-8001`,
+		`VOLUME ["/data"]`,
+		`VOLUME /cache /tmp`,
+		`USER nobody:nogroup`,
+		`WORKDIR /app`,
+		`STOPSIGNAL SIGINT`,
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, changes, Changes{
+		CMD:          []string{"/bin/sh"},
+		Entrypoint:   []string{"/bin/sh", "-c", "echo hello"},
+		Env:          []string{"FOO=bar", "BAZ=hello world", "LEGACY=value with spaces"},
+		ExposedPorts: []string{"53/udp", "80/tcp", "8000/tcp", "8001/tcp"},
+		Labels:       map[string]string{"org.example.foo": "bar"},
+		User:         "nobody:nogroup",
+		WorkingDir:   "/app",
+		Volumes:      []string{"/data", "/cache", "/tmp"},
+		StopSignal:   "SIGINT",
+	})
+
+	for _, invalid := range []string{
+		"",
+		"CMD",
+		"ONBUILD RUN true",
+		`ENV FOO="bar`,
+		"ENV =bar",
+		"EXPOSE foo",
+	} {
+		_, err := ParseChanges([]string{invalid})
+		assert.Assert(t, err != nil, invalid)
+	}
+}
+
+func TestApplyChanges(t *testing.T) {
+	config := ocispec.ImageConfig{
+		Env:        []string{"PATH=/usr/bin", "FOO=old"},
+		WorkingDir: "/app",
+		Cmd:        []string{"sh"},
+	}
+	ApplyChanges(&config, Changes{
+		Env:          []string{"FOO=new", "BAR=bar"},
+		ExposedPorts: []string{"80/tcp"},
+		Labels:       map[string]string{"foo": "bar"},
+		WorkingDir:   "src",
+		Volumes:      []string{"/data"},
+	})
+	assert.DeepEqual(t, config, ocispec.ImageConfig{
+		Env:          []string{"PATH=/usr/bin", "FOO=new", "BAR=bar"},
+		ExposedPorts: map[string]struct{}{"80/tcp": {}},
+		Labels:       map[string]string{"foo": "bar"},
+		WorkingDir:   "/app/src",
+		Volumes:      map[string]struct{}{"/data": {}},
+		Cmd:          []string{"sh"},
+	})
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"strings"
 
 	"github.com/opencontainers/image-spec/identity"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -43,6 +44,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/idutil/imagewalker"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil/pull"
+	"github.com/containerd/nerdctl/v2/pkg/p2p"
 	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
 )
 
@@ -105,7 +107,49 @@ func GetExistingImage(ctx context.Context, client *containerd.Client, snapshotte
 // EnsureImage ensures the image.
 //
 // # When insecure is set, skips verifying certs, and also falls back to HTTP when the registry does not speak HTTPS
+//
+// When the remote snapshotter fails to prepare the snapshots, the image is ensured again with
+// options.GOptions.SnapshotterFallback, if set. EnsuredImage.Snapshotter is the snapshotter actually used.
 func EnsureImage(ctx context.Context, client *containerd.Client, rawRef string, options types.ImagePullOptions) (*EnsuredImage, error) {
+	res, err := ensureImage(ctx, client, rawRef, options)
+	if err == nil {
+		return res, nil
+	}
+	snapshotter, fallback := options.GOptions.Snapshotter, options.GOptions.SnapshotterFallback
+	if fallback == "" || fallback == snapshotter || !getSnapshotterOpts(snapshotter).isRemote() || !isSnapshotterError(err) {
+		return nil, err
+	}
+	log.G(ctx).WithError(err).Warnf("remote snapshotter %q failed to prepare the snapshots of %q, falling back to snapshotter %q", snapshotter, rawRef, fallback)
+	options.GOptions.Snapshotter = fallback
+	return ensureImage(ctx, client, rawRef, options)
+}
+
+// snapshotterErrors are the messages of the errors returned by containerd when the snapshotter fails to
+// prepare the snapshots for unpacking an image.
+var snapshotterErrors = []string{
+	"unable to resolve snapshotter",
+	"failed to stat snapshot",
+	"failed to prepare extraction snapshot",
+	"failed to extract layer",
+	"failed to unpack image on snapshotter",
+}
+
+// isSnapshotterError returns whether err is caused by the snapshotter, rather than the registry.
+func isSnapshotterError(err error) bool {
+	if errdefs.IsUnavailable(err) {
+		// the snapshotter plugin is not running
+		return true
+	}
+	msg := err.Error()
+	for _, s := range snapshotterErrors {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func ensureImage(ctx context.Context, client *containerd.Client, rawRef string, options types.ImagePullOptions) (*EnsuredImage, error) {
 	switch options.Mode {
 	case "always", "missing", "never":
 		// NOP
@@ -113,18 +157,40 @@ func EnsureImage(ctx context.Context, client *containerd.Client, rawRef string,
 		return nil, fmt.Errorf("unexpected pull mode: %q", options.Mode)
 	}
 
+	if options.Mode == "always" && options.GOptions.Offline {
+		return nil, fmt.Errorf("cannot pull %q: %w", rawRef, dockerconfigresolver.ErrOffline)
+	}
+
 	// if not `always` pull and given one platform and image found locally, return existing image directly.
+	var platformErr error
 	if options.Mode != "always" && len(options.OCISpecPlatform) == 1 {
 		if res, err := GetExistingImage(ctx, client, options.GOptions.Snapshotter, rawRef, options.OCISpecPlatform[0]); err == nil {
-			return res, nil
+			if !options.RequirePlatform {
+				return res, nil
+			}
+			if platformErr = checkImagePlatform(ctx, res.Image, options.OCISpecPlatform[0]); platformErr == nil {
+				return res, nil
+			}
 		} else if !errdefs.IsNotFound(err) {
 			return nil, err
 		}
 	}
 
 	if options.Mode == "never" {
+		if platformErr != nil {
+			return nil, fmt.Errorf("image not available: %q: %w", rawRef, platformErr)
+		}
 		return nil, fmt.Errorf("image not available: %q", rawRef)
 	}
+	if options.GOptions.Offline {
+		if platformErr != nil {
+			return nil, fmt.Errorf("image not available locally: %q: %w (%w)", rawRef, platformErr, dockerconfigresolver.ErrOffline)
+		}
+		return nil, fmt.Errorf("image not available locally: %q: %w", rawRef, dockerconfigresolver.ErrOffline)
+	}
+	if platformErr != nil {
+		log.G(ctx).Infof("Pulling %q again, as the local image does not match the requested platform: %v", rawRef, platformErr)
+	}
 
 	parsedReference, err := referenceutil.Parse(rawRef)
 	if err != nil {
@@ -132,11 +198,12 @@ func EnsureImage(ctx context.Context, client *containerd.Client, rawRef string,
 	}
 
 	var dOpts []dockerconfigresolver.Opt
-	if options.GOptions.InsecureRegistry {
+	if options.GOptions.IsInsecureRegistry(parsedReference.Domain) {
 		log.G(ctx).Warnf("skipping verifying HTTPS certs for %q", parsedReference.Domain)
 		dOpts = append(dOpts, dockerconfigresolver.WithSkipVerifyCerts(true))
 	}
-	dOpts = append(dOpts, dockerconfigresolver.WithHostsDirs(options.GOptions.HostsDir))
+	dOpts = append(dOpts, dockerconfigresolver.WithHostsDirs(options.GOptions.HostsDir), dockerconfigresolver.WithOffline(options.GOptions.Offline),
+		dockerconfigresolver.WithRegistryConfigs(options.GOptions.Registries), dockerconfigresolver.WithSystemKeychain(options.GOptions.Credentials.Keychain))
 	resolver, err := dockerconfigresolver.New(ctx, parsedReference.Domain, dOpts...)
 	if err != nil {
 		return nil, err
@@ -148,7 +215,7 @@ func EnsureImage(ctx context.Context, client *containerd.Client, rawRef string,
 		if !errors.Is(err, http.ErrSchemeMismatch) && !errutil.IsErrConnectionRefused(err) {
 			return nil, err
 		}
-		if options.GOptions.InsecureRegistry {
+		if options.GOptions.IsInsecureRegistry(parsedReference.Domain) {
 			log.G(ctx).WithError(err).Warnf("server %q does not seem to support HTTPS, falling back to plain HTTP", parsedReference.Domain)
 			dOpts = append(dOpts, dockerconfigresolver.WithPlainHTTP(true))
 			resolver, err = dockerconfigresolver.New(ctx, parsedReference.Domain, dOpts...)
@@ -166,18 +233,21 @@ func EnsureImage(ctx context.Context, client *containerd.Client, rawRef string,
 }
 
 // ResolveDigest resolves `rawRef` and returns its descriptor digest.
-func ResolveDigest(ctx context.Context, rawRef string, insecure bool, hostsDirs []string) (string, error) {
+// `insecure` reports whether the registry host may skip verifying HTTPS certs, and may be nil.
+// `extraOpts` are appended to the options of the resolver, e.g., dockerconfigresolver.WithOffline.
+func ResolveDigest(ctx context.Context, rawRef string, insecure func(host string) bool, hostsDirs []string, extraOpts ...dockerconfigresolver.Opt) (string, error) {
 	parsedReference, err := referenceutil.Parse(rawRef)
 	if err != nil {
 		return "", err
 	}
 
 	var dOpts []dockerconfigresolver.Opt
-	if insecure {
+	if insecure != nil && insecure(parsedReference.Domain) {
 		log.G(ctx).Warnf("skipping verifying HTTPS certs for %q", parsedReference.Domain)
 		dOpts = append(dOpts, dockerconfigresolver.WithSkipVerifyCerts(true))
 	}
 	dOpts = append(dOpts, dockerconfigresolver.WithHostsDirs(hostsDirs))
+	dOpts = append(dOpts, extraOpts...)
 	resolver, err := dockerconfigresolver.New(ctx, parsedReference.Domain, dOpts...)
 	if err != nil {
 		return "", err
@@ -201,7 +271,7 @@ func PullImage(ctx context.Context, client *containerd.Client, resolver remotes.
 
 	var containerdImage containerd.Image
 	config := &pull.Config{
-		Resolver:   resolver,
+		Resolver:   p2p.WrapResolver(ctx, resolver, options.GOptions.P2P),
 		RemoteOpts: []containerd.RemoteOpt{},
 		Platforms:  options.OCISpecPlatform, // empty for all-platforms
 	}
@@ -255,6 +325,37 @@ func PullImage(ctx context.Context, client *containerd.Client, resolver remotes.
 
 }
 
+// checkImagePlatform returns an error when the config of the image is for a platform other than the specified one.
+// An index is resolved to the manifest of the platform by containerd, but a single manifest is not checked by containerd.
+func checkImagePlatform(ctx context.Context, image containerd.Image, platform ocispec.Platform) error {
+	desc, err := image.Config(ctx)
+	if err != nil {
+		return err
+	}
+	b, err := content.ReadBlob(ctx, image.ContentStore(), desc)
+	if err != nil {
+		return err
+	}
+	var ocispecImage ocispec.Image
+	if err := json.Unmarshal(b, &ocispecImage); err != nil {
+		return err
+	}
+	return matchImagePlatform(ocispecImage.Platform, platform)
+}
+
+// matchImagePlatform returns an error when the platform of the image config does not match the requested one.
+// The configs without the OS and the architecture (e.g., the ones of some artifacts) are considered to match.
+func matchImagePlatform(imagePlatform, platform ocispec.Platform) error {
+	if imagePlatform.OS == "" || imagePlatform.Architecture == "" {
+		return nil
+	}
+	if !platforms.OnlyStrict(platform).Match(imagePlatform) {
+		return fmt.Errorf("the image platform %q does not match the requested platform %q",
+			platforms.Format(platforms.Normalize(imagePlatform)), platforms.Format(platforms.Normalize(platform)))
+	}
+	return nil
+}
+
 func getImageConfig(ctx context.Context, image containerd.Image) (*ocispec.ImageConfig, error) {
 	desc, err := image.Config(ctx)
 	if err != nil {
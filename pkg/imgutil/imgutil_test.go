@@ -17,9 +17,14 @@
 package imgutil
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"gotest.tools/v3/assert"
+
+	"github.com/containerd/errdefs"
 )
 
 func TestParseRepoTag(t *testing.T) {
@@ -57,3 +62,46 @@ func TestParseRepoTag(t *testing.T) {
 		assert.Equal(t, tc.tag, tag)
 	}
 }
+
+func TestIsSnapshotterError(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected bool
+	}{
+		{
+			err:      fmt.Errorf("unpack: failed to prepare extraction snapshot \"extract-1\": %w", errors.New("failed to resolve layer")),
+			expected: true,
+		},
+		{
+			err:      fmt.Errorf("failed to stat snapshot sha256:abc: %w", errdefs.ErrUnavailable),
+			expected: true,
+		},
+		{
+			err:      errdefs.ErrUnavailable,
+			expected: true,
+		},
+		{
+			err:      errors.New(`failed to resolve reference "example.com/foo:latest": not found`),
+			expected: false,
+		},
+		{
+			err:      fmt.Errorf("failed to do request: %w", errors.New("connection refused")),
+			expected: false,
+		},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, isSnapshotterError(tc.err), tc.expected, tc.err.Error())
+	}
+}
+
+func TestMatchImagePlatform(t *testing.T) {
+	amd64 := ocispec.Platform{OS: "linux", Architecture: "amd64"}
+	arm64 := ocispec.Platform{OS: "linux", Architecture: "arm64"}
+	armv7 := ocispec.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}
+
+	assert.NilError(t, matchImagePlatform(amd64, amd64))
+	assert.NilError(t, matchImagePlatform(arm64, ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}))
+	assert.NilError(t, matchImagePlatform(ocispec.Platform{}, arm64))
+	assert.ErrorContains(t, matchImagePlatform(arm64, amd64), `the image platform "linux/arm64" does not match the requested platform "linux/amd64"`)
+	assert.ErrorContains(t, matchImagePlatform(armv7, ocispec.Platform{OS: "linux", Architecture: "arm", Variant: "v6"}), "does not match")
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil"
 	"github.com/containerd/nerdctl/v2/pkg/platformutil"
+	"github.com/containerd/nerdctl/v2/pkg/signutil"
 )
 
 // FromArchive loads and unpacks the images from the tar archive specified in image load options.
@@ -62,7 +63,17 @@ func FromArchive(ctx context.Context, client *containerd.Client, options types.I
 	if err != nil {
 		return nil, err
 	}
-	imgs, err := importImages(ctx, client, decompressor, options.GOptions.Snapshotter, platMC)
+	var in io.Reader = decompressor
+	if options.VerifyBundle != "" {
+		f, err := verifyArchive(decompressor, options.VerifyBundle)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(f.Name())
+		defer f.Close()
+		in = f
+	}
+	imgs, err := importImages(ctx, client, in, options.GOptions.Snapshotter, platMC)
 	if err != nil {
 		return nil, err
 	}
@@ -96,6 +107,35 @@ func FromOCIArchive(ctx context.Context, client *containerd.Client, pathToOCIArc
 	return FromArchive(ctx, client, options)
 }
 
+// verifyArchive spools the archive to a temporary file, and verifies it against the policy at policyPath.
+// The returned file is rewound for importing, and should be removed by the caller.
+func verifyArchive(in io.Reader, policyPath string) (*os.File, error) {
+	policy, err := signutil.LoadBundlePolicy(policyPath)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp("", "nerdctl-load-")
+	if err != nil {
+		return nil, err
+	}
+	if err := func() error {
+		if err := signutil.VerifyBundle(io.TeeReader(in, f), policy); err != nil {
+			return fmt.Errorf("failed to verify the archive: %w", err)
+		}
+		// Drain the trailing data not consumed by the tar reader.
+		if _, err := io.Copy(f, in); err != nil {
+			return err
+		}
+		_, err := f.Seek(0, io.SeekStart)
+		return err
+	}(); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return f, nil
+}
+
 type readCounter struct {
 	io.Reader
 	N int
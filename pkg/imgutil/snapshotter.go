@@ -71,6 +71,12 @@ func getSnapshotterOpts(snapshotter string) snapshotterOpts {
 	return &defaultSnapshotterOpts{snapshotter: snapshotter}
 }
 
+// IsRemoteSnapshotter returns whether the snapshotter is a remote snapshotter handled by nerdctl,
+// which unpacks the layers lazily while pulling.
+func IsRemoteSnapshotter(snapshotter string) bool {
+	return getSnapshotterOpts(snapshotter).isRemote()
+}
+
 // remoteSnapshotterOpts is used as a remote snapshotter implementation for
 // interface `snapshotterOpts.isRemote()` function
 type remoteSnapshotterOpts struct {
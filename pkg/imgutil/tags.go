@@ -0,0 +1,176 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package imgutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	dockerconfig "github.com/containerd/containerd/v2/core/remotes/docker/config"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
+)
+
+// ListTags returns the tags of the repository of `rawRef`, using the registry API.
+// `insecure` reports whether the registry host may skip verifying HTTPS certs, and may be nil.
+// `extraOpts` are appended to the options of the resolver, e.g., dockerconfigresolver.WithOffline.
+func ListTags(ctx context.Context, rawRef string, insecure func(host string) bool, hostsDirs []string, extraOpts ...dockerconfigresolver.Opt) ([]string, error) {
+	parsedReference, err := referenceutil.Parse(rawRef)
+	if err != nil {
+		return nil, err
+	}
+	if parsedReference.Protocol != "" {
+		return nil, fmt.Errorf("listing the tags is not supported for %s", parsedReference.Protocol)
+	}
+
+	var dOpts []dockerconfigresolver.Opt
+	if insecure != nil && insecure(parsedReference.Domain) {
+		log.G(ctx).Warnf("skipping verifying HTTPS certs for %q", parsedReference.Domain)
+		dOpts = append(dOpts, dockerconfigresolver.WithSkipVerifyCerts(true))
+	}
+	dOpts = append(dOpts, dockerconfigresolver.WithHostsDirs(hostsDirs))
+	dOpts = append(dOpts, extraOpts...)
+	ho, err := dockerconfigresolver.NewHostOptions(ctx, parsedReference.Domain, dOpts...)
+	if err != nil {
+		return nil, err
+	}
+	hosts, err := dockerconfig.ConfigureHosts(ctx, *ho)(parsedReference.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = docker.ContextWithAppendPullRepositoryScope(ctx, parsedReference.Path)
+	var errs []error
+	for _, host := range hosts {
+		if !host.Capabilities.Has(docker.HostCapabilityResolve) {
+			continue
+		}
+		tags, err := listTags(ctx, host, parsedReference.Domain, parsedReference.Path)
+		if err == nil {
+			return tags, nil
+		}
+		log.G(ctx).WithError(err).Debugf("failed to list the tags on host %q", host.Host)
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no registry host to list the tags of %q", rawRef)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// listTags follows the pagination of the tags list endpoint of the distribution spec.
+func listTags(ctx context.Context, host docker.RegistryHost, domain, repository string) ([]string, error) {
+	u := &url.URL{
+		Scheme: host.Scheme,
+		Host:   host.Host,
+		Path:   strings.TrimSuffix(host.Path, "/") + "/" + repository + "/tags/list",
+	}
+	if host.Host != domain && (domain != "docker.io" || host.Host != "registry-1.docker.io") {
+		// Mirrors configured in hosts.toml need the namespace of the upstream registry
+		u.RawQuery = url.Values{"ns": []string{domain}}.Encode()
+	}
+
+	var tags []string
+	next := u.String()
+	for next != "" {
+		resp, err := doRegistryRequest(ctx, host, next)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, page.Tags...)
+
+		next, err = nextPage(resp, u)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
+
+func doRegistryRequest(ctx context.Context, host docker.RegistryHost, target string) (*http.Response, error) {
+	client := host.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	// Retry once after the authorizer has seen the challenge of the registry
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range host.Header {
+			req.Header[k] = v
+		}
+		req.Header.Set("Accept", "application/json")
+		if host.Authorizer != nil {
+			if err := host.Authorizer.Authorize(ctx, req); err != nil {
+				return nil, err
+			}
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusUnauthorized && host.Authorizer != nil && attempt == 0 {
+			err := host.Authorizer.AddResponses(ctx, []*http.Response{resp})
+			resp.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			resp.Body.Close()
+			return nil, fmt.Errorf("unexpected status %s from %s: %s", resp.Status, target, strings.TrimSpace(string(body)))
+		}
+		return resp, nil
+	}
+}
+
+// nextPage returns the URL in the `Link: <url>; rel="next"` header, resolved against base, or "" for the last page.
+func nextPage(resp *http.Response, base *url.URL) (string, error) {
+	for _, link := range resp.Header.Values("Link") {
+		target, params, ok := strings.Cut(link, ";")
+		if !ok || !strings.Contains(params, `rel="next"`) {
+			continue
+		}
+		ref, err := url.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+		if err != nil {
+			return "", err
+		}
+		return base.ResolveReference(ref).String(), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,77 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package imgutil
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+)
+
+func TestListTagsPagination(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Path, "/v2/library/alpine/tags/list")
+		switch r.URL.Query().Get("last") {
+		case "":
+			w.Header().Set("Link", `</v2/library/alpine/tags/list?last=3.13&n=2>; rel="next"`)
+			fmt.Fprint(w, `{"name":"library/alpine","tags":["3.12","3.13"]}`)
+		case "3.13":
+			fmt.Fprint(w, `{"name":"library/alpine","tags":["3.14"]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	assert.NilError(t, err)
+	host := docker.RegistryHost{
+		Client: srv.Client(),
+		Host:   u.Host,
+		Scheme: u.Scheme,
+		Path:   "/v2",
+	}
+	tags, err := listTags(context.Background(), host, u.Host, "library/alpine")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, tags, []string{"3.12", "3.13", "3.14"})
+}
+
+func TestListTagsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"errors":[{"code":"NAME_UNKNOWN"}]}`)
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	assert.NilError(t, err)
+	host := docker.RegistryHost{
+		Client: srv.Client(),
+		Host:   u.Host,
+		Scheme: u.Scheme,
+		Path:   "/v2",
+	}
+	_, err = listTags(context.Background(), host, u.Host, "library/alpine")
+	assert.ErrorContains(t, err, "NAME_UNKNOWN")
+}
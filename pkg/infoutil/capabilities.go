@@ -0,0 +1,128 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package infoutil
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+)
+
+// cniPluginVersionTimeout is the timeout for running a CNI plugin binary to determine its version.
+const cniPluginVersionTimeout = 3 * time.Second
+
+// Capabilities collects the runtime capabilities of the host.
+// Failures to collect a specific capability are logged and do not fail the entire report.
+func Capabilities(ctx context.Context, client *containerd.Client, cniPath string) (*dockercompat.Capabilities, error) {
+	caps := &dockercompat.Capabilities{}
+	snapshotters, err := snapshotterCapabilities(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	caps.Snapshotters = snapshotters
+	caps.CNIPlugins = cniPluginVersions(ctx, cniPath)
+	fulfillPlatformCapabilities(ctx, caps)
+	return caps, nil
+}
+
+func snapshotterCapabilities(ctx context.Context, client *containerd.Client) ([]dockercompat.SnapshotterCapability, error) {
+	plugins, err := client.IntrospectionService().Plugins(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var res []dockercompat.SnapshotterCapability
+	for _, p := range plugins.Plugins {
+		if !strings.HasPrefix(p.Type, "io.containerd.snapshotter.") {
+			continue
+		}
+		s := dockercompat.SnapshotterCapability{
+			Name:    p.ID,
+			Healthy: p.InitErr == nil,
+		}
+		if p.InitErr != nil {
+			s.Error = p.InitErr.Message
+		}
+		res = append(res, s)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// cniPluginVersions runs the CNI plugin binaries in cniPath without CNI_COMMAND,
+// so that they print their "about" string, e.g., "CNI bridge plugin v1.6.2".
+func cniPluginVersions(ctx context.Context, cniPath string) []dockercompat.ComponentVersion {
+	if cniPath == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(cniPath)
+	if err != nil {
+		log.G(ctx).WithError(err).Debugf("failed to read the CNI plugin directory %q", cniPath)
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		fi, err := e.Info()
+		if err != nil || !fi.Mode().IsRegular() || fi.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		names = append(names, e.Name())
+	}
+	res := make([]dockercompat.ComponentVersion, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res[i] = dockercompat.ComponentVersion{Name: name}
+			ctx, cancel := context.WithTimeout(ctx, cniPluginVersionTimeout)
+			defer cancel()
+			cmd := exec.CommandContext(ctx, filepath.Join(cniPath, name))
+			cmd.Env = []string{}
+			out, err := cmd.CombinedOutput()
+			if v := parseCNIPluginVersion(out); v != "" {
+				res[i].Version = v
+				return
+			}
+			log.G(ctx).WithError(err).Debugf("unable to determine the version of CNI plugin %q", name)
+		}()
+	}
+	wg.Wait()
+	return res
+}
+
+var cniPluginAboutRegexp = regexp.MustCompile(`^CNI .* (v?\d+\.\d+\.\d+\S*)$`)
+
+// parseCNIPluginVersion parses the "about" string of a CNI plugin.
+func parseCNIPluginVersion(out []byte) string {
+	for _, line := range strings.Split(string(out), "\n") {
+		if m := cniPluginAboutRegexp.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
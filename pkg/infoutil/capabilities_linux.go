@@ -0,0 +1,151 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package infoutil
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/containerd/cgroups/v3"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/apparmorutil"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+const binfmtMiscPath = "/proc/sys/fs/binfmt_misc"
+
+func fulfillPlatformCapabilities(ctx context.Context, caps *dockercompat.Capabilities) {
+	caps.Cgroup.Version = CgroupsVersion()
+	controllers, err := cgroupControllers()
+	if err != nil {
+		log.G(ctx).WithError(err).Debug("failed to get the cgroup controllers")
+	}
+	caps.Cgroup.Controllers = controllers
+
+	if rootlessutil.IsRootlessChild() {
+		caps.RootlessPortDriver = rootlessKitPortDriver(ctx)
+	}
+
+	binfmt, err := binfmtHandlers()
+	if err != nil {
+		log.G(ctx).WithError(err).Debug("failed to get the binfmt_misc handlers")
+	}
+	caps.Binfmt = binfmt
+
+	caps.Security.Seccomp = seccompEnabled()
+	caps.Security.AppArmor = apparmorutil.CanApplyExistingProfile()
+	caps.Security.SELinux = selinuxEnabled()
+}
+
+// cgroupControllers returns the available cgroup controllers.
+// For rootless cgroup v2, the controllers delegated to the user are returned.
+func cgroupControllers() ([]string, error) {
+	if cgroups.Mode() != cgroups.Unified {
+		return cgroupV1Controllers()
+	}
+	p := "/sys/fs/cgroup/cgroup.controllers"
+	if rootlessutil.IsRootless() {
+		euid := rootlessutil.ParentEUID()
+		p = fmt.Sprintf("/sys/fs/cgroup/user.slice/user-%d.slice/user@%d.service/cgroup.controllers", euid, euid)
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(b)), nil
+}
+
+// cgroupV1Controllers parses /proc/cgroups and returns the enabled controllers.
+func cgroupV1Controllers() ([]string, error) {
+	f, err := os.Open("/proc/cgroups")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var res []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		// #subsys_name	hierarchy	num_cgroups	enabled
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 4 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if fields[3] == "1" {
+			res = append(res, fields[0])
+		}
+	}
+	return res, sc.Err()
+}
+
+func rootlessKitPortDriver(ctx context.Context) string {
+	client, err := rootlessutil.NewRootlessKitClient()
+	if err != nil {
+		log.G(ctx).WithError(err).Debug("failed to create the RootlessKit client")
+		return ""
+	}
+	info, err := client.Info(ctx)
+	if err != nil || info.PortDriver == nil {
+		log.G(ctx).WithError(err).Debug("failed to get the port driver of RootlessKit")
+		return ""
+	}
+	return info.PortDriver.Driver
+}
+
+// binfmtHandlers returns the names of the registered binfmt_misc handlers, e.g., "qemu-aarch64".
+func binfmtHandlers() ([]string, error) {
+	entries, err := os.ReadDir(binfmtMiscPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var res []string
+	for _, e := range entries {
+		switch e.Name() {
+		case "register", "status":
+			continue
+		}
+		res = append(res, e.Name())
+	}
+	return res, nil
+}
+
+// seccompEnabled returns whether the kernel supports seccomp.
+func seccompEnabled() bool {
+	b, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.HasPrefix(line, "Seccomp:") {
+			return true
+		}
+	}
+	return false
+}
+
+// selinuxEnabled returns whether SELinux is enabled, by checking the presence of selinuxfs.
+func selinuxEnabled() bool {
+	_, err := os.Stat("/sys/fs/selinux/enforce")
+	return err == nil
+}
@@ -289,3 +289,8 @@ func BlockIOWriteIOpsDevice(cgroupManager string) bool {
 func CPURealtime(cgroupManager string) bool {
 	return getMobySysInfo(cgroupManager).CPURealtime
 }
+
+// MemorySwappiness returns whether memory swappiness is supported or not
+func MemorySwappiness(cgroupManager string) bool {
+	return getMobySysInfo(cgroupManager).MemorySwappiness
+}
@@ -83,3 +83,17 @@ libseccomp: 2.5.1`: {
 		}
 	}
 }
+
+func TestParseCNIPluginVersion(t *testing.T) {
+	testCases := map[string]string{
+		"CNI bridge plugin v1.6.2\nCNI protocol versions supported: 0.1.0, 0.2.0, 0.3.0, 0.3.1, 0.4.0, 1.0.0, 1.1.0\n": "v1.6.2",
+		"CNI tuning plugin v1.4.0-rc.1\n":        "v1.4.0-rc.1",
+		"CNI isolation plugin version unknown\n": "",
+		"unknown command\n":                      "",
+		"":                                       "",
+	}
+
+	for s, expected := range testCases {
+		assert.Equal(t, expected, parseCNIPluginVersion([]byte(s)))
+	}
+}
@@ -163,6 +163,8 @@ type HostConfig struct {
 	// Privileged      bool              // Is the container in privileged mode
 	// PublishAllPorts bool              // Should docker publish all exposed port for the container
 	ReadonlyRootfs bool // Is the container root filesystem in read-only
+	// Immutable is a nerdctl extension: the container was created with `--immutable`, which forbids exec and cp into it
+	Immutable bool `json:",omitempty"`
 	// SecurityOpt     []string          // List of string values to customize labels for MLS systems, such as SELinux.
 	Tmpfs   map[string]string `json:"Tmpfs,omitempty"` // List of tmpfs (mounts) used for the container
 	UTSMode string            // UTS namespace to use for the container
@@ -170,15 +172,18 @@ type HostConfig struct {
 	ShmSize            int64             // Size of /dev/shm in bytes. The size must be greater than 0.
 	Sysctls            map[string]string // List of Namespaced sysctls used for the container
 	Runtime            string            // Runtime to use with this container
-	CPUSetMems         string            `json:"CpusetMems"`         // CpusetMems 0-2, 0,1
-	CPUSetCPUs         string            `json:"CpusetCpus"`         // CpusetCpus 0-2, 0,1
-	CPUQuota           int64             `json:"CpuQuota"`           // CPU CFS (Completely Fair Scheduler) quota
-	CPUShares          uint64            `json:"CpuShares"`          // CPU shares (relative weight vs. other containers)
-	CPUPeriod          uint64            `json:"CpuPeriod"`          // Limits the CPU CFS (Completely Fair Scheduler) period
-	CPURealtimePeriod  uint64            `json:"CpuRealtimePeriod"`  // Limits the CPU real-time period in microseconds
-	CPURealtimeRuntime int64             `json:"CpuRealtimeRuntime"` // Limits the CPU real-time runtime in microseconds
+	StorageOpt         map[string]string `json:"StorageOpt,omitempty"` // Storage driver options per container.
+	CPUSetMems         string            `json:"CpusetMems"`           // CpusetMems 0-2, 0,1
+	CPUSetCPUs         string            `json:"CpusetCpus"`           // CpusetCpus 0-2, 0,1
+	CPUQuota           int64             `json:"CpuQuota"`             // CPU CFS (Completely Fair Scheduler) quota
+	CPUShares          uint64            `json:"CpuShares"`            // CPU shares (relative weight vs. other containers)
+	CPUPeriod          uint64            `json:"CpuPeriod"`            // Limits the CPU CFS (Completely Fair Scheduler) period
+	CPURealtimePeriod  uint64            `json:"CpuRealtimePeriod"`    // Limits the CPU real-time period in microseconds
+	CPURealtimeRuntime int64             `json:"CpuRealtimeRuntime"`   // Limits the CPU real-time runtime in microseconds
 	Memory             int64             // Memory limit (in bytes)
 	MemorySwap         int64             // Total memory usage (memory + swap); set `-1` to enable unlimited swap
+	MemoryReservation  int64             // Memory soft limit (in bytes)
+	MemorySwappiness   *int64            // Tuning container memory swappiness behaviour
 	OomKillDisable     bool              // specifies whether to disable OOM Killer
 	Devices            []DeviceMapping   // List of devices to map inside the container
 	LinuxBlkioSettings
@@ -221,9 +226,9 @@ type Config struct {
 	// TODO: NetworkDisabled bool                `json:",omitempty"` // Is network disabled
 	// TODO: MacAddress      string              `json:",omitempty"` // Mac Address of the container
 	// TODO: OnBuild         []string            // ONBUILD metadata that were defined on the image Dockerfile
-	Labels map[string]string `json:",omitempty"` // List of labels set to this container
-	// TODO: StopSignal      string              `json:",omitempty"` // Signal to stop a container
-	// TODO: StopTimeout     *int                `json:",omitempty"` // Timeout (in seconds) to stop a container
+	Labels      map[string]string `json:",omitempty"` // List of labels set to this container
+	StopSignal  string            `json:",omitempty"` // Signal to stop a container
+	StopTimeout *int              `json:",omitempty"` // Timeout (in seconds) to stop a container
 	// TODO: Shell           []string            `json:",omitempty"` // Shell for shell-form of RUN, CMD, ENTRYPOINT
 }
 
@@ -259,6 +264,8 @@ type HostConfigLabel struct {
 	BlkioWeight uint16
 	CidFile     string
 	Devices     []DeviceMapping
+	StorageOpt  map[string]string `json:",omitempty"`
+	GroupAdd    []string          `json:",omitempty"`
 }
 
 type DeviceMapping struct {
@@ -421,6 +428,7 @@ func ContainerFromNative(n *native.Container) (*Container, error) {
 
 	c.HostConfig.BlkioWeight = hostConfigLabel.BlkioWeight
 	c.HostConfig.ContainerIDFile = hostConfigLabel.CidFile
+	c.HostConfig.StorageOpt = hostConfigLabel.StorageOpt
 
 	groupAdd, err := groupAddFromNative(n.Spec.(*specs.Spec))
 	if err != nil {
@@ -450,8 +458,9 @@ func ContainerFromNative(n *native.Container) (*Container, error) {
 	cs.Error = n.Labels[labels.Error]
 	if n.Process != nil {
 		cs.Status = statusFromNative(n.Process.Status, n.Labels)
-		cs.Running = n.Process.Status.Status == containerd.Running
 		cs.Paused = n.Process.Status.Status == containerd.Paused
+		// Like Docker, a paused container is still running.
+		cs.Running = n.Process.Status.Status == containerd.Running || cs.Paused
 		cs.Pid = n.Process.Pid
 		cs.ExitCode = int(n.Process.Status.ExitStatus)
 		if containerAnnotations[labels.StateDir] != "" {
@@ -508,6 +517,8 @@ func ContainerFromNative(n *native.Container) (*Container, error) {
 	c.HostConfig.OomKillDisable = memorySettings.DisableOOMKiller
 	c.HostConfig.Memory = memorySettings.Limit
 	c.HostConfig.MemorySwap = memorySettings.Swap
+	c.HostConfig.MemoryReservation = memorySettings.Reservation
+	c.HostConfig.MemorySwappiness = memorySettings.Swappiness
 
 	dnsSettings, err := getDNSFromNative(n.Labels)
 	if err != nil {
@@ -528,6 +539,7 @@ func ContainerFromNative(n *native.Container) (*Container, error) {
 	if n.Spec.(*specs.Spec).Root != nil && n.Spec.(*specs.Spec).Root.Readonly {
 		c.HostConfig.ReadonlyRootfs = n.Spec.(*specs.Spec).Root.Readonly
 	}
+	c.HostConfig.Immutable = n.Labels[labels.Immutable] == "true"
 
 	utsMode, err := getUtsModeFromNative(n.Spec.(*specs.Spec))
 	if err != nil {
@@ -580,6 +592,13 @@ func ContainerFromNative(n *native.Container) (*Container, error) {
 		c.Config.User = n.Labels[labels.User]
 	}
 
+	c.Config.StopSignal = n.Labels[containerd.StopSignalLabel]
+	if v, ok := n.Labels[labels.StopTimeout]; ok {
+		if stopTimeout, err := strconv.Atoi(v); err == nil {
+			c.Config.StopTimeout = &stopTimeout
+		}
+	}
+
 	return c, nil
 }
 
@@ -834,6 +853,15 @@ func getMemorySettingsFromNative(sp *specs.Spec) (*MemorySetting, error) {
 		if sp.Linux.Resources.Memory.Swap != nil {
 			res.Swap = *sp.Linux.Resources.Memory.Swap
 		}
+
+		if sp.Linux.Resources.Memory.Reservation != nil {
+			res.Reservation = *sp.Linux.Resources.Memory.Reservation
+		}
+
+		if sp.Linux.Resources.Memory.Swappiness != nil {
+			swappiness := int64(*sp.Linux.Resources.Memory.Swappiness)
+			res.Swappiness = &swappiness
+		}
 	}
 	return res, nil
 }
@@ -904,6 +932,7 @@ type IPAM struct {
 type Network struct {
 	Name       string                      `json:"Name"`
 	ID         string                      `json:"Id,omitempty"` // optional in nerdctl
+	EnableIPv6 bool                        `json:"EnableIPv6"`
 	IPAM       IPAM                        `json:"IPAM,omitempty"`
 	Labels     map[string]string           `json:"Labels"`
 	Containers map[string]EndpointResource `json:"Containers"` // Containers contains endpoints belonging to the network
@@ -913,9 +942,12 @@ type Network struct {
 type EndpointResource struct {
 	Name string `json:"Name"`
 	// EndpointID  string `json:"EndpointID"`
-	// MacAddress  string `json:"MacAddress"`
-	// IPv4Address string `json:"IPv4Address"`
-	// IPv6Address string `json:"IPv6Address"`
+	MacAddress  string `json:"MacAddress"`
+	IPv4Address string `json:"IPv4Address"`
+	IPv6Address string `json:"IPv6Address"`
+	// RxBytes and TxBytes are nerdctl extensions, counted on the interface of the container
+	RxBytes uint64 `json:"RxBytes"`
+	TxBytes uint64 `json:"TxBytes"`
 }
 
 type structuredCNI struct {
@@ -928,9 +960,11 @@ type structuredCNI struct {
 }
 
 type MemorySetting struct {
-	Limit            int64 `json:"limit"`
-	Swap             int64 `json:"swap"`
-	DisableOOMKiller bool  `json:"disableOOMKiller"`
+	Limit            int64  `json:"limit"`
+	Swap             int64  `json:"swap"`
+	Reservation      int64  `json:"reservation"`
+	Swappiness       *int64 `json:"swappiness,omitempty"`
+	DisableOOMKiller bool   `json:"disableOOMKiller"`
 }
 
 func NetworkFromNative(n *native.Network) (*Network, error) {
@@ -948,6 +982,11 @@ func NetworkFromNative(n *native.Network) (*Network, error) {
 			res.IPAM.Config = append(res.IPAM.Config, ranges...)
 		}
 	}
+	for _, cfg := range res.IPAM.Config {
+		if ip, _, err := net.ParseCIDR(cfg.Subnet); err == nil && ip.To4() == nil {
+			res.EnableIPv6 = true
+		}
+	}
 
 	if n.NerdctlID != nil {
 		res.ID = *n.NerdctlID
@@ -957,15 +996,23 @@ func NetworkFromNative(n *native.Network) (*Network, error) {
 		res.Labels = *n.NerdctlLabels
 	}
 
+	endpoints := make(map[string]*native.NetworkEndpoint, len(n.Endpoints))
+	for _, ep := range n.Endpoints {
+		endpoints[ep.ContainerID] = ep
+	}
 	res.Containers = make(map[string]EndpointResource)
 	for _, container := range n.Containers {
-		res.Containers[container.ID] = EndpointResource{
+		er := EndpointResource{
 			Name: container.Labels[labels.Name],
-			// EndpointID:  container.EndpointID,
-			// MacAddress:  container.MacAddress,
-			// IPv4Address: container.IPv4Address,
-			// IPv6Address: container.IPv6Address,
 		}
+		if ep, ok := endpoints[container.ID]; ok {
+			er.MacAddress = ep.MacAddress
+			er.IPv4Address = ep.IPv4Address
+			er.IPv6Address = ep.IPv6Address
+			er.RxBytes = ep.RxBytes
+			er.TxBytes = ep.TxBytes
+		}
+		res.Containers[container.ID] = er
 	}
 
 	return &res, nil
@@ -511,3 +511,37 @@ func TestCpuSettingsFromNative(t *testing.T) {
 		})
 	}
 }
+
+func TestNetworkFromNative(t *testing.T) {
+	id := "1234"
+	n := &native.Network{
+		CNI:       []byte(`{"name":"foo","plugins":[{"ipam":{"ranges":[[{"subnet":"10.4.2.0/24","gateway":"10.4.2.1"}]]}}]}`),
+		NerdctlID: &id,
+		Containers: []*native.Container{
+			{Container: containers.Container{ID: "c1", Labels: map[string]string{"nerdctl/name": "web"}}},
+			{Container: containers.Container{ID: "c2", Labels: map[string]string{"nerdctl/name": "db"}}},
+		},
+		Endpoints: []*native.NetworkEndpoint{
+			{
+				ContainerID: "c1",
+				IfName:      "eth0",
+				MacAddress:  "aa:bb:cc:dd:ee:ff",
+				IPv4Address: "10.4.2.2/24",
+				RxBytes:     100,
+				TxBytes:     200,
+			},
+		},
+	}
+	d, err := NetworkFromNative(n)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, d.Containers, map[string]EndpointResource{
+		"c1": {
+			Name:        "web",
+			MacAddress:  "aa:bb:cc:dd:ee:ff",
+			IPv4Address: "10.4.2.2/24",
+			RxBytes:     100,
+			TxBytes:     200,
+		},
+		"c2": {Name: "db"},
+	})
+}
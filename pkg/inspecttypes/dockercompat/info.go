@@ -60,6 +60,9 @@ type Info struct {
 	ServerVersion   string
 	SecurityOptions []string
 
+	// Capabilities is a nerdctl extension
+	Capabilities *Capabilities `json:",omitempty"`
+
 	Warnings []string
 }
 
@@ -68,6 +71,37 @@ type PluginsInfo struct {
 	Storage []string // nerdctl extension
 }
 
+// Capabilities describes the runtime capabilities of the host (nerdctl extension).
+type Capabilities struct {
+	Cgroup             CgroupCapabilities
+	Snapshotters       []SnapshotterCapability
+	CNIPlugins         []ComponentVersion
+	RootlessPortDriver string `json:",omitempty"`
+	Binfmt             []string
+	Security           SecurityCapabilities
+}
+
+// CgroupCapabilities describes the cgroup version and the controllers available to nerdctl.
+// For rootless mode, Controllers contains the controllers delegated to the user.
+type CgroupCapabilities struct {
+	Version     string
+	Controllers []string
+}
+
+// SnapshotterCapability describes a snapshotter plugin and whether it was initialized successfully.
+type SnapshotterCapability struct {
+	Name    string
+	Healthy bool
+	Error   string `json:",omitempty"`
+}
+
+// SecurityCapabilities describes the availability of the security modules.
+type SecurityCapabilities struct {
+	Seccomp  bool
+	AppArmor bool
+	SELinux  bool
+}
+
 // VersionInfo mimics a `docker version` object.
 // From https://github.com/docker/cli/blob/v20.10.8/cli/command/system/version.go#L68-L72
 type VersionInfo struct {
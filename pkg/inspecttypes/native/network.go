@@ -25,4 +25,18 @@ type Network struct {
 	NerdctlLabels *map[string]string `json:"NerdctlLabels,omitempty"`
 	File          string             `json:"File,omitempty"`
 	Containers    []*Container       `json:"Containers"`
+	Endpoints     []*NetworkEndpoint `json:"Endpoints,omitempty"`
+}
+
+// NetworkEndpoint is the interface of a running container attached to the network.
+type NetworkEndpoint struct {
+	ContainerID string `json:"ContainerID"`
+	// IfName is the name of the interface inside the container, e.g., "eth0"
+	IfName      string `json:"IfName,omitempty"`
+	MacAddress  string `json:"MacAddress,omitempty"`
+	IPv4Address string `json:"IPv4Address,omitempty"`
+	IPv6Address string `json:"IPv6Address,omitempty"`
+	// RxBytes and TxBytes are the counters of the interface, as seen from the container
+	RxBytes uint64 `json:"RxBytes"`
+	TxBytes uint64 `json:"TxBytes"`
 }
@@ -0,0 +1,33 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package native
+
+import "time"
+
+// Secret is a secret created with `nerdctl secret create`.
+// The data of the secret is never included.
+type Secret struct {
+	Name      string            `json:"Name"`
+	CreatedAt time.Time         `json:"CreatedAt"`
+	Labels    map[string]string `json:"Labels,omitempty"`
+	// Size is the size of the data in bytes, before the encryption
+	Size int64 `json:"Size"`
+	// Encrypted is true when the data is stored encrypted with the encrypt_command of nerdctl.toml
+	Encrypted bool `json:"Encrypted"`
+	// DecryptCommand is the decrypt_command of nerdctl.toml at the creation of the secret
+	DecryptCommand []string `json:"DecryptCommand,omitempty"`
+}
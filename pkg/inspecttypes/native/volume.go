@@ -16,10 +16,25 @@
 
 package native
 
+import "time"
+
 // Volume is also compatible with Docker
 type Volume struct {
 	Name       string             `json:"Name"`
 	Mountpoint string             `json:"Mountpoint"`
 	Labels     *map[string]string `json:"Labels,omitempty"`
+	Driver     string             `json:"Driver,omitempty"`
+	Options    map[string]string  `json:"Options,omitempty"`
 	Size       int64              `json:"Size,omitempty"`
+	// SizeLimit is the size limit of the volume in bytes, set with the "size" option of the local driver
+	SizeLimit int64 `json:"SizeLimit,omitempty"`
+}
+
+// VolumeSnapshot is a snapshot of a volume, taken with `nerdctl volume snapshot create`
+type VolumeSnapshot struct {
+	Name      string    `json:"Name"`
+	Volume    string    `json:"Volume"`
+	CreatedAt time.Time `json:"CreatedAt"`
+	// Method is "btrfs", "zfs", or "copy"
+	Method string `json:"Method"`
 }
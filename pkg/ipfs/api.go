@@ -0,0 +1,188 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package ipfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/containerd/containerd/v2/core/images"
+	ipfsclient "github.com/containerd/stargz-snapshotter/ipfs/client"
+)
+
+// newClient returns the client of the IPFS API of the repository `ipfsPath`.
+func newClient(ipfsPath string) (*ipfsclient.Client, error) {
+	// HTTP is only supported as of now, like the other commands
+	iurl, err := ipfsclient.GetIPFSAPIAddress(lookupIPFSPath(ipfsPath), "http")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the IPFS API address (is the IPFS daemon running?): %w", err)
+	}
+	return ipfsclient.New(iurl), nil
+}
+
+// call calls the command `cmd` (e.g., "pin/add") of the IPFS API, and returns the response body.
+// The caller has to close the body.
+func call(ctx context.Context, c *ipfsclient.Client, cmd string, args url.Values, body io.Reader, contentType string) (io.ReadCloser, error) {
+	u := c.Address + "/api/v0/" + cmd
+	if len(args) > 0 {
+		u += "?" + args.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	httpClient := c.Client
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		// The errors of the IPFS API are like {"Message": "not pinned or pinned indirectly", "Code": 0, "Type": "error"}
+		var apiErr struct {
+			Message string `json:"Message"`
+		}
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if json.Unmarshal(b, &apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(b))
+		}
+		return nil, fmt.Errorf("IPFS API %q failed with status %d: %s", cmd, resp.StatusCode, apiErr.Message)
+	}
+	return resp.Body, nil
+}
+
+// ImageCIDs returns the CIDs of all the blobs of the image `rootCID`, including `rootCID` itself first.
+//
+// The root of an image on IPFS is the JSON descriptor of the image index or the manifest, and the descriptors
+// of the blobs record their CIDs as "ipfs://<CID>" URLs.
+func ImageCIDs(ctx context.Context, ipfsPath, rootCID string) ([]string, error) {
+	c, err := newClient(ipfsPath)
+	if err != nil {
+		return nil, err
+	}
+	return imageCIDs(ctx, c, rootCID)
+}
+
+func imageCIDs(ctx context.Context, c *ipfsclient.Client, rootCID string) ([]string, error) {
+	var desc ocispec.Descriptor
+	if err := getJSON(c, rootCID, &desc); err != nil {
+		return nil, fmt.Errorf("failed to read the root descriptor %q: %w", rootCID, err)
+	}
+	cids := []string{rootCID}
+	seen := map[string]struct{}{rootCID: {}}
+	var walk func(desc ocispec.Descriptor) error
+	walk = func(desc ocispec.Descriptor) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cid, err := getIPFSCID(desc)
+		if err != nil {
+			return err
+		}
+		if _, ok := seen[cid]; ok {
+			return nil
+		}
+		seen[cid] = struct{}{}
+		cids = append(cids, cid)
+		if !images.IsManifestType(desc.MediaType) && !images.IsIndexType(desc.MediaType) {
+			return nil
+		}
+		// Both the image indexes and the manifests, of Docker and OCI
+		var children struct {
+			Config    *ocispec.Descriptor  `json:"config,omitempty"`
+			Layers    []ocispec.Descriptor `json:"layers,omitempty"`
+			Manifests []ocispec.Descriptor `json:"manifests,omitempty"`
+		}
+		if err := getJSON(c, cid, &children); err != nil {
+			return fmt.Errorf("failed to read %s (%q): %w", desc.Digest, cid, err)
+		}
+		if children.Config != nil {
+			if err := walk(*children.Config); err != nil {
+				return err
+			}
+		}
+		for _, d := range append(children.Manifests, children.Layers...) {
+			if err := walk(d); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(desc); err != nil {
+		return nil, err
+	}
+	return cids, nil
+}
+
+func getJSON(c *ipfsclient.Client, cid string, v any) error {
+	rc, err := c.Get("/ipfs/"+cid, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return json.NewDecoder(rc).Decode(v)
+}
+
+// Pin pins all the blobs of the image `rootCID`, so that the garbage collection of IPFS does not remove them.
+// The pinned CIDs are returned.
+func Pin(ctx context.Context, ipfsPath, rootCID string) ([]string, error) {
+	return updatePins(ctx, ipfsPath, rootCID, "pin/add")
+}
+
+// Unpin unpins all the blobs of the image `rootCID`. The blobs that are not pinned are ignored.
+// The unpinned CIDs are returned.
+func Unpin(ctx context.Context, ipfsPath, rootCID string) ([]string, error) {
+	return updatePins(ctx, ipfsPath, rootCID, "pin/rm")
+}
+
+func updatePins(ctx context.Context, ipfsPath, rootCID, cmd string) ([]string, error) {
+	c, err := newClient(ipfsPath)
+	if err != nil {
+		return nil, err
+	}
+	cids, err := imageCIDs(ctx, c, rootCID)
+	if err != nil {
+		return nil, err
+	}
+	var updated []string
+	for _, cid := range cids {
+		rc, err := call(ctx, c, cmd, url.Values{"arg": {cid}}, nil, "")
+		if err != nil {
+			if cmd == "pin/rm" && strings.Contains(err.Error(), "not pinned") {
+				continue
+			}
+			return updated, err
+		}
+		_, _ = io.Copy(io.Discard, rc)
+		rc.Close()
+		updated = append(updated, cid)
+	}
+	return updated, nil
+}
@@ -0,0 +1,336 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package ipfs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+
+	"github.com/ipfs/go-cid"
+)
+
+// CAR (Content Addressable aRchive) v1 is a header followed by the blocks.
+// The header and each block are prefixed with their length in unsigned varint.
+// The header is DAG-CBOR of {"roots": [CID...], "version": 1}.
+// https://ipld.io/specs/transport/car/carv1/
+
+// maxCARHeaderSize limits the size of the CAR headers read.
+const maxCARHeaderSize = 32 * 1024 * 1024
+
+// ExportCAR writes the image `rootCID` to `w` as a CAR file, so that it can be imported with ImportCAR
+// to the IPFS of a host that is not connected to the IPFS network.
+//
+// The roots of the CAR file are the CIDs of all the blobs of the image, with `rootCID` first.
+func ExportCAR(ctx context.Context, ipfsPath, rootCID string, w io.Writer) error {
+	c, err := newClient(ipfsPath)
+	if err != nil {
+		return err
+	}
+	cids, err := imageCIDs(ctx, c, rootCID)
+	if err != nil {
+		return err
+	}
+	roots := make([]cid.Cid, len(cids))
+	for i, s := range cids {
+		roots[i], err = cid.Decode(s)
+		if err != nil {
+			return fmt.Errorf("invalid CID %q: %w", s, err)
+		}
+	}
+	if err := writeCARHeader(w, roots); err != nil {
+		return err
+	}
+	// "dag/export" only supports a single root, so the blocks of the CAR files of the roots are concatenated.
+	// The duplicated blocks are allowed by CAR.
+	for _, s := range cids {
+		rc, err := call(ctx, c, "dag/export", url.Values{"arg": {s}, "progress": {"false"}}, nil, "")
+		if err != nil {
+			return err
+		}
+		br := bufio.NewReader(rc)
+		if _, err := readCARHeader(br); err != nil {
+			rc.Close()
+			return fmt.Errorf("failed to export %q: %w", s, err)
+		}
+		_, err = io.Copy(w, br)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportCAR imports the CAR file read from `r` to IPFS, and returns the first root, i.e., the root CID of the image
+// exported by ExportCAR. The roots are pinned when `pin` is true.
+func ImportCAR(ctx context.Context, ipfsPath string, r io.Reader, pin bool) (string, error) {
+	c, err := newClient(ipfsPath)
+	if err != nil {
+		return "", err
+	}
+	// The header is read before sending the CAR file, so that an invalid file fails early
+	var header bytes.Buffer
+	br := bufio.NewReader(r)
+	roots, err := readCARHeader(io.TeeReader(br, &header))
+	if err != nil {
+		return "", err
+	}
+	if len(roots) == 0 {
+		return "", errors.New("the CAR file has no root")
+	}
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		fw, err := mw.CreateFormFile("file", "image.car")
+		if err == nil {
+			_, err = io.Copy(fw, io.MultiReader(&header, br))
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	rc, err := call(ctx, c, "dag/import", url.Values{"pin-roots": {fmt.Sprint(pin)}}, pr, mw.FormDataContentType())
+	if err != nil {
+		pr.CloseWithError(err)
+		return "", err
+	}
+	defer rc.Close()
+	// The response is a stream of JSON objects like {"Root": {"Cid": {"/": "<CID>"}, "PinErrorMsg": ""}}
+	dec := json.NewDecoder(rc)
+	for {
+		var res struct {
+			Root *struct {
+				PinErrorMsg string `json:"PinErrorMsg"`
+			} `json:"Root"`
+		}
+		if err := dec.Decode(&res); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return "", err
+		}
+		if res.Root != nil && res.Root.PinErrorMsg != "" {
+			return "", fmt.Errorf("failed to pin the roots: %s", res.Root.PinErrorMsg)
+		}
+	}
+	return roots[0].String(), nil
+}
+
+// writeCARHeader writes the header of a CAR v1 file with the roots.
+func writeCARHeader(w io.Writer, roots []cid.Cid) error {
+	// DAG-CBOR sorts the keys of the maps by their length
+	h := appendCBORHead(nil, cborMap, 2)
+	h = appendCBORText(h, "roots")
+	h = appendCBORHead(h, cborArray, uint64(len(roots)))
+	for _, c := range roots {
+		// A CID is the tag 42 of the bytes of the binary CID, prefixed with the multibase identity prefix 0x00
+		h = append(h, 0xd8, cborTagCID)
+		b := append([]byte{0x00}, c.Bytes()...)
+		h = appendCBORHead(h, cborBytes, uint64(len(b)))
+		h = append(h, b...)
+	}
+	h = appendCBORText(h, "version")
+	h = appendCBORHead(h, cborUint, 1)
+
+	_, err := w.Write(append(binary.AppendUvarint(nil, uint64(len(h))), h...))
+	return err
+}
+
+// readCARHeader reads the header of a CAR v1 file, and returns the roots.
+func readCARHeader(r io.Reader) ([]cid.Cid, error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = &byteReader{r}
+	}
+	size, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CAR header: %w", err)
+	}
+	if size == 0 || size > maxCARHeaderSize {
+		return nil, fmt.Errorf("invalid CAR header size %d", size)
+	}
+	h := make([]byte, size)
+	if _, err := io.ReadFull(r, h); err != nil {
+		return nil, fmt.Errorf("failed to read the CAR header: %w", err)
+	}
+	d := &cborDecoder{b: h}
+	n, err := d.head(cborMap)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		roots   []cid.Cid
+		version uint64
+	)
+	for i := uint64(0); i < n; i++ {
+		key, err := d.text()
+		if err != nil {
+			return nil, err
+		}
+		switch key {
+		case "roots":
+			if roots, err = d.cids(); err != nil {
+				return nil, err
+			}
+		case "version":
+			if version, err = d.head(cborUint); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unexpected key %q in the CAR header", key)
+		}
+	}
+	if version != 1 {
+		return nil, fmt.Errorf("unsupported CAR version %d (only 1 is supported)", version)
+	}
+	return roots, nil
+}
+
+type byteReader struct {
+	io.Reader
+}
+
+func (r *byteReader) ReadByte() (byte, error) {
+	var b [1]byte
+	_, err := io.ReadFull(r.Reader, b[:])
+	return b[0], err
+}
+
+// The CBOR major types, and the tag of CIDs, used by the CAR headers
+const (
+	cborUint   = 0
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborTag    = 6
+	cborTagCID = 42
+)
+
+func appendCBORHead(b []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= 0xff:
+		return append(b, major|24, byte(n))
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(n))
+	case n <= 0xffffffff:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, major|27), n)
+	}
+}
+
+func appendCBORText(b []byte, s string) []byte {
+	return append(appendCBORHead(b, cborText, uint64(len(s))), s...)
+}
+
+// cborDecoder decodes the subset of CBOR used by the CAR headers.
+type cborDecoder struct {
+	b []byte
+}
+
+var errInvalidCARHeader = errors.New("invalid CAR header")
+
+// head reads the head of an item of the major type, and returns its argument (e.g., the length of a text).
+func (d *cborDecoder) head(major byte) (uint64, error) {
+	if len(d.b) == 0 || d.b[0]>>5 != major {
+		return 0, errInvalidCARHeader
+	}
+	info := d.b[0] & 0x1f
+	d.b = d.b[1:]
+	var size int
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, errInvalidCARHeader
+	}
+	if len(d.b) < size {
+		return 0, errInvalidCARHeader
+	}
+	var n uint64
+	for _, c := range d.b[:size] {
+		n = n<<8 | uint64(c)
+	}
+	d.b = d.b[size:]
+	return n, nil
+}
+
+func (d *cborDecoder) bytes(major byte) ([]byte, error) {
+	n, err := d.head(major)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(d.b)) < n {
+		return nil, errInvalidCARHeader
+	}
+	b := d.b[:n]
+	d.b = d.b[n:]
+	return b, nil
+}
+
+func (d *cborDecoder) text() (string, error) {
+	b, err := d.bytes(cborText)
+	return string(b), err
+}
+
+func (d *cborDecoder) cids() ([]cid.Cid, error) {
+	n, err := d.head(cborArray)
+	if err != nil {
+		return nil, err
+	}
+	var cids []cid.Cid
+	for i := uint64(0); i < n; i++ {
+		if tag, err := d.head(cborTag); err != nil || tag != cborTagCID {
+			return nil, errInvalidCARHeader
+		}
+		b, err := d.bytes(cborBytes)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) == 0 || b[0] != 0x00 {
+			return nil, errInvalidCARHeader
+		}
+		c, err := cid.Cast(b[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid CID in the CAR header: %w", err)
+		}
+		cids = append(cids, c)
+	}
+	return cids, nil
+}
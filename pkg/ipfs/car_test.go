@@ -0,0 +1,252 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package ipfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+// fakeIPFS is a fake IPFS API with the subset of the commands used by nerdctl.
+type fakeIPFS struct {
+	files    map[string][]byte
+	pins     map[string]bool
+	imported []byte
+	pinRoots string
+}
+
+func newFakeIPFS() *fakeIPFS {
+	return &fakeIPFS{files: map[string][]byte{}, pins: map[string]bool{}}
+}
+
+func (f *fakeIPFS) add(t *testing.T, data []byte) string {
+	t.Helper()
+	c, err := cid.Prefix{Version: 1, Codec: cid.Raw, MhType: 0x12 /* sha2-256 */, MhLength: -1}.Sum(data)
+	assert.NilError(t, err)
+	f.files[c.String()] = data
+	return c.String()
+}
+
+// addBlob adds a blob, and returns its descriptor with the CID.
+func (f *fakeIPFS) addBlob(t *testing.T, mediaType string, data []byte) ocispec.Descriptor {
+	t.Helper()
+	return ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(data),
+		Size:      int64(len(data)),
+		URLs:      []string{"ipfs://" + f.add(t, data)},
+	}
+}
+
+// addImage adds an image, and returns the CIDs of the root and the blobs.
+func (f *fakeIPFS) addImage(t *testing.T) []string {
+	t.Helper()
+	config := f.addBlob(t, ocispec.MediaTypeImageConfig, []byte(`{"architecture":"amd64","os":"linux"}`))
+	layer := f.addBlob(t, ocispec.MediaTypeImageLayerGzip, []byte("layer"))
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    []ocispec.Descriptor{layer},
+	})
+	assert.NilError(t, err)
+	manifest := f.addBlob(t, ocispec.MediaTypeImageManifest, manifestJSON)
+	rootJSON, err := json.Marshal(manifest)
+	assert.NilError(t, err)
+	root := f.add(t, rootJSON)
+	return []string{root, strings.TrimPrefix(manifest.URLs[0], "ipfs://"),
+		strings.TrimPrefix(config.URLs[0], "ipfs://"), strings.TrimPrefix(layer.URLs[0], "ipfs://")}
+}
+
+func (f *fakeIPFS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	arg := r.URL.Query().Get("arg")
+	switch r.URL.Path {
+	case "/api/v0/cat":
+		data, ok := f.files[strings.TrimPrefix(arg, "/ipfs/")]
+		if !ok {
+			http.Error(w, `{"Message": "not found"}`, http.StatusInternalServerError)
+			return
+		}
+		w.Write(data)
+	case "/api/v0/dag/export":
+		data, ok := f.files[arg]
+		if !ok {
+			http.Error(w, `{"Message": "not found"}`, http.StatusInternalServerError)
+			return
+		}
+		c := cid.MustParse(arg)
+		writeCARHeader(w, []cid.Cid{c})
+		section := append(c.Bytes(), data...)
+		w.Write(append(binary.AppendUvarint(nil, uint64(len(section))), section...))
+	case "/api/v0/dag/import":
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.imported, _ = io.ReadAll(file)
+		f.pinRoots = r.URL.Query().Get("pin-roots")
+		fmt.Fprintln(w, `{"Root": {"Cid": {"/": "dummy"}, "PinErrorMsg": ""}}`)
+	case "/api/v0/pin/add":
+		f.pins[arg] = true
+		fmt.Fprintf(w, `{"Pins": [%q]}`, arg)
+	case "/api/v0/pin/rm":
+		if !f.pins[arg] {
+			http.Error(w, `{"Message": "not pinned or pinned indirectly", "Code": 0, "Type": "error"}`, http.StatusInternalServerError)
+			return
+		}
+		delete(f.pins, arg)
+		fmt.Fprintf(w, `{"Pins": [%q]}`, arg)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// start starts the fake IPFS API, and returns the IPFS_PATH that has its address.
+func (f *fakeIPFS) start(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	host, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	assert.NilError(t, err)
+	ipfsPath := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(ipfsPath, "api"), []byte("/ip4/"+host+"/tcp/"+port), 0o600))
+	return ipfsPath
+}
+
+func TestCARHeader(t *testing.T) {
+	f := newFakeIPFS()
+	var roots []cid.Cid
+	// More than 23 roots, for the longer CBOR heads
+	for i := 0; i < 30; i++ {
+		roots = append(roots, cid.MustParse(f.add(t, []byte(fmt.Sprint(i)))))
+	}
+	var buf bytes.Buffer
+	assert.NilError(t, writeCARHeader(&buf, roots))
+	got, err := readCARHeader(&buf)
+	assert.NilError(t, err)
+	assert.Equal(t, len(got), len(roots))
+	for i := range roots {
+		assert.Assert(t, got[i].Equals(roots[i]))
+	}
+
+	_, err = readCARHeader(strings.NewReader("\x02\xa0\x00"))
+	assert.ErrorContains(t, err, "unsupported CAR version 0")
+	_, err = readCARHeader(strings.NewReader("\x03\x80\x00\x00"))
+	assert.ErrorIs(t, err, errInvalidCARHeader)
+}
+
+func TestPin(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeIPFS()
+	cids := f.addImage(t)
+	ipfsPath := f.start(t)
+
+	got, err := ImageCIDs(ctx, ipfsPath, cids[0])
+	assert.NilError(t, err)
+	assert.DeepEqual(t, got, cids)
+
+	pinned, err := Pin(ctx, ipfsPath, cids[0])
+	assert.NilError(t, err)
+	assert.DeepEqual(t, pinned, cids)
+	assert.Equal(t, len(f.pins), len(cids))
+
+	// Unpinning ignores the blobs that are not pinned
+	delete(f.pins, cids[3])
+	unpinned, err := Unpin(ctx, ipfsPath, cids[0])
+	assert.NilError(t, err)
+	assert.DeepEqual(t, unpinned, cids[:3])
+	assert.Equal(t, len(f.pins), 0)
+}
+
+func TestExportImportCAR(t *testing.T) {
+	ctx := context.Background()
+	src := newFakeIPFS()
+	cids := src.addImage(t)
+
+	var car bytes.Buffer
+	assert.NilError(t, ExportCAR(ctx, src.start(t), cids[0], &car))
+
+	roots, err := readCARHeader(bytes.NewReader(car.Bytes()))
+	assert.NilError(t, err)
+	var rootStrs []string
+	for _, r := range roots {
+		rootStrs = append(rootStrs, r.String())
+	}
+	assert.DeepEqual(t, rootStrs, cids)
+
+	dst := newFakeIPFS()
+	rootCID, err := ImportCAR(ctx, dst.start(t), bytes.NewReader(car.Bytes()), false)
+	assert.NilError(t, err)
+	assert.Equal(t, rootCID, cids[0])
+	assert.DeepEqual(t, dst.imported, car.Bytes())
+	assert.Equal(t, dst.pinRoots, "false")
+
+	_, err = ImportCAR(ctx, dst.start(t), strings.NewReader("not a CAR file"), true)
+	assert.Assert(t, err != nil)
+}
+
+func TestGatewayResolver(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeIPFS()
+	cids := f.addImage(t)
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := f.files[strings.TrimPrefix(r.URL.Path, "/ipfs/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(gateway.Close)
+
+	_, err := NewGatewayResolver("ipfs", "ipfs.io")
+	assert.ErrorContains(t, err, "invalid IPFS gateway")
+
+	r, err := NewGatewayResolver("ipfs", gateway.URL+"/")
+	assert.NilError(t, err)
+	_, desc, err := r.Resolve(ctx, cids[0])
+	assert.NilError(t, err)
+	assert.Equal(t, desc.MediaType, ocispec.MediaTypeImageManifest)
+
+	fetcher, err := r.Fetcher(ctx, cids[0])
+	assert.NilError(t, err)
+	rc, err := fetcher.Fetch(ctx, desc)
+	assert.NilError(t, err)
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	assert.NilError(t, err)
+	assert.Equal(t, digest.FromBytes(data), desc.Digest)
+
+	_, _, err = r.Resolve(ctx, "bafkreinotfound")
+	assert.ErrorContains(t, err, "not found")
+}
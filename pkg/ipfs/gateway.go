@@ -0,0 +1,110 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package ipfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/containerd/containerd/v2/core/remotes"
+	"github.com/containerd/errdefs"
+)
+
+// gatewayResolver is a read-only resolver that fetches the images from an HTTP gateway of IPFS (e.g., "https://ipfs.io"),
+// for the hosts without the IPFS daemon.
+//
+// The digests of the blobs are verified by containerd on fetching, but the root descriptor is trusted as the gateway returns it.
+type gatewayResolver struct {
+	scheme  string
+	gateway string
+	client  *http.Client
+}
+
+// NewGatewayResolver returns a resolver that fetches the images from the HTTP gateway.
+func NewGatewayResolver(scheme, gateway string) (remotes.Resolver, error) {
+	if scheme != "ipfs" && scheme != "ipns" {
+		return nil, fmt.Errorf("unsupported scheme %q", scheme)
+	}
+	if !strings.HasPrefix(gateway, "http://") && !strings.HasPrefix(gateway, "https://") {
+		return nil, fmt.Errorf("invalid IPFS gateway %q (expected \"http://\" or \"https://\")", gateway)
+	}
+	return &gatewayResolver{
+		scheme:  scheme,
+		gateway: strings.TrimSuffix(gateway, "/"),
+		client:  http.DefaultClient,
+	}, nil
+}
+
+func (r *gatewayResolver) get(ctx context.Context, scheme, name string) (io.ReadCloser, error) {
+	u := r.gateway + "/" + scheme + "/" + name
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %w", u, errdefs.ErrNotFound)
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to get %s: status %d", u, resp.StatusCode)
+	}
+}
+
+// Resolve resolves the CID (or the IPNS name) of the root descriptor.
+func (r *gatewayResolver) Resolve(ctx context.Context, ref string) (string, ocispec.Descriptor, error) {
+	rc, err := r.get(ctx, r.scheme, ref)
+	if err != nil {
+		return "", ocispec.Descriptor{}, err
+	}
+	defer rc.Close()
+	var desc ocispec.Descriptor
+	if err := json.NewDecoder(rc).Decode(&desc); err != nil {
+		return "", ocispec.Descriptor{}, err
+	}
+	if _, err := getIPFSCID(desc); err != nil {
+		return "", ocispec.Descriptor{}, err
+	}
+	return ref, desc, nil
+}
+
+func (r *gatewayResolver) Fetcher(ctx context.Context, ref string) (remotes.Fetcher, error) {
+	return remotes.FetcherFunc(func(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+		c, err := getIPFSCID(desc)
+		if err != nil {
+			return nil, err
+		}
+		// The blobs are always addressed by their CIDs, even for IPNS
+		return r.get(ctx, "ipfs", c)
+	}), nil
+}
+
+func (r *gatewayResolver) Pusher(ctx context.Context, ref string) (remotes.Pusher, error) {
+	return nil, fmt.Errorf("immutable remote")
+}
@@ -36,6 +36,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/imagewalker"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
 	"github.com/containerd/nerdctl/v2/pkg/platformutil"
 )
 
@@ -56,6 +57,10 @@ func EnsureImage(ctx context.Context, client *containerd.Client, scheme, ref, ip
 		return nil, fmt.Errorf("unexpected scheme: %q", scheme)
 	}
 
+	if options.Mode == "always" && options.GOptions.Offline {
+		return nil, fmt.Errorf("cannot pull %q: %w", ref, dockerconfigresolver.ErrOffline)
+	}
+
 	// if not `always` pull and given one platform and image found locally, return existing image directly.
 	if options.Mode != "always" && len(options.OCISpecPlatform) == 1 {
 		if res, err := imgutil.GetExistingImage(ctx, client, options.GOptions.Snapshotter, ref, options.OCISpecPlatform[0]); err == nil {
@@ -68,12 +73,22 @@ func EnsureImage(ctx context.Context, client *containerd.Client, scheme, ref, ip
 	if options.Mode == "never" {
 		return nil, fmt.Errorf("image %q is not available", ref)
 	}
+	if options.GOptions.Offline {
+		return nil, fmt.Errorf("image %q is not available locally: %w", ref, dockerconfigresolver.ErrOffline)
+	}
 	r, err := ipfs.NewResolver(ipfs.ResolverOptions{
 		Scheme:   scheme,
 		IPFSPath: lookupIPFSPath(ipfsPath),
 	})
 	if err != nil {
-		return nil, err
+		if options.IPFSGateway == "" {
+			return nil, err
+		}
+		log.G(ctx).WithError(err).Infof("IPFS API is not available, falling back to the gateway %q", options.IPFSGateway)
+		r, err = NewGatewayResolver(scheme, options.IPFSGateway)
+		if err != nil {
+			return nil, err
+		}
 	}
 	return imgutil.PullImage(ctx, client, r, ref, options)
 }
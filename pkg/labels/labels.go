@@ -60,6 +60,15 @@ const (
 	// Ports is a JSON-marshalled string of []cni.PortMapping .
 	Ports = Prefix + "ports"
 
+	// NetworkShaping is a JSON-marshalled string of netutil.Shaping, for `--network-bandwidth` and `--network-delay`
+	NetworkShaping = Prefix + "network-shaping"
+
+	// IngressAllowFrom is a JSON-marshalled string of []string, for `--allow-from`
+	IngressAllowFrom = Prefix + "ingress-allow-from"
+
+	// UserlandProxy is set to "true" when the published ports are also served by a userland proxy
+	UserlandProxy = Prefix + "userland-proxy"
+
 	// IPAddress is the static IP address of the container assigned by the user
 	IPAddress = Prefix + "ip"
 
@@ -118,4 +127,51 @@ const (
 
 	// User is the username of the container
 	User = Prefix + "user"
+
+	// EvictionPriority is the priority of the container for `nerdctl system watchdog`, as an integer.
+	// The containers with lower priorities are evicted first. "never" protects the container from eviction.
+	EvictionPriority = Prefix + "eviction-priority"
+
+	// Hooks is a JSON-marshalled containerutil.Hooks, the lifecycle hooks set by
+	// `nerdctl run --on-start`, `--on-post-start`, and `--on-stop`.
+	Hooks = Prefix + "hooks"
+
+	// WatchConfig is a JSON-marshalled watchconfig.Config, the config files watched by `nerdctl run --watch-config`.
+	WatchConfig = Prefix + "watch-config"
+
+	// Secrets is a JSON-marshalled secretstore.ContainerSecrets, the secrets set by `nerdctl run --secret`
+	Secrets = Prefix + "secrets"
+
+	// AutoUpdatePolicy is the policy of `nerdctl container auto-update`, "registry" or "local".
+	// The label is set by the user, and is compatible with Podman.
+	AutoUpdatePolicy = "io.containers.autoupdate"
+
+	// AutoUpdate is a JSON-marshalled record of the command line and the image digest of the container,
+	// for re-creating the container in `nerdctl container auto-update`. Only set along with AutoUpdatePolicy.
+	AutoUpdate = Prefix + "auto-update"
+
+	// Immutable is "true" for the containers created with `nerdctl run --immutable`,
+	// which forbids `nerdctl exec` and `nerdctl cp` into them.
+	Immutable = Prefix + "immutable"
+
+	// Adopted is "true" for the containers created by another tool (e.g., ctr or CRI)
+	// and adopted with `nerdctl container adopt`.
+	Adopted = Prefix + "adopted"
+)
+
+// The following labels are set to containerd namespaces, not to containers.
+const (
+	// NamespaceCPUQuota is the default and the maximum CPU CFS quota of the containers in the namespace,
+	// in microseconds per the CFS period of 100ms.
+	NamespaceCPUQuota = Prefix + "ns-cpu-quota"
+
+	// NamespaceMemory is the default and the maximum memory limit of the containers in the namespace, in bytes.
+	NamespaceMemory = Prefix + "ns-memory"
+
+	// NamespacePidsLimit is the default and the maximum pids limit of the containers in the namespace.
+	NamespacePidsLimit = Prefix + "ns-pids-limit"
+
+	// NamespaceDefaultLabelPrefix is the prefix of the default labels of the containers in the namespace,
+	// e.g., "nerdctl/ns-default-label.team=foo" sets "team=foo" to the containers.
+	NamespaceDefaultLabelPrefix = Prefix + "ns-default-label."
 )
@@ -0,0 +1,177 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package leaseutil provides the leases for the long operations that write content and snapshots,
+// such as `nerdctl commit` and `nerdctl image squash`.
+package leaseutil
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/leases"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/idgen"
+)
+
+// DefaultDuration is the default expiration of the leases.
+const DefaultDuration = 1 * time.Hour
+
+const (
+	// labelHeartbeat is set on the leases created by WithLease, which are kept alive by a heartbeat lease.
+	labelHeartbeat = "nerdctl/lease.heartbeat"
+	// labelHeartbeatOf is set on the heartbeat leases, with the ID of the lease they keep alive.
+	labelHeartbeatOf = "nerdctl/lease.heartbeat-of"
+	// labelExpire is the label of the expiration set by leases.WithExpiration.
+	labelExpire = "containerd.io/gc.expire"
+)
+
+// DoneFunc releases the lease.
+// Pass leases.SynchronousDelete on failure, so that the content and the snapshots that are only held by the lease
+// are removed by the gc immediately.
+type DoneFunc func(context.Context, ...leases.DeleteOpt) error
+
+// WithLease creates a lease, and returns the context with the lease.
+//
+// The lease itself does not expire, as the content writers and the snapshots hold its ID until the operation completes.
+// Instead, it is kept alive by a heartbeat lease that expires after ttl, and that is replaced every ttl/2 until the
+// returned function is called. The leases whose heartbeat has expired, i.e., the leases of the aborted processes,
+// are deleted by the next call of WithLease, so that their garbage is removed by the gc.
+// When the context already has a lease, it is used as-is.
+func WithLease(ctx context.Context, ls leases.Manager, ttl time.Duration) (context.Context, DoneFunc, error) {
+	if _, ok := leases.FromContext(ctx); ok {
+		return ctx, func(context.Context, ...leases.DeleteOpt) error { return nil }, nil
+	}
+	if ttl <= 0 {
+		ttl = DefaultDuration
+	}
+	if err := removeStaleLeases(ctx, ls); err != nil {
+		log.G(ctx).WithError(err).Warn("failed to remove the leases of the aborted processes")
+	}
+
+	// The heartbeat is created first, so that the lease is never seen without it by removeStaleLeases.
+	id := "nerdctl-" + idgen.GenerateID()
+	hb, err := newHeartbeat(ctx, ls, id, ttl)
+	if err != nil {
+		return ctx, nil, err
+	}
+	l, err := ls.Create(ctx, leases.WithID(id), leases.WithLabel(labelHeartbeat, "true"))
+	if err != nil {
+		if delErr := ls.Delete(ctx, hb); delErr != nil {
+			log.G(ctx).WithError(delErr).Warnf("failed to delete heartbeat lease %s", hb.ID)
+		}
+		return ctx, nil, err
+	}
+
+	var (
+		mu      sync.Mutex
+		current = hb
+		stop    = make(chan struct{})
+		wg      sync.WaitGroup
+	)
+	renewCtx := context.WithoutCancel(ctx)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(ttl / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				mu.Lock()
+				renewed, err := renew(renewCtx, ls, current, l.ID, ttl)
+				if err != nil {
+					log.G(ctx).WithError(err).Warnf("failed to renew lease %s", l.ID)
+				} else {
+					current = renewed
+				}
+				mu.Unlock()
+			}
+		}
+	}()
+
+	var once sync.Once
+	done := func(ctx context.Context, opts ...leases.DeleteOpt) error {
+		once.Do(func() {
+			close(stop)
+		})
+		wg.Wait()
+		mu.Lock()
+		defer mu.Unlock()
+		if err := ls.Delete(ctx, l, opts...); err != nil {
+			return err
+		}
+		if err := ls.Delete(ctx, current); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to delete heartbeat lease %s", current.ID)
+		}
+		return nil
+	}
+	return leases.WithLease(ctx, l.ID), done, nil
+}
+
+// newHeartbeat creates a heartbeat lease of the lease id, which expires after ttl.
+func newHeartbeat(ctx context.Context, ls leases.Manager, id string, ttl time.Duration) (leases.Lease, error) {
+	return ls.Create(ctx, leases.WithRandomID(), leases.WithLabel(labelHeartbeatOf, id), leases.WithExpiration(ttl))
+}
+
+// renew replaces the heartbeat lease hb of the lease id with a new one that expires after ttl.
+// The lease id itself is never touched, so that the operations holding its ID are not affected.
+func renew(ctx context.Context, ls leases.Manager, hb leases.Lease, id string, ttl time.Duration) (leases.Lease, error) {
+	renewed, err := newHeartbeat(ctx, ls, id, ttl)
+	if err != nil {
+		return hb, fmt.Errorf("failed to create heartbeat lease: %w", err)
+	}
+	if err := ls.Delete(ctx, hb); err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to delete heartbeat lease %s", hb.ID)
+	}
+	return renewed, nil
+}
+
+// removeStaleLeases deletes the leases created by WithLease that have no live heartbeat lease.
+func removeStaleLeases(ctx context.Context, ls leases.Manager) error {
+	all, err := ls.List(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	alive := make(map[string]bool)
+	for _, l := range all {
+		id, ok := l.Labels[labelHeartbeatOf]
+		if !ok {
+			continue
+		}
+		// An expired lease is listed until the gc removes it.
+		if expire, err := time.Parse(time.RFC3339, l.Labels[labelExpire]); err == nil && expire.Before(now) {
+			continue
+		}
+		alive[id] = true
+	}
+	for _, l := range all {
+		if l.Labels[labelHeartbeat] != "true" || alive[l.ID] {
+			continue
+		}
+		log.G(ctx).Debugf("removing lease %s of an aborted process", l.ID)
+		if err := ls.Delete(ctx, l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package leaseutil
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/containerd/v2/core/leases"
+	"github.com/containerd/errdefs"
+)
+
+type fakeLease struct {
+	lease     leases.Lease
+	resources []leases.Resource
+}
+
+type fakeManager struct {
+	mu     sync.Mutex
+	leases map[string]*fakeLease
+	// recreated counts the leases created with an existing ID
+	recreated int
+	deleted   []string
+}
+
+func newFakeManager() *fakeManager {
+	return &fakeManager{leases: make(map[string]*fakeLease)}
+}
+
+func (m *fakeManager) Create(_ context.Context, opts ...leases.Opt) (leases.Lease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var l leases.Lease
+	for _, o := range opts {
+		if err := o(&l); err != nil {
+			return leases.Lease{}, err
+		}
+	}
+	if _, ok := m.leases[l.ID]; ok {
+		return leases.Lease{}, errdefs.ErrAlreadyExists
+	}
+	for _, id := range m.deleted {
+		if id == l.ID {
+			m.recreated++
+		}
+	}
+	m.leases[l.ID] = &fakeLease{lease: l}
+	return l, nil
+}
+
+func (m *fakeManager) Delete(_ context.Context, l leases.Lease, _ ...leases.DeleteOpt) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.leases[l.ID]; !ok {
+		return errdefs.ErrNotFound
+	}
+	delete(m.leases, l.ID)
+	m.deleted = append(m.deleted, l.ID)
+	return nil
+}
+
+func (m *fakeManager) List(context.Context, ...string) ([]leases.Lease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var res []leases.Lease
+	for _, l := range m.leases {
+		res = append(res, l.lease)
+	}
+	return res, nil
+}
+
+func (m *fakeManager) AddResource(_ context.Context, l leases.Lease, r leases.Resource) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fl, ok := m.leases[l.ID]
+	if !ok {
+		return errdefs.ErrNotFound
+	}
+	fl.resources = append(fl.resources, r)
+	return nil
+}
+
+func (m *fakeManager) DeleteResource(context.Context, leases.Lease, leases.Resource) error {
+	return errdefs.ErrNotImplemented
+}
+
+func (m *fakeManager) ListResources(_ context.Context, l leases.Lease) ([]leases.Resource, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fl, ok := m.leases[l.ID]
+	if !ok {
+		return nil, errdefs.ErrNotFound
+	}
+	return append([]leases.Resource(nil), fl.resources...), nil
+}
+
+func TestWithLeaseRenew(t *testing.T) {
+	m := newFakeManager()
+	ctx, done, err := WithLease(context.Background(), m, 20*time.Millisecond)
+	assert.NilError(t, err)
+	id, ok := leases.FromContext(ctx)
+	assert.Assert(t, ok)
+
+	r := leases.Resource{ID: "sha256:foo", Type: "content"}
+	assert.NilError(t, m.AddResource(ctx, leases.Lease{ID: id}, r))
+
+	// the heartbeat is replaced at least twice
+	assert.Assert(t, waitFor(func() bool {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return len(m.deleted) >= 2
+	}))
+
+	m.mu.Lock()
+	// the lease of the context is never deleted nor recreated while the operation is running
+	assert.Equal(t, m.recreated, 0)
+	fl, ok := m.leases[id]
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, fl.resources, []leases.Resource{r})
+	_, expires := fl.lease.Labels[labelExpire]
+	assert.Assert(t, !expires)
+	var heartbeats int
+	for _, l := range m.leases {
+		if l.lease.Labels[labelHeartbeatOf] == id {
+			heartbeats++
+		}
+	}
+	assert.Equal(t, heartbeats, 1)
+	m.mu.Unlock()
+
+	assert.NilError(t, done(ctx, leases.SynchronousDelete))
+	time.Sleep(50 * time.Millisecond)
+	// the heartbeat is not renewed after done
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	assert.Equal(t, len(m.leases), 0)
+}
+
+func TestRemoveStaleLeases(t *testing.T) {
+	m := newFakeManager()
+	ctx := context.Background()
+	create := func(opts ...leases.Opt) {
+		_, err := m.Create(ctx, opts...)
+		assert.NilError(t, err)
+	}
+	// alive
+	create(leases.WithID("alive"), leases.WithLabel(labelHeartbeat, "true"))
+	create(leases.WithID("alive-hb"), leases.WithLabel(labelHeartbeatOf, "alive"), leases.WithExpiration(time.Hour))
+	// aborted, the heartbeat has expired but has not been removed by the gc yet
+	create(leases.WithID("expired"), leases.WithLabel(labelHeartbeat, "true"))
+	create(leases.WithID("expired-hb"), leases.WithLabel(labelHeartbeatOf, "expired"),
+		leases.WithLabel(labelExpire, time.Now().Add(-time.Minute).Format(time.RFC3339)))
+	// aborted, the heartbeat has been removed by the gc
+	create(leases.WithID("removed"), leases.WithLabel(labelHeartbeat, "true"))
+	// not created by WithLease
+	create(leases.WithID("other"))
+
+	assert.NilError(t, removeStaleLeases(ctx, m))
+	slices.Sort(m.deleted)
+	assert.DeepEqual(t, m.deleted, []string{"expired", "removed"})
+}
+
+func TestWithLeaseExisting(t *testing.T) {
+	m := newFakeManager()
+	ctx := leases.WithLease(context.Background(), "existing")
+	lctx, done, err := WithLease(ctx, m, time.Hour)
+	assert.NilError(t, err)
+	id, _ := leases.FromContext(lctx)
+	assert.Equal(t, id, "existing")
+	assert.NilError(t, done(lctx))
+	assert.Equal(t, len(m.leases), 0)
+}
+
+func waitFor(cond func() bool) bool {
+	for i := 0; i < 100; i++ {
+		if cond() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
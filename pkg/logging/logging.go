@@ -39,6 +39,8 @@ import (
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/secretstore"
 )
 
 const (
@@ -285,6 +287,30 @@ func loggingProcessAdapter(ctx context.Context, driver Driver, dataStore, addres
 	return driver.PostProcess()
 }
 
+// stageSecrets writes the secrets of `nerdctl run --secret` to the staging dir, if any.
+// The OCI hooks are too late for that, as the runtime bind-mounts the staged secrets before running the hooks,
+// and the staging dir does not survive a reboot of the host.
+func stageSecrets(ctx context.Context, address string, config *logging.Config) error {
+	client, err := containerd.New(strings.TrimPrefix(address, "unix://"), containerd.WithDefaultNamespace(config.Namespace))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	con, err := client.LoadContainer(ctx, config.ID)
+	if err != nil {
+		return err
+	}
+	lab, err := con.Labels(ctx)
+	if err != nil {
+		return err
+	}
+	cs, err := secretstore.DecodeContainerSecrets(lab[labels.Secrets])
+	if err != nil || cs == nil {
+		return err
+	}
+	return cs.Stage(config.Namespace)
+}
+
 func loggerFunc(dataStore string) (logging.LoggerFunc, error) {
 	if dataStore == "" {
 		return nil, errors.New("got empty data store")
@@ -304,6 +330,12 @@ func loggerFunc(dataStore string) (logging.LoggerFunc, error) {
 				return err
 			}
 
+			// The logging process is started before the task is created, including by the containerd restart manager,
+			// which does not go through `nerdctl start`.
+			if err := stageSecrets(ctx, logConfig.Address, config); err != nil {
+				return fmt.Errorf("failed to stage the secrets: %w", err)
+			}
+
 			loggerLock := getLockPath(dataStore, config.Namespace, config.ID)
 			f, err := os.Create(loggerLock)
 			if err != nil {
@@ -39,6 +39,7 @@ const (
 	Bind          = "bind"
 	Volume        = "volume"
 	Tmpfs         = "tmpfs"
+	Image         = "image"
 	Npipe         = "npipe"
 	pathSeparator = string(os.PathSeparator)
 )
@@ -48,6 +49,7 @@ type Processed struct {
 	Mount           specs.Mount
 	Name            string // name
 	AnonymousVolume string // anonymous volume name
+	Driver          string // volume driver, empty for the "local" driver
 	Mode            string
 	Opts            []oci.SpecOpts
 }
@@ -57,6 +59,7 @@ type volumeSpec struct {
 	Name            string
 	Source          string
 	AnonymousVolume string
+	Driver          string
 }
 
 func ProcessFlagV(s string, volStore volumestore.VolumeStore, createDir bool) (*Processed, error) {
@@ -111,6 +114,7 @@ func ProcessFlagV(s string, volStore volumestore.VolumeStore, createDir bool) (*
 			Type:            volSpec.Type,
 			Name:            volSpec.Name,
 			AnonymousVolume: volSpec.AnonymousVolume,
+			Driver:          volSpec.Driver,
 		}
 
 		// Parse volume options
@@ -211,6 +215,9 @@ func handleNamedVolumes(source string, volStore volumestore.VolumeStore) (volume
 	// src is now an absolute path
 	res.Type = Volume
 	res.Source = vol.Mountpoint
+	if vol.Driver != volumestore.LocalDriverName {
+		res.Driver = vol.Driver
+	}
 
 	return res, nil
 }
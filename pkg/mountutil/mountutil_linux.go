@@ -18,6 +18,7 @@ package mountutil
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
@@ -302,6 +303,7 @@ func ProcessFlagMount(s string, volStore volumestore.VolumeStore) (*Processed, e
 		rwOption         string
 		tmpfsSize        int64
 		tmpfsMode        os.FileMode
+		tmpfsOptions     []string
 		err              error
 	)
 
@@ -309,10 +311,11 @@ func ProcessFlagMount(s string, volStore volumestore.VolumeStore) (*Processed, e
 	mountType = Volume
 	tmpfsMode = os.FileMode(01777)
 
-	// three types of mount(and examples):
+	// four types of mount(and examples):
 	// --mount type=bind,source="$(pwd)"/target,target=/app2,readonly,bind-propagation=shared
-	// --mount type=tmpfs,destination=/app,tmpfs-mode=1770,tmpfs-size=1MB
+	// --mount type=tmpfs,destination=/app,tmpfs-mode=1770,tmpfs-size=1MB,tmpfs-uid=1000,exec
 	// --mount type=volume,src=vol-1,dst=/app,readonly
+	// --mount type=image,src=alpine,dst=/opt/alpine
 	// if type not specified, default will be set to volume
 	// --mount src=`pwd`/tmp,target=/app
 
@@ -328,6 +331,9 @@ func ProcessFlagMount(s string, volStore volumestore.VolumeStore) (*Processed, e
 			case "bind-nonrecursive":
 				bindNonRecursive = true
 				continue
+			case "exec", "noexec", "suid", "nosuid", "dev", "nodev":
+				tmpfsOptions = append(tmpfsOptions, key)
+				continue
 			}
 		}
 
@@ -343,9 +349,11 @@ func ProcessFlagMount(s string, volStore volumestore.VolumeStore) (*Processed, e
 				mountType = Tmpfs
 			case "bind":
 				mountType = Bind
+			case "image":
+				mountType = Image
 			case "volume":
 			default:
-				return nil, fmt.Errorf("invalid mount type '%s' must be a volume/bind/tmpfs", value)
+				return nil, fmt.Errorf("invalid mount type '%s' must be a volume/bind/tmpfs/image", value)
 			}
 		case "source", "src":
 			src = value
@@ -379,6 +387,11 @@ func ProcessFlagMount(s string, volStore volumestore.VolumeStore) (*Processed, e
 				return nil, fmt.Errorf("invalid value for %s: %s", key, value)
 			}
 			tmpfsMode = os.FileMode(ui64)
+		case "tmpfs-uid", "tmpfs-gid":
+			if _, err := strconv.ParseUint(value, 10, 32); err != nil {
+				return nil, fmt.Errorf("invalid value for %s: %s", key, value)
+			}
+			tmpfsOptions = append(tmpfsOptions, strings.TrimPrefix(key, "tmpfs-")+"="+value)
 		default:
 			return nil, fmt.Errorf("unexpected key '%s' in '%s'", key, field)
 		}
@@ -395,6 +408,10 @@ func ProcessFlagMount(s string, volStore volumestore.VolumeStore) (*Processed, e
 		options = append(options, rwOption)
 	}
 
+	if len(tmpfsOptions) > 0 && mountType != Tmpfs {
+		return nil, fmt.Errorf("options %v are only supported for tmpfs mounts", tmpfsOptions)
+	}
+
 	switch mountType {
 	case Tmpfs:
 		fields = []string{dst}
@@ -404,6 +421,9 @@ func ProcessFlagMount(s string, volStore volumestore.VolumeStore) (*Processed, e
 		if tmpfsSize > 0 {
 			options = append(options, getTmpfsSize(tmpfsSize))
 		}
+		options = append(options, tmpfsOptions...)
+	case Image:
+		return processImageMount(src, dst, rwOption)
 	case Volume, Bind:
 		fields = []string{src, dst}
 		if bindPropagation != "" {
@@ -433,7 +453,32 @@ func ProcessFlagMount(s string, volStore volumestore.VolumeStore) (*Processed, e
 		// createDir=false for --mount option to disallow creating directories on host if not found
 		return ProcessFlagV(fieldsStr, volStore, false)
 	}
-	return nil, fmt.Errorf("invalid mount type '%s' must be a volume/bind/tmpfs", mountType)
+	return nil, fmt.Errorf("invalid mount type '%s' must be a volume/bind/tmpfs/image", mountType)
+}
+
+// processImageMount processes `--mount type=image`.
+// The image is pulled and mounted as a snapshot when the container is created, so Mount only holds the
+// destination and the read-write mode.
+func processImageMount(src, dst, rwOption string) (*Processed, error) {
+	if src == "" {
+		return nil, errors.New("image mounts require a source image")
+	}
+	if !filepath.IsAbs(dst) {
+		return nil, fmt.Errorf("image mount destination %q must be an absolute path", dst)
+	}
+	mode := "ro"
+	if rwOption == "rw" {
+		mode = "rw"
+	}
+	return &Processed{
+		Type: Image,
+		Mount: specs.Mount{
+			Source:      src,
+			Destination: filepath.Clean(dst),
+			Options:     []string{mode},
+		},
+		Mode: mode,
+	}, nil
 }
 
 // copy from https://github.com/moby/moby/blob/085c6a98d54720e70b28354ccec6da9b1b9e7fcf/volume/mounts/linux_parser.go#L375
@@ -198,8 +198,9 @@ func TestParseVolumeOptions(t *testing.T) {
 
 func TestProcessTmpfs(t *testing.T) {
 	testCases := map[string][]string{
-		"/tmp":               {"noexec", "nosuid", "nodev"},
-		"/tmp:size=64m,exec": {"nosuid", "nodev", "size=64m", "exec"},
+		"/tmp":                           {"noexec", "nosuid", "nodev"},
+		"/tmp:size=64m,exec":             {"nosuid", "nodev", "size=64m", "exec"},
+		"/tmp:uid=1000,gid=100,mode=700": {"noexec", "nosuid", "nodev", "uid=1000", "gid=100", "mode=700"},
 	}
 	for k, expected := range testCases {
 		x, err := ProcessFlagTmpfs(k)
@@ -208,6 +209,51 @@ func TestProcessTmpfs(t *testing.T) {
 	}
 }
 
+func TestProcessFlagMountTmpfs(t *testing.T) {
+	testCases := map[string][]string{
+		"type=tmpfs,dst=/tmp": {"noexec", "nosuid", "nodev", "mode=1777"},
+		"type=tmpfs,dst=/tmp,tmpfs-size=64m,tmpfs-mode=1770,ro": {"noexec", "nosuid", "nodev", "ro", "mode=1770", "size=64m"},
+		"type=tmpfs,dst=/tmp,tmpfs-uid=1000,tmpfs-gid=100":      {"noexec", "nosuid", "nodev", "mode=1777", "uid=1000", "gid=100"},
+		"type=tmpfs,dst=/tmp,exec,suid":                         {"nodev", "mode=1777", "exec", "suid"},
+		"type=tmpfs,destination=/tmp,tmpfs-mode=700,exec,nodev": {"nosuid", "mode=700", "exec", "nodev"},
+	}
+	for k, expected := range testCases {
+		x, err := ProcessFlagMount(k, nil)
+		assert.NilError(t, err, k)
+		assert.Equal(t, x.Type, Tmpfs, k)
+		assert.DeepEqual(t, expected, x.Mount.Options)
+	}
+
+	for _, k := range []string{
+		"type=tmpfs,dst=/tmp,tmpfs-uid=user",
+		"type=tmpfs,dst=/tmp,tmpfs-gid=-1",
+		"type=bind,src=/mnt,dst=/mnt,exec",
+	} {
+		_, err := ProcessFlagMount(k, nil)
+		assert.Assert(t, err != nil, k)
+	}
+}
+
+func TestProcessFlagMountImage(t *testing.T) {
+	x, err := ProcessFlagMount("type=image,source=alpine:3.20,target=/opt/tool/", nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, x, &Processed{
+		Type:  Image,
+		Mount: specs.Mount{Source: "alpine:3.20", Destination: "/opt/tool", Options: []string{"ro"}},
+		Mode:  "ro",
+	})
+
+	x, err = ProcessFlagMount("type=image,src=alpine,dst=/opt/tool,rw=true", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, x.Mode, "rw")
+
+	_, err = ProcessFlagMount("type=image,dst=/opt/tool", nil)
+	assert.ErrorContains(t, err, "source image")
+
+	_, err = ProcessFlagMount("type=image,src=alpine,dst=opt/tool", nil)
+	assert.ErrorContains(t, err, "absolute path")
+}
+
 func TestProcessFlagV(t *testing.T) {
 	tests := []struct {
 		rawSpec string
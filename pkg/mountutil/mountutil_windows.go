@@ -24,9 +24,11 @@
 package mountutil
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/containerd/containerd/v2/pkg/oci"
@@ -81,8 +83,79 @@ func ProcessFlagTmpfs(s string) (*Processed, error) {
 	return nil, errdefs.ErrNotImplemented
 }
 
+// ProcessFlagMount processes the --mount flag.
+// The supported types are "bind", "volume", and "npipe", e.g.,
+// --mount type=bind,source=C:\data,target=C:\data,readonly
+// --mount type=volume,source=vol-1,target=C:\data
+// --mount type=npipe,source=\\.\pipe\docker_engine,target=\\.\pipe\docker_engine
 func ProcessFlagMount(s string, volStore volumestore.VolumeStore) (*Processed, error) {
-	return nil, errdefs.ErrNotImplemented
+	var (
+		mountType = Volume
+		src, dst  string
+		readonly  bool
+		err       error
+	)
+	for _, field := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(field, "=")
+		key = strings.ToLower(key)
+		if !ok {
+			switch key {
+			case "readonly", "ro":
+				readonly = true
+				continue
+			case "rw":
+				continue
+			}
+			return nil, fmt.Errorf("invalid field '%s' must be a key=value pair", field)
+		}
+		switch key {
+		case "type":
+			switch value {
+			case Bind, Volume, Npipe:
+				mountType = value
+			default:
+				return nil, fmt.Errorf("invalid mount type '%s' must be a volume/bind/npipe", value)
+			}
+		case "source", "src":
+			src = value
+		case "target", "dst", "destination":
+			dst = value
+		case "readonly", "ro":
+			readonly, err = strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %s: %s", key, value)
+			}
+		default:
+			return nil, fmt.Errorf("unexpected key '%s' in '%s'", key, field)
+		}
+	}
+	if dst == "" {
+		return nil, errors.New("mount destination must be specified")
+	}
+	if src == "" {
+		if mountType != Volume {
+			return nil, fmt.Errorf("mount source must be specified for type %q", mountType)
+		}
+		// anonymous volume
+		res, err := ProcessFlagV(dst, volStore, false)
+		if err != nil {
+			return nil, err
+		}
+		if readonly {
+			res.Mode = "ro"
+			res.Mount.Options = append(res.Mount.Options, "ro")
+		}
+		return res, nil
+	}
+	if t := parseSourceType(src); t != mountType {
+		return nil, fmt.Errorf("mount source %q is not valid for type %q", src, mountType)
+	}
+	spec := src + ":" + dst
+	if readonly {
+		spec += ":ro"
+	}
+	// createDir=false for --mount option to disallow creating directories on host if not found
+	return ProcessFlagV(spec, volStore, false)
 }
 
 func handleVolumeToMount(source string, dst string, volStore volumestore.VolumeStore, createDir bool) (volumeSpec, error) {
@@ -343,3 +343,82 @@ func TestProcessFlagVAnonymousVolumes(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessFlagMount(t *testing.T) {
+	tests := []struct {
+		rawSpec string
+		wants   *Processed
+		err     string
+	}{
+		{
+			rawSpec: `type=volume,source=TestVolume,target=C:\TestVolume\Path,readonly`,
+			wants: &Processed{
+				Type: "volume",
+				Name: "TestVolume",
+				Mount: specs.Mount{
+					Destination: `C:\TestVolume\Path`,
+					Options:     []string{"ro", "rbind"},
+				}},
+		},
+		{
+			rawSpec: `type=npipe,src=\\.\pipe\containerd-containerd,dst=\\.\pipe\containerd-containerd`,
+			wants: &Processed{
+				Type: "npipe",
+				Mount: specs.Mount{
+					Source:      `\\.\pipe\containerd-containerd`,
+					Destination: `\\.\pipe\containerd-containerd`,
+					Options:     []string{"rbind"},
+				}},
+		},
+		{
+			rawSpec: `target=C:\TestVolume\Path,ro`,
+			wants: &Processed{
+				Type: "volume",
+				Mount: specs.Mount{
+					Destination: `C:\TestVolume\Path`,
+					Options:     []string{"rbind", "ro"},
+				}},
+		},
+		{
+			rawSpec: `type=bind,source=\\.\pipe\containerd-containerd,target=C:\TestVolume\Path`,
+			err:     `mount source "\\\\.\\pipe\\containerd-containerd" is not valid for type "bind"`,
+		},
+		{
+			rawSpec: `type=npipe,target=\\.\pipe\containerd-containerd`,
+			err:     `mount source must be specified for type "npipe"`,
+		},
+		{
+			rawSpec: `type=volume,source=TestVolume`,
+			err:     "mount destination must be specified",
+		},
+		{
+			rawSpec: `type=tmpfs,target=C:\TestVolume\Path`,
+			err:     "invalid mount type 'tmpfs' must be a volume/bind/npipe",
+		},
+		{
+			rawSpec: `type=volume,source=TestVolume,target=C:\TestVolume\Path,bind-propagation=shared`,
+			err:     "unexpected key 'bind-propagation' in 'bind-propagation=shared'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rawSpec, func(t *testing.T) {
+			processedVolSpec, err := ProcessFlagMount(tt.rawSpec, mockVolumeStore)
+			if tt.err != "" {
+				assert.Error(t, err, tt.err)
+				return
+			}
+			assert.NilError(t, err)
+
+			assert.Equal(t, processedVolSpec.Type, tt.wants.Type)
+			assert.Equal(t, processedVolSpec.Mount.Destination, tt.wants.Mount.Destination)
+			assert.DeepEqual(t, processedVolSpec.Mount.Options, tt.wants.Mount.Options)
+			if tt.wants.Name != "" {
+				assert.Equal(t, processedVolSpec.Name, tt.wants.Name)
+			}
+			if tt.wants.Mount.Source != "" {
+				assert.Equal(t, processedVolSpec.Mount.Source, tt.wants.Mount.Source)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volumestore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// LocalDriverName is the name of the built-in volume driver, storing the volumes under the data store.
+const LocalDriverName = "local"
+
+// DefaultMountID is the mount ID passed to the volume plugins.
+// The volumes of the plugins are mounted once on creation (or first use), and unmounted on removal.
+const DefaultMountID = "nerdctl"
+
+// Driver is a volume driver other than the local one.
+type Driver interface {
+	// Name returns the name of the driver
+	Name() string
+	// Create creates a volume with the driver-specific options
+	Create(name string, opts map[string]string) error
+	// Remove removes a volume
+	Remove(name string) error
+	// Mount mounts a volume and returns the host path of the volume
+	Mount(name, id string) (string, error)
+	// Unmount unmounts a volume
+	Unmount(name, id string) error
+	// Path returns the host path of a mounted volume, or an empty string if the volume is not mounted
+	Path(name string) (string, error)
+}
+
+// DriverLookup returns the Driver by the name.
+type DriverLookup func(name string) (Driver, error)
+
+var (
+	// PluginSocketDirs are the directories of the sockets of the volume plugins ("<NAME>.sock").
+	PluginSocketDirs = []string{"/run/docker/plugins"}
+	// PluginSpecDirs are the directories of the spec files of the volume plugins ("<NAME>.spec" or "<NAME>.json").
+	PluginSpecDirs = []string{"/etc/docker/plugins", "/usr/lib/docker/plugins"}
+)
+
+// pluginTimeout is the timeout of a request to a volume plugin.
+// Mounting a remote file system may take a while, so this is longer than an usual API call.
+const pluginTimeout = 2 * time.Minute
+
+// pluginMediaType is the media type of the Docker plugin API.
+const pluginMediaType = "application/vnd.docker.plugins.v1.2+json"
+
+// FindPlugin finds the volume plugin by the name, from PluginSocketDirs and PluginSpecDirs.
+// The plugins implement the Docker volume plugin API: https://docs.docker.com/engine/extend/plugins_volume/
+func FindPlugin(name string) (Driver, error) {
+	if name == "" || name == LocalDriverName || strings.ContainsAny(name, "/\\") {
+		return nil, fmt.Errorf("invalid volume plugin name %q", name)
+	}
+	addr, err := findPluginAddr(name)
+	if err != nil {
+		return nil, err
+	}
+	p, err := newPlugin(name, addr)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.activate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func findPluginAddr(name string) (string, error) {
+	for _, dir := range PluginSocketDirs {
+		sock := filepath.Join(dir, name+".sock")
+		if _, err := os.Stat(sock); err == nil {
+			return "unix://" + sock, nil
+		}
+	}
+	for _, dir := range PluginSpecDirs {
+		if b, err := os.ReadFile(filepath.Join(dir, name+".spec")); err == nil {
+			return strings.TrimSpace(string(b)), nil
+		}
+		if b, err := os.ReadFile(filepath.Join(dir, name+".json")); err == nil {
+			var spec struct {
+				Addr string
+			}
+			if err := json.Unmarshal(b, &spec); err != nil {
+				return "", fmt.Errorf("failed to parse the spec of volume plugin %q: %w", name, err)
+			}
+			return spec.Addr, nil
+		}
+	}
+	return "", fmt.Errorf("volume plugin %q is not found in %v", name, slices.Concat(PluginSocketDirs, PluginSpecDirs))
+}
+
+type plugin struct {
+	name    string
+	baseURL string
+	client  *http.Client
+}
+
+func newPlugin(name, addr string) (*plugin, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address of volume plugin %q: %w", name, err)
+	}
+	p := &plugin{name: name}
+	switch u.Scheme {
+	case "unix":
+		p.baseURL = "http://plugin"
+		p.client = &http.Client{
+			Timeout: pluginTimeout,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", u.Path)
+				},
+			},
+		}
+	case "tcp", "http":
+		p.baseURL = "http://" + u.Host
+		p.client = &http.Client{Timeout: pluginTimeout}
+	default:
+		return nil, fmt.Errorf("unsupported address of volume plugin %q: %q", name, addr)
+	}
+	return p, nil
+}
+
+// call calls the method of the plugin. A non-empty "Err" field of the response is returned as an error.
+func (p *plugin) call(method string, req, resp interface{}) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	hr, err := p.client.Post(p.baseURL+"/"+method, pluginMediaType, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("volume plugin %q: %s: %w", p.name, method, err)
+	}
+	defer hr.Body.Close()
+	var errResp struct {
+		Err string
+	}
+	body := new(bytes.Buffer)
+	if _, err := body.ReadFrom(hr.Body); err != nil {
+		return fmt.Errorf("volume plugin %q: %s: %w", p.name, method, err)
+	}
+	if err := json.Unmarshal(body.Bytes(), &errResp); err == nil && errResp.Err != "" {
+		return fmt.Errorf("volume plugin %q: %s: %s", p.name, method, errResp.Err)
+	}
+	if hr.StatusCode != http.StatusOK {
+		return fmt.Errorf("volume plugin %q: %s: unexpected status %q", p.name, method, hr.Status)
+	}
+	if resp == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body.Bytes(), resp); err != nil {
+		return fmt.Errorf("volume plugin %q: %s: %w", p.name, method, err)
+	}
+	return nil
+}
+
+func (p *plugin) activate() error {
+	var resp struct {
+		Implements []string
+	}
+	if err := p.call("Plugin.Activate", struct{}{}, &resp); err != nil {
+		return err
+	}
+	if !slices.Contains(resp.Implements, "VolumeDriver") {
+		return fmt.Errorf("plugin %q does not implement VolumeDriver (implements %v)", p.name, resp.Implements)
+	}
+	return nil
+}
+
+func (p *plugin) Name() string {
+	return p.name
+}
+
+func (p *plugin) Create(name string, opts map[string]string) error {
+	return p.call("VolumeDriver.Create", struct {
+		Name string
+		Opts map[string]string `json:",omitempty"`
+	}{name, opts}, nil)
+}
+
+func (p *plugin) Remove(name string) error {
+	return p.call("VolumeDriver.Remove", struct{ Name string }{name}, nil)
+}
+
+type mountpointResponse struct {
+	Mountpoint string
+}
+
+func (p *plugin) Mount(name, id string) (string, error) {
+	var resp mountpointResponse
+	if err := p.call("VolumeDriver.Mount", struct{ Name, ID string }{name, id}, &resp); err != nil {
+		return "", err
+	}
+	if resp.Mountpoint == "" {
+		return "", fmt.Errorf("volume plugin %q: VolumeDriver.Mount: no mountpoint returned for %q", p.name, name)
+	}
+	return resp.Mountpoint, nil
+}
+
+func (p *plugin) Unmount(name, id string) error {
+	return p.call("VolumeDriver.Unmount", struct{ Name, ID string }{name, id}, nil)
+}
+
+func (p *plugin) Path(name string) (string, error) {
+	var resp mountpointResponse
+	if err := p.call("VolumeDriver.Path", struct{ Name string }{name}, &resp); err != nil {
+		return "", err
+	}
+	return resp.Mountpoint, nil
+}
@@ -0,0 +1,139 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volumestore
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// fakePlugin is an in-memory volume plugin, serving the Docker volume plugin API on a unix socket.
+type fakePlugin struct {
+	mu      sync.Mutex
+	volumes map[string]map[string]string
+	mounted map[string]bool
+}
+
+func (fp *fakePlugin) serve(t *testing.T, sock string) {
+	mux := http.NewServeMux()
+	handle := func(method string, fn func(req map[string]interface{}) interface{}) {
+		mux.HandleFunc("/"+method, func(w http.ResponseWriter, r *http.Request) {
+			var req map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&req)
+			fp.mu.Lock()
+			resp := fn(req)
+			fp.mu.Unlock()
+			json.NewEncoder(w).Encode(resp)
+		})
+	}
+	handle("Plugin.Activate", func(map[string]interface{}) interface{} {
+		return map[string][]string{"Implements": {"VolumeDriver"}}
+	})
+	handle("VolumeDriver.Create", func(req map[string]interface{}) interface{} {
+		opts := map[string]string{}
+		if o, ok := req["Opts"].(map[string]interface{}); ok {
+			for k, v := range o {
+				opts[k] = v.(string)
+			}
+		}
+		fp.volumes[req["Name"].(string)] = opts
+		return map[string]string{}
+	})
+	handle("VolumeDriver.Remove", func(req map[string]interface{}) interface{} {
+		name := req["Name"].(string)
+		if fp.mounted[name] {
+			return map[string]string{"Err": "volume is in use"}
+		}
+		delete(fp.volumes, name)
+		return map[string]string{}
+	})
+	handle("VolumeDriver.Mount", func(req map[string]interface{}) interface{} {
+		fp.mounted[req["Name"].(string)] = true
+		return map[string]string{"Mountpoint": "/mnt/fake/" + req["Name"].(string)}
+	})
+	handle("VolumeDriver.Unmount", func(req map[string]interface{}) interface{} {
+		delete(fp.mounted, req["Name"].(string))
+		return map[string]string{}
+	})
+	handle("VolumeDriver.Path", func(req map[string]interface{}) interface{} {
+		if !fp.mounted[req["Name"].(string)] {
+			return map[string]string{}
+		}
+		return map[string]string{"Mountpoint": "/mnt/fake/" + req["Name"].(string)}
+	})
+	l, err := net.Listen("unix", sock)
+	assert.NilError(t, err)
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(l)
+	t.Cleanup(func() { srv.Close() })
+}
+
+func TestVolumePlugin(t *testing.T) {
+	pluginDir := t.TempDir()
+	oldSocketDirs, oldSpecDirs := PluginSocketDirs, PluginSpecDirs
+	PluginSocketDirs, PluginSpecDirs = []string{pluginDir}, nil
+	t.Cleanup(func() { PluginSocketDirs, PluginSpecDirs = oldSocketDirs, oldSpecDirs })
+
+	fp := &fakePlugin{volumes: map[string]map[string]string{}, mounted: map[string]bool{}}
+	fp.serve(t, filepath.Join(pluginDir, "fake.sock"))
+
+	_, err := FindPlugin("nonexistent")
+	assert.ErrorContains(t, err, "not found")
+
+	vs, err := New(t.TempDir(), "default")
+	assert.NilError(t, err)
+
+	vol, err := vs.CreateWithDriver("vol1", []string{"foo=bar"}, "fake", map[string]string{"share": "nfs.example.com/export"})
+	assert.NilError(t, err)
+	assert.Equal(t, vol.Driver, "fake")
+	assert.Equal(t, vol.Mountpoint, "/mnt/fake/vol1")
+	assert.DeepEqual(t, fp.volumes["vol1"], map[string]string{"share": "nfs.example.com/export"})
+
+	vol, err = vs.Get("vol1", false)
+	assert.NilError(t, err)
+	assert.Equal(t, vol.Driver, "fake")
+	assert.Equal(t, vol.Mountpoint, "/mnt/fake/vol1")
+	assert.DeepEqual(t, vol.Options, map[string]string{"share": "nfs.example.com/export"})
+	assert.DeepEqual(t, *vol.Labels, map[string]string{"foo": "bar"})
+
+	// Using the existing volume without specifying the driver
+	vol, err = vs.Create("vol1", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, vol.Mountpoint, "/mnt/fake/vol1")
+	_, err = vs.CreateWithDriver("vol1", nil, LocalDriverName, nil)
+	assert.ErrorContains(t, err, "already exists with the driver")
+
+	_, err = vs.CreateWithDriver("vol2", nil, LocalDriverName, map[string]string{"type": "nfs"})
+	assert.ErrorContains(t, err, `does not support option "type"`)
+	_, err = vs.CreateWithDriver("vol2", nil, LocalDriverName, map[string]string{"size": "foo"})
+	assert.ErrorContains(t, err, "invalid size")
+
+	removed, warns, err := vs.Remove(func() ([]string, []error, error) {
+		return []string{"vol1"}, nil, nil
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(warns), 0)
+	assert.DeepEqual(t, removed, []string{"vol1"})
+	assert.Equal(t, len(fp.volumes), 0)
+	assert.Equal(t, len(fp.mounted), 0)
+}
@@ -0,0 +1,163 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volumestore
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"slices"
+	"strings"
+)
+
+const (
+	// NFSDriverName is the name of the built-in volume driver for NFS.
+	NFSDriverName = "nfs"
+	// CIFSDriverName is the name of the built-in volume driver for CIFS (SMB).
+	CIFSDriverName = "cifs"
+
+	// credentialsFileName is the CIFS credentials file in the volume directory, in the format of mount.cifs(8).
+	// The credentials are not recorded in volume.json, so that they are not shown in `nerdctl volume inspect`.
+	credentialsFileName = "credentials"
+	// mountsFileName lists the IDs of the containers using the network volume.
+	mountsFileName = "mounts.json"
+)
+
+// networkVolumeOptions are the options of the built-in network volume drivers.
+// "o" is passed to mount(8) as is.
+var networkVolumeOptions = map[string][]string{
+	NFSDriverName:  {"addr", "path", "o"},
+	CIFSDriverName: {"addr", "path", "username", "password", "domain", "o"},
+}
+
+// isNetworkDriver returns true for the built-in network volume drivers (NFS and CIFS).
+// The network volumes are mounted on the data directory of the volume when a container using them starts.
+func isNetworkDriver(driver string) bool {
+	_, ok := networkVolumeOptions[driver]
+	return ok
+}
+
+// expandNetworkVolumeOptions expands the options specified as a single comma-separated value,
+// e.g., {"addr": "192.168.1.2,path=/export"} to {"addr": "192.168.1.2", "path": "/export"}.
+// The value of "o" is not expanded, as it is a comma-separated list of the mount options.
+func expandNetworkVolumeOptions(driver string, opts map[string]string) map[string]string {
+	known := networkVolumeOptions[driver]
+	res := make(map[string]string, len(opts))
+	for k, v := range opts {
+		if k == "o" {
+			res[k] = v
+			continue
+		}
+		fields := strings.Split(v, ",")
+		res[k] = fields[0]
+		for i := 1; i < len(fields); i++ {
+			kk, vv, ok := strings.Cut(fields[i], "=")
+			if !ok || !slices.Contains(known, kk) {
+				// Not an option, but a part of the value
+				res[k] += "," + fields[i]
+				continue
+			}
+			if kk == "o" {
+				res[kk] = strings.Join(append([]string{vv}, fields[i+1:]...), ",")
+				break
+			}
+			k = kk
+			res[k] = vv
+		}
+	}
+	return res
+}
+
+// parseNetworkVolumeOptions validates the options of the network volume.
+// It returns the options to be recorded, and the content of the CIFS credentials file, if any.
+func parseNetworkVolumeOptions(driver string, opts map[string]string) (map[string]string, []byte, error) {
+	opts = expandNetworkVolumeOptions(driver, opts)
+	for k := range opts {
+		if !slices.Contains(networkVolumeOptions[driver], k) {
+			return nil, nil, fmt.Errorf("unknown option %q for volume driver %q (supported options: %v)", k, driver, networkVolumeOptions[driver])
+		}
+	}
+	if opts["addr"] == "" {
+		return nil, nil, fmt.Errorf("volume driver %q needs the \"addr\" option (the address of the server)", driver)
+	}
+	if strings.ContainsAny(opts["addr"], "/:") && net.ParseIP(opts["addr"]) == nil {
+		return nil, nil, fmt.Errorf("invalid addr %q, must be a host name or an IP address", opts["addr"])
+	}
+	switch driver {
+	case NFSDriverName:
+		if opts["path"] == "" {
+			opts["path"] = "/"
+		}
+		if !path.IsAbs(opts["path"]) {
+			return nil, nil, fmt.Errorf("invalid path %q, must be an absolute path of the export", opts["path"])
+		}
+		return opts, nil, nil
+	case CIFSDriverName:
+		share := strings.Trim(opts["path"], "/")
+		if share == "" {
+			return nil, nil, fmt.Errorf("volume driver %q needs the \"path\" option (the name of the share)", driver)
+		}
+		opts["path"] = "/" + share
+		if strings.Contains(opts["o"], "password=") || strings.Contains(opts["o"], "credentials=") {
+			return nil, nil, fmt.Errorf("specify the credentials with the \"username\" and \"password\" options, not with \"o\"")
+		}
+		var creds []byte
+		if opts["username"] != "" || opts["password"] != "" {
+			for _, k := range []string{"username", "password", "domain"} {
+				if strings.ContainsAny(opts[k], "\n\r") {
+					return nil, nil, fmt.Errorf("invalid %s, must not contain a newline", k)
+				}
+				if opts[k] != "" {
+					creds = append(creds, []byte(k+"="+opts[k]+"\n")...)
+				}
+			}
+		}
+		delete(opts, "password")
+		return opts, creds, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown network volume driver %q", driver)
+	}
+}
+
+// networkVolumeMountArgs returns the arguments of mount(8) for mounting the network volume on the target.
+func networkVolumeMountArgs(driver string, opts map[string]string, credentialsFile, target string) []string {
+	var source string
+	var mountOpts []string
+	addr := opts["addr"]
+	switch driver {
+	case NFSDriverName:
+		if ip := net.ParseIP(addr); ip != nil && ip.To4() == nil {
+			addr = "[" + addr + "]"
+		}
+		source = addr + ":" + opts["path"]
+	case CIFSDriverName:
+		source = "//" + addr + opts["path"]
+		if credentialsFile != "" {
+			mountOpts = append(mountOpts, "credentials="+credentialsFile)
+		} else {
+			mountOpts = append(mountOpts, "guest")
+		}
+	}
+	if opts["o"] != "" {
+		mountOpts = append(mountOpts, opts["o"])
+	}
+	args := []string{"-t", driver}
+	if len(mountOpts) > 0 {
+		args = append(args, "-o", strings.Join(mountOpts, ","))
+	}
+	return append(args, source, target)
+}
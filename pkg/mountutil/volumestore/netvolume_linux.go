@@ -0,0 +1,166 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volumestore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/moby/sys/mountinfo"
+	"golang.org/x/sys/unix"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/store"
+)
+
+// withNetworkVolume calls fn with the driver and the options of the volume, with the lock of the volume directory,
+// if the volume (identified by its data directory) uses a built-in network volume driver.
+func withNetworkVolume(dataDir string, fn func(st store.Store, driver string, opts map[string]string) error) error {
+	volDir := filepath.Dir(dataDir)
+	if filepath.Base(dataDir) != dataDirName {
+		return nil
+	}
+	content, err := os.ReadFile(filepath.Join(volDir, volumeJSONFileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	driver, opts := volumeDriver(content)
+	if !isNetworkDriver(driver) {
+		return nil
+	}
+	st, err := store.New(volDir, 0, 0o600)
+	if err != nil {
+		return err
+	}
+	return st.WithLock(func() error {
+		return fn(st, driver, opts)
+	})
+}
+
+func networkVolumeUsers(st store.Store) ([]string, error) {
+	var users []string
+	b, err := st.Get(mountsFileName)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func setNetworkVolumeUsers(st store.Store, users []string) error {
+	b, err := json.Marshal(users)
+	if err != nil {
+		return err
+	}
+	return st.Set(b, mountsFileName)
+}
+
+// MountNetworkVolume mounts the NFS or CIFS volume on its data directory (the source of the container mount),
+// unless it is already mounted, and records the container as a user of the mount.
+// It is a no-op for the volumes of the other drivers.
+func MountNetworkVolume(dataDir, containerID string) error {
+	return withNetworkVolume(dataDir, func(st store.Store, driver string, opts map[string]string) error {
+		users, err := networkVolumeUsers(st)
+		if err != nil {
+			return err
+		}
+		mounted, err := mountinfo.Mounted(dataDir)
+		if err != nil {
+			return err
+		}
+		if !mounted {
+			var credentialsFile string
+			if ok, _ := st.Exists(credentialsFileName); ok {
+				credentialsFile = filepath.Join(filepath.Dir(dataDir), credentialsFileName)
+			}
+			cmd := exec.Command("mount", networkVolumeMountArgs(driver, opts, credentialsFile, dataDir)...)
+			log.L.Debugf("mounting volume: %v", cmd.Args)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				hint := "nfs-common (Debian, Ubuntu) or nfs-utils (Fedora)"
+				if driver == CIFSDriverName {
+					hint = "cifs-utils"
+				}
+				return fmt.Errorf("failed to mount the %s volume on %q (hint: install %s): %w (output=%q)",
+					driver, dataDir, hint, err, strings.TrimSpace(string(out)))
+			}
+			// Drop the stale users left by the containers that did not stop gracefully
+			users = nil
+		}
+		if !slices.Contains(users, containerID) {
+			users = append(users, containerID)
+		}
+		return setNetworkVolumeUsers(st, users)
+	})
+}
+
+// UnmountNetworkVolume removes the container from the users of the NFS or CIFS volume,
+// and unmounts the volume when no container uses it anymore.
+// It is a no-op for the volumes of the other drivers.
+func UnmountNetworkVolume(dataDir, containerID string) error {
+	return withNetworkVolume(dataDir, func(st store.Store, _ string, _ map[string]string) error {
+		users, err := networkVolumeUsers(st)
+		if err != nil {
+			return err
+		}
+		users = slices.DeleteFunc(users, func(id string) bool { return id == containerID })
+		if err := setNetworkVolumeUsers(st, users); err != nil {
+			return err
+		}
+		if len(users) > 0 {
+			return nil
+		}
+		mounted, err := mountinfo.Mounted(dataDir)
+		if err != nil || !mounted {
+			return err
+		}
+		if err := unix.Unmount(dataDir, 0); err != nil {
+			return fmt.Errorf("failed to unmount the volume on %q: %w", dataDir, err)
+		}
+		return nil
+	})
+}
+
+// checkNetworkVolumeUnused returns an error if the network volume is mounted.
+// Removing the data directory of a mounted network volume would remove the files on the server.
+func checkNetworkVolumeUnused(dataDir string) error {
+	mounted, err := mountinfo.Mounted(dataDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if mounted {
+		return fmt.Errorf("volume is mounted on %q, stop the containers using it first", dataDir)
+	}
+	return nil
+}
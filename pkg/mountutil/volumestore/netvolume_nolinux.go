@@ -0,0 +1,38 @@
+//go:build !linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volumestore
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// MountNetworkVolume is not supported on non-Linux platforms, where the network volumes cannot be created.
+func MountNetworkVolume(dataDir, containerID string) error {
+	return nil
+}
+
+// UnmountNetworkVolume is not supported on non-Linux platforms, where the network volumes cannot be created.
+func UnmountNetworkVolume(dataDir, containerID string) error {
+	return nil
+}
+
+func checkNetworkVolumeUnused(dataDir string) error {
+	return fmt.Errorf("network volumes are not supported on %s", runtime.GOOS)
+}
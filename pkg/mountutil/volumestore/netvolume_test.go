@@ -0,0 +1,106 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volumestore
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseNetworkVolumeOptions(t *testing.T) {
+	opts, creds, err := parseNetworkVolumeOptions(NFSDriverName, map[string]string{"addr": "192.168.1.2,path=/export,o=vers=4,soft"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, opts, map[string]string{"addr": "192.168.1.2", "path": "/export", "o": "vers=4,soft"})
+	assert.Assert(t, creds == nil)
+
+	opts, _, err = parseNetworkVolumeOptions(NFSDriverName, map[string]string{"addr": "nfs.example.com"})
+	assert.NilError(t, err)
+	assert.Equal(t, opts["path"], "/")
+
+	_, _, err = parseNetworkVolumeOptions(NFSDriverName, map[string]string{"path": "/export"})
+	assert.ErrorContains(t, err, "needs the \"addr\" option")
+	_, _, err = parseNetworkVolumeOptions(NFSDriverName, map[string]string{"addr": "nfs.example.com:/export"})
+	assert.ErrorContains(t, err, "invalid addr")
+	_, _, err = parseNetworkVolumeOptions(NFSDriverName, map[string]string{"addr": "nfs.example.com", "username": "foo"})
+	assert.ErrorContains(t, err, "unknown option")
+
+	opts, creds, err = parseNetworkVolumeOptions(CIFSDriverName, map[string]string{
+		"addr": "smb.example.com", "path": "share", "username": "alice", "password": "s3cr3t,with,commas", "domain": "EXAMPLE",
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, opts, map[string]string{"addr": "smb.example.com", "path": "/share", "username": "alice", "domain": "EXAMPLE"})
+	assert.Equal(t, string(creds), "username=alice\npassword=s3cr3t,with,commas\ndomain=EXAMPLE\n")
+
+	_, _, err = parseNetworkVolumeOptions(CIFSDriverName, map[string]string{"addr": "smb.example.com"})
+	assert.ErrorContains(t, err, "needs the \"path\" option")
+	_, _, err = parseNetworkVolumeOptions(CIFSDriverName, map[string]string{"addr": "smb.example.com", "path": "share", "o": "password=foo"})
+	assert.ErrorContains(t, err, "not with \"o\"")
+}
+
+func TestNetworkVolumeMountArgs(t *testing.T) {
+	assert.DeepEqual(t,
+		networkVolumeMountArgs(NFSDriverName, map[string]string{"addr": "192.168.1.2", "path": "/export", "o": "vers=4"}, "", "/data"),
+		[]string{"-t", "nfs", "-o", "vers=4", "192.168.1.2:/export", "/data"})
+	assert.DeepEqual(t,
+		networkVolumeMountArgs(NFSDriverName, map[string]string{"addr": "fd00::2", "path": "/"}, "", "/data"),
+		[]string{"-t", "nfs", "[fd00::2]:/", "/data"})
+	assert.DeepEqual(t,
+		networkVolumeMountArgs(CIFSDriverName, map[string]string{"addr": "smb.example.com", "path": "/share", "o": "vers=3.0"}, "/vol/credentials", "/data"),
+		[]string{"-t", "cifs", "-o", "credentials=/vol/credentials,vers=3.0", "//smb.example.com/share", "/data"})
+	assert.DeepEqual(t,
+		networkVolumeMountArgs(CIFSDriverName, map[string]string{"addr": "smb.example.com", "path": "/share"}, "", "/data"),
+		[]string{"-t", "cifs", "-o", "guest", "//smb.example.com/share", "/data"})
+}
+
+func TestCreateNetworkVolume(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("network volumes are supported only on Linux")
+	}
+	dataStore := t.TempDir()
+	vs, err := New(dataStore, "default")
+	assert.NilError(t, err)
+
+	vol, err := vs.CreateWithDriver("smb", nil, CIFSDriverName, map[string]string{
+		"addr": "smb.example.com,path=share,username=alice,password=s3cr3t",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, vol.Driver, CIFSDriverName)
+	assert.Equal(t, vol.Mountpoint, filepath.Join(dataStore, "volumes", "default", "smb", "_data"))
+
+	vol, err = vs.Get("smb", true)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, vol.Options, map[string]string{"addr": "smb.example.com", "path": "/share", "username": "alice"})
+
+	volumeJSON, err := os.ReadFile(filepath.Join(dataStore, "volumes", "default", "smb", volumeJSONFileName))
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(string(volumeJSON), "s3cr3t"))
+	creds, err := os.ReadFile(filepath.Join(dataStore, "volumes", "default", "smb", credentialsFileName))
+	assert.NilError(t, err)
+	assert.Equal(t, string(creds), "username=alice\npassword=s3cr3t\n")
+
+	// Not mounted, so the volume can be removed
+	removed, _, err := vs.Remove(func() ([]string, []error, error) {
+		return []string{"smb"}, nil, nil
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, removed, []string{"smb"})
+}
@@ -0,0 +1,423 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volumestore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/containerd/continuity/fs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/identifiers"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+	"github.com/containerd/nerdctl/v2/pkg/store"
+)
+
+const (
+	snapshotsDirName     = "snapshots"
+	snapshotJSONFileName = "snapshot.json"
+
+	// SnapshotMethodCopy copies the files of the volume, with reflinks on the file systems supporting them
+	SnapshotMethodCopy = "copy"
+	// SnapshotMethodBtrfs is used for the volumes whose data directory is a btrfs subvolume
+	SnapshotMethodBtrfs = "btrfs"
+	// SnapshotMethodZFS is used for the volumes whose data directory is the mountpoint of a ZFS dataset
+	SnapshotMethodZFS = "zfs"
+)
+
+// snapshotBackend takes and restores the snapshots of the data directory of a volume.
+// snapDataDir is the directory reserved for the content of the snapshot, which does not exist yet on Create.
+type snapshotBackend interface {
+	Method() string
+	Create(dataDir, snapDataDir, snapshot string) error
+	// Restore replaces the content of dataDir with the snapshot
+	Restore(dataDir, snapDataDir, snapshot string) error
+	Remove(dataDir, snapDataDir, snapshot string) error
+}
+
+// snapshotBackendFor returns the backend of a snapshot taken with the method.
+func snapshotBackendFor(method string) (snapshotBackend, error) {
+	switch method {
+	case SnapshotMethodCopy:
+		return copySnapshotBackend{}, nil
+	case SnapshotMethodBtrfs:
+		return btrfsSnapshotBackend{}, nil
+	case SnapshotMethodZFS:
+		return zfsSnapshotBackend{}, nil
+	default:
+		return nil, fmt.Errorf("unknown snapshot method %q", method)
+	}
+}
+
+type copySnapshotBackend struct{}
+
+func (copySnapshotBackend) Method() string {
+	return SnapshotMethodCopy
+}
+
+func (copySnapshotBackend) Create(dataDir, snapDataDir, _ string) error {
+	if err := os.Mkdir(snapDataDir, 0o755); err != nil {
+		return err
+	}
+	return fs.CopyDir(snapDataDir, dataDir)
+}
+
+// Restore empties dataDir rather than recreating it, to keep its mounts and its project quota.
+func (copySnapshotBackend) Restore(dataDir, snapDataDir, _ string) error {
+	entries, err := os.ReadDir(dataDir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := os.RemoveAll(filepath.Join(dataDir, e.Name())); err != nil {
+			return err
+		}
+	}
+	return fs.CopyDir(dataDir, snapDataDir)
+}
+
+func (copySnapshotBackend) Remove(_, snapDataDir, _ string) error {
+	return os.RemoveAll(snapDataDir)
+}
+
+type btrfsSnapshotBackend struct{}
+
+func (btrfsSnapshotBackend) Method() string {
+	return SnapshotMethodBtrfs
+}
+
+func (btrfsSnapshotBackend) Create(dataDir, snapDataDir, _ string) error {
+	return runSnapshotCommand("btrfs", "subvolume", "snapshot", "-r", dataDir, snapDataDir)
+}
+
+func (btrfsSnapshotBackend) Restore(dataDir, snapDataDir, _ string) error {
+	if err := runSnapshotCommand("btrfs", "subvolume", "delete", dataDir); err != nil {
+		return err
+	}
+	return runSnapshotCommand("btrfs", "subvolume", "snapshot", snapDataDir, dataDir)
+}
+
+func (btrfsSnapshotBackend) Remove(_, snapDataDir, _ string) error {
+	return runSnapshotCommand("btrfs", "subvolume", "delete", snapDataDir)
+}
+
+// zfsSnapshotBackend takes the snapshots of the dataset as "<DATASET>@nerdctl-<SNAPSHOT>".
+// Restoring a snapshot destroys the later snapshots of the dataset (`zfs rollback -r`).
+type zfsSnapshotBackend struct{}
+
+func (zfsSnapshotBackend) Method() string {
+	return SnapshotMethodZFS
+}
+
+func (zfsSnapshotBackend) Create(dataDir, _, snapshot string) error {
+	ds, err := zfsDataset(dataDir)
+	if err != nil {
+		return err
+	}
+	return runSnapshotCommand("zfs", "snapshot", ds+"@nerdctl-"+snapshot)
+}
+
+func (zfsSnapshotBackend) Restore(dataDir, _, snapshot string) error {
+	ds, err := zfsDataset(dataDir)
+	if err != nil {
+		return err
+	}
+	return runSnapshotCommand("zfs", "rollback", "-r", ds+"@nerdctl-"+snapshot)
+}
+
+func (zfsSnapshotBackend) Remove(dataDir, _, snapshot string) error {
+	ds, err := zfsDataset(dataDir)
+	if err != nil {
+		return err
+	}
+	return runSnapshotCommand("zfs", "destroy", ds+"@nerdctl-"+snapshot)
+}
+
+// zfsDataset returns the ZFS dataset mounted on dir.
+func zfsDataset(dir string) (string, error) {
+	out, err := exec.Command("zfs", "list", "-H", "-o", "name,mountpoint", "-t", "filesystem").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list the ZFS datasets: %w", err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		name, mountpoint, ok := strings.Cut(line, "\t")
+		if ok && mountpoint == dir {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("no ZFS dataset is mounted on %q", dir)
+}
+
+func runSnapshotCommand(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to execute %v: %w (out=%q)", cmd.Args, err, string(out))
+	}
+	return nil
+}
+
+func (vs *volumeStore) CreateSnapshot(name, snapshot string) (snap *native.VolumeSnapshot, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrVolumeStore, err)
+		}
+	}()
+
+	if err = identifiers.ValidateDockerCompat(name); err != nil {
+		return nil, err
+	}
+	if err = identifiers.ValidateDockerCompat(snapshot); err != nil {
+		return nil, err
+	}
+
+	err = vs.Locker.WithLock(func() error {
+		dataDir, err := vs.snapshottableDataDir(name)
+		if err != nil {
+			return err
+		}
+		if doesExist, err := vs.manager.Exists(name, snapshotsDirName, snapshot); err != nil {
+			return err
+		} else if doesExist {
+			return fmt.Errorf("snapshot %q of volume %q already exists", snapshot, name)
+		}
+		if err = vs.manager.GroupEnsure(name, snapshotsDirName, snapshot); err != nil {
+			return err
+		}
+		snapDir, err := vs.manager.Location(name, snapshotsDirName, snapshot)
+		if err != nil {
+			return err
+		}
+		backend := detectSnapshotBackend(dataDir)
+		snap = &native.VolumeSnapshot{
+			Name:      snapshot,
+			Volume:    name,
+			CreatedAt: time.Now(),
+			Method:    backend.Method(),
+		}
+		snapJSON, err := json.MarshalIndent(snap, "", "    ")
+		if err != nil {
+			return err
+		}
+		if err = backend.Create(dataDir, filepath.Join(snapDir, dataDirName), snapshot); err == nil {
+			err = vs.manager.Set(snapJSON, name, snapshotsDirName, snapshot, snapshotJSONFileName)
+		}
+		if err != nil {
+			if rmErr := backend.Remove(dataDir, filepath.Join(snapDir, dataDirName), snapshot); rmErr != nil {
+				log.L.WithError(rmErr).Debugf("failed to remove snapshot %q of volume %q", snapshot, name)
+			}
+			if rmErr := vs.manager.Delete(name, snapshotsDirName, snapshot); rmErr != nil {
+				log.L.WithError(rmErr).Warnf("failed to remove snapshot %q of volume %q", snapshot, name)
+			}
+			return err
+		}
+		return nil
+	})
+
+	return snap, err
+}
+
+func (vs *volumeStore) ListSnapshots(name string) (snaps []native.VolumeSnapshot, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrVolumeStore, err)
+		}
+	}()
+
+	if err = identifiers.ValidateDockerCompat(name); err != nil {
+		return nil, err
+	}
+
+	err = vs.Locker.WithLock(func() error {
+		if doesExist, err := vs.manager.Exists(name); err != nil {
+			return err
+		} else if !doesExist {
+			return fmt.Errorf("volume %q: %w", name, store.ErrNotFound)
+		}
+		snaps, err = vs.rawListSnapshots(name)
+		return err
+	})
+
+	return snaps, err
+}
+
+func (vs *volumeStore) RestoreSnapshot(name, snapshot string) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrVolumeStore, err)
+		}
+	}()
+
+	if err = identifiers.ValidateDockerCompat(name); err != nil {
+		return err
+	}
+	if err = identifiers.ValidateDockerCompat(snapshot); err != nil {
+		return err
+	}
+
+	return vs.Locker.WithLock(func() error {
+		dataDir, err := vs.snapshottableDataDir(name)
+		if err != nil {
+			return err
+		}
+		snap, err := vs.rawGetSnapshot(name, snapshot)
+		if err != nil {
+			return err
+		}
+		backend, err := snapshotBackendFor(snap.Method)
+		if err != nil {
+			return err
+		}
+		snapDir, err := vs.manager.Location(name, snapshotsDirName, snapshot)
+		if err != nil {
+			return err
+		}
+		if err = backend.Restore(dataDir, filepath.Join(snapDir, dataDirName), snapshot); err != nil {
+			return fmt.Errorf("failed to restore snapshot %q of volume %q: %w", snapshot, name, err)
+		}
+		if snap.Method != SnapshotMethodZFS {
+			return nil
+		}
+		// `zfs rollback -r` has destroyed the later snapshots
+		snaps, err := vs.rawListSnapshots(name)
+		if err != nil {
+			return err
+		}
+		for _, s := range snaps {
+			if s.Method == SnapshotMethodZFS && s.CreatedAt.After(snap.CreatedAt) {
+				if err = vs.manager.Delete(name, snapshotsDirName, s.Name); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (vs *volumeStore) RemoveSnapshot(name, snapshot string) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrVolumeStore, err)
+		}
+	}()
+
+	if err = identifiers.ValidateDockerCompat(name); err != nil {
+		return err
+	}
+	if err = identifiers.ValidateDockerCompat(snapshot); err != nil {
+		return err
+	}
+
+	return vs.Locker.WithLock(func() error {
+		snap, err := vs.rawGetSnapshot(name, snapshot)
+		if err != nil {
+			return err
+		}
+		return vs.rawRemoveSnapshot(name, snap)
+	})
+}
+
+// snapshottableDataDir returns the data directory of a volume, if the volume supports snapshots.
+func (vs *volumeStore) snapshottableDataDir(name string) (string, error) {
+	content, err := vs.manager.Get(name, volumeJSONFileName)
+	if err != nil {
+		return "", err
+	}
+	if driver, _ := volumeDriver(content); driver != LocalDriverName {
+		return "", fmt.Errorf("volume %q uses the driver %q, only the volumes of the %q driver support snapshots", name, driver, LocalDriverName)
+	}
+	return vs.manager.Location(name, dataDirName)
+}
+
+func (vs *volumeStore) rawGetSnapshot(name, snapshot string) (*native.VolumeSnapshot, error) {
+	content, err := vs.manager.Get(name, snapshotsDirName, snapshot, snapshotJSONFileName)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, fmt.Errorf("snapshot %q of volume %q: %w", snapshot, name, store.ErrNotFound)
+		}
+		return nil, err
+	}
+	var snap native.VolumeSnapshot
+	if err := json.Unmarshal(content, &snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+func (vs *volumeStore) rawListSnapshots(name string) ([]native.VolumeSnapshot, error) {
+	names, err := vs.manager.List(name, snapshotsDirName)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var snaps []native.VolumeSnapshot
+	for _, n := range names {
+		snap, err := vs.rawGetSnapshot(name, n)
+		if err != nil {
+			log.L.WithError(err).Errorf("something is wrong with snapshot %q of volume %q", n, name)
+			continue
+		}
+		snaps = append(snaps, *snap)
+	}
+	sort.Slice(snaps, func(i, j int) bool {
+		return snaps[i].CreatedAt.Before(snaps[j].CreatedAt)
+	})
+	return snaps, nil
+}
+
+func (vs *volumeStore) rawRemoveSnapshot(name string, snap *native.VolumeSnapshot) error {
+	backend, err := snapshotBackendFor(snap.Method)
+	if err != nil {
+		return err
+	}
+	dataDir, err := vs.manager.Location(name, dataDirName)
+	if err != nil {
+		return err
+	}
+	snapDir, err := vs.manager.Location(name, snapshotsDirName, snap.Name)
+	if err != nil {
+		return err
+	}
+	if err = backend.Remove(dataDir, filepath.Join(snapDir, dataDirName), snap.Name); err != nil {
+		return fmt.Errorf("failed to remove snapshot %q of volume %q: %w", snap.Name, name, err)
+	}
+	return vs.manager.Delete(name, snapshotsDirName, snap.Name)
+}
+
+// removeSnapshots removes the snapshots of a volume, before the removal of the volume.
+// The snapshots outside the volume directory (ZFS) and the read-only ones (btrfs) cannot be removed with the directory.
+func (vs *volumeStore) removeSnapshots(name string) error {
+	snaps, err := vs.rawListSnapshots(name)
+	if err != nil {
+		return err
+	}
+	for _, snap := range snaps {
+		if err := vs.rawRemoveSnapshot(name, &snap); err != nil {
+			return err
+		}
+	}
+	return nil
+}
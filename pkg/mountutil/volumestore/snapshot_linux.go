@@ -0,0 +1,52 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volumestore
+
+import (
+	"os/exec"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	zfsSuperMagic = 0x2fc12fc2
+	// btrfsFirstFreeObjectID is the inode number of the root directory of a btrfs subvolume
+	btrfsFirstFreeObjectID = 256
+)
+
+// detectSnapshotBackend returns the snapshot backend for the data directory of a volume:
+// btrfs when it is a btrfs subvolume, zfs when it is the mountpoint of a ZFS dataset, and copy otherwise.
+func detectSnapshotBackend(dataDir string) snapshotBackend {
+	var sfs unix.Statfs_t
+	if err := unix.Statfs(dataDir, &sfs); err != nil {
+		return copySnapshotBackend{}
+	}
+	switch uint32(sfs.Type) {
+	case unix.BTRFS_SUPER_MAGIC:
+		var st unix.Stat_t
+		if err := unix.Stat(dataDir, &st); err == nil && st.Ino == btrfsFirstFreeObjectID {
+			if _, err := exec.LookPath("btrfs"); err == nil {
+				return btrfsSnapshotBackend{}
+			}
+		}
+	case zfsSuperMagic:
+		if _, err := zfsDataset(dataDir); err == nil {
+			return zfsSnapshotBackend{}
+		}
+	}
+	return copySnapshotBackend{}
+}
@@ -0,0 +1,74 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volumestore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSnapshots(t *testing.T) {
+	vs, err := New(t.TempDir(), "default")
+	assert.NilError(t, err)
+
+	vol, err := vs.Create("vol1", nil)
+	assert.NilError(t, err)
+	f := filepath.Join(vol.Mountpoint, "f")
+	assert.NilError(t, os.WriteFile(f, []byte("v1"), 0o644))
+
+	snap, err := vs.CreateSnapshot("vol1", "snap1")
+	assert.NilError(t, err)
+	assert.Equal(t, snap.Volume, "vol1")
+	_, err = vs.CreateSnapshot("vol1", "snap1")
+	assert.ErrorContains(t, err, "already exists")
+
+	assert.NilError(t, os.WriteFile(f, []byte("v2"), 0o644))
+	assert.NilError(t, os.WriteFile(filepath.Join(vol.Mountpoint, "g"), []byte("new"), 0o644))
+	_, err = vs.CreateSnapshot("vol1", "snap2")
+	assert.NilError(t, err)
+
+	snaps, err := vs.ListSnapshots("vol1")
+	assert.NilError(t, err)
+	assert.Equal(t, len(snaps), 2)
+	assert.Equal(t, snaps[0].Name, "snap1")
+	assert.Equal(t, snaps[1].Name, "snap2")
+
+	assert.NilError(t, vs.RestoreSnapshot("vol1", "snap1"))
+	b, err := os.ReadFile(f)
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), "v1")
+	_, err = os.Stat(filepath.Join(vol.Mountpoint, "g"))
+	assert.Assert(t, os.IsNotExist(err))
+
+	assert.ErrorContains(t, vs.RestoreSnapshot("vol1", "nosuchsnap"), "not found")
+	_, err = vs.ListSnapshots("nosuchvol")
+	assert.ErrorContains(t, err, "not found")
+
+	assert.NilError(t, vs.RemoveSnapshot("vol1", "snap2"))
+	snaps, err = vs.ListSnapshots("vol1")
+	assert.NilError(t, err)
+	assert.Equal(t, len(snaps), 1)
+
+	removed, _, err := vs.Remove(func() ([]string, []error, error) {
+		return []string{"vol1"}, nil, nil
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, removed, []string{"vol1"})
+}
@@ -24,11 +24,13 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"runtime"
 
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/identifiers"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+	"github.com/containerd/nerdctl/v2/pkg/quota"
 	"github.com/containerd/nerdctl/v2/pkg/store"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
@@ -51,6 +53,9 @@ type VolumeStore interface {
 	// NOTE that different labels will NOT create a new volume if there is one by that name already,
 	// but instead return the existing one with the (possibly different) labels
 	Create(name string, labels []string) (vol *native.Volume, err error)
+	// CreateWithDriver is like Create, with a volume driver (see Driver) and its driver-specific options.
+	// It errors if there is an existing volume by that name with a different driver.
+	CreateWithDriver(name string, labels []string, driver string, opts map[string]string) (vol *native.Volume, err error)
 	// List returns all existing volumes.
 	// Note that list is expensive as it reads all volumes individual info
 	List(size bool) (map[string]native.Volume, error)
@@ -61,6 +66,15 @@ type VolumeStore interface {
 	// Count returns the number of volumes
 	Count() (count int, err error)
 
+	// CreateSnapshot takes a snapshot of a volume of the local driver
+	CreateSnapshot(name, snapshot string) (*native.VolumeSnapshot, error)
+	// ListSnapshots returns the snapshots of a volume, the oldest first
+	ListSnapshots(name string) ([]native.VolumeSnapshot, error)
+	// RestoreSnapshot replaces the content of a volume with one of its snapshots
+	RestoreSnapshot(name, snapshot string) error
+	// RemoveSnapshot removes a snapshot of a volume
+	RemoveSnapshot(name, snapshot string) error
+
 	// Lock: see store implementation
 	Lock() error
 	// CreateWithoutLock will create a volume (or return an existing one).
@@ -84,7 +98,8 @@ func New(dataStore, namespace string) (volStore VolumeStore, err error) {
 		return nil, store.ErrInvalidArgument
 	}
 
-	st, err := store.New(filepath.Join(dataStore, volumeDirBasename, namespace), 0, 0o600)
+	volumeDir := filepath.Join(dataStore, volumeDirBasename, namespace)
+	st, err := store.New(volumeDir, 0, 0o600)
 	if err != nil {
 		return nil, err
 	}
@@ -92,6 +107,12 @@ func New(dataStore, namespace string) (volStore VolumeStore, err error) {
 	return &volumeStore{
 		Locker:  st,
 		manager: st,
+		lookupDriver: func(name string) (Driver, error) {
+			if name == ZFSDriverName {
+				return newZFSDriver(namespace, volumeDir), nil
+			}
+			return FindPlugin(name)
+		},
 	}, nil
 }
 
@@ -99,7 +120,8 @@ type volumeStore struct {
 	// Expose the lock primitives directly to satisfy interface for Lock and Release
 	store.Locker
 
-	manager store.Manager
+	manager      store.Manager
+	lookupDriver DriverLookup
 }
 
 // Exists checks if a volume exists in the store
@@ -158,10 +180,14 @@ func (vs *volumeStore) CreateWithoutLock(name string, labels []string) (vol *nat
 		return nil, err
 	}
 
-	return vs.rawCreate(name, labels)
+	return vs.rawCreate(name, labels, "", nil)
 }
 
 func (vs *volumeStore) Create(name string, labels []string) (vol *native.Volume, err error) {
+	return vs.CreateWithDriver(name, labels, "", nil)
+}
+
+func (vs *volumeStore) CreateWithDriver(name string, labels []string, driver string, opts map[string]string) (vol *native.Volume, err error) {
 	defer func() {
 		if err != nil {
 			err = errors.Join(ErrVolumeStore, err)
@@ -173,7 +199,7 @@ func (vs *volumeStore) Create(name string, labels []string) (vol *native.Volume,
 	}
 
 	err = vs.Locker.WithLock(func() error {
-		vol, err = vs.rawCreate(name, labels)
+		vol, err = vs.rawCreate(name, labels, driver, opts)
 		return err
 	})
 
@@ -263,6 +289,10 @@ func (vs *volumeStore) Remove(generator func() ([]string, []error, error)) (remo
 				// TODO: see above
 				warns = append(warns, fmt.Errorf("volume %q: %w", name, store.ErrNotFound))
 				continue
+			} else if err = vs.removeFromDriver(name); err != nil {
+				return err
+			} else if err = vs.removeSnapshots(name); err != nil {
+				return err
 			} else if err = vs.manager.Delete(name); err != nil {
 				return err
 			}
@@ -306,6 +336,12 @@ func (vs *volumeStore) Prune(filter func(vol []*native.Volume) ([]string, error)
 		}
 
 		for _, name := range toDelete {
+			if err = vs.removeFromDriver(name); err != nil {
+				return err
+			}
+			if err = vs.removeSnapshots(name); err != nil {
+				return err
+			}
 			err = vs.manager.Delete(name)
 			if err != nil {
 				return err
@@ -327,12 +363,37 @@ func (vs *volumeStore) rawGet(name string, size bool) (vol *native.Volume, err e
 		Labels: labels(content),
 	}
 
+	driver, opts := volumeDriver(content)
+	vol.Driver = driver
+	vol.Options = opts
+	if driver != LocalDriverName && !isNetworkDriver(driver) {
+		// The volume is still listed when the plugin is unavailable, without the mountpoint
+		if d, err := vs.lookupDriver(driver); err != nil {
+			log.L.WithError(err).Warnf("failed to get the mountpoint of volume %q", name)
+		} else if vol.Mountpoint, err = d.Path(name); err != nil {
+			log.L.WithError(err).Warnf("failed to get the mountpoint of volume %q", name)
+		}
+		return vol, nil
+	}
+
 	vol.Mountpoint, err = vs.manager.Location(name, dataDirName)
 	if err != nil {
 		return nil, err
 	}
 
-	if size {
+	// The usage of a volume with a size limit is read from its quota, without walking the volume
+	if sizeLimit, _ := localVolumeSize(opts); sizeLimit > 0 {
+		if q, err := quota.Get(vol.Mountpoint); err != nil {
+			log.L.WithError(err).Warnf("failed to get the quota of volume %q", name)
+		} else {
+			vol.SizeLimit = int64(q.Size)
+			vol.Size = int64(q.Used)
+			return vol, nil
+		}
+	}
+
+	// The size of a network volume is not computed, as it may take long to walk the remote file system
+	if size && !isNetworkDriver(driver) {
 		vol.Size, err = vs.manager.GroupSize(name, dataDirName)
 		if err != nil {
 			return nil, errors.Join(fmt.Errorf("failed reading volume size for %q", name), err)
@@ -342,14 +403,35 @@ func (vs *volumeStore) rawGet(name string, size bool) (vol *native.Volume, err e
 	return vol, nil
 }
 
-func (vs *volumeStore) rawCreate(name string, labels []string) (vol *native.Volume, err error) {
+// rawCreate creates a volume with the driver, or returns an existing one.
+// An empty driver stands for the driver of the existing volume, or the local driver for a new one.
+func (vs *volumeStore) rawCreate(name string, labels []string, driver string, opts map[string]string) (vol *native.Volume, err error) {
 	volOpts := struct {
-		Labels map[string]string `json:"labels"`
+		Labels  map[string]string `json:"labels"`
+		Driver  string            `json:"driver,omitempty"`
+		Options map[string]string `json:"options,omitempty"`
 	}{}
 
 	if len(labels) > 0 {
 		volOpts.Labels = strutil.ConvertKVStringsToMap(labels)
 	}
+	var credentials []byte
+	if isNetworkDriver(driver) {
+		if runtime.GOOS != "linux" {
+			return nil, fmt.Errorf("volume driver %q is not supported on %s", driver, runtime.GOOS)
+		}
+		volOpts.Driver = driver
+		if volOpts.Options, credentials, err = parseNetworkVolumeOptions(driver, opts); err != nil {
+			return nil, err
+		}
+	} else if driver != "" && driver != LocalDriverName {
+		volOpts.Driver = driver
+		volOpts.Options = opts
+	} else if len(opts) > 0 {
+		if volOpts.Options, err = parseLocalVolumeOptions(opts); err != nil {
+			return nil, err
+		}
+	}
 
 	// Failure here must exit, no need to clean-up
 	labelsJSON, err := json.MarshalIndent(volOpts, "", "    ")
@@ -357,20 +439,71 @@ func (vs *volumeStore) rawCreate(name string, labels []string) (vol *native.Volu
 		return nil, err
 	}
 
+	var d Driver
+	created := false
 	if doesExist, err := vs.manager.Exists(name, volumeJSONFileName); err != nil {
 		return nil, err
 	} else if !doesExist {
+		if volOpts.Driver != "" && !isNetworkDriver(volOpts.Driver) {
+			if d, err = vs.lookupDriver(volOpts.Driver); err != nil {
+				return nil, err
+			}
+			if err = d.Create(name, opts); err != nil {
+				return nil, err
+			}
+		}
 		if err = vs.manager.Set(labelsJSON, name, volumeJSONFileName); err != nil {
+			if d != nil {
+				if rmErr := d.Remove(name); rmErr != nil {
+					log.L.WithError(rmErr).Warnf("failed to remove volume %q from the driver %q", name, d.Name())
+				}
+			}
 			return nil, err
 		}
+		if credentials != nil {
+			if err = vs.manager.Set(credentials, name, credentialsFileName); err != nil {
+				return nil, err
+			}
+		}
+		created = true
 	} else {
+		content, err := vs.manager.Get(name, volumeJSONFileName)
+		if err != nil {
+			return nil, err
+		}
+		existingDriver, _ := volumeDriver(content)
+		if driver != "" && driver != existingDriver {
+			return nil, fmt.Errorf("volume %q already exists with the driver %q, not %q", name, existingDriver, driver)
+		}
 		log.L.Warnf("volume %q already exists and will be returned as-is", name)
 		// FIXME: we do not check if the existing volume has the same labels as requested - should we?
+		volOpts.Driver = existingDriver
+		if existingDriver != LocalDriverName && !isNetworkDriver(existingDriver) {
+			if d, err = vs.lookupDriver(existingDriver); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// At this point, we either have an existing volume, or created a new one successfully
 	vol = &native.Volume{
-		Name: name,
+		Name:   name,
+		Driver: LocalDriverName,
+	}
+	if volOpts.Driver != "" {
+		vol.Driver = volOpts.Driver
+	}
+
+	if d != nil {
+		if vol.Mountpoint, err = d.Path(name); err != nil {
+			return nil, err
+		}
+		if vol.Mountpoint == "" {
+			if vol.Mountpoint, err = d.Mount(name, DefaultMountID); err != nil {
+				return nil, err
+			}
+		}
+		return vol, nil
 	}
 
 	if err = vs.manager.GroupEnsure(name, dataDirName); err != nil {
@@ -381,10 +514,90 @@ func (vs *volumeStore) rawCreate(name string, labels []string) (vol *native.Volu
 		return nil, err
 	}
 
+	if created && vol.Driver == LocalDriverName {
+		if sizeLimit, _ := localVolumeSize(volOpts.Options); sizeLimit > 0 {
+			if err = quota.Set(vol.Mountpoint, sizeLimit); err != nil {
+				if rmErr := vs.manager.Delete(name); rmErr != nil {
+					log.L.WithError(rmErr).Warnf("failed to remove volume %q", name)
+				}
+				return nil, fmt.Errorf("failed to set the size limit of volume %q: %w", name, err)
+			}
+			vol.SizeLimit = int64(sizeLimit)
+		}
+	}
+
 	return vol, nil
 }
 
+// removeFromDriver unmounts and removes the volume from its driver, if the volume does not use the local driver.
+func (vs *volumeStore) removeFromDriver(name string) error {
+	content, err := vs.manager.Get(name, volumeJSONFileName)
+	if err != nil {
+		return err
+	}
+	driver, _ := volumeDriver(content)
+	if driver == LocalDriverName {
+		return nil
+	}
+	if isNetworkDriver(driver) {
+		dataDir, err := vs.manager.Location(name, dataDirName)
+		if err != nil {
+			return err
+		}
+		if err := checkNetworkVolumeUnused(dataDir); err != nil {
+			return fmt.Errorf("cannot remove volume %q: %w", name, err)
+		}
+		return nil
+	}
+	d, err := vs.lookupDriver(driver)
+	if err != nil {
+		return err
+	}
+	if err := d.Unmount(name, DefaultMountID); err != nil {
+		// The volume may not have been mounted
+		log.L.WithError(err).Debugf("failed to unmount volume %q", name)
+	}
+	return d.Remove(name)
+}
+
 // Private helpers
+
+// localVolumeSizeOption is the option of the local driver for the size limit of a volume, as with Docker.
+const localVolumeSizeOption = "size"
+
+// parseLocalVolumeOptions validates the options of the local driver, which only supports "size".
+func parseLocalVolumeOptions(opts map[string]string) (map[string]string, error) {
+	for k := range opts {
+		if k != localVolumeSizeOption {
+			return nil, fmt.Errorf("volume driver %q does not support option %q", LocalDriverName, k)
+		}
+	}
+	if _, err := localVolumeSize(opts); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// localVolumeSize returns the size limit of a local volume in bytes, or 0 if it does not have one.
+func localVolumeSize(opts map[string]string) (uint64, error) {
+	s, ok := opts[localVolumeSizeOption]
+	if !ok {
+		return 0, nil
+	}
+	return quota.ParseSize(s)
+}
+
+func volumeDriver(b []byte) (string, map[string]string) {
+	var vo struct {
+		Driver  string            `json:"driver,omitempty"`
+		Options map[string]string `json:"options,omitempty"`
+	}
+	if err := json.Unmarshal(b, &vo); err != nil || vo.Driver == "" {
+		return LocalDriverName, nil
+	}
+	return vo.Driver, vo.Options
+}
+
 func labels(b []byte) *map[string]string {
 	type volumeOpts struct {
 		Labels *map[string]string `json:"labels,omitempty"`
@@ -0,0 +1,146 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volumestore
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/nerdctl/v2/pkg/quota"
+)
+
+// ZFSDriverName is the name of the built-in volume driver creating a ZFS dataset for each volume.
+// The dataset is mounted on the data directory of the volume, as with the local driver.
+const ZFSDriverName = "zfs"
+
+// ZFSParentDataset is the dataset under which the datasets of the volumes are created,
+// as "<ZFSParentDataset>/<namespace>/<name>".
+var ZFSParentDataset = "zroot/nerdctl/volumes"
+
+// zfsManagedProperties are the dataset properties set by the driver, which cannot be specified as options.
+var zfsManagedProperties = []string{"mountpoint", "canmount"}
+
+type zfsDriver struct {
+	// dataset is the parent dataset of the volumes of the namespace
+	dataset string
+	// volumeDir is the directory of the volumes of the namespace in the data store
+	volumeDir string
+	// zfs runs zfs(8) and returns its output
+	zfs func(args ...string) (string, error)
+}
+
+func newZFSDriver(namespace, volumeDir string) *zfsDriver {
+	return &zfsDriver{
+		dataset:   ZFSParentDataset + "/" + namespace,
+		volumeDir: volumeDir,
+		zfs:       runZFS,
+	}
+}
+
+func runZFS(args ...string) (string, error) {
+	cmd := exec.Command("zfs", args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", fmt.Errorf("volume driver %q needs zfs(8): %w", ZFSDriverName, err)
+		}
+		return "", fmt.Errorf("failed to run %v: %w (output=%q)", cmd.Args, err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+func (d *zfsDriver) Name() string {
+	return ZFSDriverName
+}
+
+func (d *zfsDriver) datasetOf(name string) string {
+	return d.dataset + "/" + name
+}
+
+// Create creates the dataset of the volume.
+// "size" sets the quota of the dataset, and the other options are set as the properties of the dataset,
+// e.g., {"compression": "lz4"}.
+func (d *zfsDriver) Create(name string, opts map[string]string) error {
+	args := []string{"create", "-p", "-o", "mountpoint=" + filepath.Join(d.volumeDir, name, dataDirName)}
+	keys := make([]string, 0, len(opts))
+	for k := range opts {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		v := opts[k]
+		switch {
+		case k == localVolumeSizeOption:
+			size, err := quota.ParseSize(v)
+			if err != nil {
+				return err
+			}
+			args = append(args, "-o", "quota="+strconv.FormatUint(size, 10))
+		case slices.Contains(zfsManagedProperties, k):
+			return fmt.Errorf("volume driver %q does not support option %q", ZFSDriverName, k)
+		default:
+			args = append(args, "-o", k+"="+v)
+		}
+	}
+	_, err := d.zfs(append(args, d.datasetOf(name))...)
+	return err
+}
+
+// Remove destroys the dataset of the volume, with its snapshots.
+func (d *zfsDriver) Remove(name string) error {
+	_, err := d.zfs("destroy", "-r", d.datasetOf(name))
+	if err != nil && strings.Contains(err.Error(), "dataset does not exist") {
+		return nil
+	}
+	return err
+}
+
+func (d *zfsDriver) Mount(name, _ string) (string, error) {
+	mountpoint, err := d.Path(name)
+	if err != nil || mountpoint != "" {
+		return mountpoint, err
+	}
+	if _, err := d.zfs("mount", d.datasetOf(name)); err != nil {
+		return "", err
+	}
+	return d.Path(name)
+}
+
+func (d *zfsDriver) Unmount(name, _ string) error {
+	_, err := d.zfs("unmount", d.datasetOf(name))
+	return err
+}
+
+func (d *zfsDriver) Path(name string) (string, error) {
+	out, err := d.zfs("get", "-H", "-o", "value", "mounted,mountpoint", d.datasetOf(name))
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return "", fmt.Errorf("unexpected output of zfs get: %q", out)
+	}
+	if fields[0] != "yes" {
+		return "", nil
+	}
+	return fields[1], nil
+}
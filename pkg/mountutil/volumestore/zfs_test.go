@@ -0,0 +1,84 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volumestore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// fakeZFS records the zfs(8) commands, and keeps the mountpoints of the mounted datasets.
+type fakeZFS struct {
+	commands []string
+	mounted  map[string]string
+}
+
+func (f *fakeZFS) run(args ...string) (string, error) {
+	f.commands = append(f.commands, strings.Join(args, " "))
+	dataset := args[len(args)-1]
+	switch args[0] {
+	case "create":
+		for i, a := range args {
+			if strings.HasPrefix(a, "mountpoint=") && args[i-1] == "-o" {
+				f.mounted[dataset] = strings.TrimPrefix(a, "mountpoint=")
+			}
+		}
+	case "destroy":
+		if _, ok := f.mounted[dataset]; !ok {
+			return "", errors.New("cannot open '" + dataset + "': dataset does not exist")
+		}
+		delete(f.mounted, dataset)
+	case "get":
+		if mp, ok := f.mounted[dataset]; ok {
+			return fmt.Sprintf("yes\n%s\n", mp), nil
+		}
+		return "no\n/unmounted\n", nil
+	}
+	return "", nil
+}
+
+func TestZFSDriver(t *testing.T) {
+	f := &fakeZFS{mounted: map[string]string{}}
+	d := newZFSDriver("default", "/var/lib/nerdctl/volumes/default")
+	d.zfs = f.run
+
+	assert.NilError(t, d.Create("vol1", map[string]string{"size": "1G", "compression": "lz4"}))
+	assert.Equal(t, f.commands[0], "create -p -o mountpoint=/var/lib/nerdctl/volumes/default/vol1/_data "+
+		"-o compression=lz4 -o quota=1073741824 zroot/nerdctl/volumes/default/vol1")
+
+	mp, err := d.Mount("vol1", DefaultMountID)
+	assert.NilError(t, err)
+	assert.Equal(t, mp, "/var/lib/nerdctl/volumes/default/vol1/_data")
+
+	mp, err = d.Path("vol2")
+	assert.NilError(t, err)
+	assert.Equal(t, mp, "")
+
+	err = d.Create("vol2", map[string]string{"mountpoint": "/tmp"})
+	assert.ErrorContains(t, err, `does not support option "mountpoint"`)
+	err = d.Create("vol2", map[string]string{"size": "foo"})
+	assert.ErrorContains(t, err, "invalid size")
+
+	assert.NilError(t, d.Remove("vol1"))
+	assert.Equal(t, len(f.mounted), 0)
+	// Removing a volume whose dataset is already gone succeeds
+	assert.NilError(t, d.Remove("vol1"))
+}
@@ -0,0 +1,89 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package namespaceutil implements the default resource constraints and labels of the containers
+// in a containerd namespace, which are stored as the labels of the namespace.
+package namespaceutil
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+// DefaultCPUPeriod is the default CPU CFS period, in microseconds.
+const DefaultCPUPeriod = 100000
+
+// Limits are the default resource constraints and labels of the containers in a namespace.
+// The resource constraints are also the maximum ones that the containers may request.
+type Limits struct {
+	// CPUQuota is the CPU CFS quota per DefaultCPUPeriod, in microseconds. Zero means unlimited.
+	CPUQuota int64
+	// Memory is the memory limit in bytes. Zero means unlimited.
+	Memory int64
+	// PidsLimit is the pids limit. Zero means unlimited.
+	PidsLimit int64
+	// Labels are the default labels of the containers
+	Labels map[string]string
+}
+
+// ParseLimits parses the limits from the labels of a namespace.
+func ParseLimits(nsLabels map[string]string) (*Limits, error) {
+	l := &Limits{}
+	for k, p := range map[string]*int64{
+		labels.NamespaceCPUQuota:  &l.CPUQuota,
+		labels.NamespaceMemory:    &l.Memory,
+		labels.NamespacePidsLimit: &l.PidsLimit,
+	} {
+		v, ok := nsLabels[k]
+		if !ok {
+			continue
+		}
+		i, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || i < 0 {
+			return nil, fmt.Errorf("invalid namespace label %s=%q", k, v)
+		}
+		*p = i
+	}
+	for k, v := range nsLabels {
+		if key, ok := strings.CutPrefix(k, labels.NamespaceDefaultLabelPrefix); ok && key != "" {
+			if l.Labels == nil {
+				l.Labels = make(map[string]string)
+			}
+			l.Labels[key] = v
+		}
+	}
+	return l, nil
+}
+
+// Get returns the limits of the namespace.
+// A namespace that does not exist yet has no limits.
+func Get(ctx context.Context, client *containerd.Client, namespace string) (*Limits, error) {
+	nsLabels, err := client.NamespaceService().Labels(ctx, namespace)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return &Limits{}, nil
+		}
+		return nil, err
+	}
+	return ParseLimits(nsLabels)
+}
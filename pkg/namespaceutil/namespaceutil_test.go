@@ -0,0 +1,49 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package namespaceutil
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+func TestParseLimits(t *testing.T) {
+	l, err := ParseLimits(map[string]string{
+		labels.NamespaceCPUQuota:                    "150000",
+		labels.NamespaceMemory:                      "1073741824",
+		labels.NamespacePidsLimit:                   "100",
+		labels.NamespaceDefaultLabelPrefix + "team": "foo",
+		"unrelated":                                 "bar",
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, l, &Limits{
+		CPUQuota:  150000,
+		Memory:    1 << 30,
+		PidsLimit: 100,
+		Labels:    map[string]string{"team": "foo"},
+	})
+
+	l, err = ParseLimits(nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, l, &Limits{})
+
+	_, err = ParseLimits(map[string]string{labels.NamespaceMemory: "1g"})
+	assert.ErrorContains(t, err, "invalid namespace label")
+}
@@ -73,14 +73,19 @@ func (c *vlanConfig) GetPluginType() string {
 type portMapConfig struct {
 	PluginType   string          `json:"type"`
 	Capabilities map[string]bool `json:"capabilities"`
+
+	// Backend ("iptables" or "nftables") is supported since portmap plugin v1.7.0.
+	// nerdctl does not write the forwarding rules itself; the older plugins ignore this field and use iptables.
+	Backend string `json:"backend,omitempty"`
 }
 
-func newPortMapPlugin() *portMapConfig {
+func newPortMapPlugin(backend string) *portMapConfig {
 	return &portMapConfig{
 		PluginType: "portmap",
 		Capabilities: map[string]bool{
 			"portMappings": true,
 		},
+		Backend: backend,
 	}
 }
 
@@ -129,6 +134,37 @@ func (*tuningConfig) GetPluginType() string {
 	return "tuning"
 }
 
+// bandwidthConfig describes the bandwidth plugin
+type bandwidthConfig struct {
+	PluginType   string          `json:"type"`
+	Capabilities map[string]bool `json:"capabilities"`
+
+	// The static limits of the network, overridden by the "bandwidth" runtime config of the containers.
+	IngressRate  uint64 `json:"ingressRate,omitempty"`
+	IngressBurst uint64 `json:"ingressBurst,omitempty"`
+	EgressRate   uint64 `json:"egressRate,omitempty"`
+	EgressBurst  uint64 `json:"egressBurst,omitempty"`
+}
+
+func newBandwidthPlugin(shaping *Shaping) *bandwidthConfig {
+	c := &bandwidthConfig{
+		PluginType: "bandwidth",
+		Capabilities: map[string]bool{
+			"bandwidth": true,
+		},
+	}
+	if shaping != nil && shaping.Bandwidth > 0 {
+		bw := shaping.BandWidth()
+		c.IngressRate, c.IngressBurst = bw.IngressRate, bw.IngressBurst
+		c.EgressRate, c.EgressBurst = bw.EgressRate, bw.EgressBurst
+	}
+	return c
+}
+
+func (*bandwidthConfig) GetPluginType() string {
+	return "bandwidth"
+}
+
 // https://github.com/containernetworking/plugins/blob/v1.0.1/plugins/ipam/host-local/backend/allocator/config.go#L47-L56
 type hostLocalIPAMConfig struct {
 	Type        string        `json:"type"`
@@ -0,0 +1,39 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"fmt"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+	"github.com/vishvananda/netlink"
+)
+
+// InterfaceCounters returns the rx and tx byte counters of the interface of the network namespace.
+func InterfaceCounters(netnsPath, ifName string) (rxBytes, txBytes uint64, err error) {
+	err = ns.WithNetNSPath(netnsPath, func(ns.NetNS) error {
+		link, err := netlink.LinkByName(ifName)
+		if err != nil {
+			return fmt.Errorf("failed to find interface %q: %w", ifName, err)
+		}
+		if stats := link.Attrs().Statistics; stats != nil {
+			rxBytes, txBytes = stats.RxBytes, stats.TxBytes
+		}
+		return nil
+	})
+	return rxBytes, txBytes, err
+}
@@ -0,0 +1,118 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+)
+
+const (
+	// IngressPolicyAccept accepts the connections to the published ports from anywhere (default).
+	IngressPolicyAccept = "accept"
+	// IngressPolicyDeny drops the connections to the published ports,
+	// except the ones from the CIDRs allowed with `nerdctl run --allow-from`.
+	IngressPolicyDeny = "deny"
+
+	// IngressChain is the chain of the "filter" table holding the ingress policy rules.
+	// It is jumped to from the FORWARD chain.
+	IngressChain = "NERDCTL-INGRESS"
+)
+
+// ValidateIngressPolicy validates the value of `nerdctl network create --ingress-policy`.
+func ValidateIngressPolicy(policy string) error {
+	switch policy {
+	case "", IngressPolicyAccept, IngressPolicyDeny:
+		return nil
+	default:
+		return fmt.Errorf("invalid ingress policy %q, must be %q or %q", policy, IngressPolicyAccept, IngressPolicyDeny)
+	}
+}
+
+// ParseAllowFrom parses the values of `nerdctl run --allow-from`.
+// A plain IP address is treated as a single-host CIDR.
+func ParseAllowFrom(allowFrom []string) ([]*net.IPNet, error) {
+	res := make([]*net.IPNet, 0, len(allowFrom))
+	for _, s := range allowFrom {
+		if !strings.Contains(s, "/") {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid --allow-from %q, must be an IP address or a CIDR", s)
+			}
+			bits := 128
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 32
+			}
+			res = append(res, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --allow-from %q, must be an IP address or a CIDR: %w", s, err)
+		}
+		res = append(res, ipNet)
+	}
+	return res, nil
+}
+
+// ParseAllowFromLabel parses the value of labels.IngressAllowFrom.
+func ParseAllowFromLabel(s string) ([]*net.IPNet, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var allowFrom []string
+	if err := json.Unmarshal([]byte(s), &allowFrom); err != nil {
+		return nil, err
+	}
+	return ParseAllowFrom(allowFrom)
+}
+
+// IngressRules returns the rules of IngressChain for the container IP.
+// Only the connections forwarded by the port forwarding (i.e., DNAT-ed) are dropped,
+// so that the containers can still talk to each other.
+func IngressRules(cniID string, containerIP net.IP, allowFrom []*net.IPNet) [][]string {
+	bits := 128
+	if ip4 := containerIP.To4(); ip4 != nil {
+		containerIP, bits = ip4, 32
+	}
+	is4 := bits == 32
+	dst := (&net.IPNet{IP: containerIP, Mask: net.CIDRMask(bits, bits)}).String()
+	comment := []string{"-m", "comment", "--comment", "nerdctl ingress id: " + cniID}
+	var rules [][]string
+	for _, cidr := range allowFrom {
+		if (cidr.IP.To4() != nil) != is4 {
+			continue
+		}
+		rule := []string{"-s", cidr.String(), "-d", dst, "-m", "conntrack", "--ctstate", "DNAT"}
+		rules = append(rules, append(append(rule, comment...), "-j", "RETURN"))
+	}
+	rule := []string{"-d", dst, "-m", "conntrack", "--ctstate", "DNAT"}
+	rules = append(rules, append(append(rule, comment...), "-j", "DROP"))
+	return rules
+}
+
+func nerdctlIngressPolicy(b []byte) string {
+	var c struct {
+		IngressPolicy string `json:"nerdctlIngressPolicy,omitempty"`
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return ""
+	}
+	return c.IngressPolicy
+}
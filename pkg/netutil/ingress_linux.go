@@ -0,0 +1,84 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/coreos/go-iptables/iptables"
+)
+
+func newIPTables(ip net.IP) (*iptables.IPTables, error) {
+	if ip.To4() != nil {
+		return iptables.New()
+	}
+	return iptables.NewWithProtocol(iptables.ProtocolIPv6)
+}
+
+// SetupIngressPolicy drops the forwarded connections to the published ports of the container IPs,
+// except the ones from allowFrom.
+func SetupIngressPolicy(cniID string, containerIPs []net.IP, allowFrom []*net.IPNet) error {
+	for _, ip := range containerIPs {
+		ipt, err := newIPTables(ip)
+		if err != nil {
+			return err
+		}
+		if exists, err := ipt.ChainExists("filter", IngressChain); err != nil {
+			return err
+		} else if !exists {
+			if err := ipt.NewChain("filter", IngressChain); err != nil {
+				// may have been created concurrently
+				if exists, _ := ipt.ChainExists("filter", IngressChain); !exists {
+					return err
+				}
+			}
+		}
+		if exists, err := ipt.Exists("filter", "FORWARD", "-j", IngressChain); err != nil {
+			return err
+		} else if !exists {
+			if err := ipt.Insert("filter", "FORWARD", 1, "-j", IngressChain); err != nil {
+				return err
+			}
+		}
+		for _, rule := range IngressRules(cniID, ip, allowFrom) {
+			if err := ipt.AppendUnique("filter", IngressChain, rule...); err != nil {
+				return fmt.Errorf("failed to add ingress rule %v: %w", rule, err)
+			}
+		}
+	}
+	return nil
+}
+
+// RemoveIngressPolicy removes the rules added by SetupIngressPolicy.
+func RemoveIngressPolicy(cniID string, containerIPs []net.IP, allowFrom []*net.IPNet) error {
+	for _, ip := range containerIPs {
+		ipt, err := newIPTables(ip)
+		if err != nil {
+			return err
+		}
+		if exists, err := ipt.ChainExists("filter", IngressChain); err != nil || !exists {
+			continue
+		}
+		for _, rule := range IngressRules(cniID, ip, allowFrom) {
+			if err := ipt.DeleteIfExists("filter", IngressChain, rule...); err != nil {
+				return fmt.Errorf("failed to remove ingress rule %v: %w", rule, err)
+			}
+		}
+	}
+	return nil
+}
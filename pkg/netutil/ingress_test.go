@@ -0,0 +1,62 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"net"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseAllowFrom(t *testing.T) {
+	allowFrom, err := ParseAllowFrom([]string{"192.168.1.0/24", "10.0.0.1", "2001:db8::/32"})
+	assert.NilError(t, err)
+	var got []string
+	for _, cidr := range allowFrom {
+		got = append(got, cidr.String())
+	}
+	assert.DeepEqual(t, got, []string{"192.168.1.0/24", "10.0.0.1/32", "2001:db8::/32"})
+
+	_, err = ParseAllowFrom([]string{"example.com"})
+	assert.ErrorContains(t, err, "invalid --allow-from")
+	_, err = ParseAllowFrom([]string{"10.0.0.0/33"})
+	assert.ErrorContains(t, err, "invalid --allow-from")
+}
+
+func TestIngressRules(t *testing.T) {
+	allowFrom, err := ParseAllowFrom([]string{"192.168.1.0/24", "2001:db8::/32"})
+	assert.NilError(t, err)
+	rules := IngressRules("default-abc", net.ParseIP("10.4.0.2"), allowFrom)
+	assert.DeepEqual(t, rules, [][]string{
+		{"-s", "192.168.1.0/24", "-d", "10.4.0.2/32", "-m", "conntrack", "--ctstate", "DNAT",
+			"-m", "comment", "--comment", "nerdctl ingress id: default-abc", "-j", "RETURN"},
+		{"-d", "10.4.0.2/32", "-m", "conntrack", "--ctstate", "DNAT",
+			"-m", "comment", "--comment", "nerdctl ingress id: default-abc", "-j", "DROP"},
+	})
+
+	rules = IngressRules("default-abc", net.ParseIP("fd00::2"), allowFrom)
+	assert.Equal(t, len(rules), 2)
+	assert.Equal(t, rules[0][1], "2001:db8::/32")
+	assert.Equal(t, rules[0][3], "fd00::2/128")
+}
+
+func TestValidateIngressPolicy(t *testing.T) {
+	assert.NilError(t, ValidateIngressPolicy(""))
+	assert.NilError(t, ValidateIngressPolicy(IngressPolicyDeny))
+	assert.ErrorContains(t, ValidateIngressPolicy("reject"), "invalid ingress policy")
+}
@@ -0,0 +1,128 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+)
+
+const (
+	// IPMasq6Opt is the bridge network option to enable or disable the masquerading of the IPv6 traffic (NAT66),
+	// independently of "ip-masq". It defaults to the value of "ip-masq".
+	IPMasq6Opt = "ip-masq6"
+	// NDPProxyOpt is the bridge network option to proxy the neighbor discovery (NDP) of the IPv6 addresses
+	// of the containers on the specified host interface, e.g., "eth0".
+	// This makes the containers reachable from the link of the interface without a route on the router,
+	// when the IPv6 subnet of the network is a part of the prefix of the link.
+	NDPProxyOpt = "ndp-proxy"
+
+	// MasqueradeChain is the chain of the "nat" table holding the masquerade rules of the networks
+	// that masquerade only one of IPv4 and IPv6. It is jumped to from the POSTROUTING chain.
+	MasqueradeChain = "NERDCTL-MASQ"
+)
+
+// IPMasqConfig is stored in the network config when the masquerading differs between IPv4 and IPv6.
+// In that case, the bridge plugin does not masquerade, and nerdctl adds the rules of MasqueradeChain instead.
+type IPMasqConfig struct {
+	IPv4 bool `json:"ipv4"`
+	IPv6 bool `json:"ipv6"`
+}
+
+// Enabled returns whether the traffic from ip is masqueraded.
+func (c *IPMasqConfig) Enabled(ip net.IP) bool {
+	if ip.To4() != nil {
+		return c.IPv4
+	}
+	return c.IPv6
+}
+
+// parseIPMasqOptions returns the masquerade settings of IPv4 and IPv6 of the bridge network options.
+func parseIPMasqOptions(opts map[string]string, ipv6 bool) (ipMasq, ipMasq6 bool, err error) {
+	ipMasq = true
+	for _, opt := range []string{"ip-masq", "com.docker.network.bridge.enable_ip_masquerade"} {
+		if v, ok := opts[opt]; ok {
+			if ipMasq, err = strconv.ParseBool(v); err != nil {
+				return false, false, err
+			}
+		}
+	}
+	ipMasq6 = ipMasq
+	if v, ok := opts[IPMasq6Opt]; ok {
+		if !ipv6 {
+			return false, false, fmt.Errorf("network option %q requires --ipv6", IPMasq6Opt)
+		}
+		if ipMasq6, err = strconv.ParseBool(v); err != nil {
+			return false, false, err
+		}
+	}
+	return ipMasq, ipMasq6, nil
+}
+
+// parseNDPProxyOption returns the interface of the NDP proxy of the bridge network options, or an empty string.
+func parseNDPProxyOption(opts map[string]string, ipv6 bool) (string, error) {
+	iface, ok := opts[NDPProxyOpt]
+	if !ok {
+		return "", nil
+	}
+	if !ipv6 {
+		return "", fmt.Errorf("network option %q requires --ipv6", NDPProxyOpt)
+	}
+	if iface == "" {
+		return "", fmt.Errorf("network option %q requires an interface name, e.g., %q", NDPProxyOpt, NDPProxyOpt+"=eth0")
+	}
+	return iface, nil
+}
+
+// MasqueradeRules returns the rules of MasqueradeChain for the container address.
+// Like the bridge plugin, the traffic to the subnet of the network and to the multicast addresses is not masqueraded.
+func MasqueradeRules(cniID string, addr *net.IPNet) [][]string {
+	ip, bits, multicast := addr.IP, 128, "ff00::/8"
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits, multicast = ip4, 32, "224.0.0.0/4"
+	}
+	src := (&net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}).String()
+	subnet := (&net.IPNet{IP: ip.Mask(addr.Mask), Mask: addr.Mask}).String()
+	comment := []string{"-m", "comment", "--comment", "nerdctl masquerade id: " + cniID}
+	return [][]string{
+		append(append([]string{"-s", src, "-d", subnet}, comment...), "-j", "RETURN"),
+		append(append([]string{"-s", src, "-d", multicast}, comment...), "-j", "RETURN"),
+		append(append([]string{"-s", src}, comment...), "-j", "MASQUERADE"),
+	}
+}
+
+func nerdctlIPMasq(b []byte) *IPMasqConfig {
+	var c struct {
+		IPMasq *IPMasqConfig `json:"nerdctlIPMasq,omitempty"`
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil
+	}
+	return c.IPMasq
+}
+
+func nerdctlNDPProxy(b []byte) string {
+	var c struct {
+		NDPProxy string `json:"nerdctlNDPProxy,omitempty"`
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return ""
+	}
+	return c.NDPProxy
+}
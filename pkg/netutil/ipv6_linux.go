@@ -0,0 +1,124 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/vishvananda/netlink"
+)
+
+// SetupMasquerade masquerades the traffic from the container addresses, for the networks
+// that masquerade only one of IPv4 and IPv6 (see IPMasqConfig).
+func SetupMasquerade(cniID string, addrs []*net.IPNet) error {
+	for _, addr := range addrs {
+		ipt, err := newIPTables(addr.IP)
+		if err != nil {
+			return err
+		}
+		if exists, err := ipt.ChainExists("nat", MasqueradeChain); err != nil {
+			return err
+		} else if !exists {
+			if err := ipt.NewChain("nat", MasqueradeChain); err != nil {
+				// may have been created concurrently
+				if exists, _ := ipt.ChainExists("nat", MasqueradeChain); !exists {
+					return err
+				}
+			}
+		}
+		if err := ipt.AppendUnique("nat", "POSTROUTING", "-j", MasqueradeChain); err != nil {
+			return err
+		}
+		for _, rule := range MasqueradeRules(cniID, addr) {
+			if err := ipt.AppendUnique("nat", MasqueradeChain, rule...); err != nil {
+				return fmt.Errorf("failed to add masquerade rule %v: %w", rule, err)
+			}
+		}
+	}
+	return nil
+}
+
+// RemoveMasquerade removes the rules added by SetupMasquerade.
+func RemoveMasquerade(cniID string, addrs []*net.IPNet) error {
+	for _, addr := range addrs {
+		ipt, err := newIPTables(addr.IP)
+		if err != nil {
+			return err
+		}
+		if exists, err := ipt.ChainExists("nat", MasqueradeChain); err != nil || !exists {
+			continue
+		}
+		for _, rule := range MasqueradeRules(cniID, addr) {
+			if err := ipt.DeleteIfExists("nat", MasqueradeChain, rule...); err != nil {
+				return fmt.Errorf("failed to remove masquerade rule %v: %w", rule, err)
+			}
+		}
+	}
+	return nil
+}
+
+// SetupNDPProxy answers the neighbor solicitations for the IPv6 addresses of the container on the interface,
+// like `ip -6 neigh add proxy <IP> dev <IFACE>`.
+func SetupNDPProxy(iface string, ips []net.IP) error {
+	link, err := netlink.LinkByName(iface)
+	if err != nil {
+		return fmt.Errorf("failed to find the interface %q of the NDP proxy: %w", iface, err)
+	}
+	sysctl := filepath.Join("/proc/sys/net/ipv6/conf", iface, "proxy_ndp")
+	if err := os.WriteFile(sysctl, []byte("1"), 0o644); err != nil {
+		return fmt.Errorf("failed to enable the NDP proxy on %q: %w", iface, err)
+	}
+	for _, ip := range ips {
+		if err := netlink.NeighSet(ndpProxyNeigh(link, ip)); err != nil {
+			return fmt.Errorf("failed to add the NDP proxy entry of %s on %q: %w", ip, iface, err)
+		}
+	}
+	return nil
+}
+
+// RemoveNDPProxy removes the entries added by SetupNDPProxy.
+// The proxy_ndp sysctl of the interface is left enabled, as other containers may still rely on it.
+func RemoveNDPProxy(iface string, ips []net.IP) error {
+	link, err := netlink.LinkByName(iface)
+	if err != nil {
+		var linkNotFound netlink.LinkNotFoundError
+		if errors.As(err, &linkNotFound) {
+			return nil
+		}
+		return err
+	}
+	for _, ip := range ips {
+		if err := netlink.NeighDel(ndpProxyNeigh(link, ip)); err != nil && !errors.Is(err, syscall.ENOENT) {
+			return fmt.Errorf("failed to remove the NDP proxy entry of %s on %q: %w", ip, iface, err)
+		}
+	}
+	return nil
+}
+
+func ndpProxyNeigh(link netlink.Link, ip net.IP) *netlink.Neigh {
+	return &netlink.Neigh{
+		LinkIndex: link.Attrs().Index,
+		Family:    netlink.FAMILY_V6,
+		Flags:     netlink.NTF_PROXY,
+		IP:        ip,
+	}
+}
@@ -0,0 +1,40 @@
+//go:build !linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"errors"
+	"net"
+)
+
+func SetupMasquerade(_ string, _ []*net.IPNet) error {
+	return errors.New("masquerading only one of IPv4 and IPv6 is only supported on Linux")
+}
+
+func RemoveMasquerade(_ string, _ []*net.IPNet) error {
+	return nil
+}
+
+func SetupNDPProxy(_ string, _ []net.IP) error {
+	return errors.New("NDP proxy is only supported on Linux")
+}
+
+func RemoveNDPProxy(_ string, _ []net.IP) error {
+	return nil
+}
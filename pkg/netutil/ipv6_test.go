@@ -0,0 +1,82 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"net"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseIPMasqOptions(t *testing.T) {
+	ipMasq, ipMasq6, err := parseIPMasqOptions(map[string]string{}, true)
+	assert.NilError(t, err)
+	assert.Assert(t, ipMasq && ipMasq6)
+
+	// ip-masq6 defaults to ip-masq
+	ipMasq, ipMasq6, err = parseIPMasqOptions(map[string]string{"ip-masq": "false"}, true)
+	assert.NilError(t, err)
+	assert.Assert(t, !ipMasq && !ipMasq6)
+
+	ipMasq, ipMasq6, err = parseIPMasqOptions(map[string]string{IPMasq6Opt: "false"}, true)
+	assert.NilError(t, err)
+	assert.Assert(t, ipMasq && !ipMasq6)
+
+	_, _, err = parseIPMasqOptions(map[string]string{IPMasq6Opt: "false"}, false)
+	assert.ErrorContains(t, err, "requires --ipv6")
+	_, _, err = parseIPMasqOptions(map[string]string{IPMasq6Opt: "maybe"}, true)
+	assert.ErrorContains(t, err, "invalid syntax")
+}
+
+func TestParseNDPProxyOption(t *testing.T) {
+	iface, err := parseNDPProxyOption(map[string]string{}, true)
+	assert.NilError(t, err)
+	assert.Equal(t, iface, "")
+
+	iface, err = parseNDPProxyOption(map[string]string{NDPProxyOpt: "eth0"}, true)
+	assert.NilError(t, err)
+	assert.Equal(t, iface, "eth0")
+
+	_, err = parseNDPProxyOption(map[string]string{NDPProxyOpt: "eth0"}, false)
+	assert.ErrorContains(t, err, "requires --ipv6")
+	_, err = parseNDPProxyOption(map[string]string{NDPProxyOpt: ""}, true)
+	assert.ErrorContains(t, err, "requires an interface name")
+}
+
+func TestMasqueradeRules(t *testing.T) {
+	_, addr, err := net.ParseCIDR("fd00:1::2/64")
+	assert.NilError(t, err)
+	addr.IP = net.ParseIP("fd00:1::2")
+	rules := MasqueradeRules("default-abc", addr)
+	comment := []string{"-m", "comment", "--comment", "nerdctl masquerade id: default-abc"}
+	assert.DeepEqual(t, rules, [][]string{
+		append(append([]string{"-s", "fd00:1::2/128", "-d", "fd00:1::/64"}, comment...), "-j", "RETURN"),
+		append(append([]string{"-s", "fd00:1::2/128", "-d", "ff00::/8"}, comment...), "-j", "RETURN"),
+		append(append([]string{"-s", "fd00:1::2/128"}, comment...), "-j", "MASQUERADE"),
+	})
+
+	rules = MasqueradeRules("default-abc", &net.IPNet{IP: net.ParseIP("10.4.0.2"), Mask: net.CIDRMask(24, 32)})
+	assert.DeepEqual(t, rules[0][:4], []string{"-s", "10.4.0.2/32", "-d", "10.4.0.0/24"})
+	assert.Equal(t, rules[1][3], "224.0.0.0/4")
+}
+
+func TestIPMasqConfigEnabled(t *testing.T) {
+	c := &IPMasqConfig{IPv4: true, IPv6: false}
+	assert.Assert(t, c.Enabled(net.ParseIP("10.4.0.2")))
+	assert.Assert(t, !c.Enabled(net.ParseIP("fd00::2")))
+}
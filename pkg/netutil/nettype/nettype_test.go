@@ -53,6 +53,18 @@ func TestDetect(t *testing.T) {
 			names:    []string{"foo", "bar", "bridge"},
 			expected: CNI,
 		},
+		{
+			names:    []string{"pasta"},
+			expected: Pasta,
+		},
+		{
+			names:    []string{"pasta:mtu=1500"},
+			expected: Pasta,
+		},
+		{
+			names: []string{"pasta", "bridge"},
+			err:   "mixed network types",
+		},
 		{
 			names: []string{"none", "host"},
 			err:   "mixed network types",
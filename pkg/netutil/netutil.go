@@ -47,6 +47,8 @@ type CNIEnv struct {
 	Path        string
 	NetconfPath string
 	Namespace   string
+	// PortForwardingBackend is the backend of the "portmap" plugin of the networks created by this CNIEnv.
+	PortForwardingBackend string
 }
 
 type CNIEnvOpt func(e *CNIEnv) error
@@ -178,6 +180,15 @@ func WithDefaultNetwork(bridgeIP string) CNIEnvOpt {
 	}
 }
 
+// WithPortForwardingBackend sets the backend of the "portmap" plugin ("iptables" or "nftables").
+// It must precede WithDefaultNetwork for being applied to the default network.
+func WithPortForwardingBackend(backend string) CNIEnvOpt {
+	return func(e *CNIEnv) error {
+		e.PortForwardingBackend = backend
+		return nil
+	}
+}
+
 func WithNamespace(namespace string) CNIEnvOpt {
 	return func(e *CNIEnv) error {
 		err := fsEnsureRoot(e, namespace)
@@ -279,9 +290,17 @@ func (e *CNIEnv) usedSubnets() ([]*net.IPNet, error) {
 
 type NetworkConfig struct {
 	*libcni.NetworkConfigList
-	NerdctlID     *string
-	NerdctlLabels *map[string]string
-	File          string
+	NerdctlID        *string
+	NerdctlLabels    *map[string]string
+	NerdctlWireGuard *WireGuardConfig
+	NerdctlShaping   *Shaping
+	// NerdctlIngressPolicy is IngressPolicyAccept, IngressPolicyDeny, or empty (accept)
+	NerdctlIngressPolicy string
+	// NerdctlIPMasq is set when only one of IPv4 and IPv6 is masqueraded
+	NerdctlIPMasq *IPMasqConfig
+	// NerdctlNDPProxy is the host interface of the NDP proxy, or empty
+	NerdctlNDPProxy string
+	File            string
 }
 
 type cniNetworkConfig struct {
@@ -289,6 +308,11 @@ type cniNetworkConfig struct {
 	Name       string            `json:"name"`
 	ID         string            `json:"nerdctlID"`
 	Labels     map[string]string `json:"nerdctlLabels"`
+	WireGuard  *WireGuardConfig  `json:"nerdctlWireGuard,omitempty"`
+	Shaping    *Shaping          `json:"nerdctlShaping,omitempty"`
+	Ingress    string            `json:"nerdctlIngressPolicy,omitempty"`
+	IPMasq     *IPMasqConfig     `json:"nerdctlIPMasq,omitempty"`
+	NDPProxy   string            `json:"nerdctlNDPProxy,omitempty"`
 	Plugins    []CNIPlugin       `json:"plugins"`
 }
 
@@ -305,15 +329,58 @@ func (e *CNIEnv) CreateNetwork(opts types.NetworkCreateOptions) (*NetworkConfig,
 	if _, ok := netMap[opts.Name]; ok {
 		return nil, errdefs.ErrAlreadyExists
 	}
+	if err := ValidateIngressPolicy(opts.IngressPolicy); err != nil {
+		return nil, err
+	}
+	if opts.IngressPolicy == IngressPolicyDeny && opts.Driver != "bridge" {
+		return nil, fmt.Errorf("ingress policy %q is only supported for the \"bridge\" driver", opts.IngressPolicy)
+	}
 	ipam, err := e.generateIPAM(opts.IPAMDriver, opts.Subnets, opts.Gateway, opts.IPRange, opts.IPAMOptions, opts.IPv6)
 	if err != nil {
 		return nil, err
 	}
-	plugins, err := e.generateCNIPlugins(opts.Driver, opts.Name, ipam, opts.Options, opts.IPv6)
+	driverOpts := opts.Options
+	var wgOpts map[string]string
+	if opts.Driver == WireGuardDriver {
+		wgOpts, driverOpts = splitWireGuardOptions(opts.Options)
+	}
+	plugins, err := e.generateCNIPlugins(opts.Driver, opts.Name, ipam, driverOpts, opts.IPv6)
 	if err != nil {
 		return nil, err
 	}
-	netConf, err = e.generateNetworkConfig(opts.Name, opts.Labels, plugins)
+	var (
+		wg           *WireGuardConfig
+		wgPrivateKey string
+	)
+	if opts.Driver == WireGuardDriver {
+		wg, wgPrivateKey, err = newWireGuardConfig(opts.Name, wgOpts)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var shaping *Shaping
+	if s, ok := driverOpts[ShapingOpt]; ok {
+		// already validated by generateCNIPlugins
+		if shaping, err = ParseShaping(s); err != nil {
+			return nil, err
+		}
+	}
+	var ipMasq *IPMasqConfig
+	if opts.Driver == "bridge" || opts.Driver == WireGuardDriver {
+		// already validated by generateCNIPlugins
+		ipMasq4, ipMasq6, err := parseIPMasqOptions(driverOpts, opts.IPv6)
+		if err != nil {
+			return nil, err
+		}
+		if ipMasq4 != ipMasq6 {
+			ipMasq = &IPMasqConfig{IPv4: ipMasq4, IPv6: ipMasq6}
+		}
+	}
+	ndpProxy, err := parseNDPProxyOption(driverOpts, opts.IPv6)
+	if err != nil {
+		return nil, err
+	}
+	netConf, err = e.generateNetworkConfig(opts.Name, opts.Labels, plugins, wg, shaping, opts.IngressPolicy, ipMasq, ndpProxy)
 	if err != nil {
 		return nil, err
 	}
@@ -323,9 +390,27 @@ func (e *CNIEnv) CreateNetwork(opts types.NetworkCreateOptions) (*NetworkConfig,
 	if err != nil && !errdefs.IsAlreadyExists(err) {
 		return nil, err
 	}
+	if wg != nil && err == nil {
+		netConf.File = getConfigPathForNetworkName(e, opts.Name)
+		if err := setupWireGuardNetwork(netConf, wgPrivateKey); err != nil {
+			// Do not leave a half-created network behind, so that the creation can be retried.
+			// This removes the config file, the private key file, and the wireguard interface.
+			if rmErr := e.RemoveNetwork(netConf); rmErr != nil {
+				log.L.WithError(rmErr).Warnf("failed to clean up network %q", opts.Name)
+			}
+			return nil, err
+		}
+	}
 	return netConf, nil
 }
 
+func setupWireGuardNetwork(netConf *NetworkConfig, privateKey string) error {
+	if err := os.WriteFile(netConf.wireGuardKeyFile(), []byte(privateKey+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write the wireguard private key: %w", err)
+	}
+	return netConf.SetupWireGuard()
+}
+
 func (e *CNIEnv) RemoveNetwork(net *NetworkConfig) error {
 	return fsRemove(e, net)
 }
@@ -431,7 +516,7 @@ func (e *CNIEnv) createDefaultNetworkConfig(bridgeIP string) error {
 
 // generateNetworkConfig creates NetworkConfig.
 // generateNetworkConfig does not fill "File" field.
-func (e *CNIEnv) generateNetworkConfig(name string, labels []string, plugins []CNIPlugin) (*NetworkConfig, error) {
+func (e *CNIEnv) generateNetworkConfig(name string, labels []string, plugins []CNIPlugin, wg *WireGuardConfig, shaping *Shaping, ingressPolicy string, ipMasq *IPMasqConfig, ndpProxy string) (*NetworkConfig, error) {
 	if name == "" || len(plugins) == 0 {
 		return nil, errdefs.ErrInvalidArgument
 	}
@@ -449,6 +534,11 @@ func (e *CNIEnv) generateNetworkConfig(name string, labels []string, plugins []C
 		Name:       name,
 		ID:         id,
 		Labels:     labelsMap,
+		WireGuard:  wg,
+		Shaping:    shaping,
+		Ingress:    ingressPolicy,
+		IPMasq:     ipMasq,
+		NDPProxy:   ndpProxy,
 		Plugins:    plugins,
 	}
 
@@ -462,10 +552,15 @@ func (e *CNIEnv) generateNetworkConfig(name string, labels []string, plugins []C
 		return nil, err
 	}
 	return &NetworkConfig{
-		NetworkConfigList: l,
-		NerdctlID:         &id,
-		NerdctlLabels:     &labelsMap,
-		File:              "",
+		NetworkConfigList:    l,
+		NerdctlID:            &id,
+		NerdctlLabels:        &labelsMap,
+		NerdctlWireGuard:     wg,
+		NerdctlShaping:       shaping,
+		NerdctlIngressPolicy: ingressPolicy,
+		NerdctlIPMasq:        ipMasq,
+		NerdctlNDPProxy:      ndpProxy,
+		File:                 "",
 	}, nil
 }
 
@@ -493,10 +588,15 @@ func cniLoad(fileNames []string) (configList []*NetworkConfig, err error) {
 		}
 		id, nerdctlLabels := nerdctlIDLabels(netConfigList.Bytes)
 		configList = append(configList, &NetworkConfig{
-			NetworkConfigList: netConfigList,
-			NerdctlID:         id,
-			NerdctlLabels:     nerdctlLabels,
-			File:              fileName,
+			NetworkConfigList:    netConfigList,
+			NerdctlID:            id,
+			NerdctlLabels:        nerdctlLabels,
+			NerdctlWireGuard:     nerdctlWireGuard(netConfigList.Bytes),
+			NerdctlShaping:       nerdctlShaping(netConfigList.Bytes),
+			NerdctlIngressPolicy: nerdctlIngressPolicy(netConfigList.Bytes),
+			NerdctlIPMasq:        nerdctlIPMasq(netConfigList.Bytes),
+			NerdctlNDPProxy:      nerdctlNDPProxy(netConfigList.Bytes),
+			File:                 fileName,
 		})
 	}
 
@@ -521,12 +621,17 @@ func networkID(name string) string {
 }
 
 func (e *CNIEnv) parseSubnet(subnetStr string) (*net.IPNet, error) {
+	return e.parseSubnetFrom(StartingCIDR, subnetStr)
+}
+
+// parseSubnetFrom parses subnetStr, or allocates a free subnet starting from startingCIDR when subnetStr is empty.
+func (e *CNIEnv) parseSubnetFrom(startingCIDR, subnetStr string) (*net.IPNet, error) {
 	usedSubnets, err := e.usedSubnets()
 	if err != nil {
 		return nil, err
 	}
 	if subnetStr == "" {
-		_, defaultSubnet, _ := net.ParseCIDR(StartingCIDR)
+		_, defaultSubnet, _ := net.ParseCIDR(startingCIDR)
 		subnet, err := subnetutil.GetFreeSubnet(defaultSubnet, usedSubnets)
 		if err != nil {
 			return nil, err
@@ -17,8 +17,13 @@
 package netutil
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
 )
 
@@ -32,3 +37,34 @@ func TestDefaultNetworkCreation(t *testing.T) {
 	testDefaultNetworkCreation(t)
 	testDefaultNetworkCreationWithBridgeIP(t)
 }
+
+// Tests that a wireguard network is not left half-created when the interface cannot be set up.
+func TestCreateWireGuardNetworkCleanup(t *testing.T) {
+	if rootlessutil.IsRootless() {
+		t.Skip("the wireguard driver is not supported in rootless mode")
+	}
+	// Without the `wg` command in PATH, SetupWireGuard fails after the network config has been written.
+	t.Setenv("PATH", t.TempDir())
+	e, err := NewCNIEnv(fakeCNIPath(t), t.TempDir())
+	assert.NilError(t, err)
+
+	opts := types.NetworkCreateOptions{
+		Name:       "test-wireguard-cleanup",
+		Driver:     WireGuardDriver,
+		IPAMDriver: "default",
+		Subnets:    []string{""},
+		Options:    map[string]string{"wireguard.listen-port": "51821"},
+	}
+	for range 2 {
+		// The second attempt must fail the same way, not with "already exists".
+		_, err = e.CreateNetwork(opts)
+		assert.ErrorContains(t, err, "`wg` command")
+
+		confFile := getConfigPathForNetworkName(e, opts.Name)
+		_, err = os.Stat(confFile)
+		assert.Assert(t, os.IsNotExist(err), "%s must be removed", confFile)
+		keyFiles, err := filepath.Glob(filepath.Join(e.NetconfPath, "*.wg.key"))
+		assert.NilError(t, err)
+		assert.Equal(t, len(keyFiles), 0)
+	}
+}
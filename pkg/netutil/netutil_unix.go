@@ -25,7 +25,7 @@ import (
 	"net"
 	"os/exec"
 	"path/filepath"
-	"strconv"
+	"runtime"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
@@ -49,6 +49,10 @@ const (
 	// nerdctl assigns subnet address for the creation starting from `StartingCIDR`
 	// This prevents subnet address overlapping with `DefaultCIDR` used by the default network
 	StartingCIDR = "10.4.1.0/24"
+
+	// When creating an IPv6 network without passing in an IPv6 `--subnet`,
+	// nerdctl assigns a /64 out of this unique local address (ULA) prefix, starting from `StartingIPv6CIDR`.
+	StartingIPv6CIDR = "fd4e:6572:6463:1::/64"
 )
 
 func (n *NetworkConfig) subnets() []*net.IPNet {
@@ -79,6 +83,11 @@ func (n *NetworkConfig) subnets() []*net.IPNet {
 }
 
 func (n *NetworkConfig) clean() error {
+	if n.NerdctlWireGuard != nil {
+		if err := removeWireGuard(n); err != nil {
+			return err
+		}
+	}
 	// Remove the bridge network interface on the host.
 	if len(n.Plugins) > 0 && n.Plugins[0].Network.Type == "bridge" {
 		var bridge bridgeConfig
@@ -96,9 +105,12 @@ func (e *CNIEnv) generateCNIPlugins(driver string, name string, ipam map[string]
 		err     error
 	)
 	switch driver {
-	case "bridge":
+	case "bridge", WireGuardDriver:
+		if driver == WireGuardDriver && rootlessutil.IsRootless() {
+			return nil, fmt.Errorf("%q network driver is not supported in rootless mode", driver)
+		}
 		mtu := 0
-		iPMasq := true
+		var shaping *Shaping
 		for opt, v := range opts {
 			switch opt {
 			case "mtu", "com.docker.network.driver.mtu":
@@ -106,8 +118,10 @@ func (e *CNIEnv) generateCNIPlugins(driver string, name string, ipam map[string]
 				if err != nil {
 					return nil, err
 				}
-			case "ip-masq", "com.docker.network.bridge.enable_ip_masquerade":
-				iPMasq, err = strconv.ParseBool(v)
+			case "ip-masq", "com.docker.network.bridge.enable_ip_masquerade", IPMasq6Opt, NDPProxyOpt:
+				// parsed below
+			case ShapingOpt:
+				shaping, err = ParseShaping(v)
 				if err != nil {
 					return nil, err
 				}
@@ -115,6 +129,13 @@ func (e *CNIEnv) generateCNIPlugins(driver string, name string, ipam map[string]
 				return nil, fmt.Errorf("unsupported %q network option %q", driver, opt)
 			}
 		}
+		iPMasq, iPMasq6, err := parseIPMasqOptions(opts, ipv6)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := parseNDPProxyOption(opts, ipv6); err != nil {
+			return nil, err
+		}
 		var bridge *bridgeConfig
 		if name == DefaultNetworkName {
 			bridge = newBridgePlugin("nerdctl0")
@@ -124,12 +145,28 @@ func (e *CNIEnv) generateCNIPlugins(driver string, name string, ipam map[string]
 		bridge.MTU = mtu
 		bridge.IPAM = ipam
 		bridge.IsGW = true
-		bridge.IPMasq = iPMasq
+		// When only one of IPv4 and IPv6 is masqueraded, the rules are added by nerdctl (see IPMasqConfig)
+		bridge.IPMasq = iPMasq && iPMasq6
 		bridge.HairpinMode = true
 		if ipv6 {
 			bridge.Capabilities["ips"] = true
 		}
-		plugins = []CNIPlugin{bridge, newPortMapPlugin(), newFirewallPlugin(), newTuningPlugin()}
+		if runtime.GOOS == "freebsd" {
+			// The CNI plugins for FreeBSD do not include portmap, firewall, and tuning.
+			// The ports are published with pf by nerdctl (see pkg/portutil/pf).
+			plugins = []CNIPlugin{bridge}
+			break
+		}
+		plugins = []CNIPlugin{bridge, newPortMapPlugin(e.PortForwardingBackend), newFirewallPlugin(), newTuningPlugin()}
+		// The bandwidth plugin is only required when the network itself is shaped,
+		// otherwise it is added when available for `nerdctl run --network-bandwidth`.
+		if shaping != nil && shaping.Bandwidth > 0 {
+			plugins = append(plugins, newBandwidthPlugin(shaping))
+		} else if _, err := exec.LookPath(filepath.Join(e.Path, "bandwidth")); err == nil {
+			plugins = append(plugins, newBandwidthPlugin(nil))
+		} else {
+			log.L.Debugf("CNI plugin \"bandwidth\" is not installed in CNI_PATH (%q), `--network-bandwidth` will not be available for network %q", e.Path, name)
+		}
 		if name != DefaultNetworkName {
 			firewallPath := filepath.Join(e.Path, "firewall")
 			ok, err := firewallPluginGEQ110(firewallPath)
@@ -194,15 +231,30 @@ func (e *CNIEnv) generateIPAM(driver string, subnets []string, gatewayStr, ipRan
 		ipamConf.Routes = []IPAMRoute{
 			{Dst: "0.0.0.0/0"},
 		}
-		ranges, findIPv4, err := e.parseIPAMRanges(subnets, gatewayStr, ipRangeStr, ipv6)
+		ranges, findIPv4, findIPv6, err := e.parseIPAMRanges(subnets, gatewayStr, ipRangeStr, ipv6)
 		if err != nil {
 			return nil, err
 		}
 		ipamConf.Ranges = append(ipamConf.Ranges, ranges...)
 		if !findIPv4 {
-			ranges, _, _ = e.parseIPAMRanges([]string{""}, gatewayStr, ipRangeStr, ipv6)
+			ranges, _, _, _ = e.parseIPAMRanges([]string{""}, gatewayStr, ipRangeStr, ipv6)
 			ipamConf.Ranges = append(ipamConf.Ranges, ranges...)
 		}
+		if ipv6 {
+			if !findIPv6 {
+				// Allocate a unique local address (ULA) subnet, so that `--ipv6` works without `--subnet`.
+				subnet, err := e.parseSubnetFrom(StartingIPv6CIDR, "")
+				if err != nil {
+					return nil, err
+				}
+				ipamRange, err := parseIPAMRange(subnet, "", "")
+				if err != nil {
+					return nil, err
+				}
+				ipamConf.Ranges = append(ipamConf.Ranges, []IPAMRange{*ipamRange})
+			}
+			ipamConf.Routes = append(ipamConf.Routes, IPAMRoute{Dst: "::/0"})
+		}
 		ipamConfig = ipamConf
 	case "dhcp":
 		ipamConf := newDHCPIPAMConfig()
@@ -257,28 +309,29 @@ func (e *CNIEnv) generateIPAM(driver string, subnets []string, gatewayStr, ipRan
 	return ipam, nil
 }
 
-func (e *CNIEnv) parseIPAMRanges(subnets []string, gateway, ipRange string, ipv6 bool) ([][]IPAMRange, bool, error) {
-	findIPv4 := false
-	ranges := make([][]IPAMRange, 0, len(subnets))
+func (e *CNIEnv) parseIPAMRanges(subnets []string, gateway, ipRange string, ipv6 bool) (ranges [][]IPAMRange, findIPv4, findIPv6 bool, err error) {
+	ranges = make([][]IPAMRange, 0, len(subnets))
 	for i := range subnets {
 		subnet, err := e.parseSubnet(subnets[i])
 		if err != nil {
-			return nil, findIPv4, err
+			return nil, findIPv4, findIPv6, err
 		}
 		// if ipv6 flag is not set, subnets of ipv6 should be excluded
 		if !ipv6 && subnet.IP.To4() == nil {
 			continue
 		}
-		if !findIPv4 && subnet.IP.To4() != nil {
+		if subnet.IP.To4() != nil {
 			findIPv4 = true
+		} else {
+			findIPv6 = true
 		}
 		ipamRange, err := parseIPAMRange(subnet, gateway, ipRange)
 		if err != nil {
-			return nil, findIPv4, err
+			return nil, findIPv4, findIPv6, err
 		}
 		ranges = append(ranges, []IPAMRange{*ipamRange})
 	}
-	return ranges, findIPv4, nil
+	return ranges, findIPv4, findIPv6, nil
 }
 
 func firewallPluginGEQ110(firewallPath string) (bool, error) {
@@ -19,10 +19,16 @@
 package netutil
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/go-viper/mapstructure/v2"
 	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
 )
 
 func TestGuessFirewallPluginVersion(t *testing.T) {
@@ -69,3 +75,80 @@ func TestGuessFirewallPluginVersion(t *testing.T) {
 		}
 	}
 }
+
+func TestGenerateIPAMIPv6(t *testing.T) {
+	e, err := NewCNIEnv(t.TempDir(), t.TempDir())
+	assert.NilError(t, err)
+
+	decode := func(ipam map[string]interface{}) hostLocalIPAMConfig {
+		var conf hostLocalIPAMConfig
+		assert.NilError(t, mapstructure.Decode(ipam, &conf))
+		return conf
+	}
+
+	// Without an IPv6 subnet, a ULA /64 is allocated next to the IPv4 subnet.
+	ipam, err := e.generateIPAM("default", []string{"10.5.0.0/24"}, "", "", nil, true)
+	assert.NilError(t, err)
+	conf := decode(ipam)
+	assert.Equal(t, len(conf.Ranges), 2)
+	assert.Equal(t, conf.Ranges[0][0].Subnet, "10.5.0.0/24")
+	assert.Equal(t, conf.Ranges[1][0].Subnet, StartingIPv6CIDR)
+	assert.Equal(t, conf.Ranges[1][0].Gateway, "fd4e:6572:6463:1::1")
+	assert.DeepEqual(t, conf.Routes, []IPAMRoute{{Dst: "0.0.0.0/0"}, {Dst: "::/0"}})
+
+	// An explicit IPv6 subnet is kept as is.
+	ipam, err = e.generateIPAM("default", []string{"10.5.0.0/24", "fd00:1234::/64"}, "", "", nil, true)
+	assert.NilError(t, err)
+	conf = decode(ipam)
+	assert.Equal(t, len(conf.Ranges), 2)
+	assert.Equal(t, conf.Ranges[1][0].Subnet, "fd00:1234::/64")
+
+	// Without --ipv6, no IPv6 range nor route is added.
+	ipam, err = e.generateIPAM("default", []string{"10.5.0.0/24"}, "", "", nil, false)
+	assert.NilError(t, err)
+	conf = decode(ipam)
+	assert.Equal(t, len(conf.Ranges), 1)
+	assert.DeepEqual(t, conf.Routes, []IPAMRoute{{Dst: "0.0.0.0/0"}})
+}
+
+// fakeCNIPath returns a CNI_PATH with the plugins that are checked when loading a bridge network.
+func fakeCNIPath(t *testing.T) string {
+	cniPath := t.TempDir()
+	for _, plugin := range []string{"bridge", "portmap", "firewall", "tuning"} {
+		assert.NilError(t, os.WriteFile(filepath.Join(cniPath, plugin), []byte("#!/bin/sh\n"), 0o755))
+	}
+	return cniPath
+}
+
+func TestCreateNetworkIPv6Options(t *testing.T) {
+	e, err := NewCNIEnv(fakeCNIPath(t), t.TempDir())
+	assert.NilError(t, err)
+
+	_, err = e.CreateNetwork(types.NetworkCreateOptions{
+		Name:       "test-ipv6-options",
+		Driver:     "bridge",
+		IPAMDriver: "default",
+		Subnets:    []string{""},
+		IPv6:       true,
+		Options:    map[string]string{IPMasq6Opt: "false", NDPProxyOpt: "eth0"},
+	})
+	assert.NilError(t, err)
+
+	netw, err := e.NetworkByNameOrID("test-ipv6-options")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, netw.NerdctlIPMasq, &IPMasqConfig{IPv4: true, IPv6: false})
+	assert.Equal(t, netw.NerdctlNDPProxy, "eth0")
+	// The bridge plugin does not masquerade, as nerdctl masquerades IPv4 only
+	var bridge bridgeConfig
+	assert.NilError(t, json.Unmarshal(netw.Plugins[0].Bytes, &bridge))
+	assert.Equal(t, bridge.IPMasq, false)
+
+	_, err = e.CreateNetwork(types.NetworkCreateOptions{
+		Name:       "test-ipv4-only",
+		Driver:     "bridge",
+		IPAMDriver: "default",
+		Subnets:    []string{""},
+		Options:    map[string]string{NDPProxyOpt: "eth0"},
+	})
+	assert.ErrorContains(t, err, "requires --ipv6")
+}
@@ -0,0 +1,154 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/containerd/go-cni"
+)
+
+const (
+	// PastaNetworkName is the name of the network mode connecting the container to the host network with pasta(1).
+	// Options can be appended as "pasta:KEY=VALUE[,KEY=VALUE...]".
+	PastaNetworkName = "pasta"
+	// DefaultPastaMTU is the default MTU of pasta.
+	DefaultPastaMTU = 65520
+)
+
+// PastaOptions is the options of the "pasta" network mode.
+type PastaOptions struct {
+	MTU int
+}
+
+// ParsePastaNetwork parses the network name "pasta[:KEY=VALUE[,KEY=VALUE...]]".
+// The supported option is "mtu".
+func ParsePastaNetwork(network string) (*PastaOptions, error) {
+	name, optsStr, _ := strings.Cut(network, ":")
+	if name != PastaNetworkName {
+		return nil, fmt.Errorf("not a pasta network: %q", network)
+	}
+	o := &PastaOptions{MTU: DefaultPastaMTU}
+	if optsStr == "" {
+		return o, nil
+	}
+	for _, kv := range strings.Split(optsStr, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid pasta option %q, expected KEY=VALUE", kv)
+		}
+		switch k {
+		case "mtu":
+			mtu, err := strconv.Atoi(v)
+			// 68 is the minimum MTU of IPv4, 65520 is the maximum MTU of pasta
+			if err != nil || mtu < 68 || mtu > DefaultPastaMTU {
+				return nil, fmt.Errorf("invalid pasta mtu %q", v)
+			}
+			o.MTU = mtu
+		default:
+			return nil, fmt.Errorf("unknown pasta option %q", k)
+		}
+	}
+	return o, nil
+}
+
+// PastaArgs returns the arguments of pasta(1) for connecting the network namespace to the host network,
+// with the port forwarding of the published ports.
+func PastaArgs(o *PastaOptions, netnsPath, pidFile string, ports []cni.PortMapping) ([]string, error) {
+	args := []string{"--config-net", "--quiet", "--pid", pidFile, "--mtu", strconv.Itoa(o.MTU)}
+	var tcp, udp []string
+	for _, p := range ports {
+		spec := fmt.Sprintf("%d:%d", p.HostPort, p.ContainerPort)
+		if ip := net.ParseIP(p.HostIP); ip != nil && !ip.IsUnspecified() {
+			spec = p.HostIP + "/" + spec
+		}
+		switch p.Protocol {
+		case "tcp", "":
+			tcp = append(tcp, spec)
+		case "udp":
+			udp = append(udp, spec)
+		default:
+			return nil, fmt.Errorf("pasta does not support publishing %s ports", p.Protocol)
+		}
+	}
+	if len(tcp) == 0 {
+		tcp = []string{"none"}
+	}
+	if len(udp) == 0 {
+		udp = []string{"none"}
+	}
+	for _, spec := range tcp {
+		args = append(args, "-t", spec)
+	}
+	for _, spec := range udp {
+		args = append(args, "-u", spec)
+	}
+	// Do not forward the ports of the host loopback into the container
+	args = append(args, "-T", "none", "-U", "none", "--netns", netnsPath)
+	return args, nil
+}
+
+// StartPasta runs pasta(1), which daemonizes itself after setting up the network namespace.
+func StartPasta(o *PastaOptions, netnsPath, pidFile string, ports []cni.PortMapping) error {
+	pasta, err := exec.LookPath("pasta")
+	if err != nil {
+		return fmt.Errorf("pasta needs to be installed for --network=pasta (e.g., the \"passt\" package): %w", err)
+	}
+	args, err := PastaArgs(o, netnsPath, pidFile, ports)
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(pasta, args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run %v: %w (stderr=%q)", cmd.Args, err, stderr.String())
+	}
+	return nil
+}
+
+// StopPasta stops pasta(1) started by StartPasta.
+func StopPasta(pidFile string) error {
+	b, err := os.ReadFile(pidFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer os.Remove(pidFile)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		return fmt.Errorf("invalid pid file %q: %w", pidFile, err)
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if err := proc.Signal(syscall.SIGTERM); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("failed to stop pasta (pid=%d): %w", pid, err)
+	}
+	return nil
+}
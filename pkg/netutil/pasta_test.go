@@ -0,0 +1,68 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"testing"
+
+	"github.com/containerd/go-cni"
+	"gotest.tools/v3/assert"
+)
+
+func TestParsePastaNetwork(t *testing.T) {
+	o, err := ParsePastaNetwork("pasta")
+	assert.NilError(t, err)
+	assert.Equal(t, o.MTU, DefaultPastaMTU)
+
+	o, err = ParsePastaNetwork("pasta:mtu=1500")
+	assert.NilError(t, err)
+	assert.Equal(t, o.MTU, 1500)
+
+	_, err = ParsePastaNetwork("pasta:mtu=65536")
+	assert.ErrorContains(t, err, "invalid pasta mtu")
+	_, err = ParsePastaNetwork("pasta:ipv6")
+	assert.ErrorContains(t, err, "expected KEY=VALUE")
+	_, err = ParsePastaNetwork("pasta:foo=bar")
+	assert.ErrorContains(t, err, "unknown pasta option")
+	_, err = ParsePastaNetwork("bridge")
+	assert.ErrorContains(t, err, "not a pasta network")
+}
+
+func TestPastaArgs(t *testing.T) {
+	o := &PastaOptions{MTU: 1500}
+	args, err := PastaArgs(o, "/proc/42/ns/net", "/run/pasta.pid", nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{
+		"--config-net", "--quiet", "--pid", "/run/pasta.pid", "--mtu", "1500",
+		"-t", "none", "-u", "none", "-T", "none", "-U", "none", "--netns", "/proc/42/ns/net",
+	})
+
+	args, err = PastaArgs(o, "/proc/42/ns/net", "/run/pasta.pid", []cni.PortMapping{
+		{HostPort: 8080, ContainerPort: 80, Protocol: "tcp", HostIP: "0.0.0.0"},
+		{HostPort: 8443, ContainerPort: 443, Protocol: "tcp", HostIP: "127.0.0.1"},
+		{HostPort: 53, ContainerPort: 53, Protocol: "udp", HostIP: "::1"},
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{
+		"--config-net", "--quiet", "--pid", "/run/pasta.pid", "--mtu", "1500",
+		"-t", "8080:80", "-t", "127.0.0.1/8443:443", "-u", "::1/53:53",
+		"-T", "none", "-U", "none", "--netns", "/proc/42/ns/net",
+	})
+
+	_, err = PastaArgs(o, "/proc/42/ns/net", "/run/pasta.pid", []cni.PortMapping{{HostPort: 80, ContainerPort: 80, Protocol: "sctp"}})
+	assert.ErrorContains(t, err, "does not support")
+}
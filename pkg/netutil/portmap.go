@@ -0,0 +1,81 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/containernetworking/cni/libcni"
+	types100 "github.com/containernetworking/cni/pkg/types/100"
+
+	"github.com/containerd/go-cni"
+)
+
+// UpdatePortMappings replaces the port mappings of a running container attached to the network,
+// by only re-running the "portmap" plugin of the network with the previous result of the container.
+// cniID, netnsPath and ifName must match the ones used when the container was set up.
+func (e *CNIEnv) UpdatePortMappings(ctx context.Context, n *NetworkConfig, cniID, netnsPath, ifName string, prevResult *types100.Result, oldPorts, newPorts []cni.PortMapping) error {
+	var portMap *libcni.PluginConfig
+	for _, p := range n.Plugins {
+		if p.Network.Type == "portmap" {
+			portMap = p
+			break
+		}
+	}
+	if portMap == nil {
+		return fmt.Errorf("network %q does not support publishing ports (no \"portmap\" plugin)", n.Name)
+	}
+	conf, err := libcni.InjectConf(portMap, map[string]interface{}{
+		"name":       n.Name,
+		"cniVersion": n.CNIVersion,
+		"prevResult": prevResult,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Use a throwaway cache, so that the cached result of the whole network list
+	// (used by cni.Remove when the container stops) is left untouched.
+	cacheDir, err := os.MkdirTemp("", "nerdctl-portmap-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(cacheDir)
+	cniConfig := libcni.NewCNIConfigWithCacheDir([]string{e.Path}, cacheDir, nil)
+
+	rt := &libcni.RuntimeConf{
+		ContainerID: cniID,
+		NetNS:       netnsPath,
+		IfName:      ifName,
+	}
+	// The plugin does nothing on DEL without port mappings.
+	if len(oldPorts) > 0 {
+		rt.CapabilityArgs = map[string]interface{}{"portMappings": oldPorts}
+		if err := cniConfig.DelNetwork(ctx, conf, rt); err != nil {
+			return fmt.Errorf("failed to remove the port mappings of network %q: %w", n.Name, err)
+		}
+	}
+	if len(newPorts) > 0 {
+		rt.CapabilityArgs = map[string]interface{}{"portMappings": newPorts}
+		if _, err := cniConfig.AddNetwork(ctx, conf, rt); err != nil {
+			return fmt.Errorf("failed to add the port mappings of network %q: %w", n.Name, err)
+		}
+	}
+	return nil
+}
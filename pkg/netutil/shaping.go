@@ -0,0 +1,161 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containerd/go-cni"
+)
+
+// ShapingOpt is the network option (`nerdctl network create -o shaping=...`) for shaping the traffic
+// of all the containers of a network.
+const ShapingOpt = "shaping"
+
+// Shaping describes the traffic shaping of a network, or of a container.
+// The bandwidth is limited with the CNI "bandwidth" plugin, the delay is added with the netem qdisc.
+type Shaping struct {
+	// Bandwidth limits the ingress and the egress rate, in bits per second.
+	Bandwidth uint64 `json:"bandwidth,omitempty"`
+	// Delay is added to the packets sent by the container.
+	Delay time.Duration `json:"delay,omitempty"`
+}
+
+// ParseShaping parses the value of the "shaping" network option, e.g., "bandwidth=10Mbit,delay=50ms".
+func ParseShaping(s string) (*Shaping, error) {
+	shaping := &Shaping{}
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(kv), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid shaping option %q, expected KEY=VALUE", kv)
+		}
+		var err error
+		switch k {
+		case "bandwidth":
+			shaping.Bandwidth, err = ParseBandwidth(v)
+		case "delay":
+			shaping.Delay, err = ParseDelay(v)
+		default:
+			return nil, fmt.Errorf("unknown shaping option %q, expected \"bandwidth\" or \"delay\"", k)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return shaping, nil
+}
+
+// NewShaping parses the `--network-bandwidth` and `--network-delay` flags of a container.
+// It returns nil when both are empty.
+func NewShaping(bandwidth, delay string) (*Shaping, error) {
+	if bandwidth == "" && delay == "" {
+		return nil, nil
+	}
+	shaping := &Shaping{}
+	var err error
+	if bandwidth != "" {
+		if shaping.Bandwidth, err = ParseBandwidth(bandwidth); err != nil {
+			return nil, err
+		}
+	}
+	if delay != "" {
+		if shaping.Delay, err = ParseDelay(delay); err != nil {
+			return nil, err
+		}
+	}
+	return shaping, nil
+}
+
+// bandwidthUnits are the rate units of tc(8), see `man tc`.
+var bandwidthUnits = []struct {
+	suffix     string
+	multiplier uint64
+}{
+	// longer suffixes first
+	{"kibit", 1 << 10}, {"mibit", 1 << 20}, {"gibit", 1 << 30}, {"tibit", 1 << 40},
+	{"kibps", 8 << 10}, {"mibps", 8 << 20}, {"gibps", 8 << 30}, {"tibps", 8 << 40},
+	{"kbit", 1e3}, {"mbit", 1e6}, {"gbit", 1e9}, {"tbit", 1e12},
+	{"kbps", 8e3}, {"mbps", 8e6}, {"gbps", 8e9}, {"tbps", 8e12},
+	{"bit", 1}, {"bps", 8},
+}
+
+// ParseBandwidth parses a rate with the units of tc(8), e.g., "10Mbit" or "1mbps" (bytes), into bits per second.
+// A bare number is in bits per second.
+func ParseBandwidth(s string) (uint64, error) {
+	num, multiplier := strings.ToLower(strings.TrimSpace(s)), uint64(1)
+	for _, u := range bandwidthUnits {
+		if strings.HasSuffix(num, u.suffix) {
+			num, multiplier = strings.TrimSuffix(num, u.suffix), u.multiplier
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid bandwidth %q, expected a positive rate like \"10Mbit\"", s)
+	}
+	return uint64(v * float64(multiplier)), nil
+}
+
+// ParseDelay parses a delay like "50ms".
+func ParseDelay(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid delay %q, expected a positive duration like \"50ms\"", s)
+	}
+	return d, nil
+}
+
+// minBandwidthBurst is the minimum burst (in bits) of the token bucket, so that jumbo frames still pass.
+const minBandwidthBurst = 8 * 64 * 1024
+
+// BandWidth returns the runtime config of the "bandwidth" plugin.
+// The burst allows 100ms of traffic at full rate.
+func (s *Shaping) BandWidth() cni.BandWidth {
+	burst := max(s.Bandwidth/10, minBandwidthBurst)
+	return cni.BandWidth{
+		IngressRate:  s.Bandwidth,
+		IngressBurst: burst,
+		EgressRate:   s.Bandwidth,
+		EgressBurst:  burst,
+	}
+}
+
+// ParseShapingLabel parses the JSON-marshalled Shaping of the labels.NetworkShaping label.
+func ParseShapingLabel(s string) (*Shaping, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var shaping Shaping
+	if err := json.Unmarshal([]byte(s), &shaping); err != nil {
+		return nil, fmt.Errorf("failed to parse network shaping %q: %w", s, err)
+	}
+	return &shaping, nil
+}
+
+func nerdctlShaping(b []byte) *Shaping {
+	var c struct {
+		Shaping *Shaping `json:"nerdctlShaping,omitempty"`
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil
+	}
+	return c.Shaping
+}
@@ -0,0 +1,46 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+	"github.com/vishvananda/netlink"
+)
+
+// SetupDelay adds a netem qdisc delaying the packets sent from the interface of the network namespace.
+func SetupDelay(netnsPath, ifName string, delay time.Duration) error {
+	return ns.WithNetNSPath(netnsPath, func(ns.NetNS) error {
+		link, err := netlink.LinkByName(ifName)
+		if err != nil {
+			return fmt.Errorf("failed to find interface %q: %w", ifName, err)
+		}
+		qdisc := netlink.NewNetem(netlink.QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    netlink.MakeHandle(1, 0),
+			Parent:    netlink.HANDLE_ROOT,
+		}, netlink.NetemQdiscAttrs{
+			Latency: uint32(delay.Microseconds()),
+		})
+		if err := netlink.QdiscReplace(qdisc); err != nil {
+			return fmt.Errorf("failed to add netem qdisc to interface %q: %w", ifName, err)
+		}
+		return nil
+	})
+}
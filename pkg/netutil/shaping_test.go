@@ -0,0 +1,73 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseBandwidth(t *testing.T) {
+	for s, want := range map[string]uint64{
+		"10Mbit":  10_000_000,
+		"10mbit":  10_000_000,
+		"1.5kbit": 1_500,
+		"1mbps":   8_000_000,
+		"1Mibit":  1 << 20,
+		"512":     512,
+		"100bit":  100,
+	} {
+		got, err := ParseBandwidth(s)
+		assert.NilError(t, err, s)
+		assert.Equal(t, got, want, s)
+	}
+	for _, s := range []string{"", "0", "-1Mbit", "10Mb", "fast"} {
+		_, err := ParseBandwidth(s)
+		assert.ErrorContains(t, err, "invalid bandwidth", s)
+	}
+}
+
+func TestParseShaping(t *testing.T) {
+	shaping, err := ParseShaping("bandwidth=10Mbit,delay=50ms")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, shaping, &Shaping{Bandwidth: 10_000_000, Delay: 50 * time.Millisecond})
+
+	_, err = ParseShaping("delay=-1s")
+	assert.ErrorContains(t, err, "invalid delay")
+	_, err = ParseShaping("loss=1%")
+	assert.ErrorContains(t, err, "unknown shaping option")
+	_, err = ParseShaping("10Mbit")
+	assert.ErrorContains(t, err, "expected KEY=VALUE")
+}
+
+func TestNewShaping(t *testing.T) {
+	shaping, err := NewShaping("", "")
+	assert.NilError(t, err)
+	assert.Assert(t, shaping == nil)
+
+	shaping, err = NewShaping("", "20ms")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, shaping, &Shaping{Delay: 20 * time.Millisecond})
+
+	bw := (&Shaping{Bandwidth: 100_000_000}).BandWidth()
+	assert.Equal(t, bw.IngressRate, uint64(100_000_000))
+	assert.Equal(t, bw.EgressBurst, uint64(10_000_000))
+	bw = (&Shaping{Bandwidth: 1000}).BandWidth()
+	assert.Equal(t, bw.IngressBurst, uint64(minBandwidthBurst))
+}
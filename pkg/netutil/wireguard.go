@@ -0,0 +1,330 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"bufio"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// WireGuardDriver is the name of the network driver that connects a bridge network
+	// to the bridge networks of other hosts through an encrypted WireGuard tunnel.
+	WireGuardDriver = "wireguard"
+
+	// wireGuardOptPrefix is the prefix of the `--opt` keys consumed by the wireguard driver.
+	wireGuardOptPrefix = "wireguard."
+
+	// DefaultWireGuardPort is the default UDP port of the WireGuard interface.
+	DefaultWireGuardPort = 51820
+
+	// defaultWireGuardMTU leaves room for the WireGuard overhead over a 1500 bytes link.
+	defaultWireGuardMTU = 1420
+)
+
+// WireGuardConfig is stored as the "nerdctlWireGuard" field of the network config list.
+// The private key is never stored there, see NetworkConfig.wireGuardKeyFile.
+type WireGuardConfig struct {
+	Interface  string          `json:"interface"`
+	ListenPort int             `json:"listenPort"`
+	Address    string          `json:"address,omitempty"`
+	PublicKey  string          `json:"publicKey"`
+	PeersFile  string          `json:"peersFile,omitempty"`
+	Peers      []WireGuardPeer `json:"peers,omitempty"`
+}
+
+// WireGuardPeer describes a remote host participating in a wireguard network.
+type WireGuardPeer struct {
+	PublicKey           string   `json:"publicKey"`
+	Endpoint            string   `json:"endpoint,omitempty"`
+	AllowedIPs          []string `json:"allowedIPs"`
+	PersistentKeepalive int      `json:"persistentKeepalive,omitempty"`
+}
+
+// splitWireGuardOptions separates the "wireguard.*" options from the options of the underlying bridge.
+func splitWireGuardOptions(opts map[string]string) (wgOpts, rest map[string]string) {
+	wgOpts = make(map[string]string)
+	rest = make(map[string]string)
+	for k, v := range opts {
+		if strings.HasPrefix(k, wireGuardOptPrefix) {
+			wgOpts[strings.TrimPrefix(k, wireGuardOptPrefix)] = v
+		} else {
+			rest[k] = v
+		}
+	}
+	if _, ok := rest["mtu"]; !ok {
+		if _, ok := rest["com.docker.network.driver.mtu"]; !ok {
+			rest["mtu"] = strconv.Itoa(defaultWireGuardMTU)
+		}
+	}
+	return wgOpts, rest
+}
+
+// newWireGuardConfig parses the "wireguard.*" options and generates a new key pair.
+// The returned private key must be persisted by the caller.
+func newWireGuardConfig(name string, opts map[string]string) (*WireGuardConfig, string, error) {
+	wg := &WireGuardConfig{
+		Interface:  "wg-" + networkID(name)[:12],
+		ListenPort: DefaultWireGuardPort,
+	}
+	for opt, v := range opts {
+		switch opt {
+		case "listen-port":
+			port, err := strconv.Atoi(v)
+			if err != nil || port <= 0 || port > 65535 {
+				return nil, "", fmt.Errorf("invalid wireguard listen port %q", v)
+			}
+			wg.ListenPort = port
+		case "address":
+			if _, _, err := net.ParseCIDR(v); err != nil {
+				return nil, "", fmt.Errorf("invalid wireguard address %q, expected CIDR notation: %w", v, err)
+			}
+			wg.Address = v
+		case "peers":
+			peers, err := ParseWireGuardPeers(v)
+			if err != nil {
+				return nil, "", err
+			}
+			wg.Peers = peers
+		case "peers-file":
+			if _, err := LoadWireGuardPeersFile(v); err != nil {
+				return nil, "", err
+			}
+			wg.PeersFile = v
+		default:
+			return nil, "", fmt.Errorf("unsupported %q network option %q", WireGuardDriver, wireGuardOptPrefix+opt)
+		}
+	}
+	privateKey, publicKey, err := generateWireGuardKey()
+	if err != nil {
+		return nil, "", err
+	}
+	wg.PublicKey = publicKey
+	return wg, privateKey, nil
+}
+
+// generateWireGuardKey returns a base64 encoded Curve25519 key pair, like `wg genkey | wg pubkey`.
+func generateWireGuardKey() (privateKey, publicKey string, err error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate wireguard key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key.Bytes()), base64.StdEncoding.EncodeToString(key.PublicKey().Bytes()), nil
+}
+
+func validateWireGuardKey(key string) error {
+	b, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(b) != 32 {
+		return fmt.Errorf("invalid wireguard public key %q", key)
+	}
+	return nil
+}
+
+// ParseWireGuardPeers parses peers in the form of "PUBKEY,ENDPOINT,ALLOWEDIP[,ALLOWEDIP...][;PUBKEY,...]".
+// ENDPOINT may be left empty for peers that only connect to this host.
+func ParseWireGuardPeers(s string) ([]WireGuardPeer, error) {
+	var peers []WireGuardPeer
+	for _, p := range strings.Split(s, ";") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		fields := strings.Split(p, ",")
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("invalid wireguard peer %q, expected PUBKEY,ENDPOINT,ALLOWEDIP[,ALLOWEDIP...]", p)
+		}
+		peer := WireGuardPeer{
+			PublicKey:  strings.TrimSpace(fields[0]),
+			Endpoint:   strings.TrimSpace(fields[1]),
+			AllowedIPs: strings.Fields(strings.Join(fields[2:], " ")),
+		}
+		if err := peer.validate(); err != nil {
+			return nil, err
+		}
+		peers = append(peers, peer)
+	}
+	return peers, nil
+}
+
+// LoadWireGuardPeersFile loads the [Peer] sections of a wg(8) style configuration file.
+// [Interface] sections and unknown keys are ignored, so that the same file can be shared
+// across all the hosts participating in a network.
+func LoadWireGuardPeersFile(path string) ([]WireGuardPeer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open wireguard peers file: %w", err)
+	}
+	defer f.Close()
+
+	var (
+		peers  []WireGuardPeer
+		cur    *WireGuardPeer
+		lineNo int
+	)
+	flush := func() error {
+		if cur == nil {
+			return nil
+		}
+		if err := cur.validate(); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		peers = append(peers, *cur)
+		cur = nil
+		return nil
+	}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			if strings.EqualFold(line, "[Peer]") {
+				cur = &WireGuardPeer{}
+			}
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected KEY = VALUE, got %q", path, lineNo, line)
+		}
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		switch strings.ToLower(k) {
+		case "publickey":
+			cur.PublicKey = v
+		case "endpoint":
+			cur.Endpoint = v
+		case "allowedips":
+			for _, ip := range strings.Split(v, ",") {
+				if ip = strings.TrimSpace(ip); ip != "" {
+					cur.AllowedIPs = append(cur.AllowedIPs, ip)
+				}
+			}
+		case "persistentkeepalive":
+			ka, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid PersistentKeepalive %q", path, lineNo, v)
+			}
+			cur.PersistentKeepalive = ka
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return peers, nil
+}
+
+func (p *WireGuardPeer) validate() error {
+	if err := validateWireGuardKey(p.PublicKey); err != nil {
+		return err
+	}
+	if p.Endpoint != "" {
+		if _, _, err := net.SplitHostPort(p.Endpoint); err != nil {
+			return fmt.Errorf("invalid endpoint %q of wireguard peer %s: %w", p.Endpoint, p.PublicKey, err)
+		}
+	}
+	if len(p.AllowedIPs) == 0 {
+		return fmt.Errorf("wireguard peer %s has no allowed IPs", p.PublicKey)
+	}
+	for _, ip := range p.AllowedIPs {
+		if _, _, err := net.ParseCIDR(ip); err != nil {
+			return fmt.Errorf("invalid allowed IP %q of wireguard peer %s: %w", ip, p.PublicKey, err)
+		}
+	}
+	return nil
+}
+
+// peers returns the flag-provided peers merged with the ones of the peers file.
+// The local host is skipped, so that a single peers file can list every host.
+func (wg *WireGuardConfig) peers() ([]WireGuardPeer, error) {
+	peers := append([]WireGuardPeer{}, wg.Peers...)
+	if wg.PeersFile != "" {
+		filePeers, err := LoadWireGuardPeersFile(wg.PeersFile)
+		if err != nil {
+			return nil, err
+		}
+		peers = append(peers, filePeers...)
+	}
+	res := make([]WireGuardPeer, 0, len(peers))
+	seen := make(map[string]struct{}, len(peers))
+	for _, p := range peers {
+		if p.PublicKey == wg.PublicKey {
+			continue
+		}
+		if _, ok := seen[p.PublicKey]; ok {
+			continue
+		}
+		seen[p.PublicKey] = struct{}{}
+		res = append(res, p)
+	}
+	return res, nil
+}
+
+// setArgs returns the arguments of `wg set` for configuring the interface.
+func (wg *WireGuardConfig) setArgs(privateKeyFile string, peers []WireGuardPeer) []string {
+	args := []string{"set", wg.Interface,
+		"listen-port", strconv.Itoa(wg.ListenPort),
+		"private-key", privateKeyFile,
+	}
+	for _, p := range peers {
+		args = append(args, "peer", p.PublicKey)
+		if p.Endpoint != "" {
+			args = append(args, "endpoint", p.Endpoint)
+		}
+		if p.PersistentKeepalive > 0 {
+			args = append(args, "persistent-keepalive", strconv.Itoa(p.PersistentKeepalive))
+		}
+		args = append(args, "allowed-ips", strings.Join(p.AllowedIPs, ","))
+	}
+	return args
+}
+
+// wireGuardKeyFile returns the path of the private key of the network.
+func (n *NetworkConfig) wireGuardKeyFile() string {
+	return strings.TrimSuffix(n.File, ".conflist") + ".wg.key"
+}
+
+func nerdctlWireGuard(b []byte) *WireGuardConfig {
+	var c struct {
+		WireGuard *WireGuardConfig `json:"nerdctlWireGuard,omitempty"`
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil
+	}
+	return c.WireGuard
+}
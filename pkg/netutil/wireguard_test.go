@@ -0,0 +1,97 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+const (
+	testWGKey1 = "xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg="
+	testWGKey2 = "TrMvSoP4jYQlY6RIzBgbssQqY3vxI2Pi+y71lOWWXX0="
+)
+
+func TestParseWireGuardPeers(t *testing.T) {
+	peers, err := ParseWireGuardPeers(testWGKey1 + ",203.0.113.1:51820,10.4.2.0/24,10.4.3.0/24;" + testWGKey2 + ",,10.4.4.0/24")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, peers, []WireGuardPeer{
+		{PublicKey: testWGKey1, Endpoint: "203.0.113.1:51820", AllowedIPs: []string{"10.4.2.0/24", "10.4.3.0/24"}},
+		{PublicKey: testWGKey2, AllowedIPs: []string{"10.4.4.0/24"}},
+	})
+
+	_, err = ParseWireGuardPeers(testWGKey1 + ",203.0.113.1:51820")
+	assert.ErrorContains(t, err, "expected PUBKEY,ENDPOINT,ALLOWEDIP")
+	_, err = ParseWireGuardPeers("foo,203.0.113.1:51820,10.4.2.0/24")
+	assert.ErrorContains(t, err, "invalid wireguard public key")
+	_, err = ParseWireGuardPeers(testWGKey1 + ",203.0.113.1,10.4.2.0/24")
+	assert.ErrorContains(t, err, "invalid endpoint")
+	_, err = ParseWireGuardPeers(testWGKey1 + ",203.0.113.1:51820,10.4.2.0")
+	assert.ErrorContains(t, err, "invalid allowed IP")
+}
+
+func TestLoadWireGuardPeersFile(t *testing.T) {
+	const content = `
+[Interface]
+PrivateKey = ignored
+
+# host1
+[Peer]
+PublicKey = ` + testWGKey1 + `
+Endpoint = 203.0.113.1:51820
+AllowedIPs = 10.4.2.0/24, 10.4.3.0/24
+
+[Peer]
+PublicKey = ` + testWGKey2 + `
+AllowedIPs = 10.4.4.0/24
+PersistentKeepalive = 25
+`
+	path := filepath.Join(t.TempDir(), "peers.conf")
+	assert.NilError(t, os.WriteFile(path, []byte(content), 0o644))
+	peers, err := LoadWireGuardPeersFile(path)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, peers, []WireGuardPeer{
+		{PublicKey: testWGKey1, Endpoint: "203.0.113.1:51820", AllowedIPs: []string{"10.4.2.0/24", "10.4.3.0/24"}},
+		{PublicKey: testWGKey2, AllowedIPs: []string{"10.4.4.0/24"}, PersistentKeepalive: 25},
+	})
+
+	// The local host is skipped, so that the same file can be distributed to every host.
+	wg := &WireGuardConfig{Interface: "wg-test", ListenPort: DefaultWireGuardPort, PublicKey: testWGKey1, PeersFile: path}
+	merged, err := wg.peers()
+	assert.NilError(t, err)
+	assert.Equal(t, len(merged), 1)
+	assert.Equal(t, merged[0].PublicKey, testWGKey2)
+	assert.DeepEqual(t, wg.setArgs("/key", merged), []string{
+		"set", "wg-test", "listen-port", "51820", "private-key", "/key",
+		"peer", testWGKey2, "persistent-keepalive", "25", "allowed-ips", "10.4.4.0/24",
+	})
+}
+
+func TestNewWireGuardConfig(t *testing.T) {
+	wg, privateKey, err := newWireGuardConfig("foo", map[string]string{"listen-port": "51821", "address": "10.200.0.1/24"})
+	assert.NilError(t, err)
+	assert.Equal(t, wg.Interface, "wg-"+networkID("foo")[:12])
+	assert.Equal(t, wg.ListenPort, 51821)
+	assert.NilError(t, validateWireGuardKey(privateKey))
+	assert.NilError(t, validateWireGuardKey(wg.PublicKey))
+
+	_, _, err = newWireGuardConfig("foo", map[string]string{"bar": "baz"})
+	assert.ErrorContains(t, err, `unsupported "wireguard" network option "wireguard.bar"`)
+}
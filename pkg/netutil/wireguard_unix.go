@@ -0,0 +1,102 @@
+//go:build unix
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+
+	"github.com/vishvananda/netlink"
+)
+
+// SetupWireGuard creates the WireGuard interface of the network if it does not exist yet,
+// and (re-)applies the key, the listen port, the peers and the routes to the peer subnets.
+// It is idempotent, and is a no-op for networks not created with the "wireguard" driver.
+func (n *NetworkConfig) SetupWireGuard() error {
+	wg := n.NerdctlWireGuard
+	if wg == nil {
+		return nil
+	}
+	wgPath, err := exec.LookPath("wg")
+	if err != nil {
+		return fmt.Errorf("the %q network driver needs the `wg` command (wireguard-tools) to be installed: %w", WireGuardDriver, err)
+	}
+	peers, err := wg.peers()
+	if err != nil {
+		return err
+	}
+
+	link, err := netlink.LinkByName(wg.Interface)
+	if err != nil {
+		attrs := netlink.NewLinkAttrs()
+		attrs.Name = wg.Interface
+		attrs.MTU = defaultWireGuardMTU
+		if err := netlink.LinkAdd(&netlink.Wireguard{LinkAttrs: attrs}); err != nil {
+			return fmt.Errorf("failed to create wireguard interface %s (Hint: the wireguard kernel module needs to be available): %w", wg.Interface, err)
+		}
+		if link, err = netlink.LinkByName(wg.Interface); err != nil {
+			return err
+		}
+	}
+
+	if out, err := exec.Command(wgPath, wg.setArgs(n.wireGuardKeyFile(), peers)...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to configure wireguard interface %s: %w (output=%q)", wg.Interface, err, string(out))
+	}
+	if wg.Address != "" {
+		addr, err := netlink.ParseAddr(wg.Address)
+		if err != nil {
+			return err
+		}
+		if err := netlink.AddrReplace(link, addr); err != nil {
+			return fmt.Errorf("failed to assign %s to wireguard interface %s: %w", wg.Address, wg.Interface, err)
+		}
+	}
+	if err := netlink.LinkSetUp(link); err != nil {
+		return fmt.Errorf("failed to bring up wireguard interface %s: %w", wg.Interface, err)
+	}
+	for _, p := range peers {
+		for _, allowed := range p.AllowedIPs {
+			_, dst, err := net.ParseCIDR(allowed)
+			if err != nil {
+				return err
+			}
+			route := &netlink.Route{LinkIndex: link.Attrs().Index, Dst: dst}
+			if err := netlink.RouteReplace(route); err != nil {
+				return fmt.Errorf("failed to route %s through wireguard interface %s: %w", allowed, wg.Interface, err)
+			}
+		}
+	}
+	return nil
+}
+
+func removeWireGuard(n *NetworkConfig) error {
+	link, err := netlink.LinkByName(n.NerdctlWireGuard.Interface)
+	if err == nil {
+		if err := netlink.LinkDel(link); err != nil {
+			return fmt.Errorf("failed to remove network interface %s: %v", n.NerdctlWireGuard.Interface, err)
+		}
+	}
+	if err := os.Remove(n.wireGuardKeyFile()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
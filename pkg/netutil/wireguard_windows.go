@@ -0,0 +1,28 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import "fmt"
+
+// SetupWireGuard is a no-op for networks not created with the "wireguard" driver.
+// The "wireguard" driver itself is not supported on Windows.
+func (n *NetworkConfig) SetupWireGuard() error {
+	if n.NerdctlWireGuard == nil {
+		return nil
+	}
+	return fmt.Errorf("the %q network driver is not supported on Windows", WireGuardDriver)
+}
@@ -25,6 +25,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -40,12 +41,15 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
 	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil/volumestore"
 	"github.com/containerd/nerdctl/v2/pkg/namestore"
 	"github.com/containerd/nerdctl/v2/pkg/netutil"
 	"github.com/containerd/nerdctl/v2/pkg/netutil/nettype"
 	"github.com/containerd/nerdctl/v2/pkg/ocihook/state"
+	"github.com/containerd/nerdctl/v2/pkg/portutil/userlandproxy"
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
 	"github.com/containerd/nerdctl/v2/pkg/store"
+	"github.com/containerd/nerdctl/v2/pkg/watchconfig"
 )
 
 const (
@@ -174,6 +178,10 @@ func newHandlerOpts(state *specs.State, dataStore, cniPath, cniNetconfPath, brid
 	switch netType {
 	case nettype.Host, nettype.None, nettype.Container, nettype.Namespace:
 		// NOP
+	case nettype.Pasta:
+		if o.pasta, err = netutil.ParsePastaNetwork(networks[0]); err != nil {
+			return nil, err
+		}
 	case nettype.CNI:
 		e, err := netutil.NewCNIEnv(cniPath, cniNetconfPath, netutil.WithNamespace(namespace), netutil.WithDefaultNetwork(bridgeIP))
 		if err != nil {
@@ -189,6 +197,13 @@ func newHandlerOpts(state *specs.State, dataStore, cniPath, cniNetconfPath, brid
 			}
 			cniOpts = append(cniOpts, cni.WithConfListBytes(netw.Bytes))
 			o.cniNames = append(o.cniNames, netstr)
+			if netw.NerdctlWireGuard != nil {
+				o.wireGuardNetworks = append(o.wireGuardNetworks, netw)
+			}
+			o.networkShapings = append(o.networkShapings, netw.NerdctlShaping)
+			o.ingressPolicies = append(o.ingressPolicies, netw.NerdctlIngressPolicy)
+			o.ipMasqs = append(o.ipMasqs, netw.NerdctlIPMasq)
+			o.ndpProxies = append(o.ndpProxies, netw.NerdctlNDPProxy)
 		}
 		o.cni, err = cni.New(cniOpts...)
 		if err != nil {
@@ -212,6 +227,20 @@ func newHandlerOpts(state *specs.State, dataStore, cniPath, cniNetconfPath, brid
 			return nil, err
 		}
 	}
+	// The ports of a running task may have been updated with `nerdctl container publish`
+	if ports, err := loadPortsOverride(o.state.Annotations[labels.StateDir]); err != nil {
+		return nil, err
+	} else if ports != nil {
+		o.ports = *ports
+	}
+
+	if o.shaping, err = netutil.ParseShapingLabel(o.state.Annotations[labels.NetworkShaping]); err != nil {
+		return nil, err
+	}
+
+	if o.allowFrom, err = netutil.ParseAllowFromLabel(o.state.Annotations[labels.IngressAllowFrom]); err != nil {
+		return nil, err
+	}
 
 	if ipAddress, ok := o.state.Annotations[labels.IPAddress]; ok {
 		o.containerIP = ipAddress
@@ -235,26 +264,45 @@ func newHandlerOpts(state *specs.State, dataStore, cniPath, cniNetconfPath, brid
 			return nil, err
 		}
 		if b4nnEnabled {
-			socketPath, err := bypass4netnsutil.GetBypass4NetnsdDefaultSocketPath()
+			socketPath, err := bypass4netnsutil.FindBypass4NetnsdSocket()
 			if err != nil {
 				return nil, err
 			}
 			o.bypassClient, err = b4nndclient.New(socketPath)
 			if err != nil {
-				return nil, fmt.Errorf("bypass4netnsd not running? (Hint: run `containerd-rootless-setuptool.sh install-bypass4netnsd`): %w", err)
+				return nil, fmt.Errorf("bypass4netnsd not running? (Hint: run `nerdctl system bypass4netnsd start`): %w", err)
 			}
 		}
 	}
 	return o, nil
 }
 
+func loadPortsOverride(stateDir string) (*[]cni.PortMapping, error) {
+	lf, err := state.New(stateDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := lf.Load(); err != nil {
+		return nil, err
+	}
+	return lf.Ports, nil
+}
+
 type handlerOpts struct {
 	state             *specs.State
 	dataStore         string
 	rootfs            string
 	ports             []cni.PortMapping
 	cni               cni.CNI
+	pasta             *netutil.PastaOptions
 	cniNames          []string
+	wireGuardNetworks []*netutil.NetworkConfig
+	networkShapings   []*netutil.Shaping // index-aligned with cniNames
+	shaping           *netutil.Shaping
+	ingressPolicies   []string                // index-aligned with cniNames
+	ipMasqs           []*netutil.IPMasqConfig // index-aligned with cniNames
+	ndpProxies        []string                // index-aligned with cniNames
+	allowFrom         []*net.IPNet
 	fullID            string
 	rootlessKitClient rlkclient.Client
 	bypassClient      b4nndclient.Client
@@ -326,46 +374,137 @@ func getNetNSPath(state *specs.State) (string, error) {
 
 func getPortMapOpts(opts *handlerOpts) ([]cni.NamespaceOpts, error) {
 	if len(opts.ports) > 0 {
-		if !rootlessutil.IsRootlessChild() {
-			return []cni.NamespaceOpts{cni.WithCapabilityPortMap(opts.ports)}, nil
-		}
-		var (
-			childIP                            net.IP
-			portDriverDisallowsLoopbackChildIP bool
-		)
-		info, err := opts.rootlessKitClient.Info(context.TODO())
-		if err != nil {
-			log.L.WithError(err).Warn("cannot call RootlessKit Info API, make sure you have RootlessKit v0.14.1 or later")
-		} else {
-			childIP = info.NetworkDriver.ChildIP
-			portDriverDisallowsLoopbackChildIP = info.PortDriver.DisallowLoopbackChildIP // true for slirp4netns port driver
-		}
-		// For rootless, we need to modify the hostIP that is not bindable in the child namespace.
-		// https: //github.com/containerd/nerdctl/issues/88
-		//
-		// We must NOT modify opts.ports here, because we use the unmodified opts.ports for
-		// interaction with RootlessKit API.
-		ports := make([]cni.PortMapping, len(opts.ports))
-		for i, p := range opts.ports {
-			if hostIP := net.ParseIP(p.HostIP); hostIP != nil && !hostIP.IsUnspecified() {
-				// loopback address is always bindable in the child namespace, but other addresses are unlikely.
-				if !hostIP.IsLoopback() {
-					if !(childIP != nil && childIP.Equal(hostIP)) {
-						if portDriverDisallowsLoopbackChildIP {
-							p.HostIP = childIP.String()
-						} else {
-							p.HostIP = "127.0.0.1"
-						}
+		return []cni.NamespaceOpts{cni.WithCapabilityPortMap(getChildPorts(opts))}, nil
+	}
+	return nil, nil
+}
+
+// getChildPorts returns the port mappings to be bound in the current network namespace.
+func getChildPorts(opts *handlerOpts) []cni.PortMapping {
+	if !rootlessutil.IsRootlessChild() {
+		return opts.ports
+	}
+	var (
+		childIP                            net.IP
+		portDriverDisallowsLoopbackChildIP bool
+	)
+	info, err := opts.rootlessKitClient.Info(context.TODO())
+	if err != nil {
+		log.L.WithError(err).Warn("cannot call RootlessKit Info API, make sure you have RootlessKit v0.14.1 or later")
+	} else {
+		childIP = info.NetworkDriver.ChildIP
+		portDriverDisallowsLoopbackChildIP = info.PortDriver.DisallowLoopbackChildIP // true for slirp4netns port driver
+	}
+	// For rootless, we need to modify the hostIP that is not bindable in the child namespace.
+	// https: //github.com/containerd/nerdctl/issues/88
+	//
+	// We must NOT modify opts.ports here, because we use the unmodified opts.ports for
+	// interaction with RootlessKit API.
+	ports := make([]cni.PortMapping, len(opts.ports))
+	for i, p := range opts.ports {
+		if hostIP := net.ParseIP(p.HostIP); hostIP != nil && !hostIP.IsUnspecified() {
+			// loopback address is always bindable in the child namespace, but other addresses are unlikely.
+			if !hostIP.IsLoopback() {
+				if !(childIP != nil && childIP.Equal(hostIP)) {
+					if portDriverDisallowsLoopbackChildIP {
+						p.HostIP = childIP.String()
+					} else {
+						p.HostIP = "127.0.0.1"
 					}
-				} else if portDriverDisallowsLoopbackChildIP {
-					p.HostIP = childIP.String()
 				}
+			} else if portDriverDisallowsLoopbackChildIP {
+				p.HostIP = childIP.String()
 			}
-			ports[i] = p
 		}
-		return []cni.NamespaceOpts{cni.WithCapabilityPortMap(ports)}, nil
+		ports[i] = p
 	}
-	return nil, nil
+	return ports
+}
+
+func getBandwidthOpts(opts *handlerOpts) []cni.NamespaceOpts {
+	if opts.shaping != nil && opts.shaping.Bandwidth > 0 {
+		return []cni.NamespaceOpts{cni.WithCapabilityBandWidth(opts.shaping.BandWidth())}
+	}
+	return nil
+}
+
+// applyNetworkDelay adds the delay of the container, or else of the network, to the interfaces of the container.
+func applyNetworkDelay(opts *handlerOpts, nsPath string) error {
+	for i, networkShaping := range opts.networkShapings {
+		shaping := networkShaping
+		if opts.shaping != nil && opts.shaping.Delay > 0 {
+			shaping = opts.shaping
+		}
+		if shaping == nil || shaping.Delay == 0 {
+			continue
+		}
+		// go-cni names the interfaces after the index of the network
+		if err := netutil.SetupDelay(nsPath, fmt.Sprintf("eth%d", i), shaping.Delay); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ingressDeniedIPs returns the container IPs on the networks with the "deny" ingress policy.
+// results are index-aligned with opts.cniNames.
+func ingressDeniedIPs(opts *handlerOpts, results []*types100.Result) []net.IP {
+	var ips []net.IP
+	for i, policy := range opts.ingressPolicies {
+		if policy != netutil.IngressPolicyDeny || i >= len(results) || results[i] == nil {
+			continue
+		}
+		if rootlessutil.IsRootlessChild() {
+			// The port drivers of RootlessKit do not preserve the source IPs of the connections
+			log.L.Warnf("ingress policy of network %q is ignored in rootless mode", opts.cniNames[i])
+			continue
+		}
+		for _, ipc := range results[i].IPs {
+			ips = append(ips, ipc.Address.IP)
+		}
+	}
+	return ips
+}
+
+// masqueradeAddrs returns the container addresses to be masqueraded by nerdctl, on the networks
+// that masquerade only one of IPv4 and IPv6.
+// results are index-aligned with opts.cniNames.
+func masqueradeAddrs(opts *handlerOpts, results []*types100.Result) []*net.IPNet {
+	var addrs []*net.IPNet
+	for i, ipMasq := range opts.ipMasqs {
+		if ipMasq == nil || i >= len(results) || results[i] == nil {
+			continue
+		}
+		for _, ipc := range results[i].IPs {
+			if ipMasq.Enabled(ipc.Address.IP) {
+				addr := ipc.Address
+				addrs = append(addrs, &addr)
+			}
+		}
+	}
+	return addrs
+}
+
+// ndpProxyIPs returns the IPv6 addresses of the container, per host interface of the NDP proxy.
+// results are index-aligned with opts.cniNames.
+func ndpProxyIPs(opts *handlerOpts, results []*types100.Result) map[string][]net.IP {
+	res := make(map[string][]net.IP)
+	for i, iface := range opts.ndpProxies {
+		if iface == "" || i >= len(results) || results[i] == nil {
+			continue
+		}
+		if rootlessutil.IsRootlessChild() {
+			// The interfaces of the host are not in the network namespace of RootlessKit
+			log.L.Warnf("NDP proxy of network %q is ignored in rootless mode", opts.cniNames[i])
+			continue
+		}
+		for _, ipc := range results[i].IPs {
+			if ipc.Address.IP.To4() == nil {
+				res[iface] = append(res[iface], ipc.Address.IP)
+			}
+		}
+	}
+	return res
 }
 
 func getIPAddressOpts(opts *handlerOpts) ([]cni.NamespaceOpts, error) {
@@ -446,6 +585,7 @@ func applyNetworkSettings(opts *handlerOpts) error {
 	}
 	var namespaceOpts []cni.NamespaceOpts
 	namespaceOpts = append(namespaceOpts, portMapOpts...)
+	namespaceOpts = append(namespaceOpts, getBandwidthOpts(opts)...)
 	namespaceOpts = append(namespaceOpts, ipAddressOpts...)
 	namespaceOpts = append(namespaceOpts, macAddressOpts...)
 	namespaceOpts = append(namespaceOpts, ip6AddressOpts...)
@@ -475,6 +615,13 @@ func applyNetworkSettings(opts *handlerOpts) error {
 	// See https://github.com/containerd/nerdctl/issues/3355
 	_ = opts.cni.Remove(ctx, opts.fullID, "", namespaceOpts...)
 
+	// The WireGuard interfaces do not survive host reboots, so they are (re-)created lazily here.
+	for _, netw := range opts.wireGuardNetworks {
+		if err := netw.SetupWireGuard(); err != nil {
+			return err
+		}
+	}
+
 	cniRes, err := opts.cni.Setup(ctx, opts.fullID, nsPath, namespaceOpts...)
 	if err != nil {
 		return fmt.Errorf("failed to call cni.Setup: %w", err)
@@ -484,6 +631,24 @@ func applyNetworkSettings(opts *handlerOpts) error {
 		hsMeta.Networks[cniName] = cniResRaw[i]
 	}
 
+	if err := applyNetworkDelay(opts, nsPath); err != nil {
+		return err
+	}
+
+	if err := netutil.SetupIngressPolicy(opts.fullID, ingressDeniedIPs(opts, cniResRaw), opts.allowFrom); err != nil {
+		return err
+	}
+
+	if err := netutil.SetupMasquerade(opts.fullID, masqueradeAddrs(opts, cniResRaw)); err != nil {
+		return err
+	}
+
+	for iface, ips := range ndpProxyIPs(opts, cniResRaw) {
+		if err := netutil.SetupNDPProxy(iface, ips); err != nil {
+			return err
+		}
+	}
+
 	b4nnEnabled, b4nnBindEnabled, err := bypass4netnsutil.IsBypass4netnsEnabled(opts.state.Annotations)
 	if err != nil {
 		return err
@@ -493,6 +658,26 @@ func applyNetworkSettings(opts *handlerOpts) error {
 		return err
 	}
 
+	if opts.state.Annotations[labels.UserlandProxy] == "true" && len(opts.ports) > 0 {
+		if rootlessutil.IsRootlessChild() {
+			// RootlessKit port drivers already forward the ports from the host.
+			log.L.Warn("userland proxy is ignored in rootless mode")
+		} else {
+			stateDir := opts.state.Annotations[labels.StateDir]
+			// Clean up the proxies of a previous run that was not properly stopped (e.g., containerd was bounced)
+			userlandproxy.StopAll(stateDir)
+			proxies, err := userlandproxy.FromPortMappings(opts.ports, cniResRaw)
+			if err != nil {
+				return err
+			}
+			for _, p := range proxies {
+				if err := userlandproxy.Start(stateDir, p); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
 	if rootlessutil.IsRootlessChild() {
 		if b4nnEnabled {
 			bm, err := bypass4netnsutil.NewBypass4netnsCNIBypassManager(opts.bypassClient, opts.rootlessKitClient, opts.state.Annotations)
@@ -501,7 +686,7 @@ func applyNetworkSettings(opts *handlerOpts) error {
 			}
 			err = bm.StartBypass(ctx, opts.ports, opts.state.ID, opts.state.Annotations[labels.StateDir])
 			if err != nil {
-				return fmt.Errorf("bypass4netnsd not running? (Hint: run `containerd-rootless-setuptool.sh install-bypass4netnsd`): %w", err)
+				return fmt.Errorf("bypass4netnsd not running? (Hint: run `nerdctl system bypass4netnsd start`): %w", err)
 			}
 		}
 		if !b4nnBindEnabled && len(opts.ports) > 0 {
@@ -513,6 +698,62 @@ func applyNetworkSettings(opts *handlerOpts) error {
 	return nil
 }
 
+// pastaRootlessExposesPorts returns true if the ports bound by pasta in the RootlessKit child
+// have to be exposed to the host with the RootlessKit port driver.
+// In the detach-netns mode, the RootlessKit child is in the host network namespace.
+func pastaRootlessExposesPorts() (bool, error) {
+	if !rootlessutil.IsRootlessChild() {
+		return false, nil
+	}
+	detachedNetNS, err := rootlessutil.DetachedNetNS()
+	if err != nil {
+		return false, err
+	}
+	return detachedNetNS == "", nil
+}
+
+func applyPastaSettings(opts *handlerOpts) error {
+	nsPath, err := getNetNSPath(opts.state)
+	if err != nil {
+		return err
+	}
+	exposeRootless, err := pastaRootlessExposesPorts()
+	if err != nil {
+		return err
+	}
+	ports := opts.ports
+	if exposeRootless {
+		ports = getChildPorts(opts)
+	}
+	pidFile := filepath.Join(opts.state.Annotations[labels.StateDir], "pasta.pid")
+	// Stop the instance left by the previous task, if any
+	if err := netutil.StopPasta(pidFile); err != nil {
+		log.L.WithError(err).Warn("failed to stop the stale pasta process")
+	}
+	if err := netutil.StartPasta(opts.pasta, nsPath, pidFile, ports); err != nil {
+		return err
+	}
+	if exposeRootless && len(opts.ports) > 0 {
+		if err := exposePortsRootless(context.Background(), opts.rootlessKitClient, opts.ports); err != nil {
+			return fmt.Errorf("failed to expose ports in rootless mode: %w", err)
+		}
+	}
+	return nil
+}
+
+func removePastaSettings(opts *handlerOpts) error {
+	exposeRootless, err := pastaRootlessExposesPorts()
+	if err != nil {
+		return err
+	}
+	if exposeRootless && len(opts.ports) > 0 {
+		if err := unexposePortsRootless(context.Background(), opts.rootlessKitClient, opts.ports); err != nil {
+			return fmt.Errorf("failed to unexpose ports in rootless mode: %w", err)
+		}
+	}
+	return netutil.StopPasta(filepath.Join(opts.state.Annotations[labels.StateDir], "pasta.pid"))
+}
+
 func onCreateRuntime(opts *handlerOpts) error {
 	loadAppArmor()
 
@@ -528,6 +769,8 @@ func onCreateRuntime(opts *handlerOpts) error {
 	var netError error
 	if opts.cni != nil {
 		netError = applyNetworkSettings(opts)
+	} else if opts.pasta != nil {
+		netError = applyPastaSettings(opts)
 	}
 
 	// Set StartedAt and CreateError
@@ -536,18 +779,57 @@ func onCreateRuntime(opts *handlerOpts) error {
 		return err
 	}
 
+	pidStartTime, err := state.ProcessStartTime(opts.state.Pid)
+	if err != nil {
+		log.L.WithError(err).Debugf("failed to get the start time of pid %d", opts.state.Pid)
+	}
 	err = lf.Transform(func(lf *state.Store) error {
 		lf.StartedAt = time.Now()
 		lf.CreateError = netError != nil
+		lf.Pid = opts.state.Pid
+		lf.PidStartTime = pidStartTime
+		lf.Paused = false
 		return nil
 	})
 	if err != nil {
 		return err
 	}
 
+	if netError == nil {
+		startConfigWatcher(opts)
+	}
+
 	return netError
 }
 
+// startConfigWatcher starts the watcher of `nerdctl run --watch-config`, if set.
+// The failures are logged, but do not prevent the container from starting.
+func startConfigWatcher(opts *handlerOpts) {
+	cfg, err := watchconfig.Parse(opts.state.Annotations[labels.WatchConfig])
+	if err != nil {
+		log.L.WithError(err).Error("failed to start the config watcher")
+		return
+	}
+	if cfg == nil {
+		return
+	}
+	stateDir := opts.state.Annotations[labels.StateDir]
+	watchconfig.Stop(stateDir)
+	globalArgs := append(nerdctlGlobalArgs(), "--namespace="+opts.state.Annotations[labels.Namespace])
+	if err := watchconfig.Start(stateDir, globalArgs, opts.state.ID, cfg); err != nil {
+		log.L.WithError(err).Error("failed to start the config watcher")
+	}
+}
+
+// nerdctlGlobalArgs returns the global flags of the hook, which is executed as
+// `nerdctl [GLOBAL FLAGS] internal oci-hook EVENT` (see withNerdctlOCIHook in pkg/cmd/container).
+func nerdctlGlobalArgs() []string {
+	if i := slices.Index(os.Args, "internal"); i > 0 {
+		return append([]string(nil), os.Args[1:i]...)
+	}
+	return nil
+}
+
 func onPostStop(opts *handlerOpts) error {
 	lf, err := state.New(opts.state.Annotations[labels.StateDir])
 	if err != nil {
@@ -563,17 +845,30 @@ func onPostStop(opts *handlerOpts) error {
 		// Reset CreateError, and return.
 		shouldExit = lf.CreateError
 		lf.CreateError = false
+		lf.Pid = 0
+		lf.PidStartTime = 0
+		lf.Paused = false
+		if !shouldExit {
+			// The spec annotations are up-to-date for the next task
+			lf.Ports = nil
+		}
 		return nil
 	})
 	if err != nil {
 		return err
 	}
+	watchconfig.Stop(opts.state.Annotations[labels.StateDir])
 	if shouldExit {
 		return nil
 	}
 
 	ctx := context.Background()
 	ns := opts.state.Annotations[labels.Namespace]
+	if opts.pasta != nil {
+		if err := removePastaSettings(opts); err != nil {
+			return err
+		}
+	}
 	if opts.cni != nil {
 		var err error
 		b4nnEnabled, b4nnBindEnabled, err := bypass4netnsutil.IsBypass4netnsEnabled(opts.state.Annotations)
@@ -597,6 +892,7 @@ func onPostStop(opts *handlerOpts) error {
 				}
 			}
 		}
+		userlandproxy.StopAll(opts.state.Annotations[labels.StateDir])
 		portMapOpts, err := getPortMapOpts(opts)
 		if err != nil {
 			return err
@@ -615,6 +911,7 @@ func onPostStop(opts *handlerOpts) error {
 		}
 		var namespaceOpts []cni.NamespaceOpts
 		namespaceOpts = append(namespaceOpts, portMapOpts...)
+		namespaceOpts = append(namespaceOpts, getBandwidthOpts(opts)...)
 		namespaceOpts = append(namespaceOpts, ipAddressOpts...)
 		namespaceOpts = append(namespaceOpts, macAddressOpts...)
 		namespaceOpts = append(namespaceOpts, ip6AddressOpts...)
@@ -626,10 +923,28 @@ func onPostStop(opts *handlerOpts) error {
 		if err != nil {
 			return err
 		}
+		if meta, err := hs.Get(opts.state.ID); err == nil {
+			results := make([]*types100.Result, len(opts.cniNames))
+			for i, cniName := range opts.cniNames {
+				results[i] = meta.Networks[cniName]
+			}
+			if err := netutil.RemoveIngressPolicy(opts.fullID, ingressDeniedIPs(opts, results), opts.allowFrom); err != nil {
+				log.L.WithError(err).Warn("failed to remove the ingress policy rules")
+			}
+			if err := netutil.RemoveMasquerade(opts.fullID, masqueradeAddrs(opts, results)); err != nil {
+				log.L.WithError(err).Warn("failed to remove the masquerade rules")
+			}
+			for iface, ips := range ndpProxyIPs(opts, results) {
+				if err := netutil.RemoveNDPProxy(iface, ips); err != nil {
+					log.L.WithError(err).Warn("failed to remove the NDP proxy entries")
+				}
+			}
+		}
 		if err := hs.Release(opts.state.ID); err != nil {
 			return err
 		}
 	}
+	unmountNetworkVolumes(opts)
 	namst, err := namestore.New(opts.dataStore, ns)
 	if err != nil {
 		return err
@@ -642,6 +957,31 @@ func onPostStop(opts *handlerOpts) error {
 	return nil
 }
 
+// unmountNetworkVolumes unmounts the NFS and CIFS volumes of the container, if no other container uses them.
+func unmountNetworkVolumes(opts *handlerOpts) {
+	mountsJSON := opts.state.Annotations[labels.Mounts]
+	if mountsJSON == "" {
+		return
+	}
+	var mounts []struct {
+		Type   string
+		Name   string
+		Source string
+	}
+	if err := json.Unmarshal([]byte(mountsJSON), &mounts); err != nil {
+		log.L.WithError(err).Warn("failed to parse the mounts of the container")
+		return
+	}
+	for _, m := range mounts {
+		if m.Type != "volume" {
+			continue
+		}
+		if err := volumestore.UnmountNetworkVolume(m.Source, opts.state.ID); err != nil {
+			log.L.WithError(err).Warnf("failed to unmount volume %q", m.Name)
+		}
+	}
+}
+
 // writePidFile writes the pid atomically to a file.
 // From https://github.com/containerd/containerd/blob/v1.7.0-rc.2/cmd/ctr/commands/commands.go#L265-L282
 func writePidFile(path string, pid int) error {
@@ -22,11 +22,26 @@ import (
 	rlkclient "github.com/rootless-containers/rootlesskit/v2/pkg/api/client"
 
 	"github.com/containerd/go-cni"
+	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
 )
 
+// portDriverIsImplicit returns true if the ports are forwarded by the network driver of RootlessKit (pasta),
+// without the port driver.
+func portDriverIsImplicit(ctx context.Context, rlkClient rlkclient.Client) bool {
+	info, err := rlkClient.Info(ctx)
+	if err != nil {
+		log.G(ctx).WithError(err).Warn("cannot call RootlessKit Info API")
+		return false
+	}
+	return info.PortDriver != nil && info.PortDriver.Driver == rootlessutil.PortDriverImplicit
+}
+
 func exposePortsRootless(ctx context.Context, rlkClient rlkclient.Client, ports []cni.PortMapping) error {
+	if portDriverIsImplicit(ctx, rlkClient) {
+		return nil
+	}
 	pm, err := rootlessutil.NewRootlessCNIPortManager(rlkClient)
 	if err != nil {
 		return err
@@ -41,6 +56,9 @@ func exposePortsRootless(ctx context.Context, rlkClient rlkclient.Client, ports
 }
 
 func unexposePortsRootless(ctx context.Context, rlkClient rlkclient.Client, ports []cni.PortMapping) error {
+	if portDriverIsImplicit(ctx, rlkClient) {
+		return nil
+	}
 	pm, err := rootlessutil.NewRootlessCNIPortManager(rlkClient)
 	if err != nil {
 		return err
@@ -22,7 +22,8 @@
 // Since the state is transient and carrying solely informative data, errors returned from here could be treated as
 // soft-failures.
 // Note that locking is done at the container state directory level.
-// state is currently used by ocihooks and for read by dockercompat (to display started-at time)
+// state is currently used by ocihooks and for read by dockercompat (to display started-at time),
+// and by `nerdctl ps` as an index of the container commands and statuses (unless `--sync` is specified)
 package state
 
 import (
@@ -30,6 +31,8 @@ import (
 	"errors"
 	"time"
 
+	"github.com/containerd/go-cni"
+
 	"github.com/containerd/nerdctl/v2/pkg/store"
 )
 
@@ -58,6 +61,31 @@ type Store struct {
 	// StartedAt reflects the time at which we received the oci-hook onCreateRuntime event
 	StartedAt   time.Time `json:"started_at"`
 	CreateError bool      `json:"create_error"`
+	// Ports overrides the ports of the spec annotations for the current task,
+	// after they were changed with `nerdctl container publish` or `unpublish`.
+	Ports *[]cni.PortMapping `json:"ports,omitempty"`
+
+	// The following fields are cached for `nerdctl ps`, so that it does not need to unmarshal the spec
+	// nor to query the task of every container.
+
+	// Command is the command line of the container, set on creation.
+	Command string `json:"command,omitempty"`
+	// Pid is the pid of the init process of the current task, set on onCreateRuntime and reset on onPostStop.
+	Pid int `json:"pid,omitempty"`
+	// PidStartTime is the start time of the init process, used to detect the reuse of the pid.
+	PidStartTime uint64 `json:"pid_start_time,omitempty"`
+	// Paused is set by `nerdctl pause` and reset by `nerdctl unpause`.
+	Paused bool `json:"paused,omitempty"`
+}
+
+// Running returns true if the init process recorded by the oci-hook is still alive.
+// False means unknown rather than stopped: the caller has to ask containerd then.
+func (lf *Store) Running() bool {
+	if lf.Pid <= 0 || lf.PidStartTime == 0 {
+		return false
+	}
+	startTime, err := ProcessStartTime(lf.Pid)
+	return err == nil && startTime == lf.PidStartTime
 }
 
 // Load will populate the struct with existing in-store lifecycle information
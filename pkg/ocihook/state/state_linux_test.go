@@ -0,0 +1,55 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package state
+
+import (
+	"os"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseStatStartTime(t *testing.T) {
+	stat := "4242 (sleep) S 1 4242 4242 0 -1 4194560 108 0 0 0 0 0 0 0 20 0 1 0 123456 2641920 128 18446744073709551615 1 1 0 0 0 0 0 0 0 0 0 0 17 3 0 0 0 0 0"
+	startTime, err := parseStatStartTime(stat)
+	assert.NilError(t, err)
+	assert.Equal(t, startTime, uint64(123456))
+
+	// comm may contain spaces and parentheses
+	stat = "4242 (a (b) c) S 1 4242 4242 0 -1 4194560 108 0 0 0 0 0 0 0 20 0 1 0 654321 2641920 128"
+	startTime, err = parseStatStartTime(stat)
+	assert.NilError(t, err)
+	assert.Equal(t, startTime, uint64(654321))
+
+	_, err = parseStatStartTime("4242 (sleep) S 1")
+	assert.ErrorContains(t, err, "unexpected stat")
+}
+
+func TestRunning(t *testing.T) {
+	startTime, err := ProcessStartTime(os.Getpid())
+	assert.NilError(t, err)
+
+	lf := &Store{Pid: os.Getpid(), PidStartTime: startTime}
+	assert.Assert(t, lf.Running())
+
+	// the pid was reused by another process
+	lf.PidStartTime = startTime + 1
+	assert.Assert(t, !lf.Running())
+
+	lf = &Store{}
+	assert.Assert(t, !lf.Running())
+}
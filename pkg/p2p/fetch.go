@@ -0,0 +1,154 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/containerd/containerd/v2/core/remotes"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/config"
+)
+
+// peerTimeout is the timeout of asking a peer whether it has a blob.
+const peerTimeout = 500 * time.Millisecond
+
+// WrapResolver returns the resolver that fetches the blobs from the peers before `r`.
+// The references are still resolved by `r`, as only the blobs addressed by digest can be trusted from the peers.
+// `r` is returned as is when there is no peer, e.g., when cfg is not enabled.
+func WrapResolver(ctx context.Context, r remotes.Resolver, cfg config.P2PConfig) remotes.Resolver {
+	return wrapResolver(r, Peers(ctx, cfg))
+}
+
+func wrapResolver(r remotes.Resolver, peers []string) remotes.Resolver {
+	if len(peers) == 0 {
+		return r
+	}
+	return &resolver{Resolver: r, peers: peers, client: &http.Client{}}
+}
+
+type resolver struct {
+	remotes.Resolver
+	peers  []string
+	client *http.Client
+}
+
+func (r *resolver) Fetcher(ctx context.Context, ref string) (remotes.Fetcher, error) {
+	upstream, err := r.Resolver.Fetcher(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return remotes.FetcherFunc(func(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+		if peer := r.findPeer(ctx, desc); peer != "" {
+			rc, err := r.fetchFromPeer(ctx, peer, desc)
+			if err == nil {
+				log.G(ctx).Debugf("fetching %s from peer %s", desc.Digest, peer)
+				return rc, nil
+			}
+			log.G(ctx).WithError(err).Debugf("failed to fetch %s from peer %s, falling back to the registry", desc.Digest, peer)
+		}
+		return upstream.Fetch(ctx, desc)
+	}), nil
+}
+
+func (r *resolver) blobURL(peer string, dgst digest.Digest) string {
+	return "http://" + peer + blobsPath + dgst.String()
+}
+
+// findPeer asks all the peers whether they have the blob, and returns the first peer that has it.
+func (r *resolver) findPeer(ctx context.Context, desc ocispec.Descriptor) string {
+	ctx, cancel := context.WithTimeout(ctx, peerTimeout)
+	defer cancel()
+	found := make(chan string, len(r.peers))
+	for _, peer := range r.peers {
+		go func(peer string) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, r.blobURL(peer, desc.Digest), nil)
+			if err != nil {
+				found <- ""
+				return
+			}
+			resp, err := r.client.Do(req)
+			if err != nil {
+				found <- ""
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || resp.ContentLength != desc.Size {
+				found <- ""
+				return
+			}
+			found <- peer
+		}(peer)
+	}
+	for range r.peers {
+		if peer := <-found; peer != "" {
+			return peer
+		}
+	}
+	return ""
+}
+
+func (r *resolver) fetchFromPeer(ctx context.Context, peer string, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.blobURL(peer, desc.Digest), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return &verifyingReader{
+		rc:       resp.Body,
+		verifier: desc.Digest.Verifier(),
+		desc:     desc,
+	}, nil
+}
+
+// verifyingReader fails at the end of the blob if its digest does not match, so that a broken peer cannot
+// silently provide a wrong blob.
+type verifyingReader struct {
+	rc       io.ReadCloser
+	verifier digest.Verifier
+	desc     ocispec.Descriptor
+	n        int64
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.rc.Read(p)
+	v.n += int64(n)
+	v.verifier.Write(p[:n])
+	if err == io.EOF && (v.n != v.desc.Size || !v.verifier.Verified()) {
+		return n, fmt.Errorf("blob %s from the peer does not match the digest", v.desc.Digest)
+	}
+	return n, err
+}
+
+func (v *verifyingReader) Close() error {
+	return v.rc.Close()
+}
@@ -0,0 +1,279 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package p2p
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/containerd/log"
+)
+
+// serviceName is the DNS-SD service of `nerdctl p2p serve`.
+const serviceName = "_nerdctl-p2p._tcp.local."
+
+// unicastResponseBit is the top bit of the class of the mDNS questions that ask for a unicast response (RFC 6762 §5.4).
+const unicastResponseBit = 1 << 15
+
+var mdnsGroup = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+// Advertise answers the mDNS queries for the service, with the port of the server and the IPv4 addresses of the host,
+// until ctx is done.
+func Advertise(ctx context.Context, port int) error {
+	conn, err := net.ListenMulticastUDP("udp4", nil, mdnsGroup)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	log.G(ctx).Infof("advertising %s on port %d with mDNS", serviceName, port)
+	buf := make([]byte, 9000)
+	for {
+		n, src, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		resp, unicast, err := respond(buf[:n], hostname, port, localIPs())
+		if err != nil {
+			log.G(ctx).WithError(err).Debugf("ignoring mDNS message from %s", src)
+			continue
+		}
+		if resp == nil {
+			continue
+		}
+		// Like the other responders, the queries from a port other than 5353 (i.e., not a full mDNS querier)
+		// and the queries with the unicast response bit are answered with unicast.
+		dst := mdnsGroup
+		if unicast || src.Port != mdnsGroup.Port {
+			dst = src
+		}
+		if _, err := conn.WriteToUDP(resp, dst); err != nil {
+			log.G(ctx).WithError(err).Debugf("failed to answer the mDNS query from %s", src)
+		}
+	}
+}
+
+// Discover queries the peers with mDNS, and returns their addresses ("host:port") answered within the timeout.
+func Discover(ctx context.Context, timeout time.Duration) ([]string, error) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	q, err := query()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.WriteToUDP(q, mdnsGroup); err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return nil, err
+	}
+	var (
+		res  []string
+		seen = map[string]struct{}{}
+		buf  = make([]byte, 9000)
+	)
+	for {
+		n, src, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return res, nil
+			}
+			return res, err
+		}
+		for _, addr := range parseResponse(buf[:n], src.IP) {
+			if _, ok := seen[addr]; !ok {
+				seen[addr] = struct{}{}
+				res = append(res, addr)
+			}
+		}
+	}
+}
+
+// query builds the mDNS query of the PTR records of the service.
+func query() ([]byte, error) {
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{})
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(dnsmessage.Question{
+		Name:  dnsmessage.MustNewName(serviceName),
+		Type:  dnsmessage.TypePTR,
+		Class: dnsmessage.ClassINET | unicastResponseBit,
+	}); err != nil {
+		return nil, err
+	}
+	return b.Finish()
+}
+
+// respond builds the response to the mDNS message `msg`, if it queries the service.
+// A nil response is returned for the other messages.
+func respond(msg []byte, hostname string, port int, ips []net.IP) (resp []byte, unicast bool, _ error) {
+	var p dnsmessage.Parser
+	h, err := p.Start(msg)
+	if err != nil {
+		return nil, false, err
+	}
+	if h.Response {
+		return nil, false, nil
+	}
+	questions, err := p.AllQuestions()
+	if err != nil {
+		return nil, false, err
+	}
+	var asked bool
+	for _, q := range questions {
+		if (q.Type == dnsmessage.TypePTR || q.Type == dnsmessage.TypeALL) && strings.EqualFold(q.Name.String(), serviceName) {
+			asked = true
+			unicast = unicast || q.Class&unicastResponseBit != 0
+		}
+	}
+	if !asked {
+		return nil, false, nil
+	}
+
+	// The DNS labels cannot have dots
+	label := strings.ReplaceAll(hostname, ".", "-")
+	if len(label) > 63 {
+		label = label[:63]
+	}
+	instance, err := dnsmessage.NewName(label + "." + serviceName)
+	if err != nil {
+		return nil, false, err
+	}
+	target, err := dnsmessage.NewName(label + ".local.")
+	if err != nil {
+		return nil, false, err
+	}
+	const ttl = 120
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: h.ID, Response: true, Authoritative: true})
+	b.EnableCompression()
+	if err := b.StartAnswers(); err != nil {
+		return nil, false, err
+	}
+	if err := b.PTRResource(
+		dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(serviceName), Class: dnsmessage.ClassINET, TTL: ttl},
+		dnsmessage.PTRResource{PTR: instance},
+	); err != nil {
+		return nil, false, err
+	}
+	if err := b.StartAdditionals(); err != nil {
+		return nil, false, err
+	}
+	if err := b.SRVResource(
+		dnsmessage.ResourceHeader{Name: instance, Class: dnsmessage.ClassINET, TTL: ttl},
+		dnsmessage.SRVResource{Target: target, Port: uint16(port)},
+	); err != nil {
+		return nil, false, err
+	}
+	for _, ip := range ips {
+		var a dnsmessage.AResource
+		copy(a.A[:], ip.To4())
+		if err := b.AResource(dnsmessage.ResourceHeader{Name: target, Class: dnsmessage.ClassINET, TTL: ttl}, a); err != nil {
+			return nil, false, err
+		}
+	}
+	resp, err = b.Finish()
+	return resp, unicast, err
+}
+
+// parseResponse returns the addresses ("host:port") of the service in the mDNS response `msg`.
+// When the response has no address record of the target, the source address of the response is used.
+func parseResponse(msg []byte, src net.IP) []string {
+	var p dnsmessage.Parser
+	h, err := p.Start(msg)
+	if err != nil || !h.Response {
+		return nil
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return nil
+	}
+	var resources []dnsmessage.Resource
+	for _, section := range []func() ([]dnsmessage.Resource, error){p.AllAnswers, p.AllAuthorities, p.AllAdditionals} {
+		rs, err := section()
+		if err != nil {
+			return nil
+		}
+		resources = append(resources, rs...)
+	}
+	srvs := map[string]uint16{} // key: target
+	ips := map[string][]net.IP{}
+	for _, r := range resources {
+		switch body := r.Body.(type) {
+		case *dnsmessage.SRVResource:
+			if strings.HasSuffix(strings.ToLower(r.Header.Name.String()), serviceName) {
+				srvs[strings.ToLower(body.Target.String())] = body.Port
+			}
+		case *dnsmessage.AResource:
+			name := strings.ToLower(r.Header.Name.String())
+			ips[name] = append(ips[name], net.IP(body.A[:]))
+		}
+	}
+	var res []string
+	for target, port := range srvs {
+		targetIPs := ips[target]
+		if len(targetIPs) == 0 && src != nil {
+			targetIPs = []net.IP{src}
+		}
+		for _, ip := range targetIPs {
+			res = append(res, net.JoinHostPort(ip.String(), strconv.Itoa(int(port))))
+		}
+	}
+	return res
+}
+
+// localIPs returns the global unicast IPv4 addresses of the host.
+func localIPs() []net.IP {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	var ips []net.IP
+	for _, a := range addrs {
+		ipNet, ok := a.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		if ip4 := ipNet.IP.To4(); ip4 != nil && ip4.IsGlobalUnicast() {
+			ips = append(ips, ip4)
+		}
+	}
+	return ips
+}
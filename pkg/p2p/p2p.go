@@ -0,0 +1,70 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package p2p implements the peer-to-peer distribution of the blobs between the hosts on the same LAN.
+//
+// `nerdctl p2p serve` serves the blobs of the content store over HTTP, and advertises itself with mDNS.
+// When [p2p] is enabled in nerdctl.toml, the blobs are fetched from the peers before the registries.
+package p2p
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/config"
+)
+
+// discoveryTimeout is the time to wait for the mDNS responses.
+const discoveryTimeout = time.Second
+
+var (
+	discoverOnce sync.Once
+	discovered   []string
+)
+
+// Peers returns the addresses ("host:port") of the peers of cfg, i.e., the static peers and the peers discovered with mDNS.
+// The discovery is done once per process.
+func Peers(ctx context.Context, cfg config.P2PConfig) []string {
+	if !cfg.Enabled {
+		return nil
+	}
+	var peers []string
+	seen := map[string]struct{}{}
+	add := func(addrs []string) {
+		for _, a := range addrs {
+			if _, ok := seen[a]; !ok {
+				seen[a] = struct{}{}
+				peers = append(peers, a)
+			}
+		}
+	}
+	add(cfg.Peers)
+	if cfg.MDNS {
+		discoverOnce.Do(func() {
+			var err error
+			discovered, err = Discover(ctx, discoveryTimeout)
+			if err != nil {
+				log.G(ctx).WithError(err).Warn("failed to discover the peers with mDNS")
+			}
+		})
+		add(discovered)
+	}
+	log.G(ctx).Debugf("p2p peers: %v", peers)
+	return peers
+}
@@ -0,0 +1,182 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/remotes"
+	"github.com/containerd/containerd/v2/plugins/content/local"
+
+	"github.com/containerd/nerdctl/v2/pkg/config"
+)
+
+func descriptor(data []byte) ocispec.Descriptor {
+	return ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageLayer,
+		Digest:    digest.FromBytes(data),
+		Size:      int64(len(data)),
+	}
+}
+
+// newPeer starts a peer that serves `blobs`, and returns its address.
+func newPeer(t *testing.T, blobs ...[]byte) string {
+	t.Helper()
+	ctx := context.Background()
+	cs, err := local.NewStore(t.TempDir())
+	assert.NilError(t, err)
+	for _, b := range blobs {
+		desc := descriptor(b)
+		assert.NilError(t, content.WriteBlob(ctx, cs, desc.Digest.String(), bytes.NewReader(b), desc))
+	}
+	srv := httptest.NewServer(NewServer(cs, "default"))
+	t.Cleanup(srv.Close)
+	return srv.Listener.Addr().String()
+}
+
+// upstreamResolver is a resolver of a registry that has all the blobs.
+type upstreamResolver struct {
+	remotes.Resolver
+	blobs   map[digest.Digest][]byte
+	fetched []digest.Digest
+}
+
+func (r *upstreamResolver) Fetcher(ctx context.Context, ref string) (remotes.Fetcher, error) {
+	return remotes.FetcherFunc(func(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+		r.fetched = append(r.fetched, desc.Digest)
+		return io.NopCloser(bytes.NewReader(r.blobs[desc.Digest])), nil
+	}), nil
+}
+
+func fetch(t *testing.T, r remotes.Resolver, desc ocispec.Descriptor) ([]byte, error) {
+	t.Helper()
+	ctx := context.Background()
+	f, err := r.Fetcher(ctx, "example.com/foo:latest")
+	assert.NilError(t, err)
+	rc, err := f.Fetch(ctx, desc)
+	assert.NilError(t, err)
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func TestServer(t *testing.T) {
+	blob := []byte("blob")
+	peer := newPeer(t, blob)
+
+	resp, err := http.Get("http://" + peer + blobsPath + digest.FromBytes(blob).String())
+	assert.NilError(t, err)
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.NilError(t, err)
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+	assert.DeepEqual(t, b, blob)
+
+	for path, status := range map[string]int{
+		blobsPath + digest.FromString("missing").String(): http.StatusNotFound,
+		blobsPath + "invalid":                             http.StatusBadRequest,
+		"/v2/":                                            http.StatusNotFound,
+	} {
+		resp, err := http.Get("http://" + peer + path)
+		assert.NilError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, resp.StatusCode, status, path)
+	}
+}
+
+func TestWrapResolver(t *testing.T) {
+	onPeer, notOnPeer := []byte("on the peer"), []byte("not on the peer")
+	upstream := &upstreamResolver{blobs: map[digest.Digest][]byte{
+		digest.FromBytes(onPeer):    onPeer,
+		digest.FromBytes(notOnPeer): notOnPeer,
+	}}
+	assert.Equal(t, wrapResolver(upstream, nil), remotes.Resolver(upstream))
+
+	// The peer that is down is skipped
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	down := l.Addr().String()
+	l.Close()
+	r := wrapResolver(upstream, []string{down, newPeer(t, onPeer)})
+
+	b, err := fetch(t, r, descriptor(onPeer))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, b, onPeer)
+	assert.Equal(t, len(upstream.fetched), 0)
+
+	b, err = fetch(t, r, descriptor(notOnPeer))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, b, notOnPeer)
+	assert.DeepEqual(t, upstream.fetched, []digest.Digest{digest.FromBytes(notOnPeer)})
+}
+
+func TestWrapResolverBrokenPeer(t *testing.T) {
+	blob := []byte("blob")
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "4")
+		w.Write([]byte("bad!"))
+	}))
+	t.Cleanup(broken.Close)
+	r := wrapResolver(&upstreamResolver{}, []string{broken.Listener.Addr().String()})
+	_, err := fetch(t, r, descriptor(blob))
+	assert.ErrorContains(t, err, "does not match the digest")
+}
+
+func TestPeers(t *testing.T) {
+	ctx := context.Background()
+	peers := []string{"192.168.1.10:5055", "192.168.1.11:5055", "192.168.1.10:5055"}
+	assert.Assert(t, Peers(ctx, config.P2PConfig{Peers: peers}) == nil)
+	assert.DeepEqual(t, Peers(ctx, config.P2PConfig{Enabled: true, Peers: peers}), []string{"192.168.1.10:5055", "192.168.1.11:5055"})
+	// Each call uses its own config
+	assert.DeepEqual(t, Peers(ctx, config.P2PConfig{Enabled: true, Peers: peers[1:2]}), []string{"192.168.1.11:5055"})
+}
+
+func TestMDNS(t *testing.T) {
+	q, err := query()
+	assert.NilError(t, err)
+	resp, unicast, err := respond(q, "host.example", 5055, []net.IP{net.ParseIP("192.168.1.10"), net.ParseIP("10.0.0.1")})
+	assert.NilError(t, err)
+	assert.Assert(t, unicast)
+	addrs := parseResponse(resp, net.ParseIP("192.168.1.99"))
+	assert.DeepEqual(t, addrs, []string{"192.168.1.10:5055", "10.0.0.1:5055"})
+
+	// Without an address record, the source of the response is used
+	resp, _, err = respond(q, "host", 5055, nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, parseResponse(resp, net.ParseIP("192.168.1.99")), []string{"192.168.1.99:5055"})
+
+	// The responses and the queries of the other services are not answered
+	resp, _, err = respond(resp, "host", 5055, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, resp == nil)
+	other := bytes.Replace(q, []byte("nerdctl-p2p"), []byte("nerdctl-xyz"), 1)
+	assert.Assert(t, !strings.Contains(string(other), "nerdctl-p2p"))
+	resp, _, err = respond(other, "host", 5055, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, resp == nil)
+}
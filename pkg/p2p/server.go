@@ -0,0 +1,86 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package p2p
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/pkg/namespaces"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+)
+
+// blobsPath is the path prefix of the blobs, followed by the digest.
+const blobsPath = "/v1/blobs/"
+
+// NewServer returns the HTTP handler that serves the blobs of the content store in the namespace, by digest.
+// Only GET and HEAD of "/v1/blobs/<DIGEST>" are served.
+func NewServer(cs content.Store, namespace string) http.Handler {
+	return &server{cs: cs, namespace: namespace}
+}
+
+type server struct {
+	cs        content.Store
+	namespace string
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, blobsPath) {
+		http.NotFound(w, r)
+		return
+	}
+	dgst, err := digest.Parse(strings.TrimPrefix(r.URL.Path, blobsPath))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ctx := namespaces.WithNamespace(r.Context(), s.namespace)
+	info, err := s.cs.Info(ctx, dgst)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			http.NotFound(w, r)
+			return
+		}
+		log.G(ctx).WithError(err).Warnf("failed to get %s", dgst)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	ra, err := s.cs.ReaderAt(ctx, ocispec.Descriptor{Digest: dgst, Size: info.Size})
+	if err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to read %s", dgst)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	defer ra.Close()
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Docker-Content-Digest", dgst.String())
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	log.G(ctx).Debugf("serving %s to %s", dgst, r.RemoteAddr)
+	http.ServeContent(w, r, "", time.Time{}, io.NewSectionReader(ra, 0, info.Size))
+}
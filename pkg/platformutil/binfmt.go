@@ -19,6 +19,7 @@ package platformutil
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 
 	"github.com/containerd/platforms"
@@ -42,6 +43,33 @@ func qemuArchFromOCIArch(ociArch string) (string, error) {
 	return "", fmt.Errorf("unknown OCI architecture string: %q", ociArch)
 }
 
+// binfmtArchitectures are the architectures that can be emulated with QEMU, in the order of `nerdctl builder binfmt ls`.
+var binfmtArchitectures = []string{"amd64", "arm64", "386", "arm", "s390x", "ppc64le", "riscv64", "mips64", "mips64le", "loong64"}
+
+// binfmtHandler returns the name of the handler in /proc/sys/fs/binfmt_misc for the platform, or an empty string if
+// no handler is registered.
+func binfmtHandler(p platforms.Platform) (string, error) {
+	qemuArch, err := qemuArchFromOCIArch(p.Architecture)
+	if err != nil {
+		return "", err
+	}
+	candidates := []string{
+		"qemu-" + qemuArch,
+		"buildkit-qemu-" + qemuArch,
+	}
+	// Rosetta 2 for Linux on ARM Mac
+	// https://developer.apple.com/documentation/virtualization/running_intel_binaries_in_linux_vms_with_rosetta
+	if runtime.GOARCH == "arm64" && p.Architecture == "amd64" {
+		candidates = append(candidates, "rosetta")
+	}
+	for _, cand := range candidates {
+		if _, err := os.Stat(filepath.Join("/proc/sys/fs/binfmt_misc", cand)); err == nil {
+			return cand, nil
+		}
+	}
+	return "", nil
+}
+
 func canExecProbably(s string) (bool, error) {
 	if s == "" {
 		return true, nil
@@ -54,26 +82,59 @@ func canExecProbably(s string) (bool, error) {
 		return true, nil
 	}
 	if runtime.GOOS == "linux" {
-		qemuArch, err := qemuArchFromOCIArch(p.Architecture)
+		handler, err := binfmtHandler(p)
 		if err != nil {
 			return false, err
 		}
-		candidates := []string{
-			"/proc/sys/fs/binfmt_misc/qemu-" + qemuArch,
-			"/proc/sys/fs/binfmt_misc/buildkit-qemu-" + qemuArch,
+		return handler != "", nil
+	}
+	return false, nil
+}
+
+// MissingEmulators returns the platforms in ss that can probably not be executed on the host,
+// neither natively nor with an emulator registered in binfmt_misc.
+func MissingEmulators(ss ...string) ([]string, error) {
+	var missing []string
+	for _, s := range ss {
+		ok, err := canExecProbably(s)
+		if err != nil {
+			return nil, err
 		}
-		// Rosetta 2 for Linux on ARM Mac
-		// https://developer.apple.com/documentation/virtualization/running_intel_binaries_in_linux_vms_with_rosetta
-		if runtime.GOARCH == "arm64" && p.Architecture == "amd64" {
-			candidates = append(candidates, "/proc/sys/fs/binfmt_misc/rosetta")
+		if !ok {
+			missing = append(missing, s)
 		}
-		for _, cand := range candidates {
-			if _, err := os.Stat(cand); err == nil {
-				return true, nil
+	}
+	return missing, nil
+}
+
+// Emulator describes how a platform is executed on the host.
+type Emulator struct {
+	Platform string
+	// Native is true when the platform is executed without emulation.
+	Native bool
+	// Handler is the name of the handler registered in /proc/sys/fs/binfmt_misc (e.g., "qemu-aarch64"),
+	// or an empty string if the platform cannot be executed.
+	Handler string
+}
+
+// Emulators returns how the platforms that can be emulated with QEMU are executed on the host.
+func Emulators() ([]Emulator, error) {
+	emulators := make([]Emulator, 0, len(binfmtArchitectures))
+	for _, arch := range binfmtArchitectures {
+		p := platforms.Platform{OS: "linux", Architecture: arch}
+		e := Emulator{Platform: platforms.Format(p)}
+		if platforms.Default().Match(p) {
+			e.Native = true
+		} else if runtime.GOOS == "linux" {
+			handler, err := binfmtHandler(p)
+			if err != nil {
+				return nil, err
 			}
+			e.Handler = handler
 		}
+		emulators = append(emulators, e)
 	}
-	return false, nil
+	return emulators, nil
 }
 
 func CanExecProbably(ss ...string) (bool, error) {
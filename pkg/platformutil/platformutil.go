@@ -84,3 +84,35 @@ func NormalizeString(s string) (string, error) {
 	normalized := platforms.Normalize(parsed)
 	return platforms.Format(normalized), nil
 }
+
+// AttestationPlatform is the platform of the attestation manifests (provenance, SBOM)
+// that BuildKit stores in an image index alongside the image manifests.
+var AttestationPlatform = ocispec.Platform{OS: "unknown", Architecture: "unknown"}
+
+// WithAttestations returns a MatchComparer that matches the attestation manifests in addition to
+// the platforms matched by mc.
+// The attestation manifests are always ordered after the platforms matched by mc, so that they are
+// never chosen as the manifest to unpack.
+func WithAttestations(mc platforms.MatchComparer) platforms.MatchComparer {
+	return attestationMatchComparer{mc}
+}
+
+type attestationMatchComparer struct {
+	platforms.MatchComparer
+}
+
+func isAttestationPlatform(p ocispec.Platform) bool {
+	return p.OS == AttestationPlatform.OS && p.Architecture == AttestationPlatform.Architecture
+}
+
+func (m attestationMatchComparer) Match(p ocispec.Platform) bool {
+	return isAttestationPlatform(p) || m.MatchComparer.Match(p)
+}
+
+func (m attestationMatchComparer) Less(p1, p2 ocispec.Platform) bool {
+	a1, a2 := isAttestationPlatform(p1), isAttestationPlatform(p2)
+	if a1 != a2 {
+		return a2
+	}
+	return m.MatchComparer.Less(p1, p2)
+}
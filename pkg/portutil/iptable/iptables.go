@@ -45,3 +45,33 @@ func ParseIPTableRules(rules []string) []uint64 {
 
 	return ports
 }
+
+// DNATRule is a port forwarding rule written by the CNI portmap plugin.
+type DNATRule struct {
+	// Network is the name of the CNI network.
+	Network string
+	// ContainerID is the CNI container ID, i.e., "<namespace>-<container ID>" for nerdctl.
+	ContainerID string
+	Ports       []uint64
+}
+
+// ParseDNATRules parses the owners of the rules of the CNI-HOSTPORT-DNAT chain,
+// from the comments written by the CNI portmap plugin.
+// Rules without such a comment are skipped.
+func ParseDNATRules(rules []string) []DNATRule {
+	commentRegex := regexp.MustCompile(`dnat name: \\?"([^"\\]*)\\?" id: \\?"([^"\\]*)\\?"`)
+
+	var res []DNATRule
+	for _, rule := range rules {
+		matches := commentRegex.FindStringSubmatch(rule)
+		if len(matches) != 3 {
+			continue
+		}
+		res = append(res, DNATRule{
+			Network:     matches[1],
+			ContainerID: matches[2],
+			Ports:       ParseIPTableRules([]string{rule}),
+		})
+	}
+	return res
+}
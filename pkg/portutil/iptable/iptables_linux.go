@@ -28,6 +28,21 @@ func ReadIPTables(table string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	return readRules(ipt, table)
+}
+
+// ReadIP6Tables is the IPv6 counterpart of ReadIPTables.
+// The portmap plugin programs ip6tables for the containers that have an IPv6 address.
+func ReadIP6Tables(table string) ([]string, error) {
+	ipt, err := iptables.NewWithProtocol(iptables.ProtocolIPv6)
+	if err != nil {
+		return nil, err
+	}
+	return readRules(ipt, table)
+}
+
+func readRules(ipt *iptables.IPTables, table string) ([]string, error) {
+	var err error
 
 	var rules []string
 	chainExists, _ := ipt.ChainExists(table, cniDnatChain)
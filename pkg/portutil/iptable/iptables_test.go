@@ -58,6 +58,24 @@ func TestParseIPTableRules(t *testing.T) {
 	}
 }
 
+func TestParseDNATRules(t *testing.T) {
+	rules := []string{
+		"-N CNI-HOSTPORT-DNAT",
+		"-A CNI-HOSTPORT-DNAT -p tcp -m comment --comment \"dnat name: \"bridge\" id: \"default-foo\"\" -m multiport --dports 8080,8443 -j CNI-DN-some-hash",
+		`-A CNI-HOSTPORT-DNAT -p udp -m comment --comment "dnat name: \"net1\" id: \"ns1-bar\"" -m multiport --dports 53 -j CNI-DN-other-hash`,
+	}
+	got := ParseDNATRules(rules)
+	if len(got) != 2 {
+		t.Fatalf("ParseDNATRules(%v) returned %d rules; want 2", rules, len(got))
+	}
+	if got[0].Network != "bridge" || got[0].ContainerID != "default-foo" || !equal(got[0].Ports, []uint64{8080, 8443}) {
+		t.Errorf("unexpected rule %+v", got[0])
+	}
+	if got[1].Network != "net1" || got[1].ContainerID != "ns1-bar" || !equal(got[1].Ports, []uint64{53}) {
+		t.Errorf("unexpected rule %+v", got[1])
+	}
+}
+
 func equal(a, b []uint64) bool {
 	if len(a) != len(b) {
 		return false
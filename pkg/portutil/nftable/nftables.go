@@ -0,0 +1,97 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package nftable reads the port forwarding rules written by the nftables backend of the CNI portmap plugin.
+package nftable
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// TableName is the name of the table used by the CNI portmap plugin, in the "ip" and "ip6" families.
+// https://www.cni.dev/plugins/current/meta/portmap/
+const TableName = "cni_hostport"
+
+// Rule is a port forwarding rule written by the CNI portmap plugin.
+type Rule struct {
+	Family string
+	Chain  string
+	// ContainerID is the CNI container ID, i.e., "<namespace>-<container ID>" for nerdctl.
+	// The portmap plugin stores it in the comment of the rule.
+	ContainerID string
+	Ports       []uint64
+}
+
+type listOutput struct {
+	Nftables []struct {
+		Rule *struct {
+			Family  string            `json:"family"`
+			Table   string            `json:"table"`
+			Chain   string            `json:"chain"`
+			Comment string            `json:"comment"`
+			Expr    []json.RawMessage `json:"expr"`
+		} `json:"rule,omitempty"`
+	} `json:"nftables"`
+}
+
+type matchExpr struct {
+	Match *struct {
+		Left struct {
+			Payload *struct {
+				Field string `json:"field"`
+			} `json:"payload,omitempty"`
+		} `json:"left"`
+		Right json.RawMessage `json:"right"`
+	} `json:"match,omitempty"`
+}
+
+// ParseRules parses the output of `nft -j list table <FAMILY> cni_hostport`.
+// Rules without a comment (i.e., not owned by a container) are skipped.
+func ParseRules(b []byte) ([]Rule, error) {
+	var out listOutput
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	var res []Rule
+	for _, o := range out.Nftables {
+		r := o.Rule
+		if r == nil || r.Table != TableName || r.Comment == "" {
+			continue
+		}
+		rule := Rule{
+			Family:      r.Family,
+			Chain:       r.Chain,
+			ContainerID: r.Comment,
+		}
+		for _, raw := range r.Expr {
+			var e matchExpr
+			if err := json.Unmarshal(raw, &e); err != nil || e.Match == nil {
+				continue
+			}
+			if e.Match.Left.Payload == nil || e.Match.Left.Payload.Field != "dport" {
+				continue
+			}
+			var port uint64
+			if err := json.Unmarshal(e.Match.Right, &port); err == nil {
+				rule.Ports = append(rule.Ports, port)
+			}
+		}
+		sort.Slice(rule.Ports, func(i, j int) bool { return rule.Ports[i] < rule.Ports[j] })
+		res = append(res, rule)
+	}
+	return res, nil
+}
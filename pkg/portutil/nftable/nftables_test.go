@@ -0,0 +1,44 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package nftable
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseRules(t *testing.T) {
+	const out = `{"nftables": [
+{"metainfo": {"version": "1.0.9", "release_name": "Old Doc Yak #3", "json_schema_version": 1}},
+{"table": {"family": "ip", "name": "cni_hostport", "handle": 3, "comment": "CNI portmap plugin"}},
+{"chain": {"family": "ip", "table": "cni_hostport", "name": "hostports", "handle": 1}},
+{"rule": {"family": "ip", "table": "cni_hostport", "chain": "prerouting", "handle": 5, "expr": [{"jump": {"target": "hostports"}}]}},
+{"rule": {"family": "ip", "table": "cni_hostport", "chain": "hostports", "handle": 9, "comment": "default-foo",
+  "expr": [{"match": {"op": "==", "left": {"meta": {"key": "l4proto"}}, "right": "tcp"}},
+           {"match": {"op": "==", "left": {"payload": {"protocol": "tcp", "field": "dport"}}, "right": 8080}},
+           {"dnat": {"addr": "10.4.0.2", "port": 80}}]}},
+{"rule": {"family": "ip", "table": "cni_hostport", "chain": "masquerading", "handle": 10, "comment": "default-foo",
+  "expr": [{"masquerade": null}]}}
+]}`
+	rules, err := ParseRules([]byte(out))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, rules, []Rule{
+		{Family: "ip", Chain: "hostports", ContainerID: "default-foo", Ports: []uint64{8080}},
+		{Family: "ip", Chain: "masquerading", ContainerID: "default-foo"},
+	})
+}
@@ -0,0 +1,98 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package pf publishes the ports of the containers on FreeBSD with the pf(4) packet filter.
+// The redirection rules of a container are loaded into its own anchor under RootAnchor,
+// so that pf.conf(5) needs to refer to the anchors only once:
+//
+//	rdr-anchor "nerdctl/*"
+package pf
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/go-cni"
+)
+
+// RootAnchor is the anchor of the anchors of the containers.
+const RootAnchor = "nerdctl"
+
+// anchorIDLength is the length of the container ID in the anchor name.
+// An anchor name is limited to 63 characters.
+const anchorIDLength = 32
+
+// Anchor returns the anchor of the redirection rules of the container.
+func Anchor(containerID string) string {
+	if len(containerID) > anchorIDLength {
+		containerID = containerID[:anchorIDLength]
+	}
+	return RootAnchor + "/" + containerID
+}
+
+// RedirectRules returns the rdr rules in the pf.conf(5) format for publishing the ports of the container.
+// The ports published on an unspecified host IP are redirected from all the addresses of the host.
+func RedirectRules(ports []cni.PortMapping, containerIP net.IP) (string, error) {
+	if containerIP == nil {
+		return "", fmt.Errorf("no container IP to redirect the ports to")
+	}
+	family := "inet"
+	if containerIP.To4() == nil {
+		family = "inet6"
+	}
+	var sb strings.Builder
+	for _, p := range ports {
+		switch p.Protocol {
+		case "tcp", "udp":
+		default:
+			return "", fmt.Errorf("protocol %q is not supported for publishing ports with pf", p.Protocol)
+		}
+		to := "self"
+		if hostIP := net.ParseIP(p.HostIP); hostIP != nil && !hostIP.IsUnspecified() {
+			if (hostIP.To4() == nil) != (family == "inet6") {
+				// The address families of the host IP and the container IP do not match
+				continue
+			}
+			to = hostIP.String()
+		}
+		fmt.Fprintf(&sb, "rdr pass %s proto %s from any to %s port %d -> %s port %d\n",
+			family, p.Protocol, to, p.HostPort, containerIP, p.ContainerPort)
+	}
+	return sb.String(), nil
+}
+
+var rdrRegex = regexp.MustCompile(`^rdr .*proto (\w+) from .* port =? ?(\d+) -> `)
+
+// ParseRedirectedPorts parses the host ports of the protocol from the rdr rules,
+// as printed by `pfctl -s nat`.
+func ParseRedirectedPorts(rules string, protocol string) []uint64 {
+	ports := []uint64{}
+	for _, rule := range strings.Split(rules, "\n") {
+		matches := rdrRegex.FindStringSubmatch(strings.TrimSpace(rule))
+		if len(matches) != 3 || matches[1] != protocol {
+			continue
+		}
+		port, err := strconv.ParseUint(matches[2], 10, 16)
+		if err != nil {
+			continue
+		}
+		ports = append(ports, port)
+	}
+	return ports
+}
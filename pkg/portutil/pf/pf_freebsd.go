@@ -0,0 +1,65 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package pf
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+func pfctl(stdin string, args ...string) (string, error) {
+	cmd := exec.Command("pfctl", args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to run %v: %w (output=%q)", cmd.Args, err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+// Load replaces the rules of the anchor.
+// pf needs to be enabled, with `rdr-anchor "nerdctl/*"` in pf.conf(5).
+func Load(anchor, rules string) error {
+	_, err := pfctl(rules, "-a", anchor, "-f", "-")
+	return err
+}
+
+// Flush removes the rules of the anchor.
+func Flush(anchor string) error {
+	_, err := pfctl("", "-a", anchor, "-F", "all")
+	return err
+}
+
+// ReadRedirectRules returns the rdr rules of all the anchors under RootAnchor.
+func ReadRedirectRules() (string, error) {
+	anchors, err := pfctl("", "-a", RootAnchor, "-s", "Anchors")
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, anchor := range strings.Fields(anchors) {
+		rules, err := pfctl("", "-a", anchor, "-s", "nat")
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(rules)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,59 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package pf
+
+import (
+	"net"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/go-cni"
+)
+
+func TestAnchor(t *testing.T) {
+	assert.Equal(t, Anchor("abc"), "nerdctl/abc")
+	assert.Equal(t, Anchor("0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"), "nerdctl/0123456789abcdef0123456789abcdef")
+}
+
+func TestRedirectRules(t *testing.T) {
+	rules, err := RedirectRules([]cni.PortMapping{
+		{HostPort: 8080, ContainerPort: 80, Protocol: "tcp"},
+		{HostPort: 5353, ContainerPort: 53, Protocol: "udp", HostIP: "192.168.1.2"},
+		{HostPort: 8443, ContainerPort: 443, Protocol: "tcp", HostIP: "::1"},
+	}, net.ParseIP("10.4.0.2"))
+	assert.NilError(t, err)
+	assert.Equal(t, rules, "rdr pass inet proto tcp from any to self port 8080 -> 10.4.0.2 port 80\n"+
+		"rdr pass inet proto udp from any to 192.168.1.2 port 5353 -> 10.4.0.2 port 53\n")
+
+	_, err = RedirectRules([]cni.PortMapping{{HostPort: 9, ContainerPort: 9, Protocol: "sctp"}}, net.ParseIP("10.4.0.2"))
+	assert.ErrorContains(t, err, `protocol "sctp" is not supported`)
+
+	_, err = RedirectRules([]cni.PortMapping{{HostPort: 80, ContainerPort: 80, Protocol: "tcp"}}, nil)
+	assert.ErrorContains(t, err, "no container IP")
+}
+
+func TestParseRedirectedPorts(t *testing.T) {
+	rules := `rdr pass inet proto tcp from any to any port = 8080 -> 10.4.0.2 port 80
+rdr pass inet proto udp from any to 192.168.1.2 port = 5353 -> 10.4.0.2 port 53
+rdr pass inet proto tcp from any to self port 8443 -> 10.4.0.3 port 443
+nat on em0 inet from 10.4.0.0/24 to any -> (em0) round-robin
+`
+	assert.DeepEqual(t, ParseRedirectedPorts(rules, "tcp"), []uint64{8080, 8443})
+	assert.DeepEqual(t, ParseRedirectedPorts(rules, "udp"), []uint64{5353})
+	assert.DeepEqual(t, ParseRedirectedPorts("", "tcp"), []uint64{})
+}
@@ -0,0 +1,39 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package portutil
+
+import (
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/portutil/pf"
+)
+
+// redirectedPorts returns the host ports published by the pf rdr rules of the containers.
+// A redirected port is not bound on the host, so it is not detected by portAvailable.
+func redirectedPorts(protocol string) (map[uint64]bool, error) {
+	rules, err := pf.ReadRedirectRules()
+	if err != nil {
+		// pf may legitimately be disabled when no container publishes a port.
+		log.L.WithError(err).Debug("failed to read pf rules, ignoring")
+		return nil, nil
+	}
+	usedPort := make(map[uint64]bool)
+	for _, port := range pf.ParseRedirectedPorts(rules, protocol) {
+		usedPort[port] = true
+	}
+	return usedPort, nil
+}
@@ -18,8 +18,12 @@ package portutil
 
 import (
 	"fmt"
+	"net"
+
+	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/portutil/iptable"
+	"github.com/containerd/nerdctl/v2/pkg/portutil/nftable"
 	"github.com/containerd/nerdctl/v2/pkg/portutil/procnet"
 )
 
@@ -32,6 +36,13 @@ var (
 	allocateStart = 49153
 )
 
+// The readers of the port forwarding rules, replaced in tests.
+var (
+	readIPTables  = iptable.ReadIPTables
+	readIP6Tables = iptable.ReadIP6Tables
+	readNftRules  = nftable.ReadRules
+)
+
 func filter(ss []procnet.NetworkDetail, filterFunc func(detail procnet.NetworkDetail) bool) (ret []procnet.NetworkDetail) {
 	for _, s := range ss {
 		if filterFunc(s) {
@@ -41,10 +52,56 @@ func filter(ss []procnet.NetworkDetail, filterFunc func(detail procnet.NetworkDe
 	return
 }
 
-func portAllocate(protocol string, ip string, count uint64) (uint64, uint64, error) {
+// forwardedPorts returns the host ports forwarded by the CNI portmap plugin,
+// with either the iptables or the nftables backend.
+// A missing iptables, ip6tables, or nft binary is not an error, as the host may use only one of the backends.
+func forwardedPorts(ip string) []uint64 {
+	needIPv6 := ip == "" || net.ParseIP(ip).To4() == nil
+	var res []uint64
+	ipTableItems, err := readIPTables("nat")
+	if err != nil {
+		log.L.WithError(err).Debug("failed to read iptables rules, ignoring")
+	}
+	res = append(res, iptable.ParseIPTableRules(ipTableItems)...)
+	if needIPv6 {
+		// ip6tables may legitimately be missing on hosts without IPv6 networks.
+		ip6TableItems, err := readIP6Tables("nat")
+		if err != nil {
+			log.L.WithError(err).Debug("failed to read ip6tables rules, ignoring")
+		}
+		res = append(res, iptable.ParseIPTableRules(ip6TableItems)...)
+	}
+	families := []string{"ip"}
+	if needIPv6 {
+		families = append(families, "ip6")
+	}
+	for _, family := range families {
+		rules, err := readNftRules(family)
+		if err != nil {
+			log.L.WithError(err).Debugf("failed to read the %s rules of nftables, ignoring", family)
+			continue
+		}
+		for _, r := range rules {
+			res = append(res, r.Ports...)
+		}
+	}
+	return res
+}
+
+// PortInUse reports whether the host port is bound on the host, or forwarded to a container by the CNI portmap plugin.
+func PortInUse(protocol string, ip string, port uint64) (bool, error) {
+	usedPort, err := usedPorts(protocol, ip)
+	if err != nil {
+		return false, err
+	}
+	return usedPort[port], nil
+}
+
+// usedPorts returns the host ports bound on the host, and the ones forwarded to the containers.
+func usedPorts(protocol string, ip string) (map[uint64]bool, error) {
 	netprocData, err := procnet.ReadStatsFileData(protocol)
 	if err != nil {
-		return 0, 0, err
+		return nil, err
 	}
 	netprocItems := procnet.Parse(netprocData)
 	// In some circumstances, when we bind address like "0.0.0.0:80", we will get the formation of ":::80" in /proc/net/tcp6.
@@ -52,14 +109,14 @@ func portAllocate(protocol string, ip string, count uint64) (uint64, uint64, err
 	if protocol == "tcp" {
 		tempTCPV6Data, err := procnet.ReadStatsFileData("tcp6")
 		if err != nil {
-			return 0, 0, err
+			return nil, err
 		}
 		netprocItems = append(netprocItems, procnet.Parse(tempTCPV6Data)...)
 	}
 	if protocol == "udp" {
 		tempUDPV6Data, err := procnet.ReadStatsFileData("udp6")
 		if err != nil {
-			return 0, 0, err
+			return nil, err
 		}
 		netprocItems = append(netprocItems, procnet.Parse(tempUDPV6Data)...)
 	}
@@ -76,14 +133,16 @@ func portAllocate(protocol string, ip string, count uint64) (uint64, uint64, err
 		usedPort[value.LocalPort] = true
 	}
 
-	ipTableItems, err := iptable.ReadIPTables("nat")
-	if err != nil {
-		return 0, 0, err
+	for _, port := range forwardedPorts(ip) {
+		usedPort[port] = true
 	}
-	destinationPorts := iptable.ParseIPTableRules(ipTableItems)
+	return usedPort, nil
+}
 
-	for _, port := range destinationPorts {
-		usedPort[port] = true
+func portAllocate(protocol string, ip string, count uint64) (uint64, uint64, error) {
+	usedPort, err := usedPorts(protocol, ip)
+	if err != nil {
+		return 0, 0, err
 	}
 
 	start := uint64(allocateStart)
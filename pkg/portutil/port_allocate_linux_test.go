@@ -0,0 +1,69 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package portutil
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/portutil/nftable"
+)
+
+func nftHostportsRule(family string, port uint64) string {
+	return fmt.Sprintf(`{"nftables": [
+{"table": {"family": %[1]q, "name": "cni_hostport", "handle": 3}},
+{"rule": {"family": %[1]q, "table": "cni_hostport", "chain": "hostports", "handle": 9, "comment": "default-foo",
+  "expr": [{"match": {"op": "==", "left": {"payload": {"protocol": "tcp", "field": "dport"}}, "right": %[2]d}},
+           {"dnat": {"addr": "10.4.0.2", "port": 80}}]}}
+]}`, family, port)
+}
+
+func TestPortAllocateNftables(t *testing.T) {
+	origStart, origIP, origIP6, origNft := allocateStart, readIPTables, readIP6Tables, readNftRules
+	t.Cleanup(func() {
+		allocateStart, readIPTables, readIP6Tables, readNftRules = origStart, origIP, origIP6, origNft
+	})
+	// Simulate a host with only the nftables backend.
+	notFound := func(string) ([]string, error) { return nil, errors.New("iptables: executable file not found in $PATH") }
+	readIPTables, readIP6Tables = notFound, notFound
+	readNftRules = func(family string) ([]nftable.Rule, error) {
+		port := map[string]uint64{"ip": 59001, "ip6": 59002}[family]
+		return nftable.ParseRules([]byte(nftHostportsRule(family, port)))
+	}
+
+	allocateStart = 59001
+	start, end, err := portAllocate("tcp", "", 1)
+	assert.NilError(t, err)
+	assert.Equal(t, start, uint64(59003))
+	assert.Equal(t, end, uint64(59003))
+
+	// The ip6 rules do not matter for an IPv4 address.
+	allocateStart = 59001
+	start, _, err = portAllocate("tcp", "192.0.2.1", 1)
+	assert.NilError(t, err)
+	assert.Equal(t, start, uint64(59002))
+
+	inUse, err := PortInUse("tcp", "", 59002)
+	assert.NilError(t, err)
+	assert.Assert(t, inUse)
+	inUse, err = PortInUse("tcp", "192.0.2.1", 59002)
+	assert.NilError(t, err)
+	assert.Assert(t, !inUse)
+}
@@ -0,0 +1,94 @@
+//go:build freebsd || windows
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package portutil
+
+import (
+	"fmt"
+	"net"
+	"runtime"
+	"strconv"
+)
+
+const (
+	// Same range as Linux, which is compatible with Docker.
+	allocateEnd = 60999
+)
+
+var (
+	allocateStart = 49153
+)
+
+// portAvailable reports whether the port can be bound on the host.
+// These platforms have no procfs to inspect, so the port is probed with a listener.
+func portAvailable(protocol string, ip string, port uint64) bool {
+	addr := net.JoinHostPort(ip, strconv.FormatUint(port, 10))
+	switch protocol {
+	case "udp":
+		c, err := net.ListenPacket("udp", addr)
+		if err != nil {
+			return false
+		}
+		c.Close()
+	default:
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			return false
+		}
+		l.Close()
+	}
+	return true
+}
+
+// PortInUse reports whether the host port is bound on the host, or redirected to a container.
+func PortInUse(protocol string, ip string, port uint64) (bool, error) {
+	usedPort, err := redirectedPorts(protocol)
+	if err != nil {
+		return false, err
+	}
+	return usedPort[port] || !portAvailable(protocol, ip, port), nil
+}
+
+func portAllocate(protocol string, ip string, count uint64) (uint64, uint64, error) {
+	if protocol != "tcp" && protocol != "udp" {
+		return 0, 0, fmt.Errorf("auto port allocate does not support protocol %q on %s", protocol, runtime.GOOS)
+	}
+	usedPort, err := redirectedPorts(protocol)
+	if err != nil {
+		return 0, 0, err
+	}
+	start := uint64(allocateStart)
+	if count > uint64(allocateEnd-allocateStart+1) {
+		return 0, 0, fmt.Errorf("can not allocate %d ports", count)
+	}
+	for start < allocateEnd {
+		needReturn := true
+		for i := start; i < start+count; i++ {
+			if usedPort[i] || !portAvailable(protocol, ip, i) {
+				needReturn = false
+				break
+			}
+		}
+		if needReturn {
+			allocateStart = int(start + count)
+			return start, start + count - 1, nil
+		}
+		start += count
+	}
+	return 0, 0, fmt.Errorf("there is not enough %d free ports", count)
+}
@@ -1,4 +1,4 @@
-//go:build !linux
+//go:build !(linux || windows || freebsd)
 
 /*
    Copyright The containerd Authors.
@@ -20,6 +20,11 @@ package portutil
 
 import "fmt"
 
+// PortInUse always reports false, as the host ports cannot be inspected on this platform.
+func PortInUse(protocol string, ip string, port uint64) (bool, error) {
+	return false, nil
+}
+
 func portAllocate(protocol string, ip string, count uint64) (uint64, uint64, error) {
 	return 0, 0, fmt.Errorf("auto port allocate are not support Non-Linux platform yet")
 }
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"sort"
 	"strings"
 
 	"github.com/docker/go-connections/nat"
@@ -129,6 +130,30 @@ func ParseFlagP(s string) ([]cni.PortMapping, error) {
 	return mr, nil
 }
 
+// ExposedPortsToPublish returns the exposed ports of an image config (e.g., "80/tcp") that
+// are not published yet, sorted, in the form accepted by ParseFlagP.
+// This is used for `--publish-all`, so the host ports are left to be allocated automatically.
+func ExposedPortsToPublish(exposedPorts map[string]struct{}, published []cni.PortMapping) []string {
+	publishedPorts := make(map[string]struct{}, len(published))
+	for _, p := range published {
+		publishedPorts[fmt.Sprintf("%d/%s", p.ContainerPort, p.Protocol)] = struct{}{}
+	}
+	var res []string
+	for exposed := range exposedPorts {
+		port, proto, _ := strings.Cut(exposed, "/")
+		if proto == "" {
+			proto = "tcp"
+		}
+		spec := port + "/" + strings.ToLower(proto)
+		if _, ok := publishedPorts[spec]; ok {
+			continue
+		}
+		res = append(res, spec)
+	}
+	sort.Strings(res)
+	return res
+}
+
 // ParsePortsLabel parses JSON-marshalled string from label map
 // (under `labels.Ports` key) and returns []cni.PortMapping.
 func ParsePortsLabel(labelMap map[string]string) ([]cni.PortMapping, error) {
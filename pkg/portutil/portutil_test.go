@@ -465,3 +465,21 @@ func TestParseFlagP(t *testing.T) {
 		})
 	}
 }
+
+func TestExposedPortsToPublish(t *testing.T) {
+	exposed := map[string]struct{}{
+		"80/tcp":   {},
+		"443":      {},
+		"53/UDP":   {},
+		"8080/tcp": {},
+	}
+	published := []cni.PortMapping{
+		{HostPort: 8080, ContainerPort: 8080, Protocol: "tcp", HostIP: "0.0.0.0"},
+		{HostPort: 5353, ContainerPort: 53, Protocol: "tcp", HostIP: "0.0.0.0"},
+	}
+	got := ExposedPortsToPublish(exposed, published)
+	want := []string{"443/tcp", "53/udp", "80/tcp"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExposedPortsToPublish() = %v, want %v", got, want)
+	}
+}
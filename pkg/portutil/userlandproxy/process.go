@@ -0,0 +1,141 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package userlandproxy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	types100 "github.com/containernetworking/cni/pkg/types/100"
+
+	"github.com/containerd/go-cni"
+	"github.com/containerd/log"
+)
+
+// pidFilePrefix is the prefix of the pid files of the proxies, in the state directory of the container.
+const pidFilePrefix = "userland-proxy-"
+
+// FromPortMappings returns the proxies for the port mappings, forwarding to the first
+// address of the container (in the CNI results) in the same family as the host IP.
+func FromPortMappings(ports []cni.PortMapping, results []*types100.Result) ([]*Proxy, error) {
+	proxies := make([]*Proxy, 0, len(ports))
+	for _, p := range ports {
+		containerIP := containerIPForHostIP(results, p.HostIP)
+		if containerIP == "" {
+			return nil, fmt.Errorf("no suitable container address for the userland proxy of port %d/%s", p.HostPort, p.Protocol)
+		}
+		proxies = append(proxies, &Proxy{
+			Protocol:      p.Protocol,
+			HostIP:        p.HostIP,
+			HostPort:      int(p.HostPort),
+			ContainerIP:   containerIP,
+			ContainerPort: int(p.ContainerPort),
+		})
+	}
+	return proxies, nil
+}
+
+func containerIPForHostIP(results []*types100.Result, hostIP string) string {
+	wantIPv6 := false
+	if ip := net.ParseIP(hostIP); ip != nil && ip.To4() == nil {
+		wantIPv6 = true
+	}
+	for _, res := range results {
+		if res == nil {
+			continue
+		}
+		for _, ipc := range res.IPs {
+			if (ipc.Address.IP.To4() == nil) == wantIPv6 {
+				return ipc.Address.IP.String()
+			}
+		}
+	}
+	return ""
+}
+
+func (p *Proxy) pidFile(stateDir string) string {
+	return filepath.Join(stateDir, fmt.Sprintf("%s%s-%s-%d.pid", pidFilePrefix, p.Protocol, p.HostIP, p.HostPort))
+}
+
+// Start spawns `nerdctl internal userland-proxy` for the proxy, detached from the current process.
+// The pid is recorded in stateDir, so that the proxy can be stopped with Stop or StopAll.
+func Start(stateDir string, p *Proxy) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(exe, append([]string{"internal", "userland-proxy"}, p.Args()...)...)
+	cmd.SysProcAttr = sysProcAttr()
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start userland proxy for %s: %w", p.hostAddr(), err)
+	}
+	pidFile := p.pidFile(stateDir)
+	tmp := filepath.Join(stateDir, "."+filepath.Base(pidFile))
+	if err := os.WriteFile(tmp, []byte(strconv.Itoa(cmd.Process.Pid)), 0o644); err != nil {
+		cmd.Process.Kill()
+		return err
+	}
+	if err := os.Rename(tmp, pidFile); err != nil {
+		cmd.Process.Kill()
+		return err
+	}
+	log.L.Debugf("started userland proxy (pid=%d) for %s/%s", cmd.Process.Pid, p.hostAddr(), p.Protocol)
+	return cmd.Process.Release()
+}
+
+// Stop stops the proxy started with Start, if it is running.
+func Stop(stateDir string, p *Proxy) {
+	stop(p.pidFile(stateDir))
+}
+
+// StopAll stops all the proxies started in stateDir.
+func StopAll(stateDir string) {
+	pidFiles, err := filepath.Glob(filepath.Join(stateDir, pidFilePrefix+"*.pid"))
+	if err != nil {
+		return
+	}
+	for _, pidFile := range pidFiles {
+		stop(pidFile)
+	}
+}
+
+func stop(pidFile string) {
+	b, err := os.ReadFile(pidFile)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.L.WithError(err).Warnf("failed to read %s", pidFile)
+		}
+		return
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err == nil && isProxyProcess(pid) {
+		if proc, err := os.FindProcess(pid); err == nil {
+			if err := proc.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+				log.L.WithError(err).Warnf("failed to kill userland proxy (pid=%d)", pid)
+			}
+		}
+	}
+	if err := os.Remove(pidFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.L.WithError(err).Warnf("failed to remove %s", pidFile)
+	}
+}
@@ -0,0 +1,195 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package userlandproxy implements a minimal TCP/UDP forwarder for published ports,
+// for environments where the NAT rules of the portmap plugin cannot be hit
+// (e.g., connecting to a published port from the host itself without hairpin NAT).
+package userlandproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/containerd/log"
+)
+
+// udpIdleTimeout is the time after which an inactive UDP "connection" is forgotten.
+const udpIdleTimeout = 90 * time.Second
+
+// Proxy forwards HostIP:HostPort to ContainerIP:ContainerPort.
+type Proxy struct {
+	Protocol      string
+	HostIP        string
+	HostPort      int
+	ContainerIP   string
+	ContainerPort int
+}
+
+func (p *Proxy) hostAddr() string {
+	return net.JoinHostPort(p.HostIP, strconv.Itoa(p.HostPort))
+}
+
+func (p *Proxy) containerAddr() string {
+	return net.JoinHostPort(p.ContainerIP, strconv.Itoa(p.ContainerPort))
+}
+
+// Args returns the arguments of `nerdctl internal userland-proxy` for running the proxy.
+func (p *Proxy) Args() []string {
+	return []string{
+		"--proto", p.Protocol,
+		"--host-ip", p.HostIP,
+		"--host-port", strconv.Itoa(p.HostPort),
+		"--container-ip", p.ContainerIP,
+		"--container-port", strconv.Itoa(p.ContainerPort),
+	}
+}
+
+// Run runs the proxy until ctx is cancelled.
+func Run(ctx context.Context, p *Proxy) error {
+	if net.ParseIP(p.ContainerIP) == nil {
+		return fmt.Errorf("invalid container IP %q", p.ContainerIP)
+	}
+	switch p.Protocol {
+	case "tcp":
+		return runTCP(ctx, p)
+	case "udp":
+		return runUDP(ctx, p)
+	default:
+		return fmt.Errorf("userland proxy does not support protocol %q", p.Protocol)
+	}
+}
+
+func runTCP(ctx context.Context, p *Proxy) error {
+	var lc net.ListenConfig
+	l, err := lc.Listen(ctx, "tcp", p.hostAddr())
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go proxyTCPConn(ctx, conn.(*net.TCPConn), p.containerAddr())
+	}
+}
+
+func proxyTCPConn(ctx context.Context, client *net.TCPConn, backendAddr string) {
+	defer client.Close()
+	var d net.Dialer
+	c, err := d.DialContext(ctx, "tcp", backendAddr)
+	if err != nil {
+		log.G(ctx).WithError(err).Debugf("failed to dial %s", backendAddr)
+		return
+	}
+	backend := c.(*net.TCPConn)
+	defer backend.Close()
+
+	var wg sync.WaitGroup
+	pipe := func(dst, src *net.TCPConn) {
+		defer wg.Done()
+		io.Copy(dst, src)
+		dst.CloseWrite()
+	}
+	wg.Add(2)
+	go pipe(backend, client)
+	go pipe(client, backend)
+	wg.Wait()
+}
+
+func runUDP(ctx context.Context, p *Proxy) error {
+	var lc net.ListenConfig
+	pc, err := lc.ListenPacket(ctx, "udp", p.hostAddr())
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		pc.Close()
+	}()
+	backendAddr, err := net.ResolveUDPAddr("udp", p.containerAddr())
+	if err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	conns := make(map[string]*net.UDPConn)
+	buf := make([]byte, 65507)
+	for {
+		n, clientAddr, err := pc.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		key := clientAddr.String()
+		mu.Lock()
+		backend, ok := conns[key]
+		if !ok {
+			backend, err = net.DialUDP("udp", nil, backendAddr)
+			if err != nil {
+				mu.Unlock()
+				log.G(ctx).WithError(err).Debugf("failed to dial %s", backendAddr)
+				continue
+			}
+			conns[key] = backend
+			go func() {
+				replyUDP(pc, backend, clientAddr)
+				mu.Lock()
+				delete(conns, key)
+				mu.Unlock()
+				backend.Close()
+			}()
+		}
+		mu.Unlock()
+		backend.SetReadDeadline(time.Now().Add(udpIdleTimeout))
+		if _, err := backend.Write(buf[:n]); err != nil {
+			log.G(ctx).WithError(err).Debugf("failed to write to %s", backendAddr)
+		}
+	}
+}
+
+// replyUDP copies the replies of the backend to the client, until the backend is idle for udpIdleTimeout.
+func replyUDP(pc net.PacketConn, backend *net.UDPConn, clientAddr net.Addr) {
+	buf := make([]byte, 65507)
+	for {
+		n, err := backend.Read(buf)
+		if err != nil {
+			var netErr net.Error
+			if !errors.As(err, &netErr) || !netErr.Timeout() {
+				log.L.WithError(err).Debug("failed to read UDP reply")
+			}
+			return
+		}
+		if _, err := pc.WriteTo(buf[:n], clientAddr); err != nil {
+			return
+		}
+	}
+}
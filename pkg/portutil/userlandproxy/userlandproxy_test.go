@@ -0,0 +1,89 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package userlandproxy
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestRunTCP(t *testing.T) {
+	backend, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	defer backend.Close()
+	go func() {
+		for {
+			conn, err := backend.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	p := &Proxy{
+		Protocol:      "tcp",
+		HostIP:        "127.0.0.1",
+		HostPort:      freePort(t),
+		ContainerIP:   "127.0.0.1",
+		ContainerPort: backend.Addr().(*net.TCPAddr).Port,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- Run(ctx, p)
+	}()
+
+	var conn net.Conn
+	for i := 0; i < 50; i++ {
+		if conn, err = net.Dial("tcp", p.hostAddr()); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	assert.NilError(t, err)
+	_, err = conn.Write([]byte("hello"))
+	assert.NilError(t, err)
+	conn.(*net.TCPConn).CloseWrite()
+	b, err := io.ReadAll(conn)
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), "hello")
+	conn.Close()
+
+	cancel()
+	assert.NilError(t, <-errCh)
+}
+
+func TestRunUnsupportedProtocol(t *testing.T) {
+	p := &Proxy{Protocol: "sctp", HostIP: "127.0.0.1", HostPort: 1, ContainerIP: "127.0.0.1", ContainerPort: 1}
+	assert.ErrorContains(t, Run(context.Background(), p), "does not support protocol")
+}
@@ -0,0 +1,61 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package quota implements the size limits of directories, using the project quotas of xfs and ext4.
+//
+// The project ID of a directory is derived from its inode number, so that no state has to be kept
+// to allocate the IDs. The file system has to be mounted with the "pquota" (xfs) or "prjquota" (ext4) option.
+package quota
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/docker/go-units"
+)
+
+// ErrNotSupported is returned when the file system of a directory does not support project quotas,
+// or when project quotas are not enabled on it.
+var ErrNotSupported = errors.New("project quotas are not supported on this file system (mount it with the \"pquota\" option on xfs, or \"prjquota\" on ext4)")
+
+// ErrNoQuota is returned by Get for directories without a project quota.
+var ErrNoQuota = errors.New("no project quota is set")
+
+// Quota is the limit and the usage of a directory, in bytes.
+type Quota struct {
+	Size uint64
+	Used uint64
+}
+
+// quotaBlockSize is the unit of the block limits of fs_disk_quota, regardless of the file system block size.
+const quotaBlockSize = 512
+
+// ParseSize parses a size such as "10G" (in the 1024-based units, as with `--shm-size`).
+func ParseSize(s string) (uint64, error) {
+	size, err := units.RAMInBytes(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", s, err)
+	}
+	if size <= 0 {
+		return 0, fmt.Errorf("invalid size %q: must be positive", s)
+	}
+	return uint64(size), nil
+}
+
+// sizeToBlocks converts a size in bytes to quota blocks, rounding up.
+func sizeToBlocks(size uint64) uint64 {
+	return (size + quotaBlockSize - 1) / quotaBlockSize
+}
@@ -0,0 +1,217 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// The definitions below are from <linux/fs.h>, <linux/quota.h> and <linux/dqblk_xfs.h>,
+// which are not covered by golang.org/x/sys/unix.
+const (
+	fsIocFsGetXattr    = 0x801c581f // _IOR('X', 31, struct fsxattr)
+	fsIocFsSetXattr    = 0x401c5820 // _IOW('X', 32, struct fsxattr)
+	fsXflagProjInherit = 0x00000200
+
+	qXGetQuota     = 0x5803 // XQM_CMD(3)
+	qXSetQLim      = 0x5804 // XQM_CMD(4)
+	prjQuota       = 2
+	fsDquotVersion = 1
+	fsProjQuota    = 2 // d_flags
+	fsDqBSoft      = 1 << 2
+	fsDqBHard      = 1 << 3
+)
+
+// fsxattr is struct fsxattr.
+type fsxattr struct {
+	XFlags     uint32
+	ExtSize    uint32
+	NExtents   uint32
+	ProjID     uint32
+	CowExtSize uint32
+	Pad        [8]byte
+}
+
+// fsDiskQuota is struct fs_disk_quota.
+type fsDiskQuota struct {
+	Version      int8
+	Flags        int8
+	FieldMask    uint16
+	ID           uint32
+	BlkHardLimit uint64
+	BlkSoftLimit uint64
+	InoHardLimit uint64
+	InoSoftLimit uint64
+	BCount       uint64
+	ICount       uint64
+	ITimer       int32
+	BTimer       int32
+	IWarns       uint16
+	BWarns       uint16
+	ITimerHi     int8
+	BTimerHi     int8
+	RtbTimerHi   int8
+	Padding2     int8
+	RtbHardLimit uint64
+	RtbSoftLimit uint64
+	RtbCount     uint64
+	RtbTimer     int32
+	RtbWarns     uint16
+	Padding3     int16
+	Padding4     [8]byte
+}
+
+// Set sets the size limit of the directory, in bytes.
+// The limit covers the files created in the directory after the call, as the project ID is inherited on creation.
+func Set(dir string, size uint64) error {
+	var st unix.Stat_t
+	if err := unix.Stat(dir, &st); err != nil {
+		return err
+	}
+	attr, err := getFsxattr(dir)
+	if err != nil {
+		return err
+	}
+	if attr.ProjID == 0 {
+		if st.Ino > math.MaxUint32 {
+			return fmt.Errorf("cannot derive a project ID for %q from its inode number %d", dir, st.Ino)
+		}
+		attr.ProjID = uint32(st.Ino)
+	}
+	attr.XFlags |= fsXflagProjInherit
+	if err := setFsxattr(dir, attr); err != nil {
+		return err
+	}
+
+	blocks := sizeToBlocks(size)
+	d := fsDiskQuota{
+		Version:      fsDquotVersion,
+		Flags:        fsProjQuota,
+		FieldMask:    fsDqBHard | fsDqBSoft,
+		ID:           attr.ProjID,
+		BlkHardLimit: blocks,
+		BlkSoftLimit: blocks,
+	}
+	return withBackingDevice(dir, st.Dev, func(dev string) error {
+		if err := quotactl(qXSetQLim, dev, attr.ProjID, unsafe.Pointer(&d)); err != nil {
+			return fmt.Errorf("failed to set the project quota of %q: %w", dir, err)
+		}
+		return nil
+	})
+}
+
+// Get returns the size limit and the usage of the directory.
+// ErrNoQuota is returned if Set has not been called for the directory.
+func Get(dir string) (*Quota, error) {
+	var st unix.Stat_t
+	if err := unix.Stat(dir, &st); err != nil {
+		return nil, err
+	}
+	attr, err := getFsxattr(dir)
+	if err != nil {
+		return nil, err
+	}
+	if attr.ProjID == 0 {
+		return nil, ErrNoQuota
+	}
+	var d fsDiskQuota
+	err = withBackingDevice(dir, st.Dev, func(dev string) error {
+		if err := quotactl(qXGetQuota, dev, attr.ProjID, unsafe.Pointer(&d)); err != nil {
+			return fmt.Errorf("failed to get the project quota of %q: %w", dir, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if d.BlkHardLimit == 0 {
+		return nil, ErrNoQuota
+	}
+	return &Quota{
+		Size: d.BlkHardLimit * quotaBlockSize,
+		Used: d.BCount * quotaBlockSize,
+	}, nil
+}
+
+func getFsxattr(dir string) (*fsxattr, error) {
+	var attr fsxattr
+	if err := fsxattrIoctl(dir, fsIocFsGetXattr, &attr); err != nil {
+		return nil, fmt.Errorf("failed to get the attributes of %q: %w", dir, err)
+	}
+	return &attr, nil
+}
+
+func setFsxattr(dir string, attr *fsxattr) error {
+	if err := fsxattrIoctl(dir, fsIocFsSetXattr, attr); err != nil {
+		return fmt.Errorf("failed to set the project ID of %q: %w", dir, err)
+	}
+	return nil
+}
+
+func fsxattrIoctl(dir string, req uintptr, attr *fsxattr) error {
+	fd, err := unix.Open(dir, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), req, uintptr(unsafe.Pointer(attr))); errno != 0 {
+		return notSupported(errno)
+	}
+	return nil
+}
+
+// withBackingDevice calls fn with the path of a temporary block device node for the file system of dir,
+// as quotactl(2) takes a block device rather than a mount point.
+func withBackingDevice(dir string, dev uint64, fn func(dev string) error) error {
+	p := filepath.Join(filepath.Dir(dir), ".quota-dev-"+strconv.Itoa(os.Getpid()))
+	_ = os.Remove(p)
+	if err := unix.Mknod(p, unix.S_IFBLK|0o600, int(dev)); err != nil {
+		return fmt.Errorf("failed to create the block device node for %q: %w", dir, err)
+	}
+	defer os.Remove(p)
+	return fn(p)
+}
+
+func quotactl(cmd int, special string, id uint32, addr unsafe.Pointer) error {
+	p, err := unix.BytePtrFromString(special)
+	if err != nil {
+		return err
+	}
+	// QCMD(cmd, PRJQUOTA)
+	c := cmd<<8 | prjQuota
+	if _, _, errno := unix.Syscall6(unix.SYS_QUOTACTL, uintptr(c), uintptr(unsafe.Pointer(p)), uintptr(id), uintptr(addr), 0, 0); errno != 0 {
+		return notSupported(errno)
+	}
+	return nil
+}
+
+// notSupported wraps the errors that mean the lack of the project quota support with ErrNotSupported.
+func notSupported(errno unix.Errno) error {
+	switch errno {
+	case unix.ENOTTY, unix.EOPNOTSUPP, unix.ENOSYS, unix.ESRCH, unix.ENODEV, unix.ENOTBLK, unix.EINVAL:
+		return errors.Join(ErrNotSupported, errno)
+	}
+	return errno
+}
@@ -0,0 +1,30 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package quota
+
+import (
+	"testing"
+	"unsafe"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestStructSizes(t *testing.T) {
+	// sizeof(struct fsxattr) and sizeof(struct fs_disk_quota)
+	assert.Equal(t, unsafe.Sizeof(fsxattr{}), uintptr(28))
+	assert.Equal(t, unsafe.Sizeof(fsDiskQuota{}), uintptr(112))
+}
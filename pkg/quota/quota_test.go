@@ -0,0 +1,46 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package quota
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseSize(t *testing.T) {
+	size, err := ParseSize("10m")
+	assert.NilError(t, err)
+	assert.Equal(t, size, uint64(10*1024*1024))
+
+	size, err = ParseSize("1G")
+	assert.NilError(t, err)
+	assert.Equal(t, size, uint64(1024*1024*1024))
+
+	_, err = ParseSize("0")
+	assert.ErrorContains(t, err, "must be positive")
+
+	_, err = ParseSize("foo")
+	assert.ErrorContains(t, err, "invalid size")
+}
+
+func TestSizeToBlocks(t *testing.T) {
+	assert.Equal(t, sizeToBlocks(1), uint64(1))
+	assert.Equal(t, sizeToBlocks(512), uint64(1))
+	assert.Equal(t, sizeToBlocks(513), uint64(2))
+	assert.Equal(t, sizeToBlocks(1024*1024), uint64(2048))
+}
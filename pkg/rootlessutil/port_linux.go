@@ -18,6 +18,7 @@ package rootlessutil
 
 import (
 	"context"
+	"fmt"
 	"net"
 
 	"github.com/rootless-containers/rootlesskit/v2/pkg/api/client"
@@ -51,8 +52,13 @@ func (rlcpm *RootlessCNIPortManager) ExposePort(ctx context.Context, cpm cni.Por
 		ParentPort: int(cpm.HostPort),
 		ChildPort:  int(cpm.HostPort), // NOT typo of cpm.ContainerPort
 	}
-	_, err := rlcpm.Client.PortManager().AddPort(ctx, sp)
-	return err
+	if _, err := rlcpm.Client.PortManager().AddPort(ctx, sp); err != nil {
+		if cpm.HostPort < 1024 {
+			return fmt.Errorf("failed to expose port %d: %w (hint: %s)", cpm.HostPort, err, PrivilegedPortHint)
+		}
+		return err
+	}
+	return nil
 }
 
 func (rlcpm *RootlessCNIPortManager) UnexposePort(ctx context.Context, cpm cni.PortMapping) error {
@@ -0,0 +1,132 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package rootlessutil
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// PortDriverBuiltin is the default port driver of RootlessKit, with the best throughput.
+	// The source IP of the connections is not propagated to the containers.
+	PortDriverBuiltin = "builtin"
+	// PortDriverSlirp4netns is the port driver of RootlessKit that propagates the source IP of the connections.
+	PortDriverSlirp4netns = "slirp4netns"
+	// PortDriverImplicit is the port driver of RootlessKit that leaves the port forwarding to the network driver (pasta).
+	PortDriverImplicit = "implicit"
+)
+
+// PortDrivers is the list of the port drivers of RootlessKit.
+var PortDrivers = []string{PortDriverBuiltin, PortDriverSlirp4netns, PortDriverImplicit}
+
+// ValidatePortDriver validates the combination of the port driver and the network driver of RootlessKit.
+// An empty portDriver stands for the default of containerd-rootless.sh ("builtin").
+func ValidatePortDriver(portDriver, netDriver string) error {
+	switch portDriver {
+	case "", PortDriverBuiltin:
+		return nil
+	case PortDriverSlirp4netns:
+		if netDriver != "slirp4netns" {
+			return fmt.Errorf("port driver %q forwards the ports with slirp4netns, so it needs the \"slirp4netns\" network driver, not %q (hint: use the %q port driver)",
+				portDriver, netDriver, PortDriverBuiltin)
+		}
+		return nil
+	case PortDriverImplicit:
+		if netDriver != "pasta" {
+			return fmt.Errorf("port driver %q leaves the port forwarding to pasta, so it needs the \"pasta\" network driver, not %q (hint: use the %q port driver)",
+				portDriver, netDriver, PortDriverBuiltin)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown port driver %q, must be one of %v (%q: fastest, %q: propagates the source IP, %q: forwarded by pasta)",
+			portDriver, PortDrivers, PortDriverBuiltin, PortDriverSlirp4netns, PortDriverImplicit)
+	}
+}
+
+// UnprivilegedPortStart returns the value of the net.ipv4.ip_unprivileged_port_start sysctl
+// of the current network namespace.
+func UnprivilegedPortStart() (int, error) {
+	b, err := os.ReadFile("/proc/sys/net/ipv4/ip_unprivileged_port_start")
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(b)))
+}
+
+// vfsCapRevision1Size is the size of the "security.capability" xattr (struct vfs_cap_data) of revision 1.
+// Revision 2 and 3 append the upper 32 bits of the capabilities, and the root ID of the user namespace.
+const vfsCapRevision1Size = 12
+
+// fileCapsHas returns true if the content of the "security.capability" xattr
+// has the capability in the permitted set.
+func fileCapsHas(b []byte, capability int) bool {
+	if len(b) < vfsCapRevision1Size {
+		return false
+	}
+	word := capability / 32
+	offset := 4 + word*8 // magic_etc, followed by {permitted, inheritable} pairs
+	if offset+4 > len(b) {
+		return false
+	}
+	permitted := binary.LittleEndian.Uint32(b[offset : offset+4])
+	return permitted&(1<<(uint(capability)%32)) != 0
+}
+
+// HasCapNetBindService returns true if the executable has CAP_NET_BIND_SERVICE in the file capabilities,
+// i.e., `setcap cap_net_bind_service=ep` was executed for the file.
+func HasCapNetBindService(path string) (bool, error) {
+	b := make([]byte, 64)
+	n, err := unix.Getxattr(path, "security.capability", b)
+	if err != nil {
+		if errors.Is(err, unix.ENODATA) {
+			return false, nil
+		}
+		return false, err
+	}
+	return fileCapsHas(b[:n], unix.CAP_NET_BIND_SERVICE), nil
+}
+
+// PrivilegedPortHint is the hint for publishing the ports below net.ipv4.ip_unprivileged_port_start in rootless mode.
+const PrivilegedPortHint = "to publish privileged ports in rootless mode, either run `sudo sysctl -w net.ipv4.ip_unprivileged_port_start=0` " +
+	"(persist it in /etc/sysctl.d/), or run `sudo setcap cap_net_bind_service=ep $(command -v rootlesskit)` and restart containerd"
+
+// checkPrivilegedPorts checks whether RootlessKit can bind the ports below 1024.
+func checkPrivilegedPorts() SetupCheck {
+	start, err := UnprivilegedPortStart()
+	if err != nil {
+		return SetupCheck{Name: "privileged ports", Warning: true, Message: err.Error(), Hint: PrivilegedPortHint}
+	}
+	if start <= 80 {
+		return SetupCheck{Name: "privileged ports", OK: true, Message: fmt.Sprintf("net.ipv4.ip_unprivileged_port_start=%d", start)}
+	}
+	if p, err := exec.LookPath("rootlesskit"); err == nil {
+		if ok, _ := HasCapNetBindService(p); ok {
+			return SetupCheck{Name: "privileged ports", OK: true, Message: p + " has CAP_NET_BIND_SERVICE"}
+		}
+	}
+	return SetupCheck{Name: "privileged ports", Warning: true,
+		Message: fmt.Sprintf("ports below %d cannot be published (net.ipv4.ip_unprivileged_port_start=%d)", start, start),
+		Hint:    PrivilegedPortHint}
+}
@@ -0,0 +1,309 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package rootlessutil
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MinSubIDs is the minimum number of the subordinate IDs required for running containers.
+const MinSubIDs = 65536
+
+// ContainerdUnit is the name of the systemd user unit of rootless containerd.
+const ContainerdUnit = "containerd.service"
+
+// SetupCheck is the result of checking a prerequisite of rootless containerd.
+type SetupCheck struct {
+	Name string
+	OK   bool
+	// Warning is true when the prerequisite is recommended, but not required
+	Warning bool
+	Message string
+	Hint    string
+}
+
+// CountSubIDs returns the number of the subordinate IDs allocated to the user,
+// from the content of /etc/subuid or /etc/subgid ("NAME_OR_ID:START:COUNT" per line).
+func CountSubIDs(r io.Reader, userName string, uid int) (uint64, error) {
+	var count uint64
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ":")
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[0] != userName && fields[0] != strconv.Itoa(uid) {
+			continue
+		}
+		n, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid line %q: %w", line, err)
+		}
+		count += n
+	}
+	return count, scanner.Err()
+}
+
+// ResolveNetworkDriver resolves the network driver of RootlessKit ("auto", "pasta", or "slirp4netns").
+// "auto" prefers pasta over slirp4netns.
+func ResolveNetworkDriver(net string, installed func(string) bool) (string, error) {
+	switch net {
+	case "", "auto":
+		for _, d := range []string{"pasta", "slirp4netns"} {
+			if installed(d) {
+				return d, nil
+			}
+		}
+		return "", fmt.Errorf("either pasta or slirp4netns needs to be installed")
+	case "pasta", "slirp4netns":
+		if !installed(net) {
+			return "", fmt.Errorf("%s needs to be installed", net)
+		}
+		return net, nil
+	default:
+		return "", fmt.Errorf("unknown network driver %q, must be \"auto\", \"pasta\", or \"slirp4netns\"", net)
+	}
+}
+
+// MissingControllers returns the cgroup v2 controllers not listed in the content of a "cgroup.controllers" file.
+func MissingControllers(controllers string, required []string) []string {
+	delegated := strings.Fields(controllers)
+	var missing []string
+	for _, c := range required {
+		if !slices.Contains(delegated, c) {
+			missing = append(missing, c)
+		}
+	}
+	return missing
+}
+
+// GenerateContainerdUnit generates the systemd user unit of rootless containerd.
+// binDir is the directory of containerd-rootless.sh.
+// An empty portDriver leaves the port driver to the default of containerd-rootless.sh.
+func GenerateContainerdUnit(binDir, path, net, portDriver string) string {
+	var portDriverEnv string
+	if portDriver != "" {
+		portDriverEnv = "Environment=CONTAINERD_ROOTLESS_ROOTLESSKIT_PORT_DRIVER=" + portDriver + "\n"
+	}
+	return fmt.Sprintf(`[Unit]
+Description=containerd (Rootless)
+Requires=dbus.socket
+
+[Service]
+Environment=PATH=%s:/sbin:/usr/sbin:%s
+Environment=CONTAINERD_ROOTLESS_ROOTLESSKIT_NET=%s
+%sExecStart=%s
+ExecReload=/bin/kill -s HUP $MAINPID
+TimeoutSec=0
+RestartSec=2
+Restart=always
+StartLimitBurst=3
+StartLimitInterval=60s
+LimitNOFILE=infinity
+LimitNPROC=infinity
+LimitCORE=infinity
+TasksMax=infinity
+Delegate=yes
+Type=simple
+KillMode=mixed
+
+[Install]
+WantedBy=default.target
+`, binDir, path, net, portDriverEnv, filepath.Join(binDir, "containerd-rootless.sh"))
+}
+
+func commandInstalled(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// CheckSetup checks the prerequisites of rootless containerd, with the network driver (see ResolveNetworkDriver)
+// and the port driver (see ValidatePortDriver).
+// The returned string is the resolved network driver.
+func CheckSetup(net, portDriver string) ([]SetupCheck, string) {
+	var checks []SetupCheck
+	uid := os.Geteuid()
+	if uid == 0 {
+		checks = append(checks, SetupCheck{Name: "user", Message: "must not be root", Hint: "run as a non-root user"})
+		return checks, ""
+	}
+	checks = append(checks, SetupCheck{Name: "user", OK: true, Message: fmt.Sprintf("uid=%d", uid)})
+
+	home := os.Getenv("HOME")
+	if home == "" || isWritableDir(home) != nil {
+		checks = append(checks, SetupCheck{Name: "HOME", Message: fmt.Sprintf("%q is not set or not writable", home)})
+	} else {
+		checks = append(checks, SetupCheck{Name: "HOME", OK: true, Message: home})
+	}
+
+	xrd := os.Getenv("XDG_RUNTIME_DIR")
+	if xrd == "" || isWritableDir(xrd) != nil {
+		checks = append(checks, SetupCheck{Name: "XDG_RUNTIME_DIR", Message: fmt.Sprintf("%q is not set, does not exist, or is not writable", xrd),
+			Hint: "log in as the user (e.g., with ssh or `machinectl shell`) instead of `su` or `sudo`, see https://rootlesscontaine.rs/getting-started/common/login/"})
+	} else {
+		checks = append(checks, SetupCheck{Name: "XDG_RUNTIME_DIR", OK: true, Message: xrd})
+	}
+
+	if err := exec.Command("systemctl", "--user", "show-environment").Run(); err != nil {
+		checks = append(checks, SetupCheck{Name: "systemd", Message: fmt.Sprintf("`systemctl --user` is not available: %v", err),
+			Hint: "run `sudo loginctl enable-linger $(id -un)` and log in again"})
+	} else {
+		checks = append(checks, SetupCheck{Name: "systemd", OK: true, Message: "`systemctl --user` is available"})
+	}
+
+	for _, bin := range []string{"containerd", "containerd-rootless.sh", "rootlesskit", "newuidmap", "newgidmap"} {
+		if p, err := exec.LookPath(bin); err != nil {
+			hint := "install it under $PATH"
+			if strings.HasPrefix(bin, "new") {
+				hint = "install the `uidmap` package (Debian, Ubuntu) or the `shadow-utils` package (Fedora)"
+			}
+			checks = append(checks, SetupCheck{Name: bin, Message: "not found", Hint: hint})
+		} else {
+			checks = append(checks, SetupCheck{Name: bin, OK: true, Message: p})
+		}
+	}
+
+	userName := os.Getenv("USER")
+	if out, err := exec.Command("id", "-un").Output(); err == nil {
+		userName = strings.TrimSpace(string(out))
+	}
+	checks = append(checks, checkSubIDs(uid, userName)...)
+
+	resolvedNet, err := ResolveNetworkDriver(net, commandInstalled)
+	if err != nil {
+		checks = append(checks, SetupCheck{Name: "network", Message: err.Error(),
+			Hint: "install pasta (the `passt` package) or slirp4netns"})
+	} else {
+		checks = append(checks, SetupCheck{Name: "network", OK: true, Message: resolvedNet})
+		if err := ValidatePortDriver(portDriver, resolvedNet); err != nil {
+			checks = append(checks, SetupCheck{Name: "port driver", Message: err.Error(),
+				Hint: "change rootlesskit_port_driver in nerdctl.toml, or specify --net"})
+		} else {
+			pd := portDriver
+			if pd == "" {
+				pd = PortDriverBuiltin
+			}
+			checks = append(checks, SetupCheck{Name: "port driver", OK: true, Message: pd})
+		}
+	}
+	checks = append(checks, checkPrivilegedPorts())
+	checks = append(checks, checkCgroupDelegation(uid))
+	return checks, resolvedNet
+}
+
+// CheckChildSetup checks the prerequisites of rootless containerd that can be checked from
+// the RootlessKit child namespace, i.e., while rootless containerd is running.
+func CheckChildSetup(ctx context.Context, portDriver string) []SetupCheck {
+	uid := ParentEUID()
+	checks := checkSubIDs(uid, os.Getenv("USER"))
+	checks = append(checks, checkRunningPortDriver(ctx, portDriver))
+	checks = append(checks, checkCgroupDelegation(uid))
+	return checks
+}
+
+// checkRunningPortDriver checks that RootlessKit is running with the configured port driver.
+func checkRunningPortDriver(ctx context.Context, portDriver string) SetupCheck {
+	if portDriver == "" {
+		portDriver = PortDriverBuiltin
+	}
+	client, err := NewRootlessKitClient()
+	if err != nil {
+		return SetupCheck{Name: "port driver", Message: fmt.Sprintf("failed to create the RootlessKit client: %v", err)}
+	}
+	info, err := client.Info(ctx)
+	if err != nil {
+		return SetupCheck{Name: "port driver", Message: fmt.Sprintf("failed to get the RootlessKit info: %v", err)}
+	}
+	if info.PortDriver == nil {
+		return SetupCheck{Name: "port driver", Warning: true, Message: "RootlessKit is running without a port driver"}
+	}
+	if info.PortDriver.Driver != portDriver {
+		return SetupCheck{Name: "port driver", Warning: true,
+			Message: fmt.Sprintf("RootlessKit is running with the port driver %q, not %q (rootlesskit_port_driver)", info.PortDriver.Driver, portDriver),
+			Hint:    "run `nerdctl system rootless setup --force && systemctl --user restart containerd`"}
+	}
+	return SetupCheck{Name: "port driver", OK: true, Message: info.PortDriver.Driver}
+}
+
+func checkCgroupDelegation(uid int) SetupCheck {
+	controllersFile := fmt.Sprintf("/sys/fs/cgroup/user.slice/user-%d.slice/user@%d.service/cgroup.controllers", uid, uid)
+	cgroupHint := "see https://rootlesscontaine.rs/getting-started/common/cgroup2/"
+	b, err := os.ReadFile(controllersFile)
+	if err != nil {
+		return SetupCheck{Name: "cgroup", Warning: true, Message: "cgroup v2 is not enabled, resource limits are unavailable", Hint: cgroupHint}
+	}
+	if missing := MissingControllers(string(b), []string{"cpu", "memory", "pids", "io"}); len(missing) > 0 {
+		return SetupCheck{Name: "cgroup", Warning: true,
+			Message: fmt.Sprintf("controllers %v are not delegated to the user", missing),
+			Hint: "create /etc/systemd/system/user@.service.d/delegate.conf with \"[Service]\\nDelegate=cpu cpuset io memory pids\", " +
+				"run `sudo systemctl daemon-reload`, and log in again; " + cgroupHint}
+	}
+	return SetupCheck{Name: "cgroup", OK: true, Message: "cgroup v2 controllers are delegated"}
+}
+
+func checkSubIDs(uid int, userName string) []SetupCheck {
+	var checks []SetupCheck
+	for _, f := range []string{"/etc/subuid", "/etc/subgid"} {
+		name := filepath.Base(f)
+		hint := fmt.Sprintf("run `sudo usermod --add-subuids 100000-165535 --add-subgids 100000-165535 %s`", userName)
+		r, err := os.Open(f)
+		if err != nil {
+			checks = append(checks, SetupCheck{Name: name, Message: err.Error(), Hint: hint})
+			continue
+		}
+		count, err := CountSubIDs(r, userName, uid)
+		r.Close()
+		switch {
+		case err != nil:
+			checks = append(checks, SetupCheck{Name: name, Message: err.Error(), Hint: hint})
+		case count < MinSubIDs:
+			checks = append(checks, SetupCheck{Name: name, Message: fmt.Sprintf("%d IDs are allocated to %q, %d IDs are required", count, userName, MinSubIDs), Hint: hint})
+		default:
+			checks = append(checks, SetupCheck{Name: name, OK: true, Message: fmt.Sprintf("%d IDs", count)})
+		}
+	}
+	return checks
+}
+
+func isWritableDir(dir string) error {
+	st, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !st.IsDir() {
+		return fmt.Errorf("%q is not a directory", dir)
+	}
+	f, err := os.CreateTemp(dir, ".nerdctl-rootless-setup-")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,114 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package rootlessutil
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestCountSubIDs(t *testing.T) {
+	const subuid = `# comment
+alice:100000:65536
+1001:165536:1000
+bob:200000:65536
+`
+	count, err := CountSubIDs(strings.NewReader(subuid), "alice", 1000)
+	assert.NilError(t, err)
+	assert.Equal(t, count, uint64(65536))
+
+	count, err = CountSubIDs(strings.NewReader(subuid), "carol", 1001)
+	assert.NilError(t, err)
+	assert.Equal(t, count, uint64(1000))
+
+	count, err = CountSubIDs(strings.NewReader(subuid), "dave", 1002)
+	assert.NilError(t, err)
+	assert.Equal(t, count, uint64(0))
+
+	_, err = CountSubIDs(strings.NewReader("alice:100000:many\n"), "alice", 1000)
+	assert.ErrorContains(t, err, "invalid line")
+}
+
+func TestResolveNetworkDriver(t *testing.T) {
+	installed := func(names ...string) func(string) bool {
+		return func(name string) bool {
+			for _, n := range names {
+				if n == name {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	net, err := ResolveNetworkDriver("auto", installed("slirp4netns", "pasta"))
+	assert.NilError(t, err)
+	assert.Equal(t, net, "pasta")
+
+	net, err = ResolveNetworkDriver("", installed("slirp4netns"))
+	assert.NilError(t, err)
+	assert.Equal(t, net, "slirp4netns")
+
+	_, err = ResolveNetworkDriver("auto", installed())
+	assert.ErrorContains(t, err, "needs to be installed")
+
+	_, err = ResolveNetworkDriver("pasta", installed("slirp4netns"))
+	assert.ErrorContains(t, err, "pasta needs to be installed")
+
+	_, err = ResolveNetworkDriver("vpnkit", installed("vpnkit"))
+	assert.ErrorContains(t, err, "unknown network driver")
+}
+
+func TestMissingControllers(t *testing.T) {
+	assert.DeepEqual(t, MissingControllers("cpuset cpu io memory pids\n", []string{"cpu", "memory", "pids"}), []string(nil))
+	assert.DeepEqual(t, MissingControllers("memory pids\n", []string{"cpu", "memory", "pids", "io"}), []string{"cpu", "io"})
+}
+
+func TestGenerateContainerdUnit(t *testing.T) {
+	unit := GenerateContainerdUnit("/usr/local/bin", "/usr/bin:/bin", "pasta", "")
+	assert.Assert(t, strings.Contains(unit, "Environment=PATH=/usr/local/bin:/sbin:/usr/sbin:/usr/bin:/bin\n"))
+	assert.Assert(t, strings.Contains(unit, "Environment=CONTAINERD_ROOTLESS_ROOTLESSKIT_NET=pasta\n"))
+	assert.Assert(t, !strings.Contains(unit, "CONTAINERD_ROOTLESS_ROOTLESSKIT_PORT_DRIVER"))
+	assert.Assert(t, strings.Contains(unit, "ExecStart=/usr/local/bin/containerd-rootless.sh\n"))
+	assert.Assert(t, strings.Contains(unit, "Delegate=yes\n"))
+
+	unit = GenerateContainerdUnit("/usr/local/bin", "/usr/bin:/bin", "slirp4netns", "slirp4netns")
+	assert.Assert(t, strings.Contains(unit, "Environment=CONTAINERD_ROOTLESS_ROOTLESSKIT_PORT_DRIVER=slirp4netns\nExecStart="))
+}
+
+func TestValidatePortDriver(t *testing.T) {
+	assert.NilError(t, ValidatePortDriver("", "pasta"))
+	assert.NilError(t, ValidatePortDriver(PortDriverBuiltin, "slirp4netns"))
+	assert.NilError(t, ValidatePortDriver(PortDriverSlirp4netns, "slirp4netns"))
+	assert.NilError(t, ValidatePortDriver(PortDriverImplicit, "pasta"))
+	assert.ErrorContains(t, ValidatePortDriver(PortDriverSlirp4netns, "pasta"), "needs the \"slirp4netns\" network driver")
+	assert.ErrorContains(t, ValidatePortDriver(PortDriverImplicit, "slirp4netns"), "needs the \"pasta\" network driver")
+	assert.ErrorContains(t, ValidatePortDriver("socat", "pasta"), "unknown port driver")
+}
+
+func TestFileCapsHas(t *testing.T) {
+	// `setcap cap_net_bind_service=ep`: VFS_CAP_REVISION_2 | VFS_CAP_FLAGS_EFFECTIVE, permitted = 1 << 10
+	b := []byte{0x01, 0x00, 0x00, 0x02, 0x00, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	assert.Assert(t, fileCapsHas(b, 10))
+	assert.Assert(t, !fileCapsHas(b, 12))
+	// CAP_NET_RAW (13) only
+	b = []byte{0x01, 0x00, 0x00, 0x02, 0x00, 0x20, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	assert.Assert(t, !fileCapsHas(b, 10))
+	assert.Assert(t, fileCapsHas(b, 13))
+	assert.Assert(t, !fileCapsHas(nil, 10))
+}
@@ -0,0 +1,135 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/pkg/namespaces"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/config"
+)
+
+// Client runs nerdctl operations against a containerd.
+type Client struct {
+	client   *containerd.Client
+	gOptions types.GlobalCommandOptions
+	cancel   context.CancelFunc
+}
+
+// ClientOption configures a Client, like the global flags of the CLI.
+type ClientOption func(*types.GlobalCommandOptions)
+
+// WithConfig replaces all the settings of the Client with cfg, e.g., loaded from nerdctl.toml.
+// Options given after WithConfig are applied on top of it.
+func WithConfig(cfg config.Config) ClientOption {
+	return func(o *types.GlobalCommandOptions) {
+		*o = types.GlobalCommandOptions(cfg)
+	}
+}
+
+// WithAddress sets the address of containerd, like `--address`.
+func WithAddress(address string) ClientOption {
+	return func(o *types.GlobalCommandOptions) {
+		o.Address = address
+	}
+}
+
+// WithNamespace sets the containerd namespace, like `--namespace`.
+func WithNamespace(namespace string) ClientOption {
+	return func(o *types.GlobalCommandOptions) {
+		o.Namespace = namespace
+	}
+}
+
+// WithSnapshotter sets the containerd snapshotter, like `--snapshotter`.
+func WithSnapshotter(snapshotter string) ClientOption {
+	return func(o *types.GlobalCommandOptions) {
+		o.Snapshotter = snapshotter
+	}
+}
+
+// WithDataRoot sets the root directory of the persistent nerdctl state, like `--data-root`.
+func WithDataRoot(dataRoot string) ClientOption {
+	return func(o *types.GlobalCommandOptions) {
+		o.DataRoot = dataRoot
+	}
+}
+
+// WithCNI sets the directory of the CNI plugins and the directory of the CNI configs,
+// like `--cni-path` and `--cni-netconfpath`.
+func WithCNI(cniPath, cniNetConfPath string) ClientOption {
+	return func(o *types.GlobalCommandOptions) {
+		o.CNIPath = cniPath
+		o.CNINetConfPath = cniNetConfPath
+	}
+}
+
+// WithHostsDirs sets the directories of the registry configurations, like `--hosts-dir`.
+func WithHostsDirs(hostsDirs ...string) ClientOption {
+	return func(o *types.GlobalCommandOptions) {
+		o.HostsDir = hostsDirs
+	}
+}
+
+// WithInsecureRegistry allows skipping the verification of HTTPS certificates, and falling back to plain HTTP,
+// like `--insecure-registry`.
+func WithInsecureRegistry() ClientOption {
+	return func(o *types.GlobalCommandOptions) {
+		o.InsecureRegistry = true
+	}
+}
+
+// New connects to containerd. The defaults are the same as the CLI without nerdctl.toml.
+func New(ctx context.Context, opts ...ClientOption) (*Client, error) {
+	gOptions := types.GlobalCommandOptions(*config.New())
+	for _, o := range opts {
+		o(&gOptions)
+	}
+	client, _, cancel, err := clientutil.NewClient(ctx, gOptions.Namespace, gOptions.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		client:   client,
+		gOptions: gOptions,
+		cancel:   cancel,
+	}, nil
+}
+
+// Close closes the connection to containerd.
+func (c *Client) Close() error {
+	c.cancel()
+	return c.client.Close()
+}
+
+// Containerd returns the underlying containerd client, for the operations that this package does not cover.
+func (c *Client) Containerd() *containerd.Client {
+	return c.client
+}
+
+// Namespace returns the containerd namespace of the Client.
+func (c *Client) Namespace() string {
+	return c.gOptions.Namespace
+}
+
+func (c *Client) withNamespace(ctx context.Context) context.Context {
+	return namespaces.WithNamespace(ctx, c.gOptions.Namespace)
+}
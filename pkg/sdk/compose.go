@@ -0,0 +1,266 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/compose"
+	"github.com/containerd/nerdctl/v2/pkg/composer"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+// ComposeContainer is a container of a compose project.
+type ComposeContainer struct {
+	ID      string
+	Name    string
+	Service string
+}
+
+type composeOptions struct {
+	options  composer.Options
+	up       composer.UpOptions
+	down     composer.DownOptions
+	services []string
+	stdout   io.Writer
+	stderr   io.Writer
+}
+
+// ComposeOption configures the compose operations, like the flags of `nerdctl compose`.
+type ComposeOption func(*composeOptions)
+
+// WithComposeFiles sets the compose files, like `--file`.
+// Defaults to compose.yaml (or its variants) in the project directory.
+func WithComposeFiles(files ...string) ComposeOption {
+	return func(o *composeOptions) {
+		o.options.ConfigPaths = files
+	}
+}
+
+// WithProjectDirectory sets the project directory, like `--project-directory`.
+// Defaults to the directory of the first compose file.
+func WithProjectDirectory(dir string) ComposeOption {
+	return func(o *composeOptions) {
+		o.options.ProjectDirectory = dir
+	}
+}
+
+// WithProjectName sets the project name, like `--project-name`. Defaults to the name of the project directory.
+func WithProjectName(name string) ComposeOption {
+	return func(o *composeOptions) {
+		o.options.Project = name
+	}
+}
+
+// WithProfiles enables the profiles, like `--profile`.
+func WithProfiles(profiles ...string) ComposeOption {
+	return func(o *composeOptions) {
+		o.options.Profiles = profiles
+	}
+}
+
+// WithEnvFile sets the env file, like `--env-file`.
+func WithEnvFile(envFile string) ComposeOption {
+	return func(o *composeOptions) {
+		o.options.EnvFile = envFile
+	}
+}
+
+// WithServices only starts, or lists, the given services. ComposeUp also starts their dependencies.
+func WithServices(services ...string) ComposeOption {
+	return func(o *composeOptions) {
+		o.services = services
+	}
+}
+
+// WithRemoveOrphans removes the containers of the services that are not in the compose files, like `--remove-orphans`.
+func WithRemoveOrphans() ComposeOption {
+	return func(o *composeOptions) {
+		o.up.RemoveOrphans = true
+		o.down.RemoveOrphans = true
+	}
+}
+
+// WithRemoveVolumes removes the named volumes of the project on ComposeDown, like `nerdctl compose down --volumes`.
+func WithRemoveVolumes() ComposeOption {
+	return func(o *composeOptions) {
+		o.down.RemoveVolumes = true
+	}
+}
+
+// WithForceRecreate recreates the containers even if their configuration did not change, like `--force-recreate`.
+func WithForceRecreate() ComposeOption {
+	return func(o *composeOptions) {
+		o.up.ForceRecreate = true
+	}
+}
+
+// WithScale sets the number of replicas of a service, like `--scale`.
+func WithScale(service string, replicas int) ComposeOption {
+	return func(o *composeOptions) {
+		if o.up.Scale == nil {
+			o.up.Scale = make(map[string]int)
+		}
+		o.up.Scale[service] = replicas
+	}
+}
+
+// WithComposeOutput writes the output of the image builds and pulls to stdout and stderr.
+func WithComposeOutput(stdout, stderr io.Writer) ComposeOption {
+	return func(o *composeOptions) {
+		o.stdout = stdout
+		o.stderr = stderr
+	}
+}
+
+// WithNerdctlBinary sets the nerdctl binary, which compose runs for creating the containers.
+// Defaults to "nerdctl" in $PATH.
+func WithNerdctlBinary(path string) ComposeOption {
+	return func(o *composeOptions) {
+		o.options.NerdctlCmd = path
+	}
+}
+
+// ComposeUp creates and starts the containers of a compose project in the background, like `nerdctl compose up -d`,
+// and returns the containers of the started services.
+//
+// Note that compose still runs the nerdctl binary for creating the containers, see WithNerdctlBinary.
+func (c *Client) ComposeUp(ctx context.Context, opts ...ComposeOption) ([]ComposeContainer, error) {
+	ctx = c.withNamespace(ctx)
+	o := c.composeOptions(opts)
+	o.up.Detach = true
+	o.up.QuietPull = true
+	var result []ComposeContainer
+	err := c.compose(o, func(cmp *composer.Composer) error {
+		if err := cmp.Up(ctx, o.up, o.services); err != nil {
+			return err
+		}
+		var err error
+		result, err = composeContainers(ctx, cmp, o.services)
+		return err
+	})
+	return result, err
+}
+
+// ComposeDown stops and removes the containers and the networks of a compose project, like `nerdctl compose down`.
+func (c *Client) ComposeDown(ctx context.Context, opts ...ComposeOption) error {
+	ctx = c.withNamespace(ctx)
+	o := c.composeOptions(opts)
+	return c.compose(o, func(cmp *composer.Composer) error {
+		return cmp.Down(ctx, o.down)
+	})
+}
+
+// ComposeContainers returns the containers of a compose project, like `nerdctl compose ps`.
+func (c *Client) ComposeContainers(ctx context.Context, opts ...ComposeOption) ([]ComposeContainer, error) {
+	ctx = c.withNamespace(ctx)
+	o := c.composeOptions(opts)
+	var result []ComposeContainer
+	err := c.compose(o, func(cmp *composer.Composer) error {
+		var err error
+		result, err = composeContainers(ctx, cmp, o.services)
+		return err
+	})
+	return result, err
+}
+
+func (c *Client) composeOptions(opts []ComposeOption) composeOptions {
+	o := composeOptions{
+		stdout: io.Discard,
+		stderr: io.Discard,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// compose loads the project and calls fn.
+func (c *Client) compose(o composeOptions, fn func(*composer.Composer) error) error {
+	if o.options.NerdctlCmd == "" {
+		nerdctlCmd, err := exec.LookPath("nerdctl")
+		if err != nil {
+			return fmt.Errorf("failed to find the nerdctl binary for compose, see WithNerdctlBinary: %w", err)
+		}
+		o.options.NerdctlCmd = nerdctlCmd
+	}
+	o.options.NerdctlArgs = globalArgs(c.gOptions)
+	o.options.Experimental = c.gOptions.Experimental
+	o.options.Services = o.services
+
+	cmp, err := compose.New(c.client, c.gOptions, o.options, o.stdout, o.stderr)
+	// compose.New takes a lock for the whole process, which has to be released for the next operations,
+	// including when compose.New fails after taking it.
+	defer composer.Unlock()
+	if err != nil {
+		return err
+	}
+	return fn(cmp)
+}
+
+func composeContainers(ctx context.Context, cmp *composer.Composer, services []string) ([]ComposeContainer, error) {
+	serviceNames, err := cmp.ServiceNames(services...)
+	if err != nil {
+		return nil, err
+	}
+	containers, err := cmp.Containers(ctx, serviceNames...)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]ComposeContainer, 0, len(containers))
+	for _, container := range containers {
+		containerLabels, err := container.Labels(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, ComposeContainer{
+			ID:      container.ID(),
+			Name:    containerutil.GetContainerName(containerLabels),
+			Service: containerLabels[labels.ComposeService],
+		})
+	}
+	return result, nil
+}
+
+// globalArgs returns the global flags of the nerdctl commands run by compose.
+func globalArgs(gOptions types.GlobalCommandOptions) []string {
+	args := []string{
+		"--address=" + gOptions.Address,
+		"--namespace=" + gOptions.Namespace,
+		"--snapshotter=" + gOptions.Snapshotter,
+		"--data-root=" + gOptions.DataRoot,
+		"--cni-path=" + gOptions.CNIPath,
+		"--cni-netconfpath=" + gOptions.CNINetConfPath,
+		"--cgroup-manager=" + gOptions.CgroupManager,
+		"--insecure-registry=" + strconv.FormatBool(gOptions.InsecureRegistry),
+		"--experimental=" + strconv.FormatBool(gOptions.Experimental),
+	}
+	if len(gOptions.InsecureRegistries) > 0 {
+		args = append(args, "--insecure-registry="+strings.Join(gOptions.InsecureRegistries, ","))
+	}
+	if len(gOptions.HostsDir) > 0 {
+		args = append(args, "--hosts-dir="+strings.Join(gOptions.HostsDir, ","))
+	}
+	return args
+}
@@ -0,0 +1,205 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+)
+
+// Container is a container, as listed by `nerdctl ps`.
+type Container struct {
+	ID        string
+	Name      string
+	Image     string
+	Command   string
+	Status    string
+	CreatedAt time.Time
+	Labels    map[string]string
+}
+
+// ListOption configures Client.Containers.
+type ListOption func(*types.ContainerListOptions)
+
+// WithAll lists all the containers, instead of the running ones only, like `--all`.
+func WithAll() ListOption {
+	return func(o *types.ContainerListOptions) {
+		o.All = true
+	}
+}
+
+// WithFilters lists the containers matching all the filters, like `--filter`, e.g., "label=foo=bar".
+func WithFilters(filters ...string) ListOption {
+	return func(o *types.ContainerListOptions) {
+		o.Filters = append(o.Filters, filters...)
+	}
+}
+
+// Containers lists the containers, the newest first.
+func (c *Client) Containers(ctx context.Context, opts ...ListOption) ([]Container, error) {
+	ctx = c.withNamespace(ctx)
+	options := types.ContainerListOptions{
+		GOptions: c.gOptions,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	items, err := container.List(ctx, c.client, options)
+	if err != nil {
+		return nil, err
+	}
+	containers := make([]Container, 0, len(items))
+	for _, item := range items {
+		if item.ID == "" {
+			// removed while listing
+			continue
+		}
+		command, err := strconv.Unquote(item.Command)
+		if err != nil {
+			command = item.Command
+		}
+		containers = append(containers, Container{
+			ID:        item.ID,
+			Name:      item.Names,
+			Image:     item.Image,
+			Command:   command,
+			Status:    item.Status,
+			CreatedAt: item.CreatedAt,
+			Labels:    item.LabelsMap,
+		})
+	}
+	return containers, nil
+}
+
+// StopOption configures Client.StopContainer.
+type StopOption func(*types.ContainerStopOptions)
+
+// WithStopTimeout sets how long to wait for the container to stop before killing it, like `--timeout`.
+// Defaults to the stop timeout of the container, or 10 seconds.
+func WithStopTimeout(timeout time.Duration) StopOption {
+	return func(o *types.ContainerStopOptions) {
+		o.Timeout = &timeout
+	}
+}
+
+// WithStopSignal sets the signal that stops the container, like `--signal`.
+func WithStopSignal(signal string) StopOption {
+	return func(o *types.ContainerStopOptions) {
+		o.Signal = signal
+	}
+}
+
+// RemoveOption configures Client.RemoveContainer and Client.RemoveImage.
+type RemoveOption func(*removeOptions)
+
+type removeOptions struct {
+	force   bool
+	volumes bool
+}
+
+// WithForce removes a running container, or an image used by containers, like `--force`.
+func WithForce() RemoveOption {
+	return func(o *removeOptions) {
+		o.force = true
+	}
+}
+
+// WithAnonymousVolumes also removes the anonymous volumes of a container, like `nerdctl rm --volumes`.
+func WithAnonymousVolumes() RemoveOption {
+	return func(o *removeOptions) {
+		o.volumes = true
+	}
+}
+
+// StartContainer starts a created or stopped container, specified by its name, ID, or ID prefix.
+func (c *Client) StartContainer(ctx context.Context, req string) error {
+	ctx = c.withNamespace(ctx)
+	if err := c.findContainer(ctx, req); err != nil {
+		return err
+	}
+	return container.Start(ctx, c.client, []string{req}, types.ContainerStartOptions{
+		Stdout:   io.Discard,
+		GOptions: c.gOptions,
+	})
+}
+
+// StopContainer stops a running container, specified by its name, ID, or ID prefix.
+func (c *Client) StopContainer(ctx context.Context, req string, opts ...StopOption) error {
+	ctx = c.withNamespace(ctx)
+	if err := c.findContainer(ctx, req); err != nil {
+		return err
+	}
+	options := types.ContainerStopOptions{
+		Stdout:   io.Discard,
+		Stderr:   io.Discard,
+		GOptions: c.gOptions,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return container.Stop(ctx, c.client, []string{req}, options)
+}
+
+// RemoveContainer removes a container, specified by its name, ID, or ID prefix.
+func (c *Client) RemoveContainer(ctx context.Context, req string, opts ...RemoveOption) error {
+	ctx = c.withNamespace(ctx)
+	if err := c.findContainer(ctx, req); err != nil {
+		return err
+	}
+	var o removeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return container.Remove(ctx, c.client, []string{req}, types.ContainerRemoveOptions{
+		Stdout:   io.Discard,
+		GOptions: c.gOptions,
+		Force:    o.force,
+		Volumes:  o.volumes,
+	})
+}
+
+// findContainer returns an error wrapping errdefs.ErrNotFound or errdefs.ErrInvalidArgument
+// unless req matches exactly one container.
+// The functions of pkg/cmd/container only return untyped errors, which callers cannot tell apart.
+func (c *Client) findContainer(ctx context.Context, req string) error {
+	walker := &containerwalker.ContainerWalker{
+		Client: c.client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s: %w", found.Req, errdefs.ErrInvalidArgument)
+			}
+			return nil
+		},
+	}
+	n, err := walker.Walk(ctx, req)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("no such container: %s: %w", req, errdefs.ErrNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package sdk is the supported Go API of nerdctl, for embedding nerdctl operations in other tools without
+// shelling out to the nerdctl binary.
+//
+// The packages under pkg/cmd implement the CLI: their option structs mirror the flags, and their functions print
+// to the writers of the options. They change with the CLI. This package wraps them with an API that only changes
+// in backward-compatible ways within a major version of nerdctl:
+//
+//   - Functions take a context first, and the required arguments, followed by functional options.
+//     The zero set of options has the same defaults as the CLI.
+//   - Functions do not print anything unless an option asks for it (e.g., WithPullProgress),
+//     and return structs instead.
+//   - Options are only added, never removed or changed.
+//
+// Example:
+//
+//	client, err := sdk.New(ctx, sdk.WithNamespace("example"))
+//	if err != nil {
+//		return err
+//	}
+//	defer client.Close()
+//	img, err := client.Pull(ctx, "alpine:latest", sdk.WithPlatforms("linux/arm64"))
+package sdk
@@ -0,0 +1,230 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/platforms"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+	"github.com/containerd/nerdctl/v2/pkg/containerdutil"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/imagewalker"
+	"github.com/containerd/nerdctl/v2/pkg/platformutil"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
+)
+
+// Image is an image in the content store.
+type Image struct {
+	// Name is the full reference of the image, e.g., "docker.io/library/alpine:latest".
+	Name string
+	// Digest is the digest of the manifest or of the index of the image.
+	Digest    digest.Digest
+	CreatedAt time.Time
+	// Size is the size of the blobs of the image in the content store, for all the pulled platforms.
+	Size int64
+}
+
+// PullOption configures Client.Pull.
+type PullOption func(*types.ImagePullOptions) error
+
+// WithPlatforms pulls the given platforms instead of the platform of the host, like `--platform`.
+func WithPlatforms(platforms ...string) PullOption {
+	return func(o *types.ImagePullOptions) error {
+		ociSpecPlatforms, err := platformutil.NewOCISpecPlatformSlice(false, platforms)
+		if err != nil {
+			return err
+		}
+		o.OCISpecPlatform = ociSpecPlatforms
+		return nil
+	}
+}
+
+// WithAllPlatforms pulls all the platforms of the image, like `--all-platforms`.
+func WithAllPlatforms() PullOption {
+	return func(o *types.ImagePullOptions) error {
+		o.OCISpecPlatform = nil
+		return nil
+	}
+}
+
+// WithPullMode sets when the image is pulled: "always" (default), "missing", or "never".
+func WithPullMode(mode string) PullOption {
+	return func(o *types.ImagePullOptions) error {
+		switch mode {
+		case "always", "missing", "never":
+		default:
+			return fmt.Errorf("invalid pull mode %q, must be either \"always\", \"missing\", or \"never\"", mode)
+		}
+		o.Mode = mode
+		return nil
+	}
+}
+
+// WithPullProgress writes the progress of the pull to w, like the CLI does to stderr.
+func WithPullProgress(w io.Writer) PullOption {
+	return func(o *types.ImagePullOptions) error {
+		o.Quiet = false
+		o.Stdout = w
+		o.Stderr = w
+		return nil
+	}
+}
+
+// WithVerify verifies the signature of the image before pulling it, like `--verify`.
+func WithVerify(options types.ImageVerifyOptions) PullOption {
+	return func(o *types.ImagePullOptions) error {
+		o.VerifyOptions = options
+		return nil
+	}
+}
+
+// Pull pulls the image, and unpacks it when a single platform is pulled.
+func (c *Client) Pull(ctx context.Context, ref string, opts ...PullOption) (*Image, error) {
+	ctx = c.withNamespace(ctx)
+	options := types.ImagePullOptions{
+		GOptions:        c.gOptions,
+		OCISpecPlatform: []ocispec.Platform{platforms.DefaultSpec()},
+		Mode:            "always",
+		Quiet:           true,
+		Stdout:          io.Discard,
+		Stderr:          io.Discard,
+	}
+	for _, o := range opts {
+		if err := o(&options); err != nil {
+			return nil, err
+		}
+	}
+	ensured, err := image.EnsureImage(ctx, c.client, ref, options)
+	if err != nil {
+		return nil, err
+	}
+	return c.image(ctx, ensured.Image.Metadata())
+}
+
+// Images lists the images, like `nerdctl images`.
+func (c *Client) Images(ctx context.Context) ([]Image, error) {
+	ctx = c.withNamespace(ctx)
+	imageList, err := image.List(ctx, c.client, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Image, 0, len(imageList))
+	for _, img := range imageList {
+		i, err := c.image(ctx, img)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *i)
+	}
+	return result, nil
+}
+
+// RemoveImage removes an image, specified by its name, ID, or ID prefix, like `nerdctl rmi`.
+func (c *Client) RemoveImage(ctx context.Context, req string, opts ...RemoveOption) error {
+	ctx = c.withNamespace(ctx)
+	walker := &imagewalker.ImageWalker{
+		Client: c.client,
+		OnFound: func(ctx context.Context, found imagewalker.Found) error {
+			return nil
+		},
+	}
+	n, err := walker.Walk(ctx, req)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("no such image: %s: %w", req, errdefs.ErrNotFound)
+	}
+	var o removeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return image.Remove(ctx, c.client, []string{req}, types.ImageRemoveOptions{
+		Stdout:   io.Discard,
+		GOptions: c.gOptions,
+		Force:    o.force,
+	})
+}
+
+// SquashOption configures Client.Squash.
+type SquashOption func(*types.ImageSquashOptions)
+
+// WithSquashAuthor sets the author of the squashed layer, e.g., "John Hannibal Smith <hannibal@a-team.com>".
+func WithSquashAuthor(author string) SquashOption {
+	return func(o *types.ImageSquashOptions) {
+		o.Author = author
+	}
+}
+
+// WithSquashMessage sets the commit message of the squashed layer.
+func WithSquashMessage(message string) SquashOption {
+	return func(o *types.ImageSquashOptions) {
+		o.Message = message
+	}
+}
+
+// Squash squashes the last lastN layers of source into a single layer, and stores the result as target.
+func (c *Client) Squash(ctx context.Context, source, target string, lastN int, opts ...SquashOption) (*Image, error) {
+	if lastN <= 1 {
+		return nil, fmt.Errorf("the number of layers to squash must be greater than 1, got %d", lastN)
+	}
+	ctx = c.withNamespace(ctx)
+	parsedReference, err := referenceutil.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	options := types.ImageSquashOptions{
+		GOptions:         c.gOptions,
+		SourceImageRef:   source,
+		TargetImageName:  parsedReference.String(),
+		SquashLayerLastN: lastN,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	if err := image.Squash(ctx, c.client, options); err != nil {
+		return nil, err
+	}
+	img, err := c.client.ImageService().Get(ctx, options.TargetImageName)
+	if err != nil {
+		return nil, err
+	}
+	return c.image(ctx, img)
+}
+
+func (c *Client) image(ctx context.Context, img images.Image) (*Image, error) {
+	size, err := img.Size(ctx, containerdutil.NewProvider(c.client), platforms.All)
+	if err != nil {
+		return nil, err
+	}
+	return &Image{
+		Name:      img.Name,
+		Digest:    img.Target.Digest,
+		CreatedAt: img.CreatedAt,
+		Size:      size,
+	}, nil
+}
@@ -0,0 +1,81 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package sdk
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/config"
+)
+
+func TestClientOptions(t *testing.T) {
+	cfg := config.New()
+	cfg.Namespace = "from-config"
+	cfg.Snapshotter = "from-config"
+
+	gOptions := types.GlobalCommandOptions{}
+	for _, o := range []ClientOption{
+		WithConfig(*cfg),
+		WithNamespace("example"),
+		WithHostsDirs("/etc/containerd/certs.d"),
+		WithInsecureRegistry(),
+	} {
+		o(&gOptions)
+	}
+	assert.Equal(t, gOptions.Namespace, "example")
+	assert.Equal(t, gOptions.Snapshotter, "from-config")
+	assert.DeepEqual(t, gOptions.HostsDir, []string{"/etc/containerd/certs.d"})
+	assert.Equal(t, gOptions.InsecureRegistry, true)
+}
+
+func TestPullOptions(t *testing.T) {
+	var options types.ImagePullOptions
+	assert.NilError(t, WithPlatforms("linux/arm64", "linux/amd64")(&options))
+	assert.Equal(t, len(options.OCISpecPlatform), 2)
+	assert.Equal(t, options.OCISpecPlatform[0].Architecture, "arm64")
+
+	assert.ErrorContains(t, WithPlatforms("linux/invalid/arch/variant")(&options), "invalid platform")
+	assert.ErrorContains(t, WithPullMode("sometimes")(&options), "invalid pull mode")
+	assert.NilError(t, WithPullMode("missing")(&options))
+	assert.Equal(t, options.Mode, "missing")
+}
+
+func TestGlobalArgs(t *testing.T) {
+	gOptions := types.GlobalCommandOptions{
+		Address:      "/run/containerd/containerd.sock",
+		Namespace:    "example",
+		Snapshotter:  "overlayfs",
+		DataRoot:     "/var/lib/nerdctl",
+		HostsDir:     []string{"/etc/containerd/certs.d", "/etc/docker/certs.d"},
+		Experimental: true,
+	}
+	assert.DeepEqual(t, globalArgs(gOptions), []string{
+		"--address=/run/containerd/containerd.sock",
+		"--namespace=example",
+		"--snapshotter=overlayfs",
+		"--data-root=/var/lib/nerdctl",
+		"--cni-path=",
+		"--cni-netconfpath=",
+		"--cgroup-manager=",
+		"--insecure-registry=false",
+		"--experimental=true",
+		"--hosts-dir=/etc/containerd/certs.d,/etc/docker/certs.d",
+	})
+}
@@ -0,0 +1,66 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package secretstore
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Crypt specifies the external commands that encrypt and decrypt the data of the secrets,
+// e.g., `age -e -r <RECIPIENT>` and `age -d -i <IDENTITY_FILE>`, or the CLI of a KMS.
+// The commands read the data from stdin, and write the result to stdout.
+type Crypt struct {
+	EncryptCommand []string
+	DecryptCommand []string
+}
+
+func (c Crypt) encrypt(data []byte) ([]byte, error) {
+	out, err := runCryptCommand(c.EncryptCommand, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt the secret: %w", err)
+	}
+	return out, nil
+}
+
+func (c Crypt) decrypt(data []byte) ([]byte, error) {
+	if len(c.DecryptCommand) == 0 {
+		return nil, fmt.Errorf("failed to decrypt the secret: no decrypt command")
+	}
+	out, err := runCryptCommand(c.DecryptCommand, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the secret: %w", err)
+	}
+	return out, nil
+}
+
+func runCryptCommand(argv []string, data []byte) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%v: %w (stderr: %q)", argv, err, strings.TrimSpace(stderr.String()))
+	}
+	if stdout.Len() == 0 {
+		return nil, fmt.Errorf("%v printed nothing", argv)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,283 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package secretstore stores the secrets created with `nerdctl secret create`, and stages them for containers.
+// All methods are safe to use concurrently, and perform atomic writes.
+package secretstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/identifiers"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+	"github.com/containerd/nerdctl/v2/pkg/store"
+)
+
+const (
+	secretDirBasename  = "secrets"
+	secretJSONFileName = "secret.json"
+	dataFileName       = "data"
+)
+
+// MaxSize is the maximum size of the data of a secret, same as Docker.
+const MaxSize = 500 * 1024
+
+// ErrSecretStore will wrap all errors here
+var ErrSecretStore = errors.New("secret-store error")
+
+type SecretStore interface {
+	// Create creates a new secret. The data is encrypted with crypt, if crypt has an encrypt command.
+	// It errors if there is an existing secret by that name.
+	Create(name string, data []byte, labels map[string]string, crypt Crypt) (*native.Secret, error)
+	// Get returns an existing secret, without the data
+	Get(name string) (*native.Secret, error)
+	// Data returns the data of an existing secret, decrypted
+	Data(name string) ([]byte, error)
+	// List returns all existing secrets, without the data
+	List() ([]native.Secret, error)
+	// Remove one or more secrets
+	Remove(generator func() ([]string, []error, error)) (removed []string, warns []error, err error)
+}
+
+// New returns a SecretStore
+func New(dataStore, namespace string) (ss SecretStore, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrSecretStore, err)
+		}
+	}()
+
+	if dataStore == "" || namespace == "" {
+		return nil, store.ErrInvalidArgument
+	}
+
+	st, err := store.New(filepath.Join(dataStore, secretDirBasename, namespace), 0o700, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &secretStore{
+		Locker:  st,
+		manager: st,
+	}, nil
+}
+
+type secretStore struct {
+	store.Locker
+
+	manager store.Manager
+}
+
+// Create creates a new secret
+func (ss *secretStore) Create(name string, data []byte, labels map[string]string, crypt Crypt) (secret *native.Secret, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrSecretStore, err)
+		}
+	}()
+
+	if err = identifiers.ValidateDockerCompat(name); err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("secret %q: data must not be empty (%w)", name, errdefs.ErrInvalidArgument)
+	}
+	if len(data) > MaxSize {
+		return nil, fmt.Errorf("secret %q: data must be smaller than %d bytes (%w)", name, MaxSize, errdefs.ErrInvalidArgument)
+	}
+
+	secret = &native.Secret{
+		Name:      name,
+		CreatedAt: time.Now().UTC(),
+		Labels:    labels,
+		Size:      int64(len(data)),
+	}
+	if len(crypt.EncryptCommand) > 0 {
+		if len(crypt.DecryptCommand) == 0 {
+			return nil, fmt.Errorf("the decrypt command must be set with the encrypt command (%w)", errdefs.ErrInvalidArgument)
+		}
+		// Encrypted before taking the lock, as the command may take long
+		if data, err = crypt.encrypt(data); err != nil {
+			return nil, err
+		}
+		secret.Encrypted = true
+		secret.DecryptCommand = crypt.DecryptCommand
+	}
+	secretJSON, err := json.Marshal(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ss.Locker.WithLock(func() error {
+		if doesExist, err := ss.manager.Exists(name); err != nil {
+			return err
+		} else if doesExist {
+			return fmt.Errorf("secret %q: %w", name, errdefs.ErrAlreadyExists)
+		}
+		// The data is written first, so that a secret is never listed without its data
+		if err := ss.manager.Set(data, name, dataFileName); err != nil {
+			return err
+		}
+		if err := ss.manager.Set(secretJSON, name, secretJSONFileName); err != nil {
+			return errors.Join(err, ss.manager.Delete(name))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// Get retrieves a secret from the store, without the data
+func (ss *secretStore) Get(name string) (secret *native.Secret, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrSecretStore, err)
+		}
+	}()
+
+	if err = identifiers.ValidateDockerCompat(name); err != nil {
+		return nil, err
+	}
+
+	err = ss.Locker.WithLock(func() error {
+		secret, err = ss.rawGet(name)
+		return err
+	})
+
+	return secret, err
+}
+
+// Data retrieves the data of a secret, decrypted with its decrypt command
+func (ss *secretStore) Data(name string) (data []byte, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrSecretStore, err)
+		}
+	}()
+
+	if err = identifiers.ValidateDockerCompat(name); err != nil {
+		return nil, err
+	}
+
+	var secret *native.Secret
+	err = ss.Locker.WithLock(func() error {
+		if secret, err = ss.rawGet(name); err != nil {
+			return err
+		}
+		data, err = ss.manager.Get(name, dataFileName)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Decrypted after releasing the lock, as the command may take long
+	if secret.Encrypted {
+		return Crypt{DecryptCommand: secret.DecryptCommand}.decrypt(data)
+	}
+	return data, nil
+}
+
+func (ss *secretStore) List() (res []native.Secret, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrSecretStore, err)
+		}
+	}()
+
+	err = ss.Locker.WithLock(func() error {
+		names, err := ss.manager.List()
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			secret, err := ss.rawGet(name)
+			if err != nil {
+				log.L.WithError(err).Errorf("something is wrong with %q", name)
+				continue
+			}
+			res = append(res, *secret)
+		}
+
+		return nil
+	})
+
+	return res, err
+}
+
+// Remove will remove one or more secrets
+func (ss *secretStore) Remove(generator func() ([]string, []error, error)) (removed []string, warns []error, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrSecretStore, err)
+		}
+	}()
+
+	err = ss.Locker.WithLock(func() error {
+		var names []string
+		names, warns, err = generator()
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			if err = identifiers.ValidateDockerCompat(name); err != nil {
+				warns = append(warns, err)
+				continue
+			}
+
+			if doesExist, err := ss.manager.Exists(name); err != nil {
+				return err
+			} else if !doesExist {
+				warns = append(warns, fmt.Errorf("secret %q: %w", name, store.ErrNotFound))
+				continue
+			} else if err = ss.manager.Delete(name); err != nil {
+				return err
+			}
+
+			removed = append(removed, name)
+		}
+
+		return nil
+	})
+
+	return removed, warns, err
+}
+
+func (ss *secretStore) rawGet(name string) (*native.Secret, error) {
+	content, err := ss.manager.Get(name, secretJSONFileName)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, fmt.Errorf("secret %q: %w", name, store.ErrNotFound)
+		}
+		return nil, err
+	}
+	var secret native.Secret
+	if err := json.Unmarshal(content, &secret); err != nil {
+		return nil, err
+	}
+	return &secret, nil
+}
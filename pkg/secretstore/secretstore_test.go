@@ -0,0 +1,194 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package secretstore
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSecretStore(t *testing.T) {
+	ss, err := New(t.TempDir(), "default")
+	assert.NilError(t, err)
+
+	secret, err := ss.Create("secret1", []byte("hunter2"), map[string]string{"foo": "bar"}, Crypt{})
+	assert.NilError(t, err)
+	assert.Equal(t, secret.Size, int64(7))
+	assert.Assert(t, !secret.Encrypted)
+	_, err = ss.Create("secret1", []byte("hunter3"), nil, Crypt{})
+	assert.ErrorContains(t, err, "already exists")
+	_, err = ss.Create("secret2", nil, nil, Crypt{})
+	assert.ErrorContains(t, err, "must not be empty")
+	_, err = ss.Create("secret2", make([]byte, MaxSize+1), nil, Crypt{})
+	assert.ErrorContains(t, err, "must be smaller than")
+
+	secret, err = ss.Get("secret1")
+	assert.NilError(t, err)
+	assert.Equal(t, secret.Labels["foo"], "bar")
+	data, err := ss.Data("secret1")
+	assert.NilError(t, err)
+	assert.Equal(t, string(data), "hunter2")
+	_, err = ss.Get("nosuchsecret")
+	assert.ErrorContains(t, err, "not found")
+
+	secrets, err := ss.List()
+	assert.NilError(t, err)
+	assert.Equal(t, len(secrets), 1)
+
+	removed, warns, err := ss.Remove(func() ([]string, []error, error) {
+		return []string{"secret1", "nosuchsecret"}, nil, nil
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, removed, []string{"secret1"})
+	assert.Equal(t, len(warns), 1)
+}
+
+func TestSecretStoreEncryption(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test is not compatible with windows")
+	}
+	dataStore := t.TempDir()
+	ss, err := New(dataStore, "default")
+	assert.NilError(t, err)
+
+	crypt := Crypt{
+		EncryptCommand: []string{"base64"},
+		DecryptCommand: []string{"base64", "-d"},
+	}
+	secret, err := ss.Create("secret1", []byte("hunter2"), nil, crypt)
+	assert.NilError(t, err)
+	assert.Assert(t, secret.Encrypted)
+
+	stored, err := os.ReadFile(filepath.Join(dataStore, secretDirBasename, "default", "secret1", dataFileName))
+	assert.NilError(t, err)
+	assert.Equal(t, string(stored), "aHVudGVyMg==\n")
+
+	data, err := ss.Data("secret1")
+	assert.NilError(t, err)
+	assert.Equal(t, string(data), "hunter2")
+
+	_, err = ss.Create("secret2", []byte("hunter2"), nil, Crypt{EncryptCommand: []string{"base64"}})
+	assert.ErrorContains(t, err, "the decrypt command must be set")
+	_, err = ss.Create("secret2", []byte("hunter2"), nil, Crypt{EncryptCommand: []string{"false"}, DecryptCommand: []string{"true"}})
+	assert.ErrorContains(t, err, "failed to encrypt the secret")
+}
+
+func TestParseReference(t *testing.T) {
+	testCases := []struct {
+		s        string
+		expected Reference
+		err      string
+	}{
+		{
+			s:        "secret1",
+			expected: Reference{Name: "secret1", Target: "/run/secrets/secret1", Mode: 0o444},
+		},
+		{
+			s:        "id=secret1,target=password",
+			expected: Reference{Name: "secret1", Target: "/run/secrets/password", Mode: 0o444},
+		},
+		{
+			s:        "source=secret1,target=/etc/app/password,uid=1000,gid=1001,mode=0400",
+			expected: Reference{Name: "secret1", Target: "/etc/app/password", UID: 1000, GID: 1001, Mode: 0o400},
+		},
+		{
+			s:   "target=/etc/app/password",
+			err: "no secret name",
+		},
+		{
+			s:   "secret1,target=password",
+			err: "expected NAME, or KEY=VALUE pairs",
+		},
+		{
+			s:   "id=secret1,mode=4755",
+			err: "only the permission bits can be set",
+		},
+		{
+			s:   "id=secret1,uid=-1",
+			err: "must not be negative",
+		},
+		{
+			s:   "id=secret1,foo=bar",
+			err: "unknown key",
+		},
+	}
+	for _, tc := range testCases {
+		ref, err := ParseReference(tc.s)
+		if tc.err != "" {
+			assert.ErrorContains(t, err, tc.err, tc.s)
+			continue
+		}
+		assert.NilError(t, err, tc.s)
+		assert.DeepEqual(t, ref, tc.expected)
+	}
+}
+
+func TestStage(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("secrets are only supported on Linux")
+	}
+	// The staging dir has to be on a tmpfs
+	tmpfsDir, err := os.MkdirTemp("/dev/shm", "nerdctl-test-")
+	if err != nil || checkTmpfs(tmpfsDir) != nil {
+		t.Skip("test requires /dev/shm to be a tmpfs")
+	}
+	t.Cleanup(func() { os.RemoveAll(tmpfsDir) })
+	dataStore := t.TempDir()
+	ss, err := New(dataStore, "default")
+	assert.NilError(t, err)
+	_, err = ss.Create("secret1", []byte("hunter2"), nil, Crypt{})
+	assert.NilError(t, err)
+
+	cs := &ContainerSecrets{
+		DataStore:  dataStore,
+		StagingDir: filepath.Join(tmpfsDir, "staging"),
+		References: []Reference{
+			{Name: "secret1", Target: "/run/secrets/secret1", UID: os.Getuid(), GID: os.Getgid(), Mode: 0o400},
+		},
+	}
+	assert.NilError(t, cs.Stage("default"))
+	// Staging again, e.g., on restart, overwrites the files
+	assert.NilError(t, cs.Stage("default"))
+
+	mounts := cs.Mounts()
+	assert.Equal(t, len(mounts), 1)
+	assert.Equal(t, mounts[0].Destination, "/run/secrets/secret1")
+	st, err := os.Stat(mounts[0].Source)
+	assert.NilError(t, err)
+	assert.Equal(t, st.Mode().Perm(), os.FileMode(0o400))
+	data, err := os.ReadFile(mounts[0].Source)
+	assert.NilError(t, err)
+	assert.Equal(t, string(data), "hunter2")
+
+	assert.NilError(t, cs.Unstage())
+	_, err = os.Stat(cs.StagingDir)
+	assert.Assert(t, os.IsNotExist(err))
+
+	cs.References[0].Name = "nosuchsecret"
+	assert.ErrorContains(t, cs.Stage("default"), "not found")
+
+	// The disk is refused
+	if diskDir := t.TempDir(); checkTmpfs(diskDir) != nil {
+		cs.References[0].Name = "secret1"
+		cs.StagingDir = filepath.Join(diskDir, "staging")
+		assert.ErrorContains(t, cs.Stage("default"), "not on a tmpfs")
+	}
+}
@@ -0,0 +1,211 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package secretstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+const (
+	// DefaultTargetDir is the directory in the container where the secrets are mounted by default, same as Docker.
+	DefaultTargetDir = "/run/secrets"
+	// DefaultMode is the default file mode of the secrets in the container, same as Docker.
+	DefaultMode os.FileMode = 0o444
+)
+
+// Reference is a secret referred by `nerdctl run --secret`.
+type Reference struct {
+	Name   string
+	Target string
+	UID    int
+	GID    int
+	Mode   os.FileMode
+}
+
+// ParseReference parses the value of `nerdctl run --secret`, e.g., "mysecret",
+// or "id=mysecret,target=/etc/app/password,uid=1000,gid=1000,mode=0400".
+func ParseReference(s string) (Reference, error) {
+	ref := Reference{Mode: DefaultMode}
+	for _, field := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(field, "=")
+		if !ok {
+			if ref.Name != "" || strings.Contains(s, "=") {
+				return ref, fmt.Errorf("invalid secret %q: expected NAME, or KEY=VALUE pairs (%w)", s, errdefs.ErrInvalidArgument)
+			}
+			ref.Name = field
+			continue
+		}
+		var err error
+		switch k {
+		case "id", "source", "src":
+			ref.Name = v
+		case "target", "dst":
+			ref.Target = v
+		case "uid":
+			ref.UID, err = strconv.Atoi(v)
+		case "gid":
+			ref.GID, err = strconv.Atoi(v)
+		case "mode":
+			var mode uint64
+			mode, err = strconv.ParseUint(v, 8, 32)
+			if err == nil && mode&^0o777 != 0 {
+				err = errors.New("only the permission bits can be set")
+			}
+			ref.Mode = os.FileMode(mode)
+		default:
+			err = errors.New("unknown key")
+		}
+		if err != nil {
+			return ref, fmt.Errorf("invalid secret %q: %q: %w (%w)", s, field, err, errdefs.ErrInvalidArgument)
+		}
+	}
+	if ref.Name == "" {
+		return ref, fmt.Errorf("invalid secret %q: no secret name (%w)", s, errdefs.ErrInvalidArgument)
+	}
+	if ref.UID < 0 || ref.GID < 0 {
+		return ref, fmt.Errorf("invalid secret %q: uid and gid must not be negative (%w)", s, errdefs.ErrInvalidArgument)
+	}
+	if ref.Target == "" {
+		ref.Target = ref.Name
+	}
+	if !path.IsAbs(ref.Target) {
+		ref.Target = path.Join(DefaultTargetDir, ref.Target)
+	}
+	ref.Target = path.Clean(ref.Target)
+	return ref, nil
+}
+
+// ContainerSecrets is the value of the "nerdctl/secrets" label of a container.
+type ContainerSecrets struct {
+	// DataStore is the data store of the secret store
+	DataStore string
+	// StagingDir is the directory on the host where the secrets are written for the container, on a tmpfs
+	StagingDir string
+	References []Reference
+}
+
+// DecodeContainerSecrets decodes the "nerdctl/secrets" label. It returns nil for an empty label.
+func DecodeContainerSecrets(label string) (*ContainerSecrets, error) {
+	if label == "" {
+		return nil, nil
+	}
+	var cs ContainerSecrets
+	if err := json.Unmarshal([]byte(label), &cs); err != nil {
+		return nil, fmt.Errorf("failed to decode the secrets of the container: %w", err)
+	}
+	return &cs, nil
+}
+
+// StagingDir returns the directory where the secrets of a container are written:
+// "/run/nerdctl/secrets/<NAMESPACE>/<ID>", or "$XDG_RUNTIME_DIR/nerdctl/secrets/<NAMESPACE>/<ID>" in rootless mode.
+// These directories have to be on a tmpfs, so the decrypted secrets never hit the disk.
+func StagingDir(namespace, id string) (string, error) {
+	if runtime.GOOS != "linux" {
+		return "", fmt.Errorf("secrets are not supported on %s (%w)", runtime.GOOS, errdefs.ErrNotImplemented)
+	}
+	runDir := "/run"
+	if rootlessutil.IsRootless() {
+		var err error
+		if runDir, err = rootlessutil.XDGRuntimeDir(); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(runDir, "nerdctl", "secrets", namespace, id), nil
+}
+
+// Stage writes the secrets to the staging dir, decrypted.
+// It is called before every task creation of the container, i.e., by `nerdctl start`, and by the logging process
+// for the restarts by the containerd restart manager, as the staging dir does not survive a reboot.
+// It fails if the staging dir is not on a tmpfs.
+func (cs *ContainerSecrets) Stage(namespace string) error {
+	ss, err := New(cs.DataStore, namespace)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cs.StagingDir, 0o700); err != nil {
+		return err
+	}
+	if err := checkTmpfs(cs.StagingDir); err != nil {
+		return err
+	}
+	for i, ref := range cs.References {
+		data, err := ss.Data(ref.Name)
+		if err != nil {
+			return err
+		}
+		if err := writeStagedFile(filepath.Join(cs.StagingDir, strconv.Itoa(i)), data, ref); err != nil {
+			return fmt.Errorf("failed to stage secret %q: %w", ref.Name, err)
+		}
+	}
+	return nil
+}
+
+// Unstage removes the staging dir.
+func (cs *ContainerSecrets) Unstage() error {
+	return os.RemoveAll(cs.StagingDir)
+}
+
+// Mounts returns the read-only bind mounts of the staged secrets.
+func (cs *ContainerSecrets) Mounts() []specs.Mount {
+	mounts := make([]specs.Mount, len(cs.References))
+	for i, ref := range cs.References {
+		mounts[i] = specs.Mount{
+			Destination: ref.Target,
+			Type:        "bind",
+			Source:      filepath.Join(cs.StagingDir, strconv.Itoa(i)),
+			Options:     []string{"rbind", "ro", "nosuid", "nodev", "noexec"},
+		}
+	}
+	return mounts
+}
+
+// writeStagedFile writes the file atomically, so that a restarting container never reads a partial secret.
+func writeStagedFile(p string, data []byte, ref Reference) error {
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".tmp-"+filepath.Base(p))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chown(tmp.Name(), ref.UID, ref.GID); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), ref.Mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
@@ -0,0 +1,35 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package secretstore
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// checkTmpfs refuses the staging dir unless it is on a tmpfs, so that the decrypted secrets never hit the disk.
+func checkTmpfs(dir string) error {
+	var sfs unix.Statfs_t
+	if err := unix.Statfs(dir, &sfs); err != nil {
+		return err
+	}
+	if uint32(sfs.Type) != unix.TMPFS_MAGIC {
+		return fmt.Errorf("refusing to write the decrypted secrets to %q, as it is not on a tmpfs", dir)
+	}
+	return nil
+}
@@ -23,6 +23,13 @@ import (
 )
 
 // canIgnoreSignal is from https://github.com/containerd/containerd/blob/v1.7.0-rc.2/cmd/ctr/commands/signals_linux.go#L25-L27
+//
+// Like Docker, SIGCHLD and SIGPIPE are not forwarded either, as they concern nerdctl itself
+// (e.g., `nerdctl run ... | head` must not kill the container with SIGPIPE).
 func canIgnoreSignal(s os.Signal) bool {
-	return s == unix.SIGURG
+	switch s {
+	case unix.SIGURG, unix.SIGCHLD, unix.SIGPIPE:
+		return true
+	}
+	return false
 }
@@ -0,0 +1,434 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package signutil
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/containerd/containerd/v2/core/images"
+)
+
+// The requirement types of a bundle policy, as in containers-policy.json(5).
+const (
+	RequirementInsecureAcceptAnything = "insecureAcceptAnything"
+	RequirementReject                 = "reject"
+	RequirementSigstoreSigned         = "sigstoreSigned"
+)
+
+// The signed identity types of a sigstoreSigned requirement, as in containers-policy.json(5).
+const (
+	IdentityMatchRepository = "matchRepository"
+	IdentityMatchExact      = "matchExact"
+)
+
+const (
+	// cosignSignatureAnnotation is the layer annotation holding the base64 encoded signature of the layer (the payload).
+	cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+	// bundleBlobLimit is the maximum size of the blobs (index, manifests, signature payloads) kept in memory for verification.
+	bundleBlobLimit = 4 << 20
+)
+
+// BundlePolicy is the policy for verifying an image archive before loading it.
+// The format is the subset of containers-policy.json(5) that can be evaluated offline:
+// only the "docker" transport, and the insecureAcceptAnything, reject, and sigstoreSigned (with public keys) requirements.
+type BundlePolicy struct {
+	Default    []PolicyRequirement                       `json:"default"`
+	Transports map[string]map[string][]PolicyRequirement `json:"transports,omitempty"`
+}
+
+// PolicyRequirement is a requirement of a BundlePolicy.
+type PolicyRequirement struct {
+	Type string `json:"type"`
+	// KeyPath, KeyPaths, and KeyData specify the public keys of a sigstoreSigned requirement.
+	// A signature made by any of the keys satisfies the requirement.
+	KeyPath        string          `json:"keyPath,omitempty"`
+	KeyPaths       []string        `json:"keyPaths,omitempty"`
+	KeyData        string          `json:"keyData,omitempty"`
+	SignedIdentity *SignedIdentity `json:"signedIdentity,omitempty"`
+
+	keys []crypto.PublicKey
+}
+
+// SignedIdentity specifies how the identity in a signature must match the name of the image.
+// Defaults to matchRepository.
+type SignedIdentity struct {
+	Type string `json:"type"`
+}
+
+// LoadBundlePolicy reads and validates the policy file at path.
+func LoadBundlePolicy(policyPath string) (*BundlePolicy, error) {
+	b, err := os.ReadFile(policyPath)
+	if err != nil {
+		return nil, err
+	}
+	var p BundlePolicy
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy %q: %w", policyPath, err)
+	}
+	if len(p.Default) == 0 {
+		return nil, fmt.Errorf("policy %q: \"default\" must not be empty", policyPath)
+	}
+	reqs := [][]PolicyRequirement{p.Default}
+	for transport, scopes := range p.Transports {
+		if transport != "docker" {
+			return nil, fmt.Errorf("policy %q: unsupported transport %q (only \"docker\" is supported)", policyPath, transport)
+		}
+		for scope, r := range scopes {
+			if len(r) == 0 {
+				return nil, fmt.Errorf("policy %q: the requirements of scope %q must not be empty", policyPath, scope)
+			}
+			reqs = append(reqs, r)
+		}
+	}
+	for _, r := range reqs {
+		for i := range r {
+			if err := r[i].init(filepath.Dir(policyPath)); err != nil {
+				return nil, fmt.Errorf("policy %q: %w", policyPath, err)
+			}
+		}
+	}
+	return &p, nil
+}
+
+func (r *PolicyRequirement) init(baseDir string) error {
+	switch r.Type {
+	case RequirementInsecureAcceptAnything, RequirementReject:
+		return nil
+	case RequirementSigstoreSigned:
+	default:
+		return fmt.Errorf("unsupported requirement type %q", r.Type)
+	}
+	if r.SignedIdentity != nil {
+		switch r.SignedIdentity.Type {
+		case IdentityMatchRepository, IdentityMatchExact:
+		default:
+			return fmt.Errorf("unsupported signedIdentity type %q", r.SignedIdentity.Type)
+		}
+	}
+	var pems [][]byte
+	keyPaths := r.KeyPaths
+	if r.KeyPath != "" {
+		keyPaths = append([]string{r.KeyPath}, keyPaths...)
+	}
+	for _, p := range keyPaths {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(baseDir, p)
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		pems = append(pems, b)
+	}
+	if r.KeyData != "" {
+		b, err := base64.StdEncoding.DecodeString(r.KeyData)
+		if err != nil {
+			return fmt.Errorf("failed to decode keyData: %w", err)
+		}
+		pems = append(pems, b)
+	}
+	if len(pems) == 0 {
+		// Fulcio certificates and Rekor need network access or a trust root, which are not supported for offline verification.
+		return errors.New("sigstoreSigned requires keyPath, keyPaths, or keyData (keyless verification is not supported)")
+	}
+	for _, b := range pems {
+		k, err := parsePublicKey(b)
+		if err != nil {
+			return err
+		}
+		r.keys = append(r.keys, k)
+	}
+	return nil
+}
+
+func parsePublicKey(b []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("failed to decode the public key: no PEM block found")
+	}
+	k, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the public key: %w", err)
+	}
+	return k, nil
+}
+
+// requirementsFor returns the requirements for the image name, by looking up the most specific scope,
+// i.e., the name itself, the repository, the namespaces of the repository, and the registry, in this order.
+func (p *BundlePolicy) requirementsFor(name string) []PolicyRequirement {
+	scopes := p.Transports["docker"]
+	if named, err := reference.ParseNormalizedNamed(name); err == nil && len(scopes) > 0 {
+		candidates := []string{named.String()}
+		for repo := named.Name(); repo != ""; {
+			candidates = append(candidates, repo)
+			i := strings.LastIndex(repo, "/")
+			if i < 0 {
+				break
+			}
+			repo = repo[:i]
+		}
+		for _, c := range candidates {
+			if r, ok := scopes[c]; ok {
+				return r
+			}
+		}
+	}
+	if r, ok := scopes[""]; ok {
+		return r
+	}
+	return p.Default
+}
+
+// cosignPayload is the "simple signing" payload signed by cosign.
+type cosignPayload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest digest.Digest `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+// bundleSignature is a signature found in a bundle.
+type bundleSignature struct {
+	payload   []byte
+	signature []byte
+	parsed    cosignPayload
+}
+
+// VerifyBundle verifies the image archive (OCI Image Layout in a tar) read from r against the policy.
+//
+// The digests of all the blobs are verified, and then the images listed in the index are verified with
+// the cosign signatures contained in the archive, e.g., saved together with `nerdctl save IMAGE IMAGE:sha256-<DIGEST>.sig`.
+// The signature images themselves are not subject to the policy.
+func VerifyBundle(r io.Reader, policy *BundlePolicy) error {
+	blobs, index, err := readBundle(r)
+	if err != nil {
+		return err
+	}
+
+	// Collect the signatures, and the images subject to the policy.
+	var (
+		sigs []bundleSignature
+		imgs []ocispec.Descriptor
+	)
+	for _, desc := range index.Manifests {
+		s, isSig, err := readSignatures(blobs, desc)
+		if err != nil {
+			return err
+		}
+		if isSig {
+			sigs = append(sigs, s...)
+		} else {
+			imgs = append(imgs, desc)
+		}
+	}
+	if len(imgs) == 0 {
+		return errors.New("no image found in the archive")
+	}
+
+	for _, desc := range imgs {
+		name := bundleImageName(desc)
+		for _, req := range policy.requirementsFor(name) {
+			if err := req.verify(name, desc.Digest, sigs); err != nil {
+				if name == "" {
+					name = desc.Digest.String()
+				}
+				return fmt.Errorf("image %s rejected by policy: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// readBundle reads the tar stream, verifies the digests of the blobs, and returns the small blobs and the index.
+func readBundle(r io.Reader) (map[digest.Digest][]byte, *ocispec.Index, error) {
+	var (
+		blobs     = make(map[digest.Digest][]byte)
+		indexJSON []byte
+	)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read the archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := strings.TrimPrefix(path.Clean(hdr.Name), "./")
+		if name == ocispec.ImageIndexFile {
+			if indexJSON, err = io.ReadAll(io.LimitReader(tr, bundleBlobLimit)); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		parts := strings.Split(name, "/")
+		if len(parts) != 3 || parts[0] != ocispec.ImageBlobsDir {
+			continue
+		}
+		dgst := digest.NewDigestFromEncoded(digest.Algorithm(parts[1]), parts[2])
+		if err := dgst.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("invalid blob %q: %w", name, err)
+		}
+		verifier := dgst.Verifier()
+		var buf bytes.Buffer
+		w := io.Writer(verifier)
+		if hdr.Size <= bundleBlobLimit {
+			w = io.MultiWriter(verifier, &buf)
+		}
+		if _, err := io.Copy(w, tr); err != nil {
+			return nil, nil, err
+		}
+		if !verifier.Verified() {
+			return nil, nil, fmt.Errorf("blob %s does not match its digest", dgst)
+		}
+		if hdr.Size <= bundleBlobLimit {
+			blobs[dgst] = buf.Bytes()
+		}
+	}
+	if indexJSON == nil {
+		return nil, nil, fmt.Errorf("no %s found in the archive (only OCI archives, e.g., created by `nerdctl save`, can be verified)", ocispec.ImageIndexFile)
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(indexJSON, &index); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", ocispec.ImageIndexFile, err)
+	}
+	return blobs, &index, nil
+}
+
+// readSignatures returns the cosign signatures in the manifest desc.
+// isSig is false when desc is not a cosign signature manifest.
+func readSignatures(blobs map[digest.Digest][]byte, desc ocispec.Descriptor) (sigs []bundleSignature, isSig bool, err error) {
+	if !images.IsManifestType(desc.MediaType) {
+		return nil, false, nil
+	}
+	b, ok := blobs[desc.Digest]
+	if !ok {
+		return nil, false, fmt.Errorf("manifest %s not found in the archive", desc.Digest)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return nil, false, fmt.Errorf("failed to parse manifest %s: %w", desc.Digest, err)
+	}
+	for _, l := range manifest.Layers {
+		encoded, ok := l.Annotations[cosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+		isSig = true
+		sig, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, true, fmt.Errorf("invalid signature in manifest %s: %w", desc.Digest, err)
+		}
+		payload, ok := blobs[l.Digest]
+		if !ok {
+			return nil, true, fmt.Errorf("signature payload %s not found in the archive", l.Digest)
+		}
+		s := bundleSignature{payload: payload, signature: sig}
+		if err := json.Unmarshal(payload, &s.parsed); err != nil {
+			return nil, true, fmt.Errorf("failed to parse signature payload %s: %w", l.Digest, err)
+		}
+		sigs = append(sigs, s)
+	}
+	return sigs, isSig, nil
+}
+
+// bundleImageName returns the name of the image in the index, or an empty string when it has no name.
+func bundleImageName(desc ocispec.Descriptor) string {
+	if name := desc.Annotations[images.AnnotationImageName]; name != "" {
+		return name
+	}
+	return desc.Annotations[ocispec.AnnotationRefName]
+}
+
+func (r *PolicyRequirement) verify(name string, dgst digest.Digest, sigs []bundleSignature) error {
+	switch r.Type {
+	case RequirementInsecureAcceptAnything:
+		return nil
+	case RequirementReject:
+		return errors.New("rejected")
+	}
+	for _, s := range sigs {
+		if s.parsed.Critical.Image.DockerManifestDigest != dgst || !r.matchIdentity(name, s.parsed.Critical.Identity.DockerReference) {
+			continue
+		}
+		for _, k := range r.keys {
+			if verifySignature(k, s.payload, s.signature) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("no valid signature found for %s", dgst)
+}
+
+func (r *PolicyRequirement) matchIdentity(name, signedRef string) bool {
+	named, err := reference.ParseNormalizedNamed(name)
+	if err != nil {
+		return false
+	}
+	// cosign records index.docker.io for Docker Hub
+	signed, err := reference.ParseNormalizedNamed(strings.Replace(signedRef, "index.docker.io/", "docker.io/", 1))
+	if err != nil {
+		return false
+	}
+	if r.SignedIdentity != nil && r.SignedIdentity.Type == IdentityMatchExact {
+		return named.String() == signed.String()
+	}
+	return named.Name() == signed.Name()
+}
+
+func verifySignature(key crypto.PublicKey, payload, sig []byte) bool {
+	hashed := sha256.Sum256(payload)
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, hashed[:], sig)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, hashed[:], sig) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, payload, sig)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,198 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package signutil
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/containerd/v2/core/images"
+)
+
+type testBundle struct {
+	files map[string][]byte
+	index ocispec.Index
+}
+
+func (b *testBundle) addBlob(mediaType string, data []byte) ocispec.Descriptor {
+	desc := ocispec.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(data), Size: int64(len(data))}
+	b.files["blobs/sha256/"+desc.Digest.Encoded()] = data
+	return desc
+}
+
+func (b *testBundle) addManifest(t *testing.T, name string, layers ...ocispec.Descriptor) ocispec.Descriptor {
+	t.Helper()
+	config := b.addBlob(ocispec.MediaTypeImageConfig, []byte("{}"))
+	m, err := json.Marshal(ocispec.Manifest{MediaType: ocispec.MediaTypeImageManifest, Config: config, Layers: layers})
+	assert.NilError(t, err)
+	desc := b.addBlob(ocispec.MediaTypeImageManifest, m)
+	if name != "" {
+		desc.Annotations = map[string]string{images.AnnotationImageName: name}
+	}
+	b.index.Manifests = append(b.index.Manifests, desc)
+	return desc
+}
+
+func (b *testBundle) tar(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	index, err := json.Marshal(b.index)
+	assert.NilError(t, err)
+	b.files[ocispec.ImageIndexFile] = index
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, data := range b.files {
+		assert.NilError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write(data)
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, tw.Close())
+	return &buf
+}
+
+func (b *testBundle) sign(t *testing.T, key *ecdsa.PrivateKey, ref string, target ocispec.Descriptor) {
+	t.Helper()
+	payload := []byte(`{"critical":{"identity":{"docker-reference":"` + ref + `"},"image":{"docker-manifest-digest":"` +
+		target.Digest.String() + `"},"type":"cosign container image signature"},"optional":null}`)
+	hashed := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, hashed[:])
+	assert.NilError(t, err)
+	layer := b.addBlob("application/vnd.dev.cosign.simplesigning.v1+json", payload)
+	layer.Annotations = map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(sig)}
+	b.addManifest(t, ref+":sha256-"+target.Digest.Encoded()+".sig", layer)
+}
+
+func writeTestPolicy(t *testing.T, key *ecdsa.PrivateKey, policy string) *BundlePolicy {
+	t.Helper()
+	dir := t.TempDir()
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.NilError(t, err)
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "cosign.pub"), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o644))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "policy.json"), []byte(policy), 0o644))
+	p, err := LoadBundlePolicy(filepath.Join(dir, "policy.json"))
+	assert.NilError(t, err)
+	return p
+}
+
+func TestVerifyBundle(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+
+	const signedPolicy = `{
+  "default": [{"type": "reject"}],
+  "transports": {"docker": {"example.com/app": [{"type": "sigstoreSigned", "keyPath": "cosign.pub"}]}}
+}`
+
+	newBundle := func() (*testBundle, ocispec.Descriptor) {
+		b := &testBundle{files: make(map[string][]byte)}
+		img := b.addManifest(t, "example.com/app:1.0", b.addBlob(ocispec.MediaTypeImageLayer, []byte("layer")))
+		return b, img
+	}
+
+	t.Run("signed", func(t *testing.T) {
+		b, img := newBundle()
+		b.sign(t, key, "example.com/app", img)
+		assert.NilError(t, VerifyBundle(b.tar(t), writeTestPolicy(t, key, signedPolicy)))
+	})
+
+	t.Run("signed by another key", func(t *testing.T) {
+		b, img := newBundle()
+		b.sign(t, otherKey, "example.com/app", img)
+		err := VerifyBundle(b.tar(t), writeTestPolicy(t, key, signedPolicy))
+		assert.ErrorContains(t, err, "image example.com/app:1.0 rejected by policy: no valid signature found")
+	})
+
+	t.Run("signed for another repository", func(t *testing.T) {
+		b, img := newBundle()
+		b.sign(t, key, "example.com/other", img)
+		err := VerifyBundle(b.tar(t), writeTestPolicy(t, key, signedPolicy))
+		assert.ErrorContains(t, err, "no valid signature found")
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		b, _ := newBundle()
+		err := VerifyBundle(b.tar(t), writeTestPolicy(t, key, signedPolicy))
+		assert.ErrorContains(t, err, "no valid signature found")
+	})
+
+	t.Run("rejected by default", func(t *testing.T) {
+		b := &testBundle{files: make(map[string][]byte)}
+		b.addManifest(t, "example.com/unknown:1.0")
+		err := VerifyBundle(b.tar(t), writeTestPolicy(t, key, signedPolicy))
+		assert.ErrorContains(t, err, "image example.com/unknown:1.0 rejected by policy: rejected")
+	})
+
+	t.Run("tampered blob", func(t *testing.T) {
+		b, img := newBundle()
+		b.sign(t, key, "example.com/app", img)
+		for name := range b.files {
+			if name == "blobs/sha256/"+digest.FromString("layer").Encoded() {
+				b.files[name] = []byte("tampered")
+			}
+		}
+		err := VerifyBundle(b.tar(t), writeTestPolicy(t, key, signedPolicy))
+		assert.ErrorContains(t, err, "does not match its digest")
+	})
+
+	t.Run("accept anything", func(t *testing.T) {
+		b, _ := newBundle()
+		assert.NilError(t, VerifyBundle(b.tar(t), writeTestPolicy(t, key, `{"default": [{"type": "insecureAcceptAnything"}]}`)))
+	})
+
+	t.Run("not an OCI archive", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NilError(t, tar.NewWriter(&buf).Close())
+		err := VerifyBundle(&buf, writeTestPolicy(t, key, signedPolicy))
+		assert.ErrorContains(t, err, "no index.json found")
+	})
+}
+
+func TestLoadBundlePolicyInvalid(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	for policy, expected := range map[string]string{
+		`{}`:                                  `"default" must not be empty`,
+		`{"default": [{"type": "signedBy"}]}`: `unsupported requirement type "signedBy"`,
+		`{"default": [{"type": "sigstoreSigned"}]}`:                                                                       "keyless verification is not supported",
+		`{"default": [{"type": "reject"}], "transports": {"oci-archive": {}}}`:                                            `unsupported transport "oci-archive"`,
+		`{"default": [{"type": "sigstoreSigned", "keyPath": "cosign.pub", "signedIdentity": {"type": "remapIdentity"}}]}`: `unsupported signedIdentity type "remapIdentity"`,
+	} {
+		dir := t.TempDir()
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		assert.NilError(t, err)
+		assert.NilError(t, os.WriteFile(filepath.Join(dir, "cosign.pub"), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o644))
+		assert.NilError(t, os.WriteFile(filepath.Join(dir, "policy.json"), []byte(policy), 0o644))
+		_, err = LoadBundlePolicy(filepath.Join(dir, "policy.json"))
+		assert.ErrorContains(t, err, expected, policy)
+	}
+}
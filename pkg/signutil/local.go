@@ -0,0 +1,478 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package signutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/leases"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+// The labels of the signature images stored by `nerdctl image sign`.
+const (
+	// LabelSignatureSubject is the digest of the manifest signed by the signature image.
+	LabelSignatureSubject = labels.Prefix + "signature.subject"
+	// LabelSignatureProvider is the provider (cosign|notation) that made the signature image.
+	LabelSignatureProvider = labels.Prefix + "signature.provider"
+)
+
+const (
+	cosignSimpleSigningMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	cosignPayloadType            = "cosign container image signature"
+	cosignCertificateAnnotation  = "dev.sigstore.cosign/certificate"
+	cosignBundleAnnotation       = "dev.sigstore.cosign/bundle"
+)
+
+// SignLocal signs the image img without pushing it.
+//
+// The signature is stored as a local signature image whose manifest refers to img with the `subject` field,
+// so that it can be pushed together with the image later by `nerdctl push`.
+// A cosign signature is stored as `<REPO>:sha256-<DIGEST>.sig` (the tag used by cosign), and the new signature
+// is appended to the existing one. A notation signature is stored as `<REPO>:sha256-<DIGEST>.notation-<SIGNATURE>`.
+func SignLocal(ctx context.Context, client *containerd.Client, img images.Image, experimental bool, options types.ImageSignOptions) (*images.Image, error) {
+	switch options.Provider {
+	case "cosign", "notation":
+		if !experimental {
+			return nil, fmt.Errorf("%s only work with enable experimental feature", options.Provider)
+		}
+	default:
+		return nil, fmt.Errorf("no signers found: %q", options.Provider)
+	}
+	named, err := reference.ParseNormalizedNamed(img.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the image name %q: %w", img.Name, err)
+	}
+	repo := reference.TrimNamed(named).String()
+	subject := ocispec.Descriptor{
+		MediaType: img.Target.MediaType,
+		Digest:    img.Target.Digest,
+		Size:      img.Target.Size,
+	}
+
+	// Don't gc me and clean the dirty data after 1 hour!
+	ctx, done, err := client.WithLease(ctx, leases.WithRandomID(), leases.WithExpiration(1*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lease for signing: %w", err)
+	}
+	defer done(ctx)
+
+	cs := client.ContentStore()
+	sigImg := images.Image{
+		Labels: map[string]string{
+			LabelSignatureSubject:  subject.Digest.String(),
+			LabelSignatureProvider: options.Provider,
+		},
+		CreatedAt: time.Now(),
+	}
+	switch options.Provider {
+	case "cosign":
+		sigImg.Name = repo + ":" + CosignSignatureTag(subject.Digest)
+		var existing []ocispec.Descriptor
+		if old, err := client.ImageService().Get(ctx, sigImg.Name); err == nil {
+			var manifest ocispec.Manifest
+			if err := readJSON(ctx, cs, old.Target, &manifest); err != nil {
+				return nil, err
+			}
+			existing = manifest.Layers
+		} else if !errdefs.IsNotFound(err) {
+			return nil, err
+		}
+		payload, err := json.Marshal(newCosignPayload(named.Name(), subject.Digest))
+		if err != nil {
+			return nil, err
+		}
+		annotations, err := signCosignBlob(payload, options.CosignKey)
+		if err != nil {
+			return nil, err
+		}
+		if sigImg.Target, err = writeCosignSignature(ctx, cs, subject, existing, payload, annotations); err != nil {
+			return nil, err
+		}
+	case "notation":
+		if sigImg.Target, err = signNotationLocal(ctx, cs, subject, options.NotationKeyName); err != nil {
+			return nil, err
+		}
+		sigImg.Name = fmt.Sprintf("%s:%s.notation-%s", repo, digestTag(subject.Digest), sigImg.Target.Digest.Encoded()[:12])
+	}
+
+	if _, err := client.ImageService().Update(ctx, sigImg); err != nil {
+		if !errdefs.IsNotFound(err) {
+			return nil, err
+		}
+		if _, err := client.ImageService().Create(ctx, sigImg); err != nil {
+			return nil, fmt.Errorf("failed to create the signature image %s: %w", sigImg.Name, err)
+		}
+	}
+	return &sigImg, nil
+}
+
+// LocalSignatures returns the signature images stored by SignLocal for the manifest subject.
+func LocalSignatures(ctx context.Context, client *containerd.Client, subject digest.Digest) ([]images.Image, error) {
+	return client.ImageService().List(ctx, fmt.Sprintf("labels.%q==%s", LabelSignatureSubject, subject))
+}
+
+// RemoteSignatureRef returns the reference to push the signature image sig to the repository repo.
+// A cosign signature is pushed with the tag looked up by cosign, and a notation signature is pushed by
+// digest, to be found with the referrers API.
+func RemoteSignatureRef(sig images.Image, repo string) string {
+	if sig.Labels[LabelSignatureProvider] == "cosign" {
+		return repo + ":" + CosignSignatureTag(digest.Digest(sig.Labels[LabelSignatureSubject]))
+	}
+	return repo + "@" + sig.Target.Digest.String()
+}
+
+// CosignSignatureTag returns the tag of the cosign signature of the manifest dgst, i.e., "sha256-<DIGEST>.sig".
+func CosignSignatureTag(dgst digest.Digest) string {
+	return digestTag(dgst) + ".sig"
+}
+
+// digestTag returns dgst in the form used in the tags of the signatures, e.g., "sha256-<DIGEST>".
+func digestTag(dgst digest.Digest) string {
+	return fmt.Sprintf("%s-%s", dgst.Algorithm(), dgst.Encoded())
+}
+
+func newCosignPayload(repo string, dgst digest.Digest) cosignPayload {
+	var p cosignPayload
+	p.Critical.Identity.DockerReference = repo
+	p.Critical.Image.DockerManifestDigest = dgst
+	p.Critical.Type = cosignPayloadType
+	return p
+}
+
+// cosignBundle is the bundle written by `cosign sign-blob --bundle`.
+type cosignBundle struct {
+	Base64Signature string          `json:"base64Signature"`
+	Cert            string          `json:"cert,omitempty"`
+	RekorBundle     json.RawMessage `json:"rekorBundle,omitempty"`
+}
+
+// signCosignBlob signs payload with `cosign sign-blob`, and returns the annotations of the signature layer.
+func signCosignBlob(payload []byte, keyRef string) (map[string]string, error) {
+	cosignExecutable, err := exec.LookPath("cosign")
+	if err != nil {
+		log.L.WithError(err).Error("cosign executable not found in path $PATH")
+		log.L.Info("you might consider installing cosign from: https://docs.sigstore.dev/cosign/installation")
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "nerdctl-cosign-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	payloadPath := filepath.Join(dir, "payload.json")
+	if err := os.WriteFile(payloadPath, payload, 0600); err != nil {
+		return nil, err
+	}
+	bundlePath := filepath.Join(dir, "bundle.json")
+
+	cosignCmd := exec.Command(cosignExecutable, []string{"sign-blob"}...)
+	cosignCmd.Env = os.Environ()
+
+	// if key is empty, use keyless mode(experimental)
+	if keyRef != "" {
+		cosignCmd.Args = append(cosignCmd.Args, "--key", keyRef)
+	} else {
+		cosignCmd.Env = append(cosignCmd.Env, "COSIGN_EXPERIMENTAL=true")
+	}
+
+	cosignCmd.Args = append(cosignCmd.Args, "--yes", "--bundle", bundlePath, payloadPath)
+
+	log.L.Debugf("running %s %v", cosignExecutable, cosignCmd.Args)
+
+	if err := processCosignIO(cosignCmd); err != nil {
+		return nil, err
+	}
+	if err := cosignCmd.Wait(); err != nil {
+		return nil, err
+	}
+
+	b, err := os.ReadFile(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	return cosignAnnotations(b)
+}
+
+// cosignAnnotations converts the bundle written by `cosign sign-blob --bundle` to the annotations of a signature layer.
+func cosignAnnotations(b []byte) (map[string]string, error) {
+	var bundle cosignBundle
+	if err := json.Unmarshal(b, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse the cosign bundle: %w", err)
+	}
+	if bundle.Base64Signature == "" {
+		return nil, errors.New("no signature found in the cosign bundle")
+	}
+	annotations := map[string]string{
+		cosignSignatureAnnotation: bundle.Base64Signature,
+	}
+	if bundle.Cert != "" {
+		// The certificate is a base64 encoded PEM in the bundle, and a PEM in the annotation.
+		cert, err := base64.StdEncoding.DecodeString(bundle.Cert)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate in the cosign bundle: %w", err)
+		}
+		annotations[cosignCertificateAnnotation] = string(cert)
+	}
+	if len(bundle.RekorBundle) > 0 && !bytes.Equal(bundle.RekorBundle, []byte("null")) {
+		annotations[cosignBundleAnnotation] = string(bundle.RekorBundle)
+	}
+	return annotations, nil
+}
+
+// writeCosignSignature writes a cosign signature manifest with the layers existing and the new signature layer of payload.
+func writeCosignSignature(ctx context.Context, cs content.Store, subject ocispec.Descriptor, existing []ocispec.Descriptor,
+	payload []byte, annotations map[string]string) (ocispec.Descriptor, error) {
+	layer := ocispec.Descriptor{
+		MediaType:   cosignSimpleSigningMediaType,
+		Digest:      digest.FromBytes(payload),
+		Size:        int64(len(payload)),
+		Annotations: annotations,
+	}
+	if err := content.WriteBlob(ctx, cs, layer.Digest.String(), bytes.NewReader(payload), layer); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	var layers []ocispec.Descriptor
+	for _, l := range existing {
+		// Replace the signature of the same payload made with the same key.
+		if l.Digest == layer.Digest && l.Annotations[cosignSignatureAnnotation] == layer.Annotations[cosignSignatureAnnotation] {
+			continue
+		}
+		layers = append(layers, l)
+	}
+	layers = append(layers, layer)
+
+	cfg := ocispec.Image{
+		RootFS: ocispec.RootFS{Type: "layers"},
+	}
+	for _, l := range layers {
+		cfg.RootFS.DiffIDs = append(cfg.RootFS.DiffIDs, l.Digest)
+	}
+	configDesc, err := writeJSON(ctx, cs, ocispec.MediaTypeImageConfig, cfg, nil)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	manifest := ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    configDesc,
+		Layers:    layers,
+		Subject:   &subject,
+	}
+	return writeJSON(ctx, cs, ocispec.MediaTypeImageManifest, manifest, gcLabels(manifest))
+}
+
+// signNotationLocal signs subject with `notation sign --oci-layout`, and imports the signature to cs.
+func signNotationLocal(ctx context.Context, cs content.Store, subject ocispec.Descriptor, keyNameRef string) (ocispec.Descriptor, error) {
+	notationExecutable, err := exec.LookPath("notation")
+	if err != nil {
+		log.L.WithError(err).Error("notation executable not found in path $PATH")
+		log.L.Info("you might consider installing notation from: https://notaryproject.dev/docs/installation/cli/")
+		return ocispec.Descriptor{}, err
+	}
+	dir, err := os.MkdirTemp("", "nerdctl-notation-")
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	defer os.RemoveAll(dir)
+	if err := writeLayout(ctx, cs, dir, subject); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	notationCmd := exec.Command(notationExecutable, []string{"sign", "--oci-layout"}...)
+	// --oci-layout is an experimental feature of notation
+	notationCmd.Env = append(os.Environ(), "NOTATION_EXPERIMENTAL=1")
+
+	// If keyNameRef is empty, don't append --key to notation command. This will cause using the notation default key.
+	if keyNameRef != "" {
+		notationCmd.Args = append(notationCmd.Args, "--key", keyNameRef)
+	}
+
+	notationCmd.Args = append(notationCmd.Args, dir+"@"+subject.Digest.String())
+
+	log.L.Debugf("running %s %v", notationExecutable, notationCmd.Args)
+
+	if err := processNotationIO(notationCmd); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := notationCmd.Wait(); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return importReferrer(ctx, cs, dir, subject)
+}
+
+// writeLayout writes an OCI image layout in dir, containing only the manifest (or the index) subject.
+func writeLayout(ctx context.Context, cs content.Store, dir string, subject ocispec.Descriptor) error {
+	b, err := content.ReadBlob(ctx, cs, subject)
+	if err != nil {
+		return err
+	}
+	blobDir := filepath.Join(dir, ocispec.ImageBlobsDir, subject.Digest.Algorithm().String())
+	if err := os.MkdirAll(blobDir, 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(blobDir, subject.Digest.Encoded()), b, 0600); err != nil {
+		return err
+	}
+	layout, err := json.Marshal(ocispec.ImageLayout{Version: ocispec.ImageLayoutVersion})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, ocispec.ImageLayoutFile), layout, 0600); err != nil {
+		return err
+	}
+	index, err := json.Marshal(ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{subject},
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ocispec.ImageIndexFile), index, 0600)
+}
+
+// importReferrer imports the manifest referring to subject from the OCI image layout in dir, together with its blobs.
+func importReferrer(ctx context.Context, cs content.Store, dir string, subject ocispec.Descriptor) (ocispec.Descriptor, error) {
+	blobsDir := filepath.Join(dir, ocispec.ImageBlobsDir)
+	readLayoutBlob := func(desc ocispec.Descriptor) ([]byte, error) {
+		b, err := os.ReadFile(filepath.Join(blobsDir, desc.Digest.Algorithm().String(), desc.Digest.Encoded()))
+		if err != nil {
+			return nil, err
+		}
+		if digest.FromBytes(b) != desc.Digest {
+			return nil, fmt.Errorf("blob %s does not match its digest", desc.Digest)
+		}
+		return b, nil
+	}
+
+	var (
+		found    bool
+		desc     ocispec.Descriptor
+		manifest ocispec.Manifest
+	)
+	err := filepath.WalkDir(blobsDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || found {
+			return err
+		}
+		if info, err := d.Info(); err != nil || info.Size() > bundleBlobLimit {
+			return err
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		var m ocispec.Manifest
+		if json.Unmarshal(b, &m) != nil || m.MediaType != ocispec.MediaTypeImageManifest ||
+			m.Subject == nil || m.Subject.Digest != subject.Digest {
+			return nil
+		}
+		found, manifest = true, m
+		desc = ocispec.Descriptor{
+			MediaType:    m.MediaType,
+			ArtifactType: m.ArtifactType,
+			Digest:       digest.FromBytes(b),
+			Size:         int64(len(b)),
+		}
+		return nil
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if !found {
+		return ocispec.Descriptor{}, fmt.Errorf("no signature referring to %s found", subject.Digest)
+	}
+
+	for _, d := range append([]ocispec.Descriptor{manifest.Config}, manifest.Layers...) {
+		b, err := readLayoutBlob(d)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		if err := content.WriteBlob(ctx, cs, d.Digest.String(), bytes.NewReader(b), d); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+	b, err := readLayoutBlob(desc)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := content.WriteBlob(ctx, cs, desc.Digest.String(), bytes.NewReader(b), desc, content.WithLabels(gcLabels(manifest))); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return desc, nil
+}
+
+// gcLabels returns the labels to keep the config and the layers of manifest from being garbage collected.
+func gcLabels(manifest ocispec.Manifest) map[string]string {
+	labels := map[string]string{
+		"containerd.io/gc.ref.content.config": manifest.Config.Digest.String(),
+	}
+	for i, l := range manifest.Layers {
+		labels[fmt.Sprintf("containerd.io/gc.ref.content.l.%d", i)] = l.Digest.String()
+	}
+	return labels
+}
+
+func writeJSON(ctx context.Context, cs content.Store, mediaType string, v any, labels map[string]string) (ocispec.Descriptor, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	desc := ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(b),
+		Size:      int64(len(b)),
+	}
+	if err := content.WriteBlob(ctx, cs, desc.Digest.String(), bytes.NewReader(b), desc, content.WithLabels(labels)); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return desc, nil
+}
+
+func readJSON(ctx context.Context, cs content.Store, desc ocispec.Descriptor, v any) error {
+	b, err := content.ReadBlob(ctx, cs, desc)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", desc.Digest, err)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package signutil
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/plugins/content/local"
+)
+
+func newTestStore(t *testing.T) (context.Context, content.Store, ocispec.Descriptor) {
+	cs, err := local.NewStore(t.TempDir())
+	assert.NilError(t, err)
+	ctx := context.Background()
+	subject, err := writeJSON(ctx, cs, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+	}, nil)
+	assert.NilError(t, err)
+	return ctx, cs, subject
+}
+
+func cosignTestBundle(t *testing.T, key *ecdsa.PrivateKey, payload []byte) []byte {
+	hashed := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, hashed[:])
+	assert.NilError(t, err)
+	b, err := json.Marshal(cosignBundle{Base64Signature: base64.StdEncoding.EncodeToString(sig)})
+	assert.NilError(t, err)
+	return b
+}
+
+func TestWriteCosignSignature(t *testing.T) {
+	ctx, cs, subject := newTestStore(t)
+	payload, err := json.Marshal(newCosignPayload("example.com/app", subject.Digest))
+	assert.NilError(t, err)
+
+	var (
+		layers []ocispec.Descriptor
+		keys   []*ecdsa.PrivateKey
+	)
+	for i := 0; i < 2; i++ {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		assert.NilError(t, err)
+		keys = append(keys, key)
+		annotations, err := cosignAnnotations(cosignTestBundle(t, key, payload))
+		assert.NilError(t, err)
+		desc, err := writeCosignSignature(ctx, cs, subject, layers, payload, annotations)
+		assert.NilError(t, err)
+		var manifest ocispec.Manifest
+		assert.NilError(t, readJSON(ctx, cs, desc, &manifest))
+		assert.Equal(t, manifest.Subject.Digest, subject.Digest)
+		layers = manifest.Layers
+	}
+	// The signatures made with different keys are appended.
+	assert.Equal(t, len(layers), 2)
+
+	// Adding the same signature again does not duplicate the layer.
+	desc, err := writeCosignSignature(ctx, cs, subject, layers, payload, layers[1].Annotations)
+	assert.NilError(t, err)
+	var manifest ocispec.Manifest
+	assert.NilError(t, readJSON(ctx, cs, desc, &manifest))
+	assert.Equal(t, len(manifest.Layers), 2)
+
+	// The signature manifest can be verified as a part of a bundle.
+	blobs := make(map[digest.Digest][]byte)
+	for _, d := range append([]ocispec.Descriptor{desc, manifest.Config}, manifest.Layers...) {
+		b, err := content.ReadBlob(ctx, cs, d)
+		assert.NilError(t, err)
+		blobs[d.Digest] = b
+	}
+	sigs, isSig, err := readSignatures(blobs, desc)
+	assert.NilError(t, err)
+	assert.Assert(t, isSig)
+	assert.Equal(t, len(sigs), 2)
+	for i, s := range sigs {
+		assert.Equal(t, s.parsed.Critical.Image.DockerManifestDigest, subject.Digest)
+		assert.Assert(t, verifySignature(&keys[i].PublicKey, s.payload, s.signature))
+	}
+}
+
+func TestCosignAnnotations(t *testing.T) {
+	annotations, err := cosignAnnotations([]byte(`{"base64Signature":"c2ln","cert":"` +
+		base64.StdEncoding.EncodeToString([]byte("-----BEGIN CERTIFICATE-----\n")) +
+		`","rekorBundle":{"SignedEntryTimestamp":"dA=="}}`))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, annotations, map[string]string{
+		cosignSignatureAnnotation:   "c2ln",
+		cosignCertificateAnnotation: "-----BEGIN CERTIFICATE-----\n",
+		cosignBundleAnnotation:      `{"SignedEntryTimestamp":"dA=="}`,
+	})
+
+	annotations, err = cosignAnnotations([]byte(`{"base64Signature":"c2ln","rekorBundle":null}`))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, annotations, map[string]string{cosignSignatureAnnotation: "c2ln"})
+
+	_, err = cosignAnnotations([]byte(`{}`))
+	assert.ErrorContains(t, err, "no signature found")
+}
+
+func TestImportReferrer(t *testing.T) {
+	ctx, cs, subject := newTestStore(t)
+	dir := t.TempDir()
+	assert.NilError(t, writeLayout(ctx, cs, dir, subject))
+
+	_, err := importReferrer(ctx, cs, dir, subject)
+	assert.ErrorContains(t, err, "no signature referring to")
+
+	// Simulate `notation sign --oci-layout`.
+	writeLayoutBlob := func(mediaType string, b []byte) ocispec.Descriptor {
+		desc := ocispec.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(b), Size: int64(len(b))}
+		assert.NilError(t, os.WriteFile(filepath.Join(dir, ocispec.ImageBlobsDir, "sha256", desc.Digest.Encoded()), b, 0600))
+		return desc
+	}
+	config := writeLayoutBlob(ocispec.MediaTypeEmptyJSON, []byte("{}"))
+	sig := writeLayoutBlob("application/jose+json", []byte("signature"))
+	b, err := json.Marshal(ocispec.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: "application/vnd.cncf.notary.signature",
+		Config:       config,
+		Layers:       []ocispec.Descriptor{sig},
+		Subject:      &subject,
+	})
+	assert.NilError(t, err)
+	manifest := writeLayoutBlob(ocispec.MediaTypeImageManifest, b)
+
+	desc, err := importReferrer(ctx, cs, dir, subject)
+	assert.NilError(t, err)
+	assert.Equal(t, desc.Digest, manifest.Digest)
+	assert.Equal(t, desc.ArtifactType, "application/vnd.cncf.notary.signature")
+	got, err := content.ReadBlob(ctx, cs, sig)
+	assert.NilError(t, err)
+	assert.Equal(t, string(got), "signature")
+}
+
+func TestRemoteSignatureRef(t *testing.T) {
+	subject := digest.FromString("image")
+	sig := images.Image{
+		Labels: map[string]string{
+			LabelSignatureSubject:  subject.String(),
+			LabelSignatureProvider: "cosign",
+		},
+		Target: ocispec.Descriptor{Digest: digest.FromString("signature")},
+	}
+	assert.Equal(t, RemoteSignatureRef(sig, "example.com/app"), "example.com/app:sha256-"+subject.Encoded()+".sig")
+	sig.Labels[LabelSignatureProvider] = "notation"
+	assert.Equal(t, RemoteSignatureRef(sig, "example.com/app"), "example.com/app@"+sig.Target.Digest.String())
+}
@@ -60,7 +60,7 @@ func SignNotation(rawRef string, keyNameRef string) error {
 // VerifyNotation verifies an image(`rawRef`) with the pre-configured notation trust policy
 // `hostsDirs` are used to resolve image `rawRef`
 func VerifyNotation(ctx context.Context, rawRef string, hostsDirs []string) (string, error) {
-	digest, err := imgutil.ResolveDigest(ctx, rawRef, false, hostsDirs)
+	digest, err := imgutil.ResolveDigest(ctx, rawRef, nil, hostsDirs)
 	if err != nil {
 		log.G(ctx).WithError(err).Errorf("unable to resolve digest for an image %s: %v", rawRef, err)
 		return rawRef, err
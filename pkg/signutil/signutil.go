@@ -23,10 +23,15 @@ import (
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
 )
 
 // Sign signs an image using a signer and options provided in options.
-func Sign(rawRef string, experimental bool, options types.ImageSignOptions) error {
+// The signing fails in the offline mode, as the signers push the signature to the registry.
+func Sign(rawRef string, experimental, offline bool, options types.ImageSignOptions) error {
+	if options.Provider != "" && options.Provider != "none" && offline {
+		return fmt.Errorf("cannot sign %q with %s: %w", rawRef, options.Provider, dockerconfigresolver.ErrOffline)
+	}
 	switch options.Provider {
 	case "cosign":
 		if !experimental {
@@ -53,7 +58,11 @@ func Sign(rawRef string, experimental bool, options types.ImageSignOptions) erro
 }
 
 // Verify verifies an image using a verifier and options provided in options.
-func Verify(ctx context.Context, rawRef string, hostsDirs []string, experimental bool, options types.ImageVerifyOptions) (ref string, err error) {
+// The verification fails in the offline mode, as the verifiers access the registry.
+func Verify(ctx context.Context, rawRef string, hostsDirs []string, experimental, offline bool, options types.ImageVerifyOptions) (ref string, err error) {
+	if options.Provider != "" && options.Provider != "none" && offline {
+		return "", fmt.Errorf("cannot verify %q with %s: %w", rawRef, options.Provider, dockerconfigresolver.ErrOffline)
+	}
 	switch options.Provider {
 	case "cosign":
 		if !experimental {
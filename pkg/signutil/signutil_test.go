@@ -0,0 +1,40 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package signutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
+)
+
+func TestOffline(t *testing.T) {
+	for _, provider := range []string{"cosign", "notation"} {
+		err := Sign("example.com/foo:latest", true, true, types.ImageSignOptions{Provider: provider})
+		assert.Assert(t, errors.Is(err, dockerconfigresolver.ErrOffline), "%s: %v", provider, err)
+
+		_, err = Verify(context.Background(), "example.com/foo:latest", nil, true, true, types.ImageVerifyOptions{Provider: provider})
+		assert.Assert(t, errors.Is(err, dockerconfigresolver.ErrOffline), "%s: %v", provider, err)
+	}
+	// No signer, nothing to push
+	assert.NilError(t, Sign("example.com/foo:latest", true, true, types.ImageSignOptions{Provider: "none"}))
+}
@@ -18,6 +18,7 @@ package snapshotterutil
 
 import (
 	"bufio"
+	"fmt"
 	"os"
 	"os/exec"
 	"strconv"
@@ -26,6 +27,8 @@ import (
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
 )
 
 // CreateSoci creates a SOCI index(`rawRef`)
@@ -85,7 +88,11 @@ func CreateSoci(rawRef string, gOpts types.GlobalCommandOptions, allPlatform boo
 
 // PushSoci pushes a SOCI index(`rawRef`)
 // `hostsDirs` are used to resolve image `rawRef`
+// The push fails in the offline mode, as `soci push` accesses the registry without nerdctl.
 func PushSoci(rawRef string, gOpts types.GlobalCommandOptions, allPlatform bool, platforms []string) error {
+	if gOpts.Offline {
+		return fmt.Errorf("cannot push the SOCI index of %q: %w", rawRef, dockerconfigresolver.ErrOffline)
+	}
 	log.L.Debugf("pushing SOCI index: %s", rawRef)
 
 	sociExecutable, err := exec.LookPath("soci")
@@ -121,7 +128,7 @@ func PushSoci(rawRef string, gOpts types.GlobalCommandOptions, allPlatform bool,
 			sociCmd.Args = append(sociCmd.Args, "--platform", p)
 		}
 	}
-	if gOpts.InsecureRegistry {
+	if parsed, err := referenceutil.Parse(rawRef); err == nil && gOpts.IsInsecureRegistry(parsed.Domain) {
 		sociCmd.Args = append(sociCmd.Args, "--skip-verify")
 		sociCmd.Args = append(sociCmd.Args, "--plain-http")
 	}
@@ -0,0 +1,109 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package sshutil provides utilities for the SSH destinations such as "ssh://user@host:22".
+package sshutil
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Destination is a parsed "ssh://[USER@]HOST[:PORT][/PATH]".
+type Destination struct {
+	User string
+	Host string
+	Port string
+	// Path is the optional path on the remote host, e.g., the path of the containerd socket
+	Path string
+}
+
+// IsSSH returns true if s looks like "ssh://...".
+func IsSSH(s string) bool {
+	return strings.HasPrefix(s, "ssh://")
+}
+
+// Parse parses "ssh://[USER@]HOST[:PORT][/PATH]".
+func Parse(s string) (*Destination, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSH destination %q: %w", s, err)
+	}
+	if u.Scheme != "ssh" || u.Hostname() == "" || u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("invalid SSH destination %q, must be like \"ssh://[USER@]HOST[:PORT][/PATH]\"", s)
+	}
+	if _, ok := u.User.Password(); ok {
+		return nil, fmt.Errorf("invalid SSH destination %q, passwords are not supported", s)
+	}
+	d := &Destination{
+		User: u.User.Username(),
+		Host: u.Hostname(),
+		Port: u.Port(),
+	}
+	if u.Path != "/" {
+		d.Path = u.Path
+	}
+	return d, nil
+}
+
+// String returns "ssh://[USER@]HOST[:PORT][/PATH]".
+func (d *Destination) String() string {
+	u := url.URL{Scheme: "ssh", Host: d.Host, Path: d.Path}
+	if d.Port != "" {
+		u.Host = net.JoinHostPort(d.Host, d.Port)
+	} else if strings.Contains(d.Host, ":") {
+		u.Host = "[" + d.Host + "]"
+	}
+	if d.User != "" {
+		u.User = url.User(d.User)
+	}
+	return u.String()
+}
+
+// Args returns the arguments of the ssh command for connecting to the destination.
+// The remote command should follow the arguments.
+func (d *Destination) Args() []string {
+	var args []string
+	if d.User != "" {
+		args = append(args, "-l", d.User)
+	}
+	if d.Port != "" {
+		args = append(args, "-p", d.Port)
+	}
+	return append(args, "--", d.Host)
+}
+
+var shellSafeRegexp = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// ShellQuote quotes s for the remote shell, as the remote command of ssh is interpreted by the shell of the remote user.
+func ShellQuote(s string) string {
+	if shellSafeRegexp.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+// ShellJoin quotes and joins args for the remote shell.
+func ShellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, s := range args {
+		quoted[i] = ShellQuote(s)
+	}
+	return strings.Join(quoted, " ")
+}
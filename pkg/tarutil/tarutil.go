@@ -17,7 +17,10 @@
 package tarutil
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -55,3 +58,42 @@ func FindTarBinary() (string, bool, error) {
 	}
 	return "", false, fmt.Errorf("failed to find `tar` binary")
 }
+
+// Create runs the tar binary in dir to write an archive of paths (default: ".") to w,
+// preserving the numeric owners and the extended attributes (e.g., file capabilities).
+func Create(ctx context.Context, dir string, paths []string, w io.Writer) error {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	return run(ctx, dir, "-c", paths, nil, w)
+}
+
+// Extract runs the tar binary in dir to extract the archive from r, like Create.
+func Extract(ctx context.Context, dir string, r io.Reader) error {
+	return run(ctx, dir, "-x", nil, r, nil)
+}
+
+func run(ctx context.Context, dir, mode string, paths []string, stdin io.Reader, stdout io.Writer) error {
+	tarBinary, isGNUTar, err := FindTarBinary()
+	if err != nil {
+		return err
+	}
+	args := []string{mode, "-f", "-", "--numeric-owner"}
+	if isGNUTar {
+		args = append(args, "--xattrs", "--xattrs-include=*")
+	}
+	if len(paths) > 0 {
+		args = append(args, paths...)
+	}
+	cmd := exec.CommandContext(ctx, tarBinary, args...)
+	cmd.Dir = dir
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	log.G(ctx).Debugf("executing %v in %q", cmd.Args, cmd.Dir)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to execute %v: %w (stderr=%q)", cmd.Args, err, stderr.String())
+	}
+	return nil
+}
@@ -213,7 +213,7 @@ func processAttachStreamsOpt(streamsArr []string) streams {
 // StdinCloser is from https://github.com/containerd/containerd/blob/v1.4.3/cmd/ctr/commands/tasks/exec.go#L181-L194
 type StdinCloser struct {
 	mu     sync.Mutex
-	Stdin  *os.File
+	Stdin  io.Reader
 	Closer func()
 	closed bool
 }
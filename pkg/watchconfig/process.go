@@ -0,0 +1,124 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package watchconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/log"
+)
+
+const (
+	// pidFileName is the pid file of the watcher, in the state directory of the container.
+	pidFileName = "watch-config.pid"
+	// logFileName is the log file of the watcher, in the state directory of the container.
+	logFileName = "watch-config.log"
+)
+
+// Args returns the arguments of `nerdctl internal watch-config` for the container.
+func (cfg *Config) Args(id string) []string {
+	args := []string{"internal", "watch-config", "--id=" + id}
+	if cfg.Signal != "" {
+		args = append(args, "--signal="+cfg.Signal)
+	}
+	return append(append(args, "--"), cfg.Paths...)
+}
+
+// Start spawns `nerdctl internal watch-config` for the container, detached from the current process.
+// globalArgs are the global flags of nerdctl, such as `--namespace`, passed to the watcher.
+// The pid is recorded in stateDir, so that the watcher can be stopped with Stop.
+func Start(stateDir string, globalArgs []string, id string, cfg *Config) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	logFile, err := os.OpenFile(filepath.Join(stateDir, logFileName), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+	cmd := exec.Command(exe, append(append([]string(nil), globalArgs...), cfg.Args(id)...)...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = sysProcAttr()
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start the config watcher: %w", err)
+	}
+	pidFile := filepath.Join(stateDir, pidFileName)
+	tmp := filepath.Join(stateDir, "."+pidFileName)
+	if err := os.WriteFile(tmp, []byte(strconv.Itoa(cmd.Process.Pid)), 0o644); err != nil {
+		cmd.Process.Kill()
+		return err
+	}
+	if err := os.Rename(tmp, pidFile); err != nil {
+		cmd.Process.Kill()
+		return err
+	}
+	log.L.Debugf("started config watcher (pid=%d) for %v", cmd.Process.Pid, cfg.Paths)
+	return cmd.Process.Release()
+}
+
+// Stop stops the watcher started with Start in stateDir, if it is running.
+func Stop(stateDir string) {
+	pidFile := filepath.Join(stateDir, pidFileName)
+	b, err := os.ReadFile(pidFile)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.L.WithError(err).Warnf("failed to read %s", pidFile)
+		}
+		return
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err == nil && isWatcherProcess(pid) {
+		if proc, err := os.FindProcess(pid); err == nil {
+			if err := proc.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+				log.L.WithError(err).Warnf("failed to kill config watcher (pid=%d)", pid)
+			}
+		}
+	}
+	if err := os.Remove(pidFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.L.WithError(err).Warnf("failed to remove %s", pidFile)
+	}
+}
+
+// Notify restarts the container, or sends the signal to it, by executing nerdctl.
+// The restart is detached from the watcher, as the watcher is stopped when the container stops.
+func Notify(nerdctlCmd string, nerdctlArgs []string, id, sig string) error {
+	args := append([]string(nil), nerdctlArgs...)
+	if sig == "" {
+		args = append(args, "restart", id)
+	} else {
+		args = append(args, "kill", "--signal="+sig, id)
+	}
+	cmd := exec.Command(nerdctlCmd, args...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if sig != "" {
+		return cmd.Run()
+	}
+	cmd.SysProcAttr = sysProcAttr()
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
@@ -0,0 +1,201 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package watchconfig implements `nerdctl run --watch-config`, restarting (or signaling)
+// the container when the bind-mounted config files change.
+package watchconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/moby/sys/signal"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil"
+)
+
+// Debounce is the quiet period after the last change before the container is notified,
+// so that a burst of writes (e.g., an editor saving a file) triggers a single restart.
+const Debounce = 500 * time.Millisecond
+
+// Config is the configuration of `nerdctl run --watch-config`, stored in the labels.WatchConfig label.
+type Config struct {
+	// Paths are the host paths of the watched files and directories.
+	Paths []string `json:"paths"`
+	// Signal is sent to the container on changes. The container is restarted when empty.
+	Signal string `json:"signal,omitempty"`
+}
+
+// New resolves the container paths to the host paths of the bind mounts and the volumes, and validates the signal.
+// New returns nil if no path is specified.
+func New(paths []string, sig string, mounts []*mountutil.Processed) (*Config, error) {
+	if len(paths) == 0 {
+		if sig != "" {
+			return nil, errors.New("--watch-config-signal requires --watch-config")
+		}
+		return nil, nil
+	}
+	if sig != "" {
+		if _, err := signal.ParseSignal(sig); err != nil {
+			return nil, err
+		}
+	}
+	cfg := &Config{Signal: sig}
+	for _, p := range paths {
+		hostPath, err := hostPath(p, mounts)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(hostPath); err != nil {
+			return nil, fmt.Errorf("failed to watch %q: %w", p, err)
+		}
+		cfg.Paths = append(cfg.Paths, hostPath)
+	}
+	return cfg, nil
+}
+
+// hostPath returns the host path of the container path p, in the innermost bind mount or volume containing p.
+func hostPath(p string, mounts []*mountutil.Processed) (string, error) {
+	if !path.IsAbs(p) {
+		return "", fmt.Errorf("--watch-config %q must be an absolute path in the container", p)
+	}
+	p = path.Clean(p)
+	var found *mountutil.Processed
+	for _, m := range mounts {
+		if m.Type != mountutil.Bind && m.Type != mountutil.Volume {
+			continue
+		}
+		dst := path.Clean(m.Mount.Destination)
+		if p != dst && !strings.HasPrefix(p, strings.TrimSuffix(dst, "/")+"/") {
+			continue
+		}
+		if found == nil || len(dst) > len(path.Clean(found.Mount.Destination)) {
+			found = m
+		}
+	}
+	if found == nil {
+		return "", fmt.Errorf("--watch-config %q is not in a bind mount or a volume", p)
+	}
+	rel := strings.TrimPrefix(p, path.Clean(found.Mount.Destination))
+	return filepath.Join(found.Mount.Source, filepath.FromSlash(rel)), nil
+}
+
+// Parse parses the labels.WatchConfig label. An empty label returns nil.
+func Parse(label string) (*Config, error) {
+	if label == "" {
+		return nil, nil
+	}
+	var cfg Config
+	if err := json.Unmarshal([]byte(label), &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse label %q: %w", labels.WatchConfig, err)
+	}
+	return &cfg, nil
+}
+
+// Watch watches the paths, and calls onChange when they change, until ctx is done.
+// The changes within Debounce are coalesced into a single call.
+//
+// The parent directories of the files are watched, and the files are compared with
+// os.SameFile, so that the files replaced by renaming (as editors and the ConfigMap
+// volumes of Kubernetes do) are still watched.
+// The directories are not watched recursively.
+func Watch(ctx context.Context, paths []string, onChange func()) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	dirs := make(map[string]bool)
+	files := make(map[string]os.FileInfo)
+	for _, p := range paths {
+		st, err := os.Stat(p)
+		if err != nil {
+			return err
+		}
+		watched := p
+		if st.IsDir() {
+			dirs[p] = true
+		} else {
+			files[p] = st
+			watched = filepath.Dir(p)
+		}
+		if err := w.Add(watched); err != nil {
+			return fmt.Errorf("failed to watch %q: %w", watched, err)
+		}
+	}
+
+	// changed returns true if the event changed one of the watched files or directories.
+	changed := func(ev fsnotify.Event) bool {
+		if dirs[ev.Name] || dirs[filepath.Dir(ev.Name)] {
+			return true
+		}
+		res := false
+		for p, old := range files {
+			if filepath.Dir(p) != filepath.Dir(ev.Name) {
+				continue
+			}
+			st, err := os.Stat(p)
+			if err != nil {
+				// The file is being replaced. The next event will see the new file.
+				continue
+			}
+			if p == ev.Name || !os.SameFile(old, st) || !old.ModTime().Equal(st.ModTime()) || old.Size() != st.Size() {
+				files[p] = st
+				res = true
+			}
+		}
+		return res
+	}
+
+	timer := time.NewTimer(Debounce)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if ev.Op == fsnotify.Chmod {
+				continue
+			}
+			if changed(ev) {
+				log.L.Debugf("%s: %s", ev.Op, ev.Name)
+				timer.Reset(Debounce)
+			}
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			log.L.WithError(err).Warn("error while watching the config files")
+		case <-timer.C:
+			onChange()
+		}
+	}
+}
@@ -0,0 +1,108 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package watchconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/mountutil"
+)
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.MkdirAll(filepath.Join(dir, "conf", "nginx"), 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "conf", "nginx", "nginx.conf"), nil, 0o644))
+	mounts := []*mountutil.Processed{
+		{Type: mountutil.Bind, Mount: specs.Mount{Source: dir, Destination: "/etc"}},
+		{Type: mountutil.Bind, Mount: specs.Mount{Source: filepath.Join(dir, "conf"), Destination: "/etc/app/"}},
+		{Type: mountutil.Tmpfs, Mount: specs.Mount{Destination: "/etc/app/nginx"}},
+	}
+
+	cfg, err := New([]string{"/etc/app/nginx/nginx.conf", "/etc/conf"}, "SIGHUP", mounts)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, cfg.Paths, []string{filepath.Join(dir, "conf", "nginx", "nginx.conf"), filepath.Join(dir, "conf")})
+	assert.Equal(t, cfg.Signal, "SIGHUP")
+
+	cfg, err = New(nil, "", mounts)
+	assert.NilError(t, err)
+	assert.Assert(t, cfg == nil)
+
+	_, err = New([]string{"/var/app.conf"}, "", mounts)
+	assert.ErrorContains(t, err, "is not in a bind mount")
+	_, err = New([]string{"app.conf"}, "", mounts)
+	assert.ErrorContains(t, err, "must be an absolute path")
+	_, err = New([]string{"/etc/missing.conf"}, "", mounts)
+	assert.ErrorContains(t, err, "failed to watch")
+	_, err = New([]string{"/etc/conf"}, "SIGFOO", mounts)
+	assert.ErrorContains(t, err, "invalid signal")
+	_, err = New(nil, "SIGHUP", mounts)
+	assert.ErrorContains(t, err, "requires --watch-config")
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "app.conf")
+	assert.NilError(t, os.WriteFile(conf, []byte("foo"), 0o644))
+	other := filepath.Join(dir, "other.conf")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan struct{}, 10)
+	done := make(chan error)
+	go func() {
+		done <- Watch(ctx, []string{conf}, func() { changes <- struct{}{} })
+	}()
+	// Wait for the watcher to be set up
+	time.Sleep(100 * time.Millisecond)
+
+	expectChanges := func(n int) {
+		t.Helper()
+		time.Sleep(2 * Debounce)
+		assert.Equal(t, len(changes), n)
+		for range n {
+			<-changes
+		}
+	}
+
+	// Unrelated files are ignored
+	assert.NilError(t, os.WriteFile(other, []byte("foo"), 0o644))
+	expectChanges(0)
+
+	// A burst of writes is coalesced
+	for range 3 {
+		assert.NilError(t, os.WriteFile(conf, []byte("bar"), 0o644))
+	}
+	expectChanges(1)
+
+	// Replacing the file by renaming is detected, and the new file is still watched
+	tmp := filepath.Join(dir, ".app.conf.swp")
+	assert.NilError(t, os.WriteFile(tmp, []byte("baz"), 0o644))
+	assert.NilError(t, os.Rename(tmp, conf))
+	expectChanges(1)
+	assert.NilError(t, os.WriteFile(conf, []byte("qux"), 0o644))
+	expectChanges(1)
+
+	cancel()
+	assert.NilError(t, <-done)
+}